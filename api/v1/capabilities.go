@@ -0,0 +1,39 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CapabilitiesReport is the data sources available on the host,
+// and the components running with or degraded without them.
+type CapabilitiesReport struct {
+	// Time is when the capabilities were last probed.
+	Time metav1.Time `json:"time"`
+	// Capabilities are the probed data sources (e.g., NVML, kmsg), in the probe order.
+	Capabilities []Capability `json:"capabilities"`
+	// Components are the enabled components with the required capabilities,
+	// in the ascending order of the component name.
+	Components []ComponentCapabilities `json:"components,omitempty"`
+}
+
+// Capability is a data source the components depend on.
+type Capability struct {
+	// Name is the name of the capability (e.g., "nvml", "ipmitool").
+	Name string `json:"name"`
+	// Available is true if the data source is usable by GPUd.
+	Available bool `json:"available"`
+	// Path is the resolved executable or device path, if any.
+	Path string `json:"path,omitempty"`
+	// Reason is why the capability is not available.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ComponentCapabilities is the capabilities a component is running with or degraded without.
+type ComponentCapabilities struct {
+	Component string `json:"component"`
+	// Using are the required capabilities available to the component.
+	Using []string `json:"using,omitempty"`
+	// DegradedWithout are the required capabilities not available,
+	// so the component reports partial or no data.
+	DegradedWithout []string `json:"degradedWithout,omitempty"`
+}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"time"
+)
+
+// PeerState is the gossip membership state of a peer.
+type PeerState string
+
+const (
+	// PeerStateAlive means the peer responds to the gossip probes.
+	PeerStateAlive PeerState = "alive"
+	// PeerStateSuspect means the peer failed to respond to the recent probes,
+	// but has not been declared dead yet.
+	PeerStateSuspect PeerState = "suspect"
+	// PeerStateDead means the peer failed to respond to the probes
+	// past the suspicion timeout, or left the cluster (e.g., GPUd stopped).
+	PeerStateDead PeerState = "dead"
+)
+
+// NodeHealthSummary is the compact health of a node exchanged between the peers.
+type NodeHealthSummary struct {
+	// MachineID is the machine ID of the node, used as the gossip member name.
+	MachineID string `json:"machineID"`
+	// Hostname is the hostname of the node.
+	Hostname string `json:"hostname,omitempty"`
+	// Summary is the overall health summary of the node.
+	Summary HealthSummary `json:"summary"`
+	// UpdatedAt is when the node last summarized its health.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ClusterPeer is a peer node as seen by the local node.
+type ClusterPeer struct {
+	// MachineID is the gossip member name of the peer.
+	MachineID string `json:"machineID"`
+	// Address is the gossip address of the peer ("host:port").
+	Address string `json:"address"`
+	// State is the gossip membership state of the peer.
+	State PeerState `json:"state"`
+	// Health is the last health summary received from the peer,
+	// nil if not received yet.
+	Health *NodeHealthSummary `json:"health,omitempty"`
+	// LastSeen is when the health summary was last received from the peer.
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
+
+// ClusterSummary is the health of the cluster exchanged via the peer gossip,
+// available without the control plane.
+type ClusterSummary struct {
+	// Self is the health summary of the local node.
+	Self NodeHealthSummary `json:"self"`
+	// Peers is the peers known to the local node, sorted by the machine ID.
+	Peers []ClusterPeer `json:"peers"`
+	// Unhealthy is the machine IDs of the peers that are fatal, degraded,
+	// or not alive, sorted.
+	Unhealthy []string `json:"unhealthy,omitempty"`
+}
@@ -0,0 +1,22 @@
+package v1
+
+import "time"
+
+// DrainCheck is the result of a single check of the drain readiness.
+type DrainCheck struct {
+	// Name is the name of the check (e.g., "gpu-processes", "plugin/my-plugin").
+	Name string `json:"name"`
+	// Ready is true if the check does not block the maintenance.
+	Ready bool `json:"ready"`
+	// Reason is the human-readable reason of the check result.
+	Reason string `json:"reason"`
+}
+
+// DrainReadiness reports whether the node can be safely maintained
+// (e.g., drained, rebooted, or have its GPUs reset).
+type DrainReadiness struct {
+	Time time.Time `json:"time"`
+	// Ready is true only if all the checks are ready.
+	Ready  bool         `json:"ready"`
+	Checks []DrainCheck `json:"checks"`
+}
@@ -2,8 +2,13 @@ package v1
 
 // GossipRequest is the request for the gossip request.
 type GossipRequest struct {
-	MachineID   string       `json:"machineID"`
+	MachineID string `json:"machineID"`
+	// MachineInfo is the full machine info snapshot,
+	// omitted when only the changes are requested (and the snapshot was reported before).
 	MachineInfo *MachineInfo `json:"machineInfo,omitempty"`
+	// MachineInfoChanges are the changes since the last reported machine info
+	// (e.g., driver upgraded, GPU removed), empty if nothing changed or nothing reported before.
+	MachineInfoChanges []MachineInfoChange `json:"machineInfoChanges,omitempty"`
 }
 
 // GossipResponse is the response for the gossip request.
@@ -11,3 +16,29 @@ type GossipResponse struct {
 	Status string `json:"status,omitempty"`
 	Error  string `json:"error,omitempty"`
 }
+
+// MachineInfoChangeType is the type of the machine info change.
+type MachineInfoChangeType string
+
+const (
+	// MachineInfoChangeTypeAdded means the item was added (e.g., a new block device).
+	MachineInfoChangeTypeAdded MachineInfoChangeType = "added"
+	// MachineInfoChangeTypeRemoved means the item was removed (e.g., a GPU fell off the bus).
+	MachineInfoChangeTypeRemoved MachineInfoChangeType = "removed"
+	// MachineInfoChangeTypeChanged means the value was changed (e.g., driver upgraded).
+	MachineInfoChangeTypeChanged MachineInfoChangeType = "changed"
+)
+
+// MachineInfoChange is a structured change of the machine info
+// between the two snapshots.
+type MachineInfoChange struct {
+	Type MachineInfoChangeType `json:"type"`
+	// Field is the JSON path of the changed field, with the list items
+	// keyed by their identity (e.g., "gpuDriverVersion", "gpuInfo.gpus[GPU-46a3bbe2].sn",
+	// "nicInfo.privateIPInterfaces[eth0]").
+	Field string `json:"field"`
+	// From is the previous value, empty if added.
+	From string `json:"from,omitempty"`
+	// To is the current value, empty if removed.
+	To string `json:"to,omitempty"`
+}
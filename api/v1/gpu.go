@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GPU is a GPU on the host, with its performance score.
+type GPU struct {
+	GPUMapping
+
+	// PerformanceScore is the rolling performance score of the GPU,
+	// nil if not yet computed (e.g., no metrics within the window).
+	PerformanceScore *GPUPerformanceScore `json:"performance_score,omitempty"`
+}
+
+// GPUs is the list of the GPUs, sorted by the index.
+type GPUs []GPU
+
+// GPUPerformanceScore is the performance score of a GPU between 0 (worst) and 100 (best),
+// combining the utilization capacity, the throttle time, the ECC errors, and the NVLink errors
+// within the rolling window (see "docs/INTEGRATION.md" for the formula).
+type GPUPerformanceScore struct {
+	Time time.Time `json:"time"`
+	// Window is the rolling window of the metrics the score is computed from.
+	Window metav1.Duration `json:"window"`
+
+	// Score is the weighted score between 0 and 100.
+	Score float64 `json:"score"`
+
+	// Capacity is the ratio of the average graphics clock of the GPU when busy
+	// to the fastest GPU on the host, between 0 and 1.
+	Capacity float64 `json:"capacity"`
+	// ThrottleRatio is the ratio of the samples with the hardware slowdown
+	// (including the thermal and power brake slowdowns), between 0 and 1.
+	ThrottleRatio float64 `json:"throttle_ratio"`
+	// ECCCorrectedErrors is the number of the volatile corrected ECC errors within the window.
+	ECCCorrectedErrors float64 `json:"ecc_corrected_errors"`
+	// ECCUncorrectedErrors is the number of the volatile uncorrected ECC errors within the window.
+	ECCUncorrectedErrors float64 `json:"ecc_uncorrected_errors"`
+	// NVLinkErrors is the number of the NVLink replay, recovery, and CRC errors within the window.
+	NVLinkErrors float64 `json:"nvlink_errors"`
+}
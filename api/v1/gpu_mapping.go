@@ -0,0 +1,16 @@
+package v1
+
+// GPUMapping maps a GPU UUID to its stable index on the host,
+// the same values as the "gpu_uuid" and "gpu_index" labels of the per-GPU metrics.
+type GPUMapping struct {
+	// Index is the GPU index in the order of the PCI bus IDs,
+	// the same order as "nvidia-smi" and "CUDA_DEVICE_ORDER=PCI_BUS_ID".
+	Index int `json:"gpu_index"`
+	// UUID is the GPU UUID.
+	UUID string `json:"gpu_uuid"`
+	// BusID is the PCI bus ID of the GPU.
+	BusID string `json:"bus_id,omitempty"`
+}
+
+// GPUMappings is the list of the GPU mappings, sorted by the index.
+type GPUMappings []GPUMapping
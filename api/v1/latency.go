@@ -0,0 +1,34 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentCheckLatency is the summary of the check durations of a component
+// within a window, estimated from the histogram buckets.
+type ComponentCheckLatency struct {
+	Component string `json:"component"`
+	// Checks is the number of the checks completed within the window.
+	Checks int64           `json:"checks"`
+	P50    metav1.Duration `json:"p50"`
+	P95    metav1.Duration `json:"p95"`
+	P99    metav1.Duration `json:"p99"`
+	Mean   metav1.Duration `json:"mean"`
+}
+
+// ComponentCheckLatencyWindow is the check latencies of the components within a window.
+type ComponentCheckLatencyWindow struct {
+	Window metav1.Duration `json:"window"`
+	Since  metav1.Time     `json:"since"`
+	// Components is the check latencies sorted by the p99 in descending order,
+	// the slowest component first. The components with no check completed
+	// within the window are omitted.
+	Components []ComponentCheckLatency `json:"components"`
+}
+
+// ComponentCheckLatencies is the check latencies of the components
+// within the requested windows.
+type ComponentCheckLatencies struct {
+	Time    metav1.Time                   `json:"time"`
+	Windows []ComponentCheckLatencyWindow `json:"windows"`
+}
@@ -0,0 +1,19 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogEntry is a structured log entry written by the GPUd daemon.
+type LogEntry struct {
+	// Time is when the log entry was written.
+	Time metav1.Time `json:"time"`
+	// Level is the log level (e.g., "info", "warn", "error").
+	Level string `json:"level"`
+	// Message is the log message.
+	Message string `json:"message"`
+	// Caller is the source file and line that wrote the log entry.
+	Caller string `json:"caller,omitempty"`
+	// Fields is the structured key-value pairs of the log entry.
+	Fields map[string]any `json:"fields,omitempty"`
+}
@@ -0,0 +1,40 @@
+package v1
+
+import "time"
+
+// MachineStateType is the machine-level operational state,
+// the fleet-level intent for the machine set by the control plane or the operators.
+type MachineStateType string
+
+const (
+	// MachineStateActive is the machine schedulable for the workloads (default).
+	MachineStateActive MachineStateType = "active"
+	// MachineStateCordoned is the machine not accepting the new workloads,
+	// with the running ones left as is.
+	MachineStateCordoned MachineStateType = "cordoned"
+	// MachineStateDraining is the machine evicting the running workloads.
+	MachineStateDraining MachineStateType = "draining"
+	// MachineStateMaintenance is the machine taken out of the service for the maintenance.
+	MachineStateMaintenance MachineStateType = "maintenance"
+)
+
+// IsValid returns true if the machine state is one of the known states.
+func (s MachineStateType) IsValid() bool {
+	switch s {
+	case MachineStateActive, MachineStateCordoned, MachineStateDraining, MachineStateMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+// MachineState is the machine-level operational state with its provenance.
+type MachineState struct {
+	State MachineStateType `json:"state"`
+	// Reason is the optional description of the state (e.g., "RMA ticket 1234").
+	Reason string `json:"reason,omitempty"`
+	// Source is who set the state (e.g., "control-plane", "local").
+	Source string `json:"source,omitempty"`
+	// UpdatedAt is when the state was set, zero if never set.
+	UpdatedAt time.Time `json:"updated_at"`
+}
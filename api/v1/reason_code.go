@@ -0,0 +1,81 @@
+package v1
+
+import "strings"
+
+// ReasonCode is the stable machine-readable code of a health state reason or an event
+// (e.g., "IB_PORT_RATE_BELOW_EXPECTED"), unlike the free-text reasons that may change
+// between the releases. The codes are upper snake case, and once published they are
+// never renamed or reused for a different meaning.
+type ReasonCode string
+
+const (
+	// ReasonCodeHealthy is the fallback code of the healthy states with no specific code.
+	ReasonCodeHealthy ReasonCode = "HEALTHY"
+	// ReasonCodeUnhealthy is the fallback code of the unhealthy states with no specific code.
+	ReasonCodeUnhealthy ReasonCode = "UNHEALTHY"
+	// ReasonCodeDegraded is the fallback code of the degraded states with no specific code.
+	ReasonCodeDegraded ReasonCode = "DEGRADED"
+	// ReasonCodeInitializing is the fallback code of the initializing states with no specific code.
+	ReasonCodeInitializing ReasonCode = "INITIALIZING"
+
+	// ReasonCodeNVMLNotAvailable is for the NVML library not loaded or the GPU not detected,
+	// where the NVIDIA components skip the checks.
+	ReasonCodeNVMLNotAvailable ReasonCode = "NVML_NOT_AVAILABLE"
+	// ReasonCodeNVMLInitError is for the NVML initialization failures
+	// (e.g., "Unable to determine the device handle for GPU").
+	ReasonCodeNVMLInitError ReasonCode = "NVML_INIT_ERROR"
+	// ReasonCodeGPULost is for the GPU fallen off the bus.
+	ReasonCodeGPULost ReasonCode = "GPU_LOST"
+	// ReasonCodeGPURequiresReset is for the GPU requiring a reset.
+	ReasonCodeGPURequiresReset ReasonCode = "GPU_REQUIRES_RESET"
+)
+
+// ReasonCodeFromHealth returns the fallback code of the health state type.
+func ReasonCodeFromHealth(health HealthStateType) ReasonCode {
+	switch health {
+	case HealthStateTypeUnhealthy:
+		return ReasonCodeUnhealthy
+	case HealthStateTypeDegraded:
+		return ReasonCodeDegraded
+	case HealthStateTypeInitializing:
+		return ReasonCodeInitializing
+	default:
+		return ReasonCodeHealthy
+	}
+}
+
+// ReasonCodeFromEventName returns the fallback code of the event name,
+// the name in upper snake case (e.g., "EVENT_PERSISTENCE_MODE_DISABLED" for "persistence_mode_disabled").
+// The event names are stable, thus the derived codes are stable too.
+func ReasonCodeFromEventName(name string) ReasonCode {
+	if name == "" {
+		return ""
+	}
+	code := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return ReasonCode("EVENT_" + code)
+}
+
+// ReasonCodeMetadata describes a reason code in the registry.
+type ReasonCodeMetadata struct {
+	// Code is the reason code (e.g., "IB_PORT_RATE_BELOW_EXPECTED").
+	Code ReasonCode `json:"code"`
+	// Component is the name of the component that reports the code,
+	// empty for the codes shared by all the components (e.g., "GPU_LOST").
+	Component string `json:"component,omitempty"`
+	// Health is the health state type reported with the code, empty for the event codes.
+	Health HealthStateType `json:"health,omitempty"`
+	// Event is true if the code is of an event, rather than a health state.
+	Event       bool   `json:"event,omitempty"`
+	Description string `json:"description"`
+}
+
+type ReasonCodesMetadata []ReasonCodeMetadata
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RebootHistory is the host reboot history,
+// with the fatal events correlated to the following reboots.
+type RebootHistory struct {
+	// Reboots are the boots of the host, in the descending order of the boot time
+	// (the current boot first).
+	Reboots []Reboot `json:"reboots"`
+	// PendingEvents are the fatal events since the current boot,
+	// which have not been followed by a reboot yet.
+	PendingEvents []Event `json:"pendingEvents,omitempty"`
+}
+
+// Reboot is a host boot, with the fatal events that preceded the reboot.
+type Reboot struct {
+	// BootID is the boot ID of the host.
+	// Empty for the reboots recorded before the boot tracking.
+	BootID string `json:"bootID,omitempty"`
+	// BootTime is when the host booted.
+	BootTime metav1.Time `json:"bootTime"`
+	// Current is true for the current boot.
+	Current bool `json:"current,omitempty"`
+	// LastSeen is when GPUd was last seen running during the boot,
+	// which is the approximate shutdown time for the previous boots.
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// Uptime is the duration from the boot time to the last seen time.
+	Uptime *metav1.Duration `json:"uptime,omitempty"`
+
+	// PrecedingEvents are the fatal events between the previous boot and this boot,
+	// which were followed by this reboot.
+	PrecedingEvents []RebootPrecedingEvent `json:"precedingEvents,omitempty"`
+}
+
+// RebootPrecedingEvent is a fatal event followed by a reboot.
+type RebootPrecedingEvent struct {
+	Event Event `json:"event"`
+	// Resolved is true if the reboot resolved the condition:
+	// the same event has not recurred since the reboot
+	// and the component is not unhealthy (if rebooted to the current boot).
+	Resolved bool `json:"resolved"`
+	// RecurredAt is when the same event first recurred after the reboot.
+	RecurredAt *metav1.Time `json:"recurredAt,omitempty"`
+}
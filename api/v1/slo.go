@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SLOKind is the kind of the latencies an objective tracks.
+type SLOKind string
+
+const (
+	// SLOKindComponentCheck tracks the durations of the component checks.
+	SLOKindComponentCheck SLOKind = "component_check"
+	// SLOKindAPI tracks the latencies of the API requests served by GPUd.
+	SLOKindAPI SLOKind = "api"
+)
+
+// SLOStatus is the attainment of a latency objective over its rolling window.
+type SLOStatus struct {
+	// Name is the name of the objective.
+	Name string `json:"name"`
+	// Kind is the kind of the latencies the objective tracks.
+	Kind SLOKind `json:"kind"`
+	// Match is the component name glob or the API path prefix, empty if all.
+	Match string `json:"match,omitempty"`
+
+	// Threshold is the latency under which an observation is good.
+	Threshold metav1.Duration `json:"threshold"`
+	// Target is the objective ratio of the good observations (e.g., 0.99).
+	Target float64 `json:"target"`
+	// Window is the rolling window of the attainment.
+	Window metav1.Duration `json:"window"`
+
+	// Total is the number of the observations within the window.
+	Total int64 `json:"total"`
+	// Good is the number of the observations under the threshold within the window.
+	Good int64 `json:"good"`
+	// Attainment is the ratio of the good observations, 1 if none observed.
+	Attainment float64 `json:"attainment"`
+	// ErrorBudgetRemaining is the ratio of the error budget (the allowed slow observations)
+	// not spent within the window, negative if overspent.
+	ErrorBudgetRemaining float64 `json:"errorBudgetRemaining"`
+
+	// Violated is true if the attainment is below the target,
+	// with enough observations within the window.
+	Violated bool `json:"violated"`
+	// ViolatedSince is when the objective was last violated, nil if not violated.
+	ViolatedSince *time.Time `json:"violatedSince,omitempty"`
+}
+
+// SLOReport is the report of the latency objectives.
+type SLOReport struct {
+	Time       time.Time   `json:"time"`
+	Objectives []SLOStatus `json:"objectives"`
+	// Events are the recent violations and recoveries of the objectives.
+	Events Events `json:"events,omitempty"`
+}
@@ -0,0 +1,106 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StartupState is the state of the GPUd daemon startup, or a single startup phase.
+type StartupState string
+
+const (
+	// StartupStateStarting is the state while the components are being initialized.
+	// The API only serves the health check and the startup progress.
+	StartupStateStarting StartupState = "starting"
+	// StartupStateReady is the state once all the components are initialized and started.
+	StartupStateReady StartupState = "ready"
+	// StartupStateFailed is the state if the startup failed.
+	StartupStateFailed StartupState = "failed"
+)
+
+// StartupStatus is the progress of the GPUd daemon startup.
+type StartupStatus struct {
+	// State is the overall startup state.
+	State StartupState `json:"state"`
+	// StartTime is when the startup began.
+	StartTime metav1.Time `json:"startTime"`
+	// Elapsed is the time since the startup began, or the total startup time once done.
+	Elapsed metav1.Duration `json:"elapsed"`
+	// Error is the error that failed the startup.
+	Error string `json:"error,omitempty"`
+
+	// Phases is the progress of the startup phases, in the order of execution.
+	Phases []StartupPhase `json:"phases,omitempty"`
+
+	// ComponentsTotal is the number of the components to initialize.
+	ComponentsTotal int `json:"componentsTotal"`
+	// ComponentsInitialized is the number of the components initialized so far.
+	ComponentsInitialized int `json:"componentsInitialized"`
+	// Components is the initialization of each component,
+	// sorted by the initialization time in the descending order
+	// (i.e., the slowest component first).
+	Components []StartupComponent `json:"components,omitempty"`
+
+	// SelfTest is the result of the startup self-test,
+	// nil if not enabled or not run yet.
+	SelfTest *StartupSelfTest `json:"selfTest,omitempty"`
+}
+
+// StartupPhase is the progress of a single startup phase (e.g., NVML initialization).
+type StartupPhase struct {
+	// Name is the phase name (e.g., "nvml", "components").
+	Name string `json:"name"`
+	// State is the phase state.
+	State StartupState `json:"state"`
+	// StartTime is when the phase began.
+	StartTime metav1.Time `json:"startTime"`
+	// Elapsed is the time since the phase began, or the total phase time once done.
+	Elapsed metav1.Duration `json:"elapsed"`
+	// Error is the error that failed the phase.
+	Error string `json:"error,omitempty"`
+}
+
+// StartupComponent is the initialization of a single component.
+type StartupComponent struct {
+	// Name is the component name.
+	Name string `json:"name"`
+	// State is the initialization state of the component.
+	State StartupState `json:"state"`
+	// Elapsed is the time since the initialization began, or the total initialization time once done.
+	Elapsed metav1.Duration `json:"elapsed"`
+	// Error is the error that failed the initialization.
+	Error string `json:"error,omitempty"`
+}
+
+// StartupSelfTestResult is the result of a single startup self-test check.
+type StartupSelfTestResult string
+
+const (
+	// StartupSelfTestPassed is the result of the check that passed.
+	StartupSelfTestPassed StartupSelfTestResult = "passed"
+	// StartupSelfTestFailed is the result of the check that failed.
+	StartupSelfTestFailed StartupSelfTestResult = "failed"
+	// StartupSelfTestSkipped is the result of the check not applicable to the host
+	// (e.g., NVML on the host without the NVIDIA driver).
+	StartupSelfTestSkipped StartupSelfTestResult = "skipped"
+)
+
+// StartupSelfTest is the result of the startup self-test, the quick checks of the
+// core assumptions of the daemon (e.g., the state database writable).
+type StartupSelfTest struct {
+	// Passed is true if no check failed.
+	Passed bool `json:"passed"`
+	// Checks is the result of each check, in the order of execution.
+	Checks []StartupSelfTestCheck `json:"checks"`
+}
+
+// StartupSelfTestCheck is the result of a single startup self-test check.
+type StartupSelfTestCheck struct {
+	// Name is the check name (e.g., "db-writable").
+	Name string `json:"name"`
+	// Result is the check result.
+	Result StartupSelfTestResult `json:"result"`
+	// Elapsed is the time taken by the check.
+	Elapsed metav1.Duration `json:"elapsed"`
+	// Message is the error of the failed check, or the reason of the skipped check.
+	Message string `json:"message,omitempty"`
+}
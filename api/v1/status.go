@@ -0,0 +1,97 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GPUdStatus is the aggregated status of the GPUd daemon and the machine health.
+type GPUdStatus struct {
+	// GPUdVersion is the version of the running GPUd daemon.
+	GPUdVersion string `json:"gpudVersion,omitempty"`
+	// MachineID is the machine ID assigned by the control plane (or the machine UUID).
+	MachineID string `json:"machineID,omitempty"`
+
+	// StartTime is when the GPUd daemon started.
+	StartTime metav1.Time `json:"startTime"`
+	// Uptime is the duration since the GPUd daemon started.
+	Uptime metav1.Duration `json:"uptime"`
+
+	// Session is the latest control plane session state.
+	// Nil if no session activity was recorded.
+	Session *SessionStatus `json:"session,omitempty"`
+
+	// Components is the number of components by their health state type.
+	Components ComponentHealthSummary `json:"components"`
+
+	// LastFatalEvent is the most recent fatal event across all components.
+	// Nil if no fatal event was found within the lookback window.
+	LastFatalEvent *Event `json:"lastFatalEvent,omitempty"`
+
+	// DBSizeBytes is the size of the GPUd state database in bytes.
+	DBSizeBytes uint64 `json:"dbSizeBytes"`
+
+	// MetricsIngestRatePerMinute is the number of metric data points
+	// recorded per minute, averaged over the recent window.
+	MetricsIngestRatePerMinute float64 `json:"metricsIngestRatePerMinute"`
+}
+
+// SessionStatus is the latest control plane session state.
+type SessionStatus struct {
+	// Time is when the session state was last recorded.
+	Time metav1.Time `json:"time"`
+	// Success is true if the last session activity succeeded.
+	Success bool `json:"success"`
+	// Message is the detailed message of the last session activity (e.g., failure reason).
+	Message string `json:"message,omitempty"`
+}
+
+// ComponentHealthSummary is the number of components by their health state type.
+type ComponentHealthSummary struct {
+	Total int `json:"total"`
+	// ByHealth is the number of components by the worst health state type
+	// of each component (e.g., {"Healthy": 30, "Degraded": 1}).
+	ByHealth map[HealthStateType]int `json:"byHealth,omitempty"`
+	// Unhealthy lists the components that are not healthy
+	// (neither "Healthy" nor "Initializing"), excluding the ones under maintenance or muted.
+	Unhealthy []string `json:"unhealthy,omitempty"`
+	// InMaintenance lists the components under a scheduled maintenance window.
+	InMaintenance []string `json:"inMaintenance,omitempty"`
+	// Muted lists the components that are not healthy but muted until the mute expires.
+	Muted []string `json:"muted,omitempty"`
+	// Stale lists the components reporting the health states from before
+	// the restart, not yet checked since.
+	Stale []string `json:"stale,omitempty"`
+}
+
+// HealthVerdict is the overall health verdict of the machine.
+type HealthVerdict string
+
+const (
+	// HealthVerdictHealthy means no component is degraded or unhealthy.
+	HealthVerdictHealthy HealthVerdict = "healthy"
+	// HealthVerdictDegraded means at least one component is degraded, but none is unhealthy.
+	HealthVerdictDegraded HealthVerdict = "degraded"
+	// HealthVerdictFatal means at least one component is unhealthy.
+	HealthVerdictFatal HealthVerdict = "fatal"
+)
+
+// HealthSummary is the compact overall health of the machine,
+// for the pollers that do not need the full health states.
+type HealthSummary struct {
+	// Verdict is the overall verdict across all components,
+	// excluding the ones under maintenance.
+	Verdict HealthVerdict `json:"verdict"`
+	// Counts is the number of components by the worst health state type
+	// of each component (e.g., {"Healthy": 30, "Degraded": 1}).
+	Counts map[HealthStateType]int `json:"counts"`
+	// TopReasons are the reasons of the worst components,
+	// ordered by the severity and then the component name.
+	TopReasons []HealthSummaryReason `json:"topReasons,omitempty"`
+}
+
+// HealthSummaryReason is why a component is not healthy.
+type HealthSummaryReason struct {
+	Component string          `json:"component"`
+	Health    HealthStateType `json:"health"`
+	Reason    string          `json:"reason,omitempty"`
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HealthTransition is a change of the health of a component health state.
+type HealthTransition struct {
+	// Time is when the new health was checked.
+	Time metav1.Time `json:"time"`
+	// Component is the name of the component.
+	Component string `json:"component"`
+	// Name is the name of the health state.
+	Name string `json:"name,omitempty"`
+	// From is the health before the transition.
+	From HealthStateType `json:"from"`
+	// To is the health after the transition.
+	To HealthStateType `json:"to"`
+	// Reason is the reason of the new health.
+	Reason string `json:"reason,omitempty"`
+}
+
+// GPUTimelineEntryKind is the kind of the GPU timeline entry.
+type GPUTimelineEntryKind string
+
+const (
+	// GPUTimelineEntryKindXid is an Xid event of the GPU.
+	GPUTimelineEntryKindXid GPUTimelineEntryKind = "xid"
+	// GPUTimelineEntryKindECC is an ECC or row remapping event of the GPU.
+	GPUTimelineEntryKindECC GPUTimelineEntryKind = "ecc"
+	// GPUTimelineEntryKindThermal is a thermal or hardware slowdown event of the GPU.
+	GPUTimelineEntryKindThermal GPUTimelineEntryKind = "thermal"
+	// GPUTimelineEntryKindReboot is a host reboot, which also resets the GPU.
+	GPUTimelineEntryKindReboot GPUTimelineEntryKind = "reboot"
+	// GPUTimelineEntryKindHealth is a health transition of a GPU component.
+	GPUTimelineEntryKindHealth GPUTimelineEntryKind = "health"
+	// GPUTimelineEntryKindEvent is any other event of the GPU.
+	GPUTimelineEntryKindEvent GPUTimelineEntryKind = "event"
+)
+
+// GPUTimeline is the history of a single GPU.
+type GPUTimeline struct {
+	// UUID is the GPU UUID.
+	UUID string `json:"uuid"`
+	// Since is the start time of the timeline.
+	Since metav1.Time `json:"since"`
+	// Entries are in the ascending order of the time (oldest first).
+	Entries []GPUTimelineEntry `json:"entries"`
+}
+
+// GPUTimelineEntry is an event, a reboot, or a health transition in the GPU timeline.
+type GPUTimelineEntry struct {
+	Time metav1.Time          `json:"time"`
+	Kind GPUTimelineEntryKind `json:"kind"`
+	// Component is the name of the component reporting the entry (empty for the reboots).
+	Component string `json:"component,omitempty"`
+	// Name is the event name, or the health state name of the health transitions.
+	Name string `json:"name,omitempty"`
+	// Type is the event type (e.g., "Fatal"), empty for the health transitions.
+	Type EventType `json:"type,omitempty"`
+	// Message is the event message, or the reason of the health transitions.
+	Message string `json:"message,omitempty"`
+	// Transition is set for the health transitions.
+	Transition *HealthTransition `json:"transition,omitempty"`
+	// ExtraInfo is the extra information of the event.
+	ExtraInfo map[string]string `json:"extra_info,omitempty"`
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentCheckResult is the result of a component check triggered in a batch.
+type ComponentCheckResult struct {
+	Component string `json:"component"`
+	// States is the health states of the triggered check,
+	// or the last health states if the check timed out.
+	States HealthStates `json:"states"`
+	// TimedOut is true if the check did not complete within the batch timeout.
+	// The check keeps running, and its result is reported by the later health states.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Elapsed is how long the check took, or the batch timeout if timed out.
+	Elapsed metav1.Duration `json:"elapsed"`
+}
+
+// ComponentCheckResults is the results of the component checks triggered in a batch,
+// keyed by the component name.
+type ComponentCheckResults map[string]ComponentCheckResult
@@ -75,6 +75,9 @@ type HealthState struct {
 
 	// Reason represents what happened or detected by GPUd if it isn’t healthy.
 	Reason string `json:"reason,omitempty"`
+	// ReasonCode is the stable machine-readable code of the reason,
+	// listed in the reason code registry ("/v1/reason-codes").
+	ReasonCode ReasonCode `json:"reason_code,omitempty"`
 
 	// Error represents the detailed error information, which will be shown
 	// as More Information to help analyze why it isn’t healthy.
@@ -120,6 +123,19 @@ type Event struct {
 
 	// Message represents the detailed message of the event.
 	Message string `json:"message,omitempty"`
+
+	// ReasonCode is the stable machine-readable code of the event,
+	// listed in the reason code registry ("/v1/reason-codes").
+	ReasonCode ReasonCode `json:"reason_code,omitempty"`
+
+	// ExtraInfo represents the extra information of the event
+	// (e.g., "maintenance" if the event happened during a maintenance window).
+	ExtraInfo map[string]string `json:"extra_info,omitempty"`
+
+	// DedupKey is the stable deduplication key of the event,
+	// the same across the retries and the GPUd restarts,
+	// so that the same incident is only counted once.
+	DedupKey string `json:"dedup_key,omitempty"`
 }
 
 type Events []Event
@@ -138,6 +154,10 @@ type Metric struct {
 	Name        string            `json:"name"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Value       float64           `json:"value"`
+
+	// Unit is the unit of the metric value (e.g., "bytes", "percent"),
+	// empty if the metric has no registered metadata.
+	Unit MetricUnit `json:"unit,omitempty"`
 }
 
 type Metrics []Metric
@@ -149,6 +169,51 @@ type ComponentMetrics struct {
 
 type GPUdComponentMetrics []ComponentMetrics
 
+// MetricType is the type of the metric.
+type MetricType string
+
+const (
+	// MetricTypeGauge is the metric whose value can go up and down.
+	MetricTypeGauge MetricType = "gauge"
+	// MetricTypeCounter is the metric whose value only goes up (e.g., resets on restart).
+	MetricTypeCounter MetricType = "counter"
+)
+
+// MetricUnit is the unit of the metric value.
+type MetricUnit string
+
+const (
+	MetricUnitBytes          MetricUnit = "bytes"
+	MetricUnitBytesPerSecond MetricUnit = "bytes_per_second"
+	MetricUnitPercent        MetricUnit = "percent"
+	MetricUnitCelsius        MetricUnit = "celsius"
+	MetricUnitMilliwatts     MetricUnit = "milliwatts"
+	MetricUnitVolts          MetricUnit = "volts"
+	MetricUnitMegahertz      MetricUnit = "megahertz"
+	MetricUnitSeconds        MetricUnit = "seconds"
+	MetricUnitMilliseconds   MetricUnit = "milliseconds"
+	// MetricUnitCount is the unitless number of things (e.g., errors, processes).
+	MetricUnitCount MetricUnit = "count"
+	// MetricUnitBoolean is 1 for true and 0 for false.
+	MetricUnitBoolean MetricUnit = "boolean"
+	// MetricUnitRatio is the unitless ratio (e.g., the load average).
+	MetricUnitRatio MetricUnit = "ratio"
+)
+
+// MetricMetadata describes a metric, so that the consumers
+// can label and convert the values without guessing the unit from the name.
+type MetricMetadata struct {
+	// Name is the metric name (e.g., "accelerator_nvidia_memory_used_bytes").
+	Name string `json:"name"`
+	// Component is the name of the component that reports the metric.
+	Component   string     `json:"component"`
+	Type        MetricType `json:"type"`
+	Unit        MetricUnit `json:"unit"`
+	Description string     `json:"description,omitempty"`
+}
+
+type MetricsMetadata []MetricMetadata
+
 type Info struct {
 	States  HealthStates `json:"states"`
 	Events  Events       `json:"events"`
@@ -285,6 +350,9 @@ type MachineInfo struct {
 	Hostname string `json:"hostname,omitempty"`
 	// Uptime represents when the machine up
 	Uptime metav1.Time `json:"uptime,omitempty"`
+	// AppliedConfigVersion is the version of the last signed config
+	// pushed by the control plane and applied by GPUd, empty if none.
+	AppliedConfigVersion string `json:"appliedConfigVersion,omitempty"`
 
 	// CPUInfo is the CPU info of the machine.
 	CPUInfo *MachineCPUInfo `json:"cpuInfo,omitempty"`
@@ -305,6 +373,9 @@ func (i *MachineInfo) RenderTable(wr io.Writer) {
 	table.Append([]string{"Container Runtime Version", i.ContainerRuntimeVersion})
 	table.Append([]string{"OS Image", i.OSImage})
 	table.Append([]string{"Kernel Version", i.KernelVersion})
+	if i.AppliedConfigVersion != "" {
+		table.Append([]string{"Applied Config Version", i.AppliedConfigVersion})
+	}
 
 	if i.CPUInfo != nil {
 		table.Append([]string{"CPU Type", i.CPUInfo.Type})
@@ -0,0 +1,76 @@
+// Package v2 defines the v2 API response types.
+// The v2 API serves the same endpoints as the v1 API,
+// with the v1 response wrapped in the Envelope.
+package v2
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Version is the API version of the envelope.
+	Version = "v2"
+
+	// MediaType is the media type to request the v2 response
+	// via the "Accept" header, for the endpoints without the "/v2" path prefix.
+	MediaType = "application/vnd.gpud.v2+json"
+
+	// ResponseHeaderAPIVersion is the response header set to the API version
+	// of the response body.
+	ResponseHeaderAPIVersion = "GPUd-API-Version"
+)
+
+// Envelope wraps every v2 API response.
+type Envelope struct {
+	// APIVersion is the version of the envelope (e.g., "v2").
+	APIVersion string `json:"apiVersion"`
+	// RequestID is the unique ID of the request,
+	// same as the "X-Request-ID" response header.
+	RequestID string `json:"requestID,omitempty"`
+	// Node identifies the machine that served the request.
+	Node Node `json:"node"`
+	// GPUdVersion is the version of the running GPUd daemon.
+	GPUdVersion string `json:"gpudVersion,omitempty"`
+
+	// GeneratedAt is when the response was generated.
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// DataTimes is the time range of the returned data
+	// (e.g., when the health states were last checked).
+	// Nil if the endpoint does not return the timestamped data.
+	DataTimes *DataTimes `json:"dataTimes,omitempty"`
+
+	// Data is the v1 response body.
+	// Empty if the request failed.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Error is set if the request failed.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Node is the identity of the machine.
+type Node struct {
+	// MachineID is the machine ID assigned by the control plane (or the machine UUID).
+	MachineID string `json:"machineID,omitempty"`
+	// Hostname is the hostname of the machine.
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// DataTimes is the time range of the returned data.
+type DataTimes struct {
+	// Oldest is the timestamp of the oldest data,
+	// useful to detect the stale data.
+	Oldest metav1.Time `json:"oldest"`
+	// Newest is the timestamp of the most recent data.
+	Newest metav1.Time `json:"newest"`
+}
+
+// Error is the error of the failed request.
+type Error struct {
+	// Code is the HTTP status code of the response.
+	Code int `json:"code"`
+	// Message is the error message.
+	Message string `json:"message,omitempty"`
+	// Details is the original v1 error response body, if any.
+	Details json.RawMessage `json:"details,omitempty"`
+}
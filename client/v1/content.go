@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/klauspost/compress/zstd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// setRequestHeaders sets the content negotiation headers of the request.
+// The server responds in the format of the "Content-Type" header,
+// and the "Accept" header is set to the same format for the intermediaries.
+func (op *Op) setRequestHeaders(req *http.Request) {
+	if op.requestContentType != "" {
+		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
+		req.Header.Set("Accept", op.requestContentType)
+	}
+	if op.requestAcceptEncoding != "" {
+		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	}
+}
+
+// responseOpts returns the options to decode the response body
+// in the JSON or YAML format and the encoding the server responded with,
+// which may differ from the requested ones (e.g., the error responses are always JSON).
+func responseOpts(resp *http.Response, opts []OpOption) []OpOption {
+	opts = opts[:len(opts):len(opts)]
+
+	// the server that supports zstd sets the "Content-Encoding" header for the compressed responses,
+	// while the older servers without zstd respond uncompressed for the zstd requests
+	// (the gzip requests are handled as before, for the servers that omit the header)
+	requested := &Op{}
+	_ = requested.applyOpts(opts)
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		opts = append(opts, withContentEncoding(enc))
+	} else if requested.requestAcceptEncoding == httputil.RequestHeaderEncodingZstd {
+		opts = append(opts, withContentEncoding(""))
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get(httputil.RequestHeaderContentType))
+	if err != nil {
+		return opts
+	}
+	switch mediaType {
+	case httputil.RequestHeaderJSON:
+		return append(opts, WithRequestContentTypeJSON())
+	case httputil.RequestHeaderYAML:
+		return append(opts, WithRequestContentTypeYAML())
+	default:
+		return opts
+	}
+}
+
+// decodeBody decodes the optionally gzip or zstd compressed body into v in the requested format.
+// The unknown fields are ignored, so the clients keep working
+// with the newer servers that add fields to the apiv1 types.
+func (op *Op) decodeBody(rd io.Reader, v any) error {
+	switch op.requestAcceptEncoding {
+	case httputil.RequestHeaderEncodingGzip:
+		gr, err := gzip.NewReader(rd)
+		if err != nil {
+			return fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer func() {
+			_ = gr.Close()
+		}()
+		rd = gr
+
+	case httputil.RequestHeaderEncodingZstd:
+		zr, err := zstd.NewReader(rd, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		defer zr.Close()
+		rd = zr
+	}
+
+	switch op.requestContentType {
+	case httputil.RequestHeaderJSON, "":
+		if err := json.NewDecoder(rd).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode json: %w", err)
+		}
+	case httputil.RequestHeaderYAML:
+		b, err := io.ReadAll(rd)
+		if err != nil {
+			return fmt.Errorf("failed to read yaml: %w", err)
+		}
+		if err := yaml.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("failed to unmarshal yaml: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported content type: %s", op.requestContentType)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func TestSetRequestHeaders(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	require.NoError(t, err)
+
+	op := &Op{}
+	require.NoError(t, op.applyOpts([]OpOption{WithRequestContentTypeYAML(), WithAcceptEncodingGzip()}))
+	op.setRequestHeaders(req)
+	assert.Equal(t, httputil.RequestHeaderYAML, req.Header.Get(httputil.RequestHeaderContentType))
+	assert.Equal(t, httputil.RequestHeaderYAML, req.Header.Get("Accept"))
+	assert.Equal(t, httputil.RequestHeaderEncodingGzip, req.Header.Get(httputil.RequestHeaderAcceptEncoding))
+}
+
+func TestResponseContentNegotiation(t *testing.T) {
+	// the server ignores the requested YAML and responds in JSON
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, httputil.RequestHeaderYAML, r.Header.Get("Accept"))
+		w.Header().Set(httputil.RequestHeaderContentType, "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`["comp1","comp2"]`))
+	}))
+	defer srv.Close()
+
+	components, err := GetComponents(context.Background(), srv.URL, WithRequestContentTypeYAML())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comp1", "comp2"}, components)
+}
+
+func TestDecodeBodyUnknownFields(t *testing.T) {
+	jsonBody := `[{"component":"disk","futureField":true,"states":[{"name":"disk","health":"Healthy","futureNested":{"a":1}}]}]`
+	states, err := ReadHealthStates(strings.NewReader(jsonBody), WithRequestContentTypeJSON())
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "disk", states[0].Component)
+	require.Len(t, states[0].States, 1)
+	assert.Equal(t, "disk", states[0].States[0].Name)
+
+	yamlBody := `
+- component: disk
+  futureField: true
+  states:
+  - name: disk
+    health: Healthy
+`
+	states, err = ReadHealthStates(strings.NewReader(yamlBody), WithRequestContentTypeYAML())
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "disk", states[0].Component)
+}
+
+func TestResponseContentEncoding(t *testing.T) {
+	compress := func(t *testing.T, encoding string, b []byte) []byte {
+		switch encoding {
+		case httputil.RequestHeaderEncodingGzip:
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			_, err := gw.Write(b)
+			require.NoError(t, err)
+			require.NoError(t, gw.Close())
+			return buf.Bytes()
+		case httputil.RequestHeaderEncodingZstd:
+			enc, err := zstd.NewWriter(nil)
+			require.NoError(t, err)
+			defer func() {
+				_ = enc.Close()
+			}()
+			return enc.EncodeAll(b, nil)
+		default:
+			return b
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []OpOption
+		accept   string
+		encoding string
+	}{
+		{name: "zstd", opts: []OpOption{WithAcceptEncodingZstd()}, accept: "zstd", encoding: "zstd"},
+		{name: "gzip", opts: []OpOption{WithAcceptEncodingGzip()}, accept: "gzip", encoding: "gzip"},
+		// the server may respond uncompressed regardless of the request
+		{name: "zstd requested, uncompressed", opts: []OpOption{WithAcceptEncodingZstd()}, accept: "zstd"},
+		// decompressed by the transport
+		{name: "not requested", accept: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.accept, r.Header.Get(httputil.RequestHeaderAcceptEncoding))
+				w.Header().Set(httputil.RequestHeaderContentType, httputil.RequestHeaderJSON)
+				encoding := tt.encoding
+				if tt.opts == nil {
+					encoding = httputil.RequestHeaderEncodingGzip
+				}
+				if encoding != "" {
+					w.Header().Set("Content-Encoding", encoding)
+				}
+				_, _ = w.Write(compress(t, encoding, []byte(`["comp1","comp2"]`)))
+			}))
+			defer srv.Close()
+
+			components, err := GetComponents(context.Background(), srv.URL, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"comp1", "comp2"}, components)
+		})
+	}
+
+	// the raw compressed body read with the request option
+	components, err := ReadComponents(bytes.NewReader(compress(t, "zstd", []byte(`["comp1"]`))), WithAcceptEncodingZstd())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comp1"}, components)
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+var (
+	// ErrUnauthorized is matched by the errors of the 401 and 403 responses.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrServerError is matched by the errors of the 5xx responses.
+	ErrServerError = errors.New("server error")
+)
+
+// maxErrorBodySize is the maximum number of the response body bytes kept in the error.
+const maxErrorBodySize = 4096
+
+var _ error = &Error{}
+
+// Error is the error of an unexpected response status from the GPUd server.
+// Use errors.As to inspect the status code and the response body,
+// or errors.Is with errdefs.ErrNotFound, ErrUnauthorized, ErrServerError
+// (or errdefs.ErrUnavailable for 503) to handle the error.
+type Error struct {
+	// StatusCode is the response status code.
+	StatusCode int
+	// Body is the response body, truncated to 4 KiB.
+	Body string
+
+	msg string
+}
+
+func (e *Error) Error() string {
+	msg := e.msg
+	if msg == "" {
+		msg = fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+	if e.Body == "" {
+		return msg
+	}
+	return msg + ": " + e.Body
+}
+
+// Is returns true if the target is the sentinel error of the status code.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case errdefs.ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case errdefs.ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// IsNotFound returns true if the error is of a 404 response.
+func IsNotFound(err error) bool {
+	return errors.Is(err, errdefs.ErrNotFound)
+}
+
+// IsUnauthorized returns true if the error is of a 401 or 403 response.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsServerError returns true if the error is of a 5xx response.
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServerError)
+}
+
+// newStatusError reads the response body into the error of the unexpected response status.
+// The message defaults to "unexpected status code <code>" if empty.
+func newStatusError(resp *http.Response, msg string) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return &Error{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(b)),
+		msg:        msg,
+	}
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		notFound     bool
+		unauthorized bool
+		serverError  bool
+		unavailable  bool
+	}{
+		{name: "not found", statusCode: http.StatusNotFound, notFound: true},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, unauthorized: true},
+		{name: "forbidden", statusCode: http.StatusForbidden, unauthorized: true},
+		{name: "internal server error", statusCode: http.StatusInternalServerError, serverError: true},
+		{name: "service unavailable", statusCode: http.StatusServiceUnavailable, serverError: true, unavailable: true},
+		{name: "bad request", statusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(`{"code":1,"message":"failed"}` + "\n"))
+			}))
+			defer srv.Close()
+
+			_, err := GetEvents(context.Background(), srv.URL)
+			require.Error(t, err)
+
+			var statusErr *Error
+			require.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, tt.statusCode, statusErr.StatusCode)
+			assert.Equal(t, `{"code":1,"message":"failed"}`, statusErr.Body)
+			assert.Contains(t, err.Error(), "server not ready, response not 200")
+
+			assert.Equal(t, tt.notFound, IsNotFound(err))
+			assert.Equal(t, tt.notFound, errdefs.IsNotFound(err))
+			assert.Equal(t, tt.unauthorized, IsUnauthorized(err))
+			assert.Equal(t, tt.serverError, IsServerError(err))
+			assert.Equal(t, tt.unavailable, errdefs.IsUnavailable(err))
+		})
+	}
+}
+
+func TestStatusErrorDefaultMessage(t *testing.T) {
+	err := &Error{StatusCode: http.StatusTeapot}
+	assert.Equal(t, "unexpected status code 418", err.Error())
+}
+
+func TestStatusErrorBodyTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(strings.Repeat("x", 2*maxErrorBodySize)))
+	}))
+	defer srv.Close()
+
+	_, err := GetMetrics(context.Background(), srv.URL)
+	var statusErr *Error
+	require.True(t, errors.As(err, &statusErr))
+	assert.Len(t, statusErr.Body, maxErrorBodySize)
+}
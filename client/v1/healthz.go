@@ -44,7 +44,7 @@ func checkHealthz(cli *http.Client, req *http.Request, exp []byte) error {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server not ready, response not 200")
+		return newStatusError(resp, "server not ready, response not 200")
 	}
 
 	b, err := io.ReadAll(resp.Body)
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetComponentsLatency returns the p50/p95/p99 check latencies of the components
+// (all components if no WithComponent option is given) over the windows
+// set by the WithLatencyWindow options, the slowest component first.
+func GetComponentsLatency(ctx context.Context, addr string, opts ...OpOption) (*apiv1.ComponentCheckLatencies, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathComponentsLatency))
+	if err != nil {
+		return nil, err
+	}
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for c := range op.components {
+			components = append(components, c)
+		}
+		sort.Strings(components)
+		q.Set("components", strings.Join(components, ","))
+	}
+	if len(op.latencyWindows) > 0 {
+		windows := make([]string, 0, len(op.latencyWindows))
+		for _, w := range op.latencyWindows {
+			windows = append(windows, w.String())
+		}
+		q.Set("windows", strings.Join(windows, ","))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "")
+	}
+
+	var latencies apiv1.ComponentCheckLatencies
+	if err := json.NewDecoder(resp.Body).Decode(&latencies); err != nil {
+		return nil, fmt.Errorf("failed to decode check latencies: %w", err)
+	}
+	return &latencies, nil
+}
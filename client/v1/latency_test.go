@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetComponentsLatency(t *testing.T) {
+	var gotComponents, gotWindows string
+	statusCode := http.StatusOK
+	body := `{"time":"2025-01-02T00:00:00Z","windows":[{"window":"1h0m0s","since":"2025-01-01T23:00:00Z","components":[{"component":"cpu","checks":60,"p50":"50ms","p95":"95ms","p99":"99ms","mean":"40ms"}]}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/components/latency", r.URL.Path)
+		gotComponents = r.URL.Query().Get("components")
+		gotWindows = r.URL.Query().Get("windows")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	latencies, err := GetComponentsLatency(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Empty(t, gotComponents)
+	assert.Empty(t, gotWindows)
+	require.Len(t, latencies.Windows, 1)
+	assert.Equal(t, time.Hour, latencies.Windows[0].Window.Duration)
+	require.Len(t, latencies.Windows[0].Components, 1)
+	assert.Equal(t, "cpu", latencies.Windows[0].Components[0].Component)
+	assert.Equal(t, int64(60), latencies.Windows[0].Components[0].Checks)
+	assert.Equal(t, 99*time.Millisecond, latencies.Windows[0].Components[0].P99.Duration)
+
+	_, err = GetComponentsLatency(context.Background(), srv.URL, WithComponent("memory"), WithComponent("cpu"), WithLatencyWindow(5*time.Minute), WithLatencyWindow(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "cpu,memory", gotComponents)
+	assert.Equal(t, "5m0s,1h0m0s", gotWindows)
+
+	statusCode = http.StatusBadRequest
+	_, err = GetComponentsLatency(context.Background(), srv.URL, WithLatencyWindow(-time.Hour))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 400")
+
+	statusCode = http.StatusOK
+	body = `{"windows":`
+	_, err = GetComponentsLatency(context.Background(), srv.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode check latencies")
+}
@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetLogs returns the recent log entries of the GPUd daemon.
+// Use WithComponent to filter by the component.
+func GetLogs(ctx context.Context, addr string, opts ...OpOption) ([]apiv1.LogEntry, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := newLogsTailRequest(ctx, addr, op, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "")
+	}
+
+	var entries []apiv1.LogEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode log entries: %w", err)
+	}
+	return entries, nil
+}
+
+// FollowLogs streams the recent and the new log entries of the GPUd daemon
+// to the handler, until the context is canceled or the server closes the stream.
+// Use WithComponent to filter by the component.
+func FollowLogs(ctx context.Context, addr string, handler func(apiv1.LogEntry), opts ...OpOption) error {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return err
+	}
+
+	req, err := newLogsTailRequest(ctx, addr, op, true)
+	if err != nil {
+		return err
+	}
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp, "")
+	}
+
+	return readLogEvents(ctx, bufio.NewScanner(resp.Body), handler)
+}
+
+func newLogsTailRequest(ctx context.Context, addr string, op *Op, follow bool) (*http.Request, error) {
+	q := url.Values{}
+	for component := range op.components {
+		q.Set("component", component)
+		break
+	}
+	if op.logLevel != "" {
+		q.Set("level", op.logLevel)
+	}
+	if op.logLines != nil {
+		q.Set("lines", strconv.Itoa(*op.logLines))
+	}
+	if follow {
+		q.Set("follow", "true")
+	}
+
+	reqURL := fmt.Sprintf("%s/v1%s", addr, server.URLPathLogsTail)
+	if len(q) > 0 {
+		reqURL += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if follow {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	return req, nil
+}
+
+// readLogEvents reads the server-sent "log" events.
+func readLogEvents(ctx context.Context, scanner *bufio.Scanner, handler func(apiv1.LogEntry)) error {
+	// the long log entries (e.g., with the stack traces) exceed the default 64 KiB
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			event = ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if event != "" && event != "log" {
+				continue
+			}
+			var entry apiv1.LogEntry
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &entry); err != nil {
+				return fmt.Errorf("failed to decode log entry: %w", err)
+			}
+			handler(entry)
+		}
+	}
+
+	// reading the body fails once the context is canceled (e.g., interrupted)
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
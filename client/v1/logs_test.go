@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetLogs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs/tail", r.URL.Path)
+		assert.Equal(t, "accelerator-nvidia-xid", r.URL.Query().Get("component"))
+		assert.Equal(t, "warn", r.URL.Query().Get("level"))
+		assert.Equal(t, "20", r.URL.Query().Get("lines"))
+		assert.Empty(t, r.URL.Query().Get("follow"))
+		_, _ = w.Write([]byte(`[{"time":"2025-01-01T00:00:00Z","level":"warn","message":"xid found","fields":{"xid":79}}]`))
+	}))
+	defer srv.Close()
+
+	entries, err := GetLogs(context.Background(), srv.URL, WithComponent("accelerator-nvidia-xid"), WithLogLevel("warn"), WithLogLines(20))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "xid found", entries[0].Message)
+	assert.Equal(t, float64(79), entries[0].Fields["xid"])
+}
+
+func TestGetLogsErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		errorContains string
+	}{
+		{name: "not found", statusCode: http.StatusNotFound, errorContains: "unexpected status code 404"},
+		{name: "malformed JSON", statusCode: http.StatusOK, body: `[{"message":`, errorContains: "failed to decode log entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := GetLogs(context.Background(), srv.URL)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+		})
+	}
+}
+
+func TestFollowLogs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("follow"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event:log\ndata:{\"level\":\"info\",\"message\":\"first\"}\n\n" +
+			"event:other\ndata:{\"message\":\"ignored\"}\n\n" +
+			"event:log\ndata:{\"level\":\"error\",\"message\":\"second\"}\n\n"))
+	}))
+	defer srv.Close()
+
+	var msgs []string
+	err := FollowLogs(context.Background(), srv.URL, func(e apiv1.LogEntry) {
+		msgs = append(msgs, e.Message)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, msgs)
+}
+
+func TestFollowLogsMalformed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("event:log\ndata:{\n\n"))
+	}))
+	defer srv.Close()
+
+	err := FollowLogs(context.Background(), srv.URL, func(apiv1.LogEntry) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode log entry")
+}
@@ -34,7 +34,7 @@ func getMachineInfo(cli *http.Client, req *http.Request) (*apiv1.MachineInfo, er
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
 	var info apiv1.MachineInfo
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetMetricsMetadata returns the unit, type, and description of the metrics
+// reported by the components (all components if no WithComponent option is given).
+// The metrics returned by GetMetrics and GetInfo carry the same unit.
+func GetMetricsMetadata(ctx context.Context, addr string, opts ...OpOption) (apiv1.MetricsMetadata, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathMetricsMetadata))
+	if err != nil {
+		return nil, err
+	}
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for c := range op.components {
+			components = append(components, c)
+		}
+		sort.Strings(components)
+
+		q := reqURL.Query()
+		q.Set("components", strings.Join(components, ","))
+		reqURL.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "")
+	}
+
+	var metadata apiv1.MetricsMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metric metadata: %w", err)
+	}
+	return metadata, nil
+}
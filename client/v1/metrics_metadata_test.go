@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetMetricsMetadata(t *testing.T) {
+	var gotQuery string
+	statusCode := http.StatusOK
+	body := `[{"name":"memory_used_bytes","component":"memory","type":"gauge","unit":"bytes","description":"tracks the used memory in bytes"}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics/metadata", r.URL.Path)
+		gotQuery = r.URL.Query().Get("components")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	md, err := GetMetricsMetadata(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Empty(t, gotQuery)
+	assert.Equal(t, apiv1.MetricsMetadata{{
+		Name:        "memory_used_bytes",
+		Component:   "memory",
+		Type:        apiv1.MetricTypeGauge,
+		Unit:        apiv1.MetricUnitBytes,
+		Description: "tracks the used memory in bytes",
+	}}, md)
+
+	_, err = GetMetricsMetadata(context.Background(), srv.URL, WithComponent("memory"), WithComponent("cpu"))
+	require.NoError(t, err)
+	assert.Equal(t, "cpu,memory", gotQuery)
+
+	statusCode = http.StatusNotFound
+	_, err = GetMetricsMetadata(context.Background(), srv.URL, WithComponent("unknown"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 404")
+
+	statusCode = http.StatusOK
+	body = `[{"name":`
+	_, err = GetMetricsMetadata(context.Background(), srv.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode metric metadata")
+}
@@ -1,12 +1,24 @@
 // Package v1 provides the gpud v1 client for the server.
 package v1
 
-import "github.com/leptonai/gpud/pkg/httputil"
+import (
+	"time"
+
+	"github.com/leptonai/gpud/pkg/httputil"
+)
 
 type Op struct {
 	requestContentType    string
 	requestAcceptEncoding string
 	components            map[string]any
+	tags                  map[string]any
+	checkTimeout          time.Duration
+	latencyWindows        []time.Duration
+
+	logLevel string
+	logLines *int
+
+	startTime time.Time
 }
 
 type OpOption func(*Op)
@@ -40,6 +52,22 @@ func WithAcceptEncodingGzip() OpOption {
 	}
 }
 
+// WithAcceptEncodingZstd requests zstd encoding for the response,
+// smaller and faster to decode than gzip for the large responses (e.g., "/v1/info").
+func WithAcceptEncodingZstd() OpOption {
+	return func(op *Op) {
+		op.requestAcceptEncoding = httputil.RequestHeaderEncodingZstd
+	}
+}
+
+// withContentEncoding sets the encoding to decode the response body,
+// as the server responded with in the "Content-Encoding" header.
+func withContentEncoding(enc string) OpOption {
+	return func(op *Op) {
+		op.requestAcceptEncoding = enc
+	}
+}
+
 func WithComponent(component string) OpOption {
 	return func(op *Op) {
 		if op.components == nil {
@@ -48,3 +76,50 @@ func WithComponent(component string) OpOption {
 		op.components[component] = nil
 	}
 }
+
+// WithTag selects the components with the tag to trigger (see TriggerChecks).
+func WithTag(tag string) OpOption {
+	return func(op *Op) {
+		if op.tags == nil {
+			op.tags = make(map[string]any)
+		}
+		op.tags[tag] = nil
+	}
+}
+
+// WithCheckTimeout sets the timeout of the checks triggered in a batch
+// (defaults to the server default of 1 minute).
+func WithCheckTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.checkTimeout = timeout
+	}
+}
+
+// WithLatencyWindow adds a window to summarize the check latencies over
+// (defaults to the server default of 15 minutes and 1 hour).
+func WithLatencyWindow(window time.Duration) OpOption {
+	return func(op *Op) {
+		op.latencyWindows = append(op.latencyWindows, window)
+	}
+}
+
+// WithLogLevel sets the minimum level of the log entries to tail.
+func WithLogLevel(level string) OpOption {
+	return func(op *Op) {
+		op.logLevel = level
+	}
+}
+
+// WithLogLines sets the number of the recent log entries to tail (0 for all kept by the server).
+func WithLogLines(lines int) OpOption {
+	return func(op *Op) {
+		op.logLines = &lines
+	}
+}
+
+// WithStartTime sets the start time of the events to query (defaults to the current time).
+func WithStartTime(t time.Time) OpOption {
+	return func(op *Op) {
+		op.startTime = t
+	}
+}
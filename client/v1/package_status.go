@@ -3,7 +3,6 @@ package v1
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 
@@ -31,7 +30,7 @@ func GetPackageStatus(ctx context.Context, url string, opts ...OpOption) ([]pack
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %v received", resp.StatusCode)
+		return nil, newStatusError(resp, "")
 	}
 
 	rawBody, err := io.ReadAll(resp.Body)
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetReasonCodes returns the reason codes reported in the health states and events
+// of the components (all components if no WithComponent option is given),
+// with the codes shared by all components.
+func GetReasonCodes(ctx context.Context, addr string, opts ...OpOption) (apiv1.ReasonCodesMetadata, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1%s", addr, server.URLPathReasonCodes))
+	if err != nil {
+		return nil, err
+	}
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for c := range op.components {
+			components = append(components, c)
+		}
+		sort.Strings(components)
+
+		q := reqURL.Query()
+		q.Set("components", strings.Join(components, ","))
+		reqURL.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "")
+	}
+
+	var codes apiv1.ReasonCodesMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&codes); err != nil {
+		return nil, fmt.Errorf("failed to decode reason codes: %w", err)
+	}
+	return codes, nil
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetReasonCodes(t *testing.T) {
+	var gotQuery string
+	statusCode := http.StatusOK
+	body := `[{"code":"GPU_LOST","health":"Unhealthy","description":"The GPU has fallen off the bus."}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/reason-codes", r.URL.Path)
+		gotQuery = r.URL.Query().Get("components")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	codes, err := GetReasonCodes(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Empty(t, gotQuery)
+	assert.Equal(t, apiv1.ReasonCodesMetadata{{
+		Code:        apiv1.ReasonCodeGPULost,
+		Health:      apiv1.HealthStateTypeUnhealthy,
+		Description: "The GPU has fallen off the bus.",
+	}}, codes)
+
+	_, err = GetReasonCodes(context.Background(), srv.URL, WithComponent("memory"), WithComponent("cpu"))
+	require.NoError(t, err)
+	assert.Equal(t, "cpu,memory", gotQuery)
+
+	statusCode = http.StatusNotFound
+	_, err = GetReasonCodes(context.Background(), srv.URL, WithComponent("unknown"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 404")
+
+	statusCode = http.StatusOK
+	body = `[{"code":`
+	_, err = GetReasonCodes(context.Background(), srv.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode reason codes")
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/server"
+)
+
+// GetGPUdStatus returns the aggregated status of the GPUd daemon.
+func GetGPUdStatus(ctx context.Context, addr string, opts ...OpOption) (*apiv1.GPUdStatus, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1%s", addr, server.URLPathStatus), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return getGPUdStatus(createDefaultHTTPClient(), req)
+}
+
+func getGPUdStatus(cli *http.Client, req *http.Request) (*apiv1.GPUdStatus, error) {
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request to %q: %w", req.URL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "")
+	}
+
+	var st apiv1.GPUdStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("failed to decode status: %w", err)
+	}
+
+	return &st, nil
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetGPUdStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		wantErr       bool
+		errorContains string
+	}{
+		{
+			name:       "Success",
+			statusCode: http.StatusOK,
+			body:       `{"gpudVersion":"v0.5.0","machineID":"m1","components":{"total":2,"byHealth":{"Healthy":1,"Degraded":1},"unhealthy":["cpu"]},"dbSizeBytes":4096}`,
+		},
+		{
+			name:          "Wrong Status",
+			statusCode:    http.StatusForbidden,
+			wantErr:       true,
+			errorContains: "unexpected status code 403",
+		},
+		{
+			name:          "Malformed JSON",
+			statusCode:    http.StatusOK,
+			body:          `{"gpudVersion":`,
+			wantErr:       true,
+			errorContains: "failed to decode status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/status", r.URL.Path)
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			st, err := GetGPUdStatus(context.Background(), srv.URL)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "v0.5.0", st.GPUdVersion)
+			assert.Equal(t, "m1", st.MachineID)
+			assert.Equal(t, 2, st.Components.Total)
+			assert.Equal(t, 1, st.Components.ByHealth[apiv1.HealthStateTypeDegraded])
+			assert.Equal(t, []string{"cpu"}, st.Components.Unhealthy)
+			assert.Equal(t, uint64(4096), st.DBSizeBytes)
+		})
+	}
+}
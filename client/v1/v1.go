@@ -1,22 +1,19 @@
 package v1
 
 import (
-	"compress/gzip"
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
-	"sigs.k8s.io/yaml"
-
 	v1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/errdefs"
-	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 )
 
@@ -30,12 +27,7 @@ func GetComponents(ctx context.Context, addr string, opts ...OpOption) ([]string
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -45,10 +37,10 @@ func GetComponents(ctx context.Context, addr string, opts ...OpOption) ([]string
 		_ = resp.Body.Close()
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadComponents(resp.Body, opts...)
+	return ReadComponents(resp.Body, responseOpts(resp, opts)...)
 }
 
 func ReadComponents(rd io.Reader, opts ...OpOption) ([]string, error) {
@@ -58,52 +50,9 @@ func ReadComponents(rd io.Reader, opts ...OpOption) ([]string, error) {
 	}
 
 	var components []string
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&components); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &components); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&components); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &components); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &components); err != nil {
+		return nil, err
 	}
-
 	return components, nil
 }
 
@@ -133,12 +82,7 @@ func DeregisterComponent(ctx context.Context, addr string, componentName string,
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -149,7 +93,7 @@ func DeregisterComponent(ctx context.Context, addr string, componentName string,
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("server not ready, response not 200")
+		return newStatusError(resp, "server not ready, response not 200")
 	}
 
 	rb, err := io.ReadAll(resp.Body)
@@ -214,12 +158,7 @@ func GetInfo(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompone
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -229,10 +168,10 @@ func GetInfo(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompone
 		_ = resp.Body.Close()
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadInfo(resp.Body, opts...)
+	return ReadInfo(resp.Body, responseOpts(resp, opts)...)
 }
 
 func ReadInfo(rd io.Reader, opts ...OpOption) (v1.GPUdComponentInfos, error) {
@@ -242,52 +181,9 @@ func ReadInfo(rd io.Reader, opts ...OpOption) (v1.GPUdComponentInfos, error) {
 	}
 
 	var info v1.GPUdComponentInfos
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&info); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &info); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&info); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &info); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &info); err != nil {
+		return nil, err
 	}
-
 	return info, nil
 }
 
@@ -341,12 +237,7 @@ func GetHealthStates(ctx context.Context, addr string, opts ...OpOption) (v1.GPU
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -358,12 +249,12 @@ func GetHealthStates(ctx context.Context, addr string, opts ...OpOption) (v1.GPU
 
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNotFound {
-			return nil, errdefs.ErrNotFound
+			return nil, newStatusError(resp, errdefs.ErrNotFound.Error())
 		}
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadHealthStates(resp.Body, opts...)
+	return ReadHealthStates(resp.Body, responseOpts(resp, opts)...)
 }
 
 func ReadHealthStates(rd io.Reader, opts ...OpOption) (v1.GPUdComponentHealthStates, error) {
@@ -373,52 +264,9 @@ func ReadHealthStates(rd io.Reader, opts ...OpOption) (v1.GPUdComponentHealthSta
 	}
 
 	var states v1.GPUdComponentHealthStates
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&states); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &states); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&states); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &states); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &states); err != nil {
+		return nil, err
 	}
-
 	return states, nil
 }
 
@@ -428,16 +276,29 @@ func GetEvents(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompo
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/events", addr), nil)
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1/events", addr))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		components := make([]string, 0, len(op.components))
+		for component := range op.components {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+		q.Add("components", strings.Join(components, ","))
 	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
+	if !op.startTime.IsZero() {
+		q.Add("startTime", strconv.FormatInt(op.startTime.Unix(), 10))
 	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -447,10 +308,10 @@ func GetEvents(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdCompo
 		_ = resp.Body.Close()
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadEvents(resp.Body, opts...)
+	return ReadEvents(resp.Body, responseOpts(resp, opts)...)
 }
 
 func ReadEvents(rd io.Reader, opts ...OpOption) (v1.GPUdComponentEvents, error) {
@@ -460,52 +321,9 @@ func ReadEvents(rd io.Reader, opts ...OpOption) (v1.GPUdComponentEvents, error)
 	}
 
 	var evs v1.GPUdComponentEvents
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&evs); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &evs); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&evs); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &evs); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &evs); err != nil {
+		return nil, err
 	}
-
 	return evs, nil
 }
 
@@ -519,12 +337,7 @@ func GetMetrics(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdComp
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -534,10 +347,10 @@ func GetMetrics(ctx context.Context, addr string, opts ...OpOption) (v1.GPUdComp
 		_ = resp.Body.Close()
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadMetrics(resp.Body, opts...)
+	return ReadMetrics(resp.Body, responseOpts(resp, opts)...)
 }
 
 func ReadMetrics(rd io.Reader, opts ...OpOption) (v1.GPUdComponentMetrics, error) {
@@ -547,52 +360,9 @@ func ReadMetrics(rd io.Reader, opts ...OpOption) (v1.GPUdComponentMetrics, error
 	}
 
 	var metrics v1.GPUdComponentMetrics
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&metrics); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &metrics); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&metrics); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &metrics); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &metrics); err != nil {
+		return nil, err
 	}
-
 	return metrics, nil
 }
 
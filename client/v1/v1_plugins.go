@@ -1,20 +1,14 @@
 package v1
 
 import (
-	"compress/gzip"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 
-	"sigs.k8s.io/yaml"
-
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	"github.com/leptonai/gpud/pkg/errdefs"
-	"github.com/leptonai/gpud/pkg/httputil"
 )
 
 // GetPluginSpecs returns the custom plugins registered in the server.
@@ -34,12 +28,7 @@ func GetPluginSpecs(ctx context.Context, addr string, opts ...OpOption) (pkgcust
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -51,12 +40,12 @@ func GetPluginSpecs(ctx context.Context, addr string, opts ...OpOption) (pkgcust
 
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNotFound {
-			return nil, errdefs.ErrNotFound
+			return nil, newStatusError(resp, errdefs.ErrNotFound.Error())
 		}
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
-	return ReadPluginSpecs(resp.Body, opts...)
+	return ReadPluginSpecs(resp.Body, responseOpts(resp, opts)...)
 }
 
 // ReadPluginSpecs reads the custom plugin specs from the server.
@@ -67,51 +56,8 @@ func ReadPluginSpecs(rd io.Reader, opts ...OpOption) (pkgcustomplugins.Specs, er
 	}
 
 	var specs pkgcustomplugins.Specs
-	switch op.requestAcceptEncoding {
-	case httputil.RequestHeaderEncodingGzip:
-		gr, err := gzip.NewReader(rd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer func() {
-			_ = gr.Close()
-		}()
-
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(gr).Decode(&specs); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(gr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &specs); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
-
-	default:
-		switch op.requestContentType {
-		case httputil.RequestHeaderJSON, "":
-			if err := json.NewDecoder(rd).Decode(&specs); err != nil {
-				return nil, fmt.Errorf("failed to decode json: %w", err)
-			}
-		case httputil.RequestHeaderYAML:
-			b, err := io.ReadAll(rd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read yaml: %w", err)
-			}
-			if err := yaml.Unmarshal(b, &specs); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unsupported content type: %s", op.requestContentType)
-		}
+	if err := op.decodeBody(rd, &specs); err != nil {
+		return nil, err
 	}
-
 	return specs, nil
 }
@@ -4,12 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
 
-	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/server"
 )
@@ -38,12 +36,7 @@ func SetHealthyComponents(ctx context.Context, addr string, components []string,
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -54,8 +47,7 @@ func SetHealthyComponents(ctx context.Context, addr string, components []string,
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+		return nil, newStatusError(resp, fmt.Sprintf("server returned %d", resp.StatusCode))
 	}
 
 	var response server.SetHealthyStatesResponse
@@ -719,6 +719,28 @@ func TestGetEvents(t *testing.T) {
 	})
 }
 
+func TestGetEventsQuery(t *testing.T) {
+	startTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/events", r.URL.Path)
+		assert.Equal(t, "accelerator-nvidia-xid,cpu", r.URL.Query().Get("components"))
+		assert.Equal(t, "1735787045", r.URL.Query().Get("startTime"))
+		w.Header().Set(httputil.RequestHeaderContentType, httputil.RequestHeaderJSON)
+		_, err := w.Write([]byte(`[]`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	result, err := GetEvents(context.Background(), srv.URL,
+		WithComponent("cpu"),
+		WithComponent("accelerator-nvidia-xid"),
+		WithStartTime(startTime),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
 func TestReadEvents(t *testing.T) {
 	now := time.Now().UTC()
 	testEvents := apiv1.GPUdComponentEvents{
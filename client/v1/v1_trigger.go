@@ -7,9 +7,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	v1 "github.com/leptonai/gpud/api/v1"
-	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 )
 
@@ -38,12 +39,7 @@ func TriggerComponent(ctx context.Context, addr string, componentName string, op
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -54,7 +50,7 @@ func TriggerComponent(ctx context.Context, addr string, componentName string, op
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server not ready, response not 200")
+		return nil, newStatusError(resp, "server not ready, response not 200")
 	}
 
 	var healthStates v1.GPUdComponentHealthStates
@@ -66,6 +62,76 @@ func TriggerComponent(ctx context.Context, addr string, componentName string, op
 	return healthStates, nil
 }
 
+// TriggerChecks triggers the checks of the components selected by WithComponent
+// and WithTag in one call, run concurrently by the server within the timeout
+// (see WithCheckTimeout), and returns the results keyed by the component name.
+// The checks not completed within the timeout are returned with their last
+// health states, and marked as timed out.
+func TriggerChecks(ctx context.Context, addr string, opts ...OpOption) (v1.ComponentCheckResults, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	if len(op.components) == 0 && len(op.tags) == 0 {
+		return nil, errors.New("component or tag name is required")
+	}
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/v1/components/trigger-check", addr))
+	if err != nil {
+		return nil, err
+	}
+
+	q := reqURL.Query()
+	if len(op.components) > 0 {
+		q.Add("components", joinKeys(op.components))
+	}
+	if len(op.tags) > 0 {
+		q.Add("tags", joinKeys(op.tags))
+	}
+	if op.checkTimeout > 0 {
+		q.Add("timeout", op.checkTimeout.String())
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	op.setRequestHeaders(req)
+
+	resp, err := createDefaultHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp, "server not ready, response not 200")
+	}
+
+	var results v1.ComponentCheckResults
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	log.Logger.Infow("triggered component checks", "components", len(results))
+	return results, nil
+}
+
+// joinKeys returns the sorted keys joined by the comma.
+func joinKeys(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
 // TriggerComponentCheckByTag triggers all components that have the specified tag
 func TriggerComponentCheckByTag(ctx context.Context, addr string, tagName string, opts ...OpOption) error {
 	op := &Op{}
@@ -91,12 +157,7 @@ func TriggerComponentCheckByTag(ctx context.Context, addr string, tagName string
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if op.requestContentType != "" {
-		req.Header.Set(httputil.RequestHeaderContentType, op.requestContentType)
-	}
-	if op.requestAcceptEncoding != "" {
-		req.Header.Set(httputil.RequestHeaderAcceptEncoding, op.requestAcceptEncoding)
-	}
+	op.setRequestHeaders(req)
 
 	resp, err := createDefaultHTTPClient().Do(req)
 	if err != nil {
@@ -107,7 +168,7 @@ func TriggerComponentCheckByTag(ctx context.Context, addr string, tagName string
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("server not ready, response not 200")
+		return newStatusError(resp, "server not ready, response not 200")
 	}
 
 	var response struct {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -122,3 +123,35 @@ func TestTriggerComponentCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestTriggerChecks(t *testing.T) {
+	results := v1.ComponentCheckResults{
+		"comp1": {Component: "comp1", States: v1.HealthStates{{Health: v1.HealthStateTypeHealthy}}},
+		"comp2": {Component: "comp2", TimedOut: true},
+	}
+
+	_, err := TriggerChecks(context.Background(), "http://localhost:8080")
+	assert.EqualError(t, err, "component or tag name is required")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/components/trigger-check", r.URL.Path)
+		if r.URL.Query().Get("tags") == "fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "comp1,comp2", r.URL.Query().Get("components"))
+		assert.Equal(t, "nvidia", r.URL.Query().Get("tags"))
+		assert.Equal(t, "30s", r.URL.Query().Get("timeout"))
+		_, err := w.Write(mustMarshalJSON(t, results))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	got, err := TriggerChecks(context.Background(), srv.URL,
+		WithComponent("comp2"), WithComponent("comp1"), WithTag("nvidia"), WithCheckTimeout(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, results, got)
+
+	_, err = TriggerChecks(context.Background(), srv.URL, WithTag("fail"))
+	assert.ErrorContains(t, err, "server not ready, response not 200")
+}
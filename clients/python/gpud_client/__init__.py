@@ -0,0 +1,52 @@
+"""Python client of the GPUd API."""
+
+from ._version import __version__
+from .client import DEFAULT_ADDRESS, Client
+from .errors import (
+    GPUdError,
+    NotFoundError,
+    PartialFailureError,
+    ServerError,
+    StatusError,
+    TransportError,
+    UnauthorizedError,
+    UnavailableError,
+)
+from .models import (
+    ComponentEvents,
+    ComponentHealthStates,
+    ComponentInfo,
+    ComponentMetrics,
+    Event,
+    HealthState,
+    Info,
+    Metric,
+    PluginApplyResponse,
+    PluginApplyResult,
+    SuggestedActions,
+)
+
+__all__ = [
+    "__version__",
+    "DEFAULT_ADDRESS",
+    "Client",
+    "GPUdError",
+    "NotFoundError",
+    "PartialFailureError",
+    "ServerError",
+    "StatusError",
+    "TransportError",
+    "UnauthorizedError",
+    "UnavailableError",
+    "ComponentEvents",
+    "ComponentHealthStates",
+    "ComponentInfo",
+    "ComponentMetrics",
+    "Event",
+    "HealthState",
+    "Info",
+    "Metric",
+    "PluginApplyResponse",
+    "PluginApplyResult",
+    "SuggestedActions",
+]
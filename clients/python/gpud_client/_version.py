@@ -0,0 +1,2 @@
+# Set to the GPUd release version (without the "v" prefix) when releasing the package.
+__version__ = "0.0.0.dev0"
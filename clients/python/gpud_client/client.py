@@ -0,0 +1,244 @@
+"""Client of the GPUd API, mirroring the client/v1 package of the Go client."""
+
+import gzip
+import json
+import socket
+import ssl
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from datetime import datetime
+from typing import Any, Dict, Iterable, List, Optional, Tuple, Union
+
+from ._version import __version__
+from .errors import PartialFailureError, TransportError, new_status_error
+from .models import (
+    ComponentEvents,
+    ComponentHealthStates,
+    ComponentInfo,
+    ComponentMetrics,
+    PluginApplyResponse,
+)
+
+DEFAULT_ADDRESS = "https://localhost:15132"
+
+# Response status codes retried as the server may be restarting or not ready yet.
+RETRYABLE_STATUS_CODES = frozenset((502, 503, 504))
+
+Components = Optional[Iterable[str]]
+
+
+class Client:
+    """Client of the GPUd API.
+
+    The GPUd server serves a self-signed certificate by default, thus the
+    certificate is not verified unless verify is set to True (or to an SSL context).
+    Connection errors and the 502, 503, and 504 responses are retried with
+    the exponential backoff, up to the given number of retries.
+    """
+
+    def __init__(
+        self,
+        address: str = DEFAULT_ADDRESS,
+        timeout: float = 30.0,
+        retries: int = 3,
+        backoff: float = 0.5,
+        max_backoff: float = 8.0,
+        verify: Union[bool, ssl.SSLContext] = False,
+        headers: Optional[Dict[str, str]] = None,
+    ) -> None:
+        if retries < 0:
+            raise ValueError("retries must be non-negative, got %d" % retries)
+        self.address = address.rstrip("/")
+        self.timeout = timeout
+        self.retries = retries
+        self.backoff = backoff
+        self.max_backoff = max_backoff
+        self.headers = dict(headers or {})
+
+        if isinstance(verify, ssl.SSLContext):
+            self._ssl_context = verify
+        elif verify:
+            self._ssl_context = ssl.create_default_context()
+        else:
+            self._ssl_context = ssl.create_default_context()
+            self._ssl_context.check_hostname = False
+            self._ssl_context.verify_mode = ssl.CERT_NONE
+
+    def healthz(self) -> None:
+        """Raises an error if the server is not healthy."""
+        status, body = self._request("GET", "/healthz")
+        data = json.loads(body)
+        if data.get("status") != "ok":
+            raise new_status_error(status, body, "unexpected healthz response")
+
+    def wait_until_ready(self, timeout: float = 60.0, interval: float = 1.0) -> None:
+        """Blocks until the server is healthy or the timeout elapses."""
+        deadline = time.monotonic() + timeout
+        while True:
+            try:
+                self.healthz()
+                return
+            except Exception:
+                if time.monotonic() + interval > deadline:
+                    raise
+            time.sleep(interval)
+
+    def machine_info(self) -> Dict[str, Any]:
+        """Returns the machine info."""
+        return self._get_json("/machine-info")
+
+    def components(self) -> List[str]:
+        """Returns the names of the registered components."""
+        return list(self._get_json("/v1/components") or [])
+
+    def deregister_component(self, component: str) -> None:
+        """Deregisters the component, e.g., a custom plugin."""
+        if not component:
+            raise ValueError("component name is required")
+        self._request("DELETE", "/v1/components", {"componentName": component})
+
+    def info(self, components: Components = None, start_time: Optional[datetime] = None) -> List[ComponentInfo]:
+        """Returns the states, events, and metrics of the components (all if not specified)."""
+        query = _components_query(components)
+        if start_time is not None:
+            query["startTime"] = str(int(start_time.timestamp()))
+        return [ComponentInfo.from_dict(d) for d in self._get_json("/v1/info", query) or []]
+
+    def states(self, components: Components = None) -> List[ComponentHealthStates]:
+        """Returns the health states of the components (all if not specified)."""
+        data = self._get_json("/v1/states", _components_query(components))
+        return [ComponentHealthStates.from_dict(d) for d in data or []]
+
+    def events(self, components: Components = None, start_time: Optional[datetime] = None) -> List[ComponentEvents]:
+        """Returns the events of the components (all if not specified) since the start time."""
+        query = _components_query(components)
+        if start_time is not None:
+            query["startTime"] = str(int(start_time.timestamp()))
+        return [ComponentEvents.from_dict(d) for d in self._get_json("/v1/events", query) or []]
+
+    def metrics(self, components: Components = None) -> List[ComponentMetrics]:
+        """Returns the metrics of the components (all if not specified)."""
+        data = self._get_json("/v1/metrics", _components_query(components))
+        return [ComponentMetrics.from_dict(d) for d in data or []]
+
+    def metrics_metadata(self) -> List[Dict[str, Any]]:
+        """Returns the metadata of the metrics."""
+        return list(self._get_json("/v1/metrics/metadata") or [])
+
+    def reason_codes(self, components: Components = None) -> List[Dict[str, Any]]:
+        """Returns the reason codes of the components (all if not specified), with the shared codes."""
+        return list(self._get_json("/v1/reason-codes", _components_query(components)) or [])
+
+    def set_healthy(self, components: Iterable[str]) -> List[str]:
+        """Sets the components to the healthy state, returning the components set healthy.
+
+        Raises PartialFailureError if any of the components failed.
+        """
+        components = list(components)
+        query = {"components": ",".join(components)} if components else None
+        _, body = self._request("POST", "/v1/health-states/set-healthy", query)
+        try:
+            data = json.loads(body)
+        except ValueError:
+            return components
+        if data.get("failed"):
+            raise PartialFailureError("some components failed to set healthy: %s" % data["failed"], data["failed"])
+        return list(data.get("successful") or components)
+
+    def trigger_check(self, component: str) -> List[ComponentHealthStates]:
+        """Triggers the component check, returning the resulting health states."""
+        if not component:
+            raise ValueError("component name is required")
+        data = self._get_json("/v1/components/trigger-check", {"componentName": component})
+        return [ComponentHealthStates.from_dict(d) for d in data or []]
+
+    def trigger_tag(self, tag: str) -> List[str]:
+        """Triggers the checks of all the components with the tag, returning the components.
+
+        Raises PartialFailureError if any of the checks failed.
+        """
+        if not tag:
+            raise ValueError("tag name is required")
+        data = self._get_json("/v1/components/trigger-tag", {"tagName": tag})
+        if not data.get("success"):
+            raise PartialFailureError(
+                "health check failed for tag %s, components: %s" % (tag, data.get("components")),
+                data.get("components"),
+            )
+        return list(data.get("components") or [])
+
+    def plugins(self) -> List[Dict[str, Any]]:
+        """Returns the custom plugin specs."""
+        return list(self._get_json("/v1/plugins") or [])
+
+    def apply_plugins(self, specs: List[Dict[str, Any]], dry_run: bool = False) -> PluginApplyResponse:
+        """Replaces all the custom plugins with the specs, as a whole or not at all.
+
+        With dry_run, only the changes are reported.
+        """
+        query = {"dry_run": "true"} if dry_run else None
+        _, body = self._request(
+            "PUT",
+            "/v1/components/custom-plugins/bulk",
+            query,
+            json.dumps(specs).encode(),
+            retry=False,
+        )
+        return PluginApplyResponse.from_dict(json.loads(body))
+
+    def _get_json(self, path: str, query: Optional[Dict[str, str]] = None) -> Any:
+        _, body = self._request("GET", path, query)
+        return json.loads(body)
+
+    def _request(
+        self,
+        method: str,
+        path: str,
+        query: Optional[Dict[str, str]] = None,
+        data: Optional[bytes] = None,
+        retry: bool = True,
+    ) -> Tuple[int, str]:
+        url = self.address + path
+        if query:
+            url += "?" + urllib.parse.urlencode(query)
+
+        headers = {
+            "Accept-Encoding": "gzip",
+            "Content-Type": "application/json",
+            "User-Agent": "gpud-client-python/" + __version__,
+        }
+        headers.update(self.headers)
+
+        attempts = self.retries + 1 if retry else 1
+        delay = self.backoff
+        for attempt in range(attempts):
+            last = attempt == attempts - 1
+            req = urllib.request.Request(url, data=data, headers=headers, method=method)
+            try:
+                with urllib.request.urlopen(req, timeout=self.timeout, context=self._ssl_context) as resp:
+                    return resp.status, _read_body(resp)
+            except urllib.error.HTTPError as e:
+                body = _read_body(e)
+                if last or e.code not in RETRYABLE_STATUS_CODES:
+                    raise new_status_error(e.code, body) from None
+            except (urllib.error.URLError, socket.timeout, ConnectionError) as e:
+                if last:
+                    raise TransportError("failed to make request: %s" % e) from e
+            time.sleep(delay)
+            delay = min(delay * 2, self.max_backoff)
+        raise AssertionError("unreachable")
+
+
+def _components_query(components: Components) -> Dict[str, str]:
+    if not components:
+        return {}
+    return {"components": ",".join(sorted(components))}
+
+
+def _read_body(resp: Any) -> str:
+    raw = resp.read()
+    if resp.headers.get("Content-Encoding") == "gzip":
+        raw = gzip.decompress(raw)
+    return raw.decode("utf-8", errors="replace")
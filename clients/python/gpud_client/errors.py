@@ -0,0 +1,65 @@
+"""Errors of the GPUd API client, mirroring the client/v1 errors of the Go client."""
+
+from typing import Optional
+
+# Maximum number of the response body characters kept in the error.
+MAX_ERROR_BODY_SIZE = 4096
+
+
+class GPUdError(Exception):
+    """Base error of the GPUd API client."""
+
+
+class TransportError(GPUdError):
+    """Failed to reach the GPUd server after all the retries."""
+
+
+class StatusError(GPUdError):
+    """Unexpected response status from the GPUd server.
+
+    The body is truncated to 4 KiB.
+    """
+
+    def __init__(self, status_code: int, body: str = "", message: Optional[str] = None) -> None:
+        self.status_code = status_code
+        self.body = body[:MAX_ERROR_BODY_SIZE].strip()
+        self.message = message or "unexpected status code %d" % status_code
+        super().__init__(self.message + (": " + self.body if self.body else ""))
+
+
+class NotFoundError(StatusError):
+    """404 response."""
+
+
+class UnauthorizedError(StatusError):
+    """401 or 403 response."""
+
+
+class ServerError(StatusError):
+    """5xx response."""
+
+
+class UnavailableError(ServerError):
+    """503 response, e.g., the server is not ready yet."""
+
+
+class PartialFailureError(GPUdError):
+    """Some of the requested components failed."""
+
+    def __init__(self, message: str, failed: object) -> None:
+        self.failed = failed
+        super().__init__(message)
+
+
+def new_status_error(status_code: int, body: str, message: Optional[str] = None) -> StatusError:
+    """Returns the error type matching the response status code."""
+    cls = StatusError
+    if status_code == 404:
+        cls = NotFoundError
+    elif status_code in (401, 403):
+        cls = UnauthorizedError
+    elif status_code == 503:
+        cls = UnavailableError
+    elif status_code >= 500:
+        cls = ServerError
+    return cls(status_code, body, message)
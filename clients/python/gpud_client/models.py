@@ -0,0 +1,220 @@
+"""Typed models of the GPUd API responses, mirroring api/v1."""
+
+import re
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional
+
+_FRACTION = re.compile(r"\.(\d+)")
+
+
+def parse_time(value: Optional[str]) -> Optional[datetime]:
+    """Parses the RFC 3339 timestamp of the Go API types.
+
+    Returns None for the empty and zero values.
+    """
+    if not value or value.startswith("0001-01-01"):
+        return None
+    value = value.replace("Z", "+00:00")
+    # Go encodes up to nanoseconds while datetime only keeps microseconds
+    value = _FRACTION.sub(lambda m: "." + m.group(1)[:6].ljust(6, "0"), value, count=1)
+    dt = datetime.fromisoformat(value)
+    if dt.tzinfo is None:
+        dt = dt.replace(tzinfo=timezone.utc)
+    return dt
+
+
+@dataclass
+class SuggestedActions:
+    description: str = ""
+    repair_actions: List[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SuggestedActions":
+        return cls(
+            description=d.get("description", ""),
+            repair_actions=list(d.get("repair_actions") or []),
+        )
+
+
+@dataclass
+class HealthState:
+    time: Optional[datetime] = None
+    component: str = ""
+    component_type: str = ""
+    name: str = ""
+    run_mode: str = ""
+    health: str = ""
+    reason: str = ""
+    reason_code: str = ""
+    error: str = ""
+    suggested_actions: Optional[SuggestedActions] = None
+    extra_info: Dict[str, str] = field(default_factory=dict)
+    raw_output: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "HealthState":
+        actions = d.get("suggested_actions")
+        return cls(
+            time=parse_time(d.get("time")),
+            component=d.get("component", ""),
+            component_type=d.get("component_type", ""),
+            name=d.get("name", ""),
+            run_mode=d.get("run_mode", ""),
+            health=d.get("health", ""),
+            reason=d.get("reason", ""),
+            reason_code=d.get("reason_code", ""),
+            error=d.get("error", ""),
+            suggested_actions=SuggestedActions.from_dict(actions) if actions else None,
+            extra_info=dict(d.get("extra_info") or {}),
+            raw_output=d.get("raw_output", ""),
+        )
+
+
+@dataclass
+class ComponentHealthStates:
+    component: str
+    states: List[HealthState] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ComponentHealthStates":
+        return cls(
+            component=d.get("component", ""),
+            states=[HealthState.from_dict(s) for s in d.get("states") or []],
+        )
+
+
+@dataclass
+class Event:
+    component: str = ""
+    time: Optional[datetime] = None
+    name: str = ""
+    type: str = ""
+    message: str = ""
+    reason_code: str = ""
+    extra_info: Dict[str, str] = field(default_factory=dict)
+    dedup_key: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "Event":
+        return cls(
+            component=d.get("component", ""),
+            time=parse_time(d.get("time")),
+            name=d.get("name", ""),
+            type=d.get("type", ""),
+            message=d.get("message", ""),
+            reason_code=d.get("reason_code", ""),
+            extra_info=dict(d.get("extra_info") or {}),
+            dedup_key=d.get("dedup_key", ""),
+        )
+
+
+@dataclass
+class ComponentEvents:
+    component: str
+    start_time: Optional[datetime] = None
+    end_time: Optional[datetime] = None
+    events: List[Event] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ComponentEvents":
+        return cls(
+            component=d.get("component", ""),
+            start_time=parse_time(d.get("startTime")),
+            end_time=parse_time(d.get("endTime")),
+            events=[Event.from_dict(e) for e in d.get("events") or []],
+        )
+
+
+@dataclass
+class Metric:
+    unix_seconds: int = 0
+    name: str = ""
+    labels: Dict[str, str] = field(default_factory=dict)
+    value: float = 0.0
+    unit: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "Metric":
+        return cls(
+            unix_seconds=int(d.get("unix_seconds", 0)),
+            name=d.get("name", ""),
+            labels=dict(d.get("labels") or {}),
+            value=float(d.get("value", 0.0)),
+            unit=d.get("unit", ""),
+        )
+
+
+@dataclass
+class ComponentMetrics:
+    component: str
+    metrics: List[Metric] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ComponentMetrics":
+        return cls(
+            component=d.get("component", ""),
+            metrics=[Metric.from_dict(m) for m in d.get("metrics") or []],
+        )
+
+
+@dataclass
+class Info:
+    states: List[HealthState] = field(default_factory=list)
+    events: List[Event] = field(default_factory=list)
+    metrics: List[Metric] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "Info":
+        return cls(
+            states=[HealthState.from_dict(s) for s in d.get("states") or []],
+            events=[Event.from_dict(e) for e in d.get("events") or []],
+            metrics=[Metric.from_dict(m) for m in d.get("metrics") or []],
+        )
+
+
+@dataclass
+class ComponentInfo:
+    component: str
+    start_time: Optional[datetime] = None
+    end_time: Optional[datetime] = None
+    info: Info = field(default_factory=Info)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ComponentInfo":
+        return cls(
+            component=d.get("component", ""),
+            start_time=parse_time(d.get("startTime")),
+            end_time=parse_time(d.get("endTime")),
+            info=Info.from_dict(d.get("info") or {}),
+        )
+
+
+@dataclass
+class PluginApplyResult:
+    component_name: str
+    action: str
+    error: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "PluginApplyResult":
+        return cls(
+            component_name=d.get("component_name", ""),
+            action=d.get("action", ""),
+            error=d.get("error", ""),
+        )
+
+
+@dataclass
+class PluginApplyResponse:
+    dry_run: bool = False
+    results: List[PluginApplyResult] = field(default_factory=list)
+    error: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "PluginApplyResponse":
+        return cls(
+            dry_run=bool(d.get("dry_run", False)),
+            results=[PluginApplyResult.from_dict(r) for r in d.get("results") or []],
+            error=d.get("error", ""),
+        )
@@ -0,0 +1,234 @@
+import gzip
+import json
+import threading
+import unittest
+from datetime import datetime, timezone
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.parse import parse_qs, urlparse
+
+from gpud_client import (
+    Client,
+    NotFoundError,
+    PartialFailureError,
+    ServerError,
+    TransportError,
+    UnauthorizedError,
+    UnavailableError,
+)
+from gpud_client.models import parse_time
+
+
+class _Handler(BaseHTTPRequestHandler):
+    # (method, path) -> list of (status, body), the last one repeated
+    routes = {}
+    requests = []
+
+    def log_message(self, *args):
+        pass
+
+    def _serve(self):
+        u = urlparse(self.path)
+        length = int(self.headers.get("Content-Length") or 0)
+        body = self.rfile.read(length) if length else b""
+        type(self).requests.append((self.command, u.path, parse_qs(u.query), body))
+
+        responses = type(self).routes.get((self.command, u.path))
+        if not responses:
+            status, payload = 404, {"code": 404, "message": "not found"}
+        elif len(responses) > 1:
+            status, payload = responses.pop(0)
+        else:
+            status, payload = responses[0]
+
+        raw = json.dumps(payload).encode()
+        gzipped = "gzip" in (self.headers.get("Accept-Encoding") or "")
+        if gzipped:
+            raw = gzip.compress(raw)
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        if gzipped:
+            self.send_header("Content-Encoding", "gzip")
+        self.send_header("Content-Length", str(len(raw)))
+        self.end_headers()
+        self.wfile.write(raw)
+
+    do_GET = do_POST = do_PUT = do_DELETE = _serve
+
+
+class ClientTest(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        cls.server = HTTPServer(("127.0.0.1", 0), _Handler)
+        threading.Thread(target=cls.server.serve_forever, daemon=True).start()
+        cls.address = "http://127.0.0.1:%d" % cls.server.server_port
+
+    @classmethod
+    def tearDownClass(cls):
+        cls.server.shutdown()
+        cls.server.server_close()
+
+    def setUp(self):
+        _Handler.routes = {}
+        _Handler.requests = []
+        self.client = Client(self.address, retries=2, backoff=0.01)
+
+    def test_healthz(self):
+        _Handler.routes[("GET", "/healthz")] = [(200, {"status": "ok", "version": "v1"})]
+        self.client.healthz()
+        self.client.wait_until_ready(timeout=1)
+
+    def test_states(self):
+        _Handler.routes[("GET", "/v1/states")] = [
+            (
+                200,
+                [
+                    {
+                        "component": "cpu",
+                        "states": [
+                            {
+                                "time": "2025-01-02T03:04:05.123456789Z",
+                                "name": "cpu",
+                                "health": "Unhealthy",
+                                "reason": "overheating",
+                                "reason_code": "UNHEALTHY",
+                                "suggested_actions": {"repair_actions": ["REBOOT_SYSTEM"]},
+                                "extra_info": {"data": "{}"},
+                            }
+                        ],
+                    }
+                ],
+            )
+        ]
+        states = self.client.states(components=["memory", "cpu"])
+        self.assertEqual(len(states), 1)
+        st = states[0].states[0]
+        self.assertEqual(states[0].component, "cpu")
+        self.assertEqual(st.health, "Unhealthy")
+        self.assertEqual(st.reason_code, "UNHEALTHY")
+        self.assertEqual(st.suggested_actions.repair_actions, ["REBOOT_SYSTEM"])
+        self.assertEqual(st.time, datetime(2025, 1, 2, 3, 4, 5, 123456, tzinfo=timezone.utc))
+        self.assertEqual(_Handler.requests[0][2], {"components": ["cpu,memory"]})
+
+    def test_events(self):
+        _Handler.routes[("GET", "/v1/events")] = [
+            (
+                200,
+                [
+                    {
+                        "component": "os",
+                        "startTime": "2025-01-01T00:00:00Z",
+                        "endTime": "2025-01-02T00:00:00Z",
+                        "events": [
+                            {"time": "2025-01-01T12:00:00Z", "name": "reboot", "type": "Warning", "reason_code": "EVENT_REBOOT"}
+                        ],
+                    }
+                ],
+            )
+        ]
+        start = datetime(2025, 1, 1, tzinfo=timezone.utc)
+        evs = self.client.events(components=["os"], start_time=start)
+        self.assertEqual(evs[0].events[0].name, "reboot")
+        self.assertEqual(evs[0].events[0].reason_code, "EVENT_REBOOT")
+        self.assertEqual(evs[0].start_time, start)
+        self.assertEqual(_Handler.requests[0][2]["startTime"], [str(int(start.timestamp()))])
+
+    def test_metrics(self):
+        _Handler.routes[("GET", "/v1/metrics")] = [
+            (200, [{"component": "cpu", "metrics": [{"unix_seconds": 1, "name": "usage", "value": 0.5}]}])
+        ]
+        metrics = self.client.metrics()
+        self.assertEqual(metrics[0].metrics[0].value, 0.5)
+
+    def test_reason_codes(self):
+        _Handler.routes[("GET", "/v1/reason-codes")] = [(200, [{"code": "GPU_LOST", "health": "Unhealthy"}])]
+        codes = self.client.reason_codes(components=["accelerator-nvidia-ecc"])
+        self.assertEqual(codes[0]["code"], "GPU_LOST")
+        self.assertEqual(_Handler.requests[0][2], {"components": ["accelerator-nvidia-ecc"]})
+
+    def test_plugins(self):
+        _Handler.routes[("GET", "/v1/plugins")] = [(200, [{"plugin_name": "p"}])]
+        _Handler.routes[("PUT", "/v1/components/custom-plugins/bulk")] = [
+            (200, {"dry_run": True, "results": [{"component_name": "custom-plugin-p", "action": "created"}]})
+        ]
+        _Handler.routes[("DELETE", "/v1/components")] = [(200, {})]
+
+        self.assertEqual(self.client.plugins(), [{"plugin_name": "p"}])
+        resp = self.client.apply_plugins([{"plugin_name": "p"}], dry_run=True)
+        self.assertTrue(resp.dry_run)
+        self.assertEqual(resp.results[0].action, "created")
+        self.client.deregister_component("custom-plugin-p")
+
+        put = [r for r in _Handler.requests if r[0] == "PUT"][0]
+        self.assertEqual(put[2], {"dry_run": ["true"]})
+        self.assertEqual(json.loads(put[3]), [{"plugin_name": "p"}])
+        delete = [r for r in _Handler.requests if r[0] == "DELETE"][0]
+        self.assertEqual(delete[2], {"componentName": ["custom-plugin-p"]})
+
+    def test_set_healthy(self):
+        _Handler.routes[("POST", "/v1/health-states/set-healthy")] = [
+            (200, {"successful": ["cpu"]}),
+            (200, {"successful": ["cpu"], "failed": {"memory": "not supported"}}),
+        ]
+        self.assertEqual(self.client.set_healthy(["cpu"]), ["cpu"])
+        with self.assertRaises(PartialFailureError) as cm:
+            self.client.set_healthy(["cpu", "memory"])
+        self.assertEqual(cm.exception.failed, {"memory": "not supported"})
+
+    def test_trigger(self):
+        _Handler.routes[("GET", "/v1/components/trigger-check")] = [
+            (200, [{"component": "cpu", "states": [{"health": "Healthy"}]}])
+        ]
+        _Handler.routes[("GET", "/v1/components/trigger-tag")] = [
+            (200, {"components": ["cpu"], "exit": 0, "success": True}),
+            (200, {"components": ["cpu"], "exit": 1, "success": False}),
+        ]
+        self.assertEqual(self.client.trigger_check("cpu")[0].states[0].health, "Healthy")
+        self.assertEqual(self.client.trigger_tag("nvidia"), ["cpu"])
+        with self.assertRaises(PartialFailureError):
+            self.client.trigger_tag("nvidia")
+        with self.assertRaises(ValueError):
+            self.client.trigger_check("")
+
+    def test_errors(self):
+        with self.assertRaises(NotFoundError) as cm:
+            self.client.plugins()
+        self.assertEqual(cm.exception.status_code, 404)
+        self.assertIn("not found", cm.exception.body)
+
+        _Handler.routes[("GET", "/v1/components")] = [(403, {"message": "forbidden"})]
+        with self.assertRaises(UnauthorizedError):
+            self.client.components()
+
+        # 500 is not retried
+        _Handler.routes[("GET", "/v1/metrics")] = [(500, {"message": "boom"})]
+        with self.assertRaises(ServerError):
+            self.client.metrics()
+        self.assertEqual(len(_Handler.requests), 3)
+
+    def test_retries(self):
+        _Handler.routes[("GET", "/v1/components")] = [(503, {}), (503, {}), (200, ["cpu"])]
+        self.assertEqual(self.client.components(), ["cpu"])
+        self.assertEqual(len(_Handler.requests), 3)
+
+        _Handler.requests = []
+        _Handler.routes[("GET", "/v1/components")] = [(503, {})]
+        with self.assertRaises(UnavailableError):
+            self.client.components()
+        self.assertEqual(len(_Handler.requests), 3)
+
+    def test_transport_error(self):
+        client = Client("http://127.0.0.1:1", retries=1, backoff=0.01, timeout=1)
+        with self.assertRaises(TransportError):
+            client.components()
+
+
+class ParseTimeTest(unittest.TestCase):
+    def test_parse_time(self):
+        self.assertIsNone(parse_time(""))
+        self.assertIsNone(parse_time("0001-01-01T00:00:00Z"))
+        self.assertEqual(parse_time("2025-01-02T03:04:05Z"), datetime(2025, 1, 2, 3, 4, 5, tzinfo=timezone.utc))
+        self.assertEqual(parse_time("2025-01-02T03:04:05.5+00:00").microsecond, 500000)
+
+
+if __name__ == "__main__":
+    unittest.main()
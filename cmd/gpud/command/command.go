@@ -2,17 +2,23 @@ package command
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli"
 
 	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
 	cmdcompact "github.com/leptonai/gpud/cmd/gpud/compact"
+	cmdconfig "github.com/leptonai/gpud/cmd/gpud/config"
 	cmdcustomplugins "github.com/leptonai/gpud/cmd/gpud/custom-plugins"
 	cmddown "github.com/leptonai/gpud/cmd/gpud/down"
+	cmdevents "github.com/leptonai/gpud/cmd/gpud/events"
 	cmdinjectfault "github.com/leptonai/gpud/cmd/gpud/inject-fault"
 	cmdlistplugins "github.com/leptonai/gpud/cmd/gpud/list-plugins"
+	cmdlogs "github.com/leptonai/gpud/cmd/gpud/logs"
 	cmdmachineinfo "github.com/leptonai/gpud/cmd/gpud/machine-info"
 	cmdmetadata "github.com/leptonai/gpud/cmd/gpud/metadata"
+	cmdmigrate "github.com/leptonai/gpud/cmd/gpud/migrate"
+	cmdmigratedb "github.com/leptonai/gpud/cmd/gpud/migrate-db"
 	cmdnotify "github.com/leptonai/gpud/cmd/gpud/notify"
 	cmdrelease "github.com/leptonai/gpud/cmd/gpud/release"
 	cmdrun "github.com/leptonai/gpud/cmd/gpud/run"
@@ -20,13 +26,17 @@ import (
 	cmdscan "github.com/leptonai/gpud/cmd/gpud/scan"
 	cmdsethealthy "github.com/leptonai/gpud/cmd/gpud/set-healthy"
 	cmdstatus "github.com/leptonai/gpud/cmd/gpud/status"
+	cmdtokens "github.com/leptonai/gpud/cmd/gpud/tokens"
 	cmdup "github.com/leptonai/gpud/cmd/gpud/up"
 	cmdupdate "github.com/leptonai/gpud/cmd/gpud/update"
+	cmdverifyinstall "github.com/leptonai/gpud/cmd/gpud/verify-install"
 	componentssxid "github.com/leptonai/gpud/components/accelerator/nvidia/sxid"
 	componentsnvidiatemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
 	componentsxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
 	pkgconfig "github.com/leptonai/gpud/pkg/config"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/rbac"
 	pkgupdate "github.com/leptonai/gpud/pkg/update"
 	"github.com/leptonai/gpud/version"
 )
@@ -47,6 +57,19 @@ func App() *cli.App {
 	app.Usage = usage
 	app.Description = "GPU health checkers"
 
+	tokensFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "tokens-file",
+			Usage: "set the tokens file, read by \"gpud run --api-tokens-file\"",
+			Value: rbac.DefaultTokensFile,
+		},
+		&cli.StringFlag{
+			Name:  "output-format",
+			Usage: "set the output format [plain, json]",
+			Value: gpudcommon.OutputFormatPlain,
+		},
+	}
+
 	app.Commands = []cli.Command{
 		{
 			Name:  "up",
@@ -150,6 +173,16 @@ sudo rm /etc/systemd/system/gpud.service
 			Usage:  "starts gpud without any login/checkin ('gpud up' is recommended for linux) -- if --token is provided, it will perform login",
 			Action: cmdrun.Command,
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "config",
+					Usage: "set the base config file of the flags, keyed by the flag names (skipped if not exist, the command line flags take precedence)",
+					Value: pkgconfig.DefaultConfigFile,
+				},
+				&cli.StringFlag{
+					Name:  "config-dir",
+					Usage: "set the drop-in directory of the config files (*.yaml), merged on top of the base config file in the lexical order",
+					Value: pkgconfig.DefaultConfigDir,
+				},
 				&cli.StringFlag{
 					Name:  "data-dir",
 					Usage: "set the data directory for GPUd state and packages (default: /var/lib/gpud or ~/.gpud for non-root)",
@@ -191,6 +224,28 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "pprof",
 					Usage: "enable pprof (default: false)",
 				},
+				&cli.BoolFlag{
+					Name:  "debug-snapshot",
+					Usage: "serve the deterministic snapshot of the internal state at /v1/debug/snapshot, for the integration tests and the bug reports (default: false)",
+				},
+				&cli.BoolFlag{
+					Name:  "validate-contracts",
+					Usage: "validate the component health states and events against the data contracts, rejecting the malformed ones (default: false)",
+				},
+				&cli.BoolFlag{
+					Name:  "startup-self-test",
+					Usage: "run the startup self-test (state database, NVML, clock, disk space, kmsg) before declaring the readiness, reporting the failures through /readyz (default: false)",
+				},
+				&cli.Int64Flag{
+					Name:  "max-request-body-bytes",
+					Usage: "set the maximum size of the API request bodies, larger requests are rejected with 413",
+					Value: pkgconfig.DefaultMaxRequestBodyBytes,
+				},
+				&cli.BoolFlag{
+					Name:   "container",
+					Usage:  "run in the container mode (e.g., Kubernetes DaemonSet) without systemd, and report the missing host namespaces and mounts (default: auto-detected)",
+					EnvVar: "GPUD_CONTAINER",
+				},
 				&cli.BoolTFlag{
 					Name:  "enable-auto-update",
 					Usage: "enable auto update of gpud (default: true)",
@@ -209,6 +264,15 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "sets the plugin specs file (leave empty for default) -- if the file does not exist, gpud does not install/run any plugin, and updated configuration requires an gpud restart)",
 					Value: pkgcustomplugins.DefaultPluginSpecsFile,
 				},
+				cli.StringFlag{
+					Name:  "plugin-secrets-file",
+					Usage: "sets the plugin secrets file, which must be owned by root with no group or other permissions (leave empty for default) -- if the file does not exist, gpud does not inject any secret into the plugin steps",
+					Value: pkgcustomplugins.DefaultPluginSecretsFile,
+				},
+				&cli.BoolFlag{
+					Name:  "enable-external-components",
+					Usage: "enables the external components (out-of-process plugins) to register over the unix socket under the data directory",
+				},
 				cli.StringFlag{
 					Name:  "components",
 					Usage: "sets the components to enable (comma-separated, leave empty for default to enable all components, set 'none' or any other non-matching value to disable all components, prefix component name with '-' to disable it)",
@@ -230,6 +294,10 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "set the time period to retain component events for (once elapsed, old events are purged from the event store)",
 					Value: pkgconfig.DefaultEventsRetentionPeriod.Duration,
 				},
+				&cli.StringFlag{
+					Name:  "events-retention-policy",
+					Usage: `set the events retention per event type and per component in JSON, overriding the events retention period (leave empty for none, e.g., {"by_bucket":{"accelerator-nvidia-error-xid":"2160h","accelerator-nvidia-error-sxid":"2160h"},"by_type":{"Info":"168h"}})`,
+				},
 
 				&cli.IntFlag{
 					Name:  "gpu-count",
@@ -244,15 +312,153 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "nvlink-expected-link-states",
 					Usage: "set the nvlink expected link states in JSON (leave empty for default, useful for testing)",
 				},
+				&cli.StringFlag{
+					Name:  "expected-clocks",
+					Usage: `set the expected rated max clocks of the GPU products in JSON, below which the GPUs are degraded, where the first case-insensitive substring match of the product name is used (leave empty for the default A100 SXM, H100 SXM, and H200 graphics clocks, e.g., [{"product_name":"H100 80GB HBM3","min_graphics_mhz":1980}])`,
+				},
 				&cli.StringFlag{
 					Name:  "nfs-checker-configs",
 					Usage: "set the NFS checker group configs in JSON (leave empty for default, useful for testing)",
 				},
+				&cli.StringFlag{
+					Name:  "gds-probe-config",
+					Usage: `set the GPUDirect Storage cuFile probe config in JSON (leave empty to disable the probe, e.g., {"dir":"/mnt/gds","gpu_index":0})`,
+				},
+				&cli.StringFlag{
+					Name:  "gpudirect-rdma-probe-config",
+					Usage: `set the GPUDirect RDMA perftest ("ib_write_bw") loopback probe config in JSON, run at most hourly (leave empty to disable the probe, e.g., {"enabled":true,"device":"mlx5_0","gpu_index":0,"duration":"5s","min_bandwidth_gbps":300})`,
+				},
+				&cli.StringFlag{
+					Name:  "bmc-config",
+					Usage: `set the BMC Redfish endpoint and credentials in JSON (leave empty to only use the local "ipmitool", e.g., {"endpoint":"https://10.0.0.10","username":"admin","password_file":"/etc/gpud/bmc-password","insecure_skip_verify":true})`,
+				},
+				&cli.StringFlag{
+					Name:  "crash-dump-config",
+					Usage: `set the NVIDIA bug report collection on the driver crashes (Xid 79, kernel oops, "Unknown Error") in JSON (leave empty for the defaults of the "crash-dumps" directory under the data directory, e.g., {"dir":"/var/lib/gpud/crash-dumps","cooldown":"2h","max_bundles":3} or {"disabled":true})`,
+				},
+				&cli.StringFlag{
+					Name:  "memory-config",
+					Usage: `set the memory swap, pressure (PSI), and hugepage thresholds in JSON (leave empty for the defaults, set a negative threshold to disable the check, e.g., {"swap_used_percent_threshold":50,"pressure_full_avg60_threshold":5,"hugepages_used_percent_threshold":95,"expected_thp_mode":"madvise"})`,
+				},
+				&cli.StringFlag{
+					Name:   "postgres-dsn",
+					Usage:  `set the shared PostgreSQL database to store the metrics and events instead of the local state file (e.g., "postgres://gpud@db.internal:5432/gpud?sslmode=verify-full"), the machine identity and credentials stay in the local state file`,
+					EnvVar: "GPUD_POSTGRES_DSN",
+				},
+				&cli.StringFlag{
+					Name:  "gpu-idle-config",
+					Usage: `set the GPU idle detection and the hooks on the idle transitions in JSON (leave empty for the defaults of 5% utilization for 1 hour without hooks, e.g., {"utilization_threshold_percent":5,"idle_duration":"2h","hooks":{"power_limit_watts":200,"notify_control_plane":true}})`,
+				},
+				&cli.StringFlag{
+					Name:  "io-latency-probe-configs",
+					Usage: `set the IO latency probe paths and thresholds in JSON (leave empty to disable the probes, e.g., [{"path":"/mnt/scratch","p99_threshold":"500ms"}])`,
+				},
+				&cli.StringFlag{
+					Name:  "metrics-anomaly-config",
+					Usage: `set the anomaly detection against the recent baseline of each metric series in JSON (leave empty for the GPU temperature, power, and InfiniBand/NVLink error rates at 4 standard deviations, e.g., {"zscore_threshold":5,"series":[{"name":"accelerator_nvidia_temperature_current_celsius","min_deviation":3}]})`,
+				},
+				&cli.StringFlag{
+					Name:  "bandwidth-asymmetry-config",
+					Usage: `set the detection of the GPUs with the NVLink or PCIe throughput consistently below the median of the peer GPUs in JSON (leave empty for 70% of the peer median in 80% of the active samples within 30 minutes, e.g., {"window":"1h","min_ratio":0.8})`,
+				},
+				&cli.StringFlag{
+					Name:  "pcie-config",
+					Usage: `set the PCIe link generation and width the GPUs are expected to train to in JSON, in addition to the device maximum (leave empty to only compare against the device maximum, e.g., {"expected_generation":5,"expected_width":16})`,
+				},
+				&cli.StringFlag{
+					Name:  "network-reachability-config",
+					Usage: `set the additional outbound endpoints to check along with the listen port, the control plane, and the update server in JSON (leave empty for the defaults, e.g., {"endpoints":["https://registry.example.com"],"skip_update_server":true,"timeout":"5s"})`,
+				},
+				&cli.StringFlag{
+					Name:  "service-supervisor-config",
+					Usage: `set the opt-in restarts of the dead nvidia-persistenced, nvidia-fabricmanager, and DCGM services in JSON (leave empty to disable, up to 3 restarts per service per hour if enabled, e.g., {"enabled":true,"services":["nvidia-persistenced","nvidia-fabricmanager"],"max_restarts_per_hour":2})`,
+				},
+				&cli.StringFlag{
+					Name:  "kernel-counters-config",
+					Usage: `set the sysfs/procfs counters to collect as the metrics in JSON, with the path globs and the "int", "float", or "regex" parse rules, exported with the "kernel_counters_" prefix (leave empty to disable, e.g., {"metrics":[{"name":"ib_port_rcv_data","path":"/sys/class/infiniband/*/ports/*/counters/port_rcv_data","path_regex":"/infiniband/(?P<device>[^/]+)/ports/(?P<port>[0-9]+)/","parse":"int"}]})`,
+				},
+				&cli.StringFlag{
+					Name:  "api-rbac-config",
+					Usage: `set the role-based access control for the API endpoints in JSON, roles are "viewer", "operator", and "admin" (e.g., {"tokens":[{"name":"ops","sha256":"<hex digest of the token>","role":"operator"}],"client_ca_file":"/etc/gpud/ca.pem","anonymous_role":"viewer"})`,
+				},
+				&cli.StringFlag{
+					Name:  "api-tokens-file",
+					Usage: "set the file of the scoped API tokens managed by \"gpud tokens\", reloaded on change (enables the role-based access control if set, skipped if not exist)",
+				},
+				&cli.StringFlag{
+					Name:  "api-rate-limit-config",
+					Usage: `set the per-client IP rate limiting and the concurrent request cap for the API endpoints in JSON, "/healthz" is always exempted (e.g., {"requests_per_second":5,"burst":10,"max_concurrent_requests":16,"exempt_paths":["/metrics"]})`,
+				},
+				&cli.StringFlag{
+					Name:  "session-upload-config",
+					Usage: `set the periodic upload of the metrics and events to the control plane in JSON, compression is one of "none", "gzip", and "zstd", eviction_policy is one of "drop-oldest" and "drop-newest" require_ack keeps the sent batches queued until acknowledged by the control plane (leave empty to only send on the control plane requests, e.g., {"interval":"5m","max_batch_size":5000,"compression":"zstd","queue_max_bytes":268435456,"eviction_policy":"drop-oldest","require_ack":true,"ack_timeout":"5m"})`,
+				},
+				&cli.StringFlag{
+					Name:  "gossip-config",
+					Usage: `set the peer-to-peer gossip of the health summaries between the GPUd instances in JSON, for every node to serve "/v1/cluster/summary" without the control plane, secret_key is the required base64-encoded AES key of 16, 24, or 32 bytes shared by the peers (leave empty to disable, e.g., {"bind_port":7946,"join":["10.0.0.2","10.0.0.3:7946"],"secret_key":"<base64 key>","interval":"30s"})`,
+				},
+				&cli.StringFlag{
+					Name:  "slo-config",
+					Usage: `set the latency objectives of the component checks and the API in JSON, served from "/v1/slo" with the violations recorded as the events, kind is one of "component_check" (match is the component name glob) and "api" (match is the path prefix), window defaults to 1h (leave empty to disable, e.g., {"objectives":[{"name":"nvidia-checks","kind":"component_check","match":"accelerator-nvidia-*","threshold":"2s","target":0.99},{"name":"api","kind":"api","match":"/v1/","threshold":"100ms","target":0.99}]})`,
+				},
+				&cli.StringFlag{
+					Name:  "metrics-export-config",
+					Usage: `set the regular expressions of the metric family names to export on "/metrics" in JSON, deny takes precedence over allow (leave empty to export all, e.g., {"allow":["gpud_.*","accelerator_nvidia_.*"],"deny":[".*_bucket"]})`,
+				},
+				&cli.StringFlag{
+					Name:  "data-budget-config",
+					Usage: `set the per-node data budget in JSON, where the info, warning, and unknown events are sampled over the events cap, each metric series is stored at the lower resolution over the metric samples cap, and only the critical and fatal events are uploaded over the upload cap, zero to not cap (leave empty to disable, e.g., {"events_per_hour":1000,"metric_samples_per_minute":5000,"upload_bytes_per_hour":10485760})`,
+				},
+				&cli.StringFlag{
+					Name:  "load-shedding-config",
+					Usage: `set the load shedding in JSON, where the expensive component checks (e.g., nvidia-smi, ibstat, custom plugins) are deferred while the "some avg10" CPU or IO pressure stall is at or over the threshold in percent, resumed once under 80% of the thresholds, cpu defaults to 80, io defaults to 60, negative to not shed on the resource (leave empty to disable, e.g., {"cpu_some_avg10_threshold":90,"io_some_avg10_threshold":-1,"interval":"10s"})`,
+				},
+				&cli.StringFlag{
+					Name:  "drain-readiness-config",
+					Usage: `set the names of the custom plugins that must be healthy for "/v1/drain-readiness" to report the node ready for the maintenance in JSON, in addition to the built-in checks of the GPU processes, the GPU resets in progress, and the NVSwitch partitions (leave empty for the built-in checks only, e.g., {"plugins":["no-running-jobs"]})`,
+				},
+				&cli.StringFlag{
+					Name:  "driver-upgrade-config",
+					Usage: `set the custom plugins checked along with the NVIDIA components once an NVIDIA driver install, upgrade, or reload is detected, and the wait before the checks, in JSON, with the result recorded as the "driver_upgrade_validated" or "driver_upgrade_failed" event (leave empty to check the NVIDIA components after 30 seconds, e.g., {"plugins":["cuda-sample"],"settle_period":"1m"})`,
+				},
+				&cli.StringFlag{
+					Name:  "gpu-performance-score-config",
+					Usage: `set the rolling window, the weights, and the error saturations of the per-GPU performance score in "/v1/gpus" and the "gpud_gpu_performance_score" metric in JSON (leave empty for the defaults, e.g., {"window":"1h","weights":{"capacity":0.2,"throttle":0.35,"ecc":0.25,"nvlink":0.2}})`,
+				},
+				&cli.StringFlag{
+					Name:  "sqlite-config",
+					Usage: `set the busy timeout, the synchronous mode, the WAL autocheckpoint pages, and the busy retries of the state database in JSON (leave empty for the defaults, e.g., {"busy_timeout":"10s","synchronous":"NORMAL","wal_autocheckpoint":1000,"max_busy_retries":2})`,
+				},
+				&cli.BoolFlag{
+					Name:  "chaos",
+					Usage: "(developer only) enable the chaos mode that randomly injects the internal failures (SQLite write errors, NVML timeouts, control plane disconnects, plugin timeouts) with the default probabilities, never enable in production",
+				},
+				&cli.StringFlag{
+					Name:  "chaos-config",
+					Usage: `(developer only) set the chaos mode failure probabilities in JSON, implies --chaos (e.g., {"seed":1,"sqlite_write_error":0.01,"nvml_timeout":0.05,"nvml_timeout_delay":"5s","control_plane_disconnect":0.02,"control_plane_check_interval":"1m","plugin_timeout":0.1})`,
+				},
+				&cli.StringFlag{
+					Name:  "maintenance-windows",
+					Usage: `set the scheduled maintenance windows in JSON, during which the health states and events are tagged as maintenance (leave empty for none, e.g., [{"id":"kernel-upgrade","start":"2025-01-01T00:00:00Z","end":"2025-01-01T02:00:00Z","components":["os"]}])`,
+				},
+				&cli.StringFlag{
+					Name:  "component-health-hysteresis",
+					Usage: `set the per-component health state hysteresis in JSON keyed by the component name, "*" applies to all other components (e.g., {"*":{"failure_threshold":3,"recovery_threshold":2}})`,
+				},
+				&cli.StringFlag{
+					Name:  "component-adaptive-check-interval",
+					Usage: `set the per-component check intervals adapted to the health in JSON keyed by the component name, "*" applies to all other components (e.g., {"*":{"max_interval":"10m","failing_interval":"15s"}})`,
+				},
 				&cli.IntFlag{
 					Name:  "xid-reboot-threshold",
 					Usage: fmt.Sprintf("set the allowed reboot attempts for XID errors before escalation (defaults to %d)", componentsxid.DefaultRebootThreshold),
 					Value: componentsxid.DefaultRebootThreshold,
 				},
+				&cli.IntFlag{
+					Name:  "event-disposition-threshold",
+					Usage: fmt.Sprintf("set the number of the benign or false-positive operator dispositions (since the last true positive) to downgrade the events of a kind (e.g., an Xid code) on this GPU product and driver version, -1 to disable the downgrades (defaults to %d)", disposition.DefaultThreshold),
+					Value: disposition.DefaultThreshold,
+				},
 				&cli.DurationFlag{
 					Name:  "xid-lookback-period",
 					Usage: "set the lookback period for XID errors",
@@ -263,6 +469,14 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "set the lookback period for SXID errors",
 					Value: componentssxid.DefaultLookbackPeriod,
 				},
+				&cli.StringFlag{
+					Name:  "gsp-firmware-policy",
+					Usage: `set the site policy of the NVIDIA GSP firmware mode to flag the GPUs against, "enabled" or "disabled" (leave empty to not require any mode, e.g., "disabled" for the sites disabling the GSP firmware on the recurring GSP Xids)`,
+				},
+				&cli.StringFlag{
+					Name:  "cuda-userland-manifest",
+					Usage: `set the manifest file (YAML or JSON) of the expected SHA-256 checksums of the CUDA library files to verify (e.g., {"files":[{"path":"/usr/lib/x86_64-linux-gnu/libcudnn.so.8.9.7","sha256":"..."}]}, leave empty to not verify)`,
+				},
 				&cli.IntFlag{
 					Name:  "threshold-celsius-slowdown-margin",
 					Usage: fmt.Sprintf("set the minimum thermal margin (°C) before marking GPUs as degraded (defaults to %d)", componentsnvidiatemperature.ThresholdCelsiusSlowdownMargin),
@@ -335,6 +549,11 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage:  "(testing purposes) simulate containerd socket file not existing. Use to test the consecutive socket missing threshold logic that reports healthy for first few checks before reporting unhealthy.",
 					Hidden: true, // only for testing
 				},
+				cli.StringFlag{
+					Name:   "gpu-field-overrides",
+					Usage:  `(testing purposes) fake NVML values per GPU UUID in JSON (e.g., '{"GPU-xxx":{"temperature_gpu":95,"ecc_uncorrected_total":3,"remapped_rows":{"uncorrectable":2,"pending":true}}}'). Use to validate alerting end-to-end without harming the hardware.`,
+					Hidden: true, // only for testing
+				},
 			},
 		},
 		{
@@ -535,7 +754,7 @@ sudo rm /etc/systemd/system/gpud.service
 		{
 			Name:    "status",
 			Aliases: []string{"st"},
-			Usage:   "checks the status of gpud",
+			Usage:   "checks the status of gpud (e.g., uptime, control plane session, component health, last fatal event)",
 			Action:  cmdstatus.Command,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
@@ -550,6 +769,154 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "watch,w",
 					Usage: "watch for package install status",
 				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "output the gpud status in JSON (requires the gpud server running)",
+				},
+			},
+		},
+		{
+			Name:   "logs",
+			Usage:  "tails the gpud logs (e.g., gpud logs -f --component accelerator-nvidia-xid)",
+			Action: cmdlogs.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.BoolFlag{
+					Name:  "follow,f",
+					Usage: "follow the new log entries",
+				},
+				&cli.StringFlag{
+					Name:  "component,c",
+					Usage: "only show the logs of the component (e.g., accelerator-nvidia-xid)",
+				},
+				&cli.StringFlag{
+					Name:  "level",
+					Usage: "only show the logs at or above the level [debug, info, warn, error] (default: info)",
+				},
+				&cli.IntFlag{
+					Name:  "lines,n",
+					Usage: "number of the recent log entries to show (0 for all kept in memory)",
+					Value: 100,
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "output the log entries in JSON lines",
+				},
+			},
+		},
+		{
+			Name:   "events",
+			Usage:  "queries the component events (e.g., gpud events --since 24h --type fatal --component accelerator-nvidia-xid)",
+			Action: cmdevents.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.DurationFlag{
+					Name:  "since",
+					Usage: "show the events since the duration ago",
+					Value: 24 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:  "type,t",
+					Usage: "only show the events of the types (comma-separated) [info, warning, critical, fatal] (default: all)",
+				},
+				&cli.StringFlag{
+					Name:  "component,c",
+					Usage: "only show the events of the components (comma-separated, e.g., accelerator-nvidia-xid)",
+				},
+				&cli.BoolFlag{
+					Name:  "follow,f",
+					Usage: "follow the new events",
+				},
+				&cli.DurationFlag{
+					Name:  "interval",
+					Usage: "interval to poll the new events with --follow",
+					Value: 5 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "output the events in JSON lines",
+				},
+				&cli.BoolFlag{
+					Name:  "no-color",
+					Usage: "disable the colors by the event type (disabled if not a terminal or NO_COLOR is set)",
+				},
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "inspect the layered config files of the \"gpud run\" flags",
+			Subcommands: []cli.Command{
+				{
+					Name:   "render",
+					Usage:  "render the effective config merged from the base config file and the drop-in config files",
+					Action: cmdconfig.CommandRender,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "config",
+							Usage: "set the base config file",
+							Value: pkgconfig.DefaultConfigFile,
+						},
+						&cli.StringFlag{
+							Name:  "config-dir",
+							Usage: "set the drop-in directory of the config files",
+							Value: pkgconfig.DefaultConfigDir,
+						},
+						&cli.StringFlag{
+							Name:  "output-format",
+							Usage: "set the output format [plain, json]",
+							Value: gpudcommon.OutputFormatPlain,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "tokens",
+			Usage: "manage the scoped API tokens (read-only, trigger, admin) in the tokens file",
+			Subcommands: []cli.Command{
+				{
+					Name:   "create",
+					Usage:  "create a token and print it once",
+					Action: cmdtokens.CommandCreate,
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "set the token name, shown in the access logs",
+						},
+						&cli.StringFlag{
+							Name:  "scope",
+							Usage: "set the token scope [read-only, trigger, admin] (or the role names viewer, operator, admin)",
+							Value: "read-only",
+						},
+						&cli.DurationFlag{
+							Name:  "expires-in",
+							Usage: "set the token expiry from now (e.g., 720h), never expires if zero",
+						},
+					}, tokensFlags...),
+				},
+				{
+					Name:   "list",
+					Usage:  "list the tokens without the token values",
+					Action: cmdtokens.CommandList,
+					Flags:  tokensFlags,
+				},
+				{
+					Name:   "revoke",
+					Usage:  "revoke a token, rejected by the running GPUd on the next request",
+					Action: cmdtokens.CommandRevoke,
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "set the token name to revoke",
+						},
+					}, tokensFlags...),
+				},
 			},
 		},
 		{
@@ -567,6 +934,85 @@ sudo rm /etc/systemd/system/gpud.service
 				},
 			},
 		},
+		{
+			Name:   "migrate",
+			Usage:  "apply the pending schema migrations to the GPUd state database, also applied when GPUd starts (GPUd must be stopped unless --check)",
+			Action: cmdmigrate.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "data-dir",
+					Usage: "set the data directory for GPUd state and packages (default: /var/lib/gpud or ~/.gpud for non-root)",
+				},
+				&cli.BoolFlag{
+					Name:  "check",
+					Usage: "validate the pending schema migrations apply cleanly without applying them (dry run against a snapshot, the state file is only read)",
+				},
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+			},
+		},
+		{
+			Name:   "migrate-db",
+			Usage:  "migrate the metrics, events, and metadata in the GPUd state database to the shared PostgreSQL database (GPUd must be stopped)",
+			Action: cmdmigratedb.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "data-dir",
+					Usage: "set the data directory for GPUd state and packages (default: /var/lib/gpud or ~/.gpud for non-root)",
+				},
+				&cli.StringFlag{
+					Name:   "postgres-dsn",
+					Usage:  `set the shared PostgreSQL database to migrate to (e.g., "postgres://gpud@db.internal:5432/gpud?sslmode=verify-full")`,
+					EnvVar: "GPUD_POSTGRES_DSN",
+				},
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+			},
+		},
+		{
+			Name:   "verify-install",
+			Usage:  "verifies the gpud installation (binary signature, systemd unit, file permissions, and SELinux/AppArmor compatibility)",
+			Action: cmdverifyinstall.Command,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "log-level,l",
+					Usage: "set the logging level [debug, info, warn, error, fatal, panic, dpanic]",
+				},
+				&cli.StringFlag{
+					Name:  "data-dir",
+					Usage: "set the data directory for GPUd state and packages (default: /var/lib/gpud or ~/.gpud for non-root)",
+				},
+				&cli.StringFlag{
+					Name:  "bin-path",
+					Usage: "set the installed gpud binary path to verify (default: /usr/local/bin/gpud or /usr/sbin/gpud)",
+				},
+				&cli.StringFlag{
+					Name:  "url",
+					Usage: "set the package server URL to download the signed release package of the running version",
+					Value: version.DefaultURLPrefix,
+				},
+				&cli.StringFlag{
+					Name:  "package-path",
+					Usage: "set the local release package path to verify the binary against (instead of downloading)",
+				},
+				&cli.StringFlag{
+					Name:  "sig-path",
+					Usage: "set the signature path of the local release package",
+				},
+				&cli.StringFlag{
+					Name:  "sign-pub-path",
+					Usage: "set the signing public key bundle path of the local release package",
+				},
+				&cli.BoolFlag{
+					Name:  "skip-signature",
+					Usage: "skip the binary signature verification (e.g., for the offline hosts)",
+				},
+			},
+		},
 		{
 			Name:    "scan",
 			Aliases: []string{"check", "s"},
@@ -583,6 +1029,14 @@ sudo rm /etc/systemd/system/gpud.service
 					Usage: "set the time period to retain component events for (once elapsed, old events are purged from the event store)",
 					Value: pkgconfig.DefaultEventsRetentionPeriod.Duration,
 				},
+				&cli.StringFlag{
+					Name:  "data-dir",
+					Usage: "set the data directory to store the scan results (default: /var/lib/gpud or ~/.gpud for non-root)",
+				},
+				&cli.BoolFlag{
+					Name:  "compare-last",
+					Usage: "print the changes since the last scan (new unhealthy components, new Xids, InfiniBand port rate changes)",
+				},
 
 				&cli.IntFlag{
 					Name:  "gpu-count",
@@ -753,6 +1207,10 @@ sudo rm /etc/systemd/system/gpud.service
 			UsageText: "gpud machine-info",
 			Action:    cmdmachineinfo.Command,
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "export the hardware inventory (GPUs, NICs, DIMMs, drives with the serials and firmware versions) for the asset-management systems [spdx, csv, json-asset] (leave empty for the machine info; reading the DIMMs requires root)",
+				},
 				&cli.StringFlag{
 					Name:  "data-dir",
 					Usage: "set the data directory for GPUd state and packages (default: /var/lib/gpud or ~/.gpud for non-root)",
@@ -770,7 +1228,7 @@ sudo rm /etc/systemd/system/gpud.service
 		},
 		{
 			Name:   "inject-fault",
-			Usage:  "injects a fault such as writing a kernel message to the kernel log",
+			Usage:  "injects a fault such as writing a kernel message or a synthetic Xid/SXid to the kernel log",
 			Action: cmdinjectfault.Command,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
@@ -786,6 +1244,14 @@ sudo rm /etc/systemd/system/gpud.service
 					Name:  "kernel-message",
 					Usage: "set the kernel message to inject",
 				},
+				&cli.IntFlag{
+					Name:  "xid",
+					Usage: "set the NVIDIA Xid to inject as a synthetic kernel message (overrides --kernel-message)",
+				},
+				&cli.IntFlag{
+					Name:  "sxid",
+					Usage: "set the NVIDIA NVSwitch SXid to inject as a synthetic kernel message (overrides --kernel-message)",
+				},
 			},
 		},
 		{
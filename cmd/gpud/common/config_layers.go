@@ -0,0 +1,57 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/urfave/cli"
+
+	pkgconfig "github.com/leptonai/gpud/pkg/config"
+)
+
+// ConfigLayersFromContext loads the base config file ("--config")
+// and the drop-in config directory ("--config-dir") in the increasing precedence.
+func ConfigLayersFromContext(cliContext *cli.Context) ([]pkgconfig.ConfigLayer, error) {
+	return pkgconfig.LoadConfigLayers(cliContext.String("config"), cliContext.String("config-dir"))
+}
+
+// ApplyConfigLayers sets the flags not set on the command line (or by the environment variables)
+// to the merged values of the layered config files, keyed by the flag names,
+// thus the precedence is the flag defaults, the base config file, the drop-in config files
+// in the lexical order, and the command line flags.
+// Returns the paths of the loaded config files.
+func ApplyConfigLayers(cliContext *cli.Context) ([]string, error) {
+	layers, err := ConfigLayersFromContext(cliContext)
+	if err != nil {
+		return nil, err
+	}
+	merged := pkgconfig.MergeConfigLayers(layers)
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == "config" || k == "config-dir" {
+			return nil, fmt.Errorf("config key %q cannot be set in the config files", k)
+		}
+		if cliContext.IsSet(k) {
+			continue
+		}
+		v, err := pkgconfig.ConfigFlagValue(merged[k])
+		if err != nil {
+			return nil, fmt.Errorf("invalid config key %q: %w", k, err)
+		}
+		if err := cliContext.Set(k, v); err != nil {
+			return nil, fmt.Errorf("invalid config key %q: %w", k, err)
+		}
+	}
+
+	paths := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		paths = append(paths, layer.Path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// runWithConfigLayers runs the app with the args, and returns the resolved flags.
+func runWithConfigLayers(t *testing.T, args ...string) (map[string]any, []string, error) {
+	resolved := make(map[string]any)
+	var paths []string
+
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "config"},
+		cli.StringFlag{Name: "config-dir"},
+		cli.StringFlag{Name: "listen-address", Value: "0.0.0.0:15132"},
+		cli.StringFlag{Name: "components"},
+		cli.StringFlag{Name: "bmc-config"},
+		cli.BoolFlag{Name: "pprof"},
+		cli.IntFlag{Name: "gpu-count"},
+	}
+	app.Action = func(cliContext *cli.Context) error {
+		var err error
+		paths, err = ApplyConfigLayers(cliContext)
+		if err != nil {
+			return err
+		}
+		resolved["listen-address"] = cliContext.String("listen-address")
+		resolved["components"] = cliContext.String("components")
+		resolved["bmc-config"] = cliContext.String("bmc-config")
+		resolved["pprof"] = cliContext.Bool("pprof")
+		resolved["gpu-count"] = cliContext.Int("gpu-count")
+		return nil
+	}
+	err := app.Run(append([]string{"gpud"}, args...))
+	return resolved, paths, err
+}
+
+func TestApplyConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	dropIn := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(dropIn, 0o755))
+	require.NoError(t, os.WriteFile(base, []byte(`
+listen-address: 127.0.0.1:15132
+components: [accelerator-nvidia-temperature, memory]
+bmc-config:
+  enabled: true
+gpu-count: 8
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dropIn, "10-node.yaml"), []byte(`
+pprof: true
+gpu-count: 4
+`), 0o644))
+
+	resolved, paths, err := runWithConfigLayers(t, "--config", base, "--config-dir", dropIn, "--gpu-count", "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{base, filepath.Join(dropIn, "10-node.yaml")}, paths)
+	assert.Equal(t, map[string]any{
+		"listen-address": "127.0.0.1:15132",
+		"components":     "accelerator-nvidia-temperature,memory",
+		"bmc-config":     `{"enabled":true}`,
+		"pprof":          true,
+		// the command line flag takes precedence
+		"gpu-count": 2,
+	}, resolved)
+
+	// no config file
+	resolved, paths, err = runWithConfigLayers(t, "--config", filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+	assert.Equal(t, "0.0.0.0:15132", resolved["listen-address"])
+}
+
+func TestApplyConfigLayersInvalidKey(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(base, []byte("listen-adress: 127.0.0.1:15132\n"), 0o644))
+	_, _, err := runWithConfigLayers(t, "--config", base)
+	assert.ErrorContains(t, err, `invalid config key "listen-adress"`)
+
+	require.NoError(t, os.WriteFile(base, []byte("config-dir: /tmp\n"), 0o644))
+	_, _, err = runWithConfigLayers(t, "--config", base)
+	assert.ErrorContains(t, err, "cannot be set in the config files")
+
+	require.NoError(t, os.WriteFile(base, []byte("gpu-count: eight\n"), 0o644))
+	_, _, err = runWithConfigLayers(t, "--config", base)
+	assert.ErrorContains(t, err, `invalid config key "gpu-count"`)
+}
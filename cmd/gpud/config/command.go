@@ -0,0 +1,102 @@
+// Package config implements the "config" command.
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+	"sigs.k8s.io/yaml"
+
+	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
+	pkgconfig "github.com/leptonai/gpud/pkg/config"
+)
+
+// RenderedConfig is the effective config merged from the config files.
+type RenderedConfig struct {
+	// Files are the loaded config files in the increasing precedence.
+	Files []string `json:"files"`
+	// Config is the merged config, keyed by the "gpud run" flag names.
+	Config map[string]any `json:"config"`
+}
+
+// CommandRender renders the effective config merged from the base config file
+// and the drop-in config files, as applied to the "gpud run" flags
+// not set on the command line.
+func CommandRender(cliContext *cli.Context) error {
+	outputFormat, err := gpudcommon.ParseOutputFormat(cliContext.String("output-format"))
+	if err != nil {
+		return err
+	}
+
+	layers, err := gpudcommon.ConfigLayersFromContext(cliContext)
+	if err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "config_load_failed", err)
+	}
+	rendered := RenderedConfig{
+		Files:  make([]string, 0, len(layers)),
+		Config: pkgconfig.MergeConfigLayers(layers),
+	}
+	for _, layer := range layers {
+		rendered.Files = append(rendered.Files, layer.Path)
+	}
+
+	if run := cliContext.App.Command("run"); run != nil {
+		if err := validateKeys(rendered.Config, run.Flags); err != nil {
+			return gpudcommon.WrapOutputError(outputFormat, "config_invalid", err)
+		}
+	}
+
+	if outputFormat == gpudcommon.OutputFormatJSON {
+		return gpudcommon.WriteJSON(rendered)
+	}
+	return writePlain(os.Stdout, rendered)
+}
+
+// validateKeys returns an error if any config key is not a flag name.
+func validateKeys(cfg map[string]any, flags []cli.Flag) error {
+	names := make(map[string]struct{})
+	for _, f := range flags {
+		for _, name := range strings.Split(f.GetName(), ",") {
+			names[strings.TrimSpace(name)] = struct{}{}
+		}
+	}
+
+	var unknown []string
+	for k := range cfg {
+		if _, ok := names[k]; !ok || k == "config" || k == "config-dir" {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("invalid config keys (not the \"gpud run\" flags): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func writePlain(w io.Writer, rendered RenderedConfig) error {
+	if len(rendered.Files) == 0 {
+		_, err := fmt.Fprintln(w, "# no config file found")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "# merged from (in the increasing precedence, overridden by the command line flags):"); err != nil {
+		return err
+	}
+	for _, f := range rendered.Files {
+		if _, err := fmt.Fprintf(w, "# - %s\n", f); err != nil {
+			return err
+		}
+	}
+
+	b, err := yaml.Marshal(rendered.Config)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
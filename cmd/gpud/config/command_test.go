@@ -0,0 +1,49 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestValidateKeys(t *testing.T) {
+	flags := []cli.Flag{
+		cli.StringFlag{Name: "config"},
+		cli.StringFlag{Name: "log-level,l"},
+		cli.StringFlag{Name: "metrics-retention-period, retention-period"},
+	}
+	assert.NoError(t, validateKeys(map[string]any{"log-level": "debug", "retention-period": "3h"}, flags))
+	assert.NoError(t, validateKeys(map[string]any{}, flags))
+
+	err := validateKeys(map[string]any{"log-levle": "debug", "config": "/tmp/x.yaml", "l": "info"}, flags)
+	assert.EqualError(t, err, `invalid config keys (not the "gpud run" flags): config, log-levle`)
+}
+
+func TestWritePlain(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writePlain(&buf, RenderedConfig{}))
+	assert.Equal(t, "# no config file found\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writePlain(&buf, RenderedConfig{
+		Files: []string{"/etc/gpud/config.yaml", "/etc/gpud/conf.d/10-node.yaml"},
+		Config: map[string]any{
+			"pprof":      true,
+			"components": []any{"memory", "-nfs"},
+			"bmc-config": map[string]any{"enabled": true},
+		},
+	}))
+	assert.Equal(t, `# merged from (in the increasing precedence, overridden by the command line flags):
+# - /etc/gpud/config.yaml
+# - /etc/gpud/conf.d/10-node.yaml
+bmc-config:
+  enabled: true
+components:
+- memory
+- -nfs
+pprof: true
+`, buf.String())
+}
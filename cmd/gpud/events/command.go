@@ -0,0 +1,247 @@
+// Package events implements the "events" command to query the GPUd component events.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorBold   = "\033[1m"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLogger(log.CreateLogger(zapLvl, ""))
+
+	log.Logger.Debugw("starting events command")
+
+	since := cliContext.Duration("since")
+	if since <= 0 {
+		return fmt.Errorf("invalid --since %v (must be positive)", since)
+	}
+	types, err := parseTypes(cliContext.String("type"))
+	if err != nil {
+		return err
+	}
+	interval := cliContext.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("invalid --interval %v (must be positive)", interval)
+	}
+
+	rootCtx, rootCancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer rootCancel()
+
+	gpudAddr := fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+
+	cctx, ccancel := context.WithTimeout(rootCtx, time.Minute)
+	err = clientv1.BlockUntilServerReady(cctx, gpudAddr)
+	ccancel()
+	if err != nil {
+		return err
+	}
+
+	var opts []clientv1.OpOption
+	for _, c := range splitComma(cliContext.String("component")) {
+		opts = append(opts, clientv1.WithComponent(c))
+	}
+
+	printJSON := cliContext.Bool("json")
+	color := !printJSON && !cliContext.Bool("no-color") && isTerminal(os.Stdout)
+
+	// dedup the events across the polls, as the query time is in seconds
+	seen := make(map[string]struct{})
+	startTime := time.Now().Add(-since)
+	poll := func() error {
+		cctx, ccancel := context.WithTimeout(rootCtx, 15*time.Second)
+		resp, err := clientv1.GetEvents(cctx, gpudAddr, append(opts, clientv1.WithStartTime(startTime))...)
+		ccancel()
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range filterEvents(resp, types) {
+			k := eventKey(ev)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			printEvent(os.Stdout, ev, printJSON, color)
+			if ev.Time.After(startTime) {
+				startTime = ev.Time.Time
+			}
+		}
+		return nil
+	}
+
+	if err := poll(); err != nil {
+		return err
+	}
+	if !cliContext.Bool("follow") {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rootCtx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := poll(); err != nil {
+			if rootCtx.Err() != nil {
+				return nil
+			}
+			log.Logger.Warnw("failed to get events", "error", err)
+		}
+	}
+}
+
+// parseTypes parses the comma-separated event types (case-insensitive),
+// and returns nil for all the types.
+func parseTypes(s string) (map[apiv1.EventType]struct{}, error) {
+	vs := splitComma(s)
+	if len(vs) == 0 {
+		return nil, nil
+	}
+
+	types := make(map[apiv1.EventType]struct{}, len(vs))
+	for _, v := range vs {
+		var t apiv1.EventType
+		for _, known := range []apiv1.EventType{apiv1.EventTypeInfo, apiv1.EventTypeWarning, apiv1.EventTypeCritical, apiv1.EventTypeFatal} {
+			if strings.EqualFold(v, string(known)) {
+				t = known
+				break
+			}
+		}
+		if t == "" {
+			return nil, fmt.Errorf("invalid event type %q (supported: info, warning, critical, fatal)", v)
+		}
+		types[t] = struct{}{}
+	}
+	return types, nil
+}
+
+func splitComma(s string) []string {
+	var vs []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// filterEvents flattens the component events of the types (nil for all the types),
+// with the component names set, sorted by the time in the ascending order (oldest first).
+func filterEvents(resp apiv1.GPUdComponentEvents, types map[apiv1.EventType]struct{}) []apiv1.Event {
+	var evs []apiv1.Event
+	for _, ce := range resp {
+		for _, ev := range ce.Events {
+			if types != nil {
+				if _, ok := types[ev.Type]; !ok {
+					continue
+				}
+			}
+			if ev.Component == "" {
+				ev.Component = ce.Component
+			}
+			evs = append(evs, ev)
+		}
+	}
+	sort.SliceStable(evs, func(i, j int) bool {
+		if evs[i].Time.Equal(&evs[j].Time) {
+			return evs[i].Component < evs[j].Component
+		}
+		return evs[i].Time.Before(&evs[j].Time)
+	})
+	return evs
+}
+
+func eventKey(ev apiv1.Event) string {
+	return strings.Join([]string{ev.Component, ev.Time.UTC().Format(time.RFC3339Nano), ev.Name, string(ev.Type), ev.Message}, "\x00")
+}
+
+// printEvent prints the event in a line, either as JSON
+// or as "<time> <type> <component> <name> <message>".
+func printEvent(w io.Writer, ev apiv1.Event, printJSON bool, color bool) {
+	if printJSON {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(w, string(b))
+		return
+	}
+
+	typ := fmt.Sprintf("%-8s", strings.ToUpper(string(ev.Type)))
+	if color {
+		typ = typeColor(ev.Type) + typ + colorReset
+	}
+
+	var sb strings.Builder
+	sb.WriteString(ev.Time.UTC().Format(time.RFC3339))
+	sb.WriteString(" ")
+	sb.WriteString(typ)
+	sb.WriteString(" ")
+	sb.WriteString(ev.Component)
+	sb.WriteString(" ")
+	sb.WriteString(ev.Name)
+	if ev.Message != "" {
+		sb.WriteString(": ")
+		sb.WriteString(ev.Message)
+	}
+	_, _ = fmt.Fprintln(w, sb.String())
+}
+
+func typeColor(t apiv1.EventType) string {
+	switch t {
+	case apiv1.EventTypeFatal:
+		return colorBold + colorRed
+	case apiv1.EventTypeCritical:
+		return colorRed
+	case apiv1.EventTypeWarning:
+		return colorYellow
+	case apiv1.EventTypeInfo:
+		return colorCyan
+	default:
+		return ""
+	}
+}
+
+// isTerminal returns true if the file is a terminal, and colors are not disabled by NO_COLOR.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestParseTypes(t *testing.T) {
+	types, err := parseTypes("")
+	require.NoError(t, err)
+	assert.Nil(t, types)
+
+	types, err = parseTypes("fatal, Critical")
+	require.NoError(t, err)
+	assert.Len(t, types, 2)
+	assert.Contains(t, types, apiv1.EventTypeFatal)
+	assert.Contains(t, types, apiv1.EventTypeCritical)
+
+	_, err = parseTypes("fatal,bad")
+	assert.Error(t, err)
+}
+
+func TestFilterEvents(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	resp := apiv1.GPUdComponentEvents{
+		{
+			Component: "accelerator-nvidia-xid",
+			Events: []apiv1.Event{
+				{Time: metav1.NewTime(t0.Add(time.Minute)), Name: "error_xid", Type: apiv1.EventTypeFatal},
+				{Time: metav1.NewTime(t0), Name: "error_xid", Type: apiv1.EventTypeWarning},
+			},
+		},
+		{
+			Component: "cpu",
+			Events: []apiv1.Event{
+				{Time: metav1.NewTime(t0.Add(30 * time.Second)), Name: "kernel", Type: apiv1.EventTypeInfo},
+			},
+		},
+	}
+
+	evs := filterEvents(resp, nil)
+	require.Len(t, evs, 3)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+	assert.Equal(t, "cpu", evs[1].Component)
+	assert.Equal(t, apiv1.EventTypeFatal, evs[2].Type)
+
+	evs = filterEvents(resp, map[apiv1.EventType]struct{}{apiv1.EventTypeFatal: {}})
+	require.Len(t, evs, 1)
+	assert.Equal(t, "accelerator-nvidia-xid", evs[0].Component)
+}
+
+func TestPrintEvent(t *testing.T) {
+	ev := apiv1.Event{
+		Component: "accelerator-nvidia-xid",
+		Time:      metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Name:      "error_xid",
+		Type:      apiv1.EventTypeFatal,
+		Message:   "XID 79 detected on GPU 0000:9b:00.0",
+	}
+
+	buf := bytes.NewBuffer(nil)
+	printEvent(buf, ev, false, false)
+	assert.Equal(t, "2025-01-02T03:04:05Z FATAL    accelerator-nvidia-xid error_xid: XID 79 detected on GPU 0000:9b:00.0\n", buf.String())
+
+	buf.Reset()
+	printEvent(buf, ev, false, true)
+	assert.Contains(t, buf.String(), colorBold+colorRed+"FATAL   "+colorReset)
+
+	buf.Reset()
+	printEvent(buf, ev, true, true)
+	assert.JSONEq(t, `{"component":"accelerator-nvidia-xid","time":"2025-01-02T03:04:05Z","name":"error_xid","type":"Fatal","message":"XID 79 detected on GPU 0000:9b:00.0"}`, buf.String())
+}
+
+func TestEventKey(t *testing.T) {
+	ev := apiv1.Event{Component: "cpu", Name: "kernel", Type: apiv1.EventTypeInfo}
+	other := ev
+	other.Message = "different"
+	assert.Equal(t, eventKey(ev), eventKey(ev))
+	assert.NotEqual(t, eventKey(ev), eventKey(other))
+}
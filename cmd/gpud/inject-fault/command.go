@@ -4,6 +4,7 @@ package injectfault
 import (
 	"github.com/urfave/cli"
 
+	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
 	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
 	"github.com/leptonai/gpud/pkg/log"
 )
@@ -18,16 +19,34 @@ func Command(cliContext *cli.Context) error {
 
 	log.Logger.Debugw("starting inject-fault command")
 
-	kernelLogLevel := cliContext.String("kernel-log-level")
-	kernelMsg := cliContext.String("kernel-message")
+	req := newRequest(cliContext.Int("xid"), cliContext.Int("sxid"), cliContext.String("kernel-log-level"), cliContext.String("kernel-message"))
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	log.Logger.Debugw("injecting kernel message", "priority", req.KernelMessage.Priority, "message", req.KernelMessage.Message)
 
 	wr := pkgkmsgwriter.NewWriter(pkgkmsgwriter.DefaultDevKmsg)
-	if err := wr.Write(&pkgkmsgwriter.KernelMessage{
-		Priority: pkgkmsgwriter.KernelMessagePriority(kernelLogLevel),
-		Message:  kernelMsg,
-	}); err != nil {
+	if err := wr.Write(req.KernelMessage); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// newRequest creates the fault injection request from the flags,
+// where the Xid takes precedence over the SXid and the kernel message.
+func newRequest(xid int, sxid int, kernelLogLevel string, kernelMsg string) *pkgfaultinjector.Request {
+	switch {
+	case xid != 0:
+		return &pkgfaultinjector.Request{XID: &pkgfaultinjector.XIDToInject{ID: xid}}
+	case sxid != 0:
+		return &pkgfaultinjector.Request{SXID: &pkgfaultinjector.SXIDToInject{ID: sxid}}
+	default:
+		return &pkgfaultinjector.Request{
+			KernelMessage: &pkgkmsgwriter.KernelMessage{
+				Priority: pkgkmsgwriter.KernelMessagePriority(kernelLogLevel),
+				Message:  kernelMsg,
+			},
+		}
+	}
+}
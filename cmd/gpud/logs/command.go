@@ -0,0 +1,109 @@
+// Package logs implements the "logs" command to tail the GPUd daemon logs.
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	clientv1 "github.com/leptonai/gpud/client/v1"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLogger(log.CreateLogger(zapLvl, ""))
+
+	log.Logger.Debugw("starting logs command")
+
+	rootCtx, rootCancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer rootCancel()
+
+	gpudAddr := fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+
+	cctx, ccancel := context.WithTimeout(rootCtx, time.Minute)
+	err = clientv1.BlockUntilServerReady(cctx, gpudAddr)
+	ccancel()
+	if err != nil {
+		return err
+	}
+
+	opts := []clientv1.OpOption{
+		clientv1.WithLogLines(cliContext.Int("lines")),
+	}
+	if component := cliContext.String("component"); component != "" {
+		opts = append(opts, clientv1.WithComponent(component))
+	}
+	if level := cliContext.String("level"); level != "" {
+		opts = append(opts, clientv1.WithLogLevel(level))
+	}
+
+	printJSON := cliContext.Bool("json")
+	printFunc := func(e apiv1.LogEntry) {
+		printEntry(os.Stdout, e, printJSON)
+	}
+
+	if cliContext.Bool("follow") {
+		return clientv1.FollowLogs(rootCtx, gpudAddr, printFunc, opts...)
+	}
+
+	cctx, ccancel = context.WithTimeout(rootCtx, 15*time.Second)
+	entries, err := clientv1.GetLogs(cctx, gpudAddr, opts...)
+	ccancel()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		printFunc(e)
+	}
+	return nil
+}
+
+// printEntry prints the log entry in a line, either as JSON
+// or as "<time> <level> <caller> <message> <key=value...>".
+func printEntry(w io.Writer, e apiv1.LogEntry, printJSON bool) {
+	if printJSON {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(w, string(b))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(e.Time.UTC().Format(time.RFC3339))
+	sb.WriteString(" ")
+	sb.WriteString(strings.ToUpper(e.Level))
+	if e.Caller != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.Caller)
+	}
+	sb.WriteString(" ")
+	sb.WriteString(e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, e.Fields[k])
+	}
+	_, _ = fmt.Fprintln(w, sb.String())
+}
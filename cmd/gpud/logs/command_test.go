@@ -0,0 +1,30 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestPrintEntry(t *testing.T) {
+	e := apiv1.LogEntry{
+		Time:    metav1.NewTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Level:   "warn",
+		Message: "xid found",
+		Caller:  "xid/component.go:123",
+		Fields:  map[string]any{"xid": 79, "deviceUUID": "GPU-1"},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	printEntry(buf, e, false)
+	assert.Equal(t, "2025-01-02T03:04:05Z WARN xid/component.go:123 xid found deviceUUID=GPU-1 xid=79\n", buf.String())
+
+	buf.Reset()
+	printEntry(buf, e, true)
+	assert.JSONEq(t, `{"time":"2025-01-02T03:04:05Z","level":"warn","message":"xid found","caller":"xid/component.go:123","fields":{"xid":79,"deviceUUID":"GPU-1"}}`, buf.String())
+}
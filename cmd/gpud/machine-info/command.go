@@ -27,12 +27,21 @@ func Command(cliContext *cli.Context) error {
 		return gpudcommon.WrapOutputError(outputFormat, code, srcErr)
 	}
 
+	// the inventory export replaces the machine info output
+	var inventoryFormat pkgmachineinfo.InventoryFormat
+	if s := cliContext.String("format"); s != "" {
+		inventoryFormat, err = pkgmachineinfo.ParseInventoryFormat(s)
+		if err != nil {
+			return wrapErr("invalid_inventory_format", err)
+		}
+	}
+
 	logLevel := cliContext.String("log-level")
 	zapLvl, err := log.ParseLogLevel(logLevel)
 	if err != nil {
 		return wrapErr("invalid_log_level", err)
 	}
-	if outputFormat == gpudcommon.OutputFormatJSON {
+	if outputFormat == gpudcommon.OutputFormatJSON || inventoryFormat != "" {
 		log.SetLogger(nil)
 	} else {
 		log.SetLogger(log.CreateLogger(zapLvl, ""))
@@ -72,7 +81,7 @@ func Command(cliContext *cli.Context) error {
 			return err
 		}
 
-		if outputFormat == gpudcommon.OutputFormatPlain {
+		if outputFormat == gpudcommon.OutputFormatPlain && inventoryFormat == "" {
 			fmt.Printf("GPUd machine ID: %q\n\n", machineID)
 		}
 	}
@@ -86,6 +95,13 @@ func Command(cliContext *cli.Context) error {
 	if err != nil {
 		return wrapErr("failed_to_get_machine_info", err)
 	}
+	if inventoryFormat != "" {
+		rootCtx, rootCancel := context.WithTimeout(context.Background(), 3*time.Minute)
+		defer rootCancel()
+		inv := pkgmachineinfo.GetInventory(rootCtx, machineID, machineInfo, nvmlInstance)
+		return wrapErr("failed_to_write_inventory", inv.Write(os.Stdout, inventoryFormat))
+	}
+
 	if outputFormat == gpudcommon.OutputFormatPlain {
 		machineInfo.RenderTable(os.Stdout)
 	}
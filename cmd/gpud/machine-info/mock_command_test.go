@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +39,7 @@ func newCLIContext(t *testing.T, args []string) *cli.Context {
 	_ = flags.String("log-level", "info", "")
 	_ = flags.String("state-file", "", "")
 	_ = flags.String("output-format", gpudcommon.OutputFormatPlain, "")
+	_ = flags.String("format", "", "")
 
 	require.NoError(t, flags.Parse(args))
 	return cli.NewContext(app, flags, nil)
@@ -635,3 +637,46 @@ func TestCommand_InvalidLogLevelJSON(t *testing.T) {
 		assert.NotEmpty(t, jerr.Error())
 	})
 }
+
+func TestCommand_InvalidInventoryFormat(t *testing.T) {
+	cliContext := newCLIContext(t, []string{"--format", "xml"})
+	err := Command(cliContext)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported inventory format")
+}
+
+func TestCommand_InventoryFormat(t *testing.T) {
+	mockey.PatchConvey("command inventory format", t, func() {
+		mockey.Mock(gpudcommon.StateFileFromContext).To(func(cliContext *cli.Context) (string, error) {
+			return "/nonexistent/state.db", nil
+		}).Build()
+
+		mockNVML := nvidianvml.NewNoOp()
+		mockey.Mock(nvidianvml.New).To(func() (nvidianvml.Instance, error) {
+			return mockNVML, nil
+		}).Build()
+
+		machineInfo := &apiv1.MachineInfo{Hostname: "gpu-node-1"}
+		mockey.Mock(pkgmachineinfo.GetMachineInfo).To(func(nvmlInstance nvidianvml.Instance) (*apiv1.MachineInfo, error) {
+			return machineInfo, nil
+		}).Build()
+		mockey.Mock(pkgmachineinfo.GetInventory).To(func(ctx context.Context, machineID string, info *apiv1.MachineInfo, nvmlInstance nvidianvml.Instance) *pkgmachineinfo.Inventory {
+			return pkgmachineinfo.BuildInventory(machineID, pkgmachineinfo.InventorySources{MachineInfo: info}, time.Now())
+		}).Build()
+		publicIPCalled := false
+		mockey.Mock(netutil.PublicIP).To(func() (string, error) {
+			publicIPCalled = true
+			return "", errors.New("no public IP")
+		}).Build()
+
+		cliContext := newCLIContext(t, []string{"--format", "json-asset"})
+		stdout, _ := captureOutput(t, func() {
+			require.NoError(t, Command(cliContext))
+		})
+
+		var inv pkgmachineinfo.Inventory
+		require.NoError(t, json.Unmarshal([]byte(stdout), &inv))
+		assert.Equal(t, "gpu-node-1", inv.Hostname)
+		assert.False(t, publicIPCalled)
+	})
+}
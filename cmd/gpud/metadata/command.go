@@ -17,6 +17,7 @@ import (
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
 	"github.com/leptonai/gpud/pkg/osutil"
 	"github.com/leptonai/gpud/pkg/sqlite"
 )
@@ -166,6 +167,10 @@ func loadRebootHistory(ctx context.Context, dbRO *sql.DB, stateFile string) ([]r
 		_ = dbRW.Close()
 	}()
 
+	// migrate the existing event tables before the event store reads them
+	if _, err := pkgmigrations.Apply(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to apply schema migrations: %w", err)
+	}
 	eventStore, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open event store: %w", err)
@@ -0,0 +1,122 @@
+// Package migratedb implements the command to migrate the local GPUd state
+// to the shared PostgreSQL database.
+package migratedb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	"github.com/leptonai/gpud/pkg/netutil"
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/pkg/systemd"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLogger(log.CreateLogger(zapLvl, ""))
+
+	log.Logger.Debugw("starting migrate-db command")
+
+	dsn := cliContext.String("postgres-dsn")
+	if dsn == "" {
+		return fmt.Errorf("--postgres-dsn (or GPUD_POSTGRES_DSN) is required")
+	}
+
+	// the events recorded during the migration would be lost
+	if systemd.SystemctlExists() {
+		active, err := systemd.IsActive("gpud.service")
+		if err != nil {
+			return err
+		}
+		if active {
+			return fmt.Errorf("gpud is running (must be stopped before running migrate-db)")
+		}
+	}
+	if netutil.IsPortOpen(config.DefaultGPUdPort) {
+		return fmt.Errorf("gpud is running on port %d (must be stopped before running migrate-db)", config.DefaultGPUdPort)
+	}
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer rootCancel()
+
+	stateFile, err := gpudcommon.StateFileFromContext(cliContext)
+	if err != nil {
+		return fmt.Errorf("failed to get state file: %w", err)
+	}
+	dbRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true))
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer func() {
+		_ = dbRO.Close()
+	}()
+
+	pgDB, err := postgres.Open(rootCtx, dsn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = pgDB.Close()
+	}()
+
+	// migrate the existing shared tables before copying into them
+	appliedMigrations, err := pkgmigrations.ApplyPostgres(rootCtx, pgDB)
+	if err != nil {
+		return fmt.Errorf("failed to apply postgres schema migrations: %w", err)
+	}
+	for _, m := range appliedMigrations {
+		fmt.Printf("%s applied postgres schema migration %d %q\n", cmdcommon.CheckMark, m.Version, m.Name)
+	}
+
+	// same machine ID as "gpud run" uses to scope the shared tables
+	machineID, err := pkgmetadata.ReadMachineID(rootCtx, dbRO)
+	if err != nil {
+		return fmt.Errorf("failed to read machine id: %w", err)
+	}
+	if machineID == "" {
+		machineID = pkghost.MachineID()
+	}
+	log.Logger.Infow("migrating state file to postgres", "stateFile", stateFile, "machineID", machineID)
+
+	copiedMetadata, err := pkgmetadata.MirrorToPostgres(rootCtx, dbRO, pgDB, machineID)
+	if err != nil {
+		return fmt.Errorf("failed to migrate metadata: %w", err)
+	}
+	fmt.Printf("%s migrated %d metadata entries\n", cmdcommon.CheckMark, copiedMetadata)
+
+	copiedMetrics, err := pkgmetricsstore.MigrateSQLiteToPostgres(rootCtx, dbRO, pgDB, pkgmetricsstore.DefaultTableName, machineID)
+	if err != nil {
+		return fmt.Errorf("failed to migrate metrics: %w", err)
+	}
+	fmt.Printf("%s migrated %d metrics\n", cmdcommon.CheckMark, copiedMetrics)
+
+	copiedEvents, err := eventstore.MigrateSQLiteToPostgres(rootCtx, dbRO, pgDB, machineID)
+	if err != nil {
+		return fmt.Errorf("failed to migrate events: %w", err)
+	}
+	total := 0
+	for _, n := range copiedEvents {
+		total += n
+	}
+	fmt.Printf("%s migrated %d events from %d components\n", cmdcommon.CheckMark, total, len(copiedEvents))
+
+	fmt.Printf("%s successfully migrated state file to postgres (run gpud with --postgres-dsn to use it)\n", cmdcommon.CheckMark)
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package migrate implements the command to apply or check the schema migrations
+// of the GPUd state database.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
+	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	"github.com/leptonai/gpud/pkg/netutil"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/pkg/systemd"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLogger(log.CreateLogger(zapLvl, ""))
+
+	log.Logger.Debugw("starting migrate command")
+
+	// the check only reads the state file, thus safe while gpud is running
+	check := cliContext.Bool("check")
+	if !check {
+		if systemd.SystemctlExists() {
+			active, err := systemd.IsActive("gpud.service")
+			if err != nil {
+				return err
+			}
+			if active {
+				return fmt.Errorf("gpud is running (must be stopped before running migrate, or use --check)")
+			}
+		}
+		if netutil.IsPortOpen(config.DefaultGPUdPort) {
+			return fmt.Errorf("gpud is running on port %d (must be stopped before running migrate, or use --check)", config.DefaultGPUdPort)
+		}
+	}
+
+	rootCtx, rootCancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer rootCancel()
+
+	stateFile, err := gpudcommon.StateFileFromContext(cliContext)
+	if err != nil {
+		return fmt.Errorf("failed to get state file: %w", err)
+	}
+
+	if check {
+		dbRO, err := sqlite.Open(stateFile, sqlite.WithReadOnly(true))
+		if err != nil {
+			return fmt.Errorf("failed to open state file: %w", err)
+		}
+		defer func() {
+			_ = dbRO.Close()
+		}()
+
+		pending, err := pkgmigrations.Check(rootCtx, dbRO)
+		if err != nil {
+			return fmt.Errorf("failed to check schema migrations: %w", err)
+		}
+		if len(pending) == 0 {
+			fmt.Printf("%s no pending schema migrations\n", cmdcommon.CheckMark)
+			return nil
+		}
+		for _, m := range pending {
+			fmt.Printf("%s pending schema migration %d %q applies cleanly\n", cmdcommon.CheckMark, m.Version, m.Name)
+		}
+		fmt.Printf("%s successfully checked %d pending schema migration(s) (none applied)\n", cmdcommon.CheckMark, len(pending))
+		return nil
+	}
+
+	dbRW, err := sqlite.Open(stateFile)
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer func() {
+		_ = dbRW.Close()
+	}()
+
+	applied, err := pkgmigrations.Apply(rootCtx, dbRW)
+	for _, m := range applied {
+		fmt.Printf("%s applied schema migration %d %q\n", cmdcommon.CheckMark, m.Version, m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply schema migrations: %w", err)
+	}
+	fmt.Printf("%s successfully applied %d schema migration(s)\n", cmdcommon.CheckMark, len(applied))
+	return nil
+}
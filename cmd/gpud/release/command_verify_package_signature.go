@@ -27,7 +27,15 @@ func CommandVerifyPackageSignature(cliContext *cli.Context) error {
 
 	log.Logger.Debugw("starting verify-package-signature command")
 
-	signPubPath := cliContext.String("sign-pub-path")
+	if err := VerifyPackageSignature(cliContext.String("sign-pub-path"), cliContext.String("package-path"), cliContext.String("sig-path")); err != nil {
+		return err
+	}
+	fmt.Println("signature ok")
+	return nil
+}
+
+// VerifyPackageSignature verifies the package signature with the public signing keys in the bundle.
+func VerifyPackageSignature(signPubPath string, packagePath string, sigPath string) error {
 	signPubBundle, err := os.ReadFile(signPubPath)
 	if err != nil {
 		return err
@@ -36,7 +44,6 @@ func CommandVerifyPackageSignature(cliContext *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("parsing %q: %w", signPubPath, err)
 	}
-	packagePath := cliContext.String("package-path")
 	pkg, err := os.Open(packagePath)
 	if err != nil {
 		return err
@@ -51,7 +58,6 @@ func CommandVerifyPackageSignature(cliContext *cli.Context) error {
 		return fmt.Errorf("reading %q: %w", packagePath, err)
 	}
 	hash := binary.LittleEndian.AppendUint64(pkgHash.Sum(nil), uint64(pkgHash.Len()))
-	sigPath := cliContext.String("sig-path")
 	sig, err := os.ReadFile(sigPath)
 	if err != nil {
 		return err
@@ -59,6 +65,5 @@ func CommandVerifyPackageSignature(cliContext *cli.Context) error {
 	if !distsign.VerifyAny(signPubs, hash, sig) {
 		return errors.New("signature not valid")
 	}
-	fmt.Println("signature ok")
 	return nil
 }
@@ -18,27 +18,64 @@ import (
 
 	"github.com/leptonai/gpud/cmd/gpud/common"
 	gpudcomponents "github.com/leptonai/gpud/components"
+	componentsnvidiabandwidthasymmetry "github.com/leptonai/gpud/components/accelerator/nvidia/bandwidth-asymmetry"
+	componentsclockspeed "github.com/leptonai/gpud/components/accelerator/nvidia/clock-speed"
+	componentscrashdump "github.com/leptonai/gpud/components/accelerator/nvidia/crash-dump"
+	componentscudauserland "github.com/leptonai/gpud/components/accelerator/nvidia/cuda-userland"
+	componentsgds "github.com/leptonai/gpud/components/accelerator/nvidia/gds"
 	componentsnvidiagpucounts "github.com/leptonai/gpud/components/accelerator/nvidia/gpu-counts"
+	componentsgpudirectrdma "github.com/leptonai/gpud/components/accelerator/nvidia/gpudirect-rdma"
+	componentsgspfirmware "github.com/leptonai/gpud/components/accelerator/nvidia/gsp-firmware"
+	componentsnvidiaidle "github.com/leptonai/gpud/components/accelerator/nvidia/idle"
 	componentsinfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
 	componentsnvidiainfinibanditypes "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband/types"
 	componentsnvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiapcie "github.com/leptonai/gpud/components/accelerator/nvidia/pcie"
 	componentssxid "github.com/leptonai/gpud/components/accelerator/nvidia/sxid"
 	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
 	componentsxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	componentsbmc "github.com/leptonai/gpud/components/bmc"
+	componentsiolatency "github.com/leptonai/gpud/components/io-latency"
+	componentskernelcounters "github.com/leptonai/gpud/components/kernel-counters"
+	componentsmemory "github.com/leptonai/gpud/components/memory"
+	componentsmetricsanomaly "github.com/leptonai/gpud/components/metrics-anomaly"
+	componentsnetworkreachability "github.com/leptonai/gpud/components/network/reachability"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
+	componentsservicesupervisor "github.com/leptonai/gpud/components/service-supervisor"
+	"github.com/leptonai/gpud/pkg/budget"
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
 	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/drain"
+	"github.com/leptonai/gpud/pkg/driverupgrade"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gossip"
 	gpudmanager "github.com/leptonai/gpud/pkg/gpud-manager"
+	"github.com/leptonai/gpud/pkg/gpuscore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/loadshed"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/login"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
+	nvidianvmldevice "github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
 	gpudserver "github.com/leptonai/gpud/pkg/server"
+	"github.com/leptonai/gpud/pkg/session/upload"
+	"github.com/leptonai/gpud/pkg/slo"
 	pkgsqlite "github.com/leptonai/gpud/pkg/sqlite"
 	pkgsystemd "github.com/leptonai/gpud/pkg/systemd"
 	"github.com/leptonai/gpud/version"
 )
 
 func Command(cliContext *cli.Context) error {
+	// applied first, as the config files may set any flag (e.g., "log-level")
+	configFiles, err := common.ApplyConfigLayers(cliContext)
+	if err != nil {
+		return err
+	}
+
 	logLevel := cliContext.String("log-level")
 	logFile := cliContext.String("log-file")
 	zapLvl, err := log.ParseLogLevel(logLevel)
@@ -48,6 +85,9 @@ func Command(cliContext *cli.Context) error {
 	log.SetLogger(log.CreateLogger(zapLvl, logFile))
 
 	log.Logger.Debugw("starting run command")
+	if len(configFiles) > 0 {
+		log.Logger.Infow("applied config files", "files", configFiles)
+	}
 
 	dataDir, err := common.ResolveDataDir(cliContext)
 	if err != nil {
@@ -59,6 +99,15 @@ func Command(cliContext *cli.Context) error {
 
 	metricsRetentionPeriod, eventsRetentionPeriod := parseRetentionPeriods(cliContext)
 
+	var eventsRetentionPolicy *eventstore.RetentionPolicy
+	if policy := cliContext.String("events-retention-policy"); len(policy) > 0 {
+		eventsRetentionPolicy = &eventstore.RetentionPolicy{}
+		if err := json.Unmarshal([]byte(policy), eventsRetentionPolicy); err != nil {
+			return err
+		}
+		log.Logger.Infow("set events retention policy", "byType", eventsRetentionPolicy.ByType, "byBucket", eventsRetentionPolicy.ByBucket)
+	}
+
 	gpuCount := cliContext.Int("gpu-count")
 	gpuCountStr := ""
 	if gpuCount > 0 {
@@ -132,6 +181,7 @@ func Command(cliContext *cli.Context) error {
 	versionFile := cliContext.String("version-file")
 	versionFileSet := cliContext.IsSet("version-file")
 	pluginSpecsFile := cliContext.String("plugin-specs-file")
+	pluginSecretsFile := cliContext.String("plugin-secrets-file")
 	skipSessionUpdateConfig := cliContext.Bool("skip-session-update-config")
 
 	ibClassRootDir := cliContext.String("infiniband-class-root-dir")
@@ -141,6 +191,19 @@ func Command(cliContext *cli.Context) error {
 	infinibandExpectedPortStates := cliContext.String("infiniband-expected-port-states")
 	nvlinkExpectedLinkStates := cliContext.String("nvlink-expected-link-states")
 	nfsCheckerConfigs := cliContext.String("nfs-checker-configs")
+	gdsProbeConfig := cliContext.String("gds-probe-config")
+	gpudirectRDMAProbeConfig := cliContext.String("gpudirect-rdma-probe-config")
+	bmcConfig := cliContext.String("bmc-config")
+	crashDumpConfig := cliContext.String("crash-dump-config")
+	memoryConfig := cliContext.String("memory-config")
+	gpuIdleConfig := cliContext.String("gpu-idle-config")
+	ioLatencyProbeConfigs := cliContext.String("io-latency-probe-configs")
+	metricsAnomalyConfig := cliContext.String("metrics-anomaly-config")
+	bandwidthAsymmetryConfig := cliContext.String("bandwidth-asymmetry-config")
+	pcieConfig := cliContext.String("pcie-config")
+	networkReachabilityConfig := cliContext.String("network-reachability-config")
+	serviceSupervisorConfig := cliContext.String("service-supervisor-config")
+	kernelCountersConfig := cliContext.String("kernel-counters-config")
 	xidRebootThreshold := cliContext.Int("xid-reboot-threshold")
 	temperatureMarginThresholdCelsius := cliContext.Int("threshold-celsius-slowdown-margin")
 
@@ -164,6 +227,17 @@ func Command(cliContext *cli.Context) error {
 		log.Logger.Infow("set nvlink expected link states", "nvlinkExpectedLinkStates", nvlinkExpectedLinkStates)
 	}
 
+	if expectedClocks := cliContext.String("expected-clocks"); len(expectedClocks) > 0 {
+		var l componentsclockspeed.ExpectedClocksList
+		if err := json.Unmarshal([]byte(expectedClocks), &l); err != nil {
+			return err
+		}
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		componentsclockspeed.SetDefaultExpectedClocks(l)
+	}
+
 	if len(nfsCheckerConfigs) > 0 {
 		groupConfigs := make(pkgnfschecker.Configs, 0)
 		if err := json.Unmarshal([]byte(nfsCheckerConfigs), &groupConfigs); err != nil {
@@ -174,6 +248,160 @@ func Command(cliContext *cli.Context) error {
 		log.Logger.Infow("set nfs checker group configs", "groupConfigs", groupConfigs)
 	}
 
+	if len(gdsProbeConfig) > 0 {
+		var probeConfig componentsgds.ProbeConfig
+		if err := json.Unmarshal([]byte(gdsProbeConfig), &probeConfig); err != nil {
+			return err
+		}
+		if err := probeConfig.Validate(); err != nil {
+			return err
+		}
+		componentsgds.SetDefaultProbeConfig(probeConfig)
+
+		log.Logger.Infow("set gds probe config", "probeConfig", probeConfig)
+	}
+
+	if len(gpudirectRDMAProbeConfig) > 0 {
+		var probeConfig componentsgpudirectrdma.ProbeConfig
+		if err := json.Unmarshal([]byte(gpudirectRDMAProbeConfig), &probeConfig); err != nil {
+			return err
+		}
+		if err := probeConfig.Validate(); err != nil {
+			return err
+		}
+		componentsgpudirectrdma.SetDefaultProbeConfig(probeConfig)
+
+		log.Logger.Infow("set gpudirect rdma probe config", "probeConfig", probeConfig)
+	}
+
+	if len(bmcConfig) > 0 {
+		var cfg componentsbmc.Config
+		if err := json.Unmarshal([]byte(bmcConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsbmc.SetDefaultConfig(cfg)
+	}
+
+	var crashDumpCfg componentscrashdump.Config
+	if len(crashDumpConfig) > 0 {
+		if err := json.Unmarshal([]byte(crashDumpConfig), &crashDumpCfg); err != nil {
+			return err
+		}
+		if err := crashDumpCfg.Validate(); err != nil {
+			return err
+		}
+	}
+	if crashDumpCfg.Dir == "" {
+		crashDumpCfg.Dir = config.CrashDumpsDir(dataDir)
+	}
+	componentscrashdump.SetDefaultConfig(crashDumpCfg)
+
+	if len(memoryConfig) > 0 {
+		var cfg componentsmemory.Config
+		if err := json.Unmarshal([]byte(memoryConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsmemory.SetDefaultConfig(cfg)
+	}
+
+	if len(gpuIdleConfig) > 0 {
+		var cfg componentsnvidiaidle.Config
+		if err := json.Unmarshal([]byte(gpuIdleConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsnvidiaidle.SetDefaultConfig(cfg)
+
+		log.Logger.Infow("set gpu idle config", "config", cfg)
+	}
+
+	if len(ioLatencyProbeConfigs) > 0 {
+		var cfgs componentsiolatency.Configs
+		if err := json.Unmarshal([]byte(ioLatencyProbeConfigs), &cfgs); err != nil {
+			return err
+		}
+		if err := cfgs.Validate(); err != nil {
+			return err
+		}
+		componentsiolatency.SetDefaultConfigs(cfgs)
+
+		log.Logger.Infow("set io latency probe configs", "configs", cfgs)
+	}
+
+	if len(metricsAnomalyConfig) > 0 {
+		var cfg componentsmetricsanomaly.Config
+		if err := json.Unmarshal([]byte(metricsAnomalyConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsmetricsanomaly.SetDefaultConfig(cfg)
+	}
+
+	if len(bandwidthAsymmetryConfig) > 0 {
+		var cfg componentsnvidiabandwidthasymmetry.Config
+		if err := json.Unmarshal([]byte(bandwidthAsymmetryConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsnvidiabandwidthasymmetry.SetDefaultConfig(cfg)
+	}
+
+	if len(pcieConfig) > 0 {
+		var cfg componentsnvidiapcie.Config
+		if err := json.Unmarshal([]byte(pcieConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsnvidiapcie.SetDefaultConfig(cfg)
+	}
+
+	if len(networkReachabilityConfig) > 0 {
+		var cfg componentsnetworkreachability.Config
+		if err := json.Unmarshal([]byte(networkReachabilityConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsnetworkreachability.SetDefaultConfig(cfg)
+	}
+
+	if len(serviceSupervisorConfig) > 0 {
+		var cfg componentsservicesupervisor.Config
+		if err := json.Unmarshal([]byte(serviceSupervisorConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentsservicesupervisor.SetDefaultConfig(cfg)
+	}
+
+	if len(kernelCountersConfig) > 0 {
+		var cfg componentskernelcounters.Config
+		if err := json.Unmarshal([]byte(kernelCountersConfig), &cfg); err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		componentskernelcounters.SetDefaultConfig(cfg)
+	}
+
 	if cliContext.IsSet("xid-reboot-threshold") {
 		if xidRebootThreshold > 0 {
 			componentsxid.SetDefaultRebootThreshold(componentsxid.RebootThreshold{
@@ -186,8 +414,9 @@ func Command(cliContext *cli.Context) error {
 	}
 
 	if eventsRetentionPeriod > 0 && !cliContext.IsSet("xid-lookback-period") {
-		componentsxid.SetLookbackPeriod(eventsRetentionPeriod)
-		log.Logger.Infow("set xid lookback period from events retention period", "xidLookbackPeriod", eventsRetentionPeriod)
+		xidLookbackPeriod := eventsRetentionPolicy.MaxRetention(componentsxid.Name, eventsRetentionPeriod)
+		componentsxid.SetLookbackPeriod(xidLookbackPeriod)
+		log.Logger.Infow("set xid lookback period from events retention period", "xidLookbackPeriod", xidLookbackPeriod)
 	}
 
 	if cliContext.IsSet("xid-lookback-period") {
@@ -196,8 +425,9 @@ func Command(cliContext *cli.Context) error {
 	}
 
 	if eventsRetentionPeriod > 0 && !cliContext.IsSet("sxid-lookback-period") {
-		componentssxid.SetLookbackPeriod(eventsRetentionPeriod)
-		log.Logger.Infow("set sxid lookback period from events retention period", "sxidLookbackPeriod", eventsRetentionPeriod)
+		sxidLookbackPeriod := eventsRetentionPolicy.MaxRetention(componentssxid.Name, eventsRetentionPeriod)
+		componentssxid.SetLookbackPeriod(sxidLookbackPeriod)
+		log.Logger.Infow("set sxid lookback period from events retention period", "sxidLookbackPeriod", sxidLookbackPeriod)
 	}
 
 	if cliContext.IsSet("sxid-lookback-period") {
@@ -205,6 +435,20 @@ func Command(cliContext *cli.Context) error {
 		log.Logger.Infow("set sxid lookback period", "sxidLookbackPeriod", cliContext.Duration("sxid-lookback-period"))
 	}
 
+	if gspFirmwarePolicy := componentsgspfirmware.Policy(cliContext.String("gsp-firmware-policy")); gspFirmwarePolicy != componentsgspfirmware.PolicyNone {
+		if err := gspFirmwarePolicy.Validate(); err != nil {
+			return err
+		}
+		componentsgspfirmware.SetDefaultPolicy(gspFirmwarePolicy)
+	}
+
+	if cudaUserlandManifest := cliContext.String("cuda-userland-manifest"); cudaUserlandManifest != "" {
+		if _, err := componentscudauserland.LoadManifest(cudaUserlandManifest); err != nil {
+			return fmt.Errorf("failed to load cuda userland manifest: %w", err)
+		}
+		componentscudauserland.SetDefaultManifestFile(cudaUserlandManifest)
+	}
+
 	if cliContext.IsSet("threshold-celsius-slowdown-margin") {
 		componentstemperature.SetDefaultMarginThreshold(componentstemperature.Thresholds{
 			CelsiusSlowdownMargin: int32(temperatureMarginThresholdCelsius),
@@ -252,6 +496,16 @@ func Command(cliContext *cli.Context) error {
 	// When enabled, the containerd component will report the socket file as missing
 	containerdSocketMissing := cliContext.Bool("containerd-socket-missing")
 
+	// Fake NVML values injection for testing
+	// When set, the NVML devices return the configured values instead of the hardware values
+	var gpuFieldOverrides map[string]*nvidianvmldevice.FieldOverrides
+	if gpuFieldOverridesRaw := cliContext.String("gpu-field-overrides"); len(gpuFieldOverridesRaw) > 0 {
+		if err := json.Unmarshal([]byte(gpuFieldOverridesRaw), &gpuFieldOverrides); err != nil {
+			return fmt.Errorf("failed to parse gpu field overrides: %w", err)
+		}
+		log.Logger.Infow("set gpu field overrides", "gpuFieldOverrides", gpuFieldOverrides)
+	}
+
 	ibExcludedDevices := parseInfinibandExcludeDevices(ibExcludeDevicesStr)
 	if len(ibExcludedDevices) > 0 {
 		log.Logger.Infow("excluding infiniband devices from monitoring", "devices", ibExcludedDevices)
@@ -273,6 +527,7 @@ func Command(cliContext *cli.Context) error {
 			GPUUUIDsWithFabricStateHealthSummaryUnhealthy: gpuUUIDsWithFabricStateHealthSummaryUnhealthy,
 			GPUProductNameOverride:                        gpuProductNameOverride,
 			NVMLDeviceGetDevicesError:                     nvmlDeviceGetDevicesError,
+			GPUFieldOverrides:                             gpuFieldOverrides,
 			ContainerdSocketMissing:                       containerdSocketMissing,
 		}),
 	}
@@ -292,12 +547,26 @@ func Command(cliContext *cli.Context) error {
 	if pprof {
 		cfg.Pprof = true
 	}
+	if cliContext.Bool("debug-snapshot") {
+		cfg.DebugSnapshot = true
+	}
+	if cliContext.Bool("validate-contracts") {
+		cfg.ValidateContracts = true
+	}
+	if cliContext.Bool("startup-self-test") {
+		cfg.StartupSelfTest = true
+	}
+	if cliContext.IsSet("max-request-body-bytes") {
+		cfg.MaxRequestBodyBytes = cliContext.Int64("max-request-body-bytes")
+	}
 	if metricsRetentionPeriod > 0 {
 		cfg.MetricsRetentionPeriod = metav1.Duration{Duration: metricsRetentionPeriod}
 	}
 	if eventsRetentionPeriod > 0 {
 		cfg.EventsRetentionPeriod = metav1.Duration{Duration: eventsRetentionPeriod}
 	}
+	cfg.EventsRetentionPolicy = eventsRetentionPolicy
+	cfg.EventDispositionThreshold = cliContext.Int("event-disposition-threshold")
 
 	cfg.CompactPeriod = config.DefaultCompactPeriod
 
@@ -309,12 +578,154 @@ func Command(cliContext *cli.Context) error {
 	cfg.VersionFile = versionFile
 
 	cfg.PluginSpecsFile = pluginSpecsFile
+	cfg.PluginSecretsFile = pluginSecretsFile
+	if cliContext.Bool("enable-external-components") {
+		cfg.ExternalComponentsSocket = config.ExternalComponentsSocketPath(cfg.DataDir)
+	}
 	cfg.SkipSessionUpdateConfig = skipSessionUpdateConfig
+	cfg.PostgresDSN = cliContext.String("postgres-dsn")
 
 	if components != "" {
 		cfg.Components = strings.Split(components, ",")
 	}
 
+	if componentHealthHysteresis := cliContext.String("component-health-hysteresis"); len(componentHealthHysteresis) > 0 {
+		if err := json.Unmarshal([]byte(componentHealthHysteresis), &cfg.ComponentHealthHysteresis); err != nil {
+			return err
+		}
+		log.Logger.Infow("set component health hysteresis", "componentHealthHysteresis", cfg.ComponentHealthHysteresis)
+	}
+
+	if componentAdaptiveCheckInterval := cliContext.String("component-adaptive-check-interval"); len(componentAdaptiveCheckInterval) > 0 {
+		if err := json.Unmarshal([]byte(componentAdaptiveCheckInterval), &cfg.ComponentAdaptiveCheckInterval); err != nil {
+			return err
+		}
+		log.Logger.Infow("set component adaptive check interval", "componentAdaptiveCheckInterval", cfg.ComponentAdaptiveCheckInterval)
+	}
+
+	if apiRBACConfig := cliContext.String("api-rbac-config"); len(apiRBACConfig) > 0 {
+		cfg.RBAC = &rbac.Config{}
+		if err := json.Unmarshal([]byte(apiRBACConfig), cfg.RBAC); err != nil {
+			return err
+		}
+		log.Logger.Infow("set api rbac config", "tokens", len(cfg.RBAC.Tokens), "clientCAFile", cfg.RBAC.ClientCAFile, "anonymousRole", cfg.RBAC.AnonymousRole)
+	}
+
+	if apiTokensFile := cliContext.String("api-tokens-file"); len(apiTokensFile) > 0 {
+		if cfg.RBAC == nil {
+			cfg.RBAC = &rbac.Config{}
+		}
+		cfg.RBAC.TokensFile = apiTokensFile
+		log.Logger.Infow("set api tokens file", "tokensFile", apiTokensFile)
+	}
+
+	if apiRateLimitConfig := cliContext.String("api-rate-limit-config"); len(apiRateLimitConfig) > 0 {
+		cfg.RateLimit = &ratelimit.Config{}
+		if err := json.Unmarshal([]byte(apiRateLimitConfig), cfg.RateLimit); err != nil {
+			return err
+		}
+		log.Logger.Infow("set api rate limit config", "rateLimit", cfg.RateLimit)
+	}
+
+	if sessionUploadConfig := cliContext.String("session-upload-config"); len(sessionUploadConfig) > 0 {
+		cfg.SessionUpload = &upload.Config{}
+		if err := json.Unmarshal([]byte(sessionUploadConfig), cfg.SessionUpload); err != nil {
+			return err
+		}
+		log.Logger.Infow("set session upload config", "sessionUploadConfig", cfg.SessionUpload)
+	}
+
+	if gossipConfig := cliContext.String("gossip-config"); len(gossipConfig) > 0 {
+		cfg.Gossip = &gossip.Config{}
+		if err := json.Unmarshal([]byte(gossipConfig), cfg.Gossip); err != nil {
+			return err
+		}
+		// do not log the secret key
+		log.Logger.Infow("set gossip config", "bindAddr", cfg.Gossip.BindAddr, "bindPort", cfg.Gossip.BindPort, "join", cfg.Gossip.Join)
+	}
+
+	if sloConfig := cliContext.String("slo-config"); len(sloConfig) > 0 {
+		cfg.SLO = &slo.Config{}
+		if err := json.Unmarshal([]byte(sloConfig), cfg.SLO); err != nil {
+			return err
+		}
+		log.Logger.Infow("set slo config", "objectives", len(cfg.SLO.Objectives))
+	}
+
+	if metricsExportConfig := cliContext.String("metrics-export-config"); len(metricsExportConfig) > 0 {
+		cfg.MetricsExport = &pkgmetrics.ExportConfig{}
+		if err := json.Unmarshal([]byte(metricsExportConfig), cfg.MetricsExport); err != nil {
+			return err
+		}
+		log.Logger.Infow("set metrics export config", "allow", cfg.MetricsExport.Allow, "deny", cfg.MetricsExport.Deny)
+	}
+
+	if dataBudgetConfig := cliContext.String("data-budget-config"); len(dataBudgetConfig) > 0 {
+		cfg.DataBudget = &budget.Config{}
+		if err := json.Unmarshal([]byte(dataBudgetConfig), cfg.DataBudget); err != nil {
+			return err
+		}
+		log.Logger.Infow("set data budget config", "dataBudget", cfg.DataBudget)
+	}
+
+	if loadSheddingConfig := cliContext.String("load-shedding-config"); len(loadSheddingConfig) > 0 {
+		cfg.LoadShedding = &loadshed.Config{}
+		if err := json.Unmarshal([]byte(loadSheddingConfig), cfg.LoadShedding); err != nil {
+			return err
+		}
+		log.Logger.Infow("set load shedding config", "loadShedding", cfg.LoadShedding)
+	}
+
+	if drainReadinessConfig := cliContext.String("drain-readiness-config"); len(drainReadinessConfig) > 0 {
+		cfg.DrainReadiness = &drain.Config{}
+		if err := json.Unmarshal([]byte(drainReadinessConfig), cfg.DrainReadiness); err != nil {
+			return err
+		}
+		log.Logger.Infow("set drain readiness config", "plugins", cfg.DrainReadiness.Plugins)
+	}
+
+	if driverUpgradeConfig := cliContext.String("driver-upgrade-config"); len(driverUpgradeConfig) > 0 {
+		cfg.DriverUpgrade = &driverupgrade.Config{}
+		if err := json.Unmarshal([]byte(driverUpgradeConfig), cfg.DriverUpgrade); err != nil {
+			return err
+		}
+		log.Logger.Infow("set driver upgrade config", "plugins", cfg.DriverUpgrade.Plugins, "settlePeriod", cfg.DriverUpgrade.SettlePeriod.Duration)
+	}
+
+	if gpuPerformanceScoreConfig := cliContext.String("gpu-performance-score-config"); len(gpuPerformanceScoreConfig) > 0 {
+		cfg.GPUPerformanceScore = &gpuscore.Config{}
+		if err := json.Unmarshal([]byte(gpuPerformanceScoreConfig), cfg.GPUPerformanceScore); err != nil {
+			return err
+		}
+		log.Logger.Infow("set gpu performance score config", "gpuPerformanceScore", cfg.GPUPerformanceScore)
+	}
+
+	if sqliteConfig := cliContext.String("sqlite-config"); len(sqliteConfig) > 0 {
+		cfg.SQLite = &pkgsqlite.Config{}
+		if err := json.Unmarshal([]byte(sqliteConfig), cfg.SQLite); err != nil {
+			return err
+		}
+		log.Logger.Infow("set sqlite config", "sqlite", cfg.SQLite)
+	}
+
+	if maintenanceWindows := cliContext.String("maintenance-windows"); len(maintenanceWindows) > 0 {
+		if err := json.Unmarshal([]byte(maintenanceWindows), &cfg.MaintenanceWindows); err != nil {
+			return err
+		}
+		log.Logger.Infow("set maintenance windows", "maintenanceWindows", cfg.MaintenanceWindows)
+	}
+
+	if chaosConfig := cliContext.String("chaos-config"); cliContext.Bool("chaos") || len(chaosConfig) > 0 {
+		chaosCfg := pkgchaos.DefaultConfig()
+		if len(chaosConfig) > 0 {
+			if err := json.Unmarshal([]byte(chaosConfig), &chaosCfg); err != nil {
+				return err
+			}
+		}
+		cfg.Chaos = &chaosCfg
+		log.Logger.Warnw("set chaos mode, never enable in production", "chaos", cfg.Chaos)
+	}
+
 	auditLogger := log.NewNopAuditLogger()
 	if logFile != "" {
 		logAuditFile := log.CreateAuditLogFilepath(logFile)
@@ -372,8 +783,12 @@ func Command(cliContext *cli.Context) error {
 
 	log.Logger.Infof("starting gpud %v", version.Version)
 
+	containerMode := resolveContainerMode(cliContext.Bool("container"), pkghost.GetContainerEnvironment())
+	// in the container, the host systemd (if any) does not manage gpud
+	notifySystemd := !containerMode && pkgsystemd.SystemctlExists()
+
 	done := gpudserver.HandleSignals(rootCtx, rootCancel, signals, serverC, func(ctx context.Context) error {
-		if pkgsystemd.SystemctlExists() {
+		if notifySystemd {
 			if err := pkgsystemd.NotifyStopping(ctx); err != nil {
 				log.Logger.Errorw("notify stopping failed")
 			}
@@ -398,7 +813,7 @@ func Command(cliContext *cli.Context) error {
 	}
 	serverC <- server
 
-	if pkgsystemd.SystemctlExists() {
+	if notifySystemd {
 		if err := pkgsystemd.NotifyReady(rootCtx); err != nil {
 			log.Logger.Warnw("notify ready failed")
 		}
@@ -412,6 +827,32 @@ func Command(cliContext *cli.Context) error {
 	return nil
 }
 
+// resolveContainerMode returns true if gpud runs in the container mode,
+// either set by the flag or detected, and warns the missing host namespaces
+// and mounts that make the dependent components run in degraded mode.
+func resolveContainerMode(flag bool, env pkghost.ContainerEnvironment) bool {
+	if !flag && !env.InContainer {
+		return false
+	}
+
+	log.Logger.Infow("running in container mode",
+		"flag", flag,
+		"detected", env.InContainer,
+		"hostPID", env.HostPID,
+		"hostNetwork", env.HostNetwork,
+		"hostMount", env.HostMount,
+	)
+	if !env.HostPID {
+		log.Logger.Warnw("not sharing the host PID namespace, the process components only see the container processes (set hostPID in the pod spec)")
+	} else if !env.HostNetwork {
+		log.Logger.Warnw("not sharing the host network namespace, the network components only see the container network (set hostNetwork in the pod spec)")
+	}
+	if len(env.MissingPaths) > 0 {
+		log.Logger.Warnw("host paths not mounted, the dependent components run in degraded mode (see /v1/capabilities)", "missing", env.MissingPaths)
+	}
+	return true
+}
+
 func parseRetentionPeriods(cliContext *cli.Context) (metricsRetentionPeriod, eventsRetentionPeriod time.Duration) {
 	metricsRetentionPeriod = cliContext.Duration("metrics-retention-period")
 	deprecatedRetentionPeriod := cliContext.Duration("retention-period")
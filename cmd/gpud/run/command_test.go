@@ -10,6 +10,7 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/leptonai/gpud/pkg/config"
+	pkghost "github.com/leptonai/gpud/pkg/host"
 )
 
 func TestParseInfinibandExcludeDevices(t *testing.T) {
@@ -128,3 +129,13 @@ func TestParseRetentionPeriods(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveContainerMode(t *testing.T) {
+	assert.False(t, resolveContainerMode(false, pkghost.ContainerEnvironment{}))
+	assert.True(t, resolveContainerMode(true, pkghost.ContainerEnvironment{}))
+	assert.True(t, resolveContainerMode(false, pkghost.ContainerEnvironment{
+		InContainer:  true,
+		HostPID:      true,
+		MissingPaths: []string{"/dev/kmsg"},
+	}))
+}
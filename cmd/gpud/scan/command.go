@@ -47,6 +47,15 @@ func CreateCommand() func(*cli.Context) error {
 			componentssxid.SetLookbackPeriod(cliContext.Duration("sxid-lookback-period"))
 		}
 
+		dataDir, err := common.ResolveDataDir(cliContext)
+		if err != nil {
+			return err
+		}
+		resultOpts := []scan.OpOption{
+			scan.WithResultsDir(config.ScanResultsDir(dataDir)),
+			scan.WithCompareLast(cliContext.Bool("compare-last")),
+		}
+
 		return cmdScan(
 			cliContext.String("log-level"),
 			cliContext.Int("gpu-count"),
@@ -68,6 +77,7 @@ func CreateCommand() func(*cli.Context) error {
 			cliContext.IsSet("xid-reboot-threshold"),
 			cliContext.Int("threshold-celsius-slowdown-margin"),
 			cliContext.IsSet("threshold-celsius-slowdown-margin"),
+			resultOpts...,
 		)
 	}
 }
@@ -93,6 +103,7 @@ func cmdScan(
 	xidRebootThresholdIsSet bool,
 	temperatureMarginThresholdCelsius int,
 	temperatureMarginThresholdIsSet bool,
+	resultOpts ...scan.OpOption,
 ) error {
 	zapLvl, err := log.ParseLogLevel(logLevel)
 	if err != nil {
@@ -182,6 +193,7 @@ func cmdScan(
 			ContainerdSocketMissing:                       containerdSocketMissing,
 		}),
 	}
+	opts = append(opts, resultOpts...)
 	if zapLvl.Level() <= zap.DebugLevel { // e.g., info, warn, error
 		opts = append(opts, scan.WithDebug(true))
 	}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,6 +36,11 @@ func Command(cliContext *cli.Context) error {
 	rootCtx, rootCancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer rootCancel()
 
+	gpudAddr := fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort)
+	if cliContext.Bool("json") {
+		return displayStatusJSON(rootCtx, gpudAddr)
+	}
+
 	log.Logger.Debugw("getting state file")
 	stateFile, err := gpudcommon.StateFileFromContext(cliContext)
 	if err != nil {
@@ -108,21 +114,28 @@ func Command(cliContext *cli.Context) error {
 	}
 	fmt.Printf("%s successfully checked gpud status\n", cmdcommon.CheckMark)
 
-	if err := clientv1.BlockUntilServerReady(
-		rootCtx,
-		fmt.Sprintf("https://localhost:%d", config.DefaultGPUdPort),
-	); err != nil {
+	if err := clientv1.BlockUntilServerReady(rootCtx, gpudAddr); err != nil {
 		return err
 	}
 	fmt.Printf("%s successfully checked gpud health\n", cmdcommon.CheckMark)
 
+	cctx, ccancel := context.WithTimeout(rootCtx, 15*time.Second)
+	st, err := clientv1.GetGPUdStatus(cctx, gpudAddr)
+	ccancel()
+	if err != nil {
+		// e.g., older daemon without the status endpoint
+		fmt.Printf("%s failed to get gpud status: %v\n", cmdcommon.WarningSign, err)
+	} else {
+		displayStatus(os.Stdout, st)
+	}
+
 	statusWatch := cliContext.Bool("watch")
 
 	var lastPackageStatus packages.PackageStatuses
 	for {
 		var err error
 		cctx, ccancel := context.WithTimeout(rootCtx, 15*time.Second)
-		lastPackageStatus, err = clientv1.GetPackageStatus(cctx, fmt.Sprintf("https://localhost:%d%s", config.DefaultGPUdPort, server.URLPathAdminPackages))
+		lastPackageStatus, err = clientv1.GetPackageStatus(cctx, gpudAddr+server.URLPathAdminPackages)
 		ccancel()
 		if err != nil {
 			fmt.Printf("%s failed to get package status: %v\n", cmdcommon.WarningSign, err)
@@ -169,3 +182,22 @@ func Command(cliContext *cli.Context) error {
 
 	return nil
 }
+
+// displayStatusJSON prints the aggregated GPUd status from the server in JSON.
+func displayStatusJSON(ctx context.Context, gpudAddr string) error {
+	if err := clientv1.BlockUntilServerReady(ctx, gpudAddr); err != nil {
+		return err
+	}
+
+	st, err := clientv1.GetGPUdStatus(ctx, gpudAddr)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
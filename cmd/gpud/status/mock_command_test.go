@@ -35,6 +35,7 @@ func newCLIContext(t *testing.T, args []string) *cli.Context {
 	_ = flags.String("log-level", "info", "")
 	_ = flags.String("data-dir", "", "")
 	_ = flags.Bool("watch", false, "")
+	_ = flags.Bool("json", false, "")
 
 	require.NoError(t, flags.Parse(args))
 	return cli.NewContext(app, flags, nil)
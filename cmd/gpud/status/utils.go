@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	sessionstates "github.com/leptonai/gpud/pkg/session/states"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -79,3 +83,53 @@ func displayLoginStatus(ctx context.Context, dbRO *sql.DB) (*sessionstates.State
 
 	return status, nil
 }
+
+// displayStatus renders the aggregated GPUd status returned by the server.
+func displayStatus(w io.Writer, st *apiv1.GPUdStatus) {
+	nowUTC := time.Now().UTC()
+
+	fmt.Fprintf(w, "%s gpud %s up %s (started %s)\n", cmdcommon.CheckMark, st.GPUdVersion, st.Uptime.Round(time.Second), humanize.RelTime(st.StartTime.Time, nowUTC, "ago", "from now"))
+
+	switch {
+	case st.Session == nil:
+		fmt.Fprintf(w, "%s control plane session: no activity recorded\n", cmdcommon.InProgress)
+	case st.Session.Success:
+		fmt.Fprintf(w, "%s control plane session: connected at %s\n", cmdcommon.CheckMark, humanize.RelTime(st.Session.Time.Time, nowUTC, "ago", "from now"))
+	default:
+		fmt.Fprintf(w, "%s control plane session: failing at %s - %s\n", cmdcommon.WarningSign, humanize.RelTime(st.Session.Time.Time, nowUTC, "ago", "from now"), st.Session.Message)
+	}
+
+	healths := make([]string, 0, len(st.Components.ByHealth))
+	for h := range st.Components.ByHealth {
+		healths = append(healths, string(h))
+	}
+	sort.Strings(healths)
+	counts := make([]string, 0, len(healths))
+	for _, h := range healths {
+		counts = append(counts, fmt.Sprintf("%d %s", st.Components.ByHealth[apiv1.HealthStateType(h)], h))
+	}
+	if len(st.Components.Unhealthy) == 0 {
+		fmt.Fprintf(w, "%s components: %d total (%s)\n", cmdcommon.CheckMark, st.Components.Total, strings.Join(counts, ", "))
+	} else {
+		fmt.Fprintf(w, "%s components: %d total (%s) -- not healthy: %s\n", cmdcommon.WarningSign, st.Components.Total, strings.Join(counts, ", "), strings.Join(st.Components.Unhealthy, ", "))
+	}
+	if len(st.Components.InMaintenance) > 0 {
+		fmt.Fprintf(w, "%s components in maintenance: %s\n", cmdcommon.InProgress, strings.Join(st.Components.InMaintenance, ", "))
+	}
+	if len(st.Components.Muted) > 0 {
+		fmt.Fprintf(w, "%s components muted: %s\n", cmdcommon.InProgress, strings.Join(st.Components.Muted, ", "))
+	}
+	if len(st.Components.Stale) > 0 {
+		fmt.Fprintf(w, "%s components not yet checked since restart (reporting pre-restart states): %s\n", cmdcommon.InProgress, strings.Join(st.Components.Stale, ", "))
+	}
+
+	if st.LastFatalEvent == nil {
+		fmt.Fprintf(w, "%s last fatal event: none\n", cmdcommon.CheckMark)
+	} else {
+		ev := st.LastFatalEvent
+		fmt.Fprintf(w, "%s last fatal event: %s at %s - %s\n", cmdcommon.WarningSign, ev.Component, humanize.RelTime(ev.Time.Time, nowUTC, "ago", "from now"), ev.Message)
+	}
+
+	fmt.Fprintf(w, "%s db size: %s\n", cmdcommon.CheckMark, humanize.IBytes(st.DBSizeBytes))
+	fmt.Fprintf(w, "%s metrics ingest rate: %.1f data points/min\n", cmdcommon.CheckMark, st.MetricsIngestRatePerMinute)
+}
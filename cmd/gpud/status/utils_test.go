@@ -14,7 +14,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
 	sessionstates "github.com/leptonai/gpud/pkg/session/states"
 	"github.com/leptonai/gpud/pkg/sqlite"
@@ -319,3 +321,66 @@ func TestDisplayLoginStatus(t *testing.T) {
 		assert.NotContains(t, output, "warning")
 	})
 }
+
+func TestDisplayStatus(t *testing.T) {
+	now := time.Now().UTC()
+
+	t.Run("healthy", func(t *testing.T) {
+		var buf bytes.Buffer
+		displayStatus(&buf, &apiv1.GPUdStatus{
+			GPUdVersion: "v0.5.0",
+			StartTime:   metav1.NewTime(now.Add(-time.Hour)),
+			Uptime:      metav1.Duration{Duration: time.Hour},
+			Session:     &apiv1.SessionStatus{Time: metav1.NewTime(now), Success: true},
+			Components: apiv1.ComponentHealthSummary{
+				Total:    2,
+				ByHealth: map[apiv1.HealthStateType]int{apiv1.HealthStateTypeHealthy: 2},
+			},
+			DBSizeBytes:                2 * 1024 * 1024,
+			MetricsIngestRatePerMinute: 12.5,
+		})
+
+		out := buf.String()
+		assert.Contains(t, out, "gpud v0.5.0 up 1h0m0s")
+		assert.Contains(t, out, cmdcommon.CheckMark+" control plane session: connected")
+		assert.Contains(t, out, cmdcommon.CheckMark+" components: 2 total (2 Healthy)")
+		assert.Contains(t, out, "last fatal event: none")
+		assert.Contains(t, out, "db size: 2.0 MiB")
+		assert.Contains(t, out, "metrics ingest rate: 12.5 data points/min")
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		var buf bytes.Buffer
+		displayStatus(&buf, &apiv1.GPUdStatus{
+			StartTime: metav1.NewTime(now),
+			Session:   &apiv1.SessionStatus{Time: metav1.NewTime(now), Message: "token expired"},
+			Components: apiv1.ComponentHealthSummary{
+				Total: 3,
+				ByHealth: map[apiv1.HealthStateType]int{
+					apiv1.HealthStateTypeHealthy:   1,
+					apiv1.HealthStateTypeDegraded:  1,
+					apiv1.HealthStateTypeUnhealthy: 1,
+				},
+				Unhealthy:     []string{"cpu", "disk"},
+				InMaintenance: []string{"os"},
+				Stale:         []string{"cpu"},
+			},
+			LastFatalEvent: &apiv1.Event{Component: "accelerator-nvidia-error-xid", Time: metav1.NewTime(now), Message: "xid 79"},
+		})
+
+		out := buf.String()
+		assert.Contains(t, out, cmdcommon.WarningSign+" control plane session: failing")
+		assert.Contains(t, out, "token expired")
+		assert.Contains(t, out, "components: 3 total (1 Degraded, 1 Healthy, 1 Unhealthy) -- not healthy: cpu, disk")
+		assert.Contains(t, out, "components in maintenance: os")
+		assert.Contains(t, out, "not yet checked since restart (reporting pre-restart states): cpu")
+		assert.Contains(t, out, cmdcommon.WarningSign+" last fatal event: accelerator-nvidia-error-xid")
+		assert.Contains(t, out, "xid 79")
+	})
+
+	t.Run("no session", func(t *testing.T) {
+		var buf bytes.Buffer
+		displayStatus(&buf, &apiv1.GPUdStatus{StartTime: metav1.NewTime(now)})
+		assert.Contains(t, buf.String(), "control plane session: no activity recorded")
+	})
+}
@@ -0,0 +1,199 @@
+// Package tokens implements the "tokens" command to manage the scoped API tokens.
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli"
+
+	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
+	"github.com/leptonai/gpud/pkg/osutil"
+	"github.com/leptonai/gpud/pkg/rbac"
+)
+
+// TokenInfo is a token in the tokens file, without the digest.
+type TokenInfo struct {
+	Name      string     `json:"name"`
+	Role      rbac.Role  `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired"`
+}
+
+// CreatedToken is the token created, where the plaintext token is only shown once.
+type CreatedToken struct {
+	TokenInfo
+	Token string `json:"token"`
+}
+
+// CommandCreate creates a new token in the tokens file, and prints the plaintext token once.
+func CommandCreate(cliContext *cli.Context) error {
+	outputFormat, err := gpudcommon.ParseOutputFormat(cliContext.String("output-format"))
+	if err != nil {
+		return err
+	}
+	if err := osutil.RequireRoot(); err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "root_required", err)
+	}
+
+	role, err := rbac.ParseRole(cliContext.String("scope"))
+	if err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "invalid_scope", err)
+	}
+	var expiresAt *time.Time
+	if expiresIn := cliContext.Duration("expires-in"); expiresIn > 0 {
+		t := time.Now().UTC().Add(expiresIn).Truncate(time.Second)
+		expiresAt = &t
+	}
+
+	created, err := createToken(cliContext.String("tokens-file"), cliContext.String("name"), role, expiresAt)
+	if err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "token_create_failed", err)
+	}
+
+	if outputFormat == gpudcommon.OutputFormatJSON {
+		return gpudcommon.WriteJSON(created)
+	}
+	fmt.Printf("created token %q (role %s)\n", created.Name, created.Role)
+	if created.ExpiresAt != nil {
+		fmt.Printf("expires at %s\n", created.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Printf("\n%s\n\nstore the token now, it cannot be shown again\n", created.Token)
+	return nil
+}
+
+// CommandList lists the tokens in the tokens file.
+func CommandList(cliContext *cli.Context) error {
+	outputFormat, err := gpudcommon.ParseOutputFormat(cliContext.String("output-format"))
+	if err != nil {
+		return err
+	}
+	if err := osutil.RequireRoot(); err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "root_required", err)
+	}
+
+	infos, err := listTokens(cliContext.String("tokens-file"), time.Now().UTC())
+	if err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "token_list_failed", err)
+	}
+
+	if outputFormat == gpudcommon.OutputFormatJSON {
+		return gpudcommon.WriteJSON(infos)
+	}
+	writeTable(os.Stdout, infos)
+	return nil
+}
+
+// CommandRevoke removes the token from the tokens file,
+// rejected by the running GPUd on the next request.
+func CommandRevoke(cliContext *cli.Context) error {
+	outputFormat, err := gpudcommon.ParseOutputFormat(cliContext.String("output-format"))
+	if err != nil {
+		return err
+	}
+	if err := osutil.RequireRoot(); err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "root_required", err)
+	}
+
+	name := cliContext.String("name")
+	if err := revokeToken(cliContext.String("tokens-file"), name); err != nil {
+		return gpudcommon.WrapOutputError(outputFormat, "token_revoke_failed", err)
+	}
+
+	if outputFormat == gpudcommon.OutputFormatJSON {
+		return gpudcommon.WriteJSON(map[string]string{"revoked": name})
+	}
+	fmt.Printf("revoked token %q\n", name)
+	return nil
+}
+
+// readTokens returns the tokens in the file, or none if the file does not exist.
+func readTokens(path string) ([]rbac.Token, error) {
+	tokens, err := rbac.LoadTokensFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return tokens, err
+}
+
+func createToken(path string, name string, role rbac.Role, expiresAt *time.Time) (CreatedToken, error) {
+	if name == "" {
+		return CreatedToken{}, errors.New("token name is required")
+	}
+
+	tokens, err := readTokens(path)
+	if err != nil {
+		return CreatedToken{}, err
+	}
+	for _, t := range tokens {
+		if t.Name == name {
+			return CreatedToken{}, fmt.Errorf("token %q already exists (revoke it first to rotate)", name)
+		}
+	}
+
+	token, digest, err := rbac.GenerateToken()
+	if err != nil {
+		return CreatedToken{}, err
+	}
+	tokens = append(tokens, rbac.Token{Name: name, SHA256: digest, Role: role, ExpiresAt: expiresAt})
+	if err := rbac.WriteTokensFile(path, tokens); err != nil {
+		return CreatedToken{}, err
+	}
+
+	return CreatedToken{
+		TokenInfo: TokenInfo{Name: name, Role: role, ExpiresAt: expiresAt},
+		Token:     token,
+	}, nil
+}
+
+func listTokens(path string, now time.Time) ([]TokenInfo, error) {
+	tokens, err := readTokens(path)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]TokenInfo, 0, len(tokens))
+	for _, t := range tokens {
+		infos = append(infos, TokenInfo{Name: t.Name, Role: t.Role, ExpiresAt: t.ExpiresAt, Expired: t.Expired(now)})
+	}
+	return infos, nil
+}
+
+func revokeToken(path string, name string) error {
+	tokens, err := readTokens(path)
+	if err != nil {
+		return err
+	}
+	kept := make([]rbac.Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Name != name {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(tokens) {
+		return fmt.Errorf("token %q not found", name)
+	}
+	return rbac.WriteTokensFile(path, kept)
+}
+
+func writeTable(w io.Writer, infos []TokenInfo) {
+	if len(infos) == 0 {
+		_, _ = fmt.Fprintln(w, "no token found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Name", "Role", "Expires At", "Expired"})
+	for _, info := range infos {
+		expiresAt := "never"
+		if info.ExpiresAt != nil {
+			expiresAt = info.ExpiresAt.Format(time.RFC3339)
+		}
+		table.Append([]string{info.Name, string(info.Role), expiresAt, fmt.Sprintf("%v", info.Expired)})
+	}
+	table.Render()
+}
@@ -0,0 +1,78 @@
+package tokens
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/rbac"
+)
+
+func TestCreateListRevoke(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("tokens file must be owned by root")
+	}
+
+	path := filepath.Join(t.TempDir(), "gpud", "tokens.json")
+
+	infos, err := listTokens(path, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+
+	created, err := createToken(path, "ci", rbac.RoleOperator, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", created.Name)
+	assert.Contains(t, created.Token, "gpud_")
+
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	_, err = createToken(path, "dashboard", rbac.RoleViewer, &expiresAt)
+	require.NoError(t, err)
+
+	_, err = createToken(path, "ci", rbac.RoleAdmin, nil)
+	assert.ErrorContains(t, err, "already exists")
+	_, err = createToken(path, "", rbac.RoleAdmin, nil)
+	assert.Error(t, err)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	// only the digest is stored
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), created.Token)
+	sum := sha256.Sum256([]byte(created.Token))
+	assert.Contains(t, string(b), hex.EncodeToString(sum[:]))
+
+	infos, err = listTokens(path, expiresAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, TokenInfo{Name: "ci", Role: rbac.RoleOperator}, infos[0])
+	assert.Equal(t, "dashboard", infos[1].Name)
+	assert.True(t, infos[1].Expired)
+
+	buf := bytes.NewBuffer(nil)
+	writeTable(buf, infos)
+	assert.Contains(t, buf.String(), "never")
+	assert.Contains(t, buf.String(), expiresAt.Format(time.RFC3339))
+
+	require.NoError(t, revokeToken(path, "ci"))
+	assert.ErrorContains(t, revokeToken(path, "ci"), "not found")
+	infos, err = listTokens(path, time.Now())
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "dashboard", infos[0].Name)
+}
+
+func TestWriteTableEmpty(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	writeTable(buf, nil)
+	assert.Equal(t, "no token found\n", buf.String())
+}
@@ -0,0 +1,386 @@
+package verifyinstall
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Status is the result status of an installation check.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	// StatusSkip is the check that does not apply to the host (e.g., SELinux disabled).
+	StatusSkip Status = "skip"
+)
+
+// Result is the result of an installation check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Remediation is the action to fix the problem, empty if ok.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+const (
+	defaultSELinuxEnforcePath   = "/sys/fs/selinux/enforce"
+	defaultAppArmorEnabledPath  = "/sys/module/apparmor/parameters/enabled"
+	defaultAppArmorProfilesPath = "/sys/kernel/security/apparmor/profiles"
+
+	// serviceUID is the user the systemd unit runs gpud as.
+	serviceUID = 0
+)
+
+// untrustedSELinuxTypes are the SELinux types that systemd cannot execute
+// under the enforcing mode, typically left when the binary is moved
+// (instead of copied) from the home or the temporary directory.
+var untrustedSELinuxTypes = []string{"user_home_t", "admin_home_t", "tmp_t", "user_tmp_t"}
+
+type checker struct {
+	binPath   string
+	unitFile  string
+	envFile   string
+	dataDir   string
+	stateFile string
+
+	expectedUnit func() string
+
+	// verifyPackage returns the path of the signature-verified release tarball,
+	// and the function to clean it up.
+	verifyPackage func(ctx context.Context) (string, func(), error)
+
+	selinuxEnforcePath   string
+	appArmorEnabledPath  string
+	appArmorProfilesPath string
+
+	getSELinuxLabel func(path string) (string, error)
+	getOwner        func(info os.FileInfo) (int, bool)
+}
+
+func (ck *checker) run(ctx context.Context) []Result {
+	results := []Result{ck.checkBinarySignature(ctx)}
+	results = append(results, ck.checkSystemdUnit()...)
+	results = append(results, ck.checkPermissions()...)
+	results = append(results, ck.checkSELinux(), ck.checkAppArmor())
+	return results
+}
+
+func (ck *checker) checkBinarySignature(ctx context.Context) Result {
+	r := Result{Name: "binary signature"}
+	if ck.verifyPackage == nil {
+		r.Status = StatusSkip
+		r.Message = "signature verification skipped"
+		return r
+	}
+
+	tarball, cleanup, err := ck.verifyPackage(ctx)
+	if err != nil {
+		r.Status = StatusFail
+		r.Message = fmt.Sprintf("failed to verify the signed release package: %v", err)
+		r.Remediation = `check the network access to the package server, or pass the release package with "--package-path", "--sig-path", and "--sign-pub-path"`
+		return r
+	}
+	defer cleanup()
+
+	if err := compareBinary(ck.binPath, tarball); err != nil {
+		r.Status = StatusFail
+		r.Message = err.Error()
+		r.Remediation = `reinstall gpud from the official release (e.g., "sudo gpud update --next-version <version>")`
+		return r
+	}
+
+	r.Status = StatusOK
+	r.Message = fmt.Sprintf("%s matches the signed release package", ck.binPath)
+	return r
+}
+
+// compareBinary returns an error if the binary does not match the "gpud" in the release tarball.
+func compareBinary(binPath string, tarballPath string) error {
+	want, err := tarballBinaryDigest(tarballPath)
+	if err != nil {
+		return err
+	}
+	got, err := fileDigest(binPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the binary %q: %w", binPath, err)
+	}
+	if !bytes.Equal(want, got) {
+		return fmt.Errorf("%s does not match the signed release package (sha256 %x, expected %x)", binPath, got, want)
+	}
+	return nil
+}
+
+func fileDigest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func tarballBinaryDigest(tarballPath string) ([]byte, error) {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", tarballPath, err)
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+
+	tr := tar.NewReader(gr)
+	for {
+		th, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed extracting %q: %w", tarballPath, err)
+		}
+		if filepath.Base(th.Name) != "gpud" {
+			continue
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, tr); err != nil {
+			return nil, fmt.Errorf("failed extracting %q: %w", tarballPath, err)
+		}
+		return h.Sum(nil), nil
+	}
+	return nil, fmt.Errorf("%q has no gpud binary", tarballPath)
+}
+
+func (ck *checker) checkSystemdUnit() []Result {
+	unit := Result{Name: "systemd unit"}
+	b, err := os.ReadFile(ck.unitFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		unit.Status = StatusFail
+		unit.Message = fmt.Sprintf("%s not found", ck.unitFile)
+		unit.Remediation = `run "sudo gpud up" to install the systemd unit`
+		return []Result{unit}
+	case err != nil:
+		unit.Status = StatusFail
+		unit.Message = fmt.Sprintf("failed to read %s: %v", ck.unitFile, err)
+		unit.Remediation = "re-run as root"
+		return []Result{unit}
+	}
+
+	execBin := unitExecStartBinary(string(b))
+	switch {
+	case execBin == "":
+		unit.Status = StatusFail
+		unit.Message = fmt.Sprintf("%s has no ExecStart", ck.unitFile)
+		unit.Remediation = `run "sudo gpud up" to rewrite the systemd unit`
+	case !fileExists(execBin):
+		unit.Status = StatusFail
+		unit.Message = fmt.Sprintf("%s runs %s which does not exist", ck.unitFile, execBin)
+		unit.Remediation = fmt.Sprintf("install the gpud binary at %s, or run \"sudo gpud up\" to rewrite the systemd unit", execBin)
+	case ck.expectedUnit != nil && strings.TrimSpace(string(b)) != strings.TrimSpace(ck.expectedUnit()):
+		unit.Status = StatusWarn
+		unit.Message = fmt.Sprintf("%s differs from the unit shipped with this gpud version", ck.unitFile)
+		unit.Remediation = `review the local changes, or run "sudo gpud up" to rewrite the systemd unit (use a drop-in under /etc/systemd/system/gpud.service.d/ for overrides)`
+	default:
+		unit.Status = StatusOK
+		unit.Message = fmt.Sprintf("%s matches the expected unit", ck.unitFile)
+	}
+
+	env := Result{Name: "systemd environment file"}
+	if fileExists(ck.envFile) {
+		env.Status = StatusOK
+		env.Message = fmt.Sprintf("%s exists", ck.envFile)
+	} else {
+		env.Status = StatusFail
+		env.Message = fmt.Sprintf("%s not found (required by the systemd unit)", ck.envFile)
+		env.Remediation = `run "sudo gpud up" to create the environment file`
+	}
+	return []Result{unit, env}
+}
+
+// unitExecStartBinary returns the binary path of the ExecStart in the systemd unit.
+func unitExecStartBinary(unit string) string {
+	scanner := bufio.NewScanner(strings.NewReader(unit))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		v, ok := strings.CutPrefix(line, "ExecStart=")
+		if !ok {
+			continue
+		}
+		// strip the systemd command prefixes (e.g., "-", "@", "+")
+		v = strings.TrimLeft(v, "-@:+!")
+		fields := strings.Fields(v)
+		if len(fields) == 0 {
+			return ""
+		}
+		return fields[0]
+	}
+	return ""
+}
+
+func (ck *checker) checkPermissions() []Result {
+	var results []Result
+	for _, p := range []struct {
+		name string
+		path string
+		// required is true if the path must exist
+		required bool
+	}{
+		{name: "binary permissions", path: ck.binPath, required: true},
+		{name: "systemd unit permissions", path: ck.unitFile},
+		{name: "systemd environment file permissions", path: ck.envFile},
+		{name: "data directory permissions", path: ck.dataDir, required: true},
+		{name: "state file permissions", path: ck.stateFile},
+	} {
+		results = append(results, ck.checkPermission(p.name, p.path, p.required))
+	}
+	return results
+}
+
+// checkPermission checks the path is owned by the service user and is not writable by the others,
+// since gpud runs as root and loads the binary, the flags, and the state from these paths.
+func (ck *checker) checkPermission(name string, path string, required bool) Result {
+	r := Result{Name: name}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			r.Status = StatusSkip
+			r.Message = fmt.Sprintf("%s not found", path)
+			return r
+		}
+		r.Status = StatusFail
+		r.Message = fmt.Sprintf("failed to stat %s: %v", path, err)
+		if errors.Is(err, os.ErrNotExist) {
+			r.Remediation = `run "sudo gpud up" to install gpud`
+		}
+		return r
+	}
+
+	if uid, ok := ck.getOwner(info); ok && uid != serviceUID {
+		r.Status = StatusFail
+		r.Message = fmt.Sprintf("%s is owned by uid %d, not root", path, uid)
+		r.Remediation = fmt.Sprintf("sudo chown root:root %s", path)
+		return r
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0o022 != 0 {
+		fixed := mode &^ 0o022
+		r.Status = StatusFail
+		r.Message = fmt.Sprintf("%s is writable by group or others (mode %04o)", path, mode)
+		r.Remediation = fmt.Sprintf("sudo chmod %04o %s", fixed, path)
+		return r
+	}
+
+	r.Status = StatusOK
+	r.Message = fmt.Sprintf("%s (mode %04o)", path, mode)
+	return r
+}
+
+func (ck *checker) checkSELinux() Result {
+	r := Result{Name: "selinux"}
+
+	b, err := os.ReadFile(ck.selinuxEnforcePath)
+	if err != nil {
+		r.Status = StatusSkip
+		r.Message = "SELinux is not enabled"
+		return r
+	}
+	if strings.TrimSpace(string(b)) != "1" {
+		r.Status = StatusOK
+		r.Message = "SELinux is in the permissive mode"
+		return r
+	}
+
+	label, err := ck.getSELinuxLabel(ck.binPath)
+	if err != nil {
+		r.Status = StatusWarn
+		r.Message = fmt.Sprintf("SELinux is enforcing but failed to read the label of %s: %v", ck.binPath, err)
+		r.Remediation = fmt.Sprintf("check the label with \"ls -Z %s\"", ck.binPath)
+		return r
+	}
+	for _, t := range untrustedSELinuxTypes {
+		if strings.Contains(label, ":"+t+":") {
+			r.Status = StatusFail
+			r.Message = fmt.Sprintf("SELinux is enforcing and %s is labeled %q, which systemd cannot execute", ck.binPath, label)
+			r.Remediation = fmt.Sprintf("sudo restorecon -v %s", ck.binPath)
+			return r
+		}
+	}
+
+	r.Status = StatusOK
+	r.Message = fmt.Sprintf("SELinux is enforcing and %s is labeled %q", ck.binPath, label)
+	return r
+}
+
+func (ck *checker) checkAppArmor() Result {
+	r := Result{Name: "apparmor"}
+
+	b, err := os.ReadFile(ck.appArmorEnabledPath)
+	if err != nil || strings.TrimSpace(string(b)) != "Y" {
+		r.Status = StatusSkip
+		r.Message = "AppArmor is not enabled"
+		return r
+	}
+
+	b, err = os.ReadFile(ck.appArmorProfilesPath)
+	if err != nil {
+		r.Status = StatusWarn
+		r.Message = fmt.Sprintf("AppArmor is enabled but failed to read the profiles: %v", err)
+		r.Remediation = "re-run as root"
+		return r
+	}
+
+	// each line is "<profile> (<mode>)" (e.g., "/usr/local/bin/gpud (enforce)")
+	var enforced []string
+	for _, line := range strings.Split(string(b), "\n") {
+		profile, mode, ok := strings.Cut(strings.TrimSpace(line), " (")
+		if !ok || !strings.Contains(profile, "gpud") {
+			continue
+		}
+		if strings.TrimSuffix(mode, ")") == "enforce" {
+			enforced = append(enforced, profile)
+		}
+	}
+	if len(enforced) > 0 {
+		r.Status = StatusWarn
+		r.Message = fmt.Sprintf("AppArmor profile(s) %s confine gpud, which may block the access to the GPU devices, /sys, and /dev/kmsg", strings.Join(enforced, ", "))
+		r.Remediation = fmt.Sprintf("sudo aa-complain %s (or allow the access in the profile)", strings.Join(enforced, " "))
+		return r
+	}
+
+	r.Status = StatusOK
+	r.Message = "AppArmor is enabled and no profile confines gpud"
+	return r
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,186 @@
+package verifyinstall
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTarball(t *testing.T, path string, files map[string][]byte) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() {
+		_ = f.Close()
+	}()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, b := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(b))}))
+		_, err := tw.Write(b)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+func newTestChecker(t *testing.T) *checker {
+	t.Helper()
+
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "gpud")
+	require.NoError(t, os.WriteFile(binPath, []byte("binary"), 0o755))
+
+	dataDir := filepath.Join(dir, "data")
+	require.NoError(t, os.MkdirAll(dataDir, 0o755))
+
+	return &checker{
+		binPath:   binPath,
+		unitFile:  filepath.Join(dir, "gpud.service"),
+		envFile:   filepath.Join(dir, "gpud.env"),
+		dataDir:   dataDir,
+		stateFile: filepath.Join(dataDir, "gpud.state"),
+
+		selinuxEnforcePath:   filepath.Join(dir, "selinux-enforce"),
+		appArmorEnabledPath:  filepath.Join(dir, "apparmor-enabled"),
+		appArmorProfilesPath: filepath.Join(dir, "apparmor-profiles"),
+
+		getSELinuxLabel: func(string) (string, error) { return "", errors.New("not supported") },
+		getOwner:        func(os.FileInfo) (int, bool) { return 0, true },
+	}
+}
+
+func TestCheckBinarySignature(t *testing.T) {
+	ck := newTestChecker(t)
+
+	r := ck.checkBinarySignature(context.Background())
+	assert.Equal(t, StatusSkip, r.Status)
+
+	tarball := filepath.Join(t.TempDir(), "gpud.tgz")
+	writeTarball(t, tarball, map[string][]byte{"README.md": []byte("readme"), "gpud": []byte("binary")})
+	ck.verifyPackage = func(context.Context) (string, func(), error) { return tarball, func() {}, nil }
+	r = ck.checkBinarySignature(context.Background())
+	assert.Equal(t, StatusOK, r.Status, r.Message)
+
+	writeTarball(t, tarball, map[string][]byte{"gpud": []byte("other")})
+	r = ck.checkBinarySignature(context.Background())
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Contains(t, r.Message, "does not match")
+	assert.NotEmpty(t, r.Remediation)
+
+	writeTarball(t, tarball, map[string][]byte{"README.md": []byte("readme")})
+	r = ck.checkBinarySignature(context.Background())
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Contains(t, r.Message, "no gpud binary")
+
+	ck.verifyPackage = func(context.Context) (string, func(), error) { return "", nil, errors.New("signature not valid") }
+	r = ck.checkBinarySignature(context.Background())
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Contains(t, r.Message, "signature not valid")
+}
+
+func TestCheckSystemdUnit(t *testing.T) {
+	ck := newTestChecker(t)
+
+	results := ck.checkSystemdUnit()
+	require.Len(t, results, 1)
+	assert.Equal(t, StatusFail, results[0].Status)
+	assert.Contains(t, results[0].Remediation, "gpud up")
+
+	unit := "[Service]\nExecStart=" + ck.binPath + " run $FLAGS\n"
+	require.NoError(t, os.WriteFile(ck.unitFile, []byte(unit), 0o644))
+	ck.expectedUnit = func() string { return unit }
+	results = ck.checkSystemdUnit()
+	require.Len(t, results, 2)
+	assert.Equal(t, StatusOK, results[0].Status, results[0].Message)
+	assert.Equal(t, StatusFail, results[1].Status)
+
+	require.NoError(t, os.WriteFile(ck.envFile, []byte("FLAGS=\n"), 0o644))
+	results = ck.checkSystemdUnit()
+	assert.Equal(t, StatusOK, results[1].Status)
+
+	ck.expectedUnit = func() string { return unit + "Restart=always\n" }
+	results = ck.checkSystemdUnit()
+	assert.Equal(t, StatusWarn, results[0].Status)
+
+	require.NoError(t, os.WriteFile(ck.unitFile, []byte("[Service]\nExecStart=/nonexistent/gpud run\n"), 0o644))
+	results = ck.checkSystemdUnit()
+	assert.Equal(t, StatusFail, results[0].Status)
+	assert.Contains(t, results[0].Message, "/nonexistent/gpud")
+}
+
+func TestUnitExecStartBinary(t *testing.T) {
+	assert.Equal(t, "/usr/local/bin/gpud", unitExecStartBinary("[Service]\nExecStart=/usr/local/bin/gpud run $FLAGS\n"))
+	assert.Equal(t, "/usr/sbin/gpud", unitExecStartBinary("ExecStart=-/usr/sbin/gpud run"))
+	assert.Empty(t, unitExecStartBinary("[Service]\nType=simple\n"))
+}
+
+func TestCheckPermission(t *testing.T) {
+	ck := newTestChecker(t)
+
+	r := ck.checkPermission("binary", ck.binPath, true)
+	assert.Equal(t, StatusOK, r.Status, r.Message)
+
+	r = ck.checkPermission("state", ck.stateFile, false)
+	assert.Equal(t, StatusSkip, r.Status)
+
+	r = ck.checkPermission("state", ck.stateFile, true)
+	assert.Equal(t, StatusFail, r.Status)
+
+	require.NoError(t, os.Chmod(ck.binPath, 0o777))
+	r = ck.checkPermission("binary", ck.binPath, true)
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Equal(t, "sudo chmod 0755 "+ck.binPath, r.Remediation)
+
+	require.NoError(t, os.Chmod(ck.binPath, 0o755))
+	ck.getOwner = func(os.FileInfo) (int, bool) { return 1000, true }
+	r = ck.checkPermission("binary", ck.binPath, true)
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Equal(t, "sudo chown root:root "+ck.binPath, r.Remediation)
+}
+
+func TestCheckSELinux(t *testing.T) {
+	ck := newTestChecker(t)
+
+	assert.Equal(t, StatusSkip, ck.checkSELinux().Status)
+
+	require.NoError(t, os.WriteFile(ck.selinuxEnforcePath, []byte("0\n"), 0o644))
+	assert.Equal(t, StatusOK, ck.checkSELinux().Status)
+
+	require.NoError(t, os.WriteFile(ck.selinuxEnforcePath, []byte("1\n"), 0o644))
+	assert.Equal(t, StatusWarn, ck.checkSELinux().Status)
+
+	ck.getSELinuxLabel = func(string) (string, error) { return "unconfined_u:object_r:user_home_t:s0", nil }
+	r := ck.checkSELinux()
+	assert.Equal(t, StatusFail, r.Status)
+	assert.Equal(t, "sudo restorecon -v "+ck.binPath, r.Remediation)
+
+	ck.getSELinuxLabel = func(string) (string, error) { return "system_u:object_r:bin_t:s0", nil }
+	assert.Equal(t, StatusOK, ck.checkSELinux().Status)
+}
+
+func TestCheckAppArmor(t *testing.T) {
+	ck := newTestChecker(t)
+
+	assert.Equal(t, StatusSkip, ck.checkAppArmor().Status)
+
+	require.NoError(t, os.WriteFile(ck.appArmorEnabledPath, []byte("Y\n"), 0o644))
+	assert.Equal(t, StatusWarn, ck.checkAppArmor().Status)
+
+	require.NoError(t, os.WriteFile(ck.appArmorProfilesPath, []byte("/usr/sbin/sshd (enforce)\n/usr/local/bin/gpud (complain)\n"), 0o644))
+	assert.Equal(t, StatusOK, ck.checkAppArmor().Status)
+
+	require.NoError(t, os.WriteFile(ck.appArmorProfilesPath, []byte("/usr/local/bin/gpud (enforce)\n"), 0o644))
+	r := ck.checkAppArmor()
+	assert.Equal(t, StatusWarn, r.Status)
+	assert.Contains(t, r.Remediation, "aa-complain /usr/local/bin/gpud")
+}
@@ -0,0 +1,129 @@
+// Package verifyinstall implements the "verify-install" command
+// that checks the integrity of the gpud installation on the host.
+package verifyinstall
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	gpudcommon "github.com/leptonai/gpud/cmd/gpud/common"
+	cmdrelease "github.com/leptonai/gpud/cmd/gpud/release"
+	pkgconfig "github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/gpud-manager/systemd"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgupdate "github.com/leptonai/gpud/pkg/update"
+	"github.com/leptonai/gpud/version"
+)
+
+func Command(cliContext *cli.Context) error {
+	logLevel := cliContext.String("log-level")
+	zapLvl, err := log.ParseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLogger(log.CreateLogger(zapLvl, ""))
+
+	log.Logger.Debugw("starting verify-install command")
+
+	dataDir, err := gpudcommon.ResolveDataDir(cliContext)
+	if err != nil {
+		return err
+	}
+
+	binPath := cliContext.String("bin-path")
+	if binPath == "" {
+		binPath = systemd.DefaultBinPath
+		if !fileExists(binPath) && fileExists(systemd.DeprecatedDefaultBinPathSbin) {
+			// fallback to the old GPUd binary path
+			binPath = systemd.DeprecatedDefaultBinPathSbin
+		}
+	}
+
+	ck := &checker{
+		binPath:   binPath,
+		unitFile:  systemd.DefaultUnitFile,
+		envFile:   systemd.DefaultEnvFile,
+		dataDir:   dataDir,
+		stateFile: pkgconfig.StateFilePath(dataDir),
+
+		expectedUnit: systemd.GPUdServiceUnitFileContents,
+
+		selinuxEnforcePath:   defaultSELinuxEnforcePath,
+		appArmorEnabledPath:  defaultAppArmorEnabledPath,
+		appArmorProfilesPath: defaultAppArmorProfilesPath,
+
+		getSELinuxLabel: getSELinuxLabel,
+		getOwner:        getOwner,
+	}
+	if !cliContext.Bool("skip-signature") {
+		ck.verifyPackage = newPackageVerifier(
+			cliContext.String("package-path"),
+			cliContext.String("sig-path"),
+			cliContext.String("sign-pub-path"),
+			cliContext.String("url"),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results := ck.run(ctx)
+
+	failed := 0
+	for _, r := range results {
+		switch r.Status {
+		case StatusOK:
+			fmt.Printf("%s %s: %s\n", cmdcommon.CheckMark, r.Name, r.Message)
+		case StatusSkip:
+			fmt.Printf("- %s: %s (skipped)\n", r.Name, r.Message)
+		case StatusWarn:
+			fmt.Printf("%s %s (warning): %s\n", cmdcommon.WarningSign, r.Name, r.Message)
+		case StatusFail:
+			failed++
+			fmt.Printf("%s %s: %s\n", cmdcommon.WarningSign, r.Name, r.Message)
+		}
+		if r.Remediation != "" {
+			fmt.Printf("    remediation: %s\n", r.Remediation)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d installation check(s) failed", failed)
+	}
+	return nil
+}
+
+// newPackageVerifier returns the function that returns the path of the signature-verified release tarball.
+// If the package is not given, it downloads the release package of the running version,
+// verified with the signing keys issued by the embedded root keys.
+func newPackageVerifier(packagePath, sigPath, signPubPath, pkgAddr string) func(ctx context.Context) (string, func(), error) {
+	if packagePath != "" {
+		return func(_ context.Context) (string, func(), error) {
+			if sigPath == "" || signPubPath == "" {
+				return "", nil, fmt.Errorf(`"--sig-path" and "--sign-pub-path" are required with "--package-path"`)
+			}
+			if err := cmdrelease.VerifyPackageSignature(signPubPath, packagePath, sigPath); err != nil {
+				return "", nil, err
+			}
+			return packagePath, func() {}, nil
+		}
+	}
+
+	return func(ctx context.Context) (string, func(), error) {
+		if pkgAddr == "" {
+			pkgAddr = version.DefaultURLPrefix
+		}
+		dlPath, err := pkgupdate.DownloadTarball(ctx, version.Version, pkgAddr)
+		if err != nil {
+			return "", nil, err
+		}
+		return dlPath, func() {
+			_ = os.Remove(dlPath)
+		}, nil
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package verifyinstall
+
+import (
+	"errors"
+	"os"
+)
+
+func getOwner(_ os.FileInfo) (int, bool) {
+	return 0, false
+}
+
+func getSELinuxLabel(_ string) (string, error) {
+	return "", errors.New("not supported")
+}
@@ -0,0 +1,281 @@
+package bandwidthasymmetry
+
+import (
+	"sort"
+
+	componentsnvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// Interconnect is the GPU interconnect whose throughput is compared between the GPUs.
+type Interconnect string
+
+const (
+	InterconnectNVLink Interconnect = "nvlink"
+	InterconnectPCIe   Interconnect = "pcie"
+)
+
+// linkPCIe is the link of the PCIe throughput, as a GPU has a single PCIe link.
+const linkPCIe = "pcie"
+
+var (
+	// the TX and RX throughput of each link are summed
+	metricNamesNVLink = map[string]struct{}{
+		componentsnvidianvlink.SubSystem + "_link_tx_bytes_per_second": {},
+		componentsnvidianvlink.SubSystem + "_link_rx_bytes_per_second": {},
+	}
+	metricNamesPCIe = map[string]struct{}{
+		componentsnvidiautilization.SubSystem + "_pcie_tx_bytes_per_second": {},
+		componentsnvidiautilization.SubSystem + "_pcie_rx_bytes_per_second": {},
+	}
+)
+
+// LinkBandwidth is the mean throughput of a link of the GPU within the window,
+// compared to the links of the peer GPUs over the same samples.
+type LinkBandwidth struct {
+	// Link is the NVLink number, or "pcie".
+	Link string `json:"link"`
+	// BytesPerSecond is the mean TX and RX throughput of the link.
+	BytesPerSecond float64 `json:"bytes_per_second"`
+	// PeerMedianBytesPerSecond is the median of the mean throughput of the peer GPU links.
+	PeerMedianBytesPerSecond float64 `json:"peer_median_bytes_per_second"`
+	// Ratio is the ratio of the link throughput to the peer median.
+	Ratio float64 `json:"ratio"`
+}
+
+// GPUBandwidth is the interconnect throughput of a GPU within the window,
+// compared to its peer GPUs on the same node.
+type GPUBandwidth struct {
+	// UUID is the GPU UUID.
+	UUID string `json:"uuid"`
+	// Index is the GPU index, empty if unknown.
+	Index string `json:"index,omitempty"`
+	// Interconnect is the interconnect compared.
+	Interconnect Interconnect `json:"interconnect"`
+
+	// BytesPerSecond is the mean throughput of the GPU over the active samples.
+	BytesPerSecond float64 `json:"bytes_per_second"`
+	// PeerMedianBytesPerSecond is the mean of the peer median throughput over the active samples.
+	PeerMedianBytesPerSecond float64 `json:"peer_median_bytes_per_second"`
+	// Ratio is the ratio of the GPU throughput to the peer median.
+	Ratio float64 `json:"ratio"`
+
+	// ActiveSamples is the number of the samples with the peer GPUs active.
+	ActiveSamples int `json:"active_samples"`
+	// AsymmetricSamples is the number of the active samples below the min ratio.
+	AsymmetricSamples int `json:"asymmetric_samples"`
+
+	// Asymmetric is true if the GPU is consistently slower than its peers.
+	Asymmetric bool `json:"asymmetric"`
+	// Links is the per-link evidence of the asymmetric GPU,
+	// in the ascending order of the ratio (i.e., the slowest link first).
+	Links []LinkBandwidth `json:"links,omitempty"`
+}
+
+// linkSamples is the throughput data points keyed by the GPU UUID, the link,
+// and the unix milliseconds, as the data points of a scrape share the timestamp.
+type linkSamples map[string]map[string]map[int64]float64
+
+func (s linkSamples) add(uuid string, link string, ts int64, v float64) {
+	links, ok := s[uuid]
+	if !ok {
+		links = make(map[string]map[int64]float64)
+		s[uuid] = links
+	}
+	points, ok := links[link]
+	if !ok {
+		points = make(map[int64]float64)
+		links[link] = points
+	}
+	points[ts] += v
+}
+
+// analyze compares the interconnect throughput of each GPU to its peers,
+// in the order of the interconnect and the GPU UUID.
+// Only the GPUs with enough active samples are evaluated.
+func analyze(cfg Config, ms pkgmetrics.Metrics) []GPUBandwidth {
+	samples := map[Interconnect]linkSamples{
+		InterconnectNVLink: make(linkSamples),
+		InterconnectPCIe:   make(linkSamples),
+	}
+	indexes := make(map[string]string)
+	for _, m := range ms {
+		uuid := m.Labels[nvidianvml.LabelGPUUUID]
+		if uuid == "" {
+			continue
+		}
+		if _, ok := metricNamesNVLink[m.Name]; ok {
+			samples[InterconnectNVLink].add(uuid, m.Labels["link"], m.UnixMilliseconds, m.Value)
+		} else if _, ok := metricNamesPCIe[m.Name]; ok {
+			samples[InterconnectPCIe].add(uuid, linkPCIe, m.UnixMilliseconds, m.Value)
+		} else {
+			continue
+		}
+		if idx := m.Labels[nvidianvml.LabelGPUIndex]; idx != "" {
+			indexes[uuid] = idx
+		}
+	}
+
+	var results []GPUBandwidth
+	for _, ic := range []Interconnect{InterconnectNVLink, InterconnectPCIe} {
+		results = append(results, analyzeInterconnect(cfg, ic, samples[ic], indexes)...)
+	}
+	return results
+}
+
+// gpuAccumulator accumulates the active samples of a GPU.
+type gpuAccumulator struct {
+	active     int
+	asymmetric int
+	sum        float64
+	peerSum    float64
+	// the timestamps of the active samples, to compare the links over the same samples
+	activeTimes map[int64]struct{}
+}
+
+func analyzeInterconnect(cfg Config, ic Interconnect, samples linkSamples, indexes map[string]string) []GPUBandwidth {
+	// the total throughput of all links per GPU, keyed by the timestamp
+	totals := make(map[int64]map[string]float64)
+	for uuid, links := range samples {
+		for _, points := range links {
+			for ts, v := range points {
+				if totals[ts] == nil {
+					totals[ts] = make(map[string]float64)
+				}
+				totals[ts][uuid] += v
+			}
+		}
+	}
+
+	accs := make(map[string]*gpuAccumulator)
+	for ts, gpus := range totals {
+		if len(gpus) < 2 {
+			continue
+		}
+		for uuid, v := range gpus {
+			peers := make([]float64, 0, len(gpus)-1)
+			for peer, pv := range gpus {
+				if peer != uuid {
+					peers = append(peers, pv)
+				}
+			}
+			peerMedian := median(peers)
+			if peerMedian < cfg.minActiveBytesPerSecond() {
+				continue
+			}
+
+			acc, ok := accs[uuid]
+			if !ok {
+				acc = &gpuAccumulator{activeTimes: make(map[int64]struct{})}
+				accs[uuid] = acc
+			}
+			acc.active++
+			acc.sum += v
+			acc.peerSum += peerMedian
+			acc.activeTimes[ts] = struct{}{}
+			if v < cfg.minRatio()*peerMedian {
+				acc.asymmetric++
+			}
+		}
+	}
+
+	results := make([]GPUBandwidth, 0, len(accs))
+	for uuid, acc := range accs {
+		if acc.active < cfg.minSamples() {
+			continue
+		}
+		r := GPUBandwidth{
+			UUID:                     uuid,
+			Index:                    indexes[uuid],
+			Interconnect:             ic,
+			BytesPerSecond:           acc.sum / float64(acc.active),
+			PeerMedianBytesPerSecond: acc.peerSum / float64(acc.active),
+			ActiveSamples:            acc.active,
+			AsymmetricSamples:        acc.asymmetric,
+		}
+		if r.PeerMedianBytesPerSecond > 0 {
+			r.Ratio = r.BytesPerSecond / r.PeerMedianBytesPerSecond
+		}
+		r.Asymmetric = float64(acc.asymmetric)/float64(acc.active) >= cfg.minAsymmetricFraction()
+		if r.Asymmetric {
+			r.Links = compareLinks(samples, uuid, acc.activeTimes)
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UUID < results[j].UUID
+	})
+	return results
+}
+
+// compareLinks compares the mean throughput of each link of the GPU
+// to the median of the peer GPU links, over the active samples of the GPU.
+func compareLinks(samples linkSamples, uuid string, activeTimes map[int64]struct{}) []LinkBandwidth {
+	var peerMeans []float64
+	for peer, links := range samples {
+		if peer == uuid {
+			continue
+		}
+		for _, points := range links {
+			if m, ok := meanAt(points, activeTimes); ok {
+				peerMeans = append(peerMeans, m)
+			}
+		}
+	}
+	peerMedian := median(peerMeans)
+
+	links := make([]LinkBandwidth, 0, len(samples[uuid]))
+	for link, points := range samples[uuid] {
+		m, ok := meanAt(points, activeTimes)
+		if !ok {
+			continue
+		}
+		l := LinkBandwidth{
+			Link:                     link,
+			BytesPerSecond:           m,
+			PeerMedianBytesPerSecond: peerMedian,
+		}
+		if peerMedian > 0 {
+			l.Ratio = m / peerMedian
+		}
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Ratio != links[j].Ratio {
+			return links[i].Ratio < links[j].Ratio
+		}
+		return links[i].Link < links[j].Link
+	})
+	return links
+}
+
+// meanAt returns the mean of the data points at the timestamps, or false if none.
+func meanAt(points map[int64]float64, times map[int64]struct{}) (float64, bool) {
+	sum, n := 0.0, 0
+	for ts := range times {
+		if v, ok := points[ts]; ok {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// median returns the median of the values, or zero if empty.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
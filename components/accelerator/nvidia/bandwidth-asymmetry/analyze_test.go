@@ -0,0 +1,150 @@
+package bandwidthasymmetry
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	componentsnvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const gbps = 1e9
+
+var testStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func nvlinkMetric(ts time.Time, uuid string, index string, link int, tx float64, rx float64) pkgmetrics.Metrics {
+	labels := map[string]string{"gpu_uuid": uuid, "gpu_index": index, "link": strconv.Itoa(link)}
+	return pkgmetrics.Metrics{
+		{UnixMilliseconds: ts.UnixMilli(), Component: componentsnvidianvlink.Name, Name: componentsnvidianvlink.SubSystem + "_link_tx_bytes_per_second", Labels: labels, Value: tx},
+		{UnixMilliseconds: ts.UnixMilli(), Component: componentsnvidianvlink.Name, Name: componentsnvidianvlink.SubSystem + "_link_rx_bytes_per_second", Labels: labels, Value: rx},
+	}
+}
+
+func pcieMetric(ts time.Time, uuid string, index string, tx float64, rx float64) pkgmetrics.Metrics {
+	labels := map[string]string{"gpu_uuid": uuid, "gpu_index": index}
+	return pkgmetrics.Metrics{
+		{UnixMilliseconds: ts.UnixMilli(), Component: componentsnvidiautilization.Name, Name: componentsnvidiautilization.SubSystem + "_pcie_tx_bytes_per_second", Labels: labels, Value: tx},
+		{UnixMilliseconds: ts.UnixMilli(), Component: componentsnvidiautilization.Name, Name: componentsnvidiautilization.SubSystem + "_pcie_rx_bytes_per_second", Labels: labels, Value: rx},
+	}
+}
+
+// testMetrics returns the samples of 4 GPUs with 4 NVLinks each at 10 GB/s per direction,
+// where the slow GPU has the link 2 at 1 GB/s per direction.
+func testMetrics(samples int, slowUUID string) pkgmetrics.Metrics {
+	var ms pkgmetrics.Metrics
+	for i := 0; i < samples; i++ {
+		ts := testStart.Add(time.Duration(i) * time.Minute)
+		for idx, uuid := range []string{"GPU-a", "GPU-b", "GPU-c", "GPU-d"} {
+			for link := 0; link < 4; link++ {
+				v := 10 * gbps
+				if uuid == slowUUID && link == 2 {
+					v = gbps
+				}
+				ms = append(ms, nvlinkMetric(ts, uuid, strconv.Itoa(idx), link, v, v)...)
+			}
+			ms = append(ms, pcieMetric(ts, uuid, strconv.Itoa(idx), 5*gbps, 5*gbps)...)
+		}
+	}
+	return ms
+}
+
+func TestAnalyzeSymmetric(t *testing.T) {
+	results := analyze(Config{}, testMetrics(DefaultMinSamples, ""))
+	require.Len(t, results, 8)
+	for _, r := range results {
+		assert.False(t, r.Asymmetric, r.UUID)
+		assert.Equal(t, 1.0, r.Ratio)
+		assert.Equal(t, DefaultMinSamples, r.ActiveSamples)
+		assert.Empty(t, r.Links)
+	}
+	assert.Equal(t, InterconnectNVLink, results[0].Interconnect)
+	assert.Equal(t, InterconnectPCIe, results[4].Interconnect)
+}
+
+func TestAnalyzeAsymmetricLink(t *testing.T) {
+	// the 2 of 4 links is not enough to drop below the 70% ratio
+	cfg := Config{MinRatio: 0.8}
+	results := analyze(cfg, testMetrics(DefaultMinSamples, "GPU-c"))
+	require.Len(t, results, 8)
+
+	var asymmetric []GPUBandwidth
+	for _, r := range results {
+		if r.Asymmetric {
+			asymmetric = append(asymmetric, r)
+		}
+	}
+	require.Len(t, asymmetric, 1)
+	g := asymmetric[0]
+	assert.Equal(t, "GPU-c", g.UUID)
+	assert.Equal(t, "2", g.Index)
+	assert.Equal(t, InterconnectNVLink, g.Interconnect)
+	// (3*20 + 2) / 80 GB/s
+	assert.InDelta(t, 62.0/80.0, g.Ratio, 1e-9)
+	assert.Equal(t, DefaultMinSamples, g.AsymmetricSamples)
+
+	// the slowest link first
+	require.Len(t, g.Links, 4)
+	assert.Equal(t, "2", g.Links[0].Link)
+	assert.InDelta(t, 0.1, g.Links[0].Ratio, 1e-9)
+	assert.Equal(t, 20*gbps, g.Links[0].PeerMedianBytesPerSecond)
+	assert.Equal(t, 1.0, g.Links[1].Ratio)
+
+	assert.Equal(t, "GPU-c nvlink at 78% of the peer median (links 2 at 10%)", formatAsymmetry(g, cfg.minRatio()))
+}
+
+func TestAnalyzeNotEnoughSamples(t *testing.T) {
+	results := analyze(Config{MinRatio: 0.8}, testMetrics(DefaultMinSamples-1, "GPU-c"))
+	assert.Empty(t, results)
+}
+
+func TestAnalyzeIdle(t *testing.T) {
+	var ms pkgmetrics.Metrics
+	for i := 0; i < DefaultMinSamples; i++ {
+		ts := testStart.Add(time.Duration(i) * time.Minute)
+		// the peers below the active throughput are not compared
+		ms = append(ms, pcieMetric(ts, "GPU-a", "0", 100, 100)...)
+		ms = append(ms, pcieMetric(ts, "GPU-b", "1", 0, 0)...)
+		ms = append(ms, pcieMetric(ts, "GPU-c", "2", 100, 100)...)
+	}
+	assert.Empty(t, analyze(Config{}, ms))
+}
+
+func TestAnalyzeInconsistent(t *testing.T) {
+	var ms pkgmetrics.Metrics
+	for i := 0; i < 2*DefaultMinSamples; i++ {
+		ts := testStart.Add(time.Duration(i) * time.Minute)
+		// slower only half of the time
+		slow := 5 * gbps
+		if i%2 == 0 {
+			slow = gbps
+		}
+		ms = append(ms, pcieMetric(ts, "GPU-a", "0", 5*gbps, 5*gbps)...)
+		ms = append(ms, pcieMetric(ts, "GPU-b", "1", slow, slow)...)
+		ms = append(ms, pcieMetric(ts, "GPU-c", "2", 5*gbps, 5*gbps)...)
+	}
+
+	results := analyze(Config{}, ms)
+	require.Len(t, results, 3)
+	b := results[1]
+	assert.Equal(t, "GPU-b", b.UUID)
+	assert.Equal(t, DefaultMinSamples, b.AsymmetricSamples)
+	assert.False(t, b.Asymmetric)
+
+	results = analyze(Config{MinAsymmetricFraction: 0.5}, ms)
+	require.Len(t, results, 3)
+	assert.True(t, results[1].Asymmetric)
+	require.Len(t, results[1].Links, 1)
+	assert.Equal(t, linkPCIe, results[1].Links[0].Link)
+	assert.Equal(t, "GPU-b pcie at 60% of the peer median", formatAsymmetry(results[1], DefaultMinRatio))
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 0.0, median(nil))
+	assert.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+}
@@ -0,0 +1,311 @@
+// Package bandwidthasymmetry detects the GPUs consistently achieving the lower NVLink
+// or PCIe throughput than their peer GPUs on the same node, from the metrics store.
+// The asymmetric links degrade the collective communication (e.g., all-reduce)
+// of all the GPUs, even if the absolute thresholds are never crossed.
+package bandwidthasymmetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentsnvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
+)
+
+// Name is the ID of the NVIDIA interconnect bandwidth asymmetry component.
+const Name = "accelerator-nvidia-bandwidth-asymmetry"
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	nvmlInstance nvidianvml.Instance
+	metricsStore pkgmetrics.Store
+
+	getTimeNowFunc func() time.Time
+	getConfigFunc  func() Config
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the NVIDIA interconnect bandwidth asymmetry component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+		metricsStore: gpudInstance.MetricsStore,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: GetDefaultConfig,
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	// the integrated GPUs of Jetson/Tegra have no NVLink or PCIe peers
+	if nvidiategra.IsTegra() {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu interconnect bandwidth asymmetry")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.metricsStore == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no metrics store"
+		return cr
+	}
+
+	cfg := c.getConfigFunc()
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+	ms, err := c.metricsStore.Read(cctx,
+		pkgmetrics.WithSince(cr.ts.Add(-cfg.window())),
+		pkgmetrics.WithComponents(componentsnvidianvlink.Name, componentsnvidiautilization.Name),
+	)
+	ccancel()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading metrics"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	cr.GPUs = analyze(cfg, ms)
+
+	metricRatio.Reset()
+	metricAsymmetric.Reset()
+	var issues []string
+	for _, g := range cr.GPUs {
+		labels := prometheus.Labels{
+			nvidianvml.LabelGPUUUID:  g.UUID,
+			nvidianvml.LabelGPUIndex: g.Index,
+			"interconnect":           string(g.Interconnect),
+		}
+		metricRatio.With(labels).Set(g.Ratio)
+		if !g.Asymmetric {
+			metricAsymmetric.With(labels).Set(0)
+			continue
+		}
+		metricAsymmetric.With(labels).Set(1)
+
+		issues = append(issues, formatAsymmetry(g, cfg.minRatio()))
+		log.Logger.Warnw("interconnect bandwidth asymmetry detected", "uuid", g.UUID, "interconnect", g.Interconnect, "ratio", g.Ratio, "links", g.Links)
+	}
+
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("interconnect bandwidth asymmetry on %d GPU(s) (%s)", len(issues), strings.Join(issues, "; "))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "the interconnect links of the GPU(s) are consistently slower than the peer GPUs on the same node, degrading the collective communication of all GPUs -- inspect the links (e.g., 'nvidia-smi nvlink -s' for the NVLink speeds, 'lspci -vv' for the PCIe link width and speed)",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeHardwareInspection,
+			},
+		}
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	if len(cr.GPUs) == 0 {
+		cr.reason = "not enough active interconnect samples to compare the GPUs"
+	} else {
+		cr.reason = fmt.Sprintf("compared %d GPU interconnect(s), no bandwidth asymmetry found", len(cr.GPUs))
+	}
+	return cr
+}
+
+// formatAsymmetry formats the asymmetric GPU with the links below the ratio, for example,
+// "GPU-b nvlink at 45% of the peer median (links 3 at 20%, 5 at 40%)".
+func formatAsymmetry(g GPUBandwidth, minRatio float64) string {
+	s := fmt.Sprintf("%s %s at %.0f%% of the peer median", g.UUID, g.Interconnect, g.Ratio*100)
+	if g.Interconnect == InterconnectPCIe {
+		return s
+	}
+
+	var links []string
+	for _, l := range g.Links {
+		if l.Ratio < minRatio {
+			links = append(links, fmt.Sprintf("%s at %.0f%%", l.Link, l.Ratio*100))
+		}
+	}
+	if len(links) == 0 {
+		return s + " (all links uniformly slower)"
+	}
+	return s + " (links " + strings.Join(links, ", ") + ")"
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// GPUs is the interconnect throughput of the GPUs with enough active samples.
+	GPUs []GPUBandwidth `json:"gpus,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.GPUs) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "Interconnect", "GB/s", "Peer Median GB/s", "Ratio", "Asymmetric"})
+	for _, g := range cr.GPUs {
+		table.Append([]string{
+			g.UUID,
+			string(g.Interconnect),
+			fmt.Sprintf("%.2f", g.BytesPerSecond/1e9),
+			fmt.Sprintf("%.2f", g.PeerMedianBytesPerSecond/1e9),
+			fmt.Sprintf("%.2f", g.Ratio),
+			fmt.Sprintf("%t", g.Asymmetric),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.suggestedActions,
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.GPUs) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,127 @@
+package bandwidthasymmetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentstesting "github.com/leptonai/gpud/components/testing"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+type errMetricsStore struct {
+	pkgmetrics.Store
+}
+
+func (s *errMetricsStore) Read(_ context.Context, _ ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	return nil, errors.New("read failed")
+}
+
+// MockBandwidthAsymmetryComponent creates a component with the mocked metrics store,
+// checking at the time all the default minimum samples of the test metrics are recorded.
+func MockBandwidthAsymmetryComponent(ctx context.Context, metricsStore pkgmetrics.Store) components.Component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:          cctx,
+		cancel:       cancel,
+		metricsStore: metricsStore,
+		getTimeNowFunc: func() time.Time {
+			return testStart.Add(DefaultMinSamples * time.Minute)
+		},
+		getConfigFunc: GetDefaultConfig,
+	}
+}
+
+func mustComponent(t *testing.T, c components.Component) *component {
+	t.Helper()
+	comp, ok := c.(*component)
+	require.True(t, ok)
+	return comp
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.False(t, c.IsSupported())
+}
+
+func TestComponentNoMetricsStore(t *testing.T) {
+	c := MockBandwidthAsymmetryComponent(context.Background(), nil)
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "no metrics store", cr.Summary())
+}
+
+func TestComponentCheckReadError(t *testing.T) {
+	c := MockBandwidthAsymmetryComponent(context.Background(), &errMetricsStore{})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "error reading metrics", cr.Summary())
+}
+
+func TestComponentCheck(t *testing.T) {
+	store := componentstesting.NewMetricsStore()
+	c := mustComponent(t, MockBandwidthAsymmetryComponent(context.Background(), store))
+	defer c.Close()
+	c.getConfigFunc = func() Config { return Config{MinRatio: 0.8} }
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "not enough active interconnect samples to compare the GPUs", cr.Summary())
+	assert.Equal(t, "no data", cr.String())
+
+	require.NoError(t, store.Record(context.Background(), testMetrics(DefaultMinSamples, "")...))
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "compared 8 GPU interconnect(s), no bandwidth asymmetry found", cr.Summary())
+
+	store = componentstesting.NewMetricsStore()
+	require.NoError(t, store.Record(context.Background(), testMetrics(DefaultMinSamples, "GPU-c")...))
+	c.metricsStore = store
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "interconnect bandwidth asymmetry on 1 GPU(s) (GPU-c nvlink at 78% of the peer median (links 2 at 10%))", cr.Summary())
+	assert.Contains(t, cr.String(), "GPU-c")
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	require.NotNil(t, states[0].SuggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, states[0].SuggestedActions.RepairActions)
+
+	var data checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &data))
+	require.Len(t, data.GPUs, 8)
+	assert.True(t, data.GPUs[2].Asymmetric)
+	assert.Equal(t, "2", data.GPUs[2].Links[0].Link)
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateType(""), cr.HealthStateType())
+
+	states := cr.HealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
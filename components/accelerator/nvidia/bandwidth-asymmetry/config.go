@@ -0,0 +1,125 @@
+package bandwidthasymmetry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultWindow is the default duration of the metrics to compare the GPUs over.
+	DefaultWindow = 30 * time.Minute
+	// DefaultMinRatio is the default ratio of the GPU throughput to the median
+	// of its peer GPUs, below which the sample is asymmetric.
+	DefaultMinRatio = 0.7
+	// DefaultMinActiveBytesPerSecond is the default median throughput of the peer GPUs,
+	// at or above which the sample is compared, to not compare the idle GPUs.
+	DefaultMinActiveBytesPerSecond = 1_000_000_000
+	// DefaultMinSamples is the default number of the active samples of a GPU
+	// within the window, before evaluating the asymmetry
+	// (10 minutes with the default metrics sync interval).
+	DefaultMinSamples = 10
+	// DefaultMinAsymmetricFraction is the default fraction of the active samples
+	// to be asymmetric, at or above which the GPU is consistently slower than its peers.
+	DefaultMinAsymmetricFraction = 0.8
+)
+
+// Config configures the interconnect bandwidth asymmetry detection.
+type Config struct {
+	// Window is the duration of the metrics to compare the GPUs over.
+	// Defaults to DefaultWindow if zero.
+	Window metav1.Duration `json:"window,omitempty"`
+	// MinRatio is the ratio in (0, 1) of the GPU throughput to the median of its peers.
+	// Defaults to DefaultMinRatio if zero.
+	MinRatio float64 `json:"min_ratio,omitempty"`
+	// MinActiveBytesPerSecond is the median throughput of the peer GPUs to compare the sample.
+	// Defaults to DefaultMinActiveBytesPerSecond if zero.
+	MinActiveBytesPerSecond float64 `json:"min_active_bytes_per_second,omitempty"`
+	// MinSamples is the number of the active samples of a GPU to evaluate.
+	// Defaults to DefaultMinSamples if zero.
+	MinSamples int `json:"min_samples,omitempty"`
+	// MinAsymmetricFraction is the fraction in (0, 1] of the active samples to be asymmetric.
+	// Defaults to DefaultMinAsymmetricFraction if zero.
+	MinAsymmetricFraction float64 `json:"min_asymmetric_fraction,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.Window.Duration < 0 {
+		return fmt.Errorf("window must be non-negative, got %s", cfg.Window.Duration)
+	}
+	if cfg.MinRatio < 0 || cfg.MinRatio >= 1 {
+		return fmt.Errorf("min_ratio must be in (0, 1), got %v", cfg.MinRatio)
+	}
+	if cfg.MinActiveBytesPerSecond < 0 {
+		return fmt.Errorf("min_active_bytes_per_second must be non-negative, got %v", cfg.MinActiveBytesPerSecond)
+	}
+	if cfg.MinSamples < 0 {
+		return fmt.Errorf("min_samples must be non-negative, got %d", cfg.MinSamples)
+	}
+	if cfg.MinAsymmetricFraction < 0 || cfg.MinAsymmetricFraction > 1 {
+		return fmt.Errorf("min_asymmetric_fraction must be in (0, 1], got %v", cfg.MinAsymmetricFraction)
+	}
+	return nil
+}
+
+func (cfg Config) window() time.Duration {
+	if cfg.Window.Duration > 0 {
+		return cfg.Window.Duration
+	}
+	return DefaultWindow
+}
+
+func (cfg Config) minRatio() float64 {
+	if cfg.MinRatio > 0 {
+		return cfg.MinRatio
+	}
+	return DefaultMinRatio
+}
+
+func (cfg Config) minActiveBytesPerSecond() float64 {
+	if cfg.MinActiveBytesPerSecond > 0 {
+		return cfg.MinActiveBytesPerSecond
+	}
+	return DefaultMinActiveBytesPerSecond
+}
+
+func (cfg Config) minSamples() int {
+	if cfg.MinSamples > 0 {
+		return cfg.MinSamples
+	}
+	return DefaultMinSamples
+}
+
+func (cfg Config) minAsymmetricFraction() float64 {
+	if cfg.MinAsymmetricFraction > 0 {
+		return cfg.MinAsymmetricFraction
+	}
+	return DefaultMinAsymmetricFraction
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default bandwidth asymmetry config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default bandwidth asymmetry config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default bandwidth asymmetry config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
@@ -0,0 +1,29 @@
+package bandwidthasymmetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Window: metav1.Duration{Duration: time.Hour}, MinRatio: 0.5, MinAsymmetricFraction: 1}.Validate())
+
+	assert.Error(t, Config{Window: metav1.Duration{Duration: -time.Second}}.Validate())
+	assert.Error(t, Config{MinRatio: 1}.Validate())
+	assert.Error(t, Config{MinActiveBytesPerSecond: -1}.Validate())
+	assert.Error(t, Config{MinSamples: -1}.Validate())
+	assert.Error(t, Config{MinAsymmetricFraction: 1.5}.Validate())
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := Config{}
+	assert.Equal(t, DefaultWindow, cfg.window())
+	assert.Equal(t, DefaultMinRatio, cfg.minRatio())
+	assert.Equal(t, float64(DefaultMinActiveBytesPerSecond), cfg.minActiveBytesPerSecond())
+	assert.Equal(t, DefaultMinSamples, cfg.minSamples())
+	assert.Equal(t, DefaultMinAsymmetricFraction, cfg.minAsymmetricFraction())
+}
@@ -0,0 +1,49 @@
+package bandwidthasymmetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA bandwidth asymmetry component.
+const SubSystem = "accelerator_nvidia_bandwidth_asymmetry"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "ratio",
+			Help:      "tracks the ratio of the GPU interconnect throughput to the median of its peer GPUs within the window",
+		},
+		nvidianvml.GPUMetricLabelKeys("interconnect"), // labels are GPU UUID, index and interconnect
+	).MustCurryWith(componentLabel)
+
+	metricAsymmetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "asymmetric",
+			Help:      "tracks whether the GPU interconnect throughput is consistently below its peer GPUs within the window",
+		},
+		nvidianvml.GPUMetricLabelKeys("interconnect"), // labels are GPU UUID, index and interconnect
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricRatio,
+		metricAsymmetric,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_ratio", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitRatio},
+		apiv1.MetricMetadata{Name: SubSystem + "_asymmetric", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
+}
@@ -0,0 +1,132 @@
+package clockspeed
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// reasonApplicationsClocksSetting is the clock event reason of the clocks limited
+// by the applications clocks (e.g., "nvidia-smi -ac") or the locked clocks (e.g., "nvidia-smi -lgc").
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlClocksEventReasons.html
+const reasonApplicationsClocksSetting uint64 = 0x0000000000000002
+
+// ClockLimits represents the max, the applications, and the default applications clocks in MHz,
+// zero if not supported by the device.
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+type ClockLimits struct {
+	// Represents the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// BusID is the GPU bus ID from the nvml API.
+	//  e.g., "0000:0f:00.0"
+	BusID string `json:"bus_id"`
+
+	// MaxGraphicsMHz and MaxMemoryMHz are the rated max (boost) clocks.
+	MaxGraphicsMHz uint32 `json:"max_graphics_mhz"`
+	MaxMemoryMHz   uint32 `json:"max_memory_mhz"`
+
+	// ApplicationsGraphicsMHz and ApplicationsMemoryMHz are the target clocks of the applications
+	// (e.g., set by "nvidia-smi -ac").
+	ApplicationsGraphicsMHz uint32 `json:"applications_graphics_mhz"`
+	ApplicationsMemoryMHz   uint32 `json:"applications_memory_mhz"`
+
+	// DefaultApplicationsGraphicsMHz and DefaultApplicationsMemoryMHz are the default target clocks
+	// of the applications, before any "nvidia-smi -ac".
+	DefaultApplicationsGraphicsMHz uint32 `json:"default_applications_graphics_mhz"`
+	DefaultApplicationsMemoryMHz   uint32 `json:"default_applications_memory_mhz"`
+
+	// ApplicationsClocksLimited is true if the clocks are currently limited
+	// by the applications or the locked clocks setting.
+	ApplicationsClocksLimited bool `json:"applications_clocks_limited"`
+}
+
+// GetClockLimits returns the max, the applications, and the default applications clocks for a GPU.
+func GetClockLimits(uuid string, dev device.Device) (ClockLimits, error) {
+	limits := ClockLimits{
+		UUID:  uuid,
+		BusID: dev.PCIBusID(),
+	}
+
+	queries := []struct {
+		name      string
+		f         func(nvml.ClockType) (uint32, nvml.Return)
+		clockType nvml.ClockType
+		v         *uint32
+	}{
+		{"max clock info for nvml.CLOCK_GRAPHICS", dev.GetMaxClockInfo, nvml.CLOCK_GRAPHICS, &limits.MaxGraphicsMHz},
+		{"max clock info for nvml.CLOCK_MEM", dev.GetMaxClockInfo, nvml.CLOCK_MEM, &limits.MaxMemoryMHz},
+		{"applications clock for nvml.CLOCK_GRAPHICS", dev.GetApplicationsClock, nvml.CLOCK_GRAPHICS, &limits.ApplicationsGraphicsMHz},
+		{"applications clock for nvml.CLOCK_MEM", dev.GetApplicationsClock, nvml.CLOCK_MEM, &limits.ApplicationsMemoryMHz},
+		{"default applications clock for nvml.CLOCK_GRAPHICS", dev.GetDefaultApplicationsClock, nvml.CLOCK_GRAPHICS, &limits.DefaultApplicationsGraphicsMHz},
+		{"default applications clock for nvml.CLOCK_MEM", dev.GetDefaultApplicationsClock, nvml.CLOCK_MEM, &limits.DefaultApplicationsMemoryMHz},
+	}
+	for _, q := range queries {
+		v, ret := q.f(q.clockType)
+		if err := clockLimitsError(q.name, ret); err != nil {
+			return limits, err
+		}
+		if ret == nvml.SUCCESS {
+			*q.v = v
+		}
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html#group__nvmlDeviceQueries_1g7e505374454a0d4fc7339b6c885656d6
+	reasons, ret := dev.GetCurrentClocksEventReasons()
+	if err := clockLimitsError("current clocks event reasons", ret); err != nil {
+		return limits, err
+	}
+	if ret == nvml.SUCCESS {
+		limits.ApplicationsClocksLimited = reasons&reasonApplicationsClocksSetting != 0
+	}
+
+	return limits, nil
+}
+
+// clockLimitsError returns nil for the success and the "not supported" returns,
+// otherwise the error of the query.
+func clockLimitsError(name string, ret nvml.Return) error {
+	if ret == nvml.SUCCESS || nvmlerrors.IsNotSupportError(ret) {
+		return nil
+	}
+	if nvmlerrors.IsGPULostError(ret) {
+		return nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return nvmlerrors.ErrGPURequiresReset
+	}
+	return fmt.Errorf("failed to get device %s: %v", name, nvml.ErrorString(ret))
+}
+
+// evaluateClockLimits returns the issues of the clocks locked or set below the rated clocks,
+// and below the expected clocks of the product if not nil, empty if none.
+func evaluateClockLimits(clockSpeed ClockSpeed, limits ClockLimits, expected *ExpectedClocks) []string {
+	var issues []string
+
+	// leftover "nvidia-smi -ac"
+	if limits.ApplicationsGraphicsMHz > 0 && limits.ApplicationsGraphicsMHz < limits.DefaultApplicationsGraphicsMHz {
+		issues = append(issues, fmt.Sprintf("applications graphics clock %d MHz below the default %d MHz", limits.ApplicationsGraphicsMHz, limits.DefaultApplicationsGraphicsMHz))
+	}
+	if limits.ApplicationsMemoryMHz > 0 && limits.ApplicationsMemoryMHz < limits.DefaultApplicationsMemoryMHz {
+		issues = append(issues, fmt.Sprintf("applications memory clock %d MHz below the default %d MHz", limits.ApplicationsMemoryMHz, limits.DefaultApplicationsMemoryMHz))
+	}
+
+	// leftover "nvidia-smi -lgc", only observable while the setting is limiting the clock
+	if limits.ApplicationsClocksLimited && clockSpeed.ClockGraphicsSupported && clockSpeed.GraphicsMHz < limits.MaxGraphicsMHz {
+		issues = append(issues, fmt.Sprintf("graphics clock %d MHz locked below the max %d MHz", clockSpeed.GraphicsMHz, limits.MaxGraphicsMHz))
+	}
+
+	if expected != nil {
+		if limits.MaxGraphicsMHz > 0 && limits.MaxGraphicsMHz < expected.MinGraphicsMHz {
+			issues = append(issues, fmt.Sprintf("max graphics clock %d MHz below the expected %d MHz", limits.MaxGraphicsMHz, expected.MinGraphicsMHz))
+		}
+		if limits.MaxMemoryMHz > 0 && limits.MaxMemoryMHz < expected.MinMemoryMHz {
+			issues = append(issues, fmt.Sprintf("max memory clock %d MHz below the expected %d MHz", limits.MaxMemoryMHz, expected.MinMemoryMHz))
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,141 @@
+package clockspeed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func newClockLimitsDevice(appGraphicsRet nvml.Return, reasons uint64) device.Device {
+	return &testutil.MockDevice{
+		Device: &mock.Device{
+			GetMaxClockInfoFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				if clockType == nvml.CLOCK_GRAPHICS {
+					return 1980, nvml.SUCCESS
+				}
+				return 2619, nvml.SUCCESS
+			},
+			GetApplicationsClockFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				if clockType == nvml.CLOCK_GRAPHICS {
+					return 1200, appGraphicsRet
+				}
+				return 2619, nvml.SUCCESS
+			},
+			GetDefaultApplicationsClockFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				if clockType == nvml.CLOCK_GRAPHICS {
+					return 1755, nvml.SUCCESS
+				}
+				return 2619, nvml.SUCCESS
+			},
+			GetCurrentClocksEventReasonsFunc: func() (uint64, nvml.Return) {
+				return reasons, nvml.SUCCESS
+			},
+		},
+	}
+}
+
+func TestGetClockLimits(t *testing.T) {
+	limits, err := GetClockLimits("GPU-a", newClockLimitsDevice(nvml.SUCCESS, reasonApplicationsClocksSetting))
+	require.NoError(t, err)
+	assert.Equal(t, ClockLimits{
+		UUID:                           "GPU-a",
+		MaxGraphicsMHz:                 1980,
+		MaxMemoryMHz:                   2619,
+		ApplicationsGraphicsMHz:        1200,
+		ApplicationsMemoryMHz:          2619,
+		DefaultApplicationsGraphicsMHz: 1755,
+		DefaultApplicationsMemoryMHz:   2619,
+		ApplicationsClocksLimited:      true,
+	}, limits)
+
+	// not supported is not an error
+	limits, err = GetClockLimits("GPU-a", newClockLimitsDevice(nvml.ERROR_NOT_SUPPORTED, 0))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), limits.ApplicationsGraphicsMHz)
+	assert.False(t, limits.ApplicationsClocksLimited)
+
+	_, err = GetClockLimits("GPU-a", newClockLimitsDevice(nvml.ERROR_GPU_IS_LOST, 0))
+	require.True(t, errors.Is(err, nvmlerrors.ErrGPULost))
+
+	_, err = GetClockLimits("GPU-a", newClockLimitsDevice(nvml.ERROR_UNKNOWN, 0))
+	require.ErrorContains(t, err, "failed to get device applications clock for nvml.CLOCK_GRAPHICS")
+}
+
+func TestEvaluateClockLimits(t *testing.T) {
+	clockSpeed := ClockSpeed{GraphicsMHz: 990, ClockGraphicsSupported: true}
+	limits := ClockLimits{
+		MaxGraphicsMHz:                 1980,
+		MaxMemoryMHz:                   2619,
+		ApplicationsGraphicsMHz:        1755,
+		ApplicationsMemoryMHz:          2619,
+		DefaultApplicationsGraphicsMHz: 1755,
+		DefaultApplicationsMemoryMHz:   2619,
+	}
+	assert.Empty(t, evaluateClockLimits(clockSpeed, limits, nil))
+
+	limits.ApplicationsClocksLimited = true
+	assert.Equal(t, []string{"graphics clock 990 MHz locked below the max 1980 MHz"}, evaluateClockLimits(clockSpeed, limits, nil))
+
+	limits.ApplicationsClocksLimited = false
+	limits.ApplicationsMemoryMHz = 1593
+	assert.Equal(t, []string{"applications memory clock 1593 MHz below the default 2619 MHz"}, evaluateClockLimits(clockSpeed, limits, nil))
+
+	limits.ApplicationsMemoryMHz = 2619
+	assert.Empty(t, evaluateClockLimits(clockSpeed, limits, &ExpectedClocks{ProductName: "H100", MinGraphicsMHz: 1980}))
+	assert.Equal(t, []string{"max graphics clock 1980 MHz below the expected 2100 MHz"}, evaluateClockLimits(clockSpeed, limits, &ExpectedClocks{ProductName: "H100", MinGraphicsMHz: 2100}))
+}
+
+func TestExpectedClocksList(t *testing.T) {
+	l := GetDefaultExpectedClocks()
+	require.NoError(t, l.Validate())
+	require.NotNil(t, l.Find("NVIDIA H100 80GB HBM3"))
+	assert.Equal(t, uint32(1980), l.Find("NVIDIA H100 80GB HBM3").MinGraphicsMHz)
+	assert.Equal(t, uint32(1410), l.Find("NVIDIA A100-SXM4-80GB").MinGraphicsMHz)
+	assert.Nil(t, l.Find("NVIDIA H100 PCIe"))
+
+	assert.Error(t, ExpectedClocksList{{MinGraphicsMHz: 1}}.Validate())
+}
+
+func TestComponent_Check_LockedClocks(t *testing.T) {
+	devs := map[string]device.Device{
+		"GPU-a": newClockLimitsDevice(nvml.SUCCESS, 0),
+	}
+	c := &component{
+		ctx:          context.Background(),
+		nvmlInstance: &mockNVMLInstance{devices: devs, nvmlExists: true},
+		getClockSpeedFunc: func(uuid string, _ device.Device) (ClockSpeed, error) {
+			return ClockSpeed{UUID: uuid, GraphicsMHz: 1200, ClockGraphicsSupported: true}, nil
+		},
+		getClockLimitsFunc: GetClockLimits,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "clocks locked or below the rated clocks on 1 GPU(s) (GPU-a: applications graphics clock 1200 MHz below the default 1755 MHz)", cr.Summary())
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeCheckUserAppAndGPU}, cr.suggestedActions.RepairActions)
+	require.Len(t, cr.ClockLimits, 1)
+	assert.Contains(t, cr.String(), "APPLICATIONS GRAPHICS MHZ")
+
+	// the limits query errors do not fail the check
+	c.getClockLimitsFunc = func(string, device.Device) (ClockLimits, error) {
+		return ClockLimits{}, errors.New("test error")
+	}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Empty(t, cr.ClockLimits)
+}
@@ -1,4 +1,5 @@
-// Package clockspeed tracks the NVIDIA per-GPU clock speed.
+// Package clockspeed tracks the NVIDIA per-GPU clock speed, and the clocks
+// locked or set below the rated clocks (e.g., the leftover "nvidia-smi -lgc").
 package clockspeed
 
 import (
@@ -7,11 +8,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -28,6 +30,8 @@ const Name = "accelerator-nvidia-clock-speed"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -35,6 +39,9 @@ type component struct {
 
 	nvmlInstance      nvidianvml.Instance
 	getClockSpeedFunc func(uuid string, dev device.Device) (ClockSpeed, error)
+	// nil to not check the locked clocks
+	getClockLimitsFunc    func(uuid string, dev device.Device) (ClockLimits, error)
+	getExpectedClocksFunc func() ExpectedClocksList
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -49,8 +56,10 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		getTimeNowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
-		nvmlInstance:      gpudInstance.NVMLInstance,
-		getClockSpeedFunc: GetClockSpeed,
+		nvmlInstance:          gpudInstance.NVMLInstance,
+		getClockSpeedFunc:     GetClockSpeed,
+		getClockLimitsFunc:    GetClockLimits,
+		getExpectedClocksFunc: GetDefaultExpectedClocks,
 	}
 	return c, nil
 }
@@ -74,20 +83,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -152,8 +148,10 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
-	for uuid, dev := range devs {
-		clockSpeed, err := c.getClockSpeedFunc(uuid, dev)
+	labeler := nvidianvml.NewGPULabeler(devs)
+	// query all GPUs in parallel, so the readings are taken at the same time
+	for _, r := range nvidianvml.QueryDevices(devs, c.getClockSpeedFunc) {
+		uuid, clockSpeed, err := r.UUID, r.Value, r.Err
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
@@ -184,8 +182,22 @@ func (c *component) Check() components.CheckResult {
 		}
 		cr.ClockSpeeds = append(cr.ClockSpeeds, clockSpeed)
 
-		metricGraphicsMHz.With(prometheus.Labels{"uuid": uuid}).Set(float64(clockSpeed.GraphicsMHz))
-		metricMemoryMHz.With(prometheus.Labels{"uuid": uuid}).Set(float64(clockSpeed.MemoryMHz))
+		metricGraphicsMHz.With(labeler.Labels(uuid)).Set(float64(clockSpeed.GraphicsMHz))
+		metricMemoryMHz.With(labeler.Labels(uuid)).Set(float64(clockSpeed.MemoryMHz))
+	}
+
+	issues := c.checkClockLimits(cr, devs, labeler)
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("clocks locked or below the rated clocks on %d GPU(s) (%s)", len(issues), formatClockIssues(issues))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: `reset the locked and the applications clocks with "nvidia-smi -rgc", "nvidia-smi -rmc", and "nvidia-smi -rac", or inspect the GPU SKU and VBIOS if the max clocks are below the expected`,
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeCheckUserAppAndGPU,
+			},
+		}
+		log.Logger.Warnw(cr.reason)
+		return cr
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
@@ -194,10 +206,68 @@ func (c *component) Check() components.CheckResult {
 	return cr
 }
 
+// checkClockLimits queries the clock limits of the GPUs, and returns the issues
+// of the locked or reduced clocks keyed by the GPU UUID, empty if none.
+// The query errors are logged and skipped, as the clock speeds were already read.
+func (c *component) checkClockLimits(cr *checkResult, devs map[string]device.Device, labeler *nvidianvml.GPULabeler) map[string][]string {
+	if c.getClockLimitsFunc == nil {
+		return nil
+	}
+
+	var expected *ExpectedClocks
+	if c.getExpectedClocksFunc != nil {
+		expected = c.getExpectedClocksFunc().Find(c.nvmlInstance.ProductName())
+	}
+
+	clockSpeeds := make(map[string]ClockSpeed, len(cr.ClockSpeeds))
+	for _, cs := range cr.ClockSpeeds {
+		clockSpeeds[cs.UUID] = cs
+	}
+
+	issues := make(map[string][]string)
+	for _, r := range nvidianvml.QueryDevices(devs, c.getClockLimitsFunc) {
+		uuid, limits, err := r.UUID, r.Value, r.Err
+		if err != nil {
+			log.Logger.Warnw("error getting clock limits", "uuid", uuid, "error", err)
+			continue
+		}
+		cr.ClockLimits = append(cr.ClockLimits, limits)
+
+		metricMaxGraphicsMHz.With(labeler.Labels(uuid)).Set(float64(limits.MaxGraphicsMHz))
+		metricApplicationsGraphicsMHz.With(labeler.Labels(uuid)).Set(float64(limits.ApplicationsGraphicsMHz))
+
+		found := evaluateClockLimits(clockSpeeds[uuid], limits, expected)
+		locked := 0.0
+		if len(found) > 0 {
+			issues[uuid] = found
+			locked = 1
+		}
+		metricLocked.With(labeler.Labels(uuid)).Set(locked)
+	}
+	return issues
+}
+
+// formatClockIssues formats the issues sorted by the GPU UUID,
+// e.g., "GPU-a: graphics clock 990 MHz locked below the max 1980 MHz".
+func formatClockIssues(issues map[string][]string) string {
+	uuids := make([]string, 0, len(issues))
+	for uuid := range issues {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	ss := make([]string, 0, len(uuids))
+	for _, uuid := range uuids {
+		ss = append(ss, uuid+": "+strings.Join(issues[uuid], ", "))
+	}
+	return strings.Join(ss, "; ")
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
-	ClockSpeeds []ClockSpeed `json:"clock_speeds,omitempty"`
+	ClockSpeeds []ClockSpeed  `json:"clock_speeds,omitempty"`
+	ClockLimits []ClockLimits `json:"clock_limits,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -242,6 +312,26 @@ func (cr *checkResult) String() string {
 
 	table.Render()
 
+	if len(cr.ClockLimits) > 0 {
+		buf.WriteString("\n")
+		table = tablewriter.NewWriter(buf)
+		table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+		table.SetHeader([]string{"GPU UUID", "Max Graphics MHz", "Max Memory MHz", "Applications Graphics MHz", "Applications Memory MHz", "Applications Clocks Limited"})
+		for _, limits := range cr.ClockLimits {
+			table.Append([]string{
+				limits.UUID,
+				fmt.Sprintf("%d MHz", limits.MaxGraphicsMHz),
+				fmt.Sprintf("%d MHz", limits.MaxMemoryMHz),
+				fmt.Sprintf("%d MHz", limits.ApplicationsGraphicsMHz),
+				fmt.Sprintf("%d MHz", limits.ApplicationsMemoryMHz),
+				fmt.Sprintf("%t", limits.ApplicationsClocksLimited),
+			})
+		}
+
+		table.Render()
+	}
+
 	return buf.String()
 }
 
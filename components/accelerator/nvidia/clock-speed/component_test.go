@@ -13,6 +13,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
@@ -611,3 +612,7 @@ func TestComponent_Check_GPURequiresResetSuggestedActions(t *testing.T) {
 	assert.NotNil(t, states[0].SuggestedActions)
 	assert.Contains(t, states[0].SuggestedActions.RepairActions, apiv1.RepairActionTypeRebootSystem)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
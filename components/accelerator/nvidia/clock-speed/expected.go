@@ -0,0 +1,72 @@
+package clockspeed
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// ExpectedClocks is the expected rated max clocks of a GPU product,
+// below which the GPU is flagged (e.g., the wrong SKU or the power-capped VBIOS).
+type ExpectedClocks struct {
+	// ProductName is the case-insensitive substring of the GPU product name to match
+	// (e.g., "H100 80GB HBM3" for the H100 SXM).
+	ProductName string `json:"product_name"`
+	// MinGraphicsMHz is the minimum max graphics clock in MHz, zero to not check.
+	MinGraphicsMHz uint32 `json:"min_graphics_mhz,omitempty"`
+	// MinMemoryMHz is the minimum max memory clock in MHz, zero to not check.
+	MinMemoryMHz uint32 `json:"min_memory_mhz,omitempty"`
+}
+
+// ExpectedClocksList is the expected clocks of the GPU products,
+// where the first match of the product name is used.
+type ExpectedClocksList []ExpectedClocks
+
+// Validate returns an error if any of the expected clocks has no product name.
+func (l ExpectedClocksList) Validate() error {
+	for i, e := range l {
+		if e.ProductName == "" {
+			return fmt.Errorf("expected clocks [%d] has no product name", i)
+		}
+	}
+	return nil
+}
+
+// Find returns the first expected clocks matching the product name, or nil if none.
+func (l ExpectedClocksList) Find(productName string) *ExpectedClocks {
+	productName = strings.ToLower(productName)
+	for i := range l {
+		if strings.Contains(productName, strings.ToLower(l[i].ProductName)) {
+			return &l[i]
+		}
+	}
+	return nil
+}
+
+var (
+	defaultExpectedClocksMu sync.RWMutex
+	// the rated max graphics clocks of the SXM data center GPUs
+	defaultExpectedClocks = ExpectedClocksList{
+		{ProductName: "A100-SXM4", MinGraphicsMHz: 1410},
+		{ProductName: "H100 80GB HBM3", MinGraphicsMHz: 1980},
+		{ProductName: "H200", MinGraphicsMHz: 1980},
+	}
+)
+
+// GetDefaultExpectedClocks returns the default expected clocks of the GPU products.
+func GetDefaultExpectedClocks() ExpectedClocksList {
+	defaultExpectedClocksMu.RLock()
+	defer defaultExpectedClocksMu.RUnlock()
+	return defaultExpectedClocks
+}
+
+// SetDefaultExpectedClocks replaces the default expected clocks of the GPU products.
+func SetDefaultExpectedClocks(l ExpectedClocksList) {
+	log.Logger.Infow("setting default expected clocks", "expectedClocks", l)
+
+	defaultExpectedClocksMu.Lock()
+	defer defaultExpectedClocksMu.Unlock()
+	defaultExpectedClocks = l
+}
@@ -3,7 +3,9 @@ package clockspeed
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for NVIDIA clock speed metrics.
@@ -21,7 +23,7 @@ var (
 			Name:      "graphics_mhz",
 			Help:      "tracks the current GPU clock speeds in MHz",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricMemoryMHz = prometheus.NewGaugeVec(
@@ -31,7 +33,37 @@ var (
 			Name:      "memory_mhz",
 			Help:      "tracks the current GPU memory utilization percent",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricMaxGraphicsMHz = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "max_graphics_mhz",
+			Help:      "tracks the rated max GPU graphics clock speeds in MHz",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricApplicationsGraphicsMHz = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "applications_graphics_mhz",
+			Help:      "tracks the target GPU graphics clock speeds of the applications in MHz",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricLocked = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "locked",
+			Help:      "set to 1 if the GPU clocks are locked or set below the rated clocks",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -39,5 +71,15 @@ func init() {
 	pkgmetrics.MustRegister(
 		metricGraphicsMHz,
 		metricMemoryMHz,
+		metricMaxGraphicsMHz,
+		metricApplicationsGraphicsMHz,
+		metricLocked,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_graphics_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_memory_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_max_graphics_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_applications_graphics_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_locked", Type: apiv1.MetricTypeGauge},
 	)
 }
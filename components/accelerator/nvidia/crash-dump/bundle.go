@@ -0,0 +1,137 @@
+package crashdump
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	bundlePrefix = "nvidia-bug-report-"
+	// the bug report script appends ".gz" to the output file
+	bundleSuffix = ".log.gz"
+)
+
+// Bundle is a collected NVIDIA bug report.
+type Bundle struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	ModTime   time.Time `json:"mod_time"`
+}
+
+// runBugReportFunc runs the bug report script to write the report to the output file
+// (without the ".gz" suffix).
+type runBugReportFunc func(ctx context.Context, bugReportPath string, outputFile string) ([]byte, error)
+
+func runBugReport(ctx context.Context, bugReportPath string, outputFile string) ([]byte, error) {
+	// #nosec G204 -- the script path is set by the operator config, not by the API callers.
+	return osexec.CommandContext(ctx, bugReportPath, "--output-file", outputFile).CombinedOutput()
+}
+
+// collectBundle runs the bug report script and stores the report in the directory,
+// and prunes the old bundles to keep the directory within the limits.
+// The report larger than the bundle size limit is discarded.
+func collectBundle(ctx context.Context, cfg Config, now time.Time, run runBugReportFunc) (Bundle, error) {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return Bundle{}, fmt.Errorf("failed to create crash dump directory %q: %w", cfg.Dir, err)
+	}
+
+	outputFile := filepath.Join(cfg.Dir, bundlePrefix+now.UTC().Format("20060102-150405")+".log")
+	out, err := run(ctx, cfg.bugReportPath(), outputFile)
+	if err != nil {
+		// the script may leave a partial report
+		_ = os.Remove(outputFile)
+		_ = os.Remove(outputFile + ".gz")
+		return Bundle{}, fmt.Errorf("failed to run %q: %w (output: %s)", cfg.bugReportPath(), err, strings.TrimSpace(string(out)))
+	}
+
+	bundlePath := outputFile + ".gz"
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return Bundle{}, fmt.Errorf("bug report not found: %w", err)
+	}
+	if info.Size() > cfg.maxBundleBytes() {
+		_ = os.Remove(bundlePath)
+		return Bundle{}, fmt.Errorf("bug report %d bytes exceeds the limit %d bytes, discarded", info.Size(), cfg.maxBundleBytes())
+	}
+
+	b := Bundle{Path: bundlePath, SizeBytes: info.Size(), ModTime: info.ModTime()}
+	if err := pruneBundles(cfg, b.Path); err != nil {
+		log.Logger.Warnw("failed to prune crash dump bundles", "dir", cfg.Dir, "error", err)
+	}
+	return b, nil
+}
+
+// listBundles returns the bundles in the directory, sorted by the modification time
+// in the ascending order (oldest first).
+func listBundles(dir string) ([]Bundle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	bundles := make([]Bundle, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), bundlePrefix) || !strings.HasSuffix(entry.Name(), bundleSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, Bundle{
+			Path:      filepath.Join(dir, entry.Name()),
+			SizeBytes: info.Size(),
+			ModTime:   info.ModTime(),
+		})
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if bundles[i].ModTime.Equal(bundles[j].ModTime) {
+			return bundles[i].Path < bundles[j].Path
+		}
+		return bundles[i].ModTime.Before(bundles[j].ModTime)
+	})
+	return bundles, nil
+}
+
+// pruneBundles removes the oldest bundles until the number and the total size
+// of the bundles are within the limits. The bundle at the keep path is never removed.
+func pruneBundles(cfg Config, keep string) error {
+	bundles, err := listBundles(cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, b := range bundles {
+		total += b.SizeBytes
+	}
+
+	remaining := len(bundles)
+	for _, b := range bundles {
+		if remaining <= cfg.maxBundles() && total <= cfg.maxTotalBytes() {
+			break
+		}
+		if b.Path == keep {
+			continue
+		}
+		if err := os.Remove(b.Path); err != nil {
+			return err
+		}
+		log.Logger.Infow("removed old crash dump bundle", "path", b.Path, "sizeBytes", b.SizeBytes)
+
+		remaining--
+		total -= b.SizeBytes
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package crashdump
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBugReport writes the report of the size to the output file with ".gz" appended.
+func fakeBugReport(size int) runBugReportFunc {
+	return func(_ context.Context, _ string, outputFile string) ([]byte, error) {
+		return nil, os.WriteFile(outputFile+".gz", []byte(strings.Repeat("x", size)), 0644)
+	}
+}
+
+func writeBundle(t *testing.T, dir string, ts time.Time, size int) string {
+	p := filepath.Join(dir, bundlePrefix+ts.Format("20060102-150405")+bundleSuffix)
+	require.NoError(t, os.WriteFile(p, []byte(strings.Repeat("x", size)), 0644))
+	require.NoError(t, os.Chtimes(p, ts, ts))
+	return p
+}
+
+func TestCollectBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crash-dumps")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var gotPath, gotOutput string
+	run := func(ctx context.Context, bugReportPath string, outputFile string) ([]byte, error) {
+		gotPath, gotOutput = bugReportPath, outputFile
+		return fakeBugReport(10)(ctx, bugReportPath, outputFile)
+	}
+
+	b, err := collectBundle(context.Background(), Config{Dir: dir}, now, run)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBugReportPath, gotPath)
+	assert.Equal(t, filepath.Join(dir, "nvidia-bug-report-20250101-000000.log"), gotOutput)
+	assert.Equal(t, gotOutput+".gz", b.Path)
+	assert.Equal(t, int64(10), b.SizeBytes)
+
+	bundles, err := listBundles(dir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, b.Path, bundles[0].Path)
+}
+
+func TestCollectBundleTooLarge(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := collectBundle(context.Background(), Config{Dir: dir, MaxBundleBytes: 5, MaxTotalBytes: 100}, time.Now(), fakeBugReport(10))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the limit")
+
+	bundles, err := listBundles(dir)
+	require.NoError(t, err)
+	assert.Empty(t, bundles)
+}
+
+func TestCollectBundleFailed(t *testing.T) {
+	dir := t.TempDir()
+
+	run := func(_ context.Context, _ string, outputFile string) ([]byte, error) {
+		_ = os.WriteFile(outputFile, []byte("partial"), 0644)
+		return []byte("nvidia-bug-report.sh: not found"), errors.New("exit status 127")
+	}
+	_, err := collectBundle(context.Background(), Config{Dir: dir}, time.Now(), run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPruneBundles(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p1 := writeBundle(t, dir, base, 10)
+	p2 := writeBundle(t, dir, base.Add(time.Hour), 10)
+	p3 := writeBundle(t, dir, base.Add(2*time.Hour), 10)
+	// not a bundle
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.log.gz"), []byte("x"), 0644))
+
+	// by the count
+	require.NoError(t, pruneBundles(Config{Dir: dir, MaxBundles: 2}, p3))
+	bundles, err := listBundles(dir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 2)
+	assert.Equal(t, p2, bundles[0].Path)
+	assert.Equal(t, p3, bundles[1].Path)
+	_, err = os.Stat(p1)
+	assert.True(t, os.IsNotExist(err))
+
+	// by the total size, never removes the kept bundle
+	p4 := writeBundle(t, dir, base.Add(-time.Hour), 15)
+	require.NoError(t, pruneBundles(Config{Dir: dir, MaxBundleBytes: 15, MaxTotalBytes: 15}, p4))
+	bundles, err = listBundles(dir)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, p4, bundles[0].Path)
+
+	_, err = os.Stat(filepath.Join(dir, "other.log.gz"))
+	assert.NoError(t, err)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.Error(t, Config{MaxBundles: -1}.Validate())
+	assert.Error(t, Config{MaxBundleBytes: 10, MaxTotalBytes: 5}.Validate())
+	assert.Error(t, Config{MaxBundleBytes: DefaultMaxTotalBytes + 1}.Validate())
+}
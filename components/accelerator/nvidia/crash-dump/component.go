@@ -0,0 +1,446 @@
+// Package crashdump collects the NVIDIA bug reports ("nvidia-bug-report.sh")
+// automatically on the GPU driver crash indications (Xid 79, the kernel oops
+// in the NVIDIA driver, and "Unknown Error" from nvidia-smi), before the
+// machine is rebooted and the driver state is lost.
+package crashdump
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// Name is the ID of the NVIDIA crash dump component.
+const Name = "accelerator-nvidia-crash-dump"
+
+const (
+	// EventNameBugReportCollected is the event name of the collected bug report.
+	EventNameBugReportCollected = "nvidia_bug_report_collected"
+	// EventNameBugReportFailed is the event name of the failed bug report collection.
+	EventNameBugReportFailed = "nvidia_bug_report_failed"
+
+	// EventKeyTrigger stores the crash indication that triggered the collection.
+	EventKeyTrigger = "trigger"
+	// EventKeyTriggerMessage stores the message of the crash indication.
+	EventKeyTriggerMessage = "trigger_message"
+	// EventKeyTriggerTime stores the time of the crash indication, in RFC3339.
+	EventKeyTriggerTime = "trigger_time"
+	// EventKeyBundle stores the path of the collected bug report.
+	EventKeyBundle = "bundle"
+	// EventKeyBundleSizeBytes stores the size of the collected bug report.
+	EventKeyBundleSizeBytes = "bundle_size_bytes"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	cfg                Config
+	nvmlInstance       nvidianvml.Instance
+	runBugReportFunc   runBugReportFunc
+	runNvidiaSMIFunc   func(ctx context.Context) ([]byte, error)
+	eventBucket        eventstore.Bucket
+	kmsgWatcher        kmsg.Watcher
+	collectionsStarted sync.WaitGroup
+
+	collectMu sync.Mutex
+	// true while a bug report is being collected
+	collecting bool
+	// time of the last triggered collection, for the cooldown
+	lastTriggered time.Time
+	// last collection result
+	lastCollection *collection
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// collection is the result of a bug report collection.
+type collection struct {
+	trigger string
+	time    time.Time
+	bundle  Bundle
+	err     error
+}
+
+// New creates a NVIDIA crash dump component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		cfg:              GetDefaultConfig(),
+		nvmlInstance:     gpudInstance.NVMLInstance,
+		runBugReportFunc: runBugReport,
+		runNvidiaSMIFunc: func(ctx context.Context) ([]byte, error) {
+			return pkgexec.Run(ctx, "nvidia-smi", "-L")
+		},
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+
+		if os.Geteuid() == 0 {
+			// e.g., "/dev/kmsg" not mounted into the container
+			// the component runs without the kmsg watcher (degraded without the kmsg capability)
+			c.kmsgWatcher, err = kmsg.NewWatcher()
+			if err != nil {
+				log.Logger.Warnw("kmsg not available, running without kmsg watcher", "component", Name, "error", err)
+				c.kmsgWatcher = nil
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+// IsSupported returns true if the NVIDIA driver is installed and the collection is enabled.
+// The product name is not required, as the driver may already fail to talk to the GPUs.
+func (c *component) IsSupported() bool {
+	if c.cfg.Disabled || c.cfg.Dir == "" {
+		return false
+	}
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists()
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+
+	if c.kmsgWatcher != nil {
+		kmsgCh, err := c.kmsgWatcher.Watch()
+		if err != nil {
+			return err
+		}
+		go c.watchKmsg(kmsgCh)
+	}
+
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.kmsgWatcher != nil {
+		cerr := c.kmsgWatcher.Close()
+		if cerr != nil {
+			log.Logger.Errorw("failed to close kmsg watcher", "error", cerr)
+		}
+	}
+
+	// the collection may write the event on completion
+	c.collectionsStarted.Wait()
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia crash dump")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	if c.cfg.Disabled || c.cfg.Dir == "" {
+		cr.reason = "crash dump collection is disabled"
+		return cr
+	}
+	if c.nvmlInstance == nil {
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+
+	if msg, ok := c.checkUnknownError(); ok {
+		c.trigger(TriggerUnknownError, msg, cr.ts)
+	}
+
+	c.collectMu.Lock()
+	collecting, last := c.collecting, c.lastCollection
+	c.collectMu.Unlock()
+
+	switch {
+	case collecting:
+		cr.reason = "collecting nvidia bug report"
+	case last == nil:
+		cr.reason = "no crash indication found"
+	case last.err != nil:
+		cr.err = last.err
+		cr.reason = fmt.Sprintf("failed to collect nvidia bug report on %s at %s", last.trigger, last.time.Format(time.RFC3339))
+	default:
+		cr.reason = fmt.Sprintf("nvidia bug report collected on %s at %s (%s)", last.trigger, last.time.Format(time.RFC3339), last.bundle.Path)
+	}
+
+	bundles, err := listBundles(c.cfg.Dir)
+	if err != nil {
+		log.Logger.Warnw("failed to list crash dump bundles", "dir", c.cfg.Dir, "error", err)
+	}
+	cr.Bundles = bundles
+
+	return cr
+}
+
+// checkUnknownError returns the "Unknown Error" message from NVML or nvidia-smi, if any.
+func (c *component) checkUnknownError() (string, bool) {
+	if err := c.nvmlInstance.InitError(); err != nil {
+		if msg, ok := matchUnknownError(err.Error()); ok {
+			return msg, true
+		}
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, time.Minute)
+	defer ccancel()
+
+	// nvidia-smi exits non-zero on "Unknown Error", thus only checks the output
+	out, _ := c.runNvidiaSMIFunc(cctx)
+	return matchUnknownError(string(out))
+}
+
+func (c *component) watchKmsg(kmsgCh <-chan kmsg.Message) {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case message, ok := <-kmsgCh:
+			if !ok {
+				return
+			}
+			if trigger, msg := Match(message.Message); trigger != "" {
+				c.trigger(trigger, msg, message.Timestamp.Time)
+			}
+		}
+	}
+}
+
+// trigger starts the bug report collection in the background, unless another
+// collection is running or the last one was triggered within the cooldown.
+// Returns true if a collection is started.
+func (c *component) trigger(trigger string, message string, ts time.Time) bool {
+	c.collectMu.Lock()
+	defer c.collectMu.Unlock()
+
+	now := c.getTimeNowFunc()
+	if c.collecting {
+		log.Logger.Debugw("nvidia bug report collection in progress, skipping", "trigger", trigger)
+		return false
+	}
+	if !c.lastTriggered.IsZero() && now.Sub(c.lastTriggered) < c.cfg.cooldown() {
+		log.Logger.Debugw("nvidia bug report collected recently, skipping", "trigger", trigger, "lastTriggered", c.lastTriggered)
+		return false
+	}
+	if c.ctx.Err() != nil {
+		return false
+	}
+
+	c.collecting = true
+	c.lastTriggered = now
+	c.collectionsStarted.Add(1)
+
+	log.Logger.Warnw("driver crash indication found, collecting nvidia bug report", "trigger", trigger, "message", message)
+	go c.collect(trigger, message, ts)
+	return true
+}
+
+func (c *component) collect(trigger string, message string, ts time.Time) {
+	defer c.collectionsStarted.Done()
+
+	cctx, ccancel := context.WithTimeout(c.ctx, c.cfg.timeout())
+	bundle, err := collectBundle(cctx, c.cfg, c.getTimeNowFunc(), c.runBugReportFunc)
+	ccancel()
+
+	event := eventstore.Event{
+		Component: Name,
+		Time:      c.getTimeNowFunc(),
+		Type:      string(apiv1.EventTypeWarning),
+		ExtraInfo: map[string]string{
+			EventKeyTrigger:        trigger,
+			EventKeyTriggerMessage: message,
+			EventKeyTriggerTime:    ts.UTC().Format(time.RFC3339),
+		},
+	}
+	if err != nil {
+		log.Logger.Errorw("failed to collect nvidia bug report", "trigger", trigger, "error", err)
+		event.Name = EventNameBugReportFailed
+		event.Message = fmt.Sprintf("failed to collect nvidia bug report on %s: %v", trigger, err)
+	} else {
+		log.Logger.Warnw("collected nvidia bug report", "trigger", trigger, "bundle", bundle.Path, "sizeBytes", bundle.SizeBytes)
+		event.Name = EventNameBugReportCollected
+		event.Message = fmt.Sprintf("nvidia bug report collected on %s: %s", trigger, bundle.Path)
+		event.ExtraInfo[EventKeyBundle] = bundle.Path
+		event.ExtraInfo[EventKeyBundleSizeBytes] = strconv.FormatInt(bundle.SizeBytes, 10)
+	}
+
+	if c.eventBucket != nil {
+		// the event is recorded even if the component is closing,
+		// since the collection may have been triggered by a crash right before the reboot
+		cctx, ccancel = context.WithTimeout(context.WithoutCancel(c.ctx), 15*time.Second)
+		if ierr := c.eventBucket.Insert(cctx, event); ierr != nil {
+			log.Logger.Errorw("failed to record nvidia bug report event", "error", ierr)
+		}
+		ccancel()
+	}
+
+	c.collectMu.Lock()
+	c.collecting = false
+	c.lastCollection = &collection{trigger: trigger, time: event.Time, bundle: bundle, err: err}
+	c.collectMu.Unlock()
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Bundles is the collected bug reports, oldest first.
+	Bundles []Bundle `json:"bundles,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last collection
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Bundles) == 0 {
+		return "no bundle found"
+	}
+
+	s := ""
+	for _, b := range cr.Bundles {
+		s += fmt.Sprintf("%s (%d bytes, %s)\n", b.Path, b.SizeBytes, b.ModTime.UTC().Format(time.RFC3339))
+	}
+	return s
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.Bundles) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
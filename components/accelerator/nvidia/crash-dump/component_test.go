@@ -0,0 +1,268 @@
+package crashdump
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// mockInstance implements the nvidianvml.Instance interface for testing
+type mockInstance struct {
+	initErr error
+}
+
+func (m *mockInstance) NVMLExists() bool                  { return true }
+func (m *mockInstance) Library() nvmllib.Library          { return nil }
+func (m *mockInstance) Devices() map[string]device.Device { return nil }
+func (m *mockInstance) ProductName() string               { return "" }
+func (m *mockInstance) Architecture() string              { return "" }
+func (m *mockInstance) Brand() string                     { return "" }
+func (m *mockInstance) DriverVersion() string             { return "" }
+func (m *mockInstance) DriverMajor() int                  { return 0 }
+func (m *mockInstance) CUDAVersion() string               { return "" }
+func (m *mockInstance) FabricManagerSupported() bool      { return false }
+func (m *mockInstance) FabricStateSupported() bool        { return false }
+func (m *mockInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockInstance) Shutdown() error  { return nil }
+func (m *mockInstance) InitError() error { return m.initErr }
+
+// openTestEventBucket creates a test event bucket and returns cleanup function
+func openTestEventBucket(t *testing.T) (eventstore.Bucket, func()) {
+	dbRW, dbRO, sqliteCleanup := sqlite.OpenTestDB(t)
+	store, err := eventstore.New(dbRW, dbRO, time.Hour)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+
+	return bucket, func() {
+		bucket.Close()
+		sqliteCleanup()
+	}
+}
+
+// mockComponentWithBucket creates a component with mocked functions and the event bucket for testing
+func mockComponentWithBucket(
+	ctx context.Context,
+	cfg Config,
+	runBugReportFunc runBugReportFunc,
+	bucket eventstore.Bucket,
+) components.Component {
+	cctx, cancel := context.WithCancel(ctx)
+
+	return &component{
+		ctx:    cctx,
+		cancel: cancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		},
+		cfg:              cfg,
+		nvmlInstance:     &mockInstance{},
+		runBugReportFunc: runBugReportFunc,
+		runNvidiaSMIFunc: func(context.Context) ([]byte, error) {
+			return []byte("GPU 0: NVIDIA H100 80GB HBM3 (UUID: GPU-1)\n"), nil
+		},
+		eventBucket: bucket,
+	}
+}
+
+func mustComponent(t *testing.T, c components.Component) *component {
+	t.Helper()
+
+	component, ok := c.(*component)
+	require.True(t, ok)
+	return component
+}
+
+func waitCollected(t *testing.T, c *component) *collection {
+	var last *collection
+	require.Eventually(t, func() bool {
+		c.collectMu.Lock()
+		defer c.collectMu.Unlock()
+		last = c.lastCollection
+		return !c.collecting && last != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return last
+}
+
+func TestNew(t *testing.T) {
+	SetDefaultConfig(Config{Dir: t.TempDir()})
+	defer SetDefaultConfig(Config{})
+
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockInstance{},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+
+	c2, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockInstance{},
+	})
+	require.NoError(t, err)
+	defer c2.Close()
+	c2.(*component).cfg.Disabled = true
+	assert.False(t, c2.IsSupported())
+}
+
+func TestWatchKmsgCollects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Dir: t.TempDir()}, fakeBugReport(10), bucket))
+
+	kmsgCh := make(chan kmsg.Message, 2)
+	go c.watchKmsg(kmsgCh)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	kmsgCh <- kmsg.Message{Timestamp: metav1.NewTime(now), Message: "nvidia-nvlink: Nvlink Core is being initialized"}
+	kmsgCh <- kmsg.Message{Timestamp: metav1.NewTime(now), Message: "NVRM: Xid (PCI:0000:9b:00): 79, pid=<unknown>, name=<unknown>, GPU has fallen off the bus."}
+
+	last := waitCollected(t, c)
+	require.NoError(t, last.err)
+	assert.Equal(t, TriggerXid79, last.trigger)
+
+	evs, err := c.Events(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameBugReportCollected, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+	assert.Contains(t, evs[0].Message, last.bundle.Path)
+
+	stored, err := c.eventBucket.Get(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, TriggerXid79, stored[0].ExtraInfo[EventKeyTrigger])
+	assert.Equal(t, last.bundle.Path, stored[0].ExtraInfo[EventKeyBundle])
+	assert.Equal(t, "10", stored[0].ExtraInfo[EventKeyBundleSizeBytes])
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "nvidia bug report collected on xid-79")
+	assert.Len(t, cr.(*checkResult).Bundles, 1)
+}
+
+func TestTriggerCooldown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Dir: t.TempDir(), Cooldown: metav1.Duration{Duration: time.Hour}}, fakeBugReport(10), bucket))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, c.trigger(TriggerKernelOops, "oops", now))
+	waitCollected(t, c)
+
+	// within the cooldown
+	c.getTimeNowFunc = func() time.Time { return now.Add(30 * time.Minute) }
+	assert.False(t, c.trigger(TriggerXid79, "xid 79", now))
+
+	c.getTimeNowFunc = func() time.Time { return now.Add(2 * time.Hour) }
+	assert.True(t, c.trigger(TriggerXid79, "xid 79", now))
+	waitCollected(t, c)
+}
+
+func TestTriggerInProgress(t *testing.T) {
+	release := make(chan struct{})
+	run := func(ctx context.Context, bugReportPath string, outputFile string) ([]byte, error) {
+		<-release
+		return fakeBugReport(10)(ctx, bugReportPath, outputFile)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Dir: t.TempDir(), Cooldown: metav1.Duration{Duration: time.Nanosecond}}, run, bucket))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, c.trigger(TriggerKernelOops, "oops", now))
+	assert.False(t, c.trigger(TriggerKernelOops, "oops", now))
+	assert.Equal(t, "collecting nvidia bug report", c.Check().Summary())
+
+	close(release)
+	waitCollected(t, c)
+}
+
+func TestCheckUnknownError(t *testing.T) {
+	run := func(context.Context, string, string) ([]byte, error) {
+		return []byte("permission denied"), errors.New("exit status 1")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Dir: t.TempDir()}, run, bucket))
+	c.runNvidiaSMIFunc = func(context.Context) ([]byte, error) {
+		return []byte("Unable to determine the device handle for GPU0000:9A:00.0: Unknown Error\n"), errors.New("exit status 255")
+	}
+
+	c.Check()
+	last := waitCollected(t, c)
+	assert.Equal(t, TriggerUnknownError, last.trigger)
+	require.Error(t, last.err)
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "failed to collect nvidia bug report on unknown-error")
+	assert.Contains(t, cr.HealthStates()[0].Error, "permission denied")
+
+	evs, err := c.Events(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameBugReportFailed, evs[0].Name)
+}
+
+func TestCheckNVMLInitUnknownError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Dir: t.TempDir()}, fakeBugReport(10), bucket))
+	c.nvmlInstance = &mockInstance{initErr: errors.New("nvml init failed: Unknown Error")}
+
+	c.Check()
+	last := waitCollected(t, c)
+	assert.Equal(t, TriggerUnknownError, last.trigger)
+	assert.NoError(t, last.err)
+}
+
+func TestCheckDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bucket, cleanup := openTestEventBucket(t)
+	defer cleanup()
+
+	c := mustComponent(t, mockComponentWithBucket(ctx, Config{Disabled: true, Dir: t.TempDir()}, fakeBugReport(10), bucket))
+	cr := c.Check()
+	assert.Equal(t, "crash dump collection is disabled", cr.Summary())
+	assert.False(t, c.IsSupported())
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,146 @@
+package crashdump
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultBugReportPath is the default NVIDIA bug report script,
+	// installed with the NVIDIA driver.
+	DefaultBugReportPath = "nvidia-bug-report.sh"
+
+	// DefaultTimeout is the default timeout of a single bug report collection,
+	// which may take minutes on a host with many GPUs.
+	DefaultTimeout = 10 * time.Minute
+	// DefaultCooldown is the default minimum interval between two collections,
+	// since a crash is often reported repeatedly (e.g., Xid 79 for every GPU).
+	DefaultCooldown = time.Hour
+
+	// DefaultMaxBundleBytes is the default maximum size of a single bundle.
+	DefaultMaxBundleBytes = 512 * 1024 * 1024
+	// DefaultMaxTotalBytes is the default maximum size of all the bundles kept.
+	DefaultMaxTotalBytes = 2 * 1024 * 1024 * 1024
+	// DefaultMaxBundles is the default maximum number of the bundles kept.
+	DefaultMaxBundles = 5
+)
+
+// Config configures the automatic NVIDIA bug report collection.
+// A zero value uses the defaults, except the bundle directory.
+type Config struct {
+	// Disabled disables the collection.
+	Disabled bool `json:"disabled,omitempty"`
+	// Dir is the directory to store the bundles.
+	// The collection is disabled if empty.
+	Dir string `json:"dir,omitempty"`
+	// BugReportPath is the path of the "nvidia-bug-report.sh" script.
+	// Defaults to the script in the PATH if empty.
+	BugReportPath string `json:"bug_report_path,omitempty"`
+
+	// Timeout is the timeout of a single collection.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// Cooldown is the minimum interval between two collections.
+	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+
+	// MaxBundleBytes is the maximum size of a single bundle,
+	// the bundle is discarded if larger.
+	MaxBundleBytes int64 `json:"max_bundle_bytes,omitempty"`
+	// MaxTotalBytes is the maximum size of all the bundles,
+	// the oldest bundles are removed if exceeded.
+	MaxTotalBytes int64 `json:"max_total_bytes,omitempty"`
+	// MaxBundles is the maximum number of the bundles,
+	// the oldest bundles are removed if exceeded.
+	MaxBundles int `json:"max_bundles,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout must be non-negative, got %s", cfg.Timeout.Duration)
+	}
+	if cfg.Cooldown.Duration < 0 {
+		return fmt.Errorf("cooldown must be non-negative, got %s", cfg.Cooldown.Duration)
+	}
+	if cfg.MaxBundleBytes < 0 {
+		return fmt.Errorf("max_bundle_bytes must be non-negative, got %d", cfg.MaxBundleBytes)
+	}
+	if cfg.MaxTotalBytes < 0 {
+		return fmt.Errorf("max_total_bytes must be non-negative, got %d", cfg.MaxTotalBytes)
+	}
+	if cfg.MaxBundles < 0 {
+		return fmt.Errorf("max_bundles must be non-negative, got %d", cfg.MaxBundles)
+	}
+	if cfg.maxBundleBytes() > cfg.maxTotalBytes() {
+		return fmt.Errorf("max_bundle_bytes %d must not exceed max_total_bytes %d", cfg.maxBundleBytes(), cfg.maxTotalBytes())
+	}
+	return nil
+}
+
+func (cfg Config) bugReportPath() string {
+	if cfg.BugReportPath == "" {
+		return DefaultBugReportPath
+	}
+	return cfg.BugReportPath
+}
+
+func (cfg Config) timeout() time.Duration {
+	if cfg.Timeout.Duration == 0 {
+		return DefaultTimeout
+	}
+	return cfg.Timeout.Duration
+}
+
+func (cfg Config) cooldown() time.Duration {
+	if cfg.Cooldown.Duration == 0 {
+		return DefaultCooldown
+	}
+	return cfg.Cooldown.Duration
+}
+
+func (cfg Config) maxBundleBytes() int64 {
+	if cfg.MaxBundleBytes == 0 {
+		return DefaultMaxBundleBytes
+	}
+	return cfg.MaxBundleBytes
+}
+
+func (cfg Config) maxTotalBytes() int64 {
+	if cfg.MaxTotalBytes == 0 {
+		return DefaultMaxTotalBytes
+	}
+	return cfg.MaxTotalBytes
+}
+
+func (cfg Config) maxBundles() int {
+	if cfg.MaxBundles == 0 {
+		return DefaultMaxBundles
+	}
+	return cfg.MaxBundles
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default crash dump config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default crash dump config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default crash dump config", "disabled", cfg.Disabled, "dir", cfg.Dir, "bugReportPath", cfg.BugReportPath)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
@@ -0,0 +1,56 @@
+package crashdump
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+)
+
+const (
+	// TriggerXid79 is the trigger of the GPU fallen off the bus (Xid 79).
+	TriggerXid79 = "xid-79"
+	// TriggerKernelOops is the trigger of the kernel oops in the NVIDIA driver.
+	TriggerKernelOops = "kernel-oops"
+	// TriggerUnknownError is the trigger of the "Unknown Error" from nvidia-smi or NVML,
+	// when the driver can no longer talk to the GPUs.
+	TriggerUnknownError = "unknown-error"
+)
+
+// regexKernelOopsNVIDIA matches the kernel oops lines that point to the NVIDIA driver.
+// e.g.,
+//
+//	BUG: unable to handle page fault for address: ffffb1a4c2e1e000 ... [nvidia]
+//	RIP: 0010:_nv036002rm+0x1b/0x30 [nvidia]
+//	kernel BUG at /var/lib/dkms/nvidia/535.129.03/source/nvidia/nv.c:1234!
+//
+// The "Modules linked in:" lines are excluded, since they list the NVIDIA
+// modules for any oops on a GPU host.
+var regexKernelOopsNVIDIA = regexp.MustCompile(`(?i)(\bOops\b|BUG: unable to handle|kernel BUG at|general protection fault|RIP: [0-9a-f]{4}:).*nvidia`)
+
+// Match returns the crash trigger and the message if the kernel message
+// indicates a driver crash. Otherwise, returns empty strings.
+func Match(line string) (trigger string, message string) {
+	if xidErr := xid.Match(line); xidErr != nil && xidErr.Xid == 79 {
+		return TriggerXid79, fmt.Sprintf("Xid 79 (GPU has fallen off the bus) on %s", xidErr.DeviceUUID)
+	}
+	if strings.Contains(strings.ToLower(line), "modules linked in") {
+		return "", ""
+	}
+	if regexKernelOopsNVIDIA.MatchString(line) {
+		return TriggerKernelOops, strings.TrimSpace(line)
+	}
+	return "", ""
+}
+
+// matchUnknownError returns the line with the "Unknown Error" in the nvidia-smi
+// or NVML output (e.g., "Unable to determine the device handle for GPU0000:9A:00.0: Unknown Error").
+func matchUnknownError(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "Unknown Error") {
+			return strings.TrimSpace(line), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,81 @@
+package crashdump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		trigger string
+	}{
+		{
+			name:    "xid 79",
+			line:    "NVRM: Xid (PCI:0000:9b:00): 79, pid=<unknown>, name=<unknown>, GPU has fallen off the bus.",
+			trigger: TriggerXid79,
+		},
+		{
+			name:    "fallen off the bus",
+			line:    "NVRM: GPU 0000:9b:00.0: GPU has fallen off the bus.",
+			trigger: TriggerXid79,
+		},
+		{
+			name:    "other xid",
+			line:    "NVRM: Xid (PCI:0000:9b:00): 31, pid=1234, name=python, Ch 00000008",
+			trigger: "",
+		},
+		{
+			name:    "page fault in nvidia",
+			line:    "BUG: unable to handle page fault for address: ffffb1a4c2e1e000 [nvidia]",
+			trigger: TriggerKernelOops,
+		},
+		{
+			name:    "rip in nvidia",
+			line:    "RIP: 0010:_nv036002rm+0x1b/0x30 [nvidia]",
+			trigger: TriggerKernelOops,
+		},
+		{
+			name:    "kernel bug in nvidia source",
+			line:    "kernel BUG at /var/lib/dkms/nvidia/535.129.03/source/nvidia/nv.c:1234!",
+			trigger: TriggerKernelOops,
+		},
+		{
+			name:    "oops outside nvidia",
+			line:    "BUG: unable to handle page fault for address: ffffb1a4c2e1e000 [ext4]",
+			trigger: "",
+		},
+		{
+			name:    "modules linked in",
+			line:    "Modules linked in: nvidia_uvm(POE) nvidia_drm(POE) nvidia(POE) general protection fault",
+			trigger: "",
+		},
+		{
+			name:    "unrelated",
+			line:    "nvidia-nvlink: Nvlink Core is being initialized",
+			trigger: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger, msg := Match(tt.line)
+			assert.Equal(t, tt.trigger, trigger)
+			if tt.trigger == "" {
+				assert.Empty(t, msg)
+			} else {
+				assert.NotEmpty(t, msg)
+			}
+		})
+	}
+}
+
+func TestMatchUnknownError(t *testing.T) {
+	msg, ok := matchUnknownError("GPU 0: NVIDIA H100 80GB HBM3 (UUID: GPU-1)\nUnable to determine the device handle for GPU0000:9A:00.0: Unknown Error\n")
+	assert.True(t, ok)
+	assert.Equal(t, "Unable to determine the device handle for GPU0000:9A:00.0: Unknown Error", msg)
+
+	_, ok = matchUnknownError("GPU 0: NVIDIA H100 80GB HBM3 (UUID: GPU-1)\n")
+	assert.False(t, ok)
+}
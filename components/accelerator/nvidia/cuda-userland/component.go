@@ -0,0 +1,412 @@
+// Package cudauserland validates the user-space CUDA stack (libcuda, libcudart, libcudnn, libnccl):
+// the libraries are present, compatible with the driver, not conflicting across
+// the library paths, and optionally match the checksums of the manifest.
+package cudauserland
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// Name is the ID of the NVIDIA CUDA userland component.
+const Name = "accelerator-nvidia-cuda-userland"
+
+// sonameCUDA is the soname of the CUDA driver library the applications load.
+const sonameCUDA = "libcuda.so.1"
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	nvmlInstance        nvidianvml.Instance
+	listLibrariesFunc   func(ctx context.Context) ([]Library, error)
+	getManifestFileFunc func() string
+	checksums           *checksumCache
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA CUDA userland component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		nvmlInstance:        gpudInstance.NVMLInstance,
+		listLibrariesFunc:   listLibraries,
+		getManifestFileFunc: GetDefaultManifestFile,
+		checksums:           &checksumCache{},
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia cuda userland")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+	cr.DriverVersion = c.nvmlInstance.DriverVersion()
+	cr.CUDAVersion = c.nvmlInstance.CUDAVersion()
+
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	cr.Libraries, cr.err = c.listLibrariesFunc(ctx)
+	cancel()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error listing libraries in the dynamic linker cache"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	failures, conflicts := validateLibraries(cr.Libraries, cr.DriverVersion, cr.CUDAVersion)
+
+	if manifestFile := c.getManifestFileFunc(); manifestFile != "" {
+		m, err := LoadManifest(manifestFile)
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = fmt.Sprintf("error loading manifest %q", manifestFile)
+			log.Logger.Warnw(cr.reason, "error", err)
+			return cr
+		}
+		cr.ChecksumMismatches = c.checksums.verify(m)
+		failures = append(failures, cr.ChecksumMismatches...)
+	}
+
+	switch {
+	case len(failures) > 0:
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = strings.Join(append(failures, conflicts...), "; ")
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "reinstall the CUDA libraries matching the NVIDIA driver, and remove the stale copies from the library paths (run \"ldconfig\" after)",
+		}
+
+	case len(conflicts) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = strings.Join(conflicts, "; ")
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "remove the stale copies of the CUDA libraries from the library paths (run \"ldconfig\" after), so that the applications do not load the mixed versions",
+		}
+
+	default:
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "cuda userland ok (" + summarizeVersions(cr.Libraries) + ")"
+	}
+	return cr
+}
+
+// validateLibraries returns the failures (e.g., libcuda not matching the driver)
+// and the conflicts (e.g., the same soname resolved to the different versions
+// across the library paths) of the libraries.
+func validateLibraries(libs []Library, driverVersion string, cudaVersion string) (failures []string, conflicts []string) {
+	foundCUDA := false
+	versionsBySOName := make(map[string]map[string][]string)
+	var sonames []string
+	for _, lib := range libs {
+		if lib.Broken {
+			failures = append(failures, fmt.Sprintf("%s is a broken symlink", lib.Path))
+			continue
+		}
+
+		if lib.SOName == sonameCUDA {
+			foundCUDA = true
+			if driverVersion != "" && lib.Version != "" && lib.Version != driverVersion {
+				failures = append(failures, fmt.Sprintf("%s version %s does not match the driver version %s", lib.Path, lib.Version, driverVersion))
+			}
+		}
+
+		if lib.Name == LibCUDART && cudaVersion != "" && lib.Version != "" && newerMajorMinor(lib.Version, cudaVersion) {
+			failures = append(failures, fmt.Sprintf("%s CUDA runtime %s requires a newer driver than CUDA %s", lib.Path, lib.Version, cudaVersion))
+		}
+
+		if lib.Version == "" {
+			continue
+		}
+		if _, ok := versionsBySOName[lib.SOName]; !ok {
+			versionsBySOName[lib.SOName] = make(map[string][]string)
+			sonames = append(sonames, lib.SOName)
+		}
+		versionsBySOName[lib.SOName][lib.Version] = append(versionsBySOName[lib.SOName][lib.Version], lib.Path)
+	}
+	if !foundCUDA {
+		failures = append(failures, sonameCUDA+" not found in the dynamic linker cache")
+	}
+
+	sort.Strings(sonames)
+	for _, soname := range sonames {
+		versions := versionsBySOName[soname]
+		if len(versions) < 2 {
+			continue
+		}
+		parts := make([]string, 0, len(versions))
+		for v, paths := range versions {
+			parts = append(parts, fmt.Sprintf("%s (%s)", v, strings.Join(paths, ", ")))
+		}
+		sort.Strings(parts)
+		conflicts = append(conflicts, fmt.Sprintf("%s resolves to mixed versions %s", soname, strings.Join(parts, ", ")))
+	}
+	return failures, conflicts
+}
+
+// newerMajorMinor returns true if the major.minor of the version is newer than the base,
+// e.g., the CUDA runtime version "12.4.127" than the driver supported CUDA version "12.2".
+func newerMajorMinor(version string, base string) bool {
+	vMajor, vMinor, ok := parseMajorMinor(version)
+	if !ok {
+		return false
+	}
+	bMajor, bMinor, ok := parseMajorMinor(base)
+	if !ok {
+		return false
+	}
+	if vMajor != bMajor {
+		return vMajor > bMajor
+	}
+	return vMinor > bMinor
+}
+
+func parseMajorMinor(version string) (int, int, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// summarizeVersions returns the versions found per tracked library
+// (e.g., "libcuda.so 535.183.06, libcudnn.so 8.9.7").
+func summarizeVersions(libs []Library) string {
+	versions := make(map[string][]string)
+	for _, lib := range libs {
+		if lib.Version == "" {
+			continue
+		}
+		found := false
+		for _, v := range versions[lib.Name] {
+			if v == lib.Version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			versions[lib.Name] = append(versions[lib.Name], lib.Version)
+		}
+	}
+
+	var parts []string
+	for _, name := range TrackedLibraries {
+		if len(versions[name]) == 0 {
+			continue
+		}
+		parts = append(parts, name+" "+strings.Join(versions[name], "/"))
+	}
+	if len(parts) == 0 {
+		return "no versioned library found"
+	}
+	return strings.Join(parts, ", ")
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// DriverVersion is the NVIDIA driver version
+	DriverVersion string `json:"driver_version,omitempty"`
+	// CUDAVersion is the CUDA version supported by the driver
+	CUDAVersion string `json:"cuda_version,omitempty"`
+	// Libraries are the tracked libraries found in the dynamic linker cache
+	Libraries []Library `json:"libraries,omitempty"`
+	// ChecksumMismatches are the files not matching the manifest, if configured
+	ChecksumMismatches []string `json:"checksum_mismatches,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Libraries) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Library", "SOName", "Version", "Path"})
+	for _, lib := range cr.Libraries {
+		version := lib.Version
+		if lib.Broken {
+			version = "broken"
+		}
+		table.Append([]string{lib.Name, lib.SOName, version, lib.Path})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	// propagate suggested actions to health state if present
+	if cr.suggestedActions != nil {
+		state.SuggestedActions = cr.suggestedActions
+	}
+
+	if len(cr.Libraries) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,219 @@
+package cudauserland
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+)
+
+type mockNVMLInstance struct {
+	driverVersion string
+	cudaVersion   string
+}
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return nil }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) ProductName() string          { return "NVIDIA H100 80GB HBM3" }
+func (m *mockNVMLInstance) Architecture() string         { return "" }
+func (m *mockNVMLInstance) Brand() string                { return "" }
+func (m *mockNVMLInstance) DriverVersion() string        { return m.driverVersion }
+func (m *mockNVMLInstance) DriverMajor() int             { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string          { return m.cudaVersion }
+func (m *mockNVMLInstance) FabricManagerSupported() bool { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool   { return false }
+func (m *mockNVMLInstance) NVMLExists() bool             { return true }
+func (m *mockNVMLInstance) Library() lib.Library         { return nil }
+func (m *mockNVMLInstance) Shutdown() error              { return nil }
+func (m *mockNVMLInstance) InitError() error             { return nil }
+
+// MockCUDAUserlandComponent creates a component with the mocked libraries for testing
+func MockCUDAUserlandComponent(
+	ctx context.Context,
+	libs []Library,
+	manifestFile string,
+) components.Component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:    cctx,
+		cancel: cancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		nvmlInstance: &mockNVMLInstance{driverVersion: "535.183.06", cudaVersion: "12.2"},
+		listLibrariesFunc: func(context.Context) ([]Library, error) {
+			return libs, nil
+		},
+		getManifestFileFunc: func() string {
+			return manifestFile
+		},
+		checksums: &checksumCache{},
+	}
+}
+
+func mustComponent(t *testing.T, c components.Component) *component {
+	t.Helper()
+
+	component, ok := c.(*component)
+	require.True(t, ok)
+	return component
+}
+
+var testLibCUDA = Library{
+	Name:     LibCUDA,
+	SOName:   "libcuda.so.1",
+	Path:     "/usr/lib/x86_64-linux-gnu/libcuda.so.1",
+	RealPath: "/usr/lib/x86_64-linux-gnu/libcuda.so.535.183.06",
+	Version:  "535.183.06",
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockNVMLInstance{driverVersion: "535.183.06", cudaVersion: "12.2"},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.True(t, c.IsSupported())
+}
+
+func TestCheckHealthy(t *testing.T) {
+	c := mustComponent(t, MockCUDAUserlandComponent(context.Background(), []Library{
+		testLibCUDA,
+		{Name: LibCUDART, SOName: "libcudart.so.12", Path: "/usr/local/cuda/lib64/libcudart.so.12", Version: "12.2.140"},
+		{Name: LibCUDNN, SOName: "libcudnn.so.8", Path: "/usr/lib/x86_64-linux-gnu/libcudnn.so.8", Version: "8.9.7"},
+	}, ""))
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType(), cr.Summary())
+	assert.Equal(t, "cuda userland ok (libcuda.so 535.183.06, libcudart.so 12.2.140, libcudnn.so 8.9.7)", cr.Summary())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Contains(t, states[0].ExtraInfo["data"], `"driver_version":"535.183.06"`)
+}
+
+func TestCheckListError(t *testing.T) {
+	c := mustComponent(t, MockCUDAUserlandComponent(context.Background(), nil, ""))
+	c.listLibrariesFunc = func(context.Context) ([]Library, error) { return nil, errors.New("ldconfig not found") }
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "ldconfig not found", c.LastHealthStates()[0].Error)
+}
+
+func TestValidateLibraries(t *testing.T) {
+	failures, conflicts := validateLibraries(nil, "535.183.06", "12.2")
+	assert.Equal(t, []string{"libcuda.so.1 not found in the dynamic linker cache"}, failures)
+	assert.Empty(t, conflicts)
+
+	// libcuda of the previous driver left in another path
+	failures, conflicts = validateLibraries([]Library{
+		testLibCUDA,
+		{Name: LibCUDA, SOName: "libcuda.so.1", Path: "/usr/lib64/libcuda.so.1", Version: "525.85.12"},
+	}, "535.183.06", "12.2")
+	assert.Equal(t, []string{"/usr/lib64/libcuda.so.1 version 525.85.12 does not match the driver version 535.183.06"}, failures)
+	assert.Equal(t, []string{"libcuda.so.1 resolves to mixed versions 525.85.12 (/usr/lib64/libcuda.so.1), 535.183.06 (/usr/lib/x86_64-linux-gnu/libcuda.so.1)"}, conflicts)
+
+	// the CUDA runtime newer than the driver supports
+	failures, conflicts = validateLibraries([]Library{
+		testLibCUDA,
+		{Name: LibCUDART, SOName: "libcudart.so.12", Path: "/usr/local/cuda/lib64/libcudart.so.12", Version: "12.4.127"},
+	}, "535.183.06", "12.2")
+	assert.Equal(t, []string{"/usr/local/cuda/lib64/libcudart.so.12 CUDA runtime 12.4.127 requires a newer driver than CUDA 12.2"}, failures)
+	assert.Empty(t, conflicts)
+
+	// the different majors are not conflicting
+	failures, conflicts = validateLibraries([]Library{
+		testLibCUDA,
+		{Name: LibCUDNN, SOName: "libcudnn.so.8", Path: "/usr/lib/x86_64-linux-gnu/libcudnn.so.8", Version: "8.9.7"},
+		{Name: LibCUDNN, SOName: "libcudnn.so.9", Path: "/usr/lib/x86_64-linux-gnu/libcudnn.so.9", Version: "9.1.0"},
+		{Name: LibCUDNN, SOName: "libcudnn.so", Path: "/usr/lib/x86_64-linux-gnu/libcudnn.so", Broken: true},
+	}, "535.183.06", "12.2")
+	assert.Equal(t, []string{"/usr/lib/x86_64-linux-gnu/libcudnn.so is a broken symlink"}, failures)
+	assert.Empty(t, conflicts)
+}
+
+func TestCheckConflicts(t *testing.T) {
+	c := mustComponent(t, MockCUDAUserlandComponent(context.Background(), []Library{
+		testLibCUDA,
+		{Name: LibNCCL, SOName: "libnccl.so.2", Path: "/opt/conda/lib/libnccl.so.2", Version: "2.14.3"},
+		{Name: LibNCCL, SOName: "libnccl.so.2", Path: "/usr/lib/x86_64-linux-gnu/libnccl.so.2", Version: "2.18.3"},
+	}, ""))
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "libnccl.so.2 resolves to mixed versions")
+	require.NotNil(t, c.LastHealthStates()[0].SuggestedActions)
+}
+
+func TestNewerMajorMinor(t *testing.T) {
+	assert.True(t, newerMajorMinor("12.4.127", "12.2"))
+	assert.True(t, newerMajorMinor("13.0", "12.8"))
+	assert.False(t, newerMajorMinor("12.2.140", "12.2"))
+	assert.False(t, newerMajorMinor("11.8.89", "12.2"))
+	assert.False(t, newerMajorMinor("12", "12.2"))
+}
+
+func TestCheckManifest(t *testing.T) {
+	dir := t.TempDir()
+	libPath := filepath.Join(dir, "libcudnn.so.8.9.7")
+	require.NoError(t, os.WriteFile(libPath, []byte("cudnn"), 0644))
+	sum := sha256.Sum256([]byte("cudnn"))
+
+	manifestFile := filepath.Join(dir, "manifest.yaml")
+	require.NoError(t, os.WriteFile(manifestFile, []byte("files:\n- path: "+libPath+"\n  sha256: "+hex.EncodeToString(sum[:])+"\n"), 0644))
+
+	c := mustComponent(t, MockCUDAUserlandComponent(context.Background(), []Library{testLibCUDA}, manifestFile))
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType(), cr.Summary())
+
+	// modified after the checksum was cached
+	require.NoError(t, os.WriteFile(libPath, []byte("cudnn-modified"), 0644))
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, libPath+" checksum mismatch", cr.Summary())
+
+	require.NoError(t, os.Remove(libPath))
+	cr = c.Check()
+	assert.Equal(t, libPath+" not found", cr.Summary())
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	manifestFile := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(manifestFile, []byte(`{"files":[{"path":"/usr/lib/libcuda.so.1","sha256":"abc"}]}`), 0644))
+	_, err := LoadManifest(manifestFile)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(manifestFile, []byte(`{"files":[{"sha256":"abc"}]}`), 0644))
+	_, err = LoadManifest(manifestFile)
+	require.Error(t, err)
+
+	_, err = LoadManifest(filepath.Join(dir, "not-found.json"))
+	require.Error(t, err)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
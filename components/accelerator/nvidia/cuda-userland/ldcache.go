@@ -0,0 +1,158 @@
+package cudauserland
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
+	"github.com/leptonai/gpud/pkg/file"
+)
+
+// TrackedLibraries are the user-space CUDA libraries validated by the component.
+var TrackedLibraries = []string{
+	LibCUDA,
+	LibCUDART,
+	LibCUDNN,
+	LibNCCL,
+}
+
+const (
+	// LibCUDA is the CUDA driver library, installed with the NVIDIA driver.
+	LibCUDA = "libcuda.so"
+	// LibCUDART is the CUDA runtime library, installed with the CUDA toolkit.
+	LibCUDART = "libcudart.so"
+	// LibCUDNN is the cuDNN library.
+	LibCUDNN = "libcudnn.so"
+	// LibNCCL is the NCCL library.
+	LibNCCL = "libnccl.so"
+)
+
+// Library is a user-space CUDA library found in the dynamic linker cache.
+type Library struct {
+	// Name is the tracked library name (e.g., "libcudnn.so").
+	Name string `json:"name"`
+	// SOName is the name the dynamic linker resolves (e.g., "libcudnn.so.8").
+	SOName string `json:"soname"`
+	// Path is the path in the dynamic linker cache.
+	Path string `json:"path"`
+	// RealPath is the path with the symlinks resolved (e.g., "/usr/lib/x86_64-linux-gnu/libcudnn.so.8.9.7").
+	RealPath string `json:"real_path,omitempty"`
+	// Version is the library version from the resolved file name (e.g., "8.9.7").
+	Version string `json:"version,omitempty"`
+	// Broken is true if the path is a dangling symlink.
+	Broken bool `json:"broken,omitempty"`
+}
+
+// ldCacheEntry is a library entry of the "ldconfig -p" output.
+type ldCacheEntry struct {
+	soname string
+	flags  string
+	path   string
+}
+
+// listLibraries lists the tracked libraries in the dynamic linker cache,
+// sorted by the name, the soname, and the path.
+func listLibraries(ctx context.Context) ([]Library, error) {
+	execPath, err := file.LocateExecutable("ldconfig")
+	if err != nil {
+		// not in the PATH of the non-root users on some distros
+		execPath = "/sbin/ldconfig"
+	}
+	out, err := pkgexec.Run(ctx, execPath, "-p")
+	if err != nil {
+		return nil, err
+	}
+	return resolveLibraries(parseLdCache(out), filepath.EvalSymlinks), nil
+}
+
+// parseLdCache parses the "ldconfig -p" output, for example:
+//
+//	1234 libs found in cache `/etc/ld.so.cache'
+//		libcudnn.so.8 (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libcudnn.so.8
+func parseLdCache(b []byte) []ldCacheEntry {
+	var entries []ldCacheEntry
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lhs, path, ok := strings.Cut(line, " => ")
+		if !ok {
+			continue
+		}
+		soname, flags, _ := strings.Cut(lhs, " ")
+		entries = append(entries, ldCacheEntry{
+			soname: soname,
+			flags:  strings.Trim(flags, "()"),
+			path:   strings.TrimSpace(path),
+		})
+	}
+	return entries
+}
+
+// resolveLibraries returns the tracked libraries of the 64-bit cache entries,
+// with the symlinks resolved.
+func resolveLibraries(entries []ldCacheEntry, evalSymlinks func(string) (string, error)) []Library {
+	seen := make(map[string]struct{})
+	var libs []Library
+	for _, e := range entries {
+		name := trackedName(e.soname)
+		if name == "" {
+			continue
+		}
+		// e.g., "libc6,x86-64", "libc6,AArch64", "libc6,64bit"
+		// the 32-bit libraries are only for the 32-bit applications
+		if !strings.Contains(e.flags, "64") {
+			continue
+		}
+		if _, ok := seen[e.path]; ok {
+			continue
+		}
+		seen[e.path] = struct{}{}
+
+		lib := Library{Name: name, SOName: e.soname, Path: e.path}
+		realPath, err := evalSymlinks(e.path)
+		if err != nil {
+			lib.Broken = true
+		} else {
+			lib.RealPath = realPath
+			lib.Version = parseVersion(filepath.Base(realPath))
+		}
+		libs = append(libs, lib)
+	}
+
+	sort.Slice(libs, func(i, j int) bool {
+		if libs[i].Name != libs[j].Name {
+			return libs[i].Name < libs[j].Name
+		}
+		if libs[i].SOName != libs[j].SOName {
+			return libs[i].SOName < libs[j].SOName
+		}
+		return libs[i].Path < libs[j].Path
+	})
+	return libs
+}
+
+// trackedName returns the tracked library name of the soname,
+// or an empty string if not tracked (e.g., "libcudnn_ops_infer.so.8").
+func trackedName(soname string) string {
+	for _, name := range TrackedLibraries {
+		if soname == name || strings.HasPrefix(soname, name+".") {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseVersion returns the version suffix of the library file name
+// (e.g., "535.183.06" for "libcuda.so.535.183.06"),
+// or an empty string if none.
+func parseVersion(fileName string) string {
+	_, version, ok := strings.Cut(fileName, ".so.")
+	if !ok {
+		return ""
+	}
+	return version
+}
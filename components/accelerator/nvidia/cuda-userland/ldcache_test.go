@@ -0,0 +1,74 @@
+package cudauserland
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLdCache = `1234 libs found in cache ` + "`/etc/ld.so.cache'" + `
+	libz.so.1 (libc6,x86-64) => /lib/x86_64-linux-gnu/libz.so.1
+	libnccl.so.2 (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libnccl.so.2
+	libcudnn_ops_infer.so.8 (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libcudnn_ops_infer.so.8
+	libcudnn.so.8 (libc6,x86-64) => /usr/local/cuda/lib64/libcudnn.so.8
+	libcudnn.so.8 (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libcudnn.so.8
+	libcudart.so.12 (libc6,x86-64) => /usr/local/cuda/lib64/libcudart.so.12
+	libcuda.so.1 (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libcuda.so.1
+	libcuda.so.1 (libc6) => /usr/lib/i386-linux-gnu/libcuda.so.1
+	libcuda.so (libc6,x86-64) => /usr/lib/x86_64-linux-gnu/libcuda.so
+Cache generated by: ldconfig (Ubuntu GLIBC 2.35-0ubuntu3.8) stable release version 2.35
+`
+
+func TestParseLdCache(t *testing.T) {
+	entries := parseLdCache([]byte(testLdCache))
+	require.Len(t, entries, 9)
+	assert.Equal(t, ldCacheEntry{soname: "libz.so.1", flags: "libc6,x86-64", path: "/lib/x86_64-linux-gnu/libz.so.1"}, entries[0])
+	assert.Equal(t, ldCacheEntry{soname: "libcuda.so.1", flags: "libc6", path: "/usr/lib/i386-linux-gnu/libcuda.so.1"}, entries[7])
+}
+
+func TestResolveLibraries(t *testing.T) {
+	realPaths := map[string]string{
+		"/usr/lib/x86_64-linux-gnu/libnccl.so.2":  "/usr/lib/x86_64-linux-gnu/libnccl.so.2.18.3",
+		"/usr/local/cuda/lib64/libcudnn.so.8":     "/usr/local/cuda/lib64/libcudnn.so.8.6.0",
+		"/usr/lib/x86_64-linux-gnu/libcudnn.so.8": "/usr/lib/x86_64-linux-gnu/libcudnn.so.8.9.7",
+		"/usr/local/cuda/lib64/libcudart.so.12":   "/usr/local/cuda-12.2/targets/x86_64-linux/lib/libcudart.so.12.2.140",
+		"/usr/lib/x86_64-linux-gnu/libcuda.so.1":  "/usr/lib/x86_64-linux-gnu/libcuda.so.535.183.06",
+	}
+	libs := resolveLibraries(parseLdCache([]byte(testLdCache)), func(path string) (string, error) {
+		if p, ok := realPaths[path]; ok {
+			return p, nil
+		}
+		return "", errors.New("no such file or directory")
+	})
+
+	// the untracked and 32-bit libraries are skipped
+	require.Len(t, libs, 6)
+	assert.Equal(t, Library{Name: LibCUDA, SOName: "libcuda.so", Path: "/usr/lib/x86_64-linux-gnu/libcuda.so", Broken: true}, libs[0])
+	assert.Equal(t, Library{
+		Name:     LibCUDA,
+		SOName:   "libcuda.so.1",
+		Path:     "/usr/lib/x86_64-linux-gnu/libcuda.so.1",
+		RealPath: "/usr/lib/x86_64-linux-gnu/libcuda.so.535.183.06",
+		Version:  "535.183.06",
+	}, libs[1])
+	assert.Equal(t, "12.2.140", libs[2].Version)
+	assert.Equal(t, "8.9.7", libs[3].Version)
+	assert.Equal(t, "8.6.0", libs[4].Version)
+	assert.Equal(t, "2.18.3", libs[5].Version)
+}
+
+func TestTrackedName(t *testing.T) {
+	assert.Equal(t, LibCUDA, trackedName("libcuda.so.1"))
+	assert.Equal(t, LibCUDA, trackedName("libcuda.so"))
+	assert.Equal(t, LibCUDNN, trackedName("libcudnn.so.9"))
+	assert.Equal(t, "", trackedName("libcudnn_ops_infer.so.8"))
+	assert.Equal(t, "", trackedName("libcudadebugger.so.1"))
+}
+
+func TestParseVersion(t *testing.T) {
+	assert.Equal(t, "535.183.06", parseVersion("libcuda.so.535.183.06"))
+	assert.Equal(t, "12", parseVersion("libcudart.so.12"))
+	assert.Equal(t, "", parseVersion("libcuda.so"))
+}
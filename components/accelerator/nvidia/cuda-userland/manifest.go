@@ -0,0 +1,143 @@
+package cudauserland
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Manifest is the expected checksums of the user-space CUDA library files
+// (e.g., generated from the golden image), in YAML or JSON.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is the expected checksum of a library file.
+type ManifestFile struct {
+	// Path is the library file path (e.g., "/usr/lib/x86_64-linux-gnu/libcudnn.so.8.9.7").
+	Path string `json:"path"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// LoadManifest loads the manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for _, f := range m.Files {
+		if f.Path == "" {
+			return nil, errors.New("manifest file path is required")
+		}
+		if len(f.SHA256) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid sha256 %q for %q", f.SHA256, f.Path)
+		}
+	}
+	return &m, nil
+}
+
+var (
+	defaultManifestFileMu sync.RWMutex
+	defaultManifestFile   string
+)
+
+// GetDefaultManifestFile returns the current manifest file to verify
+// the library checksums against, empty if none.
+func GetDefaultManifestFile() string {
+	defaultManifestFileMu.RLock()
+	defer defaultManifestFileMu.RUnlock()
+
+	return defaultManifestFile
+}
+
+// SetDefaultManifestFile replaces the manifest file to verify the library checksums against.
+func SetDefaultManifestFile(path string) {
+	log.Logger.Infow("setting default cuda userland manifest file", "path", path)
+
+	defaultManifestFileMu.Lock()
+	defer defaultManifestFileMu.Unlock()
+	defaultManifestFile = path
+}
+
+// checksumCache caches the file checksums by the size and the modification time,
+// not to read the large libraries (e.g., cuDNN) on every check.
+type checksumCache struct {
+	mu      sync.Mutex
+	entries map[string]checksumEntry
+}
+
+type checksumEntry struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+func (c *checksumCache) sha256(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.size == fi.Size() && e.modTime.Equal(fi.ModTime()) {
+		return e.sum, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]checksumEntry)
+	}
+	c.entries[path] = checksumEntry{size: fi.Size(), modTime: fi.ModTime(), sum: sum}
+	c.mu.Unlock()
+	return sum, nil
+}
+
+// verify returns the mismatches of the files against the manifest.
+func (c *checksumCache) verify(m *Manifest) []string {
+	var mismatches []string
+	for _, f := range m.Files {
+		sum, err := c.sha256(f.Path)
+		if os.IsNotExist(err) {
+			mismatches = append(mismatches, fmt.Sprintf("%s not found", f.Path))
+			continue
+		}
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s not readable (%v)", f.Path, err))
+			continue
+		}
+		if !strings.EqualFold(sum, f.SHA256) {
+			mismatches = append(mismatches, fmt.Sprintf("%s checksum mismatch", f.Path))
+		}
+	}
+	return mismatches
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -20,14 +19,31 @@ import (
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 // Name is the name of the NVIDIA ECC component.
 const Name = "accelerator-nvidia-ecc"
 
+const (
+	// ReasonCodeECCModeReadError is for the failures reading the ECC mode.
+	ReasonCodeECCModeReadError apiv1.ReasonCode = "GPU_ECC_MODE_READ_ERROR"
+	// ReasonCodeECCErrorsReadError is for the failures reading the ECC error counts.
+	ReasonCodeECCErrorsReadError apiv1.ReasonCode = "GPU_ECC_ERRORS_READ_ERROR"
+)
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeECCModeReadError, Health: apiv1.HealthStateTypeUnhealthy, Description: "Failed to read the ECC mode of the GPU."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeECCErrorsReadError, Health: apiv1.HealthStateTypeUnhealthy, Description: "Failed to read the ECC error counts of the GPU."},
+	)
+}
+
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -76,20 +92,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -154,15 +157,18 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 	for uuid, dev := range devs {
 		eccMode, err := c.getECCModeEnabledFunc(uuid, dev)
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error getting ECC mode"
+			cr.reasonCode = ReasonCodeECCModeReadError
 
 			if errors.Is(err, nvmlerrors.ErrGPURequiresReset) {
 				cr.reason = nvmlerrors.ErrGPURequiresReset.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPURequiresReset
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPURequiresReset.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -173,6 +179,7 @@ func (c *component) Check() components.CheckResult {
 
 			if errors.Is(err, nvmlerrors.ErrGPULost) {
 				cr.reason = nvmlerrors.ErrGPULost.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPULost
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPULost.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -191,9 +198,11 @@ func (c *component) Check() components.CheckResult {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error getting ECC errors"
+			cr.reasonCode = ReasonCodeECCErrorsReadError
 
 			if errors.Is(err, nvmlerrors.ErrGPURequiresReset) {
 				cr.reason = nvmlerrors.ErrGPURequiresReset.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPURequiresReset
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPURequiresReset.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -204,6 +213,7 @@ func (c *component) Check() components.CheckResult {
 
 			if errors.Is(err, nvmlerrors.ErrGPULost) {
 				cr.reason = nvmlerrors.ErrGPULost.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPULost
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPULost.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -217,10 +227,10 @@ func (c *component) Check() components.CheckResult {
 		}
 		cr.ECCErrors = append(cr.ECCErrors, eccErrors)
 
-		metricAggregateTotalCorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Aggregate.Total.Corrected))
-		metricAggregateTotalUncorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Aggregate.Total.Uncorrected))
-		metricVolatileTotalCorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Volatile.Total.Corrected))
-		metricVolatileTotalUncorrected.With(prometheus.Labels{"uuid": uuid}).Set(float64(eccErrors.Volatile.Total.Uncorrected))
+		metricAggregateTotalCorrected.With(labeler.Labels(uuid)).Set(float64(eccErrors.Aggregate.Total.Corrected))
+		metricAggregateTotalUncorrected.With(labeler.Labels(uuid)).Set(float64(eccErrors.Aggregate.Total.Uncorrected))
+		metricVolatileTotalCorrected.With(labeler.Labels(uuid)).Set(float64(eccErrors.Volatile.Total.Corrected))
+		metricVolatileTotalUncorrected.With(labeler.Labels(uuid)).Set(float64(eccErrors.Volatile.Total.Uncorrected))
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
@@ -246,6 +256,8 @@ type checkResult struct {
 	suggestedActions *apiv1.SuggestedActions
 	// tracks the reason of the last check
 	reason string
+	// tracks the reason code of the last check
+	reasonCode apiv1.ReasonCode
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -328,12 +340,13 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 	}
 
 	state := apiv1.HealthState{
-		Time:      metav1.NewTime(cr.ts),
-		Component: Name,
-		Name:      Name,
-		Reason:    cr.reason,
-		Error:     cr.getError(),
-		Health:    cr.health,
+		Time:       metav1.NewTime(cr.ts),
+		Component:  Name,
+		Name:       Name,
+		Reason:     cr.reason,
+		ReasonCode: cr.reasonCode,
+		Error:      cr.getError(),
+		Health:     cr.health,
 	}
 
 	// propagate suggested actions to health state if present
@@ -14,6 +14,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
@@ -292,6 +293,7 @@ func TestCheck_ECCModeError(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health, "data should be marked unhealthy")
 	assert.Equal(t, errExpected, data.err)
 	assert.Equal(t, "error getting ECC mode", data.reason)
+	assert.Equal(t, ReasonCodeECCModeReadError, data.reasonCode)
 }
 
 func TestCheck_ECCErrorsError(t *testing.T) {
@@ -342,6 +344,7 @@ func TestCheck_ECCErrorsError(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, data.health, "data should be marked unhealthy")
 	assert.Equal(t, errExpected, data.err)
 	assert.Equal(t, "error getting ECC errors", data.reason)
+	assert.Equal(t, ReasonCodeECCErrorsReadError, data.reasonCode)
 }
 
 func TestCheck_NoDevices(t *testing.T) {
@@ -974,3 +977,7 @@ func TestCheck_GPURequiresResetSuggestedActions(t *testing.T) {
 		assert.Contains(t, states[0].SuggestedActions.RepairActions, apiv1.RepairActionTypeRebootSystem)
 	})
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -4,7 +4,9 @@ package ecc
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA ECC component.
@@ -22,7 +24,7 @@ var (
 			Name:      "aggregate_total_corrected",
 			Help:      "tracks the current aggregate total corrected",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricAggregateTotalUncorrected = prometheus.NewGaugeVec(
@@ -32,7 +34,7 @@ var (
 			Name:      "aggregate_total_uncorrected",
 			Help:      "tracks the current aggregate total uncorrected",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricVolatileTotalCorrected = prometheus.NewGaugeVec(
@@ -42,7 +44,7 @@ var (
 			Name:      "volatile_total_corrected",
 			Help:      "tracks the current volatile total corrected",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricVolatileTotalUncorrected = prometheus.NewGaugeVec(
@@ -52,7 +54,7 @@ var (
 			Name:      "volatile_total_uncorrected",
 			Help:      "tracks the current volatile total uncorrected",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -63,4 +65,10 @@ func init() {
 		metricVolatileTotalCorrected,
 		metricVolatileTotalUncorrected,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_aggregate_total_corrected", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_aggregate_total_uncorrected", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_volatile_total_corrected", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_volatile_total_uncorrected", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+	)
 }
@@ -102,6 +102,7 @@ import (
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	nvidiapci "github.com/leptonai/gpud/pkg/nvidia/pci"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
 )
 
 const (
@@ -119,6 +120,8 @@ const (
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -227,24 +230,15 @@ func (c *component) IsSupported() bool {
 	if c.nvmlInstance == nil {
 		return false
 	}
+	// the integrated GPUs of Jetson/Tegra have no NVSwitch or fabric manager
+	if nvidiategra.IsTegra() {
+		return false
+	}
 	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -16,6 +16,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
@@ -1582,3 +1583,7 @@ func TestCheck_FabricStateUnhealthyLogging(t *testing.T) {
 	assert.True(t, cr.FabricStateSupported)
 	assert.Equal(t, "test unhealthy reason", cr.FabricStateReason)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
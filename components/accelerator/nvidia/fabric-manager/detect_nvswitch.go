@@ -44,8 +44,10 @@ import (
 	"fmt"
 	"strings"
 
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
 	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
+	nvidiapci "github.com/leptonai/gpud/pkg/nvidia/pci"
 	"github.com/leptonai/gpud/pkg/process"
 )
 
@@ -105,66 +107,14 @@ func isNVIDIANVSwitchPCI(line string) bool {
 	return strings.Contains(line, "nvidia") && strings.Contains(line, "bridge")
 }
 
-// listPCIs executes an lspci command and filters the output using the provided match function.
+// listPCIs returns the NVIDIA lspci lines that match the provided function.
 //
-// This is a generic helper function for enumerating PCI devices. It:
-//  1. Locates the lspci executable
-//  2. Executes the lspci command
-//  3. Filters output to only NVIDIA devices (vendor ID 10de)
-//  4. Applies the custom match function to identify specific device types
-//
-// The matchFunc parameter allows callers to specify custom logic for identifying
-// specific types of NVIDIA devices (e.g., GPUs vs NVSwitch bridges).
+// The "lspci -nn" output is shared with the GPU PCI enumeration (see nvidiapci.ListPCIs),
+// so that the NVSwitch and the GPU detection in the same check cycle run "lspci" once.
 //
 // This function is used internally by ListPCINVSwitches to enumerate NVSwitch devices.
 func listPCIs(ctx context.Context, matchFunc func(line string) bool) ([]string, error) {
-	const command = "lspci -nn"
-
-	lspciPath, err := file.LocateExecutable(strings.Split(command, " ")[0])
-	if lspciPath == "" || err != nil {
-		return nil, fmt.Errorf("failed to locate lspci: %w", err)
-	}
-
-	p, err := process.New(
-		process.WithCommand(command),
-		process.WithRunAsBashScript(),
-		process.WithRunBashInline(),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := p.Start(ctx); err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := p.Close(ctx); err != nil {
-			log.Logger.Warnw("failed to abort command", "err", err)
-		}
-	}()
-
-	lines := make([]string, 0)
-	if err := process.Read(
-		ctx,
-		p,
-		process.WithReadStdout(),
-		process.WithReadStderr(),
-		process.WithProcessLine(func(line string) {
-			// Only process lines containing NVIDIA vendor ID
-			if !strings.Contains(strings.ToLower(line), DeviceVendorID) {
-				return
-			}
-
-			// Apply the custom match function to identify specific device types
-			if matchFunc != nil && matchFunc(line) {
-				lines = append(lines, line)
-			}
-		}),
-		process.WithWaitForCmd(),
-	); err != nil {
-		return nil, fmt.Errorf("failed to read lspci output: %w\n\noutput:\n%s", err, strings.Join(lines, "\n"))
-	}
-	return lines, nil
+	return nvidiapci.ListPCIs(ctx, matchFunc)
 }
 
 // CountSMINVSwitches queries nvidia-smi to count GPUs with NVLink connections,
@@ -200,6 +150,26 @@ func CountSMINVSwitches(ctx context.Context) ([]string, error) {
 func countSMINVSwitches(ctx context.Context) ([]string, error) {
 	const command = "nvidia-smi nvlink --status"
 
+	// the output is shared with the concurrent callers and cached within a check cycle
+	out, err := pkgexec.Do(ctx, command, func(ctx context.Context) ([]byte, error) {
+		lines, err := readSMINVLinkGPUs(ctx, command)
+		return []byte(strings.Join(lines, "\n")), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// readSMINVLinkGPUs runs the nvidia-smi command and returns the GPU description lines.
+func readSMINVLinkGPUs(ctx context.Context, command string) ([]string, error) {
 	execPath, err := file.LocateExecutable(strings.Split(command, " ")[0])
 	if execPath == "" || err != nil {
 		return nil, fmt.Errorf("failed to locate nvidia-smi: %w", err)
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
 	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/process"
 )
@@ -48,6 +49,8 @@ func TestListPCINVSwitchesAndCountSMINVSwitches_Wrappers(t *testing.T) {
 	lockMockeyPatch(t)
 
 	mockey.PatchConvey("ListPCINVSwitches wrapper calls listPCIs path", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{}, nil).Build()
 		mockey.Mock(process.Read).Return(nil).Build()
@@ -58,6 +61,8 @@ func TestListPCINVSwitchesAndCountSMINVSwitches_Wrappers(t *testing.T) {
 	})
 
 	mockey.PatchConvey("CountSMINVSwitches wrapper calls countSMINVSwitches path", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{}, nil).Build()
 		mockey.Mock(process.Read).Return(nil).Build()
@@ -72,6 +77,8 @@ func TestListPCIs_ErrorsWithMockey(t *testing.T) {
 	lockMockeyPatch(t)
 
 	mockey.PatchConvey("lspci executable not found", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("", errors.New("not found")).Build()
 
 		lines, err := listPCIs(context.Background(), isNVIDIANVSwitchPCI)
@@ -81,6 +88,8 @@ func TestListPCIs_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process.New failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(nil, errors.New("new failed")).Build()
 
@@ -91,6 +100,8 @@ func TestListPCIs_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process start failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{startErr: errors.New("start failed")}, nil).Build()
 
@@ -101,6 +112,8 @@ func TestListPCIs_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process read failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{}, nil).Build()
 		mockey.Mock(process.Read).Return(errors.New("read failed")).Build()
@@ -112,6 +125,8 @@ func TestListPCIs_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("close failure is ignored", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{closeErr: errors.New("close failed")}, nil).Build()
 		mockey.Mock(process.Read).Return(nil).Build()
@@ -126,6 +141,8 @@ func TestCountSMINVSwitches_ErrorsWithMockey(t *testing.T) {
 	lockMockeyPatch(t)
 
 	mockey.PatchConvey("nvidia-smi executable not found", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("", errors.New("not found")).Build()
 
 		lines, err := countSMINVSwitches(context.Background())
@@ -135,6 +152,8 @@ func TestCountSMINVSwitches_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process.New failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(nil, errors.New("new failed")).Build()
 
@@ -145,6 +164,8 @@ func TestCountSMINVSwitches_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process start failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{startErr: errors.New("start failed")}, nil).Build()
 
@@ -155,6 +176,8 @@ func TestCountSMINVSwitches_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("process read failure", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{}, nil).Build()
 		mockey.Mock(process.Read).Return(errors.New("read failed")).Build()
@@ -166,6 +189,8 @@ func TestCountSMINVSwitches_ErrorsWithMockey(t *testing.T) {
 	})
 
 	mockey.PatchConvey("close failure is ignored", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{closeErr: errors.New("close failed")}, nil).Build()
 		mockey.Mock(process.Read).Return(nil).Build()
@@ -181,6 +206,8 @@ func TestListPCIs_FiltersNonNVIDIAVendors_WithMockey(t *testing.T) {
 
 	data := []byte("0000:00:1f.0 ISA bridge [0601]: Intel Corporation Device [8086:1234]\n0005:00:00.0 Bridge [0680]: NVIDIA Corporation Device [10de:1af1] (rev a1)")
 	mockey.PatchConvey("listPCIs filters non-NVIDIA vendors", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{
 			stdoutReader: bytes.NewReader(data),
@@ -198,6 +225,8 @@ func TestCountSMINVSwitches_FiltersNonGPULines_WithMockey(t *testing.T) {
 
 	data := []byte("GPU 0: NVIDIA A100-SXM4-80GB (UUID: GPU-1)\nGPU 0 Link 0: 25.781 GB/s\nGPU 1: NVIDIA A100-SXM4-80GB (UUID: GPU-2)")
 	mockey.PatchConvey("countSMINVSwitches keeps only GPU descriptor lines", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/nvidia-smi", nil).Build()
 		mockey.Mock(process.New).Return(&mockProcess{
 			stdoutReader: bytes.NewReader(data),
@@ -0,0 +1,299 @@
+// Package gds monitors the NVIDIA GPUDirect Storage (GDS) readiness,
+// such as the nvidia-fs kernel module, the cufile.json configuration,
+// and the kernel drivers required by the configured storage paths.
+// Optional, enabled if the host has NVIDIA GPUs.
+package gds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+const (
+	// Name is the ID of the NVIDIA GPUDirect Storage component.
+	Name = "accelerator-nvidia-gds"
+
+	// DefaultProbeInterval is the minimum interval between the cuFile probes,
+	// since each probe issues real I/O to the storage.
+	DefaultProbeInterval = 10 * time.Minute
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	nvmlInstance nvidianvml.Instance
+
+	checkReadinessFunc func() (*Readiness, error)
+
+	getProbeConfigFunc func() ProbeConfig
+	runProbeFunc       func(ctx context.Context, cfg ProbeConfig) *ProbeResult
+	probeInterval      time.Duration
+
+	probeMu     sync.Mutex
+	lastProbe   *ProbeResult
+	lastProbeTS time.Time
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA GPUDirect Storage component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+
+	rc := &readinessChecker{
+		getenv:          os.Getenv,
+		cufileJSONPaths: DefaultCufileJSONPaths,
+		sysModuleDir:    DefaultSysModuleDir,
+		sysClassNVMeDir: DefaultSysClassNVMeDir,
+	}
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+
+		checkReadinessFunc: rc.check,
+
+		getProbeConfigFunc: GetDefaultProbeConfig,
+		runProbeFunc: func(ctx context.Context, cfg ProbeConfig) *ProbeResult {
+			return runProbe(ctx, cfg, runCommand)
+		},
+		probeInterval: DefaultProbeInterval,
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpudirect storage")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	cr.Readiness, cr.err = c.checkReadinessFunc()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error checking gpudirect storage readiness"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	if !cr.Readiness.Configured() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "gpudirect storage is not configured"
+		return cr
+	}
+
+	problems := append([]string{}, cr.Readiness.Problems...)
+	if cr.Readiness.NVIDIAFSLoaded {
+		cr.Probe = c.probe()
+		if cr.Probe != nil && !cr.Probe.Success {
+			problems = append(problems, fmt.Sprintf("cufile probe on %s failed", cr.Probe.Dir))
+		}
+	}
+
+	if len(problems) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "gpudirect storage misconfigured: " + strings.Join(problems, "; ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = "gpudirect storage is ready"
+	return cr
+}
+
+// probe runs the cuFile probe if enabled, at most once per probe interval.
+// It returns nil if the probe is disabled.
+func (c *component) probe() *ProbeResult {
+	if c.getProbeConfigFunc == nil || c.runProbeFunc == nil {
+		return nil
+	}
+	cfg := c.getProbeConfigFunc()
+	if cfg.Dir == "" {
+		return nil
+	}
+
+	c.probeMu.Lock()
+	defer c.probeMu.Unlock()
+
+	if c.lastProbe != nil && c.lastProbe.Dir == cfg.Dir && time.Since(c.lastProbeTS) < c.probeInterval {
+		return c.lastProbe
+	}
+
+	c.lastProbe = c.runProbeFunc(c.ctx, cfg)
+	c.lastProbeTS = time.Now()
+	if c.lastProbe != nil && !c.lastProbe.Success {
+		log.Logger.Warnw("gds probe failed", "dir", cfg.Dir, "error", c.lastProbe.Error)
+	}
+	return c.lastProbe
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Readiness *Readiness   `json:"readiness,omitempty"`
+	Probe     *ProbeResult `json:"probe,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.Readiness == nil {
+		return "no data"
+	}
+
+	b, err := yaml.Marshal(cr)
+	if err != nil {
+		return fmt.Sprintf("error marshaling data: %v", err)
+	}
+	return string(b)
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if cr.Readiness != nil {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,189 @@
+package gds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+)
+
+// mockNVMLInstance is a mock implementation of nvidianvml.Instance
+type mockNVMLInstance struct {
+	exists      bool
+	productName string
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool                  { return m.exists }
+func (m *mockNVMLInstance) Library() nvmllib.Library          { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return nil }
+func (m *mockNVMLInstance) ProductName() string               { return m.productName }
+func (m *mockNVMLInstance) Architecture() string              { return "" }
+func (m *mockNVMLInstance) Brand() string                     { return "" }
+func (m *mockNVMLInstance) DriverVersion() string             { return "" }
+func (m *mockNVMLInstance) DriverMajor() int                  { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string               { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool      { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool        { return false }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) Shutdown() error  { return nil }
+func (m *mockNVMLInstance) InitError() error { return nil }
+
+var h100 = &mockNVMLInstance{exists: true, productName: "NVIDIA H100"}
+
+// MockGDSComponent creates a component with mocked functions for testing
+func MockGDSComponent(
+	ctx context.Context,
+	nvmlInstance nvidianvml.Instance,
+	checkReadinessFunc func() (*Readiness, error),
+) components.Component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:                cctx,
+		cancel:             cancel,
+		nvmlInstance:       nvmlInstance,
+		checkReadinessFunc: checkReadinessFunc,
+		probeInterval:      DefaultProbeInterval,
+	}
+}
+
+func mustComponent(t *testing.T, c components.Component) *component {
+	t.Helper()
+
+	component, ok := c.(*component)
+	require.True(t, ok)
+	return component
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.False(t, c.IsSupported())
+	require.NoError(t, c.Close())
+}
+
+func TestIsSupported(t *testing.T) {
+	c := &component{nvmlInstance: &mockNVMLInstance{exists: true}}
+	assert.False(t, c.IsSupported())
+
+	c = &component{nvmlInstance: &mockNVMLInstance{exists: true, productName: "NVIDIA H100"}}
+	assert.True(t, c.IsSupported())
+}
+
+func TestCheckNoNVML(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), nil, nil))
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, "NVIDIA NVML instance is nil", rs.Summary())
+}
+
+func TestCheckNotConfigured(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), h100, func() (*Readiness, error) {
+		return &Readiness{}, nil
+	}))
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, "gpudirect storage is not configured", rs.Summary())
+}
+
+func TestCheckReady(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), h100, func() (*Readiness, error) {
+		return &Readiness{CufileJSONPath: "/etc/cufile.json", NVIDIAFSLoaded: true}, nil
+	}))
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, "gpudirect storage is ready", rs.Summary())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Contains(t, states[0].ExtraInfo["data"], "/etc/cufile.json")
+}
+
+func TestCheckMisconfigured(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), h100, func() (*Readiness, error) {
+		return &Readiness{
+			CufileJSONPath: "/etc/cufile.json",
+			Problems:       []string{"nvidia_fs kernel module is not loaded"},
+		}, nil
+	}))
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, rs.HealthStateType())
+	assert.Equal(t, "gpudirect storage misconfigured: nvidia_fs kernel module is not loaded", rs.Summary())
+}
+
+func TestCheckReadinessError(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), h100, func() (*Readiness, error) {
+		return nil, errors.New("permission denied")
+	}))
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, rs.HealthStateType())
+	assert.Equal(t, "error checking gpudirect storage readiness", rs.Summary())
+	assert.Equal(t, "permission denied", c.LastHealthStates()[0].Error)
+}
+
+func TestCheckProbe(t *testing.T) {
+	c := mustComponent(t, MockGDSComponent(context.Background(), h100, func() (*Readiness, error) {
+		return &Readiness{CufileJSONPath: "/etc/cufile.json", NVIDIAFSLoaded: true}, nil
+	}))
+
+	probes := 0
+	success := false
+	c.getProbeConfigFunc = func() ProbeConfig { return ProbeConfig{Dir: "/mnt/gds"} }
+	c.runProbeFunc = func(_ context.Context, cfg ProbeConfig) *ProbeResult {
+		probes++
+		return &ProbeResult{Dir: cfg.Dir, Success: success}
+	}
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, rs.HealthStateType())
+	assert.Equal(t, "gpudirect storage misconfigured: cufile probe on /mnt/gds failed", rs.Summary())
+	assert.Equal(t, 1, probes)
+
+	// cached within the probe interval
+	success = true
+	rs = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, rs.HealthStateType())
+	assert.Equal(t, 1, probes)
+
+	c.probeMu.Lock()
+	c.lastProbeTS = time.Now().Add(-2 * DefaultProbeInterval)
+	c.probeMu.Unlock()
+
+	rs = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	assert.Equal(t, 2, probes)
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateType(""), cr.HealthStateType())
+
+	states := cr.HealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,243 @@
+package gds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSysModuleDir is the directory that lists the loaded
+	// (and built-in with parameters) kernel modules.
+	DefaultSysModuleDir = "/sys/module"
+	// DefaultSysClassNVMeDir is the directory that lists the NVMe controllers.
+	DefaultSysClassNVMeDir = "/sys/class/nvme"
+
+	// EnvCufileJSONPath is the environment variable that overrides
+	// the cufile.json path, as used by the cuFile library.
+	// ref. https://docs.nvidia.com/gpudirect-storage/configuration-guide/index.html
+	EnvCufileJSONPath = "CUFILE_ENV_PATH_JSON"
+
+	nvidiaFSModule = "nvidia_fs"
+)
+
+// DefaultCufileJSONPaths is the list of the default cufile.json paths,
+// in the order of the lookup.
+var DefaultCufileJSONPaths = []string{
+	"/etc/cufile.json",
+	"/usr/local/cuda/gds/cufile.json",
+}
+
+// findCufileJSON returns the first cufile.json path that exists.
+// It returns an empty string if none exists.
+func findCufileJSON(getenv func(string) string, paths []string) string {
+	if p := getenv(EnvCufileJSONPath); p != "" {
+		paths = append([]string{p}, paths...)
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// isModuleLoaded returns true if the kernel module is loaded (or built-in).
+func isModuleLoaded(sysModuleDir string, module string) bool {
+	_, err := os.Stat(filepath.Join(sysModuleDir, module))
+	return err == nil
+}
+
+// hasNVMeControllers returns true if the host has any NVMe controller.
+func hasNVMeControllers(sysClassNVMeDir string) bool {
+	matches, err := filepath.Glob(filepath.Join(sysClassNVMeDir, "nvme*"))
+	return err == nil && len(matches) > 0
+}
+
+// CufileConfig is the subset of the cufile.json configuration
+// that is relevant to the GDS readiness.
+type CufileConfig struct {
+	Properties CufileProperties `json:"properties"`
+}
+
+// CufileProperties is the "properties" section of the cufile.json.
+type CufileProperties struct {
+	// AllowCompatMode is true if cuFile falls back to the POSIX I/O
+	// when the GDS path is not available.
+	AllowCompatMode *bool `json:"allow_compat_mode,omitempty"`
+	// RDMADevAddrList is the list of the RDMA device IP addresses
+	// used for the distributed file systems (e.g., NFS over RDMA).
+	RDMADevAddrList []string `json:"rdma_dev_addr_list,omitempty"`
+}
+
+// parseCufileJSON parses the cufile.json contents.
+// The cufile.json shipped by NVIDIA contains "//" comments,
+// which are stripped before parsing.
+func parseCufileJSON(b []byte) (*CufileConfig, error) {
+	cfg := &CufileConfig{}
+	if err := json.Unmarshal(stripJSONComments(b), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// stripJSONComments removes the "//" line comments outside of the string literals.
+func stripJSONComments(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+
+	inString := false
+	escaped := false
+	for i := 0; i < len(b); i++ {
+		ch := b[i]
+
+		if inString {
+			out = append(out, ch)
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		if ch == '"' {
+			inString = true
+			out = append(out, ch)
+			continue
+		}
+
+		if ch == '/' && i+1 < len(b) && b[i+1] == '/' {
+			// skip until the end of the line
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			if i < len(b) {
+				out = append(out, '\n')
+			}
+			continue
+		}
+
+		out = append(out, ch)
+	}
+	return out
+}
+
+// validateCufileConfig returns the list of problems found in the cufile.json configuration.
+func validateCufileConfig(cfg *CufileConfig) []string {
+	var problems []string
+	for _, addr := range cfg.Properties.RDMADevAddrList {
+		if net.ParseIP(strings.TrimSpace(addr)) == nil {
+			problems = append(problems, fmt.Sprintf("invalid rdma_dev_addr_list entry %q", addr))
+		}
+	}
+	return problems
+}
+
+// Readiness reports the GPUDirect Storage readiness of the host.
+type Readiness struct {
+	// CufileJSONPath is the path of the cufile.json in use.
+	// Empty if no cufile.json is found.
+	CufileJSONPath string `json:"cufile_json_path,omitempty"`
+	// AllowCompatMode is true if cuFile is allowed to fall back to the POSIX I/O.
+	AllowCompatMode *bool `json:"allow_compat_mode,omitempty"`
+	// RDMADevAddrList is the list of the configured RDMA device addresses.
+	RDMADevAddrList []string `json:"rdma_dev_addr_list,omitempty"`
+
+	// NVIDIAFSLoaded is true if the "nvidia_fs" kernel module is loaded.
+	NVIDIAFSLoaded bool `json:"nvidia_fs_loaded"`
+	// NVMeControllersFound is true if the host has NVMe controllers.
+	NVMeControllersFound bool `json:"nvme_controllers_found"`
+	// MissingModules is the list of the kernel modules required by the configuration
+	// but not loaded.
+	MissingModules []string `json:"missing_modules,omitempty"`
+
+	// Problems is the list of the misconfigurations found.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Configured returns true if the host is set up to use GPUDirect Storage.
+func (r *Readiness) Configured() bool {
+	return r.CufileJSONPath != "" || r.NVIDIAFSLoaded
+}
+
+var (
+	// modules required by the NVMe path
+	nvmeRequiredModules = []string{"nvme", "nvme_core"}
+	// modules required by the RDMA path (e.g., NFS over RDMA, WekaFS, GPFS)
+	rdmaRequiredModules = []string{"ib_core", "mlx5_core"}
+)
+
+type readinessChecker struct {
+	getenv          func(string) string
+	cufileJSONPaths []string
+	sysModuleDir    string
+	sysClassNVMeDir string
+}
+
+// check evaluates the GDS readiness.
+// It returns an error only if the cufile.json cannot be read.
+func (rc *readinessChecker) check() (*Readiness, error) {
+	r := &Readiness{
+		CufileJSONPath:       findCufileJSON(rc.getenv, rc.cufileJSONPaths),
+		NVIDIAFSLoaded:       isModuleLoaded(rc.sysModuleDir, nvidiaFSModule),
+		NVMeControllersFound: hasNVMeControllers(rc.sysClassNVMeDir),
+	}
+	if !r.Configured() {
+		return r, nil
+	}
+
+	if !r.NVIDIAFSLoaded {
+		r.Problems = append(r.Problems, "nvidia_fs kernel module is not loaded")
+	}
+
+	var required []string
+	if r.NVMeControllersFound {
+		required = append(required, nvmeRequiredModules...)
+	}
+
+	if r.CufileJSONPath == "" {
+		r.Problems = append(r.Problems, "cufile.json not found")
+	} else {
+		b, err := os.ReadFile(r.CufileJSONPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				r.Problems = append(r.Problems, "cufile.json not found")
+				return r, nil
+			}
+			return nil, err
+		}
+
+		cfg, err := parseCufileJSON(b)
+		if err != nil {
+			r.Problems = append(r.Problems, fmt.Sprintf("failed to parse %s: %v", r.CufileJSONPath, err))
+		} else {
+			r.AllowCompatMode = cfg.Properties.AllowCompatMode
+			r.RDMADevAddrList = cfg.Properties.RDMADevAddrList
+			r.Problems = append(r.Problems, validateCufileConfig(cfg)...)
+
+			if len(cfg.Properties.RDMADevAddrList) > 0 {
+				required = append(required, rdmaRequiredModules...)
+			}
+		}
+	}
+
+	for _, m := range required {
+		if !isModuleLoaded(rc.sysModuleDir, m) {
+			r.MissingModules = append(r.MissingModules, m)
+		}
+	}
+	sort.Strings(r.MissingModules)
+	if len(r.MissingModules) > 0 {
+		r.Problems = append(r.Problems, fmt.Sprintf("missing kernel modules: %s", strings.Join(r.MissingModules, ", ")))
+	}
+
+	return r, nil
+}
@@ -0,0 +1,161 @@
+package gds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCufileJSON = `{
+    // NOTE : Application can override custom configuration via export CUFILE_ENV_PATH_JSON=<filepath>
+    // e.g : export CUFILE_ENV_PATH_JSON="/home/<xxx>/cufile.json"
+    "logging": {
+        "dir": "/var/log", // comment after a value
+        "level": "ERROR"
+    },
+    "properties": {
+        "allow_compat_mode": true,
+        "rdma_dev_addr_list": [ "172.16.8.88", "172.16.8.89" ],
+        "url": "http://example.com"
+    }
+}
+`
+
+func TestStripJSONComments(t *testing.T) {
+	in := []byte("{\n  // comment\n  \"a\": \"http://x//y\", // trailing\n  \"b\": \"escaped \\\" // not a comment\"\n}")
+	out := stripJSONComments(in)
+	assert.Equal(t, "{\n  \n  \"a\": \"http://x//y\", \n  \"b\": \"escaped \\\" // not a comment\"\n}", string(out))
+}
+
+func TestParseCufileJSON(t *testing.T) {
+	cfg, err := parseCufileJSON([]byte(testCufileJSON))
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Properties.AllowCompatMode)
+	assert.True(t, *cfg.Properties.AllowCompatMode)
+	assert.Equal(t, []string{"172.16.8.88", "172.16.8.89"}, cfg.Properties.RDMADevAddrList)
+	assert.Empty(t, validateCufileConfig(cfg))
+
+	_, err = parseCufileJSON([]byte(`{"properties": `))
+	require.Error(t, err)
+}
+
+func TestValidateCufileConfig(t *testing.T) {
+	cfg := &CufileConfig{Properties: CufileProperties{RDMADevAddrList: []string{"10.0.0.1", "not-an-ip"}}}
+	assert.Equal(t, []string{`invalid rdma_dev_addr_list entry "not-an-ip"`}, validateCufileConfig(cfg))
+}
+
+func TestFindCufileJSON(t *testing.T) {
+	dir := t.TempDir()
+	p1 := filepath.Join(dir, "cufile1.json")
+	p2 := filepath.Join(dir, "cufile2.json")
+	require.NoError(t, os.WriteFile(p2, []byte("{}"), 0644))
+
+	noenv := func(string) string { return "" }
+	assert.Equal(t, p2, findCufileJSON(noenv, []string{p1, p2}))
+	assert.Empty(t, findCufileJSON(noenv, []string{p1}))
+
+	require.NoError(t, os.WriteFile(p1, []byte("{}"), 0644))
+	env := func(k string) string {
+		if k == EnvCufileJSONPath {
+			return p2
+		}
+		return ""
+	}
+	assert.Equal(t, p2, findCufileJSON(env, []string{p1}))
+}
+
+type testHost struct {
+	root            string
+	cufileJSONPath  string
+	sysModuleDir    string
+	sysClassNVMeDir string
+}
+
+func newTestHost(t *testing.T) *testHost {
+	root := t.TempDir()
+	h := &testHost{
+		root:            root,
+		cufileJSONPath:  filepath.Join(root, "etc", "cufile.json"),
+		sysModuleDir:    filepath.Join(root, "sys", "module"),
+		sysClassNVMeDir: filepath.Join(root, "sys", "class", "nvme"),
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(h.cufileJSONPath), 0755))
+	require.NoError(t, os.MkdirAll(h.sysModuleDir, 0755))
+	require.NoError(t, os.MkdirAll(h.sysClassNVMeDir, 0755))
+	return h
+}
+
+func (h *testHost) loadModules(t *testing.T, modules ...string) {
+	for _, m := range modules {
+		require.NoError(t, os.MkdirAll(filepath.Join(h.sysModuleDir, m), 0755))
+	}
+}
+
+func (h *testHost) checker() *readinessChecker {
+	return &readinessChecker{
+		getenv:          func(string) string { return "" },
+		cufileJSONPaths: []string{h.cufileJSONPath},
+		sysModuleDir:    h.sysModuleDir,
+		sysClassNVMeDir: h.sysClassNVMeDir,
+	}
+}
+
+func TestReadinessNotConfigured(t *testing.T) {
+	h := newTestHost(t)
+
+	r, err := h.checker().check()
+	require.NoError(t, err)
+	assert.False(t, r.Configured())
+	assert.Empty(t, r.Problems)
+}
+
+func TestReadinessReady(t *testing.T) {
+	h := newTestHost(t)
+	require.NoError(t, os.WriteFile(h.cufileJSONPath, []byte(testCufileJSON), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(h.sysClassNVMeDir, "nvme0"), 0755))
+	h.loadModules(t, "nvidia_fs", "nvme", "nvme_core", "ib_core", "mlx5_core")
+
+	r, err := h.checker().check()
+	require.NoError(t, err)
+	assert.True(t, r.Configured())
+	assert.True(t, r.NVIDIAFSLoaded)
+	assert.True(t, r.NVMeControllersFound)
+	assert.Equal(t, h.cufileJSONPath, r.CufileJSONPath)
+	assert.Empty(t, r.MissingModules)
+	assert.Empty(t, r.Problems)
+}
+
+func TestReadinessMisconfigured(t *testing.T) {
+	h := newTestHost(t)
+	require.NoError(t, os.WriteFile(h.cufileJSONPath, []byte(testCufileJSON), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(h.sysClassNVMeDir, "nvme0"), 0755))
+	h.loadModules(t, "nvme", "nvme_core")
+
+	r, err := h.checker().check()
+	require.NoError(t, err)
+	assert.True(t, r.Configured())
+	assert.False(t, r.NVIDIAFSLoaded)
+	assert.Equal(t, []string{"ib_core", "mlx5_core"}, r.MissingModules)
+	assert.Equal(t, []string{
+		"nvidia_fs kernel module is not loaded",
+		"missing kernel modules: ib_core, mlx5_core",
+	}, r.Problems)
+}
+
+func TestReadinessMissingOrInvalidCufileJSON(t *testing.T) {
+	h := newTestHost(t)
+	h.loadModules(t, "nvidia_fs")
+
+	r, err := h.checker().check()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cufile.json not found"}, r.Problems)
+
+	require.NoError(t, os.WriteFile(h.cufileJSONPath, []byte("{"), 0644))
+	r, err = h.checker().check()
+	require.NoError(t, err)
+	require.Len(t, r.Problems, 1)
+	assert.Contains(t, r.Problems[0], "failed to parse")
+}
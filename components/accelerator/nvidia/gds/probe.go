@@ -0,0 +1,152 @@
+package gds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultGdsioPath is the default path of the "gdsio" tool
+	// shipped with the GDS package.
+	DefaultGdsioPath = "/usr/local/cuda/gds/tools/gdsio"
+
+	// DefaultProbeFileSize is the default size of the probe file.
+	// Kept small to not interfere with the workloads.
+	DefaultProbeFileSize = "1M"
+
+	// DefaultProbeTimeout is the default timeout of each probe I/O.
+	DefaultProbeTimeout = 30 * time.Second
+
+	probeFileName = ".gpud-gds-probe"
+)
+
+// ProbeConfig configures the optional cuFile read/write probe.
+// The probe is disabled if the directory is empty.
+type ProbeConfig struct {
+	// Dir is the directory on the GDS-enabled file system
+	// to write the probe file to.
+	Dir string `json:"dir"`
+	// GdsioPath is the path of the "gdsio" tool.
+	// Defaults to DefaultGdsioPath if empty.
+	GdsioPath string `json:"gdsio_path,omitempty"`
+	// GPUIndex is the GPU index to run the probe I/O on.
+	GPUIndex int `json:"gpu_index,omitempty"`
+	// FileSize is the size of the probe file (e.g., "1M").
+	// Defaults to DefaultProbeFileSize if empty.
+	FileSize string `json:"file_size,omitempty"`
+}
+
+// Validate returns an error if the probe config is invalid.
+func (cfg ProbeConfig) Validate() error {
+	if cfg.Dir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(cfg.Dir) {
+		return fmt.Errorf("probe dir %q must be an absolute path", cfg.Dir)
+	}
+	if cfg.GPUIndex < 0 {
+		return fmt.Errorf("gpu index must be non-negative, got %d", cfg.GPUIndex)
+	}
+	return nil
+}
+
+var (
+	defaultProbeConfigMu sync.RWMutex
+	defaultProbeConfig   ProbeConfig
+)
+
+// GetDefaultProbeConfig returns the current default probe config.
+func GetDefaultProbeConfig() ProbeConfig {
+	defaultProbeConfigMu.RLock()
+	defer defaultProbeConfigMu.RUnlock()
+
+	return defaultProbeConfig
+}
+
+// SetDefaultProbeConfig replaces the default probe config.
+func SetDefaultProbeConfig(cfg ProbeConfig) {
+	log.Logger.Infow("setting default gds probe config", "dir", cfg.Dir, "gdsioPath", cfg.GdsioPath)
+
+	defaultProbeConfigMu.Lock()
+	defer defaultProbeConfigMu.Unlock()
+	defaultProbeConfig = cfg
+}
+
+// ProbeResult is the result of the cuFile read/write probe.
+type ProbeResult struct {
+	Dir     string `json:"dir"`
+	Success bool   `json:"success"`
+	// Took is the time taken to write and read the probe file.
+	Took  string `json:"took,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// runCommandFunc runs the command and returns the combined output.
+type runCommandFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- the gdsio path is set by the operator via the probe config.
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// runProbe writes and reads back a small file with the GPU direct transfer type ("-x 0"),
+// which fails if cuFile cannot use the GDS path for the file system.
+func runProbe(ctx context.Context, cfg ProbeConfig, run runCommandFunc) *ProbeResult {
+	gdsioPath := cfg.GdsioPath
+	if gdsioPath == "" {
+		gdsioPath = DefaultGdsioPath
+	}
+	fileSize := cfg.FileSize
+	if fileSize == "" {
+		fileSize = DefaultProbeFileSize
+	}
+
+	rs := &ProbeResult{Dir: cfg.Dir}
+	if _, err := os.Stat(gdsioPath); err != nil {
+		rs.Error = fmt.Sprintf("gdsio not found: %v", err)
+		return rs
+	}
+
+	probeFile := filepath.Join(cfg.Dir, probeFileName)
+	defer func() {
+		if err := os.Remove(probeFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Logger.Warnw("failed to remove gds probe file", "file", probeFile, "error", err)
+		}
+	}()
+
+	start := time.Now()
+
+	// "-I 1" to write, "-I 0" to read
+	for _, ioType := range []string{"1", "0"} {
+		args := []string{
+			"-f", probeFile,
+			"-d", fmt.Sprintf("%d", cfg.GPUIndex),
+			"-w", "1",
+			"-s", fileSize,
+			"-i", fileSize,
+			"-x", "0",
+			"-I", ioType,
+		}
+
+		cctx, ccancel := context.WithTimeout(ctx, DefaultProbeTimeout)
+		out, err := run(cctx, gdsioPath, args...)
+		ccancel()
+		if err != nil {
+			rs.Error = fmt.Sprintf("gdsio %s failed: %v (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+			return rs
+		}
+	}
+
+	rs.Success = true
+	rs.Took = time.Since(start).String()
+	return rs
+}
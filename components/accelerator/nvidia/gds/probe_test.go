@@ -0,0 +1,73 @@
+package gds
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeConfigValidate(t *testing.T) {
+	require.NoError(t, ProbeConfig{}.Validate())
+	require.NoError(t, ProbeConfig{Dir: "/mnt/gds"}.Validate())
+	require.Error(t, ProbeConfig{Dir: "mnt/gds"}.Validate())
+	require.Error(t, ProbeConfig{Dir: "/mnt/gds", GPUIndex: -1}.Validate())
+}
+
+func TestSetDefaultProbeConfig(t *testing.T) {
+	orig := GetDefaultProbeConfig()
+	defer SetDefaultProbeConfig(orig)
+
+	SetDefaultProbeConfig(ProbeConfig{Dir: "/mnt/gds", GPUIndex: 1})
+	assert.Equal(t, ProbeConfig{Dir: "/mnt/gds", GPUIndex: 1}, GetDefaultProbeConfig())
+}
+
+func TestRunProbe(t *testing.T) {
+	dir := t.TempDir()
+	gdsio := filepath.Join(dir, "gdsio")
+	require.NoError(t, os.WriteFile(gdsio, []byte("#!/bin/sh\n"), 0755))
+
+	var calls [][]string
+	run := func(_ context.Context, name string, args ...string) ([]byte, error) {
+		assert.Equal(t, gdsio, name)
+		calls = append(calls, args)
+		return nil, nil
+	}
+
+	rs := runProbe(context.Background(), ProbeConfig{Dir: dir, GdsioPath: gdsio, GPUIndex: 2}, run)
+	require.True(t, rs.Success, rs.Error)
+	assert.Equal(t, dir, rs.Dir)
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"-f", filepath.Join(dir, probeFileName), "-d", "2", "-w", "1", "-s", "1M", "-i", "1M", "-x", "0", "-I", "1"}, calls[0])
+	assert.Equal(t, "0", calls[1][len(calls[1])-1])
+}
+
+func TestRunProbeFailure(t *testing.T) {
+	dir := t.TempDir()
+	gdsio := filepath.Join(dir, "gdsio")
+	require.NoError(t, os.WriteFile(gdsio, []byte("#!/bin/sh\n"), 0755))
+
+	run := func(_ context.Context, _ string, _ ...string) ([]byte, error) {
+		return []byte("cuFileHandleRegister error"), errors.New("exit status 255")
+	}
+
+	rs := runProbe(context.Background(), ProbeConfig{Dir: dir, GdsioPath: gdsio}, run)
+	assert.False(t, rs.Success)
+	assert.Contains(t, rs.Error, "cuFileHandleRegister error")
+}
+
+func TestRunProbeGdsioNotFound(t *testing.T) {
+	dir := t.TempDir()
+	run := func(_ context.Context, _ string, _ ...string) ([]byte, error) {
+		t.Fatal("unexpected command run")
+		return nil, nil
+	}
+
+	rs := runProbe(context.Background(), ProbeConfig{Dir: dir, GdsioPath: filepath.Join(dir, "gdsio")}, run)
+	assert.False(t, rs.Success)
+	assert.Contains(t, rs.Error, "gdsio not found")
+}
@@ -65,6 +65,8 @@ var defaultGPMMetricIDs = []gonvml.GpmMetricId{
 }
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -120,20 +122,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -198,6 +187,7 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 
 	// First, check if all GPUs support GPM
 	for uuid, dev := range devs {
@@ -280,7 +270,7 @@ func (c *component) Check() components.CheckResult {
 		})
 
 		for metricID, metricValue := range metrics {
-			recordGPMMetricByID(metricID, uuid, metricValue)
+			recordGPMMetricByID(metricID, labeler.Labels(uuid), metricValue)
 		}
 	}
 
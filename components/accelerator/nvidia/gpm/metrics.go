@@ -5,8 +5,10 @@ import (
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for NVIDIA GPM metrics.
@@ -29,7 +31,7 @@ var (
 			Name:      "gpu_sm_occupancy_percent",
 			Help:      "tracks the current GPU SM occupancy, as a percentage of warps that were active vs theoretical maximum",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUIntUtilPercent is the percentage of time the GPU's SMs were doing integer operations (0.0 - 100.0).
@@ -41,7 +43,7 @@ var (
 		Name:      "gpu_int_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing integer operations",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUAnyTensorUtilPercent is the percentage of time the GPU's SMs were doing ANY tensor operations (0.0 - 100.0).
@@ -53,7 +55,7 @@ var (
 		Name:      "gpu_any_tensor_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing ANY tensor operations",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUDFMATensorUtilPercent is the percentage of time the GPU's SMs were doing DFMA tensor operations (0.0 - 100.0).
@@ -65,7 +67,7 @@ var (
 		Name:      "gpu_dfma_tensor_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing DFMA tensor operations",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUHMMATensorUtilPercent is the percentage of time the GPU's SMs were doing HMMA tensor operations (0.0 - 100.0).
@@ -77,7 +79,7 @@ var (
 		Name:      "gpu_hmma_tensor_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing HMMA tensor operations",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUIMMATensorUtilPercent is the percentage of time the GPU's SMs were doing IMMA tensor operations (0.0 - 100.0).
@@ -89,7 +91,7 @@ var (
 		Name:      "gpu_imma_tensor_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing IMMA tensor operations",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUFp64UtilPercent is the percentage of time the GPU's SMs were doing non-tensor FP64 math (0.0 - 100.0).
@@ -101,7 +103,7 @@ var (
 		Name:      "gpu_fp64_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing non-tensor FP64 math",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUFp32UtilPercent is the percentage of time the GPU's SMs were doing non-tensor FP32 math (0.0 - 100.0).
@@ -113,7 +115,7 @@ var (
 		Name:      "gpu_fp32_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing non-tensor FP32 math",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	// metricGPUFp16UtilPercent is the percentage of time the GPU's SMs were doing non-tensor FP16 math (0.0 - 100.0).
@@ -125,38 +127,38 @@ var (
 		Name:      "gpu_fp16_util_percent",
 		Help:      "tracks the percentage of time the GPU's SMs were doing non-tensor FP16 math",
 	},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
-func recordGPMMetricByID(metricID nvml.GpmMetricId, gpuID string, pct float64) {
+func recordGPMMetricByID(metricID nvml.GpmMetricId, labels prometheus.Labels, pct float64) {
 	switch metricID {
 	case nvml.GPM_METRIC_SM_OCCUPANCY:
-		metricGPUSMOccupancyPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUSMOccupancyPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_INTEGER_UTIL:
-		metricGPUIntUtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUIntUtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_ANY_TENSOR_UTIL:
-		metricGPUAnyTensorUtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUAnyTensorUtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_DFMA_TENSOR_UTIL:
-		metricGPUDFMATensorUtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUDFMATensorUtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_HMMA_TENSOR_UTIL:
-		metricGPUHMMATensorUtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUHMMATensorUtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_IMMA_TENSOR_UTIL:
-		metricGPUIMMATensorUtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUIMMATensorUtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_FP64_UTIL:
-		metricGPUFp64UtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUFp64UtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_FP32_UTIL:
-		metricGPUFp32UtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUFp32UtilPercent.With(labels).Set(pct)
 
 	case nvml.GPM_METRIC_FP16_UTIL:
-		metricGPUFp16UtilPercent.With(prometheus.Labels{"uuid": gpuID}).Set(pct)
+		metricGPUFp16UtilPercent.With(labels).Set(pct)
 
 	default:
 		log.Logger.Warnw("unsupported gpm metric id", "id", metricID)
@@ -175,4 +177,15 @@ func init() {
 		metricGPUFp32UtilPercent,
 		metricGPUFp16UtilPercent,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_sm_occupancy_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_int_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_any_tensor_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_dfma_tensor_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_hmma_tensor_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_imma_tensor_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_fp64_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_fp32_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_fp16_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	)
 }
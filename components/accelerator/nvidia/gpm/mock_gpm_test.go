@@ -11,12 +11,14 @@ import (
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
 	"github.com/bytedance/mockey"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
@@ -890,7 +892,7 @@ func TestRecordGPMMetricByID_AllMetricTypes(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Just verify it doesn't panic - the function sets prometheus metrics
-			recordGPMMetricByID(tc.metricID, "test-gpu-uuid", 75.5)
+			recordGPMMetricByID(tc.metricID, prometheus.Labels{nvidianvml.LabelGPUUUID: "test-gpu-uuid", nvidianvml.LabelGPUIndex: "0"}, 75.5)
 		})
 	}
 
@@ -898,7 +900,7 @@ func TestRecordGPMMetricByID_AllMetricTypes(t *testing.T) {
 	t.Run("Unsupported metric ID", func(t *testing.T) {
 		// Use an unsupported metric ID to hit the default case
 		// This should log a warning but not panic
-		recordGPMMetricByID(nvml.GpmMetricId(9999), "test-gpu-uuid", 0.0)
+		recordGPMMetricByID(nvml.GpmMetricId(9999), prometheus.Labels{nvidianvml.LabelGPUUUID: "test-gpu-uuid", nvidianvml.LabelGPUIndex: "0"}, 0.0)
 	})
 }
 
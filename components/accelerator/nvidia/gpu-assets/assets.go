@@ -0,0 +1,218 @@
+package gpuassets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameGPUAssets = "nvidia_gpu_assets"
+
+	columnID        = "id"
+	columnSerial    = "serial"
+	columnUUID      = "uuid"
+	columnPCIBusID  = "pci_bus_id"
+	columnFirstSeen = "first_seen"
+	columnLastSeen  = "last_seen"
+)
+
+// Asset is a physical GPU card installed in the machine.
+type Asset struct {
+	// Serial is the board serial number of the GPU.
+	// Empty if not supported by the GPU.
+	Serial string `json:"serial,omitempty"`
+	// UUID is the GPU UUID.
+	UUID string `json:"uuid"`
+	// PCIBusID is the PCI bus ID of the slot the GPU is installed in.
+	PCIBusID string `json:"pci_bus_id"`
+
+	// FirstSeen is when the GPU was first seen in this machine.
+	FirstSeen time.Time `json:"first_seen"`
+	// LastSeen is when the GPU was last seen in this machine.
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// ID returns the identity of the physical GPU card,
+// the serial number if available, otherwise the UUID.
+func (a Asset) ID() string {
+	if a.Serial != "" {
+		return a.Serial
+	}
+	return a.UUID
+}
+
+// CreateTable creates the table for the GPU asset inventory.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s TEXT,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s INTEGER NOT NULL,
+	%s INTEGER NOT NULL
+);`, tableNameGPUAssets, columnID, columnSerial, columnUUID, columnPCIBusID, columnFirstSeen, columnLastSeen))
+	return err
+}
+
+// ReadAssets returns the stored GPU asset inventory, sorted by the PCI bus ID.
+func ReadAssets(ctx context.Context, dbRO *sql.DB) ([]Asset, error) {
+	start := time.Now()
+	rows, err := dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s FROM %s
+ORDER BY %s ASC`, columnSerial, columnUUID, columnPCIBusID, columnFirstSeen, columnLastSeen, tableNameGPUAssets, columnPCIBusID))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var assets []Asset
+	for rows.Next() {
+		var a Asset
+		var serial sql.NullString
+		var firstSeen, lastSeen int64
+		if err := rows.Scan(&serial, &a.UUID, &a.PCIBusID, &firstSeen, &lastSeen); err != nil {
+			return nil, err
+		}
+		a.Serial = serial.String
+		a.FirstSeen = time.Unix(firstSeen, 0).UTC()
+		a.LastSeen = time.Unix(lastSeen, 0).UTC()
+		assets = append(assets, a)
+	}
+	return assets, rows.Err()
+}
+
+// UpsertAssets inserts or updates the assets, and deletes the assets
+// with the given IDs, in a single transaction.
+func UpsertAssets(ctx context.Context, dbRW *sql.DB, assets []Asset, deleteIDs []string) error {
+	start := time.Now()
+	defer func() {
+		pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	}()
+
+	tx, err := dbRW.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, id := range deleteIDs {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = ?`, tableNameGPUAssets, columnID), id); err != nil {
+			return err
+		}
+	}
+	for _, a := range assets {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(%s) DO UPDATE SET %s = excluded.%s, %s = excluded.%s, %s = excluded.%s`,
+			tableNameGPUAssets, columnID, columnSerial, columnUUID, columnPCIBusID, columnFirstSeen, columnLastSeen,
+			columnID, columnUUID, columnUUID, columnPCIBusID, columnPCIBusID, columnLastSeen, columnLastSeen),
+			a.ID(), a.Serial, a.UUID, a.PCIBusID, a.FirstSeen.Unix(), a.LastSeen.Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ChangeType is the type of the change in the GPU asset inventory.
+type ChangeType string
+
+const (
+	// ChangeTypeReplaced is when a new GPU is installed in the slot
+	// of a GPU that is no longer present (e.g., after an RMA).
+	ChangeTypeReplaced ChangeType = "gpu-replaced"
+	// ChangeTypeMoved is when a known GPU is installed in a different slot.
+	ChangeTypeMoved ChangeType = "gpu-moved"
+	// ChangeTypeAdded is when a new GPU is installed in a previously empty slot.
+	ChangeTypeAdded ChangeType = "gpu-added"
+)
+
+// Change is a change in the GPU asset inventory.
+type Change struct {
+	Type ChangeType `json:"type"`
+	// Previous is the stored asset (nil for the added GPU).
+	Previous *Asset `json:"previous,omitempty"`
+	// Current is the scanned asset.
+	Current Asset `json:"current"`
+}
+
+// Message returns the human-readable description of the change.
+func (c Change) Message() string {
+	switch c.Type {
+	case ChangeTypeReplaced:
+		return fmt.Sprintf("GPU at %s replaced (serial %q -> %q, uuid %s -> %s)", c.Current.PCIBusID, c.Previous.Serial, c.Current.Serial, c.Previous.UUID, c.Current.UUID)
+	case ChangeTypeMoved:
+		return fmt.Sprintf("GPU serial %q (uuid %s) moved from %s to %s", c.Current.Serial, c.Current.UUID, c.Previous.PCIBusID, c.Current.PCIBusID)
+	case ChangeTypeAdded:
+		return fmt.Sprintf("GPU serial %q (uuid %s) added at %s", c.Current.Serial, c.Current.UUID, c.Current.PCIBusID)
+	default:
+		return string(c.Type)
+	}
+}
+
+// Diff compares the scanned GPUs against the stored inventory.
+// It returns the changes, the updated assets to store, and the IDs
+// of the stored assets to delete (replaced by the new GPUs).
+//
+// The stored GPUs that are missing in the scan without a replacement
+// are kept in the inventory, since the GPU may be temporarily lost
+// (e.g., fell off the bus) which is reported by the other components.
+// No change is reported when the inventory is empty (first scan).
+func Diff(stored []Asset, scanned []Asset, now time.Time) ([]Change, []Asset, []string) {
+	storedByID := make(map[string]Asset, len(stored))
+	storedByBusID := make(map[string]Asset, len(stored))
+	for _, a := range stored {
+		storedByID[a.ID()] = a
+		storedByBusID[a.PCIBusID] = a
+	}
+	scannedIDs := make(map[string]struct{}, len(scanned))
+	for _, a := range scanned {
+		scannedIDs[a.ID()] = struct{}{}
+	}
+
+	var changes []Change
+	var upserts []Asset
+	var deleteIDs []string
+	for _, cur := range scanned {
+		cur.LastSeen = now
+
+		prev, known := storedByID[cur.ID()]
+		if known {
+			cur.FirstSeen = prev.FirstSeen
+			if prev.PCIBusID != cur.PCIBusID {
+				p := prev
+				changes = append(changes, Change{Type: ChangeTypeMoved, Previous: &p, Current: cur})
+			}
+			upserts = append(upserts, cur)
+			continue
+		}
+
+		cur.FirstSeen = now
+		upserts = append(upserts, cur)
+		if len(stored) == 0 {
+			continue
+		}
+
+		prev, occupied := storedByBusID[cur.PCIBusID]
+		if _, stillPresent := scannedIDs[prev.ID()]; occupied && !stillPresent {
+			p := prev
+			changes = append(changes, Change{Type: ChangeTypeReplaced, Previous: &p, Current: cur})
+			deleteIDs = append(deleteIDs, prev.ID())
+			continue
+		}
+		changes = append(changes, Change{Type: ChangeTypeAdded, Current: cur})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Current.PCIBusID < changes[j].Current.PCIBusID })
+	return changes, upserts, deleteIDs
+}
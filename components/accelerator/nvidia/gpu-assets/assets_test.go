@@ -0,0 +1,156 @@
+package gpuassets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestAssetID(t *testing.T) {
+	assert.Equal(t, "1320", Asset{Serial: "1320", UUID: "GPU-a"}.ID())
+	assert.Equal(t, "GPU-a", Asset{UUID: "GPU-a"}.ID())
+}
+
+func TestAssetsTable(t *testing.T) {
+	ctx := context.Background()
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	require.NoError(t, CreateTable(ctx, dbRW))
+	// idempotent
+	require.NoError(t, CreateTable(ctx, dbRW))
+
+	assets, err := ReadAssets(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Empty(t, assets)
+
+	t0 := time.Unix(1700000000, 0).UTC()
+	t1 := t0.Add(time.Hour)
+	require.NoError(t, UpsertAssets(ctx, dbRW, []Asset{
+		{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:02:00.0", FirstSeen: t0, LastSeen: t0},
+		{UUID: "GPU-2", PCIBusID: "0000:01:00.0", FirstSeen: t0, LastSeen: t0},
+	}, nil))
+
+	assets, err = ReadAssets(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	assert.Equal(t, Asset{UUID: "GPU-2", PCIBusID: "0000:01:00.0", FirstSeen: t0, LastSeen: t0}, assets[0])
+	assert.Equal(t, "s1", assets[1].Serial)
+
+	// update keeps the first seen time, and deletes the replaced
+	require.NoError(t, UpsertAssets(ctx, dbRW, []Asset{
+		{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:03:00.0", FirstSeen: t1, LastSeen: t1},
+		{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:01:00.0", FirstSeen: t1, LastSeen: t1},
+	}, []string{"GPU-2"}))
+
+	assets, err = ReadAssets(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	assert.Equal(t, Asset{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:01:00.0", FirstSeen: t1, LastSeen: t1}, assets[0])
+	assert.Equal(t, Asset{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:03:00.0", FirstSeen: t0, LastSeen: t1}, assets[1])
+}
+
+func TestDiff(t *testing.T) {
+	t0 := time.Unix(1700000000, 0).UTC()
+	now := t0.Add(time.Hour)
+
+	stored := []Asset{
+		{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0", FirstSeen: t0, LastSeen: t0},
+		{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:02:00.0", FirstSeen: t0, LastSeen: t0},
+		{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0", FirstSeen: t0, LastSeen: t0},
+	}
+
+	t.Run("first scan", func(t *testing.T) {
+		changes, upserts, deleteIDs := Diff(nil, []Asset{{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"}}, now)
+		assert.Empty(t, changes)
+		assert.Empty(t, deleteIDs)
+		require.Len(t, upserts, 1)
+		assert.Equal(t, now, upserts[0].FirstSeen)
+		assert.Equal(t, now, upserts[0].LastSeen)
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		changes, upserts, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+			{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+		}, now)
+		assert.Empty(t, changes)
+		assert.Empty(t, deleteIDs)
+		require.Len(t, upserts, 3)
+		assert.Equal(t, t0, upserts[0].FirstSeen)
+		assert.Equal(t, now, upserts[0].LastSeen)
+	})
+
+	t.Run("replaced", func(t *testing.T) {
+		changes, upserts, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+			{Serial: "s4", UUID: "GPU-4", PCIBusID: "0000:02:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+		}, now)
+		require.Len(t, changes, 1)
+		assert.Equal(t, ChangeTypeReplaced, changes[0].Type)
+		assert.Equal(t, "s2", changes[0].Previous.Serial)
+		assert.Equal(t, "s4", changes[0].Current.Serial)
+		assert.Contains(t, changes[0].Message(), `"s2" -> "s4"`)
+		assert.Equal(t, []string{"s2"}, deleteIDs)
+		assert.Len(t, upserts, 3)
+	})
+
+	t.Run("swapped slots", func(t *testing.T) {
+		changes, _, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:01:00.0"},
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:02:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+		}, now)
+		require.Len(t, changes, 2)
+		assert.Equal(t, ChangeTypeMoved, changes[0].Type)
+		assert.Equal(t, "s2", changes[0].Current.Serial)
+		assert.Equal(t, "0000:02:00.0", changes[0].Previous.PCIBusID)
+		assert.Equal(t, ChangeTypeMoved, changes[1].Type)
+		assert.Contains(t, changes[1].Message(), "moved from 0000:01:00.0 to 0000:02:00.0")
+		assert.Empty(t, deleteIDs)
+	})
+
+	t.Run("missing gpu is kept", func(t *testing.T) {
+		changes, upserts, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+		}, now)
+		assert.Empty(t, changes)
+		assert.Empty(t, deleteIDs)
+		assert.Len(t, upserts, 2)
+	})
+
+	t.Run("added", func(t *testing.T) {
+		changes, _, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+			{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+			{Serial: "s5", UUID: "GPU-5", PCIBusID: "0000:05:00.0"},
+		}, now)
+		require.Len(t, changes, 1)
+		assert.Equal(t, ChangeTypeAdded, changes[0].Type)
+		assert.Nil(t, changes[0].Previous)
+		assert.Contains(t, changes[0].Message(), "added at 0000:05:00.0")
+		assert.Empty(t, deleteIDs)
+	})
+
+	t.Run("new gpu in the slot of a moved gpu", func(t *testing.T) {
+		changes, _, deleteIDs := Diff(stored, []Asset{
+			{Serial: "s4", UUID: "GPU-4", PCIBusID: "0000:01:00.0"},
+			{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:04:00.0"},
+			{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+			{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:03:00.0"},
+		}, now)
+		require.Len(t, changes, 2)
+		assert.Equal(t, ChangeTypeAdded, changes[0].Type)
+		assert.Equal(t, ChangeTypeMoved, changes[1].Type)
+		assert.Empty(t, deleteIDs)
+	})
+}
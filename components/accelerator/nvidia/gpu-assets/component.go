@@ -0,0 +1,391 @@
+// Package gpuassets tracks the NVIDIA GPU serial numbers, UUIDs, and PCI bus IDs
+// in a persistent inventory, and records the events when the GPUs are replaced
+// or moved between the slots (e.g., after an RMA).
+// Optional, enabled if the host has NVIDIA GPUs.
+package gpuassets
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// Name is the ID of the NVIDIA GPU asset tracking component.
+const Name = "accelerator-nvidia-gpu-assets"
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	nvmlInstance nvidianvml.Instance
+
+	dbRW        *sql.DB
+	dbRO        *sql.DB
+	eventBucket eventstore.Bucket
+
+	getTimeNowFunc  func() time.Time
+	scanAssetsFunc  func() ([]Asset, error)
+	tableCreateOnce sync.Once
+	tableCreateErr  error
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the NVIDIA GPU asset tracking component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+
+		dbRW: gpudInstance.DBRW,
+		dbRO: gpudInstance.DBRO,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+	c.scanAssetsFunc = c.scanAssets
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu assets")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	scanned, err := c.scanAssetsFunc()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error scanning gpu assets"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+	cr.Assets = scanned
+
+	if c.dbRW == nil || c.dbRO == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("found %d gpu(s), no database to track the inventory", len(scanned))
+		return cr
+	}
+
+	c.tableCreateOnce.Do(func() {
+		c.tableCreateErr = CreateTable(c.ctx, c.dbRW)
+	})
+	if c.tableCreateErr != nil {
+		cr.err = c.tableCreateErr
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error creating gpu assets table"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	stored, err := ReadAssets(cctx, c.dbRO)
+	ccancel()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading gpu assets"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	changes, upserts, deleteIDs := Diff(stored, scanned, cr.ts)
+	cr.Assets = upserts
+	cr.Changes = changes
+
+	cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+	err = UpsertAssets(cctx, c.dbRW, upserts, deleteIDs)
+	ccancel()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error updating gpu assets"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	for _, ch := range changes {
+		log.Logger.Warnw("gpu asset changed", "type", ch.Type, "message", ch.Message())
+		if err := c.recordChangeEvent(cr.ts, ch); err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error recording gpu asset change event"
+			log.Logger.Warnw(cr.reason, "error", cr.err)
+			return cr
+		}
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("tracking %d gpu(s)", len(upserts))
+	if len(changes) > 0 {
+		cr.reason = fmt.Sprintf("tracking %d gpu(s), %d change(s) detected", len(upserts), len(changes))
+	}
+	return cr
+}
+
+// scanAssets reads the serial numbers, UUIDs, and PCI bus IDs of the GPUs.
+func (c *component) scanAssets() ([]Asset, error) {
+	var assets []Asset
+	for uuid, dev := range c.nvmlInstance.Devices() {
+		serial, ret := dev.GetSerial()
+		if ret != nvml.SUCCESS {
+			if ret != nvml.ERROR_NOT_SUPPORTED {
+				return nil, fmt.Errorf("failed to get serial for %s: %v", uuid, nvml.ErrorString(ret))
+			}
+			serial = ""
+		}
+		assets = append(assets, Asset{
+			Serial:   serial,
+			UUID:     uuid,
+			PCIBusID: dev.PCIBusID(),
+		})
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].PCIBusID < assets[j].PCIBusID })
+	return assets, nil
+}
+
+func (c *component) recordChangeEvent(ts time.Time, ch Change) error {
+	if c.eventBucket == nil {
+		return nil
+	}
+
+	extraInfo := map[string]string{
+		"serial":     ch.Current.Serial,
+		"uuid":       ch.Current.UUID,
+		"pci_bus_id": ch.Current.PCIBusID,
+	}
+	if ch.Previous != nil {
+		extraInfo["previous_serial"] = ch.Previous.Serial
+		extraInfo["previous_uuid"] = ch.Previous.UUID
+		extraInfo["previous_pci_bus_id"] = ch.Previous.PCIBusID
+	}
+	ev := eventstore.Event{
+		Component: Name,
+		Time:      ts,
+		Name:      string(ch.Type),
+		Type:      string(apiv1.EventTypeInfo),
+		Message:   ch.Message(),
+		ExtraInfo: extraInfo,
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	found, err := c.eventBucket.Find(cctx, ev)
+	ccancel()
+	if err != nil {
+		return err
+	}
+	if found != nil {
+		return nil
+	}
+
+	cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+	err = c.eventBucket.Insert(cctx, ev)
+	ccancel()
+	return err
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Assets is the current GPU asset inventory.
+	Assets []Asset `json:"assets,omitempty"`
+	// Changes is the changes detected in the last check.
+	Changes []Change `json:"changes,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Assets) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"PCI Bus ID", "Serial", "UUID", "First Seen"})
+	for _, a := range cr.Assets {
+		firstSeen := ""
+		if !a.FirstSeen.IsZero() {
+			firstSeen = a.FirstSeen.Format(time.RFC3339)
+		}
+		table.Append([]string{a.PCIBusID, a.Serial, a.UUID, firstSeen})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	b, _ := json.Marshal(cr)
+	state.ExtraInfo = map[string]string{"data": string(b)}
+	return apiv1.HealthStates{state}
+}
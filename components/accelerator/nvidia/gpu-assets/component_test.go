@@ -0,0 +1,220 @@
+package gpuassets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// mockNVMLInstance is a mock implementation of nvidianvml.Instance
+type mockNVMLInstance struct {
+	exists      bool
+	productName string
+	devices     map[string]device.Device
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool                  { return m.exists }
+func (m *mockNVMLInstance) Library() nvmllib.Library          { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devices }
+func (m *mockNVMLInstance) ProductName() string               { return m.productName }
+func (m *mockNVMLInstance) Architecture() string              { return "" }
+func (m *mockNVMLInstance) Brand() string                     { return "" }
+func (m *mockNVMLInstance) DriverVersion() string             { return "" }
+func (m *mockNVMLInstance) DriverMajor() int                  { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string               { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool      { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool        { return false }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) Shutdown() error  { return nil }
+func (m *mockNVMLInstance) InitError() error { return nil }
+
+func newMockDevices(assets ...Asset) map[string]device.Device {
+	devs := make(map[string]device.Device)
+	for _, a := range assets {
+		devs[a.UUID] = testutil.NewMockDeviceWithIDs(&mock.Device{}, "hopper", "nvidia", "9.0", a.PCIBusID, a.UUID, a.Serial, 0, 0)
+	}
+	return devs
+}
+
+// initComponentForTest initializes a component with the test database and the mock NVML instance
+func initComponentForTest(ctx context.Context, t *testing.T, nvmlInstance *mockNVMLInstance) (*component, func()) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:      ctx,
+		NVMLInstance: nvmlInstance,
+		DBRW:         dbRW,
+		DBRO:         dbRO,
+		EventStore:   store,
+	})
+	require.NoError(t, err)
+
+	c, ok := comp.(*component)
+	require.True(t, ok, "Failed to cast to *component type")
+
+	return c, func() {
+		_ = c.Close()
+		cleanup()
+	}
+}
+
+func TestComponentBasics(t *testing.T) {
+	c, cleanup := initComponentForTest(context.Background(), t, &mockNVMLInstance{exists: true, productName: "NVIDIA H100"})
+	defer cleanup()
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.True(t, c.IsSupported())
+
+	assert.False(t, (&component{}).IsSupported())
+	assert.False(t, (&component{nvmlInstance: &mockNVMLInstance{exists: true}}).IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheckNoGPU(t *testing.T) {
+	c := &component{ctx: context.Background(), getTimeNowFunc: time.Now}
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "NVIDIA NVML instance is nil", cr.Summary())
+
+	c.nvmlInstance = &mockNVMLInstance{}
+	assert.Equal(t, "NVIDIA NVML library is not loaded", c.Check().Summary())
+
+	c.nvmlInstance = &mockNVMLInstance{exists: true}
+	assert.Contains(t, c.Check().Summary(), "missing product name")
+}
+
+func TestCheckTracksChanges(t *testing.T) {
+	nvmlInstance := &mockNVMLInstance{
+		exists:      true,
+		productName: "NVIDIA H100",
+		devices: newMockDevices(
+			Asset{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+			Asset{Serial: "s2", UUID: "GPU-2", PCIBusID: "0000:02:00.0"},
+		),
+	}
+	c, cleanup := initComponentForTest(context.Background(), t, nvmlInstance)
+	defer cleanup()
+
+	now := time.Unix(1700000000, 0).UTC()
+	c.getTimeNowFunc = func() time.Time { return now }
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "tracking 2 gpu(s)", cr.Summary())
+
+	evs, err := c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, evs)
+
+	// the GPU at 0000:02:00.0 is replaced after an RMA
+	nvmlInstance.devices = newMockDevices(
+		Asset{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+		Asset{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:02:00.0"},
+	)
+	now = now.Add(time.Minute)
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "tracking 2 gpu(s), 1 change(s) detected", cr.Summary())
+
+	evs, err = c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, string(ChangeTypeReplaced), evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeInfo, evs[0].Type)
+	assert.Contains(t, evs[0].Message, `"s2" -> "s3"`)
+
+	// no new events when nothing changed
+	now = now.Add(time.Minute)
+	cr = c.Check()
+	assert.Equal(t, "tracking 2 gpu(s)", cr.Summary())
+
+	// the GPUs are swapped between the slots
+	nvmlInstance.devices = newMockDevices(
+		Asset{Serial: "s3", UUID: "GPU-3", PCIBusID: "0000:01:00.0"},
+		Asset{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:02:00.0"},
+	)
+	now = now.Add(time.Minute)
+	_ = c.Check()
+
+	evs, err = c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 3)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var data checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &data))
+	require.Len(t, data.Assets, 2)
+	assert.Equal(t, "s3", data.Assets[0].Serial)
+	require.Len(t, data.Changes, 2)
+	assert.Equal(t, ChangeTypeMoved, data.Changes[0].Type)
+
+	assert.Contains(t, cr.String(), "0000:01:00.0")
+}
+
+func TestCheckScanError(t *testing.T) {
+	c, cleanup := initComponentForTest(context.Background(), t, &mockNVMLInstance{exists: true, productName: "NVIDIA H100"})
+	defer cleanup()
+	c.scanAssetsFunc = func() ([]Asset, error) { return nil, errors.New("nvml error") }
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "error scanning gpu assets", cr.Summary())
+	assert.Equal(t, "nvml error", cr.HealthStates()[0].Error)
+}
+
+func TestCheckNoDB(t *testing.T) {
+	c := &component{
+		ctx:            context.Background(),
+		getTimeNowFunc: time.Now,
+		nvmlInstance: &mockNVMLInstance{exists: true, productName: "NVIDIA H100", devices: newMockDevices(
+			Asset{Serial: "s1", UUID: "GPU-1", PCIBusID: "0000:01:00.0"},
+		)},
+	}
+	c.scanAssetsFunc = c.scanAssets
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "found 1 gpu(s), no database to track the inventory", cr.Summary())
+
+	evs, err := c.Events(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Empty(t, cr.String())
+	assert.Empty(t, cr.Summary())
+	assert.Empty(t, cr.HealthStateType())
+	assert.Equal(t, Name, cr.ComponentName())
+	assert.Equal(t, "no data", (&checkResult{}).String())
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	nvidiapci "github.com/leptonai/gpud/pkg/nvidia/pci"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
 )
 
 const (
@@ -34,6 +35,8 @@ const defaultLookbackPeriod = 3 * 24 * time.Hour
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -108,24 +111,15 @@ func (c *component) IsSupported() bool {
 	if c.nvmlInstance == nil {
 		return false
 	}
+	// the integrated GPUs of Jetson/Tegra have no PCI GPU devices to count
+	if nvidiategra.IsTegra() {
+		return false
+	}
 	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -0,0 +1,195 @@
+package gpumodes
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/kmsg"
+)
+
+// DefaultAuditLogPath is the default auditd log file, where the "nvidia-smi" runs
+// are recorded if the execve syscalls are audited (e.g., "auditctl -a always,exit -F arch=b64 -S execve").
+const DefaultAuditLogPath = "/var/log/audit/audit.log"
+
+// attributionSlack is the extra time before the previous check to look up the change causes,
+// as the audit and kernel message timestamps may be slightly off.
+const attributionSlack = time.Minute
+
+// auditCommand is a "nvidia-smi" run recorded by auditd.
+type auditCommand struct {
+	Time time.Time
+	Args []string
+	AUID string
+	UID  string
+}
+
+func (cmd auditCommand) String() string {
+	s := strings.Join(cmd.Args, " ")
+	var ids []string
+	if cmd.AUID != "" {
+		ids = append(ids, "auid="+cmd.AUID)
+	}
+	if cmd.UID != "" {
+		ids = append(ids, "uid="+cmd.UID)
+	}
+	if len(ids) > 0 {
+		s += " (" + strings.Join(ids, ", ") + ")"
+	}
+	return fmt.Sprintf("%s at %s", s, cmd.Time.UTC().Format(time.RFC3339))
+}
+
+// modes returns the GPU modes the command changes.
+func (cmd auditCommand) modes() map[Mode]bool {
+	modes := make(map[Mode]bool)
+	for _, arg := range cmd.Args[1:] {
+		flag, _, _ := strings.Cut(arg, "=")
+		switch flag {
+		case "-e", "--ecc-config":
+			modes[ModeECC] = true
+		case "-mig", "--multi-instance-gpu":
+			modes[ModeMIG] = true
+		case "-pm", "--persistence-mode":
+			modes[ModePersistence] = true
+		}
+	}
+	return modes
+}
+
+var (
+	// e.g., "type=EXECVE msg=audit(1700000000.123:4567): argc=3 a0="nvidia-smi" a1="-pm" a2="0""
+	auditRecordRegex = regexp.MustCompile(`^type=(\w+) msg=audit\((\d+)\.(\d+):(\d+)\):\s*(.*)$`)
+	auditFieldRegex  = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+)
+
+// parseAuditLog returns the "nvidia-smi" runs between since and until, in the log order.
+func parseAuditLog(rd io.Reader, since time.Time, until time.Time) ([]auditCommand, error) {
+	type syscallIDs struct{ auid, uid string }
+	ids := make(map[string]syscallIDs)
+
+	var cmds []auditCommand
+	var serials []string
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		m := auditRecordRegex.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		recordType, serial, body := m[1], m[4], m[5]
+
+		sec, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		ts := time.Unix(sec, 0)
+		if ts.Before(since.Truncate(time.Second)) || ts.After(until) {
+			continue
+		}
+
+		fields := make(map[string]string)
+		for _, f := range auditFieldRegex.FindAllStringSubmatch(body, -1) {
+			fields[f[1]] = f[2]
+		}
+
+		switch recordType {
+		case "SYSCALL":
+			ids[serial] = syscallIDs{auid: fields["auid"], uid: fields["uid"]}
+
+		case "EXECVE":
+			argc, _ := strconv.Atoi(fields["argc"])
+			args := make([]string, 0, argc)
+			for i := 0; i < argc; i++ {
+				args = append(args, decodeAuditValue(fields[fmt.Sprintf("a%d", i)]))
+			}
+			if len(args) == 0 || filepath.Base(args[0]) != "nvidia-smi" {
+				continue
+			}
+			cmds = append(cmds, auditCommand{Time: ts, Args: args})
+			serials = append(serials, serial)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// the SYSCALL record of the same event may be logged before or after the EXECVE record
+	for i := range cmds {
+		cmds[i].AUID = ids[serials[i]].auid
+		cmds[i].UID = ids[serials[i]].uid
+	}
+	return cmds, nil
+}
+
+// decodeAuditValue unquotes the value, or decodes the hex-encoded value
+// as auditd encodes the arguments with spaces or special characters.
+func decodeAuditValue(v string) string {
+	if strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) && len(v) >= 2 {
+		return v[1 : len(v)-1]
+	}
+	if b, err := hex.DecodeString(v); err == nil && len(v) > 0 {
+		return string(b)
+	}
+	return v
+}
+
+// readAuditCommands returns the "nvidia-smi" runs in the audit log between since and until.
+// Returns no error if the audit log does not exist (e.g., auditd not installed).
+func readAuditCommands(path string, since time.Time, until time.Time) ([]auditCommand, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return parseAuditLog(f, since, until)
+}
+
+// findAuditCause returns the last "nvidia-smi" run that changes the mode, or empty if none.
+func findAuditCause(cmds []auditCommand, mode Mode) string {
+	for i := len(cmds) - 1; i >= 0; i-- {
+		if cmds[i].modes()[mode] {
+			return cmds[i].String()
+		}
+	}
+	return ""
+}
+
+var kmsgModeKeywords = map[Mode][]string{
+	ModeECC:         {"ECC"},
+	ModeMIG:         {"MIG"},
+	ModePersistence: {"persistence", "Persistence", "nvidia-persistenced"},
+}
+
+// findKmsgCause returns the last NVIDIA kernel message about the mode between since and until,
+// or empty if none.
+func findKmsgCause(msgs []kmsg.Message, mode Mode, since time.Time, until time.Time) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		if msg.Timestamp.Time.Before(since) || msg.Timestamp.Time.After(until) {
+			continue
+		}
+		if !strings.Contains(msg.Message, "NVRM") && !strings.Contains(strings.ToLower(msg.Message), "nvidia") {
+			continue
+		}
+		for _, kw := range kmsgModeKeywords[mode] {
+			if strings.Contains(msg.Message, kw) {
+				return msg.Message
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package gpumodes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/kmsg"
+)
+
+const testAuditLog = `type=SYSCALL msg=audit(1700000000.100:101): arch=c000003e syscall=59 success=yes exit=0 ppid=1 pid=200 auid=4294967295 uid=0 comm="sleep" exe="/usr/bin/sleep"
+type=EXECVE msg=audit(1700000000.100:101): argc=2 a0="sleep" a1="1"
+type=SYSCALL msg=audit(1700000100.200:102): arch=c000003e syscall=59 success=yes exit=0 ppid=1 pid=201 auid=1000 uid=0 comm="nvidia-smi" exe="/usr/bin/nvidia-smi"
+type=EXECVE msg=audit(1700000100.200:102): argc=3 a0="nvidia-smi" a1="-pm" a2="0"
+type=EXECVE msg=audit(1700000200.300:103): argc=5 a0="/usr/bin/nvidia-smi" a1="-i" a2="0" a3="--ecc-config=0" a4=6120622063
+type=SYSCALL msg=audit(1700000200.300:103): arch=c000003e syscall=59 success=yes exit=0 ppid=1 pid=202 auid=1001 uid=1001 comm="nvidia-smi" exe="/usr/bin/nvidia-smi"
+type=EXECVE msg=audit(1700009999.000:104): argc=3 a0="nvidia-smi" a1="-mig" a2="1"
+`
+
+func TestParseAuditLog(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	until := time.Unix(1700001000, 0)
+
+	cmds, err := parseAuditLog(strings.NewReader(testAuditLog), since, until)
+	require.NoError(t, err)
+	require.Len(t, cmds, 2)
+
+	assert.Equal(t, []string{"nvidia-smi", "-pm", "0"}, cmds[0].Args)
+	assert.Equal(t, "1000", cmds[0].AUID)
+	assert.Equal(t, "0", cmds[0].UID)
+	assert.Equal(t, "nvidia-smi -pm 0 (auid=1000, uid=0) at 2023-11-14T22:15:00Z", cmds[0].String())
+
+	// the SYSCALL record after the EXECVE record, and the hex-encoded argument
+	assert.Equal(t, []string{"/usr/bin/nvidia-smi", "-i", "0", "--ecc-config=0", "a b c"}, cmds[1].Args)
+	assert.Equal(t, "1001", cmds[1].AUID)
+
+	assert.Contains(t, findAuditCause(cmds, ModePersistence), "nvidia-smi -pm 0")
+	assert.Contains(t, findAuditCause(cmds, ModeECC), "--ecc-config=0")
+	// out of the time range
+	assert.Empty(t, findAuditCause(cmds, ModeMIG))
+}
+
+func TestReadAuditCommands(t *testing.T) {
+	cmds, err := readAuditCommands(filepath.Join(t.TempDir(), "not-found.log"), time.Time{}, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, cmds)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte(testAuditLog), 0o644))
+	cmds, err = readAuditCommands(path, time.Unix(1700000000, 0), time.Unix(1700010000, 0))
+	require.NoError(t, err)
+	assert.Len(t, cmds, 3)
+	assert.Contains(t, findAuditCause(cmds, ModeMIG), "nvidia-smi -mig 1")
+}
+
+func TestFindKmsgCause(t *testing.T) {
+	now := time.Now()
+	msgs := []kmsg.Message{
+		{Timestamp: metav1.NewTime(now.Add(-time.Hour)), Message: "NVRM: GPU 0000:0f:00.0: ECC mode changed, old message"},
+		{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Message: "NVRM: GPU 0000:0f:00.0: MIG mode enabled"},
+		{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Message: "systemd[1]: nvidia-persistenced.service: Deactivated successfully."},
+		{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Message: "eth0: ECC link up"},
+	}
+	since := now.Add(-10 * time.Minute)
+
+	assert.Equal(t, "NVRM: GPU 0000:0f:00.0: MIG mode enabled", findKmsgCause(msgs, ModeMIG, since, now))
+	assert.Contains(t, findKmsgCause(msgs, ModePersistence, since, now), "nvidia-persistenced")
+	assert.Empty(t, findKmsgCause(msgs, ModeECC, since, now))
+	assert.Empty(t, findKmsgCause(nil, ModeECC, since, now))
+}
@@ -0,0 +1,510 @@
+// Package gpumodes tracks the changes of the NVIDIA ECC, MIG, and persistence modes.
+package gpumodes
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/reasoncode"
+)
+
+// Name is the ID of the NVIDIA GPU modes component.
+const Name = "accelerator-nvidia-gpu-modes"
+
+const (
+	// EventNameECCModeChanged is emitted when the current or pending ECC mode changes.
+	EventNameECCModeChanged = "ecc_mode_changed"
+	// EventNameMIGModeChanged is emitted when the current or pending MIG mode changes.
+	EventNameMIGModeChanged = "mig_mode_changed"
+	// EventNamePersistenceModeChanged is emitted when the persistence mode changes.
+	EventNamePersistenceModeChanged = "persistence_mode_changed"
+
+	EventKeyDeviceUUID  = "device_uuid"
+	EventKeyDeviceBusID = "device_bus_id"
+	EventKeyMode        = "mode"
+	EventKeyPending     = "pending"
+	EventKeyFrom        = "from"
+	EventKeyTo          = "to"
+	// EventKeyChangedBy is the "nvidia-smi" run recorded by auditd that likely changed the mode.
+	EventKeyChangedBy = "changed_by"
+	// EventKeyKmsg is the NVIDIA kernel message that likely relates to the mode change.
+	EventKeyKmsg = "kmsg"
+)
+
+var modeEventNames = map[Mode]string{
+	ModeECC:         EventNameECCModeChanged,
+	ModeMIG:         EventNameMIGModeChanged,
+	ModePersistence: EventNamePersistenceModeChanged,
+}
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: apiv1.ReasonCodeFromEventName(EventNameECCModeChanged), Event: true, Description: "The current or pending ECC mode of the GPU changed."},
+		apiv1.ReasonCodeMetadata{Code: apiv1.ReasonCodeFromEventName(EventNameMIGModeChanged), Event: true, Description: "The current or pending MIG mode of the GPU changed."},
+		apiv1.ReasonCodeMetadata{Code: apiv1.ReasonCodeFromEventName(EventNamePersistenceModeChanged), Event: true, Description: "The persistence mode of the GPU changed."},
+	)
+}
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	nvmlInstance nvidianvml.Instance
+	getModesFunc func(uuid string, dev device.Device) (Modes, error)
+
+	readAuditCommandsFunc func(since time.Time, until time.Time) ([]auditCommand, error)
+	readKmsgFunc          func(ctx context.Context) ([]kmsg.Message, error)
+
+	dbRW        *sql.DB
+	dbRO        *sql.DB
+	eventBucket eventstore.Bucket
+
+	// last observed modes, loaded from the metadata on the first check
+	prevMu     sync.Mutex
+	prev       *snapshot
+	prevLoaded bool
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// snapshot is the observed GPU modes, persisted to detect the changes across the restarts.
+type snapshot struct {
+	Time time.Time        `json:"time"`
+	GPUs map[string]Modes `json:"gpus"`
+}
+
+// New creates a NVIDIA GPU modes component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		nvmlInstance: gpudInstance.NVMLInstance,
+		getModesFunc: GetModes,
+		readAuditCommandsFunc: func(since time.Time, until time.Time) ([]auditCommand, error) {
+			return readAuditCommands(DefaultAuditLogPath, since, until)
+		},
+		readKmsgFunc: kmsg.ReadAll,
+		dbRW:         gpudInstance.DBRW,
+		dbRO:         gpudInstance.DBRO,
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), 30*time.Second, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu modes")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if err := c.nvmlInstance.InitError(); err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("NVML initialization error: %v", err)
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	devs := c.nvmlInstance.Devices()
+	uuids := make([]string, 0, len(devs))
+	for uuid := range devs {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	for _, uuid := range uuids {
+		modes, err := c.getModesFunc(uuid, devs[uuid])
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error getting gpu modes"
+
+			for _, target := range []error{nvmlerrors.ErrGPURequiresReset, nvmlerrors.ErrGPULost} {
+				if errors.Is(err, target) {
+					cr.reason = target.Error()
+					cr.suggestedActions = &apiv1.SuggestedActions{
+						Description: target.Error(),
+						RepairActions: []apiv1.RepairActionType{
+							apiv1.RepairActionTypeRebootSystem,
+						},
+					}
+				}
+			}
+
+			log.Logger.Warnw(cr.reason, "uuid", uuid, "error", cr.err)
+			return cr
+		}
+		cr.Modes = append(cr.Modes, modes)
+	}
+
+	// serializes the checks from the ticker and the manual triggers, to report each change once
+	c.prevMu.Lock()
+	defer c.prevMu.Unlock()
+
+	prev := c.loadSnapshot()
+	cur := &snapshot{Time: cr.ts, GPUs: make(map[string]Modes)}
+	if prev != nil {
+		// keep the GPUs not found in this check (e.g., fallen off the bus)
+		for uuid, modes := range prev.GPUs {
+			cur.GPUs[uuid] = modes
+		}
+	}
+	for _, modes := range cr.Modes {
+		if prev != nil {
+			if prevModes, ok := prev.GPUs[modes.UUID]; ok {
+				cr.Changes = append(cr.Changes, diffModes(prevModes, modes)...)
+			}
+		}
+		cur.GPUs[modes.UUID] = modes
+	}
+
+	if len(cr.Changes) > 0 {
+		c.recordChanges(cr.Changes, prev.Time.Add(-attributionSlack), cr.ts)
+	}
+	c.saveSnapshot(cur)
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	if len(cr.Changes) == 0 {
+		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no mode change found", len(cr.Modes))
+	} else {
+		changes := make([]string, 0, len(cr.Changes))
+		for _, ch := range cr.Changes {
+			changes = append(changes, ch.String())
+		}
+		cr.reason = fmt.Sprintf("%d GPU mode change(s) found: %s", len(cr.Changes), strings.Join(changes, ", "))
+	}
+
+	return cr
+}
+
+// recordChanges inserts the mode change events, with the likely causes
+// found in the audit log and the kernel messages between since and until.
+func (c *component) recordChanges(changes []Change, since time.Time, until time.Time) {
+	var cmds []auditCommand
+	if c.readAuditCommandsFunc != nil {
+		var err error
+		cmds, err = c.readAuditCommandsFunc(since, until)
+		if err != nil {
+			log.Logger.Warnw("error reading audit log", "error", err)
+		}
+	}
+	var msgs []kmsg.Message
+	if c.readKmsgFunc != nil {
+		var err error
+		msgs, err = c.readKmsgFunc(c.ctx)
+		if err != nil {
+			log.Logger.Debugw("error reading kmsg", "error", err)
+		}
+	}
+
+	for _, ch := range changes {
+		changedBy := findAuditCause(cmds, ch.Mode)
+		kmsgLine := findKmsgCause(msgs, ch.Mode, since, until)
+		log.Logger.Warnw("gpu mode changed", "uuid", ch.UUID, "mode", ch.Mode, "pending", ch.Pending, "from", ch.From, "to", ch.To, "changedBy", changedBy)
+
+		if c.eventBucket == nil {
+			continue
+		}
+
+		msg := ch.String()
+		if changedBy != "" {
+			msg += ", likely by " + changedBy
+		}
+		if kmsgLine != "" {
+			msg += fmt.Sprintf(" (kernel message: %q)", kmsgLine)
+		}
+		ev := eventstore.Event{
+			Component: Name,
+			Time:      until,
+			Name:      modeEventNames[ch.Mode],
+			Type:      string(apiv1.EventTypeWarning),
+			Message:   msg,
+			ExtraInfo: map[string]string{
+				EventKeyDeviceUUID:  ch.UUID,
+				EventKeyDeviceBusID: ch.BusID,
+				EventKeyMode:        string(ch.Mode),
+				EventKeyPending:     fmt.Sprintf("%t", ch.Pending),
+				EventKeyFrom:        string(ch.From),
+				EventKeyTo:          string(ch.To),
+			},
+		}
+		if changedBy != "" {
+			ev.ExtraInfo[EventKeyChangedBy] = changedBy
+		}
+		if kmsgLine != "" {
+			ev.ExtraInfo[EventKeyKmsg] = kmsgLine
+		}
+
+		insertCtx, insertCancel := context.WithTimeout(c.ctx, 15*time.Second)
+		err := c.eventBucket.Insert(insertCtx, ev)
+		insertCancel()
+		if err != nil {
+			log.Logger.Warnw("error inserting gpu mode change event", "uuid", ch.UUID, "mode", ch.Mode, "error", err)
+		}
+	}
+}
+
+// loadSnapshot returns the last observed modes, read from the metadata on the first call.
+func (c *component) loadSnapshot() *snapshot {
+	if c.prevLoaded || c.dbRO == nil {
+		return c.prev
+	}
+	c.prevLoaded = true
+
+	ctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
+	raw, err := pkgmetadata.ReadMetadata(ctx, c.dbRO, pkgmetadata.MetadataKeyLastGPUModes)
+	cancel()
+	if err != nil {
+		log.Logger.Warnw("error reading last gpu modes", "error", err)
+		return c.prev
+	}
+	if raw == "" {
+		return c.prev
+	}
+
+	var s snapshot
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		log.Logger.Warnw("error decoding last gpu modes", "error", err)
+		return c.prev
+	}
+	c.prev = &s
+	return c.prev
+}
+
+// saveSnapshot tracks the observed modes for the next check, and persists them if the database is set.
+func (c *component) saveSnapshot(s *snapshot) {
+	c.prev = s
+	if c.dbRW == nil {
+		return
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		log.Logger.Warnw("error encoding gpu modes", "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
+	err = pkgmetadata.SetMetadata(ctx, c.dbRW, pkgmetadata.MetadataKeyLastGPUModes, string(b))
+	cancel()
+	if err != nil {
+		log.Logger.Warnw("error saving gpu modes", "error", err)
+	}
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Modes   []Modes  `json:"modes,omitempty"`
+	Changes []Change `json:"changes,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Modes) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "GPU Bus ID", "ECC (Current/Pending)", "MIG (Current/Pending)", "Persistence"})
+	for _, m := range cr.Modes {
+		table.Append([]string{
+			m.UUID,
+			m.BusID,
+			fmt.Sprintf("%s/%s", m.ECCCurrent, m.ECCPending),
+			fmt.Sprintf("%s/%s", m.MIGCurrent, m.MIGPending),
+			string(m.Persistence),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		Error:            cr.getError(),
+		Health:           cr.health,
+		SuggestedActions: cr.suggestedActions,
+	}
+
+	if len(cr.Modes) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
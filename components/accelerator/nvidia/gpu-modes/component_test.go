@@ -0,0 +1,282 @@
+package gpumodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type mockNVMLInstance struct {
+	devs        map[string]device.Device
+	productName string
+	initErr     error
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool                  { return true }
+func (m *mockNVMLInstance) Library() lib.Library              { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) ProductName() string               { return m.productName }
+func (m *mockNVMLInstance) Architecture() string              { return "" }
+func (m *mockNVMLInstance) Brand() string                     { return "" }
+func (m *mockNVMLInstance) DriverVersion() string             { return "" }
+func (m *mockNVMLInstance) DriverMajor() int                  { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string               { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool      { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool        { return false }
+func (m *mockNVMLInstance) Shutdown() error                   { return nil }
+func (m *mockNVMLInstance) InitError() error                  { return m.initErr }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+
+// fakeModes returns the modes set per GPU UUID.
+type fakeModes struct {
+	mu    sync.Mutex
+	modes map[string]Modes
+	err   error
+}
+
+func (f *fakeModes) get(uuid string, _ device.Device) (Modes, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return Modes{}, f.err
+	}
+	return f.modes[uuid], nil
+}
+
+func (f *fakeModes) set(uuid string, fn func(*Modes)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := f.modes[uuid]
+	fn(&m)
+	f.modes[uuid] = m
+}
+
+func defaultModes(uuid string) Modes {
+	return Modes{
+		UUID:        uuid,
+		BusID:       "0000:0f:00.0",
+		ECCCurrent:  StateEnabled,
+		ECCPending:  StateEnabled,
+		MIGCurrent:  StateDisabled,
+		MIGPending:  StateDisabled,
+		Persistence: StateEnabled,
+	}
+}
+
+type testEnv struct {
+	gpud  *components.GPUdInstance
+	fake  *fakeModes
+	store eventstore.Store
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	require.NoError(t, pkgmetadata.CreateTableMetadata(context.Background(), dbRW))
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	return &testEnv{
+		gpud: &components.GPUdInstance{
+			RootCtx: context.Background(),
+			NVMLInstance: &mockNVMLInstance{
+				devs:        map[string]device.Device{"gpu-0": nil, "gpu-1": nil},
+				productName: "NVIDIA H100",
+			},
+			DBRW:       dbRW,
+			DBRO:       dbRO,
+			EventStore: store,
+		},
+		fake: &fakeModes{modes: map[string]Modes{
+			"gpu-0": defaultModes("gpu-0"),
+			"gpu-1": defaultModes("gpu-1"),
+		}},
+		store: store,
+	}
+}
+
+func (e *testEnv) newComponent(t *testing.T, cmds []auditCommand, msgs []kmsg.Message) *component {
+	comp, err := New(e.gpud)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = comp.Close() })
+
+	c := comp.(*component)
+	c.getModesFunc = e.fake.get
+	c.readAuditCommandsFunc = func(time.Time, time.Time) ([]auditCommand, error) { return cmds, nil }
+	c.readKmsgFunc = func(context.Context) ([]kmsg.Message, error) { return msgs, nil }
+	return c
+}
+
+func TestComponentBasics(t *testing.T) {
+	env := newTestEnv(t)
+	c := env.newComponent(t, nil, nil)
+
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.True(t, c.IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+
+	c.nvmlInstance = nil
+	assert.False(t, c.IsSupported())
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+}
+
+func TestCheckModeChanges(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now().UTC()
+	cmds := []auditCommand{
+		{Time: now, Args: []string{"nvidia-smi", "-i", "1", "-e", "0"}, AUID: "1000", UID: "0"},
+	}
+	msgs := []kmsg.Message{
+		{Timestamp: metav1.NewTime(now), Message: "NVRM: nvidia-persistenced exited"},
+	}
+	c := env.newComponent(t, cmds, msgs)
+
+	// the first check is the baseline
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Len(t, cr.Modes, 2)
+	assert.Empty(t, cr.Changes)
+	assert.Equal(t, "all 2 GPU(s) were checked, no mode change found", cr.Summary())
+	assert.Contains(t, cr.String(), "enabled/enabled")
+
+	env.fake.set("gpu-1", func(m *Modes) { m.ECCPending = StateDisabled })
+	env.fake.set("gpu-0", func(m *Modes) { m.Persistence = StateDisabled })
+
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	require.Len(t, cr.Changes, 2)
+	assert.Contains(t, cr.Summary(), "2 GPU mode change(s) found")
+
+	apiEvs, err := c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, apiEvs, 2)
+
+	evs, err := c.eventBucket.Get(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	contractstest.AssertEvents(t, Name, evs...)
+	byName := map[string]eventstore.Event{}
+	for _, ev := range evs {
+		byName[ev.Name] = ev
+	}
+
+	ecc := byName[EventNameECCModeChanged]
+	assert.Equal(t, string(apiv1.EventTypeWarning), ecc.Type)
+	assert.Equal(t, "gpu-1", ecc.ExtraInfo[EventKeyDeviceUUID])
+	assert.Equal(t, "true", ecc.ExtraInfo[EventKeyPending])
+	assert.Equal(t, "enabled", ecc.ExtraInfo[EventKeyFrom])
+	assert.Equal(t, "disabled", ecc.ExtraInfo[EventKeyTo])
+	assert.Contains(t, ecc.ExtraInfo[EventKeyChangedBy], "nvidia-smi -i 1 -e 0 (auid=1000, uid=0)")
+	assert.Contains(t, ecc.Message, "likely by nvidia-smi")
+	assert.Empty(t, ecc.ExtraInfo[EventKeyKmsg])
+
+	pm := byName[EventNamePersistenceModeChanged]
+	assert.Equal(t, "gpu-0", pm.ExtraInfo[EventKeyDeviceUUID])
+	assert.Equal(t, "false", pm.ExtraInfo[EventKeyPending])
+	assert.Empty(t, pm.ExtraInfo[EventKeyChangedBy])
+	assert.Equal(t, "NVRM: nvidia-persistenced exited", pm.ExtraInfo[EventKeyKmsg])
+	assert.Contains(t, pm.Message, `kernel message: "NVRM: nvidia-persistenced exited"`)
+
+	// no more changes
+	cr = c.Check().(*checkResult)
+	assert.Empty(t, cr.Changes)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Len(t, decoded.Modes, 2)
+}
+
+func TestCheckModeChangesAcrossRestarts(t *testing.T) {
+	env := newTestEnv(t)
+	c := env.newComponent(t, nil, nil)
+	c.Check()
+
+	// changed while GPUd is down
+	env.fake.set("gpu-0", func(m *Modes) { m.MIGCurrent = StateEnabled })
+
+	c2 := env.newComponent(t, nil, nil)
+	cr := c2.Check().(*checkResult)
+	require.Len(t, cr.Changes, 1)
+	assert.Equal(t, ModeMIG, cr.Changes[0].Mode)
+	assert.False(t, cr.Changes[0].Pending)
+
+	evs, err := c2.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameMIGModeChanged, evs[0].Name)
+}
+
+func TestCheckNewGPUIsBaseline(t *testing.T) {
+	env := newTestEnv(t)
+	c := env.newComponent(t, nil, nil)
+	c.Check()
+
+	env.gpud.NVMLInstance.(*mockNVMLInstance).devs["gpu-2"] = nil
+	m := defaultModes("gpu-2")
+	m.Persistence = StateDisabled
+	env.fake.set("gpu-2", func(mm *Modes) { *mm = m })
+
+	cr := c.Check().(*checkResult)
+	assert.Empty(t, cr.Changes)
+	assert.Len(t, cr.Modes, 3)
+}
+
+func TestCheckErrors(t *testing.T) {
+	env := newTestEnv(t)
+	c := env.newComponent(t, nil, nil)
+
+	env.fake.err = nvmlerrors.ErrGPULost
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, nvmlerrors.ErrGPULost.Error(), cr.Summary())
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}, cr.suggestedActions.RepairActions)
+
+	env.fake.err = errors.New("nvml error")
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "error getting gpu modes", cr.Summary())
+	assert.Equal(t, "nvml error", cr.getError())
+
+	env.fake.err = nil
+	c.nvmlInstance = &mockNVMLInstance{productName: "NVIDIA H100", initErr: errors.New("unknown error")}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "NVML initialization error")
+
+	c.nvmlInstance = &mockNVMLInstance{}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "missing product name")
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,144 @@
+package gpumodes
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	persistencemode "github.com/leptonai/gpud/components/accelerator/nvidia/persistence-mode"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// Mode is the GPU mode tracked for the changes.
+type Mode string
+
+const (
+	ModeECC         Mode = "ecc"
+	ModeMIG         Mode = "mig"
+	ModePersistence Mode = "persistence"
+)
+
+// State is the state of a GPU mode.
+type State string
+
+const (
+	StateEnabled     State = "enabled"
+	StateDisabled    State = "disabled"
+	StateUnsupported State = "unsupported"
+)
+
+func stateOf(supported bool, enabled bool) State {
+	if !supported {
+		return StateUnsupported
+	}
+	if enabled {
+		return StateEnabled
+	}
+	return StateDisabled
+}
+
+// Modes is the ECC, MIG, and persistence modes of a GPU.
+// The "pending" modes are the target modes after the next reboot (ECC)
+// or the next GPU reset (MIG), thus change ahead of the current modes.
+type Modes struct {
+	UUID  string `json:"uuid"`
+	BusID string `json:"bus_id"`
+
+	ECCCurrent  State `json:"ecc_current"`
+	ECCPending  State `json:"ecc_pending"`
+	MIGCurrent  State `json:"mig_current"`
+	MIGPending  State `json:"mig_pending"`
+	Persistence State `json:"persistence"`
+}
+
+// GetModes returns the ECC, MIG, and persistence modes of the device.
+func GetModes(uuid string, dev device.Device) (Modes, error) {
+	eccMode, err := ecc.GetECCModeEnabled(uuid, dev)
+	if err != nil {
+		return Modes{}, err
+	}
+	pm, err := persistencemode.GetPersistenceMode(uuid, dev)
+	if err != nil {
+		return Modes{}, err
+	}
+	migCurrent, migPending, err := getMIGMode(dev)
+	if err != nil {
+		return Modes{}, err
+	}
+
+	return Modes{
+		UUID:        uuid,
+		BusID:       dev.PCIBusID(),
+		ECCCurrent:  stateOf(eccMode.Supported, eccMode.EnabledCurrent),
+		ECCPending:  stateOf(eccMode.Supported, eccMode.EnabledPending),
+		MIGCurrent:  migCurrent,
+		MIGPending:  migPending,
+		Persistence: stateOf(pm.Supported, pm.Enabled),
+	}, nil
+}
+
+// getMIGMode returns the current and pending MIG modes of the device.
+func getMIGMode(dev device.Device) (State, State, error) {
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlMultiInstanceGPU.html
+	current, pending, ret := dev.GetMigMode()
+	if nvmlerrors.IsNotSupportError(ret) {
+		return StateUnsupported, StateUnsupported, nil
+	}
+	if nvmlerrors.IsGPULostError(ret) {
+		return "", "", nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return "", "", nvmlerrors.ErrGPURequiresReset
+	}
+	// not a "not supported" error, not a success return, thus return an error here
+	if ret != nvml.SUCCESS {
+		return "", "", fmt.Errorf("failed to get current/pending mig mode: %s", nvml.ErrorString(ret))
+	}
+	return stateOf(true, current == nvml.DEVICE_MIG_ENABLE), stateOf(true, pending == nvml.DEVICE_MIG_ENABLE), nil
+}
+
+// Change is a GPU mode change between two checks.
+type Change struct {
+	UUID  string `json:"uuid"`
+	BusID string `json:"bus_id"`
+	Mode  Mode   `json:"mode"`
+	// Pending is true if the pending mode changed (e.g., "nvidia-smi -e 0"),
+	// which takes effect after the next reboot or GPU reset.
+	Pending bool  `json:"pending,omitempty"`
+	From    State `json:"from"`
+	To      State `json:"to"`
+}
+
+func (ch Change) String() string {
+	mode := string(ch.Mode)
+	if ch.Pending {
+		mode += " (pending)"
+	}
+	return fmt.Sprintf("GPU %s (%s) %s mode changed from %s to %s", ch.UUID, ch.BusID, mode, ch.From, ch.To)
+}
+
+// diffModes returns the mode changes from the previous to the current modes.
+func diffModes(prev Modes, cur Modes) []Change {
+	var changes []Change
+	add := func(mode Mode, pending bool, from State, to State) {
+		if from == to || from == "" || to == "" {
+			return
+		}
+		changes = append(changes, Change{
+			UUID:    cur.UUID,
+			BusID:   cur.BusID,
+			Mode:    mode,
+			Pending: pending,
+			From:    from,
+			To:      to,
+		})
+	}
+	add(ModeECC, false, prev.ECCCurrent, cur.ECCCurrent)
+	add(ModeECC, true, prev.ECCPending, cur.ECCPending)
+	add(ModeMIG, false, prev.MIGCurrent, cur.MIGCurrent)
+	add(ModeMIG, true, prev.MIGPending, cur.MIGPending)
+	add(ModePersistence, false, prev.Persistence, cur.Persistence)
+	return changes
+}
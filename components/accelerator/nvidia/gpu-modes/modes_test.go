@@ -0,0 +1,88 @@
+package gpumodes
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func newModesDevice(eccCurrent, eccPending nvml.EnableState, migCurrent, migPending int, migRet nvml.Return, pm nvml.EnableState) *testutil.MockDevice {
+	return testutil.NewMockDevice(&mock.Device{
+		GetEccModeFunc: func() (nvml.EnableState, nvml.EnableState, nvml.Return) {
+			return eccCurrent, eccPending, nvml.SUCCESS
+		},
+		GetMigModeFunc: func() (int, int, nvml.Return) {
+			return migCurrent, migPending, migRet
+		},
+		GetPersistenceModeFunc: func() (nvml.EnableState, nvml.Return) {
+			return pm, nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "0000:0f:00.0")
+}
+
+func TestGetModes(t *testing.T) {
+	dev := newModesDevice(nvml.FEATURE_ENABLED, nvml.FEATURE_DISABLED, nvml.DEVICE_MIG_DISABLE, nvml.DEVICE_MIG_ENABLE, nvml.SUCCESS, nvml.FEATURE_ENABLED)
+	modes, err := GetModes("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Equal(t, Modes{
+		UUID:        "gpu-0",
+		BusID:       "0000:0f:00.0",
+		ECCCurrent:  StateEnabled,
+		ECCPending:  StateDisabled,
+		MIGCurrent:  StateDisabled,
+		MIGPending:  StateEnabled,
+		Persistence: StateEnabled,
+	}, modes)
+
+	dev = newModesDevice(nvml.FEATURE_ENABLED, nvml.FEATURE_ENABLED, 0, 0, nvml.ERROR_NOT_SUPPORTED, nvml.FEATURE_DISABLED)
+	modes, err = GetModes("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Equal(t, StateUnsupported, modes.MIGCurrent)
+	assert.Equal(t, StateUnsupported, modes.MIGPending)
+	assert.Equal(t, StateDisabled, modes.Persistence)
+
+	dev = newModesDevice(nvml.FEATURE_ENABLED, nvml.FEATURE_ENABLED, 0, 0, nvml.ERROR_GPU_IS_LOST, nvml.FEATURE_ENABLED)
+	_, err = GetModes("gpu-0", dev)
+	assert.ErrorIs(t, err, nvmlerrors.ErrGPULost)
+
+	dev = newModesDevice(nvml.FEATURE_ENABLED, nvml.FEATURE_ENABLED, 0, 0, nvml.ERROR_UNKNOWN, nvml.FEATURE_ENABLED)
+	_, err = GetModes("gpu-0", dev)
+	assert.ErrorContains(t, err, "failed to get current/pending mig mode")
+}
+
+func TestDiffModes(t *testing.T) {
+	prev := Modes{
+		UUID:        "gpu-0",
+		BusID:       "0000:0f:00.0",
+		ECCCurrent:  StateEnabled,
+		ECCPending:  StateEnabled,
+		MIGCurrent:  StateDisabled,
+		MIGPending:  StateDisabled,
+		Persistence: StateEnabled,
+	}
+	assert.Empty(t, diffModes(prev, prev))
+
+	cur := prev
+	cur.ECCPending = StateDisabled
+	cur.MIGCurrent = StateEnabled
+	cur.MIGPending = StateEnabled
+	cur.Persistence = StateDisabled
+
+	changes := diffModes(prev, cur)
+	assert.Equal(t, []Change{
+		{UUID: "gpu-0", BusID: "0000:0f:00.0", Mode: ModeECC, Pending: true, From: StateEnabled, To: StateDisabled},
+		{UUID: "gpu-0", BusID: "0000:0f:00.0", Mode: ModeMIG, From: StateDisabled, To: StateEnabled},
+		{UUID: "gpu-0", BusID: "0000:0f:00.0", Mode: ModeMIG, Pending: true, From: StateDisabled, To: StateEnabled},
+		{UUID: "gpu-0", BusID: "0000:0f:00.0", Mode: ModePersistence, From: StateEnabled, To: StateDisabled},
+	}, changes)
+	assert.Equal(t, "GPU gpu-0 (0000:0f:00.0) ecc (pending) mode changed from enabled to disabled", changes[0].String())
+
+	// unknown previous state (e.g., older snapshot) is not a change
+	assert.Empty(t, diffModes(Modes{UUID: "gpu-0"}, cur))
+}
@@ -0,0 +1,419 @@
+// Package gpudirectrdma validates the GPUDirect RDMA readiness of the host:
+// the peer memory kernel module registered with ib_core, the OFED stack compatible
+// with the module, the PCIe ACS disabled on bare metal, and the optional loopback probe.
+// These misconfigurations do not fail the multi-node training, but silently
+// degrade the bandwidth by staging the GPU memory through the host memory.
+package gpudirectrdma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+const (
+	// Name is the ID of the NVIDIA GPUDirect RDMA component.
+	Name = "accelerator-nvidia-gpudirect-rdma"
+
+	// DefaultProbeInterval is the minimum interval between the loopback probes,
+	// since each probe saturates the RDMA device for the probe duration.
+	DefaultProbeInterval = time.Hour
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	nvmlInstance nvidianvml.Instance
+
+	getTimeNowFunc      func() time.Time
+	getProbeConfigFunc  func() ProbeConfig
+	getEuidFunc         func() int
+	getVirtEnvFunc      func() pkghost.VirtualizationEnvironment
+	listRDMADevicesFunc func() ([]string, error)
+	readProcModulesFunc func() (map[string]module, error)
+	getOFEDFunc         func(ctx context.Context) (*OFED, error)
+	getPCIDevicesFunc   func(ctx context.Context) (pci.Devices, error)
+	runProbeFunc        func(ctx context.Context, device string, gpuIndex int, duration time.Duration) (float64, error)
+	probeInterval       time.Duration
+
+	// probeMu serializes the probes, and guards the last probe result
+	// reused until the probe interval elapses
+	probeMu   sync.Mutex
+	lastProbe *ProbeResult
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the GPUDirect RDMA component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		nvmlInstance: gpudInstance.NVMLInstance,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getProbeConfigFunc: GetDefaultProbeConfig,
+		getEuidFunc:        os.Geteuid,
+		getVirtEnvFunc:     pkghost.VirtualizationEnv,
+		listRDMADevicesFunc: func() ([]string, error) {
+			return listRDMADevices(DefaultRDMAClassDir)
+		},
+		readProcModulesFunc: readProcModules,
+		getOFEDFunc:         getOFED,
+		getPCIDevicesFunc:   pci.List,
+		runProbeFunc:        runProbe,
+		probeInterval:       DefaultProbeInterval,
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"infiniband",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	// the integrated GPUs of Jetson/Tegra have no PCIe peer memory
+	if nvidiategra.IsTegra() {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), 10*time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpudirect rdma")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if err := c.nvmlInstance.InitError(); err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("NVML initialization error: %v", err)
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	cr.RDMADevices, cr.err = c.listRDMADevicesFunc()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error listing RDMA devices"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+	if len(cr.RDMADevices) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no RDMA device found (GPUDirect RDMA not applicable)"
+		return cr
+	}
+
+	mods, err := c.readProcModulesFunc()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading kernel modules"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+	cr.PeerMem = checkPeerMem(mods)
+
+	var issues []string
+	switch {
+	case cr.PeerMem.Module == "":
+		issues = append(issues, "peer memory module (nvidia_peermem or nv_peer_mem) not loaded")
+	case !cr.PeerMem.IBCoreLoaded:
+		issues = append(issues, fmt.Sprintf("%s loaded but ib_core not loaded", cr.PeerMem.Module))
+	case !cr.PeerMem.UsedByIBCore:
+		issues = append(issues, fmt.Sprintf("%s loaded but not registered with ib_core (incompatible OFED stack)", cr.PeerMem.Module))
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+	cr.OFED, err = c.getOFEDFunc(cctx)
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to get ofed version", "error", err)
+	}
+	if cr.OFED != nil && cr.PeerMem.Module == "nvidia_peermem" && !cr.OFED.compatible() {
+		issues = append(issues, fmt.Sprintf("%s is older than %d.%d required by nvidia_peermem", cr.OFED.Version, minOFEDMajor, minOFEDMinor))
+	}
+
+	if issue := c.checkACS(cr); issue != "" {
+		issues = append(issues, issue)
+	}
+
+	if cfg := c.getProbeConfigFunc(); cfg.Enabled {
+		cr.Probe = c.probe(cfg, cr.RDMADevices[0], cr.ts)
+		switch {
+		case cr.Probe.Error != "":
+			issues = append(issues, fmt.Sprintf("loopback probe on %s failed (%s)", cr.Probe.Device, cr.Probe.Error))
+		case cfg.MinBandwidthGbps > 0 && cr.Probe.BandwidthGbps < cfg.MinBandwidthGbps:
+			issues = append(issues, fmt.Sprintf("loopback probe on %s at %.2f Gb/s below %.2f Gb/s", cr.Probe.Device, cr.Probe.BandwidthGbps, cfg.MinBandwidthGbps))
+		}
+	}
+
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = strings.Join(issues, "; ")
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("GPUDirect RDMA ready with %s on %d RDMA device(s)", cr.PeerMem.Module, len(cr.RDMADevices))
+	return cr
+}
+
+// checkACS lists the PCI devices with ACS enabled on bare metal, and returns the issue if any.
+// Virtual machines require ACS to function, hence not checked.
+func (c *component) checkACS(cr *checkResult) string {
+	virtEnv := c.getVirtEnvFunc()
+	if virtEnv.IsKVM || virtEnv.Type == "" {
+		return ""
+	}
+	if c.getEuidFunc() != 0 {
+		// "lspci -vvv" requires root to read the ACS capabilities
+		return ""
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	devs, err := c.getPCIDevicesFunc(cctx)
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to list pci devices", "error", err)
+		return ""
+	}
+	cr.ACSChecked = true
+
+	cr.ACSEnabledDevices = findACSEnabledDevices(devs)
+	if len(cr.ACSEnabledDevices) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ACS enabled on %d PCI device(s) (%s), redirecting the peer-to-peer traffic through the root complex", len(cr.ACSEnabledDevices), strings.Join(cr.ACSEnabledDevices, ", "))
+}
+
+// probe returns the last probe result if within the probe interval,
+// otherwise runs the loopback probe.
+func (c *component) probe(cfg ProbeConfig, defaultDevice string, now time.Time) *ProbeResult {
+	c.probeMu.Lock()
+	defer c.probeMu.Unlock()
+
+	dev := cfg.Device
+	if dev == "" {
+		dev = defaultDevice
+	}
+	if c.lastProbe != nil && c.lastProbe.Device == dev && c.lastProbe.GPUIndex == cfg.GPUIndex && now.Sub(c.lastProbe.Time.Time) < c.probeInterval {
+		return c.lastProbe
+	}
+
+	log.Logger.Infow("running gpudirect rdma loopback probe", "device", dev, "gpuIndex", cfg.GPUIndex, "duration", cfg.duration())
+	res := &ProbeResult{
+		Time:     metav1.NewTime(now),
+		Device:   dev,
+		GPUIndex: cfg.GPUIndex,
+	}
+	var err error
+	res.BandwidthGbps, err = c.runProbeFunc(c.ctx, dev, cfg.GPUIndex, cfg.duration())
+	if err != nil {
+		res.Error = err.Error()
+		log.Logger.Warnw("gpudirect rdma loopback probe failed", "device", dev, "gpuIndex", cfg.GPUIndex, "error", err)
+	} else {
+		metricProbeBandwidth.With(prometheus.Labels{"device": dev, "gpu_index": strconv.Itoa(cfg.GPUIndex)}).Set(res.BandwidthGbps * 1e9 / 8)
+	}
+
+	c.lastProbe = res
+	return res
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	RDMADevices []string      `json:"rdma_devices,omitempty"`
+	PeerMem     PeerMemStatus `json:"peer_mem"`
+	// OFED is the installed OFED stack, or nil if not installed (e.g., the inbox rdma-core).
+	OFED *OFED `json:"ofed,omitempty"`
+	// ACSChecked is false if the ACS is not checked (e.g., in VM, not root).
+	ACSChecked        bool         `json:"acs_checked"`
+	ACSEnabledDevices []string     `json:"acs_enabled_devices,omitempty"`
+	Probe             *ProbeResult `json:"probe,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.RDMADevices) == 0 {
+		return "no data"
+	}
+
+	ofed := "not installed"
+	if cr.OFED != nil {
+		ofed = cr.OFED.Version
+	}
+	acs := "not checked"
+	if cr.ACSChecked {
+		acs = strconv.Itoa(len(cr.ACSEnabledDevices))
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.Append([]string{"RDMA Devices", strings.Join(cr.RDMADevices, ", ")})
+	table.Append([]string{"Peer Memory Module", cr.PeerMem.Module})
+	table.Append([]string{"Used by ib_core", strconv.FormatBool(cr.PeerMem.UsedByIBCore)})
+	table.Append([]string{"OFED", ofed})
+	table.Append([]string{"ACS Enabled Devices", acs})
+	if cr.Probe != nil {
+		probe := fmt.Sprintf("%.2f Gb/s", cr.Probe.BandwidthGbps)
+		if cr.Probe.Error != "" {
+			probe = cr.Probe.Error
+		}
+		table.Append([]string{"Loopback Probe", probe})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.RDMADevices) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,289 @@
+package gpudirectrdma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+type mockNVMLInstance struct {
+	productName string
+	initErr     error
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool                  { return true }
+func (m *mockNVMLInstance) Library() lib.Library              { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return nil }
+func (m *mockNVMLInstance) ProductName() string               { return m.productName }
+func (m *mockNVMLInstance) Architecture() string              { return "" }
+func (m *mockNVMLInstance) Brand() string                     { return "" }
+func (m *mockNVMLInstance) DriverVersion() string             { return "" }
+func (m *mockNVMLInstance) DriverMajor() int                  { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string               { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool      { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool        { return false }
+func (m *mockNVMLInstance) Shutdown() error                   { return nil }
+func (m *mockNVMLInstance) InitError() error                  { return m.initErr }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+
+var h100 = &mockNVMLInstance{productName: "NVIDIA H100 80GB HBM3"}
+
+func createMockGPUDirectRDMAComponent(ctx context.Context, nvmlInstance nvidianvml.Instance, modules string) *component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:          cctx,
+		cancel:       cancel,
+		nvmlInstance: nvmlInstance,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getProbeConfigFunc: func() ProbeConfig {
+			return ProbeConfig{}
+		},
+		getEuidFunc: func() int {
+			return 0
+		},
+		getVirtEnvFunc: func() pkghost.VirtualizationEnvironment {
+			return pkghost.VirtualizationEnvironment{Type: "none"}
+		},
+		listRDMADevicesFunc: func() ([]string, error) {
+			return []string{"mlx5_0", "mlx5_1"}, nil
+		},
+		readProcModulesFunc: func() (map[string]module, error) {
+			return parseProcModules(strings.NewReader(modules))
+		},
+		getOFEDFunc: func(context.Context) (*OFED, error) {
+			return parseOFEDVersion("MLNX_OFED_LINUX-23.10-0.5.5.0:")
+		},
+		getPCIDevicesFunc: func(context.Context) (pci.Devices, error) {
+			return nil, nil
+		},
+		runProbeFunc: func(context.Context, string, int, time.Duration) (float64, error) {
+			return 0, errors.New("unexpected probe")
+		},
+		probeInterval: DefaultProbeInterval,
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockNVMLInstance{productName: "NVIDIA H100 80GB HBM3"},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+}
+
+func TestComponentBasics(t *testing.T) {
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+
+	c.nvmlInstance = nil
+	assert.False(t, c.IsSupported())
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+}
+
+func TestCheckReady(t *testing.T) {
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "GPUDirect RDMA ready with nvidia_peermem on 2 RDMA device(s)", cr.Summary())
+	assert.True(t, cr.ACSChecked)
+	assert.Contains(t, cr.String(), "MLNX_OFED_LINUX-23.10-0.5.5.0")
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Equal(t, cr.PeerMem, decoded.PeerMem)
+	assert.Equal(t, []string{"mlx5_0", "mlx5_1"}, decoded.RDMADevices)
+}
+
+func TestCheckNoRDMADevice(t *testing.T) {
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, "")
+	defer c.Close()
+	c.listRDMADevicesFunc = func() ([]string, error) { return nil, nil }
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "no RDMA device found")
+}
+
+func TestCheckMisconfigured(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules string
+		ofed    string
+		acs     bool
+		reason  string
+	}{
+		{
+			name:    "peermem not loaded",
+			modules: "ib_core 479232 2 ib_uverbs,mlx5_ib, Live 0x0\n",
+			reason:  "peer memory module (nvidia_peermem or nv_peer_mem) not loaded",
+		},
+		{
+			name:    "peermem not registered",
+			modules: "nvidia_peermem 16384 0 - Live 0x0\nib_core 479232 2 ib_uverbs,mlx5_ib, Live 0x0\n",
+			reason:  "nvidia_peermem loaded but not registered with ib_core (incompatible OFED stack)",
+		},
+		{
+			name:    "ofed too old",
+			modules: testProcModules,
+			ofed:    "MLNX_OFED_LINUX-4.9-7.1.0.0:",
+			reason:  "MLNX_OFED_LINUX-4.9-7.1.0.0 is older than 5.1 required by nvidia_peermem",
+		},
+		{
+			name:    "acs enabled",
+			modules: testProcModules,
+			acs:     true,
+			reason:  "ACS enabled on 1 PCI device(s) (00:01.0)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := createMockGPUDirectRDMAComponent(context.Background(), h100, tt.modules)
+			defer c.Close()
+			if tt.ofed != "" {
+				c.getOFEDFunc = func(context.Context) (*OFED, error) { return parseOFEDVersion(tt.ofed) }
+			}
+			if tt.acs {
+				c.getPCIDevicesFunc = func(context.Context) (pci.Devices, error) {
+					return pci.Devices{{ID: "00:01.0", AccessControlService: &pci.AccessControlService{ACSCtl: pci.ACS{SrcValid: true}}}}, nil
+				}
+			}
+
+			cr := c.Check()
+			assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+			assert.Contains(t, cr.Summary(), tt.reason)
+		})
+	}
+}
+
+func TestCheckACSSkipped(t *testing.T) {
+	acsEnabled := func(context.Context) (pci.Devices, error) {
+		return pci.Devices{{ID: "00:01.0", AccessControlService: &pci.AccessControlService{ACSCtl: pci.ACS{SrcValid: true}}}}, nil
+	}
+
+	// virtual machines require ACS
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+	c.getPCIDevicesFunc = acsEnabled
+	c.getVirtEnvFunc = func() pkghost.VirtualizationEnvironment {
+		return pkghost.VirtualizationEnvironment{Type: "kvm", IsKVM: true}
+	}
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.False(t, cr.ACSChecked)
+
+	// not root
+	c = createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+	c.getPCIDevicesFunc = acsEnabled
+	c.getEuidFunc = func() int { return 1000 }
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.False(t, cr.ACSChecked)
+}
+
+func TestCheckProbe(t *testing.T) {
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+
+	now := time.Now().UTC()
+	c.getTimeNowFunc = func() time.Time { return now }
+	c.getProbeConfigFunc = func() ProbeConfig {
+		return ProbeConfig{Enabled: true, GPUIndex: 1, MinBandwidthGbps: 300}
+	}
+
+	probes := 0
+	bw := 390.5
+	c.runProbeFunc = func(_ context.Context, dev string, gpuIndex int, duration time.Duration) (float64, error) {
+		probes++
+		assert.Equal(t, "mlx5_0", dev)
+		assert.Equal(t, 1, gpuIndex)
+		assert.Equal(t, DefaultProbeDuration, duration)
+		return bw, nil
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	require.NotNil(t, cr.Probe)
+	assert.Equal(t, 390.5, cr.Probe.BandwidthGbps)
+	assert.Contains(t, cr.String(), "390.50 Gb/s")
+
+	// reused within the interval
+	bw = 120
+	c.getTimeNowFunc = func() time.Time { return now.Add(time.Minute) }
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, 1, probes)
+
+	// probed again after the interval
+	c.getTimeNowFunc = func() time.Time { return now.Add(DefaultProbeInterval) }
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, 2, probes)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "loopback probe on mlx5_0 at 120.00 Gb/s below 300.00 Gb/s")
+
+	// failed
+	c.getTimeNowFunc = func() time.Time { return now.Add(2 * DefaultProbeInterval) }
+	c.runProbeFunc = func(context.Context, string, int, time.Duration) (float64, error) {
+		return 0, errors.New("ib_write_bw not found (perftest not installed)")
+	}
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "loopback probe on mlx5_0 failed (ib_write_bw not found (perftest not installed))")
+}
+
+func TestCheckErrors(t *testing.T) {
+	c := createMockGPUDirectRDMAComponent(context.Background(), h100, testProcModules)
+	defer c.Close()
+	c.readProcModulesFunc = func() (map[string]module, error) { return nil, errors.New("permission denied") }
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "error reading kernel modules", cr.Summary())
+
+	c = createMockGPUDirectRDMAComponent(context.Background(), &mockNVMLInstance{productName: "NVIDIA H100", initErr: errors.New("unknown error")}, testProcModules)
+	defer c.Close()
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "NVML initialization error")
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,36 @@
+package gpudirectrdma
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the GPUDirect RDMA component.
+const SubSystem = "accelerator_nvidia_gpudirect_rdma"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricProbeBandwidth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "probe_bandwidth_bytes_per_second",
+			Help:      "average GPU memory to GPU memory bandwidth of the last loopback probe in bytes per second",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "device", "gpu_index"}, // label is name of the component
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricProbeBandwidth,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_probe_bandwidth_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
+	)
+}
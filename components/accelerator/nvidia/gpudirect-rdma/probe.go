@@ -0,0 +1,193 @@
+package gpudirectrdma
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/file"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultProbeDuration is the default duration of each loopback probe.
+	DefaultProbeDuration = 5 * time.Second
+
+	// probePort is the perftest port of the probe,
+	// other than the perftest default 18515 to not conflict with the manual runs.
+	probePort = "18525"
+	// probeClientAttempts is the number of the client attempts,
+	// as the server takes a moment to listen.
+	probeClientAttempts = 3
+)
+
+// ProbeConfig configures the optional perftest ("ib_write_bw") loopback probe,
+// writing from the GPU memory to the GPU memory over the RDMA device.
+type ProbeConfig struct {
+	// Enabled enables the loopback probe.
+	Enabled bool `json:"enabled"`
+	// Device is the RDMA device to probe (e.g., "mlx5_0").
+	// Defaults to the first RDMA device if empty.
+	Device string `json:"device,omitempty"`
+	// GPUIndex is the CUDA device index to allocate the buffers on.
+	GPUIndex int `json:"gpu_index,omitempty"`
+	// Duration is the duration of each probe.
+	// Defaults to DefaultProbeDuration if zero.
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// MinBandwidthGbps is the minimum average bandwidth in Gb/s,
+	// below which the host is reported as degraded.
+	// Zero only reports the bandwidth.
+	MinBandwidthGbps float64 `json:"min_bandwidth_gbps,omitempty"`
+}
+
+// Validate returns an error if the probe config is invalid.
+func (cfg ProbeConfig) Validate() error {
+	if cfg.GPUIndex < 0 {
+		return fmt.Errorf("gpu index must be non-negative, got %d", cfg.GPUIndex)
+	}
+	if cfg.Duration.Duration != 0 && cfg.Duration.Duration < time.Second {
+		return fmt.Errorf("probe duration must be at least 1s, got %s", cfg.Duration.Duration)
+	}
+	if cfg.MinBandwidthGbps < 0 {
+		return fmt.Errorf("min bandwidth must be non-negative, got %v", cfg.MinBandwidthGbps)
+	}
+	return nil
+}
+
+func (cfg ProbeConfig) duration() time.Duration {
+	if cfg.Duration.Duration > 0 {
+		return cfg.Duration.Duration
+	}
+	return DefaultProbeDuration
+}
+
+var (
+	defaultProbeConfigMu sync.RWMutex
+	defaultProbeConfig   ProbeConfig
+)
+
+// GetDefaultProbeConfig returns the current default probe config.
+func GetDefaultProbeConfig() ProbeConfig {
+	defaultProbeConfigMu.RLock()
+	defer defaultProbeConfigMu.RUnlock()
+
+	return defaultProbeConfig
+}
+
+// SetDefaultProbeConfig replaces the default probe config.
+func SetDefaultProbeConfig(cfg ProbeConfig) {
+	log.Logger.Infow("setting default gpudirect rdma probe config", "device", cfg.Device, "gpuIndex", cfg.GPUIndex)
+
+	defaultProbeConfigMu.Lock()
+	defer defaultProbeConfigMu.Unlock()
+	defaultProbeConfig = cfg
+}
+
+// ProbeResult is the result of the last loopback probe.
+type ProbeResult struct {
+	Time     metav1.Time `json:"time"`
+	Device   string      `json:"device"`
+	GPUIndex int         `json:"gpu_index"`
+	// BandwidthGbps is the average bandwidth in Gb/s.
+	BandwidthGbps float64 `json:"bandwidth_gbps"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// runProbe runs the "ib_write_bw" server and the client on the localhost,
+// with the buffers on the GPU memory, and returns the average bandwidth in Gb/s.
+// Requires the perftest built with the CUDA support.
+func runProbe(ctx context.Context, device string, gpuIndex int, duration time.Duration) (float64, error) {
+	execPath, err := file.LocateExecutable("ib_write_bw")
+	if err != nil {
+		return 0, errors.New("ib_write_bw not found (perftest not installed)")
+	}
+
+	args := []string{
+		"-d", device,
+		fmt.Sprintf("--use_cuda=%d", gpuIndex),
+		"-D", strconv.Itoa(int(duration.Seconds())),
+		"-p", probePort,
+		"--report_gbits",
+		"-F",
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, duration+30*time.Second)
+	defer ccancel()
+
+	server := osexec.CommandContext(cctx, execPath, args...)
+	var serverOut bytes.Buffer
+	server.Stdout = &serverOut
+	server.Stderr = &serverOut
+	if err := server.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start ib_write_bw server: %w", err)
+	}
+	defer func() {
+		_ = server.Process.Kill()
+		_ = server.Wait()
+	}()
+
+	var out []byte
+	for i := 0; i < probeClientAttempts; i++ {
+		select {
+		case <-cctx.Done():
+			return 0, cctx.Err()
+		case <-time.After(time.Second):
+		}
+
+		out, err = osexec.CommandContext(cctx, execPath, append(args, "localhost")...).CombinedOutput()
+		if err == nil {
+			break
+		}
+		log.Logger.Warnw("ib_write_bw client failed", "attempt", i+1, "error", err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ib_write_bw failed: %w (%s)", err, lastLine(string(out)+serverOut.String()))
+	}
+	return parseWriteBWOutput(string(out))
+}
+
+// parseWriteBWOutput returns the average bandwidth of the "ib_write_bw" report.
+//
+// e.g.,
+// #bytes     #iterations    BW peak[Gb/sec]    BW average[Gb/sec]   MsgRate[Mpps]
+// 65536      1893104          0.00               396.97             0.757163
+func parseWriteBWOutput(out string) (float64, error) {
+	found := false
+	bw := 0.0
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if _, err := strconv.ParseUint(fields[0], 10, 64); err != nil {
+			continue
+		}
+		if _, err := strconv.ParseUint(fields[1], 10, 64); err != nil {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			continue
+		}
+		found, bw = true, v
+	}
+	if !found {
+		return 0, fmt.Errorf("no bandwidth in ib_write_bw output (%s)", lastLine(out))
+	}
+	return bw, nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,48 @@
+package gpudirectrdma
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProbeConfigValidate(t *testing.T) {
+	assert.NoError(t, ProbeConfig{}.Validate())
+	assert.NoError(t, ProbeConfig{Enabled: true, Device: "mlx5_0", Duration: metav1.Duration{Duration: 10 * time.Second}, MinBandwidthGbps: 300}.Validate())
+	assert.Error(t, ProbeConfig{GPUIndex: -1}.Validate())
+	assert.Error(t, ProbeConfig{Duration: metav1.Duration{Duration: 500 * time.Millisecond}}.Validate())
+	assert.Error(t, ProbeConfig{Duration: metav1.Duration{Duration: -time.Second}}.Validate())
+	assert.Error(t, ProbeConfig{MinBandwidthGbps: -1}.Validate())
+
+	assert.Equal(t, DefaultProbeDuration, ProbeConfig{}.duration())
+	assert.Equal(t, 2*time.Second, ProbeConfig{Duration: metav1.Duration{Duration: 2 * time.Second}}.duration())
+}
+
+func TestParseWriteBWOutput(t *testing.T) {
+	out := `
+************************************
+* Waiting for client to connect... *
+************************************
+initializing CUDA
+Listing all CUDA devices in system:
+CUDA device 0: PCIe address is 18:00
+---------------------------------------------------------------------------------------
+                    RDMA_Write BW Test
+ Dual-port       : OFF		Device         : mlx5_0
+ Number of qps   : 1		Transport type : IB
+---------------------------------------------------------------------------------------
+ #bytes     #iterations    BW peak[Gb/sec]    BW average[Gb/sec]   MsgRate[Mpps]
+ 65536      1893104          0.00               396.97             0.757163
+---------------------------------------------------------------------------------------
+`
+	bw, err := parseWriteBWOutput(out)
+	require.NoError(t, err)
+	assert.InDelta(t, 396.97, bw, 0.001)
+
+	_, err = parseWriteBWOutput("Couldn't init CUDA: CUDA support is not enabled\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CUDA support is not enabled")
+}
@@ -0,0 +1,193 @@
+package gpudirectrdma
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
+	"github.com/leptonai/gpud/pkg/file"
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+const (
+	// DefaultProcModulesPath is the list of the loaded kernel modules.
+	DefaultProcModulesPath = "/proc/modules"
+	// DefaultRDMAClassDir is the sysfs directory of the RDMA devices.
+	DefaultRDMAClassDir = "/sys/class/infiniband"
+)
+
+// peerMemModules are the kernel modules registering the GPU memory
+// as the peer memory with ib_core, in the preference order.
+// "nv_peer_mem" is the legacy module from Mellanox, replaced by
+// "nvidia_peermem" shipped with the NVIDIA driver since R470.
+var peerMemModules = []string{"nvidia_peermem", "nv_peer_mem"}
+
+// minOFEDMajor and minOFEDMinor is the oldest MLNX_OFED supported by "nvidia_peermem".
+// ref. https://docs.nvidia.com/cuda/gpudirect-rdma/index.html
+const (
+	minOFEDMajor = 5
+	minOFEDMinor = 1
+)
+
+// module is a loaded kernel module in "/proc/modules".
+type module struct {
+	name     string
+	refCount int
+	usedBy   []string
+}
+
+// parseProcModules parses the loaded kernel modules by the name.
+//
+// e.g.,
+// ib_core 479232 9 nvidia_peermem,rdma_cm,ib_ipoib,iw_cm,ib_umad,rdma_ucm,ib_uverbs,mlx5_ib,ib_cm, Live 0x0000000000000000
+// nvidia_peermem 16384 0 - Live 0x0000000000000000 (OE)
+func parseProcModules(rd io.Reader) (map[string]module, error) {
+	mods := make(map[string]module)
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mod := module{name: fields[0]}
+		mod.refCount, _ = strconv.Atoi(fields[2])
+		if fields[3] != "-" {
+			for dep := range strings.SplitSeq(fields[3], ",") {
+				if dep != "" {
+					mod.usedBy = append(mod.usedBy, dep)
+				}
+			}
+		}
+		mods[mod.name] = mod
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mods, nil
+}
+
+func readProcModules() (map[string]module, error) {
+	f, err := os.Open(DefaultProcModulesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return parseProcModules(f)
+}
+
+// PeerMemStatus is the state of the GPU peer memory kernel module.
+type PeerMemStatus struct {
+	// Module is the loaded peer memory module, or empty if none.
+	Module string `json:"module,omitempty"`
+	// IBCoreLoaded is true if the "ib_core" module is loaded.
+	IBCoreLoaded bool `json:"ib_core_loaded"`
+	// UsedByIBCore is true if the peer memory module is registered with "ib_core",
+	// otherwise the RDMA transfers from the GPU memory fall back to the host memory.
+	UsedByIBCore bool `json:"used_by_ib_core"`
+}
+
+func checkPeerMem(mods map[string]module) PeerMemStatus {
+	var st PeerMemStatus
+	for _, name := range peerMemModules {
+		if _, ok := mods[name]; ok {
+			st.Module = name
+			break
+		}
+	}
+
+	ibcore, ok := mods["ib_core"]
+	st.IBCoreLoaded = ok
+	if st.Module == "" || !ok {
+		return st
+	}
+	for _, dep := range ibcore.usedBy {
+		if dep == st.Module {
+			st.UsedByIBCore = true
+			break
+		}
+	}
+	return st
+}
+
+// listRDMADevices returns the sorted RDMA device names (e.g., "mlx5_0").
+func listRDMADevices(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	devs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		devs = append(devs, e.Name())
+	}
+	sort.Strings(devs)
+	return devs, nil
+}
+
+// OFED is the installed OFED stack.
+type OFED struct {
+	// Version is the output of "ofed_info -s" (e.g., "MLNX_OFED_LINUX-5.8-1.0.1.1").
+	Version string `json:"version"`
+	Major   int    `json:"major"`
+	Minor   int    `json:"minor"`
+}
+
+// e.g., "MLNX_OFED_LINUX-5.8-1.0.1.1:", "OFED-internal-24.10-1.1.4:"
+var ofedVersionRegex = regexp.MustCompile(`-(\d+)\.(\d+)-`)
+
+func parseOFEDVersion(s string) (*OFED, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ":")
+	m := ofedVersionRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("unknown ofed version %q", s)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return &OFED{Version: s, Major: major, Minor: minor}, nil
+}
+
+// compatible returns true if the OFED stack supports "nvidia_peermem".
+func (o *OFED) compatible() bool {
+	if o.Major != minOFEDMajor {
+		return o.Major > minOFEDMajor
+	}
+	return o.Minor >= minOFEDMinor
+}
+
+// getOFED returns the installed MLNX_OFED (or DOCA OFED) stack,
+// or nil if not installed (e.g., the inbox rdma-core).
+func getOFED(ctx context.Context) (*OFED, error) {
+	execPath, err := file.LocateExecutable("ofed_info")
+	if err != nil {
+		return nil, nil
+	}
+	out, err := pkgexec.Run(ctx, execPath, "-s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ofed_info: %w", err)
+	}
+	return parseOFEDVersion(string(out))
+}
+
+// findACSEnabledDevices returns the PCI devices with the ACS source validation enabled,
+// which redirects the peer-to-peer traffic through the root complex.
+// ref. https://docs.nvidia.com/deeplearning/nccl/user-guide/docs/troubleshooting.html#pci-access-control-services-acs
+func findACSEnabledDevices(devs []pci.Device) []string {
+	var ids []string
+	for _, dev := range devs {
+		if dev.AccessControlService != nil && dev.AccessControlService.ACSCtl.SrcValid {
+			ids = append(ids, dev.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,101 @@
+package gpudirectrdma
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/pci"
+)
+
+const testProcModules = `nvidia_peermem 16384 0 - Live 0x0000000000000000 (OE)
+ib_core 479232 9 nvidia_peermem,rdma_cm,ib_ipoib,iw_cm,ib_umad,rdma_ucm,ib_uverbs,mlx5_ib,ib_cm, Live 0x0000000000000000
+nvidia 56725504 1189 nvidia_peermem,nvidia_uvm,nvidia_modeset, Live 0x0000000000000000 (POE)
+mlx5_core 2371584 1 mlx5_ib, Live 0x0000000000000000
+`
+
+func TestParseProcModules(t *testing.T) {
+	mods, err := parseProcModules(strings.NewReader(testProcModules + "\nbad\n"))
+	require.NoError(t, err)
+	require.Len(t, mods, 4)
+
+	assert.Equal(t, 0, mods["nvidia_peermem"].refCount)
+	assert.Empty(t, mods["nvidia_peermem"].usedBy)
+	assert.Equal(t, 9, mods["ib_core"].refCount)
+	assert.Contains(t, mods["ib_core"].usedBy, "nvidia_peermem")
+	assert.Len(t, mods["ib_core"].usedBy, 9)
+}
+
+func TestCheckPeerMem(t *testing.T) {
+	mods, err := parseProcModules(strings.NewReader(testProcModules))
+	require.NoError(t, err)
+	assert.Equal(t, PeerMemStatus{Module: "nvidia_peermem", IBCoreLoaded: true, UsedByIBCore: true}, checkPeerMem(mods))
+
+	// legacy module, not registered with ib_core
+	mods, err = parseProcModules(strings.NewReader("nv_peer_mem 16384 0 - Live 0x0\nib_core 479232 2 ib_uverbs,mlx5_ib, Live 0x0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, PeerMemStatus{Module: "nv_peer_mem", IBCoreLoaded: true}, checkPeerMem(mods))
+
+	// not loaded
+	mods, err = parseProcModules(strings.NewReader("ib_core 479232 2 ib_uverbs,mlx5_ib, Live 0x0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, PeerMemStatus{IBCoreLoaded: true}, checkPeerMem(mods))
+
+	assert.Equal(t, PeerMemStatus{Module: "nvidia_peermem"}, checkPeerMem(map[string]module{"nvidia_peermem": {name: "nvidia_peermem"}}))
+}
+
+func TestParseOFEDVersion(t *testing.T) {
+	tests := []struct {
+		in         string
+		version    string
+		major      int
+		minor      int
+		compatible bool
+	}{
+		{in: "MLNX_OFED_LINUX-5.8-1.0.1.1:\n", version: "MLNX_OFED_LINUX-5.8-1.0.1.1", major: 5, minor: 8, compatible: true},
+		{in: "MLNX_OFED_LINUX-5.0-2.1.8.0:", version: "MLNX_OFED_LINUX-5.0-2.1.8.0", major: 5, minor: 0},
+		{in: "MLNX_OFED_LINUX-4.9-7.1.0.0:", version: "MLNX_OFED_LINUX-4.9-7.1.0.0", major: 4, minor: 9},
+		{in: "OFED-internal-24.10-1.1.4:", version: "OFED-internal-24.10-1.1.4", major: 24, minor: 10, compatible: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			o, err := parseOFEDVersion(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, o.Version)
+			assert.Equal(t, tt.major, o.Major)
+			assert.Equal(t, tt.minor, o.Minor)
+			assert.Equal(t, tt.compatible, o.compatible())
+		})
+	}
+
+	_, err := parseOFEDVersion("unknown")
+	assert.Error(t, err)
+}
+
+func TestListRDMADevices(t *testing.T) {
+	dir := t.TempDir()
+	for _, dev := range []string{"mlx5_1", "mlx5_0"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, dev), 0o755))
+	}
+	devs, err := listRDMADevices(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mlx5_0", "mlx5_1"}, devs)
+
+	devs, err = listRDMADevices(filepath.Join(dir, "nonexistent"))
+	require.NoError(t, err)
+	assert.Empty(t, devs)
+}
+
+func TestFindACSEnabledDevices(t *testing.T) {
+	devs := []pci.Device{
+		{ID: "00:01.0", AccessControlService: &pci.AccessControlService{ACSCtl: pci.ACS{SrcValid: true}}},
+		{ID: "00:02.0", AccessControlService: &pci.AccessControlService{}},
+		{ID: "00:03.0"},
+	}
+	assert.Equal(t, []string{"00:01.0"}, findACSEnabledDevices(devs))
+	assert.Empty(t, findACSEnabledDevices(devs[1:]))
+}
@@ -0,0 +1,441 @@
+// Package gspfirmware tracks the NVIDIA GSP (GPU System Processor) firmware mode,
+// the GSP-related Xids, and the site policy of the GSP firmware mode.
+package gspfirmware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// Name is the ID of the NVIDIA GSP firmware component.
+const Name = "accelerator-nvidia-gsp-firmware"
+
+// GSPXids are the Xids of the GSP firmware failures,
+// handled by disabling the GSP firmware if recurring after reboot.
+//   - Xid 119: GSP RPC timeout
+//   - Xid 120: GSP error
+var GSPXids = []int{119, 120}
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	nvmlInstance       nvidianvml.Instance
+	getGSPFirmwareFunc func(uuid string, dev device.Device) (GSPFirmware, error)
+	getPolicyFunc      func() Policy
+	getLookbackFunc    func() time.Duration
+
+	// xidEventBucket reads the Xid events of the Xid component, nil if not set up
+	xidEventBucket eventstore.Bucket
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA GSP firmware component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		nvmlInstance:       gpudInstance.NVMLInstance,
+		getGSPFirmwareFunc: GetGSPFirmware,
+		getPolicyFunc:      GetDefaultPolicy,
+		getLookbackFunc:    xid.GetLookbackPeriod,
+	}
+
+	if gpudInstance.EventStore != nil {
+		// the Xid component purges its own events
+		var err error
+		c.xidEventBucket, err = gpudInstance.EventStore.Bucket(xid.Name, eventstore.WithDisablePurge())
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.xidEventBucket != nil {
+		c.xidEventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu gsp firmware")
+
+	cr := &checkResult{
+		ts:     c.getTimeNowFunc(),
+		Policy: c.getPolicyFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if err := c.nvmlInstance.InitError(); err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("NVML initialization error: %v", err)
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
+	for uuid, dev := range devs {
+		fw, err := c.getGSPFirmwareFunc(uuid, dev)
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error getting gsp firmware mode"
+
+			if errors.Is(err, nvmlerrors.ErrGPURequiresReset) || errors.Is(err, nvmlerrors.ErrGPULost) {
+				cr.reason = err.Error()
+				cr.suggestedActions = &apiv1.SuggestedActions{
+					Description: err.Error(),
+					RepairActions: []apiv1.RepairActionType{
+						apiv1.RepairActionTypeRebootSystem,
+					},
+				}
+			}
+
+			log.Logger.Warnw(cr.reason, "uuid", uuid, "error", cr.err)
+			return cr
+		}
+
+		labels := labeler.Labels(uuid)
+		metricEnabled.With(labels).Set(boolToFloat(fw.Enabled))
+		metricFallback.With(labels).Set(boolToFloat(fw.Fallback))
+
+		cr.GSPFirmwares = append(cr.GSPFirmwares, fw)
+	}
+	sort.Slice(cr.GSPFirmwares, func(i, j int) bool {
+		return cr.GSPFirmwares[i].UUID < cr.GSPFirmwares[j].UUID
+	})
+
+	if c.xidEventBucket != nil {
+		var err error
+		cr.XidCounts, err = c.countGSPXids()
+		if err != nil {
+			log.Logger.Warnw("error reading gsp xid events", "error", err)
+		}
+	}
+	for _, code := range GSPXids {
+		metricXids.With(map[string]string{"xid": strconv.Itoa(code)}).Set(float64(cr.XidCounts[code]))
+	}
+
+	var enabled, fallback []string
+	for _, fw := range cr.GSPFirmwares {
+		if !fw.Supported {
+			continue
+		}
+		if fw.Enabled {
+			enabled = append(enabled, fw.UUID)
+		} else if fw.Fallback {
+			fallback = append(fallback, fw.UUID)
+		}
+	}
+
+	totalXids := 0
+	for _, n := range cr.XidCounts {
+		totalXids += n
+	}
+
+	switch {
+	case cr.Policy == PolicyDisabled && len(enabled) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("gsp firmware enabled on %d GPU(s) (%s), but should be disabled per site policy", len(enabled), strings.Join(enabled, ", "))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "disable the gsp firmware with the nvidia kernel module parameter NVreg_EnableGpuFirmware=0, and reboot the system",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+
+	case cr.Policy == PolicyEnabled && len(fallback) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("gsp firmware fell back to disabled on %d GPU(s) (%s), but should be enabled per site policy", len(fallback), strings.Join(fallback, ", "))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "remove the nvidia kernel module parameter NVreg_EnableGpuFirmware=0, and reboot the system",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+
+	case totalXids > 0 && len(enabled) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("%d gsp-related xid(s) (%s) with gsp firmware enabled on %d GPU(s)", totalXids, formatXidCounts(cr.XidCounts), len(enabled))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "reboot the system, and if the gsp-related xids recur, disable the gsp firmware with the nvidia kernel module parameter NVreg_EnableGpuFirmware=0",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+
+	default:
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, gsp firmware enabled on %d GPU(s), fell back to disabled on %d GPU(s)", len(devs), len(enabled), len(fallback))
+	}
+
+	return cr
+}
+
+// countGSPXids returns the number of the GSP-related Xids by the code
+// within the lookback period.
+func (c *component) countGSPXids() (map[int]int, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, 15*time.Second)
+	evs, err := c.xidEventBucket.Get(ctx, c.getTimeNowFunc().Add(-c.getLookbackFunc()))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	for _, ev := range evs {
+		if ev.Name != xid.EventNameErrorXid {
+			continue
+		}
+		code, ok := parseXid(ev.ExtraInfo[xid.EventKeyErrorXidData])
+		if !ok || !isGSPXid(code) {
+			continue
+		}
+		counts[code]++
+	}
+	return counts, nil
+}
+
+// parseXid parses the Xid code from the Xid event data,
+// either the JSON payload or the code itself.
+func parseXid(data string) (int, bool) {
+	if data == "" {
+		return 0, false
+	}
+	if code, err := strconv.Atoi(data); err == nil {
+		return code, true
+	}
+	var payload struct {
+		Xid int `json:"xid"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil || payload.Xid == 0 {
+		return 0, false
+	}
+	return payload.Xid, true
+}
+
+func isGSPXid(code int) bool {
+	for _, x := range GSPXids {
+		if x == code {
+			return true
+		}
+	}
+	return false
+}
+
+func formatXidCounts(counts map[int]int) string {
+	parts := make([]string, 0, len(counts))
+	for _, code := range GSPXids {
+		if counts[code] > 0 {
+			parts = append(parts, fmt.Sprintf("xid %d x%d", code, counts[code]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	GSPFirmwares []GSPFirmware `json:"gsp_firmwares,omitempty"`
+	// XidCounts is the number of the GSP-related Xids by the code within the lookback period
+	XidCounts map[int]int `json:"xid_counts,omitempty"`
+	// Policy is the site policy of the GSP firmware mode, empty if none
+	Policy Policy `json:"policy,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.GSPFirmwares) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "GPU Bus ID", "GSP Enabled", "GSP Default", "GSP Fallback", "GSP Version"})
+	for _, fw := range cr.GSPFirmwares {
+		table.Append([]string{fw.UUID, fw.BusID, fmt.Sprintf("%t", fw.Enabled), fmt.Sprintf("%t", fw.DefaultMode), fmt.Sprintf("%t", fw.Fallback), fw.Version})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	// propagate suggested actions to health state if present
+	if cr.suggestedActions != nil {
+		state.SuggestedActions = cr.suggestedActions
+	}
+
+	if len(cr.GSPFirmwares) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
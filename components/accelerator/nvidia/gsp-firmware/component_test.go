@@ -0,0 +1,139 @@
+package gspfirmware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type mockNVMLInstance struct {
+	devs map[string]device.Device
+}
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) ProductName() string          { return "NVIDIA H100 80GB HBM3" }
+func (m *mockNVMLInstance) Architecture() string         { return "" }
+func (m *mockNVMLInstance) Brand() string                { return "" }
+func (m *mockNVMLInstance) DriverVersion() string        { return "" }
+func (m *mockNVMLInstance) DriverMajor() int             { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string          { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool   { return false }
+func (m *mockNVMLInstance) NVMLExists() bool             { return true }
+func (m *mockNVMLInstance) Library() lib.Library         { return nil }
+func (m *mockNVMLInstance) Shutdown() error              { return nil }
+func (m *mockNVMLInstance) InitError() error             { return nil }
+
+func TestCheckGSPFirmware(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	enabled := true
+	devs := map[string]device.Device{
+		"gpu-0": newGSPDevice("gpu-0", true, true, nvml.SUCCESS),
+	}
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      ctx,
+		NVMLInstance: &mockNVMLInstance{devs: devs},
+		EventStore:   store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+	comp := c.(*component)
+	policy := PolicyNone
+	comp.getPolicyFunc = func() Policy { return policy }
+	comp.getGSPFirmwareFunc = func(uuid string, dev device.Device) (GSPFirmware, error) {
+		return GSPFirmware{UUID: uuid, Supported: true, Enabled: enabled, DefaultMode: true, Fallback: !enabled}, nil
+	}
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType(), cr.Summary())
+
+	policy = PolicyDisabled
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "should be disabled per site policy")
+
+	// the gsp xids recorded by the xid component
+	xidBucket, err := store.Bucket(xid.Name)
+	require.NoError(t, err)
+	defer xidBucket.Close()
+	now := time.Now().UTC()
+	for i, data := range []string{`{"xid":119,"device_uuid":"gpu-0"}`, "120", "79"} {
+		require.NoError(t, xidBucket.Insert(ctx, eventstore.Event{
+			Time:      now.Add(time.Duration(-i) * time.Minute),
+			Name:      xid.EventNameErrorXid,
+			Type:      string(apiv1.EventTypeFatal),
+			ExtraInfo: map[string]string{xid.EventKeyErrorXidData: data},
+		}))
+	}
+
+	policy = PolicyNone
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "2 gsp-related xid(s)")
+	assert.Equal(t, map[int]int{119: 1, 120: 1}, cr.(*checkResult).XidCounts)
+
+	// gsp disabled after the xids
+	enabled = false
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "fell back to disabled on 1 GPU(s)")
+
+	policy = PolicyEnabled
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "should be enabled per site policy")
+	require.Len(t, cr.HealthStates(), 1)
+	assert.NotEmpty(t, cr.HealthStates()[0].ExtraInfo["data"])
+}
+
+func TestParseXid(t *testing.T) {
+	code, ok := parseXid("119")
+	assert.True(t, ok)
+	assert.Equal(t, 119, code)
+
+	code, ok = parseXid(`{"xid":120}`)
+	assert.True(t, ok)
+	assert.Equal(t, 120, code)
+
+	_, ok = parseXid("")
+	assert.False(t, ok)
+	_, ok = parseXid("invalid")
+	assert.False(t, ok)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, PolicyNone.Validate())
+	assert.NoError(t, PolicyEnabled.Validate())
+	assert.NoError(t, PolicyDisabled.Validate())
+	assert.Error(t, Policy("unknown").Validate())
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
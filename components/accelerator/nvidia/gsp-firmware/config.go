@@ -0,0 +1,55 @@
+package gspfirmware
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Policy is the site policy of the GSP firmware mode.
+type Policy string
+
+const (
+	// PolicyNone does not require any GSP firmware mode.
+	PolicyNone Policy = ""
+	// PolicyEnabled requires the GSP firmware to be enabled,
+	// so that the GPUs fallen back to the legacy mode are flagged.
+	PolicyEnabled Policy = "enabled"
+	// PolicyDisabled requires the GSP firmware to be disabled
+	// (e.g., the sites with the recurring GSP Xids), so that the GPUs
+	// running the GSP firmware are flagged.
+	PolicyDisabled Policy = "disabled"
+)
+
+// Validate returns an error if the policy is unknown.
+func (p Policy) Validate() error {
+	switch p {
+	case PolicyNone, PolicyEnabled, PolicyDisabled:
+		return nil
+	default:
+		return fmt.Errorf("gsp firmware policy must be one of %q, %q, and %q, got %q", PolicyNone, PolicyEnabled, PolicyDisabled, p)
+	}
+}
+
+var (
+	defaultPolicyMu sync.RWMutex
+	defaultPolicy   = PolicyNone
+)
+
+// GetDefaultPolicy returns the current default GSP firmware policy.
+func GetDefaultPolicy() Policy {
+	defaultPolicyMu.RLock()
+	defer defaultPolicyMu.RUnlock()
+
+	return defaultPolicy
+}
+
+// SetDefaultPolicy replaces the default GSP firmware policy.
+func SetDefaultPolicy(p Policy) {
+	log.Logger.Infow("setting default gsp firmware policy", "policy", p)
+
+	defaultPolicyMu.Lock()
+	defer defaultPolicyMu.Unlock()
+	defaultPolicy = p
+}
@@ -0,0 +1,84 @@
+package gspfirmware
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// GSPFirmware is the GSP (GPU System Processor) firmware mode of the device.
+//
+// With the GSP firmware enabled, the GPU resource manager (GSP-RM) is offloaded
+// from the driver to the GSP, which is the default on the Turing and later GPUs
+// since the R535 driver.
+//
+// The GSP firmware can be disabled with the "NVreg_EnableGpuFirmware=0" kernel module
+// parameter, which falls back to running the resource manager in the driver.
+type GSPFirmware struct {
+	UUID  string `json:"uuid"`
+	BusID string `json:"bus_id"`
+
+	// Enabled is true if the GSP firmware is enabled.
+	Enabled bool `json:"enabled"`
+	// DefaultMode is true if the GSP firmware is enabled by default
+	// for the device and the driver.
+	DefaultMode bool `json:"default_mode"`
+	// Fallback is true if the GSP firmware is enabled by default but disabled,
+	// thus the resource manager falls back to running in the driver.
+	Fallback bool `json:"fallback"`
+	// Version is the GSP firmware version, empty if not enabled.
+	Version string `json:"version,omitempty"`
+
+	// Supported is true if the GSP firmware mode is supported by the device.
+	Supported bool `json:"supported"`
+}
+
+// GetGSPFirmware returns the GSP firmware mode of the device.
+func GetGSPFirmware(uuid string, dev device.Device) (GSPFirmware, error) {
+	fw := GSPFirmware{
+		UUID:      uuid,
+		BusID:     dev.PCIBusID(),
+		Supported: true,
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+	enabled, defaultMode, ret := dev.GetGspFirmwareMode()
+	if nvmlerrors.IsNotSupportError(ret) {
+		fw.Supported = false
+		return fw, nil
+	}
+	if nvmlerrors.IsGPULostError(ret) {
+		return fw, nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return fw, nvmlerrors.ErrGPURequiresReset
+	}
+	// not a "not supported" error, not a success return, thus return an error here
+	if ret != nvml.SUCCESS {
+		return fw, fmt.Errorf("failed to get device gsp firmware mode: %v", nvml.ErrorString(ret))
+	}
+	fw.Enabled = enabled
+	fw.DefaultMode = defaultMode
+	fw.Fallback = defaultMode && !enabled
+
+	if !enabled {
+		return fw, nil
+	}
+
+	version, ret := dev.GetGspFirmwareVersion()
+	if nvmlerrors.IsGPULostError(ret) {
+		return fw, nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return fw, nvmlerrors.ErrGPURequiresReset
+	}
+	// the version is informational, thus not failing the check
+	if ret == nvml.SUCCESS {
+		fw.Version = version
+	}
+
+	return fw, nil
+}
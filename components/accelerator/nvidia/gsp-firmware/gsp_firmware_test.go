@@ -0,0 +1,52 @@
+package gspfirmware
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func newGSPDevice(uuid string, enabled bool, defaultMode bool, ret nvml.Return) device.Device {
+	return testutil.NewMockDevice(&mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		GetGspFirmwareModeFunc: func() (bool, bool, nvml.Return) {
+			return enabled, defaultMode, ret
+		},
+		GetGspFirmwareVersionFunc: func() (string, nvml.Return) {
+			return "570.86.15", nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "0000:01:00.0")
+}
+
+func TestGetGSPFirmware(t *testing.T) {
+	fw, err := GetGSPFirmware("gpu-0", newGSPDevice("gpu-0", true, true, nvml.SUCCESS))
+	require.NoError(t, err)
+	assert.True(t, fw.Supported)
+	assert.True(t, fw.Enabled)
+	assert.False(t, fw.Fallback)
+	assert.Equal(t, "570.86.15", fw.Version)
+	assert.Equal(t, "0000:01:00.0", fw.BusID)
+
+	fw, err = GetGSPFirmware("gpu-0", newGSPDevice("gpu-0", false, true, nvml.SUCCESS))
+	require.NoError(t, err)
+	assert.False(t, fw.Enabled)
+	assert.True(t, fw.Fallback)
+	assert.Empty(t, fw.Version)
+
+	fw, err = GetGSPFirmware("gpu-0", newGSPDevice("gpu-0", false, false, nvml.ERROR_NOT_SUPPORTED))
+	require.NoError(t, err)
+	assert.False(t, fw.Supported)
+
+	_, err = GetGSPFirmware("gpu-0", newGSPDevice("gpu-0", false, false, nvml.ERROR_GPU_IS_LOST))
+	assert.ErrorIs(t, err, nvmlerrors.ErrGPULost)
+
+	_, err = GetGSPFirmware("gpu-0", newGSPDevice("gpu-0", false, false, nvml.ERROR_UNKNOWN))
+	assert.Error(t, err)
+}
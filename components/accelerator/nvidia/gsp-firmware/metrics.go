@@ -0,0 +1,59 @@
+package gspfirmware
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA GSP firmware component.
+const SubSystem = "accelerator_nvidia_gsp_firmware"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricEnabled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "enabled",
+			Help:      "set to 1 if the GSP firmware is enabled on the GPU",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+	metricFallback = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "fallback",
+			Help:      "set to 1 if the GSP firmware is enabled by default but disabled on the GPU",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+	metricXids = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "xids",
+			Help:      "number of the GSP-related Xids within the lookback period",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "xid"},
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricEnabled,
+		metricFallback,
+		metricXids,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_enabled", Type: apiv1.MetricTypeGauge},
+		apiv1.MetricMetadata{Name: SubSystem + "_fallback", Type: apiv1.MetricTypeGauge},
+		apiv1.MetricMetadata{Name: SubSystem + "_xids", Type: apiv1.MetricTypeGauge},
+	)
+}
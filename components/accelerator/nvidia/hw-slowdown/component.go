@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -38,6 +37,8 @@ const (
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -144,20 +145,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -250,6 +238,7 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 	for uuid, dev := range devs {
 		supported, err := c.getClockEventsSupportedFunc(dev)
 		if err != nil {
@@ -295,21 +284,21 @@ func (c *component) Check() components.CheckResult {
 		}
 
 		if clockEvents.HWSlowdown {
-			metricHWSlowdown.With(prometheus.Labels{"uuid": uuid}).Set(float64(1))
+			metricHWSlowdown.With(labeler.Labels(uuid)).Set(float64(1))
 		} else {
-			metricHWSlowdown.With(prometheus.Labels{"uuid": uuid}).Set(float64(0))
+			metricHWSlowdown.With(labeler.Labels(uuid)).Set(float64(0))
 		}
 
 		if clockEvents.HWSlowdownThermal {
-			metricHWSlowdownThermal.With(prometheus.Labels{"uuid": uuid}).Set(float64(1))
+			metricHWSlowdownThermal.With(labeler.Labels(uuid)).Set(float64(1))
 		} else {
-			metricHWSlowdownThermal.With(prometheus.Labels{"uuid": uuid}).Set(float64(0))
+			metricHWSlowdownThermal.With(labeler.Labels(uuid)).Set(float64(0))
 		}
 
 		if clockEvents.HWSlowdownPowerBrake {
-			metricHWSlowdownPowerBrake.With(prometheus.Labels{"uuid": uuid}).Set(float64(1))
+			metricHWSlowdownPowerBrake.With(labeler.Labels(uuid)).Set(float64(1))
 		} else {
-			metricHWSlowdownPowerBrake.With(prometheus.Labels{"uuid": uuid}).Set(float64(0))
+			metricHWSlowdownPowerBrake.With(labeler.Labels(uuid)).Set(float64(0))
 		}
 
 		cr.ClockEvents = append(cr.ClockEvents, clockEvents)
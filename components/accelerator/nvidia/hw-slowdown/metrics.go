@@ -3,7 +3,11 @@ package hwslowdown
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventmetrics"
+	"github.com/leptonai/gpud/pkg/eventstore"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA HW slowdown component.
@@ -21,7 +25,7 @@ var (
 			Name:      "hw_slowdown",
 			Help:      "tracks hardware slowdown event -- HW Slowdown is engaged due to high temperature, power brake assertion, or high power draw",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricHWSlowdownThermal = prometheus.NewGaugeVec(
@@ -31,7 +35,7 @@ var (
 			Name:      "hw_slowdown_thermal",
 			Help:      "tracks hardware thermal slowdown event -- HW Thermal Slowdown is engaged (temperature being too high",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricHWSlowdownPowerBrake = prometheus.NewGaugeVec(
@@ -41,7 +45,7 @@ var (
 			Name:      "hw_slowdown_power_brake",
 			Help:      "tracks hardware power brake slowdown event -- HW Power Brake Slowdown is engaged (External Power Brake Assertion being triggered)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -51,4 +55,20 @@ func init() {
 		metricHWSlowdownThermal,
 		metricHWSlowdownPowerBrake,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_hw_slowdown", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_hw_slowdown_thermal", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_hw_slowdown_power_brake", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
+
+	eventmetrics.MustRegister(Name, eventmetrics.Bridge{
+		EventName: EventNameHWSlowdown,
+		Name:      SubSystem + "_hw_slowdown_events_total",
+		Help:      "tracks the hardware slowdown event counts per GPU UUID",
+		Labels:    []string{nvidianvml.LabelGPUUUID},
+		LabelValues: func(ev eventstore.Event) ([]string, bool) {
+			uuid := ev.ExtraInfo["gpu_uuid"]
+			return []string{uuid}, uuid != ""
+		},
+	})
 }
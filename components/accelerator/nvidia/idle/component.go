@@ -0,0 +1,389 @@
+// Package idle detects the NVIDIA GPUs idle beyond a configurable duration,
+// records the idle-start and idle-end transitions as events,
+// and optionally invokes the power saving hooks on the transitions.
+package idle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// Name is the ID of the NVIDIA GPU idle component.
+const Name = "accelerator-nvidia-idle"
+
+const (
+	// EventNameIdleStart is emitted when the GPU has been idle for the idle duration.
+	EventNameIdleStart = "gpu_idle_start"
+	// EventNameIdleEnd is emitted when the idle GPU is busy again.
+	EventNameIdleEnd = "gpu_idle_end"
+
+	// EventKeyDeviceUUID stores the device UUID associated with the event.
+	EventKeyDeviceUUID = "device_uuid"
+	// EventKeyDeviceBusID stores the PCI bus ID associated with the event.
+	EventKeyDeviceBusID = "device_bus_id"
+	// EventKeyIdleSince stores when the GPU became idle (RFC3339).
+	EventKeyIdleSince = "idle_since"
+	// EventKeyIdleDuration stores how long the GPU has been idle.
+	EventKeyIdleDuration = "idle_duration"
+	// EventKeyPowerLimitMilliwatts stores the power limit set by the hook.
+	EventKeyPowerLimitMilliwatts = "power_limit_milliwatts"
+	// EventKeyHookError stores the error from the hook, if any.
+	EventKeyHookError = "hook_error"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+	getConfigFunc  func() Config
+
+	nvmlInstance       nvidianvml.Instance
+	getUtilizationFunc func(uuid string, dev device.Device) (utilization.Utilization, error)
+
+	eventBucket eventstore.Bucket
+
+	// checkMu serializes the checks, for the tracker and the power limiter
+	checkMu      sync.Mutex
+	tracker      *tracker
+	powerLimiter *powerLimiter
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA GPU idle component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc:      GetDefaultConfig,
+		nvmlInstance:       gpudInstance.NVMLInstance,
+		getUtilizationFunc: utilization.GetUtilization,
+		tracker:            newTracker(),
+		powerLimiter:       newPowerLimiter(),
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	// do not leave the lowered power limits behind
+	c.checkMu.Lock()
+	if len(c.powerLimiter.previousMilliwatts) > 0 && c.nvmlInstance != nil {
+		devs := c.nvmlInstance.Devices()
+		for uuid := range c.powerLimiter.previousMilliwatts {
+			if dev, ok := devs[uuid]; ok {
+				if _, err := c.powerLimiter.restore(uuid, dev); err != nil {
+					log.Logger.Warnw("failed to restore power limit", "uuid", uuid, "error", err)
+				}
+			}
+		}
+	}
+	c.checkMu.Unlock()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu idleness")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	cfg := c.getConfigFunc()
+	threshold, idleDuration := cfg.utilizationThresholdPercent(), cfg.idleDuration()
+
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+
+	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
+	for _, r := range nvidianvml.QueryDevices(devs, c.getUtilizationFunc) {
+		uuid, util, err := r.UUID, r.Value, r.Err
+		if err != nil {
+			// the utilization errors are reported by the utilization component
+			log.Logger.Warnw("error getting utilization", "uuid", uuid, "error", err)
+			continue
+		}
+		if !util.Supported {
+			continue
+		}
+
+		if tr := c.tracker.observe(uuid, util.GPUUsedPercent, threshold, idleDuration, cr.ts); tr != nil {
+			c.handleTransition(cfg, devs[uuid], util.BusID, tr)
+		}
+
+		idle := 0.0
+		if since := c.tracker.idleSince(uuid); !since.IsZero() {
+			idle = 1
+			cr.IdleGPUs = append(cr.IdleGPUs, IdleGPU{UUID: uuid, BusID: util.BusID, Since: metav1.NewTime(since)})
+		}
+		metricIdle.With(labeler.Labels(uuid)).Set(idle)
+	}
+	sort.Slice(cr.IdleGPUs, func(i, j int) bool { return cr.IdleGPUs[i].UUID < cr.IdleGPUs[j].UUID })
+
+	// idleness is about the capacity, not the health
+	cr.health = apiv1.HealthStateTypeHealthy
+	if len(cr.IdleGPUs) > 0 {
+		cr.reason = fmt.Sprintf("%d of %d GPU(s) idle (utilization <= %d%%) for longer than %s", len(cr.IdleGPUs), len(devs), threshold, idleDuration)
+	} else {
+		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no idle GPU found", len(devs))
+	}
+
+	return cr
+}
+
+// handleTransition runs the hooks on the idle transition, and records the transition event.
+func (c *component) handleTransition(cfg Config, dev device.Device, busID string, tr *transition) {
+	ev := eventstore.Event{
+		Component: Name,
+		Time:      tr.time,
+		Type:      string(apiv1.EventTypeInfo),
+		ExtraInfo: map[string]string{
+			EventKeyDeviceUUID:   tr.uuid,
+			EventKeyDeviceBusID:  busID,
+			EventKeyIdleSince:    tr.since.UTC().Format(time.RFC3339),
+			EventKeyIdleDuration: tr.time.Sub(tr.since).Round(time.Second).String(),
+		},
+	}
+
+	var limit uint32
+	var hookErr error
+	switch tr.typ {
+	case transitionIdleStart:
+		ev.Name = EventNameIdleStart
+		ev.Message = fmt.Sprintf("GPU %s (%s) idle since %s", tr.uuid, busID, ev.ExtraInfo[EventKeyIdleSince])
+		if cfg.Hooks.NotifyControlPlane {
+			ev.Type = string(apiv1.EventTypeWarning)
+		}
+		if cfg.Hooks.PowerLimitWatts > 0 && dev != nil {
+			limit, hookErr = c.powerLimiter.lower(tr.uuid, dev, cfg.Hooks.PowerLimitWatts)
+		}
+
+	case transitionIdleEnd:
+		ev.Name = EventNameIdleEnd
+		ev.Message = fmt.Sprintf("GPU %s (%s) busy again after idle for %s", tr.uuid, busID, ev.ExtraInfo[EventKeyIdleDuration])
+		// restore even if the hook has been disabled since, not to leave the lowered limit behind
+		if dev != nil {
+			limit, hookErr = c.powerLimiter.restore(tr.uuid, dev)
+		}
+	}
+
+	if limit > 0 {
+		ev.ExtraInfo[EventKeyPowerLimitMilliwatts] = strconv.FormatUint(uint64(limit), 10)
+	}
+	if hookErr != nil {
+		log.Logger.Warnw("failed to run gpu idle hook", "uuid", tr.uuid, "transition", tr.typ, "error", hookErr)
+		ev.ExtraInfo[EventKeyHookError] = hookErr.Error()
+	}
+	log.Logger.Infow("gpu idle transition", "uuid", tr.uuid, "transition", tr.typ, "since", tr.since)
+
+	if c.eventBucket == nil {
+		return
+	}
+	insertCtx, insertCancel := context.WithTimeout(c.ctx, 15*time.Second)
+	err := c.eventBucket.Insert(insertCtx, ev)
+	insertCancel()
+	if err != nil {
+		log.Logger.Warnw("error inserting gpu idle event", "uuid", tr.uuid, "error", err)
+	}
+}
+
+// IdleGPU is a GPU idle for longer than the idle duration.
+type IdleGPU struct {
+	UUID  string      `json:"uuid"`
+	BusID string      `json:"bus_id,omitempty"`
+	Since metav1.Time `json:"since"`
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	IdleGPUs []IdleGPU `json:"idle_gpus,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.IdleGPUs) == 0 {
+		return "no idle GPU"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "GPU Bus ID", "Idle Since", "Idle For"})
+	for _, g := range cr.IdleGPUs {
+		table.Append([]string{
+			g.UUID,
+			g.BusID,
+			g.Since.UTC().Format(time.RFC3339),
+			cr.ts.Sub(g.Since.Time).Round(time.Second).String(),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Health:    cr.health,
+	}
+
+	if len(cr.IdleGPUs) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
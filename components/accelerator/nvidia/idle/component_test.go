@@ -0,0 +1,185 @@
+package idle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type mockNVMLInstance struct {
+	devs map[string]device.Device
+}
+
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockNVMLInstance) ProductName() string          { return "NVIDIA H100 80GB HBM3" }
+func (m *mockNVMLInstance) Architecture() string         { return "" }
+func (m *mockNVMLInstance) Brand() string                { return "" }
+func (m *mockNVMLInstance) DriverVersion() string        { return "" }
+func (m *mockNVMLInstance) DriverMajor() int             { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string          { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool   { return false }
+func (m *mockNVMLInstance) NVMLExists() bool             { return true }
+func (m *mockNVMLInstance) Library() lib.Library         { return nil }
+func (m *mockNVMLInstance) Shutdown() error              { return nil }
+func (m *mockNVMLInstance) InitError() error             { return nil }
+
+// newPowerDevice returns a device with the power limit settable in [100 W, 700 W].
+func newPowerDevice(uuid string, limit *uint32) device.Device {
+	return testutil.NewMockDevice(&mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) { return uuid, nvml.SUCCESS },
+		GetPowerManagementLimitFunc: func() (uint32, nvml.Return) {
+			return *limit, nvml.SUCCESS
+		},
+		GetPowerManagementLimitConstraintsFunc: func() (uint32, uint32, nvml.Return) {
+			return 100000, 700000, nvml.SUCCESS
+		},
+		SetPowerManagementLimitFunc: func(v uint32) nvml.Return {
+			*limit = v
+			return nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "0000:01:00.0")
+}
+
+func TestCheckIdleTransitions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	limit := uint32(700000)
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      ctx,
+		NVMLInstance: &mockNVMLInstance{devs: map[string]device.Device{"gpu-0": newPowerDevice("gpu-0", &limit)}},
+		EventStore:   store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+
+	comp := c.(*component)
+	now := time.Now().UTC().Truncate(time.Second)
+	comp.getTimeNowFunc = func() time.Time { return now }
+	comp.getConfigFunc = func() Config {
+		cfg := Config{Hooks: Hooks{PowerLimitWatts: 50, NotifyControlPlane: true}}
+		cfg.IdleDuration.Duration = 10 * time.Minute
+		return cfg
+	}
+	var used uint32
+	comp.getUtilizationFunc = func(uuid string, _ device.Device) (utilization.Utilization, error) {
+		return utilization.Utilization{UUID: uuid, BusID: "0000:01:00.0", GPUUsedPercent: used, Supported: true}, nil
+	}
+
+	cr := comp.Check().(*checkResult)
+	assert.Empty(t, cr.IdleGPUs)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	now = now.Add(11 * time.Minute)
+	cr = comp.Check().(*checkResult)
+	require.Len(t, cr.IdleGPUs, 1)
+	assert.Equal(t, "gpu-0", cr.IdleGPUs[0].UUID)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "1 of 1 GPU(s) idle")
+	// clamped to the minimum limit
+	assert.Equal(t, uint32(100000), limit)
+
+	evs, err := comp.Events(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameIdleStart, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+
+	used = 90
+	now = now.Add(time.Minute)
+	cr = comp.Check().(*checkResult)
+	assert.Empty(t, cr.IdleGPUs)
+	assert.Equal(t, uint32(700000), limit)
+
+	evs, err = comp.Events(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	// latest first
+	assert.Equal(t, EventNameIdleEnd, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeInfo, evs[0].Type)
+
+	raw, err := comp.eventBucket.Get(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, raw, 2)
+	assert.Equal(t, "gpu-0", raw[0].ExtraInfo[EventKeyDeviceUUID])
+	assert.Equal(t, "12m0s", raw[0].ExtraInfo[EventKeyIdleDuration])
+	assert.Equal(t, "700000", raw[0].ExtraInfo[EventKeyPowerLimitMilliwatts])
+	assert.Equal(t, "100000", raw[1].ExtraInfo[EventKeyPowerLimitMilliwatts])
+}
+
+func TestPowerLimiter(t *testing.T) {
+	limit := uint32(300000)
+	dev := newPowerDevice("gpu-0", &limit)
+	p := newPowerLimiter()
+
+	// nothing to restore
+	restored, err := p.restore("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+
+	// already below the target
+	set, err := p.lower("gpu-0", dev, 400)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(300000), set)
+	assert.Empty(t, p.previousMilliwatts)
+
+	set, err = p.lower("gpu-0", dev, 200)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(200000), set)
+	assert.Equal(t, uint32(200000), limit)
+
+	restored, err = p.restore("gpu-0", dev)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(300000), restored)
+	assert.Equal(t, uint32(300000), limit)
+}
+
+func TestCloseRestoresPowerLimit(t *testing.T) {
+	limit := uint32(700000)
+	dev := newPowerDevice("gpu-0", &limit)
+
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockNVMLInstance{devs: map[string]device.Device{"gpu-0": dev}},
+	})
+	require.NoError(t, err)
+	comp := c.(*component)
+
+	_, err = comp.powerLimiter.lower("gpu-0", dev, 300)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(300000), limit)
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, uint32(700000), limit)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,95 @@
+package idle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultUtilizationThresholdPercent is the default GPU utilization at or below which
+	// the GPU is considered idle.
+	DefaultUtilizationThresholdPercent = 5
+	// DefaultIdleDuration is the default duration the GPU must stay idle
+	// before it is reported as idle.
+	DefaultIdleDuration = time.Hour
+)
+
+// Config configures the GPU idleness detection and the hooks
+// invoked on the idle-start and idle-end transitions.
+type Config struct {
+	// UtilizationThresholdPercent is the GPU utilization percent at or below which
+	// the GPU is considered idle.
+	// Defaults to DefaultUtilizationThresholdPercent if zero.
+	UtilizationThresholdPercent uint32 `json:"utilization_threshold_percent,omitempty"`
+	// IdleDuration is how long the GPU must stay idle before the idle-start transition.
+	// Defaults to DefaultIdleDuration if zero.
+	IdleDuration metav1.Duration `json:"idle_duration,omitempty"`
+
+	// Hooks are the actions on the idle transitions, all disabled by default.
+	Hooks Hooks `json:"hooks,omitempty"`
+}
+
+// Hooks are the actions invoked on the idle transitions.
+// The custom plugins can also run on the transitions
+// with a trigger on the "gpu_idle_start" or "gpu_idle_end" events of this component.
+type Hooks struct {
+	// PowerLimitWatts lowers the power management limit of the idle GPU
+	// to the watts on idle-start, and restores the previous limit on idle-end.
+	// Zero to not change the power limit.
+	PowerLimitWatts uint32 `json:"power_limit_watts,omitempty"`
+	// NotifyControlPlane records the idle-start events as the warning events
+	// (instead of the info events), so the control plane alerts on the stranded GPUs.
+	NotifyControlPlane bool `json:"notify_control_plane,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.UtilizationThresholdPercent > 100 {
+		return fmt.Errorf("utilization_threshold_percent must be in [0, 100], got %d", cfg.UtilizationThresholdPercent)
+	}
+	if cfg.IdleDuration.Duration < 0 {
+		return fmt.Errorf("idle_duration must be non-negative, got %s", cfg.IdleDuration.Duration)
+	}
+	return nil
+}
+
+func (cfg Config) utilizationThresholdPercent() uint32 {
+	if cfg.UtilizationThresholdPercent > 0 {
+		return cfg.UtilizationThresholdPercent
+	}
+	return DefaultUtilizationThresholdPercent
+}
+
+func (cfg Config) idleDuration() time.Duration {
+	if cfg.IdleDuration.Duration > 0 {
+		return cfg.IdleDuration.Duration
+	}
+	return DefaultIdleDuration
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default GPU idleness config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default GPU idleness config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default gpu idle config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
@@ -0,0 +1,59 @@
+package idle
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// powerLimiter lowers the power limit of the idle GPUs,
+// and restores the previous limits once busy again.
+// Not safe for concurrent use.
+type powerLimiter struct {
+	// previousMilliwatts is the power limit before lowered on idle-start, per GPU UUID
+	previousMilliwatts map[string]uint32
+}
+
+func newPowerLimiter() *powerLimiter {
+	return &powerLimiter{previousMilliwatts: make(map[string]uint32)}
+}
+
+// lower sets the power limit of the GPU to the watts
+// (clamped to the supported range), and returns the new limit in milliwatts.
+func (p *powerLimiter) lower(uuid string, dev device.Device, watts uint32) (uint32, error) {
+	prev, ret := dev.GetPowerManagementLimit()
+	if ret != nvml.SUCCESS {
+		return 0, fmt.Errorf("failed to get power management limit: %v", nvml.ErrorString(ret))
+	}
+
+	target := watts * 1000
+	if minLimit, maxLimit, ret := dev.GetPowerManagementLimitConstraints(); ret == nvml.SUCCESS {
+		target = max(min(target, maxLimit), minLimit)
+	}
+	if target >= prev {
+		// already at or below the target, nothing to restore
+		return prev, nil
+	}
+
+	if ret := dev.SetPowerManagementLimit(target); ret != nvml.SUCCESS {
+		return 0, fmt.Errorf("failed to set power management limit to %d mW: %v", target, nvml.ErrorString(ret))
+	}
+	p.previousMilliwatts[uuid] = prev
+	return target, nil
+}
+
+// restore sets the power limit of the GPU back to the limit before lowered,
+// and returns the restored limit in milliwatts (zero if not lowered).
+func (p *powerLimiter) restore(uuid string, dev device.Device) (uint32, error) {
+	prev, ok := p.previousMilliwatts[uuid]
+	if !ok {
+		return 0, nil
+	}
+	if ret := dev.SetPowerManagementLimit(prev); ret != nvml.SUCCESS {
+		return 0, fmt.Errorf("failed to restore power management limit to %d mW: %v", prev, nvml.ErrorString(ret))
+	}
+	delete(p.previousMilliwatts, uuid)
+	return prev, nil
+}
@@ -0,0 +1,37 @@
+package idle
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA GPU idle component.
+const SubSystem = "accelerator_nvidia_idle"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricIdle = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "idle",
+			Help:      "set to 1 if the GPU has been idle for longer than the idle duration",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricIdle,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_idle", Type: apiv1.MetricTypeGauge},
+	)
+}
@@ -0,0 +1,77 @@
+package idle
+
+import (
+	"time"
+)
+
+// transitionType is the type of the idle transition of a GPU.
+type transitionType string
+
+const (
+	transitionIdleStart transitionType = "idle_start"
+	transitionIdleEnd   transitionType = "idle_end"
+)
+
+// transition is the idle state change of a GPU.
+type transition struct {
+	uuid  string
+	typ   transitionType
+	time  time.Time
+	since time.Time
+}
+
+// gpuIdleness is the idleness of a GPU.
+type gpuIdleness struct {
+	// belowSince is when the utilization dropped to the threshold or below,
+	// zero if the GPU is busy
+	belowSince time.Time
+	// idle is true if the GPU has been below the threshold for the idle duration
+	idle bool
+}
+
+// tracker tracks the idleness of the GPUs from the utilization samples.
+// Not safe for concurrent use.
+type tracker struct {
+	gpus map[string]*gpuIdleness
+}
+
+func newTracker() *tracker {
+	return &tracker{gpus: make(map[string]*gpuIdleness)}
+}
+
+// observe records the utilization sample of the GPU,
+// and returns the transition if the GPU became idle or busy.
+func (t *tracker) observe(uuid string, utilPercent uint32, thresholdPercent uint32, idleDuration time.Duration, now time.Time) *transition {
+	g, ok := t.gpus[uuid]
+	if !ok {
+		g = &gpuIdleness{}
+		t.gpus[uuid] = g
+	}
+
+	if utilPercent > thresholdPercent {
+		wasIdle, since := g.idle, g.belowSince
+		g.belowSince, g.idle = time.Time{}, false
+		if wasIdle {
+			return &transition{uuid: uuid, typ: transitionIdleEnd, time: now, since: since}
+		}
+		return nil
+	}
+
+	if g.belowSince.IsZero() {
+		g.belowSince = now
+	}
+	if !g.idle && now.Sub(g.belowSince) >= idleDuration {
+		g.idle = true
+		return &transition{uuid: uuid, typ: transitionIdleStart, time: now, since: g.belowSince}
+	}
+	return nil
+}
+
+// idleSince returns when the GPU became idle, or zero if not idle.
+func (t *tracker) idleSince(uuid string) time.Time {
+	g, ok := t.gpus[uuid]
+	if !ok || !g.idle {
+		return time.Time{}
+	}
+	return g.belowSince
+}
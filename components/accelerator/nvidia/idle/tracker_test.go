@@ -0,0 +1,58 @@
+package idle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerObserve(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := newTracker()
+
+	// busy
+	assert.Nil(t, tr.observe("gpu-0", 80, 5, time.Hour, base))
+	assert.True(t, tr.idleSince("gpu-0").IsZero())
+
+	// below the threshold, but not for long enough
+	assert.Nil(t, tr.observe("gpu-0", 5, 5, time.Hour, base.Add(time.Minute)))
+	assert.Nil(t, tr.observe("gpu-0", 0, 5, time.Hour, base.Add(30*time.Minute)))
+	assert.True(t, tr.idleSince("gpu-0").IsZero())
+
+	start := tr.observe("gpu-0", 0, 5, time.Hour, base.Add(61*time.Minute))
+	require.NotNil(t, start)
+	assert.Equal(t, transitionIdleStart, start.typ)
+	assert.Equal(t, base.Add(time.Minute), start.since)
+	assert.Equal(t, base.Add(time.Minute), tr.idleSince("gpu-0"))
+
+	// no repeated transition while idle
+	assert.Nil(t, tr.observe("gpu-0", 0, 5, time.Hour, base.Add(2*time.Hour)))
+
+	end := tr.observe("gpu-0", 50, 5, time.Hour, base.Add(3*time.Hour))
+	require.NotNil(t, end)
+	assert.Equal(t, transitionIdleEnd, end.typ)
+	assert.Equal(t, base.Add(time.Minute), end.since)
+	assert.True(t, tr.idleSince("gpu-0").IsZero())
+
+	// busy again resets the idle window
+	assert.Nil(t, tr.observe("gpu-0", 0, 5, time.Hour, base.Add(3*time.Hour+time.Minute)))
+	assert.Nil(t, tr.observe("gpu-0", 10, 5, time.Hour, base.Add(3*time.Hour+2*time.Minute)))
+	assert.Nil(t, tr.observe("gpu-0", 0, 5, time.Hour, base.Add(4*time.Hour)))
+	assert.True(t, tr.idleSince("gpu-0").IsZero())
+
+	assert.True(t, tr.idleSince("unknown").IsZero())
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.Error(t, Config{UtilizationThresholdPercent: 101}.Validate())
+
+	cfg := Config{}
+	cfg.IdleDuration.Duration = -time.Second
+	assert.Error(t, cfg.Validate())
+
+	assert.Equal(t, uint32(DefaultUtilizationThresholdPercent), Config{}.utilizationThresholdPercent())
+	assert.Equal(t, DefaultIdleDuration, Config{}.idleDuration())
+}
@@ -62,6 +62,8 @@ const (
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -197,20 +199,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(c.checkInterval)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), c.checkInterval, c.Check)
 	return nil
 }
 
@@ -305,6 +294,7 @@ func (c *component) Check() components.CheckResult {
 	if thresholds.IsZero() {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = reasonNoThreshold
+		cr.reasonCode = ReasonCodeThresholdNotSet
 		return cr
 	}
 
@@ -343,6 +333,7 @@ func (c *component) Check() components.CheckResult {
 		log.Logger.Warnw("error loading infiniband class devices", "devices", len(cr.ClassDevices), "error", err)
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = "error loading infiniband class devices"
+		cr.reasonCode = ReasonCodeClassReadError
 		cr.err = err
 		return cr
 	}
@@ -381,6 +372,7 @@ func (c *component) Check() components.CheckResult {
 			log.Logger.Warnw("error inserting ib ports into store", "error", err)
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error inserting ib ports into store"
+			cr.reasonCode = ReasonCodePortStoreError
 			cr.err = err
 			return cr
 		}
@@ -388,6 +380,7 @@ func (c *component) Check() components.CheckResult {
 			log.Logger.Warnw("error scanning ib ports from store", "error", err)
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error scanning ib ports from store"
+			cr.reasonCode = ReasonCodePortStoreError
 			cr.err = err
 			return cr
 		}
@@ -397,6 +390,7 @@ func (c *component) Check() components.CheckResult {
 	if len(sysClassIBPorts) == 0 {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = reasonNoIbPortData
+		cr.reasonCode = ReasonCodeNoPortData
 		log.Logger.Warnw(cr.reason)
 		return cr
 	}
@@ -463,6 +457,7 @@ func (c *component) Check() components.CheckResult {
 			// Set unhealthy state when we can't retrieve event history
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error getting ib flap/drop events"
+			cr.reasonCode = ReasonCodePortStoreError
 			cr.err = err
 			return cr
 		}
@@ -618,6 +613,12 @@ func (c *component) Check() components.CheckResult {
 				}
 
 				cr.health = apiv1.HealthStateTypeUnhealthy
+				// the drops take precedence over the flaps, as the ports are down too long
+				if len(ibDropDevs) > 0 {
+					cr.reasonCode = ReasonCodePortDrop
+				} else {
+					cr.reasonCode = ReasonCodePortFlap
+				}
 				log.Logger.Warnw(cr.reason)
 
 				cr.suggestedActions = &apiv1.SuggestedActions{
@@ -661,6 +662,8 @@ type checkResult struct {
 	suggestedActions *apiv1.SuggestedActions
 	// tracks the reason of the last check
 	reason string
+	// tracks the reason code of the last check
+	reasonCode apiv1.ReasonCode
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -684,6 +687,21 @@ func (cr *checkResult) String() string {
 	return out
 }
 
+// PortRates returns the rate in Gb/sec of each port keyed by "<device>:<port>"
+// (e.g., "mlx5_0:1"), for comparing the scan results.
+func (cr *checkResult) PortRates() map[string]uint64 {
+	if cr == nil {
+		return nil
+	}
+	rates := make(map[string]uint64)
+	for _, dev := range cr.ClassDevices {
+		for _, port := range dev.Ports {
+			rates[fmt.Sprintf("%s:%d", dev.Name, port.Port)] = uint64(port.RateGBSec)
+		}
+	}
+	return rates
+}
+
 func (cr *checkResult) Summary() string {
 	if cr == nil {
 		return ""
@@ -735,6 +753,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Name:             Name,
 		Health:           cr.health,
 		Reason:           cr.reason,
+		ReasonCode:       cr.reasonCode,
 		SuggestedActions: cr.getSuggestedActions(),
 		Error:            cr.getError(),
 	}
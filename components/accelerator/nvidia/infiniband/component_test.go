@@ -2508,3 +2508,14 @@ func TestComponentCheckDropEventsProcessedWhenUnhealthy(t *testing.T) {
 	events := mockBucket.GetAPIEvents()
 	assert.Len(t, events, 0)
 }
+
+func TestCheckResultPortRates(t *testing.T) {
+	var cr *checkResult
+	assert.Nil(t, cr.PortRates())
+
+	cr = &checkResult{ClassDevices: infinibandclass.Devices{
+		{Name: "mlx5_0", Ports: []infinibandclass.Port{{Port: 1, RateGBSec: 400}}},
+		{Name: "mlx5_1", Ports: []infinibandclass.Port{{Port: 1, RateGBSec: 200}, {Port: 2, RateGBSec: 400}}},
+	}}
+	assert.Equal(t, map[string]uint64{"mlx5_0:1": 400, "mlx5_1:1": 200, "mlx5_1:2": 400}, cr.PortRates())
+}
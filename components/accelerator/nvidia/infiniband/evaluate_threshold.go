@@ -4,6 +4,7 @@ import (
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components/accelerator/nvidia/infiniband/types"
 	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 var (
@@ -13,6 +14,38 @@ var (
 	reasonNoIbPortIssue = "ok; no infiniband port issue"
 )
 
+const (
+	// ReasonCodeThresholdNotSet is for the ports or rate thresholds not set, thus the evaluation skipped.
+	ReasonCodeThresholdNotSet apiv1.ReasonCode = "IB_THRESHOLD_NOT_SET"
+	// ReasonCodeNoPortData is for no infiniband port found, thus the evaluation skipped.
+	ReasonCodeNoPortData apiv1.ReasonCode = "IB_NO_PORT_DATA"
+	// ReasonCodePortsBelowExpected is for fewer active ports than expected (e.g., ports down or polling).
+	ReasonCodePortsBelowExpected apiv1.ReasonCode = "IB_PORTS_BELOW_EXPECTED"
+	// ReasonCodePortRateBelowExpected is for enough active ports, but some with the rates below expected.
+	ReasonCodePortRateBelowExpected apiv1.ReasonCode = "IB_PORT_RATE_BELOW_EXPECTED"
+	// ReasonCodePortDrop is for the ports down too long.
+	ReasonCodePortDrop apiv1.ReasonCode = "IB_PORT_DROP"
+	// ReasonCodePortFlap is for the ports flapping between ACTIVE and DOWN.
+	ReasonCodePortFlap apiv1.ReasonCode = "IB_PORT_FLAP"
+	// ReasonCodeClassReadError is for the failures reading the infiniband class directory.
+	ReasonCodeClassReadError apiv1.ReasonCode = "IB_CLASS_READ_ERROR"
+	// ReasonCodePortStoreError is for the failures reading or writing the port history.
+	ReasonCodePortStoreError apiv1.ReasonCode = "IB_PORT_STORE_ERROR"
+)
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeThresholdNotSet, Health: apiv1.HealthStateTypeHealthy, Description: "The infiniband ports or rate thresholds are not set, thus the evaluation is skipped."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeNoPortData, Health: apiv1.HealthStateTypeHealthy, Description: "No infiniband port is found, thus the evaluation is skipped."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePortsBelowExpected, Health: apiv1.HealthStateTypeUnhealthy, Description: "Fewer infiniband ports are active than expected (e.g., ports down, disabled, or polling)."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePortRateBelowExpected, Health: apiv1.HealthStateTypeUnhealthy, Description: "Enough infiniband ports are active, but some run below the expected rate."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePortDrop, Health: apiv1.HealthStateTypeUnhealthy, Description: "Infiniband ports have been down too long."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePortFlap, Health: apiv1.HealthStateTypeUnhealthy, Description: "Infiniband ports are flapping between ACTIVE and DOWN."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeClassReadError, Health: apiv1.HealthStateTypeUnhealthy, Description: "Failed to read the infiniband class devices."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePortStoreError, Health: apiv1.HealthStateTypeUnhealthy, Description: "Failed to read or write the infiniband port history."},
+	)
+}
+
 // evaluateHealthStateWithThresholds evaluates the current infiniband port states against the thresholds
 // and it DOES NOT take historical states into account.
 //
@@ -39,6 +72,7 @@ func evaluateHealthStateWithThresholds(thresholds types.ExpectedPortStates, ibpo
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.suggestedActions = nil
 		cr.reason = reasonNoThreshold
+		cr.reasonCode = ReasonCodeThresholdNotSet
 
 		cr.unhealthyIBPorts = nil
 		cr.thresholdsFailing = false
@@ -51,6 +85,7 @@ func evaluateHealthStateWithThresholds(thresholds types.ExpectedPortStates, ibpo
 	if len(ibports) == 0 {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = reasonNoIbPortData
+		cr.reasonCode = ReasonCodeNoPortData
 		log.Logger.Warnw(cr.reason)
 		cr.thresholdsFailing = false
 		return
@@ -64,6 +99,7 @@ func evaluateHealthStateWithThresholds(thresholds types.ExpectedPortStates, ibpo
 		cr.unhealthyIBPorts = unhealthy
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = err.Error()
+		cr.reasonCode = thresholdReasonCode(ibports, atLeastPorts, atLeastRate)
 		cr.thresholdsFailing = true
 
 		// NOTE: do not set suggested actions to "apiv1.RepairActionTypeHardwareInspection" here
@@ -76,7 +112,17 @@ func evaluateHealthStateWithThresholds(thresholds types.ExpectedPortStates, ibpo
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.suggestedActions = nil
 	cr.reason = reasonNoIbPortIssue
+	cr.reasonCode = apiv1.ReasonCodeHealthy
 
 	cr.unhealthyIBPorts = nil
 	cr.thresholdsFailing = false
 }
+
+// thresholdReasonCode returns the reason code of the ports failing the thresholds:
+// the rate code if enough ports are active regardless of the rate, or the ports code otherwise.
+func thresholdReasonCode(ibports []types.IBPort, atLeastPorts int, atLeastRate int) apiv1.ReasonCode {
+	if atLeastRate > 0 && len(checkPortsAndRate(ibports, []string{"LinkUp"}, 0)) >= atLeastPorts {
+		return ReasonCodePortRateBelowExpected
+	}
+	return ReasonCodePortsBelowExpected
+}
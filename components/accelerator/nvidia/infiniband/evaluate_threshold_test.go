@@ -56,6 +56,7 @@ func TestCheckWithEmptyIbportsToEvaluate(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, data.health)
 	assert.Equal(t, reasonNoIbPortData, data.reason)
+	assert.Equal(t, ReasonCodeNoPortData, data.reasonCode)
 }
 
 // TestEvaluateHealthStateWithThresholdsComprehensive tests more edge cases for evaluateHealthStateWithThresholds
@@ -155,3 +156,51 @@ func TestEvaluateHealthStateWithThresholdsComprehensive(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateHealthStateWithThresholdsReasonCode(t *testing.T) {
+	t.Parallel()
+
+	port := func(dev string, physState string, rate int) types.IBPort {
+		return types.IBPort{Device: dev, State: "Active", PhysicalState: physState, RateGBSec: rate, LinkLayer: "Infiniband"}
+	}
+
+	tests := []struct {
+		name       string
+		thresholds types.ExpectedPortStates
+		ports      []types.IBPort
+		want       apiv1.ReasonCode
+	}{
+		{
+			name:       "no threshold",
+			thresholds: types.ExpectedPortStates{},
+			ports:      []types.IBPort{port("mlx5_0", "LinkUp", 400)},
+			want:       ReasonCodeThresholdNotSet,
+		},
+		{
+			name:       "ok",
+			thresholds: types.ExpectedPortStates{AtLeastPorts: 2, AtLeastRate: 400},
+			ports:      []types.IBPort{port("mlx5_0", "LinkUp", 400), port("mlx5_1", "LinkUp", 400)},
+			want:       apiv1.ReasonCodeHealthy,
+		},
+		{
+			name:       "rate below expected",
+			thresholds: types.ExpectedPortStates{AtLeastPorts: 2, AtLeastRate: 400},
+			ports:      []types.IBPort{port("mlx5_0", "LinkUp", 400), port("mlx5_1", "LinkUp", 200)},
+			want:       ReasonCodePortRateBelowExpected,
+		},
+		{
+			name:       "ports below expected",
+			thresholds: types.ExpectedPortStates{AtLeastPorts: 2, AtLeastRate: 400},
+			ports:      []types.IBPort{port("mlx5_0", "LinkUp", 400), port("mlx5_1", "Polling", 400)},
+			want:       ReasonCodePortsBelowExpected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &checkResult{}
+			evaluateHealthStateWithThresholds(tt.thresholds, tt.ports, cr)
+			assert.Equal(t, tt.want, cr.reasonCode)
+			assert.Equal(t, tt.want, cr.HealthStates()[0].ReasonCode)
+		})
+	}
+}
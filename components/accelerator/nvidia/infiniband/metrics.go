@@ -3,6 +3,7 @@ package infiniband
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -29,4 +30,7 @@ func init() {
 	pkgmetrics.MustRegister(
 		metricIbLinkedDowned,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_link_downed", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+	)
 }
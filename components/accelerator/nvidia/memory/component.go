@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	gopsutilmem "github.com/shirou/gopsutil/v4/mem"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -29,6 +28,8 @@ const Name = "accelerator-nvidia-memory"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -77,20 +78,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -155,9 +143,14 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 	productName := c.nvmlInstance.ProductName()
-	for uuid, dev := range devs {
-		mem, err := c.getMemoryFunc(uuid, dev, productName, c.getVirtualMemoryFunc)
+	// query all GPUs in parallel, so the readings are taken at the same time
+	results := nvidianvml.QueryDevices(devs, func(uuid string, dev device.Device) (Memory, error) {
+		return c.getMemoryFunc(uuid, dev, productName, c.getVirtualMemoryFunc)
+	})
+	for _, r := range results {
+		uuid, mem, err := r.UUID, r.Value, r.Err
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
@@ -193,10 +186,10 @@ func (c *component) Check() components.CheckResult {
 
 		cr.Memories = append(cr.Memories, mem)
 
-		metricTotalBytes.With(prometheus.Labels{"uuid": uuid}).Set(float64(mem.TotalBytes))
-		metricReservedBytes.With(prometheus.Labels{"uuid": uuid}).Set(float64(mem.ReservedBytes))
-		metricUsedBytes.With(prometheus.Labels{"uuid": uuid}).Set(float64(mem.UsedBytes))
-		metricFreeBytes.With(prometheus.Labels{"uuid": uuid}).Set(float64(mem.FreeBytes))
+		metricTotalBytes.With(labeler.Labels(uuid)).Set(float64(mem.TotalBytes))
+		metricReservedBytes.With(labeler.Labels(uuid)).Set(float64(mem.ReservedBytes))
+		metricUsedBytes.With(labeler.Labels(uuid)).Set(float64(mem.UsedBytes))
+		metricFreeBytes.With(labeler.Labels(uuid)).Set(float64(mem.FreeBytes))
 
 		usedPct, err := mem.GetUsedPercent()
 		if err != nil {
@@ -206,7 +199,7 @@ func (c *component) Check() components.CheckResult {
 			log.Logger.Warnw(cr.reason, "error", cr.err)
 			return cr
 		}
-		metricUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(usedPct)
+		metricUsedPercent.With(labeler.Labels(uuid)).Set(usedPct)
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
@@ -1,7 +1,9 @@
 package memory
 
 import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -21,7 +23,7 @@ var (
 			Name:      "total_bytes",
 			Help:      "tracks the total memory in bytes",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricReservedBytes = prometheus.NewGaugeVec(
@@ -31,7 +33,7 @@ var (
 			Name:      "reserved_bytes",
 			Help:      "tracks the reserved memory in bytes",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricUsedBytes = prometheus.NewGaugeVec(
@@ -41,7 +43,7 @@ var (
 			Name:      "used_bytes",
 			Help:      "tracks the used memory in bytes",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricFreeBytes = prometheus.NewGaugeVec(
@@ -51,7 +53,7 @@ var (
 			Name:      "free_bytes",
 			Help:      "tracks the free memory in bytes",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricUsedPercent = prometheus.NewGaugeVec(
@@ -61,7 +63,7 @@ var (
 			Name:      "used_percent",
 			Help:      "tracks the percentage of memory used",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -73,4 +75,11 @@ func init() {
 		metricFreeBytes,
 		metricUsedPercent,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_total_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_reserved_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_free_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	)
 }
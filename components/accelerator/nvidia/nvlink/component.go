@@ -8,11 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -21,6 +21,7 @@ import (
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
 )
 
 // Name is the name of the NVIDIA NVLink component.
@@ -29,6 +30,8 @@ const Name = "accelerator-nvidia-nvlink"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -39,6 +42,10 @@ type component struct {
 	getPeerNVLinkP2PStatusFn func(dev device.Device, peer device.Device) (string, error)
 	getThresholdsFunc        func() ExpectedLinkStates
 
+	// tracks the raw throughput counters of the last check
+	// to compute the per-link bandwidth, keyed by GPU UUID and link
+	prevThroughput map[linkKey]throughputSample
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
@@ -61,6 +68,17 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 	return c, nil
 }
 
+type linkKey struct {
+	uuid string
+	link int
+}
+
+type throughputSample struct {
+	ts      time.Time
+	txBytes uint64
+	rxBytes uint64
+}
+
 func (c *component) Name() string { return Name }
 
 func (c *component) Tags() []string {
@@ -76,24 +94,15 @@ func (c *component) IsSupported() bool {
 	if c.nvmlInstance == nil {
 		return false
 	}
+	// the integrated GPUs of Jetson/Tegra have no NVLink
+	if nvidiategra.IsTegra() {
+		return false
+	}
 	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -162,6 +171,7 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 	// Only expect NVLink by default on multi-GPU hosts that advertise NVIDIA
 	// fabric support. This keeps 1-GPU machines safe: for a single GPU,
 	// `nvidia-smi topo -p2p n` legitimately shows only the self entry:
@@ -265,9 +275,10 @@ func (c *component) Check() components.CheckResult {
 			return cr
 		}
 
+		c.setLinkBandwidth(uuid, cr.ts, nvLink.States)
 		cr.NVLinks = append(cr.NVLinks, nvLink)
 
-		labels := prometheus.Labels{"uuid": uuid}
+		labels := labeler.Labels(uuid)
 		if nvLink.Supported {
 			metricSupported.With(labels).Set(1.0)
 		} else {
@@ -288,9 +299,20 @@ func (c *component) Check() components.CheckResult {
 			metricFeatureEnabled.With(labels).Set(0.0)
 			cr.InactiveNVLinkUUIDs = append(cr.InactiveNVLinkUUIDs, uuid)
 		}
-		metricReplayErrors.With(prometheus.Labels{"uuid": uuid}).Set(float64(nvLink.States.TotalReplayErrors()))
-		metricRecoveryErrors.With(prometheus.Labels{"uuid": uuid}).Set(float64(nvLink.States.TotalRecoveryErrors()))
-		metricCRCErrors.With(prometheus.Labels{"uuid": uuid}).Set(float64(nvLink.States.TotalCRCErrors()))
+		metricReplayErrors.With(labels).Set(float64(nvLink.States.TotalReplayErrors()))
+		metricRecoveryErrors.With(labels).Set(float64(nvLink.States.TotalRecoveryErrors()))
+		metricCRCErrors.With(labels).Set(float64(nvLink.States.TotalCRCErrors()))
+
+		for _, st := range nvLink.States {
+			linkLabels := labeler.Labels(uuid)
+			linkLabels["link"] = strconv.Itoa(st.Link)
+			metricLinkReplayErrors.With(linkLabels).Set(float64(st.ReplayErrors))
+			metricLinkRecoveryErrors.With(linkLabels).Set(float64(st.RecoveryErrors))
+			metricLinkCRCErrors.With(linkLabels).Set(float64(st.CRCErrors))
+			metricLinkTxBytesPerSecond.With(linkLabels).Set(st.TxBytesPerSecond)
+			metricLinkRxBytesPerSecond.With(linkLabels).Set(st.RxBytesPerSecond)
+			metricLinkBandwidthUtilization.With(linkLabels).Set(st.BandwidthUtilizationPercent)
+		}
 	}
 
 	if len(cr.ActiveNVLinkUUIDs) > 0 {
@@ -307,10 +329,31 @@ func (c *component) Check() components.CheckResult {
 	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no nvlink issue found", len(devs))
 
 	evaluateHealthStateWithThresholds(cr)
+	evaluateLinkErrorThresholds(cr)
 
 	return cr
 }
 
+// setLinkBandwidth computes the per-link bandwidth from the raw throughput
+// counters of the last check, and records the counters for the next check.
+// Only called from Check, which is not run concurrently.
+func (c *component) setLinkBandwidth(uuid string, now time.Time, states NVLinkStates) {
+	if c.prevThroughput == nil {
+		c.prevThroughput = make(map[linkKey]throughputSample)
+	}
+	for i := range states {
+		key := linkKey{uuid: uuid, link: states[i].Link}
+		if prev, ok := c.prevThroughput[key]; ok {
+			states[i].setBandwidth(prev.txBytes, prev.rxBytes, now.Sub(prev.ts).Seconds())
+		}
+		c.prevThroughput[key] = throughputSample{
+			ts:      now,
+			txBytes: states[i].ThroughputRawTxBytes,
+			rxBytes: states[i].ThroughputRawRxBytes,
+		}
+	}
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
@@ -359,6 +402,10 @@ type checkResult struct {
 	// configured.
 	SystemExpectedNVLink bool `json:"system_expected_nvlink,omitempty"`
 
+	// LinkErrorThresholdViolations lists the links exceeding the per-link
+	// error thresholds in ExpectedLinkStates (e.g., "GPU-xxx link 3 (crc_errors=120>100)").
+	LinkErrorThresholdViolations []string `json:"link_error_threshold_violations,omitempty"`
+
 	// timestamp of the last check
 	ts time.Time
 	// error from the last check
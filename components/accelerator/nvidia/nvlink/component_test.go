@@ -16,7 +16,9 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
@@ -253,6 +255,55 @@ func TestCheckOnce_Success(t *testing.T) {
 	assert.Equal(t, nvLink, lastCheckResult.NVLinks[0])
 }
 
+func TestCheckOnce_LinkBandwidthAndErrorThresholds(t *testing.T) {
+	ctx := context.Background()
+
+	uuid := "gpu-uuid-123"
+	mockDev := testutil.NewMockDevice(&mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return uuid, nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	getDevicesFunc := func() map[string]device.Device {
+		return map[string]device.Device{uuid: mockDev}
+	}
+
+	txBytes := uint64(1_000_000_000)
+	crcErrors := uint64(0)
+	getNVLinkFunc := func(_ string, _ device.Device) (NVLink, error) {
+		return NVLink{
+			UUID:      uuid,
+			Supported: true,
+			States: NVLinkStates{
+				{Link: 0, FeatureEnabled: true, CRCErrors: crcErrors, ThroughputRawTxBytes: txBytes, SpeedMBps: 50_000},
+			},
+		}, nil
+	}
+
+	c := mustComponent(t, MockNVLinkComponent(ctx, getDevicesFunc, getNVLinkFunc))
+	now := time.Unix(1000, 0).UTC()
+	c.getTimeNowFunc = func() time.Time { return now }
+	c.getThresholdsFunc = func() ExpectedLinkStates {
+		return ExpectedLinkStates{MaxCRCErrorsPerLink: 100}
+	}
+
+	// first check has no previous sample to compute the bandwidth
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+	require.Len(t, cr.NVLinks, 1)
+	assert.Zero(t, cr.NVLinks[0].States[0].TxBytesPerSecond)
+
+	now = now.Add(10 * time.Second)
+	txBytes += 5_000_000_000
+	crcErrors = 101
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+	assert.Equal(t, []string{"gpu-uuid-123 link 0 (crc_errors=101>100)"}, cr.LinkErrorThresholdViolations)
+	st := cr.NVLinks[0].States[0]
+	assert.InDelta(t, 5e8, st.TxBytesPerSecond, 0.001)
+	assert.InDelta(t, 1.0, st.BandwidthUtilizationPercent, 0.001)
+}
+
 func TestCheckOnce_NVLinkError(t *testing.T) {
 	ctx := context.Background()
 
@@ -1325,7 +1376,7 @@ func TestCheck_UnsupportedNVLinkClearsPerGPUMetrics(t *testing.T) {
 	}
 
 	component.Check()
-	labels := prometheus.Labels{"uuid": uuid}
+	labels := prometheus.Labels{nvidianvml.LabelGPUUUID: uuid, nvidianvml.LabelGPUIndex: "0"}
 	assert.Equal(t, float64(1), readGaugeValue(t, metricSupported.With(labels)))
 	assert.Equal(t, float64(1), readGaugeValue(t, metricFeatureEnabled.With(labels)))
 	assert.Equal(t, float64(7), readGaugeValue(t, metricReplayErrors.With(labels)))
@@ -1617,3 +1668,7 @@ func TestCheck_A100SXM4_8GPU_UnsupportedNVLinkButP2POK(t *testing.T) {
 		"should NOT be unhealthy when P2P probes confirm NVLink is working")
 	assert.Equal(t, "all 8 GPU(s) were checked, no nvlink issue found", cr.reason)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
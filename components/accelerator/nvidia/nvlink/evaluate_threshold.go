@@ -186,3 +186,45 @@ func evaluateHealthStateWithThresholds(cr *checkResult) {
 	cr.reason = appendNVLinkFailureDetails(cr.reason, cr)
 	setNVLinkSuggestedActions(cr)
 }
+
+// evaluateLinkErrorThresholds marks the check result as degraded when any link
+// has more replay, recovery, or CRC errors than the configured per-link thresholds.
+// A single flaky NVLink lane degrades the collective performance long before
+// any SXid appears, while the GPU level totals hide which link is failing.
+// It only downgrades a healthy result, so the unhealthy evaluations take precedence.
+func evaluateLinkErrorThresholds(cr *checkResult) {
+	if cr == nil || cr.health != apiv1.HealthStateTypeHealthy {
+		return
+	}
+	if cr.ExpectedLinkStates == nil || !cr.ExpectedLinkStates.HasLinkErrorThresholds() {
+		return
+	}
+
+	thresholds := cr.ExpectedLinkStates
+	var violations []string
+	for _, nvLink := range cr.NVLinks {
+		for _, st := range nvLink.States {
+			var exceeded []string
+			if thresholds.MaxReplayErrorsPerLink > 0 && st.ReplayErrors > thresholds.MaxReplayErrorsPerLink {
+				exceeded = append(exceeded, fmt.Sprintf("replay_errors=%d>%d", st.ReplayErrors, thresholds.MaxReplayErrorsPerLink))
+			}
+			if thresholds.MaxRecoveryErrorsPerLink > 0 && st.RecoveryErrors > thresholds.MaxRecoveryErrorsPerLink {
+				exceeded = append(exceeded, fmt.Sprintf("recovery_errors=%d>%d", st.RecoveryErrors, thresholds.MaxRecoveryErrorsPerLink))
+			}
+			if thresholds.MaxCRCErrorsPerLink > 0 && st.CRCErrors > thresholds.MaxCRCErrorsPerLink {
+				exceeded = append(exceeded, fmt.Sprintf("crc_errors=%d>%d", st.CRCErrors, thresholds.MaxCRCErrorsPerLink))
+			}
+			if len(exceeded) > 0 {
+				violations = append(violations, fmt.Sprintf("%s link %d (%s)", nvLink.UUID, st.Link, strings.Join(exceeded, ",")))
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	cr.LinkErrorThresholdViolations = violations
+	cr.health = apiv1.HealthStateTypeDegraded
+	cr.reason = fmt.Sprintf("nvlink error threshold exceeded on %d link(s): %s", len(violations), strings.Join(violations, "; "))
+	log.Logger.Warnw("nvlink error threshold exceeded", "violations", violations)
+}
@@ -489,3 +489,66 @@ func TestEvaluateThresholds_PartialDegradationFailsWhenThresholdConfigured(t *te
 	assert.Contains(t, cr.reason, "require >=2 GPUs with all links active; got 1")
 	assert.Contains(t, cr.reason, "inactive nvlinks=GPU-1")
 }
+
+func TestEvaluateLinkErrorThresholds(t *testing.T) {
+	newResult := func(thresholds *ExpectedLinkStates) *checkResult {
+		return &checkResult{
+			NVLinks: []NVLink{
+				{UUID: "GPU-0", Supported: true, States: NVLinkStates{
+					{Link: 0, FeatureEnabled: true, CRCErrors: 5},
+					{Link: 1, FeatureEnabled: true, CRCErrors: 150, ReplayErrors: 20},
+				}},
+				{UUID: "GPU-1", Supported: true, States: NVLinkStates{
+					{Link: 0, FeatureEnabled: true, RecoveryErrors: 3},
+				}},
+			},
+			ExpectedLinkStates: thresholds,
+			health:             apiv1.HealthStateTypeHealthy,
+			reason:             "existing reason",
+		}
+	}
+
+	t.Run("thresholds not set", func(t *testing.T) {
+		cr := newResult(&ExpectedLinkStates{AtLeastGPUsWithAllLinksFeatureEnabled: 2})
+		evaluateLinkErrorThresholds(cr)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+		assert.Equal(t, "existing reason", cr.reason)
+		assert.Empty(t, cr.LinkErrorThresholdViolations)
+	})
+
+	t.Run("within thresholds", func(t *testing.T) {
+		cr := newResult(&ExpectedLinkStates{MaxCRCErrorsPerLink: 1000, MaxReplayErrorsPerLink: 100, MaxRecoveryErrorsPerLink: 10})
+		evaluateLinkErrorThresholds(cr)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
+		assert.Equal(t, "existing reason", cr.reason)
+	})
+
+	t.Run("single flaky link", func(t *testing.T) {
+		cr := newResult(&ExpectedLinkStates{MaxCRCErrorsPerLink: 100, MaxReplayErrorsPerLink: 10})
+		evaluateLinkErrorThresholds(cr)
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+		require.Len(t, cr.LinkErrorThresholdViolations, 1)
+		assert.Equal(t, "GPU-0 link 1 (replay_errors=20>10,crc_errors=150>100)", cr.LinkErrorThresholdViolations[0])
+		assert.Contains(t, cr.reason, "nvlink error threshold exceeded on 1 link(s)")
+	})
+
+	t.Run("recovery errors on another gpu", func(t *testing.T) {
+		cr := newResult(&ExpectedLinkStates{MaxCRCErrorsPerLink: 100, MaxRecoveryErrorsPerLink: 1})
+		evaluateLinkErrorThresholds(cr)
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.health)
+		assert.Len(t, cr.LinkErrorThresholdViolations, 2)
+	})
+
+	t.Run("unhealthy result is kept", func(t *testing.T) {
+		cr := newResult(&ExpectedLinkStates{MaxCRCErrorsPerLink: 100})
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "nvlink threshold violated"
+		evaluateLinkErrorThresholds(cr)
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.health)
+		assert.Equal(t, "nvlink threshold violated", cr.reason)
+	})
+
+	t.Run("nil check result", func(t *testing.T) {
+		evaluateLinkErrorThresholds(nil)
+	})
+}
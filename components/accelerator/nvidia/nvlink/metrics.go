@@ -3,7 +3,9 @@ package nvlink
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA NVLink component.
@@ -21,7 +23,7 @@ var (
 			Name:      "supported",
 			Help:      "tracks whether NVLink is supported per GPU",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricFeatureEnabled = prometheus.NewGaugeVec(
@@ -31,7 +33,7 @@ var (
 			Name:      "feature_enabled",
 			Help:      "tracks the NVLink feature enabled (aggregated for all links per GPU)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricReplayErrors = prometheus.NewGaugeVec(
@@ -41,7 +43,7 @@ var (
 			Name:      "replay_errors",
 			Help:      "tracks the replay errors in NVLink (aggregated for all links per GPU)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricRecoveryErrors = prometheus.NewGaugeVec(
@@ -51,7 +53,7 @@ var (
 			Name:      "recovery_errors",
 			Help:      "tracks the recovery errors in NVLink (aggregated for all links per GPU)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricCRCErrors = prometheus.NewGaugeVec(
@@ -61,7 +63,67 @@ var (
 			Name:      "crc_errors",
 			Help:      "tracks the CRC errors in NVLink (aggregated for all links per GPU)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricLinkReplayErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_replay_errors",
+			Help:      "tracks the replay errors in NVLink per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
+	).MustCurryWith(componentLabel)
+
+	metricLinkRecoveryErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_recovery_errors",
+			Help:      "tracks the recovery errors in NVLink per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
+	).MustCurryWith(componentLabel)
+
+	metricLinkCRCErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_crc_errors",
+			Help:      "tracks the CRC errors in NVLink per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
+	).MustCurryWith(componentLabel)
+
+	metricLinkTxBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_tx_bytes_per_second",
+			Help:      "tracks the NVLink TX throughput (data + protocol overhead) per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
+	).MustCurryWith(componentLabel)
+
+	metricLinkRxBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_rx_bytes_per_second",
+			Help:      "tracks the NVLink RX throughput (data + protocol overhead) per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
+	).MustCurryWith(componentLabel)
+
+	metricLinkBandwidthUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_bandwidth_utilization_percent",
+			Help:      "tracks the NVLink bandwidth utilization in percent of the link speed per link",
+		},
+		nvidianvml.GPUMetricLabelKeys("link"), // labels are GPU UUID, index and link number
 	).MustCurryWith(componentLabel)
 )
 
@@ -72,5 +134,24 @@ func init() {
 		metricReplayErrors,
 		metricRecoveryErrors,
 		metricCRCErrors,
+		metricLinkReplayErrors,
+		metricLinkRecoveryErrors,
+		metricLinkCRCErrors,
+		metricLinkTxBytesPerSecond,
+		metricLinkRxBytesPerSecond,
+		metricLinkBandwidthUtilization,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_supported", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_feature_enabled", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_replay_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_recovery_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_crc_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_replay_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_recovery_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_crc_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_tx_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_rx_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_bandwidth_utilization_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
 	)
 }
@@ -1,6 +1,9 @@
 package nvlink
 
 import (
+	"encoding/binary"
+	"math"
+
 	"github.com/leptonai/gpud/pkg/log"
 
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
@@ -87,6 +90,33 @@ type NVLinkState struct {
 	ThroughputRawTxBytes uint64 `json:"throughput_raw_tx_bytes"`
 	// ThroughputRawRxBytes is the NVLink RX Data throughput + protocol overhead in bytes.
 	ThroughputRawRxBytes uint64 `json:"throughput_raw_rx_bytes"`
+
+	// SpeedMBps is the link speed in MB/s, zero if not reported by the device.
+	SpeedMBps uint64 `json:"speed_mbps,omitempty"`
+
+	// TxBytesPerSecond is the TX throughput since the last check.
+	TxBytesPerSecond float64 `json:"tx_bytes_per_second,omitempty"`
+	// RxBytesPerSecond is the RX throughput since the last check.
+	RxBytesPerSecond float64 `json:"rx_bytes_per_second,omitempty"`
+	// BandwidthUtilizationPercent is the higher of the TX and RX throughput
+	// relative to the link speed, zero if the link speed is unknown.
+	BandwidthUtilizationPercent float64 `json:"bandwidth_utilization_percent,omitempty"`
+}
+
+// setBandwidth sets the throughput and the bandwidth utilization
+// from the raw throughput counters of the previous check.
+// No-op if the counters were reset (e.g., driver reload) or no time has elapsed.
+func (st *NVLinkState) setBandwidth(prevTxBytes uint64, prevRxBytes uint64, elapsedSeconds float64) {
+	if elapsedSeconds <= 0 || st.ThroughputRawTxBytes < prevTxBytes || st.ThroughputRawRxBytes < prevRxBytes {
+		return
+	}
+
+	st.TxBytesPerSecond = float64(st.ThroughputRawTxBytes-prevTxBytes) / elapsedSeconds
+	st.RxBytesPerSecond = float64(st.ThroughputRawRxBytes-prevRxBytes) / elapsedSeconds
+	if st.SpeedMBps > 0 {
+		speedBytesPerSecond := float64(st.SpeedMBps) * 1000 * 1000
+		st.BandwidthUtilizationPercent = math.Max(st.TxBytesPerSecond, st.RxBytesPerSecond) / speedBytesPerSecond * 100
+	}
 }
 
 // GetNVLink queries the NVLink information for a device.
@@ -157,6 +187,8 @@ func GetNVLink(uuid string, dev device.Device) (NVLink, error) {
 			nvlinkState.CRCErrors = crcErrors
 		}
 
+		setLinkFieldValues(dev, link, &nvlinkState)
+
 		// TODO
 		// nvmlDeviceGetNvLinkRemotePciInfo_v2
 		// ref. https://docs.nvidia.com/deploy/nvml-api/group__NvLink.html#group__NvLink_1gee01cb84cd8a08f08ddaec36cd9e62ff
@@ -166,3 +198,53 @@ func GetNVLink(uuid string, dev device.Device) (NVLink, error) {
 
 	return nvlink, nil
 }
+
+// setLinkFieldValues reads the raw throughput counters and the speed of the link
+// using the NVML field values, which are scoped per link.
+// The fields not supported by the device are left as zero.
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlFieldValueQueries.html
+func setLinkFieldValues(dev device.Device, link int, st *NVLinkState) {
+	values := []nvml.FieldValue{
+		{FieldId: nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_TX, ScopeId: uint32(link)},
+		{FieldId: nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_RX, ScopeId: uint32(link)},
+		{FieldId: nvml.FI_DEV_NVLINK_GET_SPEED, ScopeId: uint32(link)},
+	}
+	if ret := nvml.DeviceGetFieldValues(dev, values); ret != nvml.SUCCESS {
+		log.Logger.Debugw("failed to get nvlink field values", "link", link, "error", nvml.ErrorString(ret))
+		return
+	}
+
+	for _, v := range values {
+		if nvml.Return(v.NvmlReturn) != nvml.SUCCESS {
+			continue
+		}
+		switch v.FieldId {
+		case nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_TX:
+			// reported in KiB
+			st.ThroughputRawTxBytes = fieldValueUint64(v) * 1024
+		case nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_RX:
+			// reported in KiB
+			st.ThroughputRawRxBytes = fieldValueUint64(v) * 1024
+		case nvml.FI_DEV_NVLINK_GET_SPEED:
+			st.SpeedMBps = fieldValueUint64(v)
+		}
+	}
+}
+
+// fieldValueUint64 decodes the NVML field value as an unsigned integer.
+func fieldValueUint64(v nvml.FieldValue) uint64 {
+	switch nvml.ValueType(v.ValueType) {
+	case nvml.VALUE_TYPE_UNSIGNED_INT:
+		return uint64(binary.LittleEndian.Uint32(v.Value[:4]))
+	case nvml.VALUE_TYPE_UNSIGNED_SHORT:
+		return uint64(binary.LittleEndian.Uint16(v.Value[:2]))
+	case nvml.VALUE_TYPE_DOUBLE:
+		f := math.Float64frombits(binary.LittleEndian.Uint64(v.Value[:]))
+		if f < 0 {
+			return 0
+		}
+		return uint64(f)
+	default:
+		return binary.LittleEndian.Uint64(v.Value[:])
+	}
+}
@@ -1,7 +1,9 @@
 package nvlink
 
 import (
+	"encoding/binary"
 	"errors"
+	"math"
 	"testing"
 
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
@@ -45,6 +47,10 @@ func (m *mockDevice) GetNvLinkErrorCounter(_ int, counter nvml.NvLinkErrorCounte
 	}
 }
 
+func (m *mockDevice) GetFieldValues(_ []nvml.FieldValue) nvml.Return {
+	return m.fieldValuesErr
+}
+
 func (m *mockDevice) PCIBusID() string {
 	return m.busID
 }
@@ -281,3 +287,88 @@ func TestGetNVLink(t *testing.T) {
 		})
 	}
 }
+
+func TestSetLinkFieldValues(t *testing.T) {
+	origDeviceGetFieldValues := nvml.DeviceGetFieldValues
+	defer func() {
+		nvml.DeviceGetFieldValues = origDeviceGetFieldValues
+	}()
+
+	putUint64 := func(v uint64) [8]byte {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], v)
+		return b
+	}
+
+	var gotScopes []uint32
+	nvml.DeviceGetFieldValues = func(_ nvml.Device, values []nvml.FieldValue) nvml.Return {
+		for i := range values {
+			gotScopes = append(gotScopes, values[i].ScopeId)
+			switch values[i].FieldId {
+			case nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_TX:
+				values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+				values[i].Value = putUint64(2)
+			case nvml.FI_DEV_NVLINK_THROUGHPUT_RAW_RX:
+				values[i].ValueType = uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG)
+				values[i].Value = putUint64(3)
+			case nvml.FI_DEV_NVLINK_GET_SPEED:
+				// not supported by this link
+				values[i].NvmlReturn = uint32(nvml.ERROR_NOT_SUPPORTED)
+			}
+		}
+		return nvml.SUCCESS
+	}
+
+	st := NVLinkState{Link: 5}
+	setLinkFieldValues(&mockDevice{}, 5, &st)
+	assert.Equal(t, []uint32{5, 5, 5}, gotScopes)
+	assert.Equal(t, uint64(2048), st.ThroughputRawTxBytes)
+	assert.Equal(t, uint64(3072), st.ThroughputRawRxBytes)
+	assert.Zero(t, st.SpeedMBps)
+
+	// failed query leaves the fields as zero
+	nvml.DeviceGetFieldValues = func(_ nvml.Device, _ []nvml.FieldValue) nvml.Return {
+		return nvml.ERROR_NOT_SUPPORTED
+	}
+	st = NVLinkState{Link: 0}
+	setLinkFieldValues(&mockDevice{}, 0, &st)
+	assert.Zero(t, st.ThroughputRawTxBytes)
+	assert.Zero(t, st.ThroughputRawRxBytes)
+}
+
+func TestFieldValueUint64(t *testing.T) {
+	var b [8]byte
+	binary.LittleEndian.PutUint32(b[:4], 50000)
+	assert.Equal(t, uint64(50000), fieldValueUint64(nvml.FieldValue{ValueType: uint32(nvml.VALUE_TYPE_UNSIGNED_INT), Value: b}))
+
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(12.7))
+	assert.Equal(t, uint64(12), fieldValueUint64(nvml.FieldValue{ValueType: uint32(nvml.VALUE_TYPE_DOUBLE), Value: b}))
+
+	binary.LittleEndian.PutUint64(b[:], 1<<40)
+	assert.Equal(t, uint64(1<<40), fieldValueUint64(nvml.FieldValue{ValueType: uint32(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG), Value: b}))
+}
+
+func TestNVLinkStateSetBandwidth(t *testing.T) {
+	st := NVLinkState{
+		ThroughputRawTxBytes: 3_000_000_000,
+		ThroughputRawRxBytes: 1_000_000_000,
+		SpeedMBps:            50_000,
+	}
+	st.setBandwidth(1_000_000_000, 500_000_000, 2)
+	assert.InDelta(t, 1e9, st.TxBytesPerSecond, 0.001)
+	assert.InDelta(t, 2.5e8, st.RxBytesPerSecond, 0.001)
+	// 1 GB/s out of 50 GB/s
+	assert.InDelta(t, 2.0, st.BandwidthUtilizationPercent, 0.001)
+
+	// counter reset
+	st = NVLinkState{ThroughputRawTxBytes: 10, ThroughputRawRxBytes: 10, SpeedMBps: 50_000}
+	st.setBandwidth(100, 0, 2)
+	assert.Zero(t, st.TxBytesPerSecond)
+	assert.Zero(t, st.BandwidthUtilizationPercent)
+
+	// unknown link speed
+	st = NVLinkState{ThroughputRawTxBytes: 100, ThroughputRawRxBytes: 100}
+	st.setBandwidth(0, 0, 1)
+	assert.InDelta(t, 100, st.TxBytesPerSecond, 0.001)
+	assert.Zero(t, st.BandwidthUtilizationPercent)
+}
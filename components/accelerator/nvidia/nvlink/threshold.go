@@ -17,6 +17,18 @@ type ExpectedLinkStates struct {
 	// - (e.g., nvidia-smi returns "Unable to retrieve NVLink information as all links are inActive")
 	// e.g., if set to 8 and one GPU has some nvlinks feature disabled, it will be considered as unhealthy.
 	AtLeastGPUsWithAllLinksFeatureEnabled int `json:"at_least_gpus_with_all_links_feature_enabled"`
+
+	// MaxReplayErrorsPerLink is the maximum number of replay errors on a single link.
+	// A single flaky link degrades the collective performance long before any SXid,
+	// so a link exceeding the threshold marks the component as degraded.
+	// Zero disables the check.
+	MaxReplayErrorsPerLink uint64 `json:"max_replay_errors_per_link,omitempty"`
+	// MaxRecoveryErrorsPerLink is the maximum number of recovery errors on a single link.
+	// Zero disables the check.
+	MaxRecoveryErrorsPerLink uint64 `json:"max_recovery_errors_per_link,omitempty"`
+	// MaxCRCErrorsPerLink is the maximum number of CRC errors on a single link.
+	// Zero disables the check.
+	MaxCRCErrorsPerLink uint64 `json:"max_crc_errors_per_link,omitempty"`
 }
 
 var (
@@ -42,7 +54,12 @@ func SetDefaultExpectedLinkStates(states ExpectedLinkStates) {
 		states.AtLeastGPUsWithAllLinksFeatureEnabled = 0
 	}
 
-	log.Logger.Infow("setting default expected link states", "at_least_gpus_with_all_links_feature_enabled", states.AtLeastGPUsWithAllLinksFeatureEnabled)
+	log.Logger.Infow("setting default expected link states",
+		"at_least_gpus_with_all_links_feature_enabled", states.AtLeastGPUsWithAllLinksFeatureEnabled,
+		"max_replay_errors_per_link", states.MaxReplayErrorsPerLink,
+		"max_recovery_errors_per_link", states.MaxRecoveryErrorsPerLink,
+		"max_crc_errors_per_link", states.MaxCRCErrorsPerLink,
+	)
 
 	defaultExpectedLinkStatesMu.Lock()
 	defer defaultExpectedLinkStatesMu.Unlock()
@@ -55,3 +72,8 @@ func SetDefaultExpectedLinkStates(states ExpectedLinkStates) {
 func (s ExpectedLinkStates) IsZero() bool {
 	return s.AtLeastGPUsWithAllLinksFeatureEnabled <= 0
 }
+
+// HasLinkErrorThresholds returns true if any of the per-link error thresholds is set.
+func (s ExpectedLinkStates) HasLinkErrorThresholds() bool {
+	return s.MaxReplayErrorsPerLink > 0 || s.MaxRecoveryErrorsPerLink > 0 || s.MaxCRCErrorsPerLink > 0
+}
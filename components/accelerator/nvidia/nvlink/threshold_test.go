@@ -196,3 +196,14 @@ func TestSetDefaultExpectedLinkStates_NegativeValues(t *testing.T) {
 		})
 	}
 }
+
+func TestExpectedLinkStates_HasLinkErrorThresholds(t *testing.T) {
+	assert.False(t, ExpectedLinkStates{}.HasLinkErrorThresholds())
+	assert.False(t, ExpectedLinkStates{AtLeastGPUsWithAllLinksFeatureEnabled: 8}.HasLinkErrorThresholds())
+	assert.True(t, ExpectedLinkStates{MaxReplayErrorsPerLink: 1}.HasLinkErrorThresholds())
+	assert.True(t, ExpectedLinkStates{MaxRecoveryErrorsPerLink: 1}.HasLinkErrorThresholds())
+	assert.True(t, ExpectedLinkStates{MaxCRCErrorsPerLink: 1}.HasLinkErrorThresholds())
+
+	// per-link thresholds do not count as the GPU count threshold
+	assert.True(t, ExpectedLinkStates{MaxCRCErrorsPerLink: 1}.IsZero())
+}
@@ -0,0 +1,329 @@
+// Package pcie tracks the PCIe link generation and width of the NVIDIA GPUs against
+// the device maximum and the expected link (e.g., Gen5 x16), to find the downtrained links
+// (e.g., a loose riser, a damaged slot), which routinely survive the reboots unnoticed.
+package pcie
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
+)
+
+// Name is the ID of the NVIDIA PCIe component.
+const Name = "accelerator-nvidia-pcie"
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+	getConfigFunc  func() Config
+
+	nvmlInstance nvidianvml.Instance
+	getLinkFunc  func(uuid string, dev device.Device) (Link, error)
+	// identifySlotFunc sets the physical slot and the upstream port of the GPU link
+	identifySlotFunc func(link *Link)
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the NVIDIA PCIe component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: GetDefaultConfig,
+		nvmlInstance:  gpudInstance.NVMLInstance,
+		getLinkFunc:   GetLink,
+		identifySlotFunc: func(link *Link) {
+			identifySlot(sysfsPCIDevicesDir, readSlots(sysfsPCISlotsDir), link)
+		},
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	// the integrated GPUs of Jetson/Tegra are not attached over PCIe
+	if nvidiategra.IsTegra() {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia gpu pcie links")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if err := c.nvmlInstance.InitError(); err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("NVML initialization error: %v", err)
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	cfg := c.getConfigFunc()
+
+	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
+	uuids := make([]string, 0, len(devs))
+	for uuid := range devs {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	for _, uuid := range uuids {
+		link, err := c.getLinkFunc(uuid, devs[uuid])
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error getting pcie link"
+
+			for _, target := range []error{nvmlerrors.ErrGPURequiresReset, nvmlerrors.ErrGPULost} {
+				if errors.Is(err, target) {
+					cr.reason = target.Error()
+					cr.suggestedActions = &apiv1.SuggestedActions{
+						Description: target.Error(),
+						RepairActions: []apiv1.RepairActionType{
+							apiv1.RepairActionTypeRebootSystem,
+						},
+					}
+				}
+			}
+
+			log.Logger.Warnw(cr.reason, "uuid", uuid, "error", cr.err)
+			return cr
+		}
+
+		evaluate(cfg, &link)
+		if link.Downtrained() && c.identifySlotFunc != nil {
+			c.identifySlotFunc(&link)
+		}
+		cr.Links = append(cr.Links, link)
+
+		labels := labeler.Labels(uuid)
+		metricLinkGeneration.With(labels).Set(float64(link.CurrentGeneration))
+		metricLinkWidth.With(labels).Set(float64(link.CurrentWidth))
+		if link.Downtrained() {
+			metricLinkDowntrained.With(labels).Set(1)
+		} else {
+			metricLinkDowntrained.With(labels).Set(0)
+		}
+	}
+
+	var issues []string
+	for _, link := range cr.Links {
+		if !link.Downtrained() {
+			continue
+		}
+		issues = append(issues, link.identity()+": "+strings.Join(link.Issues, ", "))
+		log.Logger.Warnw("pcie link downtrained", "uuid", link.UUID, "busID", link.BusID, "slot", link.Slot, "upstreamPort", link.UpstreamPort, "issues", link.Issues)
+	}
+
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("pcie link downtrained on %d GPU(s) (%s)", len(issues), strings.Join(issues, "; "))
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "the PCIe link of the GPU(s) trained below the device maximum or the expected generation and width, limiting the host to GPU bandwidth -- reseat the GPU and the riser in the slot, and compare the link status with 'lspci -vv' (LnkSta against LnkCap)",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeHardwareInspection,
+			},
+		}
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no pcie link downtraining found", len(cr.Links))
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Links is the PCIe links of the GPUs.
+	Links []Link `json:"links,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Links) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "Bus ID", "P-State", "Current", "Max", "Downtrained"})
+	for _, link := range cr.Links {
+		table.Append([]string{
+			link.UUID,
+			link.BusID,
+			link.PerformanceState,
+			fmt.Sprintf("Gen%d x%d", link.CurrentGeneration, link.CurrentWidth),
+			fmt.Sprintf("Gen%d x%d", link.MaxGeneration, link.MaxWidth),
+			fmt.Sprintf("%t", link.Downtrained()),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		SuggestedActions: cr.suggestedActions,
+		Error:            cr.getError(),
+		Health:           cr.health,
+	}
+
+	if len(cr.Links) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
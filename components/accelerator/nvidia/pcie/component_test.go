@@ -0,0 +1,175 @@
+package pcie
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+)
+
+type mockNVMLInstance struct {
+	devs        map[string]device.Device
+	productName string
+	initErr     error
+}
+
+func (m *mockNVMLInstance) NVMLExists() bool                  { return true }
+func (m *mockNVMLInstance) Library() lib.Library              { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devs }
+func (m *mockNVMLInstance) ProductName() string               { return m.productName }
+func (m *mockNVMLInstance) Architecture() string              { return "" }
+func (m *mockNVMLInstance) Brand() string                     { return "" }
+func (m *mockNVMLInstance) DriverVersion() string             { return "" }
+func (m *mockNVMLInstance) DriverMajor() int                  { return 0 }
+func (m *mockNVMLInstance) CUDAVersion() string               { return "" }
+func (m *mockNVMLInstance) FabricManagerSupported() bool      { return false }
+func (m *mockNVMLInstance) FabricStateSupported() bool        { return false }
+func (m *mockNVMLInstance) Shutdown() error                   { return nil }
+func (m *mockNVMLInstance) InitError() error                  { return m.initErr }
+func (m *mockNVMLInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+
+// createMockPCIeComponent creates a component reading the links of the mocked GPUs,
+// identifying all the GPUs on the same slot.
+func createMockPCIeComponent(ctx context.Context, links map[string]Link, cfg Config) *component {
+	devs := make(map[string]device.Device, len(links))
+	for uuid := range links {
+		devs[uuid] = nil
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:    cctx,
+		cancel: cancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: func() Config {
+			return cfg
+		},
+		nvmlInstance: &mockNVMLInstance{devs: devs, productName: "NVIDIA H100"},
+		getLinkFunc: func(uuid string, _ device.Device) (Link, error) {
+			link, ok := links[uuid]
+			if !ok {
+				return Link{}, fmt.Errorf("unknown gpu %s", uuid)
+			}
+			return link, nil
+		},
+		identifySlotFunc: func(link *Link) {
+			link.Slot = "3"
+			link.UpstreamPort = "0000:18:00.0"
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockNVMLInstance{productName: "NVIDIA H100"},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+}
+
+func TestComponentBasics(t *testing.T) {
+	c := createMockPCIeComponent(context.Background(), nil, Config{})
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+
+	evs, err := c.Events(context.Background(), c.getTimeNowFunc())
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+}
+
+func TestCheckHealthy(t *testing.T) {
+	c := createMockPCIeComponent(context.Background(), map[string]Link{
+		"gpu-0": {UUID: "gpu-0", BusID: "0000:19:00.0", PerformanceState: "P0", CurrentGeneration: 5, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+		// idle, thus the generation not compared
+		"gpu-1": {UUID: "gpu-1", BusID: "0000:3b:00.0", PerformanceState: "P8", CurrentGeneration: 1, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+	}, Config{ExpectedGeneration: 5, ExpectedWidth: 16})
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "all 2 GPU(s) were checked, no pcie link downtraining found", cr.Summary())
+	assert.Contains(t, cr.String(), "Gen5 x16")
+	for _, link := range cr.Links {
+		assert.Empty(t, link.Slot, "slot only identified for the downtrained links")
+	}
+}
+
+func TestCheckDowntrained(t *testing.T) {
+	c := createMockPCIeComponent(context.Background(), map[string]Link{
+		"gpu-0": {UUID: "gpu-0", BusID: "0000:19:00.0", PerformanceState: "P0", CurrentGeneration: 3, MaxGeneration: 5, CurrentWidth: 8, MaxWidth: 16},
+		"gpu-1": {UUID: "gpu-1", BusID: "0000:3b:00.0", PerformanceState: "P0", CurrentGeneration: 5, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+	}, Config{})
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "pcie link downtrained on 1 GPU(s) (gpu-0 (0000:19:00.0, slot 3, upstream port 0000:18:00.0): Gen3 below the device max Gen5, x8 below the device max x16)", cr.Summary())
+	require.NotNil(t, cr.suggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, cr.suggestedActions.RepairActions)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	require.Len(t, decoded.Links, 2)
+	assert.Equal(t, "3", decoded.Links[0].Slot)
+	assert.Len(t, decoded.Links[0].Issues, 2)
+}
+
+func TestCheckExpected(t *testing.T) {
+	// a Gen4 slot capping the Gen4 device, where Gen5 is declared
+	c := createMockPCIeComponent(context.Background(), map[string]Link{
+		"gpu-0": {UUID: "gpu-0", BusID: "0000:19:00.0", CurrentGeneration: 4, MaxGeneration: 4, CurrentWidth: 16, MaxWidth: 16},
+	}, Config{ExpectedGeneration: 5, ExpectedWidth: 16})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "Gen4 below the expected Gen5")
+}
+
+func TestCheckError(t *testing.T) {
+	c := createMockPCIeComponent(context.Background(), map[string]Link{"gpu-0": {}}, Config{})
+	defer c.Close()
+	c.getLinkFunc = func(string, device.Device) (Link, error) {
+		return Link{}, nvmlerrors.ErrGPULost
+	}
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, nvmlerrors.ErrGPULost.Error(), cr.Summary())
+
+	c.nvmlInstance = &mockNVMLInstance{initErr: fmt.Errorf("init failed")}
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "init failed")
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,57 @@
+package pcie
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// MaxGeneration is the latest PCIe generation.
+const MaxGeneration = 6
+
+// Config declares the PCIe link the GPUs are expected to train to in the slots (e.g., Gen5 x16),
+// in addition to the device maximum reported by NVML.
+type Config struct {
+	// ExpectedGeneration is the expected PCIe generation of the GPU links (e.g., 5 for Gen5).
+	// Zero to only compare against the device maximum.
+	ExpectedGeneration int `json:"expected_generation,omitempty"`
+	// ExpectedWidth is the expected number of the PCIe lanes of the GPU links (e.g., 16 for x16).
+	// Zero to only compare against the device maximum.
+	ExpectedWidth int `json:"expected_width,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.ExpectedGeneration < 0 || cfg.ExpectedGeneration > MaxGeneration {
+		return fmt.Errorf("expected_generation must be in [1, %d], got %d", MaxGeneration, cfg.ExpectedGeneration)
+	}
+	switch cfg.ExpectedWidth {
+	case 0, 1, 2, 4, 8, 16, 32:
+	default:
+		return fmt.Errorf("expected_width must be one of 1, 2, 4, 8, 16, or 32, got %d", cfg.ExpectedWidth)
+	}
+	return nil
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default PCIe link config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default PCIe link config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default pcie link config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
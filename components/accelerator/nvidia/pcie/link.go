@@ -0,0 +1,215 @@
+package pcie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+const (
+	// sysfsPCIDevicesDir is the sysfs directory of the PCI devices.
+	sysfsPCIDevicesDir = "/sys/bus/pci/devices"
+	// sysfsPCISlotsDir is the sysfs directory of the physical PCI slots.
+	sysfsPCISlotsDir = "/sys/bus/pci/slots"
+)
+
+// Link is the PCIe link of a GPU.
+type Link struct {
+	UUID  string `json:"uuid"`
+	BusID string `json:"bus_id"`
+
+	// Slot is the physical slot of the GPU, or of the nearest upstream bridge
+	// (e.g., the riser or the PCIe switch), empty if not reported by the firmware.
+	Slot string `json:"slot,omitempty"`
+	// UpstreamPort is the bus ID of the bridge port the GPU is attached to
+	// (e.g., the root port, the riser or the PCIe switch downstream port).
+	UpstreamPort string `json:"upstream_port,omitempty"`
+
+	// PerformanceState is the GPU performance state (e.g., "P0"), empty if not supported.
+	// The idle GPUs lower the link generation to save power, thus the generation is
+	// only compared at P0 (or if the performance state is not supported).
+	PerformanceState string `json:"performance_state,omitempty"`
+
+	CurrentGeneration int `json:"current_generation"`
+	MaxGeneration     int `json:"max_generation"`
+	CurrentWidth      int `json:"current_width"`
+	MaxWidth          int `json:"max_width"`
+
+	// Issues is the downtrained link generation and width, empty if none.
+	Issues []string `json:"issues,omitempty"`
+}
+
+// Downtrained returns true if the link trained below the device maximum or the expectation.
+func (l Link) Downtrained() bool {
+	return len(l.Issues) > 0
+}
+
+// identity returns the GPU with its slot and upstream port, for example,
+// "GPU-a (0000:19:00.0, slot 3, upstream port 0000:18:00.0)".
+func (l Link) identity() string {
+	ids := []string{l.BusID}
+	if l.Slot != "" {
+		ids = append(ids, "slot "+l.Slot)
+	}
+	if l.UpstreamPort != "" {
+		ids = append(ids, "upstream port "+l.UpstreamPort)
+	}
+	return fmt.Sprintf("%s (%s)", l.UUID, strings.Join(ids, ", "))
+}
+
+// GetLink returns the current and maximum PCIe link generation and width of the device.
+func GetLink(uuid string, dev device.Device) (Link, error) {
+	link := Link{
+		UUID:  uuid,
+		BusID: dev.PCIBusID(),
+	}
+
+	var ret nvml.Return
+	read := func(name string, fn func() (int, nvml.Return), v *int) error {
+		*v, ret = fn()
+		if nvmlerrors.IsGPULostError(ret) {
+			return nvmlerrors.ErrGPULost
+		}
+		if nvmlerrors.IsGPURequiresReset(ret) {
+			return nvmlerrors.ErrGPURequiresReset
+		}
+		if ret != nvml.SUCCESS {
+			return fmt.Errorf("failed to get %s: %s", name, nvml.ErrorString(ret))
+		}
+		return nil
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+	if err := read("current pcie link generation", dev.GetCurrPcieLinkGeneration, &link.CurrentGeneration); err != nil {
+		return Link{}, err
+	}
+	if err := read("current pcie link width", dev.GetCurrPcieLinkWidth, &link.CurrentWidth); err != nil {
+		return Link{}, err
+	}
+	if err := read("max pcie link width", dev.GetMaxPcieLinkWidth, &link.MaxWidth); err != nil {
+		return Link{}, err
+	}
+
+	// "nvmlDeviceGetMaxPcieLinkGeneration" is capped by the slot (e.g., Gen3 for a Gen5 GPU in a Gen3 slot),
+	// thus hides the downtraining to the slot generation, where the device maximum is not capped
+	if err := read("gpu max pcie link generation", dev.GetGpuMaxPcieLinkGeneration, &link.MaxGeneration); err != nil {
+		if !nvmlerrors.IsNotSupportError(ret) {
+			return Link{}, err
+		}
+		if err := read("max pcie link generation", dev.GetMaxPcieLinkGeneration, &link.MaxGeneration); err != nil {
+			return Link{}, err
+		}
+	}
+
+	pstate, ret := dev.GetPerformanceState()
+	if ret == nvml.SUCCESS && pstate != nvml.PSTATE_UNKNOWN {
+		link.PerformanceState = fmt.Sprintf("P%d", pstate)
+	}
+
+	return link, nil
+}
+
+// evaluate sets the link issues against the device maximum and the expected generation and width,
+// where the expectation is only compared if not already below the device maximum.
+func evaluate(cfg Config, link *Link) {
+	link.Issues = nil
+
+	if link.PerformanceState == "" || link.PerformanceState == "P0" {
+		switch {
+		case link.MaxGeneration > 0 && link.CurrentGeneration < link.MaxGeneration:
+			link.Issues = append(link.Issues, fmt.Sprintf("Gen%d below the device max Gen%d", link.CurrentGeneration, link.MaxGeneration))
+		case cfg.ExpectedGeneration > 0 && link.CurrentGeneration < cfg.ExpectedGeneration:
+			link.Issues = append(link.Issues, fmt.Sprintf("Gen%d below the expected Gen%d", link.CurrentGeneration, cfg.ExpectedGeneration))
+		}
+	}
+
+	switch {
+	case link.MaxWidth > 0 && link.CurrentWidth < link.MaxWidth:
+		link.Issues = append(link.Issues, fmt.Sprintf("x%d below the device max x%d", link.CurrentWidth, link.MaxWidth))
+	case cfg.ExpectedWidth > 0 && link.CurrentWidth < cfg.ExpectedWidth:
+		link.Issues = append(link.Issues, fmt.Sprintf("x%d below the expected x%d", link.CurrentWidth, cfg.ExpectedWidth))
+	}
+}
+
+// slotAddressRegex matches the physical slot address in sysfs (e.g., "0000:19:00", without the function).
+var slotAddressRegex = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}$`)
+
+// pciBusIDRegex matches the PCI bus ID in the sysfs device path (e.g., "0000:19:00.0").
+var pciBusIDRegex = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// readSlots returns the physical slot names by the slot addresses (e.g., "0000:19:00" to "3").
+func readSlots(slotsDir string) map[string]string {
+	entries, err := os.ReadDir(slotsDir)
+	if err != nil {
+		return nil
+	}
+	slots := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(slotsDir, entry.Name(), "address"))
+		if err != nil {
+			continue
+		}
+		addr := strings.ToLower(strings.TrimSpace(string(b)))
+		if slotAddressRegex.MatchString(addr) {
+			slots[addr] = entry.Name()
+		}
+	}
+	return slots
+}
+
+// identifySlot sets the physical slot and the upstream port of the GPU,
+// from the sysfs device path of the bridges between the root complex and the GPU
+// (e.g., "/sys/devices/pci0000:16/0000:16:02.0/0000:17:00.0/0000:18:00.0/0000:19:00.0").
+func identifySlot(devicesDir string, slots map[string]string, link *Link) {
+	busID := normalizeBusID(link.BusID)
+	if busID == "" {
+		return
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(devicesDir, busID))
+	if err != nil {
+		return
+	}
+
+	var chain []string
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if pciBusIDRegex.MatchString(elem) {
+			chain = append(chain, elem)
+		}
+	}
+	if len(chain) == 0 || chain[len(chain)-1] != busID {
+		return
+	}
+	if len(chain) > 1 {
+		link.UpstreamPort = chain[len(chain)-2]
+	}
+
+	// the slot is reported for the device in the slot, which is the upstream PCIe switch or the riser
+	// if the GPU is not directly plugged into the slot
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr := chain[i][:strings.LastIndex(chain[i], ".")]
+		if slot, ok := slots[addr]; ok {
+			link.Slot = slot
+			return
+		}
+	}
+}
+
+// normalizeBusID normalizes the NVML PCI bus ID to the sysfs format
+// (e.g., "00000000:19:00.0" to "0000:19:00.0").
+func normalizeBusID(busID string) string {
+	busID = strings.ToLower(strings.TrimSpace(busID))
+	if len(busID) == len("00000000:19:00.0") && strings.HasPrefix(busID, "0000") {
+		busID = busID[4:]
+	}
+	if !pciBusIDRegex.MatchString(busID) {
+		return ""
+	}
+	return busID
+}
@@ -0,0 +1,157 @@
+package pcie
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func newMockDevice(curGen, curWidth, maxGen, maxWidth int, gpuMaxGenRet nvml.Return, pstate nvml.Pstates) *testutil.MockDevice {
+	return testutil.NewMockDevice(&mock.Device{
+		GetCurrPcieLinkGenerationFunc: func() (int, nvml.Return) { return curGen, nvml.SUCCESS },
+		GetCurrPcieLinkWidthFunc:      func() (int, nvml.Return) { return curWidth, nvml.SUCCESS },
+		GetMaxPcieLinkWidthFunc:       func() (int, nvml.Return) { return maxWidth, nvml.SUCCESS },
+		GetGpuMaxPcieLinkGenerationFunc: func() (int, nvml.Return) {
+			return maxGen, gpuMaxGenRet
+		},
+		GetMaxPcieLinkGenerationFunc: func() (int, nvml.Return) { return maxGen - 1, nvml.SUCCESS },
+		GetPerformanceStateFunc: func() (nvml.Pstates, nvml.Return) {
+			return pstate, nvml.SUCCESS
+		},
+	}, "hopper", "nvidia", "9.0", "00000000:19:00.0")
+}
+
+func TestGetLink(t *testing.T) {
+	link, err := GetLink("gpu-0", newMockDevice(3, 16, 5, 16, nvml.SUCCESS, nvml.PSTATE_0))
+	require.NoError(t, err)
+	assert.Equal(t, Link{
+		UUID:              "gpu-0",
+		BusID:             "00000000:19:00.0",
+		PerformanceState:  "P0",
+		CurrentGeneration: 3,
+		MaxGeneration:     5,
+		CurrentWidth:      16,
+		MaxWidth:          16,
+	}, link)
+
+	// falls back to the max generation capped by the slot
+	link, err = GetLink("gpu-0", newMockDevice(4, 16, 5, 16, nvml.ERROR_NOT_SUPPORTED, nvml.PSTATE_UNKNOWN))
+	require.NoError(t, err)
+	assert.Equal(t, 4, link.MaxGeneration)
+	assert.Empty(t, link.PerformanceState)
+
+	dev := newMockDevice(4, 16, 5, 16, nvml.SUCCESS, nvml.PSTATE_0)
+	dev.GetCurrPcieLinkWidthFunc = func() (int, nvml.Return) { return 0, nvml.ERROR_GPU_IS_LOST }
+	_, err = GetLink("gpu-0", dev)
+	assert.ErrorIs(t, err, nvmlerrors.ErrGPULost)
+
+	dev.GetCurrPcieLinkWidthFunc = func() (int, nvml.Return) { return 0, nvml.ERROR_UNKNOWN }
+	_, err = GetLink("gpu-0", dev)
+	assert.ErrorContains(t, err, "failed to get current pcie link width")
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		link   Link
+		issues []string
+	}{
+		{
+			name: "at max",
+			cfg:  Config{ExpectedGeneration: 5, ExpectedWidth: 16},
+			link: Link{PerformanceState: "P0", CurrentGeneration: 5, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+		},
+		{
+			name:   "generation below device max",
+			link:   Link{PerformanceState: "P0", CurrentGeneration: 3, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+			issues: []string{"Gen3 below the device max Gen5"},
+		},
+		{
+			name: "idle generation not compared",
+			cfg:  Config{ExpectedGeneration: 5},
+			link: Link{PerformanceState: "P8", CurrentGeneration: 1, MaxGeneration: 5, CurrentWidth: 16, MaxWidth: 16},
+		},
+		{
+			name:   "width below device max at idle",
+			link:   Link{PerformanceState: "P8", CurrentGeneration: 1, MaxGeneration: 5, CurrentWidth: 8, MaxWidth: 16},
+			issues: []string{"x8 below the device max x16"},
+		},
+		{
+			name:   "below expected",
+			cfg:    Config{ExpectedGeneration: 5, ExpectedWidth: 16},
+			link:   Link{CurrentGeneration: 4, MaxGeneration: 4, CurrentWidth: 8, MaxWidth: 8},
+			issues: []string{"Gen4 below the expected Gen5", "x8 below the expected x16"},
+		},
+		{
+			name:   "device max reported once",
+			cfg:    Config{ExpectedGeneration: 5, ExpectedWidth: 16},
+			link:   Link{PerformanceState: "P0", CurrentGeneration: 3, MaxGeneration: 5, CurrentWidth: 4, MaxWidth: 16},
+			issues: []string{"Gen3 below the device max Gen5", "x4 below the device max x16"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := tt.link
+			evaluate(tt.cfg, &link)
+			assert.Equal(t, tt.issues, link.Issues)
+			assert.Equal(t, len(tt.issues) > 0, link.Downtrained())
+		})
+	}
+}
+
+func TestIdentifySlot(t *testing.T) {
+	root := t.TempDir()
+
+	// the GPU behind a PCIe switch, in the slot "3" of the switch upstream port
+	gpuDir := filepath.Join(root, "devices", "pci0000:16", "0000:16:02.0", "0000:17:00.0", "0000:18:00.0", "0000:19:00.0")
+	require.NoError(t, os.MkdirAll(gpuDir, 0o755))
+	devicesDir := filepath.Join(root, "bus", "pci", "devices")
+	require.NoError(t, os.MkdirAll(devicesDir, 0o755))
+	require.NoError(t, os.Symlink(gpuDir, filepath.Join(devicesDir, "0000:19:00.0")))
+
+	slotsDir := filepath.Join(root, "bus", "pci", "slots")
+	for name, addr := range map[string]string{"3": "0000:17:00\n", "7": "0000:3b:00\n", "bad": "unknown"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(slotsDir, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(slotsDir, name, "address"), []byte(addr), 0o644))
+	}
+	slots := readSlots(slotsDir)
+	assert.Equal(t, map[string]string{"0000:17:00": "3", "0000:3b:00": "7"}, slots)
+
+	link := Link{UUID: "gpu-0", BusID: "00000000:19:00.0"}
+	identifySlot(devicesDir, slots, &link)
+	assert.Equal(t, "3", link.Slot)
+	assert.Equal(t, "0000:18:00.0", link.UpstreamPort)
+	assert.Equal(t, "gpu-0 (00000000:19:00.0, slot 3, upstream port 0000:18:00.0)", link.identity())
+
+	// not found in sysfs
+	link = Link{UUID: "gpu-1", BusID: "00000000:3b:00.0"}
+	identifySlot(devicesDir, slots, &link)
+	assert.Empty(t, link.Slot)
+	assert.Empty(t, link.UpstreamPort)
+	assert.Equal(t, "gpu-1 (00000000:3b:00.0)", link.identity())
+
+	assert.Nil(t, readSlots(filepath.Join(root, "missing")))
+}
+
+func TestNormalizeBusID(t *testing.T) {
+	assert.Equal(t, "0000:3b:00.0", normalizeBusID("00000000:3B:00.0"))
+	assert.Equal(t, "0000:3b:00.0", normalizeBusID("0000:3b:00.0"))
+	assert.Empty(t, normalizeBusID("invalid"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{ExpectedGeneration: 5, ExpectedWidth: 16}.Validate())
+	assert.Error(t, Config{ExpectedGeneration: -1}.Validate())
+	assert.Error(t, Config{ExpectedGeneration: 7}.Validate())
+	assert.Error(t, Config{ExpectedWidth: 12}.Validate())
+}
@@ -0,0 +1,61 @@
+package pcie
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA PCIe component.
+const SubSystem = "accelerator_nvidia_pcie"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricLinkGeneration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_generation",
+			Help:      "tracks the current PCIe link generation of the GPU",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricLinkWidth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_width",
+			Help:      "tracks the current number of the PCIe link lanes of the GPU",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricLinkDowntrained = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "link_downtrained",
+			Help:      "tracks whether the PCIe link of the GPU trained below the device maximum or the expected generation and width",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricLinkGeneration,
+		metricLinkWidth,
+		metricLinkDowntrained,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_link_generation", Type: apiv1.MetricTypeGauge},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_width", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_link_downtrained", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
+}
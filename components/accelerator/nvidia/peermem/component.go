@@ -18,6 +18,7 @@ import (
 	"github.com/leptonai/gpud/pkg/kmsg"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
 )
 
 // Name is the ID of the NVIDIA peermem component.
@@ -26,6 +27,8 @@ const Name = "accelerator-nvidia-peermem"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -93,24 +96,15 @@ func (c *component) IsSupported() bool {
 	if c.nvmlInstance == nil {
 		return false
 	}
+	// the integrated GPUs of Jetson/Tegra have no PCIe peer memory
+	if nvidiategra.IsTegra() {
+		return false
+	}
 	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -21,6 +21,7 @@ import (
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 // Name is the ID of the NVIDIA persistence mode component.
@@ -36,9 +37,26 @@ const (
 	EventKeyDeviceBusID = "device_bus_id"
 )
 
+const (
+	// ReasonCodePersistenceModeDisabled is for the GPUs with persistence mode disabled.
+	ReasonCodePersistenceModeDisabled apiv1.ReasonCode = "GPU_PERSISTENCE_MODE_DISABLED"
+	// ReasonCodePersistenceModeReadError is for the failures reading the persistence mode.
+	ReasonCodePersistenceModeReadError apiv1.ReasonCode = "GPU_PERSISTENCE_MODE_READ_ERROR"
+)
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePersistenceModeDisabled, Health: apiv1.HealthStateTypeUnhealthy, Description: "Persistence mode is disabled on the GPUs."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePersistenceModeReadError, Health: apiv1.HealthStateTypeUnhealthy, Description: "Failed to read the persistence mode of the GPU."},
+		apiv1.ReasonCodeMetadata{Code: apiv1.ReasonCodeFromEventName(EventNamePersistenceModeDisabled), Event: true, Description: "Persistence mode was detected as disabled on the GPU."},
+	)
+}
+
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -97,20 +115,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), 30*time.Second, c.Check)
 	return nil
 }
 
@@ -193,9 +198,11 @@ func (c *component) Check() components.CheckResult {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "error getting persistence mode"
+			cr.reasonCode = ReasonCodePersistenceModeReadError
 
 			if errors.Is(err, nvmlerrors.ErrGPURequiresReset) {
 				cr.reason = nvmlerrors.ErrGPURequiresReset.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPURequiresReset
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPURequiresReset.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -206,6 +213,7 @@ func (c *component) Check() components.CheckResult {
 
 			if errors.Is(err, nvmlerrors.ErrGPULost) {
 				cr.reason = nvmlerrors.ErrGPULost.Error()
+				cr.reasonCode = apiv1.ReasonCodeGPULost
 				cr.suggestedActions = &apiv1.SuggestedActions{
 					Description: nvmlerrors.ErrGPULost.Error(),
 					RepairActions: []apiv1.RepairActionType{
@@ -265,6 +273,7 @@ func (c *component) Check() components.CheckResult {
 		} else {
 			cr.reason = strings.Join(notEnabled, ", ")
 		}
+		cr.reasonCode = ReasonCodePersistenceModeDisabled
 	} else {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no persistence mode issue found", len(devs))
@@ -289,6 +298,8 @@ type checkResult struct {
 	suggestedActions *apiv1.SuggestedActions
 	// tracks the reason of the last check
 	reason string
+	// tracks the reason code of the last check
+	reasonCode apiv1.ReasonCode
 }
 
 func (cr *checkResult) ComponentName() string {
@@ -350,12 +361,13 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 	}
 
 	state := apiv1.HealthState{
-		Time:      metav1.NewTime(cr.ts),
-		Component: Name,
-		Name:      Name,
-		Reason:    cr.reason,
-		Error:     cr.getError(),
-		Health:    cr.health,
+		Time:       metav1.NewTime(cr.ts),
+		Component:  Name,
+		Name:       Name,
+		Reason:     cr.reason,
+		ReasonCode: cr.reasonCode,
+		Error:      cr.getError(),
+		Health:     cr.health,
 	}
 
 	// propagate suggested actions to health state if present
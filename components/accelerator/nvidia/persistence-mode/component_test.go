@@ -14,6 +14,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
@@ -590,6 +591,7 @@ func TestCheck_EventRecordedOnPersistenceModeDisabled(t *testing.T) {
 	assert.Equal(t, EventNamePersistenceModeDisabled, events[0].Name)
 	assert.Equal(t, uuid, events[0].ExtraInfo[EventKeyDeviceUUID])
 	assert.Equal(t, busID, events[0].ExtraInfo[EventKeyDeviceBusID])
+	contractstest.AssertEvents(t, Name, events...)
 	assert.Contains(t, events[0].Message, uuid)
 	assert.Contains(t, events[0].Message, busID)
 }
@@ -1040,6 +1042,7 @@ func TestPersistenceModeCheck(t *testing.T) {
 		persistenceModes  []PersistenceMode
 		expectedHealth    apiv1.HealthStateType
 		expectedReasonCmp string
+		expectedCode      apiv1.ReasonCode
 	}{
 		{
 			name: "all GPUs have persistence mode supported and enabled",
@@ -1058,6 +1061,7 @@ func TestPersistenceModeCheck(t *testing.T) {
 			},
 			expectedHealth:    apiv1.HealthStateTypeUnhealthy,
 			expectedReasonCmp: "GPU-2 persistence mode supported but not enabled",
+			expectedCode:      ReasonCodePersistenceModeDisabled,
 		},
 		{
 			name: "all GPUs have persistence mode supported but not enabled",
@@ -1067,6 +1071,7 @@ func TestPersistenceModeCheck(t *testing.T) {
 			},
 			expectedHealth:    apiv1.HealthStateTypeUnhealthy,
 			expectedReasonCmp: "all 2 GPU(s) disabled persistence mode",
+			expectedCode:      ReasonCodePersistenceModeDisabled,
 		},
 		{
 			name: "GPU has persistence mode not supported",
@@ -1122,6 +1127,7 @@ func TestPersistenceModeCheck(t *testing.T) {
 			// Verify the result
 			assert.Equal(t, tt.expectedHealth, result.health)
 			assert.Equal(t, tt.expectedReasonCmp, result.reason)
+			assert.Equal(t, tt.expectedCode, result.reasonCode)
 		})
 	}
 }
@@ -1261,3 +1267,7 @@ func TestCheck_GPURequiresResetSuggestedActions(t *testing.T) {
 	assert.NotNil(t, states[0].SuggestedActions)
 	assert.Contains(t, states[0].SuggestedActions.RepairActions, apiv1.RepairActionTypeRebootSystem)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -28,6 +27,8 @@ const Name = "accelerator-nvidia-power"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -74,20 +75,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -152,8 +140,10 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
-	for uuid, dev := range devs {
-		power, err := c.getPowerFunc(uuid, dev)
+	labeler := nvidianvml.NewGPULabeler(devs)
+	// query all GPUs in parallel, so the readings are taken at the same time
+	for _, r := range nvidianvml.QueryDevices(devs, c.getPowerFunc) {
+		uuid, power, err := r.UUID, r.Value, r.Err
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
@@ -184,8 +174,8 @@ func (c *component) Check() components.CheckResult {
 		}
 		cr.Powers = append(cr.Powers, power)
 
-		metricCurrentUsageMilliWatts.With(prometheus.Labels{"uuid": uuid}).Set(float64(power.UsageMilliWatts))
-		metricEnforcedLimitMilliWatts.With(prometheus.Labels{"uuid": uuid}).Set(float64(power.EnforcedLimitMilliWatts))
+		metricCurrentUsageMilliWatts.With(labeler.Labels(uuid)).Set(float64(power.UsageMilliWatts))
+		metricEnforcedLimitMilliWatts.With(labeler.Labels(uuid)).Set(float64(power.EnforcedLimitMilliWatts))
 
 		usedPct, err := power.GetUsedPercent()
 		if err != nil {
@@ -216,7 +206,7 @@ func (c *component) Check() components.CheckResult {
 			log.Logger.Warnw(cr.reason, "error", err)
 			return cr
 		}
-		metricUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(usedPct)
+		metricUsedPercent.With(labeler.Labels(uuid)).Set(usedPct)
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
@@ -3,7 +3,9 @@ package power
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA power component.
@@ -21,7 +23,7 @@ var (
 			Name:      "current_usage_milli_watts",
 			Help:      "tracks the current power in milliwatts",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricEnforcedLimitMilliWatts = prometheus.NewGaugeVec(
@@ -31,7 +33,7 @@ var (
 			Name:      "enforced_limit_milli_watts",
 			Help:      "tracks the enforced power limit in milliwatts",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricUsedPercent = prometheus.NewGaugeVec(
@@ -41,7 +43,7 @@ var (
 			Name:      "used_percent",
 			Help:      "tracks the percentage of power used",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -51,4 +53,9 @@ func init() {
 		metricEnforcedLimitMilliWatts,
 		metricUsedPercent,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_current_usage_milli_watts", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMilliwatts},
+		apiv1.MetricMetadata{Name: SubSystem + "_enforced_limit_milli_watts", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMilliwatts},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	)
 }
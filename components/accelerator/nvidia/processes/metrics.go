@@ -1,7 +1,9 @@
 package processes
 
 import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -21,10 +23,13 @@ var (
 			Name:      "running_total",
 			Help:      "tracks the current per-GPU process counter",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
 func init() {
 	pkgmetrics.MustRegister(metricRunningProcesses)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_running_total", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+	)
 }
@@ -11,28 +11,49 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/log"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 // Name is the ID of the remapped rows component.
 const Name = "accelerator-nvidia-remapped-rows"
 
+const (
+	// ReasonCodeRowRemappingNotSupported is for the GPU product not supporting row remapping.
+	ReasonCodeRowRemappingNotSupported apiv1.ReasonCode = "GPU_ROW_REMAPPING_NOT_SUPPORTED"
+	// ReasonCodeRowRemappingFailed is for the GPU qualifying for RMA (row remapping failed).
+	ReasonCodeRowRemappingFailed apiv1.ReasonCode = "GPU_ROW_REMAPPING_FAILED"
+	// ReasonCodeRowRemappingPending is for the GPU requiring a reset to apply the pending row remapping.
+	ReasonCodeRowRemappingPending apiv1.ReasonCode = "GPU_ROW_REMAPPING_PENDING"
+)
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeRowRemappingNotSupported, Health: apiv1.HealthStateTypeHealthy, Description: "The GPU product does not support row remapping, thus the check is skipped."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeRowRemappingFailed, Health: apiv1.HealthStateTypeUnhealthy, Description: "The GPU row remapping failed, thus the GPU qualifies for RMA."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeRowRemappingPending, Health: apiv1.HealthStateTypeUnhealthy, Description: "The GPU has a pending row remapping, which requires a GPU reset or a reboot."},
+	)
+}
+
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	getTimeNowFunc func() time.Time
+	getTimeNowFunc  func() time.Time
+	getBootTimeFunc func() time.Time
 
 	nvmlInstance        nvidianvml.Instance
 	getRemappedRowsFunc func(uuid string, dev device.Device) (RemappedRows, error)
@@ -40,7 +61,8 @@ type component struct {
 	gpuUUIDsWithRowRemappingPending map[string]any
 	gpuUUIDsWithRowRemappingFailed  map[string]any
 
-	eventBucket eventstore.Bucket
+	eventBucket          eventstore.Bucket
+	pendingRebootTracker *pendingRebootTracker
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -55,6 +77,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		getTimeNowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
+		getBootTimeFunc:                 pkghost.BootTime,
 		nvmlInstance:                    gpudInstance.NVMLInstance,
 		getRemappedRowsFunc:             GetRemappedRows,
 		gpuUUIDsWithRowRemappingPending: make(map[string]any),
@@ -69,6 +92,9 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 			return nil, err
 		}
 	}
+	c.pendingRebootTracker = newPendingRebootTracker(c.eventBucket, func() time.Time {
+		return c.getBootTimeFunc()
+	})
 
 	if gpudInstance != nil && gpudInstance.FailureInjector != nil {
 		for _, uuid := range gpudInstance.FailureInjector.GPUUUIDsWithRowRemappingPending {
@@ -101,20 +127,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -195,12 +208,15 @@ func (c *component) Check() components.CheckResult {
 	if !cr.MemoryErrorManagementCapabilities.RowRemapping {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = fmt.Sprintf("%q does not support row remapping", cr.ProductName)
+		cr.reasonCode = ReasonCodeRowRemappingNotSupported
 		return cr
 	}
 
 	issues := make([]string, 0)
+	qualifiesForRMA := false
 
 	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
 	for uuid, dev := range devs {
 		remappedRows, err := c.getRemappedRowsFunc(uuid, dev)
 		if err != nil {
@@ -211,10 +227,10 @@ func (c *component) Check() components.CheckResult {
 			continue
 		}
 
-		metricUncorrectableErrors.With(prometheus.Labels{"uuid": uuid}).Set(float64(remappedRows.RemappedDueToCorrectableErrors))
+		metricUncorrectableErrors.With(labeler.Labels(uuid)).Set(float64(remappedRows.RemappedDueToCorrectableErrors))
 
 		if remappedRows.RemappingPending {
-			metricRemappingPending.With(prometheus.Labels{"uuid": uuid}).Set(float64(1.0))
+			metricRemappingPending.With(labeler.Labels(uuid)).Set(float64(1.0))
 		} else {
 			if _, ok := c.gpuUUIDsWithRowRemappingPending[uuid]; ok {
 				log.Logger.Warnw("marking row remapping pending to inject failures", "uuid", uuid)
@@ -222,11 +238,11 @@ func (c *component) Check() components.CheckResult {
 			} else {
 				log.Logger.Debugw("row remapping pending", "uuid", uuid)
 			}
-			metricRemappingPending.With(prometheus.Labels{"uuid": uuid}).Set(float64(0.0))
+			metricRemappingPending.With(labeler.Labels(uuid)).Set(float64(0.0))
 		}
 
 		if remappedRows.RemappingFailed {
-			metricRemappingFailed.With(prometheus.Labels{"uuid": uuid}).Set(float64(1.0))
+			metricRemappingFailed.With(labeler.Labels(uuid)).Set(float64(1.0))
 		} else {
 			if _, ok := c.gpuUUIDsWithRowRemappingFailed[uuid]; ok {
 				log.Logger.Warnw("marking row remapping failed to inject failures", "uuid", uuid)
@@ -234,7 +250,7 @@ func (c *component) Check() components.CheckResult {
 			} else {
 				log.Logger.Debugw("row remapping failed", "uuid", uuid)
 			}
-			metricRemappingFailed.With(prometheus.Labels{"uuid": uuid}).Set(float64(0.0))
+			metricRemappingFailed.With(labeler.Labels(uuid)).Set(float64(0.0))
 		}
 
 		cr.RemappedRows = append(cr.RemappedRows, remappedRows)
@@ -251,9 +267,6 @@ func (c *component) Check() components.CheckResult {
 				}
 			}
 
-			// no need to track these as events
-			// because NVML simply returns remapping pending
-			// whenever there's a pending remapping
 		}
 
 		if remappedRows.RemappingFailed {
@@ -272,16 +285,37 @@ func (c *component) Check() components.CheckResult {
 		}
 
 		if remappedRows.QualifiesForRMA() {
+			qualifiesForRMA = true
 			issues = append(issues, fmt.Sprintf("%s qualifies for RMA (row remapping failed, remapped due to %d uncorrectable error(s))", dev.PCIBusID(), remappedRows.RemappedDueToUncorrectableErrors))
 		}
+
+		// track the pending row remapping until a reboot clears it,
+		// as NVML only returns whether the remapping is pending now
+		pending, ok, err := c.pendingRebootTracker.observe(c.ctx, cr.ts, remappedRows)
+		if err != nil {
+			log.Logger.Warnw("failed to track row remapping pending", "uuid", uuid, "error", err)
+		}
+		if ok {
+			cr.PendingReboots = append(cr.PendingReboots, pending)
+		}
+
 		if remappedRows.RequiresReset() {
-			issues = append(issues, fmt.Sprintf("%s needs reset (detected pending row remapping)", dev.PCIBusID()))
+			if ok {
+				issues = append(issues, fmt.Sprintf("%s needs reset (detected pending row remapping, pending reboot for %s)", dev.PCIBusID(), pending.Age()))
+			} else {
+				issues = append(issues, fmt.Sprintf("%s needs reset (detected pending row remapping)", dev.PCIBusID()))
+			}
 		}
 	}
 
 	if len(issues) > 0 {
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = strings.Join(issues, ", ")
+		// RMA takes precedence over the reset
+		cr.reasonCode = ReasonCodeRowRemappingPending
+		if qualifiesForRMA {
+			cr.reasonCode = ReasonCodeRowRemappingFailed
+		}
 	} else {
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = fmt.Sprintf("%d devices support remapped rows and found no issue", len(devs))
@@ -299,6 +333,8 @@ type checkResult struct {
 	MemoryErrorManagementCapabilities nvidiaproduct.MemoryErrorManagementCapabilities `json:"memory_error_management_capabilities"`
 	// RemappedRows maps from GPU UUID to the remapped rows data.
 	RemappedRows []RemappedRows `json:"remapped_rows,omitempty"`
+	// PendingReboots is the GPUs with the pending row remapping since the first detection in the current boot.
+	PendingReboots []PendingReboot `json:"pending_reboots,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -309,6 +345,8 @@ type checkResult struct {
 	health apiv1.HealthStateType
 	// tracks the reason of the last check
 	reason string
+	// tracks the reason code of the last check
+	reasonCode apiv1.ReasonCode
 
 	// suggested actions
 	suggestedActions *apiv1.SuggestedActions
@@ -384,6 +422,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Component:        Name,
 		Name:             Name,
 		Reason:           cr.reason,
+		ReasonCode:       cr.reasonCode,
 		Error:            cr.getError(),
 		Health:           cr.health,
 		SuggestedActions: cr.suggestedActions,
@@ -19,6 +19,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
@@ -1143,6 +1144,7 @@ func TestStateTransitions(t *testing.T) {
 	require.Len(t, states, 1)
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
 	assert.Contains(t, states[0].Reason, "needs reset")
+	assert.Equal(t, ReasonCodeRowRemappingPending, states[0].ReasonCode)
 	require.NotNil(t, c.lastCheckResult.suggestedActions, "Pending state should have suggestedActions")
 	assert.Equal(t, "row remapping pending requires GPU reset or system reboot", c.lastCheckResult.suggestedActions.Description)
 	require.Len(t, c.lastCheckResult.suggestedActions.RepairActions, 1)
@@ -1168,6 +1170,7 @@ func TestStateTransitions(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
 	assert.Contains(t, states[0].Reason, "qualifies for RMA") // RMA message due to failed
 	assert.Contains(t, states[0].Reason, "needs reset")       // Reset message due to pending
+	assert.Equal(t, ReasonCodeRowRemappingFailed, states[0].ReasonCode)
 	require.NotNil(t, c.lastCheckResult.suggestedActions, "Pending and Failed state should have suggestedActions")
 	assert.Equal(t, "row remapping failure requires hardware inspection", c.lastCheckResult.suggestedActions.Description, "Failed suggestion should take precedence")
 	require.Len(t, c.lastCheckResult.suggestedActions.RepairActions, 1)
@@ -1749,3 +1752,7 @@ func TestNewWithFailureInjector(t *testing.T) {
 	// Verify component properties
 	assert.Equal(t, Name, comp.Name())
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -3,7 +3,9 @@ package remappedrows
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for remapped rows metrics.
@@ -21,7 +23,7 @@ var (
 			Name:      "due_to_uncorrectable_errors",
 			Help:      "tracks the number of rows remapped due to uncorrectable errors",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricRemappingPending = prometheus.NewGaugeVec(
@@ -31,7 +33,7 @@ var (
 			Name:      "remapping_pending",
 			Help:      "set to 1 if this GPU requires a reset to actually remap the row",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricRemappingFailed = prometheus.NewGaugeVec(
@@ -41,7 +43,7 @@ var (
 			Name:      "remapping_failed",
 			Help:      "set to 1 if a remapping has failed in the past",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -51,4 +53,9 @@ func init() {
 		metricRemappingPending,
 		metricRemappingFailed,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_due_to_uncorrectable_errors", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_remapping_pending", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_remapping_failed", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
 }
@@ -0,0 +1,164 @@
+package remappedrows
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// EventNameRowRemappingPending is the event name when a pending row remapping is first detected
+	// (e.g., after Xid 63), which requires a reboot (or a GPU reset) to apply.
+	EventNameRowRemappingPending = "row_remapping_pending"
+	// EventNameRowRemappingPendingCleared is the event name when the pending row remapping
+	// is cleared without a reboot (e.g., by a GPU reset).
+	EventNameRowRemappingPendingCleared = "row_remapping_pending_cleared"
+
+	EventKeyGPUUUID  = "gpu_uuid"
+	EventKeyGPUBusID = "gpu_bus_id"
+)
+
+// PendingReboot is a GPU with the pending row remapping, tracked from the first detection
+// until a reboot clears it.
+type PendingReboot struct {
+	UUID  string `json:"uuid"`
+	BusID string `json:"bus_id"`
+	// DetectedAt is when the pending row remapping was first detected in the current boot.
+	DetectedAt time.Time `json:"detected_at"`
+	// AgeSeconds is the age of the pending row remapping in seconds.
+	AgeSeconds int64 `json:"age_seconds"`
+}
+
+// Age returns the age of the pending row remapping.
+func (p PendingReboot) Age() time.Duration {
+	return time.Duration(p.AgeSeconds) * time.Second
+}
+
+// pendingRebootTracker tracks the GPUs with the pending row remapping since the last boot,
+// persisted as the events so that the first detection time survives the GPUd restarts.
+type pendingRebootTracker struct {
+	bucket          eventstore.Bucket
+	getBootTimeFunc func() time.Time
+
+	mu       sync.Mutex
+	loaded   bool
+	detected map[string]PendingReboot
+}
+
+func newPendingRebootTracker(bucket eventstore.Bucket, getBootTimeFunc func() time.Time) *pendingRebootTracker {
+	return &pendingRebootTracker{
+		bucket:          bucket,
+		getBootTimeFunc: getBootTimeFunc,
+		detected:        make(map[string]PendingReboot),
+	}
+}
+
+// load reads the pending row remapping events since the last boot,
+// where the events before the boot are cleared by the reboot.
+func (t *pendingRebootTracker) load(ctx context.Context) error {
+	if t.loaded || t.bucket == nil {
+		t.loaded = true
+		return nil
+	}
+
+	evs, err := t.bucket.Get(ctx, t.getBootTimeFunc())
+	if err != nil {
+		return err
+	}
+
+	// in the ascending order of time (the oldest event first)
+	for i := len(evs) - 1; i >= 0; i-- {
+		ev := evs[i]
+		uuid := ev.ExtraInfo[EventKeyGPUUUID]
+		if uuid == "" {
+			continue
+		}
+		switch ev.Name {
+		case EventNameRowRemappingPending:
+			if _, ok := t.detected[uuid]; !ok {
+				t.detected[uuid] = PendingReboot{UUID: uuid, BusID: ev.ExtraInfo[EventKeyGPUBusID], DetectedAt: ev.Time.UTC()}
+			}
+		case EventNameRowRemappingPendingCleared:
+			delete(t.detected, uuid)
+		}
+	}
+
+	t.loaded = true
+	return nil
+}
+
+// observe updates the tracked GPU with the remapped rows of the current check,
+// and returns the GPU with the pending row remapping (or false if not pending).
+func (t *pendingRebootTracker) observe(ctx context.Context, now time.Time, row RemappedRows) (PendingReboot, bool, error) {
+	if t == nil {
+		return PendingReboot{}, false, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(ctx); err != nil {
+		return PendingReboot{}, false, err
+	}
+
+	p, tracked := t.detected[row.UUID]
+	switch {
+	case row.RemappingPending && !tracked:
+		p = PendingReboot{UUID: row.UUID, BusID: row.BusID, DetectedAt: now}
+		if err := t.insert(ctx, EventNameRowRemappingPending, apiv1.EventTypeWarning, now, row,
+			fmt.Sprintf("GPU %s (%s) has a pending row remapping since %s, reboot required", row.UUID, row.BusID, now.UTC().Format(time.RFC3339))); err != nil {
+			return PendingReboot{}, false, err
+		}
+		t.detected[row.UUID] = p
+
+	case !row.RemappingPending && tracked:
+		// not pending anymore in the same boot (e.g., GPU reset)
+		if err := t.insert(ctx, EventNameRowRemappingPendingCleared, apiv1.EventTypeInfo, now, row,
+			fmt.Sprintf("GPU %s (%s) pending row remapping cleared without reboot after %s", row.UUID, row.BusID, now.Sub(p.DetectedAt).Truncate(time.Second))); err != nil {
+			return PendingReboot{}, false, err
+		}
+		delete(t.detected, row.UUID)
+		return PendingReboot{}, false, nil
+
+	case !row.RemappingPending:
+		return PendingReboot{}, false, nil
+	}
+
+	p.AgeSeconds = int64(now.Sub(p.DetectedAt).Seconds())
+	return p, true, nil
+}
+
+// reset drops the tracked GPUs, to be reloaded from the events (e.g., after the events are purged).
+func (t *pendingRebootTracker) reset() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.loaded = false
+	t.detected = make(map[string]PendingReboot)
+}
+
+func (t *pendingRebootTracker) insert(ctx context.Context, name string, eventType apiv1.EventType, now time.Time, row RemappedRows, msg string) error {
+	if t.bucket == nil {
+		return nil
+	}
+	log.Logger.Infow("recording row remapping pending event", "name", name, "uuid", row.UUID, "busID", row.BusID)
+	return t.bucket.Insert(ctx, eventstore.Event{
+		Time:    now,
+		Name:    name,
+		Type:    string(eventType),
+		Message: msg,
+		ExtraInfo: map[string]string{
+			EventKeyGPUUUID:  row.UUID,
+			EventKeyGPUBusID: row.BusID,
+		},
+	})
+}
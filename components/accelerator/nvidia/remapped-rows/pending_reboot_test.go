@@ -0,0 +1,205 @@
+package remappedrows
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func newTestPendingRebootBucket(t *testing.T) (eventstore.Store, eventstore.Bucket) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	t.Cleanup(cleanup)
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+	t.Cleanup(bucket.Close)
+	return store, bucket
+}
+
+func TestPendingRebootTracker(t *testing.T) {
+	ctx := context.Background()
+	_, bucket := newTestPendingRebootBucket(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	bootTime := now.Add(-time.Hour)
+	getBootTime := func() time.Time { return bootTime }
+
+	tr := newPendingRebootTracker(bucket, getBootTime)
+
+	pendingRow := RemappedRows{UUID: "GPU-0", BusID: "0000:01:00.0", RemappingPending: true}
+	clearedRow := RemappedRows{UUID: "GPU-0", BusID: "0000:01:00.0"}
+
+	// not pending, nothing tracked
+	_, ok, err := tr.observe(ctx, now, clearedRow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	p, ok, err := tr.observe(ctx, now, pendingRow)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "0000:01:00.0", p.BusID)
+	assert.Equal(t, now, p.DetectedAt)
+	assert.Equal(t, int64(0), p.AgeSeconds)
+
+	// age grows from the first detection
+	p, ok, err = tr.observe(ctx, now.Add(10*time.Minute), pendingRow)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, now, p.DetectedAt)
+	assert.Equal(t, 10*time.Minute, p.Age())
+
+	evs, err := bucket.Get(ctx, bootTime)
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameRowRemappingPending, evs[0].Name)
+	assert.Equal(t, "GPU-0", evs[0].ExtraInfo[EventKeyGPUUUID])
+	contractstest.AssertEvents(t, Name, evs...)
+
+	// the detection time survives the GPUd restart
+	tr2 := newPendingRebootTracker(bucket, getBootTime)
+	p, ok, err = tr2.observe(ctx, now.Add(20*time.Minute), pendingRow)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, now, p.DetectedAt)
+	assert.Equal(t, 20*time.Minute, p.Age())
+
+	// cleared without reboot (e.g., GPU reset)
+	_, ok, err = tr2.observe(ctx, now.Add(30*time.Minute), clearedRow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	evs, err = bucket.Get(ctx, bootTime)
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.Equal(t, EventNameRowRemappingPendingCleared, evs[0].Name)
+
+	tr3 := newPendingRebootTracker(bucket, getBootTime)
+	require.NoError(t, tr3.load(ctx))
+	assert.Empty(t, tr3.detected)
+
+	// detected again after the reset
+	p, ok, err = tr2.observe(ctx, now.Add(40*time.Minute), pendingRow)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(40*time.Minute), p.DetectedAt)
+
+	// the events before the reboot are ignored
+	bootTime = now.Add(time.Hour)
+	tr4 := newPendingRebootTracker(bucket, getBootTime)
+	require.NoError(t, tr4.load(ctx))
+	assert.Empty(t, tr4.detected)
+}
+
+func TestPendingRebootTrackerReset(t *testing.T) {
+	ctx := context.Background()
+	_, bucket := newTestPendingRebootBucket(t)
+
+	now := time.Now().UTC()
+	tr := newPendingRebootTracker(bucket, func() time.Time { return now.Add(-time.Hour) })
+
+	row := RemappedRows{UUID: "GPU-0", RemappingPending: true}
+	_, ok, err := tr.observe(ctx, now, row)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = bucket.Purge(ctx, now.Add(time.Minute).Unix())
+	require.NoError(t, err)
+	tr.reset()
+
+	p, ok, err := tr.observe(ctx, now.Add(5*time.Minute), row)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(5*time.Minute), p.DetectedAt)
+}
+
+func TestPendingRebootTrackerNil(t *testing.T) {
+	var tr *pendingRebootTracker
+	_, ok, err := tr.observe(context.Background(), time.Now(), RemappedRows{UUID: "GPU-0", RemappingPending: true})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	tr.reset()
+
+	// no event bucket, tracked in memory
+	tr = newPendingRebootTracker(nil, time.Now)
+	now := time.Now().UTC()
+	_, ok, err = tr.observe(context.Background(), now, RemappedRows{UUID: "GPU-0", RemappingPending: true})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCheckPendingReboot(t *testing.T) {
+	store, _ := newTestPendingRebootBucket(t)
+
+	mockDev := testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "0000:01:00.0")
+	nvmlInstance := &mockNVMLInstance{
+		getDevicesFunc: func() map[string]device.Device {
+			return map[string]device.Device{"GPU-0": mockDev}
+		},
+		getProductNameFunc: func() string { return "NVIDIA Test GPU" },
+		getMemoryErrorManagementCapabilitiesFunc: func() nvidiaproduct.MemoryErrorManagementCapabilities {
+			return nvidiaproduct.MemoryErrorManagementCapabilities{RowRemapping: true}
+		},
+	}
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: nvmlInstance,
+		EventStore:   store,
+	})
+	require.NoError(t, err)
+	defer func() { _ = comp.Close() }()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	c := mustComponent(t, comp)
+	c.getBootTimeFunc = func() time.Time { return now.Add(-time.Hour) }
+	c.getTimeNowFunc = func() time.Time { return now }
+	c.getRemappedRowsFunc = func(uuid string, dev device.Device) (RemappedRows, error) {
+		return RemappedRows{UUID: uuid, BusID: dev.PCIBusID(), RemappingPending: true}, nil
+	}
+
+	c.Check()
+	c.getTimeNowFunc = func() time.Time { return now.Add(90 * time.Minute) }
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, ReasonCodeRowRemappingPending, cr.reasonCode)
+	assert.Contains(t, cr.Summary(), "needs reset")
+	assert.Contains(t, cr.Summary(), "pending reboot for 1h30m0s")
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	require.Len(t, decoded.PendingReboots, 1)
+	assert.Equal(t, "GPU-0", decoded.PendingReboots[0].UUID)
+	assert.Equal(t, "0000:01:00.0", decoded.PendingReboots[0].BusID)
+	assert.Equal(t, int64(90*60), decoded.PendingReboots[0].AgeSeconds)
+
+	evs, err := c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameRowRemappingPending, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+
+	// set healthy purges the events, and the condition is detected again if still pending
+	c.getTimeNowFunc = func() time.Time { return now.Add(100 * time.Minute) }
+	require.NoError(t, c.SetHealthy())
+	cr = c.Check().(*checkResult)
+	require.Len(t, cr.PendingReboots, 1)
+	assert.Equal(t, now.Add(100*time.Minute), cr.PendingReboots[0].DetectedAt)
+}
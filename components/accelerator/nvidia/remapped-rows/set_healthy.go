@@ -23,6 +23,7 @@ func (c *component) SetHealthy() error {
 		}
 		log.Logger.Infow("successfully purged remapped rows events", "count", purged)
 	}
+	c.pendingRebootTracker.reset()
 
 	return nil
 }
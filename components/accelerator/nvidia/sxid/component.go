@@ -22,6 +22,7 @@ import (
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/kmsg"
 	"github.com/leptonai/gpud/pkg/log"
@@ -41,6 +42,19 @@ const (
 	EventKeyErrorSXidData = "data"
 	// EventKeyDeviceUUID stores the device identifier associated with an SXID event.
 	EventKeyDeviceUUID = "device_uuid"
+	// EventKeyNVSwitch stores the NVSwitch device instance (e.g., "nvidia-nvswitch3").
+	EventKeyNVSwitch = "nvswitch"
+	// EventKeyNVSwitchPort stores the NVSwitch port (link) number.
+	EventKeyNVSwitchPort = "nvswitch_port"
+	// EventKeyNVSwitchSerial stores the NVSwitch serial number.
+	EventKeyNVSwitchSerial = "nvswitch_serial"
+	// EventKeyGPUUUIDs stores the comma-separated UUIDs of the GPUs connected to the NVSwitch port.
+	EventKeyGPUUUIDs = "gpu_uuids"
+	// EventKeyTrunkLink is set to "true" if the NVSwitch port is a trunk link (not connected to any local GPU).
+	EventKeyTrunkLink = "trunk_link"
+
+	// sysfsPCIDevicesDir is the sysfs directory of the PCI devices.
+	sysfsPCIDevicesDir = "/sys/bus/pci/devices"
 
 	// DefaultStateUpdatePeriod is the background SXID state refresh period.
 	DefaultStateUpdatePeriod = 30 * time.Second
@@ -60,9 +74,17 @@ type component struct {
 	eventBucket      eventstore.Bucket
 	kmsgWatcher      kmsg.Watcher
 
+	// resets downgrades the SXids within the GPU reset windows, nil if not set up
+	resets *gpureset.Tracker
+
 	readAllKmsg  func(context.Context) ([]kmsg.Message, error)
 	extraEventCh chan *eventstore.Event
 
+	// getNVSwitchTopologyFunc discovers the NVSwitch ports connected to the GPUs
+	getNVSwitchTopologyFunc func() nvswitchTopology
+	topologyMu              sync.Mutex
+	topology                nvswitchTopology
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 
@@ -83,6 +105,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		},
 
 		rebootEventStore: gpudInstance.RebootEventStore,
+		resets:           gpudInstance.GPUResets,
 
 		extraEventCh: make(chan *eventstore.Event, 256),
 	}
@@ -96,10 +119,12 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
+			// e.g., "/dev/kmsg" not mounted into the container
+			// the component runs without the kmsg watcher (degraded without the kmsg capability)
 			c.kmsgWatcher, err = kmsg.NewWatcher()
 			if err != nil {
-				ccancel()
-				return nil, err
+				log.Logger.Warnw("kmsg not available, running without kmsg watcher", "component", Name, "error", err)
+				c.kmsgWatcher = nil
 			}
 		}
 	}
@@ -108,6 +133,15 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		c.readAllKmsg = kmsg.ReadAll
 	}
 
+	if c.nvmlInstance != nil {
+		c.getNVSwitchTopologyFunc = func() nvswitchTopology {
+			links := getNVSwitchLinks(c.nvmlInstance.Devices())
+			return buildNVSwitchTopology(links, func(busID string) string {
+				return readPCIDeviceSerialNumber(sysfsPCIDevicesDir, busID)
+			})
+		}
+	}
+
 	return c, nil
 }
 
@@ -176,6 +210,8 @@ func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events,
 		return nil, err
 	}
 
+	events = c.applyResetWindows(ctx, events, since)
+
 	var ret apiv1.Events
 	for _, event := range events {
 		ev := resolveSXIDEvent(event)
@@ -267,6 +303,7 @@ func (c *component) Check() components.CheckResult {
 		if sxidErr == nil {
 			continue
 		}
+		c.enrichPortIdentity(sxidErr)
 		cr.FoundErrors = append(cr.FoundErrors, FoundError{
 			Kmsg:  kmsg,
 			Error: *sxidErr,
@@ -437,21 +474,21 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				continue
 			}
 
+			c.enrichPortIdentity(sxidErr)
+
 			id := uuid.New()
 			var sxidName string
 			if sxidErr.Detail != nil {
 				sxidName = sxidErr.Detail.Name
 			}
 			logger := log.Logger.With("id", id, "sxid", sxidErr.SXid, "sxidName", sxidName, "deviceUUID", sxidErr.DeviceUUID)
-			logger.Infow("got sxid event", "kmsg", message, "kmsgTimestamp", message.Timestamp.Unix())
+			logger.Infow("got sxid event", "kmsg", message, "kmsgTimestamp", message.Timestamp.Unix(), "nvswitch", sxidErr.NVSwitch, "portIdentity", sxidErr.PortIdentity)
 
 			event := eventstore.Event{
-				Time: message.Timestamp.Time,
-				Name: EventNameErrorSXid,
-				ExtraInfo: map[string]string{
-					EventKeyErrorSXidData: strconv.FormatInt(int64(sxidErr.SXid), 10),
-					EventKeyDeviceUUID:    sxidErr.DeviceUUID,
-				},
+				Time:      message.Timestamp.Time,
+				Name:      EventNameErrorSXid,
+				ExtraInfo: eventExtraInfo(sxidErr),
+				DedupKey:  message.DedupKey(),
 			}
 			sameEvent, err := c.eventBucket.Find(c.ctx, event)
 			if err != nil {
@@ -475,6 +512,51 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 	}
 }
 
+// enrichPortIdentity sets what the NVSwitch port of the SXid connects to.
+// The NVSwitch port map is discovered on the first port specific SXid
+// (retried until any NVSwitch port is found).
+func (c *component) enrichPortIdentity(sxidErr *Error) {
+	if c.getNVSwitchTopologyFunc == nil || sxidErr == nil || sxidErr.Port == nil {
+		return
+	}
+
+	c.topologyMu.Lock()
+	if len(c.topology) == 0 {
+		c.topology = c.getNVSwitchTopologyFunc()
+	}
+	topo := c.topology
+	c.topologyMu.Unlock()
+
+	topo.enrich(sxidErr)
+}
+
+// eventExtraInfo returns the event extra info of the SXid error,
+// with the NVSwitch port identity if known.
+func eventExtraInfo(sxidErr *Error) map[string]string {
+	extraInfo := map[string]string{
+		EventKeyErrorSXidData: strconv.FormatInt(int64(sxidErr.SXid), 10),
+		EventKeyDeviceUUID:    sxidErr.DeviceUUID,
+	}
+	if sxidErr.NVSwitch != "" {
+		extraInfo[EventKeyNVSwitch] = sxidErr.NVSwitch
+	}
+	if sxidErr.Port != nil {
+		extraInfo[EventKeyNVSwitchPort] = strconv.Itoa(*sxidErr.Port)
+	}
+	if id := sxidErr.PortIdentity; id != nil {
+		if id.NVSwitchSerial != "" {
+			extraInfo[EventKeyNVSwitchSerial] = id.NVSwitchSerial
+		}
+		if len(id.GPUUUIDs) > 0 {
+			extraInfo[EventKeyGPUUUIDs] = strings.Join(id.GPUUUIDs, ",")
+		}
+		if id.TrunkLink {
+			extraInfo[EventKeyTrunkLink] = "true"
+		}
+	}
+	return extraInfo
+}
+
 func (c *component) updateCurrentState() error {
 	if c.rebootEventStore == nil || c.eventBucket == nil {
 		return nil
@@ -494,6 +576,7 @@ func (c *component) updateCurrentState() error {
 		return fmt.Errorf("failed to get all events: %w", err)
 	}
 	localEvents = trimEventsAfterSetHealthy(localEvents)
+	localEvents = c.applyResetWindows(c.ctx, localEvents, now.Add(-GetLookbackPeriod()))
 	events := mergeEvents(rebootEvents, localEvents)
 
 	c.mu.Lock()
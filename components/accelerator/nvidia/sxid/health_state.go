@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -120,6 +121,9 @@ func resolveSXIDEvent(event eventstore.Event) eventstore.Event {
 			}
 			ret.Type = string(detail.EventType)
 			ret.Message = fmt.Sprintf("SXID %d(%s) detected on %s", currSXid, detail.Name, event.ExtraInfo[EventKeyDeviceUUID])
+			if loc := describePortIdentity(event.ExtraInfo); loc != "" {
+				ret.Message += " (" + loc + ")"
+			}
 
 			sxidValue, ok := uint64FromInt(currSXid)
 			if !ok {
@@ -131,8 +135,15 @@ func resolveSXIDEvent(event eventstore.Event) eventstore.Event {
 				DataSource:             "kmsg",
 				DeviceUUID:             event.ExtraInfo[EventKeyDeviceUUID],
 				SXid:                   sxidValue,
+				NVSwitch:               event.ExtraInfo[EventKeyNVSwitch],
+				NVSwitchPort:           event.ExtraInfo[EventKeyNVSwitchPort],
+				NVSwitchSerial:         event.ExtraInfo[EventKeyNVSwitchSerial],
+				TrunkLink:              event.ExtraInfo[EventKeyTrunkLink] == "true",
 				SuggestedActionsByGPUd: detail.SuggestedActionsByGPUd,
 			}
+			if v := event.ExtraInfo[EventKeyGPUUUIDs]; v != "" {
+				sxidErr.GPUUUIDs = strings.Split(v, ",")
+			}
 			raw, _ := json.Marshal(sxidErr)
 
 			ret.ExtraInfo[EventKeyErrorSXidData] = string(raw)
@@ -141,6 +152,32 @@ func resolveSXIDEvent(event eventstore.Event) eventstore.Event {
 	return ret
 }
 
+// describePortIdentity describes the NVSwitch port and what it connects to
+// from the event extra info (e.g., "nvidia-nvswitch3 serial 12-34-56-78-9a-bc-de-f0 port 32 connected to GPU-abc").
+// Returns empty string if the port is unknown.
+func describePortIdentity(extraInfo map[string]string) string {
+	port := extraInfo[EventKeyNVSwitchPort]
+	if port == "" {
+		return ""
+	}
+
+	var parts []string
+	if v := extraInfo[EventKeyNVSwitch]; v != "" {
+		parts = append(parts, v)
+	}
+	if v := extraInfo[EventKeyNVSwitchSerial]; v != "" {
+		parts = append(parts, "serial "+v)
+	}
+	parts = append(parts, "port "+port)
+	switch {
+	case extraInfo[EventKeyGPUUUIDs] != "":
+		parts = append(parts, "connected to "+extraInfo[EventKeyGPUUUIDs])
+	case extraInfo[EventKeyTrunkLink] == "true":
+		parts = append(parts, "trunk link")
+	}
+	return strings.Join(parts, " ")
+}
+
 // sxidErrorEventDetail represents an SXid error from kmsg.
 type sxidErrorEventDetail struct {
 	// Time is the time of the event.
@@ -156,6 +193,17 @@ type sxidErrorEventDetail struct {
 	// The monitoring component can use this SXid to decide its own action.
 	SXid uint64 `json:"sxid"`
 
+	// NVSwitch is the NVSwitch device instance (e.g., "nvidia-nvswitch3").
+	NVSwitch string `json:"nvswitch,omitempty"`
+	// NVSwitchPort is the NVSwitch port (link) number, empty if the SXid is not specific to a port.
+	NVSwitchPort string `json:"nvswitch_port,omitempty"`
+	// NVSwitchSerial is the serial number of the NVSwitch.
+	NVSwitchSerial string `json:"nvswitch_serial,omitempty"`
+	// GPUUUIDs are the GPUs connected to the NVSwitch port.
+	GPUUUIDs []string `json:"gpu_uuids,omitempty"`
+	// TrunkLink is true if the NVSwitch port is a trunk link.
+	TrunkLink bool `json:"trunk_link,omitempty"`
+
 	// SuggestedActionsByGPUd are the suggested actions for the error.
 	SuggestedActionsByGPUd *apiv1.SuggestedActions `json:"suggested_actions_by_gpud,omitempty"`
 }
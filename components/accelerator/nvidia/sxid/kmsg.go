@@ -1,6 +1,7 @@
 package sxid
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 )
@@ -18,11 +19,21 @@ const (
 
 	// RegexNVSwitchSXidDeviceUUID matches NVSwitch SXid messages and captures the PCI device ID.
 	RegexNVSwitchSXidDeviceUUID = `SXid \((PCI:[0-9a-fA-F:\.]+)\)`
+
+	// RegexNVSwitchSXidInstance matches NVSwitch SXid messages and captures the NVSwitch device instance.
+	// e.g., "nvidia-nvswitch3" in "nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, ..."
+	RegexNVSwitchSXidInstance = `(nvidia-nvswitch\d+): SXid`
+
+	// RegexNVSwitchSXidPort matches NVSwitch SXid messages and captures the NVSwitch port (link) number.
+	// e.g., 32 in "SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error"
+	RegexNVSwitchSXidPort = `SXid.*?: \d+, .*?\bLink (\d+)\b`
 )
 
 var (
 	compiledRegexNVSwitchSXidKMessage   = regexp.MustCompile(RegexNVSwitchSXidKMessage)
 	compiledRegexNVSwitchSXidDeviceUUID = regexp.MustCompile(RegexNVSwitchSXidDeviceUUID)
+	compiledRegexNVSwitchSXidInstance   = regexp.MustCompile(RegexNVSwitchSXidInstance)
+	compiledRegexNVSwitchSXidPort       = regexp.MustCompile(RegexNVSwitchSXidPort)
 )
 
 // ExtractNVSwitchSXid extracts the nvidia NVSwitch SXid error code from the kmsg log line.
@@ -46,11 +57,40 @@ func ExtractNVSwitchSXidDeviceUUID(line string) string {
 	return ""
 }
 
+// ExtractNVSwitchSXidInstance extracts the NVSwitch device instance (e.g., "nvidia-nvswitch3") from the kmsg log line.
+// Returns empty string if the instance is not found.
+func ExtractNVSwitchSXidInstance(line string) string {
+	if match := compiledRegexNVSwitchSXidInstance.FindStringSubmatch(line); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// ExtractNVSwitchSXidPort extracts the NVSwitch port (link) number from the kmsg log line.
+// Returns false if the port is not found (e.g., the SXid is not specific to a link).
+func ExtractNVSwitchSXidPort(line string) (int, bool) {
+	if match := compiledRegexNVSwitchSXidPort.FindStringSubmatch(line); match != nil {
+		if port, err := strconv.Atoi(match[1]); err == nil {
+			return port, true
+		}
+	}
+	return 0, false
+}
+
 // Error describes a parsed SXid kernel message.
 type Error struct {
-	SXid       int     `json:"sxid"`
-	DeviceUUID string  `json:"device_uuid"`
-	Detail     *Detail `json:"detail,omitempty"`
+	SXid       int    `json:"sxid"`
+	DeviceUUID string `json:"device_uuid"`
+
+	// NVSwitch is the NVSwitch device instance (e.g., "nvidia-nvswitch3").
+	NVSwitch string `json:"nvswitch,omitempty"`
+	// Port is the NVSwitch port (link) number, nil if the SXid is not specific to a port.
+	Port *int `json:"port,omitempty"`
+	// PortIdentity is what the NVSwitch port connects to,
+	// nil if the port is unknown or not found in the NVLink topology.
+	PortIdentity *PortIdentity `json:"port_identity,omitempty"`
+
+	Detail *Detail `json:"detail,omitempty"`
 }
 
 // Match returns a matching xid error object if found.
@@ -65,9 +105,44 @@ func Match(line string) *Error {
 		return nil
 	}
 	deviceUUID := ExtractNVSwitchSXidDeviceUUID(line)
-	return &Error{
+	sxidErr := &Error{
 		SXid:       extractedID,
 		DeviceUUID: deviceUUID,
+		NVSwitch:   ExtractNVSwitchSXidInstance(line),
 		Detail:     detail,
 	}
+	if port, ok := ExtractNVSwitchSXidPort(line); ok {
+		sxidErr.Port = &port
+	}
+	return sxidErr
+}
+
+// MessageToInject represents a synthetic kernel message snippet and its log priority.
+type MessageToInject struct {
+	Priority string
+	Message  string
+}
+
+// GetMessageToInject returns an example NVSwitch SXid dmesg line for a given SXid.
+// The always fatal SXids are logged with the error priority.
+// If the SXid is not recognized, it returns a generic placeholder.
+func GetMessageToInject(sxid int) MessageToInject {
+	detail, ok := GetDetail(sxid)
+	if !ok {
+		return MessageToInject{
+			Priority: "KERN_WARNING",
+			Message:  fmt.Sprintf("nvidia-nvswitch0: SXid (PCI:0000:05:00.0): %d, Non-fatal, unknown", sxid),
+		}
+	}
+
+	if detail.AlwaysFatal {
+		return MessageToInject{
+			Priority: "KERN_ERR",
+			Message:  fmt.Sprintf("nvidia-nvswitch0: SXid (PCI:0000:05:00.0): %d, Fatal, %s", sxid, detail.Name),
+		}
+	}
+	return MessageToInject{
+		Priority: "KERN_WARNING",
+		Message:  fmt.Sprintf("nvidia-nvswitch0: SXid (PCI:0000:05:00.0): %d, Non-fatal, %s", sxid, detail.Name),
+	}
 }
@@ -168,3 +168,90 @@ func TestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMessageToInject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		sxid         int
+		expectedPrio string
+		fatal        bool
+	}{
+		{name: "known non-fatal SXid", sxid: 12028, expectedPrio: "KERN_WARNING"},
+		{name: "known always fatal SXid", sxid: 22003, expectedPrio: "KERN_ERR", fatal: true},
+		{name: "unknown SXid", sxid: 99999, expectedPrio: "KERN_WARNING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := GetMessageToInject(tt.sxid)
+			if msg.Priority != tt.expectedPrio {
+				t.Errorf("GetMessageToInject(%d).Priority = %q, want %q", tt.sxid, msg.Priority, tt.expectedPrio)
+			}
+
+			// the injected message must round-trip through the kmsg parser
+			if got := ExtractNVSwitchSXid(msg.Message); got != tt.sxid {
+				t.Errorf("ExtractNVSwitchSXid(%q) = %d, want %d", msg.Message, got, tt.sxid)
+			}
+			if got := ExtractNVSwitchSXidDeviceUUID(msg.Message); got != "PCI:0000:05:00.0" {
+				t.Errorf("ExtractNVSwitchSXidDeviceUUID(%q) = %q, want PCI:0000:05:00.0", msg.Message, got)
+			}
+
+			detail, ok := GetDetail(tt.sxid)
+			if ok && detail.AlwaysFatal != tt.fatal {
+				t.Errorf("GetDetail(%d).AlwaysFatal = %v, want %v", tt.sxid, detail.AlwaysFatal, tt.fatal)
+			}
+		})
+	}
+}
+
+func TestExtractNVSwitchSXidInstanceAndPort(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		input            string
+		expectedInstance string
+		expectedPort     int
+		expectedFound    bool
+	}{
+		{
+			name:             "link specific SXid",
+			input:            "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)",
+			expectedInstance: "nvidia-nvswitch3",
+			expectedPort:     32,
+			expectedFound:    true,
+		},
+		{
+			name:             "fatal link SXid",
+			input:            "[131453.740743] nvidia-nvswitch0: SXid (PCI:0000:00:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			expectedInstance: "nvidia-nvswitch0",
+			expectedPort:     30,
+			expectedFound:    true,
+		},
+		{
+			name:             "data payload without link",
+			input:            "[131453.740758] nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Data {0x50610002, 0x10100030}",
+			expectedInstance: "nvidia-nvswitch0",
+		},
+		{
+			name:  "no match",
+			input: "Regular log content without SXid errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractNVSwitchSXidInstance(tt.input); got != tt.expectedInstance {
+				t.Errorf("ExtractNVSwitchSXidInstance(%q) = %q, want %q", tt.input, got, tt.expectedInstance)
+			}
+			port, found := ExtractNVSwitchSXidPort(tt.input)
+			if found != tt.expectedFound || port != tt.expectedPort {
+				t.Errorf("ExtractNVSwitchSXidPort(%q) = %d, %v, want %d, %v", tt.input, port, found, tt.expectedPort, tt.expectedFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package sxid
+
+import (
+	"github.com/leptonai/gpud/pkg/eventmetrics"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+// SubSystem is the Prometheus subsystem name for the SXid metrics.
+const SubSystem = "accelerator_nvidia_sxid"
+
+func init() {
+	eventmetrics.MustRegister(Name, eventmetrics.Bridge{
+		EventName: EventNameErrorSXid,
+		Name:      SubSystem + "_errors_total",
+		Help:      "tracks the SXid error counts per NVSwitch and SXid code",
+		Labels:    []string{"nvswitch", "sxid"},
+		LabelValues: func(ev eventstore.Event) ([]string, bool) {
+			code := ev.ExtraInfo[EventKeyErrorSXidData]
+			if code == "" {
+				return nil, false
+			}
+			return []string{ev.ExtraInfo[EventKeyNVSwitch], code}, true
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package sxid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestApplyResetWindows(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(gpureset.BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	since := now.Add(-time.Hour)
+
+	events := eventstore.Events{
+		createSXidEvent(now.Add(time.Minute), 12028, apiv1.EventTypeFatal, apiv1.RepairActionTypeRebootSystem),
+		createSXidEvent(now.Add(-30*time.Minute), 12028, apiv1.EventTypeFatal, apiv1.RepairActionTypeRebootSystem),
+	}
+
+	// no reset windows set up
+	c := &component{}
+	assert.Equal(t, events, c.applyResetWindows(ctx, events, since))
+
+	resets := gpureset.New(bucket, nil)
+	require.NoError(t, resets.Begin(ctx, gpureset.SourceGPUd, "reboot requested by control plane"))
+
+	c.resets = resets
+	adjusted := c.applyResetWindows(ctx, append(eventstore.Events{}, events...), since)
+	require.Len(t, adjusted, 2)
+	assert.Equal(t, string(apiv1.EventTypeInfo), adjusted[0].Type)
+	assert.Equal(t, "true", adjusted[0].ExtraInfo[gpureset.EventKeyGPUReset])
+	// outside of the window
+	assert.Equal(t, string(apiv1.EventTypeFatal), adjusted[1].Type)
+
+	state := evolveHealthyState(adjusted[:1])
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, state.Health)
+
+	state = evolveHealthyState(adjusted)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, state.Health)
+}
@@ -0,0 +1,210 @@
+package sxid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// PortIdentity is what the NVSwitch port of the SXid connects to,
+// so that the operators know which cable or GPU to inspect.
+type PortIdentity struct {
+	// NVSwitchSerial is the serial number of the NVSwitch
+	// (PCIe device serial number), empty if unknown.
+	NVSwitchSerial string `json:"nvswitch_serial,omitempty"`
+	// GPUUUIDs are the GPUs connected to the port.
+	GPUUUIDs []string `json:"gpu_uuids,omitempty"`
+	// TrunkLink is true if the port is not connected to any local GPU,
+	// which is a trunk link to another NVSwitch (e.g., the other baseboard).
+	TrunkLink bool `json:"trunk_link,omitempty"`
+}
+
+// nvswitchLink is a GPU NVLink connected to an NVSwitch port.
+type nvswitchLink struct {
+	gpuUUID string
+
+	// switchBusID is the normalized PCI bus ID of the NVSwitch (e.g., "0000:05:00.0").
+	switchBusID string
+	// switchPort is the port (link) number on the NVSwitch.
+	switchPort int
+}
+
+// nvswitchTopology maps the NVSwitch ports to the GPUs,
+// keyed by the normalized PCI bus ID of the NVSwitch.
+type nvswitchTopology map[string]*nvswitchPorts
+
+type nvswitchPorts struct {
+	serial string
+	// gpus maps the port to the connected GPU UUIDs
+	gpus map[int][]string
+}
+
+// buildNVSwitchTopology builds the port map from the GPU NVLinks,
+// and reads the NVSwitch serial numbers.
+func buildNVSwitchTopology(links []nvswitchLink, getSerial func(busID string) string) nvswitchTopology {
+	topo := make(nvswitchTopology)
+	for _, l := range links {
+		ports, ok := topo[l.switchBusID]
+		if !ok {
+			ports = &nvswitchPorts{gpus: make(map[int][]string)}
+			if getSerial != nil {
+				ports.serial = getSerial(l.switchBusID)
+			}
+			topo[l.switchBusID] = ports
+		}
+		if !slices.Contains(ports.gpus[l.switchPort], l.gpuUUID) {
+			ports.gpus[l.switchPort] = append(ports.gpus[l.switchPort], l.gpuUUID)
+			sort.Strings(ports.gpus[l.switchPort])
+		}
+	}
+	return topo
+}
+
+// identify returns what the NVSwitch port connects to.
+// It returns nil if the NVSwitch is not found in the topology.
+func (t nvswitchTopology) identify(switchBusID string, port int) *PortIdentity {
+	ports, ok := t[normalizePCIBusID(switchBusID)]
+	if !ok {
+		return nil
+	}
+	id := &PortIdentity{NVSwitchSerial: ports.serial}
+	if gpus, ok := ports.gpus[port]; ok {
+		id.GPUUUIDs = append([]string(nil), gpus...)
+	} else {
+		// the GPU facing ports of this NVSwitch are known,
+		// so the other ports are the trunk links
+		id.TrunkLink = true
+	}
+	return id
+}
+
+// enrich sets the port identity of the SXid error, if the port is known.
+func (t nvswitchTopology) enrich(sxidErr *Error) {
+	if len(t) == 0 || sxidErr == nil || sxidErr.Port == nil || sxidErr.DeviceUUID == "" {
+		return
+	}
+	sxidErr.PortIdentity = t.identify(sxidErr.DeviceUUID, *sxidErr.Port)
+}
+
+// getNVSwitchLinks returns the NVLinks of the GPUs connected to the NVSwitch ports.
+func getNVSwitchLinks(devs map[string]device.Device) []nvswitchLink {
+	var links []nvswitchLink
+	for uuid, dev := range devs {
+		for link := range int(nvml.NVLINK_MAX_LINKS) {
+			remoteType, ret := dev.GetNvLinkRemoteDeviceType(link)
+			if nvmlerrors.IsNotSupportError(ret) {
+				// fewer links than the max (or no NVLink at all)
+				break
+			}
+			if ret != nvml.SUCCESS || remoteType != nvml.NVLINK_DEVICE_TYPE_SWITCH {
+				continue
+			}
+
+			pciInfo, ret := dev.GetNvLinkRemotePciInfo(link)
+			if ret != nvml.SUCCESS {
+				log.Logger.Debugw("failed to get nvlink remote pci info", "uuid", uuid, "link", link, "error", nvml.ErrorString(ret))
+				continue
+			}
+
+			// the link number on the remote device (the NVSwitch port)
+			values := []nvml.FieldValue{{FieldId: nvml.FI_DEV_NVLINK_REMOTE_NVLINK_ID, ScopeId: uint32(link)}}
+			if ret := dev.GetFieldValues(values); ret != nvml.SUCCESS || nvml.Return(values[0].NvmlReturn) != nvml.SUCCESS {
+				log.Logger.Debugw("failed to get nvlink remote link id", "uuid", uuid, "link", link, "error", nvml.ErrorString(ret))
+				continue
+			}
+
+			links = append(links, nvswitchLink{
+				gpuUUID:     uuid,
+				switchBusID: normalizePCIBusID(pciBusIDString(pciInfo.BusId[:])),
+				switchPort:  int(binary.LittleEndian.Uint32(values[0].Value[:4])),
+			})
+		}
+	}
+	return links
+}
+
+// normalizePCIBusID normalizes the PCI bus ID to the "dddd:bb:dd.f" format,
+// both for the kmsg (e.g., "PCI:0000:05:00.0") and the NVML (e.g., "00000000:05:00.0") formats.
+func normalizePCIBusID(id string) string {
+	id = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(id), "PCI:"))
+	parts := strings.Split(id, ":")
+	switch len(parts) {
+	case 2:
+		return "0000:" + id
+	case 3:
+		domain := strings.TrimLeft(parts[0], "0")
+		if len(domain) < 4 {
+			domain = strings.Repeat("0", 4-len(domain)) + domain
+		}
+		return domain + ":" + parts[1] + ":" + parts[2]
+	default:
+		return id
+	}
+}
+
+func pciBusIDString(b []uint8) string {
+	n := 0
+	for n < len(b) && b[n] != 0 {
+		n++
+	}
+	return string(b[:n])
+}
+
+const (
+	// pciExtCapStart is the offset of the first PCIe extended capability in the config space.
+	pciExtCapStart = 0x100
+	// pciExtCapIDDeviceSerialNumber is the PCIe extended capability ID of the device serial number.
+	pciExtCapIDDeviceSerialNumber = 0x0003
+)
+
+// readPCIDeviceSerialNumber reads the PCIe device serial number
+// from the sysfs config space (requires root to read the extended config space),
+// formatted as "lspci -vvv" does (e.g., "12-34-56-78-9a-bc-de-f0").
+// Returns empty string if not found.
+func readPCIDeviceSerialNumber(sysfsDevicesDir string, busID string) string {
+	cfg, err := os.ReadFile(filepath.Join(sysfsDevicesDir, busID, "config"))
+	if err != nil {
+		log.Logger.Debugw("failed to read pci config space", "busID", busID, "error", err)
+		return ""
+	}
+	return parsePCIDeviceSerialNumber(cfg)
+}
+
+func parsePCIDeviceSerialNumber(cfg []byte) string {
+	// walk the extended capability list, bounded in case of a malformed list
+	offset := pciExtCapStart
+	for range 64 {
+		if offset < pciExtCapStart || offset+12 > len(cfg) {
+			return ""
+		}
+		header := binary.LittleEndian.Uint32(cfg[offset : offset+4])
+		if header == 0 || header == 0xffffffff {
+			return ""
+		}
+
+		if header&0xffff == pciExtCapIDDeviceSerialNumber {
+			lower := binary.LittleEndian.Uint32(cfg[offset+4 : offset+8])
+			upper := binary.LittleEndian.Uint32(cfg[offset+8 : offset+12])
+			serial := uint64(upper)<<32 | uint64(lower)
+
+			bs := make([]string, 8)
+			for i := range 8 {
+				bs[i] = fmt.Sprintf("%02x", byte(serial>>(8*(7-i))))
+			}
+			return strings.Join(bs, "-")
+		}
+
+		offset = int(header >> 20)
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package sxid
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+func TestNormalizePCIBusID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "PCI:0000:05:00.0", expected: "0000:05:00.0"},
+		{input: "00000000:05:00.0", expected: "0000:05:00.0"},
+		{input: "0000:A9:00.0", expected: "0000:a9:00.0"},
+		{input: "00000018:00:00.0", expected: "0018:00:00.0"},
+		{input: "05:00.0", expected: "0000:05:00.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizePCIBusID(tt.input))
+		})
+	}
+}
+
+func TestNVSwitchTopologyIdentify(t *testing.T) {
+	links := []nvswitchLink{
+		{gpuUUID: "GPU-1", switchBusID: "0000:05:00.0", switchPort: 32},
+		{gpuUUID: "GPU-1", switchBusID: "0000:05:00.0", switchPort: 33},
+		{gpuUUID: "GPU-2", switchBusID: "0000:05:00.0", switchPort: 40},
+		{gpuUUID: "GPU-2", switchBusID: "0000:06:00.0", switchPort: 32},
+	}
+	topo := buildNVSwitchTopology(links, func(busID string) string {
+		return "serial-" + busID
+	})
+	require.Len(t, topo, 2)
+
+	id := topo.identify("PCI:0000:05:00.0", 32)
+	require.NotNil(t, id)
+	assert.Equal(t, &PortIdentity{NVSwitchSerial: "serial-0000:05:00.0", GPUUUIDs: []string{"GPU-1"}}, id)
+
+	// the port not connected to any local GPU
+	id = topo.identify("PCI:0000:05:00.0", 2)
+	require.NotNil(t, id)
+	assert.True(t, id.TrunkLink)
+	assert.Empty(t, id.GPUUUIDs)
+
+	// unknown NVSwitch
+	assert.Nil(t, topo.identify("PCI:0000:07:00.0", 32))
+
+	sxidErr := Match("nvidia-nvswitch3: SXid (PCI:0000:06:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)")
+	require.NotNil(t, sxidErr)
+	topo.enrich(sxidErr)
+	require.NotNil(t, sxidErr.PortIdentity)
+	assert.Equal(t, []string{"GPU-2"}, sxidErr.PortIdentity.GPUUUIDs)
+
+	// not specific to a port
+	sxidErr = Match("nvidia-nvswitch0: SXid (PCI:0000:05:00.0): 20034, Data {0x50610002, 0x10100030}")
+	require.NotNil(t, sxidErr)
+	topo.enrich(sxidErr)
+	assert.Nil(t, sxidErr.PortIdentity)
+}
+
+func TestParsePCIDeviceSerialNumber(t *testing.T) {
+	cfg := make([]byte, 4096)
+	// first extended capability (AER, ID 0x0001), next at 0x140
+	binary.LittleEndian.PutUint32(cfg[0x100:], 0x140<<20|0x1<<16|0x0001)
+	// device serial number capability, last one
+	binary.LittleEndian.PutUint32(cfg[0x140:], 0x1<<16|pciExtCapIDDeviceSerialNumber)
+	binary.LittleEndian.PutUint32(cfg[0x144:], 0x9abcdef0)
+	binary.LittleEndian.PutUint32(cfg[0x148:], 0x12345678)
+	assert.Equal(t, "12-34-56-78-9a-bc-de-f0", parsePCIDeviceSerialNumber(cfg))
+
+	// no extended config space (e.g., read as non-root)
+	assert.Empty(t, parsePCIDeviceSerialNumber(cfg[:256]))
+
+	// no serial number capability
+	noSerial := make([]byte, 4096)
+	binary.LittleEndian.PutUint32(noSerial[0x100:], 0x1<<16|0x0001)
+	assert.Empty(t, parsePCIDeviceSerialNumber(noSerial))
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "0000:05:00.0"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0000:05:00.0", "config"), cfg, 0o644))
+	assert.Equal(t, "12-34-56-78-9a-bc-de-f0", readPCIDeviceSerialNumber(dir, "0000:05:00.0"))
+	assert.Empty(t, readPCIDeviceSerialNumber(dir, "0000:06:00.0"))
+}
+
+func TestComponentEnrichPortIdentity(t *testing.T) {
+	calls := 0
+	c := &component{
+		getNVSwitchTopologyFunc: func() nvswitchTopology {
+			calls++
+			return buildNVSwitchTopology([]nvswitchLink{
+				{gpuUUID: "GPU-1", switchBusID: "0000:05:00.0", switchPort: 32},
+			}, func(string) string { return "12-34-56-78-9a-bc-de-f0" })
+		},
+	}
+
+	sxidErr := Match("nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)")
+	require.NotNil(t, sxidErr)
+	c.enrichPortIdentity(sxidErr)
+	c.enrichPortIdentity(sxidErr)
+	assert.Equal(t, 1, calls, "topology should be discovered once")
+
+	extraInfo := eventExtraInfo(sxidErr)
+	assert.Equal(t, map[string]string{
+		EventKeyErrorSXidData:  "12028",
+		EventKeyDeviceUUID:     "PCI:0000:05:00.0",
+		EventKeyNVSwitch:       "nvidia-nvswitch3",
+		EventKeyNVSwitchPort:   "32",
+		EventKeyNVSwitchSerial: "12-34-56-78-9a-bc-de-f0",
+		EventKeyGPUUUIDs:       "GPU-1",
+	}, extraInfo)
+	contractstest.AssertEvents(t, Name, eventstore.Event{Name: EventNameErrorSXid, ExtraInfo: extraInfo})
+
+	resolved := resolveSXIDEvent(eventstore.Event{Time: time.Now(), Name: EventNameErrorSXid, ExtraInfo: extraInfo})
+	assert.Contains(t, resolved.Message, "(nvidia-nvswitch3 serial 12-34-56-78-9a-bc-de-f0 port 32 connected to GPU-1)")
+
+	var detail sxidErrorEventDetail
+	require.NoError(t, json.Unmarshal([]byte(resolved.ExtraInfo[EventKeyErrorSXidData]), &detail))
+	assert.Equal(t, "32", detail.NVSwitchPort)
+	assert.Equal(t, []string{"GPU-1"}, detail.GPUUUIDs)
+	assert.False(t, detail.TrunkLink)
+}
+
+func TestDescribePortIdentity(t *testing.T) {
+	assert.Empty(t, describePortIdentity(map[string]string{EventKeyNVSwitch: "nvidia-nvswitch0"}))
+	assert.Equal(t, "nvidia-nvswitch0 port 2 trunk link", describePortIdentity(map[string]string{
+		EventKeyNVSwitch:     "nvidia-nvswitch0",
+		EventKeyNVSwitchPort: "2",
+		EventKeyTrunkLink:    "true",
+	}))
+}
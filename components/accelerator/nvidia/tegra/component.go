@@ -0,0 +1,300 @@
+// Package tegra tracks the integrated GPU of the NVIDIA Jetson/Tegra devices,
+// read from the sysfs as NVML is not available (or only partially) on the integrated GPUs.
+// Optional, enabled if the host is a Jetson/Tegra device.
+package tegra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
+	"github.com/leptonai/gpud/pkg/reasoncode"
+)
+
+const (
+	// Name is the ID of the NVIDIA Jetson/Tegra component.
+	Name = "accelerator-nvidia-tegra"
+
+	// ThresholdTemperatureCelsius is the integrated GPU temperature at or above which
+	// the GPU is degraded, as the Jetson modules throttle the clocks near 100°C.
+	ThresholdTemperatureCelsius = 95.0
+)
+
+const (
+	// ReasonCodeGPUNotFound is for the Jetson/Tegra devices without the integrated GPU found.
+	ReasonCodeGPUNotFound apiv1.ReasonCode = "TEGRA_GPU_NOT_FOUND"
+	// ReasonCodeGPUReadError is for the failures reading the integrated GPU stats.
+	ReasonCodeGPUReadError apiv1.ReasonCode = "TEGRA_GPU_READ_ERROR"
+	// ReasonCodeGPUTemperatureHigh is for the integrated GPU temperature over the threshold.
+	ReasonCodeGPUTemperatureHigh apiv1.ReasonCode = "TEGRA_GPU_TEMPERATURE_HIGH"
+)
+
+func init() {
+	reasoncode.MustRegister(Name,
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeGPUNotFound, Health: apiv1.HealthStateTypeUnhealthy, Description: "The integrated GPU of the Jetson/Tegra device is not found (e.g., the GPU driver not loaded)."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeGPUReadError, Health: apiv1.HealthStateTypeDegraded, Description: "Failed to read the integrated GPU stats from the sysfs."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodeGPUTemperatureHigh, Health: apiv1.HealthStateTypeDegraded, Description: "The integrated GPU temperature is over the threshold, and may be throttled."},
+	)
+}
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	isTegraFunc      func() bool
+	getInfoFunc      func() (*nvidiategra.Info, error)
+	getGPUStatsFunc  func() (*nvidiategra.GPUStats, error)
+	thresholdCelsius float64
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA Jetson/Tegra component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		isTegraFunc: nvidiategra.IsTegra,
+		getInfoFunc: func() (*nvidiategra.Info, error) {
+			return nvidiategra.Detect(nvidiategra.DefaultReleaseFile, nvidiategra.DefaultDeviceTreeDir)
+		},
+		getGPUStatsFunc: func() (*nvidiategra.GPUStats, error) {
+			return nvidiategra.ReadGPUStats(nvidiategra.DefaultDevfreqDir, nvidiategra.DefaultThermalDir)
+		},
+		thresholdCelsius: ThresholdTemperatureCelsius,
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"tegra",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return c.isTegraFunc()
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia tegra gpu")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if !c.isTegraFunc() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "not a Jetson/Tegra device"
+		return cr
+	}
+
+	cr.Info, cr.err = c.getInfoFunc()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "error reading Jetson/Tegra device info"
+		cr.reasonCode = ReasonCodeGPUReadError
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	cr.GPU, cr.err = c.getGPUStatsFunc()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "error reading integrated GPU stats"
+		cr.reasonCode = ReasonCodeGPUReadError
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+	if cr.GPU == nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "integrated GPU not found (GPU driver not loaded?)"
+		cr.reasonCode = ReasonCodeGPUNotFound
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+		return cr
+	}
+
+	labels := prometheus.Labels{"device": cr.GPU.Device}
+	metricGPULoadPercent.With(labels).Set(cr.GPU.LoadPercent)
+	metricGPUFrequencyMHz.With(labels).Set(float64(cr.GPU.CurFrequencyHz) / 1e6)
+	if cr.GPU.ThermalZone != "" {
+		metricGPUTemperatureCelsius.With(labels).Set(cr.GPU.TemperatureCelsius)
+	}
+
+	if cr.GPU.ThermalZone != "" && cr.GPU.TemperatureCelsius >= c.thresholdCelsius {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("integrated GPU %s temperature %.1f°C exceeds the threshold %.1f°C", cr.GPU.Device, cr.GPU.TemperatureCelsius, c.thresholdCelsius)
+		cr.reasonCode = ReasonCodeGPUTemperatureHigh
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("integrated GPU %s found, no issue found", cr.GPU.Device)
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Info *nvidiategra.Info     `json:"info,omitempty"`
+	GPU  *nvidiategra.GPUStats `json:"gpu,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+	// tracks the reason code of the last check, empty to fall back to the health
+	reasonCode apiv1.ReasonCode
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.GPU == nil {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	if cr.Info != nil {
+		table.Append([]string{"Model", cr.Info.Model})
+		table.Append([]string{"SoC", cr.Info.SoC})
+		table.Append([]string{"L4T Release", cr.Info.L4TRelease})
+	}
+	table.Append([]string{"GPU Device", cr.GPU.Device})
+	table.Append([]string{"GPU Load", fmt.Sprintf("%.1f %%", cr.GPU.LoadPercent)})
+	table.Append([]string{"GPU Frequency", fmt.Sprintf("%d MHz", cr.GPU.CurFrequencyHz/1000000)})
+	if cr.GPU.ThermalZone != "" {
+		table.Append([]string{"GPU Temperature", fmt.Sprintf("%.1f °C", cr.GPU.TemperatureCelsius)})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:       metav1.NewTime(time.Now().UTC()),
+				Component:  Name,
+				Name:       Name,
+				Health:     apiv1.HealthStateTypeHealthy,
+				Reason:     "no data yet",
+				ReasonCode: apiv1.ReasonCodeHealthy,
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:             metav1.NewTime(cr.ts),
+		Component:        Name,
+		Name:             Name,
+		Reason:           cr.reason,
+		ReasonCode:       cr.reasonCode,
+		Error:            cr.getError(),
+		Health:           cr.health,
+		SuggestedActions: cr.suggestedActions,
+	}
+
+	if cr.GPU != nil || cr.Info != nil {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,156 @@
+package tegra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	nvidiategra "github.com/leptonai/gpud/pkg/nvidia/tegra"
+)
+
+var testInfo = &nvidiategra.Info{Model: "NVIDIA Jetson AGX Orin Developer Kit", SoC: "tegra234", L4TRelease: "36.3.0"}
+
+func createMockTegraComponent(ctx context.Context, getGPUStatsFunc func() (*nvidiategra.GPUStats, error)) *component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:    cctx,
+		cancel: cancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		isTegraFunc: func() bool {
+			return true
+		},
+		getInfoFunc: func() (*nvidiategra.Info, error) {
+			return testInfo, nil
+		},
+		getGPUStatsFunc:  getGPUStatsFunc,
+		thresholdCelsius: ThresholdTemperatureCelsius,
+	}
+}
+
+func TestNew(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	c, ok := comp.(*component)
+	require.True(t, ok)
+	assert.Equal(t, ThresholdTemperatureCelsius, c.thresholdCelsius)
+}
+
+func TestComponentBasics(t *testing.T) {
+	c := createMockTegraComponent(context.Background(), func() (*nvidiategra.GPUStats, error) {
+		return nil, nil
+	})
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), "tegra")
+	assert.True(t, c.IsSupported())
+
+	c.isTegraFunc = func() bool { return false }
+	assert.False(t, c.IsSupported())
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "not a Jetson/Tegra device", cr.Summary())
+
+	evs, err := c.Events(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+
+	var nilResult *checkResult
+	states := nilResult.HealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheckHealthy(t *testing.T) {
+	c := createMockTegraComponent(context.Background(), func() (*nvidiategra.GPUStats, error) {
+		return &nvidiategra.GPUStats{
+			Device:             "17000000.ga10b",
+			LoadPercent:        45.3,
+			CurFrequencyHz:     1300500000,
+			ThermalZone:        "GPU-therm",
+			TemperatureCelsius: 51.5,
+		}, nil
+	})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "integrated GPU 17000000.ga10b found, no issue found", cr.Summary())
+	assert.Contains(t, cr.String(), "17000000.ga10b")
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Equal(t, testInfo, decoded.Info)
+	assert.Equal(t, 45.3, decoded.GPU.LoadPercent)
+}
+
+func TestCheckTemperatureHigh(t *testing.T) {
+	c := createMockTegraComponent(context.Background(), func() (*nvidiategra.GPUStats, error) {
+		return &nvidiategra.GPUStats{
+			Device:             "17000000.ga10b",
+			ThermalZone:        "GPU-therm",
+			TemperatureCelsius: 97,
+		}, nil
+	})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "exceeds the threshold 95.0°C")
+	assert.Equal(t, ReasonCodeGPUTemperatureHigh, cr.HealthStates()[0].ReasonCode)
+
+	// the temperature is not evaluated without the thermal zone
+	c.getGPUStatsFunc = func() (*nvidiategra.GPUStats, error) {
+		return &nvidiategra.GPUStats{Device: "57000000.gpu"}, nil
+	}
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+}
+
+func TestCheckGPUNotFound(t *testing.T) {
+	c := createMockTegraComponent(context.Background(), func() (*nvidiategra.GPUStats, error) {
+		return nil, nil
+	})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "no data", cr.String())
+	states := cr.HealthStates()
+	assert.Equal(t, ReasonCodeGPUNotFound, states[0].ReasonCode)
+	require.NotNil(t, states[0].SuggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeRebootSystem}, states[0].SuggestedActions.RepairActions)
+}
+
+func TestCheckReadError(t *testing.T) {
+	c := createMockTegraComponent(context.Background(), func() (*nvidiategra.GPUStats, error) {
+		return nil, errors.New("permission denied")
+	})
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "permission denied", cr.HealthStates()[0].Error)
+	assert.Equal(t, ReasonCodeGPUReadError, cr.HealthStates()[0].ReasonCode)
+
+	c.getInfoFunc = func() (*nvidiategra.Info, error) { return nil, errors.New("device tree read error") }
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "error reading Jetson/Tegra device info", cr.Summary())
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,60 @@
+package tegra
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA Jetson/Tegra component.
+const SubSystem = "accelerator_nvidia_tegra"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricGPULoadPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "gpu_load_percent",
+			Help:      "tracks the current integrated GPU load percent",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "device"},
+	).MustCurryWith(componentLabel)
+
+	metricGPUFrequencyMHz = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "gpu_frequency_mhz",
+			Help:      "tracks the current integrated GPU frequency in MHz",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "device"},
+	).MustCurryWith(componentLabel)
+
+	metricGPUTemperatureCelsius = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "gpu_temperature_celsius",
+			Help:      "tracks the current integrated GPU temperature in degrees Celsius",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "device"},
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricGPULoadPercent,
+		metricGPUFrequencyMHz,
+		metricGPUTemperatureCelsius,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_load_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_frequency_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_temperature_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+	)
+}
@@ -1,4 +1,5 @@
-// Package temperature tracks the NVIDIA per-GPU temperatures.
+// Package temperature tracks the NVIDIA per-GPU temperatures,
+// and the fans and the cooling-related throttle reasons.
 package temperature
 
 import (
@@ -7,12 +8,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -29,6 +30,8 @@ const Name = "accelerator-nvidia-temperature"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -36,6 +39,7 @@ type component struct {
 
 	nvmlInstance       nvidianvml.Instance
 	getTemperatureFunc func(uuid string, dev device.Device) (Temperature, error)
+	getCoolingFunc     func(uuid string, dev device.Device, clockEventsSupported bool) (Cooling, error)
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -55,6 +59,7 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		},
 		nvmlInstance:       gpudInstance.NVMLInstance,
 		getTemperatureFunc: GetTemperature,
+		getCoolingFunc:     GetCooling,
 	}
 
 	return c, nil
@@ -79,20 +84,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -163,8 +155,10 @@ func (c *component) Check() components.CheckResult {
 	marginThresholdExceeded := make([]string, 0)
 
 	devs := c.nvmlInstance.Devices()
-	for uuid, dev := range devs {
-		temp, err := c.getTemperatureFunc(uuid, dev)
+	labeler := nvidianvml.NewGPULabeler(devs)
+	// query all GPUs in parallel, so the readings are taken at the same time
+	for _, r := range nvidianvml.QueryDevices(devs, c.getTemperatureFunc) {
+		uuid, temp, err := r.UUID, r.Value, r.Err
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
@@ -249,11 +243,11 @@ func (c *component) Check() components.CheckResult {
 
 		cr.Temperatures = append(cr.Temperatures, temp)
 
-		metricCurrentCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.CurrentCelsiusGPUCore))
-		metricCurrentHBMCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.CurrentCelsiusHBM))
-		metricThresholdSlowdownCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.ThresholdCelsiusSlowdown))
-		metricThresholdMemMaxCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.ThresholdCelsiusMemMax))
-		metricMarginCelsius.With(prometheus.Labels{"uuid": uuid}).Set(float64(temp.ThresholdCelsiusSlowdownMargin))
+		metricCurrentCelsius.With(labeler.Labels(uuid)).Set(float64(temp.CurrentCelsiusGPUCore))
+		metricCurrentHBMCelsius.With(labeler.Labels(uuid)).Set(float64(temp.CurrentCelsiusHBM))
+		metricThresholdSlowdownCelsius.With(labeler.Labels(uuid)).Set(float64(temp.ThresholdCelsiusSlowdown))
+		metricThresholdMemMaxCelsius.With(labeler.Labels(uuid)).Set(float64(temp.ThresholdCelsiusMemMax))
+		metricMarginCelsius.With(labeler.Labels(uuid)).Set(float64(temp.ThresholdCelsiusSlowdownMargin))
 
 		slowdownPct, err := temp.GetUsedPercentSlowdown()
 		if err != nil {
@@ -263,16 +257,66 @@ func (c *component) Check() components.CheckResult {
 			log.Logger.Warnw(cr.reason, "uuid", uuid, "error", cr.err)
 			return cr
 		}
-		metricSlowdownUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(slowdownPct)
+		metricSlowdownUsedPercent.With(labeler.Labels(uuid)).Set(slowdownPct)
 
 		memMaxPct := 0.0
 		if temp.ThresholdCelsiusMemMax > 0 && temp.HBMTemperatureSupported {
 			memMaxPct = float64(temp.CurrentCelsiusHBM) / float64(temp.ThresholdCelsiusMemMax) * 100
 		}
-		metricMemMaxUsedPercent.With(prometheus.Labels{"uuid": uuid}).Set(memMaxPct)
+		metricMemMaxUsedPercent.With(labeler.Labels(uuid)).Set(memMaxPct)
+	}
+
+	fanFailures := make([]string, 0)
+	thermalThrottled := make([]string, 0)
+	if c.getCoolingFunc != nil {
+		clockEventsSupported := nvidianvml.ClockEventsSupportedVersion(c.nvmlInstance.DriverMajor())
+		for _, r := range nvidianvml.QueryDevices(devs, func(uuid string, dev device.Device) (Cooling, error) {
+			return c.getCoolingFunc(uuid, dev, clockEventsSupported)
+		}) {
+			uuid, cool, err := r.UUID, r.Value, r.Err
+			if err != nil {
+				// only the lost GPUs and the GPUs requiring reset fail the query,
+				// already reported by the temperature query above
+				log.Logger.Warnw("error getting cooling", "uuid", uuid, "error", err)
+				continue
+			}
+			cr.Coolings = append(cr.Coolings, cool)
+
+			for _, fan := range cool.Fans {
+				labels := labeler.Labels(uuid)
+				labels["fan"] = strconv.Itoa(fan.Index)
+				metricFanSpeedPercent.With(labels).Set(float64(fan.SpeedPercent))
+				if fan.TargetSpeedSupported {
+					metricFanTargetSpeedPercent.With(labels).Set(float64(fan.TargetSpeedPercent))
+				}
+			}
+			if failures := cool.FanFailures(); len(failures) > 0 {
+				fanFailures = append(fanFailures, fmt.Sprintf("%s %s", uuid, strings.Join(failures, ", ")))
+			}
+
+			if cool.ClockEventsSupported {
+				metricSWThermalSlowdown.With(labeler.Labels(uuid)).Set(boolToFloat(cool.SWThermalSlowdown))
+				metricHWThermalSlowdown.With(labeler.Labels(uuid)).Set(boolToFloat(cool.HWThermalSlowdown))
+				if cool.ThermalThrottled() {
+					thermalThrottled = append(thermalThrottled, uuid)
+				}
+			}
+		}
 	}
 
 	switch {
+	case len(fanFailures) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("fan failures detected: %s", strings.Join(fanFailures, "; "))
+		if len(thermalThrottled) > 0 {
+			cr.reason += fmt.Sprintf(" (thermal slowdown active on %s)", strings.Join(thermalThrottled, ", "))
+		}
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			Description: "inspect the GPU fans and the airflow (e.g., a failed fan, a blocked intake)",
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeHardwareInspection,
+			},
+		}
 	case len(marginThresholdExceeded) > 0:
 		cr.health = apiv1.HealthStateTypeDegraded
 		cr.reason = fmt.Sprintf("margin threshold exceeded: %s", strings.Join(marginThresholdExceeded, ", "))
@@ -282,6 +326,9 @@ func (c *component) Check() components.CheckResult {
 	case len(hbmTempThresholdExceeded) > 0:
 		cr.health = apiv1.HealthStateTypeDegraded
 		cr.reason = fmt.Sprintf("HBM temperature anomalies detected: %s", strings.Join(hbmTempThresholdExceeded, ", "))
+	case len(thermalThrottled) > 0:
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("thermal slowdown active: %s", strings.Join(thermalThrottled, ", "))
 	default:
 		cr.health = apiv1.HealthStateTypeHealthy
 		cr.reason = fmt.Sprintf("all %d GPU(s) were checked, no temperature issue found", len(devs))
@@ -294,6 +341,8 @@ var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
 	Temperatures []Temperature `json:"temperatures,omitempty"`
+	// Coolings is the fan readings and the cooling-related throttle reasons per GPU.
+	Coolings []Cooling `json:"coolings,omitempty"`
 
 	// timestamp of the last check
 	ts time.Time
@@ -344,6 +393,41 @@ func (cr *checkResult) String() string {
 	}
 	table.Render()
 
+	if fans := cr.fansTable(); fans != "" {
+		buf.WriteString("\n")
+		buf.WriteString(fans)
+	}
+
+	return buf.String()
+}
+
+// fansTable returns the fan readings of the GPUs with fans,
+// or an empty string if no GPU has fans (e.g., passively cooled).
+func (cr *checkResult) fansTable() string {
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"GPU UUID", "Fan", "Speed", "Target speed", "Thermal slowdown"})
+	rows := 0
+	for _, cool := range cr.Coolings {
+		for _, fan := range cool.Fans {
+			target := "n/a"
+			if fan.TargetSpeedSupported {
+				target = fmt.Sprintf("%d %%", fan.TargetSpeedPercent)
+			}
+			table.Append([]string{
+				cool.UUID,
+				strconv.Itoa(fan.Index),
+				fmt.Sprintf("%d %%", fan.SpeedPercent),
+				target,
+				fmt.Sprintf("%v", cool.ThermalThrottled()),
+			})
+			rows++
+		}
+	}
+	if rows == 0 {
+		return ""
+	}
+	table.Render()
 	return buf.String()
 }
 
@@ -0,0 +1,184 @@
+package temperature
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// FanSpeedLagPercent is the fan speed (in percentage points of the maximum speed)
+// below the target fan speed to consider the fan as failing to follow the fan curve
+// (e.g., a worn bearing, a blocked intake), rather than still ramping up.
+const FanSpeedLagPercent = 30
+
+// Fan represents the speed readings of a GPU fan.
+type Fan struct {
+	// Index is the fan index on the device, starting from 0.
+	Index int `json:"index"`
+
+	// SpeedPercent is the current fan speed in percent of the maximum speed.
+	// The fan speed is the intended speed, which may not reflect the actual speed
+	// if the fan is physically blocked.
+	SpeedPercent uint32 `json:"speed_percent"`
+
+	// TargetSpeedPercent is the fan speed the driver targets from its fan curve,
+	// in percent of the maximum speed.
+	TargetSpeedPercent int `json:"target_speed_percent"`
+	// TargetSpeedSupported indicates whether NVML provided the target fan speed.
+	TargetSpeedSupported bool `json:"target_speed_supported"`
+}
+
+// Failure returns the fan failure description, or an empty string if the fan
+// follows its target speed: the fan is stopped while targeted to spin,
+// or lags behind the target by FanSpeedLagPercent or more.
+func (fan Fan) Failure() string {
+	if !fan.TargetSpeedSupported || fan.TargetSpeedPercent <= 0 {
+		return ""
+	}
+	if fan.SpeedPercent == 0 {
+		return fmt.Sprintf("fan %d stopped while targeted at %d %%", fan.Index, fan.TargetSpeedPercent)
+	}
+	if int(fan.SpeedPercent)+FanSpeedLagPercent <= fan.TargetSpeedPercent {
+		return fmt.Sprintf("fan %d at %d %% lagging the target %d %%", fan.Index, fan.SpeedPercent, fan.TargetSpeedPercent)
+	}
+	return ""
+}
+
+// Cooling represents the fan readings and the cooling-related throttle reasons for a device.
+type Cooling struct {
+	// Represents the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// BusID is the GPU bus ID from the nvml API.
+	//  e.g., "0000:0f:00.0"
+	BusID string `json:"bus_id"`
+
+	// FansSupported indicates whether NVML reported any fan,
+	// false for the passively cooled GPUs (e.g., the data center GPUs cooled by the chassis).
+	FansSupported bool  `json:"fans_supported"`
+	Fans          []Fan `json:"fans,omitempty"`
+
+	// ClockEventsSupported indicates whether NVML provided the clock event reasons.
+	ClockEventsSupported bool `json:"clock_events_supported"`
+	// SWThermalSlowdown is true if the driver is reducing the clocks
+	// to keep the GPU and memory temperatures within the operating limits.
+	SWThermalSlowdown bool `json:"sw_thermal_slowdown"`
+	// HWThermalSlowdown is true if the hardware is reducing the clocks
+	// by a factor of 2 or more, as the temperature is too high.
+	HWThermalSlowdown bool `json:"hw_thermal_slowdown"`
+}
+
+// FanFailures returns the descriptions of the fans failing to follow their target speeds.
+func (cool Cooling) FanFailures() []string {
+	var failures []string
+	for _, fan := range cool.Fans {
+		if f := fan.Failure(); f != "" {
+			failures = append(failures, f)
+		}
+	}
+	return failures
+}
+
+// ThermalThrottled returns true if any cooling-related throttle reason is active.
+func (cool Cooling) ThermalThrottled() bool {
+	return cool.SWThermalSlowdown || cool.HWThermalSlowdown
+}
+
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlClocksEventReasons.html
+const (
+	clocksEventReasonSWThermalSlowdown uint64 = 0x0000000000000020
+	clocksEventReasonHWThermalSlowdown uint64 = 0x0000000000000040
+)
+
+// GetCooling returns the fan readings and the cooling-related throttle reasons for a device.
+// The clock event reasons are only queried if clockEventsSupported is true, as the API
+// is not available in the drivers older than 535 (see "ClockEventsSupportedVersion").
+func GetCooling(uuid string, dev device.Device, clockEventsSupported bool) (Cooling, error) {
+	cool := Cooling{
+		UUID:  uuid,
+		BusID: dev.PCIBusID(),
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+	numFans, ret := dev.GetNumFans()
+	if ret != nvml.SUCCESS {
+		if nvmlerrors.IsGPULostError(ret) {
+			return cool, nvmlerrors.ErrGPULost
+		}
+		if nvmlerrors.IsGPURequiresReset(ret) {
+			return cool, nvmlerrors.ErrGPURequiresReset
+		}
+		if nvmlerrors.IsNotSupportError(ret) {
+			log.Logger.Debugw("device fans not supported", "error", nvml.ErrorString(ret))
+		} else {
+			log.Logger.Warnw("failed to get device number of fans", "error", nvml.ErrorString(ret))
+		}
+		numFans = 0
+	}
+	cool.FansSupported = numFans > 0
+
+	for i := 0; i < numFans; i++ {
+		fan := Fan{Index: i}
+
+		// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+		speed, ret := dev.GetFanSpeed_v2(i)
+		if ret != nvml.SUCCESS {
+			if nvmlerrors.IsGPULostError(ret) {
+				return cool, nvmlerrors.ErrGPULost
+			}
+			if nvmlerrors.IsGPURequiresReset(ret) {
+				return cool, nvmlerrors.ErrGPURequiresReset
+			}
+			log.Logger.Warnw("failed to get device fan speed", "fan", i, "error", nvml.ErrorString(ret))
+			continue
+		}
+		fan.SpeedPercent = speed
+
+		// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+		target, ret := dev.GetTargetFanSpeed(i)
+		if ret == nvml.SUCCESS {
+			fan.TargetSpeedPercent = target
+			fan.TargetSpeedSupported = true
+		} else {
+			if nvmlerrors.IsGPULostError(ret) {
+				return cool, nvmlerrors.ErrGPULost
+			}
+			if nvmlerrors.IsGPURequiresReset(ret) {
+				return cool, nvmlerrors.ErrGPURequiresReset
+			}
+			log.Logger.Debugw("device target fan speed not supported", "fan", i, "error", nvml.ErrorString(ret))
+		}
+
+		cool.Fans = append(cool.Fans, fan)
+	}
+
+	if !clockEventsSupported {
+		return cool, nil
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html#group__nvmlDeviceQueries_1g7e505374454a0d4fc7339b6c885656d6
+	reasons, ret := dev.GetCurrentClocksEventReasons()
+	if ret == nvml.SUCCESS {
+		cool.ClockEventsSupported = true
+		cool.SWThermalSlowdown = reasons&clocksEventReasonSWThermalSlowdown != 0
+		cool.HWThermalSlowdown = reasons&clocksEventReasonHWThermalSlowdown != 0
+	} else {
+		if nvmlerrors.IsGPULostError(ret) {
+			return cool, nvmlerrors.ErrGPULost
+		}
+		if nvmlerrors.IsGPURequiresReset(ret) {
+			return cool, nvmlerrors.ErrGPURequiresReset
+		}
+		if nvmlerrors.IsNotSupportError(ret) {
+			log.Logger.Debugw("device clock events not supported", "error", nvml.ErrorString(ret))
+		} else {
+			log.Logger.Warnw("failed to get device clock event reasons", "error", nvml.ErrorString(ret))
+		}
+	}
+
+	return cool, nil
+}
@@ -0,0 +1,211 @@
+package temperature
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func TestFanFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		fan  Fan
+		want string
+	}{
+		{name: "following the target", fan: Fan{SpeedPercent: 55, TargetSpeedPercent: 60, TargetSpeedSupported: true}},
+		{name: "idle", fan: Fan{SpeedPercent: 0, TargetSpeedPercent: 0, TargetSpeedSupported: true}},
+		{name: "target not supported", fan: Fan{SpeedPercent: 0}},
+		{name: "stopped", fan: Fan{Index: 1, SpeedPercent: 0, TargetSpeedPercent: 45, TargetSpeedSupported: true}, want: "fan 1 stopped while targeted at 45 %"},
+		{name: "lagging", fan: Fan{SpeedPercent: 40, TargetSpeedPercent: 70, TargetSpeedSupported: true}, want: "fan 0 at 40 % lagging the target 70 %"},
+		{name: "ramping up", fan: Fan{SpeedPercent: 41, TargetSpeedPercent: 70, TargetSpeedSupported: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.fan.Failure())
+		})
+	}
+}
+
+func TestGetCooling(t *testing.T) {
+	testUUID := "GPU-12345678"
+
+	newDevice := func(numFansRet nvml.Return, targetRet nvml.Return, reasons uint64, reasonsRet nvml.Return) device.Device {
+		return testutil.NewMockDevice(&mock.Device{
+			GetNumFansFunc: func() (int, nvml.Return) {
+				return 2, numFansRet
+			},
+			GetFanSpeed_v2Func: func(n int) (uint32, nvml.Return) {
+				return uint32(30 + n*10), nvml.SUCCESS
+			},
+			GetTargetFanSpeedFunc: func(n int) (int, nvml.Return) {
+				return 35 + n*10, targetRet
+			},
+			GetCurrentClocksEventReasonsFunc: func() (uint64, nvml.Return) {
+				return reasons, reasonsRet
+			},
+		}, "test-arch", "test-brand", "test-cuda", "0000:0f:00.0")
+	}
+
+	t.Run("fans and thermal slowdown", func(t *testing.T) {
+		cool, err := GetCooling(testUUID, newDevice(nvml.SUCCESS, nvml.SUCCESS, clocksEventReasonSWThermalSlowdown, nvml.SUCCESS), true)
+		require.NoError(t, err)
+		assert.Equal(t, Cooling{
+			UUID:          testUUID,
+			BusID:         "0000:0f:00.0",
+			FansSupported: true,
+			Fans: []Fan{
+				{Index: 0, SpeedPercent: 30, TargetSpeedPercent: 35, TargetSpeedSupported: true},
+				{Index: 1, SpeedPercent: 40, TargetSpeedPercent: 45, TargetSpeedSupported: true},
+			},
+			ClockEventsSupported: true,
+			SWThermalSlowdown:    true,
+		}, cool)
+		assert.True(t, cool.ThermalThrottled())
+		assert.Empty(t, cool.FanFailures())
+	})
+
+	t.Run("passively cooled", func(t *testing.T) {
+		cool, err := GetCooling(testUUID, newDevice(nvml.ERROR_NOT_SUPPORTED, nvml.SUCCESS, 0, nvml.SUCCESS), true)
+		require.NoError(t, err)
+		assert.False(t, cool.FansSupported)
+		assert.Empty(t, cool.Fans)
+		assert.True(t, cool.ClockEventsSupported)
+		assert.False(t, cool.ThermalThrottled())
+	})
+
+	t.Run("target fan speed not supported", func(t *testing.T) {
+		cool, err := GetCooling(testUUID, newDevice(nvml.SUCCESS, nvml.ERROR_NOT_SUPPORTED, 0, nvml.SUCCESS), true)
+		require.NoError(t, err)
+		require.Len(t, cool.Fans, 2)
+		assert.False(t, cool.Fans[0].TargetSpeedSupported)
+	})
+
+	t.Run("clock events not supported by the driver", func(t *testing.T) {
+		// the clock event reasons are not queried at all
+		cool, err := GetCooling(testUUID, newDevice(nvml.SUCCESS, nvml.SUCCESS, 0, nvml.ERROR_GPU_IS_LOST), false)
+		require.NoError(t, err)
+		assert.False(t, cool.ClockEventsSupported)
+	})
+
+	t.Run("clock events not supported by the device", func(t *testing.T) {
+		cool, err := GetCooling(testUUID, newDevice(nvml.SUCCESS, nvml.SUCCESS, 0, nvml.ERROR_NOT_SUPPORTED), true)
+		require.NoError(t, err)
+		assert.False(t, cool.ClockEventsSupported)
+	})
+
+	t.Run("GPU lost", func(t *testing.T) {
+		_, err := GetCooling(testUUID, newDevice(nvml.ERROR_GPU_IS_LOST, nvml.SUCCESS, 0, nvml.SUCCESS), true)
+		assert.ErrorIs(t, err, nvmlerrors.ErrGPULost)
+	})
+
+	t.Run("GPU requires reset", func(t *testing.T) {
+		_, err := GetCooling(testUUID, newDevice(nvml.SUCCESS, nvml.ERROR_RESET_REQUIRED, 0, nvml.SUCCESS), true)
+		assert.ErrorIs(t, err, nvmlerrors.ErrGPURequiresReset)
+	})
+}
+
+func TestCheck_Cooling(t *testing.T) {
+	uuid := "gpu-uuid-123"
+	mockDev := testutil.NewMockDevice(&mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return uuid, nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "test-pci")
+	mockNVML := &mockNVMLInstance{
+		devices:  map[string]device.Device{uuid: mockDev},
+		exists:   true,
+		prodName: "Test GPU",
+	}
+
+	getTemperatureFunc := func(_ string, _ device.Device) (Temperature, error) {
+		return Temperature{UUID: uuid, CurrentCelsiusGPUCore: 70, ThresholdCelsiusGPUMax: 100, UsedPercentSlowdown: "70.00"}, nil
+	}
+
+	tests := []struct {
+		name          string
+		cooling       Cooling
+		coolingErr    error
+		wantHealth    apiv1.HealthStateType
+		wantReason    string
+		wantSuggested bool
+	}{
+		{
+			name:       "fans following the target",
+			cooling:    Cooling{UUID: uuid, FansSupported: true, Fans: []Fan{{SpeedPercent: 50, TargetSpeedPercent: 50, TargetSpeedSupported: true}}, ClockEventsSupported: true},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no temperature issue found",
+		},
+		{
+			name:       "passively cooled",
+			cooling:    Cooling{UUID: uuid},
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no temperature issue found",
+		},
+		{
+			name:          "fan stopped",
+			cooling:       Cooling{UUID: uuid, FansSupported: true, Fans: []Fan{{SpeedPercent: 0, TargetSpeedPercent: 60, TargetSpeedSupported: true}}, ClockEventsSupported: true, SWThermalSlowdown: true},
+			wantHealth:    apiv1.HealthStateTypeDegraded,
+			wantReason:    "fan failures detected: gpu-uuid-123 fan 0 stopped while targeted at 60 % (thermal slowdown active on gpu-uuid-123)",
+			wantSuggested: true,
+		},
+		{
+			name:       "thermal slowdown",
+			cooling:    Cooling{UUID: uuid, ClockEventsSupported: true, HWThermalSlowdown: true},
+			wantHealth: apiv1.HealthStateTypeDegraded,
+			wantReason: "thermal slowdown active: gpu-uuid-123",
+		},
+		{
+			name:       "cooling error",
+			coolingErr: nvmlerrors.ErrGPULost,
+			wantHealth: apiv1.HealthStateTypeHealthy,
+			wantReason: "no temperature issue found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustComponent(t, MockTemperatureComponent(context.Background(), mockNVML, getTemperatureFunc))
+			defer c.Close()
+			c.getCoolingFunc = func(string, device.Device, bool) (Cooling, error) {
+				return tt.cooling, tt.coolingErr
+			}
+
+			cr, ok := c.Check().(*checkResult)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantHealth, cr.health)
+			assert.Contains(t, cr.reason, tt.wantReason)
+			if tt.wantSuggested {
+				require.NotNil(t, cr.suggestedActions)
+				assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeHardwareInspection}, cr.suggestedActions.RepairActions)
+			} else {
+				assert.Nil(t, cr.suggestedActions)
+			}
+			if tt.coolingErr == nil {
+				assert.Equal(t, []Cooling{tt.cooling}, cr.Coolings)
+			} else {
+				assert.Empty(t, cr.Coolings)
+			}
+		})
+	}
+}
+
+func TestCheckResult_String_Fans(t *testing.T) {
+	cr := &checkResult{
+		Temperatures: []Temperature{{UUID: "gpu-0"}},
+		Coolings:     []Cooling{{UUID: "gpu-0", FansSupported: true, Fans: []Fan{{SpeedPercent: 40, TargetSpeedPercent: 45, TargetSpeedSupported: true}}}},
+	}
+	s := cr.String()
+	assert.Contains(t, s, "TARGET SPEED")
+	assert.Contains(t, s, "45 %")
+
+	cr.Coolings = []Cooling{{UUID: "gpu-0"}}
+	assert.NotContains(t, cr.String(), "TARGET SPEED")
+}
@@ -3,7 +3,9 @@ package temperature
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA temperature component.
@@ -21,7 +23,7 @@ var (
 			Name:      "current_celsius",
 			Help:      "tracks the current temperature in celsius",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricCurrentHBMCelsius = prometheus.NewGaugeVec(
@@ -31,7 +33,7 @@ var (
 			Name:      "current_hbm_celsius",
 			Help:      "tracks the current HBM temperature in celsius",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricThresholdSlowdownCelsius = prometheus.NewGaugeVec(
@@ -41,7 +43,7 @@ var (
 			Name:      "slowdown_threshold_celsius",
 			Help:      "tracks the threshold temperature in celsius for slowdown",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricThresholdMemMaxCelsius = prometheus.NewGaugeVec(
@@ -51,7 +53,7 @@ var (
 			Name:      "mem_max_threshold_celsius",
 			Help:      "tracks the memory max threshold temperature in celsius",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricSlowdownUsedPercent = prometheus.NewGaugeVec(
@@ -61,7 +63,7 @@ var (
 			Name:      "slowdown_used_percent",
 			Help:      "tracks the percentage of slowdown used",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricMemMaxUsedPercent = prometheus.NewGaugeVec(
@@ -71,7 +73,7 @@ var (
 			Name:      "mem_max_used_percent",
 			Help:      "tracks the percentage of memory max temperature used",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricMarginCelsius = prometheus.NewGaugeVec(
@@ -81,7 +83,47 @@ var (
 			Name:      "margin_celsius",
 			Help:      "tracks the thermal margin in celsius to the nearest slowdown threshold (driver-defined; could be GPU core or HBM)",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricFanSpeedPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "fan_speed_percent",
+			Help:      "tracks the current fan speed in percent of the maximum speed",
+		},
+		nvidianvml.GPUMetricLabelKeys("fan"), // labels are GPU UUID and index, and the fan index
+	).MustCurryWith(componentLabel)
+
+	metricFanTargetSpeedPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "fan_target_speed_percent",
+			Help:      "tracks the fan speed targeted by the driver fan curve in percent of the maximum speed",
+		},
+		nvidianvml.GPUMetricLabelKeys("fan"), // labels are GPU UUID and index, and the fan index
+	).MustCurryWith(componentLabel)
+
+	metricSWThermalSlowdown = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "sw_thermal_slowdown",
+			Help:      "set to 1 if the SW thermal slowdown is active (0 if not)",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricHWThermalSlowdown = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "hw_thermal_slowdown",
+			Help:      "set to 1 if the HW thermal slowdown is active (0 if not)",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -94,5 +136,29 @@ func init() {
 		metricSlowdownUsedPercent,
 		metricMemMaxUsedPercent,
 		metricMarginCelsius,
+		metricFanSpeedPercent,
+		metricFanTargetSpeedPercent,
+		metricSWThermalSlowdown,
+		metricHWThermalSlowdown,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_current_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+		apiv1.MetricMetadata{Name: SubSystem + "_current_hbm_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+		apiv1.MetricMetadata{Name: SubSystem + "_slowdown_threshold_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+		apiv1.MetricMetadata{Name: SubSystem + "_mem_max_threshold_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+		apiv1.MetricMetadata{Name: SubSystem + "_slowdown_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_mem_max_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_margin_celsius", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCelsius},
+		apiv1.MetricMetadata{Name: SubSystem + "_fan_speed_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_fan_target_speed_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_sw_thermal_slowdown", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_hw_thermal_slowdown", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
 	)
 }
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
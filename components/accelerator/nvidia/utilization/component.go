@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
@@ -28,6 +27,8 @@ const Name = "accelerator-nvidia-utilization"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -35,6 +36,8 @@ type component struct {
 
 	nvmlInstance       nvidianvml.Instance
 	getUtilizationFunc func(uuid string, dev device.Device) (Utilization, error)
+	// nil to skip the PCIe throughput
+	getPCIeThroughputFunc func(dev device.Device) (PCIeThroughput, error)
 
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
@@ -49,8 +52,9 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		getTimeNowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
-		nvmlInstance:       gpudInstance.NVMLInstance,
-		getUtilizationFunc: GetUtilization,
+		nvmlInstance:          gpudInstance.NVMLInstance,
+		getUtilizationFunc:    GetUtilization,
+		getPCIeThroughputFunc: GetPCIeThroughput,
 	}
 	return c, nil
 }
@@ -74,20 +78,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -152,8 +143,10 @@ func (c *component) Check() components.CheckResult {
 	}
 
 	devs := c.nvmlInstance.Devices()
-	for uuid, dev := range devs {
-		util, err := c.getUtilizationFunc(uuid, dev)
+	labeler := nvidianvml.NewGPULabeler(devs)
+	// query all GPUs in parallel, so the readings are taken at the same time
+	for _, r := range nvidianvml.QueryDevices(devs, c.getUtilization) {
+		uuid, util, err := r.UUID, r.Value, r.Err
 		if err != nil {
 			cr.err = err
 			cr.health = apiv1.HealthStateTypeUnhealthy
@@ -184,8 +177,12 @@ func (c *component) Check() components.CheckResult {
 		}
 		cr.Utilizations = append(cr.Utilizations, util)
 
-		metricGPUUtilPercent.With(prometheus.Labels{"uuid": uuid}).Set(float64(util.GPUUsedPercent))
-		metricMemoryUtilPercent.With(prometheus.Labels{"uuid": uuid}).Set(float64(util.MemoryUsedPercent))
+		metricGPUUtilPercent.With(labeler.Labels(uuid)).Set(float64(util.GPUUsedPercent))
+		metricMemoryUtilPercent.With(labeler.Labels(uuid)).Set(float64(util.MemoryUsedPercent))
+		if util.PCIeTxBytesPerSecond > 0 || util.PCIeRxBytesPerSecond > 0 {
+			metricPCIeTxBytesPerSecond.With(labeler.Labels(uuid)).Set(float64(util.PCIeTxBytesPerSecond))
+			metricPCIeRxBytesPerSecond.With(labeler.Labels(uuid)).Set(float64(util.PCIeRxBytesPerSecond))
+		}
 	}
 
 	cr.health = apiv1.HealthStateTypeHealthy
@@ -194,6 +191,22 @@ func (c *component) Check() components.CheckResult {
 	return cr
 }
 
+// getUtilization returns the utilization of the GPU, with the PCIe throughput if supported.
+func (c *component) getUtilization(uuid string, dev device.Device) (Utilization, error) {
+	util, err := c.getUtilizationFunc(uuid, dev)
+	if err != nil || c.getPCIeThroughputFunc == nil {
+		return util, err
+	}
+
+	throughput, err := c.getPCIeThroughputFunc(dev)
+	if err != nil {
+		return util, err
+	}
+	util.PCIeTxBytesPerSecond = throughput.TxBytesPerSecond
+	util.PCIeRxBytesPerSecond = throughput.RxBytesPerSecond
+	return util, nil
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
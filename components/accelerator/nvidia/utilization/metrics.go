@@ -3,7 +3,9 @@ package utilization
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for the NVIDIA utilization component.
@@ -21,7 +23,7 @@ var (
 			Name:      "gpu_util_percent",
 			Help:      "tracks the current GPU utilization/used percent",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 
 	metricMemoryUtilPercent = prometheus.NewGaugeVec(
@@ -31,7 +33,27 @@ var (
 			Name:      "memory_util_percent",
 			Help:      "tracks the current GPU memory utilization percent",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey, "uuid"}, // label is GPU ID
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricPCIeTxBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "pcie_tx_bytes_per_second",
+			Help:      "tracks the current GPU PCIe TX throughput",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricPCIeRxBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "pcie_rx_bytes_per_second",
+			Help:      "tracks the current GPU PCIe RX throughput",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
 	).MustCurryWith(componentLabel)
 )
 
@@ -39,5 +61,13 @@ func init() {
 	pkgmetrics.MustRegister(
 		metricGPUUtilPercent,
 		metricMemoryUtilPercent,
+		metricPCIeTxBytesPerSecond,
+		metricPCIeRxBytesPerSecond,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_memory_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_pcie_tx_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
+		apiv1.MetricMetadata{Name: SubSystem + "_pcie_rx_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
 	)
 }
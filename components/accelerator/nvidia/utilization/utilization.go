@@ -28,6 +28,11 @@ type Utilization struct {
 	// Percent of time over the past sample period during which global (device) memory was being read or written.
 	MemoryUsedPercent uint32 `json:"memory_used_percent"`
 
+	// PCIeTxBytesPerSecond is the PCIe TX throughput, zero if not supported.
+	PCIeTxBytesPerSecond uint64 `json:"pcie_tx_bytes_per_second,omitempty"`
+	// PCIeRxBytesPerSecond is the PCIe RX throughput, zero if not supported.
+	PCIeRxBytesPerSecond uint64 `json:"pcie_rx_bytes_per_second,omitempty"`
+
 	// Supported is true if the utilization is supported by the device.
 	Supported bool `json:"supported"`
 }
@@ -60,3 +65,46 @@ func GetUtilization(uuid string, dev device.Device) (Utilization, error) {
 
 	return util, nil
 }
+
+// PCIeThroughput represents the data from the nvmlDeviceGetPcieThroughput API,
+// the PCIe throughput sampled over a 20ms interval.
+// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html
+type PCIeThroughput struct {
+	// TxBytesPerSecond is the PCIe TX throughput.
+	TxBytesPerSecond uint64 `json:"tx_bytes_per_second"`
+	// RxBytesPerSecond is the PCIe RX throughput.
+	RxBytesPerSecond uint64 `json:"rx_bytes_per_second"`
+
+	// Supported is true if the PCIe throughput is supported by the device.
+	Supported bool `json:"supported"`
+}
+
+// GetPCIeThroughput returns the PCIe throughput for a device.
+func GetPCIeThroughput(dev device.Device) (PCIeThroughput, error) {
+	throughput := PCIeThroughput{Supported: true}
+
+	for _, counter := range []nvml.PcieUtilCounter{nvml.PCIE_UTIL_TX_BYTES, nvml.PCIE_UTIL_RX_BYTES} {
+		// in KB/s
+		kbps, ret := dev.GetPcieThroughput(counter)
+		if nvmlerrors.IsNotSupportError(ret) {
+			return PCIeThroughput{}, nil
+		}
+		if nvmlerrors.IsGPULostError(ret) {
+			return throughput, nvmlerrors.ErrGPULost
+		}
+		if nvmlerrors.IsGPURequiresReset(ret) {
+			return throughput, nvmlerrors.ErrGPURequiresReset
+		}
+		if ret != nvml.SUCCESS {
+			return throughput, fmt.Errorf("failed to get device pcie throughput: %v", nvml.ErrorString(ret))
+		}
+
+		if counter == nvml.PCIE_UTIL_TX_BYTES {
+			throughput.TxBytesPerSecond = uint64(kbps) * 1024
+		} else {
+			throughput.RxBytesPerSecond = uint64(kbps) * 1024
+		}
+	}
+
+	return throughput, nil
+}
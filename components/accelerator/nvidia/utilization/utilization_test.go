@@ -193,3 +193,51 @@ func TestUtilizationWithGPULostError(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, nvmlerrors.ErrGPULost), "Expected GPU lost error")
 }
+
+func TestGetPCIeThroughput(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockReturn nvml.Return
+		expected   PCIeThroughput
+		expectErr  error
+	}{
+		{
+			name:       "success case",
+			mockReturn: nvml.SUCCESS,
+			expected:   PCIeThroughput{TxBytesPerSecond: 2 * 1024, RxBytesPerSecond: 3 * 1024, Supported: true},
+		},
+		{
+			name:       "not supported case",
+			mockReturn: nvml.ERROR_NOT_SUPPORTED,
+			expected:   PCIeThroughput{},
+		},
+		{
+			name:       "gpu lost case",
+			mockReturn: nvml.ERROR_GPU_IS_LOST,
+			expectErr:  nvmlerrors.ErrGPULost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDevice := &testutil.MockDevice{
+				Device: &mock.Device{
+					GetPcieThroughputFunc: func(counter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+						if counter == nvml.PCIE_UTIL_TX_BYTES {
+							return 2, tt.mockReturn
+						}
+						return 3, tt.mockReturn
+					},
+				},
+			}
+
+			throughput, err := GetPCIeThroughput(mockDevice)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, throughput)
+		})
+	}
+}
@@ -0,0 +1,523 @@
+// Package vgpu tracks the NVIDIA vGPU (GRID) instances on the vGPU hosts,
+// per physical GPU, with the per-VM utilization, memory and licensing state,
+// and attributes the Xids on the vGPU host GPUs to the VMs.
+package vgpu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// Name is the ID of the NVIDIA vGPU component.
+const Name = "accelerator-nvidia-vgpu"
+
+const (
+	// EventNameVGPUXid is the event name of the Xid attributed to the VMs.
+	EventNameVGPUXid = "vgpu_xid"
+
+	// EventKeyVMIDs stores the comma-separated IDs of the VMs attributed to the Xid.
+	EventKeyVMIDs = "vm_ids"
+	// EventKeyVGPUUUIDs stores the comma-separated UUIDs of the vGPUs attributed to the Xid.
+	EventKeyVGPUUUIDs = "vgpu_uuids"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+
+	nvmlInstance nvidianvml.Instance
+	getGPUFunc   func(uuid string, dev device.Device) (GPU, error)
+
+	eventBucket eventstore.Bucket
+	kmsgWatcher kmsg.Watcher
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a NVIDIA vGPU component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		nvmlInstance: gpudInstance.NVMLInstance,
+		getGPUFunc:   GetGPU,
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+
+		if os.Geteuid() == 0 {
+			// e.g., "/dev/kmsg" not mounted into the container
+			// the component runs without the kmsg watcher (degraded without the kmsg capability)
+			c.kmsgWatcher, err = kmsg.NewWatcher()
+			if err != nil {
+				log.Logger.Warnw("kmsg not available, running without kmsg watcher", "component", Name, "error", err)
+				c.kmsgWatcher = nil
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"gpu",
+		"nvidia",
+		"vgpu",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	if c.nvmlInstance == nil {
+		return false
+	}
+	return c.nvmlInstance.NVMLExists() && c.nvmlInstance.ProductName() != ""
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+
+	if c.kmsgWatcher != nil {
+		kmsgCh, err := c.kmsgWatcher.Watch()
+		if err != nil {
+			return err
+		}
+		go c.watchXids(kmsgCh)
+	}
+
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.kmsgWatcher != nil {
+		cerr := c.kmsgWatcher.Close()
+		if cerr != nil {
+			log.Logger.Errorw("failed to close kmsg watcher", "error", cerr)
+		}
+	}
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking nvidia vgpu")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.nvmlInstance == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML instance is nil"
+		return cr
+	}
+	if !c.nvmlInstance.NVMLExists() {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML library is not loaded"
+		return cr
+	}
+	if err := c.nvmlInstance.InitError(); err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = fmt.Sprintf("NVML initialization error: %v", err)
+		cr.suggestedActions = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeRebootSystem,
+			},
+		}
+		return cr
+	}
+	if c.nvmlInstance.ProductName() == "" {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "NVIDIA NVML is loaded but GPU is not detected (missing product name)"
+		return cr
+	}
+
+	// the VMs come and go, thus drop the series of the detached vGPUs
+	metricGPUUtilPercent.Reset()
+	metricMemoryUtilPercent.Reset()
+	metricFBUsedBytes.Reset()
+	metricLicensed.Reset()
+
+	devs := c.nvmlInstance.Devices()
+	labeler := nvidianvml.NewGPULabeler(devs)
+
+	var unlicensed []string
+	for _, r := range nvidianvml.QueryDevices(devs, c.getGPUFunc) {
+		uuid, gpu, err := r.UUID, r.Value, r.Err
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error getting vgpu instances"
+
+			if errors.Is(err, nvmlerrors.ErrGPURequiresReset) {
+				cr.reason = nvmlerrors.ErrGPURequiresReset.Error()
+				cr.suggestedActions = &apiv1.SuggestedActions{
+					Description: nvmlerrors.ErrGPURequiresReset.Error(),
+					RepairActions: []apiv1.RepairActionType{
+						apiv1.RepairActionTypeRebootSystem,
+					},
+				}
+			}
+
+			if errors.Is(err, nvmlerrors.ErrGPULost) {
+				cr.reason = nvmlerrors.ErrGPULost.Error()
+				cr.suggestedActions = &apiv1.SuggestedActions{
+					Description: nvmlerrors.ErrGPULost.Error(),
+					RepairActions: []apiv1.RepairActionType{
+						apiv1.RepairActionTypeRebootSystem,
+					},
+				}
+			}
+
+			log.Logger.Warnw(cr.reason, "error", err)
+			return cr
+		}
+		if !gpu.IsVGPUHost() {
+			continue
+		}
+		cr.GPUs = append(cr.GPUs, gpu)
+
+		metricVGPUs.With(labeler.Labels(uuid)).Set(float64(len(gpu.VGPUs)))
+		for _, v := range gpu.VGPUs {
+			labels := labeler.Labels(uuid)
+			labels["vgpu_uuid"] = v.UUID
+			labels["vm_id"] = v.VMID
+			if v.UtilizationSupported {
+				metricGPUUtilPercent.With(labels).Set(float64(v.GPUUsedPercent))
+				metricMemoryUtilPercent.With(labels).Set(float64(v.MemoryUsedPercent))
+			}
+			metricFBUsedBytes.With(labels).Set(float64(v.FBUsedBytes))
+			licensed := 0.0
+			if v.Licensed {
+				licensed = 1.0
+			}
+			metricLicensed.With(labels).Set(licensed)
+
+			if v.IsUnlicensed() {
+				unlicensed = append(unlicensed, v.VMID)
+			}
+		}
+	}
+
+	if len(cr.GPUs) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no vGPU host GPU found"
+		return cr
+	}
+
+	vgpus := 0
+	for _, gpu := range cr.GPUs {
+		vgpus += len(gpu.VGPUs)
+	}
+
+	if len(unlicensed) > 0 {
+		sort.Strings(unlicensed)
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("%d vGPU(s) unlicensed (VM(s) %s)", len(unlicensed), strings.Join(unlicensed, ", "))
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("%d vGPU(s) on %d vGPU host GPU(s), no issue found", vgpus, len(cr.GPUs))
+
+	return cr
+}
+
+// lastGPUs returns the vGPU host GPUs of the last check.
+func (c *component) lastGPUs() []GPU {
+	c.lastMu.RLock()
+	defer c.lastMu.RUnlock()
+	if c.lastCheckResult == nil {
+		return nil
+	}
+	return c.lastCheckResult.GPUs
+}
+
+func (c *component) watchXids(kmsgCh <-chan kmsg.Message) {
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case message, ok := <-kmsgCh:
+			if !ok {
+				return
+			}
+			if err := c.processXid(message); err != nil {
+				log.Logger.Errorw("failed to record vgpu xid event", "error", err)
+			}
+		}
+	}
+}
+
+// processXid records the Xid event attributed to the VMs on the same GPU,
+// if the message is an Xid on a vGPU host GPU.
+// The Xid is attributed to all the VMs on the GPU, as the host driver does not log the VM.
+func (c *component) processXid(message kmsg.Message) error {
+	if c.eventBucket == nil {
+		return nil
+	}
+
+	xidErr := xid.Match(message.Message)
+	if xidErr == nil {
+		return nil
+	}
+	gpu, found := findGPUByXidBusID(xidErr.DeviceUUID, c.lastGPUs())
+	if !isVGPUXid(xidErr, gpu, found) {
+		return nil
+	}
+
+	vmIDs := make([]string, 0, len(gpu.VGPUs))
+	vgpuUUIDs := make([]string, 0, len(gpu.VGPUs))
+	for _, v := range gpu.VGPUs {
+		vmIDs = append(vmIDs, v.VMID)
+		vgpuUUIDs = append(vgpuUUIDs, v.UUID)
+	}
+
+	var xidName string
+	if xidErr.Detail != nil {
+		xidName = xidErr.Detail.Description
+	}
+	msg := fmt.Sprintf("Xid %d (%s) on %s", xidErr.Xid, xidName, xidErr.DeviceUUID)
+	if len(vmIDs) > 0 {
+		msg += fmt.Sprintf(" hosting VM(s) %s", strings.Join(vmIDs, ", "))
+	}
+
+	event := eventstore.Event{
+		Component: Name,
+		Time:      message.Timestamp.Time,
+		Name:      EventNameVGPUXid,
+		Type:      string(apiv1.EventTypeWarning),
+		Message:   msg,
+		ExtraInfo: map[string]string{
+			xid.EventKeyDeviceUUID: xidErr.DeviceUUID,
+			"xid":                  strconv.Itoa(xidErr.Xid),
+			EventKeyVMIDs:          strings.Join(vmIDs, ","),
+			EventKeyVGPUUUIDs:      strings.Join(vgpuUUIDs, ","),
+		},
+		DedupKey: message.DedupKey(),
+	}
+	if xidErr.Detail != nil && xidErr.Detail.EventType != "" {
+		event.Type = string(xidErr.Detail.EventType)
+	}
+	if pid, name := extractXidProcess(message.Message); pid != "" {
+		event.ExtraInfo["pid"] = pid
+		event.ExtraInfo["process_name"] = name
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	defer ccancel()
+
+	sameEvent, err := c.eventBucket.Find(cctx, event)
+	if err != nil {
+		return err
+	}
+	if sameEvent != nil {
+		return nil
+	}
+	if err := c.eventBucket.Insert(cctx, event); err != nil {
+		return err
+	}
+	log.Logger.Warnw("vgpu xid event", "xid", xidErr.Xid, "deviceUUID", xidErr.DeviceUUID, "vmIDs", vmIDs)
+
+	var devs map[string]device.Device
+	if c.nvmlInstance != nil {
+		devs = c.nvmlInstance.Devices()
+	}
+	labeler := nvidianvml.NewGPULabeler(devs)
+
+	if len(vmIDs) == 0 {
+		vmIDs = []string{""}
+	}
+	for _, vmID := range vmIDs {
+		labels := labeler.Labels(gpu.UUID)
+		labels["vm_id"] = vmID
+		labels["xid"] = strconv.Itoa(xidErr.Xid)
+		metricXids.With(labels).Inc()
+	}
+	return nil
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// GPUs is the vGPU host GPUs, with the active vGPU instances.
+	GPUs []GPU `json:"gpus,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the suggested actions for the last check
+	suggestedActions *apiv1.SuggestedActions
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.GPUs) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"GPU UUID", "vGPU UUID", "Type", "VM ID", "Used %", "FB Used", "License"})
+	for _, gpu := range cr.GPUs {
+		for _, v := range gpu.VGPUs {
+			used := "n/a"
+			if v.UtilizationSupported {
+				used = fmt.Sprintf("%d %%", v.GPUUsedPercent)
+			}
+			table.Append([]string{
+				gpu.UUID,
+				v.UUID,
+				v.Type,
+				v.VMID,
+				used,
+				fmt.Sprintf("%d MiB", v.FBUsedBytes/(1024*1024)),
+				v.LicenseState,
+			})
+		}
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	// propagate suggested actions to health state if present
+	if cr.suggestedActions != nil {
+		state.SuggestedActions = cr.suggestedActions
+	}
+
+	if len(cr.GPUs) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
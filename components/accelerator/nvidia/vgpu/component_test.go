@@ -0,0 +1,225 @@
+package vgpu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// mockInstance implements the nvidianvml.Instance interface for testing
+type mockInstance struct {
+	devices map[string]device.Device
+}
+
+func (m *mockInstance) NVMLExists() bool                  { return true }
+func (m *mockInstance) Library() nvmllib.Library          { return nil }
+func (m *mockInstance) Devices() map[string]device.Device { return m.devices }
+func (m *mockInstance) ProductName() string               { return "Test GPU" }
+func (m *mockInstance) Architecture() string              { return "" }
+func (m *mockInstance) Brand() string                     { return "" }
+func (m *mockInstance) DriverVersion() string             { return "" }
+func (m *mockInstance) DriverMajor() int                  { return 0 }
+func (m *mockInstance) CUDAVersion() string               { return "" }
+func (m *mockInstance) FabricManagerSupported() bool      { return false }
+func (m *mockInstance) FabricStateSupported() bool        { return false }
+func (m *mockInstance) GetMemoryErrorManagementCapabilities() nvidiaproduct.MemoryErrorManagementCapabilities {
+	return nvidiaproduct.MemoryErrorManagementCapabilities{}
+}
+func (m *mockInstance) Shutdown() error  { return nil }
+func (m *mockInstance) InitError() error { return nil }
+
+// createMockVGPUComponent creates a component with mocked functions for testing
+func createMockVGPUComponent(
+	ctx context.Context,
+	nvmlInstance *mockInstance,
+	getGPUFunc func(uuid string, dev device.Device) (GPU, error),
+) *component {
+	cctx, cancel := context.WithCancel(ctx)
+
+	return &component{
+		ctx:    cctx,
+		cancel: cancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		},
+		nvmlInstance: nvmlInstance,
+		getGPUFunc:   getGPUFunc,
+	}
+}
+
+// createMockInstance creates the NVML instance with the two GPUs for testing
+func createMockInstance() *mockInstance {
+	return &mockInstance{
+		devices: map[string]device.Device{
+			"GPU-1": testutil.NewMockDevice(nil, "", "", "", "0000:3b:00.0"),
+			"GPU-2": testutil.NewMockDevice(nil, "", "", "", "0000:5e:00.0"),
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&components.GPUdInstance{
+		RootCtx:      context.Background(),
+		NVMLInstance: &mockInstance{},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+	assert.Contains(t, c.Tags(), "vgpu")
+}
+
+func TestCheck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("no vgpu host", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+			return GPU{UUID: uuid, BusID: dev.PCIBusID(), VirtualizationMode: "none", Supported: true}, nil
+		})
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+		assert.Equal(t, "no vGPU host GPU found", cr.Summary())
+		assert.Nil(t, cr.HealthStates()[0].ExtraInfo)
+	})
+
+	t.Run("licensed vgpus", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+			return GPU{
+				UUID:               uuid,
+				BusID:              dev.PCIBusID(),
+				VirtualizationMode: "host_vgpu",
+				Supported:          true,
+				VGPUs: []VGPU{
+					{UUID: "vgpu-" + uuid, VMID: "vm-" + uuid, Licensed: true, LicenseState: "licensed", UtilizationSupported: true, GPUUsedPercent: 30},
+				},
+			}, nil
+		})
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+		assert.Equal(t, "2 vGPU(s) on 2 vGPU host GPU(s), no issue found", cr.Summary())
+		assert.Contains(t, cr.String(), "vm-GPU-1")
+		assert.Contains(t, cr.HealthStates()[0].ExtraInfo["data"], `"vm_id":"vm-GPU-2"`)
+		assert.Len(t, c.lastGPUs(), 2)
+	})
+
+	t.Run("unlicensed vgpu", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+			v := VGPU{UUID: "vgpu-" + uuid, VMID: "vm-" + uuid, Licensed: true, LicenseState: "licensed"}
+			if uuid == "GPU-2" {
+				v.Licensed = false
+				v.LicenseState = "unlicensed_restricted"
+			}
+			return GPU{UUID: uuid, BusID: dev.PCIBusID(), VirtualizationMode: "host_vgpu", Supported: true, VGPUs: []VGPU{v}}, nil
+		})
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+		assert.Equal(t, "1 vGPU(s) unlicensed (VM(s) vm-GPU-2)", cr.Summary())
+	})
+
+	t.Run("gpu lost", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+			return GPU{}, nvmlerrors.ErrGPULost
+		})
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+		assert.Equal(t, nvmlerrors.ErrGPULost.Error(), cr.Summary())
+		require.NotNil(t, cr.HealthStates()[0].SuggestedActions)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+			return GPU{}, errors.New("nvml error")
+		})
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+		assert.Equal(t, "nvml error", cr.HealthStates()[0].Error)
+	})
+
+	t.Run("nil nvml instance", func(t *testing.T) {
+		c := createMockVGPUComponent(ctx, createMockInstance(), nil)
+		c.nvmlInstance = nil
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	})
+}
+
+func TestProcessXid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, time.Hour)
+	require.NoError(t, err)
+
+	c := createMockVGPUComponent(ctx, createMockInstance(), func(uuid string, dev device.Device) (GPU, error) {
+		gpu := GPU{UUID: uuid, BusID: dev.PCIBusID(), VirtualizationMode: "host_vgpu", Supported: true}
+		if uuid == "GPU-1" {
+			gpu.VGPUs = []VGPU{
+				{UUID: "vgpu-1", VMID: "vm-1", Licensed: true},
+				{UUID: "vgpu-2", VMID: "vm-2", Licensed: true},
+			}
+		}
+		return gpu, nil
+	})
+	c.eventBucket, err = store.Bucket(Name)
+	require.NoError(t, err)
+	defer c.eventBucket.Close()
+	_ = c.Check()
+
+	now := time.Now().UTC()
+	msg := kmsg.Message{
+		Timestamp: metav1.NewTime(now),
+		Message:   "NVRM: Xid (PCI:0000:3b:00): 43, pid=23011, name=vgpu, Ch 00000012",
+	}
+	require.NoError(t, c.processXid(msg))
+	// the same message is recorded once
+	require.NoError(t, c.processXid(msg))
+
+	// no vGPU on the GPU, thus not attributed
+	require.NoError(t, c.processXid(kmsg.Message{
+		Timestamp: metav1.NewTime(now),
+		Message:   "NVRM: Xid (PCI:0000:5e:00): 43, pid=23011, name=python3, Ch 00000012",
+	}))
+	// not an Xid
+	require.NoError(t, c.processXid(kmsg.Message{Timestamp: metav1.NewTime(now), Message: "hello"}))
+
+	evs, err := c.eventBucket.Get(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameVGPUXid, evs[0].Name)
+	assert.Equal(t, "vm-1,vm-2", evs[0].ExtraInfo[EventKeyVMIDs])
+	assert.Equal(t, "vgpu-1,vgpu-2", evs[0].ExtraInfo[EventKeyVGPUUUIDs])
+	assert.Equal(t, "43", evs[0].ExtraInfo["xid"])
+	assert.Equal(t, "23011", evs[0].ExtraInfo["pid"])
+	contractstest.AssertEvents(t, Name, evs...)
+	assert.Contains(t, evs[0].Message, "hosting VM(s) vm-1, vm-2")
+
+	apiEvs, err := c.Events(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, apiEvs, 1)
+	assert.Contains(t, apiEvs[0].Message, "vm-1")
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
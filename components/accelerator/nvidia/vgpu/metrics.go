@@ -0,0 +1,97 @@
+package vgpu
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// SubSystem is the Prometheus subsystem name for the NVIDIA vGPU component.
+const SubSystem = "accelerator_nvidia_vgpu"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricVGPUs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "instances",
+			Help:      "tracks the current number of the active vGPU instances per physical GPU",
+		},
+		nvidianvml.GPUMetricLabelKeys(), // labels are GPU UUID and index
+	).MustCurryWith(componentLabel)
+
+	metricGPUUtilPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "gpu_util_percent",
+			Help:      "tracks the current vGPU SM utilization percent",
+		},
+		nvidianvml.GPUMetricLabelKeys("vgpu_uuid", "vm_id"),
+	).MustCurryWith(componentLabel)
+
+	metricMemoryUtilPercent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "memory_util_percent",
+			Help:      "tracks the current vGPU memory utilization percent",
+		},
+		nvidianvml.GPUMetricLabelKeys("vgpu_uuid", "vm_id"),
+	).MustCurryWith(componentLabel)
+
+	metricFBUsedBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "fb_used_bytes",
+			Help:      "tracks the current vGPU frame buffer memory used in bytes",
+		},
+		nvidianvml.GPUMetricLabelKeys("vgpu_uuid", "vm_id"),
+	).MustCurryWith(componentLabel)
+
+	metricLicensed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "licensed",
+			Help:      "set to 1 if the vGPU guest holds the license, 0 otherwise",
+		},
+		nvidianvml.GPUMetricLabelKeys("vgpu_uuid", "vm_id"),
+	).MustCurryWith(componentLabel)
+
+	metricXids = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "xids_total",
+			Help:      "total number of the Xids on the vGPU host GPUs per VM",
+		},
+		nvidianvml.GPUMetricLabelKeys("vm_id", "xid"),
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricVGPUs,
+		metricGPUUtilPercent,
+		metricMemoryUtilPercent,
+		metricFBUsedBytes,
+		metricLicensed,
+		metricXids,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_instances", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpu_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_memory_util_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_fb_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_licensed", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: SubSystem + "_xids_total", Type: apiv1.MetricTypeCounter, Unit: apiv1.MetricUnitCount},
+	)
+}
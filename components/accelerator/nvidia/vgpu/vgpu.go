@@ -0,0 +1,278 @@
+package vgpu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// GPU represents the vGPU host state of a physical GPU.
+type GPU struct {
+	// Represents the physical GPU UUID.
+	UUID string `json:"uuid"`
+
+	// BusID is the GPU bus ID from the nvml API.
+	//  e.g., "0000:0f:00.0"
+	BusID string `json:"bus_id"`
+
+	// VirtualizationMode is the GPU virtualization mode (e.g., "host_vgpu", "passthrough").
+	VirtualizationMode string `json:"virtualization_mode"`
+
+	// VGPUs is the active vGPU instances on the physical GPU.
+	VGPUs []VGPU `json:"vgpus,omitempty"`
+
+	// Supported is true if the virtualization mode query is supported by the device.
+	Supported bool `json:"supported"`
+}
+
+// IsVGPUHost returns true if the GPU is virtualized to host the vGPU instances.
+func (g GPU) IsVGPUHost() bool {
+	return g.VirtualizationMode == virtualizationModeString(nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU) ||
+		g.VirtualizationMode == virtualizationModeString(nvml.GPU_VIRTUALIZATION_MODE_HOST_VSGA)
+}
+
+// VGPU represents an active vGPU instance, and the VM it is attached to.
+type VGPU struct {
+	// UUID is the vGPU instance UUID.
+	UUID string `json:"uuid"`
+	// Type is the vGPU type name (e.g., "GRID A100-4C").
+	Type string `json:"type,omitempty"`
+
+	// VMID is the ID of the VM the vGPU is attached to,
+	// either the domain ID or the UUID of the VM (see VMIDType).
+	VMID string `json:"vm_id"`
+	// VMIDType is the type of the VM ID ("domain_id" or "uuid").
+	VMIDType string `json:"vm_id_type,omitempty"`
+	// VMDriverVersion is the NVIDIA driver version in the guest VM.
+	VMDriverVersion string `json:"vm_driver_version,omitempty"`
+
+	// FBUsedBytes is the frame buffer memory used by the vGPU.
+	FBUsedBytes uint64 `json:"fb_used_bytes"`
+
+	// UtilizationSupported is true if the vGPU utilization sample was found.
+	UtilizationSupported bool `json:"utilization_supported"`
+	// GPUUsedPercent is the SM utilization of the vGPU.
+	GPUUsedPercent uint32 `json:"gpu_used_percent"`
+	// MemoryUsedPercent is the frame buffer memory bandwidth utilization of the vGPU.
+	MemoryUsedPercent uint32 `json:"memory_used_percent"`
+	// EncoderUsedPercent is the encoder utilization of the vGPU.
+	EncoderUsedPercent uint32 `json:"encoder_used_percent"`
+	// DecoderUsedPercent is the decoder utilization of the vGPU.
+	DecoderUsedPercent uint32 `json:"decoder_used_percent"`
+
+	// Licensed is true if the guest VM holds the vGPU software license.
+	Licensed bool `json:"licensed"`
+	// LicenseState is the license state reported by the guest driver
+	// (e.g., "licensed", "unlicensed_restricted").
+	LicenseState string `json:"license_state,omitempty"`
+	// LicenseExpiry is the license expiry in RFC3339 UTC, if the expiry is valid.
+	LicenseExpiry string `json:"license_expiry,omitempty"`
+}
+
+// IsUnlicensed returns true if the guest is running without the license,
+// either already restricted or in the unlicensed state
+// (the performance is degraded once the grace period ends).
+func (v VGPU) IsUnlicensed() bool {
+	if v.Licensed {
+		return false
+	}
+	return v.LicenseState == licenseStateString(nvml.GRID_LICENSE_STATE_UNLICENSED_RESTRICTED) ||
+		v.LicenseState == licenseStateString(nvml.GRID_LICENSE_STATE_UNLICENSED)
+}
+
+// GetGPU returns the vGPU host state of the device, with all its active vGPU instances.
+func GetGPU(uuid string, dev device.Device) (GPU, error) {
+	gpu := GPU{
+		UUID:      uuid,
+		BusID:     dev.PCIBusID(),
+		Supported: true,
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlVgpu.html
+	mode, ret := dev.GetVirtualizationMode()
+	if nvmlerrors.IsNotSupportError(ret) {
+		gpu.Supported = false
+		return gpu, nil
+	}
+	if nvmlerrors.IsGPULostError(ret) {
+		return gpu, nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return gpu, nvmlerrors.ErrGPURequiresReset
+	}
+	if ret != nvml.SUCCESS {
+		return gpu, fmt.Errorf("failed to get device virtualization mode: %v", nvml.ErrorString(ret))
+	}
+	gpu.VirtualizationMode = virtualizationModeString(mode)
+	if !gpu.IsVGPUHost() {
+		return gpu, nil
+	}
+
+	instances, ret := dev.GetActiveVgpus()
+	if nvmlerrors.IsGPULostError(ret) {
+		return gpu, nvmlerrors.ErrGPULost
+	}
+	if nvmlerrors.IsGPURequiresReset(ret) {
+		return gpu, nvmlerrors.ErrGPURequiresReset
+	}
+	if ret != nvml.SUCCESS {
+		return gpu, fmt.Errorf("failed to get active vgpus: %v", nvml.ErrorString(ret))
+	}
+	if len(instances) == 0 {
+		return gpu, nil
+	}
+
+	// utilization is best-effort, not every vGPU type reports the samples
+	var utils map[uint32]nvml.VgpuInstanceUtilizationSample
+	valType, samples, ret := dev.GetVgpuUtilization(0)
+	if ret == nvml.SUCCESS {
+		utils = make(map[uint32]nvml.VgpuInstanceUtilizationSample, len(samples))
+		for _, s := range samples {
+			// keep the latest sample of each instance
+			if prev, ok := utils[s.VgpuInstance]; !ok || s.TimeStamp >= prev.TimeStamp {
+				utils[s.VgpuInstance] = s
+			}
+		}
+	}
+
+	for _, inst := range instances {
+		v, err := getVGPU(inst)
+		if err != nil {
+			return gpu, err
+		}
+		if id, ok := vgpuInstanceID(inst); ok {
+			if s, ok := utils[id]; ok {
+				v.UtilizationSupported = true
+				v.GPUUsedPercent = sampleValuePercent(valType, s.SmUtil)
+				v.MemoryUsedPercent = sampleValuePercent(valType, s.MemUtil)
+				v.EncoderUsedPercent = sampleValuePercent(valType, s.EncUtil)
+				v.DecoderUsedPercent = sampleValuePercent(valType, s.DecUtil)
+			}
+		}
+		gpu.VGPUs = append(gpu.VGPUs, v)
+	}
+
+	return gpu, nil
+}
+
+func getVGPU(inst nvml.VgpuInstance) (VGPU, error) {
+	var v VGPU
+
+	uuid, ret := inst.GetUUID()
+	if ret != nvml.SUCCESS {
+		return v, fmt.Errorf("failed to get vgpu uuid: %v", nvml.ErrorString(ret))
+	}
+	v.UUID = uuid
+
+	vmID, vmIDType, ret := inst.GetVmID()
+	if ret != nvml.SUCCESS {
+		return v, fmt.Errorf("failed to get vm id of vgpu %s: %v", uuid, nvml.ErrorString(ret))
+	}
+	v.VMID = vmID
+	switch vmIDType {
+	case nvml.VGPU_VM_ID_DOMAIN_ID:
+		v.VMIDType = "domain_id"
+	case nvml.VGPU_VM_ID_UUID:
+		v.VMIDType = "uuid"
+	}
+
+	fbUsage, ret := inst.GetFbUsage()
+	if ret != nvml.SUCCESS {
+		return v, fmt.Errorf("failed to get frame buffer usage of vgpu %s: %v", uuid, nvml.ErrorString(ret))
+	}
+	v.FBUsedBytes = fbUsage
+
+	// the type and guest driver fields are informational,
+	// e.g., the guest driver version is not available until the guest driver loads
+	if typeID, ret := inst.GetType(); ret == nvml.SUCCESS && typeID != nil {
+		if name, ret := typeID.GetName(); ret == nvml.SUCCESS {
+			v.Type = name
+		}
+	}
+	if version, ret := inst.GetVmDriverVersion(); ret == nvml.SUCCESS {
+		v.VMDriverVersion = version
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/structnvmlVgpuLicenseInfo__t.html
+	licenseInfo, ret := inst.GetLicenseInfo()
+	if ret == nvml.SUCCESS {
+		v.Licensed = licenseInfo.IsLicensed != 0
+		v.LicenseState = licenseStateString(licenseInfo.CurrentState)
+		if licenseInfo.LicenseExpiry.Status == nvml.GRID_LICENSE_EXPIRY_VALID {
+			exp := licenseInfo.LicenseExpiry
+			v.LicenseExpiry = fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ", exp.Year, exp.Month, exp.Day, exp.Hour, exp.Min, exp.Sec)
+		}
+	} else if !nvmlerrors.IsNotSupportError(ret) {
+		return v, fmt.Errorf("failed to get license info of vgpu %s: %v", uuid, nvml.ErrorString(ret))
+	}
+
+	return v, nil
+}
+
+// vgpuInstanceID returns the NVML vGPU instance ID, to match the utilization samples.
+// The go-nvml handle is the instance ID itself, but not exported.
+func vgpuInstanceID(inst nvml.VgpuInstance) (uint32, bool) {
+	rv := reflect.ValueOf(inst)
+	if rv.Kind() != reflect.Uint32 {
+		return 0, false
+	}
+	return uint32(rv.Uint()), true
+}
+
+// sampleValuePercent decodes the NVML sample value as a percent.
+func sampleValuePercent(valType nvml.ValueType, v [8]byte) uint32 {
+	switch valType {
+	case nvml.VALUE_TYPE_DOUBLE:
+		f := math.Float64frombits(binary.LittleEndian.Uint64(v[:]))
+		if f < 0 {
+			return 0
+		}
+		return uint32(f)
+	case nvml.VALUE_TYPE_UNSIGNED_LONG, nvml.VALUE_TYPE_UNSIGNED_LONG_LONG:
+		return uint32(binary.LittleEndian.Uint64(v[:]))
+	case nvml.VALUE_TYPE_UNSIGNED_SHORT:
+		return uint32(binary.LittleEndian.Uint16(v[:2]))
+	default:
+		return binary.LittleEndian.Uint32(v[:4])
+	}
+}
+
+func virtualizationModeString(mode nvml.GpuVirtualizationMode) string {
+	switch mode {
+	case nvml.GPU_VIRTUALIZATION_MODE_NONE:
+		return "none"
+	case nvml.GPU_VIRTUALIZATION_MODE_PASSTHROUGH:
+		return "passthrough"
+	case nvml.GPU_VIRTUALIZATION_MODE_VGPU:
+		return "vgpu"
+	case nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU:
+		return "host_vgpu"
+	case nvml.GPU_VIRTUALIZATION_MODE_HOST_VSGA:
+		return "host_vsga"
+	default:
+		return fmt.Sprintf("unknown(%d)", mode)
+	}
+}
+
+func licenseStateString(state uint32) string {
+	switch state {
+	case nvml.GRID_LICENSE_STATE_UNINITIALIZED:
+		return "uninitialized"
+	case nvml.GRID_LICENSE_STATE_UNLICENSED_UNRESTRICTED:
+		return "unlicensed_unrestricted"
+	case nvml.GRID_LICENSE_STATE_UNLICENSED_RESTRICTED:
+		return "unlicensed_restricted"
+	case nvml.GRID_LICENSE_STATE_UNLICENSED:
+		return "unlicensed"
+	case nvml.GRID_LICENSE_STATE_LICENSED:
+		return "licensed"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,166 @@
+package vgpu
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func newMockVgpuInstance(uuid, vmID string, license nvml.VgpuLicenseInfo) *mock.VgpuInstance {
+	return &mock.VgpuInstance{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return uuid, nvml.SUCCESS
+		},
+		GetVmIDFunc: func() (string, nvml.VgpuVmIdType, nvml.Return) {
+			return vmID, nvml.VGPU_VM_ID_UUID, nvml.SUCCESS
+		},
+		GetFbUsageFunc: func() (uint64, nvml.Return) {
+			return 2 * 1024 * 1024 * 1024, nvml.SUCCESS
+		},
+		GetTypeFunc: func() (nvml.VgpuTypeId, nvml.Return) {
+			return &mock.VgpuTypeId{
+				GetNameFunc: func() (string, nvml.Return) {
+					return "GRID A100-4C", nvml.SUCCESS
+				},
+			}, nvml.SUCCESS
+		},
+		GetVmDriverVersionFunc: func() (string, nvml.Return) {
+			return "550.54.15", nvml.SUCCESS
+		},
+		GetLicenseInfoFunc: func() (nvml.VgpuLicenseInfo, nvml.Return) {
+			return license, nvml.SUCCESS
+		},
+	}
+}
+
+func newMockVGPUHostDevice(mode nvml.GpuVirtualizationMode, modeRet nvml.Return, instances ...nvml.VgpuInstance) *testutil.MockDevice {
+	return testutil.NewMockDevice(&mock.Device{
+		GetVirtualizationModeFunc: func() (nvml.GpuVirtualizationMode, nvml.Return) {
+			return mode, modeRet
+		},
+		GetActiveVgpusFunc: func() ([]nvml.VgpuInstance, nvml.Return) {
+			return instances, nvml.SUCCESS
+		},
+		GetVgpuUtilizationFunc: func(uint64) (nvml.ValueType, []nvml.VgpuInstanceUtilizationSample, nvml.Return) {
+			return nvml.VALUE_TYPE_UNSIGNED_INT, nil, nvml.SUCCESS
+		},
+	}, "test-arch", "test-brand", "test-cuda", "0000:3b:00.0")
+}
+
+func TestGetGPU(t *testing.T) {
+	licensed := nvml.VgpuLicenseInfo{
+		IsLicensed:   1,
+		CurrentState: nvml.GRID_LICENSE_STATE_LICENSED,
+		LicenseExpiry: nvml.VgpuLicenseExpiry{
+			Year: 2026, Month: 3, Day: 4, Hour: 5, Min: 6, Sec: 7,
+			Status: nvml.GRID_LICENSE_EXPIRY_VALID,
+		},
+	}
+	unlicensed := nvml.VgpuLicenseInfo{CurrentState: nvml.GRID_LICENSE_STATE_UNLICENSED_RESTRICTED}
+
+	t.Run("vgpu host", func(t *testing.T) {
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU, nvml.SUCCESS,
+			newMockVgpuInstance("vgpu-1", "vm-1", licensed),
+			newMockVgpuInstance("vgpu-2", "vm-2", unlicensed),
+		)
+
+		gpu, err := GetGPU("GPU-1", dev)
+		require.NoError(t, err)
+		assert.True(t, gpu.Supported)
+		assert.True(t, gpu.IsVGPUHost())
+		assert.Equal(t, "0000:3b:00.0", gpu.BusID)
+		require.Len(t, gpu.VGPUs, 2)
+
+		assert.Equal(t, "vgpu-1", gpu.VGPUs[0].UUID)
+		assert.Equal(t, "vm-1", gpu.VGPUs[0].VMID)
+		assert.Equal(t, "uuid", gpu.VGPUs[0].VMIDType)
+		assert.Equal(t, "GRID A100-4C", gpu.VGPUs[0].Type)
+		assert.Equal(t, "550.54.15", gpu.VGPUs[0].VMDriverVersion)
+		assert.Equal(t, uint64(2*1024*1024*1024), gpu.VGPUs[0].FBUsedBytes)
+		assert.True(t, gpu.VGPUs[0].Licensed)
+		assert.Equal(t, "licensed", gpu.VGPUs[0].LicenseState)
+		assert.Equal(t, "2026-03-04T05:06:07Z", gpu.VGPUs[0].LicenseExpiry)
+		assert.False(t, gpu.VGPUs[0].IsUnlicensed())
+		// mock instances are not the NVML handles, thus no matching sample
+		assert.False(t, gpu.VGPUs[0].UtilizationSupported)
+
+		assert.False(t, gpu.VGPUs[1].Licensed)
+		assert.Equal(t, "unlicensed_restricted", gpu.VGPUs[1].LicenseState)
+		assert.Empty(t, gpu.VGPUs[1].LicenseExpiry)
+		assert.True(t, gpu.VGPUs[1].IsUnlicensed())
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_PASSTHROUGH, nvml.SUCCESS)
+		gpu, err := GetGPU("GPU-1", dev)
+		require.NoError(t, err)
+		assert.False(t, gpu.IsVGPUHost())
+		assert.Equal(t, "passthrough", gpu.VirtualizationMode)
+		assert.Empty(t, gpu.VGPUs)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_NONE, nvml.ERROR_NOT_SUPPORTED)
+		gpu, err := GetGPU("GPU-1", dev)
+		require.NoError(t, err)
+		assert.False(t, gpu.Supported)
+		assert.False(t, gpu.IsVGPUHost())
+	})
+
+	t.Run("gpu lost", func(t *testing.T) {
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_NONE, nvml.ERROR_GPU_IS_LOST)
+		_, err := GetGPU("GPU-1", dev)
+		assert.ErrorIs(t, err, nvmlerrors.ErrGPULost)
+	})
+
+	t.Run("vm id error", func(t *testing.T) {
+		inst := newMockVgpuInstance("vgpu-1", "vm-1", licensed)
+		inst.GetVmIDFunc = func() (string, nvml.VgpuVmIdType, nvml.Return) {
+			return "", 0, nvml.ERROR_UNKNOWN
+		}
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU, nvml.SUCCESS, inst)
+		_, err := GetGPU("GPU-1", dev)
+		assert.Error(t, err)
+	})
+
+	t.Run("license not supported", func(t *testing.T) {
+		inst := newMockVgpuInstance("vgpu-1", "vm-1", licensed)
+		inst.GetLicenseInfoFunc = func() (nvml.VgpuLicenseInfo, nvml.Return) {
+			return nvml.VgpuLicenseInfo{}, nvml.ERROR_NOT_SUPPORTED
+		}
+		dev := newMockVGPUHostDevice(nvml.GPU_VIRTUALIZATION_MODE_HOST_VGPU, nvml.SUCCESS, inst)
+		gpu, err := GetGPU("GPU-1", dev)
+		require.NoError(t, err)
+		require.Len(t, gpu.VGPUs, 1)
+		assert.Empty(t, gpu.VGPUs[0].LicenseState)
+		assert.False(t, gpu.VGPUs[0].IsUnlicensed())
+	})
+}
+
+func TestSampleValuePercent(t *testing.T) {
+	var v [8]byte
+	binary.LittleEndian.PutUint32(v[:4], 42)
+	assert.Equal(t, uint32(42), sampleValuePercent(nvml.VALUE_TYPE_UNSIGNED_INT, v))
+
+	binary.LittleEndian.PutUint64(v[:], 77)
+	assert.Equal(t, uint32(77), sampleValuePercent(nvml.VALUE_TYPE_UNSIGNED_LONG_LONG, v))
+
+	binary.LittleEndian.PutUint64(v[:], math.Float64bits(12.7))
+	assert.Equal(t, uint32(12), sampleValuePercent(nvml.VALUE_TYPE_DOUBLE, v))
+
+	binary.LittleEndian.PutUint64(v[:], math.Float64bits(-1))
+	assert.Equal(t, uint32(0), sampleValuePercent(nvml.VALUE_TYPE_DOUBLE, v))
+}
+
+func TestVGPUInstanceID(t *testing.T) {
+	_, ok := vgpuInstanceID(&mock.VgpuInstance{})
+	assert.False(t, ok)
+}
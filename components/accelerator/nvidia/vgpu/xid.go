@@ -0,0 +1,46 @@
+package vgpu
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+)
+
+// XidVGPUStartError is the Xid logged when a vGPU fails to start on the host
+// (e.g., the guest requested an unsupported or misconfigured vGPU type).
+// ref. https://docs.nvidia.com/deploy/xid-errors/index.html
+const XidVGPUStartError = 78
+
+// e.g.,
+// NVRM: Xid (PCI:0000:3b:00): 43, pid=23011, name=vgpu, Ch 00000012
+var compiledRegexXidProcess = regexp.MustCompile(`NVRM: Xid \(PCI:[0-9a-fA-F:.]+\): \d+, pid=([^,]+), name=([^,]+)`)
+
+// extractXidProcess returns the pid and the process name of the Xid, if logged.
+func extractXidProcess(line string) (string, string) {
+	if match := compiledRegexXidProcess.FindStringSubmatch(line); len(match) == 3 {
+		return strings.Trim(match[1], "'"), strings.TrimSpace(match[2])
+	}
+	return "", ""
+}
+
+// findGPUByXidBusID returns the GPU the Xid is logged for, or false if not found.
+// The Xid bus ID omits the PCI function (e.g., "PCI:0000:3b:00" for "0000:3b:00.0").
+func findGPUByXidBusID(xidBusID string, gpus []GPU) (GPU, bool) {
+	prefix := strings.ToLower(strings.TrimPrefix(xidBusID, "PCI:")) + "."
+	for _, gpu := range gpus {
+		if strings.HasPrefix(strings.ToLower(gpu.BusID), prefix) {
+			return gpu, true
+		}
+	}
+	return GPU{}, false
+}
+
+// isVGPUXid returns true if the Xid should be attributed to the VMs:
+// the vGPU start error, or any Xid on the GPU hosting the vGPU instances.
+func isVGPUXid(xidErr *xid.Error, gpu GPU, found bool) bool {
+	if xidErr.Xid == XidVGPUStartError {
+		return true
+	}
+	return found && len(gpu.VGPUs) > 0
+}
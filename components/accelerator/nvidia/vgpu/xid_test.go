@@ -0,0 +1,50 @@
+package vgpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+)
+
+func TestExtractXidProcess(t *testing.T) {
+	pid, name := extractXidProcess("NVRM: Xid (PCI:0000:3b:00): 43, pid=23011, name=vgpu, Ch 00000012")
+	assert.Equal(t, "23011", pid)
+	assert.Equal(t, "vgpu", name)
+
+	pid, name = extractXidProcess("NVRM: Xid (PCI:0000:05:00): 79, pid='<unknown>', name=<unknown>, GPU has fallen off the bus.")
+	assert.Equal(t, "<unknown>", pid)
+	assert.Equal(t, "<unknown>", name)
+
+	pid, name = extractXidProcess("NVRM: Xid (PCI:0000:3b:00): 78, vGPU Start Error")
+	assert.Empty(t, pid)
+	assert.Empty(t, name)
+}
+
+func TestFindGPUByXidBusID(t *testing.T) {
+	gpus := []GPU{
+		{UUID: "GPU-1", BusID: "0000:3B:00.0"},
+		{UUID: "GPU-2", BusID: "0000:5e:00.0"},
+	}
+
+	gpu, ok := findGPUByXidBusID("PCI:0000:3b:00", gpus)
+	assert.True(t, ok)
+	assert.Equal(t, "GPU-1", gpu.UUID)
+
+	gpu, ok = findGPUByXidBusID("PCI:0000:5e:00", gpus)
+	assert.True(t, ok)
+	assert.Equal(t, "GPU-2", gpu.UUID)
+
+	_, ok = findGPUByXidBusID("PCI:0000:3b:01", gpus)
+	assert.False(t, ok)
+}
+
+func TestIsVGPUXid(t *testing.T) {
+	host := GPU{UUID: "GPU-1", VGPUs: []VGPU{{UUID: "vgpu-1", VMID: "vm-1"}}}
+
+	assert.True(t, isVGPUXid(&xid.Error{Xid: 43}, host, true))
+	assert.False(t, isVGPUXid(&xid.Error{Xid: 43}, GPU{UUID: "GPU-1"}, true))
+	assert.False(t, isVGPUXid(&xid.Error{Xid: 43}, GPU{}, false))
+	assert.True(t, isVGPUXid(&xid.Error{Xid: XidVGPUStartError}, GPU{}, false))
+}
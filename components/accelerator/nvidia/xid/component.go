@@ -18,12 +18,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/olekukonko/tablewriter"
-	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/disposition"
 	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/kmsg"
 	"github.com/leptonai/gpud/pkg/log"
@@ -59,12 +60,18 @@ type component struct {
 	devices      map[string]device.Device
 
 	getTimeNowFunc   func() time.Time
+	getBootTimeFunc  func() time.Time
 	getThresholdFunc func() RebootThreshold
 
 	rebootEventStore pkghost.RebootEventStore
 	eventBucket      eventstore.Bucket
 	kmsgWatcher      kmsg.Watcher
 
+	// dispositions downgrades the Xids marked benign by the operators, nil if not set up
+	dispositions *disposition.Manager
+	// resets downgrades the Xids within the GPU reset windows, nil if not set up
+	resets *gpureset.Tracker
+
 	readAllKmsg  func(context.Context) ([]kmsg.Message, error)
 	extraEventCh chan *eventstore.Event
 
@@ -86,9 +93,12 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		getTimeNowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
+		getBootTimeFunc:  pkghost.BootTime,
 		getThresholdFunc: GetDefaultRebootThreshold,
 
 		rebootEventStore: gpudInstance.RebootEventStore,
+		dispositions:     gpudInstance.EventDispositions,
+		resets:           gpudInstance.GPUResets,
 		extraEventCh:     make(chan *eventstore.Event, 256),
 	}
 
@@ -105,10 +115,12 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		}
 
 		if os.Geteuid() == 0 {
+			// e.g., "/dev/kmsg" not mounted into the container
+			// the component runs without the kmsg watcher (degraded without the kmsg capability)
 			c.kmsgWatcher, err = kmsg.NewWatcher()
 			if err != nil {
-				ccancel()
-				return nil, err
+				log.Logger.Warnw("kmsg not available, running without kmsg watcher", "component", Name, "error", err)
+				c.kmsgWatcher = nil
 			}
 		}
 	}
@@ -158,6 +170,12 @@ func (c *component) Start() error {
 		}
 	}
 
+	// the previous boot errors are no longer in the kmsg,
+	// thus verify the remediation once from the stored events
+	if err := c.verifyDBERemediations(c.ctx); err != nil {
+		log.Logger.Warnw("failed to verify dbe remediation", "error", err)
+	}
+
 	if c.kmsgWatcher != nil {
 		kmsgCh, err := c.kmsgWatcher.Watch()
 		if err != nil {
@@ -185,6 +203,8 @@ func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events,
 		return nil, err
 	}
 
+	events = c.applyResetWindows(ctx, events, since)
+
 	var ret apiv1.Events
 	for _, event := range events {
 		ev := resolveXIDEvent(event, c.devices)
@@ -392,6 +412,18 @@ func (cr *checkResult) String() string {
 	return strings.Join(outputs, "\n\n")
 }
 
+// XidErrors returns the Xid errors found, for comparing the scan results.
+func (cr *checkResult) XidErrors() []Error {
+	if cr == nil {
+		return nil
+	}
+	errs := make([]Error, 0, len(cr.FoundErrors))
+	for _, foundErr := range cr.FoundErrors {
+		errs = append(errs, foundErr.Error)
+	}
+	return errs
+}
+
 func (cr *checkResult) Summary() string {
 	if cr == nil {
 		return ""
@@ -467,6 +499,17 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 		case message := <-kmsgCh:
 			xidErr := Match(message.Message)
 			if xidErr == nil {
+				// the driver reload starts the reset window of the following Xids
+				reloaded, err := c.resets.ObserveKmsg(c.ctx, message)
+				if err != nil {
+					log.Logger.Errorw("failed to record gpu reset window", "error", err)
+				}
+				if reloaded {
+					if err := c.updateCurrentState(); err != nil {
+						log.Logger.Errorw("failed to update current state", "error", err)
+					}
+					continue
+				}
 				log.Logger.Debugw("not xid event, skip", "kmsg", message)
 				continue
 			}
@@ -486,12 +529,16 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				continue
 			}
 
+			// the static Xid details may be reclassified on the newer driver branches
+			var detailVariant string
+			xidErr.Detail, detailVariant = resolveDetailForDriver(xidErr.Xid, xidErr.Detail, c.nvmlInstance.DriverMajor())
+
 			id := uuid.New()
 			var xidName string
 			if xidErr.Detail != nil {
 				xidName = xidErr.Detail.Description
 			}
-			logger := log.Logger.With("id", id, "xid", xidErr.Xid, "xidName", xidName, "deviceUUID", xidErr.DeviceUUID)
+			logger := log.Logger.With("id", id, "xid", xidErr.Xid, "xidName", xidName, "deviceUUID", xidErr.DeviceUUID, "detailVariant", detailVariant)
 			logger.Infow("got xid event", "kmsg", message, "kmsgTimestamp", message.Timestamp.Unix())
 
 			xidValue, ok := uint64FromInt(xidErr.Xid)
@@ -524,8 +571,10 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				Time: message.Timestamp.Time,
 				Name: EventNameErrorXid,
 				ExtraInfo: map[string]string{
-					EventKeyDeviceUUID: xidErr.DeviceUUID,
+					EventKeyDeviceUUID:    xidErr.DeviceUUID,
+					EventKeyDetailVariant: detailVariant,
 				},
+				DedupKey: message.DedupKey(),
 			}
 			// IMPORTANT: Set event.Type from Match() result to preserve precise unit-based severity.
 			//
@@ -566,10 +615,9 @@ func (c *component) start(kmsgCh <-chan kmsg.Message, updatePeriod time.Duration
 				continue
 			}
 			logger.Infow("inserted the event successfully")
-			metricXIDErrs.With(prometheus.Labels{
-				"uuid": convertBusIDToUUID(xidErr.DeviceUUID, c.devices),
-				"xid":  strconv.Itoa(xidErr.Xid),
-			}).Inc()
+			labels := nvidianvml.NewGPULabeler(c.devices).Labels(convertBusIDToUUID(xidErr.DeviceUUID, c.devices))
+			labels["xid"] = strconv.Itoa(xidErr.Xid)
+			metricXIDErrs.With(labels).Inc()
 			if err = c.updateCurrentState(); err != nil {
 				logger.Errorw("failed to update current state", "error", err)
 				continue
@@ -598,7 +646,8 @@ func (c *component) updateCurrentState() error {
 		return fmt.Errorf("failed to get all events: %w", err)
 	}
 	localEvents = trimEventsAfterSetHealthy(localEvents)
-	events := mergeEvents(rebootEvents, localEvents)
+	localEvents = c.applyResetWindows(c.ctx, localEvents, now.Add(-GetLookbackPeriod()))
+	events := mergeEvents(rebootEvents, c.applyDispositions(localEvents))
 
 	c.mu.Lock()
 	c.currState = evolveHealthyState(events, c.devices, rebootThreshold.Threshold)
@@ -1625,3 +1625,17 @@ func TestStartWithXID63And64NotSkippedWhenNoRowRemapping(t *testing.T) {
 	states := comp.LastHealthStates()
 	assert.NotNil(t, states)
 }
+
+func TestCheckResultXidErrors(t *testing.T) {
+	var cr *checkResult
+	assert.Nil(t, cr.XidErrors())
+
+	cr = &checkResult{FoundErrors: []FoundError{
+		{Error: Error{Xid: 79, DeviceUUID: "PCI:0000:9b:00"}},
+		{Error: Error{Xid: 48, DeviceUUID: "PCI:0000:9c:00"}},
+	}}
+	assert.Equal(t, []Error{
+		{Xid: 79, DeviceUUID: "PCI:0000:9b:00"},
+		{Xid: 48, DeviceUUID: "PCI:0000:9c:00"},
+	}, cr.XidErrors())
+}
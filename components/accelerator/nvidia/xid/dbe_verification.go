@@ -0,0 +1,240 @@
+package xid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+const (
+	// EventNameDBERemediationVerified is emitted on the first start after the reboot
+	// following the ECC DBE related Xids, when the page retirement (or row remapping)
+	// completed and the volatile ECC error counts were reset.
+	EventNameDBERemediationVerified = "dbe_remediation_verified"
+	// EventNameDBERemediationFailed is emitted on the first start after the reboot
+	// following the ECC DBE related Xids, when the reboot did not remediate the errors.
+	EventNameDBERemediationFailed = "dbe_remediation_failed"
+
+	// EventKeyDBEXids stores the comma-separated DBE related Xids being verified.
+	EventKeyDBEXids = "xids"
+	// EventKeyDBEMechanism stores how the error address is taken offline
+	// ("row_remapping" or "page_retirement").
+	EventKeyDBEMechanism = "mechanism"
+	// EventKeyDBEOfflineAddresses stores the number of the remapped rows (or retired pages)
+	// due to the uncorrectable errors.
+	EventKeyDBEOfflineAddresses = "offline_addresses"
+	// EventKeyDBEVolatileUncorrected stores the volatile uncorrectable ECC error count.
+	EventKeyDBEVolatileUncorrected = "volatile_uncorrected"
+	// EventKeyDBEFailures stores the semicolon-separated failed checks.
+	EventKeyDBEFailures = "failures"
+
+	dbeMechanismRowRemapping   = "row_remapping"
+	dbeMechanismPageRetirement = "page_retirement"
+)
+
+// dbeRelatedXids are the Xids of the ECC double bit (uncorrectable) errors,
+// remediated by the reboot that retires (or remaps) the error address.
+var dbeRelatedXids = map[uint64]struct{}{
+	48: {}, // DBE (double bit error) ECC error
+	63: {}, // ECC page retirement or row remapping recording event
+	64: {}, // ECC page retirement or row remapper recording failure
+	94: {}, // contained ECC error
+	95: {}, // uncontained ECC error
+}
+
+// dbeVerification is the post-reboot verification result of a GPU.
+type dbeVerification struct {
+	uuid string
+	xids []uint64
+
+	mechanism           string
+	offlineAddresses    int
+	volatileUncorrected uint64
+
+	failures []string
+}
+
+func (v dbeVerification) passed() bool {
+	return len(v.failures) == 0
+}
+
+// pendingDBEVerifications returns the DBE related Xids before the boot
+// that are not yet verified, keyed by the GPU UUID (or the bus ID if unresolved).
+// The events are expected to be sorted by time in descending order.
+func pendingDBEVerifications(events eventstore.Events, bootTime time.Time, devices map[string]device.Device) map[string][]uint64 {
+	pending := make(map[string][]uint64)
+	for _, ev := range slices.Backward(events) {
+		switch ev.Name {
+		case EventNameDBERemediationVerified, EventNameDBERemediationFailed:
+			delete(pending, ev.ExtraInfo[EventKeyDeviceUUID])
+
+		case EventNameErrorXid:
+			// the errors of the current boot are verified after the next reboot
+			if !ev.Time.Before(bootTime) {
+				continue
+			}
+
+			var detail xidErrorEventDetail
+			if err := json.Unmarshal([]byte(ev.ExtraInfo[EventKeyErrorXidData]), &detail); err != nil {
+				continue
+			}
+			if _, ok := dbeRelatedXids[detail.Xid]; !ok {
+				continue
+			}
+
+			id := ev.ExtraInfo[EventKeyDeviceUUID]
+			if uuid := convertBusIDToUUID(id, devices); uuid != "" {
+				id = uuid
+			}
+			if !slices.Contains(pending[id], detail.Xid) {
+				pending[id] = append(pending[id], detail.Xid)
+			}
+		}
+	}
+	return pending
+}
+
+// verifyDBERemediation checks the GPU memory error remediation after the reboot:
+// the page retirement (or row remapping) is no longer pending, the error address is offline,
+// and the volatile uncorrectable ECC error counts were reset.
+func verifyDBERemediation(uuid string, xids []uint64, dev device.Device) dbeVerification {
+	v := dbeVerification{uuid: uuid, xids: xids}
+	if dev == nil {
+		v.failures = append(v.failures, "GPU not found after reboot")
+		return v
+	}
+
+	_, uncRows, isPending, failureOccurred, ret := dev.GetRemappedRows()
+	switch {
+	case ret == nvml.SUCCESS:
+		v.mechanism = dbeMechanismRowRemapping
+		v.offlineAddresses = uncRows
+		if isPending {
+			v.failures = append(v.failures, "row remapping still pending")
+		}
+		if failureOccurred {
+			v.failures = append(v.failures, "row remapping failed")
+		}
+		if uncRows == 0 {
+			v.failures = append(v.failures, "no row remapped for the uncorrectable errors")
+		}
+
+	case nvmlerrors.IsNotSupportError(ret):
+		// pre-Ampere GPUs retire the pages instead
+		v.mechanism = dbeMechanismPageRetirement
+		pendingStatus, ret := dev.GetRetiredPagesPendingStatus()
+		if ret != nvml.SUCCESS {
+			v.failures = append(v.failures, fmt.Sprintf("failed to get retired pages pending status: %s", nvml.ErrorString(ret)))
+		} else if pendingStatus == nvml.FEATURE_ENABLED {
+			v.failures = append(v.failures, "page retirement still pending")
+		}
+
+		pages, ret := dev.GetRetiredPages(nvml.PAGE_RETIREMENT_CAUSE_DOUBLE_BIT_ECC_ERROR)
+		if ret != nvml.SUCCESS {
+			v.failures = append(v.failures, fmt.Sprintf("failed to get retired pages: %s", nvml.ErrorString(ret)))
+		} else {
+			v.offlineAddresses = len(pages)
+			if len(pages) == 0 {
+				v.failures = append(v.failures, "no page retired for the double bit errors")
+			}
+		}
+
+	default:
+		v.failures = append(v.failures, fmt.Sprintf("failed to get remapped rows: %s", nvml.ErrorString(ret)))
+	}
+
+	cnt, ret := dev.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.VOLATILE_ECC)
+	switch {
+	case ret == nvml.SUCCESS:
+		v.volatileUncorrected = cnt
+		if cnt > 0 {
+			v.failures = append(v.failures, fmt.Sprintf("volatile uncorrectable ECC error count not reset (%d)", cnt))
+		}
+	case nvmlerrors.IsNotSupportError(ret):
+		// ECC mode disabled
+	default:
+		v.failures = append(v.failures, fmt.Sprintf("failed to get volatile ECC errors: %s", nvml.ErrorString(ret)))
+	}
+
+	return v
+}
+
+func (v dbeVerification) event(now time.Time) eventstore.Event {
+	xids := make([]string, 0, len(v.xids))
+	for _, x := range v.xids {
+		xids = append(xids, strconv.FormatUint(x, 10))
+	}
+
+	ev := eventstore.Event{
+		Time: now,
+		ExtraInfo: map[string]string{
+			EventKeyDeviceUUID:             v.uuid,
+			EventKeyDBEXids:                strings.Join(xids, ","),
+			EventKeyDBEMechanism:           v.mechanism,
+			EventKeyDBEOfflineAddresses:    strconv.Itoa(v.offlineAddresses),
+			EventKeyDBEVolatileUncorrected: strconv.FormatUint(v.volatileUncorrected, 10),
+		},
+	}
+	if v.passed() {
+		ev.Name = EventNameDBERemediationVerified
+		ev.Type = string(apiv1.EventTypeInfo)
+		ev.Message = fmt.Sprintf("GPU %s recovered from Xid %s after reboot (%d address(es) offline, ECC counts reset)", v.uuid, strings.Join(xids, ","), v.offlineAddresses)
+	} else {
+		ev.Name = EventNameDBERemediationFailed
+		ev.Type = string(apiv1.EventTypeCritical)
+		ev.Message = fmt.Sprintf("GPU %s not remediated from Xid %s by reboot: %s", v.uuid, strings.Join(xids, ","), strings.Join(v.failures, "; "))
+		ev.ExtraInfo[EventKeyDBEFailures] = strings.Join(v.failures, "; ")
+	}
+	return ev
+}
+
+// verifyDBERemediations verifies the DBE related Xids of the previous boots,
+// and records the outcomes as events.
+// The verification runs once per error, since the outcome event clears the pending Xids.
+func (c *component) verifyDBERemediations(ctx context.Context) error {
+	if c.eventBucket == nil || c.getBootTimeFunc == nil {
+		return nil
+	}
+	// otherwise, all GPUs would be reported as not found
+	if c.nvmlInstance == nil || !c.nvmlInstance.NVMLExists() {
+		return nil
+	}
+	bootTime := c.getBootTimeFunc()
+	if bootTime.IsZero() {
+		return nil
+	}
+
+	events, err := c.eventBucket.Get(ctx, c.getTimeNowFunc().Add(-GetLookbackPeriod()))
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+	pending := pendingDBEVerifications(events, bootTime, c.devices)
+
+	uuids := make([]string, 0, len(pending))
+	for uuid := range pending {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	for _, uuid := range uuids {
+		v := verifyDBERemediation(uuid, pending[uuid], c.devices[uuid])
+		if err := c.eventBucket.Insert(ctx, v.event(c.getTimeNowFunc())); err != nil {
+			return fmt.Errorf("failed to record dbe remediation verification: %w", err)
+		}
+		log.Logger.Infow("verified dbe remediation", "uuid", uuid, "xids", v.xids, "passed", v.passed(), "failures", v.failures)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package xid
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func newTestXidEvent(t *testing.T, ts time.Time, deviceID string, xid uint64) eventstore.Event {
+	b, err := json.Marshal(xidErrorEventDetail{
+		Time:       metav1.NewTime(ts),
+		DataSource: "kmsg",
+		DeviceUUID: deviceID,
+		Xid:        xid,
+	})
+	require.NoError(t, err)
+	return eventstore.Event{
+		Time: ts,
+		Name: EventNameErrorXid,
+		ExtraInfo: map[string]string{
+			EventKeyDeviceUUID:   deviceID,
+			EventKeyErrorXidData: string(b),
+		},
+	}
+}
+
+func newTestDBEDevice(mockDev *mock.Device) device.Device {
+	return testutil.NewMockDeviceWithIDs(mockDev, "test-arch", "test-brand", "test-cuda", "0000:01:00.0", "GPU-0", "serial-0", 0, 0)
+}
+
+func TestPendingDBEVerifications(t *testing.T) {
+	t.Parallel()
+
+	bootTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	devices := map[string]device.Device{"GPU-0": newTestDBEDevice(&mock.Device{})}
+
+	events := eventstore.Events{
+		// current boot, verified after the next reboot
+		newTestXidEvent(t, bootTime.Add(time.Minute), "PCI:0000:01:00", 48),
+		{Time: bootTime.Add(-2 * time.Hour), Name: EventNameDBERemediationVerified, ExtraInfo: map[string]string{EventKeyDeviceUUID: "GPU-1"}},
+		newTestXidEvent(t, bootTime.Add(-3*time.Hour), "PCI:0000:02:00", 48),
+		newTestXidEvent(t, bootTime.Add(-4*time.Hour), "PCI:0000:01:00", 94),
+		// not a DBE related Xid
+		newTestXidEvent(t, bootTime.Add(-5*time.Hour), "PCI:0000:01:00", 79),
+		newTestXidEvent(t, bootTime.Add(-6*time.Hour), "PCI:0000:01:00", 48),
+		newTestXidEvent(t, bootTime.Add(-7*time.Hour), "PCI:0000:01:00", 48),
+	}
+
+	pending := pendingDBEVerifications(events, bootTime, devices)
+	assert.Equal(t, map[string][]uint64{
+		"GPU-0": {48, 94},
+		// unresolved bus ID, verified before the last reboot
+		"PCI:0000:02:00": {48},
+	}, pending)
+
+	// verified after the errors
+	events = append(eventstore.Events{
+		{Time: bootTime.Add(time.Second), Name: EventNameDBERemediationFailed, ExtraInfo: map[string]string{EventKeyDeviceUUID: "GPU-0"}},
+	}, events...)
+	pending = pendingDBEVerifications(events, bootTime, devices)
+	assert.Equal(t, map[string][]uint64{"PCI:0000:02:00": {48}}, pending)
+}
+
+func TestVerifyDBERemediation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		dev              *mock.Device
+		nilDevice        bool
+		wantMechanism    string
+		wantOffline      int
+		wantFailures     []string
+		wantVolatileUncr uint64
+	}{
+		{
+			name: "row remapping completed",
+			dev: &mock.Device{
+				GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+					return 0, 1, false, false, nvml.SUCCESS
+				},
+				GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+					return 0, nvml.SUCCESS
+				},
+			},
+			wantMechanism: dbeMechanismRowRemapping,
+			wantOffline:   1,
+		},
+		{
+			name: "row remapping pending and ecc counts not reset",
+			dev: &mock.Device{
+				GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+					return 0, 1, true, false, nvml.SUCCESS
+				},
+				GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+					return 2, nvml.SUCCESS
+				},
+			},
+			wantMechanism:    dbeMechanismRowRemapping,
+			wantOffline:      1,
+			wantVolatileUncr: 2,
+			wantFailures: []string{
+				"row remapping still pending",
+				"volatile uncorrectable ECC error count not reset (2)",
+			},
+		},
+		{
+			name: "row remapping failed without remapped rows",
+			dev: &mock.Device{
+				GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+					return 0, 0, false, true, nvml.SUCCESS
+				},
+				GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+					return 0, nvml.ERROR_NOT_SUPPORTED
+				},
+			},
+			wantMechanism: dbeMechanismRowRemapping,
+			wantFailures: []string{
+				"row remapping failed",
+				"no row remapped for the uncorrectable errors",
+			},
+		},
+		{
+			name: "page retirement completed",
+			dev: &mock.Device{
+				GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+					return 0, 0, false, false, nvml.ERROR_NOT_SUPPORTED
+				},
+				GetRetiredPagesPendingStatusFunc: func() (nvml.EnableState, nvml.Return) {
+					return nvml.FEATURE_DISABLED, nvml.SUCCESS
+				},
+				GetRetiredPagesFunc: func(cause nvml.PageRetirementCause) ([]uint64, nvml.Return) {
+					assert.Equal(t, nvml.PAGE_RETIREMENT_CAUSE_DOUBLE_BIT_ECC_ERROR, cause)
+					return []uint64{0x1000, 0x2000}, nvml.SUCCESS
+				},
+				GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+					return 0, nvml.SUCCESS
+				},
+			},
+			wantMechanism: dbeMechanismPageRetirement,
+			wantOffline:   2,
+		},
+		{
+			name: "page retirement pending without retired pages",
+			dev: &mock.Device{
+				GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+					return 0, 0, false, false, nvml.ERROR_NOT_SUPPORTED
+				},
+				GetRetiredPagesPendingStatusFunc: func() (nvml.EnableState, nvml.Return) {
+					return nvml.FEATURE_ENABLED, nvml.SUCCESS
+				},
+				GetRetiredPagesFunc: func(nvml.PageRetirementCause) ([]uint64, nvml.Return) {
+					return nil, nvml.SUCCESS
+				},
+				GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+					return 0, nvml.SUCCESS
+				},
+			},
+			wantMechanism: dbeMechanismPageRetirement,
+			wantFailures: []string{
+				"page retirement still pending",
+				"no page retired for the double bit errors",
+			},
+		},
+		{
+			name:         "gpu not found",
+			nilDevice:    true,
+			wantFailures: []string{"GPU not found after reboot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dev device.Device
+			if !tt.nilDevice {
+				dev = newTestDBEDevice(tt.dev)
+			}
+			v := verifyDBERemediation("GPU-0", []uint64{48}, dev)
+			assert.Equal(t, tt.wantMechanism, v.mechanism)
+			assert.Equal(t, tt.wantOffline, v.offlineAddresses)
+			assert.Equal(t, tt.wantVolatileUncr, v.volatileUncorrected)
+			assert.Equal(t, tt.wantFailures, v.failures)
+			assert.Equal(t, len(tt.wantFailures) == 0, v.passed())
+
+			ev := v.event(time.Now())
+			if v.passed() {
+				assert.Equal(t, EventNameDBERemediationVerified, ev.Name)
+				assert.Equal(t, string(apiv1.EventTypeInfo), ev.Type)
+			} else {
+				assert.Equal(t, EventNameDBERemediationFailed, ev.Name)
+				assert.Equal(t, string(apiv1.EventTypeCritical), ev.Type)
+				assert.NotEmpty(t, ev.ExtraInfo[EventKeyDBEFailures])
+			}
+			assert.Equal(t, "48", ev.ExtraInfo[EventKeyDBEXids])
+		})
+	}
+}
+
+func TestComponentVerifyDBERemediations(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	bootTime := now.Add(-time.Hour)
+
+	devices := map[string]device.Device{
+		"GPU-0": newTestDBEDevice(&mock.Device{
+			GetRemappedRowsFunc: func() (int, int, bool, bool, nvml.Return) {
+				return 0, 1, false, false, nvml.SUCCESS
+			},
+			GetTotalEccErrorsFunc: func(nvml.MemoryErrorType, nvml.EccCounterType) (uint64, nvml.Return) {
+				return 0, nvml.SUCCESS
+			},
+		}),
+	}
+	c := &component{
+		ctx:             ctx,
+		nvmlInstance:    &mockNVMLInstance{devices: devices},
+		devices:         devices,
+		eventBucket:     bucket,
+		getTimeNowFunc:  func() time.Time { return now },
+		getBootTimeFunc: func() time.Time { return bootTime },
+	}
+
+	require.NoError(t, bucket.Insert(ctx, newTestXidEvent(t, bootTime.Add(-time.Minute), "PCI:0000:01:00", 48)))
+	require.NoError(t, c.verifyDBERemediations(ctx))
+
+	events, err := bucket.Get(ctx, bootTime.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, EventNameDBERemediationVerified, events[0].Name)
+	assert.Equal(t, "GPU-0", events[0].ExtraInfo[EventKeyDeviceUUID])
+	assert.Equal(t, dbeMechanismRowRemapping, events[0].ExtraInfo[EventKeyDBEMechanism])
+	contractstest.AssertEvents(t, Name, events...)
+
+	// verified only once
+	require.NoError(t, c.verifyDBERemediations(ctx))
+	events, err = bucket.Get(ctx, bootTime.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
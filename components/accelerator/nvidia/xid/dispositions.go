@@ -0,0 +1,66 @@
+package xid
+
+import (
+	"encoding/json"
+	"strconv"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// EventKeyDisposition stores the disposition that downgraded the Xid event
+// (e.g., "benign=2,false-positive=1").
+const EventKeyDisposition = "disposition"
+
+// applyDispositions downgrades the Xid events of the codes that the operators
+// marked benign or false positive on this GPU product and driver version,
+// keyed by the Xid code (e.g., "79"), so that the known-noisy Xids stop alerting.
+// The events of the other codes are returned as is.
+func (c *component) applyDispositions(events eventstore.Events) eventstore.Events {
+	if c.dispositions == nil {
+		return events
+	}
+
+	var product, driverVersion string
+	if c.nvmlInstance != nil {
+		product = c.nvmlInstance.ProductName()
+		driverVersion = c.nvmlInstance.DriverVersion()
+	}
+
+	for i, event := range events {
+		if event.Name != EventNameErrorXid {
+			continue
+		}
+
+		resolved := resolveXIDEvent(event, c.devices)
+		var xidErr xidErrorEventDetail
+		if err := json.Unmarshal([]byte(resolved.ExtraInfo[EventKeyErrorXidData]), &xidErr); err != nil {
+			continue
+		}
+
+		adj, ok := c.dispositions.Adjustment(Name, strconv.FormatUint(xidErr.Xid, 10), product, driverVersion)
+		if !ok {
+			continue
+		}
+		eventType, downgraded := adj.Downgrade(apiv1.EventType(resolved.Type))
+		if !downgraded {
+			continue
+		}
+
+		xidErr.SuggestedActionsByGPUd = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeIgnoreNoActionRequired},
+		}
+		raw, err := json.Marshal(xidErr)
+		if err != nil {
+			continue
+		}
+
+		log.Logger.Debugw("downgraded xid event by dispositions", "xid", xidErr.Xid, "from", resolved.Type, "to", eventType, "benign", adj.Benign, "falsePositive", adj.FalsePositive)
+		resolved.Type = string(eventType)
+		resolved.ExtraInfo[EventKeyErrorXidData] = string(raw)
+		resolved.ExtraInfo[EventKeyDisposition] = "benign=" + strconv.Itoa(adj.Benign) + ",false-positive=" + strconv.Itoa(adj.FalsePositive)
+		events[i] = resolved
+	}
+	return events
+}
@@ -0,0 +1,58 @@
+package xid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestApplyDispositions(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	m, err := disposition.NewManager(ctx, dbRW, dbRO, 2)
+	require.NoError(t, err)
+	for _, d := range []disposition.Disposition{disposition.DispositionBenign, disposition.DispositionFalsePositive} {
+		_, err := m.Record(ctx, disposition.Record{Component: Name, Key: "79", Disposition: d})
+		require.NoError(t, err)
+	}
+	_, err = m.Record(ctx, disposition.Record{Component: Name, Key: "13", Disposition: disposition.DispositionBenign})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	events := eventstore.Events{
+		createXidEvent(now, 79, apiv1.EventTypeFatal, apiv1.RepairActionTypeRebootSystem),
+		createXidEvent(now.Add(-time.Minute), 13, apiv1.EventTypeCritical, apiv1.RepairActionTypeCheckUserAppAndGPU),
+	}
+
+	// no dispositions set up
+	c := &component{}
+	assert.Equal(t, events, c.applyDispositions(events))
+
+	c.dispositions = m
+	adjusted := c.applyDispositions(append(eventstore.Events{}, events...))
+	require.Len(t, adjusted, 2)
+	assert.Equal(t, string(apiv1.EventTypeWarning), adjusted[0].Type)
+	assert.Equal(t, "benign=1,false-positive=1", adjusted[0].ExtraInfo[EventKeyDisposition])
+	// under the threshold
+	assert.Equal(t, string(apiv1.EventTypeCritical), adjusted[1].Type)
+
+	// the downgraded Xid 79 no longer suggests the reboot
+	state := evolveHealthyState(adjusted, nil, DefaultRebootThreshold)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, state.Health)
+	assert.Contains(t, state.Reason, "XID 13")
+
+	state = evolveHealthyState(adjusted[:1], nil, DefaultRebootThreshold)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, state.Health)
+	require.NotNil(t, state.SuggestedActions)
+	assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeIgnoreNoActionRequired}, state.SuggestedActions.RepairActions)
+}
@@ -0,0 +1,67 @@
+package xid
+
+import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// EventKeyDetailVariant stores the Xid detail variant the event was classified with,
+	// either DetailVariantDefault or the driver branch of the override (e.g., "r550").
+	EventKeyDetailVariant = "detail_variant"
+
+	// DetailVariantDefault is the detail variant of the static Xid tables.
+	DetailVariantDefault = "default"
+)
+
+// driverOverride overrides the Xid detail on the driver branches since the minimum major version.
+type driverOverride struct {
+	Xid int
+	// MinDriverMajor is the first driver branch the override applies to (e.g., 550 for R550).
+	MinDriverMajor int
+	// Variant names the override in the events (e.g., "r550").
+	Variant string
+
+	EventType              apiv1.EventType
+	SuggestedActionsByGPUd *apiv1.SuggestedActions
+}
+
+// driverOverrides are in the descending order of the minimum driver major version per Xid,
+// so that the override of the latest applicable driver branch is selected.
+var driverOverrides = []driverOverride{
+	// Xid 94 (contained ECC error) is contained to the faulting application by the driver,
+	// and other applications keep running on the GPU.
+	// Since R550, the remapping of the faulting row is tracked by the remapped-rows component,
+	// which suggests the reboot once the remapping is pending, so this Xid alone
+	// does not warrant a reboot (same as Xid 63/64 discarded in favor of the remapped-rows checks).
+	{
+		Xid:            94,
+		MinDriverMajor: 550,
+		Variant:        "r550",
+		EventType:      apiv1.EventTypeWarning,
+		SuggestedActionsByGPUd: &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{
+				apiv1.RepairActionTypeIgnoreNoActionRequired,
+			},
+		},
+	},
+}
+
+// resolveDetailForDriver returns a copy of the Xid detail with the override
+// of the driver major version applied, and the detail variant.
+// It returns the detail as is with DetailVariantDefault if the driver version is unknown (0)
+// or no override applies.
+func resolveDetailForDriver(xid int, detail *Detail, driverMajor int) (*Detail, string) {
+	if detail == nil || driverMajor <= 0 {
+		return detail, DetailVariantDefault
+	}
+	for _, o := range driverOverrides {
+		if o.Xid != xid || driverMajor < o.MinDriverMajor {
+			continue
+		}
+		d := *detail
+		d.EventType = o.EventType
+		d.SuggestedActionsByGPUd = copySuggestedActions(o.SuggestedActionsByGPUd)
+		return &d, o.Variant
+	}
+	return detail, DetailVariantDefault
+}
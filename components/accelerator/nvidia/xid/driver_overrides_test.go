@@ -0,0 +1,62 @@
+package xid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestResolveDetailForDriver(t *testing.T) {
+	base, ok := GetDetail(94)
+	require.True(t, ok)
+	require.Equal(t, apiv1.EventTypeFatal, base.EventType)
+
+	// driver version unknown
+	d, variant := resolveDetailForDriver(94, base, 0)
+	assert.Same(t, base, d)
+	assert.Equal(t, DetailVariantDefault, variant)
+
+	// before the override branch
+	d, variant = resolveDetailForDriver(94, base, 535)
+	assert.Same(t, base, d)
+	assert.Equal(t, DetailVariantDefault, variant)
+
+	for _, major := range []int{550, 570} {
+		d, variant = resolveDetailForDriver(94, base, major)
+		assert.Equal(t, "r550", variant)
+		assert.Equal(t, apiv1.EventTypeWarning, d.EventType)
+		require.NotNil(t, d.SuggestedActionsByGPUd)
+		assert.Equal(t, []apiv1.RepairActionType{apiv1.RepairActionTypeIgnoreNoActionRequired}, d.SuggestedActionsByGPUd.RepairActions)
+		// the other fields and the static table are not modified
+		assert.Equal(t, base.Description, d.Description)
+		assert.Equal(t, apiv1.EventTypeFatal, base.EventType)
+	}
+
+	// no override for the Xid
+	base79, ok := GetDetail(79)
+	require.True(t, ok)
+	d, variant = resolveDetailForDriver(79, base79, 570)
+	assert.Same(t, base79, d)
+	assert.Equal(t, DetailVariantDefault, variant)
+
+	d, variant = resolveDetailForDriver(94, nil, 570)
+	assert.Nil(t, d)
+	assert.Equal(t, DetailVariantDefault, variant)
+}
+
+func TestDriverOverridesOrdered(t *testing.T) {
+	lastMajor := make(map[int]int)
+	for _, o := range driverOverrides {
+		_, ok := GetDetail(o.Xid)
+		assert.True(t, ok, "override of unknown Xid %d", o.Xid)
+		assert.NotEmpty(t, o.Variant)
+		assert.NotEmpty(t, o.EventType)
+		if prev, ok := lastMajor[o.Xid]; ok {
+			assert.Less(t, o.MinDriverMajor, prev, "overrides of Xid %d must be in the descending order of the driver major version", o.Xid)
+		}
+		lastMajor[o.Xid] = o.MinDriverMajor
+	}
+}
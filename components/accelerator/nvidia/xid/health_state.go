@@ -14,6 +14,7 @@ import (
 	"github.com/leptonai/gpud/pkg/eventstore"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 const (
@@ -50,6 +51,28 @@ func init() {
 			catalogMnemonicMap[entry.Code] = entry.Mnemonic
 		}
 	})
+
+	mds := make([]apiv1.ReasonCodeMetadata, 0, len(details))
+	for id, detail := range details {
+		health := apiv1.HealthStateTypeHealthy
+		switch detail.EventType {
+		case apiv1.EventTypeCritical:
+			health = apiv1.HealthStateTypeDegraded
+		case apiv1.EventTypeFatal:
+			health = apiv1.HealthStateTypeUnhealthy
+		}
+		mds = append(mds, apiv1.ReasonCodeMetadata{
+			Code:        xidReasonCode(uint64(id)),
+			Health:      health,
+			Description: fmt.Sprintf("Xid %d: %s", id, detail.Description),
+		})
+	}
+	reasoncode.MustRegister(Name, mds...)
+}
+
+// xidReasonCode returns the reason code of the Xid (e.g., "GPU_XID_79").
+func xidReasonCode(xid uint64) apiv1.ReasonCode {
+	return apiv1.ReasonCode("GPU_XID_" + strconv.FormatUint(xid, 10))
 }
 
 // evolveHealthyState resolves the state of the XID error component.
@@ -114,15 +137,18 @@ func evolveHealthyState(events eventstore.Events, devices map[string]device.Devi
 		}
 	}
 	var reason string
+	var code apiv1.ReasonCode
 	if lastXidErr == nil {
 		reason = "XIDComponent is healthy"
 	} else {
 		reason = lastXidErr.buildMessage(devices)
+		code = xidReasonCode(lastXidErr.Xid)
 	}
 	return apiv1.HealthState{
 		Name:             StateNameErrorXid,
 		Health:           translateToStateHealth(lastHealth),
 		Reason:           reason,
+		ReasonCode:       code,
 		SuggestedActions: lastSuggestedAction,
 	}
 }
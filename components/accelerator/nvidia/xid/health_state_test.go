@@ -80,6 +80,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		state := evolveHealthyState(eventstore.Events{}, nil, DefaultRebootThreshold)
 		assert.Equal(t, apiv1.HealthStateTypeHealthy, state.Health)
 		assert.Equal(t, "XIDComponent is healthy", state.Reason)
+		assert.Empty(t, state.ReasonCode)
 	})
 
 	mockDevice := testutil.NewMockDevice(&mock.Device{}, "test-arch", "test-brand", "test-cuda", "0000:9b:00.0")
@@ -95,6 +96,7 @@ func TestStateUpdateBasedOnEvents(t *testing.T) {
 		// XID 123 (SPI_PMU_RPC_WRITE_FAIL) has mnemonic, expect it in reason
 		assert.Contains(t, state.Reason, "XID 123")
 		assert.Contains(t, state.Reason, "GPU PCI:0000:9b:00")
+		assert.Equal(t, apiv1.ReasonCode("GPU_XID_123"), state.ReasonCode)
 	})
 
 	t.Run("fatal xid", func(t *testing.T) {
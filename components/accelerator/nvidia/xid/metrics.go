@@ -3,7 +3,9 @@ package xid
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 )
 
 // SubSystem is the Prometheus subsystem name for XID metrics.
@@ -21,10 +23,7 @@ var (
 			Name:      "errors_total",
 			Help:      "tracks the error counts per GPU UUID and XID code",
 		},
-		[]string{pkgmetrics.MetricComponentLabelKey,
-			"uuid", // label is GPU ID
-			"xid",  // label is XID error code
-		},
+		nvidianvml.GPUMetricLabelKeys("xid"), // labels are GPU UUID, index and XID error code
 	).MustCurryWith(componentLabel)
 )
 
@@ -32,4 +31,7 @@ func init() {
 	pkgmetrics.MustRegister(
 		metricXIDErrs,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_errors_total", Type: apiv1.MetricTypeCounter, Unit: apiv1.MetricUnitCount},
+	)
 }
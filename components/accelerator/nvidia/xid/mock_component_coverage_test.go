@@ -144,7 +144,7 @@ func TestNew_Branches_WithMockey(t *testing.T) {
 		assert.Contains(t, err.Error(), "bucket failed")
 	})
 
-	mockey.PatchConvey("New returns watcher error when running as root", t, func() {
+	mockey.PatchConvey("New runs without watcher on watcher error when running as root", t, func() {
 		mockey.Mock(os.Geteuid).To(func() int { return 0 }).Build()
 		mockey.Mock(kmsg.NewWatcher).To(func(...kmsg.OpOption) (kmsg.Watcher, error) {
 			return nil, errors.New("watcher failed")
@@ -155,9 +155,10 @@ func TestNew_Branches_WithMockey(t *testing.T) {
 			EventStore: &stubEventStore{bucket: &stubEventBucket{}},
 		}
 		comp, err := New(gpudInstance)
-		require.Error(t, err)
-		assert.Nil(t, comp)
-		assert.Contains(t, err.Error(), "watcher failed")
+		require.NoError(t, err)
+		require.NotNil(t, comp)
+		assert.Nil(t, comp.(*component).kmsgWatcher)
+		require.NoError(t, comp.Close())
 	})
 }
 
@@ -0,0 +1,61 @@
+package xid
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// applyResetWindows downgrades the Xid events within the GPU reset windows
+// (e.g., the host reboots or the driver reloads) to the info events with no action required,
+// since the cascade of the Xids is expected while the GPUs are being reset.
+// The events outside of the windows are returned as is.
+func (c *component) applyResetWindows(ctx context.Context, events eventstore.Events, since time.Time) eventstore.Events {
+	if c.resets == nil || len(events) == 0 {
+		return events
+	}
+
+	windows, err := c.resets.Windows(ctx, since)
+	if err != nil {
+		log.Logger.Warnw("failed to get gpu reset windows", "error", err)
+		return events
+	}
+	if len(windows) == 0 {
+		return events
+	}
+
+	for i, event := range events {
+		if event.Name != EventNameErrorXid {
+			continue
+		}
+		w, ok := gpureset.Find(windows, event.Time)
+		if !ok {
+			continue
+		}
+
+		resolved := resolveXIDEvent(event, c.devices)
+		var xidErr xidErrorEventDetail
+		if err := json.Unmarshal([]byte(resolved.ExtraInfo[EventKeyErrorXidData]), &xidErr); err != nil {
+			continue
+		}
+		xidErr.SuggestedActionsByGPUd = &apiv1.SuggestedActions{
+			RepairActions: []apiv1.RepairActionType{apiv1.RepairActionTypeIgnoreNoActionRequired},
+		}
+		raw, err := json.Marshal(xidErr)
+		if err != nil {
+			continue
+		}
+
+		log.Logger.Debugw("downgraded xid event within gpu reset window", "xid", xidErr.Xid, "from", resolved.Type, "reason", w.Reason)
+		resolved.Type = string(apiv1.EventTypeInfo)
+		resolved.ExtraInfo = gpureset.Tag(resolved.ExtraInfo, w)
+		resolved.ExtraInfo[EventKeyErrorXidData] = string(raw)
+		events[i] = resolved
+	}
+	return events
+}
@@ -4,45 +4,70 @@ package all
 import (
 	"github.com/leptonai/gpud/components"
 
+	componentsacceleratornvidiabandwidthasymmetry "github.com/leptonai/gpud/components/accelerator/nvidia/bandwidth-asymmetry"
 	componentsacceleratornvidiaclockspeed "github.com/leptonai/gpud/components/accelerator/nvidia/clock-speed"
+	componentsacceleratornvidiacrashdump "github.com/leptonai/gpud/components/accelerator/nvidia/crash-dump"
+	componentsacceleratornvidiacudauserland "github.com/leptonai/gpud/components/accelerator/nvidia/cuda-userland"
 	componentsacceleratornvidiaecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
 	componentsacceleratornvidiafabricmanager "github.com/leptonai/gpud/components/accelerator/nvidia/fabric-manager"
+	componentsacceleratornvidiagds "github.com/leptonai/gpud/components/accelerator/nvidia/gds"
 	componentsacceleratornvidiagpm "github.com/leptonai/gpud/components/accelerator/nvidia/gpm"
+	componentsacceleratornvidiagpuassets "github.com/leptonai/gpud/components/accelerator/nvidia/gpu-assets"
 	componentsacceleratornvidiagpucounts "github.com/leptonai/gpud/components/accelerator/nvidia/gpu-counts"
+	componentsacceleratornvidiagpumodes "github.com/leptonai/gpud/components/accelerator/nvidia/gpu-modes"
+	componentsacceleratornvidiagpudirectrdma "github.com/leptonai/gpud/components/accelerator/nvidia/gpudirect-rdma"
+	componentsacceleratornvidiagspfirmware "github.com/leptonai/gpud/components/accelerator/nvidia/gsp-firmware"
 	componentsacceleratornvidiahwslowdown "github.com/leptonai/gpud/components/accelerator/nvidia/hw-slowdown"
+	componentsacceleratornvidiaidle "github.com/leptonai/gpud/components/accelerator/nvidia/idle"
 	componentsacceleratornvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
 	componentsacceleratornvidiamemory "github.com/leptonai/gpud/components/accelerator/nvidia/memory"
 	componentsacceleratornvidianccl "github.com/leptonai/gpud/components/accelerator/nvidia/nccl"
 	componentsacceleratornvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsacceleratornvidiapcie "github.com/leptonai/gpud/components/accelerator/nvidia/pcie"
 	componentsacceleratornvidiapeermem "github.com/leptonai/gpud/components/accelerator/nvidia/peermem"
 	componentsacceleratornvidiapersistencemode "github.com/leptonai/gpud/components/accelerator/nvidia/persistence-mode"
 	componentsacceleratornvidiapower "github.com/leptonai/gpud/components/accelerator/nvidia/power"
 	componentsacceleratornvidiaprocesses "github.com/leptonai/gpud/components/accelerator/nvidia/processes"
 	componentsacceleratornvidiaremappedrows "github.com/leptonai/gpud/components/accelerator/nvidia/remapped-rows"
 	componentsacceleratornvidiasxid "github.com/leptonai/gpud/components/accelerator/nvidia/sxid"
+	componentsacceleratornvidiategra "github.com/leptonai/gpud/components/accelerator/nvidia/tegra"
 	componentsacceleratornvidiatemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
 	componentsacceleratornvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	componentsacceleratornvidiavgpu "github.com/leptonai/gpud/components/accelerator/nvidia/vgpu"
 	componentsacceleratornvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	componentsbmc "github.com/leptonai/gpud/components/bmc"
 	componentscontainerd "github.com/leptonai/gpud/components/containerd"
 	componentscpu "github.com/leptonai/gpud/components/cpu"
 	componentsdisk "github.com/leptonai/gpud/components/disk"
 	componentsdocker "github.com/leptonai/gpud/components/docker"
 	componentsfuse "github.com/leptonai/gpud/components/fuse"
+	componentsiolatency "github.com/leptonai/gpud/components/io-latency"
+	componentskernelcounters "github.com/leptonai/gpud/components/kernel-counters"
 	componentskernelmodule "github.com/leptonai/gpud/components/kernel-module"
 	componentskubelet "github.com/leptonai/gpud/components/kubelet"
 	componentslibrary "github.com/leptonai/gpud/components/library"
 	componentsmemory "github.com/leptonai/gpud/components/memory"
+	componentsmetricsanomaly "github.com/leptonai/gpud/components/metrics-anomaly"
 	componentsnetworklatency "github.com/leptonai/gpud/components/network/latency"
+	componentsnetworkreachability "github.com/leptonai/gpud/components/network/reachability"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
 	componentsos "github.com/leptonai/gpud/components/os"
 	componentspci "github.com/leptonai/gpud/components/pci"
+	componentsservicesupervisor "github.com/leptonai/gpud/components/service-supervisor"
 	componentstailscale "github.com/leptonai/gpud/components/tailscale"
+	"github.com/leptonai/gpud/pkg/capabilities"
 )
 
 // Component describes a component registration entry.
 type Component struct {
 	Name     string
 	InitFunc components.InitFunc
+	// Capabilities are the data sources the component depends on,
+	// reported as degraded without if not available on the host.
+	Capabilities []string
+	// Deferrable is true if the check is expensive (e.g., "nvidia-smi", "ibstat"),
+	// thus deferred under the host pressure if the load shedding is enabled.
+	Deferrable bool
 }
 
 // All returns every component registration in initialization order.
@@ -51,37 +76,55 @@ func All() []Component {
 }
 
 var componentInits = []Component{
-	{Name: componentsacceleratornvidiaclockspeed.Name, InitFunc: componentsacceleratornvidiaclockspeed.New},
-	{Name: componentsacceleratornvidiaecc.Name, InitFunc: componentsacceleratornvidiaecc.New},
-	{Name: componentsacceleratornvidiafabricmanager.Name, InitFunc: componentsacceleratornvidiafabricmanager.New},
-	{Name: componentsacceleratornvidiagpm.Name, InitFunc: componentsacceleratornvidiagpm.New},
-	{Name: componentsacceleratornvidiagpucounts.Name, InitFunc: componentsacceleratornvidiagpucounts.New},
-	{Name: componentsacceleratornvidiahwslowdown.Name, InitFunc: componentsacceleratornvidiahwslowdown.New},
-	{Name: componentsacceleratornvidiainfiniband.Name, InitFunc: componentsacceleratornvidiainfiniband.New},
-	{Name: componentsacceleratornvidiamemory.Name, InitFunc: componentsacceleratornvidiamemory.New},
-	{Name: componentsacceleratornvidianccl.Name, InitFunc: componentsacceleratornvidianccl.New},
-	{Name: componentsacceleratornvidianvlink.Name, InitFunc: componentsacceleratornvidianvlink.New},
-	{Name: componentsacceleratornvidiapeermem.Name, InitFunc: componentsacceleratornvidiapeermem.New},
-	{Name: componentsacceleratornvidiapersistencemode.Name, InitFunc: componentsacceleratornvidiapersistencemode.New},
-	{Name: componentsacceleratornvidiapower.Name, InitFunc: componentsacceleratornvidiapower.New},
-	{Name: componentsacceleratornvidiaprocesses.Name, InitFunc: componentsacceleratornvidiaprocesses.New},
-	{Name: componentsacceleratornvidiaremappedrows.Name, InitFunc: componentsacceleratornvidiaremappedrows.New},
-	{Name: componentsacceleratornvidiasxid.Name, InitFunc: componentsacceleratornvidiasxid.New},
-	{Name: componentsacceleratornvidiatemperature.Name, InitFunc: componentsacceleratornvidiatemperature.New},
-	{Name: componentsacceleratornvidiautilization.Name, InitFunc: componentsacceleratornvidiautilization.New},
-	{Name: componentsacceleratornvidiaxid.Name, InitFunc: componentsacceleratornvidiaxid.New},
+	{Name: componentsacceleratornvidiabandwidthasymmetry.Name, InitFunc: componentsacceleratornvidiabandwidthasymmetry.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiaclockspeed.Name, InitFunc: componentsacceleratornvidiaclockspeed.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiacrashdump.Name, InitFunc: componentsacceleratornvidiacrashdump.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}},
+	{Name: componentsacceleratornvidiacudauserland.Name, InitFunc: componentsacceleratornvidiacudauserland.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiaecc.Name, InitFunc: componentsacceleratornvidiaecc.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiafabricmanager.Name, InitFunc: componentsacceleratornvidiafabricmanager.New, Capabilities: []string{capabilities.NVML, capabilities.NvidiaSMI}, Deferrable: true},
+	{Name: componentsacceleratornvidiagds.Name, InitFunc: componentsacceleratornvidiagds.New, Capabilities: []string{capabilities.NVML}, Deferrable: true},
+	{Name: componentsacceleratornvidiagpm.Name, InitFunc: componentsacceleratornvidiagpm.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiagpuassets.Name, InitFunc: componentsacceleratornvidiagpuassets.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiagpucounts.Name, InitFunc: componentsacceleratornvidiagpucounts.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiagpudirectrdma.Name, InitFunc: componentsacceleratornvidiagpudirectrdma.New, Capabilities: []string{capabilities.NVML}, Deferrable: true},
+	{Name: componentsacceleratornvidiagpumodes.Name, InitFunc: componentsacceleratornvidiagpumodes.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiagspfirmware.Name, InitFunc: componentsacceleratornvidiagspfirmware.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiahwslowdown.Name, InitFunc: componentsacceleratornvidiahwslowdown.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiaidle.Name, InitFunc: componentsacceleratornvidiaidle.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiainfiniband.Name, InitFunc: componentsacceleratornvidiainfiniband.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}, Deferrable: true},
+	{Name: componentsacceleratornvidiamemory.Name, InitFunc: componentsacceleratornvidiamemory.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidianccl.Name, InitFunc: componentsacceleratornvidianccl.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}},
+	{Name: componentsacceleratornvidianvlink.Name, InitFunc: componentsacceleratornvidianvlink.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiapcie.Name, InitFunc: componentsacceleratornvidiapcie.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiapeermem.Name, InitFunc: componentsacceleratornvidiapeermem.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}, Deferrable: true},
+	{Name: componentsacceleratornvidiapersistencemode.Name, InitFunc: componentsacceleratornvidiapersistencemode.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiapower.Name, InitFunc: componentsacceleratornvidiapower.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiaprocesses.Name, InitFunc: componentsacceleratornvidiaprocesses.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiaremappedrows.Name, InitFunc: componentsacceleratornvidiaremappedrows.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiasxid.Name, InitFunc: componentsacceleratornvidiasxid.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}},
+	{Name: componentsacceleratornvidiategra.Name, InitFunc: componentsacceleratornvidiategra.New},
+	{Name: componentsacceleratornvidiatemperature.Name, InitFunc: componentsacceleratornvidiatemperature.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiautilization.Name, InitFunc: componentsacceleratornvidiautilization.New, Capabilities: []string{capabilities.NVML}},
+	{Name: componentsacceleratornvidiavgpu.Name, InitFunc: componentsacceleratornvidiavgpu.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}},
+	{Name: componentsacceleratornvidiaxid.Name, InitFunc: componentsacceleratornvidiaxid.New, Capabilities: []string{capabilities.NVML, capabilities.Kmsg}},
+	{Name: componentsbmc.Name, InitFunc: componentsbmc.New, Capabilities: []string{capabilities.Ipmitool}, Deferrable: true},
 	{Name: componentscontainerd.Name, InitFunc: componentscontainerd.New},
-	{Name: componentscpu.Name, InitFunc: componentscpu.New},
-	{Name: componentsdisk.Name, InitFunc: componentsdisk.New},
+	{Name: componentscpu.Name, InitFunc: componentscpu.New, Capabilities: []string{capabilities.Kmsg}},
+	{Name: componentsdisk.Name, InitFunc: componentsdisk.New, Capabilities: []string{capabilities.Kmsg}},
 	{Name: componentsdocker.Name, InitFunc: componentsdocker.New},
 	{Name: componentsfuse.Name, InitFunc: componentsfuse.New},
+	{Name: componentsiolatency.Name, InitFunc: componentsiolatency.New, Deferrable: true},
+	{Name: componentskernelcounters.Name, InitFunc: componentskernelcounters.New},
 	{Name: componentskernelmodule.Name, InitFunc: componentskernelmodule.New},
 	{Name: componentskubelet.Name, InitFunc: componentskubelet.New},
 	{Name: componentslibrary.Name, InitFunc: componentslibrary.New},
-	{Name: componentsmemory.Name, InitFunc: componentsmemory.New},
-	{Name: componentsnetworklatency.Name, InitFunc: componentsnetworklatency.New},
-	{Name: componentsnfs.Name, InitFunc: componentsnfs.New},
-	{Name: componentsos.Name, InitFunc: componentsos.New},
+	{Name: componentsmemory.Name, InitFunc: componentsmemory.New, Capabilities: []string{capabilities.Kmsg}},
+	{Name: componentsmetricsanomaly.Name, InitFunc: componentsmetricsanomaly.New},
+	{Name: componentsnetworklatency.Name, InitFunc: componentsnetworklatency.New, Deferrable: true},
+	{Name: componentsnetworkreachability.Name, InitFunc: componentsnetworkreachability.New, Deferrable: true},
+	{Name: componentsnfs.Name, InitFunc: componentsnfs.New, Deferrable: true},
+	{Name: componentsos.Name, InitFunc: componentsos.New, Capabilities: []string{capabilities.Kmsg}},
 	{Name: componentspci.Name, InitFunc: componentspci.New},
+	{Name: componentsservicesupervisor.Name, InitFunc: componentsservicesupervisor.New},
 	{Name: componentstailscale.Name, InitFunc: componentstailscale.New},
 }
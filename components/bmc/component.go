@@ -0,0 +1,413 @@
+// Package bmc monitors the hardware health reported by the baseboard
+// management controller (BMC), such as the power supplies, the voltage rails,
+// the chassis intrusion, and the system event log (SEL).
+// The BMC is queried via Redfish if configured, falling back to the
+// in-band IPMI with "ipmitool".
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// Name is the ID of the BMC component.
+	Name = "bmc"
+
+	// EventNameSEL is the event name of the BMC system event log entries.
+	EventNameSEL = "bmc-sel"
+
+	// DefaultIPMIDevice is the in-band IPMI device required by "ipmitool".
+	DefaultIPMIDevice = "/dev/ipmi0"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	eventBucket eventstore.Bucket
+
+	getTimeNowFunc   func() time.Time
+	getConfigFunc    func() Config
+	queryRedfishFunc func(ctx context.Context, cfg Config) (*Snapshot, error)
+	// findIpmitoolFunc returns the "ipmitool" path if the IPMI is available.
+	findIpmitoolFunc func(cfg Config) (string, bool)
+	queryIPMIFunc    func(ctx context.Context, ipmitoolPath string) (*Snapshot, error)
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates a BMC component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: GetDefaultConfig,
+		queryRedfishFunc: func(ctx context.Context, cfg Config) (*Snapshot, error) {
+			return newRedfishClient(cfg).query(ctx)
+		},
+		findIpmitoolFunc: findIpmitool,
+		queryIPMIFunc: func(ctx context.Context, ipmitoolPath string) (*Snapshot, error) {
+			return queryIPMI(ctx, ipmitoolPath, runCommand, time.Local)
+		},
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"hardware",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	cfg := c.getConfigFunc()
+	if cfg.Endpoint != "" {
+		return true
+	}
+	if cfg.DisableIPMI {
+		return false
+	}
+	_, ok := c.findIpmitoolFunc(cfg)
+	return ok
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking bmc")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cr.Snapshot, cr.err = c.query()
+	if cr.err != nil {
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error querying bmc"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+	if cr.Snapshot == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "bmc not configured"
+		return cr
+	}
+
+	updateMetrics(cr.Snapshot)
+
+	if err := c.recordSELEvents(cr.ts, cr.Snapshot); err != nil {
+		log.Logger.Warnw("failed to record bmc sel events", "error", err)
+	}
+
+	cr.health, cr.reason = evaluate(cr.Snapshot)
+	if cr.health != apiv1.HealthStateTypeHealthy {
+		log.Logger.Warnw(cr.reason, "source", cr.Snapshot.Source)
+	}
+	return cr
+}
+
+// query queries the BMC via Redfish if configured, then falls back to the IPMI.
+// It returns nil if neither is available.
+func (c *component) query() (*Snapshot, error) {
+	cfg := c.getConfigFunc()
+
+	var errs []error
+	if cfg.Endpoint != "" {
+		cctx, ccancel := context.WithTimeout(c.ctx, DefaultRequestTimeout)
+		snap, err := c.queryRedfishFunc(cctx, cfg)
+		ccancel()
+		if err == nil {
+			return snap, nil
+		}
+		log.Logger.Warnw("failed to query bmc via redfish", "endpoint", cfg.Endpoint, "error", err)
+		errs = append(errs, fmt.Errorf("redfish: %w", err))
+	}
+
+	if !cfg.DisableIPMI {
+		if ipmitoolPath, ok := c.findIpmitoolFunc(cfg); ok {
+			cctx, ccancel := context.WithTimeout(c.ctx, DefaultRequestTimeout)
+			snap, err := c.queryIPMIFunc(cctx, ipmitoolPath)
+			ccancel()
+			if err == nil {
+				return snap, nil
+			}
+			errs = append(errs, fmt.Errorf("ipmi: %w", err))
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// findIpmitool returns the "ipmitool" path if the tool and the IPMI device exist.
+func findIpmitool(cfg Config) (string, bool) {
+	if _, err := os.Stat(DefaultIPMIDevice); err != nil {
+		return "", false
+	}
+	if cfg.IpmitoolPath != "" {
+		return cfg.IpmitoolPath, true
+	}
+	p, err := pkgfile.LocateExecutable("ipmitool")
+	if err != nil {
+		return "", false
+	}
+	return p, true
+}
+
+// evaluate returns the health of the BMC snapshot, where the critical
+// power supply or voltage rail is unhealthy, and the warnings or
+// the chassis intrusion are degraded.
+func evaluate(snap *Snapshot) (apiv1.HealthStateType, string) {
+	var critical, warning []string
+	for _, psu := range snap.PowerSupplies {
+		switch {
+		case strings.EqualFold(psu.Health, HealthCritical):
+			critical = append(critical, fmt.Sprintf("power supply %s critical", psu.Name))
+		case strings.EqualFold(psu.Health, HealthWarning):
+			warning = append(warning, fmt.Sprintf("power supply %s warning", psu.Name))
+		}
+	}
+	for _, v := range snap.Voltages {
+		switch {
+		case strings.EqualFold(v.Health, HealthCritical):
+			critical = append(critical, fmt.Sprintf("voltage %s critical", v.Name))
+		case strings.EqualFold(v.Health, HealthWarning):
+			warning = append(warning, fmt.Sprintf("voltage %s warning", v.Name))
+		}
+	}
+	if snap.Intrusion != "" && snap.Intrusion != IntrusionNormal {
+		warning = append(warning, fmt.Sprintf("chassis intrusion %s", snap.Intrusion))
+	}
+
+	switch {
+	case len(critical) > 0:
+		return apiv1.HealthStateTypeUnhealthy, "bmc reported hardware failure: " + strings.Join(append(critical, warning...), "; ")
+	case len(warning) > 0:
+		return apiv1.HealthStateTypeDegraded, "bmc reported hardware warning: " + strings.Join(warning, "; ")
+	default:
+		return apiv1.HealthStateTypeHealthy, fmt.Sprintf("bmc reported %d power supply(s) and %d voltage rail(s) ok", len(snap.PowerSupplies), len(snap.Voltages))
+	}
+}
+
+func updateMetrics(snap *Snapshot) {
+	for _, psu := range snap.PowerSupplies {
+		healthy := 0.0
+		if psu.Health == "" || strings.EqualFold(psu.Health, HealthOK) {
+			healthy = 1.0
+		}
+		metricPowerSupplyHealthy.With(map[string]string{"power_supply": psu.Name}).Set(healthy)
+	}
+	for _, v := range snap.Voltages {
+		if v.ReadingVolts == nil {
+			continue
+		}
+		metricVoltage.With(map[string]string{"rail": v.Name}).Set(*v.ReadingVolts)
+	}
+}
+
+// recordSELEvents converts the SEL entries created within the lookback
+// into the events, skipping the ones already recorded.
+func (c *component) recordSELEvents(now time.Time, snap *Snapshot) error {
+	if c.eventBucket == nil {
+		return nil
+	}
+
+	since := now.Add(-DefaultSELLookback)
+	for _, entry := range snap.SELEntries {
+		if entry.Created.Before(since) {
+			continue
+		}
+
+		ev := eventstore.Event{
+			Component: Name,
+			Time:      entry.Created,
+			Name:      EventNameSEL,
+			Type:      string(entry.EventType()),
+			Message:   entry.Message,
+			ExtraInfo: map[string]string{
+				"sel_id":   entry.ID,
+				"severity": entry.Severity,
+				"source":   snap.Source,
+			},
+		}
+
+		cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+		found, err := c.eventBucket.Find(cctx, ev)
+		ccancel()
+		if err != nil {
+			return err
+		}
+		if found != nil {
+			continue
+		}
+
+		cctx, ccancel = context.WithTimeout(c.ctx, 15*time.Second)
+		err = c.eventBucket.Insert(cctx, ev)
+		ccancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if cr.Snapshot == nil {
+		return "no data"
+	}
+
+	b, err := yaml.Marshal(cr.Snapshot)
+	if err != nil {
+		return fmt.Sprintf("error marshaling data: %v", err)
+	}
+	return string(b)
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if cr.Snapshot != nil {
+		b, _ := json.Marshal(cr.Snapshot)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
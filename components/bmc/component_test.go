@@ -0,0 +1,256 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// openTestEventStore creates a test event store and returns cleanup function
+func openTestEventStore(t *testing.T) (eventstore.Store, func()) {
+	dbRW, dbRO, sqliteCleanup := sqlite.OpenTestDB(t)
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	return store, func() {
+		sqliteCleanup()
+	}
+}
+
+func mustComponent(t *testing.T, comp components.Component) *component {
+	t.Helper()
+
+	c, ok := comp.(*component)
+	if !ok {
+		t.Fatal("expected *component")
+	}
+	return c
+}
+
+func TestComponentBasics(t *testing.T) {
+	store, cleanup := openTestEventStore(t)
+	defer cleanup()
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:    context.Background(),
+		EventStore: store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+
+	c := mustComponent(t, comp)
+	c.getConfigFunc = func() Config { return Config{} }
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "", false }
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.False(t, c.IsSupported())
+
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "/usr/bin/ipmitool", true }
+	assert.True(t, c.IsSupported())
+
+	c.getConfigFunc = func() Config { return Config{DisableIPMI: true} }
+	assert.False(t, c.IsSupported())
+
+	c.getConfigFunc = func() Config { return Config{Endpoint: "https://10.0.0.10", DisableIPMI: true} }
+	assert.True(t, c.IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestCheckNotConfigured(t *testing.T) {
+	store, cleanup := openTestEventStore(t)
+	defer cleanup()
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:    context.Background(),
+		EventStore: store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+
+	c := mustComponent(t, comp)
+	c.getConfigFunc = func() Config { return Config{} }
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "", false }
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "bmc not configured", cr.Summary())
+	assert.Equal(t, "no data", cr.String())
+}
+
+func TestCheckFallbackToIPMI(t *testing.T) {
+	store, cleanup := openTestEventStore(t)
+	defer cleanup()
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:    context.Background(),
+		EventStore: store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+
+	c := mustComponent(t, comp)
+	c.getConfigFunc = func() Config { return Config{Endpoint: "https://10.0.0.10"} }
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "", false }
+	c.queryRedfishFunc = func(context.Context, Config) (*Snapshot, error) {
+		return nil, errors.New("connection refused")
+	}
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "/usr/bin/ipmitool", true }
+	c.queryIPMIFunc = func(_ context.Context, ipmitoolPath string) (*Snapshot, error) {
+		assert.Equal(t, "/usr/bin/ipmitool", ipmitoolPath)
+		return &Snapshot{
+			Source:        SourceIPMI,
+			PowerSupplies: []PowerSupply{{Name: "PS1", State: "Enabled", Health: HealthOK}},
+			Intrusion:     IntrusionNormal,
+		}, nil
+	}
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var snap Snapshot
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &snap))
+	assert.Equal(t, SourceIPMI, snap.Source)
+
+	// both failing
+	c.queryIPMIFunc = func(context.Context, string) (*Snapshot, error) {
+		return nil, errors.New("no ipmi device")
+	}
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	states = c.LastHealthStates()
+	assert.Contains(t, states[0].Error, "connection refused")
+	assert.Contains(t, states[0].Error, "no ipmi device")
+
+	// ipmi disabled
+	c.getConfigFunc = func() Config { return Config{Endpoint: "https://10.0.0.10", DisableIPMI: true} }
+	c.queryIPMIFunc = func(context.Context, string) (*Snapshot, error) {
+		t.Fatal("unexpected ipmi query")
+		return nil, nil
+	}
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		snap   *Snapshot
+		health apiv1.HealthStateType
+		reason string
+	}{
+		{
+			name:   "healthy",
+			snap:   &Snapshot{PowerSupplies: []PowerSupply{{Name: "PS1", Health: HealthOK}}, Intrusion: IntrusionNormal},
+			health: apiv1.HealthStateTypeHealthy,
+			reason: "bmc reported 1 power supply(s) and 0 voltage rail(s) ok",
+		},
+		{
+			name:   "critical power supply",
+			snap:   &Snapshot{PowerSupplies: []PowerSupply{{Name: "PS1", Health: HealthCritical}}, Voltages: []Voltage{{Name: "P5V", Health: HealthWarning}}},
+			health: apiv1.HealthStateTypeUnhealthy,
+			reason: "bmc reported hardware failure: power supply PS1 critical; voltage P5V warning",
+		},
+		{
+			name:   "critical voltage",
+			snap:   &Snapshot{Voltages: []Voltage{{Name: "P3V3", Health: HealthCritical}}},
+			health: apiv1.HealthStateTypeUnhealthy,
+			reason: "bmc reported hardware failure: voltage P3V3 critical",
+		},
+		{
+			name:   "intrusion",
+			snap:   &Snapshot{Intrusion: "HardwareIntrusion"},
+			health: apiv1.HealthStateTypeDegraded,
+			reason: "bmc reported hardware warning: chassis intrusion HardwareIntrusion",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health, reason := evaluate(tt.snap)
+			assert.Equal(t, tt.health, health)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestCheckRecordsSELEvents(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	store, cleanup := openTestEventStore(t)
+	defer cleanup()
+
+	comp, err := New(&components.GPUdInstance{
+		RootCtx:    context.Background(),
+		EventStore: store,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+
+	c := mustComponent(t, comp)
+	c.getConfigFunc = func() Config { return Config{Endpoint: "https://10.0.0.10"} }
+	c.findIpmitoolFunc = func(Config) (string, bool) { return "", false }
+	c.getTimeNowFunc = func() time.Time { return now }
+	c.queryRedfishFunc = func(context.Context, Config) (*Snapshot, error) {
+		return &Snapshot{
+			Source:        SourceRedfish,
+			PowerSupplies: []PowerSupply{{Name: "PSU2", Health: HealthCritical}},
+			SELEntries: []SELEntry{
+				// older than the lookback
+				{ID: "1", Created: now.Add(-10 * 24 * time.Hour), Severity: HealthCritical, Message: "old"},
+				{ID: "2", Created: now.Add(-time.Hour), Severity: HealthCritical, Message: "PSU2 failed"},
+				{ID: "3", Created: now.Add(-time.Minute), Severity: HealthOK, Message: "PSU1 inserted"},
+			},
+		}, nil
+	}
+
+	// repeated checks must not duplicate the events
+	for i := 0; i < 2; i++ {
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	}
+
+	evs, err := c.Events(context.Background(), now.Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+
+	// latest first
+	assert.Equal(t, "PSU1 inserted", evs[0].Message)
+	assert.Equal(t, apiv1.EventTypeInfo, evs[0].Type)
+	assert.Equal(t, EventNameSEL, evs[1].Name)
+	assert.Equal(t, "PSU2 failed", evs[1].Message)
+	assert.Equal(t, apiv1.EventTypeCritical, evs[1].Type)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Endpoint: "https://10.0.0.10", Username: "admin", PasswordFile: "/etc/gpud/bmc-password"}.Validate())
+	assert.Error(t, Config{Endpoint: "10.0.0.10"}.Validate())
+	assert.Error(t, Config{Endpoint: "ftp://10.0.0.10"}.Validate())
+	assert.Error(t, Config{Endpoint: "https://10.0.0.10", Username: "admin"}.Validate())
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &Snapshot{})
+}
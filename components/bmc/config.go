@@ -0,0 +1,83 @@
+package bmc
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultRequestTimeout is the default timeout of each Redfish or IPMI query,
+	// since the BMCs are often slow to respond.
+	DefaultRequestTimeout = 30 * time.Second
+
+	// DefaultSELLookback is how far back the system event log entries are
+	// converted into the events, to not flood the events with the old history.
+	DefaultSELLookback = 3 * 24 * time.Hour
+)
+
+// Config configures how to query the BMC.
+// The Redfish endpoint is queried if set, otherwise (or if the Redfish query fails)
+// the local BMC is queried with "ipmitool" over the in-band IPMI interface.
+type Config struct {
+	// Endpoint is the Redfish service URL of the BMC (e.g., "https://10.0.0.10").
+	// Leave empty to only use the IPMI.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Username is the Redfish user name.
+	Username string `json:"username,omitempty"`
+	// PasswordFile is the file that contains the Redfish password,
+	// to not expose the password in the config or the process arguments.
+	PasswordFile string `json:"password_file,omitempty"`
+	// InsecureSkipVerify skips the TLS certificate verification of the BMC,
+	// which commonly uses a self-signed certificate.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+
+	// DisableIPMI disables the "ipmitool" fallback.
+	DisableIPMI bool `json:"disable_ipmi,omitempty"`
+	// IpmitoolPath is the path of the "ipmitool" command.
+	// Defaults to the "ipmitool" in the PATH if empty.
+	IpmitoolPath string `json:"ipmitool_path,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", cfg.Endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("endpoint %q must be an http or https URL", cfg.Endpoint)
+	}
+	if cfg.Username != "" && cfg.PasswordFile == "" {
+		return fmt.Errorf("password_file is required with username %q", cfg.Username)
+	}
+	return nil
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default BMC config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default BMC config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default bmc config", "endpoint", cfg.Endpoint, "username", cfg.Username, "disableIPMI", cfg.DisableIPMI)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
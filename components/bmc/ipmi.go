@@ -0,0 +1,206 @@
+package bmc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
+)
+
+// ipmiTimeLayout is the "ipmitool sel elist" date and time layout.
+const ipmiTimeLayout = "01/02/2006 15:04:05"
+
+// runCommandFunc runs the command and returns the combined output.
+type runCommandFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// runCommand runs the command via the shared runner, as the BMC is slow to respond
+// and the same "ipmitool" queries may be issued concurrently.
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return pkgexec.Run(ctx, name, args...)
+}
+
+// queryIPMI reads the power supplies, voltages, chassis intrusion, and
+// the system event log entries from the local BMC with "ipmitool".
+func queryIPMI(ctx context.Context, ipmitoolPath string, run runCommandFunc, loc *time.Location) (*Snapshot, error) {
+	snap := &Snapshot{Source: SourceIPMI}
+
+	out, err := run(ctx, ipmitoolPath, "sdr", "type", "Power Supply")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read power supply sensors: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	snap.PowerSupplies = parseIPMIPowerSupplies(string(out))
+
+	out, err = run(ctx, ipmitoolPath, "sdr", "type", "Voltage")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read voltage sensors: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	snap.Voltages = parseIPMIVoltages(string(out))
+
+	out, err = run(ctx, ipmitoolPath, "sdr", "type", "Physical Security")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read physical security sensors: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	snap.Intrusion = parseIPMIIntrusion(string(out))
+
+	out, err = run(ctx, ipmitoolPath, "sel", "elist")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system event log: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	snap.SELEntries = parseIPMISEL(string(out), loc)
+
+	return snap, nil
+}
+
+// sdrRecord is a line of the "ipmitool sdr type" output
+// (e.g., "PS1 Status | C8h | ok | 10.1 | Presence detected").
+type sdrRecord struct {
+	name   string
+	status string
+	text   string
+}
+
+func parseSDR(out string) []sdrRecord {
+	var records []sdrRecord
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 5 {
+			continue
+		}
+		records = append(records, sdrRecord{
+			name:   strings.TrimSpace(fields[0]),
+			status: strings.ToLower(strings.TrimSpace(fields[2])),
+			text:   strings.TrimSpace(strings.Join(fields[4:], "|")),
+		})
+	}
+	return records
+}
+
+// sdrHealth converts the sensor status to the Redfish health
+// ("cr" for critical, "nr" for non-recoverable, "nc" for non-critical).
+func sdrHealth(status string) string {
+	switch status {
+	case "cr", "nr", "lcr", "ucr", "lnr", "unr":
+		return HealthCritical
+	case "nc", "lnc", "unc":
+		return HealthWarning
+	default:
+		return HealthOK
+	}
+}
+
+func parseIPMIPowerSupplies(out string) []PowerSupply {
+	var psus []PowerSupply
+	for _, r := range parseSDR(out) {
+		psu := PowerSupply{
+			Name:   r.name,
+			State:  "Enabled",
+			Health: sdrHealth(r.status),
+		}
+
+		text := strings.ToLower(r.text)
+		switch {
+		case strings.Contains(text, "predictive failure"):
+			psu.Health = HealthWarning
+		case strings.Contains(text, "fail") || strings.Contains(text, "lost"):
+			psu.Health = HealthCritical
+		case r.status == "ns" || (text != "" && !strings.Contains(text, "presence detected")):
+			psu.State = "Absent"
+			psu.Health = ""
+		}
+		psus = append(psus, psu)
+	}
+	return psus
+}
+
+func parseIPMIVoltages(out string) []Voltage {
+	var voltages []Voltage
+	for _, r := range parseSDR(out) {
+		if r.status == "ns" {
+			continue
+		}
+		v := Voltage{
+			Name:   r.name,
+			Health: sdrHealth(r.status),
+		}
+		if reading, ok := strings.CutSuffix(r.text, " Volts"); ok {
+			var f float64
+			if _, err := fmt.Sscanf(reading, "%g", &f); err == nil {
+				v.ReadingVolts = &f
+			}
+		}
+		voltages = append(voltages, v)
+	}
+	return voltages
+}
+
+func parseIPMIIntrusion(out string) string {
+	records := parseSDR(out)
+	if len(records) == 0 {
+		return ""
+	}
+	for _, r := range records {
+		if strings.Contains(strings.ToLower(r.text), "intrusion") {
+			return "HardwareIntrusion"
+		}
+	}
+	return IntrusionNormal
+}
+
+// parseIPMISEL parses the "ipmitool sel elist" output
+// (e.g., "1 | 01/02/2025 | 03:04:05 | Power Supply PS1 Status | Power Supply AC lost | Asserted").
+// The entries without a valid timestamp (e.g., "Pre-Init") are skipped.
+func parseIPMISEL(out string, loc *time.Location) []SELEntry {
+	var entries []SELEntry
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 5 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		// newer ipmitool appends the time zone (e.g., "03:04:05 UTC")
+		clock, tz, hasTZ := strings.Cut(fields[2], " ")
+		entryLoc := loc
+		if hasTZ && strings.EqualFold(tz, "UTC") {
+			entryLoc = time.UTC
+		}
+		created, err := time.ParseInLocation(ipmiTimeLayout, fields[1]+" "+clock, entryLoc)
+		if err != nil {
+			continue
+		}
+
+		sensor, desc := fields[3], fields[4]
+		direction := ""
+		if len(fields) > 5 {
+			direction = fields[5]
+		}
+		msg := sensor + ": " + desc
+		if direction != "" {
+			msg += " (" + direction + ")"
+		}
+		entries = append(entries, SELEntry{
+			ID:       fields[0],
+			Created:  created.UTC(),
+			Severity: ipmiSELSeverity(desc, direction),
+			Message:  msg,
+		})
+	}
+	return entries
+}
+
+func ipmiSELSeverity(desc string, direction string) string {
+	if strings.EqualFold(direction, "Deasserted") {
+		return HealthOK
+	}
+	d := strings.ToLower(desc)
+	for _, kw := range []string{"fail", "lost", "non-recoverable", "uncorrectable", "critical", "intrusion"} {
+		if strings.Contains(d, kw) && !strings.Contains(d, "non-critical") {
+			return HealthCritical
+		}
+	}
+	return HealthWarning
+}
@@ -0,0 +1,107 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSDRPowerSupply = `PS1 Status       | C8h | ok  | 10.1 | Presence detected
+PS2 Status       | C9h | ok  | 10.2 | Presence detected, Power Supply AC lost
+PS3 Status       | CAh | ok  | 10.3 | Presence detected, Predictive failure
+PS4 Status       | CBh | ns  | 10.4 | No Reading
+`
+	testSDRVoltage = `P12V             | 30h | ok  |  7.1 | 12.10 Volts
+P3V3             | 31h | cr  |  7.1 | 2.80 Volts
+P5V              | 32h | nc  |  7.1 | 4.70 Volts
+PVCCIN           | 33h | ns  |  7.1 | No Reading
+`
+	testSDRPhysicalSecurity = `Chassis Intru     | 73h | ok  | 23.1 | General Chassis intrusion
+`
+	testSELElist = `   1 | 01/02/2025 | 03:04:05 UTC | Power Supply PS2 Status | Power Supply AC lost | Asserted
+   2 | Pre-Init   | 0000000001 | System Event | Timestamp Clock Sync | Asserted
+   3 | 01/02/2025 | 03:05:05 UTC | Power Supply PS2 Status | Power Supply AC lost | Deasserted
+   4 | 01/02/2025 | 03:06:05 | Memory #0x01 | Correctable ECC | Asserted
+`
+)
+
+func TestParseIPMIPowerSupplies(t *testing.T) {
+	assert.Equal(t, []PowerSupply{
+		{Name: "PS1 Status", State: "Enabled", Health: HealthOK},
+		{Name: "PS2 Status", State: "Enabled", Health: HealthCritical},
+		{Name: "PS3 Status", State: "Enabled", Health: HealthWarning},
+		{Name: "PS4 Status", State: "Absent"},
+	}, parseIPMIPowerSupplies(testSDRPowerSupply))
+	assert.Empty(t, parseIPMIPowerSupplies(""))
+}
+
+func TestParseIPMIVoltages(t *testing.T) {
+	voltages := parseIPMIVoltages(testSDRVoltage)
+	require.Len(t, voltages, 3)
+	assert.Equal(t, "P12V", voltages[0].Name)
+	assert.Equal(t, HealthOK, voltages[0].Health)
+	require.NotNil(t, voltages[0].ReadingVolts)
+	assert.InDelta(t, 12.1, *voltages[0].ReadingVolts, 0.001)
+	assert.Equal(t, HealthCritical, voltages[1].Health)
+	assert.Equal(t, HealthWarning, voltages[2].Health)
+}
+
+func TestParseIPMIIntrusion(t *testing.T) {
+	assert.Equal(t, "HardwareIntrusion", parseIPMIIntrusion(testSDRPhysicalSecurity))
+	assert.Equal(t, IntrusionNormal, parseIPMIIntrusion("Chassis Intru     | 73h | ok  | 23.1 | \n"))
+	assert.Empty(t, parseIPMIIntrusion(""))
+}
+
+func TestParseIPMISEL(t *testing.T) {
+	loc := time.FixedZone("test", 3600)
+	entries := parseIPMISEL(testSELElist, loc)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, SELEntry{
+		ID:       "1",
+		Created:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Severity: HealthCritical,
+		Message:  "Power Supply PS2 Status: Power Supply AC lost (Asserted)",
+	}, entries[0])
+	assert.Equal(t, HealthOK, entries[1].Severity)
+	assert.Equal(t, "4", entries[2].ID)
+	assert.Equal(t, time.Date(2025, 1, 2, 2, 6, 5, 0, time.UTC), entries[2].Created)
+	assert.Equal(t, HealthWarning, entries[2].Severity)
+}
+
+func TestQueryIPMI(t *testing.T) {
+	outputs := map[string]string{
+		"sdr type Power Supply":      testSDRPowerSupply,
+		"sdr type Voltage":           testSDRVoltage,
+		"sdr type Physical Security": testSDRPhysicalSecurity,
+		"sel elist":                  testSELElist,
+	}
+	run := func(_ context.Context, name string, args ...string) ([]byte, error) {
+		assert.Equal(t, "/usr/bin/ipmitool", name)
+		out, ok := outputs[strings.Join(args, " ")]
+		if !ok {
+			return []byte("unknown command"), errors.New("exit status 1")
+		}
+		return []byte(out), nil
+	}
+
+	snap, err := queryIPMI(context.Background(), "/usr/bin/ipmitool", run, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, SourceIPMI, snap.Source)
+	assert.Len(t, snap.PowerSupplies, 4)
+	assert.Len(t, snap.Voltages, 3)
+	assert.Equal(t, "HardwareIntrusion", snap.Intrusion)
+	assert.Len(t, snap.SELEntries, 3)
+
+	delete(outputs, "sel elist")
+	_, err = queryIPMI(context.Background(), "/usr/bin/ipmitool", run, time.UTC)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "system event log")
+	assert.Contains(t, err.Error(), "unknown command")
+}
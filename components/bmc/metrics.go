@@ -0,0 +1,48 @@
+package bmc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the BMC component.
+const SubSystem = "bmc"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricVoltage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "voltage_volts",
+			Help:      "tracks the voltage rail readings reported by the BMC",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "rail"}, // label is voltage rail name
+	).MustCurryWith(componentLabel)
+
+	metricPowerSupplyHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "power_supply_healthy",
+			Help:      "tracks whether the power supply unit is healthy (1) or not (0)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "power_supply"}, // label is power supply name
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricVoltage,
+		metricPowerSupplyHealthy,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_voltage_volts", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitVolts},
+		apiv1.MetricMetadata{Name: SubSystem + "_power_supply_healthy", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
+}
@@ -0,0 +1,273 @@
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	redfishPathChassis  = "/redfish/v1/Chassis"
+	redfishPathManagers = "/redfish/v1/Managers"
+	redfishPathSystems  = "/redfish/v1/Systems"
+
+	// maxRedfishPages is the maximum number of the collection pages to follow.
+	maxRedfishPages = 10
+)
+
+type redfishLink struct {
+	ODataID string `json:"@odata.id"`
+}
+
+type redfishCollection struct {
+	Members  []redfishLink `json:"Members"`
+	NextLink string        `json:"Members@odata.nextLink"`
+}
+
+type redfishStatus struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+type redfishChassis struct {
+	PhysicalSecurity *struct {
+		IntrusionSensor string `json:"IntrusionSensor"`
+	} `json:"PhysicalSecurity"`
+	Power *redfishLink `json:"Power"`
+}
+
+type redfishPower struct {
+	PowerSupplies []struct {
+		Name   string        `json:"Name"`
+		Status redfishStatus `json:"Status"`
+	} `json:"PowerSupplies"`
+	Voltages []struct {
+		Name         string        `json:"Name"`
+		ReadingVolts *float64      `json:"ReadingVolts"`
+		Status       redfishStatus `json:"Status"`
+	} `json:"Voltages"`
+}
+
+type redfishLogServices struct {
+	LogServices *redfishLink `json:"LogServices"`
+}
+
+type redfishLogService struct {
+	Entries *redfishLink `json:"Entries"`
+}
+
+type redfishLogEntries struct {
+	Members []struct {
+		ID       string `json:"Id"`
+		Created  string `json:"Created"`
+		Severity string `json:"Severity"`
+		Message  string `json:"Message"`
+	} `json:"Members"`
+	NextLink string `json:"Members@odata.nextLink"`
+}
+
+// redfishClient queries the BMC via the Redfish API.
+type redfishClient struct {
+	endpoint     string
+	username     string
+	passwordFile string
+	cli          *http.Client
+}
+
+func newRedfishClient(cfg Config) *redfishClient {
+	return &redfishClient{
+		endpoint:     strings.TrimSuffix(cfg.Endpoint, "/"),
+		username:     cfg.Username,
+		passwordFile: cfg.PasswordFile,
+		cli: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				// #nosec G402 -- opted in by the operator for the self-signed BMC certificates.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+			},
+		},
+	}
+}
+
+// query reads the power supplies, voltages, and chassis intrusion of all the chassis,
+// and the system event log entries of the managers (or the systems).
+func (rc *redfishClient) query(ctx context.Context) (*Snapshot, error) {
+	password := ""
+	if rc.passwordFile != "" {
+		b, err := os.ReadFile(rc.passwordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimSpace(string(b))
+	}
+
+	snap := &Snapshot{Source: SourceRedfish}
+
+	chassisLinks, err := rc.members(ctx, password, redfishPathChassis)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range chassisLinks {
+		var chassis redfishChassis
+		if err := rc.get(ctx, password, link, &chassis); err != nil {
+			return nil, err
+		}
+		if chassis.PhysicalSecurity != nil && chassis.PhysicalSecurity.IntrusionSensor != "" {
+			// report the intrusion of any chassis
+			if snap.Intrusion == "" || snap.Intrusion == IntrusionNormal {
+				snap.Intrusion = chassis.PhysicalSecurity.IntrusionSensor
+			}
+		}
+		if chassis.Power == nil || chassis.Power.ODataID == "" {
+			continue
+		}
+
+		var power redfishPower
+		if err := rc.get(ctx, password, chassis.Power.ODataID, &power); err != nil {
+			return nil, err
+		}
+		for _, psu := range power.PowerSupplies {
+			snap.PowerSupplies = append(snap.PowerSupplies, PowerSupply{
+				Name:   psu.Name,
+				State:  psu.Status.State,
+				Health: psu.Status.Health,
+			})
+		}
+		for _, v := range power.Voltages {
+			snap.Voltages = append(snap.Voltages, Voltage{
+				Name:         v.Name,
+				ReadingVolts: v.ReadingVolts,
+				Health:       v.Status.Health,
+			})
+		}
+	}
+
+	snap.SELEntries, err = rc.selEntries(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// selEntries reads the entries of the "SEL" log service,
+// which is under the managers or the systems depending on the vendor.
+func (rc *redfishClient) selEntries(ctx context.Context, password string) ([]SELEntry, error) {
+	for _, root := range []string{redfishPathManagers, redfishPathSystems} {
+		links, err := rc.members(ctx, password, root)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			var res redfishLogServices
+			if err := rc.get(ctx, password, link, &res); err != nil {
+				return nil, err
+			}
+			if res.LogServices == nil || res.LogServices.ODataID == "" {
+				continue
+			}
+
+			services, err := rc.members(ctx, password, res.LogServices.ODataID)
+			if err != nil {
+				return nil, err
+			}
+			for _, svc := range services {
+				if !strings.Contains(strings.ToLower(lastSegment(svc)), "sel") {
+					continue
+				}
+
+				var ls redfishLogService
+				if err := rc.get(ctx, password, svc, &ls); err != nil {
+					return nil, err
+				}
+				if ls.Entries == nil || ls.Entries.ODataID == "" {
+					continue
+				}
+				return rc.logEntries(ctx, password, ls.Entries.ODataID)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (rc *redfishClient) logEntries(ctx context.Context, password string, path string) ([]SELEntry, error) {
+	var entries []SELEntry
+	for page := 0; path != "" && page < maxRedfishPages; page++ {
+		var res redfishLogEntries
+		if err := rc.get(ctx, password, path, &res); err != nil {
+			return nil, err
+		}
+		for _, m := range res.Members {
+			created, err := time.Parse(time.RFC3339, m.Created)
+			if err != nil {
+				// entries without a valid timestamp cannot be ordered
+				continue
+			}
+			entries = append(entries, SELEntry{
+				ID:       m.ID,
+				Created:  created.UTC(),
+				Severity: m.Severity,
+				Message:  m.Message,
+			})
+		}
+		path = res.NextLink
+	}
+	return entries, nil
+}
+
+// members returns the member links of the collection.
+func (rc *redfishClient) members(ctx context.Context, password string, path string) ([]string, error) {
+	var links []string
+	for page := 0; path != "" && page < maxRedfishPages; page++ {
+		var res redfishCollection
+		if err := rc.get(ctx, password, path, &res); err != nil {
+			return nil, err
+		}
+		for _, m := range res.Members {
+			if m.ODataID != "" {
+				links = append(links, m.ODataID)
+			}
+		}
+		path = res.NextLink
+	}
+	return links, nil
+}
+
+func (rc *redfishClient) get(ctx context.Context, password string, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rc.endpoint+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if rc.username != "" {
+		req.SetBasicAuth(rc.username, password)
+	}
+
+	resp, err := rc.cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %q: %w", path, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %q: %w", path, err)
+	}
+	return nil
+}
+
+// lastSegment returns the last path segment of the link (e.g., "SEL" of "/redfish/v1/Managers/1/LogServices/SEL").
+func lastSegment(link string) string {
+	link = strings.TrimSuffix(link, "/")
+	return link[strings.LastIndex(link, "/")+1:]
+}
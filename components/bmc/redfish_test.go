@@ -0,0 +1,109 @@
+package bmc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRedfishResponses = map[string]string{
+	"/redfish/v1/Chassis":   `{"Members":[{"@odata.id":"/redfish/v1/Chassis/1"}]}`,
+	"/redfish/v1/Chassis/1": `{"PhysicalSecurity":{"IntrusionSensor":"Normal"},"Power":{"@odata.id":"/redfish/v1/Chassis/1/Power"}}`,
+	"/redfish/v1/Chassis/1/Power": `{
+		"PowerSupplies":[
+			{"Name":"PSU1","Status":{"State":"Enabled","Health":"OK"}},
+			{"Name":"PSU2","Status":{"State":"Enabled","Health":"Critical"}}
+		],
+		"Voltages":[{"Name":"P12V","ReadingVolts":12.1,"Status":{"Health":"OK"}}]
+	}`,
+	"/redfish/v1/Managers":                   `{"Members":[{"@odata.id":"/redfish/v1/Managers/1"}]}`,
+	"/redfish/v1/Managers/1":                 `{"LogServices":{"@odata.id":"/redfish/v1/Managers/1/LogServices"}}`,
+	"/redfish/v1/Managers/1/LogServices":     `{"Members":[{"@odata.id":"/redfish/v1/Managers/1/LogServices/Lclog"},{"@odata.id":"/redfish/v1/Managers/1/LogServices/SEL"}]}`,
+	"/redfish/v1/Managers/1/LogServices/SEL": `{"Entries":{"@odata.id":"/redfish/v1/Managers/1/LogServices/SEL/Entries"}}`,
+	"/redfish/v1/Managers/1/LogServices/SEL/Entries": `{
+		"Members":[{"Id":"1","Created":"2025-01-02T03:04:05Z","Severity":"Critical","Message":"PSU2 failed"}],
+		"Members@odata.nextLink":"/redfish/v1/Managers/1/LogServices/SEL/Entries?page=2"
+	}`,
+	"/redfish/v1/Managers/1/LogServices/SEL/Entries?page=2": `{
+		"Members":[
+			{"Id":"2","Created":"invalid","Severity":"OK","Message":"skipped"},
+			{"Id":"3","Created":"2025-01-02T04:04:05+01:00","Severity":"Warning","Message":"fan slow"}
+		]
+	}`,
+}
+
+func newTestRedfishServer(t *testing.T, username string, password string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username != "" {
+			u, p, ok := r.BasicAuth()
+			if !ok || u != username || p != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		body, ok := testRedfishResponses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRedfishQuery(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0600))
+
+	srv := newTestRedfishServer(t, "admin", "secret")
+	snap, err := newRedfishClient(Config{
+		Endpoint:     srv.URL + "/",
+		Username:     "admin",
+		PasswordFile: passwordFile,
+	}).query(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, SourceRedfish, snap.Source)
+	assert.Equal(t, IntrusionNormal, snap.Intrusion)
+	assert.Equal(t, []PowerSupply{
+		{Name: "PSU1", State: "Enabled", Health: HealthOK},
+		{Name: "PSU2", State: "Enabled", Health: HealthCritical},
+	}, snap.PowerSupplies)
+	require.Len(t, snap.Voltages, 1)
+	assert.Equal(t, "P12V", snap.Voltages[0].Name)
+	require.NotNil(t, snap.Voltages[0].ReadingVolts)
+	assert.InDelta(t, 12.1, *snap.Voltages[0].ReadingVolts, 0.001)
+
+	require.Len(t, snap.SELEntries, 2)
+	assert.Equal(t, SELEntry{ID: "1", Created: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Severity: HealthCritical, Message: "PSU2 failed"}, snap.SELEntries[0])
+	assert.Equal(t, "3", snap.SELEntries[1].ID)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), snap.SELEntries[1].Created)
+}
+
+func TestRedfishQueryErrors(t *testing.T) {
+	srv := newTestRedfishServer(t, "admin", "secret")
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("wrong"), 0600))
+	_, err := newRedfishClient(Config{Endpoint: srv.URL, Username: "admin", PasswordFile: passwordFile}).query(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	_, err = newRedfishClient(Config{Endpoint: srv.URL, Username: "admin", PasswordFile: filepath.Join(t.TempDir(), "missing")}).query(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password file")
+}
+
+func TestLastSegment(t *testing.T) {
+	assert.Equal(t, "SEL", lastSegment("/redfish/v1/Managers/1/LogServices/SEL"))
+	assert.Equal(t, "SEL", lastSegment("/redfish/v1/Managers/1/LogServices/SEL/"))
+	assert.Equal(t, "SEL", lastSegment("SEL"))
+}
@@ -0,0 +1,80 @@
+package bmc
+
+import (
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// SourceRedfish is the snapshot source when queried via Redfish.
+	SourceRedfish = "redfish"
+	// SourceIPMI is the snapshot source when queried via "ipmitool".
+	SourceIPMI = "ipmi"
+
+	// HealthOK is the Redfish health of the resource that is normal.
+	HealthOK = "OK"
+	// HealthWarning is the Redfish health of the resource that requires attention.
+	HealthWarning = "Warning"
+	// HealthCritical is the Redfish health of the resource that requires immediate attention.
+	HealthCritical = "Critical"
+
+	// IntrusionNormal is the chassis intrusion sensor status with no intrusion.
+	IntrusionNormal = "Normal"
+)
+
+// Snapshot is the hardware health reported by the BMC.
+type Snapshot struct {
+	// Source is how the BMC was queried ("redfish" or "ipmi").
+	Source string `json:"source"`
+
+	PowerSupplies []PowerSupply `json:"power_supplies,omitempty"`
+	Voltages      []Voltage     `json:"voltages,omitempty"`
+
+	// Intrusion is the chassis intrusion sensor status
+	// (e.g., "Normal", "HardwareIntrusion"), empty if not reported.
+	Intrusion string `json:"intrusion,omitempty"`
+
+	// SELEntries is the system event log entries.
+	SELEntries []SELEntry `json:"-"`
+}
+
+// PowerSupply is the status of a power supply unit.
+type PowerSupply struct {
+	Name string `json:"name"`
+	// State is the Redfish state (e.g., "Enabled", "Absent").
+	State string `json:"state,omitempty"`
+	// Health is the Redfish health ("OK", "Warning", or "Critical").
+	Health string `json:"health,omitempty"`
+}
+
+// Voltage is the reading of a voltage rail sensor.
+type Voltage struct {
+	Name string `json:"name"`
+	// ReadingVolts is the voltage reading, nil if not available.
+	ReadingVolts *float64 `json:"reading_volts,omitempty"`
+	// Health is the Redfish health ("OK", "Warning", or "Critical").
+	Health string `json:"health,omitempty"`
+}
+
+// SELEntry is an entry of the BMC system event log.
+type SELEntry struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	// Severity is the Redfish severity ("OK", "Warning", or "Critical").
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+}
+
+// EventType returns the event type of the SEL entry by its severity.
+func (e SELEntry) EventType() apiv1.EventType {
+	switch {
+	case strings.EqualFold(e.Severity, HealthCritical):
+		return apiv1.EventTypeCritical
+	case strings.EqualFold(e.Severity, HealthWarning):
+		return apiv1.EventTypeWarning
+	default:
+		return apiv1.EventTypeInfo
+	}
+}
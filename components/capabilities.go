@@ -0,0 +1,117 @@
+package components
+
+import (
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// CapabilitiesExtraInfoKey is the health state extra info key
+	// for the comma-separated capabilities the component is running with.
+	CapabilitiesExtraInfoKey = "capabilities"
+	// DegradedCapabilitiesExtraInfoKey is the health state extra info key
+	// for the comma-separated capabilities the component is degraded without.
+	DegradedCapabilitiesExtraInfoKey = "degraded_without"
+)
+
+// Capabilities looks up the data sources available on the host (e.g., "nvml", "kmsg").
+type Capabilities interface {
+	// Available returns true if the capability is available.
+	Available(name string) bool
+}
+
+// WithCapabilities wraps the initialization function so that the initialized component
+// annotates its health states with the required capabilities it is running with
+// or degraded without.
+// It returns the original initialization function if the capabilities are nil
+// or the component requires none.
+func WithCapabilities(initFunc InitFunc, required []string, caps Capabilities) InitFunc {
+	if caps == nil || len(required) == 0 {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newCapabilitiesComponent(c, required, caps), nil
+	}
+}
+
+func newCapabilitiesComponent(c Component, required []string, caps Capabilities) Component {
+	cc := &capabilitiesComponent{
+		Component: c,
+		required:  required,
+		caps:      caps,
+	}
+	return wrapComponent(cc, c, nil)
+}
+
+var _ Component = &capabilitiesComponent{}
+
+// capabilitiesComponent wraps a component to annotate its health states
+// with the required capabilities.
+type capabilitiesComponent struct {
+	Component
+
+	required []string
+	caps     Capabilities
+}
+
+func (c *capabilitiesComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+	return wrapCheckResult(&capabilitiesCheckResult{CheckResult: cr, states: c.tagHealthStates(cr.HealthStates())}, cr)
+}
+
+func (c *capabilitiesComponent) LastHealthStates() apiv1.HealthStates {
+	return c.tagHealthStates(c.Component.LastHealthStates())
+}
+
+// tagHealthStates returns a copy of the health states tagged with the capabilities.
+func (c *capabilitiesComponent) tagHealthStates(states apiv1.HealthStates) apiv1.HealthStates {
+	if len(states) == 0 {
+		return states
+	}
+
+	var using, degraded []string
+	for _, name := range c.required {
+		if c.caps.Available(name) {
+			using = append(using, name)
+		} else {
+			degraded = append(degraded, name)
+		}
+	}
+
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		extraInfo := make(map[string]string, len(s.ExtraInfo)+2)
+		for k, v := range s.ExtraInfo {
+			extraInfo[k] = v
+		}
+		if len(using) > 0 {
+			extraInfo[CapabilitiesExtraInfoKey] = strings.Join(using, ",")
+		}
+		if len(degraded) > 0 {
+			extraInfo[DegradedCapabilitiesExtraInfoKey] = strings.Join(degraded, ",")
+		}
+		s.ExtraInfo = extraInfo
+		copied = append(copied, s)
+	}
+	return copied
+}
+
+var _ CheckResult = &capabilitiesCheckResult{}
+
+// capabilitiesCheckResult overrides the health states of the underlying check result.
+type capabilitiesCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *capabilitiesCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type fixedCapabilities map[string]bool
+
+func (c fixedCapabilities) Available(name string) bool {
+	return c[name]
+}
+
+func TestWithCapabilitiesNil(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithCapabilities(initFunc, []string{"nvml"}, nil)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+
+	c, err = WithCapabilities(initFunc, nil, fixedCapabilities{})(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestCapabilitiesComponent(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	inner := &scriptedComponent{
+		ts:     ts,
+		script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy},
+	}
+	caps := fixedCapabilities{"nvml": true}
+	c := newCapabilitiesComponent(inner, []string{"nvml", "kmsg"}, caps)
+
+	cr := c.Check()
+	require.Len(t, cr.HealthStates(), 1)
+	assert.Equal(t, "nvml", cr.HealthStates()[0].ExtraInfo[CapabilitiesExtraInfoKey])
+	assert.Equal(t, "kmsg", cr.HealthStates()[0].ExtraInfo[DegradedCapabilitiesExtraInfoKey])
+	// the underlying health states are not modified
+	assert.Nil(t, inner.LastHealthStates()[0].ExtraInfo)
+
+	// reflects the capabilities as of now
+	caps["kmsg"] = true
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "nvml,kmsg", states[0].ExtraInfo[CapabilitiesExtraInfoKey])
+	_, ok := states[0].ExtraInfo[DegradedCapabilitiesExtraInfoKey]
+	assert.False(t, ok)
+}
+
+func TestCapabilitiesComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newCapabilitiesComponent(inner, []string{"nvml"}, fixedCapabilities{})
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newCapabilitiesComponent(&scriptedComponent{}, []string{"nvml"}, fixedCapabilities{}).(HealthSettable)
+	assert.False(t, ok)
+}
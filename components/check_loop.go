@@ -0,0 +1,155 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// CheckDurationMetricName is the name of the histogram of the periodic component check
+// durations, stored in the metrics store as the reserved histogram metric names
+// (e.g., the "_bucket" suffix) to summarize the check latencies over time.
+const CheckDurationMetricName = "gpud_component_check_duration_seconds"
+
+var metricCheckDurationSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "gpud",
+		Subsystem: "component",
+		Name:      "check_duration_seconds",
+		Help:      "time taken to run the periodic check of the component, bounded by the watchdog timeout if enabled",
+
+		// want to track with lowest bound 0.01s (10ms) and highest bound 10 minutes
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 180, 600},
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricCheckDurationSeconds)
+}
+
+// checkLoopBinder is implemented by the components embedding [CheckLoop],
+// and forwarded by the wrappers of the components (see [WithCheckLoop]).
+type checkLoopBinder interface {
+	bindCheckLoop(check func() CheckResult, gpudInstance *GPUdInstance)
+}
+
+// CheckLoop runs the periodic checks of a component.
+// Embed in the component to run the periodic checks through the wrappers
+// of the component (e.g., bounded by the watchdog), once bound with [WithCheckLoop].
+// The zero value runs the checks of the component itself.
+type CheckLoop struct {
+	mu sync.Mutex
+	// check is the check of the outermost wrapper, nil if not bound
+	check func() CheckResult
+	// observeDuration is called with the duration of every check, nil if not set
+	observeDuration func(componentName string, elapsed time.Duration)
+	// adaptiveIntervals returns the adaptive interval config, nil if not set
+	adaptiveIntervals func(componentName string) AdaptiveIntervalConfig
+	// deferrer defers the checks, nil if not set
+	deferrer CheckDeferrer
+}
+
+func (l *CheckLoop) bindCheckLoop(check func() CheckResult, gpudInstance *GPUdInstance) {
+	l.mu.Lock()
+	l.check = check
+	l.observeDuration = gpudInstance.CheckDurationObserver
+	l.adaptiveIntervals = gpudInstance.AdaptiveCheckIntervals
+	l.deferrer = gpudInstance.CheckDeferrer
+	l.mu.Unlock()
+}
+
+// Start runs the periodic checks of the component in the background until the context
+// is done, the first one immediately and the next ones on the ticks of the [CheckTicker],
+// at the interval adapted by [GPUdInstance.AdaptiveCheckIntervals] and deferred by
+// [GPUdInstance.CheckDeferrer] if bound.
+// The check is replaced by the check of the outermost wrapper, if bound with [WithCheckLoop].
+// The panics in the checks are recovered with [RecoverCheck].
+func (l *CheckLoop) Start(ctx context.Context, componentName string, interval time.Duration, check func() CheckResult) {
+	l.start(ctx, componentName, interval, check, true)
+}
+
+// StartAfterInterval is the same as [CheckLoop.Start], but runs the first check
+// on the first tick (e.g., once the component collected the data to check).
+func (l *CheckLoop) StartAfterInterval(ctx context.Context, componentName string, interval time.Duration, check func() CheckResult) {
+	l.start(ctx, componentName, interval, check, false)
+}
+
+func (l *CheckLoop) start(ctx context.Context, componentName string, interval time.Duration, check func() CheckResult, immediate bool) {
+	ticker := l.newTicker(componentName, interval)
+	go func() {
+		defer ticker.Stop()
+
+		if immediate {
+			ticker.Observe(l.RunCheck(componentName, check))
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ticker.Observe(l.RunCheck(componentName, check))
+		}
+	}()
+}
+
+func (l *CheckLoop) newTicker(componentName string, interval time.Duration) *CheckTicker {
+	l.mu.Lock()
+	adaptiveIntervals := l.adaptiveIntervals
+	deferrer := l.deferrer
+	l.mu.Unlock()
+
+	var cfg AdaptiveIntervalConfig
+	if adaptiveIntervals != nil {
+		cfg = adaptiveIntervals(componentName)
+	}
+	return NewCheckTicker(componentName, interval, cfg, deferrer)
+}
+
+// RunCheck runs a single periodic check of the component, for the components
+// scheduling their own checks (e.g., once a day).
+// The check is replaced by the check of the outermost wrapper, if bound with [WithCheckLoop].
+// The panics in the check are recovered with [RecoverCheck], and the duration
+// of the check is recorded and passed to [GPUdInstance.CheckDurationObserver].
+func (l *CheckLoop) RunCheck(componentName string, check func() CheckResult) CheckResult {
+	l.mu.Lock()
+	if l.check != nil {
+		check = l.check
+	}
+	observeDuration := l.observeDuration
+	l.mu.Unlock()
+
+	start := time.Now()
+	cr := RecoverCheck(componentName, check)
+	elapsed := time.Since(start)
+
+	metricCheckDurationSeconds.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: componentName}).Observe(elapsed.Seconds())
+	if observeDuration != nil {
+		observeDuration(componentName, elapsed)
+	}
+	return cr
+}
+
+// WithCheckLoop wraps the initialization function so that the periodic checks
+// of the initialized component started with [CheckLoop] run through the check
+// of the component returned by the initialization function, configured by the
+// GPUd instance (e.g., [GPUdInstance.AdaptiveCheckIntervals]).
+// Must be the outermost wrapper, to run the periodic checks through all the others.
+func WithCheckLoop(initFunc InitFunc) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := c.(checkLoopBinder); ok {
+			b.bindCheckLoop(c.Check, gpudInstance)
+		}
+		return c, nil
+	}
+}
@@ -0,0 +1,170 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// loopComponent runs the periodic checks of the blocking component with the check loop.
+type loopComponent struct {
+	CheckLoop
+	*blockingComponent
+
+	ctx           context.Context
+	interval      time.Duration
+	afterInterval bool
+}
+
+func (c *loopComponent) Start() error {
+	if c.afterInterval {
+		c.CheckLoop.StartAfterInterval(c.ctx, c.Name(), c.interval, c.Check)
+	} else {
+		c.CheckLoop.Start(c.ctx, c.Name(), c.interval, c.Check)
+	}
+	return nil
+}
+
+func (c *loopComponent) Check() CheckResult {
+	return c.blockingComponent.Check()
+}
+
+func newLoopComponent(t *testing.T, interval time.Duration) *loopComponent {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &loopComponent{
+		blockingComponent: &blockingComponent{release: make(chan struct{})},
+		ctx:               ctx,
+		interval:          interval,
+	}
+}
+
+func TestCheckLoopStart(t *testing.T) {
+	c := newLoopComponent(t, time.Hour)
+	close(c.release)
+
+	require.NoError(t, c.Start())
+	assert.Eventually(t, func() bool { return c.numChecks() == 1 }, time.Second, 5*time.Millisecond)
+
+	c = newLoopComponent(t, 10*time.Millisecond)
+	close(c.release)
+
+	require.NoError(t, c.Start())
+	assert.Eventually(t, func() bool { return c.numChecks() >= 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestCheckLoopStartAfterInterval(t *testing.T) {
+	c := newLoopComponent(t, time.Hour)
+	c.afterInterval = true
+	close(c.release)
+
+	require.NoError(t, c.Start())
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, c.numChecks())
+}
+
+func TestCheckLoopRunCheckRecover(t *testing.T) {
+	var l CheckLoop
+	cr := l.RunCheck("test", func() CheckResult { panic("boom") })
+
+	require.NotNil(t, cr)
+	assert.Equal(t, InternalErrorReason, cr.HealthStates()[0].Reason)
+}
+
+func TestCheckLoopRunCheckDuration(t *testing.T) {
+	c := &blockingComponent{release: make(chan struct{})}
+	close(c.release)
+
+	var l CheckLoop
+	l.RunCheck("test-duration", c.Check)
+	l.RunCheck("test-duration", c.Check)
+	assert.Equal(t, uint64(2), checkDurationCount(t, "test-duration"))
+}
+
+func checkDurationCount(t *testing.T, componentName string) uint64 {
+	m := &dto.Metric{}
+	require.NoError(t, metricCheckDurationSeconds.WithLabelValues(componentName).(prometheus.Histogram).Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestWithCheckLoop(t *testing.T) {
+	inner := newLoopComponent(t, 10*time.Millisecond)
+	t.Cleanup(func() { close(inner.release) })
+
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+	initFunc = WithWatchdog(initFunc, WatchdogConfig{Timeout: metav1.Duration{Duration: 20 * time.Millisecond}, HangThreshold: 2})
+
+	c, err := WithCheckLoop(initFunc)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+
+	// the periodic checks time out on the watchdog, without starting another check
+	assert.Eventually(t, func() bool {
+		states := c.LastHealthStates()
+		return len(states) == 1 && states[0].Reason == WatchdogHangReason
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, inner.numChecks())
+}
+
+func TestWithCheckLoopDurationObserver(t *testing.T) {
+	inner := newLoopComponent(t, time.Hour)
+	close(inner.release)
+
+	observed := make(chan string, 1)
+	gpudInstance := &GPUdInstance{
+		RootCtx: context.Background(),
+		CheckDurationObserver: func(componentName string, _ time.Duration) {
+			observed <- componentName
+		},
+	}
+	c, err := WithCheckLoop(func(*GPUdInstance) (Component, error) { return inner, nil })(gpudInstance)
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+
+	select {
+	case name := <-observed:
+		assert.Equal(t, "blocking", name)
+	case <-time.After(time.Second):
+		t.Fatal("check duration not observed")
+	}
+}
+
+func TestWithCheckLoopAdaptiveIntervals(t *testing.T) {
+	cfg := AdaptiveIntervalConfig{MaxInterval: metav1.Duration{Duration: 10 * time.Minute}}
+	gpudInstance := &GPUdInstance{
+		RootCtx: context.Background(),
+		AdaptiveCheckIntervals: func(componentName string) AdaptiveIntervalConfig {
+			if componentName == "cpu" {
+				return cfg
+			}
+			return AdaptiveIntervalConfig{}
+		},
+	}
+
+	var l CheckLoop
+	ticker := l.newTicker("cpu", time.Minute)
+	assert.Equal(t, AdaptiveIntervalConfig{}, ticker.cfg)
+	ticker.Stop()
+
+	l.bindCheckLoop(nil, gpudInstance)
+	ticker = l.newTicker("cpu", time.Minute)
+	assert.Equal(t, cfg, ticker.cfg)
+	ticker.Stop()
+
+	ticker = l.newTicker("disk", time.Minute)
+	assert.Equal(t, AdaptiveIntervalConfig{}, ticker.cfg)
+	ticker.Stop()
+}
+
+func TestWithCheckLoopInitError(t *testing.T) {
+	initFunc := func(*GPUdInstance) (Component, error) { return nil, assert.AnError }
+
+	_, err := WithCheckLoop(initFunc)(&GPUdInstance{RootCtx: context.Background()})
+	require.ErrorIs(t, err, assert.AnError)
+}
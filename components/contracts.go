@@ -0,0 +1,65 @@
+package components
+
+import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/contracts"
+)
+
+// WithContracts wraps the initialization function so that the initialized component
+// replaces the health state extra info violating its data contract with the violation
+// (see [contracts.EnforceHealthStates]), and counts the violations.
+// The health and the reason of the health states are kept as is.
+//
+// It must wrap the component initialization function before the other wrappers
+// that add the extra info (e.g., [WithMute]), as their keys are not in the contracts.
+func WithContracts(initFunc InitFunc) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newContractComponent(c), nil
+	}
+}
+
+func newContractComponent(c Component) Component {
+	cc := &contractComponent{Component: c}
+	return wrapComponent(cc, c, nil)
+}
+
+var _ Component = &contractComponent{}
+
+// contractComponent wraps a component to enforce its data contract on the health states.
+type contractComponent struct {
+	Component
+}
+
+func (c *contractComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+
+	states, rejected := contracts.EnforceHealthStates(c.Name(), cr.HealthStates())
+	if !rejected {
+		return cr
+	}
+	return wrapCheckResult(&contractCheckResult{CheckResult: cr, states: states}, cr)
+}
+
+func (c *contractComponent) LastHealthStates() apiv1.HealthStates {
+	states, _ := contracts.EnforceHealthStates(c.Name(), c.Component.LastHealthStates())
+	return states
+}
+
+var _ CheckResult = &contractCheckResult{}
+
+// contractCheckResult overrides the health states of the underlying check result.
+type contractCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *contractCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
@@ -0,0 +1,65 @@
+package components
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/contracts"
+)
+
+// contractedComponent reports the health states as the "cpu" component,
+// which declares the data contract.
+type contractedComponent struct {
+	*scriptedComponent
+	extraInfo map[string]string
+}
+
+func (c *contractedComponent) Name() string { return "cpu" }
+
+func (c *contractedComponent) Check() CheckResult {
+	cr := c.scriptedComponent.Check()
+	c.last[0].ExtraInfo = c.extraInfo
+	return cr
+}
+
+func TestContractComponent(t *testing.T) {
+	inner := &contractedComponent{
+		scriptedComponent: &scriptedComponent{
+			script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeHealthy},
+		},
+	}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+	c, err := WithContracts(initFunc)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+
+	// no data yet
+	cr := c.Check()
+	assert.Nil(t, cr.HealthStates()[0].ExtraInfo)
+
+	inner.extraInfo = map[string]string{"data": `{"renamed":1}`}
+	cr = c.Check()
+	require.Len(t, cr.HealthStates(), 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Contains(t, cr.HealthStates()[0].ExtraInfo[contracts.ExtraInfoKeyViolation], `component "cpu" violates the contract`)
+	assert.NotContains(t, cr.HealthStates()[0].ExtraInfo, "data")
+	assert.Contains(t, c.LastHealthStates()[0].ExtraInfo, contracts.ExtraInfoKeyViolation)
+	// the underlying health states are not modified
+	assert.Equal(t, inner.extraInfo, inner.LastHealthStates()[0].ExtraInfo)
+}
+
+func TestContractComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newContractComponent(inner)
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newContractComponent(&scriptedComponent{}).(HealthSettable)
+	assert.False(t, ok)
+}
@@ -1,4 +1,5 @@
-// Package cpu tracks the combined usage of all CPUs (not per-CPU).
+// Package cpu tracks the combined usage of all CPUs (not per-CPU),
+// and the per-package CPU frequency and throttling.
 package cpu
 
 import (
@@ -7,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -30,6 +32,8 @@ const Name = "cpu"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -40,6 +44,11 @@ type component struct {
 	getPrevTimeStatFunc func() *cpu.TimesStat
 	setPrevTimeStatFunc func(cpu.TimesStat)
 
+	getPackageThrottlesFunc func() ([]PackageThrottle, error)
+	throttleTracker         *throttleTracker
+	// number of consecutive throttled checks to report degraded
+	throttleSustainedChecks int
+
 	eventBucket eventstore.Bucket
 	kmsgSyncer  *kmsg.Syncer
 
@@ -60,6 +69,12 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 
 		getPrevTimeStatFunc: getPrevTimeStat,
 		setPrevTimeStatFunc: setPrevTimeStat,
+
+		getPackageThrottlesFunc: func() ([]PackageThrottle, error) {
+			return readPackageThrottles(DefaultSysfsCPUDir)
+		},
+		throttleTracker:         newThrottleTracker(),
+		throttleSustainedChecks: DefaultThrottleSustainedChecks,
 	}
 
 	if gpudInstance.EventStore != nil {
@@ -95,20 +110,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -224,6 +226,32 @@ func (c *component) Check() components.CheckResult {
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = "ok"
 
+	if c.getPackageThrottlesFunc != nil {
+		throttles, err := c.getPackageThrottlesFunc()
+		if err != nil {
+			// best-effort, the sysfs layout varies across platforms
+			log.Logger.Warnw("error reading cpu throttle counters", "error", err)
+		}
+		if c.throttleTracker != nil {
+			c.throttleTracker.observe(throttles)
+		}
+
+		for _, t := range throttles {
+			pkgID := strconv.Itoa(t.PackageID)
+			metricFrequencyCurrentMHz.With(prometheus.Labels{"package": pkgID}).Set(t.CurrentFrequencyMHz)
+			metricFrequencyMaxMHz.With(prometheus.Labels{"package": pkgID}).Set(t.MaxFrequencyMHz)
+			metricThrottleCount.With(prometheus.Labels{"package": pkgID, "reason": "thermal"}).Set(float64(t.ThermalThrottleCount))
+			metricThrottleCount.With(prometheus.Labels{"package": pkgID, "reason": "power_limit"}).Set(float64(t.PowerLimitCount))
+		}
+		cr.Throttles = throttles
+
+		if reason := evaluateThrottles(throttles, c.throttleSustainedChecks); reason != "" {
+			cr.health = apiv1.HealthStateTypeDegraded
+			cr.reason = reason
+			log.Logger.Warnw(cr.reason)
+		}
+	}
+
 	return cr
 }
 
@@ -233,6 +261,9 @@ type checkResult struct {
 	Cores Cores `json:"cores"`
 	Usage Usage `json:"usage"`
 
+	// Throttles reports the frequency and throttle counters per CPU package.
+	Throttles []PackageThrottle `json:"throttles,omitempty"`
+
 	// timestamp of the last check
 	ts time.Time
 	// error from the last check
@@ -283,6 +314,9 @@ func (cr *checkResult) String() string {
 	table.Append([]string{"Avg Load 1-min", cr.Usage.LoadAvg1Min})
 	table.Append([]string{"Avg Load 5-min", cr.Usage.LoadAvg5Min})
 	table.Append([]string{"Avg Load 15-min", cr.Usage.LoadAvg15Min})
+	for _, t := range cr.Throttles {
+		table.Append([]string{fmt.Sprintf("Package %d Frequency", t.PackageID), fmt.Sprintf("%.0f/%.0f MHz", t.CurrentFrequencyMHz, t.MaxFrequencyMHz)})
+	}
 	table.Render()
 
 	return buf.String()
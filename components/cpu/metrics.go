@@ -3,6 +3,7 @@ package cpu
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -34,11 +35,51 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey},
 	).MustCurryWith(componentLabel)
+
+	metricFrequencyCurrentMHz = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "frequency_current_mhz",
+			Help:      "tracks the average current CPU frequency of the package in MHz",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "package"},
+	).MustCurryWith(componentLabel)
+
+	metricFrequencyMaxMHz = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "frequency_max_mhz",
+			Help:      "tracks the maximum CPU frequency of the package in MHz",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "package"},
+	).MustCurryWith(componentLabel)
+
+	metricThrottleCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "throttle_count",
+			Help:      "tracks the number of CPU throttle events of the package since boot",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "package", "reason"}, // reason is "thermal" or "power_limit"
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
 	pkgmetrics.MustRegister(
 		metricLoadAverage,
 		metricUsedPercent,
+		metricFrequencyCurrentMHz,
+		metricFrequencyMaxMHz,
+		metricThrottleCount,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_load_average", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitRatio},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_frequency_current_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_frequency_max_mhz", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMegahertz},
+		apiv1.MetricMetadata{Name: SubSystem + "_throttle_count", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
 	)
 }
@@ -0,0 +1,252 @@
+package cpu
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultSysfsCPUDir is the sysfs directory that exposes per-CPU
+	// frequency (cpufreq) and thermal throttle counters.
+	DefaultSysfsCPUDir = "/sys/devices/system/cpu"
+
+	// DefaultThrottleSustainedChecks is the number of consecutive checks
+	// (one per minute by default) in which the throttle counters of a package
+	// must keep increasing before the component reports degraded.
+	// A single throttle burst (e.g., a short turbo excursion) is expected,
+	// but throttling that lasts several minutes slows down the workload.
+	DefaultThrottleSustainedChecks = 5
+)
+
+// PackageThrottle reports the frequency and throttle counters
+// of a single CPU package (socket).
+type PackageThrottle struct {
+	// PackageID is the physical package ID from "topology/physical_package_id".
+	PackageID int `json:"package_id"`
+
+	// CurrentFrequencyMHz is the average current frequency
+	// across all logical CPUs of the package.
+	CurrentFrequencyMHz float64 `json:"current_frequency_mhz"`
+	// MaxFrequencyMHz is the maximum hardware frequency
+	// across all logical CPUs of the package.
+	MaxFrequencyMHz float64 `json:"max_frequency_mhz"`
+
+	// ThermalThrottleCount is the sum of the package-level and core-level
+	// thermal throttle event counters since boot.
+	ThermalThrottleCount uint64 `json:"thermal_throttle_count"`
+	// PowerLimitCount is the sum of the package-level and core-level
+	// power limit event counters since boot.
+	PowerLimitCount uint64 `json:"power_limit_count"`
+
+	// SustainedChecks is the number of consecutive checks
+	// in which the throttle counters increased.
+	SustainedChecks int `json:"sustained_checks"`
+}
+
+// readPackageThrottles reads the per-package frequency and throttle counters
+// from the sysfs CPU directory (e.g., "/sys/devices/system/cpu").
+// It returns no entry if the system does not expose cpufreq or
+// thermal throttle information (e.g., virtual machines).
+func readPackageThrottles(sysfsCPUDir string) ([]PackageThrottle, error) {
+	cpuDirs, err := filepath.Glob(filepath.Join(sysfsCPUDir, "cpu[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	type pkgAgg struct {
+		curFreqSumKHz float64
+		curFreqCnt    int
+		maxFreqKHz    float64
+
+		pkgThermal uint64
+		pkgPower   uint64
+
+		// core-level counters are shared by the hyperthreads of the same core
+		// thus deduplicate by core ID
+		coreThermal map[int]uint64
+		corePower   map[int]uint64
+	}
+	pkgs := make(map[int]*pkgAgg)
+
+	for _, dir := range cpuDirs {
+		pkgID, err := readInt(filepath.Join(dir, "topology", "physical_package_id"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// offline CPUs do not have the topology directory
+				continue
+			}
+			return nil, err
+		}
+		coreID, err := readInt(filepath.Join(dir, "topology", "core_id"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		agg, ok := pkgs[pkgID]
+		if !ok {
+			agg = &pkgAgg{
+				coreThermal: make(map[int]uint64),
+				corePower:   make(map[int]uint64),
+			}
+			pkgs[pkgID] = agg
+		}
+
+		if v, ok, err := readOptionalUint(filepath.Join(dir, "cpufreq", "scaling_cur_freq")); err != nil {
+			return nil, err
+		} else if ok {
+			agg.curFreqSumKHz += float64(v)
+			agg.curFreqCnt++
+		}
+		if v, ok, err := readOptionalUint(filepath.Join(dir, "cpufreq", "cpuinfo_max_freq")); err != nil {
+			return nil, err
+		} else if ok && float64(v) > agg.maxFreqKHz {
+			agg.maxFreqKHz = float64(v)
+		}
+
+		throttleDir := filepath.Join(dir, "thermal_throttle")
+		if v, ok, err := readOptionalUint(filepath.Join(throttleDir, "package_throttle_count")); err != nil {
+			return nil, err
+		} else if ok && v > agg.pkgThermal {
+			agg.pkgThermal = v
+		}
+		if v, ok, err := readOptionalUint(filepath.Join(throttleDir, "package_power_limit_count")); err != nil {
+			return nil, err
+		} else if ok && v > agg.pkgPower {
+			agg.pkgPower = v
+		}
+		if v, ok, err := readOptionalUint(filepath.Join(throttleDir, "core_throttle_count")); err != nil {
+			return nil, err
+		} else if ok && v > agg.coreThermal[coreID] {
+			agg.coreThermal[coreID] = v
+		}
+		if v, ok, err := readOptionalUint(filepath.Join(throttleDir, "core_power_limit_count")); err != nil {
+			return nil, err
+		} else if ok && v > agg.corePower[coreID] {
+			agg.corePower[coreID] = v
+		}
+	}
+
+	rs := make([]PackageThrottle, 0, len(pkgs))
+	for pkgID, agg := range pkgs {
+		if agg.curFreqCnt == 0 && agg.maxFreqKHz == 0 && agg.pkgThermal == 0 && agg.pkgPower == 0 && len(agg.coreThermal) == 0 && len(agg.corePower) == 0 {
+			continue
+		}
+
+		pt := PackageThrottle{
+			PackageID:            pkgID,
+			MaxFrequencyMHz:      agg.maxFreqKHz / 1000,
+			ThermalThrottleCount: agg.pkgThermal,
+			PowerLimitCount:      agg.pkgPower,
+		}
+		if agg.curFreqCnt > 0 {
+			pt.CurrentFrequencyMHz = agg.curFreqSumKHz / float64(agg.curFreqCnt) / 1000
+		}
+		for _, v := range agg.coreThermal {
+			pt.ThermalThrottleCount += v
+		}
+		for _, v := range agg.corePower {
+			pt.PowerLimitCount += v
+		}
+		rs = append(rs, pt)
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].PackageID < rs[j].PackageID
+	})
+	return rs, nil
+}
+
+// throttleTracker tracks the throttle counters across checks
+// to detect sustained throttling.
+type throttleTracker struct {
+	mu        sync.Mutex
+	prev      map[int]PackageThrottle
+	sustained map[int]int
+}
+
+func newThrottleTracker() *throttleTracker {
+	return &throttleTracker{
+		prev:      make(map[int]PackageThrottle),
+		sustained: make(map[int]int),
+	}
+}
+
+// observe updates the sustained check counts of the packages in-place.
+// The first observation of a package only records the baseline,
+// since the kernel counters are cumulative since boot.
+func (t *throttleTracker) observe(cur []PackageThrottle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range cur {
+		pkgID := cur[i].PackageID
+
+		prev, ok := t.prev[pkgID]
+		t.prev[pkgID] = cur[i]
+		if !ok {
+			t.sustained[pkgID] = 0
+			continue
+		}
+
+		// counters only go backwards when the kernel resets them (e.g., CPU hotplug)
+		increased := cur[i].ThermalThrottleCount > prev.ThermalThrottleCount ||
+			cur[i].PowerLimitCount > prev.PowerLimitCount
+		if increased {
+			t.sustained[pkgID]++
+		} else {
+			t.sustained[pkgID] = 0
+		}
+		cur[i].SustainedChecks = t.sustained[pkgID]
+	}
+}
+
+// evaluateThrottles returns the reason for the sustained throttling
+// if any of the packages has been throttled for at least "threshold" consecutive checks.
+// It returns an empty string if no package is throttled for a sustained period.
+func evaluateThrottles(pkgs []PackageThrottle, threshold int) string {
+	if threshold <= 0 {
+		return ""
+	}
+
+	var throttled []string
+	for _, p := range pkgs {
+		if p.SustainedChecks < threshold {
+			continue
+		}
+		throttled = append(throttled, fmt.Sprintf("package %d (%.0f/%.0f MHz)", p.PackageID, p.CurrentFrequencyMHz, p.MaxFrequencyMHz))
+	}
+	if len(throttled) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("cpu throttled for %d consecutive checks: %s", threshold, strings.Join(throttled, ", "))
+}
+
+func readInt(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// readOptionalUint returns false if the file does not exist.
+func readOptionalUint(path string) (uint64, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	return v, true, nil
+}
@@ -0,0 +1,165 @@
+package cpu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func writeSysfsFile(t *testing.T, path string, v uint64) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(strconv.FormatUint(v, 10)+"\n"), 0644))
+}
+
+func writeFakeCPU(t *testing.T, root string, cpuIdx int, pkgID int, coreID int, curKHz uint64, maxKHz uint64, pkgThrottle uint64, coreThrottle uint64, pkgPower uint64) {
+	t.Helper()
+	dir := filepath.Join(root, "cpu"+strconv.Itoa(cpuIdx))
+	writeSysfsFile(t, filepath.Join(dir, "topology", "physical_package_id"), uint64(pkgID))
+	writeSysfsFile(t, filepath.Join(dir, "topology", "core_id"), uint64(coreID))
+	writeSysfsFile(t, filepath.Join(dir, "cpufreq", "scaling_cur_freq"), curKHz)
+	writeSysfsFile(t, filepath.Join(dir, "cpufreq", "cpuinfo_max_freq"), maxKHz)
+	writeSysfsFile(t, filepath.Join(dir, "thermal_throttle", "package_throttle_count"), pkgThrottle)
+	writeSysfsFile(t, filepath.Join(dir, "thermal_throttle", "core_throttle_count"), coreThrottle)
+	writeSysfsFile(t, filepath.Join(dir, "thermal_throttle", "package_power_limit_count"), pkgPower)
+}
+
+func TestReadPackageThrottles(t *testing.T) {
+	root := t.TempDir()
+
+	// package 0: two hyperthreads on core 0, one CPU on core 1
+	writeFakeCPU(t, root, 0, 0, 0, 2000000, 3000000, 10, 3, 1)
+	writeFakeCPU(t, root, 1, 0, 0, 1000000, 3000000, 10, 3, 1)
+	writeFakeCPU(t, root, 2, 0, 1, 3000000, 3000000, 10, 2, 1)
+	// package 1: no throttle counters
+	dir := filepath.Join(root, "cpu3")
+	writeSysfsFile(t, filepath.Join(dir, "topology", "physical_package_id"), 1)
+	writeSysfsFile(t, filepath.Join(dir, "topology", "core_id"), 0)
+	writeSysfsFile(t, filepath.Join(dir, "cpufreq", "scaling_cur_freq"), 2500000)
+	writeSysfsFile(t, filepath.Join(dir, "cpufreq", "cpuinfo_max_freq"), 2600000)
+	// offline CPU without topology
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cpu4"), 0755))
+	// not a CPU directory
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cpufreq"), 0755))
+
+	pkgs, err := readPackageThrottles(root)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+
+	assert.Equal(t, 0, pkgs[0].PackageID)
+	assert.InDelta(t, 2000.0, pkgs[0].CurrentFrequencyMHz, 0.01)
+	assert.InDelta(t, 3000.0, pkgs[0].MaxFrequencyMHz, 0.01)
+	// package counter (10) + core 0 (3) + core 1 (2)
+	assert.Equal(t, uint64(15), pkgs[0].ThermalThrottleCount)
+	assert.Equal(t, uint64(1), pkgs[0].PowerLimitCount)
+
+	assert.Equal(t, 1, pkgs[1].PackageID)
+	assert.InDelta(t, 2500.0, pkgs[1].CurrentFrequencyMHz, 0.01)
+	assert.InDelta(t, 2600.0, pkgs[1].MaxFrequencyMHz, 0.01)
+	assert.Equal(t, uint64(0), pkgs[1].ThermalThrottleCount)
+}
+
+func TestReadPackageThrottlesNotExist(t *testing.T) {
+	pkgs, err := readPackageThrottles(filepath.Join(t.TempDir(), "does-not-exist"))
+	require.NoError(t, err)
+	assert.Empty(t, pkgs)
+}
+
+func TestReadPackageThrottlesInvalid(t *testing.T) {
+	root := t.TempDir()
+	writeFakeCPU(t, root, 0, 0, 0, 2000000, 3000000, 0, 0, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cpu0", "thermal_throttle", "core_throttle_count"), []byte("invalid"), 0644))
+
+	_, err := readPackageThrottles(root)
+	require.Error(t, err)
+}
+
+func TestThrottleTrackerObserve(t *testing.T) {
+	tr := newThrottleTracker()
+
+	// first observation only records the baseline
+	cur := []PackageThrottle{{PackageID: 0, ThermalThrottleCount: 100}}
+	tr.observe(cur)
+	assert.Equal(t, 0, cur[0].SustainedChecks)
+
+	for i := 1; i <= 3; i++ {
+		cur = []PackageThrottle{{PackageID: 0, ThermalThrottleCount: uint64(100 + i)}}
+		tr.observe(cur)
+		assert.Equal(t, i, cur[0].SustainedChecks)
+	}
+
+	// power limit counter increase also counts
+	cur = []PackageThrottle{{PackageID: 0, ThermalThrottleCount: 103, PowerLimitCount: 1}}
+	tr.observe(cur)
+	assert.Equal(t, 4, cur[0].SustainedChecks)
+
+	// no increase resets the count
+	cur = []PackageThrottle{{PackageID: 0, ThermalThrottleCount: 103, PowerLimitCount: 1}}
+	tr.observe(cur)
+	assert.Equal(t, 0, cur[0].SustainedChecks)
+
+	// counter reset is not treated as throttling
+	cur = []PackageThrottle{{PackageID: 0, ThermalThrottleCount: 1}}
+	tr.observe(cur)
+	assert.Equal(t, 0, cur[0].SustainedChecks)
+}
+
+func TestEvaluateThrottles(t *testing.T) {
+	pkgs := []PackageThrottle{
+		{PackageID: 0, CurrentFrequencyMHz: 1200, MaxFrequencyMHz: 3000, SustainedChecks: 5},
+		{PackageID: 1, CurrentFrequencyMHz: 2900, MaxFrequencyMHz: 3000, SustainedChecks: 2},
+	}
+
+	assert.Equal(t, "cpu throttled for 5 consecutive checks: package 0 (1200/3000 MHz)", evaluateThrottles(pkgs, 5))
+	assert.Empty(t, evaluateThrottles(pkgs, 6))
+	assert.Empty(t, evaluateThrottles(pkgs, 0))
+	assert.Empty(t, evaluateThrottles(nil, 5))
+}
+
+func TestComponentCheckSustainedThrottling(t *testing.T) {
+	var thermal uint64
+	c := &component{
+		ctx:    context.Background(),
+		cancel: func() {},
+
+		getTimeStatFunc: func(ctx context.Context) (cpu.TimesStat, error) {
+			return cpu.TimesStat{}, nil
+		},
+		getUsedPctFunc: func(ctx context.Context) (float64, error) {
+			return 10, nil
+		},
+		getLoadAvgStatFunc: func(ctx context.Context) (*load.AvgStat, error) {
+			return &load.AvgStat{}, nil
+		},
+		getPackageThrottlesFunc: func() ([]PackageThrottle, error) {
+			thermal += 10
+			return []PackageThrottle{{PackageID: 0, CurrentFrequencyMHz: 1200, MaxFrequencyMHz: 3000, ThermalThrottleCount: thermal}}, nil
+		},
+		throttleTracker:         newThrottleTracker(),
+		throttleSustainedChecks: 3,
+	}
+
+	// baseline + 2 increases are not yet sustained
+	for i := 0; i < 3; i++ {
+		rs := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, rs.HealthStateType())
+	}
+
+	rs := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, rs.HealthStateType())
+	assert.Equal(t, "cpu throttled for 3 consecutive checks: package 0 (1200/3000 MHz)", rs.Summary())
+
+	cr, ok := rs.(*checkResult)
+	require.True(t, ok)
+	require.Len(t, cr.Throttles, 1)
+	assert.Equal(t, 3, cr.Throttles[0].SustainedChecks)
+}
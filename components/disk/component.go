@@ -1,4 +1,5 @@
-// Package disk tracks the disk usage of all the mount points specified in the configuration.
+// Package disk tracks the disk usage of all the mount points specified in the configuration,
+// and forecasts the time until full from the recent growth of the used bytes.
 package disk
 
 import (
@@ -41,6 +42,8 @@ type kmsgSyncerCloser interface {
 }
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -74,6 +77,18 @@ type component struct {
 	// Default is 10% (matches Kubernetes default).
 	freeSpaceThresholdPercent float64
 
+	// forecaster estimates the time until full of the tracked filesystems
+	// and the GPUd database files from their recent growth, nil to disable.
+	forecaster *usageForecaster
+	// forecastWarningPeriod is the forecasted time until full
+	// below which the system is considered degraded.
+	// Default is 7 days.
+	forecastWarningPeriod time.Duration
+
+	dbFiles           []string
+	getDBFileSizeFunc func(file string) (uint64, error)
+	getUsageFunc      func(ctx context.Context, path string) (*disk.Usage, error)
+
 	rebootEventStore pkghost.RebootEventStore
 	eventBucket      eventstore.Bucket
 	kmsgSyncer       kmsgSyncerCloser
@@ -159,6 +174,13 @@ func newComponent(
 		freeSpaceThresholdBytesDegraded: defaultFreeSpaceThresholdBytesDegraded,
 		freeSpaceThresholdPercent:       defaultFreeSpaceThresholdPercent,
 
+		forecaster:            newUsageForecaster(),
+		forecastWarningPeriod: defaultForecastWarningPeriod,
+
+		dbFiles:           gpudInstance.DBFiles,
+		getDBFileSizeFunc: getDBFileSize,
+		getUsageFunc:      disk.GetUsage,
+
 		nfsStatTimeoutCounts: make(map[string]int),
 	}
 
@@ -224,20 +246,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -699,6 +708,19 @@ func (c *component) Check() components.CheckResult {
 		cr.reason += strings.Join(degradedPartitionsDueToThresholdExceeded, "; ")
 	}
 
+	if forecastReasons := c.forecastUsages(cr); len(forecastReasons) > 0 {
+		if cr.health == apiv1.HealthStateTypeHealthy {
+			cr.health = apiv1.HealthStateTypeDegraded
+		}
+		if cr.reason == "ok" {
+			cr.reason = ""
+		}
+		if cr.reason != "" {
+			cr.reason += "; "
+		}
+		cr.reason += strings.Join(forecastReasons, "; ")
+	}
+
 	var statTimeoutMounts []string
 	for _, p := range cr.NFSPartitions {
 		consecutive := c.recordNFSStatTimeout(p.MountPoint, p.StatTimedOut)
@@ -903,6 +925,10 @@ type checkResult struct {
 
 	MountTargetUsages map[string]disk.FindMntOutput `json:"mount_target_usages"`
 
+	// Forecasts are the estimated time until full of the tracked filesystems
+	// and the GPUd database files, once enough samples are collected.
+	Forecasts []Forecast `json:"forecasts,omitempty"`
+
 	// timestamp of the last check
 	ts time.Time
 	// error from the last check
@@ -0,0 +1,234 @@
+package disk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// defaultForecastWindow is the lookback of the used bytes to estimate the growth rate.
+	defaultForecastWindow = 24 * time.Hour
+	// defaultForecastMinSpan is the minimum span of the samples before forecasting,
+	// to not extrapolate from the short bursts (e.g., right after the restart).
+	defaultForecastMinSpan = time.Hour
+	// defaultForecastSampleInterval is the minimum interval between the samples,
+	// to bound the number of the samples within the window.
+	defaultForecastSampleInterval = 5 * time.Minute
+
+	// defaultForecastWarningPeriod is the forecasted time until full
+	// below which the component is degraded, ahead of the exhaustion.
+	defaultForecastWarningPeriod = 7 * 24 * time.Hour
+)
+
+const (
+	// ForecastKindFilesystem is the forecast of a tracked mount point.
+	ForecastKindFilesystem = "filesystem"
+	// ForecastKindDatabase is the forecast of a GPUd database file filling its filesystem.
+	ForecastKindDatabase = "database"
+)
+
+// Forecast is the estimated time until a filesystem is full
+// from the recent growth rate of the used bytes.
+type Forecast struct {
+	// Kind is either "filesystem" or "database".
+	Kind string `json:"kind"`
+	// Path is the mount point of the filesystem,
+	// or the database file (which fills its filesystem).
+	Path string `json:"path"`
+
+	// UsedBytes is the used bytes of the filesystem, or the size of the database file.
+	UsedBytes uint64 `json:"used_bytes"`
+	// FreeBytes is the free bytes of the filesystem (holding the database file).
+	FreeBytes uint64 `json:"free_bytes"`
+
+	// GrowthBytesPerDay is the growth rate of the used bytes, estimated over the window.
+	GrowthBytesPerDay float64 `json:"growth_bytes_per_day"`
+	// DaysUntilFull is the estimated days until the filesystem is full,
+	// only set when the used bytes are growing.
+	DaysUntilFull *float64 `json:"days_until_full,omitempty"`
+}
+
+type usageSample struct {
+	ts   time.Time
+	used float64
+}
+
+// usageForecaster estimates the growth rate of the used bytes
+// with the least squares over the recent samples.
+// Safe for concurrent use.
+type usageForecaster struct {
+	window         time.Duration
+	minSpan        time.Duration
+	sampleInterval time.Duration
+
+	mu      sync.Mutex
+	samples map[string][]usageSample
+}
+
+func newUsageForecaster() *usageForecaster {
+	return &usageForecaster{
+		window:         defaultForecastWindow,
+		minSpan:        defaultForecastMinSpan,
+		sampleInterval: defaultForecastSampleInterval,
+		samples:        make(map[string][]usageSample),
+	}
+}
+
+// observe records the used bytes, and returns the growth rate in bytes per day,
+// or false if not enough samples yet.
+func (f *usageForecaster) observe(key string, ts time.Time, used uint64) (float64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	samples := f.samples[key]
+	if len(samples) == 0 || ts.Sub(samples[len(samples)-1].ts) >= f.sampleInterval {
+		samples = append(samples, usageSample{ts: ts, used: float64(used)})
+	} else {
+		// keeps the latest usage without growing the samples
+		samples[len(samples)-1].used = float64(used)
+	}
+
+	cutoff := ts.Add(-f.window)
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].ts.Before(cutoff) })
+	samples = samples[i:]
+	f.samples[key] = samples
+
+	if len(samples) < 2 || samples[len(samples)-1].ts.Sub(samples[0].ts) < f.minSpan {
+		return 0, false
+	}
+
+	// least squares slope of the used bytes over the days since the first sample
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.ts.Sub(samples[0].ts).Hours() / 24
+		sumX += x
+		sumY += s.used
+		sumXY += x * s.used
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denom, true
+}
+
+// newForecast returns the forecast of the filesystem with the free bytes
+// filled by the growth rate, with the days until full set only if growing.
+func newForecast(kind string, path string, used uint64, free uint64, growthBytesPerDay float64) Forecast {
+	fc := Forecast{
+		Kind:              kind,
+		Path:              path,
+		UsedBytes:         used,
+		FreeBytes:         free,
+		GrowthBytesPerDay: growthBytesPerDay,
+	}
+	if growthBytesPerDay > 0 {
+		days := float64(free) / growthBytesPerDay
+		fc.DaysUntilFull = &days
+	}
+	return fc
+}
+
+// forecastUsages updates the forecasts of the tracked filesystems and the GPUd database files,
+// and returns the reasons for the ones forecasted to be full within the warning period.
+func (c *component) forecastUsages(cr *checkResult) []string {
+	if c.forecaster == nil {
+		return nil
+	}
+
+	for _, p := range cr.ExtPartitions {
+		if p.Usage == nil || p.Usage.TotalBytes == 0 {
+			continue
+		}
+		if _, ok := c.mountPointsToTrackUsage[p.MountPoint]; !ok {
+			continue
+		}
+		growth, ok := c.forecaster.observe(ForecastKindFilesystem+":"+p.MountPoint, cr.ts, p.Usage.UsedBytes)
+		if !ok {
+			continue
+		}
+		cr.Forecasts = append(cr.Forecasts, newForecast(ForecastKindFilesystem, p.MountPoint, p.Usage.UsedBytes, p.Usage.FreeBytes, growth))
+	}
+
+	for _, file := range c.dbFiles {
+		size, err := c.getDBFileSizeFunc(file)
+		if err != nil {
+			log.Logger.Warnw("failed to get database file size", "file", file, "error", err)
+			continue
+		}
+		metricDBBytes.With(prometheus.Labels{"file": file}).Set(float64(size))
+
+		growth, ok := c.forecaster.observe(ForecastKindDatabase+":"+file, cr.ts, size)
+		if !ok {
+			continue
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+		usage, err := c.getUsageFunc(timeoutCtx, filepath.Dir(file))
+		cancel()
+		if err != nil {
+			log.Logger.Warnw("failed to get filesystem usage of database file", "file", file, "error", err)
+			continue
+		}
+		cr.Forecasts = append(cr.Forecasts, newForecast(ForecastKindDatabase, file, size, usage.FreeBytes, growth))
+	}
+
+	var reasons []string
+	for _, fc := range cr.Forecasts {
+		labels := prometheus.Labels{"kind": fc.Kind, "path": fc.Path}
+		metricGrowthBytesPerSecond.With(labels).Set(fc.GrowthBytesPerDay / (24 * time.Hour).Seconds())
+		if fc.DaysUntilFull == nil {
+			// not growing, never full
+			metricUntilFullSeconds.Delete(labels)
+			continue
+		}
+		metricUntilFullSeconds.With(labels).Set(*fc.DaysUntilFull * (24 * time.Hour).Seconds())
+
+		if *fc.DaysUntilFull >= c.forecastWarningPeriod.Hours()/24 {
+			continue
+		}
+		reason := fmt.Sprintf("%s %s forecasted to be full in %s (growing %s/day, %s free)",
+			fc.Kind,
+			fc.Path,
+			humanizeDays(*fc.DaysUntilFull),
+			humanize.IBytes(uint64(fc.GrowthBytesPerDay)),
+			humanize.IBytes(fc.FreeBytes))
+		log.Logger.Warnw(reason)
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+func humanizeDays(days float64) string {
+	if days < 1 {
+		return fmt.Sprintf("%.0f hours", math.Ceil(days*24))
+	}
+	return fmt.Sprintf("%.1f days", days)
+}
+
+// getDBFileSize returns the size of the SQLite database file
+// including its write-ahead log, which grows between the checkpoints.
+func getDBFileSize(file string) (uint64, error) {
+	st, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	size := uint64(st.Size())
+	if wal, err := os.Stat(file + "-wal"); err == nil {
+		size += uint64(wal.Size())
+	}
+	return size, nil
+}
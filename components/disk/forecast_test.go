@@ -0,0 +1,144 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/disk"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func TestUsageForecasterObserve(t *testing.T) {
+	f := newUsageForecaster()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// not enough span yet
+	_, ok := f.observe("/", now, 10*gib)
+	assert.False(t, ok)
+	_, ok = f.observe("/", now.Add(30*time.Minute), 10*gib+gib/48)
+	assert.False(t, ok)
+
+	// 1 GiB per day
+	var growth float64
+	for i := 2; i <= 12; i++ {
+		growth, ok = f.observe("/", now.Add(time.Duration(i)*30*time.Minute), 10*gib+uint64(i)*gib/48)
+	}
+	require.True(t, ok)
+	assert.InDelta(t, float64(gib), growth, float64(gib)/100)
+
+	// the samples within the sample interval are coalesced
+	_, _ = f.observe("/", now.Add(6*time.Hour+time.Minute), 10*gib+12*gib/48)
+	assert.Len(t, f.samples["/"], 13)
+
+	// the samples out of the window are pruned, and the shrinking usage is not growing
+	_, ok = f.observe("/", now.Add(40*time.Hour), 5*gib)
+	assert.False(t, ok)
+	assert.Len(t, f.samples["/"], 1)
+	growth, ok = f.observe("/", now.Add(42*time.Hour), 3*gib)
+	require.True(t, ok)
+	assert.Negative(t, growth)
+
+	// the keys are tracked separately
+	_, ok = f.observe("other", now.Add(33*time.Hour), gib)
+	assert.False(t, ok)
+}
+
+func TestNewForecast(t *testing.T) {
+	fc := newForecast(ForecastKindFilesystem, "/", 10*gib, 5*gib, float64(2*gib))
+	require.NotNil(t, fc.DaysUntilFull)
+	assert.InDelta(t, 2.5, *fc.DaysUntilFull, 0.001)
+
+	fc = newForecast(ForecastKindFilesystem, "/", 10*gib, 5*gib, 0)
+	assert.Nil(t, fc.DaysUntilFull)
+	fc = newForecast(ForecastKindFilesystem, "/", 10*gib, 5*gib, -1)
+	assert.Nil(t, fc.DaysUntilFull)
+}
+
+func TestForecastUsages(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "gpud.state")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var dbSize uint64
+	c := &component{
+		ctx:                     context.Background(),
+		mountPointsToTrackUsage: map[string]struct{}{"/": {}},
+		forecaster:              newUsageForecaster(),
+		forecastWarningPeriod:   defaultForecastWarningPeriod,
+		dbFiles:                 []string{dbFile, "/missing.state"},
+		getDBFileSizeFunc: func(file string) (uint64, error) {
+			if file != dbFile {
+				return 0, errors.New("not found")
+			}
+			return dbSize, nil
+		},
+		getUsageFunc: func(ctx context.Context, path string) (*disk.Usage, error) {
+			assert.Equal(t, filepath.Dir(dbFile), path)
+			return &disk.Usage{TotalBytes: 100 * gib, FreeBytes: 50 * gib}, nil
+		},
+	}
+
+	var reasons []string
+	var cr *checkResult
+	for i := 0; i <= 4; i++ {
+		cr = &checkResult{
+			ts: now.Add(time.Duration(i) * 30 * time.Minute),
+			ExtPartitions: disk.Partitions{
+				// 4 GiB per day with 10 GiB free on the root, not growing on the other mount point
+				{MountPoint: "/", Usage: &disk.Usage{TotalBytes: 100 * gib, FreeBytes: 10*gib - uint64(i)*gib/12, UsedBytes: 90*gib + uint64(i)*gib/12}},
+				{MountPoint: "/data", Usage: &disk.Usage{TotalBytes: 100 * gib, FreeBytes: gib, UsedBytes: 99 * gib}},
+			},
+		}
+		// slow database growth of 48 MiB per day
+		dbSize = uint64(i) * 1024 * 1024
+		reasons = c.forecastUsages(cr)
+	}
+
+	require.Len(t, cr.Forecasts, 2)
+	assert.Equal(t, ForecastKindFilesystem, cr.Forecasts[0].Kind)
+	assert.Equal(t, "/", cr.Forecasts[0].Path)
+	require.NotNil(t, cr.Forecasts[0].DaysUntilFull)
+	assert.InDelta(t, 2.5, *cr.Forecasts[0].DaysUntilFull, 0.1)
+
+	assert.Equal(t, ForecastKindDatabase, cr.Forecasts[1].Kind)
+	assert.Equal(t, dbFile, cr.Forecasts[1].Path)
+	assert.Equal(t, uint64(4*1024*1024), cr.Forecasts[1].UsedBytes)
+	require.NotNil(t, cr.Forecasts[1].DaysUntilFull)
+	assert.Greater(t, *cr.Forecasts[1].DaysUntilFull, 7.0)
+
+	// only the root is forecasted to be full within the warning period
+	require.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "filesystem / forecasted to be full in 2.")
+
+	// disabled
+	c.forecaster = nil
+	assert.Nil(t, c.forecastUsages(&checkResult{ts: now}))
+}
+
+func TestGetDBFileSize(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gpud.state")
+	_, err := getDBFileSize(file)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(file, make([]byte, 100), 0o600))
+	size, err := getDBFileSize(file)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), size)
+
+	require.NoError(t, os.WriteFile(file+"-wal", make([]byte, 50), 0o600))
+	size, err = getDBFileSize(file)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150), size)
+}
+
+func TestHumanizeDays(t *testing.T) {
+	assert.Equal(t, "3 hours", humanizeDays(0.1))
+	assert.Equal(t, "2.5 days", humanizeDays(2.5))
+}
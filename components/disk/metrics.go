@@ -3,6 +3,7 @@ package disk
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -43,6 +44,36 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey, "mount_point"}, // label is the mount point
 	).MustCurryWith(componentLabel)
+
+	metricGrowthBytesPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "growth_bytes_per_second",
+			Help:      "tracks the growth rate of the used bytes of the filesystem (or the gpud database file) over the last day",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "kind", "path"}, // label is "filesystem" or "database", and the mount point or the database file
+	).MustCurryWith(componentLabel)
+
+	metricUntilFullSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "until_full_seconds",
+			Help:      "tracks the forecasted time until the filesystem (or the filesystem of the gpud database file) is full, only set when growing",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "kind", "path"}, // label is "filesystem" or "database", and the mount point or the database file
+	).MustCurryWith(componentLabel)
+
+	metricDBBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "gpud_db_bytes",
+			Help:      "tracks the size of the gpud database file including its write-ahead log",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "file"}, // label is the database file
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
@@ -50,5 +81,16 @@ func init() {
 		metricTotalBytes,
 		metricFreeBytes,
 		metricUsedBytes,
+		metricGrowthBytesPerSecond,
+		metricUntilFullSeconds,
+		metricDBBytes,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_total_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_free_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_growth_bytes_per_second", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytesPerSecond},
+		apiv1.MetricMetadata{Name: SubSystem + "_until_full_seconds", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitSeconds},
+		apiv1.MetricMetadata{Name: SubSystem + "_gpud_db_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
 	)
 }
@@ -13,6 +13,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	pkgdocker "github.com/leptonai/gpud/pkg/docker"
 )
 
@@ -1206,3 +1207,7 @@ func TestCheckErrorHandlingWithSuccessfulScenario(t *testing.T) {
 	assert.Equal(t, "ok", cr.reason)
 	assert.Len(t, cr.Containers, 2)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
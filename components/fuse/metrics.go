@@ -3,6 +3,7 @@ package fuse
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -40,4 +41,8 @@ func init() {
 		metricConnsCongestedPct,
 		metricConnsMaxBackgroundPct,
 	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_connections_congested_percent_against_threshold", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_connections_max_background_percent_against_threshold", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	)
 }
@@ -0,0 +1,258 @@
+package components
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// HysteresisExtraInfoKey is the health state extra info key that describes
+// why the reported health state differs from the latest check result.
+const HysteresisExtraInfoKey = "hysteresis"
+
+// HysteresisConfig configures the health state debouncing of a component.
+// A zero value reports every check result as is.
+type HysteresisConfig struct {
+	// FailureThreshold is the number of consecutive failing (unhealthy or degraded)
+	// checks required before reporting the failure.
+	// Zero or one reports the failure immediately.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+	// RecoveryThreshold is the number of consecutive passing checks
+	// required before reporting the recovery from a reported failure.
+	// Zero or one reports the recovery immediately.
+	RecoveryThreshold int `json:"recovery_threshold,omitempty"`
+}
+
+// Validate returns an error if the thresholds are invalid.
+func (cfg HysteresisConfig) Validate() error {
+	if cfg.FailureThreshold < 0 {
+		return fmt.Errorf("failure_threshold must be non-negative, got %d", cfg.FailureThreshold)
+	}
+	if cfg.RecoveryThreshold < 0 {
+		return fmt.Errorf("recovery_threshold must be non-negative, got %d", cfg.RecoveryThreshold)
+	}
+	return nil
+}
+
+// Enabled returns true if the config debounces any health state transition.
+func (cfg HysteresisConfig) Enabled() bool {
+	return cfg.FailureThreshold > 1 || cfg.RecoveryThreshold > 1
+}
+
+// WithHysteresis wraps the initialization function so that the initialized component
+// reports its health states with the hysteresis applied.
+// It returns the original initialization function if the config is not enabled.
+//
+// Only the checks are counted (e.g., the periodic checks run with [CheckLoop]),
+// not the reads of the last health states.
+//
+// Setting the wrapped component healthy also resets the hysteresis counters.
+func WithHysteresis(initFunc InitFunc, cfg HysteresisConfig) InitFunc {
+	if !cfg.Enabled() {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newHysteresisComponent(c, cfg), nil
+	}
+}
+
+func newHysteresisComponent(c Component, cfg HysteresisConfig) Component {
+	hc := &hysteresisComponent{
+		Component: c,
+		debouncer: &hysteresisDebouncer{cfg: cfg},
+	}
+	return wrapComponent(hc, c, hc.debouncer.reset)
+}
+
+var _ Component = &hysteresisComponent{}
+
+// hysteresisComponent wraps a component to debounce its health states.
+type hysteresisComponent struct {
+	Component
+	debouncer *hysteresisDebouncer
+}
+
+func (c *hysteresisComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+
+	states, overridden := c.debouncer.observe(cr.HealthStates())
+	if !overridden {
+		return cr
+	}
+	return wrapCheckResult(&hysteresisCheckResult{CheckResult: cr, states: states}, cr)
+}
+
+func (c *hysteresisComponent) LastHealthStates() apiv1.HealthStates {
+	return c.debouncer.view(c.Component.LastHealthStates())
+}
+
+var _ CheckResult = &hysteresisCheckResult{}
+
+// hysteresisCheckResult overrides the health states of the underlying check result.
+type hysteresisCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *hysteresisCheckResult) HealthStateType() apiv1.HealthStateType {
+	return WorstHealthStateType(cr.states)
+}
+
+func (cr *hysteresisCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
+
+// hysteresisDebouncer tracks the consecutive failing and passing checks.
+type hysteresisDebouncer struct {
+	cfg HysteresisConfig
+
+	mu sync.Mutex
+
+	// lastFailing is true if the last observed check was failing
+	lastFailing bool
+	// overridden is true if the health states of the last observed check were overridden
+	overridden bool
+
+	// last reported failing health states
+	// nil if the currently reported health is passing
+	reportedFailure apiv1.HealthStates
+
+	consecutiveFailures int
+	consecutivePasses   int
+}
+
+// observe counts the health states of a check and returns the health states to report,
+// and true if the returned health states differ from the observed ones.
+func (d *hysteresisDebouncer) observe(states apiv1.HealthStates) (apiv1.HealthStates, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	failing := isFailingHealthStateType(WorstHealthStateType(states))
+	if failing {
+		d.consecutiveFailures++
+		d.consecutivePasses = 0
+	} else {
+		d.consecutivePasses++
+		d.consecutiveFailures = 0
+	}
+	d.lastFailing = failing
+
+	switch {
+	case failing && d.reportedFailure == nil && d.consecutiveFailures < d.cfg.FailureThreshold:
+		// not yet failed for long enough, keep reporting healthy
+		d.overridden = true
+
+	case !failing && d.reportedFailure != nil && d.consecutivePasses < d.cfg.RecoveryThreshold:
+		// not yet recovered for long enough, keep reporting the last failure
+		d.overridden = true
+
+	default:
+		if failing {
+			d.reportedFailure = states
+		} else {
+			d.reportedFailure = nil
+		}
+		d.overridden = false
+		return states, false
+	}
+	return d.override(states), true
+}
+
+// view returns the health states to report for the last health states of the component,
+// without counting them as a check. The override of the last observed check applies
+// while the health states are failing (or passing) the same as the last observed check.
+func (d *hysteresisDebouncer) view(states apiv1.HealthStates) apiv1.HealthStates {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.overridden || isFailingHealthStateType(WorstHealthStateType(states)) != d.lastFailing {
+		return states
+	}
+	return d.override(states)
+}
+
+// override returns the health states overridden by the last observed check.
+// Must be called with the lock held.
+func (d *hysteresisDebouncer) override(states apiv1.HealthStates) apiv1.HealthStates {
+	if d.lastFailing {
+		return overrideHealthStates(states, apiv1.HealthStateTypeHealthy,
+			fmt.Sprintf("suppressed %s (%d of %d consecutive failing checks)", WorstHealthStateType(states), d.consecutiveFailures, d.cfg.FailureThreshold))
+	}
+	return overrideHealthStates(d.reportedFailure, "",
+		fmt.Sprintf("recovering (%d of %d consecutive passing checks)", d.consecutivePasses, d.cfg.RecoveryThreshold))
+}
+
+// reset clears the hysteresis counters, and the next check result is reported as is.
+func (d *hysteresisDebouncer) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lastFailing = false
+	d.overridden = false
+	d.reportedFailure = nil
+	d.consecutiveFailures = 0
+	d.consecutivePasses = 0
+}
+
+// overrideHealthStates returns a copy of the health states with the health overridden
+// (if non-empty) and the hysteresis reason set in the extra info.
+func overrideHealthStates(states apiv1.HealthStates, health apiv1.HealthStateType, reason string) apiv1.HealthStates {
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		if health != "" {
+			s.Health = health
+		}
+
+		extraInfo := make(map[string]string, len(s.ExtraInfo)+1)
+		for k, v := range s.ExtraInfo {
+			extraInfo[k] = v
+		}
+		extraInfo[HysteresisExtraInfoKey] = reason
+		s.ExtraInfo = extraInfo
+
+		copied = append(copied, s)
+	}
+	return copied
+}
+
+func latestHealthStateTime(states apiv1.HealthStates) time.Time {
+	var latest time.Time
+	for _, s := range states {
+		if s.Time.After(latest) {
+			latest = s.Time.Time
+		}
+	}
+	return latest
+}
+
+func isFailingHealthStateType(health apiv1.HealthStateType) bool {
+	return health == apiv1.HealthStateTypeUnhealthy || health == apiv1.HealthStateTypeDegraded
+}
+
+// WorstHealthStateType returns the most severe health state type of the health states.
+// It returns "Healthy" if the health states are empty.
+func WorstHealthStateType(states apiv1.HealthStates) apiv1.HealthStateType {
+	worst := apiv1.HealthStateTypeHealthy
+	for _, s := range states {
+		switch s.Health {
+		case apiv1.HealthStateTypeUnhealthy:
+			return apiv1.HealthStateTypeUnhealthy
+		case apiv1.HealthStateTypeDegraded:
+			worst = apiv1.HealthStateTypeDegraded
+		case apiv1.HealthStateTypeInitializing:
+			if worst == apiv1.HealthStateTypeHealthy {
+				worst = apiv1.HealthStateTypeInitializing
+			}
+		}
+	}
+	return worst
+}
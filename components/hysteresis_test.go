@@ -0,0 +1,223 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// scriptedComponent returns the health states of the next scripted health
+// on every check, and caches the last check result.
+type scriptedComponent struct {
+	mu     sync.Mutex
+	script []apiv1.HealthStateType
+	ts     time.Time
+	last   apiv1.HealthStates
+}
+
+func (s *scriptedComponent) Name() string      { return "scripted" }
+func (s *scriptedComponent) Tags() []string    { return nil }
+func (s *scriptedComponent) IsSupported() bool { return true }
+func (s *scriptedComponent) Start() error      { return nil }
+func (s *scriptedComponent) Close() error      { return nil }
+func (s *scriptedComponent) LastHealthStates() apiv1.HealthStates {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+func (s *scriptedComponent) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (s *scriptedComponent) Check() CheckResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.script[0]
+	s.script = s.script[1:]
+	s.ts = s.ts.Add(time.Minute)
+	s.last = apiv1.HealthStates{{
+		Time:      metav1.NewTime(s.ts),
+		Component: "scripted",
+		Name:      "scripted",
+		Health:    h,
+		Reason:    string(h),
+	}}
+	return &scriptedCheckResult{states: s.last}
+}
+
+type scriptedCheckResult struct {
+	states apiv1.HealthStates
+}
+
+func (r *scriptedCheckResult) ComponentName() string { return "scripted" }
+func (r *scriptedCheckResult) String() string        { return "" }
+func (r *scriptedCheckResult) Summary() string       { return r.states[0].Reason }
+func (r *scriptedCheckResult) HealthStateType() apiv1.HealthStateType {
+	return r.states[0].Health
+}
+func (r *scriptedCheckResult) HealthStates() apiv1.HealthStates { return r.states }
+
+type scriptedHealthSettableComponent struct {
+	*scriptedComponent
+	setHealthyCalled bool
+}
+
+func (s *scriptedHealthSettableComponent) SetHealthy() error {
+	s.setHealthyCalled = true
+	return nil
+}
+
+func TestHysteresisConfig(t *testing.T) {
+	assert.False(t, HysteresisConfig{}.Enabled())
+	assert.False(t, HysteresisConfig{FailureThreshold: 1, RecoveryThreshold: 1}.Enabled())
+	assert.True(t, HysteresisConfig{FailureThreshold: 2}.Enabled())
+	assert.True(t, HysteresisConfig{RecoveryThreshold: 2}.Enabled())
+
+	require.NoError(t, HysteresisConfig{FailureThreshold: 3, RecoveryThreshold: 2}.Validate())
+	require.Error(t, HysteresisConfig{FailureThreshold: -1}.Validate())
+	require.Error(t, HysteresisConfig{RecoveryThreshold: -1}.Validate())
+}
+
+func TestWithHysteresisDisabled(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithHysteresis(initFunc, HysteresisConfig{})(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestWithHysteresisInitError(t *testing.T) {
+	initFunc := func(*GPUdInstance) (Component, error) { return nil, errors.New("init failed") }
+
+	_, err := WithHysteresis(initFunc, HysteresisConfig{FailureThreshold: 3})(&GPUdInstance{RootCtx: context.Background()})
+	require.Error(t, err)
+}
+
+func TestHysteresisComponentCheck(t *testing.T) {
+	u := apiv1.HealthStateTypeUnhealthy
+	h := apiv1.HealthStateTypeHealthy
+	d := apiv1.HealthStateTypeDegraded
+
+	inner := &scriptedComponent{
+		script: []apiv1.HealthStateType{
+			u, h, // transient failure is suppressed
+			u, d, u, // third consecutive failure is reported
+			h, // recovery is held
+			h, // second consecutive pass is reported
+		},
+	}
+	c := newHysteresisComponent(inner, HysteresisConfig{FailureThreshold: 3, RecoveryThreshold: 2})
+
+	expected := []struct {
+		health     apiv1.HealthStateType
+		reason     string
+		hysteresis string
+	}{
+		{h, "Unhealthy", "suppressed Unhealthy (1 of 3 consecutive failing checks)"},
+		{h, "Healthy", ""},
+		{h, "Unhealthy", "suppressed Unhealthy (1 of 3 consecutive failing checks)"},
+		{h, "Degraded", "suppressed Degraded (2 of 3 consecutive failing checks)"},
+		{u, "Unhealthy", ""},
+		{u, "Unhealthy", "recovering (1 of 2 consecutive passing checks)"},
+		{h, "Healthy", ""},
+	}
+	for i, exp := range expected {
+		cr := c.Check()
+		require.NotNil(t, cr, "check %d", i)
+		assert.Equal(t, exp.health, cr.HealthStateType(), "check %d", i)
+
+		states := cr.HealthStates()
+		require.Len(t, states, 1)
+		assert.Equal(t, exp.reason, states[0].Reason, "check %d", i)
+		assert.Equal(t, exp.hysteresis, states[0].ExtraInfo[HysteresisExtraInfoKey], "check %d", i)
+
+		// reading the last health states must not count as a check
+		last := c.LastHealthStates()
+		require.Len(t, last, 1)
+		assert.Equal(t, exp.health, last[0].Health, "check %d", i)
+		assert.Equal(t, exp.hysteresis, last[0].ExtraInfo[HysteresisExtraInfoKey], "check %d", i)
+	}
+}
+
+func TestHysteresisComponentLastHealthStatesNotCounted(t *testing.T) {
+	u := apiv1.HealthStateTypeUnhealthy
+
+	inner := &scriptedComponent{script: []apiv1.HealthStateType{u}}
+	c := newHysteresisComponent(inner, HysteresisConfig{FailureThreshold: 3})
+
+	// no check yet, reported as is
+	assert.Empty(t, c.LastHealthStates())
+
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.Check().HealthStateType())
+
+	// e.g., the health states without the time, read by the API and the session
+	inner.last[0].Time = metav1.Time{}
+	for i := 0; i < 5; i++ {
+		last := c.LastHealthStates()
+		require.Len(t, last, 1)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, last[0].Health, "read %d", i)
+		assert.Equal(t, "suppressed Unhealthy (1 of 3 consecutive failing checks)", last[0].ExtraInfo[HysteresisExtraInfoKey], "read %d", i)
+	}
+
+	// changed without a check (e.g., by an event), reported as is
+	inner.last[0].Health = apiv1.HealthStateTypeHealthy
+	last := c.LastHealthStates()
+	require.Len(t, last, 1)
+	assert.NotContains(t, last[0].ExtraInfo, HysteresisExtraInfoKey)
+}
+
+func TestHysteresisComponentSetHealthy(t *testing.T) {
+	u := apiv1.HealthStateTypeUnhealthy
+	h := apiv1.HealthStateTypeHealthy
+
+	inner := &scriptedHealthSettableComponent{
+		scriptedComponent: &scriptedComponent{script: []apiv1.HealthStateType{u, h, u}},
+	}
+	c := newHysteresisComponent(inner, HysteresisConfig{RecoveryThreshold: 3})
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+
+	assert.Equal(t, u, c.Check().HealthStateType())
+	// recovery is held
+	assert.Equal(t, u, c.Check().HealthStateType())
+
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	// counters are reset, and the next failure is reported immediately
+	assert.Equal(t, u, c.Check().HealthStateType())
+}
+
+func TestHysteresisComponentNotHealthSettable(t *testing.T) {
+	c := newHysteresisComponent(&scriptedComponent{}, HysteresisConfig{FailureThreshold: 2})
+	_, ok := c.(HealthSettable)
+	assert.False(t, ok)
+	require.NoError(t, c.Close())
+}
+
+func TestWorstHealthStateType(t *testing.T) {
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, WorstHealthStateType(nil))
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, WorstHealthStateType(apiv1.HealthStates{
+		{Health: apiv1.HealthStateTypeHealthy},
+		{Health: apiv1.HealthStateTypeDegraded},
+		{Health: apiv1.HealthStateTypeInitializing},
+	}))
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, WorstHealthStateType(apiv1.HealthStates{
+		{Health: apiv1.HealthStateTypeDegraded},
+		{Health: apiv1.HealthStateTypeUnhealthy},
+	}))
+	assert.Equal(t, apiv1.HealthStateTypeInitializing, WorstHealthStateType(apiv1.HealthStates{
+		{Health: apiv1.HealthStateTypeInitializing},
+	}))
+}
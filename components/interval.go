@@ -0,0 +1,185 @@
+package components
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// MinCheckInterval is the shortest check interval allowed by the adaptive interval config,
+// to not busy-loop the checks of a failing component.
+const MinCheckInterval = 5 * time.Second
+
+var metricCheckIntervalSeconds = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "gpud",
+		Subsystem: "component",
+		Name:      "check_interval_seconds",
+		Help:      "current interval between the periodic checks of the component",
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricCheckIntervalSeconds)
+}
+
+// AdaptiveIntervalConfig configures the interval of the periodic checks of a component
+// based on its health. A zero value checks at the fixed default interval of the component.
+type AdaptiveIntervalConfig struct {
+	// MaxInterval is the longest interval between the checks while the component is healthy.
+	// The interval doubles per healthy check from the default interval up to this.
+	// Zero (or not longer than the default interval) does not stretch the interval.
+	MaxInterval metav1.Duration `json:"max_interval,omitempty"`
+	// FailingInterval is the interval between the checks while the component
+	// is degraded or unhealthy, to confirm the failure and detect the recovery sooner.
+	// Zero uses the default interval.
+	FailingInterval metav1.Duration `json:"failing_interval,omitempty"`
+}
+
+// Validate returns an error if the intervals are invalid.
+func (cfg AdaptiveIntervalConfig) Validate() error {
+	if cfg.MaxInterval.Duration < 0 {
+		return fmt.Errorf("max_interval must be non-negative, got %s", cfg.MaxInterval.Duration)
+	}
+	if cfg.FailingInterval.Duration < 0 {
+		return fmt.Errorf("failing_interval must be non-negative, got %s", cfg.FailingInterval.Duration)
+	}
+	if cfg.FailingInterval.Duration > 0 && cfg.FailingInterval.Duration < MinCheckInterval {
+		return fmt.Errorf("failing_interval must be at least %s, got %s", MinCheckInterval, cfg.FailingInterval.Duration)
+	}
+	return nil
+}
+
+// CheckTicker delivers the ticks of the periodic checks of a component,
+// with the interval adapted to the health of the last check.
+// If the check deferrer is set, the ticks are held while the checks
+// of the component are deferred.
+// Not safe for concurrent use.
+type CheckTicker struct {
+	// C delivers the tick once the interval passes since the last observed check.
+	C <-chan time.Time
+
+	componentName string
+	defInterval   time.Duration
+	cfg           AdaptiveIntervalConfig
+
+	timer    *time.Timer
+	interval time.Duration
+	// healthy is true if the last observed check was healthy
+	healthy bool
+
+	// stopc is closed on stop to end relaying the ticks, nil if not relaying
+	stopc    chan struct{}
+	stopOnce sync.Once
+}
+
+// NewCheckTicker creates the check ticker of the component with the default interval,
+// adapted by the config, and deferred by the check deferrer if not nil.
+func NewCheckTicker(componentName string, interval time.Duration, cfg AdaptiveIntervalConfig, deferrer CheckDeferrer) *CheckTicker {
+	t := newCheckTicker(componentName, interval, cfg)
+	if deferrer != nil {
+		t.relayDeferred(deferrer)
+	}
+	return t
+}
+
+func newCheckTicker(componentName string, interval time.Duration, cfg AdaptiveIntervalConfig) *CheckTicker {
+	timer := time.NewTimer(interval)
+	metricCheckIntervalSeconds.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: componentName}).Set(interval.Seconds())
+	t := &CheckTicker{
+		C:             timer.C,
+		componentName: componentName,
+		defInterval:   interval,
+		cfg:           cfg,
+		timer:         timer,
+		interval:      interval,
+	}
+	setSchedule(t, time.Now())
+	return t
+}
+
+// Observe schedules the next tick based on the health of the check result,
+// and returns the interval until the next tick.
+// Must be called after each check, once the previous tick is received (or before the first).
+func (t *CheckTicker) Observe(cr CheckResult) time.Duration {
+	next := t.nextInterval(cr)
+	if next != t.interval {
+		log.Logger.Debugw("check interval changed", "component", t.componentName, "from", t.interval, "to", next)
+		metricCheckIntervalSeconds.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: t.componentName}).Set(next.Seconds())
+	}
+	t.interval = next
+	t.healthy = cr != nil && cr.HealthStateType() == apiv1.HealthStateTypeHealthy
+	t.timer.Reset(next)
+	setSchedule(t, time.Now())
+	return next
+}
+
+func (t *CheckTicker) nextInterval(cr CheckResult) time.Duration {
+	if cr == nil {
+		return t.defInterval
+	}
+
+	switch health := cr.HealthStateType(); {
+	case isFailingHealthStateType(health):
+		if t.cfg.FailingInterval.Duration > 0 {
+			return t.cfg.FailingInterval.Duration
+		}
+		return t.defInterval
+
+	case health == apiv1.HealthStateTypeHealthy:
+		maxInterval := t.cfg.MaxInterval.Duration
+		if maxInterval <= t.defInterval || !t.healthy {
+			// e.g., recovered from a failure, check at the default interval first
+			return t.defInterval
+		}
+		return min(t.interval*2, maxInterval)
+
+	default:
+		return t.defInterval
+	}
+}
+
+// relayDeferred relays the ticks of the timer, held while the checks are deferred.
+func (t *CheckTicker) relayDeferred(deferrer CheckDeferrer) {
+	c := make(chan time.Time, 1)
+	t.C = c
+	t.stopc = make(chan struct{})
+
+	go func() {
+		for {
+			var now time.Time
+			select {
+			case <-t.stopc:
+				return
+			case now = <-t.timer.C:
+			}
+
+			if !deferrer.waitWhileDeferred(t.stopc, t.componentName) {
+				return
+			}
+			select {
+			case c <- now:
+			default:
+			}
+		}
+	}()
+}
+
+// Stop stops the ticker.
+func (t *CheckTicker) Stop() {
+	t.timer.Stop()
+	deleteSchedule(t)
+	if t.stopc != nil {
+		t.stopOnce.Do(func() {
+			close(t.stopc)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func healthCheckResult(h apiv1.HealthStateType) CheckResult {
+	return &scriptedCheckResult{states: apiv1.HealthStates{{Health: h, Reason: string(h)}}}
+}
+
+func TestAdaptiveIntervalConfigValidate(t *testing.T) {
+	require.NoError(t, AdaptiveIntervalConfig{}.Validate())
+	require.NoError(t, AdaptiveIntervalConfig{
+		MaxInterval:     metav1.Duration{Duration: 10 * time.Minute},
+		FailingInterval: metav1.Duration{Duration: 15 * time.Second},
+	}.Validate())
+	require.Error(t, AdaptiveIntervalConfig{MaxInterval: metav1.Duration{Duration: -time.Minute}}.Validate())
+	require.Error(t, AdaptiveIntervalConfig{FailingInterval: metav1.Duration{Duration: -time.Minute}}.Validate())
+	require.Error(t, AdaptiveIntervalConfig{FailingInterval: metav1.Duration{Duration: time.Second}}.Validate())
+}
+
+func TestCheckTickerFixed(t *testing.T) {
+	ticker := newCheckTicker("fixed", time.Minute, AdaptiveIntervalConfig{})
+	defer ticker.Stop()
+
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeUnhealthy)))
+	assert.Equal(t, time.Minute, ticker.Observe(nil))
+}
+
+func TestCheckTickerAdaptive(t *testing.T) {
+	ticker := newCheckTicker("adaptive", time.Minute, AdaptiveIntervalConfig{
+		MaxInterval:     metav1.Duration{Duration: 5 * time.Minute},
+		FailingInterval: metav1.Duration{Duration: 15 * time.Second},
+	})
+	defer ticker.Stop()
+
+	// stretched while healthy, up to the max
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, 2*time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, 4*time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, 5*time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, 5*time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+
+	// tightened once failing
+	assert.Equal(t, 15*time.Second, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeDegraded)))
+	assert.Equal(t, 15*time.Second, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeUnhealthy)))
+
+	// back to the default interval on the recovery, then stretched again
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+	assert.Equal(t, 2*time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeInitializing)))
+	assert.Equal(t, time.Minute, ticker.Observe(healthCheckResult(apiv1.HealthStateTypeHealthy)))
+}
+
+func TestCheckTickerTicks(t *testing.T) {
+	ticker := newCheckTicker("ticks", time.Hour, AdaptiveIntervalConfig{
+		FailingInterval: metav1.Duration{Duration: 10 * time.Millisecond},
+	})
+	defer ticker.Stop()
+
+	ticker.Observe(healthCheckResult(apiv1.HealthStateTypeUnhealthy))
+	select {
+	case <-ticker.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a tick at the failing interval")
+	}
+}
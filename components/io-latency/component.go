@@ -0,0 +1,309 @@
+// Package iolatency probes the IO latency of the data and scratch volumes
+// with small direct reads and writes, to detect the hung or slow file systems
+// (e.g., NFS, Lustre, local NVMe) that stall the GPU jobs.
+package iolatency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Name is the ID of the IO latency component.
+const Name = "io-latency"
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+	getConfigsFunc func() Configs
+	newProberFunc  func(cfg Config) *prober
+	probeInterval  time.Duration
+
+	probersMu sync.Mutex
+	probers   map[string]*prober
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the IO latency component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigsFunc: GetDefaultConfigs,
+		newProberFunc:  newProber,
+		probeInterval:  DefaultProbeInterval,
+		probers:        make(map[string]*prober),
+	}
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"disk",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	go func() {
+		ticker := time.NewTicker(c.probeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			c.probeAll()
+		}
+	}()
+
+	c.CheckLoop.StartAfterInterval(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+// syncProbers returns the probers of the currently configured paths,
+// creating the probers of the new paths and dropping the removed ones.
+func (c *component) syncProbers() []*prober {
+	cfgs := c.getConfigsFunc()
+
+	c.probersMu.Lock()
+	defer c.probersMu.Unlock()
+
+	current := make(map[string]*prober, len(cfgs))
+	probers := make([]*prober, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		key := filepath.Clean(cfg.Path)
+		p, ok := c.probers[key]
+		if !ok || p.cfg != cfg {
+			p = c.newProberFunc(cfg)
+		}
+		current[key] = p
+		probers = append(probers, p)
+	}
+	c.probers = current
+
+	sort.Slice(probers, func(i, j int) bool { return probers[i].cfg.Path < probers[j].cfg.Path })
+	return probers
+}
+
+// probeAll probes all the configured paths in parallel,
+// so that a hung path does not delay the others.
+func (c *component) probeAll() {
+	now := c.getTimeNowFunc()
+
+	var wg sync.WaitGroup
+	for _, p := range c.syncProbers() {
+		wg.Add(1)
+		go func(p *prober) {
+			defer wg.Done()
+			p.probe(c.ctx, now)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking io latency")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	probers := c.syncProbers()
+	if len(probers) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no io latency probe path configured"
+		return cr
+	}
+
+	var problems []string
+	for _, p := range probers {
+		st := p.stats()
+		cr.Paths = append(cr.Paths, st)
+
+		metricErrorRatio.With(prometheus.Labels{"path": st.Path}).Set(st.ErrorRate)
+		for _, l := range []struct {
+			op       string
+			quantile string
+			latency  time.Duration
+		}{
+			{op: "write", quantile: "p50", latency: st.WriteP50.Duration},
+			{op: "write", quantile: "p99", latency: st.WriteP99.Duration},
+			{op: "read", quantile: "p50", latency: st.ReadP50.Duration},
+			{op: "read", quantile: "p99", latency: st.ReadP99.Duration},
+		} {
+			metricLatencySeconds.With(prometheus.Labels{"path": st.Path, "op": l.op, "quantile": l.quantile}).Set(l.latency.Seconds())
+		}
+
+		problems = append(problems, evaluate(p.cfg, st)...)
+	}
+
+	if len(problems) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = "slow or failing io: " + strings.Join(problems, "; ")
+		log.Logger.Warnw(cr.reason)
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("io latency ok on %d path(s)", len(probers))
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Paths []PathStats `json:"paths,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Paths) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Path", "Samples", "Error Rate", "Write p50", "Write p99", "Read p50", "Read p99", "Direct"})
+	for _, st := range cr.Paths {
+		table.Append([]string{
+			st.Path,
+			fmt.Sprintf("%d", st.Samples),
+			fmt.Sprintf("%.0f%%", st.ErrorRate*100),
+			st.WriteP50.Duration.String(),
+			st.WriteP99.Duration.String(),
+			st.ReadP50.Duration.String(),
+			st.ReadP99.Duration.String(),
+			fmt.Sprintf("%v", st.Direct),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.Paths) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
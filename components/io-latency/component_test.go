@@ -0,0 +1,103 @@
+package iolatency
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+)
+
+func TestComponentNoConfig(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+	c, ok := comp.(*component)
+	if !ok {
+		t.Fatal("expected *component")
+	}
+	c.getConfigsFunc = func() Configs { return nil }
+
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "no io latency probe path configured", cr.Summary())
+	assert.Equal(t, "no data", cr.String())
+}
+
+func TestComponentCheck(t *testing.T) {
+	results := map[string]probeResult{
+		"/mnt/data":    {write: 5 * time.Millisecond, read: time.Millisecond, direct: true},
+		"/mnt/scratch": {write: 3 * time.Second, read: time.Millisecond, direct: true},
+	}
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+	c, ok := comp.(*component)
+	if !ok {
+		t.Fatal("expected *component")
+	}
+	c.getConfigsFunc = func() Configs { return Configs{{Path: "/mnt/scratch"}, {Path: "/mnt/data"}} }
+	c.newProberFunc = func(cfg Config) *prober {
+		p := newProber(cfg)
+		p.probeFunc = func(string) probeResult { return results[cfg.Path] }
+		return p
+	}
+
+	// before the first probe, nothing to evaluate
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	c.probeAll()
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "/mnt/scratch write p50 latency 3s exceeds 100ms")
+	assert.Contains(t, cr.Summary(), "/mnt/scratch write p99 latency 3s exceeds 1s")
+	assert.NotContains(t, cr.Summary(), "/mnt/data")
+	assert.Contains(t, cr.String(), "/mnt/scratch")
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, states[0].Health)
+	var data checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &data))
+	require.Len(t, data.Paths, 2)
+	assert.Equal(t, "/mnt/data", data.Paths[0].Path)
+	assert.Equal(t, 5*time.Millisecond, data.Paths[0].WriteP50.Duration)
+
+	// the probers are kept across the checks, and dropped once unconfigured
+	p := c.syncProbers()[0]
+	c.getConfigsFunc = func() Configs { return Configs{{Path: "/mnt/data"}} }
+	probers := c.syncProbers()
+	require.Len(t, probers, 1)
+	assert.Same(t, p, probers[0])
+
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "io latency ok on 1 path(s)", cr.Summary())
+}
+
+func TestCheckResultNil(t *testing.T) {
+	var cr *checkResult
+	assert.Equal(t, "", cr.String())
+	assert.Equal(t, "", cr.Summary())
+	states := cr.HealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,134 @@
+package iolatency
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultProbeFileName is the file name under the probed path
+	// to write to and read from.
+	DefaultProbeFileName = ".gpud-io-latency-probe"
+
+	// DefaultProbeInterval is the interval between the probes of each path.
+	DefaultProbeInterval = 10 * time.Second
+	// DefaultProbeTimeout is how long a probe can take before it is counted
+	// as failed (e.g., hung NFS server).
+	DefaultProbeTimeout = 30 * time.Second
+	// DefaultWindowSize is the number of the most recent probes
+	// to compute the latency percentiles and the error rate from
+	// (5 minutes with the default probe interval).
+	DefaultWindowSize = 30
+
+	// DefaultP50Threshold is the default median latency threshold of a single
+	// 4 KiB read or write, above which the path is degraded.
+	DefaultP50Threshold = 100 * time.Millisecond
+	// DefaultP99Threshold is the default tail latency threshold of a single
+	// 4 KiB read or write, above which the path is degraded.
+	DefaultP99Threshold = time.Second
+	// DefaultMaxErrorRate is the default ratio of the failed probes in the window,
+	// above which the path is degraded.
+	DefaultMaxErrorRate = 0.1
+)
+
+// Config configures the IO latency probe of a path.
+type Config struct {
+	// Path is the directory on the volume to probe (e.g., "/mnt/scratch").
+	// Must be an absolute path, and writable by gpud.
+	Path string `json:"path"`
+
+	// P50Threshold is the median latency threshold.
+	// Defaults to DefaultP50Threshold if zero.
+	P50Threshold metav1.Duration `json:"p50_threshold,omitempty"`
+	// P99Threshold is the tail latency threshold.
+	// Defaults to DefaultP99Threshold if zero.
+	P99Threshold metav1.Duration `json:"p99_threshold,omitempty"`
+	// MaxErrorRate is the ratio of the failed probes in [0, 1].
+	// Defaults to DefaultMaxErrorRate if zero.
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.Path == "" {
+		return fmt.Errorf("path is empty")
+	}
+	if !filepath.IsAbs(cfg.Path) {
+		return fmt.Errorf("path %q must be an absolute path", cfg.Path)
+	}
+	if cfg.P50Threshold.Duration < 0 || cfg.P99Threshold.Duration < 0 {
+		return fmt.Errorf("latency thresholds of %q must be non-negative", cfg.Path)
+	}
+	if cfg.MaxErrorRate < 0 || cfg.MaxErrorRate > 1 {
+		return fmt.Errorf("max_error_rate of %q must be in [0, 1], got %v", cfg.Path, cfg.MaxErrorRate)
+	}
+	return nil
+}
+
+func (cfg Config) p50Threshold() time.Duration {
+	if cfg.P50Threshold.Duration > 0 {
+		return cfg.P50Threshold.Duration
+	}
+	return DefaultP50Threshold
+}
+
+func (cfg Config) p99Threshold() time.Duration {
+	if cfg.P99Threshold.Duration > 0 {
+		return cfg.P99Threshold.Duration
+	}
+	return DefaultP99Threshold
+}
+
+func (cfg Config) maxErrorRate() float64 {
+	if cfg.MaxErrorRate > 0 {
+		return cfg.MaxErrorRate
+	}
+	return DefaultMaxErrorRate
+}
+
+// Configs is a list of the probe configs.
+type Configs []Config
+
+// Validate returns an error if any config is invalid or the paths are duplicate.
+func (cfgs Configs) Validate() error {
+	seen := make(map[string]struct{}, len(cfgs))
+	for _, cfg := range cfgs {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		p := filepath.Clean(cfg.Path)
+		if _, ok := seen[p]; ok {
+			return fmt.Errorf("duplicate path %q", cfg.Path)
+		}
+		seen[p] = struct{}{}
+	}
+	return nil
+}
+
+var (
+	defaultConfigsMu sync.RWMutex
+	defaultConfigs   Configs
+)
+
+// GetDefaultConfigs returns the current default IO latency probe configs.
+func GetDefaultConfigs() Configs {
+	defaultConfigsMu.RLock()
+	defer defaultConfigsMu.RUnlock()
+
+	return defaultConfigs
+}
+
+// SetDefaultConfigs replaces the default IO latency probe configs.
+func SetDefaultConfigs(cfgs Configs) {
+	log.Logger.Infow("setting default io latency probe configs", "count", len(cfgs))
+
+	defaultConfigsMu.Lock()
+	defer defaultConfigsMu.Unlock()
+	defaultConfigs = cfgs
+}
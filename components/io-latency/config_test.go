@@ -0,0 +1,40 @@
+package iolatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.Error(t, Config{}.Validate())
+	assert.Error(t, Config{Path: "scratch"}.Validate())
+	assert.Error(t, Config{Path: "/mnt/scratch", MaxErrorRate: 1.5}.Validate())
+	assert.Error(t, Config{Path: "/mnt/scratch", P99Threshold: metav1.Duration{Duration: -time.Second}}.Validate())
+	assert.NoError(t, Config{Path: "/mnt/scratch"}.Validate())
+
+	assert.NoError(t, Configs{{Path: "/mnt/scratch"}, {Path: "/mnt/data"}}.Validate())
+	assert.Error(t, Configs{{Path: "/mnt/scratch"}, {Path: "/mnt/scratch/"}}.Validate())
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := Config{Path: "/mnt/scratch"}
+	assert.Equal(t, DefaultP50Threshold, cfg.p50Threshold())
+	assert.Equal(t, DefaultP99Threshold, cfg.p99Threshold())
+	assert.InDelta(t, DefaultMaxErrorRate, cfg.maxErrorRate(), 0.0001)
+
+	cfg = Config{Path: "/mnt/scratch", P50Threshold: metav1.Duration{Duration: time.Second}, P99Threshold: metav1.Duration{Duration: 5 * time.Second}, MaxErrorRate: 0.5}
+	assert.Equal(t, time.Second, cfg.p50Threshold())
+	assert.Equal(t, 5*time.Second, cfg.p99Threshold())
+	assert.InDelta(t, 0.5, cfg.maxErrorRate(), 0.0001)
+}
+
+func TestDefaultConfigs(t *testing.T) {
+	prev := GetDefaultConfigs()
+	defer SetDefaultConfigs(prev)
+
+	SetDefaultConfigs(Configs{{Path: "/mnt/scratch"}})
+	assert.Equal(t, Configs{{Path: "/mnt/scratch"}}, GetDefaultConfigs())
+}
@@ -0,0 +1,48 @@
+package iolatency
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem used by the IO latency metrics.
+const SubSystem = "io_latency"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricLatencySeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "probe_latency_seconds",
+			Help:      "tracks the latency percentiles of the 4 KiB direct IO probes in the recent window",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "path", "op", "quantile"}, // label is the probed path, "read" or "write", and "p50" or "p99"
+	).MustCurryWith(componentLabel)
+
+	metricErrorRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "probe_error_ratio",
+			Help:      "tracks the ratio of the failed (including timed out) IO probes in the recent window",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "path"}, // label is the probed path
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricLatencySeconds,
+		metricErrorRatio,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_probe_latency_seconds", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitSeconds},
+		apiv1.MetricMetadata{Name: SubSystem + "_probe_error_ratio", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitRatio},
+	)
+}
@@ -0,0 +1,264 @@
+package iolatency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// probeBlockSize is the size of each probe read and write,
+// aligned to the logical block size as required by O_DIRECT.
+const probeBlockSize = 4096
+
+// errProbeHung is the error recorded when the previous probe has not returned yet.
+var errProbeHung = errors.New("previous probe still hung")
+
+// probeResult is the result of a single write and read probe.
+type probeResult struct {
+	write time.Duration
+	read  time.Duration
+	// direct is false if the file system does not support O_DIRECT (e.g., tmpfs)
+	// thus the probe went through the page cache
+	direct bool
+	err    error
+}
+
+// probeFile writes a block to the file and reads it back, bypassing the page cache if possible.
+func probeFile(file string) probeResult {
+	block := alignedBlock(probeBlockSize)
+	copy(block, fmt.Sprintf("gpud io latency probe %d\n", time.Now().UnixNano()))
+
+	r := probeResult{direct: true}
+	flags := openFlagDirect
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_SYNC|flags, 0o644)
+	if err != nil && flags != 0 && errors.Is(err, syscall.EINVAL) {
+		r.direct = false
+		flags = 0
+		f, err = os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_SYNC, 0o644)
+	}
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	start := time.Now()
+	_, err = f.Write(block)
+	r.write = time.Since(start)
+	cerr := f.Close()
+	if err != nil {
+		r.err = fmt.Errorf("failed to write: %w", err)
+		return r
+	}
+	if cerr != nil {
+		r.err = fmt.Errorf("failed to close: %w", cerr)
+		return r
+	}
+
+	f, err = os.OpenFile(file, os.O_RDONLY|flags, 0)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	read := alignedBlock(probeBlockSize)
+	start = time.Now()
+	n, err := f.Read(read)
+	r.read = time.Since(start)
+	if err != nil {
+		r.err = fmt.Errorf("failed to read: %w", err)
+		return r
+	}
+	if !bytes.Equal(read[:n], block) {
+		r.err = errors.New("read back contents do not match the written contents")
+	}
+	return r
+}
+
+// alignedBlock returns a zeroed buffer whose address is aligned to its size.
+func alignedBlock(size int) []byte {
+	b := make([]byte, 2*size)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&b[0])) & uintptr(size-1)); rem != 0 {
+		offset = size - rem
+	}
+	return b[offset : offset+size]
+}
+
+// sample is a probe result in the window.
+type sample struct {
+	ts     time.Time
+	write  time.Duration
+	read   time.Duration
+	direct bool
+	err    error
+}
+
+// prober probes a path periodically, and keeps the most recent probe results.
+type prober struct {
+	cfg        Config
+	probeFunc  func(file string) probeResult
+	timeout    time.Duration
+	windowSize int
+
+	mu      sync.Mutex
+	samples []sample
+	// start time of the probe not returned yet, zero if none
+	inFlightSince time.Time
+}
+
+func newProber(cfg Config) *prober {
+	return &prober{
+		cfg:        cfg,
+		probeFunc:  probeFile,
+		timeout:    DefaultProbeTimeout,
+		windowSize: DefaultWindowSize,
+	}
+}
+
+// probe probes the path once, and records the result.
+// A probe that does not return within the timeout is recorded as failed,
+// and no other probe is issued until it returns, to not pile up the blocked
+// goroutines on a hung file system.
+func (p *prober) probe(ctx context.Context, now time.Time) {
+	p.mu.Lock()
+	if !p.inFlightSince.IsZero() {
+		p.recordLocked(sample{ts: now, err: fmt.Errorf("%w for %s", errProbeHung, now.Sub(p.inFlightSince).Truncate(time.Second))})
+		p.mu.Unlock()
+		return
+	}
+	p.inFlightSince = now
+	p.mu.Unlock()
+
+	done := make(chan probeResult, 1)
+	go func() {
+		r := p.probeFunc(filepath.Join(p.cfg.Path, DefaultProbeFileName))
+
+		p.mu.Lock()
+		p.inFlightSince = time.Time{}
+		p.mu.Unlock()
+
+		done <- r
+	}()
+
+	s := sample{ts: now}
+	select {
+	case r := <-done:
+		s.write, s.read, s.direct, s.err = r.write, r.read, r.direct, r.err
+	case <-time.After(p.timeout):
+		s.err = fmt.Errorf("probe timed out after %s", p.timeout)
+	case <-ctx.Done():
+		return
+	}
+
+	p.mu.Lock()
+	p.recordLocked(s)
+	p.mu.Unlock()
+}
+
+func (p *prober) recordLocked(s sample) {
+	p.samples = append(p.samples, s)
+	if len(p.samples) > p.windowSize {
+		p.samples = p.samples[len(p.samples)-p.windowSize:]
+	}
+}
+
+// PathStats is the latency and error statistics of the recent probes of a path.
+type PathStats struct {
+	Path string `json:"path"`
+	// Samples is the number of the probes in the window.
+	Samples int `json:"samples"`
+	// Errors is the number of the failed probes in the window.
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	LastError string  `json:"last_error,omitempty"`
+	// Direct is true if the latest successful probe bypassed the page cache.
+	Direct bool `json:"direct"`
+
+	WriteP50 metav1.Duration `json:"write_p50"`
+	WriteP99 metav1.Duration `json:"write_p99"`
+	ReadP50  metav1.Duration `json:"read_p50"`
+	ReadP99  metav1.Duration `json:"read_p99"`
+}
+
+// stats returns the statistics of the probes in the window.
+func (p *prober) stats() PathStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st := PathStats{Path: p.cfg.Path, Samples: len(p.samples)}
+	writes := make([]time.Duration, 0, len(p.samples))
+	reads := make([]time.Duration, 0, len(p.samples))
+	for _, s := range p.samples {
+		if s.err != nil {
+			st.Errors++
+			st.LastError = s.err.Error()
+			continue
+		}
+		st.Direct = s.direct
+		writes = append(writes, s.write)
+		reads = append(reads, s.read)
+	}
+	if st.Samples > 0 {
+		st.ErrorRate = float64(st.Errors) / float64(st.Samples)
+	}
+	st.WriteP50.Duration, st.WriteP99.Duration = percentile(writes, 0.5), percentile(writes, 0.99)
+	st.ReadP50.Duration, st.ReadP99.Duration = percentile(reads, 0.5), percentile(reads, 0.99)
+	return st
+}
+
+// percentile returns the nearest-rank percentile of the durations, or zero if empty.
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// evaluate returns the problems of the path, empty if healthy.
+func evaluate(cfg Config, st PathStats) []string {
+	if st.Samples == 0 {
+		return nil
+	}
+
+	var problems []string
+	if st.ErrorRate > cfg.maxErrorRate() {
+		problems = append(problems, fmt.Sprintf("%s probe error rate %.0f%% exceeds %.0f%% (%s)", st.Path, st.ErrorRate*100, cfg.maxErrorRate()*100, st.LastError))
+	}
+	for _, l := range []struct {
+		op        string
+		quantile  string
+		latency   time.Duration
+		threshold time.Duration
+	}{
+		{op: "write", quantile: "p50", latency: st.WriteP50.Duration, threshold: cfg.p50Threshold()},
+		{op: "write", quantile: "p99", latency: st.WriteP99.Duration, threshold: cfg.p99Threshold()},
+		{op: "read", quantile: "p50", latency: st.ReadP50.Duration, threshold: cfg.p50Threshold()},
+		{op: "read", quantile: "p99", latency: st.ReadP99.Duration, threshold: cfg.p99Threshold()},
+	} {
+		if l.latency > l.threshold {
+			problems = append(problems, fmt.Sprintf("%s %s %s latency %s exceeds %s", st.Path, l.op, l.quantile, l.latency, l.threshold))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,7 @@
+package iolatency
+
+import "golang.org/x/sys/unix"
+
+// openFlagDirect bypasses the page cache, so the probes measure the storage
+// (or the NFS server) rather than the memory.
+const openFlagDirect = unix.O_DIRECT
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package iolatency
+
+// openFlagDirect is not supported, the probes go through the page cache.
+const openFlagDirect = 0
@@ -0,0 +1,154 @@
+package iolatency
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProbeFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), DefaultProbeFileName)
+
+	r := probeFile(file)
+	require.NoError(t, r.err)
+	assert.Positive(t, r.write)
+	assert.Positive(t, r.read)
+
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Len(t, b, probeBlockSize)
+
+	r = probeFile(filepath.Join(t.TempDir(), "nonexistent", DefaultProbeFileName))
+	assert.Error(t, r.err)
+}
+
+func TestAlignedBlock(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		b := alignedBlock(probeBlockSize)
+		assert.Len(t, b, probeBlockSize)
+		assert.Zero(t, uintptr(unsafe.Pointer(&b[0]))%probeBlockSize)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Zero(t, percentile(nil, 0.5))
+
+	ds := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(ds, 0.5))
+	assert.Equal(t, 99*time.Millisecond, percentile(ds, 0.99))
+	assert.Equal(t, 100*time.Millisecond, percentile(ds, 1))
+	// not sorted in place
+	assert.Equal(t, 100*time.Millisecond, ds[0])
+
+	assert.Equal(t, 3*time.Millisecond, percentile([]time.Duration{3 * time.Millisecond}, 0.99))
+}
+
+func TestProberStats(t *testing.T) {
+	results := []probeResult{
+		{write: 10 * time.Millisecond, read: time.Millisecond, direct: true},
+		{write: 20 * time.Millisecond, read: 2 * time.Millisecond, direct: true},
+		{err: errors.New("input/output error")},
+	}
+	p := newProber(Config{Path: "/mnt/scratch"})
+	p.windowSize = 2
+	p.probeFunc = func(file string) probeResult {
+		assert.Equal(t, filepath.Join("/mnt/scratch", DefaultProbeFileName), file)
+		r := results[0]
+		results = results[1:]
+		return r
+	}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		p.probe(context.Background(), now)
+	}
+
+	// the window only keeps the last two probes
+	st := p.stats()
+	assert.Equal(t, 2, st.Samples)
+	assert.Equal(t, 1, st.Errors)
+	assert.InDelta(t, 0.5, st.ErrorRate, 0.001)
+	assert.Equal(t, "input/output error", st.LastError)
+	assert.True(t, st.Direct)
+	assert.Equal(t, 20*time.Millisecond, st.WriteP50.Duration)
+	assert.Equal(t, 2*time.Millisecond, st.ReadP99.Duration)
+}
+
+func TestProberHung(t *testing.T) {
+	release := make(chan struct{})
+	p := newProber(Config{Path: "/mnt/nfs"})
+	p.timeout = 10 * time.Millisecond
+	p.probeFunc = func(string) probeResult {
+		<-release
+		return probeResult{write: time.Millisecond, read: time.Millisecond}
+	}
+
+	now := time.Now()
+	p.probe(context.Background(), now)
+	st := p.stats()
+	require.Equal(t, 1, st.Errors)
+	assert.Contains(t, st.LastError, "timed out")
+
+	// no new probe is issued while the previous one is hung
+	p.probe(context.Background(), now.Add(10*time.Second))
+	st = p.stats()
+	require.Equal(t, 2, st.Errors)
+	assert.Contains(t, st.LastError, "still hung for 10s")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.inFlightSince.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p.timeout = 5 * time.Second
+	p.probe(context.Background(), now.Add(20*time.Second))
+	st = p.stats()
+	assert.Equal(t, 3, st.Samples)
+	assert.Equal(t, 2, st.Errors)
+}
+
+func TestEvaluate(t *testing.T) {
+	cfg := Config{Path: "/mnt/scratch"}
+	assert.Empty(t, evaluate(cfg, PathStats{Path: "/mnt/scratch"}))
+
+	healthy := PathStats{
+		Path:     "/mnt/scratch",
+		Samples:  30,
+		WriteP50: metav1.Duration{Duration: 5 * time.Millisecond},
+		WriteP99: metav1.Duration{Duration: 50 * time.Millisecond},
+		ReadP50:  metav1.Duration{Duration: time.Millisecond},
+		ReadP99:  metav1.Duration{Duration: 10 * time.Millisecond},
+	}
+	assert.Empty(t, evaluate(cfg, healthy))
+
+	slow := healthy
+	slow.WriteP99 = metav1.Duration{Duration: 2 * time.Second}
+	problems := evaluate(cfg, slow)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "write p99 latency 2s exceeds 1s")
+
+	// custom thresholds
+	cfg.P99Threshold = metav1.Duration{Duration: 5 * time.Second}
+	assert.Empty(t, evaluate(cfg, slow))
+
+	failing := healthy
+	failing.Errors = 6
+	failing.ErrorRate = 0.2
+	failing.LastError = "probe timed out after 30s"
+	problems = evaluate(cfg, failing)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "error rate 20% exceeds 10%")
+}
@@ -0,0 +1,223 @@
+package kernelcounters
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// labelPath is the file path label set for every sample.
+const labelPath = "path"
+
+// maxFileSize caps the bytes read from each file,
+// as the procfs files report the zero size.
+const maxFileSize = 1024 * 1024
+
+// Sample is a metric value read from a file.
+type Sample struct {
+	Name   string            `json:"name"`
+	Path   string            `json:"path"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// ReadError is a file failed to read or parse.
+type ReadError struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// collect reads the files of the metric spec, and returns the samples and the per-file errors.
+func collect(spec *compiledSpec, maxFiles int, globFunc func(string) ([]string, error), readFileFunc func(string) ([]byte, error)) ([]Sample, []ReadError) {
+	paths, err := globFunc(spec.Path)
+	if err != nil {
+		return nil, []ReadError{{Name: spec.Name, Path: spec.Path, Error: err.Error()}}
+	}
+	if len(paths) == 0 {
+		return nil, []ReadError{{Name: spec.Name, Path: spec.Path, Error: "no file matched"}}
+	}
+	if len(paths) > maxFiles {
+		paths = paths[:maxFiles]
+	}
+
+	var samples []Sample
+	var errs []ReadError
+	for _, path := range paths {
+		b, err := readFileFunc(path)
+		if err != nil {
+			errs = append(errs, ReadError{Name: spec.Name, Path: path, Error: err.Error()})
+			continue
+		}
+		ss, err := spec.parseSamples(path, string(b))
+		if err != nil {
+			errs = append(errs, ReadError{Name: spec.Name, Path: path, Error: err.Error()})
+			continue
+		}
+		samples = append(samples, ss...)
+	}
+	return samples, errs
+}
+
+// parseSamples parses the file content into the samples,
+// with every label name of the spec set (empty if not matched).
+func (spec *compiledSpec) parseSamples(path string, content string) ([]Sample, error) {
+	labels := make(map[string]string, len(spec.labelNames))
+	for _, name := range spec.labelNames {
+		labels[name] = ""
+	}
+	for k, v := range spec.Labels {
+		labels[k] = v
+	}
+	if spec.pathRegex != nil {
+		m := spec.pathRegex.FindStringSubmatch(path)
+		if m == nil {
+			return nil, fmt.Errorf("path does not match %q", spec.PathRegex)
+		}
+		for i, name := range spec.pathRegex.SubexpNames() {
+			if name != "" {
+				labels[name] = m[i]
+			}
+		}
+	}
+
+	switch spec.parse() {
+	case ParseInt:
+		s := strings.TrimSpace(content)
+		v, err := strconv.ParseInt(s, 0, 64)
+		if err != nil {
+			// e.g., the counters larger than int64 max
+			u, uerr := strconv.ParseUint(s, 0, 64)
+			if uerr != nil {
+				return nil, fmt.Errorf("failed to parse %q as int: %w", s, err)
+			}
+			return []Sample{{Name: spec.Name, Path: path, Labels: labels, Value: float64(u)}}, nil
+		}
+		return []Sample{{Name: spec.Name, Path: path, Labels: labels, Value: float64(v)}}, nil
+
+	case ParseFloat:
+		s := strings.TrimSpace(content)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as float: %w", s, err)
+		}
+		return []Sample{{Name: spec.Name, Path: path, Labels: labels, Value: v}}, nil
+
+	case ParseRegex:
+		matches := spec.valueRegex.FindAllStringSubmatch(content, -1)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("content does not match %q", spec.Regex)
+		}
+		samples := make([]Sample, 0, len(matches))
+		for _, m := range matches {
+			v, err := strconv.ParseFloat(strings.TrimSpace(m[spec.valueGroup]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %q as float: %w", m[spec.valueGroup], err)
+			}
+			ls := make(map[string]string, len(labels))
+			for k, lv := range labels {
+				ls[k] = lv
+			}
+			for i, name := range spec.valueRegex.SubexpNames() {
+				if name != "" && i != spec.valueGroup {
+					ls[name] = m[i]
+				}
+			}
+			samples = append(samples, Sample{Name: spec.Name, Path: path, Labels: ls, Value: v})
+		}
+		return samples, nil
+	}
+	return nil, fmt.Errorf("unknown parse %q", spec.Parse)
+}
+
+// readFile reads up to maxFileSize bytes of the file.
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return io.ReadAll(io.LimitReader(f, maxFileSize))
+}
+
+var _ prometheus.Collector = &collector{}
+
+// collector exports the last collected samples as the gauges,
+// where the metric names are only known from the config.
+// It describes no metric thus is an unchecked collector.
+type collector struct {
+	mu      sync.RWMutex
+	specs   map[string]*compiledSpec
+	samples []Sample
+}
+
+func newCollector() *collector {
+	return &collector{}
+}
+
+func (c *collector) set(specs map[string]*compiledSpec, samples []Sample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.specs = specs
+	c.samples = samples
+}
+
+func (c *collector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	descs := make(map[string]*prometheus.Desc, len(c.specs))
+	seen := make(map[string]bool, len(c.samples))
+	for _, s := range c.samples {
+		spec, ok := c.specs[s.Name]
+		if !ok {
+			continue
+		}
+		desc, ok := descs[s.Name]
+		if !ok {
+			help := spec.Help
+			if help == "" {
+				help = fmt.Sprintf("kernel counter read from %s", spec.Path)
+			}
+			desc = prometheus.NewDesc(
+				spec.fqName,
+				help,
+				append([]string{pkgmetrics.MetricComponentLabelKey, labelPath}, spec.labelNames...),
+				nil,
+			)
+			descs[s.Name] = desc
+		}
+
+		values := make([]string, 0, 2+len(spec.labelNames))
+		values = append(values, Name, s.Path)
+		for _, name := range spec.labelNames {
+			values = append(values, s.Labels[name])
+		}
+
+		// the duplicate or the invalid metric fails the whole gather,
+		// thus skipped (e.g., the same regex match twice, non-UTF-8 label values)
+		key := s.Name + "\xff" + strings.Join(values, "\xff")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, s.Value, values...)
+		if err != nil {
+			log.Logger.Debugw("skipping invalid kernel counter metric", "name", s.Name, "path", s.Path, "error", err)
+			continue
+		}
+		ch <- m
+	}
+}
@@ -0,0 +1,264 @@
+// Package kernelcounters collects the arbitrary sysfs and procfs counters
+// as the metrics, configured with the file paths (or globs) and the parse rules,
+// for the one-off counters that do not merit a dedicated component.
+// Opt-in, as there is no metric to collect without the config.
+package kernelcounters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Name is the ID of the kernel counters component.
+const Name = "kernel-counters"
+
+// maxReasonErrors is the maximum number of the read errors in the health state reason,
+// where the rest are in the extra info.
+const maxReasonErrors = 3
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	getTimeNowFunc func() time.Time
+	getConfigFunc  func() Config
+	globFunc       func(pattern string) ([]string, error)
+	readFileFunc   func(path string) ([]byte, error)
+
+	collector *collector
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the kernel counters component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	return &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: GetDefaultConfig,
+		globFunc:      filepath.Glob,
+		readFileFunc:  readFile,
+
+		collector: defaultCollector,
+	}, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"kernel",
+		Name,
+	}
+}
+
+// IsSupported returns true only if any metric is configured.
+func (c *component) IsSupported() bool {
+	return len(c.getConfigFunc().Metrics) > 0
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking kernel counters")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cfg := c.getConfigFunc()
+	if len(cfg.Metrics) == 0 {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no kernel counter configured"
+		return cr
+	}
+
+	specs := make(map[string]*compiledSpec, len(cfg.Metrics))
+	for _, spec := range cfg.Metrics {
+		cs, err := spec.compile()
+		if err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeDegraded
+			cr.reason = "invalid kernel counters config"
+			return cr
+		}
+		specs[spec.Name] = cs
+
+		samples, errs := collect(cs, cfg.maxFilesPerMetric(), c.globFunc, c.readFileFunc)
+		cr.Samples = append(cr.Samples, samples...)
+		cr.Errors = append(cr.Errors, errs...)
+	}
+	c.collector.set(specs, cr.Samples)
+
+	if len(cr.Errors) > 0 {
+		issues := make([]string, 0, maxReasonErrors)
+		for _, e := range cr.Errors {
+			if len(issues) == maxReasonErrors {
+				issues = append(issues, "...")
+				break
+			}
+			issues = append(issues, fmt.Sprintf("%s: %s (%s)", e.Name, e.Path, e.Error))
+		}
+		log.Logger.Warnw("failed to read kernel counters", "errors", len(cr.Errors))
+
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = fmt.Sprintf("collected %d sample(s), failed to read %d file(s): %s", len(cr.Samples), len(cr.Errors), strings.Join(issues, "; "))
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("collected %d sample(s) of %d metric(s)", len(cr.Samples), len(cfg.Metrics))
+	return cr
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Samples []Sample    `json:"samples,omitempty"`
+	Errors  []ReadError `json:"errors,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Samples) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Metric", "Path", "Labels", "Value"})
+	for _, s := range cr.Samples {
+		labels := make([]string, 0, len(s.Labels))
+		for k, v := range s.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		table.Append([]string{
+			SubSystem + "_" + s.Name,
+			s.Path,
+			strings.Join(labels, ","),
+			fmt.Sprintf("%g", s.Value),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.Samples) > 0 || len(cr.Errors) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
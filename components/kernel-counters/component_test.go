@@ -0,0 +1,181 @@
+package kernelcounters
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestComponentBasics(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+	c, ok := comp.(*component)
+	require.True(t, ok)
+
+	c.getConfigFunc = func() Config { return Config{} }
+	c.collector = newCollector()
+
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+	assert.False(t, c.IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+
+	evs, err := c.Events(context.Background(), c.getTimeNowFunc())
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "no kernel counter configured", cr.Summary())
+
+	c.getConfigFunc = func() Config { return Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x"}}} }
+	assert.True(t, c.IsSupported())
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "infiniband", "mlx5_0", "ports", "1", "counters", "port_rcv_data"), "100\n")
+	writeFile(t, filepath.Join(dir, "infiniband", "mlx5_1", "ports", "1", "counters", "port_rcv_data"), "200\n")
+	writeFile(t, filepath.Join(dir, "vmstat"), "nr_free_pages 1\npgfault 10\npgmajfault 2\n")
+
+	cfg := Config{Metrics: []MetricSpec{
+		{
+			Name:      "ib_port_rcv_data",
+			Help:      "ib port received data",
+			Path:      filepath.Join(dir, "infiniband", "*", "ports", "*", "counters", "port_rcv_data"),
+			PathRegex: `/infiniband/(?P<device>[^/]+)/ports/(?P<port>[0-9]+)/`,
+			Parse:     ParseInt,
+		},
+		{
+			Name:  "vmstat",
+			Path:  filepath.Join(dir, "vmstat"),
+			Parse: ParseRegex,
+			Regex: `(?m)^(?P<counter>pgfault|pgmajfault) (?P<value>[0-9]+)$`,
+		},
+	}}
+	require.NoError(t, cfg.Validate())
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+	c, ok := comp.(*component)
+	require.True(t, ok)
+
+	c.getConfigFunc = func() Config { return cfg }
+	c.collector = newCollector()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "collected 4 sample(s) of 2 metric(s)", cr.Summary())
+	assert.Contains(t, cr.String(), "kernel_counters_ib_port_rcv_data")
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c.collector))
+	expected := `
+# HELP kernel_counters_ib_port_rcv_data ib port received data
+# TYPE kernel_counters_ib_port_rcv_data gauge
+kernel_counters_ib_port_rcv_data{device="mlx5_0",gpud_component="kernel-counters",path="DIR/infiniband/mlx5_0/ports/1/counters/port_rcv_data",port="1"} 100
+kernel_counters_ib_port_rcv_data{device="mlx5_1",gpud_component="kernel-counters",path="DIR/infiniband/mlx5_1/ports/1/counters/port_rcv_data",port="1"} 200
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(strings.ReplaceAll(expected, "DIR", dir)), "kernel_counters_ib_port_rcv_data"))
+	assert.Equal(t, 4, testutil.CollectAndCount(c.collector))
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Len(t, decoded.Samples, 4)
+
+	// the removed file is reported, and the rest still collected
+	require.NoError(t, os.Remove(filepath.Join(dir, "vmstat")))
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "failed to read 1 file(s)")
+	assert.Contains(t, cr.Summary(), "no file matched")
+	assert.Equal(t, 2, testutil.CollectAndCount(c.collector))
+}
+
+func TestCheckReadErrors(t *testing.T) {
+	dir := t.TempDir()
+	for i, content := range []string{"1", "x", "y", "z", "w", "6"} {
+		writeFile(t, filepath.Join(dir, "c"+string(rune('0'+i))), content)
+	}
+
+	cfg := Config{
+		Metrics:           []MetricSpec{{Name: "c", Path: filepath.Join(dir, "c*")}},
+		MaxFilesPerMetric: 5,
+	}
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+	c, ok := comp.(*component)
+	require.True(t, ok)
+
+	c.getConfigFunc = func() Config { return cfg }
+	c.collector = newCollector()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	// the 6th file is over the limit
+	assert.Len(t, cr.Samples, 1)
+	assert.Len(t, cr.Errors, 4)
+	assert.Contains(t, cr.Summary(), "failed to parse")
+	assert.True(t, strings.HasSuffix(cr.Summary(), "..."), cr.Summary())
+}
+
+func TestCheckInvalidConfig(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+	c, ok := comp.(*component)
+	require.True(t, ok)
+
+	c.getConfigFunc = func() Config { return Config{Metrics: []MetricSpec{{Name: "a-b", Path: "/proc/x"}}} }
+	c.collector = newCollector()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "invalid kernel counters config", cr.Summary())
+}
+
+func TestCollectorSkipsDuplicates(t *testing.T) {
+	spec, err := MetricSpec{Name: "a", Path: "/x", Parse: ParseRegex, Regex: `(?m)^(?P<k>\w+) (?P<value>[0-9]+)$`}.compile()
+	require.NoError(t, err)
+	ss, err := spec.parseSamples("/x", "a 1\na 2\nb 3\n")
+	require.NoError(t, err)
+	require.Len(t, ss, 3)
+
+	col := newCollector()
+	col.set(map[string]*compiledSpec{"a": spec}, ss)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(col))
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	assert.Len(t, mfs[0].GetMetric(), 2)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,231 @@
+package kernelcounters
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// Parse is how the file content is parsed into the metric value.
+type Parse string
+
+const (
+	// ParseInt parses the whole trimmed content as an integer,
+	// with the base prefix if any (e.g., "0x1f").
+	ParseInt Parse = "int"
+	// ParseFloat parses the whole trimmed content as a float.
+	ParseFloat Parse = "float"
+	// ParseRegex parses the values captured by the regex, where every match is a sample.
+	// The value is the capture group named "value" (or the first group if none),
+	// and the other named capture groups are the labels (e.g., "/proc/vmstat" lines).
+	ParseRegex Parse = "regex"
+)
+
+// DefaultMaxFilesPerMetric is the default maximum number of the files
+// matched by a path glob, to not blow up the metric cardinality.
+const DefaultMaxFilesPerMetric = 256
+
+// Config configures the kernel counters to collect as the metrics.
+type Config struct {
+	Metrics []MetricSpec `json:"metrics"`
+	// MaxFilesPerMetric is the maximum number of the files read for each metric,
+	// where the rest of the glob matches are skipped.
+	// Defaults to DefaultMaxFilesPerMetric if zero.
+	MaxFilesPerMetric int `json:"max_files_per_metric,omitempty"`
+}
+
+// MetricSpec is a metric collected from the sysfs or procfs files.
+type MetricSpec struct {
+	// Name is the metric name, exported with the "kernel_counters_" prefix.
+	// e.g., "ib_port_rcv_data" is exported as "kernel_counters_ib_port_rcv_data".
+	Name string `json:"name"`
+	// Help is the metric description.
+	Help string `json:"help,omitempty"`
+	// Path is the file path, or the glob pattern to read multiple files.
+	// e.g., "/sys/class/infiniband/*/ports/*/counters/port_rcv_data"
+	Path string `json:"path"`
+	// PathRegex is matched against each file path, where the named capture groups are the labels.
+	// e.g., "/sys/class/infiniband/(?P<device>[^/]+)/ports/(?P<port>[0-9]+)/"
+	PathRegex string `json:"path_regex,omitempty"`
+	// Parse is how the content is parsed. Defaults to ParseFloat if empty.
+	Parse Parse `json:"parse,omitempty"`
+	// Regex is required for ParseRegex.
+	// e.g., "(?m)^(?P<counter>pgfault|pgmajfault) (?P<value>[0-9]+)$"
+	Regex string `json:"regex,omitempty"`
+	// Labels are the static labels added to every sample.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+var (
+	metricNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelNameRegex  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// reservedLabels are set by the component for every sample.
+var reservedLabels = map[string]bool{
+	pkgmetrics.MetricComponentLabelKey: true,
+	labelPath:                          true,
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.MaxFilesPerMetric < 0 {
+		return fmt.Errorf("max_files_per_metric must be non-negative, got %d", cfg.MaxFilesPerMetric)
+	}
+	names := make(map[string]bool, len(cfg.Metrics))
+	for _, spec := range cfg.Metrics {
+		if _, err := spec.compile(); err != nil {
+			return err
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("duplicate metric name %q", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+	return nil
+}
+
+func (cfg Config) maxFilesPerMetric() int {
+	if cfg.MaxFilesPerMetric > 0 {
+		return cfg.MaxFilesPerMetric
+	}
+	return DefaultMaxFilesPerMetric
+}
+
+// compiledSpec is the metric spec with the compiled regexes and the label names,
+// where the label names are the same for every sample of the metric.
+type compiledSpec struct {
+	MetricSpec
+
+	fqName     string
+	pathRegex  *regexp.Regexp
+	valueRegex *regexp.Regexp
+	valueGroup int
+
+	// labelNames are the sorted static, path regex, and value regex label names
+	labelNames []string
+}
+
+func (spec MetricSpec) compile() (*compiledSpec, error) {
+	if !metricNameRegex.MatchString(spec.Name) {
+		return nil, fmt.Errorf("invalid metric name %q", spec.Name)
+	}
+	if spec.Path == "" {
+		return nil, fmt.Errorf("metric %q: empty path", spec.Name)
+	}
+	if _, err := filepath.Match(spec.Path, ""); err != nil {
+		return nil, fmt.Errorf("metric %q: invalid path glob %q: %w", spec.Name, spec.Path, err)
+	}
+
+	cs := &compiledSpec{
+		MetricSpec: spec,
+		fqName:     SubSystem + "_" + spec.Name,
+	}
+
+	labels := make(map[string]bool)
+	addLabel := func(name string) error {
+		if !labelNameRegex.MatchString(name) {
+			return fmt.Errorf("metric %q: invalid label name %q", spec.Name, name)
+		}
+		if reservedLabels[name] {
+			return fmt.Errorf("metric %q: reserved label name %q", spec.Name, name)
+		}
+		if labels[name] {
+			return fmt.Errorf("metric %q: duplicate label name %q", spec.Name, name)
+		}
+		labels[name] = true
+		cs.labelNames = append(cs.labelNames, name)
+		return nil
+	}
+	for k := range spec.Labels {
+		if err := addLabel(k); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.PathRegex != "" {
+		var err error
+		cs.pathRegex, err = regexp.Compile(spec.PathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("metric %q: invalid path regex: %w", spec.Name, err)
+		}
+		for _, name := range cs.pathRegex.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			if err := addLabel(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch spec.parse() {
+	case ParseInt, ParseFloat:
+		if spec.Regex != "" {
+			return nil, fmt.Errorf("metric %q: regex set for %q parse", spec.Name, spec.parse())
+		}
+	case ParseRegex:
+		if spec.Regex == "" {
+			return nil, fmt.Errorf("metric %q: empty regex", spec.Name)
+		}
+		var err error
+		cs.valueRegex, err = regexp.Compile(spec.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("metric %q: invalid regex: %w", spec.Name, err)
+		}
+		if cs.valueRegex.NumSubexp() == 0 {
+			return nil, fmt.Errorf("metric %q: regex has no capture group", spec.Name)
+		}
+		cs.valueGroup = 1
+		if idx := cs.valueRegex.SubexpIndex("value"); idx > 0 {
+			cs.valueGroup = idx
+		}
+		for i, name := range cs.valueRegex.SubexpNames() {
+			if name == "" || i == cs.valueGroup {
+				continue
+			}
+			if err := addLabel(name); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("metric %q: unknown parse %q", spec.Name, spec.Parse)
+	}
+
+	sort.Strings(cs.labelNames)
+	return cs, nil
+}
+
+func (spec MetricSpec) parse() Parse {
+	if spec.Parse == "" {
+		return ParseFloat
+	}
+	return spec.Parse
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default kernel counters config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default kernel counters config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default kernel counters config", "metrics", len(cfg.Metrics))
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
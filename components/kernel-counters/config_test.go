@@ -0,0 +1,98 @@
+package kernelcounters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Metrics: []MetricSpec{
+		{Name: "rcv_data", Path: "/sys/class/infiniband/*/ports/*/counters/port_rcv_data", PathRegex: `/infiniband/(?P<device>[^/]+)/ports/(?P<port>[0-9]+)/`, Parse: ParseInt},
+		{Name: "vmstat", Path: "/proc/vmstat", Parse: ParseRegex, Regex: `(?m)^(?P<counter>pgfault|pgmajfault) (?P<value>[0-9]+)$`, Labels: map[string]string{"source": "vmstat"}},
+	}}.Validate())
+
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{"negative max files", Config{MaxFilesPerMetric: -1}, "max_files_per_metric"},
+		{"invalid name", Config{Metrics: []MetricSpec{{Name: "a-b", Path: "/proc/x"}}}, "invalid metric name"},
+		{"empty path", Config{Metrics: []MetricSpec{{Name: "a"}}}, "empty path"},
+		{"invalid glob", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/["}}}, "invalid path glob"},
+		{"duplicate name", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x"}, {Name: "a", Path: "/proc/y"}}}, "duplicate metric name"},
+		{"unknown parse", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Parse: "hex"}}}, "unknown parse"},
+		{"regex without regex parse", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Regex: "(.*)"}}}, "regex set"},
+		{"empty regex", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Parse: ParseRegex}}}, "empty regex"},
+		{"no capture group", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Parse: ParseRegex, Regex: "[0-9]+"}}}, "no capture group"},
+		{"invalid path regex", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", PathRegex: "("}}}, "invalid path regex"},
+		{"reserved label", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Labels: map[string]string{"path": "x"}}}}, "reserved label"},
+		{"duplicate label", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", PathRegex: "(?P<dev>.*)", Labels: map[string]string{"dev": "x"}}}}, "duplicate label"},
+		{"invalid label", Config{Metrics: []MetricSpec{{Name: "a", Path: "/proc/x", Labels: map[string]string{"a-b": "x"}}}}, "invalid label name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	assert.Equal(t, DefaultMaxFilesPerMetric, Config{}.maxFilesPerMetric())
+	assert.Equal(t, 3, Config{MaxFilesPerMetric: 3}.maxFilesPerMetric())
+}
+
+func TestParseSamples(t *testing.T) {
+	spec, err := MetricSpec{Name: "a", Path: "/x", Parse: ParseInt}.compile()
+	require.NoError(t, err)
+	ss, err := spec.parseSamples("/x", " 0x1f\n")
+	require.NoError(t, err)
+	require.Len(t, ss, 1)
+	assert.Equal(t, float64(31), ss[0].Value)
+
+	// larger than int64 max
+	ss, err = spec.parseSamples("/x", "18446744073709551615\n")
+	require.NoError(t, err)
+	assert.Equal(t, float64(18446744073709551615), ss[0].Value)
+
+	_, err = spec.parseSamples("/x", "1.5")
+	assert.Error(t, err)
+
+	spec, err = MetricSpec{Name: "a", Path: "/x"}.compile()
+	require.NoError(t, err)
+	ss, err = spec.parseSamples("/x", "1.5\n")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, ss[0].Value)
+
+	// the first capture group is the value if no "value" group
+	spec, err = MetricSpec{Name: "a", Path: "/x", Parse: ParseRegex, Regex: `temp: ([0-9.]+)`}.compile()
+	require.NoError(t, err)
+	ss, err = spec.parseSamples("/x", "temp: 42.5 C")
+	require.NoError(t, err)
+	assert.Equal(t, 42.5, ss[0].Value)
+	_, err = spec.parseSamples("/x", "none")
+	assert.Error(t, err)
+
+	spec, err = MetricSpec{
+		Name:      "a",
+		Path:      "/x/*/y",
+		PathRegex: `/x/(?P<dev>[^/]+)/`,
+		Parse:     ParseRegex,
+		Regex:     `(?m)^(?P<counter>\w+) (?P<value>[0-9]+)$`,
+		Labels:    map[string]string{"team": "infra"},
+	}.compile()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"counter", "dev", "team"}, spec.labelNames)
+	ss, err = spec.parseSamples("/x/mlx5_0/y", "pgfault 10\npgmajfault 2\n")
+	require.NoError(t, err)
+	require.Len(t, ss, 2)
+	assert.Equal(t, map[string]string{"counter": "pgfault", "dev": "mlx5_0", "team": "infra"}, ss[0].Labels)
+	assert.Equal(t, float64(10), ss[0].Value)
+	assert.Equal(t, map[string]string{"counter": "pgmajfault", "dev": "mlx5_0", "team": "infra"}, ss[1].Labels)
+
+	_, err = spec.parseSamples("/z/mlx5_0/y", "pgfault 10\n")
+	assert.ErrorContains(t, err, "path does not match")
+}
@@ -0,0 +1,18 @@
+package kernelcounters
+
+import (
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the kernel counters component,
+// the prefix of every configured metric name.
+const SubSystem = "kernel_counters"
+
+// defaultCollector exports the configured metrics from the last check.
+var defaultCollector = newCollector()
+
+func init() {
+	pkgmetrics.MustRegister(
+		defaultCollector,
+	)
+}
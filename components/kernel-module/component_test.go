@@ -12,6 +12,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 )
 
 func TestComponentName(t *testing.T) {
@@ -549,3 +550,7 @@ func TestDataTimestamp(t *testing.T) {
 	assert.True(t, !c.lastCheckResult.ts.Before(beforeCheck), "Timestamp should be after the check started")
 	assert.True(t, !c.lastCheckResult.ts.After(afterCheck), "Timestamp should be before the check ended")
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -16,6 +16,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 )
 
 // MockGPUdInstance creates a minimal GPUdInstance for testing
@@ -709,3 +710,7 @@ func Test_componentConstructor_CheckKubeletRunning(t *testing.T) {
 		_ = c.checkKubeletRunning()
 	})
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/file"
 )
 
@@ -151,3 +152,7 @@ func TestDataMethods(t *testing.T) {
 	assert.Empty(t, nilData.HealthStateType())
 	assert.Empty(t, nilData.getError())
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,172 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// DeferredExtraInfoKey is the health state extra info key set to the reason
+// the checks of the component are deferred (e.g., "cpu pressure 92.1% >= 80.0%"),
+// meaning the health states may be stale until the checks resume.
+const DeferredExtraInfoKey = "deferred"
+
+// deferPollInterval is the interval to poll whether the deferred check can resume.
+var deferPollInterval = 5 * time.Second
+
+var metricCheckDeferred = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "gpud",
+		Subsystem: "component",
+		Name:      "check_deferred",
+		Help:      "set to 1 if the periodic check of the component is deferred under the host pressure",
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricCheckDeferred)
+}
+
+// CheckDeferrer returns the reason and true if the checks of the component
+// should be deferred (e.g., under the host pressure).
+// A nil deferrer never defers the checks.
+type CheckDeferrer func(componentName string) (reason string, deferred bool)
+
+func (d CheckDeferrer) deferral(componentName string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	return d(componentName)
+}
+
+// WaitWhileDeferred blocks while the checks of the component are deferred,
+// and returns false if the context is canceled before the checks resume.
+func (d CheckDeferrer) WaitWhileDeferred(ctx context.Context, componentName string) bool {
+	return d.waitWhileDeferred(ctx.Done(), componentName)
+}
+
+func (d CheckDeferrer) waitWhileDeferred(done <-chan struct{}, componentName string) bool {
+	if _, deferred := d.deferral(componentName); !deferred {
+		return true
+	}
+
+	gauge := metricCheckDeferred.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: componentName})
+	gauge.Set(1)
+	defer gauge.Set(0)
+
+	ticker := time.NewTicker(deferPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return false
+		case <-ticker.C:
+		}
+		if _, deferred := d.deferral(componentName); !deferred {
+			return true
+		}
+	}
+}
+
+// TagDeferredHealthStates returns a copy of the health states tagged with
+// the deferral reason, if the checks of the component are deferred.
+// Otherwise, returns the health states as is.
+func (d CheckDeferrer) TagDeferredHealthStates(componentName string, states apiv1.HealthStates) apiv1.HealthStates {
+	reason, deferred := d.deferral(componentName)
+	if !deferred || len(states) == 0 {
+		return states
+	}
+
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		extraInfo := make(map[string]string, len(s.ExtraInfo)+1)
+		for k, v := range s.ExtraInfo {
+			extraInfo[k] = v
+		}
+		extraInfo[DeferredExtraInfoKey] = reason
+		s.ExtraInfo = extraInfo
+		copied = append(copied, s)
+	}
+	return copied
+}
+
+// WithLoadShedding wraps the initialization function so that the initialized component
+// does not run the on-demand checks while its checks are deferred
+// (see [GPUdInstance.CheckDeferrer]), and tags its health states with the deferral reason.
+// The periodic checks are deferred by the [CheckTicker].
+func WithLoadShedding(initFunc InitFunc) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newLoadSheddingComponent(c, gpudInstance.CheckDeferrer), nil
+	}
+}
+
+func newLoadSheddingComponent(c Component, deferrer CheckDeferrer) Component {
+	lc := &loadSheddingComponent{Component: c, deferrer: deferrer}
+	return wrapComponent(lc, c, nil)
+}
+
+var _ Component = &loadSheddingComponent{}
+
+// loadSheddingComponent wraps a component to defer its on-demand checks.
+type loadSheddingComponent struct {
+	Component
+	deferrer CheckDeferrer
+}
+
+func (c *loadSheddingComponent) Check() CheckResult {
+	reason, deferred := c.deferrer.deferral(c.Name())
+	if !deferred {
+		return c.Component.Check()
+	}
+	return &deferredCheckResult{
+		componentName: c.Name(),
+		reason:        reason,
+		states:        c.LastHealthStates(),
+	}
+}
+
+func (c *loadSheddingComponent) LastHealthStates() apiv1.HealthStates {
+	return c.deferrer.TagDeferredHealthStates(c.Name(), c.Component.LastHealthStates())
+}
+
+var _ CheckResult = &deferredCheckResult{}
+
+// deferredCheckResult is the result of a deferred check,
+// with the last health states of the component.
+type deferredCheckResult struct {
+	componentName string
+	reason        string
+	states        apiv1.HealthStates
+}
+
+func (cr *deferredCheckResult) ComponentName() string {
+	return cr.componentName
+}
+
+func (cr *deferredCheckResult) String() string {
+	return cr.Summary()
+}
+
+func (cr *deferredCheckResult) Summary() string {
+	return "check deferred (" + cr.reason + ")"
+}
+
+func (cr *deferredCheckResult) HealthStateType() apiv1.HealthStateType {
+	if len(cr.states) == 0 {
+		return apiv1.HealthStateTypeHealthy
+	}
+	return cr.states[0].Health
+}
+
+func (cr *deferredCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
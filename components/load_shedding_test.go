@@ -0,0 +1,113 @@
+package components
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// newTestCheckDeferrer defers the checks of the component while the flag is set.
+func newTestCheckDeferrer(t *testing.T, componentName string, deferred *atomic.Bool) CheckDeferrer {
+	prevInterval := deferPollInterval
+	deferPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		deferPollInterval = prevInterval
+	})
+	return func(name string) (string, bool) {
+		if name != componentName || !deferred.Load() {
+			return "", false
+		}
+		return "cpu pressure 92.0% >= 80.0%", true
+	}
+}
+
+func TestLoadSheddingComponent(t *testing.T) {
+	var deferred atomic.Bool
+	deferrer := newTestCheckDeferrer(t, "scripted", &deferred)
+
+	inner := &scriptedComponent{script: []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy}}
+	c := newLoadSheddingComponent(inner, deferrer)
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.NotContains(t, c.LastHealthStates()[0].ExtraInfo, DeferredExtraInfoKey)
+
+	// the script is exhausted, thus panics if the check runs
+	deferred.Store(true)
+	cr = c.Check()
+	assert.Equal(t, "check deferred (cpu pressure 92.0% >= 80.0%)", cr.Summary())
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	require.Len(t, cr.HealthStates(), 1)
+	assert.Equal(t, "cpu pressure 92.0% >= 80.0%", cr.HealthStates()[0].ExtraInfo[DeferredExtraInfoKey])
+	assert.Equal(t, "cpu pressure 92.0% >= 80.0%", c.LastHealthStates()[0].ExtraInfo[DeferredExtraInfoKey])
+	// the underlying health states are not modified
+	assert.Nil(t, inner.LastHealthStates()[0].ExtraInfo)
+
+	deferred.Store(false)
+	assert.NotContains(t, c.LastHealthStates()[0].ExtraInfo, DeferredExtraInfoKey)
+}
+
+func TestLoadSheddingComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newLoadSheddingComponent(inner, nil)
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newLoadSheddingComponent(&scriptedComponent{}, nil).(HealthSettable)
+	assert.False(t, ok)
+}
+
+func TestCheckTickerDeferred(t *testing.T) {
+	var deferred atomic.Bool
+	deferred.Store(true)
+	deferrer := newTestCheckDeferrer(t, "deferred", &deferred)
+
+	ticker := NewCheckTicker("deferred", 10*time.Millisecond, AdaptiveIntervalConfig{}, deferrer)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C:
+		t.Fatal("tick delivered while deferred")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	deferred.Store(false)
+	select {
+	case <-ticker.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tick not delivered after resumed")
+	}
+
+	// not deferred for other components
+	other := NewCheckTicker("other", 10*time.Millisecond, AdaptiveIntervalConfig{}, deferrer)
+	defer other.Stop()
+	deferred.Store(true)
+	select {
+	case <-other.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tick not delivered")
+	}
+}
+
+func TestWaitWhileDeferred(t *testing.T) {
+	var deferred atomic.Bool
+	deferrer := newTestCheckDeferrer(t, "deferred", &deferred)
+	assert.True(t, deferrer.WaitWhileDeferred(context.Background(), "deferred"))
+
+	deferred.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.False(t, deferrer.WaitWhileDeferred(ctx, "deferred"))
+
+	// never deferred without the deferrer
+	assert.True(t, CheckDeferrer(nil).WaitWhileDeferred(ctx, "deferred"))
+}
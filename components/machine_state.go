@@ -0,0 +1,97 @@
+package components
+
+import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// MachineStateExtraInfoKey is the health state extra info key
+// for the machine-level operational state (e.g., "cordoned", "draining").
+const MachineStateExtraInfoKey = "machine_state"
+
+// MachineStateGetter looks up the machine-level operational state.
+type MachineStateGetter interface {
+	// Get returns the current machine state.
+	Get() apiv1.MachineState
+}
+
+// WithMachineState wraps the initialization function so that the initialized component
+// tags all its health states with the current machine state.
+// It returns the original initialization function if the getter is nil.
+func WithMachineState(initFunc InitFunc, getter MachineStateGetter) InitFunc {
+	if getter == nil {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newMachineStateComponent(c, getter), nil
+	}
+}
+
+func newMachineStateComponent(c Component, getter MachineStateGetter) Component {
+	mc := &machineStateComponent{
+		Component: c,
+		getter:    getter,
+	}
+	return wrapComponent(mc, c, nil)
+}
+
+var _ Component = &machineStateComponent{}
+
+// machineStateComponent wraps a component to tag its health states with the machine state.
+type machineStateComponent struct {
+	Component
+
+	getter MachineStateGetter
+}
+
+func (c *machineStateComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+
+	states := cr.HealthStates()
+	if len(states) == 0 {
+		return cr
+	}
+	return wrapCheckResult(&machineStateCheckResult{CheckResult: cr, states: c.tagHealthStates(states)}, cr)
+}
+
+func (c *machineStateComponent) LastHealthStates() apiv1.HealthStates {
+	return c.tagHealthStates(c.Component.LastHealthStates())
+}
+
+// tagHealthStates returns a copy of the health states tagged with the machine state.
+func (c *machineStateComponent) tagHealthStates(states apiv1.HealthStates) apiv1.HealthStates {
+	if len(states) == 0 {
+		return states
+	}
+
+	state := string(c.getter.Get().State)
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		extraInfo := make(map[string]string, len(s.ExtraInfo)+1)
+		for k, v := range s.ExtraInfo {
+			extraInfo[k] = v
+		}
+		extraInfo[MachineStateExtraInfoKey] = state
+		s.ExtraInfo = extraInfo
+		copied = append(copied, s)
+	}
+	return copied
+}
+
+var _ CheckResult = &machineStateCheckResult{}
+
+// machineStateCheckResult overrides the health states of the underlying check result.
+type machineStateCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *machineStateCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
@@ -0,0 +1,61 @@
+package components
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type fixedMachineState struct {
+	state apiv1.MachineStateType
+}
+
+func (m *fixedMachineState) Get() apiv1.MachineState {
+	return apiv1.MachineState{State: m.state}
+}
+
+func TestWithMachineStateNil(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithMachineState(initFunc, nil)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestMachineStateComponent(t *testing.T) {
+	getter := &fixedMachineState{state: apiv1.MachineStateActive}
+	inner := &scriptedComponent{
+		script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy},
+	}
+	c := newMachineStateComponent(inner, getter)
+
+	cr := c.Check()
+	require.Len(t, cr.HealthStates(), 1)
+	assert.Equal(t, "active", cr.HealthStates()[0].ExtraInfo[MachineStateExtraInfoKey])
+
+	getter.state = apiv1.MachineStateDraining
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, "draining", cr.HealthStates()[0].ExtraInfo[MachineStateExtraInfoKey])
+	assert.Equal(t, "draining", c.LastHealthStates()[0].ExtraInfo[MachineStateExtraInfoKey])
+	// the underlying health states are not modified
+	assert.Nil(t, inner.LastHealthStates()[0].ExtraInfo)
+}
+
+func TestMachineStateComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newMachineStateComponent(inner, &fixedMachineState{})
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newMachineStateComponent(&scriptedComponent{}, &fixedMachineState{}).(HealthSettable)
+	assert.False(t, ok)
+}
@@ -0,0 +1,145 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// MaintenanceExtraInfoKey is the health state and event extra info key
+	// set to "true" if the component was under maintenance.
+	MaintenanceExtraInfoKey = "maintenance"
+	// MaintenanceWindowExtraInfoKey is the health state and event extra info key
+	// for the ID of the maintenance window.
+	MaintenanceWindowExtraInfoKey = "maintenance_window"
+)
+
+// MaintenanceWindows looks up the scheduled maintenance windows.
+type MaintenanceWindows interface {
+	// ActiveWindow returns the ID of the maintenance window that covers
+	// the component at the given time, and false if none covers it.
+	ActiveWindow(component string, t time.Time) (string, bool)
+}
+
+// IsUnderMaintenance returns true if the extra info is tagged as maintenance.
+func IsUnderMaintenance(extraInfo map[string]string) bool {
+	return extraInfo[MaintenanceExtraInfoKey] == "true"
+}
+
+// WithMaintenance wraps the initialization function so that the initialized component
+// tags its health states and events that fall within the maintenance windows.
+// It returns the original initialization function if the windows are nil.
+func WithMaintenance(initFunc InitFunc, windows MaintenanceWindows) InitFunc {
+	if windows == nil {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newMaintenanceComponent(c, windows), nil
+	}
+}
+
+func newMaintenanceComponent(c Component, windows MaintenanceWindows) Component {
+	mc := &maintenanceComponent{
+		Component:      c,
+		windows:        windows,
+		getTimeNowFunc: func() time.Time { return time.Now().UTC() },
+	}
+	return wrapComponent(mc, c, nil)
+}
+
+var _ Component = &maintenanceComponent{}
+
+// maintenanceComponent wraps a component to tag its health states
+// and events during the maintenance windows.
+type maintenanceComponent struct {
+	Component
+
+	windows        MaintenanceWindows
+	getTimeNowFunc func() time.Time
+}
+
+func (c *maintenanceComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+
+	states, tagged := c.tagHealthStates(cr.HealthStates())
+	if !tagged {
+		return cr
+	}
+	return wrapCheckResult(&maintenanceCheckResult{CheckResult: cr, states: states}, cr)
+}
+
+func (c *maintenanceComponent) LastHealthStates() apiv1.HealthStates {
+	states, _ := c.tagHealthStates(c.Component.LastHealthStates())
+	return states
+}
+
+func (c *maintenanceComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	evs, err := c.Component.Events(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for i := range evs {
+		id, ok := c.windows.ActiveWindow(c.Name(), evs[i].Time.Time)
+		if !ok {
+			continue
+		}
+		evs[i].ExtraInfo = tagMaintenance(evs[i].ExtraInfo, id)
+	}
+	return evs, nil
+}
+
+// tagHealthStates returns a copy of the health states tagged with the maintenance window,
+// and true if the component is under maintenance at the time of the health states.
+func (c *maintenanceComponent) tagHealthStates(states apiv1.HealthStates) (apiv1.HealthStates, bool) {
+	if len(states) == 0 {
+		return states, false
+	}
+
+	ts := latestHealthStateTime(states)
+	if ts.IsZero() {
+		ts = c.getTimeNowFunc()
+	}
+	id, ok := c.windows.ActiveWindow(c.Name(), ts)
+	if !ok {
+		return states, false
+	}
+
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		s.ExtraInfo = tagMaintenance(s.ExtraInfo, id)
+		copied = append(copied, s)
+	}
+	return copied, true
+}
+
+// tagMaintenance returns a copy of the extra info with the maintenance tags.
+func tagMaintenance(extraInfo map[string]string, windowID string) map[string]string {
+	copied := make(map[string]string, len(extraInfo)+2)
+	for k, v := range extraInfo {
+		copied[k] = v
+	}
+	copied[MaintenanceExtraInfoKey] = "true"
+	copied[MaintenanceWindowExtraInfoKey] = windowID
+	return copied
+}
+
+var _ CheckResult = &maintenanceCheckResult{}
+
+// maintenanceCheckResult overrides the health states of the underlying check result.
+type maintenanceCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *maintenanceCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
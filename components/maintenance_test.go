@@ -0,0 +1,100 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// fixedMaintenanceWindows covers the component "scripted" in [start, end).
+type fixedMaintenanceWindows struct {
+	start time.Time
+	end   time.Time
+}
+
+func (w *fixedMaintenanceWindows) ActiveWindow(component string, t time.Time) (string, bool) {
+	if component != "scripted" || t.Before(w.start) || !t.Before(w.end) {
+		return "", false
+	}
+	return "w1", true
+}
+
+type eventsScriptedComponent struct {
+	*scriptedComponent
+	events apiv1.Events
+}
+
+func (s *eventsScriptedComponent) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return append(apiv1.Events(nil), s.events...), nil
+}
+
+func TestWithMaintenanceNil(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithMaintenance(initFunc, nil)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestMaintenanceComponent(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := &fixedMaintenanceWindows{start: ts.Add(90 * time.Second), end: ts.Add(150 * time.Second)}
+
+	inner := &eventsScriptedComponent{
+		scriptedComponent: &scriptedComponent{
+			ts:     ts,
+			script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy},
+		},
+		events: apiv1.Events{
+			{Time: metav1.NewTime(ts), Name: "before"},
+			{Time: metav1.NewTime(ts.Add(2 * time.Minute)), Name: "reboot", ExtraInfo: map[string]string{"k": "v"}},
+		},
+	}
+	c := newMaintenanceComponent(inner, windows)
+
+	// first check at ts+1m is before the window
+	cr := c.Check()
+	assert.False(t, IsUnderMaintenance(cr.HealthStates()[0].ExtraInfo))
+
+	// second check at ts+2m is within the window
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	require.Len(t, cr.HealthStates(), 1)
+	assert.True(t, IsUnderMaintenance(cr.HealthStates()[0].ExtraInfo))
+	assert.Equal(t, "w1", cr.HealthStates()[0].ExtraInfo[MaintenanceWindowExtraInfoKey])
+	assert.True(t, IsUnderMaintenance(c.LastHealthStates()[0].ExtraInfo))
+	// the underlying health states are not modified
+	assert.Nil(t, inner.LastHealthStates()[0].ExtraInfo)
+
+	// third check at ts+3m is after the window
+	cr = c.Check()
+	assert.False(t, IsUnderMaintenance(cr.HealthStates()[0].ExtraInfo))
+
+	evs, err := c.Events(context.Background(), ts)
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.False(t, IsUnderMaintenance(evs[0].ExtraInfo))
+	assert.True(t, IsUnderMaintenance(evs[1].ExtraInfo))
+	assert.Equal(t, "v", evs[1].ExtraInfo["k"])
+	assert.Equal(t, map[string]string{"k": "v"}, inner.events[1].ExtraInfo)
+}
+
+func TestMaintenanceComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newMaintenanceComponent(inner, &fixedMaintenanceWindows{})
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newMaintenanceComponent(&scriptedComponent{}, &fixedMaintenanceWindows{}).(HealthSettable)
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,6 +39,8 @@ const (
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -46,6 +49,15 @@ type component struct {
 	getVirtualMemoryFunc            func(context.Context) (*mem.VirtualMemoryStat, error)
 	getCurrentBPFJITBufferBytesFunc func() (uint64, error)
 
+	getSwapMemoryFunc     func(context.Context) (*mem.SwapMemoryStat, error)
+	readPressureFunc      func() (*Pressure, error)
+	readHugePagePoolsFunc func() ([]HugePagePool, error)
+	// returns the "enabled" and "defrag" transparent hugepage modes
+	readTHPModesFunc func() (string, string, error)
+
+	// cfg is the swap, pressure, and hugepage thresholds
+	cfg Config
+
 	// availableThresholdBytes is the threshold for available memory in bytes.
 	// When available memory falls below this value, a warning is logged.
 	// This mirrors Kubernetes' memory.available threshold for MemoryPressure detection.
@@ -75,6 +87,30 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		getCurrentBPFJITBufferBytesFunc: getCurrentBPFJITBufferBytes,
 
 		availableThresholdBytes: defaultAvailableThresholdBytes,
+
+		getSwapMemoryFunc: mem.SwapMemoryWithContext,
+
+		cfg: GetDefaultConfig(),
+	}
+
+	if runtime.GOOS == "linux" {
+		c.readPressureFunc = func() (*Pressure, error) {
+			return readPressure(defaultPressureFile)
+		}
+		c.readHugePagePoolsFunc = func() ([]HugePagePool, error) {
+			return readHugePagePools(defaultHugePagesDir)
+		}
+		c.readTHPModesFunc = func() (string, string, error) {
+			enabled, err := readTHPMode(defaultTHPEnabledFile)
+			if err != nil {
+				return "", "", err
+			}
+			defrag, err := readTHPMode(defaultTHPDefragFile)
+			if err != nil {
+				return "", "", err
+			}
+			return enabled, defrag, nil
+		}
 	}
 
 	if gpudInstance.EventStore != nil {
@@ -110,20 +146,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			_ = c.Check()
-
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-		}
-	}()
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -222,6 +245,15 @@ func (c *component) Check() components.CheckResult {
 		)
 	}
 
+	c.checkSwapAndHugePages(cr)
+
+	if issues := c.evaluateThresholds(cr); len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = strings.Join(issues, "; ")
+		log.Logger.Warnw("memory degraded", "reason", cr.reason)
+		return cr
+	}
+
 	cr.health = apiv1.HealthStateTypeHealthy
 	cr.reason = "ok"
 	log.Logger.Debugw(cr.reason, "used", humanize.IBytes(cr.UsedBytes), "total", humanize.IBytes(cr.TotalBytes))
@@ -229,6 +261,98 @@ func (c *component) Check() components.CheckResult {
 	return cr
 }
 
+// checkSwapAndHugePages collects the swap usage, the memory pressure, and the hugepage states.
+// These are best-effort, and the failures are only logged (e.g., not supported by the kernel).
+func (c *component) checkSwapAndHugePages(cr *checkResult) {
+	if c.getSwapMemoryFunc != nil {
+		cctx, ccancel := context.WithTimeout(c.ctx, 5*time.Second)
+		swap, err := c.getSwapMemoryFunc(cctx)
+		ccancel()
+		if err != nil {
+			log.Logger.Warnw("error getting swap memory", "error", err)
+		} else {
+			cr.SwapTotalBytes = swap.Total
+			cr.SwapUsedBytes = swap.Used
+			cr.SwapUsedPercent = fmt.Sprintf("%.2f", swap.UsedPercent)
+
+			metricSwapTotalBytes.With(prometheus.Labels{}).Set(float64(swap.Total))
+			metricSwapUsedBytes.With(prometheus.Labels{}).Set(float64(swap.Used))
+		}
+	}
+
+	if c.readPressureFunc != nil {
+		pressure, err := c.readPressureFunc()
+		if err != nil {
+			log.Logger.Warnw("error reading memory pressure", "error", err)
+		} else if pressure != nil {
+			cr.Pressure = pressure
+
+			metricPressureAvg60Percent.With(prometheus.Labels{"type": "some"}).Set(pressure.Some.Avg60)
+			metricPressureAvg60Percent.With(prometheus.Labels{"type": "full"}).Set(pressure.Full.Avg60)
+		}
+	}
+
+	if c.readHugePagePoolsFunc != nil {
+		pools, err := c.readHugePagePoolsFunc()
+		if err != nil {
+			log.Logger.Warnw("error reading hugepage pools", "error", err)
+		} else {
+			cr.HugePagePools = pools
+
+			for _, p := range pools {
+				labels := prometheus.Labels{"page_size": fmt.Sprintf("%dkB", p.PageSizeBytes/1024)}
+				metricHugePagesPool.With(labels).Set(float64(p.Total))
+				metricHugePagesFree.With(labels).Set(float64(p.Free))
+			}
+		}
+	}
+
+	if c.readTHPModesFunc != nil {
+		enabled, defrag, err := c.readTHPModesFunc()
+		if err != nil {
+			log.Logger.Warnw("error reading transparent hugepage mode", "error", err)
+		} else {
+			cr.THPEnabled = enabled
+			cr.THPDefrag = defrag
+		}
+	}
+}
+
+// evaluateThresholds returns the reasons of the thresholds exceeded, if any.
+func (c *component) evaluateThresholds(cr *checkResult) []string {
+	var issues []string
+
+	if th := c.cfg.swapUsedPercentThreshold(); th >= 0 && cr.SwapTotalBytes > 0 {
+		pct := float64(cr.SwapUsedBytes) / float64(cr.SwapTotalBytes) * 100
+		if pct >= th {
+			issues = append(issues, fmt.Sprintf("swap used %.2f%% (%s of %s) exceeds the threshold %.2f%%", pct, humanize.IBytes(cr.SwapUsedBytes), humanize.IBytes(cr.SwapTotalBytes), th))
+		}
+	}
+
+	if th := c.cfg.pressureFullAvg60Threshold(); th >= 0 && cr.Pressure != nil {
+		if cr.Pressure.Full.Avg60 >= th {
+			issues = append(issues, fmt.Sprintf("memory pressure (full avg60) %.2f%% exceeds the threshold %.2f%%", cr.Pressure.Full.Avg60, th))
+		}
+	}
+
+	if th := c.cfg.hugePagesUsedPercentThreshold(); th >= 0 {
+		for _, p := range cr.HugePagePools {
+			if p.Total == 0 {
+				continue
+			}
+			if pct := p.UsedPercent(); pct >= th {
+				issues = append(issues, fmt.Sprintf("hugepage pool %s used %.2f%% (%d free, %d reserved of %d) exceeds the threshold %.2f%%", humanize.IBytes(p.PageSizeBytes), pct, p.Free, p.Reserved, p.Total, th))
+			}
+		}
+	}
+
+	if c.cfg.ExpectedTHPMode != "" && cr.THPEnabled != "" && cr.THPEnabled != c.cfg.ExpectedTHPMode {
+		issues = append(issues, fmt.Sprintf("transparent hugepage mode %q, expected %q", cr.THPEnabled, c.cfg.ExpectedTHPMode))
+	}
+
+	return issues
+}
+
 var _ components.CheckResult = &checkResult{}
 
 type checkResult struct {
@@ -248,6 +372,23 @@ type checkResult struct {
 	// ref. https://github.com/deckhouse/deckhouse/issues/7402
 	BPFJITBufferBytes uint64 `json:"bpf_jit_buffer_bytes"`
 
+	SwapTotalBytes  uint64 `json:"swap_total_bytes"`
+	SwapUsedBytes   uint64 `json:"swap_used_bytes"`
+	SwapUsedPercent string `json:"swap_used_percent,omitempty"`
+
+	// Represents the memory pressure stall information (PSI).
+	// ref. "cat /proc/pressure/memory"
+	Pressure *Pressure `json:"pressure,omitempty"`
+
+	// Represents the hugepage pools of every page size.
+	// ref. "ls /sys/kernel/mm/hugepages"
+	HugePagePools []HugePagePool `json:"hugepage_pools,omitempty"`
+
+	// Represents the transparent hugepage modes.
+	// ref. "cat /sys/kernel/mm/transparent_hugepage/enabled"
+	THPEnabled string `json:"thp_enabled,omitempty"`
+	THPDefrag  string `json:"thp_defrag,omitempty"`
+
 	// timestamp of the last check
 	ts time.Time
 	// error from the last check
@@ -278,6 +419,21 @@ func (cr *checkResult) String() string {
 	if runtime.GOOS == "linux" {
 		table.Append([]string{"BPF JIT Buffer", humanize.IBytes(cr.BPFJITBufferBytes)})
 	}
+	if cr.SwapTotalBytes > 0 {
+		table.Append([]string{"Swap Used", humanize.IBytes(cr.SwapUsedBytes) + " / " + humanize.IBytes(cr.SwapTotalBytes)})
+	}
+	if cr.Pressure != nil {
+		table.Append([]string{"Pressure (some/full avg60)", fmt.Sprintf("%.2f %% / %.2f %%", cr.Pressure.Some.Avg60, cr.Pressure.Full.Avg60)})
+	}
+	for _, p := range cr.HugePagePools {
+		if p.Total == 0 {
+			continue
+		}
+		table.Append([]string{"HugePages " + humanize.IBytes(p.PageSizeBytes), fmt.Sprintf("%d free / %d total", p.Free, p.Total)})
+	}
+	if cr.THPEnabled != "" {
+		table.Append([]string{"THP", cr.THPEnabled})
+	}
 	table.Render()
 
 	return buf.String()
@@ -14,6 +14,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/eventstore"
 )
 
@@ -540,3 +541,112 @@ func TestKubernetesMemoryPressureThreshold(t *testing.T) {
 		assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
 	})
 }
+
+func TestComponentCheckSwapAndHugePages(t *testing.T) {
+	newComponent := func(cfg Config, swap *mem.SwapMemoryStat, pressure *Pressure, pools []HugePagePool, thp string) *component {
+		return &component{
+			ctx:    context.Background(),
+			cancel: func() {},
+			getTimeNowFunc: func() time.Time {
+				return time.Now().UTC()
+			},
+			getVirtualMemoryFunc: func(context.Context) (*mem.VirtualMemoryStat, error) {
+				return &mem.VirtualMemoryStat{Total: 16 << 30, Available: 8 << 30, Used: 8 << 30}, nil
+			},
+			getSwapMemoryFunc: func(context.Context) (*mem.SwapMemoryStat, error) {
+				return swap, nil
+			},
+			readPressureFunc: func() (*Pressure, error) {
+				return pressure, nil
+			},
+			readHugePagePoolsFunc: func() ([]HugePagePool, error) {
+				return pools, nil
+			},
+			readTHPModesFunc: func() (string, string, error) {
+				return thp, "madvise", nil
+			},
+			cfg: cfg,
+		}
+	}
+
+	healthySwap := &mem.SwapMemoryStat{Total: 8 << 30, Used: 1 << 30, UsedPercent: 12.5}
+	healthyPools := []HugePagePool{
+		{PageSizeBytes: 2 << 20, Total: 1024, Free: 512},
+		// empty pool is not checked
+		{PageSizeBytes: 1 << 30},
+	}
+
+	c := newComponent(Config{}, healthySwap, &Pressure{Full: PressureStats{Avg60: 1}}, healthyPools, "madvise")
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "ok", cr.Summary())
+	assert.Equal(t, uint64(8<<30), cr.SwapTotalBytes)
+	assert.Equal(t, "12.50", cr.SwapUsedPercent)
+	assert.Equal(t, 1.0, cr.Pressure.Full.Avg60)
+	assert.Len(t, cr.HugePagePools, 2)
+	assert.Equal(t, "madvise", cr.THPEnabled)
+	assert.Equal(t, "madvise", cr.THPDefrag)
+	assert.Contains(t, cr.String(), "Swap Used")
+	assert.Contains(t, cr.String(), "HugePages 2.0 MiB")
+
+	// swap, pressure, exhausted hugepages, and unexpected THP mode
+	c = newComponent(
+		Config{ExpectedTHPMode: "never"},
+		&mem.SwapMemoryStat{Total: 8 << 30, Used: 7 << 30},
+		&Pressure{Full: PressureStats{Avg60: 25}},
+		[]HugePagePool{{PageSizeBytes: 1 << 30, Total: 8, Free: 0}},
+		"always",
+	)
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "swap used 87.50%")
+	assert.Contains(t, cr.Summary(), "memory pressure (full avg60) 25.00%")
+	assert.Contains(t, cr.Summary(), "hugepage pool 1.0 GiB used 100.00%")
+	assert.Contains(t, cr.Summary(), `transparent hugepage mode "always", expected "never"`)
+
+	// disabled thresholds
+	c = newComponent(
+		Config{SwapUsedPercentThreshold: -1, PressureFullAvg60Threshold: -1, HugePagesUsedPercentThreshold: -1},
+		&mem.SwapMemoryStat{Total: 8 << 30, Used: 8 << 30},
+		&Pressure{Full: PressureStats{Avg60: 90}},
+		[]HugePagePool{{PageSizeBytes: 2 << 20, Total: 8}},
+		"always",
+	)
+	cr = c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+}
+
+func TestComponentCheckSwapAndHugePagesErrors(t *testing.T) {
+	c := &component{
+		ctx:    context.Background(),
+		cancel: func() {},
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getVirtualMemoryFunc: func(context.Context) (*mem.VirtualMemoryStat, error) {
+			return &mem.VirtualMemoryStat{Total: 16 << 30, Available: 8 << 30}, nil
+		},
+		getSwapMemoryFunc: func(context.Context) (*mem.SwapMemoryStat, error) {
+			return nil, errors.New("swap error")
+		},
+		readPressureFunc: func() (*Pressure, error) {
+			return nil, errors.New("pressure error")
+		},
+		readHugePagePoolsFunc: func() ([]HugePagePool, error) {
+			return nil, errors.New("hugepages error")
+		},
+		readTHPModesFunc: func() (string, string, error) {
+			return "", "", errors.New("thp error")
+		},
+	}
+
+	// best-effort, does not affect the health
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Nil(t, cr.Pressure)
+	assert.Empty(t, cr.HugePagePools)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultSwapUsedPercentThreshold is the default swap usage to report degraded,
+	// since the data loaders stall badly once the pages are swapped out.
+	DefaultSwapUsedPercentThreshold = 80.0
+	// DefaultPressureFullAvg60Threshold is the default share of the time (in percent)
+	// over the last 60 seconds that all the non-idle tasks were stalled on memory.
+	// ref. https://docs.kernel.org/accounting/psi.html
+	DefaultPressureFullAvg60Threshold = 10.0
+	// DefaultHugePagesUsedPercentThreshold is the default hugepage pool usage
+	// to report degraded, which is when the pool is exhausted, since the RDMA
+	// memory registration fails to pin the hugepage-backed buffers.
+	DefaultHugePagesUsedPercentThreshold = 100.0
+)
+
+// Config configures the memory component thresholds.
+// A zero threshold uses the default, and a negative threshold disables the check.
+type Config struct {
+	// SwapUsedPercentThreshold is the swap usage in percent to report degraded.
+	SwapUsedPercentThreshold float64 `json:"swap_used_percent_threshold,omitempty"`
+	// PressureFullAvg60Threshold is the "full" memory pressure (PSI) averaged
+	// over 60 seconds in percent to report degraded.
+	PressureFullAvg60Threshold float64 `json:"pressure_full_avg60_threshold,omitempty"`
+	// HugePagesUsedPercentThreshold is the usage of any hugepage pool in percent
+	// to report degraded (only the pools with the pages allocated).
+	HugePagesUsedPercentThreshold float64 `json:"hugepages_used_percent_threshold,omitempty"`
+	// ExpectedTHPMode is the expected transparent hugepage mode
+	// ("always", "madvise", or "never") to report degraded if different.
+	// Not checked if empty.
+	ExpectedTHPMode string `json:"expected_thp_mode,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	for name, v := range map[string]float64{
+		"swap_used_percent_threshold":      cfg.SwapUsedPercentThreshold,
+		"pressure_full_avg60_threshold":    cfg.PressureFullAvg60Threshold,
+		"hugepages_used_percent_threshold": cfg.HugePagesUsedPercentThreshold,
+	} {
+		if v > 100 {
+			return fmt.Errorf("%s must not exceed 100, got %.2f", name, v)
+		}
+	}
+	switch cfg.ExpectedTHPMode {
+	case "", "always", "madvise", "never":
+	default:
+		return fmt.Errorf("expected_thp_mode must be one of always, madvise, never, got %q", cfg.ExpectedTHPMode)
+	}
+	return nil
+}
+
+// threshold returns the default if zero, or -1 if disabled.
+func threshold(v float64, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	if v < 0 {
+		return -1
+	}
+	return v
+}
+
+func (cfg Config) swapUsedPercentThreshold() float64 {
+	return threshold(cfg.SwapUsedPercentThreshold, DefaultSwapUsedPercentThreshold)
+}
+
+func (cfg Config) pressureFullAvg60Threshold() float64 {
+	return threshold(cfg.PressureFullAvg60Threshold, DefaultPressureFullAvg60Threshold)
+}
+
+func (cfg Config) hugePagesUsedPercentThreshold() float64 {
+	return threshold(cfg.HugePagesUsedPercentThreshold, DefaultHugePagesUsedPercentThreshold)
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default memory config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default memory config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default memory config",
+		"swapUsedPercentThreshold", cfg.SwapUsedPercentThreshold,
+		"pressureFullAvg60Threshold", cfg.PressureFullAvg60Threshold,
+		"hugePagesUsedPercentThreshold", cfg.HugePagesUsedPercentThreshold,
+		"expectedTHPMode", cfg.ExpectedTHPMode,
+	)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
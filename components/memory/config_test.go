@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{SwapUsedPercentThreshold: -1, ExpectedTHPMode: "never"}.Validate())
+	assert.Error(t, Config{PressureFullAvg60Threshold: 101}.Validate())
+	assert.Error(t, Config{ExpectedTHPMode: "sometimes"}.Validate())
+}
+
+func TestConfigThresholds(t *testing.T) {
+	cfg := Config{}
+	assert.Equal(t, DefaultSwapUsedPercentThreshold, cfg.swapUsedPercentThreshold())
+	assert.Equal(t, DefaultPressureFullAvg60Threshold, cfg.pressureFullAvg60Threshold())
+	assert.Equal(t, DefaultHugePagesUsedPercentThreshold, cfg.hugePagesUsedPercentThreshold())
+
+	cfg = Config{SwapUsedPercentThreshold: 50, PressureFullAvg60Threshold: -5}
+	assert.Equal(t, 50.0, cfg.swapUsedPercentThreshold())
+	assert.Equal(t, -1.0, cfg.pressureFullAvg60Threshold())
+}
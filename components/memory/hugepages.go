@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ref. https://docs.kernel.org/admin-guide/mm/hugetlbpage.html
+	defaultHugePagesDir = "/sys/kernel/mm/hugepages"
+	// ref. https://docs.kernel.org/admin-guide/mm/transhuge.html
+	defaultTHPEnabledFile = "/sys/kernel/mm/transparent_hugepage/enabled"
+	defaultTHPDefragFile  = "/sys/kernel/mm/transparent_hugepage/defrag"
+)
+
+// e.g., "hugepages-2048kB"
+var regexHugePagesDir = regexp.MustCompile(`^hugepages-(\d+)kB$`)
+
+// HugePagePool is the hugepage pool of a page size.
+type HugePagePool struct {
+	// PageSizeBytes is the size of a single page in bytes (e.g., 2 MiB, 1 GiB).
+	PageSizeBytes uint64 `json:"page_size_bytes"`
+	// Total is the number of the pages in the pool ("nr_hugepages").
+	Total uint64 `json:"total"`
+	// Free is the number of the pages not allocated ("free_hugepages").
+	Free uint64 `json:"free"`
+	// Reserved is the number of the pages reserved but not allocated yet ("resv_hugepages"),
+	// which are counted as free.
+	Reserved uint64 `json:"reserved"`
+	// Surplus is the number of the pages allocated over the pool ("surplus_hugepages").
+	Surplus uint64 `json:"surplus"`
+}
+
+// UsedPercent returns the share of the pages in use, including the reserved ones,
+// or zero if the pool is empty.
+func (p HugePagePool) UsedPercent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	// reserved pages are promised to the mappings, thus not available to the others
+	avail := p.Free
+	if p.Reserved < avail {
+		avail -= p.Reserved
+	} else {
+		avail = 0
+	}
+	return float64(p.Total-avail) / float64(p.Total) * 100
+}
+
+// readHugePagePools reads the hugepage pools of every page size,
+// sorted by the page size, and returns nil if not supported.
+func readHugePagePools(dir string) ([]HugePagePool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var pools []HugePagePool
+	for _, entry := range entries {
+		matches := regexHugePagesDir.FindStringSubmatch(entry.Name())
+		if len(matches) != 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		pool := HugePagePool{PageSizeBytes: kb * 1024}
+		for name, v := range map[string]*uint64{
+			"nr_hugepages":      &pool.Total,
+			"free_hugepages":    &pool.Free,
+			"resv_hugepages":    &pool.Reserved,
+			"surplus_hugepages": &pool.Surplus,
+		} {
+			*v, err = readUint(filepath.Join(dir, entry.Name(), name))
+			if err != nil {
+				return nil, err
+			}
+		}
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].PageSizeBytes < pools[j].PageSizeBytes
+	})
+	return pools, nil
+}
+
+func readUint(file string) (uint64, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// readTHPMode reads the selected transparent hugepage mode in the brackets
+// (e.g., "madvise" of "always [madvise] never"), and returns an empty string if not supported.
+func readTHPMode(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, f := range strings.Fields(string(b)) {
+		if strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]") {
+			return strings.Trim(f, "[]"), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHugePagePool(t *testing.T, dir string, name string, total, free, resv, surplus string) {
+	d := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(d, 0755))
+	for f, v := range map[string]string{
+		"nr_hugepages":      total,
+		"free_hugepages":    free,
+		"resv_hugepages":    resv,
+		"surplus_hugepages": surplus,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(d, f), []byte(v+"\n"), 0644))
+	}
+}
+
+func TestReadHugePagePools(t *testing.T) {
+	dir := t.TempDir()
+	writeHugePagePool(t, dir, "hugepages-1048576kB", "8", "0", "0", "0")
+	writeHugePagePool(t, dir, "hugepages-2048kB", "1024", "512", "128", "0")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "not-a-pool"), 0755))
+
+	pools, err := readHugePagePools(dir)
+	require.NoError(t, err)
+	require.Len(t, pools, 2)
+
+	assert.Equal(t, HugePagePool{PageSizeBytes: 2 * 1024 * 1024, Total: 1024, Free: 512, Reserved: 128}, pools[0])
+	assert.InDelta(t, 62.5, pools[0].UsedPercent(), 0.001)
+
+	assert.Equal(t, uint64(1024*1024*1024), pools[1].PageSizeBytes)
+	assert.InDelta(t, 100.0, pools[1].UsedPercent(), 0.001)
+
+	pools, err = readHugePagePools(filepath.Join(dir, "does-not-exist"))
+	require.NoError(t, err)
+	assert.Nil(t, pools)
+}
+
+func TestHugePagePoolUsedPercent(t *testing.T) {
+	assert.Zero(t, HugePagePool{}.UsedPercent())
+	assert.InDelta(t, 0.0, HugePagePool{Total: 10, Free: 10}.UsedPercent(), 0.001)
+	// reserved more than free
+	assert.InDelta(t, 100.0, HugePagePool{Total: 10, Free: 2, Reserved: 5}.UsedPercent(), 0.001)
+}
+
+func TestReadTHPMode(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "enabled")
+	require.NoError(t, os.WriteFile(file, []byte("always [madvise] never\n"), 0644))
+
+	mode, err := readTHPMode(file)
+	require.NoError(t, err)
+	assert.Equal(t, "madvise", mode)
+
+	mode, err = readTHPMode(filepath.Join(t.TempDir(), "does-not-exist"))
+	require.NoError(t, err)
+	assert.Empty(t, mode)
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -63,6 +64,56 @@ var (
 		},
 		[]string{pkgmetrics.MetricComponentLabelKey},
 	).MustCurryWith(componentLabel)
+
+	metricSwapTotalBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "swap_total_bytes",
+			Help:      "tracks the total swap in bytes",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey},
+	).MustCurryWith(componentLabel)
+
+	metricSwapUsedBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "swap_used_bytes",
+			Help:      "tracks the used swap in bytes",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey},
+	).MustCurryWith(componentLabel)
+
+	metricPressureAvg60Percent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "pressure_avg60_percent",
+			Help:      "tracks the share of the time stalled on memory over the last 60 seconds (PSI), by some or full of the tasks",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "type"},
+	).MustCurryWith(componentLabel)
+
+	metricHugePagesPool = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "hugepages_pool_pages",
+			Help:      "tracks the number of the pages in the hugepage pool, by the page size",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "page_size"},
+	).MustCurryWith(componentLabel)
+
+	metricHugePagesFree = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "hugepages_free_pages",
+			Help:      "tracks the number of the free pages in the hugepage pool, by the page size",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "page_size"},
+	).MustCurryWith(componentLabel)
 )
 
 func init() {
@@ -72,5 +123,22 @@ func init() {
 		metricUsedBytes,
 		metricUsedPercent,
 		metricFreeBytes,
+		metricSwapTotalBytes,
+		metricSwapUsedBytes,
+		metricPressureAvg60Percent,
+		metricHugePagesPool,
+		metricHugePagesFree,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_total_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_available_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_free_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_swap_total_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_swap_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: SubSystem + "_pressure_avg60_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+		apiv1.MetricMetadata{Name: SubSystem + "_hugepages_pool_pages", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
+		apiv1.MetricMetadata{Name: SubSystem + "_hugepages_free_pages", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitCount},
 	)
 }
@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"github.com/leptonai/gpud/pkg/psi"
+)
+
+const defaultPressureFile = psi.DefaultMemoryFile
+
+// PressureStats is a line of the pressure stall information (PSI),
+// the share of the time in percent that the tasks were stalled on memory.
+type PressureStats = psi.Stats
+
+// Pressure is the memory pressure stall information.
+type Pressure = psi.Pressure
+
+// readPressure reads the memory PSI file, and returns nil
+// if not supported by the kernel (e.g., "CONFIG_PSI" disabled or "psi=0").
+func readPressure(file string) (*Pressure, error) {
+	return psi.Read(file)
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPressure(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "memory")
+	require.NoError(t, os.WriteFile(file, []byte(`some avg10=1.50 avg60=2.25 avg300=0.75 total=123456
+full avg10=0.50 avg60=12.00 avg300=0.10 total=6543
+`), 0644))
+
+	p, err := readPressure(file)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, PressureStats{Avg10: 1.5, Avg60: 2.25, Avg300: 0.75, TotalMicroseconds: 123456}, p.Some)
+	assert.Equal(t, PressureStats{Avg10: 0.5, Avg60: 12, Avg300: 0.1, TotalMicroseconds: 6543}, p.Full)
+}
+
+func TestReadPressureNotSupported(t *testing.T) {
+	p, err := readPressure(filepath.Join(t.TempDir(), "does-not-exist"))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestReadPressureInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "memory")
+	require.NoError(t, os.WriteFile(file, []byte("some avg10=bad avg60=0.00 avg300=0.00 total=0\n"), 0644))
+
+	_, err := readPressure(file)
+	assert.Error(t, err)
+}
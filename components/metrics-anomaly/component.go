@@ -0,0 +1,348 @@
+// Package metricsanomaly detects the anomalies on the metric series in the metrics store
+// (e.g., GPU temperature, power, InfiniBand error rates), when a data point deviates
+// sharply from the recent baseline of its own series, even if the fixed thresholds
+// are not crossed.
+package metricsanomaly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// Name is the ID of the metrics anomaly detection component.
+const Name = "metrics-anomaly"
+
+const (
+	// EventNameMetricAnomaly is the event name of the detected anomaly.
+	EventNameMetricAnomaly = "metric_anomaly"
+
+	// DefaultLookback is how far back the first check reads the metrics,
+	// to learn the baselines without waiting for the new data points.
+	DefaultLookback = time.Hour
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	metricsStore pkgmetrics.Store
+	eventBucket  eventstore.Bucket
+
+	getTimeNowFunc func() time.Time
+	getConfigFunc  func() Config
+
+	// checkMu serializes the checks, as the detector is not safe for concurrent use
+	checkMu  sync.Mutex
+	detector *detector
+	// cursor is the time of the last data point read from the metrics store
+	cursor time.Time
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the metrics anomaly detection component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		metricsStore: gpudInstance.MetricsStore,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc: GetDefaultConfig,
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"metrics",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking metrics anomalies")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	if c.metricsStore == nil {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "no metrics store"
+		return cr
+	}
+
+	c.checkMu.Lock()
+	defer c.checkMu.Unlock()
+
+	cfg := c.getConfigFunc()
+	if c.detector == nil || !reflect.DeepEqual(c.detector.cfg, cfg) {
+		// the baselines are relearned with the new config
+		c.detector = newDetector(cfg)
+		c.cursor = time.Time{}
+	}
+	since := c.cursor
+	if since.IsZero() {
+		since = cr.ts.Add(-DefaultLookback)
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+	ms, err := c.metricsStore.Read(cctx, pkgmetrics.WithSince(since))
+	ccancel()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "error reading metrics"
+		log.Logger.Warnw(cr.reason, "error", cr.err)
+		return cr
+	}
+
+	for _, m := range ms {
+		if t := time.UnixMilli(m.UnixMilliseconds).UTC(); t.After(c.cursor) {
+			c.cursor = t
+		}
+
+		a := c.detector.observe(m)
+		if a == nil {
+			continue
+		}
+		cr.Anomalies = append(cr.Anomalies, *a)
+
+		metricDetectedTotal.With(prometheus.Labels{"metric": a.Metric}).Inc()
+		log.Logger.Warnw("metric anomaly detected", "metric", a.Metric, "labels", a.Labels, "value", a.Value, "baseline", a.Baseline, "stddev", a.StdDev)
+
+		if err := c.recordAnomalyEvent(*a); err != nil {
+			cr.err = err
+			cr.health = apiv1.HealthStateTypeUnhealthy
+			cr.reason = "error recording metric anomaly event"
+			log.Logger.Warnw(cr.reason, "error", cr.err)
+			return cr
+		}
+	}
+	cr.Series = len(c.detector.states)
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("tracking %d series", cr.Series)
+	if len(cr.Anomalies) > 0 {
+		cr.reason = fmt.Sprintf("tracking %d series, %d anomaly(s) detected", cr.Series, len(cr.Anomalies))
+	}
+	return cr
+}
+
+func (c *component) recordAnomalyEvent(a Anomaly) error {
+	if c.eventBucket == nil {
+		return nil
+	}
+
+	extraInfo := make(map[string]string, len(a.Labels)+5)
+	for k, v := range a.Labels {
+		extraInfo[k] = v
+	}
+	extraInfo["metric"] = a.Metric
+	extraInfo["value"] = strconv.FormatFloat(a.Value, 'f', -1, 64)
+	extraInfo["baseline"] = strconv.FormatFloat(a.Baseline, 'f', -1, 64)
+	extraInfo["stddev"] = strconv.FormatFloat(a.StdDev, 'f', -1, 64)
+	extraInfo["zscore"] = strconv.FormatFloat(a.ZScore, 'f', 2, 64)
+
+	ev := eventstore.Event{
+		Component: Name,
+		Time:      a.Time.Time,
+		Name:      EventNameMetricAnomaly,
+		Type:      string(apiv1.EventTypeWarning),
+		Message:   fmt.Sprintf("%s%s at %.2f deviates from its baseline %.2f (stddev %.2f)", a.Metric, formatLabels(a.Labels), a.Value, a.Baseline, a.StdDev),
+		ExtraInfo: extraInfo,
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	err := c.eventBucket.Insert(cctx, ev)
+	ccancel()
+	return err
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(labels)
+	return string(b)
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Series is the number of the series with the baselines.
+	Series int `json:"series"`
+	// Anomalies is the anomalies detected in the last check.
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Anomalies) == 0 {
+		return "no anomaly"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Time", "Metric", "Labels", "Value", "Baseline", "StdDev"})
+	for _, a := range cr.Anomalies {
+		table.Append([]string{
+			a.Time.Format(time.RFC3339),
+			a.Metric,
+			formatLabels(a.Labels),
+			fmt.Sprintf("%.2f", a.Value),
+			fmt.Sprintf("%.2f", a.Baseline),
+			fmt.Sprintf("%.2f", a.StdDev),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	b, _ := json.Marshal(cr)
+	state.ExtraInfo = map[string]string{"data": string(b)}
+	return apiv1.HealthStates{state}
+}
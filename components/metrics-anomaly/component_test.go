@@ -0,0 +1,143 @@
+package metricsanomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type fakeMetricsStore struct {
+	metrics pkgmetrics.Metrics
+}
+
+func (s *fakeMetricsStore) Record(_ context.Context, ms ...pkgmetrics.Metric) error {
+	s.metrics = append(s.metrics, ms...)
+	return nil
+}
+
+func (s *fakeMetricsStore) Read(_ context.Context, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	op := &pkgmetrics.Op{}
+	if err := op.ApplyOpts(opts); err != nil {
+		return nil, err
+	}
+	var ms pkgmetrics.Metrics
+	for _, m := range s.metrics {
+		if m.UnixMilliseconds >= op.Since.UnixMilli() {
+			ms = append(ms, m)
+		}
+	}
+	return ms, nil
+}
+
+func (s *fakeMetricsStore) Purge(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
+// createGPUdInstance creates a GPUdInstance with the test event store for testing
+func createGPUdInstance(t *testing.T, metricsStore pkgmetrics.Store) (*components.GPUdInstance, func()) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+
+	eventStore, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	return &components.GPUdInstance{
+		RootCtx:      context.Background(),
+		EventStore:   eventStore,
+		MetricsStore: metricsStore,
+	}, cleanup
+}
+
+func mustComponent(t *testing.T, comp components.Component) *component {
+	t.Helper()
+
+	c, ok := comp.(*component)
+	require.True(t, ok)
+	return c
+}
+
+func TestComponentNoMetricsStore(t *testing.T) {
+	gpudInstance, cleanup := createGPUdInstance(t, nil)
+	defer cleanup()
+
+	comp, err := New(gpudInstance)
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+	c := mustComponent(t, comp)
+	assert.Equal(t, Name, c.Name())
+	assert.True(t, c.IsSupported())
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "no metrics store", cr.Summary())
+}
+
+func TestComponentCheck(t *testing.T) {
+	const name = "accelerator_nvidia_temperature_current_celsius"
+
+	store := &fakeMetricsStore{}
+	gpudInstance, cleanup := createGPUdInstance(t, store)
+	defer cleanup()
+
+	comp, err := New(gpudInstance)
+	require.NoError(t, err)
+	defer func() {
+		_ = comp.Close()
+	}()
+	c := mustComponent(t, comp)
+	c.getConfigFunc = func() Config {
+		return Config{Series: []Series{{Name: name, MinDeviation: 5}}, MinSamples: 10}
+	}
+	now := testStart.Add(30 * time.Minute)
+	c.getTimeNowFunc = func() time.Time { return now }
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, store.Record(context.Background(), testMetric(name, "GPU-0", i, 40+float64(i%2))))
+	}
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "tracking 1 series", cr.Summary())
+
+	require.NoError(t, store.Record(context.Background(), testMetric(name, "GPU-0", 20, 75)))
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "tracking 1 series, 1 anomaly(s) detected", cr.Summary())
+	require.Len(t, cr.(*checkResult).Anomalies, 1)
+
+	// the same data point is not detected twice
+	cr = c.Check()
+	assert.Empty(t, cr.(*checkResult).Anomalies)
+
+	evs, err := c.Events(context.Background(), testStart)
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameMetricAnomaly, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+	assert.Contains(t, evs[0].Message, "GPU-0")
+
+	stored, err := c.eventBucket.Get(context.Background(), testStart)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "GPU-0", stored[0].ExtraInfo["uuid"])
+	assert.Equal(t, name, stored[0].ExtraInfo["metric"])
+	assert.Equal(t, "75", stored[0].ExtraInfo["value"])
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Contains(t, states[0].ExtraInfo["data"], `"series":1`)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,164 @@
+package metricsanomaly
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	componentsnvidiainfiniband "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband"
+	componentsnvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiapower "github.com/leptonai/gpud/components/accelerator/nvidia/power"
+	componentsnvidiatemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultAlpha is the default smoothing factor of the moving average and variance,
+	// where the larger value adapts the baseline faster to the recent data points.
+	DefaultAlpha = 0.1
+	// DefaultZScoreThreshold is the default number of the standard deviations
+	// from the baseline, at or above which the data point is anomalous.
+	DefaultZScoreThreshold = 4.0
+	// DefaultMinSamples is the default number of the data points of a series
+	// to learn the baseline from, before detecting the anomalies
+	// (30 minutes with the default metrics sync interval).
+	DefaultMinSamples = 30
+	// DefaultCooldown is the default duration to suppress the repeated
+	// anomaly events of the same series.
+	DefaultCooldown = 30 * time.Minute
+)
+
+// Series selects the metric series to detect the anomalies of.
+// Each label set of the metric (e.g., per GPU UUID) has its own baseline.
+type Series struct {
+	// Name is the metric name in the metrics store
+	// (e.g., "accelerator_nvidia_temperature_current_celsius").
+	Name string `json:"name"`
+	// Rate evaluates the per-second rate of the cumulative counter,
+	// instead of the value itself (e.g., error counters).
+	Rate bool `json:"rate,omitempty"`
+	// MinDeviation is the minimum absolute deviation from the baseline
+	// to be anomalous, to ignore the tiny changes of the very stable series
+	// (e.g., 1°C on a GPU always at 40°C).
+	MinDeviation float64 `json:"min_deviation,omitempty"`
+}
+
+// DefaultSeries returns the GPU temperature, power, and the InfiniBand and NVLink
+// error rate series.
+func DefaultSeries() []Series {
+	return []Series{
+		{Name: componentsnvidiatemperature.SubSystem + "_current_celsius", MinDeviation: 5},
+		{Name: componentsnvidiapower.SubSystem + "_current_usage_milli_watts", MinDeviation: 50_000},
+		{Name: componentsnvidiainfiniband.SubSystem + "_link_downed", Rate: true},
+		{Name: componentsnvidianvlink.SubSystem + "_replay_errors", Rate: true},
+	}
+}
+
+// Config configures the anomaly detection on the metric series.
+type Config struct {
+	// Series are the metric series to track.
+	// Defaults to DefaultSeries if empty.
+	Series []Series `json:"series,omitempty"`
+
+	// Alpha is the smoothing factor in (0, 1).
+	// Defaults to DefaultAlpha if zero.
+	Alpha float64 `json:"alpha,omitempty"`
+	// ZScoreThreshold is the number of the standard deviations from the baseline.
+	// Defaults to DefaultZScoreThreshold if zero.
+	ZScoreThreshold float64 `json:"zscore_threshold,omitempty"`
+	// MinSamples is the number of the data points to learn the baseline from.
+	// Defaults to DefaultMinSamples if zero.
+	MinSamples int `json:"min_samples,omitempty"`
+	// Cooldown is the duration to suppress the repeated events of the same series.
+	// Defaults to DefaultCooldown if zero.
+	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	seen := make(map[string]struct{}, len(cfg.Series))
+	for _, s := range cfg.Series {
+		if s.Name == "" {
+			return fmt.Errorf("series name is empty")
+		}
+		if _, ok := seen[s.Name]; ok {
+			return fmt.Errorf("duplicate series %q", s.Name)
+		}
+		seen[s.Name] = struct{}{}
+		if s.MinDeviation < 0 {
+			return fmt.Errorf("min_deviation of %q must be non-negative, got %v", s.Name, s.MinDeviation)
+		}
+	}
+	if cfg.Alpha < 0 || cfg.Alpha >= 1 {
+		return fmt.Errorf("alpha must be in (0, 1), got %v", cfg.Alpha)
+	}
+	if cfg.ZScoreThreshold < 0 {
+		return fmt.Errorf("zscore_threshold must be non-negative, got %v", cfg.ZScoreThreshold)
+	}
+	if cfg.MinSamples < 0 {
+		return fmt.Errorf("min_samples must be non-negative, got %d", cfg.MinSamples)
+	}
+	if cfg.Cooldown.Duration < 0 {
+		return fmt.Errorf("cooldown must be non-negative, got %s", cfg.Cooldown.Duration)
+	}
+	return nil
+}
+
+func (cfg Config) series() []Series {
+	if len(cfg.Series) > 0 {
+		return cfg.Series
+	}
+	return DefaultSeries()
+}
+
+func (cfg Config) alpha() float64 {
+	if cfg.Alpha > 0 {
+		return cfg.Alpha
+	}
+	return DefaultAlpha
+}
+
+func (cfg Config) zScoreThreshold() float64 {
+	if cfg.ZScoreThreshold > 0 {
+		return cfg.ZScoreThreshold
+	}
+	return DefaultZScoreThreshold
+}
+
+func (cfg Config) minSamples() int {
+	if cfg.MinSamples > 0 {
+		return cfg.MinSamples
+	}
+	return DefaultMinSamples
+}
+
+func (cfg Config) cooldown() time.Duration {
+	if cfg.Cooldown.Duration > 0 {
+		return cfg.Cooldown.Duration
+	}
+	return DefaultCooldown
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default anomaly detection config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default anomaly detection config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default metrics anomaly config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
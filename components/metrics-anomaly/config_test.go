@@ -0,0 +1,31 @@
+package metricsanomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Series: DefaultSeries(), Alpha: 0.5, Cooldown: metav1.Duration{Duration: time.Minute}}.Validate())
+
+	assert.Error(t, Config{Series: []Series{{}}}.Validate())
+	assert.Error(t, Config{Series: []Series{{Name: "a"}, {Name: "a"}}}.Validate())
+	assert.Error(t, Config{Series: []Series{{Name: "a", MinDeviation: -1}}}.Validate())
+	assert.Error(t, Config{Alpha: 1}.Validate())
+	assert.Error(t, Config{ZScoreThreshold: -1}.Validate())
+	assert.Error(t, Config{MinSamples: -1}.Validate())
+	assert.Error(t, Config{Cooldown: metav1.Duration{Duration: -time.Second}}.Validate())
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := Config{}
+	assert.Equal(t, DefaultSeries(), cfg.series())
+	assert.Equal(t, DefaultAlpha, cfg.alpha())
+	assert.Equal(t, DefaultZScoreThreshold, cfg.zScoreThreshold())
+	assert.Equal(t, DefaultMinSamples, cfg.minSamples())
+	assert.Equal(t, DefaultCooldown, cfg.cooldown())
+}
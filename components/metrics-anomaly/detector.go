@@ -0,0 +1,167 @@
+package metricsanomaly
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// Anomaly is a data point deviating sharply from the recent baseline of its own series.
+type Anomaly struct {
+	Time metav1.Time `json:"time"`
+	// Metric is the metric name.
+	Metric string `json:"metric"`
+	// Labels identifies the series of the metric (e.g., the GPU UUID).
+	Labels map[string]string `json:"labels,omitempty"`
+	// Value is the data point, or the per-second rate for the counters.
+	Value float64 `json:"value"`
+	// Baseline is the moving average of the series before the data point.
+	Baseline float64 `json:"baseline"`
+	// StdDev is the moving standard deviation of the series before the data point.
+	StdDev float64 `json:"stddev"`
+	// ZScore is the number of the standard deviations from the baseline,
+	// zero if the series has been constant.
+	ZScore float64 `json:"zscore"`
+}
+
+// seriesState is the exponentially weighted moving average and variance of a series.
+type seriesState struct {
+	mean     float64
+	variance float64
+	samples  int
+
+	// lastTime is the time of the last observed data point, to skip the ones already seen
+	lastTime time.Time
+	// lastValue is the last raw value of a counter, to compute the rate
+	lastValue float64
+
+	lastAnomaly time.Time
+}
+
+// detector keeps the baseline of each series, not safe for concurrent use.
+type detector struct {
+	cfg    Config
+	series map[string]Series
+	states map[string]*seriesState
+}
+
+func newDetector(cfg Config) *detector {
+	d := &detector{
+		cfg:    cfg,
+		series: make(map[string]Series),
+		states: make(map[string]*seriesState),
+	}
+	for _, s := range cfg.series() {
+		d.series[s.Name] = s
+	}
+	return d
+}
+
+// observe updates the baseline of the series of the metric, and returns the anomaly
+// if the data point deviates from the baseline, or nil otherwise.
+// The repeated anomalies of the same series within the cooldown are not returned.
+func (d *detector) observe(m pkgmetrics.Metric) *Anomaly {
+	s, ok := d.series[m.Name]
+	if !ok {
+		return nil
+	}
+
+	key := seriesKey(m)
+	st, ok := d.states[key]
+	if !ok {
+		st = &seriesState{}
+		d.states[key] = st
+	}
+
+	ts := time.UnixMilli(m.UnixMilliseconds).UTC()
+	if !ts.After(st.lastTime) {
+		return nil
+	}
+	prevTime := st.lastTime
+	st.lastTime = ts
+
+	value := m.Value
+	if s.Rate {
+		prevValue := st.lastValue
+		st.lastValue = m.Value
+		if prevTime.IsZero() {
+			return nil
+		}
+		if m.Value < prevValue {
+			// counter reset (e.g., driver reload), restart from the new value
+			return nil
+		}
+		value = (m.Value - prevValue) / ts.Sub(prevTime).Seconds()
+	}
+
+	var anomaly *Anomaly
+	if st.samples >= d.cfg.minSamples() {
+		diff := math.Abs(value - st.mean)
+		stddev := math.Sqrt(st.variance)
+
+		var zscore float64
+		if stddev > 0 {
+			zscore = diff / stddev
+		}
+		deviated := diff > 0 && diff >= s.MinDeviation && (stddev == 0 || zscore >= d.cfg.zScoreThreshold())
+		if deviated && ts.Sub(st.lastAnomaly) >= d.cfg.cooldown() {
+			st.lastAnomaly = ts
+			anomaly = &Anomaly{
+				Time:     metav1.NewTime(ts),
+				Metric:   m.Name,
+				Labels:   m.Labels,
+				Value:    value,
+				Baseline: st.mean,
+				StdDev:   stddev,
+				ZScore:   zscore,
+			}
+		}
+	}
+
+	st.update(value, d.cfg.alpha())
+	return anomaly
+}
+
+// update adds the value to the moving average and variance.
+// ref. "Incremental calculation of weighted mean and variance" by Tony Finch
+func (st *seriesState) update(value float64, alpha float64) {
+	st.samples++
+	if st.samples == 1 {
+		st.mean = value
+		st.variance = 0
+		return
+	}
+	diff := value - st.mean
+	incr := alpha * diff
+	st.mean += incr
+	st.variance = (1 - alpha) * (st.variance + diff*incr)
+}
+
+// seriesKey returns the metric name with the sorted labels.
+func seriesKey(m pkgmetrics.Metric) string {
+	if len(m.Labels) == 0 {
+		return m.Component + "/" + m.Name
+	}
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(m.Component)
+	sb.WriteString("/")
+	sb.WriteString(m.Name)
+	for _, k := range keys {
+		sb.WriteString(",")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(m.Labels[k])
+	}
+	return sb.String()
+}
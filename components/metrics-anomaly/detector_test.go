@@ -0,0 +1,103 @@
+package metricsanomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var testStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testMetric(name string, uuid string, minute int, value float64) pkgmetrics.Metric {
+	return pkgmetrics.Metric{
+		UnixMilliseconds: testStart.Add(time.Duration(minute) * time.Minute).UnixMilli(),
+		Component:        "test",
+		Name:             name,
+		Labels:           map[string]string{"uuid": uuid},
+		Value:            value,
+	}
+}
+
+func TestDetectorValue(t *testing.T) {
+	const name = "temperature"
+	d := newDetector(Config{
+		Series:     []Series{{Name: name, MinDeviation: 5}},
+		MinSamples: 10,
+	})
+
+	// learns the baseline around 40 on one GPU and 70 on the other
+	for i := 0; i < 20; i++ {
+		jitter := float64(i%3) - 1
+		assert.Nil(t, d.observe(testMetric(name, "GPU-0", i, 40+jitter)))
+		assert.Nil(t, d.observe(testMetric(name, "GPU-1", i, 70+jitter)))
+	}
+
+	// 60 is within the fixed thresholds, and normal for the other GPU
+	a := d.observe(testMetric(name, "GPU-0", 20, 60))
+	require.NotNil(t, a)
+	assert.Equal(t, name, a.Metric)
+	assert.Equal(t, "GPU-0", a.Labels["uuid"])
+	assert.Equal(t, 60.0, a.Value)
+	assert.InDelta(t, 40, a.Baseline, 1)
+	assert.Greater(t, a.ZScore, DefaultZScoreThreshold)
+
+	// suppressed within the cooldown
+	assert.Nil(t, d.observe(testMetric(name, "GPU-0", 21, 65)))
+	assert.Nil(t, d.observe(testMetric(name, "GPU-1", 20, 70)))
+
+	// already observed data points are skipped
+	assert.Nil(t, d.observe(testMetric(name, "GPU-1", 20, 100)))
+
+	// not tracked
+	assert.Nil(t, d.observe(testMetric("other", "GPU-0", 22, 1000)))
+}
+
+func TestDetectorMinDeviation(t *testing.T) {
+	const name = "temperature"
+	d := newDetector(Config{
+		Series:     []Series{{Name: name, MinDeviation: 5}},
+		MinSamples: 5,
+	})
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, d.observe(testMetric(name, "GPU-0", i, 40)))
+	}
+	// constant series, but the deviation is too small
+	// (while the zscore is infinite)
+	assert.Nil(t, d.observe(testMetric(name, "GPU-0", 10, 42)))
+
+	assert.NotNil(t, d.observe(testMetric(name, "GPU-0", 11, 50)))
+}
+
+func TestDetectorRate(t *testing.T) {
+	const name = "link_downed"
+	d := newDetector(Config{
+		Series:     []Series{{Name: name, Rate: true}},
+		MinSamples: 5,
+	})
+
+	// the counter stays the same
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, d.observe(testMetric(name, "mlx5_0_1", i, 3)))
+	}
+
+	a := d.observe(testMetric(name, "mlx5_0_1", 10, 9))
+	require.NotNil(t, a)
+	assert.InDelta(t, 0.1, a.Value, 1e-9) // 6 per minute
+	assert.Zero(t, a.Baseline)
+
+	// counter reset is not an anomaly
+	d.cfg.Cooldown.Duration = time.Nanosecond
+	assert.Nil(t, d.observe(testMetric(name, "mlx5_0_1", 11, 0)))
+}
+
+func TestSeriesKey(t *testing.T) {
+	a := pkgmetrics.Metric{Component: "c", Name: "m", Labels: map[string]string{"b": "2", "a": "1"}}
+	b := pkgmetrics.Metric{Component: "c", Name: "m", Labels: map[string]string{"a": "1", "b": "2"}}
+	assert.Equal(t, seriesKey(a), seriesKey(b))
+	assert.Equal(t, "c/m,a=1,b=2", seriesKey(a))
+	assert.Equal(t, "c/m", seriesKey(pkgmetrics.Metric{Component: "c", Name: "m"}))
+}
@@ -0,0 +1,36 @@
+package metricsanomaly
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem used by the metrics anomaly detection.
+const SubSystem = "metrics_anomaly"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricDetectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "detected_total",
+			Help:      "tracks the number of the anomalies detected against the baseline of each metric series",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "metric"}, // label is the tracked metric name
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricDetectedTotal,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_detected_total", Type: apiv1.MetricTypeCounter, Unit: apiv1.MetricUnitCount},
+	)
+}
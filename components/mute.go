@@ -0,0 +1,129 @@
+package components
+
+import (
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// MutedExtraInfoKey is the health state extra info key
+	// set to "true" if the unhealthy component was muted.
+	MutedExtraInfoKey = "muted"
+	// MutedUntilExtraInfoKey is the health state extra info key
+	// for when the mute of the component expires, in RFC3339.
+	MutedUntilExtraInfoKey = "muted_until"
+)
+
+// Mutes looks up the component mutes.
+type Mutes interface {
+	// MutedUntil returns when the mute of the component expires,
+	// and false if the component is not muted now.
+	MutedUntil(component string) (time.Time, bool)
+}
+
+// IsMuted returns true if the extra info is tagged as muted.
+func IsMuted(extraInfo map[string]string) bool {
+	return extraInfo[MutedExtraInfoKey] == "true"
+}
+
+// WithMute wraps the initialization function so that the initialized component
+// tags its non-healthy health states while the component is muted.
+// The checks keep running and their data keep recorded as is.
+// It returns the original initialization function if the mutes are nil.
+func WithMute(initFunc InitFunc, mutes Mutes) InitFunc {
+	if mutes == nil {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newMuteComponent(c, mutes), nil
+	}
+}
+
+func newMuteComponent(c Component, mutes Mutes) Component {
+	mc := &muteComponent{
+		Component: c,
+		mutes:     mutes,
+	}
+	return wrapComponent(mc, c, nil)
+}
+
+var _ Component = &muteComponent{}
+
+// muteComponent wraps a component to tag its non-healthy health states while muted.
+type muteComponent struct {
+	Component
+
+	mutes Mutes
+}
+
+func (c *muteComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+
+	states, tagged := c.tagHealthStates(cr.HealthStates())
+	if !tagged {
+		return cr
+	}
+	return wrapCheckResult(&muteCheckResult{CheckResult: cr, states: states}, cr)
+}
+
+func (c *muteComponent) LastHealthStates() apiv1.HealthStates {
+	states, _ := c.tagHealthStates(c.Component.LastHealthStates())
+	return states
+}
+
+// tagHealthStates returns a copy of the health states with the non-healthy ones tagged as muted,
+// and true if the component is muted with any non-healthy health state.
+func (c *muteComponent) tagHealthStates(states apiv1.HealthStates) (apiv1.HealthStates, bool) {
+	if len(states) == 0 {
+		return states, false
+	}
+	until, ok := c.mutes.MutedUntil(c.Name())
+	if !ok {
+		return states, false
+	}
+
+	tagged := false
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		if s.Health != apiv1.HealthStateTypeHealthy && s.Health != apiv1.HealthStateTypeInitializing {
+			s.ExtraInfo = tagMuted(s.ExtraInfo, until)
+			tagged = true
+		}
+		copied = append(copied, s)
+	}
+	if !tagged {
+		return states, false
+	}
+	return copied, true
+}
+
+// tagMuted returns a copy of the extra info with the mute tags.
+func tagMuted(extraInfo map[string]string, until time.Time) map[string]string {
+	copied := make(map[string]string, len(extraInfo)+2)
+	for k, v := range extraInfo {
+		copied[k] = v
+	}
+	copied[MutedExtraInfoKey] = "true"
+	copied[MutedUntilExtraInfoKey] = until.UTC().Format(time.RFC3339)
+	return copied
+}
+
+var _ CheckResult = &muteCheckResult{}
+
+// muteCheckResult overrides the health states of the underlying check result.
+type muteCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *muteCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
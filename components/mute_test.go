@@ -0,0 +1,75 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// fixedMutes mutes the component "scripted" if until is set.
+type fixedMutes struct {
+	until time.Time
+}
+
+func (m *fixedMutes) MutedUntil(component string) (time.Time, bool) {
+	if component != "scripted" || m.until.IsZero() {
+		return time.Time{}, false
+	}
+	return m.until, true
+}
+
+func TestWithMuteNil(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithMute(initFunc, nil)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestMuteComponent(t *testing.T) {
+	until := time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)
+	mutes := &fixedMutes{until: until}
+
+	inner := &scriptedComponent{
+		script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeUnhealthy},
+	}
+	c := newMuteComponent(inner, mutes)
+
+	// healthy states are not tagged
+	cr := c.Check()
+	assert.False(t, IsMuted(cr.HealthStates()[0].ExtraInfo))
+
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	require.Len(t, cr.HealthStates(), 1)
+	assert.True(t, IsMuted(cr.HealthStates()[0].ExtraInfo))
+	assert.Equal(t, "2025-01-01T04:00:00Z", cr.HealthStates()[0].ExtraInfo[MutedUntilExtraInfoKey])
+	assert.True(t, IsMuted(c.LastHealthStates()[0].ExtraInfo))
+	// the underlying health states are not modified
+	assert.Nil(t, inner.LastHealthStates()[0].ExtraInfo)
+
+	// unmuted
+	mutes.until = time.Time{}
+	cr = c.Check()
+	assert.False(t, IsMuted(cr.HealthStates()[0].ExtraInfo))
+	assert.False(t, IsMuted(c.LastHealthStates()[0].ExtraInfo))
+}
+
+func TestMuteComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newMuteComponent(inner, &fixedMutes{})
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newMuteComponent(&scriptedComponent{}, &fixedMutes{}).(HealthSettable)
+	assert.False(t, ok)
+}
@@ -15,6 +15,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/netutil/latency"
 	latencyedge "github.com/leptonai/gpud/pkg/netutil/latency/edge"
 )
@@ -397,3 +398,7 @@ func TestComponentCheckWithDisabledThreshold(t *testing.T) {
 	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.health)
 	assert.Contains(t, cr.reason, "no issue found")
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -3,6 +3,7 @@ package latency
 import (
 	"github.com/prometheus/client_golang/prometheus"
 
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
 
@@ -27,4 +28,7 @@ var (
 
 func init() {
 	pkgmetrics.MustRegister(metricEdgeInMilliseconds)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_edge_in_milliseconds", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitMilliseconds},
+	)
 }
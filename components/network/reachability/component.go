@@ -0,0 +1,349 @@
+// Package reachability self-checks the network configuration GPUd depends on:
+// its own listen port is reachable on the configured address, and the outbound
+// endpoints (e.g., the control plane, the update server) are reachable, with the
+// DNS, connect, proxy, and TLS failures reported as the distinct reasons,
+// as the "agent shows offline" issues are mostly the host network misconfigurations.
+package reachability
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+// Name is the ID of the network reachability component.
+const Name = "network-reachability"
+
+// DefaultUpdateServerURL is the update server GPUd downloads the releases from.
+const DefaultUpdateServerURL = "https://pkg.gpud.dev/"
+
+const (
+	targetListen       = "listen"
+	targetControlPlane = "control-plane"
+	targetUpdateServer = "update-server"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	listenAddress string
+
+	getConfigFunc    func() Config
+	readEndpointFunc func(ctx context.Context) (string, error)
+	newProberFunc    func(timeout time.Duration) *prober
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the network reachability component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		listenAddress: gpudInstance.ListenAddress,
+
+		getConfigFunc: GetDefaultConfig,
+		readEndpointFunc: func(ctx context.Context) (string, error) {
+			return readEndpoint(ctx, gpudInstance.DBRO)
+		},
+		newProberFunc: newProber,
+	}
+	return c, nil
+}
+
+// readEndpoint reads the control plane endpoint the machine logged in to,
+// empty if not logged in.
+func readEndpoint(ctx context.Context, dbRO *sql.DB) (string, error) {
+	if dbRO == nil {
+		return "", nil
+	}
+	return pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyEndpoint)
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"network",
+		Name,
+	}
+}
+
+func (c *component) IsSupported() bool {
+	return true
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), 5*time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking network reachability")
+
+	cr := &checkResult{
+		ts: time.Now().UTC(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cfg := c.getConfigFunc()
+	p := c.newProberFunc(cfg.timeout())
+
+	if addr := listenProbeAddress(c.listenAddress); addr != "" {
+		cr.Targets = append(cr.Targets, p.probeListen(c.ctx, addr))
+	}
+
+	for _, t := range c.outboundTargets(cfg) {
+		cr.Targets = append(cr.Targets, p.probeEndpoint(c.ctx, t.name, t.endpoint))
+	}
+
+	metricReachable.Reset()
+	var issues []string
+	for _, t := range cr.Targets {
+		labels := prometheus.Labels{"target": t.Name, "address": t.Address}
+		if t.Reachable {
+			metricReachable.With(labels).Set(1)
+			continue
+		}
+		metricReachable.With(labels).Set(0)
+
+		issues = append(issues, formatFailure(t))
+		log.Logger.Warnw("target unreachable", "target", t.Name, "address", t.Address, "proxy", t.Proxy, "failure", t.Failure, "error", t.Error)
+	}
+
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = strings.Join(issues, "; ")
+		return cr
+	}
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("checked %d target(s), all reachable", len(cr.Targets))
+	return cr
+}
+
+type outboundTarget struct {
+	name     string
+	endpoint endpoint
+}
+
+// outboundTargets returns the control plane (if logged in), the update server
+// (unless skipped), and the configured endpoints.
+func (c *component) outboundTargets(cfg Config) []outboundTarget {
+	var targets []outboundTarget
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 10*time.Second)
+	controlPlane, err := c.readEndpointFunc(cctx)
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to read control plane endpoint", "error", err)
+	}
+	if controlPlane != "" {
+		if ep, err := parseEndpoint(controlPlane); err == nil {
+			targets = append(targets, outboundTarget{name: targetControlPlane, endpoint: ep})
+		} else {
+			log.Logger.Warnw("failed to parse control plane endpoint", "endpoint", controlPlane, "error", err)
+		}
+	}
+
+	if !cfg.SkipUpdateServer {
+		if ep, err := parseEndpoint(DefaultUpdateServerURL); err == nil {
+			targets = append(targets, outboundTarget{name: targetUpdateServer, endpoint: ep})
+		}
+	}
+
+	for _, raw := range cfg.Endpoints {
+		// validated when the config is set
+		if ep, err := parseEndpoint(raw); err == nil {
+			targets = append(targets, outboundTarget{name: raw, endpoint: ep})
+		}
+	}
+	return targets
+}
+
+// listenProbeAddress returns the address to self-connect to the listen address,
+// the loopback address if listening on all the addresses, or empty if unknown.
+func listenProbeAddress(listenAddress string) string {
+	if listenAddress == "" {
+		return ""
+	}
+	host, port, err := net.SplitHostPort(listenAddress)
+	if err != nil || port == "" {
+		return ""
+	}
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// formatFailure describes the unreachable target, for example,
+// "control-plane (gpud-gateway.example.com:443) DNS resolution failed: ...".
+func formatFailure(t Target) string {
+	what := fmt.Sprintf("%s (%s)", t.Name, t.Address)
+	if t.Name == targetListen {
+		what = fmt.Sprintf("gpud listen port (%s)", t.Address)
+	}
+
+	var failure string
+	switch t.Failure {
+	case FailureDNS:
+		failure = "DNS resolution failed"
+	case FailureConnect:
+		failure = "connection failed (check the firewall rules)"
+	case FailureProxy:
+		failure = fmt.Sprintf("unreachable through proxy %s", t.Proxy)
+	case FailureTLS:
+		failure = "TLS handshake failed (check the intercepting proxy and the CA certificates)"
+	default:
+		failure = "unreachable"
+	}
+	return fmt.Sprintf("%s %s: %s", what, failure, t.Error)
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	// Targets is the reachability of the listen address and the outbound endpoints.
+	Targets []Target `json:"targets,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Targets) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Target", "Address", "Proxy", "Reachable", "Failure", "Latency"})
+	for _, t := range cr.Targets {
+		table.Append([]string{
+			t.Name,
+			t.Address,
+			t.Proxy,
+			fmt.Sprintf("%t", t.Reachable),
+			string(t.Failure),
+			fmt.Sprintf("%dms", t.LatencyMilliseconds),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.Targets) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
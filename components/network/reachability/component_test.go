@@ -0,0 +1,185 @@
+package reachability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+)
+
+// newDirectProber creates a prober connecting directly, ignoring the proxy
+// environment variables of the test host.
+func newDirectProber(timeout time.Duration) *prober {
+	p := newProber(timeout)
+	p.proxyFunc = func(*http.Request) (*url.URL, error) { return nil, nil }
+	return p
+}
+
+func TestComponentBasics(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(&components.GPUdInstance{
+		RootCtx: context.Background(),
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = c.Close()
+	}()
+
+	assert.Equal(t, Name, c.Name())
+	assert.Equal(t, []string{"network", Name}, c.Tags())
+	assert.True(t, c.IsSupported())
+
+	evs, err := c.Events(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.Equal(t, "no data yet", states[0].Reason)
+}
+
+func TestListenProbeAddress(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", listenProbeAddress(""))
+	assert.Equal(t, "", listenProbeAddress("invalid"))
+	assert.Equal(t, "127.0.0.1:15132", listenProbeAddress(":15132"))
+	assert.Equal(t, "127.0.0.1:15132", listenProbeAddress("0.0.0.0:15132"))
+	assert.Equal(t, "[::1]:15132", listenProbeAddress("[::]:15132"))
+	assert.Equal(t, "10.0.0.1:15132", listenProbeAddress("10.0.0.1:15132"))
+}
+
+func TestCheckHealthy(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		listenAddress: ln.Addr().String(),
+		getConfigFunc: func() Config { return Config{SkipUpdateServer: true} },
+		readEndpointFunc: func(context.Context) (string, error) {
+			return "", nil
+		},
+		newProberFunc: newDirectProber,
+	}
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	require.Len(t, cr.Targets, 1)
+	assert.Equal(t, targetListen, cr.Targets[0].Name)
+	assert.True(t, cr.Targets[0].Reachable)
+	assert.Contains(t, cr.String(), targetListen)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Len(t, decoded.Targets, 1)
+}
+
+func TestCheckDegraded(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		listenAddress: closed,
+		getConfigFunc: func() Config {
+			return Config{Endpoints: []string{"https://internal.invalid"}}
+		},
+		readEndpointFunc: func(context.Context) (string, error) {
+			return "https://control-plane.invalid", nil
+		},
+		newProberFunc: func(timeout time.Duration) *prober {
+			p := newDirectProber(timeout)
+			p.lookupHostFunc = func(context.Context, string) ([]string, error) {
+				return nil, errors.New("no such host")
+			}
+			return p
+		},
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+
+	names := make([]string, 0, len(cr.Targets))
+	for _, tgt := range cr.Targets {
+		names = append(names, tgt.Name)
+		assert.False(t, tgt.Reachable)
+	}
+	assert.Equal(t, []string{targetListen, targetControlPlane, targetUpdateServer, "https://internal.invalid"}, names)
+
+	assert.Contains(t, cr.Summary(), "gpud listen port ("+closed+") connection failed")
+	assert.Contains(t, cr.Summary(), "control-plane (control-plane.invalid:443) DNS resolution failed")
+	assert.Contains(t, cr.Summary(), "update-server (pkg.gpud.dev:443) DNS resolution failed")
+}
+
+func TestCheckReadEndpointError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &component{
+		ctx:           ctx,
+		cancel:        cancel,
+		getConfigFunc: func() Config { return Config{SkipUpdateServer: true} },
+		readEndpointFunc: func(context.Context) (string, error) {
+			return "", errors.New("db error")
+		},
+		newProberFunc: newDirectProber,
+	}
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Empty(t, cr.Targets)
+	assert.Equal(t, "no data", cr.String())
+}
+
+func TestFormatFailure(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		"example (example.com:443) unreachable through proxy http://proxy:3128: EOF",
+		formatFailure(Target{Name: "example", Address: "example.com:443", Proxy: "http://proxy:3128", Failure: FailureProxy, Error: "EOF"}),
+	)
+	assert.Contains(t,
+		formatFailure(Target{Name: "example", Address: "example.com:443", Failure: FailureTLS, Error: "x509"}),
+		"TLS handshake failed",
+	)
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
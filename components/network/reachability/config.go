@@ -0,0 +1,70 @@
+package reachability
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultTimeout is the default timeout of each reachability probe.
+const DefaultTimeout = 10 * time.Second
+
+// Config configures the reachability self-check.
+type Config struct {
+	// Endpoints are the additional outbound endpoints to check
+	// (e.g., "https://registry.internal"), along with the control plane
+	// and the update server. The port defaults to 443 for "https" and 80 for "http".
+	Endpoints []string `json:"endpoints,omitempty"`
+	// SkipUpdateServer skips the update server check
+	// (e.g., the air-gapped hosts with the auto update disabled).
+	SkipUpdateServer bool `json:"skip_update_server,omitempty"`
+	// Timeout is the timeout of each probe.
+	// Defaults to DefaultTimeout if zero.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout must be non-negative, got %s", cfg.Timeout.Duration)
+	}
+	for _, ep := range cfg.Endpoints {
+		if _, err := parseEndpoint(ep); err != nil {
+			return fmt.Errorf("invalid endpoint %q: %w", ep, err)
+		}
+	}
+	return nil
+}
+
+func (cfg Config) timeout() time.Duration {
+	if cfg.Timeout.Duration > 0 {
+		return cfg.Timeout.Duration
+	}
+	return DefaultTimeout
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default reachability config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default reachability config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default network reachability config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
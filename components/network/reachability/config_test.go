@@ -0,0 +1,51 @@
+package reachability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "empty", cfg: Config{}},
+		{name: "valid endpoints", cfg: Config{Endpoints: []string{"https://example.com", "example.com:8443", "http://10.0.0.1"}}},
+		{name: "negative timeout", cfg: Config{Timeout: metav1.Duration{Duration: -time.Second}}, wantErr: true},
+		{name: "empty endpoint", cfg: Config{Endpoints: []string{""}}, wantErr: true},
+		{name: "unsupported scheme", cfg: Config{Endpoints: []string{"ftp://example.com"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultTimeout, Config{}.timeout())
+	assert.Equal(t, 3*time.Second, Config{Timeout: metav1.Duration{Duration: 3 * time.Second}}.timeout())
+}
+
+func TestSetDefaultConfig(t *testing.T) {
+	orig := GetDefaultConfig()
+	defer SetDefaultConfig(orig)
+
+	cfg := Config{Endpoints: []string{"https://example.com"}, SkipUpdateServer: true}
+	SetDefaultConfig(cfg)
+	assert.Equal(t, cfg, GetDefaultConfig())
+}
@@ -0,0 +1,36 @@
+package reachability
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the network reachability component.
+const SubSystem = "network_reachability"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricReachable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "reachable",
+			Help:      "tracks whether the target (the listen address, the control plane, or the outbound endpoint) is reachable",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "target", "address"}, // label is name of the component
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricReachable,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_reachable", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
+}
@@ -0,0 +1,263 @@
+package reachability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FailureType is the reason a target is unreachable,
+// to tell apart the network configuration problems.
+type FailureType string
+
+const (
+	// FailureDNS means the target host name does not resolve.
+	FailureDNS FailureType = "dns"
+	// FailureConnect means the TCP connection to the target failed
+	// (e.g., refused, or dropped by a firewall).
+	FailureConnect FailureType = "connect"
+	// FailureProxy means the target is unreachable through the HTTP(S) proxy
+	// (e.g., the proxy does not resolve, refuses, or rejects the tunnel).
+	FailureProxy FailureType = "proxy"
+	// FailureTLS means the TLS handshake with the target failed
+	// (e.g., an untrusted certificate of an intercepting proxy).
+	FailureTLS FailureType = "tls"
+)
+
+// Target is the reachability of a target.
+type Target struct {
+	// Name describes the target (e.g., "listen", "control-plane", "update-server").
+	Name string `json:"name"`
+	// Address is the host and port of the target.
+	Address string `json:"address"`
+	// Proxy is the proxy the target is reached through, empty if direct.
+	Proxy string `json:"proxy,omitempty"`
+	// Reachable is true if the target is reachable.
+	Reachable bool `json:"reachable"`
+	// Failure is the failure type of the unreachable target.
+	Failure FailureType `json:"failure,omitempty"`
+	// Error is the error of the unreachable target.
+	Error string `json:"error,omitempty"`
+	// LatencyMilliseconds is the duration of the probe in milliseconds.
+	LatencyMilliseconds int64 `json:"latency_milliseconds"`
+}
+
+// endpoint is a parsed outbound endpoint.
+type endpoint struct {
+	url  *url.URL
+	host string
+	port string
+}
+
+func (e endpoint) address() string {
+	return net.JoinHostPort(e.host, e.port)
+}
+
+// parseEndpoint parses the endpoint URL, or the host (and port) with the "https" scheme.
+func parseEndpoint(raw string) (endpoint, error) {
+	if raw == "" {
+		return endpoint{}, errors.New("empty endpoint")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return endpoint{}, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return endpoint{}, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return endpoint{}, errors.New("empty host")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return endpoint{url: u, host: u.Hostname(), port: port}, nil
+}
+
+// prober probes the reachability of the targets.
+type prober struct {
+	timeout time.Duration
+
+	lookupHostFunc func(ctx context.Context, host string) ([]string, error)
+	dialFunc       func(ctx context.Context, network, address string) (net.Conn, error)
+	proxyFunc      func(*http.Request) (*url.URL, error)
+	tlsConfig      *tls.Config
+}
+
+func newProber(timeout time.Duration) *prober {
+	dialer := &net.Dialer{}
+	return &prober{
+		timeout:        timeout,
+		lookupHostFunc: net.DefaultResolver.LookupHost,
+		dialFunc:       dialer.DialContext,
+		proxyFunc:      http.ProxyFromEnvironment,
+	}
+}
+
+// probeListen connects to the GPUd listen address.
+func (p *prober) probeListen(ctx context.Context, address string) Target {
+	t := Target{Name: targetListen, Address: address}
+	start := time.Now()
+	defer func() {
+		t.LatencyMilliseconds = time.Since(start).Milliseconds()
+	}()
+
+	cctx, ccancel := context.WithTimeout(ctx, p.timeout)
+	defer ccancel()
+
+	conn, err := p.dialFunc(cctx, "tcp", address)
+	if err != nil {
+		t.Failure, t.Error = FailureConnect, err.Error()
+		return t
+	}
+	_ = conn.Close()
+
+	t.Reachable = true
+	return t
+}
+
+// probeEndpoint checks the outbound endpoint is reachable,
+// through the proxy if configured in the environment (e.g., "HTTPS_PROXY").
+func (p *prober) probeEndpoint(ctx context.Context, name string, ep endpoint) (t Target) {
+	t = Target{Name: name, Address: ep.address()}
+	start := time.Now()
+	defer func() {
+		t.LatencyMilliseconds = time.Since(start).Milliseconds()
+	}()
+
+	cctx, ccancel := context.WithTimeout(ctx, p.timeout)
+	defer ccancel()
+
+	proxyURL, err := p.proxyFunc(&http.Request{URL: ep.url})
+	if err != nil {
+		t.Failure, t.Error = FailureProxy, err.Error()
+		return t
+	}
+	if proxyURL != nil {
+		t.Proxy = proxyURL.Redacted()
+		t.Failure, err = p.probeThroughProxy(cctx, ep, proxyURL)
+	} else {
+		t.Failure, err = p.probeDirect(cctx, ep)
+	}
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+
+	t.Reachable = true
+	return t
+}
+
+// probeDirect resolves, connects, and (if "https") handshakes with the endpoint,
+// and returns the failure type of the first step failed.
+func (p *prober) probeDirect(ctx context.Context, ep endpoint) (FailureType, error) {
+	addr := ep.host
+	if net.ParseIP(ep.host) == nil {
+		addrs, err := p.lookupHostFunc(ctx, ep.host)
+		if err != nil {
+			return FailureDNS, err
+		}
+		if len(addrs) == 0 {
+			return FailureDNS, fmt.Errorf("no address found for %q", ep.host)
+		}
+		addr = addrs[0]
+	}
+
+	conn, err := p.dialFunc(ctx, "tcp", net.JoinHostPort(addr, ep.port))
+	if err != nil {
+		return FailureConnect, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if ep.url.Scheme != "https" {
+		return "", nil
+	}
+	tlsConn := tls.Client(conn, p.tlsClientConfig(ep.host))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return FailureTLS, err
+	}
+	return "", nil
+}
+
+// probeThroughProxy sends a request to the endpoint through the proxy,
+// where any response status means the endpoint is reachable.
+func (p *prober) probeThroughProxy(ctx context.Context, ep endpoint, proxyURL *url.URL) (FailureType, error) {
+	if net.ParseIP(proxyURL.Hostname()) == nil {
+		if _, err := p.lookupHostFunc(ctx, proxyURL.Hostname()); err != nil {
+			return FailureProxy, fmt.Errorf("failed to resolve proxy: %w", err)
+		}
+	}
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			DialContext:     p.dialFunc,
+			TLSClientConfig: p.tlsClientConfig(ep.host),
+		},
+		// the redirects are not followed, as any response means reachable
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer cli.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, ep.url.Scheme+"://"+ep.address()+"/", nil)
+	if err != nil {
+		return FailureProxy, err
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		if isTLSError(err) {
+			return FailureTLS, err
+		}
+		return FailureProxy, err
+	}
+	_ = resp.Body.Close()
+	return "", nil
+}
+
+func (p *prober) tlsClientConfig(serverName string) *tls.Config {
+	if p.tlsConfig != nil {
+		cfg := p.tlsConfig.Clone()
+		cfg.ServerName = serverName
+		return cfg
+	}
+	return &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+}
+
+// isTLSError returns true if the error is from the TLS handshake or the certificate verification.
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		unknownAuth  x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		certInvalid  x509.CertificateInvalidError
+		alertErr     tls.AlertError
+		tlsPrefixErr = strings.Contains(err.Error(), "tls: ")
+	)
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &unknownAuth) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &certInvalid) ||
+		errors.As(err, &alertErr) ||
+		tlsPrefixErr
+}
@@ -0,0 +1,193 @@
+package reachability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw      string
+		wantAddr string
+		wantErr  bool
+	}{
+		{raw: "https://example.com", wantAddr: "example.com:443"},
+		{raw: "https://example.com:8443/path", wantAddr: "example.com:8443"},
+		{raw: "http://example.com", wantAddr: "example.com:80"},
+		{raw: "example.com", wantAddr: "example.com:443"},
+		{raw: "10.0.0.1:15132", wantAddr: "10.0.0.1:15132"},
+		{raw: "", wantErr: true},
+		{raw: "ftp://example.com", wantErr: true},
+		{raw: "https://", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			ep, err := parseEndpoint(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAddr, ep.address())
+		})
+	}
+}
+
+func newTestProber() *prober {
+	p := newProber(5 * time.Second)
+	p.proxyFunc = func(*http.Request) (*url.URL, error) { return nil, nil }
+	return p
+}
+
+// closedAddress returns the address no one listens on.
+func closedAddress(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return addr
+}
+
+func TestProbeListen(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	p := newTestProber()
+	tgt := p.probeListen(context.Background(), ln.Addr().String())
+	assert.True(t, tgt.Reachable)
+	assert.Equal(t, targetListen, tgt.Name)
+	assert.Empty(t, tgt.Failure)
+
+	tgt = p.probeListen(context.Background(), closedAddress(t))
+	assert.False(t, tgt.Reachable)
+	assert.Equal(t, FailureConnect, tgt.Failure)
+	assert.NotEmpty(t, tgt.Error)
+}
+
+func TestProbeEndpointDirect(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	ep, err := parseEndpoint(srv.URL)
+	require.NoError(t, err)
+
+	t.Run("reachable", func(t *testing.T) {
+		p := newTestProber()
+		p.tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.True(t, tgt.Reachable, tgt.Error)
+	})
+
+	t.Run("tls failure", func(t *testing.T) {
+		p := newTestProber()
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureTLS, tgt.Failure)
+	})
+
+	t.Run("dns failure", func(t *testing.T) {
+		p := newTestProber()
+		p.lookupHostFunc = func(context.Context, string) ([]string, error) {
+			return nil, &net.DNSError{Err: "no such host", Name: "nonexistent.invalid", IsNotFound: true}
+		}
+		ep, err := parseEndpoint("https://nonexistent.invalid")
+		require.NoError(t, err)
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureDNS, tgt.Failure)
+		assert.Equal(t, "nonexistent.invalid:443", tgt.Address)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		p := newTestProber()
+		ep, err := parseEndpoint("https://" + closedAddress(t))
+		require.NoError(t, err)
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureConnect, tgt.Failure)
+	})
+}
+
+func TestProbeEndpointProxy(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// the plain HTTP test server acts as the forward proxy
+	proxyURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	ep, err := parseEndpoint("http://example.com")
+	require.NoError(t, err)
+
+	t.Run("reachable", func(t *testing.T) {
+		p := newTestProber()
+		p.proxyFunc = http.ProxyURL(proxyURL)
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.True(t, tgt.Reachable, tgt.Error)
+		assert.Equal(t, proxyURL.String(), tgt.Proxy)
+	})
+
+	t.Run("proxy unreachable", func(t *testing.T) {
+		closed, err := url.Parse("http://" + closedAddress(t))
+		require.NoError(t, err)
+
+		p := newTestProber()
+		p.proxyFunc = http.ProxyURL(closed)
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureProxy, tgt.Failure)
+	})
+
+	t.Run("proxy dns failure", func(t *testing.T) {
+		p := newTestProber()
+		p.proxyFunc = http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy.invalid:3128"})
+		p.lookupHostFunc = func(context.Context, string) ([]string, error) {
+			return nil, errors.New("no such host")
+		}
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureProxy, tgt.Failure)
+		assert.Contains(t, tgt.Error, "failed to resolve proxy")
+	})
+
+	t.Run("proxy config error", func(t *testing.T) {
+		p := newTestProber()
+		p.proxyFunc = func(*http.Request) (*url.URL, error) { return nil, errors.New("invalid proxy") }
+		tgt := p.probeEndpoint(context.Background(), "test", ep)
+		assert.False(t, tgt.Reachable)
+		assert.Equal(t, FailureProxy, tgt.Failure)
+	})
+}
+
+func TestIsTLSError(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isTLSError(x509.UnknownAuthorityError{}))
+	assert.True(t, isTLSError(errors.New("tls: handshake failure")))
+	assert.False(t, isTLSError(errors.New("connection refused")))
+}
@@ -1,4 +1,5 @@
-// Package nfs writes to and reads from the specified NFS mount points.
+// Package nfs writes to and reads from the specified NFS mount points,
+// and detects the hung mounts and the RPC timeouts, retransmits, and slow round trips.
 package nfs
 
 import (
@@ -26,6 +27,8 @@ const Name = "nfs"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -42,6 +45,11 @@ type component struct {
 	checkChecker          func(ctx context.Context, checker pkgnfschecker.Checker) pkgnfschecker.CheckResult
 	cleanChecker          func(checker pkgnfschecker.Checker) error
 
+	// probeHang returns the duration the volume path has been hung for, nil to skip
+	probeHang func(path string) time.Duration
+	// sampleRPCStats samples the RPC statistics of the mount of the volume path, nil to skip
+	sampleRPCStats func(path string) (string, pkgnfschecker.RPCStats, bool, error)
+
 	lastMu          sync.RWMutex
 	lastCheckResult *checkResult
 }
@@ -73,6 +81,9 @@ func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
 		cleanChecker: func(checker pkgnfschecker.Checker) error {
 			return checker.Clean()
 		},
+
+		probeHang:      pkgnfschecker.NewHangDetector(pkgnfschecker.DefaultHangTimeout).Probe,
+		sampleRPCStats: pkgnfschecker.NewRPCSampler(pkgnfschecker.DefaultMountStatsPath).Sample,
 	}
 
 	return c, nil
@@ -91,20 +102,7 @@ func (c *component) IsSupported() bool {
 }
 
 func (c *component) Start() error {
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-c.ctx.Done():
-				return
-			case <-ticker.C:
-			}
-
-			_ = c.Check()
-		}
-	}()
+	c.CheckLoop.StartAfterInterval(c.ctx, c.Name(), time.Minute, c.Check)
 	return nil
 }
 
@@ -148,6 +146,29 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
+	// probe the hung mounts first, as the other checks would time out on them
+	// while leaving the blocked goroutines behind on every check
+	if c.probeHang != nil {
+		for _, groupConfig := range groupConfigs {
+			hung := c.probeHang(groupConfig.VolumePath)
+			if hung == 0 {
+				continue
+			}
+			cr.err = fmt.Errorf("stat %s not returned for %s", groupConfig.VolumePath, hung.Truncate(time.Second))
+			cr.health = apiv1.HealthStateTypeDegraded
+			cr.reason = "NFS mount hung for " + groupConfig.VolumePath + " - server may be unresponsive"
+			cr.NFSCheckResults = append(cr.NFSCheckResults, pkgnfschecker.CheckResult{
+				Dir:          groupConfig.VolumePath,
+				Message:      "hung",
+				Error:        cr.err.Error(),
+				TimeoutError: true,
+				FailureMode:  pkgnfschecker.FailureModeHung,
+			})
+			log.Logger.Warnw(cr.reason, "health", cr.health, "error", cr.err)
+			return cr
+		}
+	}
+
 	memberConfigs := groupConfigs.GetMemberConfigs(c.machineID)
 	timeoutCtx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	err := c.validateMemberConfigs(timeoutCtx, memberConfigs)
@@ -252,6 +273,25 @@ func (c *component) Check() components.CheckResult {
 			return cr
 		}
 
+		if c.sampleRPCStats != nil {
+			mountPoint, stats, ok, err := c.sampleRPCStats(memberConfig.VolumePath)
+			if err != nil {
+				log.Logger.Warnw("failed to sample nfs rpc stats", "volume_path", memberConfig.VolumePath, "error", err)
+			}
+			nfsResult.MountPoint = mountPoint
+			if ok {
+				nfsResult.RPCStats = &stats
+				if mode, reason := stats.Evaluate(); mode != "" {
+					nfsResult.FailureMode = mode
+					cr.NFSCheckResults = append(cr.NFSCheckResults, nfsResult)
+					cr.health = apiv1.HealthStateTypeDegraded
+					cr.reason = "NFS " + reason + " for " + mountPoint
+					log.Logger.Warnw(cr.reason, "health", cr.health, "failure_mode", mode)
+					return cr
+				}
+			}
+		}
+
 		cr.NFSCheckResults = append(cr.NFSCheckResults, nfsResult)
 		msg = append(msg, nfsResult.Message)
 	}
@@ -295,9 +335,14 @@ func (cr *checkResult) String() string {
 	buf := bytes.NewBuffer(nil)
 	table := tablewriter.NewWriter(buf)
 	table.SetAlignment(tablewriter.ALIGN_CENTER)
-	table.SetHeader([]string{"Directory", "Message"})
+	table.SetHeader([]string{"Directory", "Message", "Failure Mode", "RPC Retransmits", "RPC Avg RTT"})
 	for _, nfsResult := range cr.NFSCheckResults {
-		table.Append([]string{nfsResult.Dir, nfsResult.Message})
+		retransmits, avgRTT := "", ""
+		if nfsResult.RPCStats != nil {
+			retransmits = fmt.Sprintf("%d", nfsResult.RPCStats.Retransmits())
+			avgRTT = nfsResult.RPCStats.AvgRTT().String()
+		}
+		table.Append([]string{nfsResult.Dir, nfsResult.Message, string(nfsResult.FailureMode), retransmits, avgRTT})
 	}
 	table.Render()
 
@@ -1398,3 +1398,100 @@ func (m *mockChecker) Check(_ context.Context) pkgnfschecker.CheckResult {
 func (m *mockChecker) Clean() error {
 	return nil
 }
+
+// newSuccessfulTestComponent creates a component whose shared-file checks all pass on dir.
+func newSuccessfulTestComponent(dir string) *component {
+	return &component{
+		ctx:       context.Background(),
+		machineID: "test-machine",
+		getGroupConfigsFunc: func() pkgnfschecker.Configs {
+			return pkgnfschecker.Configs{
+				{
+					VolumePath:   dir,
+					DirName:      ".gpud-test",
+					FileContents: "test content",
+				},
+			}
+		},
+		findMntTargetDevice: func(_ string) (string, string, error) {
+			return "server:/export/path", "nfs", nil
+		},
+		isNFSFSType: func(_ string) bool {
+			return true
+		},
+		validateMemberConfigs: func(_ context.Context, _ pkgnfschecker.MemberConfigs) error {
+			return nil
+		},
+		newChecker: func(_ context.Context, _ *pkgnfschecker.MemberConfig) (pkgnfschecker.Checker, error) {
+			return &mockChecker{}, nil
+		},
+		writeChecker: func(_ context.Context, _ pkgnfschecker.Checker) error {
+			return nil
+		},
+		checkChecker: func(_ context.Context, _ pkgnfschecker.Checker) pkgnfschecker.CheckResult {
+			return pkgnfschecker.CheckResult{
+				Dir:     dir,
+				Message: "correctly read/wrote on " + dir,
+			}
+		},
+		cleanChecker: func(_ pkgnfschecker.Checker) error {
+			return nil
+		},
+	}
+}
+
+func TestCheckWithHungMount(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	c := newSuccessfulTestComponent(tmpDir)
+	c.probeHang = func(_ string) time.Duration {
+		return 2 * time.Minute
+	}
+	c.validateMemberConfigs = func(_ context.Context, _ pkgnfschecker.MemberConfigs) error {
+		return errors.New("should not be called")
+	}
+
+	result := c.Check()
+	cr := mustCheckResult(t, result)
+
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, result.HealthStateType())
+	assert.Equal(t, "NFS mount hung for "+tmpDir+" - server may be unresponsive", result.Summary())
+	require.Len(t, cr.NFSCheckResults, 1)
+	assert.Equal(t, pkgnfschecker.FailureModeHung, cr.NFSCheckResults[0].FailureMode)
+	assert.True(t, cr.NFSCheckResults[0].TimeoutError)
+	assert.Contains(t, cr.getError(), "not returned for 2m0s")
+}
+
+func TestCheckWithRPCStats(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	stats := pkgnfschecker.RPCStats{Ops: 1000, Transmissions: 1001, RTTMilliseconds: 5000}
+	c := newSuccessfulTestComponent(tmpDir)
+	c.probeHang = func(_ string) time.Duration {
+		return 0
+	}
+	c.sampleRPCStats = func(_ string) (string, pkgnfschecker.RPCStats, bool, error) {
+		return "/mnt/nfs", stats, true, nil
+	}
+
+	result := c.Check()
+	cr := mustCheckResult(t, result)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, result.HealthStateType())
+	require.Len(t, cr.NFSCheckResults, 1)
+	assert.Equal(t, "/mnt/nfs", cr.NFSCheckResults[0].MountPoint)
+	assert.Equal(t, &stats, cr.NFSCheckResults[0].RPCStats)
+	assert.Contains(t, cr.String(), "5ms")
+
+	stats = pkgnfschecker.RPCStats{Ops: 1000, Transmissions: 1200, RTTMilliseconds: 5000}
+	result = c.Check()
+	cr = mustCheckResult(t, result)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, result.HealthStateType())
+	assert.Equal(t, "NFS 200 RPC retransmit(s) for 1000 operation(s) (20.0%) for /mnt/nfs", result.Summary())
+	assert.Equal(t, pkgnfschecker.FailureModeRPCRetransmits, cr.NFSCheckResults[0].FailureMode)
+
+	stats = pkgnfschecker.RPCStats{Ops: 10, Transmissions: 12, MajorTimeouts: 2}
+	result = c.Check()
+	cr = mustCheckResult(t, result)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, result.HealthStateType())
+	assert.Equal(t, pkgnfschecker.FailureModeRPCTimeouts, cr.NFSCheckResults[0].FailureMode)
+}
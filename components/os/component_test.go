@@ -21,6 +21,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 	"github.com/leptonai/gpud/pkg/eventstore"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/process"
@@ -4598,3 +4599,7 @@ func (m *mockEventStore) Bucket(_ string, _ ...eventstore.OpOption) (eventstore.
 func (m *mockEventStore) Close() {
 	// No-op
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -28,6 +28,8 @@ const Name = "pci"
 var _ components.Component = &component{}
 
 type component struct {
+	components.CheckLoop
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -103,7 +105,7 @@ func (c *component) Start() error {
 				}
 			}
 
-			_ = c.Check()
+			_ = c.CheckLoop.RunCheck(c.Name(), c.Check)
 		}
 	}()
 	return nil
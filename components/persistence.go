@@ -0,0 +1,260 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// StaleExtraInfoKey is the health state extra info key set to "true"
+	// if the health states were restored from before the restart,
+	// and the component has not completed a check since.
+	StaleExtraInfoKey = "stale"
+	// StaleAgeExtraInfoKey is the health state extra info key for how long ago
+	// the restored health states were checked (e.g., "3m20s").
+	StaleAgeExtraInfoKey = "stale_age"
+
+	// DefaultHealthStatePersistInterval is the interval at which the persistence wrapper
+	// reads the latest health states of the underlying component to persist,
+	// so that the periodic checks are persisted even if no one reads the health states.
+	DefaultHealthStatePersistInterval = 30 * time.Second
+
+	// noDataYetReason is the reason of the health state reported by the components
+	// before the first check completes.
+	noDataYetReason = "no data yet"
+)
+
+// HealthStateStore persists the last health states per component.
+type HealthStateStore interface {
+	// SaveHealthStates replaces the last health states of the component.
+	SaveHealthStates(ctx context.Context, component string, states apiv1.HealthStates) error
+	// LoadHealthStates returns the last health states of the component, or nil if none.
+	LoadHealthStates(ctx context.Context, component string) (apiv1.HealthStates, error)
+}
+
+// HealthTransitionRecorder records the health transitions of the component health states.
+// The persistence wrapper records the health transitions if the health state store
+// also implements it.
+type HealthTransitionRecorder interface {
+	// RecordHealthTransition records the health transition of the component health state.
+	RecordHealthTransition(ctx context.Context, tr apiv1.HealthTransition) error
+}
+
+// IsStale returns true if the extra info is tagged as stale.
+func IsStale(extraInfo map[string]string) bool {
+	return extraInfo[StaleExtraInfoKey] == "true"
+}
+
+// WithPersistence wraps the initialization function so that the initialized component
+// persists its last health states, and reports the health states persisted before
+// the restart (tagged as stale) until its first check completes.
+// It returns the original initialization function if the store is nil.
+//
+// Setting the wrapped component healthy drops the health states restored from before the restart.
+func WithPersistence(initFunc InitFunc, store HealthStateStore) InitFunc {
+	if store == nil {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCtx := context.Background()
+		if gpudInstance != nil && gpudInstance.RootCtx != nil {
+			rootCtx = gpudInstance.RootCtx
+		}
+		return newPersistenceComponent(rootCtx, c, store, DefaultHealthStatePersistInterval), nil
+	}
+}
+
+func newPersistenceComponent(rootCtx context.Context, c Component, store HealthStateStore, persistInterval time.Duration) Component {
+	cctx, ccancel := context.WithCancel(rootCtx)
+	pc := &persistenceComponent{
+		Component:       c,
+		ctx:             cctx,
+		cancel:          ccancel,
+		store:           store,
+		persistInterval: persistInterval,
+		getTimeNowFunc:  func() time.Time { return time.Now().UTC() },
+	}
+
+	restored, err := store.LoadHealthStates(cctx, c.Name())
+	if err != nil {
+		log.Logger.Warnw("failed to load persisted health states", "component", c.Name(), "error", err)
+	}
+	pc.restored = restored
+	pc.previous = restored
+	pc.recorder, _ = store.(HealthTransitionRecorder)
+
+	return wrapComponent(pc, c, pc.clearRestored)
+}
+
+var _ Component = &persistenceComponent{}
+
+// persistenceComponent wraps a component to persist its health states.
+type persistenceComponent struct {
+	Component
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	store           HealthStateStore
+	recorder        HealthTransitionRecorder
+	persistInterval time.Duration
+	getTimeNowFunc  func() time.Time
+
+	mu sync.Mutex
+	// health states persisted before the restart,
+	// nil once the component completes a check
+	restored apiv1.HealthStates
+	// time of the last persisted health states
+	// to not persist the same check result twice
+	lastPersisted time.Time
+	// last persisted health states (or restored from before the restart)
+	// to record the health transitions
+	previous apiv1.HealthStates
+}
+
+func (c *persistenceComponent) Start() error {
+	if err := c.Component.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.persistInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_ = c.LastHealthStates()
+		}
+	}()
+	return nil
+}
+
+func (c *persistenceComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+	c.observe(cr.HealthStates())
+	return cr
+}
+
+func (c *persistenceComponent) LastHealthStates() apiv1.HealthStates {
+	return c.observe(c.Component.LastHealthStates())
+}
+
+func (c *persistenceComponent) Close() error {
+	c.cancel()
+	return c.Component.Close()
+}
+
+// observe persists the health states if they are from a new check,
+// and returns the health states to report.
+func (c *persistenceComponent) observe(states apiv1.HealthStates) apiv1.HealthStates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if isNoDataYet(states) {
+		if len(c.restored) == 0 {
+			return states
+		}
+		return tagStale(c.restored, c.getTimeNowFunc())
+	}
+	c.restored = nil
+
+	ts := latestHealthStateTime(states)
+	if ts.IsZero() || !ts.After(c.lastPersisted) {
+		return states
+	}
+	if err := c.store.SaveHealthStates(c.ctx, c.Name(), states); err != nil {
+		log.Logger.Warnw("failed to persist health states", "component", c.Name(), "error", err)
+		return states
+	}
+	c.lastPersisted = ts
+	c.recordTransitions(states)
+	return states
+}
+
+// clearRestored drops the health states restored from before the restart,
+// as the pre-restart failure must not be reported once set healthy.
+func (c *persistenceComponent) clearRestored() {
+	c.mu.Lock()
+	c.restored = nil
+	c.mu.Unlock()
+}
+
+// recordTransitions records the health transitions from the previous health states.
+func (c *persistenceComponent) recordTransitions(states apiv1.HealthStates) {
+	previous := c.previous
+	c.previous = states
+	if c.recorder == nil {
+		return
+	}
+
+	prevHealth := make(map[string]apiv1.HealthStateType, len(previous))
+	for _, s := range previous {
+		prevHealth[s.Name] = s.Health
+	}
+	for _, s := range states {
+		from, ok := prevHealth[s.Name]
+		if !ok || from == "" || s.Health == "" || from == s.Health {
+			continue
+		}
+		tr := apiv1.HealthTransition{
+			Time:      s.Time,
+			Component: c.Name(),
+			Name:      s.Name,
+			From:      from,
+			To:        s.Health,
+			Reason:    s.Reason,
+		}
+		if err := c.recorder.RecordHealthTransition(c.ctx, tr); err != nil {
+			log.Logger.Warnw("failed to record health transition", "component", c.Name(), "error", err)
+		}
+	}
+}
+
+// isNoDataYet returns true if the component has not completed a check yet.
+func isNoDataYet(states apiv1.HealthStates) bool {
+	if len(states) == 0 {
+		return true
+	}
+	for _, s := range states {
+		if s.Reason != noDataYetReason {
+			return false
+		}
+	}
+	return true
+}
+
+// tagStale returns a copy of the health states tagged as stale,
+// with how long ago the health states were checked.
+func tagStale(states apiv1.HealthStates, now time.Time) apiv1.HealthStates {
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, s := range states {
+		extraInfo := make(map[string]string, len(s.ExtraInfo)+2)
+		for k, v := range s.ExtraInfo {
+			extraInfo[k] = v
+		}
+		extraInfo[StaleExtraInfoKey] = "true"
+		if !s.Time.IsZero() {
+			extraInfo[StaleAgeExtraInfoKey] = now.Sub(s.Time.Time).Truncate(time.Second).String()
+		}
+		s.ExtraInfo = extraInfo
+		copied = append(copied, s)
+	}
+	return copied
+}
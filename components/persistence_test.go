@@ -0,0 +1,171 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type memHealthStateStore struct {
+	mu     sync.Mutex
+	saves  int
+	states map[string]apiv1.HealthStates
+}
+
+func (s *memHealthStateStore) SaveHealthStates(_ context.Context, component string, states apiv1.HealthStates) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	s.states[component] = states
+	return nil
+}
+
+func (s *memHealthStateStore) LoadHealthStates(_ context.Context, component string) (apiv1.HealthStates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[component], nil
+}
+
+func TestWithPersistenceNil(t *testing.T) {
+	inner := &scriptedComponent{}
+	initFunc := func(*GPUdInstance) (Component, error) { return inner, nil }
+
+	c, err := WithPersistence(initFunc, nil)(&GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	assert.Same(t, inner, c)
+}
+
+func TestIsNoDataYet(t *testing.T) {
+	assert.True(t, isNoDataYet(nil))
+	assert.True(t, isNoDataYet(apiv1.HealthStates{{Reason: "no data yet"}}))
+	assert.False(t, isNoDataYet(apiv1.HealthStates{{Reason: "ok"}}))
+}
+
+func TestPersistenceComponent(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memHealthStateStore{states: map[string]apiv1.HealthStates{
+		"scripted": {{
+			Time:      metav1.NewTime(ts.Add(-5 * time.Minute)),
+			Component: "scripted",
+			Health:    apiv1.HealthStateTypeUnhealthy,
+			Reason:    "pre-restart failure",
+		}},
+	}}
+
+	inner := &scriptedComponent{
+		ts:     ts,
+		script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeDegraded},
+	}
+	c := newPersistenceComponent(context.Background(), inner, store, time.Hour).(*persistenceComponent)
+	c.getTimeNowFunc = func() time.Time { return ts }
+
+	// before the first check, the pre-restart health states are reported as stale
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+	assert.Equal(t, "pre-restart failure", states[0].Reason)
+	assert.True(t, IsStale(states[0].ExtraInfo))
+	assert.Equal(t, "5m0s", states[0].ExtraInfo[StaleAgeExtraInfoKey])
+	// the restored health states are not modified
+	assert.Nil(t, store.states["scripted"][0].ExtraInfo)
+	assert.Equal(t, 0, store.saves)
+
+	// the first check replaces the stale health states and persists
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, 1, store.saves)
+	states = c.LastHealthStates()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+	assert.False(t, IsStale(states[0].ExtraInfo))
+	// the same check result is not persisted twice
+	assert.Equal(t, 1, store.saves)
+
+	// the checks run by the component itself are persisted on read
+	_ = inner.Check()
+	states = c.LastHealthStates()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, states[0].Health)
+	assert.Equal(t, 2, store.saves)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, store.states["scripted"][0].Health)
+}
+
+type memHealthTransitionStore struct {
+	*memHealthStateStore
+	transitions []apiv1.HealthTransition
+}
+
+func (s *memHealthTransitionStore) RecordHealthTransition(_ context.Context, tr apiv1.HealthTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transitions = append(s.transitions, tr)
+	return nil
+}
+
+func TestPersistenceComponentTransitions(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memHealthTransitionStore{memHealthStateStore: &memHealthStateStore{states: map[string]apiv1.HealthStates{
+		"scripted": {{
+			Time:      metav1.NewTime(ts.Add(-5 * time.Minute)),
+			Component: "scripted",
+			Name:      "scripted",
+			Health:    apiv1.HealthStateTypeUnhealthy,
+		}},
+	}}}
+
+	inner := &scriptedComponent{
+		ts: ts,
+		script: []apiv1.HealthStateType{
+			apiv1.HealthStateTypeHealthy,
+			apiv1.HealthStateTypeHealthy,
+			apiv1.HealthStateTypeDegraded,
+		},
+	}
+	c := newPersistenceComponent(context.Background(), inner, store, time.Hour)
+
+	// the transition from the health states restored from before the restart
+	_ = c.Check()
+	require.Len(t, store.transitions, 1)
+	assert.Equal(t, "scripted", store.transitions[0].Component)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, store.transitions[0].From)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, store.transitions[0].To)
+	assert.True(t, ts.Add(time.Minute).Equal(store.transitions[0].Time.Time))
+
+	// no transition
+	_ = c.Check()
+	require.Len(t, store.transitions, 1)
+
+	_ = c.Check()
+	require.Len(t, store.transitions, 2)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, store.transitions[1].From)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, store.transitions[1].To)
+	assert.Equal(t, string(apiv1.HealthStateTypeDegraded), store.transitions[1].Reason)
+}
+
+func TestPersistenceComponentNothingRestored(t *testing.T) {
+	store := &memHealthStateStore{states: map[string]apiv1.HealthStates{}}
+	inner := &scriptedComponent{}
+	c := newPersistenceComponent(context.Background(), inner, store, time.Hour)
+	assert.Empty(t, c.LastHealthStates())
+}
+
+func TestPersistenceComponentSetHealthy(t *testing.T) {
+	store := &memHealthStateStore{states: map[string]apiv1.HealthStates{
+		"scripted": {{Health: apiv1.HealthStateTypeUnhealthy, Reason: "pre-restart failure"}},
+	}}
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+
+	c := newPersistenceComponent(context.Background(), inner, store, time.Hour)
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.Len(t, c.LastHealthStates(), 1)
+
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+	assert.Empty(t, c.LastHealthStates())
+}
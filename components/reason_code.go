@@ -0,0 +1,67 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/reasoncode"
+)
+
+// WithReasonCodes wraps the initialization function so that the initialized component
+// sets the reason codes of its health states and events, if not set by the component
+// (see pkg/reasoncode for the fallback codes).
+func WithReasonCodes(initFunc InitFunc) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newReasonCodeComponent(c), nil
+	}
+}
+
+func newReasonCodeComponent(c Component) Component {
+	rc := &reasonCodeComponent{Component: c}
+	return wrapComponent(rc, c, nil)
+}
+
+var _ Component = &reasonCodeComponent{}
+
+// reasonCodeComponent wraps a component to set the reason codes.
+type reasonCodeComponent struct {
+	Component
+}
+
+func (c *reasonCodeComponent) Check() CheckResult {
+	cr := c.Component.Check()
+	if cr == nil {
+		return nil
+	}
+	return wrapCheckResult(&reasonCodeCheckResult{CheckResult: cr, states: reasoncode.SetHealthStates(cr.HealthStates())}, cr)
+}
+
+func (c *reasonCodeComponent) LastHealthStates() apiv1.HealthStates {
+	return reasoncode.SetHealthStates(c.Component.LastHealthStates())
+}
+
+func (c *reasonCodeComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	evs, err := c.Component.Events(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	reasoncode.SetEvents(evs)
+	return evs, nil
+}
+
+var _ CheckResult = &reasonCodeCheckResult{}
+
+// reasonCodeCheckResult overrides the health states of the underlying check result.
+type reasonCodeCheckResult struct {
+	CheckResult
+	states apiv1.HealthStates
+}
+
+func (cr *reasonCodeCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// eventsComponent returns the fixed events.
+type eventsComponent struct {
+	*scriptedComponent
+	evs apiv1.Events
+}
+
+func (c *eventsComponent) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return c.evs, nil
+}
+
+func TestReasonCodeComponent(t *testing.T) {
+	inner := &scriptedComponent{
+		script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy, apiv1.HealthStateTypeUnhealthy},
+	}
+	c := newReasonCodeComponent(inner)
+
+	cr := c.Check()
+	require.Len(t, cr.HealthStates(), 1)
+	assert.Equal(t, apiv1.ReasonCodeHealthy, cr.HealthStates()[0].ReasonCode)
+
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, apiv1.ReasonCodeUnhealthy, cr.HealthStates()[0].ReasonCode)
+	assert.Equal(t, apiv1.ReasonCodeUnhealthy, c.LastHealthStates()[0].ReasonCode)
+	// the underlying health states are not modified
+	assert.Empty(t, inner.LastHealthStates()[0].ReasonCode)
+}
+
+func TestReasonCodeComponentEvents(t *testing.T) {
+	c := newReasonCodeComponent(&eventsComponent{
+		scriptedComponent: &scriptedComponent{},
+		evs: apiv1.Events{
+			{Name: "row_remapping_failed"},
+			{Name: "xid", ReasonCode: "GPU_XID_79"},
+		},
+	})
+
+	evs, err := c.Events(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.Equal(t, apiv1.ReasonCode("EVENT_ROW_REMAPPING_FAILED"), evs[0].ReasonCode)
+	assert.Equal(t, apiv1.ReasonCode("GPU_XID_79"), evs[1].ReasonCode)
+}
+
+func TestReasonCodeComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newReasonCodeComponent(inner)
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+
+	_, ok = newReasonCodeComponent(&scriptedComponent{}).(HealthSettable)
+	assert.False(t, ok)
+}
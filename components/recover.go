@@ -0,0 +1,150 @@
+package components
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/crashreport"
+)
+
+const (
+	// InternalErrorReason is the health state reason of the component
+	// whose check panicked.
+	InternalErrorReason = "internal error"
+	// CrashReportExtraInfoKey is the health state extra info key
+	// set to the path of the crash report of the panic, if written.
+	CrashReportExtraInfoKey = "crash_report"
+)
+
+// RecoverCheck runs the check of the component, and recovers the panic in the check
+// into the check result of the unhealthy health state with the reason "internal error".
+// The panic is captured in the crash report (see "pkg/crashreport").
+//
+// e.g., the periodic checks of the component run with [CheckLoop] are recovered.
+func RecoverCheck(componentName string, check func() CheckResult) (cr CheckResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			states := recoverHealthStates(componentName, "check", r, debug.Stack())
+			cr = &recoverCheckResult{componentName: componentName, states: states}
+		}
+	}()
+
+	return check()
+}
+
+// recoverHealthStates captures the recovered panic, and returns the unhealthy
+// health states of the panic.
+func recoverHealthStates(componentName string, call string, recovered any, stack []byte) apiv1.HealthStates {
+	rep := crashreport.Capture(crashreport.KindComponent, componentName, recovered, stack, map[string]string{"call": call})
+
+	st := apiv1.HealthState{
+		Time:      metav1.NewTime(rep.Time),
+		Component: componentName,
+		Name:      componentName,
+		Health:    apiv1.HealthStateTypeUnhealthy,
+		Reason:    InternalErrorReason,
+		Error:     fmt.Sprintf("%s panicked: %s", call, rep.Panic),
+	}
+	if rep.File != "" {
+		st.ExtraInfo = map[string]string{CrashReportExtraInfoKey: rep.File}
+	}
+	return apiv1.HealthStates{st}
+}
+
+// WithRecover wraps the initialization function so that a panic in the checks
+// of the initialized component is recovered (see [RecoverCheck]) rather than
+// taking down the daemon, and the component reports the unhealthy health state
+// with the reason "internal error" until a check returns, including the panics
+// in the periodic checks of the component run with [CheckLoop].
+//
+// Setting the wrapped component healthy clears the reported panic.
+func WithRecover(initFunc InitFunc) InitFunc {
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newRecoverComponent(c), nil
+	}
+}
+
+func newRecoverComponent(c Component) Component {
+	rc := &recoverComponent{Component: c}
+	return wrapComponent(rc, c, func() { rc.setPanicked(nil) })
+}
+
+var _ Component = &recoverComponent{}
+
+// recoverComponent wraps a component to recover the panics in its checks.
+type recoverComponent struct {
+	Component
+
+	mu sync.RWMutex
+	// panicked is the health states of the last panic, nil if the last check returned
+	panicked apiv1.HealthStates
+}
+
+func (c *recoverComponent) Check() CheckResult {
+	cr := RecoverCheck(c.Name(), c.Component.Check)
+	if rc, ok := cr.(*recoverCheckResult); ok {
+		c.setPanicked(rc.states)
+	} else {
+		c.setPanicked(nil)
+	}
+	return cr
+}
+
+func (c *recoverComponent) LastHealthStates() (states apiv1.HealthStates) {
+	c.mu.RLock()
+	panicked := c.panicked
+	c.mu.RUnlock()
+	if panicked != nil {
+		return panicked
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			states = recoverHealthStates(c.Name(), "last health states", r, debug.Stack())
+			c.setPanicked(states)
+		}
+	}()
+	return c.Component.LastHealthStates()
+}
+
+func (c *recoverComponent) setPanicked(states apiv1.HealthStates) {
+	c.mu.Lock()
+	c.panicked = states
+	c.mu.Unlock()
+}
+
+var _ CheckResult = &recoverCheckResult{}
+
+// recoverCheckResult is the check result of a panicked check.
+type recoverCheckResult struct {
+	componentName string
+	states        apiv1.HealthStates
+}
+
+func (cr *recoverCheckResult) ComponentName() string {
+	return cr.componentName
+}
+
+func (cr *recoverCheckResult) String() string {
+	return cr.Summary()
+}
+
+func (cr *recoverCheckResult) Summary() string {
+	return cr.states[0].Error
+}
+
+func (cr *recoverCheckResult) HealthStateType() apiv1.HealthStateType {
+	return apiv1.HealthStateTypeUnhealthy
+}
+
+func (cr *recoverCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
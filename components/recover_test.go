@@ -0,0 +1,77 @@
+package components
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/crashreport"
+)
+
+func TestRecoverComponent(t *testing.T) {
+	crashreport.SetWriter(crashreport.NewWriter(t.TempDir(), 0))
+	t.Cleanup(func() { crashreport.SetWriter(nil) })
+
+	inner := &scriptedComponent{script: []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy}}
+	c := newRecoverComponent(inner)
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+
+	// the script is exhausted, the check panics
+	require.NotPanics(t, func() { cr = c.Check() })
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	require.Len(t, cr.HealthStates(), 1)
+	st := cr.HealthStates()[0]
+	assert.Equal(t, InternalErrorReason, st.Reason)
+	assert.Contains(t, st.Error, "check panicked")
+	require.NotEmpty(t, st.ExtraInfo[CrashReportExtraInfoKey])
+	_, err := os.Stat(st.ExtraInfo[CrashReportExtraInfoKey])
+	require.NoError(t, err)
+
+	// reported until a check returns, not the stale states of the underlying component
+	assert.Equal(t, cr.HealthStates(), c.LastHealthStates())
+
+	inner.script = []apiv1.HealthStateType{apiv1.HealthStateTypeHealthy}
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, c.LastHealthStates()[0].Health)
+}
+
+func TestRecoverCheckPeriodic(t *testing.T) {
+	crashreport.SetWriter(nil)
+
+	inner := &scriptedComponent{}
+	c := newRecoverComponent(inner)
+
+	// e.g., the periodic checks of the underlying component
+	var l CheckLoop
+	l.bindCheckLoop(c.Check, &GPUdInstance{})
+	cr := l.RunCheck(inner.Name(), inner.Check)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Nil(t, cr.HealthStates()[0].ExtraInfo)
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, InternalErrorReason, states[0].Reason)
+}
+
+func TestRecoverComponentHealthSettable(t *testing.T) {
+	inner := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := newRecoverComponent(inner)
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+	assert.Empty(t, c.LastHealthStates())
+
+	_, ok = newRecoverComponent(&scriptedComponent{}).(HealthSettable)
+	assert.False(t, ok)
+}
@@ -8,11 +8,17 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
 	nvidiacommon "github.com/leptonai/gpud/pkg/config/common"
+	"github.com/leptonai/gpud/pkg/disposition"
 	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
 	pkghost "github.com/leptonai/gpud/pkg/host"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	nvidianvmldevice "github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 )
 
 var (
@@ -37,13 +43,49 @@ type GPUdInstance struct {
 	DBRW *sql.DB
 	DBRO *sql.DB
 
+	// DBFiles are the files of the GPUd databases (e.g., the state file),
+	// to track their growth, empty if in-memory.
+	DBFiles []string
+
 	EventStore       eventstore.Store
 	RebootEventStore pkghost.RebootEventStore
 
+	// MetricsStore is the store of the scraped metrics, nil if not set up.
+	MetricsStore pkgmetrics.Store
+
 	MountPoints  []string
 	MountTargets []string
 
 	FailureInjector *FailureInjector
+
+	// Chaos injects the random failures into the internal boundaries
+	// (e.g., plugin runs), nil if the chaos mode is disabled.
+	Chaos *pkgchaos.Injector
+
+	// EventDispositions is the operator dispositions on the events,
+	// to downgrade the known-noisy events, nil if not set up.
+	EventDispositions *disposition.Manager
+
+	// GPUResets is the GPU reset windows (e.g., the host reboots),
+	// to tag the expected Xid and SXid events, nil if not set up.
+	GPUResets *gpureset.Tracker
+
+	// ListenAddress is the address the GPUd server listens on
+	// (e.g., "0.0.0.0:15132"), to self-check the port reachability.
+	ListenAddress string
+
+	// CheckDurationObserver is called with the duration of every periodic check
+	// of the components run with [CheckLoop] (e.g., to track the latency objectives),
+	// nil if not set up.
+	CheckDurationObserver func(componentName string, elapsed time.Duration)
+
+	// AdaptiveCheckIntervals returns the adaptive interval config of the periodic checks
+	// of the component run with [CheckLoop], nil to check at the fixed default intervals.
+	AdaptiveCheckIntervals func(componentName string) AdaptiveIntervalConfig
+
+	// CheckDeferrer defers the checks of the components under the host pressure
+	// (see [WithLoadShedding]), nil if the load shedding is disabled.
+	CheckDeferrer CheckDeferrer
 }
 
 // FailureInjector configures test-only failure injection for selected components.
@@ -69,6 +111,11 @@ type FailureInjector struct {
 	// ref. https://github.com/leptonai/gpud/pull/1180
 	NVMLDeviceGetDevicesError bool
 
+	// GPUFieldOverrides maps the GPU UUID to the fake NVML values
+	// (e.g., temperature, ECC error counts, remapped rows) to report,
+	// so alerting pipelines can be validated without harming the hardware.
+	GPUFieldOverrides map[string]*nvidianvmldevice.FieldOverrides
+
 	// ContainerdSocketMissing when true simulates containerd socket file not existing.
 	// This is useful for testing the consecutive socket missing threshold logic
 	// that reports healthy for the first few checks before reporting unhealthy.
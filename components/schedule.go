@@ -0,0 +1,82 @@
+package components
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckSchedule is the current schedule of the periodic checks of a component.
+type CheckSchedule struct {
+	Component string `json:"component"`
+	// DefaultInterval is the fixed default interval of the component.
+	DefaultInterval metav1.Duration `json:"default_interval"`
+	// Interval is the current interval, adapted to the health of the last check.
+	Interval metav1.Duration `json:"interval"`
+	// NextCheckAt is when the next check is scheduled (unless deferred).
+	NextCheckAt metav1.Time `json:"next_check_at"`
+}
+
+var (
+	schedulesMu sync.RWMutex
+	schedules   = make(map[string]scheduleEntry)
+)
+
+type scheduleEntry struct {
+	ticker   *CheckTicker
+	schedule CheckSchedule
+}
+
+// setSchedule records the schedule of the ticker,
+// replacing the previous ticker of the same component (e.g., restarted).
+func setSchedule(t *CheckTicker, now time.Time) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	schedules[t.componentName] = scheduleEntry{
+		ticker: t,
+		schedule: CheckSchedule{
+			Component:       t.componentName,
+			DefaultInterval: metav1.Duration{Duration: t.defInterval},
+			Interval:        metav1.Duration{Duration: t.interval},
+			NextCheckAt:     metav1.NewTime(now.Add(t.interval).UTC()),
+		},
+	}
+}
+
+// deleteSchedule removes the schedule of the ticker, if not replaced by another ticker.
+func deleteSchedule(t *CheckTicker) {
+	schedulesMu.Lock()
+	defer schedulesMu.Unlock()
+
+	if e, ok := schedules[t.componentName]; ok && e.ticker == t {
+		delete(schedules, t.componentName)
+	}
+}
+
+// GetCheckSchedule returns the current check schedule of the component,
+// or false if the component has no running check ticker.
+func GetCheckSchedule(componentName string) (CheckSchedule, bool) {
+	schedulesMu.RLock()
+	defer schedulesMu.RUnlock()
+
+	e, ok := schedules[componentName]
+	return e.schedule, ok
+}
+
+// ListCheckSchedules returns the current check schedules, sorted by the component name.
+func ListCheckSchedules() []CheckSchedule {
+	schedulesMu.RLock()
+	defer schedulesMu.RUnlock()
+
+	ss := make([]CheckSchedule, 0, len(schedules))
+	for _, e := range schedules {
+		ss = append(ss, e.schedule)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Component < ss[j].Component
+	})
+	return ss
+}
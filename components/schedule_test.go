@@ -0,0 +1,49 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestCheckSchedules(t *testing.T) {
+	before := time.Now()
+	t1 := newCheckTicker("test-schedule-a", time.Minute, AdaptiveIntervalConfig{FailingInterval: metav1.Duration{Duration: 10 * time.Second}})
+	t2 := newCheckTicker("test-schedule-b", time.Hour, AdaptiveIntervalConfig{})
+	defer t2.Stop()
+
+	s, ok := GetCheckSchedule("test-schedule-a")
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, s.DefaultInterval.Duration)
+	assert.Equal(t, time.Minute, s.Interval.Duration)
+	assert.False(t, s.NextCheckAt.Time.Before(before.Add(time.Minute).Truncate(time.Second)))
+
+	t1.Observe(healthCheckResult(apiv1.HealthStateTypeUnhealthy))
+	s, ok = GetCheckSchedule("test-schedule-a")
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Second, s.Interval.Duration)
+
+	var names []string
+	for _, s := range ListCheckSchedules() {
+		if s.Component == "test-schedule-a" || s.Component == "test-schedule-b" {
+			names = append(names, s.Component)
+		}
+	}
+	assert.Equal(t, []string{"test-schedule-a", "test-schedule-b"}, names)
+
+	// the stopped ticker does not remove the schedule of its replacement
+	t3 := newCheckTicker("test-schedule-a", 2*time.Minute, AdaptiveIntervalConfig{})
+	t1.Stop()
+	s, ok = GetCheckSchedule("test-schedule-a")
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Minute, s.DefaultInterval.Duration)
+
+	t3.Stop()
+	_, ok = GetCheckSchedule("test-schedule-a")
+	assert.False(t, ok)
+}
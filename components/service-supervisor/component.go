@@ -0,0 +1,502 @@
+// Package servicesupervisor restarts the dead NVIDIA services
+// (e.g., nvidia-persistenced, nvidia-fabricmanager, DCGM) with the rate limiting,
+// as these services dying is common and the fix is always a restart.
+// Opt-in, as the restarts change the host state.
+package servicesupervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/systemd"
+)
+
+const (
+	// Name is the ID of the service supervisor component.
+	Name = "service-supervisor"
+
+	// EventNameServiceRestarted is the event of a dead service restarted.
+	EventNameServiceRestarted = "service_restarted"
+	// EventNameServiceRestartFailed is the event of a dead service failed to restart.
+	EventNameServiceRestartFailed = "service_restart_failed"
+	// EventNameServiceRestartRateLimited is the event of a dead service
+	// left dead after the maximum number of the restarts within an hour.
+	EventNameServiceRestartRateLimited = "service_restart_rate_limited"
+)
+
+const (
+	restartWindow  = time.Hour
+	restartTimeout = 2 * time.Minute
+)
+
+// Action is the remediation taken on a supervised service in the last check.
+type Action string
+
+const (
+	ActionNone          Action = "none"
+	ActionNotInstalled  Action = "not-installed"
+	ActionSkipped       Action = "skipped"
+	ActionRestarted     Action = "restarted"
+	ActionRestartFailed Action = "restart-failed"
+	ActionRateLimited   Action = "rate-limited"
+)
+
+var _ components.Component = &component{}
+
+type component struct {
+	components.CheckLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	eventBucket eventstore.Bucket
+
+	getTimeNowFunc     func() time.Time
+	getConfigFunc      func() Config
+	systemctlExistFunc func() bool
+	getUnitStateFunc   func(ctx context.Context, unit string) (systemd.UnitState, error)
+	restartUnitFunc    func(ctx context.Context, unit string) ([]byte, error)
+
+	// restarts tracks the restart attempts of each service within the last hour,
+	// loaded from the events on the first check to survive the GPUd restarts
+	restartsMu     sync.Mutex
+	restartsLoaded bool
+	restarts       map[string][]time.Time
+	// rateLimited tracks the services left dead by the rate limit,
+	// to record the rate limited event once
+	rateLimited map[string]bool
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+}
+
+// New creates the service supervisor component.
+func New(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	cctx, ccancel := context.WithCancel(gpudInstance.RootCtx)
+	c := &component{
+		ctx:    cctx,
+		cancel: ccancel,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc:      GetDefaultConfig,
+		systemctlExistFunc: systemd.SystemctlExists,
+		getUnitStateFunc:   systemd.GetUnitState,
+		restartUnitFunc:    systemd.RestartUnit,
+
+		restarts:    make(map[string][]time.Time),
+		rateLimited: make(map[string]bool),
+	}
+
+	if gpudInstance.EventStore != nil {
+		var err error
+		c.eventBucket, err = gpudInstance.EventStore.Bucket(Name)
+		if err != nil {
+			ccancel()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *component) Name() string { return Name }
+
+func (c *component) Tags() []string {
+	return []string{
+		"accelerator",
+		"nvidia",
+		"systemd",
+		Name,
+	}
+}
+
+// IsSupported returns true only if the remediation is enabled,
+// as the services are already tracked by the other components.
+func (c *component) IsSupported() bool {
+	return c.getConfigFunc().Enabled && c.systemctlExistFunc()
+}
+
+func (c *component) Start() error {
+	c.CheckLoop.Start(c.ctx, c.Name(), time.Minute, c.Check)
+	return nil
+}
+
+func (c *component) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	return lastCheckResult.HealthStates()
+}
+
+func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	if c.eventBucket == nil {
+		return nil, nil
+	}
+	evs, err := c.eventBucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *component) Close() error {
+	log.Logger.Debugw("closing component")
+
+	c.cancel()
+
+	if c.eventBucket != nil {
+		c.eventBucket.Close()
+	}
+
+	return nil
+}
+
+func (c *component) Check() components.CheckResult {
+	log.Logger.Infow("checking supervised services")
+
+	cr := &checkResult{
+		ts: c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cfg := c.getConfigFunc()
+	if !cfg.Enabled {
+		cr.health = apiv1.HealthStateTypeHealthy
+		cr.reason = "service supervisor not enabled"
+		return cr
+	}
+
+	c.loadRestarts(cr.ts)
+
+	var issues []string
+	for _, svc := range cfg.services() {
+		st := c.superviseService(svc, cfg.maxRestartsPerHour(), cr.ts)
+		cr.Services = append(cr.Services, st)
+
+		switch {
+		case st.Error != "" && st.Action == ActionNone:
+			issues = append(issues, fmt.Sprintf("%s: failed to get state (%s)", svc, st.Error))
+		case st.Action == ActionRestartFailed:
+			issues = append(issues, fmt.Sprintf("%s: restart failed (%s)", svc, st.Error))
+		case st.Action == ActionRateLimited:
+			issues = append(issues, fmt.Sprintf("%s: %s, not restarted after %d restart(s) within an hour", svc, st.ActiveState, st.RestartsLastHour))
+		}
+	}
+
+	if len(issues) > 0 {
+		cr.health = apiv1.HealthStateTypeDegraded
+		cr.reason = strings.Join(issues, "; ")
+		return cr
+	}
+
+	restarted := 0
+	for _, st := range cr.Services {
+		if st.Action == ActionRestarted {
+			restarted++
+		}
+	}
+	cr.health = apiv1.HealthStateTypeHealthy
+	cr.reason = fmt.Sprintf("supervised %d service(s), restarted %d", len(cr.Services), restarted)
+	return cr
+}
+
+// superviseService restarts the service if dead (failed, or stopped while enabled),
+// unless restarted the maximum number of times within the last hour.
+func (c *component) superviseService(svc string, maxRestarts int, now time.Time) ServiceStatus {
+	st := ServiceStatus{Service: svc, Action: ActionNone}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 30*time.Second)
+	us, err := c.getUnitStateFunc(cctx, svc)
+	ccancel()
+	if err != nil {
+		st.Error = err.Error()
+		log.Logger.Warnw("failed to get service state", "service", svc, "error", err)
+		return st
+	}
+	st.ActiveState = us.ActiveState
+	st.SubState = us.SubState
+	st.UnitFileState = us.UnitFileState
+
+	c.restartsMu.Lock()
+	defer c.restartsMu.Unlock()
+
+	st.RestartsLastHour = len(c.pruneRestartsLocked(svc, now))
+
+	if us.LoadState != "loaded" {
+		st.Action = ActionNotInstalled
+		return st
+	}
+	if !needsRestart(us) {
+		if us.ActiveState == "active" {
+			// recovered (e.g., by the restart), to record the next rate limit
+			delete(c.rateLimited, svc)
+		}
+		if us.ActiveState == "inactive" || strings.HasPrefix(us.UnitFileState, "masked") {
+			st.Action = ActionSkipped
+		}
+		return st
+	}
+
+	if st.RestartsLastHour >= maxRestarts {
+		st.Action = ActionRateLimited
+		metricRestartsTotal.With(prometheus.Labels{"service": svc, "result": "rate_limited"}).Inc()
+		if !c.rateLimited[svc] {
+			c.rateLimited[svc] = true
+			c.recordEvent(now, EventNameServiceRestartRateLimited, apiv1.EventTypeCritical,
+				fmt.Sprintf("%s is %s, not restarted after %d restart(s) within an hour", svc, us.ActiveState, st.RestartsLastHour),
+				st, "")
+		}
+		log.Logger.Warnw("service dead, restart rate limited", "service", svc, "activeState", us.ActiveState, "restarts", st.RestartsLastHour)
+		return st
+	}
+
+	log.Logger.Warnw("restarting dead service", "service", svc, "activeState", us.ActiveState, "subState", us.SubState)
+	c.restarts[svc] = append(c.restarts[svc], now)
+	st.RestartsLastHour++
+
+	rctx, rcancel := context.WithTimeout(c.ctx, restartTimeout)
+	out, err := c.restartUnitFunc(rctx, svc)
+	rcancel()
+	if err != nil {
+		st.Action = ActionRestartFailed
+		st.Error = err.Error()
+		if o := strings.TrimSpace(string(out)); o != "" {
+			st.Error += ": " + o
+		}
+		metricRestartsTotal.With(prometheus.Labels{"service": svc, "result": "failed"}).Inc()
+		c.recordEvent(now, EventNameServiceRestartFailed, apiv1.EventTypeCritical,
+			fmt.Sprintf("failed to restart %s (was %s): %s", svc, us.ActiveState, st.Error),
+			st, us.ActiveState)
+		log.Logger.Errorw("failed to restart service", "service", svc, "error", st.Error)
+		return st
+	}
+
+	st.Action = ActionRestarted
+	metricRestartsTotal.With(prometheus.Labels{"service": svc, "result": "succeeded"}).Inc()
+	c.recordEvent(now, EventNameServiceRestarted, apiv1.EventTypeWarning,
+		fmt.Sprintf("restarted %s (was %s/%s)", svc, us.ActiveState, us.SubState),
+		st, us.ActiveState)
+	log.Logger.Infow("restarted service", "service", svc)
+	return st
+}
+
+// needsRestart returns true if the service is failed,
+// or stopped while enabled to start on boot, and not masked.
+func needsRestart(us systemd.UnitState) bool {
+	if strings.HasPrefix(us.UnitFileState, "masked") {
+		return false
+	}
+	switch us.ActiveState {
+	case "failed":
+		return true
+	case "inactive":
+		return strings.HasPrefix(us.UnitFileState, "enabled")
+	default:
+		// e.g., "active", "activating", "deactivating", "reloading"
+		return false
+	}
+}
+
+// pruneRestartsLocked drops the restart attempts older than the window,
+// and returns the remaining.
+func (c *component) pruneRestartsLocked(svc string, now time.Time) []time.Time {
+	kept := c.restarts[svc][:0]
+	for _, t := range c.restarts[svc] {
+		if now.Sub(t) < restartWindow {
+			kept = append(kept, t)
+		}
+	}
+	c.restarts[svc] = kept
+	return kept
+}
+
+// loadRestarts loads the restart attempts within the last hour from the events once,
+// so the rate limit survives the GPUd restarts.
+func (c *component) loadRestarts(now time.Time) {
+	c.restartsMu.Lock()
+	defer c.restartsMu.Unlock()
+
+	if c.restartsLoaded || c.eventBucket == nil {
+		return
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	evs, err := c.eventBucket.Get(cctx, now.Add(-restartWindow))
+	ccancel()
+	if err != nil {
+		// retried on the next check
+		log.Logger.Warnw("failed to load service restart events", "error", err)
+		return
+	}
+	c.restartsLoaded = true
+
+	for _, ev := range evs {
+		if ev.Name != EventNameServiceRestarted && ev.Name != EventNameServiceRestartFailed {
+			continue
+		}
+		svc := ev.ExtraInfo["service"]
+		if svc == "" {
+			continue
+		}
+		c.restarts[svc] = append(c.restarts[svc], ev.Time)
+	}
+}
+
+func (c *component) recordEvent(now time.Time, name string, typ apiv1.EventType, msg string, st ServiceStatus, previousState string) {
+	if c.eventBucket == nil {
+		return
+	}
+
+	ev := eventstore.Event{
+		Component: Name,
+		Time:      now,
+		Name:      name,
+		Type:      string(typ),
+		Message:   msg,
+		ExtraInfo: map[string]string{
+			"service":            st.Service,
+			"restarts_last_hour": strconv.Itoa(st.RestartsLastHour),
+		},
+	}
+	if previousState != "" {
+		ev.ExtraInfo["previous_state"] = previousState
+	}
+
+	cctx, ccancel := context.WithTimeout(c.ctx, 15*time.Second)
+	err := c.eventBucket.Insert(cctx, ev)
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to insert service supervisor event", "service", st.Service, "event", name, "error", err)
+	}
+}
+
+// ServiceStatus is the state of a supervised service and the remediation in the last check.
+type ServiceStatus struct {
+	Service       string `json:"service"`
+	ActiveState   string `json:"active_state,omitempty"`
+	SubState      string `json:"sub_state,omitempty"`
+	UnitFileState string `json:"unit_file_state,omitempty"`
+
+	Action Action `json:"action"`
+	// RestartsLastHour is the number of the restart attempts within the last hour,
+	// including the one in the last check.
+	RestartsLastHour int    `json:"restarts_last_hour"`
+	Error            string `json:"error,omitempty"`
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	Services []ServiceStatus `json:"services,omitempty"`
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return Name
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.Services) == 0 {
+		return "no data"
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Service", "Active State", "Sub State", "Unit File State", "Action", "Restarts (1h)"})
+	for _, st := range cr.Services {
+		table.Append([]string{
+			st.Service,
+			st.ActiveState,
+			st.SubState,
+			st.UnitFileState,
+			string(st.Action),
+			strconv.Itoa(st.RestartsLastHour),
+		})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: Name,
+				Name:      Name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+
+	state := apiv1.HealthState{
+		Time:      metav1.NewTime(cr.ts),
+		Component: Name,
+		Name:      Name,
+		Reason:    cr.reason,
+		Error:     cr.getError(),
+		Health:    cr.health,
+	}
+
+	if len(cr.Services) > 0 {
+		b, _ := json.Marshal(cr)
+		state.ExtraInfo = map[string]string{"data": string(b)}
+	}
+	return apiv1.HealthStates{state}
+}
@@ -0,0 +1,304 @@
+package servicesupervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/pkg/systemd"
+)
+
+// fakeSystemd tracks the unit states and the restarts,
+// where a restarted unit becomes active unless failing the restarts.
+type fakeSystemd struct {
+	mu          sync.Mutex
+	states      map[string]systemd.UnitState
+	restarts    map[string]int
+	restartFail bool
+	stayDead    bool
+}
+
+func newFakeSystemd(states map[string]systemd.UnitState) *fakeSystemd {
+	return &fakeSystemd{states: states, restarts: make(map[string]int)}
+}
+
+func (f *fakeSystemd) getUnitState(_ context.Context, unit string) (systemd.UnitState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, ok := f.states[unit]
+	if !ok {
+		return systemd.UnitState{LoadState: "not-found", ActiveState: "inactive", SubState: "dead"}, nil
+	}
+	return st, nil
+}
+
+func (f *fakeSystemd) restartUnit(_ context.Context, unit string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restarts[unit]++
+	if f.restartFail {
+		return []byte("Job for unit failed."), errors.New("exit status 1")
+	}
+	if !f.stayDead {
+		st := f.states[unit]
+		st.ActiveState, st.SubState = "active", "running"
+		f.states[unit] = st
+	}
+	return nil, nil
+}
+
+func (f *fakeSystemd) setState(unit string, st systemd.UnitState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[unit] = st
+}
+
+func (f *fakeSystemd) restartCount(unit string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.restarts[unit]
+}
+
+var (
+	unitFailed   = systemd.UnitState{LoadState: "loaded", ActiveState: "failed", SubState: "failed", UnitFileState: "enabled"}
+	unitActive   = systemd.UnitState{LoadState: "loaded", ActiveState: "active", SubState: "running", UnitFileState: "enabled"}
+	unitDisabled = systemd.UnitState{LoadState: "loaded", ActiveState: "inactive", SubState: "dead", UnitFileState: "disabled"}
+)
+
+// createMockComponent creates a component restarting the units of the fake systemd,
+// recording the events to the bucket if non-nil.
+func createMockComponent(ctx context.Context, bucket eventstore.Bucket, cfg Config, fake *fakeSystemd) *component {
+	cctx, cancel := context.WithCancel(ctx)
+	return &component{
+		ctx:         cctx,
+		cancel:      cancel,
+		eventBucket: bucket,
+
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getConfigFunc:      func() Config { return cfg },
+		systemctlExistFunc: func() bool { return true },
+		getUnitStateFunc:   fake.getUnitState,
+		restartUnitFunc:    fake.restartUnit,
+
+		restarts:    make(map[string][]time.Time),
+		rateLimited: make(map[string]bool),
+	}
+}
+
+func newTestBucket(t *testing.T) eventstore.Bucket {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	t.Cleanup(cleanup)
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(Name)
+	require.NoError(t, err)
+	return bucket
+}
+
+func TestNew(t *testing.T) {
+	comp, err := New(&components.GPUdInstance{RootCtx: context.Background()})
+	require.NoError(t, err)
+	defer comp.Close()
+
+	c, ok := comp.(*component)
+	require.True(t, ok)
+	assert.Nil(t, c.eventBucket)
+	assert.NotNil(t, c.restarts)
+	assert.NotNil(t, c.rateLimited)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Enabled: true, Services: []string{"nvidia-persistenced"}, MaxRestartsPerHour: 5}.Validate())
+	assert.Error(t, Config{MaxRestartsPerHour: -1}.Validate())
+	assert.Error(t, Config{Services: []string{""}}.Validate())
+
+	assert.Equal(t, DefaultServices, Config{}.services())
+	assert.Equal(t, DefaultMaxRestartsPerHour, Config{}.maxRestartsPerHour())
+}
+
+func TestComponentBasics(t *testing.T) {
+	c := createMockComponent(context.Background(), nil, Config{}, newFakeSystemd(nil))
+	defer c.Close()
+	assert.Equal(t, Name, c.Name())
+	assert.Contains(t, c.Tags(), Name)
+
+	// opt-in
+	assert.False(t, c.IsSupported())
+	c.getConfigFunc = func() Config { return Config{Enabled: true} }
+	assert.True(t, c.IsSupported())
+	c.systemctlExistFunc = func() bool { return false }
+	assert.False(t, c.IsSupported())
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "no data yet", states[0].Reason)
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, evs)
+}
+
+func TestCheckNotEnabled(t *testing.T) {
+	fake := newFakeSystemd(map[string]systemd.UnitState{"nvidia-persistenced": unitFailed})
+	c := createMockComponent(context.Background(), nil, Config{}, fake)
+	defer c.Close()
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, 0, fake.restartCount("nvidia-persistenced"))
+}
+
+func TestCheckRestartsDeadService(t *testing.T) {
+	fake := newFakeSystemd(map[string]systemd.UnitState{
+		"nvidia-persistenced":  unitFailed,
+		"nvidia-fabricmanager": unitActive,
+		"nvidia-dcgm":          unitDisabled,
+	})
+	bucket := newTestBucket(t)
+	c := createMockComponent(context.Background(), bucket, Config{Enabled: true}, fake)
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, 1, fake.restartCount("nvidia-persistenced"))
+	assert.Equal(t, 0, fake.restartCount("nvidia-fabricmanager"))
+	assert.Equal(t, 0, fake.restartCount("nvidia-dcgm"))
+
+	actions := map[string]Action{}
+	for _, st := range cr.Services {
+		actions[st.Service] = st.Action
+	}
+	assert.Equal(t, map[string]Action{
+		"nvidia-persistenced":  ActionRestarted,
+		"nvidia-fabricmanager": ActionNone,
+		"nvidia-dcgm":          ActionSkipped,
+		"dcgm":                 ActionNotInstalled,
+	}, actions)
+	assert.Contains(t, cr.reason, "restarted 1")
+	assert.Contains(t, cr.String(), "nvidia-persistenced")
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameServiceRestarted, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, evs[0].Type)
+
+	// recovered, no restart
+	c.Check()
+	assert.Equal(t, 1, fake.restartCount("nvidia-persistenced"))
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	var decoded checkResult
+	require.NoError(t, json.Unmarshal([]byte(states[0].ExtraInfo["data"]), &decoded))
+	assert.Len(t, decoded.Services, 4)
+}
+
+func TestCheckRateLimited(t *testing.T) {
+	fake := newFakeSystemd(map[string]systemd.UnitState{"nvidia-persistenced": unitFailed})
+	fake.stayDead = true
+	bucket := newTestBucket(t)
+	cfg := Config{Enabled: true, Services: []string{"nvidia-persistenced"}, MaxRestartsPerHour: 2}
+	c := createMockComponent(context.Background(), bucket, cfg, fake)
+	defer c.Close()
+
+	now := time.Now().UTC()
+	c.getTimeNowFunc = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		cr := c.Check()
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	}
+	assert.Equal(t, 2, fake.restartCount("nvidia-persistenced"))
+
+	// rate limited, the event is recorded once
+	for i := 0; i < 2; i++ {
+		cr := c.Check().(*checkResult)
+		assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+		assert.Contains(t, cr.Summary(), "not restarted after 2 restart(s) within an hour")
+		assert.Equal(t, ActionRateLimited, cr.Services[0].Action)
+	}
+	assert.Equal(t, 2, fake.restartCount("nvidia-persistenced"))
+
+	evs, err := c.Events(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+	names := map[string]int{}
+	for _, ev := range evs {
+		names[ev.Name]++
+	}
+	assert.Equal(t, map[string]int{EventNameServiceRestarted: 2, EventNameServiceRestartRateLimited: 1}, names)
+
+	// the restarts are loaded from the events after the GPUd restart
+	c2 := createMockComponent(context.Background(), bucket, cfg, fake)
+	defer c2.Close()
+	c2.getTimeNowFunc = func() time.Time { return now.Add(time.Minute) }
+	cr := c2.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, 2, fake.restartCount("nvidia-persistenced"))
+
+	// restarted again after the window
+	c.getTimeNowFunc = func() time.Time { return now.Add(restartWindow + time.Minute) }
+	cr = c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, 3, fake.restartCount("nvidia-persistenced"))
+}
+
+func TestCheckRestartFailed(t *testing.T) {
+	fake := newFakeSystemd(map[string]systemd.UnitState{"nvidia-fabricmanager": unitFailed})
+	fake.restartFail = true
+	bucket := newTestBucket(t)
+	c := createMockComponent(context.Background(), bucket, Config{Enabled: true, Services: []string{"nvidia-fabricmanager"}}, fake)
+	defer c.Close()
+
+	cr := c.Check().(*checkResult)
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, ActionRestartFailed, cr.Services[0].Action)
+	assert.Contains(t, cr.Summary(), "Job for unit failed.")
+
+	evs, err := c.Events(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, EventNameServiceRestartFailed, evs[0].Name)
+	assert.Equal(t, apiv1.EventTypeCritical, evs[0].Type)
+}
+
+func TestCheckUnitStateError(t *testing.T) {
+	fake := newFakeSystemd(nil)
+	c := createMockComponent(context.Background(), nil, Config{Enabled: true, Services: []string{"nvidia-persistenced"}}, fake)
+	defer c.Close()
+	c.getUnitStateFunc = func(context.Context, string) (systemd.UnitState, error) {
+		return systemd.UnitState{}, errors.New("systemctl timed out")
+	}
+
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Contains(t, cr.Summary(), "systemctl timed out")
+}
+
+func TestNeedsRestart(t *testing.T) {
+	assert.True(t, needsRestart(unitFailed))
+	assert.True(t, needsRestart(systemd.UnitState{LoadState: "loaded", ActiveState: "inactive", UnitFileState: "enabled"}))
+	assert.False(t, needsRestart(unitDisabled))
+	assert.False(t, needsRestart(unitActive))
+	assert.False(t, needsRestart(systemd.UnitState{LoadState: "loaded", ActiveState: "failed", UnitFileState: "masked"}))
+	assert.False(t, needsRestart(systemd.UnitState{LoadState: "loaded", ActiveState: "activating", UnitFileState: "enabled"}))
+}
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
@@ -0,0 +1,85 @@
+package servicesupervisor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultMaxRestartsPerHour is the default maximum number of the restarts
+// of each service within an hour.
+const DefaultMaxRestartsPerHour = 3
+
+// DefaultServices are the NVIDIA services supervised by default,
+// where the services not installed are skipped.
+var DefaultServices = []string{
+	"nvidia-persistenced",
+	"nvidia-fabricmanager",
+	"nvidia-dcgm",
+	"dcgm",
+}
+
+// Config configures the supervised service remediation.
+type Config struct {
+	// Enabled enables restarting the dead services.
+	// Disabled by default, as the restarts change the host state.
+	Enabled bool `json:"enabled"`
+	// Services are the systemd units to supervise.
+	// Defaults to DefaultServices if empty.
+	Services []string `json:"services,omitempty"`
+	// MaxRestartsPerHour is the maximum number of the restart attempts
+	// of each service within the last hour, after which the service is left dead.
+	// Defaults to DefaultMaxRestartsPerHour if zero.
+	MaxRestartsPerHour int `json:"max_restarts_per_hour,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.MaxRestartsPerHour < 0 {
+		return fmt.Errorf("max_restarts_per_hour must be non-negative, got %d", cfg.MaxRestartsPerHour)
+	}
+	for _, svc := range cfg.Services {
+		if svc == "" {
+			return errors.New("empty service name")
+		}
+	}
+	return nil
+}
+
+func (cfg Config) services() []string {
+	if len(cfg.Services) > 0 {
+		return cfg.Services
+	}
+	return DefaultServices
+}
+
+func (cfg Config) maxRestartsPerHour() int {
+	if cfg.MaxRestartsPerHour > 0 {
+		return cfg.MaxRestartsPerHour
+	}
+	return DefaultMaxRestartsPerHour
+}
+
+var (
+	defaultConfigMu sync.RWMutex
+	defaultConfig   Config
+)
+
+// GetDefaultConfig returns the current default service supervisor config.
+func GetDefaultConfig() Config {
+	defaultConfigMu.RLock()
+	defer defaultConfigMu.RUnlock()
+
+	return defaultConfig
+}
+
+// SetDefaultConfig replaces the default service supervisor config.
+func SetDefaultConfig(cfg Config) {
+	log.Logger.Infow("setting default service supervisor config", "config", cfg)
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = cfg
+}
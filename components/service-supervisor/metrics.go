@@ -0,0 +1,36 @@
+package servicesupervisor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name for the service supervisor component.
+const SubSystem = "service_supervisor"
+
+var (
+	componentLabel = prometheus.Labels{
+		pkgmetrics.MetricComponentLabelKey: Name,
+	}
+
+	metricRestartsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "restarts_total",
+			Help:      "total number of the service restart attempts by the result (succeeded, failed, or rate_limited)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "service", "result"}, // label is name of the component
+	).MustCurryWith(componentLabel)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricRestartsTotal,
+	)
+	pkgmetrics.MustRegisterMetadata(Name,
+		apiv1.MetricMetadata{Name: SubSystem + "_restarts_total", Type: apiv1.MetricTypeCounter, Unit: apiv1.MetricUnitCount},
+	)
+}
@@ -11,6 +11,7 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/contracts/contractstest"
 )
 
 // mockComponent creates a component with mock functions for testing
@@ -475,3 +476,7 @@ func TestCheckResultMethodsNilReceiver(t *testing.T) {
 	require.Len(t, states, 1)
 	assert.Equal(t, "no data yet", states[0].Reason)
 }
+
+func TestDataContract(t *testing.T) {
+	contractstest.AssertHealthStateData(t, Name, &checkResult{})
+}
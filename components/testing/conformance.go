@@ -0,0 +1,306 @@
+// Package testing provides the conformance test suite for the GPUd components,
+// and the in-memory fakes of the GPUd dependencies (e.g., event store, metrics store),
+// so that the custom component implementations can be tested the same way the built-in components are.
+//
+// e.g.,
+//
+//	func TestConformance(t *testing.T) {
+//		componentstesting.Run(t, mycomponent.New)
+//	}
+package testing
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// metricNameRegex is the Prometheus metric name in snake case,
+// as the metrics are stored and reported by the name.
+var metricNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$`)
+
+var validHealthStateTypes = map[apiv1.HealthStateType]struct{}{
+	apiv1.HealthStateTypeHealthy:      {},
+	apiv1.HealthStateTypeUnhealthy:    {},
+	apiv1.HealthStateTypeDegraded:     {},
+	apiv1.HealthStateTypeInitializing: {},
+}
+
+var validEventTypes = map[apiv1.EventType]struct{}{
+	apiv1.EventTypeUnknown:  {},
+	apiv1.EventTypeInfo:     {},
+	apiv1.EventTypeWarning:  {},
+	apiv1.EventTypeCritical: {},
+	apiv1.EventTypeFatal:    {},
+}
+
+// Op is the conformance suite options.
+type Op struct {
+	gpudInstance *components.GPUdInstance
+	skipStart    bool
+	timeout      time.Duration
+}
+
+// OpOption applies the conformance suite option.
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+	if op.timeout == 0 {
+		op.timeout = time.Minute
+	}
+}
+
+// WithGPUdInstance sets the GPUd instance to initialize the component with.
+// Defaults to [NewGPUdInstance].
+func WithGPUdInstance(gpudInstance *components.GPUdInstance) OpOption {
+	return func(op *Op) {
+		op.gpudInstance = gpudInstance
+	}
+}
+
+// WithSkipStart skips starting the component
+// (e.g., the component requires the real hardware in its background loop).
+func WithSkipStart() OpOption {
+	return func(op *Op) {
+		op.skipStart = true
+	}
+}
+
+// WithTimeout sets the timeout of each check of the suite.
+// Defaults to 1 minute.
+func WithTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.timeout = timeout
+	}
+}
+
+// Run runs the conformance test suite against the component,
+// initialized by the init function:
+//
+//   - lifecycle: initializes, starts, checks, and closes the component
+//   - health states: the health states are of the component and the known health types
+//   - events: the events are of the known event types, the latest event first
+//   - metrics: the metric metadata is registered with the snake case names and the known types
+func Run(t *testing.T, initFunc components.InitFunc, opts ...OpOption) {
+	t.Helper()
+
+	op := &Op{}
+	op.applyOpts(opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gpudInstance := op.gpudInstance
+	if gpudInstance == nil {
+		gpudInstance = NewGPUdInstance(ctx)
+	}
+
+	c, err := initFunc(gpudInstance)
+	if err != nil {
+		t.Fatalf("failed to initialize component: %v", err)
+	}
+	if c == nil {
+		t.Fatal("init function returned nil component")
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			t.Errorf("failed to close component %q: %v", c.Name(), err)
+		}
+	}()
+
+	name := c.Name()
+	t.Run("lifecycle", func(t *testing.T) {
+		checkLifecycle(t, c, op)
+	})
+	t.Run("health_states", func(t *testing.T) {
+		checkHealthStates(t, name, c.LastHealthStates())
+
+		cr := runWithTimeout(t, op.timeout, c.Check)
+		if cr != nil {
+			checkHealthStates(t, name, cr.HealthStates())
+		}
+	})
+	t.Run("events", func(t *testing.T) {
+		cctx, ccancel := context.WithTimeout(ctx, op.timeout)
+		evs, err := c.Events(cctx, time.Now().Add(-time.Hour))
+		ccancel()
+		if err != nil {
+			t.Fatalf("failed to get events: %v", err)
+		}
+		checkEvents(t, evs)
+	})
+	t.Run("metrics", func(t *testing.T) {
+		checkMetrics(t, name)
+	})
+}
+
+func checkLifecycle(t *testing.T, c components.Component, op *Op) {
+	name := c.Name()
+	if name == "" {
+		t.Fatal("component name is empty")
+	}
+	if c.Name() != name {
+		t.Errorf("component name changed from %q to %q", name, c.Name())
+	}
+	if strings.TrimSpace(name) != name || strings.ContainsAny(name, "/ ") {
+		t.Errorf("component name %q must not contain spaces or slashes (used in the HTTP paths)", name)
+	}
+
+	// the tags are static
+	tags := c.Tags()
+	if fmt.Sprint(tags) != fmt.Sprint(c.Tags()) {
+		t.Errorf("component tags changed from %v to %v", tags, c.Tags())
+	}
+	for _, tag := range tags {
+		if tag == "" {
+			t.Error("component tag is empty")
+		}
+	}
+
+	// must not panic on the unsupported machines
+	_ = c.IsSupported()
+
+	if !op.skipStart {
+		if err := c.Start(); err != nil {
+			t.Fatalf("failed to start component: %v", err)
+		}
+	}
+
+	cr := runWithTimeout(t, op.timeout, c.Check)
+	if cr == nil {
+		return
+	}
+	if cr.ComponentName() != name {
+		t.Errorf("check result component name %q, expected %q", cr.ComponentName(), name)
+	}
+	if _, ok := validHealthStateTypes[cr.HealthStateType()]; !ok {
+		t.Errorf("check result health state type %q is unknown", cr.HealthStateType())
+	}
+	_ = cr.String()
+	_ = cr.Summary()
+}
+
+// runWithTimeout runs the check, failing the test if it does not return within the timeout.
+func runWithTimeout(t *testing.T, timeout time.Duration, check func() components.CheckResult) components.CheckResult {
+	ch := make(chan components.CheckResult, 1)
+	go func() {
+		ch <- check()
+	}()
+
+	select {
+	case cr := <-ch:
+		if cr == nil {
+			t.Error("check returned nil result")
+		}
+		return cr
+	case <-time.After(timeout):
+		t.Errorf("check did not return within %v", timeout)
+		return nil
+	}
+}
+
+// reporter is the subset of [testing.T] to report the conformance failures.
+type reporter interface {
+	Errorf(format string, args ...any)
+}
+
+func checkHealthStates(t reporter, name string, states apiv1.HealthStates) {
+	if len(states) == 0 {
+		t.Errorf("no health state (expected at least one, even if not checked yet)")
+	}
+	for i, st := range states {
+		if st.Component != name {
+			t.Errorf("health state %d component %q, expected %q", i, st.Component, name)
+		}
+		if st.Name == "" {
+			t.Errorf("health state %d name is empty", i)
+		}
+		if st.Time.IsZero() {
+			t.Errorf("health state %d time is zero", i)
+		}
+		if _, ok := validHealthStateTypes[st.Health]; !ok {
+			t.Errorf("health state %d health %q is unknown", i, st.Health)
+		}
+		if st.Health != apiv1.HealthStateTypeHealthy && st.Reason == "" {
+			t.Errorf("health state %d is %q without reason", i, st.Health)
+		}
+		if st.SuggestedActions != nil && len(st.SuggestedActions.RepairActions) == 0 {
+			t.Errorf("health state %d has suggested actions without repair actions", i)
+		}
+	}
+}
+
+func checkEvents(t reporter, evs apiv1.Events) {
+	for i, ev := range evs {
+		if ev.Time.IsZero() {
+			t.Errorf("event %d time is zero", i)
+		}
+		if ev.Name == "" {
+			t.Errorf("event %d name is empty", i)
+		}
+		if _, ok := validEventTypes[ev.Type]; !ok {
+			t.Errorf("event %d type %q is unknown", i, ev.Type)
+		}
+		if i > 0 && ev.Time.After(evs[i-1].Time.Time) {
+			t.Errorf("event %d is newer than event %d (expected the latest event first)", i, i-1)
+		}
+	}
+}
+
+func checkMetrics(t *testing.T, name string) {
+	mds, err := pkgmetrics.ListMetadata(pkgmetrics.WithComponents(name))
+	if err != nil {
+		t.Fatalf("failed to list metric metadata: %v", err)
+	}
+	for _, md := range mds {
+		if !metricNameRegex.MatchString(md.Name) {
+			t.Errorf("metric %q is not in snake case", md.Name)
+		}
+		switch md.Type {
+		case apiv1.MetricTypeCounter:
+			if !strings.HasSuffix(md.Name, "_total") {
+				t.Errorf("counter metric %q must end with \"_total\"", md.Name)
+			}
+		case apiv1.MetricTypeGauge:
+			if strings.HasSuffix(md.Name, "_total") {
+				t.Errorf("gauge metric %q must not end with \"_total\"", md.Name)
+			}
+		default:
+			t.Errorf("metric %q type %q is unknown", md.Name, md.Type)
+		}
+	}
+
+	// the reported metrics must be registered with the metadata
+	families, err := pkgmetrics.DefaultGatherer().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			reported := false
+			for _, l := range m.GetLabel() {
+				if l.GetName() == pkgmetrics.MetricComponentLabelKey && l.GetValue() == name {
+					reported = true
+					break
+				}
+			}
+			if !reported {
+				continue
+			}
+			if _, ok := pkgmetrics.GetMetadata(f.GetName()); !ok {
+				t.Errorf("metric %q is reported without metadata (see pkgmetrics.MustRegisterMetadata)", f.GetName())
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentscpu "github.com/leptonai/gpud/components/cpu"
+	componentsmemory "github.com/leptonai/gpud/components/memory"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const testComponentName = "test-conformance"
+
+var _ components.Component = &testComponent{}
+
+// testComponent is the minimal component that records an event on every check.
+type testComponent struct {
+	bucket eventstore.Bucket
+}
+
+// mockInitFunc initializes the test component with the event bucket of the GPUd instance.
+func mockInitFunc(gpudInstance *components.GPUdInstance) (components.Component, error) {
+	bucket, err := gpudInstance.EventStore.Bucket(testComponentName)
+	if err != nil {
+		return nil, err
+	}
+	return &testComponent{bucket: bucket}, nil
+}
+
+func (c *testComponent) Name() string      { return testComponentName }
+func (c *testComponent) Tags() []string    { return []string{testComponentName} }
+func (c *testComponent) IsSupported() bool { return true }
+func (c *testComponent) Start() error      { return nil }
+
+func (c *testComponent) Check() components.CheckResult {
+	now := time.Now().UTC()
+	_ = c.bucket.Insert(context.Background(), eventstore.Event{Time: now, Name: "checked", Type: string(apiv1.EventTypeInfo)})
+	return &testCheckResult{ts: now}
+}
+
+func (c *testComponent) LastHealthStates() apiv1.HealthStates {
+	return (&testCheckResult{ts: time.Now().UTC()}).HealthStates()
+}
+
+func (c *testComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	evs, err := c.bucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return evs.Events(), nil
+}
+
+func (c *testComponent) Close() error {
+	c.bucket.Close()
+	return nil
+}
+
+type testCheckResult struct {
+	ts time.Time
+}
+
+func (cr *testCheckResult) ComponentName() string { return testComponentName }
+func (cr *testCheckResult) String() string        { return "ok" }
+func (cr *testCheckResult) Summary() string       { return "ok" }
+func (cr *testCheckResult) HealthStateType() apiv1.HealthStateType {
+	return apiv1.HealthStateTypeHealthy
+}
+
+func (cr *testCheckResult) HealthStates() apiv1.HealthStates {
+	return apiv1.HealthStates{
+		{
+			Time:      metav1.NewTime(cr.ts),
+			Component: testComponentName,
+			Name:      testComponentName,
+			Health:    apiv1.HealthStateTypeHealthy,
+			Reason:    "ok",
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	gpudInstance := NewGPUdInstance(context.Background())
+	Run(t, mockInitFunc, WithGPUdInstance(gpudInstance))
+
+	b := gpudInstance.EventStore.(*EventStore).GetBucket(testComponentName)
+	assert.True(t, b.Closed())
+	evs, err := b.Get(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, evs, 2)
+}
+
+func TestRunBuiltinComponents(t *testing.T) {
+	for _, initFunc := range []components.InitFunc{
+		componentscpu.New,
+		componentsmemory.New,
+	} {
+		Run(t, initFunc, WithSkipStart(), WithTimeout(30*time.Second))
+	}
+}
+
+type recordingReporter struct {
+	errs []string
+}
+
+func (r *recordingReporter) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestCheckHealthStates(t *testing.T) {
+	r := &recordingReporter{}
+	checkHealthStates(r, testComponentName, nil)
+	assert.Len(t, r.errs, 1)
+
+	r = &recordingReporter{}
+	checkHealthStates(r, testComponentName, apiv1.HealthStates{
+		{Component: "other", Health: "unknown"},
+		{Component: testComponentName, Name: "x", Time: metav1.Now(), Health: apiv1.HealthStateTypeUnhealthy},
+	})
+	assert.Equal(t, []string{
+		`health state 0 component "other", expected "test-conformance"`,
+		"health state 0 name is empty",
+		"health state 0 time is zero",
+		`health state 0 health "unknown" is unknown`,
+		`health state 0 is "unknown" without reason`,
+		`health state 1 is "Unhealthy" without reason`,
+	}, r.errs)
+}
+
+func TestCheckEvents(t *testing.T) {
+	now := time.Now()
+	r := &recordingReporter{}
+	checkEvents(r, apiv1.Events{
+		{Time: metav1.NewTime(now), Name: "a", Type: apiv1.EventTypeInfo},
+		{Time: metav1.NewTime(now.Add(time.Minute)), Type: "bad"},
+	})
+	assert.Equal(t, []string{
+		"event 1 name is empty",
+		`event 1 type "bad" is unknown`,
+		"event 1 is newer than event 0 (expected the latest event first)",
+	}, r.errs)
+}
+
+func TestEventBucket(t *testing.T) {
+	ctx := context.Background()
+	store := NewEventStore()
+
+	bucket, err := store.Bucket("test")
+	require.NoError(t, err)
+	assert.Equal(t, "test", bucket.Name())
+
+	latest, err := bucket.Latest(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	now := time.Now().UTC()
+	for i := range 3 {
+		require.NoError(t, bucket.Insert(ctx, eventstore.Event{
+			Time:    now.Add(time.Duration(-i) * time.Minute),
+			Name:    "test",
+			Type:    string(apiv1.EventTypeWarning),
+			Message: "message",
+		}))
+	}
+
+	found, err := bucket.Find(ctx, eventstore.Event{Time: now.Add(-time.Minute), Name: "test", Type: string(apiv1.EventTypeWarning)})
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	found, err = bucket.Find(ctx, eventstore.Event{Time: now, Name: "test", Type: string(apiv1.EventTypeWarning), Message: "other"})
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	evs, err := bucket.Get(ctx, now.Add(-90*time.Second))
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.Equal(t, now, evs[0].Time)
+
+	latest, err = bucket.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now, latest.Time)
+
+	purged, err := bucket.Purge(ctx, now.Add(-30*time.Second).Unix())
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	// same bucket for the same name
+	again, err := store.Bucket("test")
+	require.NoError(t, err)
+	evs, err = again.Get(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+}
+
+func TestMetricsStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetricsStore()
+
+	now := time.Now()
+	require.NoError(t, store.Record(ctx,
+		pkgmetrics.Metric{UnixMilliseconds: now.UnixMilli(), Component: "a", Name: "m1", Value: 1},
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-time.Hour).UnixMilli(), Component: "b", Name: "m2", Value: 2},
+	))
+
+	ms, err := store.Read(ctx)
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+	assert.Equal(t, "m2", ms[0].Name)
+
+	ms, err = store.Read(ctx, pkgmetrics.WithComponents("a"))
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	assert.Equal(t, "m1", ms[0].Name)
+
+	ms, err = store.Read(ctx, pkgmetrics.WithSince(now.Add(-time.Minute)))
+	require.NoError(t, err)
+	assert.Len(t, ms, 1)
+
+	purged, err := store.Purge(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestRebootEventStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	store := NewRebootEventStore(now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	evs, err := store.GetRebootEvents(ctx, now.Add(-90*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, now.Add(-time.Hour), evs[0].Time)
+
+	require.NoError(t, store.RecordReboot(ctx))
+	evs, err = store.GetRebootEvents(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, evs, 3)
+}
+
+func TestNewRegistry(t *testing.T) {
+	registry, gpudInstance := NewRegistry(context.Background())
+	require.NotNil(t, gpudInstance.EventStore)
+
+	c, err := registry.Register(mockInitFunc)
+	require.NoError(t, err)
+	assert.Equal(t, c, registry.Get(testComponentName))
+}
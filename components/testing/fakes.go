@@ -0,0 +1,284 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var (
+	_ eventstore.Store         = &EventStore{}
+	_ eventstore.Bucket        = &EventBucket{}
+	_ pkgmetrics.Store         = &MetricsStore{}
+	_ pkghost.RebootEventStore = &RebootEventStore{}
+)
+
+// EventStore is the in-memory event store,
+// with the same query semantics as the SQLite event store.
+// Safe for concurrent use.
+type EventStore struct {
+	mu      sync.Mutex
+	buckets map[string]*EventBucket
+}
+
+// NewEventStore creates the in-memory event store.
+func NewEventStore() *EventStore {
+	return &EventStore{buckets: make(map[string]*EventBucket)}
+}
+
+// Bucket returns the bucket of the name, creating it if not found.
+// The same bucket is returned for the same name.
+func (s *EventStore) Bucket(name string, _ ...eventstore.OpOption) (eventstore.Bucket, error) {
+	return s.GetBucket(name), nil
+}
+
+// GetBucket returns the bucket of the name, creating it if not found,
+// to inspect the events inserted by the component.
+func (s *EventStore) GetBucket(name string) *EventBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[name]
+	if !ok {
+		b = &EventBucket{name: name}
+		s.buckets[name] = b
+	}
+	return b
+}
+
+// EventBucket is the in-memory event bucket.
+// Safe for concurrent use.
+type EventBucket struct {
+	name string
+
+	mu     sync.RWMutex
+	events eventstore.Events
+	closed bool
+}
+
+func (b *EventBucket) Name() string {
+	return b.name
+}
+
+func (b *EventBucket) Insert(_ context.Context, ev eventstore.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, ev)
+	sort.SliceStable(b.events, func(i, j int) bool {
+		return b.events[i].Time.After(b.events[j].Time)
+	})
+	return nil
+}
+
+// Find returns the event of the same timestamp (in seconds), name, and type,
+// and the same message if set, or nil if not found.
+func (b *EventBucket) Find(_ context.Context, ev eventstore.Event) (*eventstore.Event, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, e := range b.events {
+		if e.Time.Unix() != ev.Time.Unix() || e.Name != ev.Name || e.Type != ev.Type {
+			continue
+		}
+		if ev.Message != "" && e.Message != ev.Message {
+			continue
+		}
+		found := e
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// Get returns the events after the since time (in seconds), the latest event first.
+func (b *EventBucket) Get(_ context.Context, since time.Time) (eventstore.Events, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var evs eventstore.Events
+	for _, e := range b.events {
+		if e.Time.Unix() > since.Unix() {
+			evs = append(evs, e)
+		}
+	}
+	return evs, nil
+}
+
+func (b *EventBucket) Latest(_ context.Context) (*eventstore.Event, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.events) == 0 {
+		return nil, nil
+	}
+	latest := b.events[0]
+	return &latest, nil
+}
+
+func (b *EventBucket) Purge(_ context.Context, beforeTimestamp int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := make(eventstore.Events, 0, len(b.events))
+	for _, e := range b.events {
+		if e.Time.Unix() >= beforeTimestamp {
+			kept = append(kept, e)
+		}
+	}
+	purged := len(b.events) - len(kept)
+	b.events = kept
+	return purged, nil
+}
+
+func (b *EventBucket) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+}
+
+// Closed returns true if the bucket was closed by the component.
+func (b *EventBucket) Closed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.closed
+}
+
+// MetricsStore is the in-memory metrics store.
+// Safe for concurrent use.
+type MetricsStore struct {
+	mu      sync.RWMutex
+	metrics pkgmetrics.Metrics
+}
+
+// NewMetricsStore creates the in-memory metrics store.
+func NewMetricsStore() *MetricsStore {
+	return &MetricsStore{}
+}
+
+func (s *MetricsStore) Record(_ context.Context, ms ...pkgmetrics.Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics = append(s.metrics, ms...)
+	return nil
+}
+
+// Read returns the metrics since the time (all if zero) of the selected components (all if empty),
+// in the ascending order of the timestamp.
+func (s *MetricsStore) Read(_ context.Context, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	op := &pkgmetrics.Op{}
+	if err := op.ApplyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ms pkgmetrics.Metrics
+	for _, m := range s.metrics {
+		if !op.Since.IsZero() && m.UnixMilliseconds < op.Since.UnixMilli() {
+			continue
+		}
+		if len(op.SelectedComponents) > 0 {
+			if _, ok := op.SelectedComponents[m.Component]; !ok {
+				continue
+			}
+		}
+		ms = append(ms, m)
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].UnixMilliseconds < ms[j].UnixMilliseconds
+	})
+	return ms, nil
+}
+
+func (s *MetricsStore) Purge(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make(pkgmetrics.Metrics, 0, len(s.metrics))
+	for _, m := range s.metrics {
+		if m.UnixMilliseconds >= before.UnixMilli() {
+			kept = append(kept, m)
+		}
+	}
+	purged := len(s.metrics) - len(kept)
+	s.metrics = kept
+	return purged, nil
+}
+
+// RebootEventStore is the in-memory reboot event store.
+// Safe for concurrent use.
+type RebootEventStore struct {
+	mu     sync.RWMutex
+	events eventstore.Events
+}
+
+// NewRebootEventStore creates the in-memory reboot event store,
+// with the optional reboot times.
+func NewRebootEventStore(rebootTimes ...time.Time) *RebootEventStore {
+	s := &RebootEventStore{}
+	for _, t := range rebootTimes {
+		s.add(t)
+	}
+	return s
+}
+
+func (s *RebootEventStore) RecordReboot(_ context.Context) error {
+	s.add(time.Now().UTC())
+	return nil
+}
+
+func (s *RebootEventStore) add(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, eventstore.Event{
+		Component: pkghost.EventBucketName,
+		Time:      t,
+		Name:      pkghost.EventNameReboot,
+		Type:      string(apiv1.EventTypeWarning),
+		Message:   fmt.Sprintf("system reboot detected %v", t),
+	})
+	sort.SliceStable(s.events, func(i, j int) bool {
+		return s.events[i].Time.After(s.events[j].Time)
+	})
+}
+
+func (s *RebootEventStore) GetRebootEvents(_ context.Context, since time.Time) (eventstore.Events, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var evs eventstore.Events
+	for _, e := range s.events {
+		if e.Time.Unix() > since.Unix() {
+			evs = append(evs, e)
+		}
+	}
+	return evs, nil
+}
+
+// NewGPUdInstance returns the GPUd instance with the in-memory event stores,
+// without the NVML instance and the database.
+func NewGPUdInstance(ctx context.Context) *components.GPUdInstance {
+	return &components.GPUdInstance{
+		RootCtx:          ctx,
+		MachineID:        "test-machine-id",
+		EventStore:       NewEventStore(),
+		RebootEventStore: NewRebootEventStore(),
+	}
+}
+
+// NewRegistry returns the component registry backed by the GPUd instance of [NewGPUdInstance].
+func NewRegistry(ctx context.Context) (components.Registry, *components.GPUdInstance) {
+	gpudInstance := NewGPUdInstance(ctx)
+	return components.NewRegistry(gpudInstance), gpudInstance
+}
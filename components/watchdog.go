@@ -0,0 +1,288 @@
+package components
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// WatchdogExtraInfoKey is the health state extra info key
+	// set to the number of consecutive check timeouts.
+	WatchdogExtraInfoKey = "check_timeouts"
+
+	// WatchdogHangReason is the health state reason of the component
+	// whose checks keep timing out.
+	WatchdogHangReason = "check hang"
+
+	// DefaultWatchdogTimeout is the default timeout of a single component check.
+	// Must be longer than the timeouts of the underlying commands (e.g., ibstat).
+	DefaultWatchdogTimeout = 3 * time.Minute
+	// DefaultWatchdogHangThreshold is the default number of consecutive
+	// check timeouts before reporting the component as unhealthy.
+	DefaultWatchdogHangThreshold = 3
+)
+
+var (
+	metricCheckTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "component",
+			Name:      "check_timeouts_total",
+			Help:      "total number of the component checks that did not complete within the timeout",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey},
+	)
+	metricCheckHung = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "gpud",
+			Subsystem: "component",
+			Name:      "check_hung",
+			Help:      "set to 1 if a timed out check of the component is still running (leaked goroutine)",
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricCheckTimeoutsTotal,
+		metricCheckHung,
+	)
+}
+
+// WatchdogConfig configures the time-bounded checks of a component.
+// A zero value uses the defaults.
+type WatchdogConfig struct {
+	// Timeout is the hard timeout of a single check.
+	// Zero uses DefaultWatchdogTimeout.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// HangThreshold is the number of consecutive check timeouts
+	// before reporting the component as unhealthy.
+	// Zero uses DefaultWatchdogHangThreshold.
+	HangThreshold int `json:"hang_threshold,omitempty"`
+	// Disabled runs the checks without the timeout.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg WatchdogConfig) Validate() error {
+	if cfg.Timeout.Duration < 0 {
+		return fmt.Errorf("timeout must be non-negative, got %s", cfg.Timeout.Duration)
+	}
+	if cfg.HangThreshold < 0 {
+		return fmt.Errorf("hang_threshold must be non-negative, got %d", cfg.HangThreshold)
+	}
+	return nil
+}
+
+func (cfg WatchdogConfig) timeout() time.Duration {
+	if cfg.Timeout.Duration == 0 {
+		return DefaultWatchdogTimeout
+	}
+	return cfg.Timeout.Duration
+}
+
+func (cfg WatchdogConfig) hangThreshold() int {
+	if cfg.HangThreshold == 0 {
+		return DefaultWatchdogHangThreshold
+	}
+	return cfg.HangThreshold
+}
+
+// WithWatchdog wraps the initialization function so that the checks of the
+// initialized component are bounded by the timeout, not blocking the callers
+// (e.g., the periodic checks run with [CheckLoop], the on-demand checks of all
+// components) on a single hung check.
+// It returns the original initialization function if the watchdog is disabled.
+//
+// A timed out check keeps running in the background, and no other check is
+// started until it returns, so at most one goroutine leaks per component.
+// The component is reported unhealthy with the reason "check hang" after the
+// consecutive timeouts reach the threshold, until a check completes in time.
+//
+// Setting the wrapped component healthy resets the consecutive timeouts.
+func WithWatchdog(initFunc InitFunc, cfg WatchdogConfig) InitFunc {
+	if cfg.Disabled {
+		return initFunc
+	}
+	return func(gpudInstance *GPUdInstance) (Component, error) {
+		c, err := initFunc(gpudInstance)
+		if err != nil {
+			return nil, err
+		}
+		return newWatchdogComponent(c, cfg), nil
+	}
+}
+
+func newWatchdogComponent(c Component, cfg WatchdogConfig) Component {
+	wc := &watchdogComponent{
+		Component:      c,
+		timeout:        cfg.timeout(),
+		hangThreshold:  cfg.hangThreshold(),
+		getTimeNowFunc: func() time.Time { return time.Now().UTC() },
+	}
+	return wrapComponent(wc, c, wc.resetTimeouts)
+}
+
+var _ Component = &watchdogComponent{}
+
+// watchdogComponent wraps a component to bound its checks by the timeout.
+type watchdogComponent struct {
+	Component
+
+	timeout        time.Duration
+	hangThreshold  int
+	getTimeNowFunc func() time.Time
+
+	mu sync.Mutex
+	// inflight is the running check, nil if none is running
+	inflight *watchdogCheck
+	// consecutiveTimeouts is the number of consecutive checks that timed out
+	consecutiveTimeouts int
+}
+
+func (c *watchdogComponent) Check() CheckResult {
+	c.mu.Lock()
+	wc := c.inflight
+	if wc == nil {
+		wc = &watchdogCheck{done: make(chan struct{}), started: c.getTimeNowFunc()}
+		c.inflight = wc
+		go c.run(wc)
+	} else {
+		// the previous check timed out and is still running,
+		// wait for it instead of starting another one
+		log.Logger.Warnw("previous check still running", "component", c.Name(), "started", wc.started)
+	}
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.timeout - c.getTimeNowFunc().Sub(wc.started))
+	defer timer.Stop()
+
+	select {
+	case <-wc.done:
+		c.resetTimeouts()
+		return wc.result
+
+	case <-timer.C:
+		metricCheckTimeoutsTotal.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: c.Name()}).Inc()
+		metricCheckHung.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: c.Name()}).Set(1)
+
+		c.mu.Lock()
+		c.consecutiveTimeouts++
+		timeouts := c.consecutiveTimeouts
+		c.mu.Unlock()
+
+		log.Logger.Warnw("check timed out", "component", c.Name(), "timeout", c.timeout, "consecutiveTimeouts", timeouts)
+		return c.timedOutCheckResult(timeouts)
+	}
+}
+
+// watchdogCheck is a single run of the underlying check.
+type watchdogCheck struct {
+	started time.Time
+	// done is closed when the check returns
+	done chan struct{}
+	// result is set before closing done
+	result CheckResult
+}
+
+// run runs the underlying check, and clears the inflight check once it returns.
+func (c *watchdogComponent) run(wc *watchdogCheck) {
+	wc.result = c.Component.Check()
+
+	elapsed := c.getTimeNowFunc().Sub(wc.started)
+	metricCheckHung.With(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: c.Name()}).Set(0)
+	if elapsed > c.timeout {
+		log.Logger.Warnw("timed out check returned", "component", c.Name(), "elapsed", elapsed)
+	}
+
+	c.mu.Lock()
+	c.inflight = nil
+	c.mu.Unlock()
+	close(wc.done)
+}
+
+func (c *watchdogComponent) LastHealthStates() apiv1.HealthStates {
+	c.mu.Lock()
+	timeouts := c.consecutiveTimeouts
+	c.mu.Unlock()
+
+	if timeouts < c.hangThreshold {
+		return c.Component.LastHealthStates()
+	}
+	return c.hangHealthStates(timeouts)
+}
+
+// timedOutCheckResult returns the check result of the timed out check.
+// Below the threshold, the last health states of the component are reported as is.
+func (c *watchdogComponent) timedOutCheckResult(timeouts int) CheckResult {
+	cr := &watchdogCheckResult{
+		componentName: c.Name(),
+		summary:       fmt.Sprintf("check timed out after %s (%d consecutive timeout(s))", c.timeout, timeouts),
+	}
+	if timeouts < c.hangThreshold {
+		cr.states = c.Component.LastHealthStates()
+	} else {
+		cr.states = c.hangHealthStates(timeouts)
+	}
+	return cr
+}
+
+func (c *watchdogComponent) hangHealthStates(timeouts int) apiv1.HealthStates {
+	return apiv1.HealthStates{
+		{
+			Time:      metav1.NewTime(c.getTimeNowFunc()),
+			Component: c.Name(),
+			Name:      c.Name(),
+			Health:    apiv1.HealthStateTypeUnhealthy,
+			Reason:    WatchdogHangReason,
+			Error:     fmt.Sprintf("check timed out after %s (%d consecutive timeouts)", c.timeout, timeouts),
+			ExtraInfo: map[string]string{
+				WatchdogExtraInfoKey: fmt.Sprintf("%d", timeouts),
+			},
+		},
+	}
+}
+
+func (c *watchdogComponent) resetTimeouts() {
+	c.mu.Lock()
+	c.consecutiveTimeouts = 0
+	c.mu.Unlock()
+}
+
+var _ CheckResult = &watchdogCheckResult{}
+
+// watchdogCheckResult is the check result of a timed out check.
+type watchdogCheckResult struct {
+	componentName string
+	summary       string
+	states        apiv1.HealthStates
+}
+
+func (cr *watchdogCheckResult) ComponentName() string {
+	return cr.componentName
+}
+
+func (cr *watchdogCheckResult) String() string {
+	return cr.summary
+}
+
+func (cr *watchdogCheckResult) Summary() string {
+	return cr.summary
+}
+
+func (cr *watchdogCheckResult) HealthStateType() apiv1.HealthStateType {
+	return WorstHealthStateType(cr.states)
+}
+
+func (cr *watchdogCheckResult) HealthStates() apiv1.HealthStates {
+	return cr.states
+}
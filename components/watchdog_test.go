@@ -0,0 +1,145 @@
+package components
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// blockingComponent blocks the checks until released.
+type blockingComponent struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	checks int
+}
+
+func (b *blockingComponent) Name() string      { return "blocking" }
+func (b *blockingComponent) Tags() []string    { return nil }
+func (b *blockingComponent) IsSupported() bool { return true }
+func (b *blockingComponent) Start() error      { return nil }
+func (b *blockingComponent) Close() error      { return nil }
+func (b *blockingComponent) LastHealthStates() apiv1.HealthStates {
+	return apiv1.HealthStates{{Name: "blocking", Health: apiv1.HealthStateTypeHealthy, Reason: "last"}}
+}
+func (b *blockingComponent) Events(_ context.Context, _ time.Time) (apiv1.Events, error) {
+	return nil, nil
+}
+
+func (b *blockingComponent) Check() CheckResult {
+	b.mu.Lock()
+	b.checks++
+	b.mu.Unlock()
+
+	<-b.release
+	return &scriptedCheckResult{states: apiv1.HealthStates{{
+		Time:   metav1.Now(),
+		Name:   "blocking",
+		Health: apiv1.HealthStateTypeHealthy,
+		Reason: "checked",
+	}}}
+}
+
+func (b *blockingComponent) numChecks() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.checks
+}
+
+type blockingHealthSettableComponent struct {
+	*blockingComponent
+}
+
+func (b *blockingHealthSettableComponent) SetHealthy() error { return nil }
+
+func TestWatchdogConfigValidate(t *testing.T) {
+	assert.NoError(t, WatchdogConfig{}.Validate())
+	assert.Error(t, WatchdogConfig{Timeout: metav1.Duration{Duration: -time.Second}}.Validate())
+	assert.Error(t, WatchdogConfig{HangThreshold: -1}.Validate())
+
+	cfg := WatchdogConfig{}
+	assert.Equal(t, DefaultWatchdogTimeout, cfg.timeout())
+	assert.Equal(t, DefaultWatchdogHangThreshold, cfg.hangThreshold())
+}
+
+func TestWithWatchdogDisabled(t *testing.T) {
+	b := &blockingComponent{release: make(chan struct{})}
+	initFunc := WithWatchdog(func(*GPUdInstance) (Component, error) { return b, nil }, WatchdogConfig{Disabled: true})
+	c, err := initFunc(&GPUdInstance{})
+	require.NoError(t, err)
+	assert.Equal(t, Component(b), c)
+}
+
+func TestWatchdogCompletesInTime(t *testing.T) {
+	b := &blockingComponent{release: make(chan struct{})}
+	close(b.release)
+
+	c := newWatchdogComponent(b, WatchdogConfig{Timeout: metav1.Duration{Duration: time.Second}})
+	cr := c.Check()
+	require.NotNil(t, cr)
+	assert.Equal(t, "checked", cr.HealthStates()[0].Reason)
+	assert.Equal(t, "last", c.LastHealthStates()[0].Reason)
+}
+
+func TestWatchdogHang(t *testing.T) {
+	b := &blockingComponent{release: make(chan struct{})}
+	c := newWatchdogComponent(b, WatchdogConfig{
+		Timeout:       metav1.Duration{Duration: 20 * time.Millisecond},
+		HangThreshold: 2,
+	})
+
+	// below the threshold, the last health states are reported as is
+	cr := c.Check()
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, cr.HealthStateType())
+	assert.Equal(t, "last", cr.HealthStates()[0].Reason)
+	assert.Contains(t, cr.Summary(), "1 consecutive timeout(s)")
+
+	// the hung check is not started again
+	cr = c.Check()
+	assert.Equal(t, 1, b.numChecks())
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, cr.HealthStateType())
+	assert.Equal(t, WatchdogHangReason, cr.HealthStates()[0].Reason)
+	assert.Equal(t, "2", cr.HealthStates()[0].ExtraInfo[WatchdogExtraInfoKey])
+
+	states := c.LastHealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+	assert.Equal(t, WatchdogHangReason, states[0].Reason)
+
+	// the hung check returns, and the next check recovers
+	close(b.release)
+	require.Eventually(t, func() bool {
+		wc := c.(*watchdogComponent)
+		wc.mu.Lock()
+		defer wc.mu.Unlock()
+		return wc.inflight == nil
+	}, time.Second, 5*time.Millisecond)
+
+	cr = c.Check()
+	assert.Equal(t, 2, b.numChecks())
+	assert.Equal(t, "checked", cr.HealthStates()[0].Reason)
+	assert.Equal(t, "last", c.LastHealthStates()[0].Reason)
+}
+
+func TestWatchdogHealthSettable(t *testing.T) {
+	b := &blockingComponent{release: make(chan struct{})}
+	defer close(b.release)
+
+	c := newWatchdogComponent(&blockingHealthSettableComponent{blockingComponent: b}, WatchdogConfig{
+		Timeout:       metav1.Duration{Duration: 10 * time.Millisecond},
+		HangThreshold: 1,
+	})
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, c.Check().HealthStateType())
+	require.NoError(t, hs.SetHealthy())
+	assert.Equal(t, "last", c.LastHealthStates()[0].Reason)
+}
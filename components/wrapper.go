@@ -0,0 +1,119 @@
+package components
+
+// wrapComponent returns the wrapper component implementing the same optional interfaces
+// (HealthSettable and Deregisterable) as the underlying component, forwarded to the
+// underlying component, since the wrapper only exposes the methods of [Component].
+// The check loop of the underlying component (see [CheckLoop]) is forwarded as well.
+// If non-nil, onSetHealthy is called after the underlying component is set healthy,
+// to reset the state of the wrapper.
+func wrapComponent(wrapper Component, underlying Component, onSetHealthy func()) Component {
+	hs, _ := underlying.(HealthSettable)
+	dr, _ := underlying.(Deregisterable)
+	lb, _ := underlying.(checkLoopBinder)
+	if hs == nil && dr == nil && lb == nil {
+		return wrapper
+	}
+
+	fc := &forwardingComponent{Component: wrapper, checkLoopBinder: lb}
+	switch {
+	case hs != nil && dr != nil:
+		return &healthSettableDeregisterableComponent{
+			healthSettableComponent: &healthSettableComponent{
+				forwardingComponent: fc,
+				healthSettable:      hs,
+				onSetHealthy:        onSetHealthy,
+			},
+			deregisterable: dr,
+		}
+	case hs != nil:
+		return &healthSettableComponent{
+			forwardingComponent: fc,
+			healthSettable:      hs,
+			onSetHealthy:        onSetHealthy,
+		}
+	case dr != nil:
+		return &deregisterableComponent{
+			forwardingComponent: fc,
+			deregisterable:      dr,
+		}
+	default:
+		return fc
+	}
+}
+
+var _ checkLoopBinder = &forwardingComponent{}
+
+// forwardingComponent forwards the check loop of the underlying component, if any.
+type forwardingComponent struct {
+	Component
+	checkLoopBinder checkLoopBinder
+}
+
+func (c *forwardingComponent) bindCheckLoop(check func() CheckResult, gpudInstance *GPUdInstance) {
+	if c.checkLoopBinder != nil {
+		c.checkLoopBinder.bindCheckLoop(check, gpudInstance)
+	}
+}
+
+var _ HealthSettable = &healthSettableComponent{}
+
+type healthSettableComponent struct {
+	*forwardingComponent
+	healthSettable HealthSettable
+	onSetHealthy   func()
+}
+
+func (c *healthSettableComponent) SetHealthy() error {
+	if err := c.healthSettable.SetHealthy(); err != nil {
+		return err
+	}
+	if c.onSetHealthy != nil {
+		c.onSetHealthy()
+	}
+	return nil
+}
+
+var _ Deregisterable = &deregisterableComponent{}
+
+type deregisterableComponent struct {
+	*forwardingComponent
+	deregisterable Deregisterable
+}
+
+func (c *deregisterableComponent) CanDeregister() bool {
+	return c.deregisterable.CanDeregister()
+}
+
+var (
+	_ HealthSettable = &healthSettableDeregisterableComponent{}
+	_ Deregisterable = &healthSettableDeregisterableComponent{}
+)
+
+type healthSettableDeregisterableComponent struct {
+	*healthSettableComponent
+	deregisterable Deregisterable
+}
+
+func (c *healthSettableDeregisterableComponent) CanDeregister() bool {
+	return c.deregisterable.CanDeregister()
+}
+
+// wrapCheckResult returns the wrapper check result implementing the optional
+// CheckResultDebugger interface if the underlying check result does.
+func wrapCheckResult(wrapper CheckResult, underlying CheckResult) CheckResult {
+	if d, ok := underlying.(CheckResultDebugger); ok {
+		return &debuggerCheckResult{CheckResult: wrapper, debugger: d}
+	}
+	return wrapper
+}
+
+var _ CheckResultDebugger = &debuggerCheckResult{}
+
+type debuggerCheckResult struct {
+	CheckResult
+	debugger CheckResultDebugger
+}
+
+func (cr *debuggerCheckResult) Debug() string {
+	return cr.debugger.Debug()
+}
@@ -0,0 +1,66 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+type deregisterableScriptedComponent struct {
+	*scriptedHealthSettableComponent
+}
+
+func (d *deregisterableScriptedComponent) CanDeregister() bool { return true }
+
+type debuggerCheckResultStub struct {
+	*scriptedCheckResult
+}
+
+func (r *debuggerCheckResultStub) Debug() string { return "debug" }
+
+func TestWrapComponent(t *testing.T) {
+	wrapper := &scriptedComponent{}
+	assert.Same(t, wrapper, wrapComponent(wrapper, &scriptedComponent{}, nil))
+
+	settable := &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}
+	c := wrapComponent(wrapper, settable, nil)
+	_, ok := c.(HealthSettable)
+	assert.True(t, ok)
+	_, ok = c.(Deregisterable)
+	assert.False(t, ok)
+
+	inner := &deregisterableScriptedComponent{scriptedHealthSettableComponent: &scriptedHealthSettableComponent{scriptedComponent: &scriptedComponent{}}}
+	called := false
+	c = wrapComponent(wrapper, inner, func() { called = true })
+	hs, ok := c.(HealthSettable)
+	require.True(t, ok)
+	require.NoError(t, hs.SetHealthy())
+	assert.True(t, inner.setHealthyCalled)
+	assert.True(t, called)
+	dr, ok := c.(Deregisterable)
+	require.True(t, ok)
+	assert.True(t, dr.CanDeregister())
+
+	loop := &loopComponent{blockingComponent: &blockingComponent{}}
+	c = wrapComponent(wrapper, loop, nil)
+	_, ok = c.(checkLoopBinder)
+	assert.True(t, ok)
+	_, ok = c.(HealthSettable)
+	assert.False(t, ok)
+}
+
+func TestWrapCheckResult(t *testing.T) {
+	states := apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}
+	plain := &scriptedCheckResult{states: states}
+	wrapper := &hysteresisCheckResult{CheckResult: plain, states: states}
+	assert.Same(t, wrapper, wrapCheckResult(wrapper, plain))
+
+	cr := wrapCheckResult(wrapper, &debuggerCheckResultStub{scriptedCheckResult: plain})
+	d, ok := cr.(CheckResultDebugger)
+	require.True(t, ok)
+	assert.Equal(t, "debug", d.Debug())
+	assert.Equal(t, states, cr.HealthStates())
+}
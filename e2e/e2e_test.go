@@ -488,7 +488,7 @@ var _ = Describe("[GPUD E2E]", Ordered, func() {
 				}
 
 				_, err = clientv1.GetHealthStates(rootCtx, "https://"+ep, append(opts, clientv1.WithComponent("unknown!!!"))...)
-				Expect(err).To(Equal(errdefs.ErrNotFound), "expected ErrNotFound")
+				Expect(errdefs.IsNotFound(err)).To(BeTrue(), "expected ErrNotFound")
 			})
 		}
 	})
@@ -0,0 +1,132 @@
+package boottracker
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// rebootTimeTolerance is the tolerance to match the boot times
+// from the different sources (e.g., the reboot events and the boot records).
+const rebootTimeTolerance = time.Minute
+
+// MergeRebootEvents adds the boot times of the reboot events
+// (recorded before the boot tracking) not matching any of the boots.
+// The returned boots are in the ascending order of the boot time.
+func MergeRebootEvents(boots []Boot, rebootTimes []time.Time) []Boot {
+	merged := append([]Boot(nil), boots...)
+	for _, rt := range rebootTimes {
+		found := false
+		for _, b := range boots {
+			if d := b.Time.Sub(rt); d > -rebootTimeTolerance && d < rebootTimeTolerance {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, Boot{Time: rt.UTC()})
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	return merged
+}
+
+// Correlate builds the reboot history from the boots (in the ascending order of the boot time)
+// and the fatal events. The unhealthy components (currently not healthy) are used
+// to decide whether the latest reboot resolved the condition.
+func Correlate(boots []Boot, currentBootID string, events apiv1.Events, unhealthyComponents map[string]bool, now time.Time) apiv1.RebootHistory {
+	fatal := make(apiv1.Events, 0, len(events))
+	for _, ev := range events {
+		if ev.Type == apiv1.EventTypeFatal {
+			fatal = append(fatal, ev)
+		}
+	}
+	sort.SliceStable(fatal, func(i, j int) bool {
+		return fatal[i].Time.Time.Before(fatal[j].Time.Time)
+	})
+
+	hist := apiv1.RebootHistory{Reboots: make([]apiv1.Reboot, 0, len(boots))}
+	for i, b := range boots {
+		current := b.ID != "" && b.ID == currentBootID
+		r := apiv1.Reboot{
+			BootID:   b.ID,
+			BootTime: metav1.NewTime(b.Time),
+			Current:  current,
+		}
+
+		lastSeen := b.LastSeen
+		if current {
+			lastSeen = now
+		}
+		if !lastSeen.IsZero() {
+			r.LastSeen = &metav1.Time{Time: lastSeen}
+			r.Uptime = &metav1.Duration{Duration: lastSeen.Sub(b.Time)}
+		}
+
+		// the events between the previous boot and this boot were followed by this reboot
+		var prevBootTime time.Time
+		if i > 0 {
+			prevBootTime = boots[i-1].Time
+		}
+		var nextBootTime time.Time
+		if i+1 < len(boots) {
+			nextBootTime = boots[i+1].Time
+		}
+		for _, ev := range fatal {
+			if !ev.Time.Time.After(prevBootTime) || !ev.Time.Time.Before(b.Time) {
+				continue
+			}
+
+			pe := apiv1.RebootPrecedingEvent{Event: ev, Resolved: true}
+			if recurred := firstRecurrence(fatal, ev, b.Time, nextBootTime); recurred != nil {
+				pe.Resolved = false
+				pe.RecurredAt = &metav1.Time{Time: recurred.Time.Time}
+			} else if nextBootTime.IsZero() && unhealthyComponents[ev.Component] {
+				// rebooted to the latest boot, but the component is still not healthy
+				pe.Resolved = false
+			}
+			r.PrecedingEvents = append(r.PrecedingEvents, pe)
+		}
+
+		hist.Reboots = append(hist.Reboots, r)
+	}
+
+	if len(boots) > 0 {
+		latest := boots[len(boots)-1].Time
+		for _, ev := range fatal {
+			if !ev.Time.Time.Before(latest) {
+				hist.PendingEvents = append(hist.PendingEvents, ev)
+			}
+		}
+	}
+
+	// the current boot first
+	for i, j := 0, len(hist.Reboots)-1; i < j; i, j = i+1, j-1 {
+		hist.Reboots[i], hist.Reboots[j] = hist.Reboots[j], hist.Reboots[i]
+	}
+	return hist
+}
+
+// firstRecurrence returns the first event of the same component and name
+// during the boot (from the boot time until the next boot time, zero for now).
+func firstRecurrence(events apiv1.Events, ev apiv1.Event, bootTime time.Time, nextBootTime time.Time) *apiv1.Event {
+	for i := range events {
+		other := events[i]
+		if other.Component != ev.Component || other.Name != ev.Name {
+			continue
+		}
+		if other.Time.Time.Before(bootTime) {
+			continue
+		}
+		if !nextBootTime.IsZero() && !other.Time.Time.Before(nextBootTime) {
+			continue
+		}
+		return &events[i]
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package boottracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestMergeRebootEvents(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	boots := []Boot{{ID: "boot-2", Time: base.Add(24 * time.Hour)}}
+
+	merged := MergeRebootEvents(boots, []time.Time{
+		base,
+		// same as the tracked boot
+		base.Add(24*time.Hour + 10*time.Second),
+	})
+	require.Len(t, merged, 2)
+	assert.Equal(t, Boot{Time: base}, merged[0])
+	assert.Equal(t, "boot-2", merged[1].ID)
+}
+
+func TestCorrelate(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	boots := []Boot{
+		{ID: "boot-1", Time: base, LastSeen: base.Add(10 * time.Hour)},
+		{ID: "boot-2", Time: base.Add(11 * time.Hour), LastSeen: base.Add(20 * time.Hour)},
+		{ID: "boot-3", Time: base.Add(21 * time.Hour)},
+	}
+	ev := func(component string, name string, at time.Duration, typ apiv1.EventType) apiv1.Event {
+		return apiv1.Event{Component: component, Name: name, Time: metav1.NewTime(base.Add(at)), Type: typ}
+	}
+	events := apiv1.Events{
+		// resolved by the reboot to boot-2
+		ev("xid", "error_xid", 5*time.Hour, apiv1.EventTypeFatal),
+		// not resolved by the reboot to boot-2, recurred
+		ev("sxid", "error_sxid", 6*time.Hour, apiv1.EventTypeFatal),
+		ev("sxid", "error_sxid", 12*time.Hour, apiv1.EventTypeFatal),
+		// rebooted to the current boot, but still unhealthy
+		ev("ib", "ib_port_down", 19*time.Hour, apiv1.EventTypeFatal),
+		// not fatal
+		ev("os", "kmsg", 7*time.Hour, apiv1.EventTypeWarning),
+		// pending the reboot
+		ev("xid", "error_xid", 22*time.Hour, apiv1.EventTypeFatal),
+	}
+	now := base.Add(23 * time.Hour)
+
+	hist := Correlate(boots, "boot-3", events, map[string]bool{"ib": true}, now)
+	require.Len(t, hist.Reboots, 3)
+
+	current := hist.Reboots[0]
+	assert.Equal(t, "boot-3", current.BootID)
+	assert.True(t, current.Current)
+	require.NotNil(t, current.Uptime)
+	assert.Equal(t, 2*time.Hour, current.Uptime.Duration)
+	require.Len(t, current.PrecedingEvents, 2)
+	assert.Equal(t, "sxid", current.PrecedingEvents[0].Event.Component)
+	assert.True(t, current.PrecedingEvents[0].Resolved)
+	assert.Equal(t, "ib", current.PrecedingEvents[1].Event.Component)
+	assert.False(t, current.PrecedingEvents[1].Resolved)
+	assert.Nil(t, current.PrecedingEvents[1].RecurredAt)
+
+	boot2 := hist.Reboots[1]
+	assert.Equal(t, "boot-2", boot2.BootID)
+	assert.False(t, boot2.Current)
+	require.NotNil(t, boot2.Uptime)
+	assert.Equal(t, 9*time.Hour, boot2.Uptime.Duration)
+	require.Len(t, boot2.PrecedingEvents, 2)
+	assert.Equal(t, "xid", boot2.PrecedingEvents[0].Event.Component)
+	assert.True(t, boot2.PrecedingEvents[0].Resolved)
+	assert.Equal(t, "sxid", boot2.PrecedingEvents[1].Event.Component)
+	assert.False(t, boot2.PrecedingEvents[1].Resolved)
+	require.NotNil(t, boot2.PrecedingEvents[1].RecurredAt)
+	assert.Equal(t, base.Add(12*time.Hour), boot2.PrecedingEvents[1].RecurredAt.Time)
+
+	assert.Empty(t, hist.Reboots[2].PrecedingEvents)
+
+	require.Len(t, hist.PendingEvents, 1)
+	assert.Equal(t, base.Add(22*time.Hour), hist.PendingEvents[0].Time.Time)
+}
+
+func TestCorrelateNoBoots(t *testing.T) {
+	hist := Correlate(nil, "", apiv1.Events{{Type: apiv1.EventTypeFatal}}, nil, time.Now())
+	assert.Empty(t, hist.Reboots)
+	assert.Empty(t, hist.PendingEvents)
+}
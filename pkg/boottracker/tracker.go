@@ -0,0 +1,177 @@
+// Package boottracker records the host boots (boot IDs and boot times)
+// and correlates the fatal events with the following reboots,
+// so that it is known whether a suggested reboot was performed and resolved the issue.
+package boottracker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameBoots = "gpud_boots"
+
+	columnBootID    = "boot_id"
+	columnBootTime  = "boot_time"
+	columnFirstSeen = "first_seen"
+	columnLastSeen  = "last_seen"
+
+	// DefaultHeartbeatInterval is the interval to update the last seen time of the current boot.
+	DefaultHeartbeatInterval = time.Minute
+)
+
+// Boot is a host boot seen by GPUd.
+type Boot struct {
+	// ID is the boot ID (e.g., "/proc/sys/kernel/random/boot_id").
+	ID string
+	// Time is when the host booted.
+	Time time.Time
+	// FirstSeen is when GPUd first ran during the boot.
+	FirstSeen time.Time
+	// LastSeen is when GPUd was last seen running during the boot,
+	// which is the approximate shutdown time of the previous boots.
+	LastSeen time.Time
+}
+
+// CreateTable creates the table for the boots.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s INTEGER NOT NULL,
+	%s INTEGER NOT NULL,
+	%s INTEGER NOT NULL
+) WITHOUT ROWID;`, tableNameBoots, columnBootID, columnBootTime, columnFirstSeen, columnLastSeen))
+	return err
+}
+
+// Tracker records the current boot and lists the boot history.
+// Safe for concurrent use.
+type Tracker struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	current        Boot
+	getTimeNowFunc func() time.Time
+}
+
+// New creates the boot tracker and records the current boot.
+// The boot ID must be non-empty.
+func New(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, bootID string, bootTime time.Time) (*Tracker, error) {
+	if bootID == "" {
+		return nil, errors.New("boot id is empty")
+	}
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create boots table: %w", err)
+	}
+
+	t := &Tracker{
+		dbRW: dbRW,
+		dbRO: dbRO,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	now := t.getTimeNowFunc()
+	start := time.Now()
+	// keep the first seen time of the boot across the GPUd restarts
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s, %s) VALUES (?, ?, ?, ?)
+ON CONFLICT(%s) DO UPDATE SET %s = excluded.%s`,
+		tableNameBoots, columnBootID, columnBootTime, columnFirstSeen, columnLastSeen,
+		columnBootID, columnLastSeen, columnLastSeen),
+		bootID, bootTime.Unix(), now.Unix(), now.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record boot: %w", err)
+	}
+
+	boots, err := t.Boots(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range boots {
+		if b.ID == bootID {
+			t.current = b
+		}
+	}
+	return t, nil
+}
+
+// Current returns the current boot.
+func (t *Tracker) Current() Boot {
+	return t.current
+}
+
+// Start updates the last seen time of the current boot periodically,
+// until the context is canceled.
+func (t *Tracker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.heartbeat(ctx); err != nil {
+				log.Logger.Warnw("failed to update boot last seen time", "bootID", t.current.ID, "error", err)
+			}
+		}
+	}
+}
+
+func (t *Tracker) heartbeat(ctx context.Context) error {
+	start := time.Now()
+	_, err := t.dbRW.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, tableNameBoots, columnLastSeen, columnBootID),
+		t.getTimeNowFunc().Unix(), t.current.ID)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Boots returns the boots since the given time, in the ascending order of the boot time.
+func (t *Tracker) Boots(ctx context.Context, since time.Time) ([]Boot, error) {
+	start := time.Now()
+	rows, err := t.dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s FROM %s WHERE %s >= ? ORDER BY %s ASC`,
+		columnBootID, columnBootTime, columnFirstSeen, columnLastSeen, tableNameBoots, columnBootTime, columnBootTime),
+		since.Unix())
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var boots []Boot
+	for rows.Next() {
+		var b Boot
+		var bootTime, firstSeen, lastSeen int64
+		if err := rows.Scan(&b.ID, &bootTime, &firstSeen, &lastSeen); err != nil {
+			return nil, err
+		}
+		b.Time = time.Unix(bootTime, 0).UTC()
+		b.FirstSeen = time.Unix(firstSeen, 0).UTC()
+		b.LastSeen = time.Unix(lastSeen, 0).UTC()
+		boots = append(boots, b)
+	}
+	return boots, rows.Err()
+}
+
+// Purge deletes the boots before the given time.
+// The current boot is never deleted.
+func (t *Tracker) Purge(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	_, err := t.dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s < ? AND %s != ?`, tableNameBoots, columnBootTime, columnBootID),
+		before.Unix(), t.current.ID)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
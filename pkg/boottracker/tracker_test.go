@@ -0,0 +1,76 @@
+package boottracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestTracker(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := New(ctx, dbRW, dbRO, "", time.Now())
+	require.Error(t, err)
+
+	boot1 := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	t1, err := New(ctx, dbRW, dbRO, "boot-1", boot1)
+	require.NoError(t, err)
+	assert.Equal(t, "boot-1", t1.Current().ID)
+	assert.Equal(t, boot1, t1.Current().Time)
+	firstSeen := t1.Current().FirstSeen
+
+	// gpud restarted in the same boot keeps the first seen time
+	t1.getTimeNowFunc = func() time.Time { return firstSeen.Add(time.Hour) }
+	require.NoError(t, t1.heartbeat(ctx))
+	t1, err = New(ctx, dbRW, dbRO, "boot-1", boot1)
+	require.NoError(t, err)
+	assert.Equal(t, firstSeen, t1.Current().FirstSeen)
+
+	boot2 := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	t2, err := New(ctx, dbRW, dbRO, "boot-2", boot2)
+	require.NoError(t, err)
+
+	boots, err := t2.Boots(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, boots, 2)
+	assert.Equal(t, "boot-1", boots[0].ID)
+	assert.Equal(t, "boot-2", boots[1].ID)
+
+	boots, err = t2.Boots(ctx, boot2.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, boots, 1)
+	assert.Equal(t, "boot-2", boots[0].ID)
+
+	// the current boot is never purged
+	require.NoError(t, t2.Purge(ctx, time.Now()))
+	boots, err = t2.Boots(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, boots, 1)
+	assert.Equal(t, "boot-2", boots[0].ID)
+}
+
+func TestTrackerStart(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tr, err := New(ctx, dbRW, dbRO, "boot-1", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	lastSeen := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	tr.getTimeNowFunc = func() time.Time { return lastSeen }
+
+	go tr.Start(ctx, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		boots, err := tr.Boots(ctx, time.Time{})
+		return err == nil && len(boots) == 1 && boots[0].LastSeen.Equal(lastSeen)
+	}, 5*time.Second, 10*time.Millisecond)
+}
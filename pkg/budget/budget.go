@@ -0,0 +1,194 @@
+package budget
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// Budget tracks the data used within the current windows against the caps.
+// Safe for concurrent use.
+type Budget struct {
+	cfg            Config
+	getTimeNowFunc func() time.Time
+
+	mu      sync.Mutex
+	events  window
+	metrics window
+	upload  window
+
+	// eventsOver is the number of the lower-severity events
+	// seen over the cap within the current events window
+	eventsOver int64
+	// metricsSeq is incremented per metrics batch over budget,
+	// to rotate the series stored at the lower resolution
+	metricsSeq uint64
+}
+
+// window counts the usage within a fixed window.
+type window struct {
+	start time.Time
+	used  int64
+}
+
+// roll resets the usage if the window has passed.
+func (w *window) roll(now time.Time, d time.Duration) bool {
+	start := now.Truncate(d)
+	if w.start.Equal(start) {
+		return false
+	}
+	w.start = start
+	w.used = 0
+	return true
+}
+
+// New creates the data budget.
+// Returns nil if the config is nil, with which all the data is admitted.
+func New(cfg *Config) *Budget {
+	if cfg == nil {
+		return nil
+	}
+	return &Budget{
+		cfg: *cfg,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// isHighSeverity returns true if the event type is never sampled away.
+func isHighSeverity(eventType string) bool {
+	return eventType == string(apiv1.EventTypeCritical) || eventType == string(apiv1.EventTypeFatal)
+}
+
+// AdmitEvent returns true if the event is to be recorded.
+// Once over the hourly cap, 1 in every n lower-severity events is recorded,
+// where n grows with the overflow (1 in 2 for the first cap of the overflow,
+// 1 in 3 for the next, and so on), while the critical and fatal events
+// are always recorded.
+func (b *Budget) AdmitEvent(bucket string, eventType string) bool {
+	if b == nil || b.cfg.EventsPerHour == 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.events.roll(b.getTimeNowFunc(), time.Hour) {
+		b.eventsOver = 0
+	}
+
+	limit := int64(b.cfg.EventsPerHour)
+	if isHighSeverity(eventType) || b.events.used < limit {
+		b.events.used++
+		return true
+	}
+
+	over := b.eventsOver
+	b.eventsOver++
+	if over%(2+over/limit) == 0 {
+		b.events.used++
+		return true
+	}
+	metricSampledEvents.WithLabelValues(bucket, eventType).Inc()
+	return false
+}
+
+// SampleMetrics returns the metric samples to store.
+// Once the batch exceeds the remaining of the per-minute cap,
+// only 1 in every n series is stored per batch (rotated across the batches),
+// so that every series is still stored at the lower resolution.
+func (b *Budget) SampleMetrics(ms pkgmetrics.Metrics) pkgmetrics.Metrics {
+	if b == nil || b.cfg.MetricSamplesPerMinute == 0 || len(ms) == 0 {
+		return ms
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.metrics.roll(b.getTimeNowFunc(), time.Minute)
+
+	remaining := int64(b.cfg.MetricSamplesPerMinute) - b.metrics.used
+	if int64(len(ms)) <= remaining {
+		b.metrics.used += int64(len(ms))
+		return ms
+	}
+
+	// the series are all sampled away if no budget remains within the minute
+	var n uint64
+	if remaining > 0 {
+		n = uint64((int64(len(ms)) + remaining - 1) / remaining)
+	}
+	seq := b.metricsSeq
+	b.metricsSeq++
+
+	kept := make(pkgmetrics.Metrics, 0, max(remaining, 0))
+	sampled := make(map[string]int)
+	for _, m := range ms {
+		if n > 0 && (seriesHash(m)+seq)%n == 0 {
+			kept = append(kept, m)
+			continue
+		}
+		sampled[m.Component]++
+	}
+	for component, cnt := range sampled {
+		metricSampledMetricSamples.WithLabelValues(component).Add(float64(cnt))
+	}
+
+	b.metrics.used += int64(len(kept))
+	return kept
+}
+
+// seriesHash identifies the series of the metric sample by its component, name, and labels.
+func seriesHash(m pkgmetrics.Metric) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.Component))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(m.Name))
+
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(k))
+		_, _ = h.Write([]byte{'='})
+		_, _ = h.Write([]byte(m.Labels[k]))
+	}
+	return h.Sum64()
+}
+
+// AdmitUpload returns true if the upload of the given bytes is within the hourly cap.
+// The uploads of only the critical and fatal events are always admitted.
+// The caller is expected to retry with only the critical and fatal events
+// if not admitted, and to record the bytes sampled away with [Budget.SampledUpload].
+func (b *Budget) AdmitUpload(size int, highSeverityOnly bool) bool {
+	if b == nil || b.cfg.UploadBytesPerHour == 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.upload.roll(b.getTimeNowFunc(), time.Hour)
+
+	if !highSeverityOnly && b.upload.used+int64(size) > b.cfg.UploadBytesPerHour {
+		return false
+	}
+	b.upload.used += int64(size)
+	return true
+}
+
+// SampledUpload records the bytes not uploaded over the upload budget.
+func (b *Budget) SampledUpload(size int) {
+	if b == nil || size <= 0 {
+		return
+	}
+	metricSampledUploadBytes.Add(float64(size))
+}
@@ -0,0 +1,124 @@
+package budget
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	require.NoError(t, cfg.Validate())
+
+	require.NoError(t, (&Config{EventsPerHour: 10, MetricSamplesPerMinute: 10, UploadBytesPerHour: 10}).Validate())
+	require.Error(t, (&Config{EventsPerHour: -1}).Validate())
+	require.Error(t, (&Config{MetricSamplesPerMinute: -1}).Validate())
+	require.Error(t, (&Config{UploadBytesPerHour: -1}).Validate())
+}
+
+func TestNilBudget(t *testing.T) {
+	b := New(nil)
+	assert.Nil(t, b)
+	assert.True(t, b.AdmitEvent("xid", string(apiv1.EventTypeInfo)))
+	ms := pkgmetrics.Metrics{{Name: "m"}}
+	assert.Equal(t, ms, b.SampleMetrics(ms))
+	assert.True(t, b.AdmitUpload(1<<30, false))
+	b.SampledUpload(1)
+}
+
+func TestAdmitEvent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(&Config{EventsPerHour: 10})
+	b.getTimeNowFunc = func() time.Time { return now }
+
+	admitted := 0
+	for i := 0; i < 10; i++ {
+		if b.AdmitEvent("os", string(apiv1.EventTypeInfo)) {
+			admitted++
+		}
+	}
+	assert.Equal(t, 10, admitted)
+
+	// the critical and fatal events are never sampled away
+	assert.True(t, b.AdmitEvent("os", string(apiv1.EventTypeCritical)))
+	assert.True(t, b.AdmitEvent("os", string(apiv1.EventTypeFatal)))
+
+	// 1 in 2 for the first 10 events over the cap, and 1 in 3 for the next 10
+	admitted = 0
+	for i := 0; i < 10; i++ {
+		if b.AdmitEvent("os", string(apiv1.EventTypeWarning)) {
+			admitted++
+		}
+	}
+	assert.Equal(t, 5, admitted)
+	admitted = 0
+	for i := 0; i < 12; i++ {
+		if b.AdmitEvent("os", string(apiv1.EventTypeWarning)) {
+			admitted++
+		}
+	}
+	assert.Equal(t, 4, admitted)
+
+	// the next hour starts with the full budget
+	now = now.Add(time.Hour)
+	for i := 0; i < 10; i++ {
+		assert.True(t, b.AdmitEvent("os", string(apiv1.EventTypeInfo)))
+	}
+}
+
+func TestSampleMetrics(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(&Config{MetricSamplesPerMinute: 100})
+	b.getTimeNowFunc = func() time.Time { return now }
+
+	newBatch := func(n int) pkgmetrics.Metrics {
+		ms := make(pkgmetrics.Metrics, 0, n)
+		for i := 0; i < n; i++ {
+			ms = append(ms, pkgmetrics.Metric{Component: "comp", Name: "m", Labels: map[string]string{"gpu": strconv.Itoa(i)}})
+		}
+		return ms
+	}
+
+	// within the budget
+	assert.Len(t, b.SampleMetrics(newBatch(50)), 50)
+
+	// 400 samples over the remaining 50, so about 1 in 8 series is kept
+	kept := b.SampleMetrics(newBatch(400))
+	assert.NotEmpty(t, kept)
+	assert.Less(t, len(kept), 100)
+
+	// the budget is used up within the minute
+	now = now.Add(30 * time.Second)
+	b.metrics.used = 100
+	assert.Empty(t, b.SampleMetrics(newBatch(10)))
+
+	// the series kept rotate across the batches
+	now = now.Add(time.Minute)
+	first := b.SampleMetrics(newBatch(400))
+	now = now.Add(time.Minute)
+	second := b.SampleMetrics(newBatch(400))
+	require.NotEmpty(t, first)
+	require.NotEmpty(t, second)
+	assert.NotEqual(t, first[0].Labels["gpu"], second[0].Labels["gpu"])
+}
+
+func TestAdmitUpload(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(&Config{UploadBytesPerHour: 100})
+	b.getTimeNowFunc = func() time.Time { return now }
+
+	assert.True(t, b.AdmitUpload(60, false))
+	assert.False(t, b.AdmitUpload(60, false))
+	// the critical and fatal events are always uploaded
+	assert.True(t, b.AdmitUpload(60, true))
+	assert.False(t, b.AdmitUpload(1, false))
+
+	now = now.Add(time.Hour)
+	assert.True(t, b.AdmitUpload(60, false))
+}
@@ -0,0 +1,39 @@
+// Package budget enforces the per-node data budget on the events recorded,
+// the metric samples stored, and the bytes uploaded to the control plane,
+// with the lower-severity data sampled away when over budget,
+// so that a pathological node does not overwhelm the local storage
+// nor the upstream pipelines.
+package budget
+
+import "fmt"
+
+// Config configures the data budget, where zero disables the cap.
+type Config struct {
+	// EventsPerHour caps the events recorded per hour. Once over the cap,
+	// the info, warning, and unknown events are sampled, while the critical
+	// and fatal events are always recorded.
+	EventsPerHour int `json:"events_per_hour,omitempty"`
+	// MetricSamplesPerMinute caps the metric samples stored per minute.
+	// Once over the cap, each series is stored at a lower resolution.
+	MetricSamplesPerMinute int `json:"metric_samples_per_minute,omitempty"`
+	// UploadBytesPerHour caps the bytes uploaded to the control plane per hour.
+	// Once over the cap, the upload batches only carry the critical and fatal events.
+	UploadBytesPerHour int64 `json:"upload_bytes_per_hour,omitempty"`
+}
+
+// Validate returns an error if any of the caps is negative.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.EventsPerHour < 0 {
+		return fmt.Errorf("events_per_hour must be non-negative, got %d", cfg.EventsPerHour)
+	}
+	if cfg.MetricSamplesPerMinute < 0 {
+		return fmt.Errorf("metric_samples_per_minute must be non-negative, got %d", cfg.MetricSamplesPerMinute)
+	}
+	if cfg.UploadBytesPerHour < 0 {
+		return fmt.Errorf("upload_bytes_per_hour must be non-negative, got %d", cfg.UploadBytesPerHour)
+	}
+	return nil
+}
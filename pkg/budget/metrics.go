@@ -0,0 +1,44 @@
+package budget
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var (
+	metricSampledEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "budget",
+			Name:      "sampled_events_total",
+			Help:      "total number of the events not recorded over the events budget per bucket and event type",
+		},
+		[]string{"bucket", "type"},
+	)
+	metricSampledMetricSamples = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "budget",
+			Name:      "sampled_metric_samples_total",
+			Help:      "total number of the metric samples not stored over the metric samples budget per component",
+		},
+		[]string{"component"},
+	)
+	metricSampledUploadBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "budget",
+			Name:      "sampled_upload_bytes_total",
+			Help:      "total number of the bytes not uploaded to the control plane over the upload budget",
+		},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricSampledEvents,
+		metricSampledMetricSamples,
+		metricSampledUploadBytes,
+	)
+}
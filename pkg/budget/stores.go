@@ -0,0 +1,72 @@
+package budget
+
+import (
+	"context"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// WrapEventStore returns the event store whose buckets insert
+// the events admitted by the budget, and skip the rest.
+// Returns the store as is if the budget is nil.
+func WrapEventStore(store eventstore.Store, b *Budget) eventstore.Store {
+	if b == nil || store == nil {
+		return store
+	}
+	return &eventStore{Store: store, budget: b}
+}
+
+var _ eventstore.Store = &eventStore{}
+
+type eventStore struct {
+	eventstore.Store
+	budget *Budget
+}
+
+func (s *eventStore) Bucket(name string, opts ...eventstore.OpOption) (eventstore.Bucket, error) {
+	bucket, err := s.Store.Bucket(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &eventBucket{Bucket: bucket, budget: s.budget}, nil
+}
+
+var _ eventstore.Bucket = &eventBucket{}
+
+type eventBucket struct {
+	eventstore.Bucket
+	budget *Budget
+}
+
+func (b *eventBucket) Insert(ctx context.Context, ev eventstore.Event) error {
+	if !b.budget.AdmitEvent(b.Name(), ev.Type) {
+		return nil
+	}
+	return b.Bucket.Insert(ctx, ev)
+}
+
+// WrapMetricsStore returns the metrics store that records
+// the metric samples sampled by the budget.
+// Returns the store as is if the budget is nil.
+func WrapMetricsStore(store pkgmetrics.Store, b *Budget) pkgmetrics.Store {
+	if b == nil || store == nil {
+		return store
+	}
+	return &metricsStore{Store: store, budget: b}
+}
+
+var _ pkgmetrics.Store = &metricsStore{}
+
+type metricsStore struct {
+	pkgmetrics.Store
+	budget *Budget
+}
+
+func (s *metricsStore) Record(ctx context.Context, ms ...pkgmetrics.Metric) error {
+	ms = s.budget.SampleMetrics(ms)
+	if len(ms) == 0 {
+		return nil
+	}
+	return s.Store.Record(ctx, ms...)
+}
@@ -0,0 +1,67 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestWrapEventStore(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	assert.Equal(t, store, WrapEventStore(store, nil))
+
+	wrapped := WrapEventStore(store, New(&Config{EventsPerHour: 1}))
+	bucket, err := wrapped.Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for i, typ := range []apiv1.EventType{apiv1.EventTypeInfo, apiv1.EventTypeInfo, apiv1.EventTypeInfo, apiv1.EventTypeFatal} {
+		require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: now.Add(time.Duration(i) * time.Second), Name: "ev", Type: string(typ)}))
+	}
+
+	// the first info within the cap, 1 in 2 over the cap, and the fatal
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 3)
+	assert.Equal(t, string(apiv1.EventTypeFatal), evs[0].Type)
+}
+
+type fakeMetricsStore struct {
+	pkgmetrics.Store
+	recorded pkgmetrics.Metrics
+}
+
+func (f *fakeMetricsStore) Record(_ context.Context, ms ...pkgmetrics.Metric) error {
+	f.recorded = append(f.recorded, ms...)
+	return nil
+}
+
+func TestWrapMetricsStore(t *testing.T) {
+	store := &fakeMetricsStore{}
+	assert.Equal(t, pkgmetrics.Store(store), WrapMetricsStore(store, nil))
+
+	b := New(&Config{MetricSamplesPerMinute: 2})
+	wrapped := WrapMetricsStore(store, b)
+
+	ctx := context.Background()
+	require.NoError(t, wrapped.Record(ctx, pkgmetrics.Metric{Name: "a"}, pkgmetrics.Metric{Name: "b"}))
+	assert.Len(t, store.recorded, 2)
+
+	// the budget is used up within the minute
+	require.NoError(t, wrapped.Record(ctx, pkgmetrics.Metric{Name: "c"}))
+	assert.Len(t, store.recorded, 2)
+}
@@ -0,0 +1,186 @@
+// Package capabilities detects the data sources available on the host
+// (e.g., NVML, nvidia-smi, kmsg), so that the components running without
+// some of them can be diagnosed remotely.
+package capabilities
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+const (
+	// NVML is the NVIDIA Management Library, for all the NVIDIA GPU components.
+	NVML = "nvml"
+	// NvidiaSMI is the "nvidia-smi" executable.
+	NvidiaSMI = "nvidia-smi"
+	// Ibstat is the "ibstat" executable of the InfiniBand diagnostics.
+	Ibstat = "ibstat"
+	// DCGM is the NVIDIA Data Center GPU Manager ("dcgmi" executable).
+	DCGM = "dcgm"
+	// Ipmitool is the "ipmitool" executable with the in-band IPMI device.
+	Ipmitool = "ipmitool"
+	// Kmsg is the read access to the kernel message buffer.
+	Kmsg = "kmsg"
+)
+
+const (
+	defaultDevKmsg    = "/dev/kmsg"
+	defaultIPMIDevice = "/dev/ipmi0"
+)
+
+// probe returns the capability, with the reason if not available.
+type probe func() apiv1.Capability
+
+// Detector probes the capabilities, and keeps the last probed ones.
+// Safe for concurrent use.
+type Detector struct {
+	probes         []probe
+	getTimeNowFunc func() time.Time
+
+	mu   sync.RWMutex
+	last apiv1.CapabilitiesReport
+}
+
+// NewDetector creates the detector of the built-in capabilities.
+// The NVML instance may be nil if not loaded.
+func NewDetector(nvmlInstance nvidianvml.Instance) *Detector {
+	return &Detector{
+		probes: []probe{
+			func() apiv1.Capability {
+				return probeNVML(nvmlInstance)
+			},
+			func() apiv1.Capability {
+				return probeExecutable(NvidiaSMI, NvidiaSMI)
+			},
+			func() apiv1.Capability {
+				return probeExecutable(Ibstat, Ibstat)
+			},
+			func() apiv1.Capability {
+				return probeExecutable(DCGM, "dcgmi")
+			},
+			func() apiv1.Capability {
+				return probeIpmitool(defaultIPMIDevice)
+			},
+			func() apiv1.Capability {
+				return probeReadable(Kmsg, defaultDevKmsg)
+			},
+		},
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Probe probes all the capabilities, and returns the report without the components.
+func (d *Detector) Probe() apiv1.CapabilitiesReport {
+	caps := make([]apiv1.Capability, 0, len(d.probes))
+	for _, p := range d.probes {
+		caps = append(caps, p())
+	}
+	report := apiv1.CapabilitiesReport{
+		Time:         metav1.NewTime(d.getTimeNowFunc()),
+		Capabilities: caps,
+	}
+
+	d.mu.Lock()
+	d.last = report
+	d.mu.Unlock()
+
+	return report
+}
+
+// Last returns the last probed capabilities, or the zero report if not probed yet.
+func (d *Detector) Last() apiv1.CapabilitiesReport {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.last
+}
+
+// Available returns true if the capability was available as of the last probe.
+func (d *Detector) Available(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, c := range d.last.Capabilities {
+		if c.Name == name {
+			return c.Available
+		}
+	}
+	return false
+}
+
+// Report returns the last probed capabilities, with the capabilities of the components
+// from the component names to their required capabilities.
+func (d *Detector) Report(required map[string][]string) apiv1.CapabilitiesReport {
+	report := d.Last()
+	for name, caps := range required {
+		if len(caps) == 0 {
+			continue
+		}
+		cc := apiv1.ComponentCapabilities{Component: name}
+		for _, c := range caps {
+			if d.Available(c) {
+				cc.Using = append(cc.Using, c)
+			} else {
+				cc.DegradedWithout = append(cc.DegradedWithout, c)
+			}
+		}
+		report.Components = append(report.Components, cc)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Component < report.Components[j].Component
+	})
+	return report
+}
+
+func probeNVML(nvmlInstance nvidianvml.Instance) apiv1.Capability {
+	c := apiv1.Capability{Name: NVML}
+	switch {
+	case nvmlInstance == nil:
+		c.Reason = "NVML instance not initialized"
+	case !nvmlInstance.NVMLExists():
+		c.Reason = "NVML library not found"
+	default:
+		c.Available = true
+	}
+	return c
+}
+
+func probeExecutable(name string, bin string) apiv1.Capability {
+	c := apiv1.Capability{Name: name}
+	p, err := pkgfile.LocateExecutable(bin)
+	if err != nil {
+		c.Reason = err.Error()
+		return c
+	}
+	c.Available = true
+	c.Path = p
+	return c
+}
+
+func probeIpmitool(device string) apiv1.Capability {
+	if _, err := os.Stat(device); err != nil {
+		return apiv1.Capability{Name: Ipmitool, Reason: "IPMI device not found: " + err.Error()}
+	}
+	return probeExecutable(Ipmitool, Ipmitool)
+}
+
+// probeReadable checks the read permission of the file (e.g., "/dev/kmsg" requires root).
+func probeReadable(name string, file string) apiv1.Capability {
+	c := apiv1.Capability{Name: name, Path: file}
+	f, err := os.Open(file)
+	if err != nil {
+		c.Reason = err.Error()
+		return c
+	}
+	_ = f.Close()
+	c.Available = true
+	return c
+}
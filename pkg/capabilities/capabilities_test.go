@@ -0,0 +1,103 @@
+package capabilities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestDetector(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	kmsgAvailable := false
+	d := &Detector{
+		probes: []probe{
+			func() apiv1.Capability { return apiv1.Capability{Name: NVML, Available: true} },
+			func() apiv1.Capability {
+				if kmsgAvailable {
+					return apiv1.Capability{Name: Kmsg, Available: true}
+				}
+				return apiv1.Capability{Name: Kmsg, Reason: "permission denied"}
+			},
+		},
+		getTimeNowFunc: func() time.Time { return now },
+	}
+
+	// not probed yet
+	assert.False(t, d.Available(NVML))
+	assert.Empty(t, d.Last().Capabilities)
+
+	report := d.Probe()
+	assert.Equal(t, now, report.Time.Time)
+	require.Len(t, report.Capabilities, 2)
+	assert.True(t, d.Available(NVML))
+	assert.False(t, d.Available(Kmsg))
+	assert.False(t, d.Available(Ipmitool))
+
+	required := map[string][]string{
+		"xid":  {NVML, Kmsg},
+		"cpu":  {Kmsg},
+		"pci":  nil,
+		"nvml": {NVML},
+	}
+	report = d.Report(required)
+	assert.Equal(t, []apiv1.ComponentCapabilities{
+		{Component: "cpu", DegradedWithout: []string{Kmsg}},
+		{Component: "nvml", Using: []string{NVML}},
+		{Component: "xid", Using: []string{NVML}, DegradedWithout: []string{Kmsg}},
+	}, report.Components)
+
+	kmsgAvailable = true
+	d.Probe()
+	assert.True(t, d.Available(Kmsg))
+	// the last report does not have the components
+	assert.Empty(t, d.Last().Components)
+}
+
+func TestProbeNVML(t *testing.T) {
+	c := probeNVML(nil)
+	assert.Equal(t, NVML, c.Name)
+	assert.False(t, c.Available)
+	assert.Equal(t, "NVML instance not initialized", c.Reason)
+}
+
+func TestProbeExecutable(t *testing.T) {
+	c := probeExecutable(DCGM, "gpud-test-nonexistent-binary")
+	assert.Equal(t, DCGM, c.Name)
+	assert.False(t, c.Available)
+	assert.NotEmpty(t, c.Reason)
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "ibstat")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	c = probeExecutable(Ibstat, "ibstat")
+	assert.True(t, c.Available)
+	assert.Equal(t, bin, c.Path)
+	assert.Empty(t, c.Reason)
+}
+
+func TestProbeIpmitool(t *testing.T) {
+	c := probeIpmitool(filepath.Join(t.TempDir(), "ipmi0"))
+	assert.Equal(t, Ipmitool, c.Name)
+	assert.False(t, c.Available)
+	assert.Contains(t, c.Reason, "IPMI device not found")
+}
+
+func TestProbeReadable(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "kmsg")
+	c := probeReadable(Kmsg, f)
+	assert.False(t, c.Available)
+	assert.NotEmpty(t, c.Reason)
+
+	require.NoError(t, os.WriteFile(f, nil, 0o644))
+	c = probeReadable(Kmsg, f)
+	assert.True(t, c.Available)
+	assert.Equal(t, f, c.Path)
+}
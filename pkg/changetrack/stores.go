@@ -0,0 +1,102 @@
+package changetrack
+
+import (
+	"context"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// WrapEventStore returns the event store whose buckets record the changes
+// of the events of the component of the bucket name.
+// Returns the store as is if the tracker is nil.
+func WrapEventStore(store eventstore.Store, t *Tracker) eventstore.Store {
+	if t == nil || store == nil {
+		return store
+	}
+	return &eventStore{Store: store, tracker: t}
+}
+
+var _ eventstore.Store = &eventStore{}
+
+type eventStore struct {
+	eventstore.Store
+	tracker *Tracker
+}
+
+func (s *eventStore) Bucket(name string, opts ...eventstore.OpOption) (eventstore.Bucket, error) {
+	bucket, err := s.Store.Bucket(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &eventBucket{Bucket: bucket, component: name, tracker: s.tracker}, nil
+}
+
+var _ eventstore.Bucket = &eventBucket{}
+
+type eventBucket struct {
+	eventstore.Bucket
+	// the bucket name passed to the store, as the bucket may report the table name
+	component string
+	tracker   *Tracker
+}
+
+func (b *eventBucket) Insert(ctx context.Context, ev eventstore.Event) error {
+	if err := b.Bucket.Insert(ctx, ev); err != nil {
+		return err
+	}
+	b.tracker.Touch(b.component, KindEvents, time.Now())
+	return nil
+}
+
+func (b *eventBucket) Purge(ctx context.Context, beforeTimestamp int64) (int, error) {
+	purged, err := b.Bucket.Purge(ctx, beforeTimestamp)
+	if purged > 0 {
+		b.tracker.Touch(b.component, KindEvents, time.Now())
+	}
+	return purged, err
+}
+
+// WrapMetricsStore returns the metrics store that records the changes
+// of the metrics of the components of the recorded data points.
+// Returns the store as is if the tracker is nil.
+func WrapMetricsStore(store pkgmetrics.Store, t *Tracker) pkgmetrics.Store {
+	if t == nil || store == nil {
+		return store
+	}
+	return &metricsStore{Store: store, tracker: t}
+}
+
+var _ pkgmetrics.Store = &metricsStore{}
+
+type metricsStore struct {
+	pkgmetrics.Store
+	tracker *Tracker
+}
+
+func (s *metricsStore) Record(ctx context.Context, ms ...pkgmetrics.Metric) error {
+	if err := s.Store.Record(ctx, ms...); err != nil {
+		return err
+	}
+	now := time.Now()
+	touched := make(map[string]struct{})
+	for _, m := range ms {
+		if _, ok := touched[m.Component]; ok {
+			continue
+		}
+		touched[m.Component] = struct{}{}
+		s.tracker.Touch(m.Component, KindMetrics, now)
+	}
+	return nil
+}
+
+func (s *metricsStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged, err := s.Store.Purge(ctx, before)
+	if purged > 0 {
+		// the purged data points are not reported by the component,
+		// thus every component is treated as changed
+		s.tracker.TouchAll(KindMetrics, time.Now())
+	}
+	return purged, err
+}
@@ -0,0 +1,78 @@
+package changetrack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestWrapEventStore(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	assert.Equal(t, store, WrapEventStore(store, nil))
+
+	tr := New()
+	bucket, err := WrapEventStore(store, tr).Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	v0 := tr.Version("", []string{"test"}, KindEvents)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: now.Add(-time.Hour), Name: "old", Type: "Warning"}))
+	v1 := tr.Version("", []string{"test"}, KindEvents)
+	assert.NotEqual(t, v0.Token, v1.Token)
+
+	purged, err := bucket.Purge(ctx, now.Add(-2*time.Hour).Unix())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.Equal(t, v1.Token, tr.Version("", []string{"test"}, KindEvents).Token, "nothing purged")
+
+	purged, err = bucket.Purge(ctx, now.Unix())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NotEqual(t, v1.Token, tr.Version("", []string{"test"}, KindEvents).Token)
+}
+
+func TestWrapMetricsStore(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := pkgmetricsstore.NewSQLiteStore(ctx, dbRW, dbRO, pkgmetricsstore.DefaultTableName)
+	require.NoError(t, err)
+	assert.Equal(t, store, WrapMetricsStore(store, nil))
+
+	tr := New()
+	wrapped := WrapMetricsStore(store, tr)
+
+	a0 := tr.Version("", []string{"a"}, KindMetrics)
+	b0 := tr.Version("", []string{"b"}, KindMetrics)
+
+	now := time.Now().UTC()
+	require.NoError(t, wrapped.Record(ctx,
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-time.Hour).UnixMilli(), Component: "a", Name: "m0", Value: 1},
+		pkgmetrics.Metric{UnixMilliseconds: now.Add(-time.Hour).UnixMilli(), Component: "a", Name: "m1", Value: 1},
+	))
+	a1 := tr.Version("", []string{"a"}, KindMetrics)
+	assert.NotEqual(t, a0.Token, a1.Token)
+	assert.Equal(t, b0, tr.Version("", []string{"b"}, KindMetrics), "other component not changed")
+
+	purged, err := wrapped.Purge(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.NotEqual(t, a1.Token, tr.Version("", []string{"a"}, KindMetrics).Token)
+	assert.NotEqual(t, b0.Token, tr.Version("", []string{"b"}, KindMetrics).Token, "purge changes every component")
+}
@@ -0,0 +1,203 @@
+// Package changetrack tracks the changes of the health states, the events,
+// and the metrics of each component, so that the polling requests for the
+// unchanged data are answered as not modified, without reading and
+// serializing the data again.
+package changetrack
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// Kind is the kind of the data of a component.
+type Kind string
+
+const (
+	KindStates  Kind = "states"
+	KindEvents  Kind = "events"
+	KindMetrics Kind = "metrics"
+)
+
+// Tracker tracks the revision and the last modified time
+// of each kind of the data of each component.
+type Tracker struct {
+	// start is the last modified time of the data never changed
+	// since the tracker is created (e.g., the events recorded before the restart),
+	// and also distinguishes the revisions before and after the restart
+	start time.Time
+
+	mu      sync.RWMutex
+	entries map[entryKey]*entry
+	// all is the changes of the kinds of the data of every component
+	all map[Kind]*entry
+}
+
+type entryKey struct {
+	component string
+	kind      Kind
+}
+
+type entry struct {
+	revision     uint64
+	lastModified time.Time
+	// fingerprint is the hash of the last observed health states
+	fingerprint uint64
+}
+
+// New creates a new tracker.
+func New() *Tracker {
+	return newTracker(time.Now().UTC())
+}
+
+func newTracker(start time.Time) *Tracker {
+	return &Tracker{
+		start:   start,
+		entries: make(map[entryKey]*entry),
+		all:     make(map[Kind]*entry),
+	}
+}
+
+// Touch records the change of the kind of the data of the component at the time.
+func (t *Tracker) Touch(component string, kind Kind, at time.Time) {
+	if t == nil || component == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.touchLocked(t.getEntryLocked(component, kind), at)
+}
+
+// TouchAll records the change of the kind of the data of every component
+// at the time (e.g., the old data points purged from the store).
+func (t *Tracker) TouchAll(kind Kind, at time.Time) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.all[kind]
+	if !ok {
+		e = &entry{lastModified: t.start}
+		t.all[kind] = e
+	}
+	t.touchLocked(e, at)
+}
+
+// ObserveStates records the change of the health states of the component
+// if they differ from the last observed ones (e.g., checked again).
+func (t *Tracker) ObserveStates(component string, states apiv1.HealthStates, at time.Time) {
+	if t == nil || component == "" {
+		return
+	}
+
+	fp, ok := fingerprint(states)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.getEntryLocked(component, KindStates)
+	if ok && e.revision > 0 && e.fingerprint == fp {
+		return
+	}
+	e.fingerprint = fp
+	t.touchLocked(e, at)
+}
+
+func (t *Tracker) getEntryLocked(component string, kind Kind) *entry {
+	k := entryKey{component: component, kind: kind}
+	e, ok := t.entries[k]
+	if !ok {
+		e = &entry{lastModified: t.start}
+		t.entries[k] = e
+	}
+	return e
+}
+
+func (t *Tracker) touchLocked(e *entry, at time.Time) {
+	e.revision++
+	if at.After(e.lastModified) {
+		e.lastModified = at.UTC()
+	}
+}
+
+// Version is the version of the data of a set of the components.
+type Version struct {
+	// Token changes whenever any of the data changes, or the tracker is recreated.
+	Token string
+	// LastModified is the latest change time of the data.
+	LastModified time.Time
+}
+
+// Version returns the version of the kinds of the data of the components,
+// for the variant of the response (e.g., the request path and the query).
+func (t *Tracker) Version(variant string, components []string, kinds ...Kind) Version {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(t.start.UnixNano()))
+	_, _ = h.Write(b[:])
+	_, _ = h.Write([]byte(variant))
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	lastModified := t.start
+	for _, kind := range kinds {
+		var revision uint64
+		if e, ok := t.all[kind]; ok {
+			revision = e.revision
+			if e.lastModified.After(lastModified) {
+				lastModified = e.lastModified
+			}
+		}
+
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(kind))
+		binary.BigEndian.PutUint64(b[:], revision)
+		_, _ = h.Write(b[:])
+	}
+	for _, component := range components {
+		for _, kind := range kinds {
+			var revision uint64
+			if e, ok := t.entries[entryKey{component: component, kind: kind}]; ok {
+				revision = e.revision
+				if e.lastModified.After(lastModified) {
+					lastModified = e.lastModified
+				}
+			}
+
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(component))
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(kind))
+			binary.BigEndian.PutUint64(b[:], revision)
+			_, _ = h.Write(b[:])
+		}
+	}
+
+	return Version{
+		Token:        hex.EncodeToString(h.Sum(nil)),
+		LastModified: lastModified,
+	}
+}
+
+// fingerprint returns the hash of the health states,
+// or false if the health states cannot be encoded (always treated as changed).
+func fingerprint(states apiv1.HealthStates) (uint64, bool) {
+	b, err := json.Marshal(states)
+	if err != nil {
+		return 0, false
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64(), true
+}
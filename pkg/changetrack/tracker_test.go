@@ -0,0 +1,74 @@
+package changetrack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestTrackerVersion(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := newTracker(start)
+
+	v0 := tr.Version("/v1/events", []string{"a", "b"}, KindEvents)
+	assert.Equal(t, start, v0.LastModified, "never changed since the start")
+	assert.NotEmpty(t, v0.Token)
+	assert.Equal(t, v0, tr.Version("/v1/events", []string{"a", "b"}, KindEvents), "stable if not changed")
+	assert.NotEqual(t, v0.Token, tr.Version("/v1/events?components=a", []string{"a", "b"}, KindEvents).Token, "varies by the variant")
+
+	// the changes of the other components and kinds do not change the version
+	tr.Touch("c", KindEvents, start.Add(time.Minute))
+	tr.Touch("a", KindMetrics, start.Add(time.Minute))
+	tr.Touch("", KindEvents, start.Add(time.Minute))
+	assert.Equal(t, v0, tr.Version("/v1/events", []string{"a", "b"}, KindEvents))
+
+	tr.Touch("b", KindEvents, start.Add(2*time.Minute))
+	v1 := tr.Version("/v1/events", []string{"a", "b"}, KindEvents)
+	assert.NotEqual(t, v0.Token, v1.Token)
+	assert.Equal(t, start.Add(2*time.Minute), v1.LastModified)
+
+	// the change at an earlier time still changes the token, not the last modified time
+	tr.Touch("a", KindEvents, start.Add(time.Minute))
+	v2 := tr.Version("/v1/events", []string{"a", "b"}, KindEvents)
+	assert.NotEqual(t, v1.Token, v2.Token)
+	assert.Equal(t, start.Add(2*time.Minute), v2.LastModified)
+
+	tr.TouchAll(KindEvents, start.Add(3*time.Minute))
+	v3 := tr.Version("/v1/events", []string{"a", "b"}, KindEvents)
+	assert.NotEqual(t, v2.Token, v3.Token)
+	assert.Equal(t, start.Add(3*time.Minute), v3.LastModified)
+
+	// recreated tracker (e.g., restarted) does not reuse the tokens
+	assert.NotEqual(t, v0.Token, newTracker(start.Add(time.Hour)).Version("/v1/events", []string{"a", "b"}, KindEvents).Token)
+
+	var nilTracker *Tracker
+	nilTracker.Touch("a", KindEvents, start)
+	nilTracker.TouchAll(KindEvents, start)
+	nilTracker.ObserveStates("a", nil, start)
+}
+
+func TestTrackerObserveStates(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := newTracker(start)
+
+	checked := metav1.NewTime(start.Add(time.Minute))
+	states := apiv1.HealthStates{{Time: checked, Component: "a", Health: apiv1.HealthStateTypeHealthy}}
+
+	v0 := tr.Version("/v1/states", []string{"a"}, KindStates)
+	tr.ObserveStates("a", states, start.Add(time.Minute))
+	v1 := tr.Version("/v1/states", []string{"a"}, KindStates)
+	assert.NotEqual(t, v0.Token, v1.Token, "first observed")
+	assert.Equal(t, start.Add(time.Minute), v1.LastModified)
+
+	tr.ObserveStates("a", apiv1.HealthStates{{Time: checked, Component: "a", Health: apiv1.HealthStateTypeHealthy}}, start.Add(2*time.Minute))
+	assert.Equal(t, v1, tr.Version("/v1/states", []string{"a"}, KindStates), "same states")
+
+	tr.ObserveStates("a", apiv1.HealthStates{{Time: checked, Component: "a", Health: apiv1.HealthStateTypeUnhealthy}}, start.Add(3*time.Minute))
+	v2 := tr.Version("/v1/states", []string{"a"}, KindStates)
+	assert.NotEqual(t, v1.Token, v2.Token)
+	assert.Equal(t, start.Add(3*time.Minute), v2.LastModified)
+}
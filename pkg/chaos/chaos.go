@@ -0,0 +1,193 @@
+// Package chaos randomly injects failures into the GPUd internal boundaries
+// (e.g., SQLite writes, NVML calls, control plane connections, plugin runs),
+// to validate that GPUd degrades gracefully under partial internal failures.
+// Only for the resilience testing, never enable it in production.
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// Boundary is the internal boundary to inject the failures into.
+type Boundary string
+
+const (
+	// BoundarySQLiteWrite fails the SQLite write transactions.
+	BoundarySQLiteWrite Boundary = "sqlite_write"
+	// BoundaryNVML times out the NVML device queries.
+	BoundaryNVML Boundary = "nvml"
+	// BoundaryControlPlane disconnects the control plane session.
+	BoundaryControlPlane Boundary = "control_plane"
+	// BoundaryPlugin times out the custom plugin runs.
+	BoundaryPlugin Boundary = "plugin"
+)
+
+const (
+	// DefaultProbability is the default failure probability of each boundary.
+	DefaultProbability = 0.05
+	// DefaultNVMLTimeoutDelay is the default delay of the NVML timeouts.
+	DefaultNVMLTimeoutDelay = 2 * time.Second
+	// DefaultControlPlaneCheckInterval is the default interval to decide
+	// whether to disconnect the control plane session.
+	DefaultControlPlaneCheckInterval = time.Minute
+)
+
+// Config is the chaos mode configuration.
+// The probabilities are in [0, 1], where 0 never fails and 1 always fails.
+type Config struct {
+	// Seed is the random seed to reproduce the failures, or 0 to seed with the current time.
+	Seed int64 `json:"seed,omitempty"`
+
+	// SQLiteWriteError is the probability to fail each SQLite write transaction.
+	SQLiteWriteError float64 `json:"sqlite_write_error"`
+	// NVMLTimeout is the probability to time out each NVML device query.
+	NVMLTimeout float64 `json:"nvml_timeout"`
+	// NVMLTimeoutDelay is how long a timed out NVML query blocks before returning.
+	NVMLTimeoutDelay metav1.Duration `json:"nvml_timeout_delay,omitempty"`
+	// ControlPlaneDisconnect is the probability to disconnect the control plane session,
+	// checked every control plane check interval.
+	ControlPlaneDisconnect float64 `json:"control_plane_disconnect"`
+	// ControlPlaneCheckInterval is the interval to check the control plane disconnects.
+	ControlPlaneCheckInterval metav1.Duration `json:"control_plane_check_interval,omitempty"`
+	// PluginTimeout is the probability to time out each custom plugin run.
+	PluginTimeout float64 `json:"plugin_timeout"`
+}
+
+// DefaultConfig returns the chaos config with the default probability for all boundaries.
+func DefaultConfig() Config {
+	return Config{
+		SQLiteWriteError:       DefaultProbability,
+		NVMLTimeout:            DefaultProbability,
+		ControlPlaneDisconnect: DefaultProbability,
+		PluginTimeout:          DefaultProbability,
+	}
+}
+
+// SetDefaults sets the default delays and intervals if not set.
+func (cfg *Config) SetDefaults() {
+	if cfg.NVMLTimeoutDelay.Duration == 0 {
+		cfg.NVMLTimeoutDelay = metav1.Duration{Duration: DefaultNVMLTimeoutDelay}
+	}
+	if cfg.ControlPlaneCheckInterval.Duration == 0 {
+		cfg.ControlPlaneCheckInterval = metav1.Duration{Duration: DefaultControlPlaneCheckInterval}
+	}
+}
+
+// Validate returns an error if a probability is not in [0, 1] or a duration is negative.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	for b, p := range cfg.probabilities() {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s probability must be in [0, 1], got %v", b, p)
+		}
+	}
+	if cfg.NVMLTimeoutDelay.Duration < 0 {
+		return fmt.Errorf("nvml_timeout_delay must be non-negative, got %v", cfg.NVMLTimeoutDelay.Duration)
+	}
+	if cfg.ControlPlaneCheckInterval.Duration < 0 {
+		return fmt.Errorf("control_plane_check_interval must be non-negative, got %v", cfg.ControlPlaneCheckInterval.Duration)
+	}
+	return nil
+}
+
+func (cfg *Config) probabilities() map[Boundary]float64 {
+	return map[Boundary]float64{
+		BoundarySQLiteWrite:  cfg.SQLiteWriteError,
+		BoundaryNVML:         cfg.NVMLTimeout,
+		BoundaryControlPlane: cfg.ControlPlaneDisconnect,
+		BoundaryPlugin:       cfg.PluginTimeout,
+	}
+}
+
+var metricInjectedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "gpud",
+		Subsystem: "chaos",
+		Name:      "injected_failures_total",
+		Help:      "total number of the failures injected by the chaos mode per boundary",
+	},
+	[]string{"boundary"},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricInjectedTotal)
+}
+
+// Injector decides whether to inject the failures.
+// A nil injector never injects. Safe for concurrent use.
+type Injector struct {
+	cfg           Config
+	probabilities map[Boundary]float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New creates the chaos injector with the defaults set.
+func New(cfg Config) (*Injector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.SetDefaults()
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{
+		cfg:           cfg,
+		probabilities: cfg.probabilities(),
+		rnd:           rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Config returns the config with the defaults set.
+func (inj *Injector) Config() Config {
+	if inj == nil {
+		return Config{}
+	}
+	return inj.cfg
+}
+
+// Fail returns true if the failure should be injected into the boundary.
+func (inj *Injector) Fail(b Boundary) bool {
+	if inj == nil {
+		return false
+	}
+	p := inj.probabilities[b]
+	if p <= 0 {
+		return false
+	}
+
+	inj.mu.Lock()
+	fail := inj.rnd.Float64() < p
+	inj.mu.Unlock()
+
+	if fail {
+		metricInjectedTotal.WithLabelValues(string(b)).Inc()
+		log.Logger.Warnw("chaos: injecting failure", "boundary", b)
+	}
+	return fail
+}
+
+// FailFunc returns the function to decide the failures of the boundary,
+// or nil if the injector is nil or the boundary never fails.
+func (inj *Injector) FailFunc(b Boundary) func() bool {
+	if inj == nil || inj.probabilities[b] <= 0 {
+		return nil
+	}
+	return func() bool {
+		return inj.Fail(b)
+	}
+}
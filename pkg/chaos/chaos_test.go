@@ -0,0 +1,84 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	assert.NoError(t, nilCfg.Validate())
+
+	cfg := DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "negative sqlite write error", modify: func(c *Config) { c.SQLiteWriteError = -0.1 }},
+		{name: "nvml timeout above 1", modify: func(c *Config) { c.NVMLTimeout = 1.1 }},
+		{name: "negative nvml timeout delay", modify: func(c *Config) { c.NVMLTimeoutDelay = metav1.Duration{Duration: -time.Second} }},
+		{name: "negative control plane check interval", modify: func(c *Config) { c.ControlPlaneCheckInterval = metav1.Duration{Duration: -time.Second} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			assert.Error(t, cfg.Validate())
+
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewSetsDefaults(t *testing.T) {
+	inj, err := New(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNVMLTimeoutDelay, inj.Config().NVMLTimeoutDelay.Duration)
+	assert.Equal(t, DefaultControlPlaneCheckInterval, inj.Config().ControlPlaneCheckInterval.Duration)
+}
+
+func TestNilInjector(t *testing.T) {
+	var inj *Injector
+	assert.False(t, inj.Fail(BoundaryNVML))
+	assert.Nil(t, inj.FailFunc(BoundaryNVML))
+	assert.Equal(t, Config{}, inj.Config())
+}
+
+func TestInjectorProbabilities(t *testing.T) {
+	inj, err := New(Config{Seed: 1, NVMLTimeout: 1, PluginTimeout: 0})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, inj.Fail(BoundaryNVML))
+		assert.False(t, inj.Fail(BoundaryPlugin))
+	}
+
+	assert.Nil(t, inj.FailFunc(BoundaryPlugin))
+	f := inj.FailFunc(BoundaryNVML)
+	require.NotNil(t, f)
+	assert.True(t, f())
+}
+
+func TestInjectorSeedReproducible(t *testing.T) {
+	run := func() []bool {
+		inj, err := New(Config{Seed: 42, SQLiteWriteError: 0.5})
+		require.NoError(t, err)
+		fails := make([]bool, 0, 100)
+		for i := 0; i < 100; i++ {
+			fails = append(fails, inj.Fail(BoundarySQLiteWrite))
+		}
+		return fails
+	}
+
+	first := run()
+	assert.Equal(t, first, run())
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
@@ -0,0 +1,177 @@
+// Package clockskew detects the wall clock jumps against the monotonic clock
+// (e.g., the NTP step corrections, the manual "date -s"), so that the samples and
+// the events timestamped before the wall clock catches up after a backward jump
+// are annotated, rather than stored as if ordered after the earlier ones.
+package clockskew
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultThreshold is the default minimum difference between the wall clock
+	// and the monotonic clock elapsed times to be considered as a clock jump.
+	DefaultThreshold = time.Second
+	// DefaultInterval is the default interval to compare the clocks.
+	DefaultInterval = 5 * time.Second
+)
+
+// Jump is a wall clock jump against the monotonic clock.
+type Jump struct {
+	// DetectedAt is the wall clock time the jump was detected, after the jump.
+	DetectedAt time.Time `json:"detected_at"`
+	// Offset is the wall clock jump, negative for the backward jump.
+	Offset time.Duration `json:"offset"`
+}
+
+// Detector compares the elapsed wall clock time against the monotonic clock,
+// and tracks the latest wall clock time observed before the last backward jump.
+// Until the wall clock passes that time again, the new timestamps overlap with
+// the already recorded ones, and reported as affected.
+type Detector struct {
+	threshold time.Duration
+
+	getWallFunc func() time.Time
+	getMonoFunc func() time.Duration
+
+	mu       sync.Mutex
+	lastWall time.Time
+	lastMono time.Duration
+	// the latest wall clock time ever observed
+	maxWall time.Time
+	// the latest wall clock time observed before the backward jump,
+	// zero if the wall clock already caught up
+	highWater time.Time
+	// the last backward jump, while the high water is set
+	backward Jump
+	lastJump *Jump
+}
+
+// New creates a new detector with the threshold,
+// or DefaultThreshold if the threshold is not positive.
+func New(threshold time.Duration) *Detector {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	start := time.Now()
+	return &Detector{
+		threshold: threshold,
+		getWallFunc: func() time.Time {
+			// strips the monotonic clock reading, to compare the wall clocks
+			return time.Now().UTC().Round(0)
+		},
+		getMonoFunc: func() time.Duration {
+			return time.Since(start)
+		},
+	}
+}
+
+// Observe compares the clocks since the last observation,
+// and returns the jump if detected, nil otherwise.
+func (d *Detector) Observe() *Jump {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.observeLocked()
+}
+
+func (d *Detector) observeLocked() *Jump {
+	wall, mono := d.getWallFunc(), d.getMonoFunc()
+	defer func() {
+		d.lastWall, d.lastMono = wall, mono
+		if wall.After(d.maxWall) {
+			d.maxWall = wall
+		}
+		if !d.highWater.IsZero() && wall.After(d.highWater) {
+			log.Logger.Infow("wall clock caught up after the backward jump", "highWater", d.highWater)
+			d.highWater = time.Time{}
+		}
+	}()
+
+	if d.lastWall.IsZero() {
+		return nil
+	}
+
+	offset := wall.Sub(d.lastWall) - (mono - d.lastMono)
+	if offset > -d.threshold && offset < d.threshold {
+		return nil
+	}
+
+	jump := &Jump{DetectedAt: wall, Offset: offset}
+	d.lastJump = jump
+
+	direction := directionForward
+	if offset < 0 {
+		direction = directionBackward
+		if d.maxWall.After(d.highWater) {
+			d.highWater = d.maxWall
+		}
+		d.backward = *jump
+	}
+	metricClockJumps.WithLabelValues(direction).Inc()
+	metricClockJumpOffset.Set(offset.Seconds())
+
+	log.Logger.Warnw("wall clock jump detected", "direction", direction, "offset", offset, "highWater", d.highWater)
+	return jump
+}
+
+// Affected returns the last backward jump and true if the time is not after
+// the latest wall clock time observed before the jump, thus overlapping with
+// the already recorded timestamps. Returns false if no backward jump is pending.
+func (d *Detector) Affected(t time.Time) (Jump, bool) {
+	if d == nil {
+		return Jump{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// observe first, to not miss the jump between the ticks
+	d.observeLocked()
+
+	if d.highWater.IsZero() || t.After(d.highWater) {
+		return Jump{}, false
+	}
+	return d.backward, true
+}
+
+// LastJump returns the last detected jump, nil if none.
+func (d *Detector) LastJump() *Jump {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.lastJump == nil {
+		return nil
+	}
+	jump := *d.lastJump
+	return &jump
+}
+
+// Start compares the clocks every interval until the context is canceled.
+func (d *Detector) Start(ctx context.Context, interval time.Duration) {
+	d.Observe()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Observe()
+		}
+	}
+}
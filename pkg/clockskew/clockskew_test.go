@@ -0,0 +1,99 @@
+package clockskew
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances the wall and the monotonic clocks together,
+// and steps the wall clock alone.
+type fakeClock struct {
+	wall time.Time
+	mono time.Duration
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.wall = c.wall.Add(d)
+	c.mono += d
+}
+
+func newTestDetector(c *fakeClock) *Detector {
+	d := New(0)
+	d.getWallFunc = func() time.Time { return c.wall }
+	d.getMonoFunc = func() time.Duration { return c.mono }
+	return d
+}
+
+func TestDetectorBackwardJump(t *testing.T) {
+	c := &fakeClock{wall: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	d := newTestDetector(c)
+	assert.Equal(t, DefaultThreshold, d.threshold)
+
+	assert.Nil(t, d.Observe())
+	c.advance(5 * time.Second)
+	// within the threshold
+	c.wall = c.wall.Add(-500 * time.Millisecond)
+	assert.Nil(t, d.Observe())
+
+	beforeJump := c.wall
+	_, affected := d.Affected(beforeJump)
+	assert.False(t, affected)
+
+	// NTP step 3 minutes back
+	c.advance(5 * time.Second)
+	c.wall = c.wall.Add(-3 * time.Minute)
+	jump := d.Observe()
+	require.NotNil(t, jump)
+	assert.Equal(t, -3*time.Minute, jump.Offset)
+	assert.Equal(t, c.wall, jump.DetectedAt)
+
+	got, affected := d.Affected(c.wall)
+	assert.True(t, affected)
+	assert.Equal(t, *jump, got)
+	_, affected = d.Affected(beforeJump.Add(time.Second))
+	assert.False(t, affected, "after the latest wall clock observed before the jump")
+
+	// a forward jump while catching up does not replace the backward jump
+	c.advance(time.Second)
+	c.wall = c.wall.Add(10 * time.Second)
+	forward := d.Observe()
+	require.NotNil(t, forward)
+	assert.Equal(t, 10*time.Second, forward.Offset)
+	got, affected = d.Affected(c.wall)
+	assert.True(t, affected)
+	assert.Equal(t, -3*time.Minute, got.Offset)
+	assert.Equal(t, forward, d.LastJump())
+
+	// caught up
+	c.advance(3 * time.Minute)
+	_, affected = d.Affected(c.wall)
+	assert.False(t, affected)
+	_, affected = d.Affected(beforeJump)
+	assert.False(t, affected)
+}
+
+func TestDetectorNil(t *testing.T) {
+	var d *Detector
+	assert.Nil(t, d.Observe())
+	assert.Nil(t, d.LastJump())
+	_, affected := d.Affected(time.Now())
+	assert.False(t, affected)
+}
+
+func TestDetectorStart(t *testing.T) {
+	d := New(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx, time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+	assert.Nil(t, d.LastJump())
+}
@@ -0,0 +1,49 @@
+package clockskew
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	directionForward  = "forward"
+	directionBackward = "backward"
+)
+
+var (
+	metricClockJumps = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "clock",
+			Name:      "jumps_total",
+			Help:      "total number of the wall clock jumps against the monotonic clock per direction",
+		},
+		[]string{"direction"},
+	)
+	metricClockJumpOffset = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "gpud",
+			Subsystem: "clock",
+			Name:      "last_jump_offset_seconds",
+			Help:      "offset of the last wall clock jump in seconds, negative for the backward jump",
+		},
+	)
+	metricAnnotated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "clock",
+			Name:      "skew_annotated_total",
+			Help:      "total number of the events and the metric samples annotated after the backward wall clock jump",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricClockJumps,
+		metricClockJumpOffset,
+		metricAnnotated,
+	)
+}
@@ -0,0 +1,107 @@
+package clockskew
+
+import (
+	"context"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// EventKeyClockSkew is the extra info key of the events timestamped
+	// after the backward wall clock jump, before the wall clock catches up,
+	// with the jump offset (e.g., "-3m0s").
+	EventKeyClockSkew = "clock_skew"
+	// MetricLabelClockSkew is the label key of the metric samples timestamped
+	// after the backward wall clock jump, before the wall clock catches up.
+	MetricLabelClockSkew = "clock_skew"
+	// MetricLabelValueBackward is the label value of the metric samples
+	// timestamped after the backward wall clock jump.
+	MetricLabelValueBackward = directionBackward
+)
+
+// WrapEventStore returns the event store whose buckets annotate the events
+// affected by the backward wall clock jump.
+// Returns the store as is if the detector is nil.
+func WrapEventStore(store eventstore.Store, d *Detector) eventstore.Store {
+	if d == nil || store == nil {
+		return store
+	}
+	return &eventStore{Store: store, detector: d}
+}
+
+var _ eventstore.Store = &eventStore{}
+
+type eventStore struct {
+	eventstore.Store
+	detector *Detector
+}
+
+func (s *eventStore) Bucket(name string, opts ...eventstore.OpOption) (eventstore.Bucket, error) {
+	bucket, err := s.Store.Bucket(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &eventBucket{Bucket: bucket, detector: s.detector}, nil
+}
+
+var _ eventstore.Bucket = &eventBucket{}
+
+type eventBucket struct {
+	eventstore.Bucket
+	detector *Detector
+}
+
+func (b *eventBucket) Insert(ctx context.Context, ev eventstore.Event) error {
+	if jump, ok := b.detector.Affected(ev.Time); ok {
+		// copy, as the components may reuse the extra info
+		extraInfo := make(map[string]string, len(ev.ExtraInfo)+1)
+		for k, v := range ev.ExtraInfo {
+			extraInfo[k] = v
+		}
+		extraInfo[EventKeyClockSkew] = jump.Offset.String()
+		ev.ExtraInfo = extraInfo
+		metricAnnotated.WithLabelValues("event").Inc()
+	}
+	return b.Bucket.Insert(ctx, ev)
+}
+
+// WrapMetricsStore returns the metrics store that labels the metric samples
+// affected by the backward wall clock jump.
+// Returns the store as is if the detector is nil.
+func WrapMetricsStore(store pkgmetrics.Store, d *Detector) pkgmetrics.Store {
+	if d == nil || store == nil {
+		return store
+	}
+	return &metricsStore{Store: store, detector: d}
+}
+
+var _ pkgmetrics.Store = &metricsStore{}
+
+type metricsStore struct {
+	pkgmetrics.Store
+	detector *Detector
+}
+
+func (s *metricsStore) Record(ctx context.Context, ms ...pkgmetrics.Metric) error {
+	copied := false
+	for i := range ms {
+		if _, ok := s.detector.Affected(time.UnixMilli(ms[i].UnixMilliseconds)); !ok {
+			continue
+		}
+		// copy, as the samples and their labels may be shared with the caller
+		if !copied {
+			ms = append([]pkgmetrics.Metric(nil), ms...)
+			copied = true
+		}
+		labels := make(map[string]string, len(ms[i].Labels)+1)
+		for k, v := range ms[i].Labels {
+			labels[k] = v
+		}
+		labels[MetricLabelClockSkew] = MetricLabelValueBackward
+		ms[i].Labels = labels
+		metricAnnotated.WithLabelValues("metric").Inc()
+	}
+	return s.Store.Record(ctx, ms...)
+}
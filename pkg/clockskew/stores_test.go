@@ -0,0 +1,90 @@
+package clockskew
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// newJumpedDetector returns the detector after the wall clock stepped back a minute.
+func newJumpedDetector(t *testing.T) (*Detector, *fakeClock) {
+	c := &fakeClock{wall: time.Now().UTC().Round(0)}
+	d := newTestDetector(c)
+	d.Observe()
+	c.advance(time.Second)
+	c.wall = c.wall.Add(-time.Minute)
+	require.NotNil(t, d.Observe())
+	return d, c
+}
+
+func TestWrapEventStore(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	assert.Equal(t, store, WrapEventStore(store, nil))
+
+	d, c := newJumpedDetector(t)
+	bucket, err := WrapEventStore(store, d).Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	ctx := context.Background()
+	extraInfo := map[string]string{"gpu_uuid": "gpu-0"}
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: c.wall, Name: "affected", Type: "Warning", ExtraInfo: extraInfo}))
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: c.wall.Add(time.Hour), Name: "not-affected", Type: "Warning"}))
+	assert.Len(t, extraInfo, 1, "extra info of the caller not modified")
+
+	evs, err := bucket.Get(ctx, c.wall.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	for _, ev := range evs {
+		assert.Equal(t, time.UTC, ev.Time.Location())
+		switch ev.Name {
+		case "affected":
+			assert.Equal(t, "-1m0s", ev.ExtraInfo[EventKeyClockSkew])
+			assert.Equal(t, "gpu-0", ev.ExtraInfo["gpu_uuid"])
+		case "not-affected":
+			assert.NotContains(t, ev.ExtraInfo, EventKeyClockSkew)
+		}
+	}
+}
+
+type fakeMetricsStore struct {
+	pkgmetrics.Store
+	recorded pkgmetrics.Metrics
+}
+
+func (f *fakeMetricsStore) Record(_ context.Context, ms ...pkgmetrics.Metric) error {
+	f.recorded = append(f.recorded, ms...)
+	return nil
+}
+
+func TestWrapMetricsStore(t *testing.T) {
+	store := &fakeMetricsStore{}
+	assert.Equal(t, pkgmetrics.Store(store), WrapMetricsStore(store, nil))
+
+	d, c := newJumpedDetector(t)
+	wrapped := WrapMetricsStore(store, d)
+
+	labels := map[string]string{"gpu_uuid": "gpu-0"}
+	ms := []pkgmetrics.Metric{
+		{UnixMilliseconds: c.wall.UnixMilli(), Name: "affected", Labels: labels},
+		{UnixMilliseconds: c.wall.Add(time.Hour).UnixMilli(), Name: "not-affected", Labels: labels},
+	}
+	require.NoError(t, wrapped.Record(context.Background(), ms...))
+	require.Len(t, store.recorded, 2)
+	assert.Equal(t, map[string]string{"gpu_uuid": "gpu-0", MetricLabelClockSkew: MetricLabelValueBackward}, store.recorded[0].Labels)
+	assert.Equal(t, labels, store.recorded[1].Labels)
+
+	assert.Len(t, labels, 1, "labels of the caller not modified")
+	assert.Equal(t, labels, ms[0].Labels, "samples of the caller not modified")
+}
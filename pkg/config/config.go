@@ -10,7 +10,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/budget"
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
 	pkgconfigcommon "github.com/leptonai/gpud/pkg/config/common"
+	"github.com/leptonai/gpud/pkg/drain"
+	"github.com/leptonai/gpud/pkg/driverupgrade"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gossip"
+	"github.com/leptonai/gpud/pkg/gpuscore"
+	"github.com/leptonai/gpud/pkg/loadshed"
+	"github.com/leptonai/gpud/pkg/maintenance"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
+	"github.com/leptonai/gpud/pkg/session/upload"
+	"github.com/leptonai/gpud/pkg/slo"
+	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
 // Config provides gpud configuration data for the server
@@ -35,12 +50,48 @@ type Config struct {
 	// Once elapsed, old events are purged from the event store.
 	EventsRetentionPeriod metav1.Duration `json:"events_retention_period"`
 
+	// EventsRetentionPolicy overrides the events retention period
+	// per event type and per component bucket (e.g., keep the Xid events longer).
+	EventsRetentionPolicy *eventstore.RetentionPolicy `json:"events_retention_policy,omitempty"`
+
+	// EventDispositionThreshold is the number of the benign or false-positive
+	// operator dispositions (since the last true positive) to downgrade the events
+	// of a kind on this GPU product and driver version.
+	// Zero uses the default, and a negative value disables the downgrades.
+	EventDispositionThreshold int `json:"event_disposition_threshold,omitempty"`
+
 	// Interval at which to compact the state database.
 	CompactPeriod metav1.Duration `json:"compact_period"`
 
 	// Set true to enable profiler.
 	Pprof bool `json:"pprof"`
 
+	// Set true to serve the deterministic snapshot of the internal state
+	// at "/v1/debug/snapshot" (e.g., for the integration tests and the bug reports).
+	DebugSnapshot bool `json:"debug_snapshot,omitempty"`
+
+	// Set true to validate the component health states and events against
+	// the data contracts (see "pkg/contracts"), replacing the malformed health
+	// state extra info with the violation and dropping the malformed events.
+	ValidateContracts bool `json:"validate_contracts,omitempty"`
+
+	// Set true to run the startup self-test (e.g., the state database writable,
+	// NVML loadable) before declaring the readiness, reporting the failures
+	// through "/readyz" and the startup event.
+	StartupSelfTest bool `json:"startup_self_test,omitempty"`
+
+	// CrashReportDir is the directory of the crash reports of the panics
+	// recovered in the component checks and the HTTP handlers (see "pkg/crashreport").
+	// If empty, defaults to "crash-reports" under the data directory.
+	CrashReportDir string `json:"crash_report_dir,omitempty"`
+	// MaxCrashReports is the number of the newest crash reports to keep,
+	// the older reports are removed. Zero uses the default.
+	MaxCrashReports int `json:"max_crash_reports,omitempty"`
+
+	// MaxRequestBodyBytes is the maximum size of the API request bodies,
+	// rejected with 413 if larger. Defaults to DefaultMaxRequestBodyBytes if zero.
+	MaxRequestBodyBytes int64 `json:"max_request_body_bytes,omitempty"`
+
 	// Set false to disable auto update
 	EnableAutoUpdate bool `json:"enable_auto_update"`
 
@@ -58,6 +109,15 @@ type Config struct {
 
 	// PluginSpecsFile is the file that contains the plugin specs.
 	PluginSpecsFile string `json:"plugin_specs_file"`
+	// PluginSecretsFile is the root-only file that contains the secrets
+	// injected into the plugin steps by reference.
+	// If empty or the file does not exist, no secret is injected.
+	PluginSecretsFile string `json:"plugin_secrets_file"`
+
+	// ExternalComponentsSocket is the unix socket for the external components
+	// (out-of-process plugins) to register with GPUd.
+	// If empty, the external components are disabled.
+	ExternalComponentsSocket string `json:"external_components_socket,omitempty"`
 
 	// Components specifies the components to enable.
 	// Leave empty, "*", or "all" to enable all components.
@@ -66,9 +126,93 @@ type Config struct {
 	selectedComponents map[string]any `json:"-"`
 	disabledComponents map[string]any `json:"-"`
 
+	// ComponentHealthHysteresis configures the health state debouncing per component,
+	// keyed by the component name. Use the key "*" to apply to all components
+	// that are not explicitly listed.
+	// e.g., {"accelerator-nvidia-temperature": {"failure_threshold": 3, "recovery_threshold": 2}}
+	ComponentHealthHysteresis map[string]components.HysteresisConfig `json:"component_health_hysteresis,omitempty"`
+
+	// ComponentCheckWatchdog configures the check timeouts per component,
+	// keyed by the component name. Use the key "*" to apply to all components
+	// that are not explicitly listed. The components not configured use the defaults.
+	// e.g., {"accelerator-nvidia-infiniband": {"timeout": "1m", "hang_threshold": 2}}
+	ComponentCheckWatchdog map[string]components.WatchdogConfig `json:"component_check_watchdog,omitempty"`
+
+	// ComponentAdaptiveCheckInterval configures the check intervals adapted to the health per component,
+	// keyed by the component name. Use the key "*" to apply to all components
+	// that are not explicitly listed. The components not configured check at the fixed intervals.
+	// e.g., {"*": {"max_interval": "10m", "failing_interval": "15s"}}
+	ComponentAdaptiveCheckInterval map[string]components.AdaptiveIntervalConfig `json:"component_adaptive_check_interval,omitempty"`
+
+	// RBAC configures the role-based access control for the API endpoints.
+	// If nil, every client with the network access has the full access.
+	RBAC *rbac.Config `json:"rbac,omitempty"`
+
+	// RateLimit configures the per-client rate limiting and the concurrent
+	// request cap for the API endpoints. If nil, the requests are not limited.
+	RateLimit *ratelimit.Config `json:"rate_limit,omitempty"`
+
+	// SessionUpload configures the periodic upload of the metrics and events
+	// to the control plane in compressed batches.
+	// If nil, the metrics and events are only sent on the control plane requests.
+	SessionUpload *upload.Config `json:"session_upload,omitempty"`
+
+	// Gossip enables the peer-to-peer exchange of the health summaries
+	// between the GPUd instances in a cluster, without the control plane.
+	// If nil, the gossip is disabled.
+	Gossip *gossip.Config `json:"gossip,omitempty"`
+
+	// SLO declares the latency objectives of the component checks and the API,
+	// with the attainment served from "/v1/slo" and the violations recorded as the events.
+	// If nil, the latency objectives are not tracked.
+	SLO *slo.Config `json:"slo,omitempty"`
+
+	// MetricsExport limits the metric families exported on "/metrics"
+	// for the external Prometheus servers.
+	// If nil, all the metric families are exported.
+	MetricsExport *pkgmetrics.ExportConfig `json:"metrics_export,omitempty"`
+
+	// DataBudget caps the events recorded, the metric samples stored,
+	// and the bytes uploaded to the control plane, with the lower-severity
+	// data sampled away when over budget. If nil, the data is not capped.
+	DataBudget *budget.Config `json:"data_budget,omitempty"`
+
+	// LoadShedding defers the expensive component checks (e.g., "nvidia-smi", "ibstat",
+	// custom plugins) while the host CPU or IO pressure is over the thresholds,
+	// with the health states tagged as deferred. If nil, the checks are never deferred.
+	LoadShedding *loadshed.Config `json:"load_shedding,omitempty"`
+
+	// DrainReadiness configures the extra checks of the plugins
+	// for the node to be reported ready for the maintenance.
+	// If nil, only the built-in checks are run.
+	DrainReadiness *drain.Config `json:"drain_readiness,omitempty"`
+
+	// DriverUpgrade configures the plugins revalidated along with the NVIDIA
+	// components once a driver install, upgrade, or reload is detected.
+	// If nil, only the NVIDIA components are revalidated.
+	DriverUpgrade *driverupgrade.Config `json:"driver_upgrade,omitempty"`
+
+	// GPUPerformanceScore configures the window and the weights of the rolling
+	// per-GPU performance score. If nil, the default window and weights are used.
+	GPUPerformanceScore *gpuscore.Config `json:"gpu_performance_score,omitempty"`
+
+	// SQLite configures the busy timeout, the synchronous mode, the WAL autocheckpoint,
+	// and the busy retries of the state database. If nil, the defaults are used.
+	SQLite *sqlite.Config `json:"sqlite,omitempty"`
+
+	// MaintenanceWindows declares the scheduled maintenance windows, during which
+	// the health states and events of the covered components are tagged as maintenance.
+	// The windows are persisted in the state database along with the windows
+	// added via the API, and expire after the end time.
+	MaintenanceWindows []maintenance.Window `json:"maintenance_windows,omitempty"`
+
 	// FailureInjector is the failure injector.
 	FailureInjector *components.FailureInjector `json:"failure_injector,omitempty"`
 
+	// Chaos enables the chaos mode that randomly injects failures
+	// into the internal boundaries (only for the resilience testing).
+	Chaos *pkgchaos.Config `json:"chaos,omitempty"`
+
 	// SkipSessionUpdateConfig skips processing of updateConfig session commands. Intended for testing.
 	SkipSessionUpdateConfig bool `json:"skip_session_update_config"`
 
@@ -88,6 +232,12 @@ type Config struct {
 	// This allows gpud up to pass the assigned machine ID from login to gpud run.
 	SessionMachineID string `json:"-"`
 
+	// PostgresDSN is the data source name of the shared PostgreSQL database
+	// to store the metrics and events (e.g., "postgres://gpud@db.internal:5432/gpud?sslmode=verify-full").
+	// If empty, the metrics and events are stored in the local SQLite state file.
+	// The machine identity and the session credentials always stay in the local state file.
+	PostgresDSN string `json:"-"`
+
 	// SessionEndpoint is the control plane endpoint.
 	// Used when DBInMemory is true and session credentials are passed via CLI flags.
 	// This allows gpud up to pass the endpoint from login to gpud run.
@@ -105,10 +255,105 @@ func (config *Config) Validate() error {
 	if config.EventsRetentionPeriod.Duration > 0 && config.EventsRetentionPeriod.Duration < time.Minute {
 		return fmt.Errorf("events_retention_period must be at least 1 minute, got %d", config.EventsRetentionPeriod.Duration)
 	}
+	for name, h := range config.ComponentHealthHysteresis {
+		if err := h.Validate(); err != nil {
+			return fmt.Errorf("invalid component_health_hysteresis for %q: %w", name, err)
+		}
+	}
+	for name, w := range config.ComponentCheckWatchdog {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid component_check_watchdog for %q: %w", name, err)
+		}
+	}
+	for name, i := range config.ComponentAdaptiveCheckInterval {
+		if err := i.Validate(); err != nil {
+			return fmt.Errorf("invalid component_adaptive_check_interval for %q: %w", name, err)
+		}
+	}
+	if err := config.RBAC.Validate(); err != nil {
+		return fmt.Errorf("invalid rbac: %w", err)
+	}
+	if err := config.EventsRetentionPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid events_retention_policy: %w", err)
+	}
+	if err := config.Chaos.Validate(); err != nil {
+		return fmt.Errorf("invalid chaos: %w", err)
+	}
+	if config.MaxCrashReports < 0 {
+		return fmt.Errorf("max_crash_reports must be non-negative, got %d", config.MaxCrashReports)
+	}
+	if config.MaxRequestBodyBytes < 0 {
+		return fmt.Errorf("max_request_body_bytes must be non-negative, got %d", config.MaxRequestBodyBytes)
+	}
+	if err := config.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate_limit: %w", err)
+	}
+	if err := config.SessionUpload.Validate(); err != nil {
+		return fmt.Errorf("invalid session_upload: %w", err)
+	}
+	if err := config.Gossip.Validate(); err != nil {
+		return fmt.Errorf("invalid gossip: %w", err)
+	}
+	if err := config.SLO.Validate(); err != nil {
+		return fmt.Errorf("invalid slo: %w", err)
+	}
+	if err := config.MetricsExport.Validate(); err != nil {
+		return fmt.Errorf("invalid metrics_export: %w", err)
+	}
+	if err := config.DataBudget.Validate(); err != nil {
+		return fmt.Errorf("invalid data_budget: %w", err)
+	}
+	if err := config.LoadShedding.Validate(); err != nil {
+		return fmt.Errorf("invalid load_shedding: %w", err)
+	}
+	if err := config.DrainReadiness.Validate(); err != nil {
+		return fmt.Errorf("invalid drain_readiness: %w", err)
+	}
+	if err := config.DriverUpgrade.Validate(); err != nil {
+		return fmt.Errorf("invalid driver_upgrade: %w", err)
+	}
+	if err := config.GPUPerformanceScore.Validate(); err != nil {
+		return fmt.Errorf("invalid gpu_performance_score: %w", err)
+	}
+	if err := config.SQLite.Validate(); err != nil {
+		return fmt.Errorf("invalid sqlite: %w", err)
+	}
+	for _, w := range config.MaintenanceWindows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid maintenance_windows %q: %w", w.ID, err)
+		}
+	}
 
 	return nil
 }
 
+// HealthHysteresis returns the health state hysteresis config of the component.
+// It falls back to the "*" entry if the component is not explicitly configured.
+func (config *Config) HealthHysteresis(componentName string) components.HysteresisConfig {
+	if h, ok := config.ComponentHealthHysteresis[componentName]; ok {
+		return h
+	}
+	return config.ComponentHealthHysteresis["*"]
+}
+
+// CheckWatchdog returns the check watchdog config of the component.
+// It falls back to the "*" entry if the component is not explicitly configured.
+func (config *Config) CheckWatchdog(componentName string) components.WatchdogConfig {
+	if w, ok := config.ComponentCheckWatchdog[componentName]; ok {
+		return w
+	}
+	return config.ComponentCheckWatchdog["*"]
+}
+
+// AdaptiveCheckInterval returns the adaptive check interval config of the component.
+// It falls back to the "*" entry if the component is not explicitly configured.
+func (config *Config) AdaptiveCheckInterval(componentName string) components.AdaptiveIntervalConfig {
+	if i, ok := config.ComponentAdaptiveCheckInterval[componentName]; ok {
+		return i
+	}
+	return config.ComponentAdaptiveCheckInterval["*"]
+}
+
 // ShouldEnable returns true if the component should be enabled.
 // If the enable component sets are not specified, it will return true,
 // meaning it should be enabled by default.
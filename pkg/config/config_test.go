@@ -1,10 +1,26 @@
 package config
 
 import (
+	"encoding/base64"
 	"testing"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/budget"
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
+	"github.com/leptonai/gpud/pkg/drain"
+	"github.com/leptonai/gpud/pkg/driverupgrade"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gossip"
+	"github.com/leptonai/gpud/pkg/maintenance"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
+	"github.com/leptonai/gpud/pkg/session/upload"
+	"github.com/leptonai/gpud/pkg/slo"
 )
 
 func TestConfigValidate_AutoUpdateExitCode(t *testing.T) {
@@ -86,6 +102,278 @@ func TestConfigValidate_EventsRetentionPeriod(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_ComponentHealthHysteresis(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		ComponentHealthHysteresis: map[string]components.HysteresisConfig{
+			"cpu": {FailureThreshold: 3, RecoveryThreshold: 2},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.ComponentHealthHysteresis["disk"] = components.HysteresisConfig{FailureThreshold: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for negative failure threshold")
+	}
+}
+
+func TestConfig_AdaptiveCheckInterval(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+	}
+	if i := cfg.AdaptiveCheckInterval("cpu"); i.MaxInterval.Duration != 0 || i.FailingInterval.Duration != 0 {
+		t.Fatalf("expected fixed interval, got %+v", i)
+	}
+
+	cfg.ComponentAdaptiveCheckInterval = map[string]components.AdaptiveIntervalConfig{
+		"*":   {MaxInterval: metav1.Duration{Duration: 10 * time.Minute}},
+		"cpu": {FailingInterval: metav1.Duration{Duration: 15 * time.Second}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+	if i := cfg.AdaptiveCheckInterval("cpu"); i.FailingInterval.Duration != 15*time.Second || i.MaxInterval.Duration != 0 {
+		t.Fatalf("unexpected cpu interval %+v", i)
+	}
+	if i := cfg.AdaptiveCheckInterval("disk"); i.MaxInterval.Duration != 10*time.Minute {
+		t.Fatalf("unexpected default interval %+v", i)
+	}
+
+	cfg.ComponentAdaptiveCheckInterval["disk"] = components.AdaptiveIntervalConfig{FailingInterval: metav1.Duration{Duration: time.Second}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for too short failing interval")
+	}
+}
+
+func TestConfigValidate_RBAC(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		RBAC:                   &rbac.Config{AnonymousRole: rbac.RoleViewer},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.RBAC.AnonymousRole = "guest"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for invalid anonymous role")
+	}
+}
+
+func TestConfigValidate_EventsRetentionPolicy(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		EventsRetentionPolicy: &eventstore.RetentionPolicy{
+			ByType: map[string]metav1.Duration{"Info": {Duration: 7 * 24 * time.Hour}},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.EventsRetentionPolicy.ByType["Debug"] = metav1.Duration{Duration: time.Hour}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for unknown event type")
+	}
+}
+
+func TestConfigValidate_RateLimit(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		RateLimit:              &ratelimit.Config{RequestsPerSecond: 5, MaxConcurrentRequests: 16},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.RateLimit.RequestsPerSecond = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for negative requests per second")
+	}
+}
+
+func TestConfigValidate_Chaos(t *testing.T) {
+	chaosCfg := pkgchaos.DefaultConfig()
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		Chaos:                  &chaosCfg,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.Chaos.NVMLTimeout = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for chaos probability > 1")
+	}
+}
+
+func TestConfigValidate_SessionUpload(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		SessionUpload:          &upload.Config{Compression: upload.CompressionZstd},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.SessionUpload.Compression = "brotli"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for invalid compression")
+	}
+}
+
+func TestConfigValidate_Gossip(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		Gossip:                 &gossip.Config{Join: []string{"10.0.0.2"}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for missing secret key")
+	}
+
+	cfg.Gossip.SecretKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.Gossip.SecretKey = "invalid"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for invalid secret key")
+	}
+}
+
+func TestConfigValidate_SLO(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		SLO: &slo.Config{Objectives: []slo.Objective{{
+			Name:      "nvidia-checks",
+			Kind:      apiv1.SLOKindComponentCheck,
+			Match:     "accelerator-nvidia-*",
+			Threshold: metav1.Duration{Duration: 2 * time.Second},
+			Target:    0.99,
+		}}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.SLO.Objectives[0].Target = 1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for invalid target")
+	}
+}
+
+func TestConfigValidate_MetricsExport(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		MetricsExport:          &pkgmetrics.ExportConfig{Allow: []string{"gpud_.*"}, Deny: []string{".*_bucket"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.MetricsExport.Deny = []string{"("}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for invalid deny pattern")
+	}
+}
+
+func TestConfigValidate_DataBudget(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		DataBudget:             &budget.Config{EventsPerHour: 1000, MetricSamplesPerMinute: 5000, UploadBytesPerHour: 10 << 20},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.DataBudget.EventsPerHour = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for negative events per hour")
+	}
+}
+
+func TestConfigValidate_DrainReadiness(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		DrainReadiness:         &drain.Config{Plugins: []string{"no-running-jobs"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.DrainReadiness.Plugins = append(cfg.DrainReadiness.Plugins, "no-running-jobs")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for duplicate plugin")
+	}
+}
+
+func TestConfigValidate_DriverUpgrade(t *testing.T) {
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		DriverUpgrade:          &driverupgrade.Config{Plugins: []string{"cuda-sample"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.DriverUpgrade.SettlePeriod = metav1.Duration{Duration: -time.Minute}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for negative settle period")
+	}
+}
+
+func TestConfigValidate_MaintenanceWindows(t *testing.T) {
+	now := time.Now()
+	cfg := &Config{
+		Address:                "localhost:8080",
+		MetricsRetentionPeriod: metav1.Duration{Duration: time.Hour},
+		MaintenanceWindows:     []maintenance.Window{{ID: "reboot", Start: now, End: now.Add(time.Hour)}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Config.Validate() unexpected error = %v", err)
+	}
+
+	cfg.MaintenanceWindows[0].End = now.Add(-time.Hour)
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for end before start")
+	}
+}
+
+func TestConfig_HealthHysteresis(t *testing.T) {
+	cfg := &Config{}
+	if h := cfg.HealthHysteresis("cpu"); h.Enabled() {
+		t.Fatalf("expected no hysteresis, got %+v", h)
+	}
+
+	cfg.ComponentHealthHysteresis = map[string]components.HysteresisConfig{
+		"*":   {FailureThreshold: 2},
+		"cpu": {FailureThreshold: 5, RecoveryThreshold: 3},
+	}
+	if h := cfg.HealthHysteresis("cpu"); h.FailureThreshold != 5 || h.RecoveryThreshold != 3 {
+		t.Fatalf("unexpected cpu hysteresis %+v", h)
+	}
+	if h := cfg.HealthHysteresis("disk"); h.FailureThreshold != 2 || h.RecoveryThreshold != 0 {
+		t.Fatalf("unexpected default hysteresis %+v", h)
+	}
+}
+
 func TestConfig_ShouldEnable(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -813,3 +1101,28 @@ func TestConfig_WildcardAndAll_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestConfig_CheckWatchdog(t *testing.T) {
+	cfg := &Config{}
+	if w := cfg.CheckWatchdog("cpu"); w.Disabled || w.Timeout.Duration != 0 {
+		t.Fatalf("expected default watchdog, got %+v", w)
+	}
+
+	cfg.ComponentCheckWatchdog = map[string]components.WatchdogConfig{
+		"*":   {HangThreshold: 5},
+		"cpu": {Disabled: true},
+	}
+	if w := cfg.CheckWatchdog("cpu"); !w.Disabled {
+		t.Fatalf("unexpected cpu watchdog %+v", w)
+	}
+	if w := cfg.CheckWatchdog("disk"); w.HangThreshold != 5 {
+		t.Fatalf("unexpected default watchdog %+v", w)
+	}
+
+	cfg.Address = "localhost:8080"
+	cfg.MetricsRetentionPeriod = metav1.Duration{Duration: time.Hour}
+	cfg.ComponentCheckWatchdog["disk"] = components.WatchdogConfig{HangThreshold: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Config.Validate() expected error for negative hang threshold")
+	}
+}
@@ -16,6 +16,10 @@ const (
 	DefaultAPIVersion = "v1"
 	DefaultGPUdPort   = 15132
 	DefaultDataDir    = "/var/lib/gpud"
+
+	// DefaultMaxRequestBodyBytes is the default maximum size of the API request bodies
+	// (e.g., the plugin specs, the set healthy requests).
+	DefaultMaxRequestBodyBytes = 8 * 1024 * 1024
 )
 
 var (
@@ -131,16 +135,41 @@ func StateFilePath(dataDir string) string {
 	return filepath.Join(dataDir, "gpud.state")
 }
 
+// CrashReportDirPath returns the crash report directory under the dataDir.
+func CrashReportDirPath(dataDir string) string {
+	return filepath.Join(dataDir, "crash-reports")
+}
+
 // FifoFilePath returns the FIFO pipe path under the dataDir.
 func FifoFilePath(dataDir string) string {
 	return filepath.Join(dataDir, "gpud.fifo")
 }
 
+// ExternalComponentsSocketPath returns the external components unix socket path under the dataDir.
+func ExternalComponentsSocketPath(dataDir string) string {
+	return filepath.Join(dataDir, "external-components.sock")
+}
+
 // PackagesDir returns the packages directory under the dataDir.
 func PackagesDir(dataDir string) string {
 	return filepath.Join(dataDir, "packages")
 }
 
+// SessionUploadQueueDir returns the directory of the session upload queue under the dataDir.
+func SessionUploadQueueDir(dataDir string) string {
+	return filepath.Join(dataDir, "session-upload-queue")
+}
+
+// CrashDumpsDir returns the directory of the NVIDIA bug reports collected on the driver crashes under the dataDir.
+func CrashDumpsDir(dataDir string) string {
+	return filepath.Join(dataDir, "crash-dumps")
+}
+
+// ScanResultsDir returns the directory of the "gpud scan" results under the dataDir.
+func ScanResultsDir(dataDir string) string {
+	return filepath.Join(dataDir, "scan-results")
+}
+
 // VersionFilePath returns the version file path under the dataDir.
 func VersionFilePath(dataDir string) string {
 	return filepath.Join(dataDir, "target_version")
@@ -73,6 +73,10 @@ func TestDefaultStateAndFifoFiles(t *testing.T) {
 	})
 }
 
+func TestSessionUploadQueueDir(t *testing.T) {
+	assert.Equal(t, "/var/lib/gpud/session-upload-queue", SessionUploadQueueDir("/var/lib/gpud"))
+}
+
 func TestPackagesDir(t *testing.T) {
 	tests := []struct {
 		name     string
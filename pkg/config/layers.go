@@ -0,0 +1,141 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultConfigFile is the default base config file of the "gpud run" flags.
+	DefaultConfigFile = "/etc/gpud/config.yaml"
+	// DefaultConfigDir is the default drop-in directory of the config files,
+	// merged on top of the base config file in the lexical order of the file names.
+	DefaultConfigDir = "/etc/gpud/conf.d"
+)
+
+// ConfigLayer is a config file, keyed by the "gpud run" flag names
+// (e.g., "listen-address", "components", "component-health-hysteresis").
+type ConfigLayer struct {
+	Path   string         `json:"path"`
+	Values map[string]any `json:"values"`
+}
+
+// LoadConfigLayers loads the base config file and the "*.yaml" and "*.yml" files
+// in the drop-in directory (in the lexical order), in the increasing precedence.
+// The files or the directory that do not exist are skipped.
+func LoadConfigLayers(baseFile string, dropInDir string) ([]ConfigLayer, error) {
+	var paths []string
+	if baseFile != "" {
+		paths = append(paths, baseFile)
+	}
+	if dropInDir != "" {
+		entries, err := os.ReadDir(dropInDir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		var names []string
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dropInDir, name))
+		}
+	}
+
+	layers := make([]ConfigLayer, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		values := make(map[string]any)
+		if err := yaml.Unmarshal(b, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+		}
+		layers = append(layers, ConfigLayer{Path: path, Values: values})
+	}
+	return layers, nil
+}
+
+// MergeConfigLayers merges the config layers in the order, where the later layer overrides the earlier.
+// The maps are merged recursively, the other values (including the lists) are replaced,
+// and a null value removes the key set by the earlier layers.
+func MergeConfigLayers(layers []ConfigLayer) map[string]any {
+	merged := make(map[string]any)
+	for _, layer := range layers {
+		mergeValues(merged, layer.Values)
+	}
+	return merged
+}
+
+func mergeValues(dst map[string]any, src map[string]any) {
+	for k, v := range src {
+		if v == nil {
+			delete(dst, k)
+			continue
+		}
+		srcMap, ok := v.(map[string]any)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]any)
+		if !ok {
+			dstMap = make(map[string]any, len(srcMap))
+			dst[k] = dstMap
+		}
+		mergeValues(dstMap, srcMap)
+	}
+}
+
+// ConfigFlagValue returns the flag value of the merged config value.
+// The lists of the scalars are joined with commas (e.g., "components"),
+// and the other lists and the maps are JSON-encoded (e.g., "bmc-config").
+func ConfigFlagValue(v any) (string, error) {
+	switch tv := v.(type) {
+	case string:
+		return tv, nil
+	case bool:
+		return strconv.FormatBool(tv), nil
+	case float64:
+		return strconv.FormatFloat(tv, 'f', -1, 64), nil
+	case []any:
+		ss := make([]string, 0, len(tv))
+		for _, elem := range tv {
+			switch elem.(type) {
+			case string, bool, float64:
+				s, _ := ConfigFlagValue(elem)
+				ss = append(ss, s)
+			default:
+				return encodeJSON(v)
+			}
+		}
+		return strings.Join(ss, ","), nil
+	default:
+		return encodeJSON(v)
+	}
+}
+
+func encodeJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAndMergeConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	dropIn := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(dropIn, 0o755))
+
+	require.NoError(t, os.WriteFile(base, []byte(`
+listen-address: 0.0.0.0:15132
+pprof: false
+components: [accelerator-nvidia-temperature, memory]
+component-health-hysteresis:
+  "*":
+    failure_threshold: 3
+    recovery_threshold: 2
+gpu-count: 8
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dropIn, "20-node.yaml"), []byte(`
+component-health-hysteresis:
+  "*":
+    failure_threshold: 5
+gpu-count: null
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dropIn, "10-site.yml"), []byte(`
+pprof: true
+gpu-count: 4
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dropIn, "README.md"), []byte("not a config"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dropIn, "sub.yaml"), 0o755))
+
+	layers, err := LoadConfigLayers(base, dropIn)
+	require.NoError(t, err)
+	require.Len(t, layers, 3)
+	assert.Equal(t, base, layers[0].Path)
+	assert.Equal(t, filepath.Join(dropIn, "10-site.yml"), layers[1].Path)
+	assert.Equal(t, filepath.Join(dropIn, "20-node.yaml"), layers[2].Path)
+
+	merged := MergeConfigLayers(layers)
+	assert.Equal(t, map[string]any{
+		"listen-address": "0.0.0.0:15132",
+		"pprof":          true,
+		"components":     []any{"accelerator-nvidia-temperature", "memory"},
+		"component-health-hysteresis": map[string]any{
+			"*": map[string]any{
+				"failure_threshold":  float64(5),
+				"recovery_threshold": float64(2),
+			},
+		},
+	}, merged)
+
+	// the layers are not modified by the merge
+	assert.Equal(t, float64(3), layers[0].Values["component-health-hysteresis"].(map[string]any)["*"].(map[string]any)["failure_threshold"])
+}
+
+func TestLoadConfigLayersNotExist(t *testing.T) {
+	dir := t.TempDir()
+	layers, err := LoadConfigLayers(filepath.Join(dir, "config.yaml"), filepath.Join(dir, "conf.d"))
+	require.NoError(t, err)
+	assert.Empty(t, layers)
+
+	layers, err = LoadConfigLayers("", "")
+	require.NoError(t, err)
+	assert.Empty(t, layers)
+	assert.Empty(t, MergeConfigLayers(layers))
+}
+
+func TestLoadConfigLayersInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- not\n- a map\n"), 0o644))
+	_, err := LoadConfigLayers(path, "")
+	assert.ErrorContains(t, err, "failed to parse config file")
+}
+
+func TestConfigFlagValue(t *testing.T) {
+	tests := []struct {
+		v        any
+		expected string
+	}{
+		{"0.0.0.0:15132", "0.0.0.0:15132"},
+		{true, "true"},
+		{float64(8), "8"},
+		{1.5, "1.5"},
+		{[]any{"memory", "-nfs"}, "memory,-nfs"},
+		{[]any{map[string]any{"dir": "/mnt/nfs"}}, `[{"dir":"/mnt/nfs"}]`},
+		{map[string]any{"enabled": true}, `{"enabled":true}`},
+	}
+	for _, tt := range tests {
+		s, err := ConfigFlagValue(tt.v)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, s)
+	}
+}
@@ -0,0 +1,192 @@
+// Package contracts defines the data contracts of the components: the JSON Schemas
+// of the health state extra info (e.g., the "data" of the check results) and of the
+// event extra info by the event name, so that the downstream parsers can rely on the
+// keys and the types, and the changes are made on purpose.
+//
+// The contracts are checked in under "schemas" (one file per component), validated
+// in the component tests (see the "contractstest" package), and optionally at runtime,
+// where the malformed output is rejected and counted by the
+// "gpud_contracts_violations_total" metric.
+package contracts
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+// ExtraInfoKeyData is the health state extra info key of the check result data,
+// encoded as the JSON document.
+const ExtraInfoKeyData = "data"
+
+// Contract is the data contract of a component.
+type Contract struct {
+	// Component is the name of the component.
+	Component string `json:"component"`
+	// HealthStateExtraInfo is the schema of the health state extra info object,
+	// nil to not validate the health states.
+	HealthStateExtraInfo *Schema `json:"health_state_extra_info,omitempty"`
+	// Events is the schemas of the event extra info objects by the event name.
+	// The events of the names not declared are not validated at runtime
+	// (e.g., the matched kernel messages), but fail the component tests.
+	Events map[string]*Schema `json:"events,omitempty"`
+}
+
+// Check returns an error if the contract is malformed.
+func (c Contract) Check() error {
+	if c.Component == "" {
+		return fmt.Errorf("contract missing the component name")
+	}
+	if err := c.HealthStateExtraInfo.Check(); err != nil {
+		return fmt.Errorf("contract of component %q has the malformed health state extra info schema: %w", c.Component, err)
+	}
+	for _, name := range sortedKeys(c.Events) {
+		if c.Events[name] == nil {
+			return fmt.Errorf("contract of component %q missing the schema of event %q", c.Component, name)
+		}
+		if err := c.Events[name].Check(); err != nil {
+			return fmt.Errorf("contract of component %q has the malformed schema of event %q: %w", c.Component, name, err)
+		}
+	}
+	return nil
+}
+
+// SchemasDir is the directory of the contracts, relative to this package.
+const SchemasDir = "schemas"
+
+//go:embed schemas/*.json
+var schemasFS embed.FS
+
+var contracts map[string]Contract
+
+func init() {
+	var err error
+	contracts, err = load(schemasFS, SchemasDir)
+	if err != nil {
+		panic(fmt.Errorf("failed to load component data contracts: %w", err))
+	}
+}
+
+// load reads the contracts of the components, one file per component,
+// named after the component (e.g., "accelerator-nvidia-xid.json").
+func load(fsys fs.FS, dir string) (map[string]Contract, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]Contract, len(entries))
+	for _, entry := range entries {
+		b, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var c Contract
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", entry.Name(), err)
+		}
+		if err := c.Check(); err != nil {
+			return nil, fmt.Errorf("%q: %w", entry.Name(), err)
+		}
+		if want := strings.TrimSuffix(entry.Name(), ".json"); c.Component != want {
+			return nil, fmt.Errorf("%q declares component %q, expected %q", entry.Name(), c.Component, want)
+		}
+		loaded[c.Component] = c
+	}
+	return loaded, nil
+}
+
+// Get returns the contract of the component, and false if not declared.
+func Get(component string) (Contract, bool) {
+	c, ok := contracts[component]
+	return c, ok
+}
+
+// All returns the contracts of all the components, sorted by the component name.
+func All() []Contract {
+	all := make([]Contract, 0, len(contracts))
+	for _, name := range sortedKeys(contracts) {
+		all = append(all, contracts[name])
+	}
+	return all
+}
+
+// ValidateHealthState returns an error if the health state extra info
+// does not match the contract of the component.
+// Returns nil if the component declares no health state contract.
+func ValidateHealthState(component string, st apiv1.HealthState) error {
+	c, ok := contracts[component]
+	if !ok || c.HealthStateExtraInfo == nil {
+		return nil
+	}
+	if err := c.HealthStateExtraInfo.Validate(extraInfoValue(st.ExtraInfo)); err != nil {
+		return fmt.Errorf("health state %q of component %q violates the contract: %w", st.Name, component, err)
+	}
+	return nil
+}
+
+// ValidateEvent returns an error if the event extra info
+// does not match the contract of the component for the event name.
+// Returns nil if the component declares no contract for the event name.
+func ValidateEvent(component string, ev eventstore.Event) error {
+	c, ok := contracts[component]
+	if !ok {
+		return nil
+	}
+	s, ok := c.Events[ev.Name]
+	if !ok {
+		return nil
+	}
+	if err := s.Validate(extraInfoValue(ev.ExtraInfo)); err != nil {
+		return fmt.Errorf("event %q of component %q violates the contract: %w", ev.Name, component, err)
+	}
+	return nil
+}
+
+// DeclaresEvent returns true if the contract of the component
+// declares the extra info schema of the event name.
+func DeclaresEvent(component string, eventName string) bool {
+	_, ok := contracts[component].Events[eventName]
+	return ok
+}
+
+// HealthStateExtraInfoSchema returns the health state extra info schema
+// of the check result data, where the data schema is generated from the value
+// (see [Generate]), and the data is optional (e.g., not checked yet).
+func HealthStateExtraInfoSchema(data any) *Schema {
+	return &Schema{
+		Type: Types{TypeObject},
+		Properties: map[string]*Schema{
+			ExtraInfoKeyData: {
+				Type:             Types{TypeString},
+				ContentMediaType: ContentMediaTypeJSON,
+				ContentSchema:    Generate(data),
+			},
+		},
+		AdditionalProperties: False(),
+	}
+}
+
+// extraInfoValue returns the extra info as the decoded JSON object.
+func extraInfoValue(extraInfo map[string]string) map[string]any {
+	v := make(map[string]any, len(extraInfo))
+	for k, s := range extraInfo {
+		v[k] = s
+	}
+	return v
+}
+
+// Marshal returns the contract encoded as the checked-in file.
+func Marshal(c Contract) ([]byte, error) {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
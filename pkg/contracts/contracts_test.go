@@ -0,0 +1,97 @@
+package contracts
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+// TestSchemasFormat fails if a checked-in contract is not in the canonical format,
+// so that the regenerated contracts only diff on the actual changes.
+func TestSchemasFormat(t *testing.T) {
+	require.NotEmpty(t, All())
+
+	update, _ := strconv.ParseBool(os.Getenv("GPUD_UPDATE_CONTRACTS"))
+	for _, c := range All() {
+		p := filepath.Join(SchemasDir, c.Component+".json")
+		b, err := os.ReadFile(p)
+		require.NoError(t, err)
+
+		want, err := Marshal(c)
+		require.NoError(t, err)
+		if update {
+			require.NoError(t, os.WriteFile(p, want, 0o644))
+			continue
+		}
+		assert.Equal(t, string(want), string(b), "%s not in the canonical format (rerun with GPUD_UPDATE_CONTRACTS=true)", p)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/a.json": {Data: []byte(`{"component":"a","events":{"ev":{"type":"object"}}}`)},
+	}
+	loaded, err := load(fsys, "schemas")
+	require.NoError(t, err)
+	assert.Equal(t, Contract{Component: "a", Events: map[string]*Schema{"ev": {Type: Types{TypeObject}}}}, loaded["a"])
+
+	fsys["schemas/b.json"] = &fstest.MapFile{Data: []byte(`{"component":"c"}`)}
+	_, err = load(fsys, "schemas")
+	assert.EqualError(t, err, `"b.json" declares component "c", expected "b"`)
+
+	fsys["schemas/b.json"] = &fstest.MapFile{Data: []byte(`{"component":"b","events":{"ev":{"type":"map"}}}`)}
+	_, err = load(fsys, "schemas")
+	assert.EqualError(t, err, `"b.json": contract of component "b" has the malformed schema of event "ev": /: unknown type "map"`)
+
+	fsys["schemas/b.json"] = &fstest.MapFile{Data: []byte(`{`)}
+	_, err = load(fsys, "schemas")
+	assert.ErrorContains(t, err, `failed to parse "b.json"`)
+}
+
+func TestValidate(t *testing.T) {
+	orig := contracts
+	defer func() { contracts = orig }()
+
+	contracts = map[string]Contract{
+		"test": {
+			Component:            "test",
+			HealthStateExtraInfo: HealthStateExtraInfoSchema(&testEmbedded{}),
+			Events: map[string]*Schema{
+				"ev": {
+					Type:                 Types{TypeObject},
+					Properties:           map[string]*Schema{"gpu_uuid": {Type: Types{TypeString}}},
+					Required:             []string{"gpu_uuid"},
+					AdditionalProperties: False(),
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, ValidateHealthState("test", apiv1.HealthState{Name: "test"}))
+	assert.NoError(t, ValidateHealthState("test", apiv1.HealthState{Name: "test", ExtraInfo: map[string]string{"data": `{"embedded":"a"}`}}))
+	assert.EqualError(t,
+		ValidateHealthState("test", apiv1.HealthState{Name: "test", ExtraInfo: map[string]string{"data": `{"embedded":1}`}}),
+		`health state "test" of component "test" violates the contract: /data/embedded: expected string, got integer`)
+	assert.EqualError(t,
+		ValidateHealthState("test", apiv1.HealthState{Name: "test", ExtraInfo: map[string]string{"other": "a"}}),
+		`health state "test" of component "test" violates the contract: /other: not allowed`)
+	assert.NoError(t, ValidateHealthState("unknown", apiv1.HealthState{ExtraInfo: map[string]string{"other": "a"}}))
+
+	assert.True(t, DeclaresEvent("test", "ev"))
+	assert.False(t, DeclaresEvent("test", "other"))
+	assert.False(t, DeclaresEvent("unknown", "ev"))
+
+	assert.NoError(t, ValidateEvent("test", eventstore.Event{Name: "ev", ExtraInfo: map[string]string{"gpu_uuid": "GPU-0"}}))
+	assert.EqualError(t,
+		ValidateEvent("test", eventstore.Event{Name: "ev"}),
+		`event "ev" of component "test" violates the contract: /: missing required property "gpu_uuid"`)
+	assert.NoError(t, ValidateEvent("test", eventstore.Event{Name: "other", ExtraInfo: map[string]string{"a": "b"}}))
+}
@@ -0,0 +1,111 @@
+// Package contractstest asserts the component output against the checked-in
+// data contracts in the component tests.
+//
+// e.g.,
+//
+//	func TestDataContract(t *testing.T) {
+//		contractstest.AssertHealthStateData(t, Name, &checkResult{})
+//	}
+//
+// To regenerate the contracts after changing the check result on purpose, run the
+// tests with "GPUD_UPDATE_CONTRACTS=true", and review the schema diffs.
+package contractstest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/contracts"
+	"github.com/leptonai/gpud/pkg/eventstore"
+)
+
+// EnvUpdateContracts is the environment variable to regenerate the contracts
+// from the check results, rather than failing the tests.
+const EnvUpdateContracts = "GPUD_UPDATE_CONTRACTS"
+
+// AssertHealthStateData fails the test if the health state extra info contract
+// of the component does not match the schema generated from the check result data
+// (e.g., a JSON key renamed, a field added or removed).
+func AssertHealthStateData(t *testing.T, component string, data any) {
+	t.Helper()
+
+	c, ok := contracts.Get(component)
+	if !ok {
+		c = contracts.Contract{Component: component}
+	}
+	expected := contracts.HealthStateExtraInfoSchema(data)
+
+	got, err := contracts.Marshal(contracts.Contract{Component: component, HealthStateExtraInfo: c.HealthStateExtraInfo})
+	if err != nil {
+		t.Fatalf("failed to marshal contract: %v", err)
+	}
+	want, err := contracts.Marshal(contracts.Contract{Component: component, HealthStateExtraInfo: expected})
+	if err != nil {
+		t.Fatalf("failed to marshal contract: %v", err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	if update, _ := strconv.ParseBool(os.Getenv(EnvUpdateContracts)); update {
+		c.HealthStateExtraInfo = expected
+		write(t, c)
+		return
+	}
+	t.Errorf("health state data of component %q does not match the contract in %s (rerun with %s=true to update, and review the diff):\ncontract:\n%s\ncheck result:\n%s",
+		component, path(t, component), EnvUpdateContracts, got, want)
+}
+
+// AssertHealthStates fails the test if any of the health states
+// violates the contract of the component.
+func AssertHealthStates(t *testing.T, component string, states apiv1.HealthStates) {
+	t.Helper()
+
+	for _, st := range states {
+		if err := contracts.ValidateHealthState(component, st); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// AssertEvents fails the test if any of the events is not declared by the contract
+// of the component, or violates the contract.
+func AssertEvents(t *testing.T, component string, evs ...eventstore.Event) {
+	t.Helper()
+
+	for _, ev := range evs {
+		if !contracts.DeclaresEvent(component, ev.Name) {
+			t.Errorf("event %q of component %q not declared in %s", ev.Name, component, path(t, component))
+			continue
+		}
+		if err := contracts.ValidateEvent(component, ev); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// path returns the path of the contract file of the component.
+func path(t *testing.T, component string) string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("failed to locate the contracts")
+	}
+	return filepath.Join(filepath.Dir(file), "..", contracts.SchemasDir, component+".json")
+}
+
+func write(t *testing.T, c contracts.Contract) {
+	b, err := contracts.Marshal(c)
+	if err != nil {
+		t.Fatalf("failed to marshal contract: %v", err)
+	}
+	p := path(t, c.Component)
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatalf("failed to write contract: %v", err)
+	}
+	t.Logf("updated contract %s", p)
+}
@@ -0,0 +1,80 @@
+package contracts
+
+import (
+	"context"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// ExtraInfoKeyViolation is the extra info key of the health state whose extra info
+// was rejected for violating the contract, set to the violation.
+const ExtraInfoKeyViolation = "contract_violation"
+
+// EnforceHealthStates returns the health states with the extra info violating the
+// contract of the component replaced by the violation (see [ExtraInfoKeyViolation]),
+// so that the downstream parsers never see the malformed extra info, while the health
+// and the reason are kept as is. Returns the health states as is and false if all valid.
+func EnforceHealthStates(component string, states apiv1.HealthStates) (apiv1.HealthStates, bool) {
+	var copied apiv1.HealthStates
+	for i, st := range states {
+		err := ValidateHealthState(component, st)
+		if err == nil {
+			continue
+		}
+
+		log.Logger.Warnw("rejected health state extra info violating the contract", "component", component, "healthState", st.Name, "error", err)
+		metricViolations.WithLabelValues(component, kindHealthState).Inc()
+
+		if copied == nil {
+			copied = append(apiv1.HealthStates(nil), states...)
+		}
+		copied[i].ExtraInfo = map[string]string{ExtraInfoKeyViolation: err.Error()}
+	}
+	if copied == nil {
+		return states, false
+	}
+	return copied, true
+}
+
+// WrapEventStore returns the event store whose buckets reject
+// the events violating the contract of the component, named after the bucket.
+func WrapEventStore(store eventstore.Store) eventstore.Store {
+	if store == nil {
+		return nil
+	}
+	return &eventStore{Store: store}
+}
+
+var _ eventstore.Store = &eventStore{}
+
+type eventStore struct {
+	eventstore.Store
+}
+
+func (s *eventStore) Bucket(name string, opts ...eventstore.OpOption) (eventstore.Bucket, error) {
+	bucket, err := s.Store.Bucket(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &eventBucket{Bucket: bucket, component: name}, nil
+}
+
+var _ eventstore.Bucket = &eventBucket{}
+
+type eventBucket struct {
+	eventstore.Bucket
+	// the bucket name passed to the store, as the bucket may report the table name
+	component string
+}
+
+func (b *eventBucket) Insert(ctx context.Context, ev eventstore.Event) error {
+	if err := ValidateEvent(b.component, ev); err != nil {
+		// not returned, as the events sampled away by the data budget
+		log.Logger.Warnw("rejected event violating the contract", "component", b.component, "event", ev.Name, "error", err)
+		metricViolations.WithLabelValues(b.component, kindEvent).Inc()
+		return nil
+	}
+	return b.Bucket.Insert(ctx, ev)
+}
@@ -0,0 +1,77 @@
+package contracts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func setTestContract(t *testing.T) {
+	orig := contracts
+	t.Cleanup(func() { contracts = orig })
+
+	contracts = map[string]Contract{
+		"test": {
+			Component:            "test",
+			HealthStateExtraInfo: HealthStateExtraInfoSchema(&testEmbedded{}),
+			Events: map[string]*Schema{
+				"ev": {Type: Types{TypeObject}, Required: []string{"gpu_uuid"}},
+			},
+		},
+	}
+}
+
+func TestEnforceHealthStates(t *testing.T) {
+	setTestContract(t)
+
+	valid := apiv1.HealthStates{{Name: "test", Health: apiv1.HealthStateTypeHealthy, ExtraInfo: map[string]string{"data": `{"embedded":"a"}`}}}
+	enforced, rejected := EnforceHealthStates("test", valid)
+	assert.False(t, rejected)
+	assert.Equal(t, valid, enforced)
+
+	invalid := apiv1.HealthStates{{Name: "test", Health: apiv1.HealthStateTypeUnhealthy, Reason: "bad", ExtraInfo: map[string]string{"data": `{"embedded":"a","renamed":"a"}`}}}
+	enforced, rejected = EnforceHealthStates("test", invalid)
+	assert.True(t, rejected)
+	require.Len(t, enforced, 1)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, enforced[0].Health)
+	assert.Equal(t, "bad", enforced[0].Reason)
+	assert.Equal(t, map[string]string{ExtraInfoKeyViolation: `health state "test" of component "test" violates the contract: /data/renamed: not allowed`}, enforced[0].ExtraInfo)
+
+	// the original health states are not modified
+	assert.Equal(t, `{"embedded":"a","renamed":"a"}`, invalid[0].ExtraInfo["data"])
+}
+
+func TestWrapEventStore(t *testing.T) {
+	setTestContract(t)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	assert.Nil(t, WrapEventStore(nil))
+
+	bucket, err := WrapEventStore(store).Bucket("test")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: now, Name: "ev", Type: string(apiv1.EventTypeWarning), ExtraInfo: map[string]string{"gpu_uuid": "GPU-0"}}))
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: now, Name: "ev", Type: string(apiv1.EventTypeWarning), Message: "rejected"}))
+	require.NoError(t, bucket.Insert(ctx, eventstore.Event{Time: now, Name: "other", Type: string(apiv1.EventTypeInfo)}))
+
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	for _, ev := range evs {
+		assert.NotEqual(t, "rejected", ev.Message)
+	}
+}
@@ -0,0 +1,162 @@
+package contracts
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	metaTimeType       = reflect.TypeOf(metav1.Time{})
+	metaDurationType   = reflect.TypeOf(metav1.Duration{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generate returns the schema of the JSON encoding of the value (e.g., the check result),
+// following the "encoding/json" rules: the exported fields by the JSON tag names,
+// required unless "omitempty", the embedded structs flattened, and the nil
+// pointers, slices, and maps encoded as null. The objects disallow the properties
+// not declared, so that the added or renamed keys fail the contract.
+//
+// The value is dereferenced if a pointer, and the types with the custom
+// JSON encoding are accepted as any value, except the well-known time types.
+func Generate(v any) *Schema {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return generate(t, make(map[reflect.Type]bool))
+}
+
+func generate(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case timeType, metaDurationType:
+		return &Schema{Type: Types{TypeString}}
+	case metaTimeType:
+		// the zero time is encoded as null
+		return &Schema{Type: Types{TypeString, TypeNull}}
+	case jsonRawMessageType:
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Pointer {
+		s := generate(t.Elem(), visiting)
+		return nullable(s)
+	}
+
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: Types{TypeString}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{TypeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// base64 encoded
+			return &Schema{Type: Types{TypeString, TypeNull}}
+		}
+		return &Schema{Type: Types{TypeArray, TypeNull}, Items: generate(t.Elem(), visiting)}
+	case reflect.Array:
+		return &Schema{Type: Types{TypeArray}, Items: generate(t.Elem(), visiting)}
+
+	case reflect.Map:
+		return &Schema{Type: Types{TypeObject, TypeNull}, AdditionalProperties: generate(t.Elem(), visiting)}
+
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive type
+			return &Schema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: Types{TypeObject}, Properties: make(map[string]*Schema), AdditionalProperties: False()}
+		addFields(s, t, visiting)
+		return s
+
+	default:
+		// interfaces, and the types not encoded by "encoding/json" (e.g., channels)
+		return &Schema{}
+	}
+}
+
+// addFields adds the struct fields as the object properties,
+// with the embedded struct fields flattened.
+func addFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !ft.Implements(jsonMarshalerType) {
+				addFields(s, ft, visiting)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := generate(f.Type, visiting)
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: Types{TypeString}}
+		}
+		s.Properties[name] = prop
+		if !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOption(opts string, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func nullable(s *Schema) *Schema {
+	if len(s.Type) == 0 || s.Type.has(TypeNull) {
+		return s
+	}
+	copied := *s
+	copied.Type = append(append(Types{}, s.Type...), TypeNull)
+	return &copied
+}
@@ -0,0 +1,95 @@
+package contracts
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testNode struct {
+	Children []testNode `json:"children,omitempty"`
+}
+
+type testResult struct {
+	testEmbedded
+
+	Name     string            `json:"name"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Enabled  *bool             `json:"enabled"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Quoted   int               `json:"quoted,string"`
+	Time     time.Time         `json:"time"`
+	MetaTime metav1.Time       `json:"meta_time"`
+	Node     testNode          `json:"node"`
+	NoTag    string
+	Ignored  string `json:"-"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(&testResult{})
+	require.NoError(t, s.Check())
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "type": "object",
+  "properties": {
+    "embedded": {"type": "string"},
+    "name": {"type": "string"},
+    "count": {"type": "integer"},
+    "ratio": {"type": "number"},
+    "enabled": {"type": ["boolean", "null"]},
+    "tags": {"type": ["array", "null"], "items": {"type": "string"}},
+    "labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+    "raw": {"type": ["string", "null"]},
+    "quoted": {"type": "string"},
+    "time": {"type": "string"},
+    "meta_time": {"type": ["string", "null"]},
+    "node": {
+      "type": "object",
+      "properties": {"children": {"type": ["array", "null"], "items": {}}},
+      "additionalProperties": false
+    },
+    "NoTag": {"type": "string"}
+  },
+  "required": ["embedded", "name", "ratio", "enabled", "tags", "quoted", "time", "meta_time", "node", "NoTag"],
+  "additionalProperties": false
+}`, string(b))
+
+	// the encoded zero value and a populated one match the generated schema
+	for _, v := range []testResult{
+		{},
+		{
+			testEmbedded: testEmbedded{Embedded: "e"},
+			Name:         "a",
+			Count:        1,
+			Ratio:        0.5,
+			Tags:         []string{"t"},
+			Labels:       map[string]string{"k": "v"},
+			Raw:          []byte("raw"),
+			Quoted:       1,
+			Time:         time.Now(),
+			MetaTime:     metav1.Now(),
+			Node:         testNode{Children: []testNode{{}}},
+		},
+	} {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		var doc any
+		require.NoError(t, json.Unmarshal(b, &doc))
+		assert.NoError(t, s.Validate(doc), string(b))
+	}
+
+	assert.Equal(t, &Schema{}, Generate(nil))
+}
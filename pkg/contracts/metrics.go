@@ -0,0 +1,26 @@
+package contracts
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	kindHealthState = "health_state"
+	kindEvent       = "event"
+)
+
+var metricViolations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "gpud",
+		Subsystem: "contracts",
+		Name:      "violations_total",
+		Help:      "total number of the health states and the events rejected for violating the component data contracts",
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey, "kind"},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricViolations)
+}
@@ -0,0 +1,337 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The JSON Schema types.
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// ContentMediaTypeJSON is the content media type of the string values
+// that are JSON documents (e.g., the "data" health state extra info).
+const ContentMediaTypeJSON = "application/json"
+
+// Schema is the subset of the JSON Schema (draft 2020-12) to declare
+// the component data contracts: the types, the object properties,
+// the array items, the enums and patterns of the strings, and the
+// JSON documents encoded in the strings ("contentMediaType" and "contentSchema").
+// An empty schema accepts any value.
+type Schema struct {
+	Description string `json:"description,omitempty"`
+
+	// Type is the allowed types, any type if empty.
+	Type Types `json:"type,omitempty"`
+
+	// Properties is the schemas of the object properties.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Required is the object properties that must be present.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is the schema of the object properties not in the Properties,
+	// nil to allow any, or the schema rejecting any ("false") to disallow.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+
+	// Items is the schema of the array items.
+	Items *Schema `json:"items,omitempty"`
+
+	// Enum is the allowed values.
+	Enum []any `json:"enum,omitempty"`
+	// Pattern is the regular expression the strings must match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// ContentMediaType is the media type of the string contents
+	// (only "application/json" is validated).
+	ContentMediaType string `json:"contentMediaType,omitempty"`
+	// ContentSchema is the schema of the JSON document in the string.
+	ContentSchema *Schema `json:"contentSchema,omitempty"`
+
+	// set if the schema is the boolean "false" schema
+	reject bool
+}
+
+// False returns the schema rejecting any value
+// (e.g., to disallow the additional properties).
+func False() *Schema {
+	return &Schema{reject: true}
+}
+
+// MarshalJSON marshals the boolean schema as "false", and the rest as the object.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.reject {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// UnmarshalJSON unmarshals the boolean schemas ("true" or "false"), and the object schemas.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{reject: true}
+		return nil
+	}
+	type plain Schema
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*s = Schema(p)
+	return nil
+}
+
+// Types is the allowed JSON Schema types, marshaled as the string if only one.
+type Types []string
+
+// MarshalJSON marshals the single type as the string, and the rest as the array.
+func (ts Types) MarshalJSON() ([]byte, error) {
+	if len(ts) == 1 {
+		return json.Marshal(ts[0])
+	}
+	return json.Marshal([]string(ts))
+}
+
+// UnmarshalJSON unmarshals the type string or the array of the type strings.
+func (ts *Types) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*ts = Types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings: %w", err)
+	}
+	*ts = many
+	return nil
+}
+
+func (ts Types) has(typ string) bool {
+	for _, t := range ts {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an error if the schema is malformed
+// (e.g., unknown type, invalid pattern, required property not declared).
+func (s *Schema) Check() error {
+	return s.check("")
+}
+
+func (s *Schema) check(path string) error {
+	if s == nil || s.reject {
+		return nil
+	}
+	for _, t := range s.Type {
+		switch t {
+		case TypeObject, TypeArray, TypeString, TypeNumber, TypeInteger, TypeBoolean, TypeNull:
+		default:
+			return fmt.Errorf("%s: unknown type %q", pathOrRoot(path), t)
+		}
+	}
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", pathOrRoot(path), s.Pattern, err)
+		}
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok && s.AdditionalProperties != nil && s.AdditionalProperties.reject {
+			return fmt.Errorf("%s: required property %q not declared", pathOrRoot(path), name)
+		}
+	}
+	if s.ContentSchema != nil && s.ContentMediaType != ContentMediaTypeJSON {
+		return fmt.Errorf("%s: content schema without the %q content media type", pathOrRoot(path), ContentMediaTypeJSON)
+	}
+
+	for _, name := range sortedKeys(s.Properties) {
+		if err := s.Properties[name].check(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	if err := s.AdditionalProperties.check(path + "/*"); err != nil {
+		return err
+	}
+	if err := s.Items.check(path + "/*"); err != nil {
+		return err
+	}
+	return s.ContentSchema.check(path)
+}
+
+// ValidationError is the value not matching the schema.
+type ValidationError struct {
+	// Path is the JSON pointer to the value (e.g., "/data/gpus/0/uuid"), empty for the root.
+	Path string
+	// Reason is why the value does not match (e.g., "expected string, got number").
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return pathOrRoot(e.Path) + ": " + e.Reason
+}
+
+// Validate returns the first value not matching the schema as the [ValidationError],
+// where the value is decoded from JSON (e.g., "map[string]any", "[]any", "float64").
+func (s *Schema) Validate(v any) error {
+	return s.validate("", v)
+}
+
+func (s *Schema) validate(path string, v any) error {
+	if s == nil {
+		return nil
+	}
+	if s.reject {
+		return &ValidationError{Path: path, Reason: "not allowed"}
+	}
+
+	typ := typeOf(v)
+	// the whole numbers are also of the "number" type
+	if len(s.Type) > 0 && !s.Type.has(typ) && (typ != TypeInteger || !s.Type.has(TypeNumber)) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), typ)}
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) && typeOf(e) == typ {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("%v is not one of %v", v, s.Enum)}
+		}
+	}
+
+	switch vv := v.(type) {
+	case string:
+		if s.Pattern != "" {
+			re, err := compilePattern(s.Pattern)
+			if err != nil {
+				return &ValidationError{Path: path, Reason: err.Error()}
+			}
+			if !re.MatchString(vv) {
+				return &ValidationError{Path: path, Reason: fmt.Sprintf("%q does not match %q", vv, s.Pattern)}
+			}
+		}
+		if s.ContentMediaType == ContentMediaTypeJSON {
+			var doc any
+			if err := json.Unmarshal([]byte(vv), &doc); err != nil {
+				return &ValidationError{Path: path, Reason: "invalid JSON: " + err.Error()}
+			}
+			if err := s.ContentSchema.validate(path, doc); err != nil {
+				return err
+			}
+		}
+
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := vv[name]; !ok {
+				return &ValidationError{Path: path, Reason: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+		for _, name := range sortedKeys(vv) {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if err := prop.validate(path+"/"+name, vv[name]); err != nil {
+				return err
+			}
+		}
+
+	case []any:
+		for i, item := range vv {
+			if err := s.Items.validate(fmt.Sprintf("%s/%d", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// typeOf returns the JSON Schema type of the decoded JSON value,
+// "integer" for the whole numbers.
+func typeOf(v any) string {
+	switch vv := v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case float64:
+		if vv == math.Trunc(vv) && !math.IsInf(vv, 0) {
+			return TypeInteger
+		}
+		return TypeNumber
+	case int, int64:
+		return TypeInteger
+	case json.Number:
+		if _, err := vv.Int64(); err == nil {
+			return TypeInteger
+		}
+		return TypeNumber
+	case map[string]any:
+		return TypeObject
+	case []any:
+		return TypeArray
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = make(map[string]*regexp.Regexp)
+)
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package contracts
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	s := &Schema{
+		Type: Types{TypeObject},
+		Properties: map[string]*Schema{
+			"name":  {Type: Types{TypeString}, Pattern: "^gpu-[0-9]+$"},
+			"count": {Type: Types{TypeInteger}},
+			"ratio": {Type: Types{TypeNumber}},
+			"state": {Type: Types{TypeString}, Enum: []any{"on", "off"}},
+			"tags":  {Type: Types{TypeArray, TypeNull}, Items: &Schema{Type: Types{TypeString}}},
+			"data": {
+				Type:             Types{TypeString},
+				ContentMediaType: ContentMediaTypeJSON,
+				ContentSchema:    &Schema{Type: Types{TypeObject}, Required: []string{"ok"}, Properties: map[string]*Schema{"ok": {Type: Types{TypeBoolean}}}},
+			},
+		},
+		Required:             []string{"name"},
+		AdditionalProperties: False(),
+	}
+	require.NoError(t, s.Check())
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{name: "valid", doc: `{"name":"gpu-0","count":3,"ratio":3,"state":"on","tags":["a"],"data":"{\"ok\":true}"}`},
+		{name: "null array", doc: `{"name":"gpu-0","tags":null}`},
+		{name: "missing required", doc: `{"count":1}`, wantErr: `/: missing required property "name"`},
+		{name: "additional property", doc: `{"name":"gpu-0","extra":1}`, wantErr: "/extra: not allowed"},
+		{name: "wrong type", doc: `{"name":"gpu-0","count":"1"}`, wantErr: "/count: expected integer, got string"},
+		{name: "not integer", doc: `{"name":"gpu-0","count":1.5}`, wantErr: "/count: expected integer, got number"},
+		{name: "pattern", doc: `{"name":"cpu"}`, wantErr: `/name: "cpu" does not match "^gpu-[0-9]+$"`},
+		{name: "enum", doc: `{"name":"gpu-0","state":"unknown"}`, wantErr: "/state: unknown is not one of [on off]"},
+		{name: "array item", doc: `{"name":"gpu-0","tags":["a",1]}`, wantErr: "/tags/1: expected string, got integer"},
+		{name: "invalid content", doc: `{"name":"gpu-0","data":"{"}`, wantErr: "/data: invalid JSON: unexpected end of JSON input"},
+		{name: "content schema", doc: `{"name":"gpu-0","data":"{\"ok\":1}"}`, wantErr: "/data/ok: expected boolean, got integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(tt.doc), &v))
+
+			err := s.Validate(v)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	assert.NoError(t, (*Schema)(nil).Check())
+	assert.EqualError(t, (&Schema{Type: Types{"map"}}).Check(), `/: unknown type "map"`)
+	assert.ErrorContains(t, (&Schema{Properties: map[string]*Schema{"a": {Pattern: "("}}}).Check(), `/a: invalid pattern "("`)
+	assert.EqualError(t, (&Schema{Required: []string{"a"}, AdditionalProperties: False()}).Check(), `/: required property "a" not declared`)
+	assert.NoError(t, (&Schema{Required: []string{"a"}}).Check())
+	assert.EqualError(t, (&Schema{ContentSchema: &Schema{}}).Check(), `/: content schema without the "application/json" content media type`)
+}
+
+func TestSchemaJSON(t *testing.T) {
+	s := &Schema{
+		Type:                 Types{TypeObject},
+		Properties:           map[string]*Schema{"a": {Type: Types{TypeString, TypeNull}}},
+		AdditionalProperties: False(),
+	}
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"a":{"type":["string","null"]}},"additionalProperties":false}`, string(b))
+
+	var decoded Schema
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, *s, decoded)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"additionalProperties":true}`), &decoded))
+	assert.Equal(t, Schema{AdditionalProperties: &Schema{}}, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"type":1}`), &decoded))
+}
@@ -0,0 +1,223 @@
+// Package crashreport captures the recovered panics (e.g., in the component checks
+// and the HTTP handlers) as the JSON reports with the stack and the input context
+// in a local directory, keeping the newest reports, so that a panic neither takes
+// down the daemon nor gets silently swallowed.
+//
+// The recovered panics are counted by the "gpud_panics_recovered_total" metric.
+package crashreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/version"
+)
+
+const (
+	// KindComponent is the kind of the panics in the component checks.
+	KindComponent = "component"
+	// KindHTTP is the kind of the panics in the HTTP handlers.
+	KindHTTP = "http"
+
+	// DefaultMaxReports is the default number of the newest crash reports to keep.
+	DefaultMaxReports = 50
+
+	// maxStackBytes is the maximum size of the stack in a report,
+	// to not fill the disk with the deep recursions.
+	maxStackBytes = 64 * 1024
+
+	fileExt = ".json"
+)
+
+// Report is a recovered panic.
+type Report struct {
+	// Time is when the panic was recovered.
+	Time time.Time `json:"time"`
+	// Kind is where the panic was recovered (e.g., "component", "http").
+	Kind string `json:"kind"`
+	// Component is the name of the component whose check panicked,
+	// empty for the HTTP handlers.
+	Component string `json:"component,omitempty"`
+	// Panic is the recovered value.
+	Panic string `json:"panic"`
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string `json:"stack"`
+	// Context is the input context of the panicking call
+	// (e.g., the HTTP method, path, and request ID).
+	Context map[string]string `json:"context,omitempty"`
+	// Version is the GPUd version.
+	Version string `json:"version"`
+
+	// File is the path of the written report, empty if not written.
+	File string `json:"-"`
+}
+
+// Writer writes the crash reports to a directory,
+// removing the oldest reports beyond the maximum.
+type Writer struct {
+	dir        string
+	maxReports int
+
+	mu sync.Mutex
+}
+
+// NewWriter creates the writer of the crash reports in the directory,
+// created on the first report if not exists.
+// Zero maxReports keeps DefaultMaxReports reports.
+func NewWriter(dir string, maxReports int) *Writer {
+	if maxReports <= 0 {
+		maxReports = DefaultMaxReports
+	}
+	return &Writer{dir: dir, maxReports: maxReports}
+}
+
+// Dir returns the crash report directory.
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// Write writes the report, and returns the path of the report file.
+func (w *Writer) Write(r Report) (string, error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return "", err
+	}
+	p := filepath.Join(w.dir, fileName(r))
+	// the stacks and the request paths may carry the sensitive data
+	if err := os.WriteFile(p, b, 0o600); err != nil {
+		return "", err
+	}
+	if err := w.rotate(); err != nil {
+		log.Logger.Warnw("failed to rotate crash reports", "dir", w.dir, "error", err)
+	}
+	return p, nil
+}
+
+// rotate removes the oldest reports beyond the maximum.
+func (w *Writer) rotate() error {
+	files, err := List(w.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > w.maxReports {
+		if err := os.Remove(files[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// List returns the paths of the crash reports in the directory, oldest first.
+// Returns no error if the directory does not exist.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	// the file names are prefixed with the time
+	sort.Strings(files)
+
+	for i, f := range files {
+		files[i] = filepath.Join(dir, f)
+	}
+	return files, nil
+}
+
+// fileName returns the report file name, prefixed with the time to sort by the time
+// (e.g., "20250102T150405.000000000Z-component-accelerator-nvidia-xid.json").
+func fileName(r Report) string {
+	name := r.Component
+	if name == "" {
+		name = r.Kind
+	} else {
+		name = r.Kind + "-" + name
+	}
+	return r.Time.UTC().Format("20060102T150405.000000000Z") + "-" + sanitize(name) + fileExt
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+var (
+	defaultWriterMu sync.RWMutex
+	defaultWriter   *Writer
+)
+
+// SetWriter sets the writer of the crash reports captured by [Capture].
+// Set to nil to not write the reports (still logged and counted).
+func SetWriter(w *Writer) {
+	defaultWriterMu.Lock()
+	defaultWriter = w
+	defaultWriterMu.Unlock()
+}
+
+func getWriter() *Writer {
+	defaultWriterMu.RLock()
+	defer defaultWriterMu.RUnlock()
+	return defaultWriter
+}
+
+// Capture records the recovered panic value with the stack of the panicking goroutine
+// (e.g., "debug.Stack()" in the deferred function): logs the panic, counts it in the
+// metric, and writes the report with the writer set by [SetWriter].
+// The input context is copied as is into the report.
+func Capture(kind string, component string, recovered any, stack []byte, ctx map[string]string) Report {
+	if len(stack) > maxStackBytes {
+		stack = stack[:maxStackBytes]
+	}
+	r := Report{
+		Time:      time.Now().UTC(),
+		Kind:      kind,
+		Component: component,
+		Panic:     fmt.Sprint(recovered),
+		Stack:     string(stack),
+		Context:   ctx,
+		Version:   version.Version,
+	}
+	metricPanicsRecovered.WithLabelValues(component, kind).Inc()
+
+	if w := getWriter(); w != nil {
+		p, err := w.Write(r)
+		if err != nil {
+			log.Logger.Errorw("failed to write crash report", "dir", w.Dir(), "error", err)
+		}
+		r.File = p
+	}
+
+	log.Logger.Errorw("recovered panic", "kind", kind, "component", component, "panic", r.Panic, "context", ctx, "report", r.File, "stack", r.Stack)
+	return r
+}
@@ -0,0 +1,78 @@
+package crashreport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRotate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crash-reports")
+	w := NewWriter(dir, 3)
+
+	start := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	var written []string
+	for i := 0; i < 5; i++ {
+		p, err := w.Write(Report{Time: start.Add(time.Duration(i) * time.Second), Kind: KindComponent, Component: "accelerator-nvidia-xid", Panic: "boom"})
+		require.NoError(t, err)
+		written = append(written, p)
+	}
+
+	files, err := List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, written[2:], files)
+	assert.Equal(t, "20250102T150409.000000000Z-component-accelerator-nvidia-xid.json", filepath.Base(files[2]))
+
+	fi, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+}
+
+func TestListNotExist(t *testing.T) {
+	files, err := List(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFileName(t *testing.T) {
+	tm := time.Date(2025, 1, 2, 15, 4, 5, 6, time.UTC)
+	assert.Equal(t, "20250102T150405.000000006Z-http.json", fileName(Report{Time: tm, Kind: KindHTTP}))
+	assert.Equal(t, "20250102T150405.000000006Z-component-a_b_c.json", fileName(Report{Time: tm, Kind: KindComponent, Component: "a/b c"}))
+}
+
+func TestCapture(t *testing.T) {
+	dir := t.TempDir()
+	SetWriter(NewWriter(dir, 0))
+	t.Cleanup(func() { SetWriter(nil) })
+
+	before := testutil.ToFloat64(metricPanicsRecovered.WithLabelValues("test", KindComponent))
+	r := Capture(KindComponent, "test", "boom", []byte("goroutine 1 [running]:"), map[string]string{"call": "check"})
+	assert.Equal(t, before+1, testutil.ToFloat64(metricPanicsRecovered.WithLabelValues("test", KindComponent)))
+	assert.Equal(t, "boom", r.Panic)
+	require.NotEmpty(t, r.File)
+
+	b, err := os.ReadFile(r.File)
+	require.NoError(t, err)
+	var got Report
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, KindComponent, got.Kind)
+	assert.Equal(t, "test", got.Component)
+	assert.Equal(t, "boom", got.Panic)
+	assert.Equal(t, "goroutine 1 [running]:", got.Stack)
+	assert.Equal(t, map[string]string{"call": "check"}, got.Context)
+	assert.NotEmpty(t, got.Version)
+}
+
+func TestCaptureWithoutWriter(t *testing.T) {
+	SetWriter(nil)
+
+	r := Capture(KindHTTP, "", "boom", make([]byte, maxStackBytes+1), nil)
+	assert.Empty(t, r.File)
+	assert.Len(t, r.Stack, maxStackBytes)
+}
@@ -0,0 +1,21 @@
+package crashreport
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var metricPanicsRecovered = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "gpud",
+		Subsystem: "",
+		Name:      "panics_recovered_total",
+		Help:      "total number of the panics recovered in the component checks and the HTTP handlers",
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey, "kind"},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricPanicsRecovered)
+}
@@ -0,0 +1,141 @@
+package customplugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxAssertFileContentBytes is the maximum file size to match the content regex.
+const maxAssertFileContentBytes = 16 * 1024 * 1024
+
+// AssertFileResult is the result of the file assertion step,
+// written to the plugin output as a JSON line.
+type AssertFileResult struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+
+	Exists     bool    `json:"exists"`
+	AgeSeconds float64 `json:"age_seconds,omitempty"`
+	SizeBytes  int64   `json:"size_bytes,omitempty"`
+	SHA256     string  `json:"sha256,omitempty"`
+}
+
+// Validate validates the file assertion.
+func (a *AssertFile) Validate() error {
+	if a.Path == "" {
+		return ErrPathRequired
+	}
+	if a.MaxAge.Duration < 0 {
+		return fmt.Errorf("invalid max age %s", a.MaxAge.Duration)
+	}
+	if a.ContentRegex != "" {
+		if _, err := regexp.Compile(a.ContentRegex); err != nil {
+			return fmt.Errorf("invalid content regex %q: %w", a.ContentRegex, err)
+		}
+	}
+	if a.SHA256 != "" {
+		if b, err := hex.DecodeString(a.SHA256); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("invalid sha256 %q", a.SHA256)
+		}
+	}
+	if !a.expectExists() && (a.MaxAge.Duration > 0 || a.ContentRegex != "" || a.SHA256 != "") {
+		return errors.New("max_age, content_regex, and sha256 require the file to exist")
+	}
+	return nil
+}
+
+func (a *AssertFile) expectExists() bool {
+	return a.Exists == nil || *a.Exists
+}
+
+// execute checks the file and returns the result,
+// with the failure reason if any assertion fails (empty if passed).
+func (a *AssertFile) execute(stepName string) (AssertFileResult, string) {
+	return a.check(stepName, time.Now())
+}
+
+func (a *AssertFile) check(stepName string, now time.Time) (AssertFileResult, string) {
+	res := AssertFileResult{Step: stepName}
+	fail := func(format string, args ...any) (AssertFileResult, string) {
+		res.Reason = fmt.Sprintf(format, args...)
+		return res, res.Reason
+	}
+
+	info, err := os.Stat(a.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fail("failed to stat %q: %v", a.Path, err)
+	}
+	res.Exists = err == nil
+
+	if !a.expectExists() {
+		if res.Exists {
+			return fail("%q exists (expected not to exist)", a.Path)
+		}
+		res.Passed = true
+		return res, ""
+	}
+	if !res.Exists {
+		return fail("%q does not exist", a.Path)
+	}
+	if info.IsDir() {
+		return fail("%q is a directory", a.Path)
+	}
+
+	res.SizeBytes = info.Size()
+	age := now.Sub(info.ModTime())
+	res.AgeSeconds = age.Seconds()
+	if a.MaxAge.Duration > 0 && age > a.MaxAge.Duration {
+		return fail("%q last modified %s ago (max age %s)", a.Path, age.Truncate(time.Second), a.MaxAge.Duration)
+	}
+
+	if a.ContentRegex == "" && a.SHA256 == "" {
+		res.Passed = true
+		return res, ""
+	}
+	if a.ContentRegex != "" && info.Size() > maxAssertFileContentBytes {
+		return fail("%q is too large to match the content (%d bytes)", a.Path, info.Size())
+	}
+
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return fail("failed to open %q: %v", a.Path, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	var rd io.Reader = io.TeeReader(f, h)
+	var content []byte
+	if a.ContentRegex != "" {
+		content, err = io.ReadAll(io.LimitReader(rd, maxAssertFileContentBytes))
+	} else {
+		_, err = io.Copy(io.Discard, rd)
+	}
+	if err != nil {
+		return fail("failed to read %q: %v", a.Path, err)
+	}
+	res.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	if a.ContentRegex != "" {
+		// already validated
+		re := regexp.MustCompile(a.ContentRegex)
+		if !re.Match(content) {
+			return fail("%q content does not match %q", a.Path, a.ContentRegex)
+		}
+	}
+	if a.SHA256 != "" && !strings.EqualFold(res.SHA256, a.SHA256) {
+		return fail("%q sha256 %s does not match %s", a.Path, res.SHA256, a.SHA256)
+	}
+
+	res.Passed = true
+	return res, ""
+}
@@ -0,0 +1,124 @@
+package customplugins
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAssertFileValidate(t *testing.T) {
+	notExist := false
+	sum := sha256.Sum256([]byte("alive"))
+
+	assert.NoError(t, (&AssertFile{Path: "/tmp/heartbeat"}).Validate())
+	assert.NoError(t, (&AssertFile{Path: "/tmp/heartbeat", Exists: &notExist}).Validate())
+	assert.NoError(t, (&AssertFile{Path: "/tmp/heartbeat", SHA256: hex.EncodeToString(sum[:])}).Validate())
+	assert.ErrorIs(t, (&AssertFile{}).Validate(), ErrPathRequired)
+	assert.Error(t, (&AssertFile{Path: "/tmp/heartbeat", MaxAge: metav1.Duration{Duration: -time.Second}}).Validate())
+	assert.Error(t, (&AssertFile{Path: "/tmp/heartbeat", ContentRegex: "[invalid"}).Validate())
+	assert.Error(t, (&AssertFile{Path: "/tmp/heartbeat", SHA256: "abc"}).Validate())
+	assert.Error(t, (&AssertFile{Path: "/tmp/heartbeat", Exists: &notExist, ContentRegex: "alive"}).Validate())
+}
+
+func TestAssertFileCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "heartbeat")
+	require.NoError(t, os.WriteFile(path, []byte("alive\n"), 0o644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	now := info.ModTime().Add(5 * time.Minute)
+
+	sum := sha256.Sum256([]byte("alive\n"))
+	checksum := hex.EncodeToString(sum[:])
+	notExist := false
+
+	t.Run("exists", func(t *testing.T) {
+		res, failure := (&AssertFile{Path: path}).check("exists", now)
+		assert.Empty(t, failure)
+		assert.True(t, res.Passed)
+		assert.True(t, res.Exists)
+		assert.Equal(t, int64(6), res.SizeBytes)
+		assert.InDelta(t, 300, res.AgeSeconds, 1)
+		assert.Empty(t, res.SHA256)
+	})
+
+	t.Run("does not exist", func(t *testing.T) {
+		res, failure := (&AssertFile{Path: filepath.Join(dir, "missing")}).check("missing", now)
+		assert.Contains(t, failure, "does not exist")
+		assert.False(t, res.Passed)
+		assert.False(t, res.Exists)
+
+		res, failure = (&AssertFile{Path: filepath.Join(dir, "missing"), Exists: &notExist}).check("missing", now)
+		assert.Empty(t, failure)
+		assert.True(t, res.Passed)
+
+		_, failure = (&AssertFile{Path: path, Exists: &notExist}).check("exists", now)
+		assert.Contains(t, failure, "expected not to exist")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		_, failure := (&AssertFile{Path: dir}).check("dir", now)
+		assert.Contains(t, failure, "is a directory")
+	})
+
+	t.Run("freshness", func(t *testing.T) {
+		_, failure := (&AssertFile{Path: path, MaxAge: metav1.Duration{Duration: 10 * time.Minute}}).check("fresh", now)
+		assert.Empty(t, failure)
+
+		_, failure = (&AssertFile{Path: path, MaxAge: metav1.Duration{Duration: time.Minute}}).check("stale", now)
+		assert.Contains(t, failure, "last modified 5m0s ago (max age 1m0s)")
+	})
+
+	t.Run("content and checksum", func(t *testing.T) {
+		res, failure := (&AssertFile{Path: path, ContentRegex: "^alive", SHA256: checksum}).check("content", now)
+		assert.Empty(t, failure)
+		assert.Equal(t, checksum, res.SHA256)
+
+		_, failure = (&AssertFile{Path: path, ContentRegex: "^dead"}).check("content", now)
+		assert.Contains(t, failure, "content does not match")
+
+		res, failure = (&AssertFile{Path: path, SHA256: hex.EncodeToString(make([]byte, sha256.Size))}).check("checksum", now)
+		assert.Contains(t, failure, "sha256 "+checksum+" does not match")
+		assert.Equal(t, checksum, res.SHA256)
+	})
+}
+
+func TestExecuteAllStepsWithAssertFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heartbeat")
+	require.NoError(t, os.WriteFile(path, []byte("alive\n"), 0o644))
+
+	plugin := &Plugin{
+		Steps: []Step{
+			{
+				Name:       "check-heartbeat",
+				AssertFile: &AssertFile{Path: path, ContentRegex: "alive"},
+			},
+			{
+				Name: "echo",
+				RunBashScript: &RunBashScript{
+					ContentType: "plaintext",
+					Script:      "echo 'after assertion'",
+				},
+			},
+		},
+	}
+	output, exitCode, err := plugin.executeAllSteps(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), exitCode)
+	assert.Contains(t, string(output), "after assertion")
+
+	var res AssertFileResult
+	require.NoError(t, json.NewDecoder(bytes.NewReader(output)).Decode(&res))
+	assert.Equal(t, "check-heartbeat", res.Step)
+	assert.True(t, res.Passed)
+}
@@ -0,0 +1,155 @@
+package customplugins
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// ApplyActionCreated is the action of the plugin newly registered.
+	ApplyActionCreated = "created"
+	// ApplyActionUpdated is the action of the plugin re-registered with the changed spec.
+	ApplyActionUpdated = "updated"
+	// ApplyActionDeleted is the action of the plugin deregistered.
+	ApplyActionDeleted = "deleted"
+	// ApplyActionUnchanged is the action of the plugin kept as is.
+	ApplyActionUnchanged = "unchanged"
+	// ApplyActionSaved is the action of the init plugin, only saved
+	// and run on the next GPUd start.
+	ApplyActionSaved = "saved"
+)
+
+// ApplyResult is the result of a single plugin in the bulk apply.
+type ApplyResult struct {
+	// ComponentName is the component name of the plugin.
+	ComponentName string `json:"component_name"`
+	// Action is the change to the plugin (e.g., "created", "deleted").
+	Action string `json:"action"`
+	// Error is the error of the plugin if it failed to apply.
+	Error string `json:"error,omitempty"`
+}
+
+// RegisteredSpecs returns the specs of the custom plugins in the registry.
+func RegisteredSpecs(registry components.Registry) Specs {
+	var specs Specs
+	for _, c := range registry.All() {
+		if registeree, ok := c.(CustomPluginRegisteree); ok && registeree.IsCustomPlugin() {
+			specs = append(specs, registeree.Spec())
+		}
+	}
+	return specs
+}
+
+// DiffSpecs returns the changes to apply the desired specs over the current ones,
+// sorted by the component name. Both specs must be expanded.
+func DiffSpecs(current Specs, desired Specs) []ApplyResult {
+	currentByName := make(map[string]Spec, len(current))
+	for _, spec := range current {
+		currentByName[spec.ComponentName()] = spec
+	}
+
+	results := make([]ApplyResult, 0, len(current)+len(desired))
+	desiredNames := make(map[string]struct{}, len(desired))
+	for _, spec := range desired {
+		name := spec.ComponentName()
+		desiredNames[name] = struct{}{}
+
+		action := ApplyActionUnchanged
+		prev, ok := currentByName[name]
+		switch {
+		case spec.PluginType == SpecTypeInit:
+			action = ApplyActionSaved
+		case !ok:
+			action = ApplyActionCreated
+		case !(Specs{prev}).Equal(Specs{spec}):
+			action = ApplyActionUpdated
+		}
+		results = append(results, ApplyResult{ComponentName: name, Action: action})
+	}
+	for name := range currentByName {
+		if _, ok := desiredNames[name]; !ok {
+			results = append(results, ApplyResult{ComponentName: name, Action: ApplyActionDeleted})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ComponentName < results[j].ComponentName
+	})
+	return results
+}
+
+// ApplySpecs applies the expanded specs to the registry as a whole, or not at all:
+// the new plugins are registered, the changed ones re-registered, and the ones
+// not in the specs deregistered. The unchanged plugins keep running.
+//
+// If any plugin fails to initialize, or the save function fails, the previous
+// plugins are restored, and the error is returned with the results.
+// The save function is called after all plugins are registered, to persist the specs.
+func ApplySpecs(registry components.Registry, specs Specs, save func() error) ([]ApplyResult, error) {
+	results := DiffSpecs(RegisteredSpecs(registry), specs)
+
+	specsByName := make(map[string]*Spec, len(specs))
+	for i := range specs {
+		specsByName[specs[i].ComponentName()] = &specs[i]
+	}
+
+	var previous, current []components.Component
+	rollback := func() {
+		for _, c := range current {
+			_ = registry.Deregister(c.Name())
+			if err := c.Close(); err != nil {
+				log.Logger.Warnw("failed to close plugin on rollback", "name", c.Name(), "error", err)
+			}
+		}
+		for _, c := range previous {
+			prev := c
+			if _, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+				return prev, nil
+			}); err != nil {
+				log.Logger.Errorw("failed to restore plugin on rollback", "name", prev.Name(), "error", err)
+			}
+		}
+	}
+
+	for _, r := range results {
+		if r.Action == ApplyActionUpdated || r.Action == ApplyActionDeleted {
+			if c := registry.Deregister(r.ComponentName); c != nil {
+				previous = append(previous, c)
+			}
+		}
+	}
+	for i := range results {
+		if results[i].Action != ApplyActionCreated && results[i].Action != ApplyActionUpdated {
+			continue
+		}
+		c, err := registry.Register(specsByName[results[i].ComponentName].NewInitFunc())
+		if err != nil {
+			rollback()
+			results[i].Error = err.Error()
+			return results, fmt.Errorf("failed to initialize plugin %q: %w", results[i].ComponentName, err)
+		}
+		current = append(current, c)
+	}
+
+	if save != nil {
+		if err := save(); err != nil {
+			rollback()
+			return results, fmt.Errorf("failed to save plugin specs: %w", err)
+		}
+	}
+
+	for _, c := range previous {
+		if err := c.Close(); err != nil {
+			log.Logger.Warnw("failed to close previous plugin", "name", c.Name(), "error", err)
+		}
+	}
+	for _, c := range current {
+		if err := c.Start(); err != nil {
+			log.Logger.Warnw("failed to start plugin", "name", c.Name(), "error", err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package customplugins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/components"
+)
+
+func newBulkTestSpec(name string, script string) Spec {
+	return Spec{
+		PluginName: name,
+		PluginType: SpecTypeComponent,
+		RunMode:    "manual",
+		HealthStatePlugin: &Plugin{
+			Steps: []Step{
+				{
+					Name: "step",
+					RunBashScript: &RunBashScript{
+						ContentType: "plaintext",
+						Script:      script,
+					},
+				},
+			},
+		},
+		Timeout: metav1.Duration{Duration: 10 * time.Second},
+	}
+}
+
+func TestDiffSpecs(t *testing.T) {
+	current := Specs{
+		newBulkTestSpec("keep", "echo keep"),
+		newBulkTestSpec("change", "echo old"),
+		newBulkTestSpec("remove", "echo remove"),
+	}
+	initSpec := newBulkTestSpec("setup", "echo setup")
+	initSpec.PluginType = SpecTypeInit
+	desired := Specs{
+		newBulkTestSpec("keep", "echo keep"),
+		newBulkTestSpec("change", "echo new"),
+		newBulkTestSpec("add", "echo add"),
+		initSpec,
+	}
+
+	results := DiffSpecs(current, desired)
+	assert.Equal(t, []ApplyResult{
+		{ComponentName: desired[2].ComponentName(), Action: ApplyActionCreated},
+		{ComponentName: desired[1].ComponentName(), Action: ApplyActionUpdated},
+		{ComponentName: desired[0].ComponentName(), Action: ApplyActionUnchanged},
+		{ComponentName: current[2].ComponentName(), Action: ApplyActionDeleted},
+		{ComponentName: initSpec.ComponentName(), Action: ApplyActionSaved},
+	}, results)
+}
+
+func TestApplySpecs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	initial := Specs{
+		newBulkTestSpec("keep", "echo keep"),
+		newBulkTestSpec("change", "echo old"),
+		newBulkTestSpec("remove", "echo remove"),
+	}
+	_, err := ApplySpecs(registry, initial, nil)
+	require.NoError(t, err)
+	require.Len(t, RegisteredSpecs(registry), 3)
+	kept := registry.Get(initial[0].ComponentName())
+
+	desired := Specs{
+		newBulkTestSpec("keep", "echo keep"),
+		newBulkTestSpec("change", "echo new"),
+		newBulkTestSpec("add", "echo add"),
+	}
+	saved := false
+	results, err := ApplySpecs(registry, desired, func() error {
+		saved = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, saved)
+	assert.Len(t, results, 4)
+
+	assert.Nil(t, registry.Get(initial[2].ComponentName()))
+	assert.NotNil(t, registry.Get(desired[2].ComponentName()))
+	// unchanged plugins keep running
+	assert.Equal(t, kept, registry.Get(desired[0].ComponentName()))
+
+	changed, ok := registry.Get(desired[1].ComponentName()).(CustomPluginRegisteree)
+	require.True(t, ok)
+	assert.Equal(t, "echo new", changed.Spec().HealthStatePlugin.Steps[0].RunBashScript.Script)
+}
+
+func TestApplySpecsRollback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	initial := Specs{
+		newBulkTestSpec("change", "echo old"),
+		newBulkTestSpec("remove", "echo remove"),
+	}
+	_, err := ApplySpecs(registry, initial, nil)
+	require.NoError(t, err)
+
+	desired := Specs{
+		newBulkTestSpec("change", "echo new"),
+		newBulkTestSpec("add", "echo add"),
+	}
+	_, err = ApplySpecs(registry, desired, func() error {
+		return errors.New("disk full")
+	})
+	require.Error(t, err)
+
+	// the previous plugins are restored
+	assert.Nil(t, registry.Get(desired[1].ComponentName()))
+	assert.NotNil(t, registry.Get(initial[1].ComponentName()))
+	restored, ok := registry.Get(initial[0].ComponentName()).(CustomPluginRegisteree)
+	require.True(t, ok)
+	assert.Equal(t, "echo old", restored.Spec().HealthStatePlugin.Steps[0].RunBashScript.Script)
+}
@@ -11,8 +11,10 @@ import (
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 // NewInitFunc creates a new component initializer for the given plugin spec.
@@ -32,6 +34,8 @@ func (spec *Spec) NewInitFunc() components.InitFunc {
 			cancel:            ccancel,
 			spec:              spec,
 			healthStateSetter: healthStateSetter,
+			chaos:             gpudInstance.Chaos,
+			deferrer:          gpudInstance.CheckDeferrer,
 		}
 		return c, nil
 	}
@@ -49,6 +53,12 @@ type component struct {
 	lastCheckResult *checkResult
 
 	healthStateSetter pkgmetrics.HealthStateSetter
+
+	// chaos times out the plugin runs at random, nil if disabled
+	chaos *pkgchaos.Injector
+
+	// deferrer defers the periodic plugin runs under the host pressure, nil if disabled
+	deferrer components.CheckDeferrer
 }
 
 var _ CustomPluginRegisteree = &component{}
@@ -109,6 +119,10 @@ func (c *component) Start() error {
 		defer ticker.Stop()
 
 		for {
+			// deferred under the host pressure, if the load shedding is enabled
+			if !c.deferrer.WaitWhileDeferred(c.ctx, c.Name()) {
+				return
+			}
 			_ = c.Check()
 
 			select {
@@ -145,7 +159,12 @@ func (c *component) Check() components.CheckResult {
 		return cr
 	}
 
-	cctx, ccancel := context.WithTimeout(c.ctx, c.spec.Timeout.Duration)
+	timeout := c.spec.Timeout.Duration
+	if c.chaos.Fail(pkgchaos.BoundaryPlugin) {
+		// already expired, as if the plugin run timed out
+		timeout = 0
+	}
+	cctx, ccancel := context.WithTimeout(c.ctx, timeout)
 	defer ccancel()
 
 	cr.out, cr.exitCode, cr.err = c.spec.HealthStatePlugin.executeAllSteps(cctx)
@@ -160,6 +179,7 @@ func (c *component) Check() components.CheckResult {
 
 			cr.health = apiv1.HealthStateTypeUnhealthy
 			cr.reason = "failed to parse plugin output"
+			cr.reasonCode = ReasonCodePluginOutputParseFailed
 			cr.err = exErr
 			return cr
 		}
@@ -180,6 +200,7 @@ func (c *component) Check() components.CheckResult {
 
 					cr.health = apiv1.HealthStateTypeUnhealthy
 					cr.reason = "unexpected plugin output"
+					cr.reasonCode = ReasonCodePluginOutputUnexpected
 				}
 
 				if len(data.suggestedActions) > 0 {
@@ -212,6 +233,7 @@ func (c *component) Check() components.CheckResult {
 	if cr.err != nil {
 		cr.health = apiv1.HealthStateTypeUnhealthy
 		cr.reason = fmt.Sprintf("error executing state plugin (exit code: %d)", cr.exitCode)
+		cr.reasonCode = ReasonCodePluginExecutionFailed
 		log.Logger.Warnw(cr.reason, "error", cr.err)
 		return cr
 	}
@@ -243,13 +265,14 @@ func (c *component) LastHealthStates() apiv1.HealthStates {
 				// thus no need to redundantly display two here as [component name]/[plugin name]
 				Name: "check",
 
-				RunMode: apiv1.RunModeType(c.spec.RunMode),
-				Health:  apiv1.HealthStateTypeHealthy,
-				Reason:  "no data yet",
+				RunMode:    apiv1.RunModeType(c.spec.RunMode),
+				Health:     apiv1.HealthStateTypeHealthy,
+				Reason:     "no data yet",
+				ReasonCode: apiv1.ReasonCodeHealthy,
 			},
 		}
 	}
-	return lastCheckResult.HealthStates()
+	return c.deferrer.TagDeferredHealthStates(c.Name(), lastCheckResult.HealthStates())
 }
 
 func (c *component) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
@@ -285,6 +308,8 @@ type checkResult struct {
 	health apiv1.HealthStateType
 	// tracks the reason of the last check
 	reason string
+	// tracks the reason code of the last check, empty for the fallback code
+	reasonCode apiv1.ReasonCode
 	// extra info extracted from the output
 	extraInfo map[string]string
 	// suggested actions extracted from the output
@@ -332,6 +357,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 				ComponentType: apiv1.ComponentTypeCustomPlugin,
 				Health:        apiv1.HealthStateTypeHealthy,
 				Reason:        "no data yet",
+				ReasonCode:    apiv1.ReasonCodeHealthy,
 			},
 		}
 	}
@@ -347,6 +373,7 @@ func (cr *checkResult) HealthStates() apiv1.HealthStates {
 		Name: "check",
 
 		Reason:           cr.reason,
+		ReasonCode:       reasoncode.ForHealthState(apiv1.HealthState{Health: cr.health, ReasonCode: cr.reasonCode}),
 		Error:            cr.getError(),
 		RunMode:          cr.runMode,
 		Health:           cr.health,
@@ -1,4 +1,27 @@
 package customplugins
 
+import (
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/reasoncode"
+)
+
 // DefaultPluginSpecsFile is the default file path for the plugin specs.
 const DefaultPluginSpecsFile = "/etc/default/gpud.plugins.yaml"
+
+const (
+	// ReasonCodePluginOutputParseFailed is for the plugin output failed to parse.
+	ReasonCodePluginOutputParseFailed apiv1.ReasonCode = "PLUGIN_OUTPUT_PARSE_FAILED"
+	// ReasonCodePluginOutputUnexpected is for the plugin output not matching the expected rules.
+	ReasonCodePluginOutputUnexpected apiv1.ReasonCode = "PLUGIN_OUTPUT_UNEXPECTED"
+	// ReasonCodePluginExecutionFailed is for the plugin command failed (e.g., non-zero exit code).
+	ReasonCodePluginExecutionFailed apiv1.ReasonCode = "PLUGIN_EXECUTION_FAILED"
+)
+
+func init() {
+	// shared by all the custom plugins, as the plugin component names are not known in advance
+	reasoncode.MustRegister("",
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePluginOutputParseFailed, Health: apiv1.HealthStateTypeUnhealthy, Description: "The custom plugin output failed to parse."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePluginOutputUnexpected, Health: apiv1.HealthStateTypeUnhealthy, Description: "The custom plugin output does not match the expected rules."},
+		apiv1.ReasonCodeMetadata{Code: ReasonCodePluginExecutionFailed, Health: apiv1.HealthStateTypeUnhealthy, Description: "The custom plugin command failed (e.g., non-zero exit code or timed out)."},
+	)
+}
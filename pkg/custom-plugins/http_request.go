@@ -0,0 +1,155 @@
+package customplugins
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHTTPRequestTimeout is the default timeout of the HTTP request step.
+	DefaultHTTPRequestTimeout = 10 * time.Second
+
+	// maxHTTPResponseBodyBytes is the maximum response body size to read and match.
+	maxHTTPResponseBodyBytes = 1024 * 1024
+)
+
+// HTTPRequestResult is the result of the HTTP request step,
+// written to the plugin output as a JSON line.
+type HTTPRequestResult struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+
+	StatusCode int   `json:"status_code,omitempty"`
+	DurationMs int64 `json:"duration_ms"`
+	BodyBytes  int   `json:"body_bytes"`
+}
+
+// Validate validates the HTTP request.
+func (r *HTTPRequest) Validate() error {
+	if r.URL == "" {
+		return ErrURLRequired
+	}
+	if r.ExpectedStatus != 0 && (r.ExpectedStatus < 100 || r.ExpectedStatus > 599) {
+		return fmt.Errorf("invalid expected status %d", r.ExpectedStatus)
+	}
+	if r.ExpectedBodyRegex != "" {
+		if _, err := regexp.Compile(r.ExpectedBodyRegex); err != nil {
+			return fmt.Errorf("invalid expected body regex %q: %w", r.ExpectedBodyRegex, err)
+		}
+	}
+	if r.Timeout.Duration < 0 {
+		return fmt.Errorf("invalid timeout %s", r.Timeout.Duration)
+	}
+	return nil
+}
+
+// execute sends the HTTP request and returns the result,
+// with the failure reason if the response does not match (empty if passed).
+// The environment variables (e.g., the secrets) are expanded in the URL, the headers, and the body.
+func (r *HTTPRequest) execute(ctx context.Context, stepName string, envs []string) (HTTPRequestResult, string) {
+	res := HTTPRequestResult{Step: stepName}
+	fail := func(format string, args ...any) (HTTPRequestResult, string) {
+		res.Reason = fmt.Sprintf(format, args...)
+		return res, res.Reason
+	}
+
+	timeout := r.Timeout.Duration
+	if timeout == 0 {
+		timeout = DefaultHTTPRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	expand := expandEnvs(envs)
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(expand(r.Body))
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), expand(r.URL), body)
+	if err != nil {
+		return fail("failed to create request: %v", err)
+	}
+	for k, v := range r.Headers {
+		req.Header.Set(k, expand(v))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if r.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+	cli := &http.Client{Transport: transport}
+	defer cli.CloseIdleConnections()
+
+	start := time.Now()
+	resp, err := cli.Do(req)
+	if err != nil {
+		res.DurationMs = time.Since(start).Milliseconds()
+		return fail("request failed: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBodyBytes))
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.StatusCode = resp.StatusCode
+	res.BodyBytes = len(b)
+	if err != nil {
+		return fail("failed to read response body: %v", err)
+	}
+
+	if r.ExpectedStatus != 0 {
+		if resp.StatusCode != r.ExpectedStatus {
+			return fail("unexpected status %d (expected %d)", resp.StatusCode, r.ExpectedStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fail("unexpected status %d (expected 2xx)", resp.StatusCode)
+	}
+
+	if r.ExpectedBodyRegex != "" {
+		// already validated
+		re := regexp.MustCompile(r.ExpectedBodyRegex)
+		if !re.Match(bytes.TrimSpace(b)) {
+			return fail("response body does not match %q", r.ExpectedBodyRegex)
+		}
+	}
+
+	res.Passed = true
+	return res, ""
+}
+
+// expandEnvs returns the function that expands "${NAME}" or "$NAME"
+// with the given environment variables only (e.g., the step secrets),
+// leaving the other references as is.
+func expandEnvs(envs []string) func(string) string {
+	values := make(map[string]string, len(envs))
+	for _, env := range envs {
+		k, v, _ := strings.Cut(env, "=")
+		values[k] = v
+	}
+	return func(s string) string {
+		if len(values) == 0 {
+			return s
+		}
+		return os.Expand(s, func(k string) string {
+			if v, ok := values[k]; ok {
+				return v
+			}
+			return "${" + k + "}"
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package customplugins
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHTTPRequestValidate(t *testing.T) {
+	assert.NoError(t, (&HTTPRequest{URL: "http://localhost"}).Validate())
+	assert.NoError(t, (&HTTPRequest{URL: "http://localhost", ExpectedStatus: 204, ExpectedBodyRegex: "ok"}).Validate())
+	assert.ErrorIs(t, (&HTTPRequest{}).Validate(), ErrURLRequired)
+	assert.Error(t, (&HTTPRequest{URL: "http://localhost", ExpectedStatus: 1000}).Validate())
+	assert.Error(t, (&HTTPRequest{URL: "http://localhost", ExpectedBodyRegex: "[invalid"}).Validate())
+	assert.Error(t, (&HTTPRequest{URL: "http://localhost", Timeout: metav1.Duration{Duration: -time.Second}}).Validate())
+}
+
+func TestHTTPRequestExecute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status": "ok"}`))
+		case "/echo":
+			b, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(r.Method + " " + string(b)))
+		case "/slow":
+			time.Sleep(time.Second)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	envs := []string{"API_TOKEN=secret-token"}
+
+	t.Run("passed", func(t *testing.T) {
+		req := &HTTPRequest{
+			URL:               srv.URL + "/health",
+			Headers:           map[string]string{"Authorization": "Bearer ${API_TOKEN}"},
+			ExpectedBodyRegex: `"status":\s*"ok"`,
+		}
+		res, failure := req.execute(context.Background(), "health", envs)
+		assert.Empty(t, failure)
+		assert.True(t, res.Passed)
+		assert.Equal(t, "health", res.Step)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, len(`{"status": "ok"}`), res.BodyBytes)
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		req := &HTTPRequest{URL: srv.URL + "/health"}
+		res, failure := req.execute(context.Background(), "health", nil)
+		assert.Equal(t, "unexpected status 401 (expected 2xx)", failure)
+		assert.False(t, res.Passed)
+		assert.Equal(t, failure, res.Reason)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("expected status and body", func(t *testing.T) {
+		req := &HTTPRequest{
+			Method:            "post",
+			URL:               srv.URL + "/echo",
+			Body:              "token=${API_TOKEN}",
+			ExpectedStatus:    http.StatusCreated,
+			ExpectedBodyRegex: "^POST token=secret-token$",
+		}
+		res, failure := req.execute(context.Background(), "echo", envs)
+		assert.Empty(t, failure)
+		assert.True(t, res.Passed)
+
+		req.ExpectedStatus = http.StatusOK
+		_, failure = req.execute(context.Background(), "echo", envs)
+		assert.Equal(t, "unexpected status 201 (expected 200)", failure)
+	})
+
+	t.Run("body mismatch", func(t *testing.T) {
+		req := &HTTPRequest{
+			URL:               srv.URL + "/health",
+			Headers:           map[string]string{"Authorization": "Bearer ${API_TOKEN}"},
+			ExpectedBodyRegex: "degraded",
+		}
+		_, failure := req.execute(context.Background(), "health", envs)
+		assert.Contains(t, failure, "response body does not match")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		req := &HTTPRequest{URL: srv.URL + "/slow", Timeout: metav1.Duration{Duration: 50 * time.Millisecond}}
+		res, failure := req.execute(context.Background(), "slow", nil)
+		assert.True(t, strings.HasPrefix(failure, "request failed"), failure)
+		assert.False(t, res.Passed)
+		assert.Zero(t, res.StatusCode)
+	})
+}
+
+func TestExpandEnvs(t *testing.T) {
+	expand := expandEnvs([]string{"A=1", "B=x=y"})
+	assert.Equal(t, "1 x=y ${C}", expand("${A} $B ${C}"))
+	assert.Equal(t, "${A}", expandEnvs(nil)("${A}"))
+}
+
+func TestExecuteAllStepsWithHTTPRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	plugin := &Plugin{
+		Steps: []Step{
+			{
+				Name:        "check-server",
+				HTTPRequest: &HTTPRequest{URL: srv.URL},
+			},
+			{
+				Name: "never-run",
+				RunBashScript: &RunBashScript{
+					ContentType: "plaintext",
+					Script:      "echo 'never run'",
+				},
+			},
+		},
+	}
+	output, exitCode, err := plugin.executeAllSteps(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step "check-server": unexpected status 503`)
+	assert.Equal(t, int32(1), exitCode)
+	assert.NotContains(t, string(output), "never run")
+
+	var res HTTPRequestResult
+	require.NoError(t, json.Unmarshal(output, &res))
+	assert.Equal(t, "check-server", res.Step)
+	assert.False(t, res.Passed)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
@@ -2,6 +2,7 @@ package customplugins
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/leptonai/gpud/pkg/process"
@@ -23,6 +24,7 @@ func (p *Plugin) executeAllSteps(ctx context.Context) ([]byte, int32, error) {
 	// run them in sequence, one by one
 	// this is to avoid running multiple commands in parallel
 	processRunner := process.NewExclusiveRunner()
+	stepSecrets := getSecrets()
 
 	var err error
 	output := make([]byte, 0)
@@ -31,8 +33,13 @@ func (p *Plugin) executeAllSteps(ctx context.Context) ([]byte, int32, error) {
 	for _, b := range p.Steps {
 		switch {
 		case b.RunBashScript != nil:
+			envs, serr := stepSecrets.envs(b.Secrets)
+			if serr != nil {
+				return output, 0, fmt.Errorf("step %q: %w", b.Name, serr)
+			}
+
 			var out []byte
-			out, exitCode, err = b.RunBashScript.executeBash(ctx, processRunner)
+			out, exitCode, err = b.RunBashScript.executeBash(ctx, processRunner, envs...)
 			if len(out) > 0 {
 				output = append(output, out...)
 			}
@@ -43,6 +50,33 @@ func (p *Plugin) executeAllSteps(ctx context.Context) ([]byte, int32, error) {
 				return output, exitCode, err
 			}
 
+		case b.HTTPRequest != nil, b.AssertFile != nil:
+			envs, serr := stepSecrets.envs(b.Secrets)
+			if serr != nil {
+				return output, 0, fmt.Errorf("step %q: %w", b.Name, serr)
+			}
+
+			// the failure is empty if the step passed
+			var res any
+			var failure string
+			if b.HTTPRequest != nil {
+				res, failure = b.HTTPRequest.execute(ctx, b.Name, envs)
+			} else {
+				res, failure = b.AssertFile.execute(b.Name)
+			}
+
+			// one JSON line per step, to be parsed with the JSON paths
+			out, merr := json.Marshal(res)
+			if merr != nil {
+				return output, 0, merr
+			}
+			output = append(output, stepSecrets.redact(append(out, '\n'))...)
+
+			if failure != "" {
+				return output, 1, fmt.Errorf("step %q: %s", b.Name, stepSecrets.redact([]byte(failure)))
+			}
+			exitCode = 0
+
 		default:
 			return nil, 0, fmt.Errorf("unsupported plugin step: %T", b)
 		}
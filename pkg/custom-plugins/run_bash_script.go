@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
 
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/process"
@@ -45,13 +46,21 @@ func (b *RunBashScript) decode() (string, error) {
 }
 
 // executeBash runs the specified bash script and returns the output and its exit code.
-func (b *RunBashScript) executeBash(ctx context.Context, processRunner process.Runner) ([]byte, int32, error) {
+// The environment variables (e.g., the secrets) are set in addition to the ones of GPUd,
+// and the secret values are redacted from the output.
+func (b *RunBashScript) executeBash(ctx context.Context, processRunner process.Runner, envs ...string) ([]byte, int32, error) {
 	decoded, err := b.decode()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	execOut, exitCode, err := processRunner.RunUntilCompletion(ctx, decoded)
+	var opts []process.OpOption
+	if len(envs) > 0 {
+		// the process only inherits the environment of GPUd if none is set
+		opts = append(opts, process.WithEnvs(mergeEnvs(os.Environ(), envs)...))
+	}
+	execOut, exitCode, err := processRunner.RunUntilCompletion(ctx, decoded, opts...)
+	execOut = getSecrets().redact(execOut)
 	if err != nil {
 		log.Logger.Errorw("failed to run bash script", "output", string(execOut), "exitCode", exitCode, "error", err)
 	} else {
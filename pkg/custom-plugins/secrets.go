@@ -0,0 +1,242 @@
+package customplugins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+)
+
+// DefaultPluginSecretsFile is the default file of the secrets for the plugin steps.
+const DefaultPluginSecretsFile = "/etc/default/gpud.plugin-secrets.yaml"
+
+const (
+	// redactedSecret replaces the secret values in the plugin output.
+	redactedSecret = "[REDACTED]"
+
+	// secretCommandTimeout is the timeout to run the command of a secret (e.g., a KMS decrypt).
+	secretCommandTimeout = 30 * time.Second
+)
+
+var (
+	ErrSecretNotFound     = errors.New("secret not found")
+	ErrInvalidSecretRef   = errors.New("invalid secret reference")
+	ErrSecretsFileNotSafe = errors.New("secrets file must be owned by root and not accessible by group or others")
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretsFile is the file of the named secrets for the plugin steps.
+// The file must be owned by root with no group or other permissions (e.g., 0600).
+type SecretsFile struct {
+	Secrets []SecretSource `json:"secrets"`
+}
+
+// SecretSource defines a named secret and where its value is read from.
+// Exactly one of the value, the environment variable, or the command must be set.
+type SecretSource struct {
+	// Name is the name to reference the secret in the plugin steps.
+	Name string `json:"name"`
+
+	// Value is the secret value in the file.
+	Value string `json:"value,omitempty"`
+	// FromEnv is the environment variable of GPUd to read the secret value from.
+	FromEnv string `json:"from_env,omitempty"`
+	// FromCommand is the bash command to print the secret value to the stdout
+	// (e.g., "aws kms decrypt ..."), run once when the secrets are loaded.
+	FromCommand string `json:"from_command,omitempty"`
+}
+
+// SecretRef references a secret to inject into the environment of a plugin step.
+type SecretRef struct {
+	// Env is the environment variable name of the secret in the step (e.g., "API_TOKEN").
+	Env string `json:"env"`
+	// Secret is the name of the secret in the secrets file.
+	Secret string `json:"secret"`
+}
+
+// Validate validates the secret reference.
+func (r SecretRef) Validate() error {
+	if !envNameRegex.MatchString(r.Env) {
+		return fmt.Errorf("%w: invalid env %q", ErrInvalidSecretRef, r.Env)
+	}
+	if r.Secret == "" {
+		return fmt.Errorf("%w: secret name is required for env %q", ErrInvalidSecretRef, r.Env)
+	}
+	return nil
+}
+
+// Secrets holds the resolved secret values for the plugin steps.
+// The values are never logged, nor returned in the plugin output.
+type Secrets struct {
+	values map[string]string
+	// redactOrder is the secret values in the descending order of the length,
+	// so that a value containing another is redacted as a whole
+	redactOrder []string
+}
+
+// LoadSecrets loads the secrets from the root-only file,
+// and resolves the values from the file, the environment variables, or the commands.
+func LoadSecrets(path string) (*Secrets, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pkgfile.CheckRootOnly(fi); err != nil {
+		return nil, fmt.Errorf("%w (%v)", ErrSecretsFileNotSafe, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f SecretsFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(f.Secrets))
+	for _, src := range f.Secrets {
+		if src.Name == "" {
+			return nil, errors.New("secret name is required")
+		}
+		if _, ok := values[src.Name]; ok {
+			return nil, fmt.Errorf("duplicate secret %q", src.Name)
+		}
+		v, err := src.resolve()
+		if err != nil {
+			// never include the value
+			return nil, fmt.Errorf("failed to resolve secret %q: %w", src.Name, err)
+		}
+		values[src.Name] = v
+	}
+	return newSecrets(values), nil
+}
+
+func newSecrets(values map[string]string) *Secrets {
+	s := &Secrets{values: values}
+	for _, v := range values {
+		s.redactOrder = append(s.redactOrder, v)
+	}
+	sort.Slice(s.redactOrder, func(i, j int) bool {
+		return len(s.redactOrder[i]) > len(s.redactOrder[j])
+	})
+	return s
+}
+
+func (src SecretSource) resolve() (string, error) {
+	set := 0
+	for _, v := range []string{src.Value, src.FromEnv, src.FromCommand} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", errors.New("exactly one of value, from_env, and from_command must be set")
+	}
+
+	var v string
+	switch {
+	case src.Value != "":
+		v = src.Value
+
+	case src.FromEnv != "":
+		v = os.Getenv(src.FromEnv)
+
+	case src.FromCommand != "":
+		ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+		defer cancel()
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "bash", "-c", src.FromCommand)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("command failed: %w (stderr %q)", err, strings.TrimSpace(stderr.String()))
+		}
+		v = strings.TrimRight(string(out), "\r\n")
+	}
+	if v == "" {
+		return "", errors.New("empty secret value")
+	}
+	return v, nil
+}
+
+// envs returns the environment variables of the referenced secrets, in the "KEY=VALUE" format.
+func (s *Secrets) envs(refs []SecretRef) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	envs := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		var v string
+		ok := false
+		if s != nil {
+			v, ok = s.values[ref.Secret]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrSecretNotFound, ref.Secret)
+		}
+		envs = append(envs, ref.Env+"="+v)
+	}
+	return envs, nil
+}
+
+// redact replaces all the secret values in the output.
+func (s *Secrets) redact(b []byte) []byte {
+	if s == nil || len(b) == 0 {
+		return b
+	}
+	for _, v := range s.redactOrder {
+		b = bytes.ReplaceAll(b, []byte(v), []byte(redactedSecret))
+	}
+	return b
+}
+
+// mergeEnvs returns the base environment variables overridden by the given ones.
+func mergeEnvs(base []string, envs []string) []string {
+	override := make(map[string]struct{}, len(envs))
+	for _, env := range envs {
+		k, _, _ := strings.Cut(env, "=")
+		override[k] = struct{}{}
+	}
+	merged := make([]string, 0, len(base)+len(envs))
+	for _, env := range base {
+		k, _, _ := strings.Cut(env, "=")
+		if _, ok := override[k]; ok {
+			continue
+		}
+		merged = append(merged, env)
+	}
+	return append(merged, envs...)
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   *Secrets
+)
+
+// SetSecrets sets the secrets to inject into the plugin steps by reference,
+// and to redact from the plugin output. Set to nil to unset.
+func SetSecrets(s *Secrets) {
+	secretsMu.Lock()
+	secrets = s
+	secretsMu.Unlock()
+}
+
+func getSecrets() *Secrets {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return secrets
+}
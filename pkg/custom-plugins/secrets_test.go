@@ -0,0 +1,127 @@
+package customplugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecretsFile(t *testing.T, content string, perm os.FileMode) string {
+	p := filepath.Join(t.TempDir(), "secrets.yaml")
+	require.NoError(t, os.WriteFile(p, []byte(content), perm))
+	require.NoError(t, os.Chmod(p, perm))
+	return p
+}
+
+func TestLoadSecrets(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root to own the secrets file")
+	}
+	t.Setenv("TEST_PLUGIN_SECRET", "from-env-value")
+
+	p := writeSecretsFile(t, `secrets:
+- name: api-token
+  value: file-value
+- name: env-token
+  from_env: TEST_PLUGIN_SECRET
+- name: kms-token
+  from_command: echo command-value
+`, 0o600)
+	s, err := LoadSecrets(p)
+	require.NoError(t, err)
+
+	envs, err := s.envs([]SecretRef{
+		{Env: "API_TOKEN", Secret: "api-token"},
+		{Env: "ENV_TOKEN", Secret: "env-token"},
+		{Env: "KMS_TOKEN", Secret: "kms-token"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"API_TOKEN=file-value", "ENV_TOKEN=from-env-value", "KMS_TOKEN=command-value"}, envs)
+
+	_, err = s.envs([]SecretRef{{Env: "X", Secret: "unknown"}})
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	// readable by others
+	p = writeSecretsFile(t, "secrets: []\n", 0o644)
+	_, err = LoadSecrets(p)
+	assert.ErrorIs(t, err, ErrSecretsFileNotSafe)
+
+	for _, content := range []string{
+		"secrets:\n- name: a\n  value: x\n- name: a\n  value: y\n",
+		"secrets:\n- name: a\n",
+		"secrets:\n- name: a\n  value: x\n  from_env: HOME\n",
+		"secrets:\n- name: a\n  from_env: TEST_PLUGIN_SECRET_NOT_SET\n",
+		"secrets:\n- name: a\n  from_command: exit 1\n",
+	} {
+		p = writeSecretsFile(t, content, 0o600)
+		_, err = LoadSecrets(p)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestSecretsRedact(t *testing.T) {
+	s := newSecrets(map[string]string{"a": "token", "b": "token-long"})
+	assert.Equal(t, "x=[REDACTED] y=[REDACTED]", string(s.redact([]byte("x=token-long y=token"))))
+
+	var nilSecrets *Secrets
+	assert.Equal(t, "token", string(nilSecrets.redact([]byte("token"))))
+	_, err := nilSecrets.envs([]SecretRef{{Env: "X", Secret: "a"}})
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestMergeEnvs(t *testing.T) {
+	assert.Equal(t, []string{"PATH=/bin", "TOKEN=new"}, mergeEnvs([]string{"PATH=/bin", "TOKEN=old"}, []string{"TOKEN=new"}))
+}
+
+func TestPluginStepSecrets(t *testing.T) {
+	SetSecrets(newSecrets(map[string]string{"api-token": "s3cr3t-value"}))
+	defer SetSecrets(nil)
+
+	plugin := Plugin{
+		Steps: []Step{
+			{
+				Name: "call-api",
+				RunBashScript: &RunBashScript{
+					ContentType: "plaintext",
+					Script:      `echo "token=${API_TOKEN}"; echo "path=${PATH}"`,
+				},
+				Secrets: []SecretRef{{Env: "API_TOKEN", Secret: "api-token"}},
+			},
+		},
+	}
+	require.NoError(t, plugin.Validate())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, exitCode, err := plugin.executeAllSteps(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), exitCode)
+	assert.Contains(t, string(out), "token=[REDACTED]")
+	assert.NotContains(t, string(out), "s3cr3t-value")
+	// still inherits the environment of GPUd
+	assert.NotContains(t, string(out), "path=\n")
+
+	plugin.Steps[0].Secrets = []SecretRef{{Env: "API_TOKEN", Secret: "unknown"}}
+	_, _, err = plugin.executeAllSteps(ctx)
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestStepValidateSecrets(t *testing.T) {
+	step := Step{
+		Name:          "step",
+		RunBashScript: &RunBashScript{ContentType: "plaintext", Script: "echo"},
+	}
+	for _, refs := range [][]SecretRef{
+		{{Env: "1INVALID", Secret: "a"}},
+		{{Env: "VALID", Secret: ""}},
+		{{Env: "VALID", Secret: "a"}, {Env: "VALID", Secret: "b"}},
+	} {
+		step.Secrets = refs
+		assert.ErrorIs(t, step.Validate(), ErrInvalidSecretRef)
+	}
+}
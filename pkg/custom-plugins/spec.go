@@ -124,8 +124,12 @@ var (
 	ErrMissingPluginStep        = errors.New("plugin step cannot be empty")
 	ErrMissingStatePlugin       = errors.New("state plugin is required")
 	ErrScriptRequired           = errors.New("script is required")
+	ErrMultiplePluginSteps      = errors.New("plugin step must have only one of run_bash_script, http_request, and assert_file")
+	ErrURLRequired              = errors.New("url is required")
+	ErrPathRequired             = errors.New("path is required")
 	ErrIntervalTooShort         = errors.New("interval is too short")
 	ErrComponentListNotExpanded = errors.New("component list must be expanded before validation")
+	ErrInvalidTrigger           = errors.New("invalid trigger")
 )
 
 const (
@@ -279,25 +283,48 @@ func (pluginSpecs Specs) ExpandComponentList() (Specs, error) {
 					Steps:  make([]Step, len(spec.HealthStatePlugin.Steps)),
 					Parser: spec.HealthStatePlugin.Parser,
 				},
-				Timeout:  spec.Timeout,
-				Interval: spec.Interval,
+				Timeout:         spec.Timeout,
+				Interval:        spec.Interval,
+				Triggers:        spec.Triggers,
+				TriggerCooldown: spec.TriggerCooldown,
 			}
 
 			// Copy and substitute each step
+			subst := func(s string) string {
+				s = strings.ReplaceAll(s, "${NAME}", name)
+				return strings.ReplaceAll(s, "${PAR}", param)
+			}
 			for i, step := range spec.HealthStatePlugin.Steps {
-				if step.RunBashScript != nil {
+				switch {
+				case step.RunBashScript != nil:
 					// Substitute parameters in the script
-					script := step.RunBashScript.Script
-					script = strings.ReplaceAll(script, "${NAME}", name)
-					script = strings.ReplaceAll(script, "${PAR}", param)
-
 					expandedPlugin.HealthStatePlugin.Steps[i] = Step{
 						Name: step.Name,
 						RunBashScript: &RunBashScript{
 							ContentType: step.RunBashScript.ContentType,
-							Script:      script,
+							Script:      subst(step.RunBashScript.Script),
 						},
 					}
+
+				case step.HTTPRequest != nil:
+					// Substitute parameters in the URL and the body
+					req := *step.HTTPRequest
+					req.URL = subst(req.URL)
+					req.Body = subst(req.Body)
+					expandedPlugin.HealthStatePlugin.Steps[i] = Step{
+						Name:        step.Name,
+						HTTPRequest: &req,
+						Secrets:     step.Secrets,
+					}
+
+				case step.AssertFile != nil:
+					// Substitute parameters in the path
+					assert := *step.AssertFile
+					assert.Path = subst(assert.Path)
+					expandedPlugin.HealthStatePlugin.Steps[i] = Step{
+						Name:       step.Name,
+						AssertFile: &assert,
+					}
 				}
 			}
 
@@ -344,6 +371,10 @@ func (spec *Spec) Validate() error {
 		return ErrComponentListNotExpanded
 	}
 
+	if err := spec.validateTriggers(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -4257,3 +4257,47 @@ func TestValidateComponentListTypeWithoutExpansion(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrInvalidPluginType)
 }
+
+func TestExpandedValidateWithHTTPRequestAndAssertFile(t *testing.T) {
+	specs := Specs{
+		{
+			PluginName:    "endpoint",
+			PluginType:    SpecTypeComponentList,
+			RunMode:       "auto",
+			Timeout:       metav1.Duration{Duration: 30 * time.Second},
+			Interval:      metav1.Duration{Duration: 5 * time.Minute},
+			ComponentList: []string{"inference:8000"},
+			HealthStatePlugin: &Plugin{
+				Steps: []Step{
+					{
+						Name:        "check-endpoint",
+						HTTPRequest: &HTTPRequest{URL: "http://localhost:${PAR}/health", ExpectedStatus: 200},
+						Secrets:     []SecretRef{{Env: "API_TOKEN", Secret: "api-token"}},
+					},
+					{
+						Name:       "check-heartbeat",
+						AssertFile: &AssertFile{Path: "/var/run/${NAME}/heartbeat"},
+					},
+				},
+			},
+		},
+	}
+
+	expanded, err := specs.ExpandedValidate()
+	assert.NoError(t, err)
+	if !assert.Len(t, expanded, 1) {
+		return
+	}
+
+	steps := expanded[0].HealthStatePlugin.Steps
+	if !assert.Len(t, steps, 2) {
+		return
+	}
+	assert.Equal(t, "http://localhost:8000/health", steps[0].HTTPRequest.URL)
+	assert.Equal(t, 200, steps[0].HTTPRequest.ExpectedStatus)
+	assert.Equal(t, []SecretRef{{Env: "API_TOKEN", Secret: "api-token"}}, steps[0].Secrets)
+	assert.Equal(t, "/var/run/inference/heartbeat", steps[1].AssertFile.Path)
+
+	// the parent spec is not modified
+	assert.Equal(t, "http://localhost:${PAR}/health", specs[0].HealthStatePlugin.Steps[0].HTTPRequest.URL)
+}
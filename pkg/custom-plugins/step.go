@@ -1,15 +1,44 @@
 package customplugins
 
+import "fmt"
+
 // Validate validates the plugin step.
 func (st *Step) Validate() error {
 	if st.Name == "" {
 		return ErrStepNameRequired
 	}
 
+	envs := make(map[string]struct{}, len(st.Secrets))
+	for _, ref := range st.Secrets {
+		if err := ref.Validate(); err != nil {
+			return err
+		}
+		if _, ok := envs[ref.Env]; ok {
+			return fmt.Errorf("%w: duplicate env %q", ErrInvalidSecretRef, ref.Env)
+		}
+		envs[ref.Env] = struct{}{}
+	}
+
+	set := 0
+	for _, ok := range []bool{st.RunBashScript != nil, st.HTTPRequest != nil, st.AssertFile != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return ErrMultiplePluginSteps
+	}
+
 	switch {
 	case st.RunBashScript != nil:
 		return st.RunBashScript.Validate()
 
+	case st.HTTPRequest != nil:
+		return st.HTTPRequest.Validate()
+
+	case st.AssertFile != nil:
+		return st.AssertFile.Validate()
+
 	default:
 		return ErrMissingPluginStep
 	}
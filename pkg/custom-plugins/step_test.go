@@ -40,6 +40,34 @@ func TestPluginStepValidation(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "valid http request step",
+			step: Step{
+				Name:        "http-step",
+				HTTPRequest: &HTTPRequest{URL: "http://localhost:8000/health"},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid assert file step",
+			step: Step{
+				Name:       "file-step",
+				AssertFile: &AssertFile{Path: "/tmp/heartbeat"},
+			},
+			expectError: false,
+		},
+		{
+			name: "multiple step types",
+			step: Step{
+				Name: "multiple-steps",
+				RunBashScript: &RunBashScript{
+					ContentType: "plaintext",
+					Script:      "echo 'Multiple steps'",
+				},
+				AssertFile: &AssertFile{Path: "/tmp/heartbeat"},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,338 @@
+package customplugins
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultTriggerCooldown is the default minimum interval between two triggered runs of a plugin.
+	DefaultTriggerCooldown = 10 * time.Minute
+
+	// DefaultTriggerPollInterval is the default interval to poll the watched components.
+	DefaultTriggerPollInterval = 30 * time.Second
+)
+
+var (
+	validTriggerEventTypes = []apiv1.EventType{
+		apiv1.EventTypeUnknown,
+		apiv1.EventTypeInfo,
+		apiv1.EventTypeWarning,
+		apiv1.EventTypeCritical,
+		apiv1.EventTypeFatal,
+	}
+	validTriggerHealthStateTypes = []apiv1.HealthStateType{
+		apiv1.HealthStateTypeHealthy,
+		apiv1.HealthStateTypeUnhealthy,
+		apiv1.HealthStateTypeDegraded,
+	}
+)
+
+func (spec *Spec) validateTriggers() error {
+	if len(spec.Triggers) == 0 {
+		return nil
+	}
+	if spec.PluginType != SpecTypeComponent {
+		return fmt.Errorf("%w: triggers are only supported for %q type plugins", ErrInvalidTrigger, SpecTypeComponent)
+	}
+	if spec.TriggerCooldown.Duration < 0 {
+		return fmt.Errorf("%w: trigger cooldown must be non-negative", ErrInvalidTrigger)
+	}
+
+	for i, t := range spec.Triggers {
+		if (t.OnEvent == nil) == (t.OnHealth == nil) {
+			return fmt.Errorf("%w: trigger %d must set exactly one of on_event or on_health", ErrInvalidTrigger, i)
+		}
+
+		component := ""
+		if t.OnEvent != nil {
+			component = t.OnEvent.Component
+			for _, typ := range t.OnEvent.Types {
+				if !slices.Contains(validTriggerEventTypes, apiv1.EventType(typ)) {
+					return fmt.Errorf("%w: trigger %d has unknown event type %q", ErrInvalidTrigger, i, typ)
+				}
+			}
+		} else {
+			component = t.OnHealth.Component
+			for _, h := range t.OnHealth.Health {
+				if !slices.Contains(validTriggerHealthStateTypes, apiv1.HealthStateType(h)) {
+					return fmt.Errorf("%w: trigger %d has unknown health state type %q", ErrInvalidTrigger, i, h)
+				}
+			}
+		}
+
+		if component == "" {
+			return fmt.Errorf("%w: trigger %d requires a component", ErrInvalidTrigger, i)
+		}
+		if component == spec.ComponentName() {
+			// the plugin would trigger itself
+			return fmt.Errorf("%w: trigger %d cannot watch the plugin itself", ErrInvalidTrigger, i)
+		}
+	}
+	return nil
+}
+
+func (spec *Spec) triggerCooldown() time.Duration {
+	if spec.TriggerCooldown.Duration > 0 {
+		return spec.TriggerCooldown.Duration
+	}
+	return DefaultTriggerCooldown
+}
+
+var xidMessageRegex = regexp.MustCompile(`^XID (\d+)`)
+
+// matches returns true if the event matches the trigger.
+func (t *EventTrigger) matches(ev apiv1.Event) bool {
+	if t.Name != "" && t.Name != ev.Name {
+		return false
+	}
+	if len(t.Types) > 0 && !slices.Contains(t.Types, string(ev.Type)) {
+		return false
+	}
+	if len(t.Xids) > 0 {
+		m := xidMessageRegex.FindStringSubmatch(ev.Message)
+		if len(m) != 2 {
+			return false
+		}
+		xid, err := strconv.Atoi(m[1])
+		if err != nil || !slices.Contains(t.Xids, xid) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if the health state type matches the trigger.
+func (t *HealthTrigger) matches(health apiv1.HealthStateType) bool {
+	if len(t.Health) == 0 {
+		return health == apiv1.HealthStateTypeUnhealthy
+	}
+	return slices.Contains(t.Health, string(health))
+}
+
+// TriggerWatcher watches the components referenced by the plugin triggers,
+// and runs the plugins whose trigger conditions match.
+//
+// Loop protection:
+//   - a plugin cannot watch itself
+//   - a plugin is not triggered again while its triggered run is in progress
+//   - a plugin is not triggered again within its trigger cooldown
+//   - events and health states from before the watcher started are ignored
+type TriggerWatcher struct {
+	registry     components.Registry
+	pollInterval time.Duration
+	runFunc      func(c components.Component)
+
+	mu sync.Mutex
+	// startTime is when the watcher started, to ignore the past events
+	startTime time.Time
+	// lastEventTime is the latest seen event time per watched component
+	lastEventTime map[string]time.Time
+	// lastHealth is the last observed health state type per watched component
+	lastHealth map[string]apiv1.HealthStateType
+	// lastTriggered is the last triggered run time per plugin
+	lastTriggered map[string]time.Time
+	// running is the set of plugins whose triggered run is in progress
+	running map[string]struct{}
+}
+
+// NewTriggerWatcher creates a trigger watcher on the plugins in the registry.
+// The registry is read on every poll, so the plugins registered
+// after the start are also watched.
+func NewTriggerWatcher(registry components.Registry) *TriggerWatcher {
+	return &TriggerWatcher{
+		registry:      registry,
+		pollInterval:  DefaultTriggerPollInterval,
+		runFunc:       func(c components.Component) { _ = c.Check() },
+		lastEventTime: make(map[string]time.Time),
+		lastHealth:    make(map[string]apiv1.HealthStateType),
+		lastTriggered: make(map[string]time.Time),
+		running:       make(map[string]struct{}),
+	}
+}
+
+// Start starts polling the watched components until the context is canceled.
+func (w *TriggerWatcher) Start(ctx context.Context) {
+	w.mu.Lock()
+	w.startTime = time.Now().UTC()
+	w.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.poll(ctx)
+			}
+		}
+	}()
+}
+
+type triggeredPlugin struct {
+	comp components.Component
+	spec Spec
+}
+
+// poll evaluates the triggers of all the plugins once.
+func (w *TriggerWatcher) poll(ctx context.Context) {
+	var plugins []triggeredPlugin
+	eventWatched := make(map[string]struct{})
+	healthWatched := make(map[string]struct{})
+	for _, c := range w.registry.All() {
+		registeree, ok := c.(CustomPluginRegisteree)
+		if !ok || !registeree.IsCustomPlugin() {
+			continue
+		}
+		spec := registeree.Spec()
+		if len(spec.Triggers) == 0 {
+			continue
+		}
+		plugins = append(plugins, triggeredPlugin{comp: c, spec: spec})
+
+		for _, t := range spec.Triggers {
+			if t.OnEvent != nil {
+				eventWatched[t.OnEvent.Component] = struct{}{}
+			}
+			if t.OnHealth != nil {
+				healthWatched[t.OnHealth.Component] = struct{}{}
+			}
+		}
+	}
+	if len(plugins) == 0 {
+		return
+	}
+
+	newEvents := make(map[string]apiv1.Events)
+	for name := range eventWatched {
+		newEvents[name] = w.readNewEvents(ctx, name)
+	}
+
+	// components with a health state transition in this poll
+	transitioned := make(map[string]apiv1.HealthStateType)
+	for name := range healthWatched {
+		if health, ok := w.observeHealth(name); ok {
+			transitioned[name] = health
+		}
+	}
+
+	for _, p := range plugins {
+		for _, t := range p.spec.Triggers {
+			if t.OnEvent != nil {
+				for _, ev := range newEvents[t.OnEvent.Component] {
+					if t.OnEvent.matches(ev) {
+						w.trigger(p, fmt.Sprintf("event %q (%s) from %s", ev.Name, ev.Type, t.OnEvent.Component))
+						break
+					}
+				}
+			}
+			if t.OnHealth != nil {
+				if health, ok := transitioned[t.OnHealth.Component]; ok && t.OnHealth.matches(health) {
+					w.trigger(p, fmt.Sprintf("%s became %s", t.OnHealth.Component, health))
+				}
+			}
+		}
+	}
+}
+
+// readNewEvents returns the events of the component
+// that have not been seen since the last poll.
+func (w *TriggerWatcher) readNewEvents(ctx context.Context, name string) apiv1.Events {
+	c := w.registry.Get(name)
+	if c == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	since, ok := w.lastEventTime[name]
+	if !ok {
+		since = w.startTime
+	}
+	w.mu.Unlock()
+
+	evs, err := c.Events(ctx, since)
+	if err != nil {
+		log.Logger.Warnw("failed to read events for plugin triggers", "component", name, "error", err)
+		return nil
+	}
+
+	latest := since
+	var ret apiv1.Events
+	for _, ev := range evs {
+		if !ev.Time.After(since) {
+			continue
+		}
+		ret = append(ret, ev)
+		if ev.Time.After(latest) {
+			latest = ev.Time.Time
+		}
+	}
+
+	w.mu.Lock()
+	w.lastEventTime[name] = latest
+	w.mu.Unlock()
+
+	return ret
+}
+
+// observeHealth returns the current health state type of the component
+// and true if it changed since the last poll.
+// The first observation is not a transition.
+func (w *TriggerWatcher) observeHealth(name string) (apiv1.HealthStateType, bool) {
+	c := w.registry.Get(name)
+	if c == nil {
+		return "", false
+	}
+	health := components.WorstHealthStateType(c.LastHealthStates())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev, ok := w.lastHealth[name]
+	w.lastHealth[name] = health
+	return health, ok && prev != health
+}
+
+// trigger runs the plugin in the background, unless it is
+// already running or still within its cooldown.
+func (w *TriggerWatcher) trigger(p triggeredPlugin, reason string) {
+	name := p.comp.Name()
+	now := time.Now().UTC()
+
+	w.mu.Lock()
+	if _, ok := w.running[name]; ok {
+		w.mu.Unlock()
+		log.Logger.Debugw("plugin triggered run still in progress, skipping", "plugin", name, "reason", reason)
+		return
+	}
+	if last, ok := w.lastTriggered[name]; ok && now.Sub(last) < p.spec.triggerCooldown() {
+		w.mu.Unlock()
+		log.Logger.Debugw("plugin trigger in cooldown, skipping", "plugin", name, "reason", reason, "lastTriggered", last)
+		return
+	}
+	w.running[name] = struct{}{}
+	w.lastTriggered[name] = now
+	w.mu.Unlock()
+
+	log.Logger.Infow("triggering plugin", "plugin", name, "reason", reason)
+	go func() {
+		defer func() {
+			w.mu.Lock()
+			delete(w.running, name)
+			w.mu.Unlock()
+		}()
+		w.runFunc(p.comp)
+	}()
+}
@@ -0,0 +1,315 @@
+package customplugins
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+func TestSpecValidateTriggers(t *testing.T) {
+	newSpec := func(triggers ...Trigger) *Spec {
+		return &Spec{
+			PluginName: "nvidia-bug-report",
+			PluginType: SpecTypeComponent,
+			RunMode:    string(apiv1.RunModeTypeManual),
+			HealthStatePlugin: &Plugin{
+				Steps: []Step{{Name: "collect", RunBashScript: &RunBashScript{ContentType: "plaintext", Script: "echo ok"}}},
+			},
+			Triggers: triggers,
+		}
+	}
+
+	require.NoError(t, newSpec().Validate())
+	require.NoError(t, newSpec(
+		Trigger{OnEvent: &EventTrigger{Component: "accelerator-nvidia-error-xid", Types: []string{"Fatal"}, Xids: []int{79, 48}}},
+		Trigger{OnHealth: &HealthTrigger{Component: "accelerator-nvidia-ecc", Health: []string{"Unhealthy", "Degraded"}}},
+	).Validate())
+
+	tests := []struct {
+		name string
+		spec *Spec
+	}{
+		{"no condition", newSpec(Trigger{})},
+		{"both conditions", newSpec(Trigger{
+			OnEvent:  &EventTrigger{Component: "a"},
+			OnHealth: &HealthTrigger{Component: "b"},
+		})},
+		{"missing component", newSpec(Trigger{OnEvent: &EventTrigger{}})},
+		{"self trigger", newSpec(Trigger{OnHealth: &HealthTrigger{Component: ConvertToComponentName("nvidia-bug-report")}})},
+		{"unknown event type", newSpec(Trigger{OnEvent: &EventTrigger{Component: "a", Types: []string{"Bad"}}})},
+		{"unknown health", newSpec(Trigger{OnHealth: &HealthTrigger{Component: "a", Health: []string{"Initializing"}}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrInvalidTrigger)
+		})
+	}
+
+	initSpec := newSpec(Trigger{OnEvent: &EventTrigger{Component: "a"}})
+	initSpec.PluginType = SpecTypeInit
+	assert.ErrorIs(t, initSpec.Validate(), ErrInvalidTrigger)
+
+	negative := newSpec(Trigger{OnEvent: &EventTrigger{Component: "a"}})
+	negative.TriggerCooldown = metav1.Duration{Duration: -time.Second}
+	assert.ErrorIs(t, negative.Validate(), ErrInvalidTrigger)
+}
+
+func TestEventTriggerMatches(t *testing.T) {
+	trigger := &EventTrigger{Component: "accelerator-nvidia-error-xid", Name: "error_xid", Types: []string{"Fatal"}, Xids: []int{79, 48}}
+
+	assert.True(t, trigger.matches(apiv1.Event{Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 79(GPU_HAS_FALLEN_OFF_THE_BUS) detected on GPU-abc"}))
+	assert.True(t, trigger.matches(apiv1.Event{Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 48 detected on GPU-abc"}))
+	assert.False(t, trigger.matches(apiv1.Event{Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 13 detected on GPU-abc"}))
+	assert.False(t, trigger.matches(apiv1.Event{Name: "error_xid", Type: apiv1.EventTypeWarning, Message: "XID 79 detected on GPU-abc"}))
+	assert.False(t, trigger.matches(apiv1.Event{Name: "reboot", Type: apiv1.EventTypeFatal, Message: "XID 79 detected on GPU-abc"}))
+	assert.False(t, trigger.matches(apiv1.Event{Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "unknown"}))
+
+	anyEvent := &EventTrigger{Component: "os"}
+	assert.True(t, anyEvent.matches(apiv1.Event{Name: "reboot"}))
+}
+
+func TestHealthTriggerMatches(t *testing.T) {
+	assert.True(t, (&HealthTrigger{}).matches(apiv1.HealthStateTypeUnhealthy))
+	assert.False(t, (&HealthTrigger{}).matches(apiv1.HealthStateTypeDegraded))
+	assert.True(t, (&HealthTrigger{Health: []string{"Degraded"}}).matches(apiv1.HealthStateTypeDegraded))
+}
+
+type triggerTestComponent struct {
+	name string
+
+	mu     sync.Mutex
+	events apiv1.Events
+	health apiv1.HealthStateType
+	spec   *Spec
+}
+
+func (c *triggerTestComponent) Name() string                  { return c.name }
+func (c *triggerTestComponent) Tags() []string                { return nil }
+func (c *triggerTestComponent) IsSupported() bool             { return true }
+func (c *triggerTestComponent) Start() error                  { return nil }
+func (c *triggerTestComponent) Check() components.CheckResult { return nil }
+func (c *triggerTestComponent) Close() error                  { return nil }
+
+func (c *triggerTestComponent) Events(_ context.Context, since time.Time) (apiv1.Events, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.name == "broken" {
+		return nil, errors.New("events error")
+	}
+	var ret apiv1.Events
+	for _, ev := range c.events {
+		if !ev.Time.Time.Before(since) {
+			ret = append(ret, ev)
+		}
+	}
+	return ret, nil
+}
+
+func (c *triggerTestComponent) LastHealthStates() apiv1.HealthStates {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.health == "" {
+		return nil
+	}
+	return apiv1.HealthStates{{Health: c.health}}
+}
+
+func (c *triggerTestComponent) IsCustomPlugin() bool { return c.spec != nil }
+
+func (c *triggerTestComponent) Spec() Spec {
+	if c.spec == nil {
+		return Spec{}
+	}
+	return *c.spec
+}
+
+func (c *triggerTestComponent) addEvent(ev apiv1.Event) {
+	c.mu.Lock()
+	c.events = append(c.events, ev)
+	c.mu.Unlock()
+}
+
+func (c *triggerTestComponent) setHealth(h apiv1.HealthStateType) {
+	c.mu.Lock()
+	c.health = h
+	c.mu.Unlock()
+}
+
+type triggerTestRuns struct {
+	mu   sync.Mutex
+	runs []string
+	wg   sync.WaitGroup
+}
+
+func (r *triggerTestRuns) run(c components.Component) {
+	defer r.wg.Done()
+	r.mu.Lock()
+	r.runs = append(r.runs, c.Name())
+	r.mu.Unlock()
+}
+
+func (r *triggerTestRuns) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.runs...)
+}
+
+func newTestTriggerWatcher(t *testing.T, comps ...components.Component) (*TriggerWatcher, *triggerTestRuns) {
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: context.Background()})
+	for _, c := range comps {
+		c := c
+		_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return c, nil })
+		require.NoError(t, err)
+	}
+
+	runs := &triggerTestRuns{}
+	w := NewTriggerWatcher(registry)
+	w.runFunc = runs.run
+	w.startTime = time.Now().UTC().Add(-time.Minute)
+	return w, runs
+}
+
+func TestTriggerWatcherOnEvent(t *testing.T) {
+	xid := &triggerTestComponent{name: "accelerator-nvidia-error-xid"}
+	// events before the watcher started are ignored
+	xid.addEvent(apiv1.Event{Time: metav1.NewTime(time.Now().Add(-time.Hour)), Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 79 detected"})
+
+	plugin := &triggerTestComponent{
+		name: "nvidia-bug-report",
+		spec: &Spec{
+			PluginName: "nvidia-bug-report",
+			PluginType: SpecTypeComponent,
+			Triggers:   []Trigger{{OnEvent: &EventTrigger{Component: xid.name, Xids: []int{79, 48}}}},
+		},
+	}
+	w, runs := newTestTriggerWatcher(t, xid, plugin, &triggerTestComponent{name: "cpu"})
+
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+
+	// non-matching xid
+	xid.addEvent(apiv1.Event{Time: metav1.NewTime(time.Now()), Name: "error_xid", Type: apiv1.EventTypeWarning, Message: "XID 13 detected"})
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+
+	runs.wg.Add(1)
+	xid.addEvent(apiv1.Event{Time: metav1.NewTime(time.Now().Add(time.Second)), Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 79 detected"})
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Equal(t, []string{"nvidia-bug-report"}, runs.get())
+
+	// same event is not seen twice
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Len(t, runs.get(), 1)
+
+	// a new matching event within the cooldown does not trigger
+	xid.addEvent(apiv1.Event{Time: metav1.NewTime(time.Now().Add(2 * time.Second)), Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 48 detected"})
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Len(t, runs.get(), 1)
+
+	// after the cooldown
+	w.mu.Lock()
+	w.lastTriggered[plugin.name] = time.Now().Add(-2 * DefaultTriggerCooldown)
+	w.mu.Unlock()
+	runs.wg.Add(1)
+	xid.addEvent(apiv1.Event{Time: metav1.NewTime(time.Now().Add(3 * time.Second)), Name: "error_xid", Type: apiv1.EventTypeFatal, Message: "XID 48 detected"})
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Len(t, runs.get(), 2)
+}
+
+func TestTriggerWatcherOnHealth(t *testing.T) {
+	ecc := &triggerTestComponent{name: "accelerator-nvidia-ecc", health: apiv1.HealthStateTypeUnhealthy}
+	plugin := &triggerTestComponent{
+		name: "ecc-diag",
+		spec: &Spec{
+			PluginName:      "ecc-diag",
+			PluginType:      SpecTypeComponent,
+			TriggerCooldown: metav1.Duration{Duration: time.Nanosecond},
+			Triggers:        []Trigger{{OnHealth: &HealthTrigger{Component: ecc.name}}},
+		},
+	}
+	w, runs := newTestTriggerWatcher(t, ecc, plugin)
+
+	// the first observation is not a transition
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+
+	// still unhealthy, no transition
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+
+	ecc.setHealth(apiv1.HealthStateTypeHealthy)
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+
+	runs.wg.Add(1)
+	ecc.setHealth(apiv1.HealthStateTypeUnhealthy)
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Equal(t, []string{"ecc-diag"}, runs.get())
+}
+
+func TestTriggerWatcherSkipsRunning(t *testing.T) {
+	plugin := &triggerTestComponent{
+		name: "slow",
+		spec: &Spec{PluginName: "slow", PluginType: SpecTypeComponent, TriggerCooldown: metav1.Duration{Duration: time.Nanosecond}},
+	}
+	w, _ := newTestTriggerWatcher(t, plugin)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	runs := 0
+	w.runFunc = func(components.Component) {
+		runs++
+		<-release
+		close(done)
+	}
+
+	p := triggeredPlugin{comp: plugin, spec: *plugin.spec}
+	w.trigger(p, "first")
+	time.Sleep(10 * time.Millisecond)
+	w.trigger(p, "second")
+
+	close(release)
+	<-done
+	assert.Equal(t, 1, runs)
+}
+
+func TestTriggerWatcherEventsError(t *testing.T) {
+	plugin := &triggerTestComponent{
+		name: "plugin",
+		spec: &Spec{
+			PluginName: "plugin",
+			PluginType: SpecTypeComponent,
+			Triggers: []Trigger{
+				{OnEvent: &EventTrigger{Component: "broken"}},
+				{OnEvent: &EventTrigger{Component: "not-registered"}},
+				{OnHealth: &HealthTrigger{Component: "not-registered"}},
+			},
+		},
+	}
+	w, runs := newTestTriggerWatcher(t, plugin, &triggerTestComponent{name: "broken"})
+
+	w.poll(context.Background())
+	runs.wg.Wait()
+	assert.Empty(t, runs.get())
+}
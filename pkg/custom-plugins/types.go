@@ -89,6 +89,54 @@ type Spec struct {
 	// this value is ignored.
 	// Similarly, if set to zero, it runs only once.
 	Interval metav1.Duration `json:"interval"`
+
+	// Triggers defines the conditions to run the plugin automatically
+	// in reaction to the observed events or health state changes,
+	// in addition to the run mode and the interval.
+	// Only applicable to "component" type plugins.
+	// e.g., run a "manual" mode bug report collection plugin on fatal Xids.
+	Triggers []Trigger `json:"triggers,omitempty"`
+
+	// TriggerCooldown is the minimum interval between two triggered runs,
+	// to prevent the plugins from triggering each other in a loop.
+	// If zero, it uses the default cooldown (10-minute).
+	TriggerCooldown metav1.Duration `json:"trigger_cooldown,omitempty"`
+}
+
+// Trigger defines a condition to run the plugin.
+// Exactly one of the conditions must be set.
+type Trigger struct {
+	// OnEvent runs the plugin when the component emits a matching event.
+	OnEvent *EventTrigger `json:"on_event,omitempty"`
+	// OnHealth runs the plugin when the component transitions into a matching health state.
+	OnHealth *HealthTrigger `json:"on_health,omitempty"`
+}
+
+// EventTrigger matches the events of a component.
+// All the non-empty fields must match.
+type EventTrigger struct {
+	// Component is the name of the component to watch the events of
+	// (e.g., "accelerator-nvidia-error-xid").
+	Component string `json:"component"`
+	// Name is the event name to match (e.g., "error_xid").
+	// If empty, it matches all events of the component.
+	Name string `json:"name,omitempty"`
+	// Types is the list of event types to match (e.g., "Fatal", "Critical").
+	// If empty, it matches all event types.
+	Types []string `json:"types,omitempty"`
+	// Xids is the list of Xid codes to match (e.g., [79, 48]),
+	// read from the "XID <code>" event message.
+	// If empty, it matches all Xids.
+	Xids []int `json:"xids,omitempty"`
+}
+
+// HealthTrigger matches the health state transitions of a component.
+type HealthTrigger struct {
+	// Component is the name of the component to watch the health states of.
+	Component string `json:"component"`
+	// Health is the list of health state types to match (e.g., "Unhealthy", "Degraded").
+	// If empty, it matches "Unhealthy".
+	Health []string `json:"health,omitempty"`
 }
 
 // Plugin represents a plugin spec.
@@ -112,6 +160,18 @@ type Step struct {
 	// RunBashScript is the bash script to run for this step.
 	RunBashScript *RunBashScript `json:"run_bash_script,omitempty"`
 
+	// HTTPRequest is the HTTP request to send for this step.
+	HTTPRequest *HTTPRequest `json:"http_request,omitempty"`
+
+	// AssertFile is the file assertion to check for this step.
+	AssertFile *AssertFile `json:"assert_file,omitempty"`
+
+	// Secrets is a list of the secrets to inject into the step environment,
+	// referenced by name from the plugin secrets file.
+	// The secret values are never stored in the spec,
+	// and redacted from the plugin output.
+	Secrets []SecretRef `json:"secrets,omitempty"`
+
 	// TODO
 	// we may support other ways to run plugins in the future
 	// e.g., container image
@@ -128,6 +188,52 @@ type RunBashScript struct {
 	Script string `json:"script"`
 }
 
+// HTTPRequest represents an HTTP request step, which fails
+// if the response does not match the expected status or body.
+// The secrets of the step are expanded in the URL, the headers, and the body
+// (e.g., "Bearer ${API_TOKEN}").
+type HTTPRequest struct {
+	// Method is the HTTP method (defaults to "GET").
+	Method string `json:"method,omitempty"`
+	// URL is the URL to send the request to.
+	URL string `json:"url"`
+	// Headers are the request headers.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the request body.
+	Body string `json:"body,omitempty"`
+
+	// ExpectedStatus is the expected response status code.
+	// If zero, any 2xx status code is expected.
+	ExpectedStatus int `json:"expected_status,omitempty"`
+	// ExpectedBodyRegex is the regex the response body must match, if not empty.
+	ExpectedBodyRegex string `json:"expected_body_regex,omitempty"`
+
+	// Timeout is the timeout of the request (defaults to 10 seconds),
+	// bounded by the plugin timeout.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// InsecureSkipVerify skips the TLS certificate verification
+	// (e.g., the self-signed certificate of the local GPUd server).
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// AssertFile represents a file assertion step, which fails
+// if the file does not match all the set assertions.
+type AssertFile struct {
+	// Path is the path of the file to check.
+	Path string `json:"path"`
+
+	// Exists is whether the file is expected to exist (defaults to true).
+	// If false, no other assertion is allowed.
+	Exists *bool `json:"exists,omitempty"`
+	// MaxAge is the maximum age of the file since its last modification, if not zero
+	// (e.g., "10m" for a heartbeat file updated by a job).
+	MaxAge metav1.Duration `json:"max_age,omitempty"`
+	// ContentRegex is the regex the file content must match, if not empty.
+	ContentRegex string `json:"content_regex,omitempty"`
+	// SHA256 is the expected hex-encoded SHA-256 checksum of the file, if not empty.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
 // PluginOutputParseConfig configures the parser for the plugin output.
 type PluginOutputParseConfig struct {
 	// JSONPaths is a list of JSON paths to the output fields.
@@ -0,0 +1,115 @@
+// Package disposition records the operator dispositions on the component events
+// (e.g., an Xid marked benign), and derives the severity adjustments from the
+// accumulated dispositions, so that the known-noisy events on a hardware and
+// driver combination stop alerting, with the adjustments exported for review.
+package disposition
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// ErrInvalidRecord is returned when the disposition record is invalid.
+var ErrInvalidRecord = errors.New("invalid disposition record")
+
+// Disposition is the operator verdict on an event.
+type Disposition string
+
+const (
+	// DispositionTruePositive means the event reflected a real failure.
+	// It resets the accumulated benign and false-positive dispositions.
+	DispositionTruePositive Disposition = "true-positive"
+	// DispositionFalsePositive means the event did not reflect any failure.
+	DispositionFalsePositive Disposition = "false-positive"
+	// DispositionBenign means the event reflected a real condition
+	// that does not need any action (e.g., an application fault).
+	DispositionBenign Disposition = "benign"
+)
+
+// Record is an operator disposition on the events of a kind.
+type Record struct {
+	// ID is the unique identifier of the record, generated if empty.
+	ID string `json:"id"`
+	// Time is when the disposition was recorded, defaults to now.
+	Time time.Time `json:"time"`
+
+	// Component is the name of the component of the event (e.g., "accelerator-nvidia-xid").
+	Component string `json:"component"`
+	// Key identifies the kind of the event within the component
+	// (e.g., the Xid code "79" for the Xid component).
+	Key string `json:"key"`
+
+	Disposition Disposition `json:"disposition"`
+
+	// Operator is the optional name of the operator who recorded the disposition.
+	Operator string `json:"operator,omitempty"`
+	// Note is the optional description (e.g., the ticket link).
+	Note string `json:"note,omitempty"`
+
+	// Product is the GPU product name where the event was observed, set by GPUd.
+	Product string `json:"product,omitempty"`
+	// DriverVersion is the GPU driver version where the event was observed, set by GPUd.
+	DriverVersion string `json:"driver_version,omitempty"`
+}
+
+// Validate returns an error if the record is invalid.
+func (r Record) Validate() error {
+	if r.ID == "" {
+		return errors.New("id is required")
+	}
+	if r.Time.IsZero() {
+		return errors.New("time is required")
+	}
+	if r.Component == "" {
+		return errors.New("component is required")
+	}
+	if r.Key == "" {
+		return errors.New("key is required")
+	}
+	switch r.Disposition {
+	case DispositionTruePositive, DispositionFalsePositive, DispositionBenign:
+	default:
+		return fmt.Errorf("disposition must be one of %q, %q, %q, got %q", DispositionTruePositive, DispositionFalsePositive, DispositionBenign, r.Disposition)
+	}
+	return nil
+}
+
+// Adjustment is the severity adjustment of the events of a kind
+// on a hardware and driver combination, derived from the dispositions.
+type Adjustment struct {
+	Component string `json:"component"`
+	Key       string `json:"key"`
+
+	Product       string `json:"product,omitempty"`
+	DriverVersion string `json:"driver_version,omitempty"`
+
+	// EventType is the event type the critical and fatal events are downgraded to.
+	EventType apiv1.EventType `json:"event_type"`
+
+	// Benign is the number of the benign dispositions since the last true positive.
+	Benign int `json:"benign"`
+	// FalsePositive is the number of the false-positive dispositions since the last true positive.
+	FalsePositive int `json:"false_positive"`
+	// TruePositive is the total number of the true-positive dispositions.
+	TruePositive int `json:"true_positive"`
+
+	// Since is the time of the first benign or false-positive disposition
+	// since the last true positive.
+	Since time.Time `json:"since"`
+	// LastUpdated is the time of the last disposition.
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Downgrade returns the adjusted event type, and false if not downgraded
+// (only the critical and fatal events are downgraded).
+func (a Adjustment) Downgrade(eventType apiv1.EventType) (apiv1.EventType, bool) {
+	switch eventType {
+	case apiv1.EventTypeCritical, apiv1.EventTypeFatal:
+		return a.EventType, true
+	default:
+		return eventType, false
+	}
+}
@@ -0,0 +1,206 @@
+package disposition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultThreshold is the default number of the benign or false-positive dispositions
+// (since the last true positive) to downgrade the events of a kind.
+const DefaultThreshold = 3
+
+// adjustmentKey identifies the events of a kind on a hardware and driver combination,
+// so that the adjustments are re-evaluated after the driver upgrades.
+type adjustmentKey struct {
+	component     string
+	key           string
+	product       string
+	driverVersion string
+}
+
+// Manager manages the dispositions persisted in the database,
+// and the severity adjustments derived from them.
+// Safe for concurrent use.
+type Manager struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	// threshold is the number of the dispositions to downgrade, or <= 0 to disable
+	threshold      int
+	getTimeNowFunc func() time.Time
+
+	mu      sync.RWMutex
+	records []Record
+	// tallies are the accumulated dispositions of every kind, including the ones under the threshold
+	tallies map[adjustmentKey]*Adjustment
+}
+
+// NewManager creates the disposition manager, with the number of the benign
+// or false-positive dispositions to downgrade the events of a kind.
+// A zero threshold uses the default, and a negative threshold disables the downgrades
+// (the dispositions are still recorded).
+func NewManager(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, threshold int) (*Manager, error) {
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create dispositions table: %w", err)
+	}
+	records, err := Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dispositions: %w", err)
+	}
+
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	m := &Manager{
+		dbRW:      dbRW,
+		dbRO:      dbRO,
+		threshold: threshold,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		tallies: make(map[adjustmentKey]*Adjustment),
+	}
+	for _, r := range records {
+		m.addLocked(r)
+	}
+	return m, nil
+}
+
+// Record stores the disposition, with the ID and the time set if empty.
+// It returns an error wrapping ErrInvalidRecord if the record is invalid.
+func (m *Manager) Record(ctx context.Context, r Record) (Record, error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	if r.Time.IsZero() {
+		r.Time = m.getTimeNowFunc()
+	}
+	r.Time = r.Time.UTC().Truncate(time.Second)
+	if err := r.Validate(); err != nil {
+		return Record{}, fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := Insert(ctx, m.dbRW, r); err != nil {
+		return Record{}, err
+	}
+	m.addLocked(r)
+
+	log.Logger.Infow("recorded event disposition", "component", r.Component, "key", r.Key, "disposition", r.Disposition, "operator", r.Operator)
+	return r, nil
+}
+
+// addLocked accumulates the disposition, in the time order (then by the ID, same as the database).
+func (m *Manager) addLocked(r Record) {
+	i := sort.Search(len(m.records), func(i int) bool {
+		if m.records[i].Time.Equal(r.Time) {
+			return m.records[i].ID > r.ID
+		}
+		return m.records[i].Time.After(r.Time)
+	})
+	m.records = append(m.records, Record{})
+	copy(m.records[i+1:], m.records[i:])
+	m.records[i] = r
+
+	// out-of-order records (e.g., backfilled) need the tally re-accumulated in the time order
+	k := adjustmentKey{component: r.Component, key: r.Key, product: r.Product, driverVersion: r.DriverVersion}
+	tally := &Adjustment{
+		Component:     r.Component,
+		Key:           r.Key,
+		Product:       r.Product,
+		DriverVersion: r.DriverVersion,
+		EventType:     apiv1.EventTypeWarning,
+	}
+	for _, rec := range m.records {
+		if rec.Component != k.component || rec.Key != k.key || rec.Product != k.product || rec.DriverVersion != k.driverVersion {
+			continue
+		}
+		switch rec.Disposition {
+		case DispositionTruePositive:
+			tally.TruePositive++
+			tally.Benign = 0
+			tally.FalsePositive = 0
+			tally.Since = time.Time{}
+		case DispositionBenign:
+			tally.Benign++
+		case DispositionFalsePositive:
+			tally.FalsePositive++
+		}
+		if rec.Disposition != DispositionTruePositive && tally.Since.IsZero() {
+			tally.Since = rec.Time
+		}
+		tally.LastUpdated = rec.Time
+	}
+	m.tallies[k] = tally
+}
+
+// List returns the dispositions of the component (all components if empty),
+// sorted by the time.
+func (m *Manager) List(component string) []Record {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]Record, 0, len(m.records))
+	for _, r := range m.records {
+		if component == "" || r.Component == component {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Adjustments returns the severity adjustments in effect (the kinds with the benign
+// and false-positive dispositions reaching the threshold), sorted by the component and the key.
+func (m *Manager) Adjustments() []Adjustment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	adjustments := make([]Adjustment, 0)
+	for _, tally := range m.tallies {
+		if m.adjusted(tally) {
+			adjustments = append(adjustments, *tally)
+		}
+	}
+	sort.Slice(adjustments, func(i, j int) bool {
+		a, b := adjustments[i], adjustments[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Product != b.Product {
+			return a.Product < b.Product
+		}
+		return a.DriverVersion < b.DriverVersion
+	})
+	return adjustments
+}
+
+// Adjustment returns the severity adjustment in effect for the events of the kind
+// on the hardware and driver combination, and false if not adjusted.
+func (m *Manager) Adjustment(component string, key string, product string, driverVersion string) (Adjustment, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tally, ok := m.tallies[adjustmentKey{component: component, key: key, product: product, driverVersion: driverVersion}]
+	if !ok || !m.adjusted(tally) {
+		return Adjustment{}, false
+	}
+	return *tally, true
+}
+
+func (m *Manager) adjusted(tally *Adjustment) bool {
+	return m.threshold > 0 && tally.Benign+tally.FalsePositive >= m.threshold
+}
@@ -0,0 +1,116 @@
+package disposition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestManager(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m, err := NewManager(ctx, dbRW, dbRO, 2)
+	require.NoError(t, err)
+	m.getTimeNowFunc = func() time.Time { return now }
+
+	record := func(key string, d Disposition, at time.Time) {
+		r, err := m.Record(ctx, Record{Time: at, Component: "accelerator-nvidia-xid", Key: key, Disposition: d, Product: "H100", DriverVersion: "550.54.15"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, r.ID)
+	}
+
+	_, err = m.Record(ctx, Record{Component: "accelerator-nvidia-xid", Key: "13", Disposition: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+
+	record("13", DispositionBenign, now.Add(-3*time.Hour))
+	_, ok := m.Adjustment("accelerator-nvidia-xid", "13", "H100", "550.54.15")
+	assert.False(t, ok)
+
+	record("13", DispositionFalsePositive, now.Add(-2*time.Hour))
+	adj, ok := m.Adjustment("accelerator-nvidia-xid", "13", "H100", "550.54.15")
+	require.True(t, ok)
+	assert.Equal(t, apiv1.EventTypeWarning, adj.EventType)
+	assert.Equal(t, 1, adj.Benign)
+	assert.Equal(t, 1, adj.FalsePositive)
+	assert.Equal(t, now.Add(-3*time.Hour), adj.Since)
+
+	// other hardware and driver combinations are not adjusted
+	_, ok = m.Adjustment("accelerator-nvidia-xid", "13", "H100", "570.86.10")
+	assert.False(t, ok)
+
+	// the true positive resets the tally
+	record("79", DispositionBenign, now.Add(-3*time.Hour))
+	record("79", DispositionBenign, now.Add(-2*time.Hour))
+	record("79", DispositionTruePositive, now.Add(-time.Hour))
+	_, ok = m.Adjustment("accelerator-nvidia-xid", "79", "H100", "550.54.15")
+	assert.False(t, ok)
+
+	// the backfilled records are accumulated in the time order
+	record("79", DispositionBenign, now.Add(-4*time.Hour))
+	_, ok = m.Adjustment("accelerator-nvidia-xid", "79", "H100", "550.54.15")
+	assert.False(t, ok)
+
+	adjustments := m.Adjustments()
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, "13", adjustments[0].Key)
+
+	list := m.List("accelerator-nvidia-xid")
+	require.Len(t, list, 6)
+	assert.Equal(t, now.Add(-4*time.Hour), list[0].Time)
+	assert.Empty(t, m.List("cpu"))
+
+	// persisted across the restarts
+	m2, err := NewManager(ctx, dbRW, dbRO, 2)
+	require.NoError(t, err)
+	assert.Equal(t, list, m2.List(""))
+	assert.Equal(t, adjustments, m2.Adjustments())
+
+	// the downgrades are disabled by the negative threshold
+	m3, err := NewManager(ctx, dbRW, dbRO, -1)
+	require.NoError(t, err)
+	assert.Empty(t, m3.Adjustments())
+	assert.Len(t, m3.List(""), 6)
+}
+
+func TestRecordValidate(t *testing.T) {
+	valid := Record{ID: "1", Time: time.Now(), Component: "accelerator-nvidia-xid", Key: "79", Disposition: DispositionBenign}
+	assert.NoError(t, valid.Validate())
+
+	for _, mutate := range []func(r *Record){
+		func(r *Record) { r.ID = "" },
+		func(r *Record) { r.Time = time.Time{} },
+		func(r *Record) { r.Component = "" },
+		func(r *Record) { r.Key = "" },
+		func(r *Record) { r.Disposition = "ignored" },
+	} {
+		r := valid
+		mutate(&r)
+		assert.Error(t, r.Validate())
+	}
+}
+
+func TestAdjustmentDowngrade(t *testing.T) {
+	adj := Adjustment{EventType: apiv1.EventTypeWarning}
+
+	typ, ok := adj.Downgrade(apiv1.EventTypeFatal)
+	assert.True(t, ok)
+	assert.Equal(t, apiv1.EventTypeWarning, typ)
+
+	typ, ok = adj.Downgrade(apiv1.EventTypeCritical)
+	assert.True(t, ok)
+	assert.Equal(t, apiv1.EventTypeWarning, typ)
+
+	typ, ok = adj.Downgrade(apiv1.EventTypeInfo)
+	assert.False(t, ok)
+	assert.Equal(t, apiv1.EventTypeInfo, typ)
+}
@@ -0,0 +1,86 @@
+package disposition
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameDispositions = "gpud_event_dispositions"
+
+	columnID            = "id"
+	columnTime          = "time"
+	columnComponent     = "component"
+	columnKey           = "key"
+	columnDisposition   = "disposition"
+	columnOperator      = "operator"
+	columnNote          = "note"
+	columnProduct       = "product"
+	columnDriverVersion = "driver_version"
+)
+
+// CreateTable creates the table for the dispositions.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s INTEGER NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT,
+	%s TEXT,
+	%s TEXT,
+	%s TEXT
+);`, tableNameDispositions, columnID, columnTime, columnComponent, columnKey, columnDisposition, columnOperator, columnNote, columnProduct, columnDriverVersion))
+	return err
+}
+
+// Insert inserts the disposition record.
+func Insert(ctx context.Context, dbRW *sql.DB, r Record) error {
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		tableNameDispositions, columnID, columnTime, columnComponent, columnKey, columnDisposition, columnOperator, columnNote, columnProduct, columnDriverVersion),
+		r.ID, r.Time.Unix(), r.Component, r.Key, string(r.Disposition), r.Operator, r.Note, r.Product, r.DriverVersion)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Read returns all the stored records, sorted by the time.
+func Read(ctx context.Context, dbRO *sql.DB) ([]Record, error) {
+	start := time.Now()
+	rows, err := dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s, %s, %s, %s, %s FROM %s
+ORDER BY %s ASC, %s ASC`, columnID, columnTime, columnComponent, columnKey, columnDisposition, columnOperator, columnNote, columnProduct, columnDriverVersion, tableNameDispositions, columnTime, columnID))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var records []Record
+	for rows.Next() {
+		var r Record
+		var unix int64
+		var disposition string
+		var operator, note, product, driverVersion sql.NullString
+		if err := rows.Scan(&r.ID, &unix, &r.Component, &r.Key, &disposition, &operator, &note, &product, &driverVersion); err != nil {
+			return nil, err
+		}
+		r.Time = time.Unix(unix, 0).UTC()
+		r.Disposition = Disposition(disposition)
+		r.Operator = operator.String
+		r.Note = note.String
+		r.Product = product.String
+		r.DriverVersion = driverVersion.String
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
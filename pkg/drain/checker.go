@@ -0,0 +1,212 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/processes"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+const (
+	// CheckGPUProcesses checks no process is running on the GPUs.
+	CheckGPUProcesses = "gpu-processes"
+	// CheckRepairActions checks no GPU reset or reboot is in progress.
+	CheckRepairActions = "repair-actions"
+	// CheckNVSwitchPartitions checks no shared NVSwitch partition is activated.
+	CheckNVSwitchPartitions = "nvswitch-partitions"
+
+	// checkPluginPrefix prefixes the names of the plugin checks.
+	checkPluginPrefix = "plugin/"
+)
+
+// Checker checks whether the node can be safely maintained.
+// Safe for concurrent use.
+type Checker struct {
+	gpudInstance *components.GPUdInstance
+	registry     components.Registry
+	plugins      []string
+
+	getTimeNowFunc     func() time.Time
+	getProcessesFunc   func(uuid string, dev device.Device) (processes.Processes, error)
+	listPartitionsFunc func(ctx context.Context) ([]partition, error)
+}
+
+// New creates the drain readiness checker.
+// The NVML instance and the GPU reset tracker are read from the GPUd instance
+// on every check, as the NVML instance is set once initialized in the background.
+// The extra checks of the plugins are not run if the config is nil.
+func New(gpudInstance *components.GPUdInstance, registry components.Registry, cfg *Config) *Checker {
+	c := &Checker{
+		gpudInstance: gpudInstance,
+		registry:     registry,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		getProcessesFunc:   processes.GetProcesses,
+		listPartitionsFunc: listPartitions,
+	}
+	if cfg != nil {
+		c.plugins = cfg.Plugins
+	}
+	return c
+}
+
+// Check runs all the checks, and returns the node as ready only if all the checks are ready.
+func (c *Checker) Check(ctx context.Context) apiv1.DrainReadiness {
+	checks := []apiv1.DrainCheck{
+		c.checkGPUProcesses(),
+		c.checkRepairActions(ctx),
+		c.checkNVSwitchPartitions(ctx),
+	}
+	for _, name := range c.plugins {
+		checks = append(checks, c.checkPlugin(name))
+	}
+
+	ready := true
+	for _, ch := range checks {
+		ready = ready && ch.Ready
+	}
+	return apiv1.DrainReadiness{
+		Time:   c.getTimeNowFunc(),
+		Ready:  ready,
+		Checks: checks,
+	}
+}
+
+// checkGPUProcesses is ready if no process is running on any GPU.
+// The defunct processes are ignored, as they no longer run the workloads.
+func (c *Checker) checkGPUProcesses() apiv1.DrainCheck {
+	ch := apiv1.DrainCheck{Name: CheckGPUProcesses}
+
+	if c.gpudInstance == nil || c.gpudInstance.NVMLInstance == nil || !c.gpudInstance.NVMLInstance.NVMLExists() {
+		ch.Ready = true
+		ch.Reason = "NVIDIA NVML is not loaded"
+		return ch
+	}
+
+	devs := c.gpudInstance.NVMLInstance.Devices()
+	uuids := make([]string, 0, len(devs))
+	for uuid := range devs {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	var pids []string
+	for _, uuid := range uuids {
+		procs, err := c.getProcessesFunc(uuid, devs[uuid])
+		if err != nil {
+			// the node is not safe to maintain if the processes cannot be verified
+			ch.Reason = fmt.Sprintf("failed to get the processes of GPU %s: %v", uuid, err)
+			return ch
+		}
+		for _, p := range procs.RunningProcesses {
+			if p.ZombieStatus {
+				continue
+			}
+			pids = append(pids, strconv.FormatUint(uint64(p.PID), 10))
+		}
+	}
+
+	if len(pids) > 0 {
+		ch.Reason = fmt.Sprintf("%d GPU process(es) running (pids %s)", len(pids), strings.Join(pids, ", "))
+		return ch
+	}
+	ch.Ready = true
+	ch.Reason = fmt.Sprintf("no GPU process running on %d GPU(s)", len(uuids))
+	return ch
+}
+
+// checkRepairActions is ready if no GPU reset or reboot is in progress
+// (e.g., the reboot requested by the control plane, or the GPUs still settling after the driver reload).
+func (c *Checker) checkRepairActions(ctx context.Context) apiv1.DrainCheck {
+	ch := apiv1.DrainCheck{Name: CheckRepairActions}
+
+	if c.gpudInstance == nil || c.gpudInstance.GPUResets == nil {
+		ch.Ready = true
+		ch.Reason = "gpu reset tracking not set up"
+		return ch
+	}
+
+	now := c.getTimeNowFunc()
+	windows, err := c.gpudInstance.GPUResets.Windows(ctx, now)
+	if err != nil {
+		ch.Reason = fmt.Sprintf("failed to get the gpu reset windows: %v", err)
+		return ch
+	}
+	for _, w := range windows {
+		if w.Contains(now) {
+			ch.Reason = fmt.Sprintf("gpu reset in progress (%s) until %s", w.Reason, w.End.Format(time.RFC3339))
+			return ch
+		}
+	}
+	ch.Ready = true
+	ch.Reason = "no gpu reset in progress"
+	return ch
+}
+
+// checkNVSwitchPartitions is ready if no shared NVSwitch partition is activated.
+func (c *Checker) checkNVSwitchPartitions(ctx context.Context) apiv1.DrainCheck {
+	ch := apiv1.DrainCheck{Name: CheckNVSwitchPartitions}
+
+	partitions, err := c.listPartitionsFunc(ctx)
+	if errors.Is(err, errPartitionManagerNotFound) {
+		ch.Ready = true
+		ch.Reason = "shared nvswitch mode not in use (fmpm not found)"
+		return ch
+	}
+	if err != nil {
+		ch.Reason = fmt.Sprintf("failed to list the nvswitch partitions: %v", err)
+		return ch
+	}
+
+	var active []string
+	for _, p := range partitions {
+		if p.IsActive != 0 {
+			active = append(active, strconv.Itoa(p.ID))
+		}
+	}
+	if len(active) > 0 {
+		ch.Reason = fmt.Sprintf("%d nvswitch partition(s) activated (ids %s)", len(active), strings.Join(active, ", "))
+		return ch
+	}
+	ch.Ready = true
+	ch.Reason = fmt.Sprintf("no nvswitch partition activated out of %d", len(partitions))
+	return ch
+}
+
+// checkPlugin is ready if the last health states of the plugin are all healthy.
+func (c *Checker) checkPlugin(name string) apiv1.DrainCheck {
+	ch := apiv1.DrainCheck{Name: checkPluginPrefix + name}
+
+	var comp components.Component
+	if c.registry != nil {
+		comp = c.registry.Get(name)
+	}
+	if comp == nil {
+		ch.Reason = "plugin not found"
+		return ch
+	}
+
+	states := comp.LastHealthStates()
+	if len(states) == 0 {
+		ch.Reason = "plugin not checked yet"
+		return ch
+	}
+	for _, st := range states {
+		if st.Health != apiv1.HealthStateTypeHealthy {
+			ch.Reason = fmt.Sprintf("plugin %s: %s", st.Health, st.Reason)
+			return ch
+		}
+	}
+	ch.Ready = true
+	ch.Reason = states[0].Reason
+	return ch
+}
@@ -0,0 +1,198 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/processes"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type fakeNVMLInstance struct {
+	nvidianvml.Instance
+	devs map[string]device.Device
+}
+
+func (f *fakeNVMLInstance) NVMLExists() bool                  { return true }
+func (f *fakeNVMLInstance) Devices() map[string]device.Device { return f.devs }
+
+type fakeComponent struct {
+	components.Component
+	states apiv1.HealthStates
+}
+
+func (f *fakeComponent) LastHealthStates() apiv1.HealthStates { return f.states }
+
+type fakeRegistry struct {
+	components.Registry
+	comps map[string]components.Component
+}
+
+func (f *fakeRegistry) Get(name string) components.Component {
+	if c, ok := f.comps[name]; ok {
+		return c
+	}
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	require.NoError(t, cfg.Validate())
+	require.NoError(t, (&Config{Plugins: []string{"a", "b"}}).Validate())
+	require.Error(t, (&Config{Plugins: []string{""}}).Validate())
+	require.Error(t, (&Config{Plugins: []string{"a", "a"}}).Validate())
+}
+
+func newTestChecker(gpudInstance *components.GPUdInstance, registry components.Registry, cfg *Config) *Checker {
+	c := New(gpudInstance, registry, cfg)
+	c.getProcessesFunc = func(uuid string, _ device.Device) (processes.Processes, error) {
+		return processes.Processes{UUID: uuid}, nil
+	}
+	c.listPartitionsFunc = func(context.Context) ([]partition, error) {
+		return nil, errPartitionManagerNotFound
+	}
+	return c
+}
+
+func findCheck(t *testing.T, r apiv1.DrainReadiness, name string) apiv1.DrainCheck {
+	for _, ch := range r.Checks {
+		if ch.Name == name {
+			return ch
+		}
+	}
+	t.Fatalf("check %q not found", name)
+	return apiv1.DrainCheck{}
+}
+
+func TestCheckNoGPU(t *testing.T) {
+	c := newTestChecker(&components.GPUdInstance{}, nil, nil)
+	r := c.Check(context.Background())
+	assert.True(t, r.Ready)
+	require.Len(t, r.Checks, 3)
+	assert.Equal(t, "NVIDIA NVML is not loaded", findCheck(t, r, CheckGPUProcesses).Reason)
+}
+
+func TestCheckGPUProcesses(t *testing.T) {
+	gpudInstance := &components.GPUdInstance{
+		NVMLInstance: &fakeNVMLInstance{devs: map[string]device.Device{"GPU-0": nil, "GPU-1": nil}},
+	}
+	c := newTestChecker(gpudInstance, nil, nil)
+
+	ch := findCheck(t, c.Check(context.Background()), CheckGPUProcesses)
+	assert.True(t, ch.Ready)
+	assert.Equal(t, "no GPU process running on 2 GPU(s)", ch.Reason)
+
+	c.getProcessesFunc = func(uuid string, _ device.Device) (processes.Processes, error) {
+		if uuid == "GPU-1" {
+			return processes.Processes{UUID: uuid, RunningProcesses: []processes.Process{
+				{PID: 100},
+				{PID: 200, ZombieStatus: true},
+			}}, nil
+		}
+		return processes.Processes{UUID: uuid}, nil
+	}
+	r := c.Check(context.Background())
+	assert.False(t, r.Ready)
+	ch = findCheck(t, r, CheckGPUProcesses)
+	assert.False(t, ch.Ready)
+	assert.Equal(t, "1 GPU process(es) running (pids 100)", ch.Reason)
+
+	// not ready if the processes cannot be verified
+	c.getProcessesFunc = func(string, device.Device) (processes.Processes, error) {
+		return processes.Processes{}, errors.New("gpu lost")
+	}
+	ch = findCheck(t, c.Check(context.Background()), CheckGPUProcesses)
+	assert.False(t, ch.Ready)
+	assert.Contains(t, ch.Reason, "gpu lost")
+}
+
+func TestCheckRepairActions(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(gpureset.BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	tracker := gpureset.New(bucket, nil)
+	c := newTestChecker(&components.GPUdInstance{GPUResets: tracker}, nil, nil)
+
+	ctx := context.Background()
+	ch := findCheck(t, c.Check(ctx), CheckRepairActions)
+	assert.True(t, ch.Ready)
+
+	require.NoError(t, tracker.Begin(ctx, gpureset.SourceGPUd, "reboot requested by control plane"))
+	ch = findCheck(t, c.Check(ctx), CheckRepairActions)
+	assert.False(t, ch.Ready)
+	assert.Contains(t, ch.Reason, "reboot requested by control plane")
+
+	// ready once the window ends
+	c.getTimeNowFunc = func() time.Time { return time.Now().UTC().Add(gpureset.DefaultWindowPeriod + time.Minute) }
+	ch = findCheck(t, c.Check(ctx), CheckRepairActions)
+	assert.True(t, ch.Ready)
+}
+
+func TestCheckNVSwitchPartitions(t *testing.T) {
+	c := newTestChecker(nil, nil, nil)
+	ch := findCheck(t, c.Check(context.Background()), CheckNVSwitchPartitions)
+	assert.True(t, ch.Ready)
+
+	c.listPartitionsFunc = func(context.Context) ([]partition, error) {
+		return []partition{{ID: 0, NumGPUs: 8}, {ID: 3, IsActive: 1, NumGPUs: 2}}, nil
+	}
+	ch = findCheck(t, c.Check(context.Background()), CheckNVSwitchPartitions)
+	assert.False(t, ch.Ready)
+	assert.Equal(t, "1 nvswitch partition(s) activated (ids 3)", ch.Reason)
+
+	c.listPartitionsFunc = func(context.Context) ([]partition, error) {
+		return nil, errors.New("failed to connect to fabric manager")
+	}
+	ch = findCheck(t, c.Check(context.Background()), CheckNVSwitchPartitions)
+	assert.False(t, ch.Ready)
+}
+
+func TestParsePartitions(t *testing.T) {
+	partitions, err := parsePartitions([]byte(`{
+  "partitionInfo": [
+    {"partitionId": 0, "isActive": 0, "numGpus": 8, "gpuInfo": []},
+    {"partitionId": 1, "isActive": 1, "numGpus": 4, "gpuInfo": []}
+  ],
+  "numPartitions": 2
+}`))
+	require.NoError(t, err)
+	require.Len(t, partitions, 2)
+	assert.Equal(t, partition{ID: 1, IsActive: 1, NumGPUs: 4}, partitions[1])
+
+	_, err = parsePartitions([]byte("Error: failed to connect"))
+	require.Error(t, err)
+}
+
+func TestCheckPlugins(t *testing.T) {
+	registry := &fakeRegistry{comps: map[string]components.Component{
+		"no-jobs":   &fakeComponent{states: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy, Reason: "no job scheduled"}}},
+		"scheduler": &fakeComponent{states: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "2 jobs running"}}},
+		"pending":   &fakeComponent{},
+	}}
+	c := newTestChecker(nil, registry, &Config{Plugins: []string{"no-jobs", "scheduler", "pending", "missing"}})
+
+	r := c.Check(context.Background())
+	assert.False(t, r.Ready)
+	require.Len(t, r.Checks, 7)
+	assert.Equal(t, apiv1.DrainCheck{Name: "plugin/no-jobs", Ready: true, Reason: "no job scheduled"}, findCheck(t, r, "plugin/no-jobs"))
+	assert.Equal(t, "plugin Unhealthy: 2 jobs running", findCheck(t, r, "plugin/scheduler").Reason)
+	assert.Equal(t, "plugin not checked yet", findCheck(t, r, "plugin/pending").Reason)
+	assert.Equal(t, "plugin not found", findCheck(t, r, "plugin/missing").Reason)
+}
@@ -0,0 +1,36 @@
+// Package drain reports whether the node can be safely maintained,
+// with no GPU processes running, no GPU reset or reboot in progress,
+// no NVSwitch partitions activated, and the extra checks of the plugins passing,
+// so that the orchestration does not guess from the nvidia-smi output alone.
+package drain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Config configures the extra checks of the drain readiness.
+type Config struct {
+	// Plugins are the names of the custom plugins (or any other components)
+	// that must be healthy for the node to be ready for the maintenance
+	// (e.g., a plugin that checks the job scheduler has no job on the node).
+	Plugins []string `json:"plugins,omitempty"`
+}
+
+// Validate returns an error if any of the plugin names is empty or duplicate.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(cfg.Plugins))
+	for _, name := range cfg.Plugins {
+		if name == "" {
+			return errors.New("plugin name is required")
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate plugin %q", name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
+	"github.com/leptonai/gpud/pkg/file"
+)
+
+// errPartitionManagerNotFound is returned if the fabric manager partition manager
+// is not installed, in which case the shared NVSwitch mode is not in use.
+var errPartitionManagerNotFound = errors.New("fmpm not found")
+
+// partition is a shared NVSwitch partition of the fabric manager.
+type partition struct {
+	ID int `json:"partitionId"`
+	// IsActive is 1 if the partition is activated for a workload.
+	IsActive int `json:"isActive"`
+	NumGPUs  int `json:"numGpus"`
+}
+
+// partitionList is the output of the "fmpm -l" command.
+type partitionList struct {
+	PartitionInfo []partition `json:"partitionInfo"`
+}
+
+// listPartitions lists the shared NVSwitch partitions of the fabric manager.
+func listPartitions(ctx context.Context) ([]partition, error) {
+	execPath, err := file.LocateExecutable("fmpm")
+	if execPath == "" || err != nil {
+		return nil, errPartitionManagerNotFound
+	}
+	out, err := pkgexec.Run(ctx, execPath, "-l")
+	if err != nil {
+		return nil, err
+	}
+	return parsePartitions(out)
+}
+
+// parsePartitions parses the JSON output of the "fmpm -l" command.
+func parsePartitions(b []byte) ([]partition, error) {
+	var l partitionList
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	return l.PartitionInfo, nil
+}
@@ -0,0 +1,42 @@
+package driverupgrade
+
+import (
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config configures the revalidation after the driver upgrades.
+type Config struct {
+	// Plugins are the names of the custom plugins (or any other components)
+	// to check along with the NVIDIA components after a driver upgrade
+	// (e.g., a plugin that runs a CUDA sample).
+	Plugins []string `json:"plugins,omitempty"`
+
+	// SettlePeriod is how long to wait after the upgrade is detected before
+	// revalidating, for the GPUs to be reinitialized. Zero uses the default.
+	SettlePeriod metav1.Duration `json:"settle_period,omitempty"`
+}
+
+// Validate returns an error if any of the plugin names is empty or duplicate,
+// or if the settle period is negative.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(cfg.Plugins))
+	for _, name := range cfg.Plugins {
+		if name == "" {
+			return errors.New("plugin name is required")
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate plugin %q", name)
+		}
+		seen[name] = struct{}{}
+	}
+	if cfg.SettlePeriod.Duration < 0 {
+		return fmt.Errorf("settle period must be non-negative, got %s", cfg.SettlePeriod.Duration)
+	}
+	return nil
+}
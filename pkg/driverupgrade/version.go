@@ -0,0 +1,43 @@
+package driverupgrade
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+const (
+	// DefaultVersionFile is the file of the loaded NVIDIA kernel module version.
+	DefaultVersionFile = "/proc/driver/nvidia/version"
+
+	// versionNone is the version recorded when the NVIDIA kernel module is not loaded.
+	versionNone = "none"
+)
+
+// e.g.,
+// "NVRM version: NVIDIA UNIX x86_64 Kernel Module  535.129.03  Thu Oct 19 18:56:32 UTC 2023"
+// "NVRM version: NVIDIA UNIX Open Kernel Module for x86_64  550.54.15  Release Build  (dvs-builder@U16-I3-D08-1-2)  Mon Feb 19 20:40:57 UTC 2024"
+var kernelModuleVersionRegex = regexp.MustCompile(`Kernel Module.*?\s(\d+\.\d+(?:\.\d+)*)(?:\s|$)`)
+
+// ReadVersion reads the version of the loaded NVIDIA kernel module from the file
+// (e.g., "/proc/driver/nvidia/version"), and returns an empty string
+// with no error if the file does not exist (the kernel module not loaded).
+func ReadVersion(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return parseVersion(string(b))
+}
+
+func parseVersion(s string) (string, error) {
+	m := kernelModuleVersionRegex.FindStringSubmatch(s)
+	if len(m) != 2 {
+		return "", fmt.Errorf("kernel module version not found in %q", s)
+	}
+	return m[1], nil
+}
@@ -0,0 +1,436 @@
+// Package driverupgrade detects the NVIDIA driver installs and upgrades
+// (the version of the loaded kernel module changed, or the module reloaded),
+// and revalidates the NVIDIA components and the selected plugins right after,
+// recording the consolidated validated or failed event, so that a driver rollout
+// is verified without waiting for the next scheduled checks.
+package driverupgrade
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+const (
+	// BucketName is the event bucket of the driver upgrade validations.
+	BucketName = "driver-upgrade"
+	// EventNameValidated is the event name of the driver upgrade
+	// with all the revalidated components healthy.
+	EventNameValidated = "driver_upgrade_validated"
+	// EventNameFailed is the event name of the driver upgrade
+	// with any of the revalidated components not healthy.
+	EventNameFailed = "driver_upgrade_failed"
+
+	// DefaultPollInterval is the default interval to check the driver version.
+	DefaultPollInterval = time.Minute
+	// DefaultSettlePeriod is the default wait after the upgrade is detected
+	// before revalidating, for the GPUs to be reinitialized.
+	DefaultSettlePeriod = 30 * time.Second
+	// DefaultCheckTimeout is the timeout of the revalidation checks.
+	DefaultCheckTimeout = 5 * time.Minute
+
+	// reloadLookback is how far back the driver reloads are looked up,
+	// to cover the reloads observed from the kmsg after the previous poll.
+	reloadLookback = 10 * time.Minute
+
+	// nvidiaComponentPrefix is the name prefix of the NVIDIA components to revalidate.
+	nvidiaComponentPrefix = "accelerator-nvidia-"
+
+	eventKeyReason      = "reason"
+	eventKeyFromVersion = "from_version"
+	eventKeyToVersion   = "to_version"
+	eventKeyChecked     = "checked"
+)
+
+// Reason is how the driver upgrade was detected.
+type Reason string
+
+const (
+	// ReasonInstalled is the kernel module loaded for the first time
+	// since it was last seen not loaded.
+	ReasonInstalled Reason = "installed"
+	// ReasonVersionChanged is the version of the kernel module changed
+	// (e.g., upgraded, downgraded), including across the restarts and reboots.
+	ReasonVersionChanged Reason = "version changed"
+	// ReasonModuleReloaded is the kernel module reloaded with the same version
+	// (e.g., the module unloaded and loaded by the driver installer).
+	ReasonModuleReloaded Reason = "module reloaded"
+)
+
+// Validation is the result of the revalidation after a driver upgrade.
+type Validation struct {
+	Time        time.Time `json:"time"`
+	Reason      Reason    `json:"reason"`
+	FromVersion string    `json:"from_version,omitempty"`
+	ToVersion   string    `json:"to_version"`
+	// Passed is true if all the revalidated components are healthy.
+	Passed bool `json:"passed"`
+	// Checked is the number of the revalidated components.
+	Checked int `json:"checked"`
+	// Failed is the reason of each component not healthy (or timed out), by the component name.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// CheckFunc runs the checks of the components concurrently within the timeout.
+type CheckFunc func(comps []components.Component, timeout time.Duration) apiv1.ComponentCheckResults
+
+// Watcher polls the loaded driver version and the driver reloads,
+// and revalidates the components on the changes.
+// Not safe for concurrent use, only polled by the goroutine started by Start.
+type Watcher struct {
+	cfg       Config
+	dbRW      *sql.DB
+	dbRO      *sql.DB
+	bucket    eventstore.Bucket
+	resets    *gpureset.Tracker
+	registry  components.Registry
+	checkFunc CheckFunc
+
+	readVersionFunc func() (string, error)
+	pollInterval    time.Duration
+	getTimeNowFunc  func() time.Time
+
+	// startTime is when the watcher started, to ignore the reloads before
+	// (detected by the version change across the restarts instead)
+	startTime time.Time
+	// seenReloads is the driver reload times (in unix nanoseconds) already revalidated
+	seenReloads map[int64]struct{}
+}
+
+// New creates the driver upgrade watcher.
+// The driver reloads are not watched if the reset tracker is nil.
+func New(cfg *Config, dbRW *sql.DB, dbRO *sql.DB, bucket eventstore.Bucket, resets *gpureset.Tracker, registry components.Registry, checkFunc CheckFunc) *Watcher {
+	w := &Watcher{
+		dbRW:      dbRW,
+		dbRO:      dbRO,
+		bucket:    bucket,
+		resets:    resets,
+		registry:  registry,
+		checkFunc: checkFunc,
+		readVersionFunc: func() (string, error) {
+			return ReadVersion(DefaultVersionFile)
+		},
+		pollInterval: DefaultPollInterval,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		seenReloads: make(map[int64]struct{}),
+	}
+	if cfg != nil {
+		w.cfg = *cfg
+	}
+	if w.cfg.SettlePeriod.Duration == 0 {
+		w.cfg.SettlePeriod.Duration = DefaultSettlePeriod
+	}
+	return w
+}
+
+// Start polls right away, to detect the upgrades while the daemon was not running,
+// and then periodically until the context is canceled.
+func (w *Watcher) Start(ctx context.Context) {
+	w.startTime = w.getTimeNowFunc()
+
+	go func() {
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			w.poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+type change struct {
+	reason      Reason
+	fromVersion string
+	toVersion   string
+}
+
+// poll detects the driver upgrade once, and revalidates if detected.
+func (w *Watcher) poll(ctx context.Context) {
+	c, err := w.detect(ctx)
+	if err != nil {
+		log.Logger.Warnw("failed to detect nvidia driver upgrade", "error", err)
+		return
+	}
+	if c == nil {
+		return
+	}
+	log.Logger.Infow("nvidia driver upgrade detected, revalidating", "reason", c.reason, "from", c.fromVersion, "to", c.toVersion, "settlePeriod", w.cfg.SettlePeriod.Duration)
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(w.cfg.SettlePeriod.Duration):
+	}
+
+	v := w.revalidate(*c)
+	if err := w.bucket.Insert(ctx, Event(v)); err != nil {
+		log.Logger.Errorw("failed to insert driver upgrade event", "error", err)
+	}
+	log.Logger.Infow("nvidia driver upgrade revalidated", "passed", v.Passed, "checked", v.Checked, "failed", len(v.Failed))
+}
+
+// detect returns the driver upgrade since the last poll, and nil if none.
+func (w *Watcher) detect(ctx context.Context) (*change, error) {
+	cur, err := w.readVersionFunc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read driver version: %w", err)
+	}
+	if cur == "" {
+		cur = versionNone
+	}
+
+	last, err := pkgmetadata.ReadMetadata(ctx, w.dbRO, pkgmetadata.MetadataKeyLastNVIDIADriverVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last driver version: %w", err)
+	}
+
+	// the reloads are marked seen even if the version changed,
+	// as one revalidation covers both
+	reloaded, err := w.reloaded(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cur == versionNone:
+		// e.g., unloaded in the middle of an upgrade,
+		// the previous version is kept to report the upgrade from
+		if last == "" {
+			return nil, w.setVersion(ctx, cur)
+		}
+		return nil, nil
+
+	case last == "":
+		// first seen, nothing to compare with
+		return nil, w.setVersion(ctx, cur)
+
+	case last == versionNone:
+		return &change{reason: ReasonInstalled, toVersion: cur}, w.setVersion(ctx, cur)
+
+	case last != cur:
+		return &change{reason: ReasonVersionChanged, fromVersion: last, toVersion: cur}, w.setVersion(ctx, cur)
+
+	case reloaded:
+		return &change{reason: ReasonModuleReloaded, fromVersion: last, toVersion: cur}, nil
+	}
+	return nil, nil
+}
+
+func (w *Watcher) setVersion(ctx context.Context, version string) error {
+	if err := pkgmetadata.SetMetadata(ctx, w.dbRW, pkgmetadata.MetadataKeyLastNVIDIADriverVersion, version); err != nil {
+		return fmt.Errorf("failed to set last driver version: %w", err)
+	}
+	return nil
+}
+
+// reloaded returns true if a driver reload not yet seen is observed
+// after the watcher started.
+func (w *Watcher) reloaded(ctx context.Context) (bool, error) {
+	if w.resets == nil {
+		return false, nil
+	}
+
+	now := w.getTimeNowFunc()
+	since := now.Add(-reloadLookback)
+	if since.Before(w.startTime) {
+		since = w.startTime
+	}
+	windows, err := w.resets.Windows(ctx, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to get gpu reset windows: %w", err)
+	}
+
+	for t := range w.seenReloads {
+		if t < now.Add(-2*reloadLookback).UnixNano() {
+			delete(w.seenReloads, t)
+		}
+	}
+
+	found := false
+	for _, win := range windows {
+		if win.Reason != gpureset.ReasonDriverReloaded {
+			continue
+		}
+		// the window starts before the reload
+		reloadTime := win.Start.Add(gpureset.DefaultLeadPeriod)
+		if reloadTime.Before(since) {
+			continue
+		}
+		if _, ok := w.seenReloads[reloadTime.UnixNano()]; ok {
+			continue
+		}
+		w.seenReloads[reloadTime.UnixNano()] = struct{}{}
+		found = true
+	}
+	return found, nil
+}
+
+// revalidate checks all the NVIDIA components and the selected plugins.
+func (w *Watcher) revalidate(c change) Validation {
+	comps := w.selectComponents()
+	results := w.checkFunc(comps, DefaultCheckTimeout)
+
+	v := Validation{
+		Time:        w.getTimeNowFunc(),
+		Reason:      c.reason,
+		FromVersion: c.fromVersion,
+		ToVersion:   c.toVersion,
+		Passed:      true,
+		Checked:     len(comps),
+	}
+	for name, res := range results {
+		reason, ok := failedReason(res)
+		if !ok {
+			continue
+		}
+		if v.Failed == nil {
+			v.Failed = make(map[string]string)
+		}
+		v.Failed[name] = reason
+		v.Passed = false
+	}
+	return v
+}
+
+// selectComponents returns the registered NVIDIA components and the selected plugins,
+// sorted by the name.
+func (w *Watcher) selectComponents() []components.Component {
+	selected := make(map[string]components.Component)
+	for _, comp := range w.registry.All() {
+		if strings.HasPrefix(comp.Name(), nvidiaComponentPrefix) {
+			selected[comp.Name()] = comp
+		}
+	}
+	for _, name := range w.cfg.Plugins {
+		comp := w.registry.Get(name)
+		if comp == nil {
+			log.Logger.Warnw("driver upgrade revalidation plugin not found", "plugin", name)
+			continue
+		}
+		selected[name] = comp
+	}
+
+	comps := make([]components.Component, 0, len(selected))
+	for _, comp := range selected {
+		comps = append(comps, comp)
+	}
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].Name() < comps[j].Name()
+	})
+	return comps
+}
+
+// failedReason returns the reason of the check result not healthy,
+// and false if healthy.
+func failedReason(res apiv1.ComponentCheckResult) (string, bool) {
+	if res.TimedOut {
+		return "timed out", true
+	}
+	for _, st := range res.States {
+		if st.Health != apiv1.HealthStateTypeUnhealthy && st.Health != apiv1.HealthStateTypeDegraded {
+			continue
+		}
+		if st.Reason != "" {
+			return st.Reason, true
+		}
+		if st.Error != "" {
+			return st.Error, true
+		}
+		return string(st.Health), true
+	}
+	return "", false
+}
+
+// Event returns the consolidated event of the validation, of the warning type
+// with the reason of each failed component by the component name if any failed.
+func Event(v Validation) eventstore.Event {
+	ev := eventstore.Event{
+		Component: BucketName,
+		Time:      v.Time,
+		Name:      EventNameValidated,
+		Type:      string(apiv1.EventTypeInfo),
+		Message:   fmt.Sprintf("nvidia driver %s (%s) validated with %d component(s) healthy", v.Reason, describeVersions(v), v.Checked),
+		ExtraInfo: map[string]string{
+			eventKeyReason:      string(v.Reason),
+			eventKeyFromVersion: v.FromVersion,
+			eventKeyToVersion:   v.ToVersion,
+			eventKeyChecked:     strconv.Itoa(v.Checked),
+		},
+	}
+	if v.Passed {
+		return ev
+	}
+
+	failed := make([]string, 0, len(v.Failed))
+	for name, reason := range v.Failed {
+		failed = append(failed, name)
+		ev.ExtraInfo[name] = reason
+	}
+	sort.Strings(failed)
+
+	ev.Name = EventNameFailed
+	ev.Type = string(apiv1.EventTypeWarning)
+	ev.Message = fmt.Sprintf("nvidia driver %s (%s) failed validation: %s", v.Reason, describeVersions(v), strings.Join(failed, ", "))
+	return ev
+}
+
+func describeVersions(v Validation) string {
+	if v.FromVersion == "" || v.FromVersion == v.ToVersion {
+		return v.ToVersion
+	}
+	return v.FromVersion + " -> " + v.ToVersion
+}
+
+// Validations returns the validations since the time, the latest first.
+func (w *Watcher) Validations(ctx context.Context, since time.Time) ([]Validation, error) {
+	evs, err := w.bucket.Get(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	validations := make([]Validation, 0, len(evs))
+	for _, ev := range evs {
+		if ev.Name != EventNameValidated && ev.Name != EventNameFailed {
+			continue
+		}
+		v := Validation{
+			Time:        ev.Time,
+			Reason:      Reason(ev.ExtraInfo[eventKeyReason]),
+			FromVersion: ev.ExtraInfo[eventKeyFromVersion],
+			ToVersion:   ev.ExtraInfo[eventKeyToVersion],
+			Passed:      ev.Name == EventNameValidated,
+		}
+		v.Checked, _ = strconv.Atoi(ev.ExtraInfo[eventKeyChecked])
+		for k, reason := range ev.ExtraInfo {
+			switch k {
+			case eventKeyReason, eventKeyFromVersion, eventKeyToVersion, eventKeyChecked:
+				continue
+			}
+			if v.Failed == nil {
+				v.Failed = make(map[string]string)
+			}
+			v.Failed[k] = reason
+		}
+		validations = append(validations, v)
+	}
+	return validations, nil
+}
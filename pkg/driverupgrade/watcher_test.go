@@ -0,0 +1,198 @@
+package driverupgrade
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type fakeComponent struct {
+	components.Component
+	name string
+}
+
+func (f *fakeComponent) Name() string { return f.name }
+
+type fakeRegistry struct {
+	components.Registry
+	comps []components.Component
+}
+
+func (f *fakeRegistry) All() []components.Component { return f.comps }
+
+func (f *fakeRegistry) Get(name string) components.Component {
+	for _, c := range f.comps {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	require.NoError(t, cfg.Validate())
+	require.NoError(t, (&Config{Plugins: []string{"a", "b"}, SettlePeriod: metav1.Duration{Duration: time.Minute}}).Validate())
+	require.Error(t, (&Config{Plugins: []string{""}}).Validate())
+	require.Error(t, (&Config{Plugins: []string{"a", "a"}}).Validate())
+	require.Error(t, (&Config{SettlePeriod: metav1.Duration{Duration: -time.Second}}).Validate())
+}
+
+func TestReadVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	v, err := ReadVersion(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	for content, want := range map[string]string{
+		"NVRM version: NVIDIA UNIX x86_64 Kernel Module  535.129.03  Thu Oct 19 18:56:32 UTC 2023\nGCC version:  gcc version 11.4.0\n":                    "535.129.03",
+		"NVRM version: NVIDIA UNIX Open Kernel Module for x86_64  550.54.15  Release Build  (dvs-builder@U16-I3-D08-1-2)  Mon Feb 19 20:40:57 UTC 2024\n": "550.54.15",
+		"NVRM version: NVIDIA UNIX Open Kernel Module for aarch64  570.86.15":                                                                             "570.86.15",
+	} {
+		p := filepath.Join(dir, "version")
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		v, err = ReadVersion(p)
+		require.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+
+	_, err = parseVersion("unexpected")
+	require.Error(t, err)
+}
+
+func TestWatcher(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+	resetBucket, err := store.Bucket(gpureset.BucketName)
+	require.NoError(t, err)
+	defer resetBucket.Close()
+	resets := gpureset.New(resetBucket, nil)
+
+	registry := &fakeRegistry{comps: []components.Component{
+		&fakeComponent{name: "accelerator-nvidia-ecc"},
+		&fakeComponent{name: "accelerator-nvidia-xid"},
+		&fakeComponent{name: "cpu"},
+		&fakeComponent{name: "cuda-sample"},
+	}}
+
+	var checked []string
+	results := apiv1.ComponentCheckResults{}
+	checkFunc := func(comps []components.Component, timeout time.Duration) apiv1.ComponentCheckResults {
+		checked = checked[:0]
+		for _, c := range comps {
+			checked = append(checked, c.Name())
+		}
+		assert.Equal(t, DefaultCheckTimeout, timeout)
+		return results
+	}
+
+	w := New(&Config{Plugins: []string{"cuda-sample", "missing"}, SettlePeriod: metav1.Duration{Duration: time.Millisecond}}, dbRW, dbRO, bucket, resets, registry, checkFunc)
+	version := ""
+	w.readVersionFunc = func() (string, error) { return version, nil }
+	w.startTime = time.Now().UTC()
+
+	latest := func() []Validation {
+		vs, err := w.Validations(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		return vs
+	}
+
+	// first seen with no driver, then installed
+	w.poll(ctx)
+	assert.Empty(t, latest())
+
+	version = "535.129.03"
+	w.poll(ctx)
+	vs := latest()
+	require.Len(t, vs, 1)
+	assert.Equal(t, ReasonInstalled, vs[0].Reason)
+	assert.Equal(t, "535.129.03", vs[0].ToVersion)
+	assert.True(t, vs[0].Passed)
+	assert.Equal(t, 3, vs[0].Checked)
+	assert.Equal(t, []string{"accelerator-nvidia-ecc", "accelerator-nvidia-xid", "cuda-sample"}, checked)
+
+	// no change
+	w.poll(ctx)
+	assert.Len(t, latest(), 1)
+
+	// unloaded in the middle of the upgrade, then upgraded with a failure
+	version = ""
+	w.poll(ctx)
+	assert.Len(t, latest(), 1)
+
+	results["accelerator-nvidia-xid"] = apiv1.ComponentCheckResult{States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "XID 79"}}}
+	results["cuda-sample"] = apiv1.ComponentCheckResult{TimedOut: true}
+	results["accelerator-nvidia-ecc"] = apiv1.ComponentCheckResult{States: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}}
+	version = "550.54.15"
+	time.Sleep(time.Second) // the events are stored at the second granularity
+	w.poll(ctx)
+	vs = latest()
+	require.Len(t, vs, 2)
+	assert.Equal(t, ReasonVersionChanged, vs[0].Reason)
+	assert.Equal(t, "535.129.03", vs[0].FromVersion)
+	assert.Equal(t, "550.54.15", vs[0].ToVersion)
+	assert.False(t, vs[0].Passed)
+	assert.Equal(t, map[string]string{"accelerator-nvidia-xid": "XID 79", "cuda-sample": "timed out"}, vs[0].Failed)
+
+	// reloaded with the same version, revalidated once
+	results = apiv1.ComponentCheckResults{}
+	_, err = resets.ObserveKmsg(ctx, kmsg.Message{Timestamp: metav1.NewTime(time.Now().UTC()), Message: "NVRM: loading NVIDIA UNIX x86_64 Kernel Module  550.54.15"})
+	require.NoError(t, err)
+	time.Sleep(time.Second)
+	w.poll(ctx)
+	vs = latest()
+	require.Len(t, vs, 3)
+	assert.Equal(t, ReasonModuleReloaded, vs[0].Reason)
+	assert.True(t, vs[0].Passed)
+
+	w.poll(ctx)
+	assert.Len(t, latest(), 3)
+
+	// detected across the restart
+	w2 := New(nil, dbRW, dbRO, bucket, nil, registry, checkFunc)
+	w2.readVersionFunc = func() (string, error) { return "570.86.15", nil }
+	w2.cfg.SettlePeriod.Duration = time.Millisecond
+	c, err := w2.detect(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, change{reason: ReasonVersionChanged, fromVersion: "550.54.15", toVersion: "570.86.15"}, *c)
+}
+
+func TestEvent(t *testing.T) {
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	ev := Event(Validation{Time: now, Reason: ReasonVersionChanged, FromVersion: "535.129.03", ToVersion: "550.54.15", Passed: true, Checked: 20})
+	assert.Equal(t, EventNameValidated, ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeInfo), ev.Type)
+	assert.Equal(t, "nvidia driver version changed (535.129.03 -> 550.54.15) validated with 20 component(s) healthy", ev.Message)
+
+	ev = Event(Validation{Time: now, Reason: ReasonModuleReloaded, FromVersion: "550.54.15", ToVersion: "550.54.15", Checked: 20, Failed: map[string]string{"b": "timed out", "a": "XID 79"}})
+	assert.Equal(t, EventNameFailed, ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeWarning), ev.Type)
+	assert.Equal(t, "nvidia driver module reloaded (550.54.15) failed validation: a, b", ev.Message)
+	assert.Equal(t, "XID 79", ev.ExtraInfo["a"])
+}
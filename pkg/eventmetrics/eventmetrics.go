@@ -0,0 +1,193 @@
+// Package eventmetrics bridges the events to the counter metrics, so that the
+// rate-based alerts and the dashboards are built from the metrics alone,
+// without parsing the event stream.
+//
+// Every inserted event is counted by the component, the event name and type,
+// and the components register the bridges to count their events by the event
+// details (e.g., the SXid code, the GPU UUID).
+package eventmetrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var metricEventsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "gpud",
+		Subsystem: "",
+		Name:      "events_total",
+		Help:      "total number of the events inserted per component, event name, and event type",
+	},
+	[]string{pkgmetrics.MetricComponentLabelKey, "event", "type"},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricEventsTotal)
+}
+
+// Bridge counts the events of the name as a counter metric,
+// labeled by the event details.
+type Bridge struct {
+	// EventName is the name of the events to count (e.g., "error_sxid").
+	EventName string
+	// Name is the counter metric name (e.g., "accelerator_nvidia_sxid_errors_total").
+	Name string
+	// Help is the description of the counter metric.
+	Help string
+	// Labels is the label keys of the counter metric.
+	Labels []string
+	// LabelValues returns the label values of the event in the order of the Labels,
+	// or false to not count the event (e.g., missing the event details).
+	// Nil to count every event of the name with the empty label values.
+	LabelValues func(ev eventstore.Event) ([]string, bool)
+}
+
+type registeredBridge struct {
+	Bridge
+	counter *prometheus.CounterVec
+}
+
+var (
+	registryMu sync.RWMutex
+	// bridges by the component and the event name
+	registry = make(map[string]map[string][]*registeredBridge)
+)
+
+// MustRegister registers the bridges of the component, with the counter metrics
+// and their metadata. Panics if the bridge is invalid or the metric is already registered.
+func MustRegister(component string, bridges ...Bridge) {
+	if err := register(pkgmetrics.DefaultRegisterer(), component, bridges...); err != nil {
+		panic(err)
+	}
+	mds := make([]apiv1.MetricMetadata, 0, len(bridges))
+	for _, b := range bridges {
+		mds = append(mds, apiv1.MetricMetadata{Name: b.Name, Type: apiv1.MetricTypeCounter, Unit: apiv1.MetricUnitCount})
+	}
+	pkgmetrics.MustRegisterMetadata(component, mds...)
+}
+
+func register(reg prometheus.Registerer, component string, bridges ...Bridge) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, b := range bridges {
+		if b.EventName == "" || b.Name == "" {
+			return fmt.Errorf("event metric bridge of component %q missing the event name or the metric name", component)
+		}
+
+		counter := prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: b.Name,
+				Help: b.Help,
+			},
+			append([]string{pkgmetrics.MetricComponentLabelKey}, b.Labels...),
+		)
+		if err := reg.Register(counter); err != nil {
+			return fmt.Errorf("failed to register event metric %q: %w", b.Name, err)
+		}
+
+		if registry[component] == nil {
+			registry[component] = make(map[string][]*registeredBridge)
+		}
+		registry[component][b.EventName] = append(registry[component][b.EventName], &registeredBridge{Bridge: b, counter: counter})
+	}
+	return nil
+}
+
+// Observe counts the event inserted into the bucket of the component.
+func Observe(component string, ev eventstore.Event) {
+	metricEventsTotal.WithLabelValues(component, ev.Name, ev.Type).Inc()
+
+	registryMu.RLock()
+	bridges := registry[component][ev.Name]
+	registryMu.RUnlock()
+
+	for _, b := range bridges {
+		values := make([]string, len(b.Labels))
+		if b.LabelValues != nil {
+			vs, ok := b.LabelValues(ev)
+			if !ok || len(vs) != len(b.Labels) {
+				continue
+			}
+			copy(values, vs)
+		}
+		b.counter.WithLabelValues(append([]string{component}, values...)...).Inc()
+	}
+}
+
+// recentKeys is the number of the recent event dedup keys per bucket,
+// to not count the events the store deduplicates (e.g., the same event inserted twice).
+const recentKeys = 1024
+
+// WrapEventStore returns the event store whose buckets count
+// the inserted events as the metrics.
+func WrapEventStore(store eventstore.Store) eventstore.Store {
+	if store == nil {
+		return nil
+	}
+	return &eventStore{Store: store}
+}
+
+var _ eventstore.Store = &eventStore{}
+
+type eventStore struct {
+	eventstore.Store
+}
+
+func (s *eventStore) Bucket(name string, opts ...eventstore.OpOption) (eventstore.Bucket, error) {
+	bucket, err := s.Store.Bucket(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &eventBucket{Bucket: bucket, component: name, seen: make(map[string]struct{})}, nil
+}
+
+var _ eventstore.Bucket = &eventBucket{}
+
+type eventBucket struct {
+	eventstore.Bucket
+	// the bucket name passed to the store, as the bucket may report the table name
+	component string
+
+	mu sync.Mutex
+	// the recent dedup keys in the insertion order, evicted in the same order
+	keys []string
+	seen map[string]struct{}
+}
+
+func (b *eventBucket) Insert(ctx context.Context, ev eventstore.Event) error {
+	if err := b.Bucket.Insert(ctx, ev); err != nil {
+		return err
+	}
+	if !b.observeKey(eventstore.DedupKey(b.component, ev)) {
+		return nil
+	}
+	// the components may not set the event component, thus counted by the bucket
+	Observe(b.component, ev)
+	return nil
+}
+
+// observeKey returns false if the dedup key was recently inserted.
+func (b *eventBucket) observeKey(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.seen[key]; ok {
+		return false
+	}
+	if len(b.keys) >= recentKeys {
+		delete(b.seen, b.keys[0])
+		b.keys = b.keys[1:]
+	}
+	b.keys = append(b.keys, key)
+	b.seen[key] = struct{}{}
+	return true
+}
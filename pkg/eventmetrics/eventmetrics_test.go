@@ -0,0 +1,127 @@
+package eventmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	err := register(reg, "test-register", Bridge{Name: "test_register_total"})
+	assert.ErrorContains(t, err, "missing the event name or the metric name")
+
+	require.NoError(t, register(reg, "test-register", Bridge{EventName: "ev", Name: "test_register_total"}))
+	err = register(reg, "test-register", Bridge{EventName: "ev", Name: "test_register_total"})
+	assert.ErrorContains(t, err, `failed to register event metric "test_register_total"`)
+}
+
+func TestWrapEventStore(t *testing.T) {
+	const component = "test-wrap"
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, register(reg, component,
+		Bridge{
+			EventName: "error_code",
+			Name:      "test_wrap_errors_total",
+			Labels:    []string{"gpu_uuid", "code"},
+			LabelValues: func(ev eventstore.Event) ([]string, bool) {
+				code, ok := ev.ExtraInfo["code"]
+				return []string{ev.ExtraInfo["gpu_uuid"], code}, ok
+			},
+		},
+		Bridge{
+			EventName: "error_code",
+			Name:      "test_wrap_all_errors_total",
+		},
+	))
+	bridges := registry[component]["error_code"]
+	require.Len(t, bridges, 2)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+
+	bucket, err := WrapEventStore(store).Bucket(component)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	for i, ev := range []eventstore.Event{
+		{Name: "error_code", Type: "Critical", Message: "first", ExtraInfo: map[string]string{"gpu_uuid": "gpu-0", "code": "79"}},
+		{Name: "error_code", Type: "Critical", Message: "second", ExtraInfo: map[string]string{"gpu_uuid": "gpu-0", "code": "79"}},
+		{Name: "error_code", Type: "Warning", ExtraInfo: map[string]string{"gpu_uuid": "gpu-1", "code": "31"}},
+		// no code, only counted by the bridge without labels
+		{Name: "error_code", Type: "Warning", ExtraInfo: map[string]string{"gpu_uuid": "gpu-1"}},
+		{Name: "other", Type: "Info"},
+	} {
+		ev.Component = component
+		ev.Time = now.Add(time.Duration(i) * time.Second)
+		require.NoError(t, bucket.Insert(context.Background(), ev))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(bridges[0].counter.WithLabelValues(component, "gpu-0", "79")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(bridges[0].counter.WithLabelValues(component, "gpu-1", "31")))
+	assert.Equal(t, 2, testutil.CollectAndCount(bridges[0].counter))
+	assert.Equal(t, 4.0, testutil.ToFloat64(bridges[1].counter.WithLabelValues(component)))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metricEventsTotal.WithLabelValues(component, "error_code", "Critical")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metricEventsTotal.WithLabelValues(component, "error_code", "Warning")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metricEventsTotal.WithLabelValues(component, "other", "Info")))
+
+	evs, err := bucket.Get(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 5)
+
+	// deduplicated by the store, thus not counted
+	require.NoError(t, bucket.Insert(context.Background(), eventstore.Event{
+		Component: component,
+		Time:      now.Add(4 * time.Second),
+		Name:      "other",
+		Type:      "Info",
+	}))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metricEventsTotal.WithLabelValues(component, "other", "Info")))
+}
+
+func TestObserveKeyEviction(t *testing.T) {
+	b := &eventBucket{seen: make(map[string]struct{})}
+	for i := 0; i < recentKeys+1; i++ {
+		assert.True(t, b.observeKey(fmt.Sprintf("key-%d", i)))
+	}
+	assert.Len(t, b.keys, recentKeys)
+	assert.Len(t, b.seen, recentKeys)
+	assert.False(t, b.observeKey(fmt.Sprintf("key-%d", recentKeys)))
+	// evicted
+	assert.True(t, b.observeKey("key-0"))
+}
+
+type failingBucket struct {
+	eventstore.Bucket
+}
+
+func (b *failingBucket) Insert(context.Context, eventstore.Event) error {
+	return errors.New("insert failed")
+}
+
+func TestInsertFailedNotCounted(t *testing.T) {
+	const component = "test-insert-failed"
+
+	b := &eventBucket{Bucket: &failingBucket{}, component: component}
+	err := b.Insert(context.Background(), eventstore.Event{Name: "ev", Type: "Warning"})
+	assert.ErrorContains(t, err, "insert failed")
+	assert.Equal(t, 0.0, testutil.ToFloat64(metricEventsTotal.WithLabelValues(component, "ev", "Warning")))
+
+	assert.Nil(t, WrapEventStore(nil))
+}
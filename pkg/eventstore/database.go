@@ -11,6 +11,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/leptonai/gpud/pkg/intern"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
 )
@@ -50,6 +51,7 @@ type database struct {
 	dbRW      *sql.DB
 	dbRO      *sql.DB
 	retention time.Duration
+	policy    *RetentionPolicy
 }
 
 type table struct {
@@ -57,22 +59,37 @@ type table struct {
 	rootCancel context.CancelFunc
 
 	// retention is the duration to keep the events
+	// (of the types without its own retention)
 	// set to 0 to disable periodic purge
 	retention time.Duration
+	// retentionByType is the duration to keep the events per event type
+	retentionByType map[string]time.Duration
 	// purgeInterval is the interval to purge the events
 	// set to 0 to disable periodic purge
 	purgeInterval time.Duration
 
-	table string
-	dbRW  *sql.DB
-	dbRO  *sql.DB
+	// bucket is the normalized bucket name for the metrics
+	bucket string
+	table  string
+	dbRW   *sql.DB
+	dbRO   *sql.DB
 }
 
 func New(dbRW *sql.DB, dbRO *sql.DB, retention time.Duration) (Store, error) {
+	return NewWithRetentionPolicy(dbRW, dbRO, retention, nil)
+}
+
+// NewWithRetentionPolicy creates the event store with the per event type and per bucket retentions,
+// and the retention for the rest of the events.
+func NewWithRetentionPolicy(dbRW *sql.DB, dbRO *sql.DB, retention time.Duration, policy *RetentionPolicy) (Store, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
 	return &database{
 		dbRW:      dbRW,
 		dbRO:      dbRO,
 		retention: retention,
+		policy:    policy,
 	}, nil
 }
 
@@ -82,14 +99,15 @@ func (d *database) Bucket(name string, opts ...OpOption) (Bucket, error) {
 		return nil, err
 	}
 
+	retention := d.policy.resolve(name, d.retention)
 	// actual check interval should be lower than the retention period
 	// in case of GPUd restarts
-	purgeInterval := d.retention / 5
+	purgeInterval := retention.minRetention() / 5
 	if purgeInterval < time.Second {
 		purgeInterval = time.Second
 	}
 	if op.disablePurge {
-		d.retention = 0
+		retention = bucketRetention{}
 		purgeInterval = 0
 	}
 
@@ -102,10 +120,10 @@ func (d *database) Bucket(name string, opts ...OpOption) (Bucket, error) {
 		return nil, fmt.Errorf("failed to drop legacy table %q: %w", legacyTable, err)
 	}
 
-	return newTable(d.dbRW, d.dbRO, name, d.retention, purgeInterval)
+	return newTable(d.dbRW, d.dbRO, name, retention, purgeInterval)
 }
 
-func newTable(dbRW *sql.DB, dbRO *sql.DB, name string, retention time.Duration, purgeInterval time.Duration) (*table, error) {
+func newTable(dbRW *sql.DB, dbRO *sql.DB, name string, retention bucketRetention, purgeInterval time.Duration) (*table, error) {
 	tableName := defaultTableName(name, schemaVersion)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	err := createTable(ctx, dbRW, tableName)
@@ -116,15 +134,17 @@ func newTable(dbRW *sql.DB, dbRO *sql.DB, name string, retention time.Duration,
 
 	rootCtx, rootCancel := context.WithCancel(context.Background())
 	t := &table{
-		rootCtx:       rootCtx,
-		rootCancel:    rootCancel,
-		table:         tableName,
-		dbRW:          dbRW,
-		dbRO:          dbRO,
-		retention:     retention,
-		purgeInterval: purgeInterval,
-	}
-	if retention > time.Second {
+		rootCtx:         rootCtx,
+		rootCancel:      rootCancel,
+		bucket:          bucketKey(name),
+		table:           tableName,
+		dbRW:            dbRW,
+		dbRO:            dbRO,
+		retention:       retention.fallback,
+		retentionByType: retention.byType,
+		purgeInterval:   purgeInterval,
+	}
+	if retention.minRetention() > time.Second {
 		go t.runPurge()
 	}
 	return t, nil
@@ -134,12 +154,17 @@ func newTable(dbRW *sql.DB, dbRO *sql.DB, name string, retention time.Duration,
 // The table name is in the format of "components_{component_name}_events_{schemaVersion}".
 // Suffix with the version, in case we change the table schema.
 func defaultTableName(componentName string, schemaVersion string) string {
+	tableName := fmt.Sprintf("components_%s_events_%s", bucketKey(componentName), schemaVersion)
+	return tableName
+}
+
+// bucketKey normalizes the component name to identify the bucket
+// (e.g., "accelerator-nvidia-error-xid" to "accelerator_nvidia_error_xid").
+func bucketKey(componentName string) string {
 	c := strings.ReplaceAll(componentName, " ", "_")
 	c = strings.ReplaceAll(c, "-", "_")
 	c = strings.ReplaceAll(c, "__", "_")
-	c = strings.ToLower(c)
-	tableName := fmt.Sprintf("components_%s_events_%s", c, schemaVersion)
-	return tableName
+	return strings.ToLower(c)
 }
 
 func (t *table) Name() string {
@@ -147,7 +172,7 @@ func (t *table) Name() string {
 }
 
 func (t *table) runPurge() {
-	log.Logger.Infow("start purging", "table", t.table, "retention", t.retention, "checkInterval", t.purgeInterval)
+	log.Logger.Infow("start purging", "table", t.table, "retention", t.retention, "retentionByType", t.retentionByType, "checkInterval", t.purgeInterval)
 	for {
 		select {
 		case <-t.rootCtx.Done():
@@ -155,8 +180,7 @@ func (t *table) runPurge() {
 		case <-time.After(t.purgeInterval):
 		}
 
-		now := time.Now().UTC()
-		purged, err := t.Purge(t.rootCtx, now.Add(-t.retention).Unix())
+		purged, err := t.compact(t.rootCtx, time.Now().UTC())
 		if err != nil {
 			log.Logger.Errorw("failed to purge data", "table", t.table, "retention", t.retention, "error", err)
 		} else {
@@ -165,6 +189,47 @@ func (t *table) runPurge() {
 	}
 }
 
+// compact purges the events past their retentions, per event type,
+// and updates the event count metrics of the table.
+func (t *table) compact(ctx context.Context, now time.Time) (int, error) {
+	retention := bucketRetention{fallback: t.retention, byType: t.retentionByType}
+
+	purged := 0
+	for _, rule := range retention.rules(now) {
+		cond, args := rule.condition()
+		start := time.Now()
+		rs, err := t.dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s`, t.table, cond), args...)
+		if err != nil {
+			return purged, err
+		}
+		pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+
+		affected, err := rs.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += int(affected)
+	}
+	metricPurgedTotal.WithLabelValues(t.bucket).Add(float64(purged))
+
+	start := time.Now()
+	rows, err := t.dbRO.QueryContext(ctx, fmt.Sprintf(`SELECT %s, COUNT(*) FROM %s GROUP BY %s`, columnType, t.table, columnType))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return purged, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	counts, err := scanTypeCounts(rows)
+	if err != nil {
+		return purged, err
+	}
+	setEventCounts(t.bucket, counts)
+
+	return purged, nil
+}
+
 func (t *table) Close() {
 	if t.rootCancel != nil {
 		log.Logger.Debugw("closing the store", "table", t.table)
@@ -173,7 +238,7 @@ func (t *table) Close() {
 }
 
 func (t *table) Insert(ctx context.Context, ev Event) error {
-	return insertEvent(ctx, t.dbRW, t.table, ev)
+	return insertEvent(ctx, t.dbRW, t.bucket, t.table, ev)
 }
 
 // Find returns nil if the event is not found.
@@ -208,6 +273,7 @@ CREATE TABLE IF NOT EXISTS %s (
 	%s TEXT NOT NULL,
 	%s TEXT NOT NULL,
 	%s TEXT,
+	%s TEXT,
 	%s TEXT
 );`, tableName,
 		columnTimestamp,
@@ -215,12 +281,22 @@ CREATE TABLE IF NOT EXISTS %s (
 		columnType,
 		columnMessage,
 		columnExtraInfo,
+		columnDedupKey,
 	))
 	if err != nil {
 		_ = tx.Rollback()
 		return err
 	}
 
+	// NULL keys are not considered duplicates
+	// (the tables created before the key was introduced are migrated by "pkg/migrations")
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
+		tableName, columnDedupKey, tableName, columnDedupKey))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
 		tableName, columnTimestamp, tableName, columnTimestamp))
 	if err != nil {
@@ -245,7 +321,8 @@ CREATE TABLE IF NOT EXISTS %s (
 	return tx.Commit()
 }
 
-func insertEvent(ctx context.Context, db *sql.DB, tableName string, ev Event) error {
+// insertEvent inserts the event, or skips it if the event of the same dedup key is already stored.
+func insertEvent(ctx context.Context, db *sql.DB, bucket string, tableName string, ev Event) error {
 	var extraInfoJSON []byte
 	if ev.ExtraInfo != nil {
 		var err error
@@ -256,33 +333,40 @@ func insertEvent(ctx context.Context, db *sql.DB, tableName string, ev Event) er
 	}
 
 	start := time.Now()
-	_, err := db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))",
+	rs, err := db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?) ON CONFLICT(%s) DO NOTHING",
 		tableName,
 		columnTimestamp,
 		columnName,
 		columnType,
 		columnMessage,
 		columnExtraInfo,
+		columnDedupKey,
+		columnDedupKey,
 	),
 		ev.Time.Unix(),
 		ev.Name,
 		ev.Type,
 		ev.Message,
 		string(extraInfoJSON),
+		DedupKey(bucket, ev),
 	)
 	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
 
-	return err
+	return recordDeduplicated(bucket, rs)
 }
 
 func findEvent(ctx context.Context, db *sql.DB, tableName string, ev Event) (*Event, error) {
 	selectStatement := fmt.Sprintf(`
-SELECT %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
+SELECT %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
 		columnTimestamp,
 		columnName,
 		columnType,
 		columnMessage,
 		columnExtraInfo,
+		columnDedupKey,
 		tableName,
 		columnTimestamp,
 		columnName,
@@ -325,11 +409,11 @@ SELECT %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
 
 // Returns the event in the descending order of timestamp (latest event first).
 func getEvents(ctx context.Context, db *sql.DB, tableName string, since time.Time) (Events, error) {
-	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s
 FROM %s
 WHERE %s > ?
 ORDER BY %s DESC`,
-		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnDedupKey,
 		tableName,
 		columnTimestamp,
 		columnTimestamp,
@@ -365,8 +449,8 @@ ORDER BY %s DESC`,
 }
 
 func lastEvent(ctx context.Context, db *sql.DB, tableName string) (*Event, error) {
-	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s FROM %s ORDER BY %s DESC LIMIT 1`,
-		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, tableName, columnTimestamp)
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s FROM %s ORDER BY %s DESC LIMIT 1`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnDedupKey, tableName, columnTimestamp)
 
 	start := time.Now()
 	row := db.QueryRowContext(ctx, query)
@@ -388,25 +472,31 @@ func scanRow(row *sql.Row) (Event, error) {
 	var timestamp int64
 	var msg sql.NullString
 	var extraInfo sql.NullString
+	var dedupKey sql.NullString
 	err := row.Scan(
 		&timestamp,
 		&event.Name,
 		&event.Type,
 		&msg,
 		&extraInfo,
+		&dedupKey,
 	)
 	if err != nil {
 		return event, err
 	}
 
-	event.Time = time.Unix(timestamp, 0)
+	event.Time = time.Unix(timestamp, 0).UTC()
 	if msg.Valid {
 		event.Message = msg.String
 	}
+	if dedupKey.Valid {
+		event.DedupKey = dedupKey.String
+	}
 
 	if err := unmarshalIfValid(extraInfo, &event.ExtraInfo); err != nil {
 		return event, fmt.Errorf("failed to unmarshal extra info: %w", err)
 	}
+	internEvent(&event)
 
 	return event, nil
 }
@@ -416,29 +506,45 @@ func scanRows(rows *sql.Rows) (Event, error) {
 	var timestamp int64
 	var msg sql.NullString
 	var extraInfo sql.NullString
+	var dedupKey sql.NullString
 	err := rows.Scan(
 		&timestamp,
 		&event.Name,
 		&event.Type,
 		&msg,
 		&extraInfo,
+		&dedupKey,
 	)
 	if err != nil {
 		return event, err
 	}
 
-	event.Time = time.Unix(timestamp, 0)
+	event.Time = time.Unix(timestamp, 0).UTC()
 	if msg.Valid {
 		event.Message = msg.String
 	}
+	if dedupKey.Valid {
+		event.DedupKey = dedupKey.String
+	}
 
 	if err := unmarshalIfValid(extraInfo, &event.ExtraInfo); err != nil {
 		return event, fmt.Errorf("failed to unmarshal extra info: %w", err)
 	}
+	internEvent(&event)
 
 	return event, nil
 }
 
+// internEvent interns the event name, type, and extra info keys,
+// which repeat across the events read from the bucket.
+func internEvent(ev *Event) {
+	ev.Name = intern.String(ev.Name)
+	ev.Type = intern.String(ev.Type)
+	if len(ev.ExtraInfo) > 0 {
+		ev.ExtraInfo = intern.Labels(ev.ExtraInfo)
+	}
+}
+
 func purgeEvents(ctx context.Context, db *sql.DB, tableName string, beforeTimestamp int64) (int, error) {
 	deleteStatement := fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, tableName, columnTimestamp)
 
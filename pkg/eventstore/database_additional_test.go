@@ -2,8 +2,10 @@ package eventstore
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -58,3 +60,35 @@ func TestCreateTableWithClosedDB(t *testing.T) {
 	err := createTable(ctx, dbRW, "test_table_closed_db")
 	require.Error(t, err)
 }
+
+func TestGetInternsEventStrings(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test_intern")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bucket.Insert(ctx, Event{
+			Time:      now.Add(time.Duration(i) * time.Second),
+			Name:      "xid",
+			Type:      string(apiv1.EventTypeCritical),
+			Message:   fmt.Sprintf("test %d", i),
+			ExtraInfo: map[string]string{"gpu_uuid": "GPU-0"},
+		}))
+	}
+
+	evs, err := bucket.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 3)
+	for _, ev := range evs[1:] {
+		assert.Equal(t, unsafe.StringData(evs[0].Name), unsafe.StringData(ev.Name))
+		assert.Equal(t, unsafe.StringData(evs[0].Type), unsafe.StringData(ev.Type))
+		assert.Equal(t, map[string]string{"gpu_uuid": "GPU-0"}, ev.ExtraInfo)
+	}
+}
@@ -1210,7 +1210,7 @@ func TestRetentionPurge(t *testing.T) {
 		dbRW,
 		dbRO,
 		testTableName,
-		10*time.Second,
+		bucketRetention{fallback: 10 * time.Second},
 		// much shorter than the retention period
 		// to make tests less flaky
 		50*time.Millisecond,
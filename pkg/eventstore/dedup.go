@@ -0,0 +1,57 @@
+package eventstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+const (
+	// columnDedupKey represents the deduplication key of the event,
+	// enforced unique so that the same event is only stored once
+	// (e.g., the same kmsg line replayed after GPUd restarts).
+	// NULL for the events stored before the key was introduced
+	// (the column added to the existing tables by the schema migration).
+	columnDedupKey = "dedup_key"
+)
+
+// DedupKey returns the stable deduplication key of the event in the bucket,
+// the hash of the bucket, the event name, type, message, extra info
+// (the source identifiers, e.g., the GPU UUID and the data source),
+// and the exact event time, so that only the same source record
+// (e.g., retried, or replayed after restarts) shares the key, while
+// the distinct occurrences of the same event (e.g., the repeated Xids) do not.
+// Returns the key of the event as is, if already set by the source (e.g., the
+// kmsg events keyed on the boot ID and the sequence number, stable across restarts
+// unlike the event time derived from the uptime).
+func DedupKey(bucket string, ev Event) string {
+	if ev.DedupKey != "" {
+		return ev.DedupKey
+	}
+
+	h := sha256.New()
+	write := func(s string) {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	write(bucketKey(bucket))
+	write(ev.Name)
+	write(ev.Type)
+	write(ev.Message)
+
+	keys := make([]string, 0, len(ev.ExtraInfo))
+	for k := range ev.ExtraInfo {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		write(k)
+		write(ev.ExtraInfo[k])
+	}
+	// not the unix nanoseconds, which overflow for the times beyond the years 1678 to 2262
+	write(strconv.FormatInt(ev.Time.Unix(), 10) + "." + strconv.Itoa(ev.Time.Nanosecond()))
+
+	// 128 bits are enough to not collide within a bucket
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
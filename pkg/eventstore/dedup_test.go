@@ -0,0 +1,95 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestDedupKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 30, 10, 0, time.UTC)
+	ev := Event{
+		Time:      now,
+		Name:      "xid",
+		Type:      string(apiv1.EventTypeFatal),
+		Message:   "Xid 79",
+		ExtraInfo: map[string]string{"uuid": "GPU-1", "source": "kmsg"},
+	}
+
+	key := DedupKey("accelerator-nvidia-xid", ev)
+	assert.Len(t, key, 32)
+	assert.Equal(t, key, DedupKey("accelerator-nvidia-xid", ev))
+
+	// the same source record observed again
+	again := ev
+	again.ExtraInfo = map[string]string{"source": "kmsg", "uuid": "GPU-1"}
+	assert.Equal(t, key, DedupKey("accelerator-nvidia-xid", again))
+
+	// the distinct occurrences of the same event
+	other := ev
+	other.Time = now.Add(time.Second)
+	assert.NotEqual(t, key, DedupKey("accelerator-nvidia-xid", other))
+	other.Time = now.Add(time.Millisecond)
+	assert.NotEqual(t, key, DedupKey("accelerator-nvidia-xid", other))
+
+	other = ev
+	other.Message = "Xid 48"
+	assert.NotEqual(t, key, DedupKey("accelerator-nvidia-xid", other))
+
+	other = ev
+	other.ExtraInfo = map[string]string{"uuid": "GPU-2", "source": "kmsg"}
+	assert.NotEqual(t, key, DedupKey("accelerator-nvidia-xid", other))
+
+	assert.NotEqual(t, key, DedupKey("accelerator-nvidia-sxid", ev))
+
+	preset := ev
+	preset.DedupKey = "abc"
+	assert.Equal(t, "abc", DedupKey("accelerator-nvidia-xid", preset))
+}
+
+func TestInsertDeduplicated(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test_dedup")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	ev := Event{
+		Time:    now,
+		Name:    "xid",
+		Type:    string(apiv1.EventTypeFatal),
+		Message: "Xid 79",
+	}
+	require.NoError(t, bucket.Insert(ctx, ev))
+
+	// e.g., retried, or replayed after restarts
+	require.NoError(t, bucket.Insert(ctx, ev))
+
+	// the same event occurred again within the same second
+	again := ev
+	again.Time = now.Add(time.Millisecond)
+	require.NoError(t, bucket.Insert(ctx, again))
+
+	events, err := bucket.Get(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.ElementsMatch(t, []string{DedupKey("test_dedup", ev), DedupKey("test_dedup", again)}, []string{events[0].DedupKey, events[1].DedupKey})
+	assert.Equal(t, events[0].DedupKey, events[0].ToEvent().DedupKey)
+}
@@ -0,0 +1,68 @@
+package eventstore
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var (
+	metricEvents = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "gpud",
+			Subsystem: "eventstore",
+			Name:      "events",
+			Help:      "number of the events in the event store per bucket and event type, as of the last compaction",
+		},
+		[]string{"bucket", "type"},
+	)
+	metricPurgedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "eventstore",
+			Name:      "purged_total",
+			Help:      "total number of the events purged past their retentions per bucket",
+		},
+		[]string{"bucket"},
+	)
+	metricDeduplicatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "eventstore",
+			Name:      "deduplicated_total",
+			Help:      "total number of the events not inserted as the events of the same dedup keys are already stored, per bucket",
+		},
+		[]string{"bucket"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricEvents,
+		metricPurgedTotal,
+		metricDeduplicatedTotal,
+	)
+}
+
+// setEventCounts sets the event counts of the bucket,
+// resetting the counts of the event types no longer in the bucket.
+func setEventCounts(bucket string, counts map[string]int) {
+	metricEvents.DeletePartialMatch(prometheus.Labels{"bucket": bucket})
+	for typ, cnt := range counts {
+		metricEvents.WithLabelValues(bucket, typ).Set(float64(cnt))
+	}
+}
+
+// recordDeduplicated counts the event as deduplicated if the insert affected no row.
+func recordDeduplicated(bucket string, rs sql.Result) error {
+	affected, err := rs.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		metricDeduplicatedTotal.WithLabelValues(bucket).Inc()
+	}
+	return nil
+}
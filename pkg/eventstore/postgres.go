@@ -0,0 +1,454 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/postgres"
+)
+
+// columnBucket represents the normalized component name of the bucket,
+// since all the buckets share the same PostgreSQL table.
+const columnBucket = "bucket"
+
+// postgresTableName is the table shared by all the buckets (and all the GPUd instances)
+// in the PostgreSQL database.
+var postgresTableName = "gpud_events_" + schemaVersion
+
+var (
+	_ Store  = &postgresDatabase{}
+	_ Bucket = &postgresBucket{}
+)
+
+type postgresDatabase struct {
+	db        *sql.DB
+	retention time.Duration
+	policy    *RetentionPolicy
+	machineID string
+}
+
+// NewPostgres creates the event store in the shared PostgreSQL database,
+// with the optional per event type and per bucket retentions.
+// The events are scoped by the machine ID, so each GPUd instance
+// only reads and purges its own events.
+func NewPostgres(db *sql.DB, retention time.Duration, policy *RetentionPolicy, machineID string) (Store, error) {
+	if machineID == "" {
+		return nil, errors.New("machine id is empty")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err := createPostgresTable(ctx, db)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresDatabase{
+		db:        db,
+		retention: retention,
+		policy:    policy,
+		machineID: machineID,
+	}, nil
+}
+
+func (d *postgresDatabase) Bucket(name string, opts ...OpOption) (Bucket, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	retention := d.policy.resolve(name, d.retention)
+	// actual check interval should be lower than the retention period
+	// in case of GPUd restarts
+	purgeInterval := max(retention.minRetention()/5, time.Second)
+	if op.disablePurge {
+		retention = bucketRetention{}
+		purgeInterval = 0
+	}
+
+	rootCtx, rootCancel := context.WithCancel(context.Background())
+	b := &postgresBucket{
+		rootCtx:       rootCtx,
+		rootCancel:    rootCancel,
+		retention:     retention,
+		purgeInterval: purgeInterval,
+		name:          bucketKey(name),
+		db:            d.db,
+		machineID:     d.machineID,
+	}
+	if retention.minRetention() > time.Second {
+		go b.runPurge()
+	}
+	return b, nil
+}
+
+type postgresBucket struct {
+	rootCtx    context.Context
+	rootCancel context.CancelFunc
+
+	retention     bucketRetention
+	purgeInterval time.Duration
+
+	name      string
+	db        *sql.DB
+	machineID string
+}
+
+func (b *postgresBucket) Name() string {
+	return b.name
+}
+
+func (b *postgresBucket) runPurge() {
+	log.Logger.Infow("start purging", "bucket", b.name, "retention", b.retention.fallback, "retentionByType", b.retention.byType, "checkInterval", b.purgeInterval)
+	for {
+		select {
+		case <-b.rootCtx.Done():
+			return
+		case <-time.After(b.purgeInterval):
+		}
+
+		purged, err := b.compact(b.rootCtx, time.Now().UTC())
+		if err != nil {
+			log.Logger.Errorw("failed to purge data", "bucket", b.name, "retention", b.retention.fallback, "error", err)
+		} else {
+			log.Logger.Infow("purged data", "bucket", b.name, "retention", b.retention.fallback, "purged", purged)
+		}
+	}
+}
+
+// compact purges the events of the bucket past their retentions, per event type,
+// and updates the event count metrics of the bucket.
+func (b *postgresBucket) compact(ctx context.Context, now time.Time) (int, error) {
+	scope := fmt.Sprintf("%s = ? AND %s = ?", postgres.ColumnMachineID, columnBucket)
+
+	purged := 0
+	for _, rule := range b.retention.rules(now) {
+		cond, args := rule.condition()
+		query := fmt.Sprintf(`DELETE FROM %s WHERE %s AND %s`, postgresTableName, scope, cond)
+
+		start := time.Now()
+		rs, err := b.db.ExecContext(ctx, postgres.Rebind(query), append([]any{b.machineID, b.name}, args...)...)
+		if err != nil {
+			return purged, err
+		}
+		pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+
+		affected, err := rs.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += int(affected)
+	}
+	metricPurgedTotal.WithLabelValues(b.name).Add(float64(purged))
+
+	query := fmt.Sprintf(`SELECT %s, COUNT(*) FROM %s WHERE %s GROUP BY %s`, columnType, postgresTableName, scope, columnType)
+	start := time.Now()
+	rows, err := b.db.QueryContext(ctx, postgres.Rebind(query), b.machineID, b.name)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return purged, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	counts, err := scanTypeCounts(rows)
+	if err != nil {
+		return purged, err
+	}
+	setEventCounts(b.name, counts)
+
+	return purged, nil
+}
+
+func (b *postgresBucket) Close() {
+	if b.rootCancel != nil {
+		log.Logger.Debugw("closing the store", "bucket", b.name)
+		b.rootCancel()
+	}
+}
+
+func (b *postgresBucket) Insert(ctx context.Context, ev Event) error {
+	return insertPostgresEvent(ctx, b.db, b.machineID, b.name, ev)
+}
+
+// Find returns nil if the event is not found.
+func (b *postgresBucket) Find(ctx context.Context, ev Event) (*Event, error) {
+	query := fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? AND %s = ? AND %s = ? AND %s = ?`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnDedupKey,
+		postgresTableName,
+		postgres.ColumnMachineID, columnBucket, columnTimestamp, columnName, columnType,
+	)
+	params := []any{b.machineID, b.name, ev.Time.Unix(), ev.Name, ev.Type}
+	if ev.Message != "" {
+		query += fmt.Sprintf(" AND %s = ?", columnMessage)
+		params = append(params, ev.Message)
+	}
+
+	start := time.Now()
+	rows, err := b.db.QueryContext(ctx, postgres.Rebind(query), params...)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		event, err := scanRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if compareEvent(event, ev) {
+			return &event, nil
+		}
+	}
+	return nil, rows.Err()
+}
+
+// Get queries the event in the descending order of timestamp (latest event first).
+func (b *postgresBucket) Get(ctx context.Context, since time.Time) (Events, error) {
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s
+FROM %s
+WHERE %s = ? AND %s = ? AND %s > ?
+ORDER BY %s DESC`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnDedupKey,
+		postgresTableName,
+		postgres.ColumnMachineID, columnBucket, columnTimestamp,
+		columnTimestamp,
+	)
+
+	start := time.Now()
+	rows, err := b.db.QueryContext(ctx, postgres.Rebind(query), b.machineID, b.name, since.UTC().Unix())
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var events Events
+	for rows.Next() {
+		event, err := scanRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return events, nil
+}
+
+// Latest queries the latest event, returns nil if no event found.
+func (b *postgresBucket) Latest(ctx context.Context) (*Event, error) {
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s, %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s DESC LIMIT 1`,
+		columnTimestamp, columnName, columnType, columnMessage, columnExtraInfo, columnDedupKey,
+		postgresTableName,
+		postgres.ColumnMachineID, columnBucket,
+		columnTimestamp,
+	)
+
+	start := time.Now()
+	row := b.db.QueryRowContext(ctx, postgres.Rebind(query), b.machineID, b.name)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+
+	ev, err := scanRow(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ev, nil
+}
+
+func (b *postgresBucket) Purge(ctx context.Context, beforeTimestamp int64) (int, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = ? AND %s = ? AND %s < ?`,
+		postgresTableName, postgres.ColumnMachineID, columnBucket, columnTimestamp)
+
+	start := time.Now()
+	rs, err := b.db.ExecContext(ctx, postgres.Rebind(query), b.machineID, b.name, beforeTimestamp)
+	if err != nil {
+		return 0, err
+	}
+	pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+
+	affected, err := rs.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+func createPostgresTable(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s BIGINT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT,
+	%s TEXT,
+	%s TEXT
+);`, postgresTableName,
+		postgres.ColumnMachineID,
+		columnBucket,
+		columnTimestamp,
+		columnName,
+		columnType,
+		columnMessage,
+		columnExtraInfo,
+		columnDedupKey,
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// the keys are unique per machine, as the same events may happen on the different machines
+	// (e.g., the same Xid on the GPUs of the same PCI bus IDs)
+	// (the tables created before the key was introduced are migrated by "pkg/migrations")
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_%s_%s ON %s(%s, %s);`,
+		postgresTableName, postgres.ColumnMachineID, columnDedupKey,
+		postgresTableName, postgres.ColumnMachineID, columnDedupKey))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// all the queries are scoped by the machine and the bucket
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s_%s_%s ON %s(%s, %s, %s);`,
+		postgresTableName, postgres.ColumnMachineID, columnBucket, columnTimestamp,
+		postgresTableName, postgres.ColumnMachineID, columnBucket, columnTimestamp))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertPostgresEvent inserts the event, or skips it if the event of the same dedup key
+// is already stored for the machine.
+func insertPostgresEvent(ctx context.Context, db *sql.DB, machineID string, bucket string, ev Event) error {
+	var extraInfoJSON []byte
+	if ev.ExtraInfo != nil {
+		var err error
+		extraInfoJSON, err = json.Marshal(ev.ExtraInfo)
+		if err != nil {
+			return fmt.Errorf("failed to marshal extra info: %w", err)
+		}
+	}
+
+	start := time.Now()
+	rs, err := db.ExecContext(ctx, postgres.Rebind(fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?) ON CONFLICT (%s, %s) DO NOTHING",
+		postgresTableName,
+		postgres.ColumnMachineID,
+		columnBucket,
+		columnTimestamp,
+		columnName,
+		columnType,
+		columnMessage,
+		columnExtraInfo,
+		columnDedupKey,
+		postgres.ColumnMachineID,
+		columnDedupKey,
+	)),
+		machineID,
+		bucket,
+		ev.Time.Unix(),
+		ev.Name,
+		ev.Type,
+		ev.Message,
+		string(extraInfoJSON),
+		DedupKey(bucket, ev),
+	)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+
+	return recordDeduplicated(bucket, rs)
+}
+
+// MigrateSQLiteToPostgres copies the events of all the buckets in the SQLite database
+// to the shared PostgreSQL table, scoped by the machine ID.
+// The copied events are deduplicated by their keys, so it is safe to run again.
+// Returns the number of the copied events per bucket.
+func MigrateSQLiteToPostgres(ctx context.Context, sqliteDB *sql.DB, pgDB *sql.DB, machineID string) (map[string]int, error) {
+	if machineID == "" {
+		return nil, errors.New("machine id is empty")
+	}
+	if err := createPostgresTable(ctx, pgDB); err != nil {
+		return nil, err
+	}
+
+	buckets, err := listSQLiteBuckets(ctx, sqliteDB)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make(map[string]int, len(buckets))
+	for bucket, tableName := range buckets {
+		evs, err := getEvents(ctx, sqliteDB, tableName, time.Time{})
+		if err != nil {
+			return copied, fmt.Errorf("failed to read events from %q: %w", tableName, err)
+		}
+		for _, ev := range evs {
+			if err := insertPostgresEvent(ctx, pgDB, machineID, bucket, ev); err != nil {
+				return copied, fmt.Errorf("failed to copy events of %q: %w", bucket, err)
+			}
+			copied[bucket]++
+		}
+	}
+	return copied, nil
+}
+
+// listSQLiteBuckets returns the event tables of the current schema in the SQLite database,
+// keyed by the bucket key.
+func listSQLiteBuckets(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	prefix, suffix := "components_", "_events_"+schemaVersion
+
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	buckets := make(map[string]string)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) {
+			buckets[strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)] = name
+		}
+	}
+	return buckets, rows.Err()
+}
@@ -0,0 +1,139 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestListSQLiteBuckets(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	for _, name := range []string{"accelerator-nvidia-xid", "cpu"} {
+		b, err := store.Bucket(name)
+		require.NoError(t, err)
+		defer b.Close()
+	}
+
+	// not an event table of the current schema
+	_, err = dbRW.Exec("CREATE TABLE components_cpu_events_v0_4_0 (timestamp INTEGER)")
+	require.NoError(t, err)
+
+	buckets, err := listSQLiteBuckets(context.Background(), dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		bucketKey("accelerator-nvidia-xid"): defaultTableName("accelerator-nvidia-xid", schemaVersion),
+		bucketKey("cpu"):                    defaultTableName("cpu", schemaVersion),
+	}, buckets)
+}
+
+func TestNewPostgresEmptyMachineID(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPostgres(nil, time.Hour, nil, "")
+	require.Error(t, err)
+}
+
+func TestPostgresBucket(t *testing.T) {
+	db, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	machineID := "test-machine-" + time.Now().Format("20060102150405.000000000")
+
+	store, err := NewPostgres(db, 0, nil, machineID)
+	require.NoError(t, err)
+	b, err := store.Bucket("test-component", WithDisablePurge())
+	require.NoError(t, err)
+	defer b.Close()
+
+	// other machines sharing the same table must not be visible
+	other, err := NewPostgres(db, 0, nil, machineID+"-other")
+	require.NoError(t, err)
+	ob, err := other.Bucket("test-component", WithDisablePurge())
+	require.NoError(t, err)
+	defer ob.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ev := Event{
+		Time:      now,
+		Name:      "test-event",
+		Type:      string(apiv1.EventTypeWarning),
+		Message:   "test message",
+		ExtraInfo: map[string]string{"a": "b"},
+	}
+	require.NoError(t, b.Insert(ctx, ev))
+	require.NoError(t, ob.Insert(ctx, Event{Time: now.Add(time.Minute), Name: "other-event", Type: string(apiv1.EventTypeInfo)}))
+
+	found, err := b.Find(ctx, ev)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "test message", found.Message)
+	assert.Equal(t, map[string]string{"a": "b"}, found.ExtraInfo)
+
+	evs, err := b.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "test-event", evs[0].Name)
+
+	latest, err := b.Latest(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "test-event", latest.Name)
+
+	purged, err := b.Purge(ctx, now.Add(time.Second).Unix())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	evs, err = ob.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 1)
+}
+
+func TestMigrateSQLiteToPostgres(t *testing.T) {
+	db, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	dbRW, dbRO, sqliteCleanup := sqlite.OpenTestDB(t)
+	defer sqliteCleanup()
+
+	ctx := context.Background()
+	machineID := "test-machine-" + time.Now().Format("20060102150405.000000000")
+
+	local, err := New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	lb, err := local.Bucket("test-component", WithDisablePurge())
+	require.NoError(t, err)
+	defer lb.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := range 3 {
+		require.NoError(t, lb.Insert(ctx, Event{Time: now.Add(time.Duration(i) * time.Second), Name: "test-event", Type: string(apiv1.EventTypeInfo)}))
+	}
+
+	copied, err := MigrateSQLiteToPostgres(ctx, dbRO, db, machineID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{bucketKey("test-component"): 3}, copied)
+
+	store, err := NewPostgres(db, 0, nil, machineID)
+	require.NoError(t, err)
+	b, err := store.Bucket("test-component", WithDisablePurge())
+	require.NoError(t, err)
+	defer b.Close()
+
+	evs, err := b.Get(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evs, 3)
+}
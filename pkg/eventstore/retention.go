@@ -0,0 +1,170 @@
+package eventstore
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// RetentionPolicy configures the event retention per event type and per bucket,
+// on top of the store retention which applies to the rest of the events.
+// e.g., keep the Xid events for 90 days and the info events for 7 days:
+//
+//	{"by_bucket": {"accelerator-nvidia-error-xid": "2160h"}, "by_type": {"Info": "168h"}}
+type RetentionPolicy struct {
+	// ByType maps the event type (e.g., "Info", "Fatal") to its retention.
+	ByType map[string]metav1.Duration `json:"by_type,omitempty"`
+	// ByBucket maps the bucket (component) name to its retention,
+	// which takes precedence over the event type retention.
+	ByBucket map[string]metav1.Duration `json:"by_bucket,omitempty"`
+}
+
+var knownEventTypes = map[string]struct{}{
+	string(apiv1.EventTypeUnknown):  {},
+	string(apiv1.EventTypeInfo):     {},
+	string(apiv1.EventTypeWarning):  {},
+	string(apiv1.EventTypeCritical): {},
+	string(apiv1.EventTypeFatal):    {},
+}
+
+// Validate returns an error if the event type is unknown or the retention is less than 1 minute.
+func (p *RetentionPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for typ, d := range p.ByType {
+		if _, ok := knownEventTypes[typ]; !ok {
+			return fmt.Errorf("unknown event type %q", typ)
+		}
+		if d.Duration < time.Minute {
+			return fmt.Errorf("retention of event type %q must be at least 1 minute, got %v", typ, d.Duration)
+		}
+	}
+	for bucket, d := range p.ByBucket {
+		if bucket == "" {
+			return fmt.Errorf("empty bucket name")
+		}
+		if d.Duration < time.Minute {
+			return fmt.Errorf("retention of bucket %q must be at least 1 minute, got %v", bucket, d.Duration)
+		}
+	}
+	return nil
+}
+
+// bucketRetention is the resolved retention policy of a bucket.
+type bucketRetention struct {
+	// fallback is the retention of the event types not in byType.
+	fallback time.Duration
+	byType   map[string]time.Duration
+}
+
+// resolve returns the retention of the bucket, with the store retention as the fallback.
+func (p *RetentionPolicy) resolve(bucket string, retention time.Duration) bucketRetention {
+	if p == nil {
+		return bucketRetention{fallback: retention}
+	}
+	if d, ok := p.ByBucket[bucket]; ok {
+		return bucketRetention{fallback: d.Duration}
+	}
+
+	r := bucketRetention{fallback: retention}
+	if len(p.ByType) > 0 {
+		r.byType = make(map[string]time.Duration, len(p.ByType))
+		for typ, d := range p.ByType {
+			r.byType[typ] = d.Duration
+		}
+	}
+	return r
+}
+
+// MaxRetention returns the longest retention of the events in the bucket,
+// with the store retention as the fallback (e.g., to decide the lookback period of the component).
+func (p *RetentionPolicy) MaxRetention(bucket string, retention time.Duration) time.Duration {
+	r := p.resolve(bucket, retention)
+	m := r.fallback
+	for _, d := range r.byType {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
+
+// minRetention returns the shortest retention of the bucket,
+// to decide the purge interval.
+func (r bucketRetention) minRetention() time.Duration {
+	m := r.fallback
+	for _, d := range r.byType {
+		if m == 0 || d < m {
+			m = d
+		}
+	}
+	return m
+}
+
+// purgeRule deletes the events of the types (or the other types if exclude is true)
+// before the timestamp in unix seconds.
+type purgeRule struct {
+	types   []string
+	exclude bool
+	before  int64
+}
+
+// rules returns the purge rules at the time,
+// one per event type with its own retention, and one for the rest.
+func (r bucketRetention) rules(now time.Time) []purgeRule {
+	types := make([]string, 0, len(r.byType))
+	for typ := range r.byType {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	rules := make([]purgeRule, 0, len(types)+1)
+	for _, typ := range types {
+		rules = append(rules, purgeRule{types: []string{typ}, before: now.Add(-r.byType[typ]).Unix()})
+	}
+	if r.fallback > 0 {
+		rules = append(rules, purgeRule{types: types, exclude: true, before: now.Add(-r.fallback).Unix()})
+	}
+	return rules
+}
+
+// condition returns the SQL condition of the rule (with the "?" placeholders) and its arguments.
+func (pr purgeRule) condition() (string, []any) {
+	cond := fmt.Sprintf("%s < ?", columnTimestamp)
+	args := []any{pr.before}
+	if len(pr.types) == 0 {
+		return cond, args
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(pr.types)), ",")
+	op := "IN"
+	if pr.exclude {
+		op = "NOT IN"
+	}
+	cond += fmt.Sprintf(" AND %s %s (%s)", columnType, op, placeholders)
+	for _, typ := range pr.types {
+		args = append(args, typ)
+	}
+	return cond, args
+}
+
+// scanTypeCounts reads the rows of the event types and their counts.
+func scanTypeCounts(rows *sql.Rows) (map[string]int, error) {
+	counts := make(map[string]int)
+	for rows.Next() {
+		var typ sql.NullString
+		var cnt int
+		if err := rows.Scan(&typ, &cnt); err != nil {
+			return nil, err
+		}
+		counts[typ.String] += cnt
+	}
+	return counts, rows.Err()
+}
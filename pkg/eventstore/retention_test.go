@@ -0,0 +1,194 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	var nilPolicy *RetentionPolicy
+	require.NoError(t, nilPolicy.Validate())
+
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			policy: RetentionPolicy{
+				ByType:   map[string]metav1.Duration{"Info": {Duration: 7 * 24 * time.Hour}},
+				ByBucket: map[string]metav1.Duration{"accelerator-nvidia-error-xid": {Duration: 90 * 24 * time.Hour}},
+			},
+		},
+		{
+			name:    "unknown event type",
+			policy:  RetentionPolicy{ByType: map[string]metav1.Duration{"Debug": {Duration: time.Hour}}},
+			wantErr: true,
+		},
+		{
+			name:    "event type retention too short",
+			policy:  RetentionPolicy{ByType: map[string]metav1.Duration{"Info": {Duration: time.Second}}},
+			wantErr: true,
+		},
+		{
+			name:    "empty bucket name",
+			policy:  RetentionPolicy{ByBucket: map[string]metav1.Duration{"": {Duration: time.Hour}}},
+			wantErr: true,
+		},
+		{
+			name:    "bucket retention too short",
+			policy:  RetentionPolicy{ByBucket: map[string]metav1.Duration{"xid": {}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyResolve(t *testing.T) {
+	var nilPolicy *RetentionPolicy
+	assert.Equal(t, bucketRetention{fallback: time.Hour}, nilPolicy.resolve("a", time.Hour))
+	assert.Equal(t, time.Hour, nilPolicy.MaxRetention("a", time.Hour))
+
+	policy := &RetentionPolicy{
+		ByType:   map[string]metav1.Duration{"Info": {Duration: time.Minute}, "Fatal": {Duration: 3 * time.Hour}},
+		ByBucket: map[string]metav1.Duration{"xid": {Duration: 2 * time.Hour}},
+	}
+
+	// bucket retention applies to all the event types
+	r := policy.resolve("xid", time.Hour)
+	assert.Equal(t, bucketRetention{fallback: 2 * time.Hour}, r)
+	assert.Equal(t, 2*time.Hour, r.minRetention())
+	assert.Equal(t, 2*time.Hour, policy.MaxRetention("xid", time.Hour))
+
+	r = policy.resolve("other", time.Hour)
+	assert.Equal(t, time.Hour, r.fallback)
+	assert.Equal(t, map[string]time.Duration{"Info": time.Minute, "Fatal": 3 * time.Hour}, r.byType)
+	assert.Equal(t, time.Minute, r.minRetention())
+	assert.Equal(t, 3*time.Hour, policy.MaxRetention("other", time.Hour))
+
+	// no store retention
+	assert.Equal(t, time.Minute, policy.resolve("other", 0).minRetention())
+}
+
+func TestBucketRetentionRules(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	r := bucketRetention{
+		fallback: time.Hour,
+		byType:   map[string]time.Duration{"Info": time.Minute, "Fatal": 2 * time.Hour},
+	}
+	rules := r.rules(now)
+	require.Len(t, rules, 3)
+	assert.Equal(t, purgeRule{types: []string{"Fatal"}, before: 10000 - 7200}, rules[0])
+	assert.Equal(t, purgeRule{types: []string{"Info"}, before: 10000 - 60}, rules[1])
+	assert.Equal(t, purgeRule{types: []string{"Fatal", "Info"}, exclude: true, before: 10000 - 3600}, rules[2])
+
+	cond, args := rules[0].condition()
+	assert.Equal(t, "timestamp < ? AND type IN (?)", cond)
+	assert.Equal(t, []any{int64(10000 - 7200), "Fatal"}, args)
+
+	cond, args = rules[2].condition()
+	assert.Equal(t, "timestamp < ? AND type NOT IN (?,?)", cond)
+	assert.Equal(t, []any{int64(10000 - 3600), "Fatal", "Info"}, args)
+
+	// no fallback retention keeps the rest of the events
+	rules = bucketRetention{byType: map[string]time.Duration{"Info": time.Minute}}.rules(now)
+	require.Len(t, rules, 1)
+	assert.Equal(t, []string{"Info"}, rules[0].types)
+
+	rules = bucketRetention{fallback: time.Hour}.rules(now)
+	require.Len(t, rules, 1)
+	cond, args = rules[0].condition()
+	assert.Equal(t, "timestamp < ?", cond)
+	assert.Equal(t, []any{int64(10000 - 3600)}, args)
+}
+
+func TestCompactByEventType(t *testing.T) {
+	t.Parallel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policy := &RetentionPolicy{
+		ByType: map[string]metav1.Duration{
+			"Info":  {Duration: 7 * 24 * time.Hour},
+			"Fatal": {Duration: 90 * 24 * time.Hour},
+		},
+	}
+	store, err := NewWithRetentionPolicy(dbRW, dbRO, 30*24*time.Hour, policy)
+	require.NoError(t, err)
+
+	// disable the background purge to compact explicitly
+	bucket, err := store.Bucket("test-compact-by-type", WithDisablePurge())
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Now().UTC()
+	for _, ev := range []Event{
+		{Time: now.Add(-24 * time.Hour), Name: "info-recent", Type: "Info"},
+		{Time: now.Add(-10 * 24 * time.Hour), Name: "info-old", Type: "Info"},
+		{Time: now.Add(-10 * 24 * time.Hour), Name: "warning-recent", Type: "Warning"},
+		{Time: now.Add(-40 * 24 * time.Hour), Name: "warning-old", Type: "Warning"},
+		{Time: now.Add(-60 * 24 * time.Hour), Name: "fatal-recent", Type: "Fatal"},
+		{Time: now.Add(-100 * 24 * time.Hour), Name: "fatal-old", Type: "Fatal"},
+	} {
+		require.NoError(t, bucket.Insert(ctx, ev))
+	}
+
+	tb := bucket.(*table)
+	tb.retention = 30 * 24 * time.Hour
+	tb.retentionByType = policy.resolve("test-compact-by-type", 30*24*time.Hour).byType
+
+	purged, err := tb.compact(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, purged)
+
+	events, err := bucket.Get(ctx, time.Time{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(events))
+	for _, ev := range events {
+		names = append(names, ev.Name)
+	}
+	assert.Equal(t, []string{"info-recent", "warning-recent", "fatal-recent"}, names)
+
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(metricEvents.WithLabelValues(tb.bucket, "Info")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(metricEvents.WithLabelValues(tb.bucket, "Warning")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(metricEvents.WithLabelValues(tb.bucket, "Fatal")))
+	assert.Equal(t, float64(3), promtestutil.ToFloat64(metricPurgedTotal.WithLabelValues(tb.bucket)))
+
+	// bucket retention overrides the event type retentions
+	store, err = NewWithRetentionPolicy(dbRW, dbRO, time.Hour, &RetentionPolicy{
+		ByType:   map[string]metav1.Duration{"Info": {Duration: time.Minute}},
+		ByBucket: map[string]metav1.Duration{"test-compact-by-bucket": {Duration: 90 * 24 * time.Hour}},
+	})
+	require.NoError(t, err)
+	overridden, err := store.Bucket("test-compact-by-bucket")
+	require.NoError(t, err)
+	defer overridden.Close()
+	assert.Equal(t, 90*24*time.Hour, overridden.(*table).retention)
+	assert.Empty(t, overridden.(*table).retentionByType)
+
+	_, err = NewWithRetentionPolicy(dbRW, dbRO, time.Hour, &RetentionPolicy{ByType: map[string]metav1.Duration{"Debug": {Duration: time.Hour}}})
+	assert.Error(t, err)
+}
@@ -38,6 +38,10 @@ type Event struct {
 
 	// ExtraInfo represents the extra information of the event.
 	ExtraInfo map[string]string
+
+	// DedupKey is the stable deduplication key of the event, enforced unique in the bucket.
+	// Computed by [DedupKey] on insert if empty.
+	DedupKey string
 }
 
 func (e *Event) ToEvent() apiv1.Event {
@@ -47,6 +51,7 @@ func (e *Event) ToEvent() apiv1.Event {
 		Name:      e.Name,
 		Type:      apiv1.EventType(e.Type),
 		Message:   e.Message,
+		DedupKey:  e.DedupKey,
 	}
 }
 
@@ -0,0 +1,254 @@
+// Package exec implements the shared execution of the external commands
+// (e.g., "nvidia-smi", "ibstat", "lspci") across the components.
+//
+// The concurrent callers of the same command share a single invocation,
+// the successful outputs are cached for a short TTL so that the components
+// checked in the same cycle do not run the same command again, and the
+// number of the commands running at the same time is capped.
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultTTL is the default duration the successful command outputs are cached.
+	// Shorter than the check intervals of the components, so that the outputs are only
+	// shared within a check cycle.
+	DefaultTTL = 10 * time.Second
+	// DefaultTimeout is the default timeout of a single command invocation,
+	// independent of the callers' contexts as the invocation is shared.
+	DefaultTimeout = 2 * time.Minute
+	// DefaultMaxConcurrency is the default number of the commands running at the same time.
+	DefaultMaxConcurrency = 4
+)
+
+// Func runs the command and returns the output.
+type Func func(ctx context.Context) ([]byte, error)
+
+type OpOption func(*Op)
+
+type Op struct {
+	ttl            time.Duration
+	timeout        time.Duration
+	maxConcurrency int
+}
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.ttl < 0 {
+		op.ttl = 0
+	}
+	if op.timeout <= 0 {
+		op.timeout = DefaultTimeout
+	}
+	if op.maxConcurrency <= 0 {
+		op.maxConcurrency = DefaultMaxConcurrency
+	}
+}
+
+// WithTTL sets the duration the successful outputs are cached.
+// Zero disables the caching, while the concurrent callers still share the invocation.
+func WithTTL(ttl time.Duration) OpOption {
+	return func(op *Op) {
+		op.ttl = ttl
+	}
+}
+
+// WithTimeout sets the timeout of a single command invocation.
+func WithTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.timeout = timeout
+	}
+}
+
+// WithMaxConcurrency sets the number of the commands running at the same time.
+func WithMaxConcurrency(n int) OpOption {
+	return func(op *Op) {
+		op.maxConcurrency = n
+	}
+}
+
+// Runner runs the external commands with the deduplication, the caching,
+// and the concurrency limit. Safe for concurrent use.
+type Runner struct {
+	ttl     time.Duration
+	timeout time.Duration
+	sem     chan struct{}
+
+	getTimeNowFunc func() time.Time
+
+	mu       sync.Mutex
+	inflight map[string]*call
+	cache    map[string]cachedOutput
+}
+
+// call is a single command invocation shared by the concurrent callers.
+type call struct {
+	// done is closed when the invocation returns
+	done chan struct{}
+	// out and err are set before closing done
+	out []byte
+	err error
+}
+
+type cachedOutput struct {
+	out     []byte
+	expires time.Time
+}
+
+// NewRunner creates a new runner.
+func NewRunner(opts ...OpOption) *Runner {
+	op := &Op{ttl: DefaultTTL}
+	op.applyOpts(opts)
+
+	return &Runner{
+		ttl:            op.ttl,
+		timeout:        op.timeout,
+		sem:            make(chan struct{}, op.maxConcurrency),
+		getTimeNowFunc: func() time.Time { return time.Now().UTC() },
+		inflight:       make(map[string]*call),
+		cache:          make(map[string]cachedOutput),
+	}
+}
+
+// Do returns the output of the function for the key, where the key identifies
+// the command and its arguments (e.g., "lspci -nn").
+//
+// The output is returned from the cache if the same key succeeded within the TTL.
+// Otherwise, the concurrent callers of the same key wait for a single invocation.
+// The errors are returned to all waiting callers, but never cached.
+//
+// The function runs with the context detached from the calling context,
+// bounded by the runner timeout, since other callers may be waiting on it.
+// The caller returns early with its context error if canceled while waiting.
+func (r *Runner) Do(ctx context.Context, key string, fn Func) ([]byte, error) {
+	command := commandLabel(key)
+
+	r.mu.Lock()
+	if c, ok := r.cache[key]; ok {
+		if r.getTimeNowFunc().Before(c.expires) {
+			r.mu.Unlock()
+			metricSharedTotal.WithLabelValues(command, sourceCache).Inc()
+			return c.out, nil
+		}
+		delete(r.cache, key)
+	}
+
+	cl, ok := r.inflight[key]
+	if ok {
+		metricSharedTotal.WithLabelValues(command, sourceInflight).Inc()
+	} else {
+		cl = &call{done: make(chan struct{})}
+		r.inflight[key] = cl
+		go r.invoke(context.WithoutCancel(ctx), key, fn, cl)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-cl.done:
+		return cl.out, cl.err
+	}
+}
+
+// invoke runs the function once the concurrency limit allows,
+// and caches the output if succeeded.
+func (r *Runner) invoke(ctx context.Context, key string, fn Func, cl *call) {
+	command := commandLabel(key)
+
+	cctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	select {
+	case r.sem <- struct{}{}:
+		start := r.getTimeNowFunc()
+		cl.out, cl.err = fn(cctx)
+		<-r.sem
+
+		metricRunsTotal.WithLabelValues(command, resultLabel(cl.err)).Inc()
+		metricRunDurationSeconds.WithLabelValues(command).Observe(r.getTimeNowFunc().Sub(start).Seconds())
+		if cl.err != nil {
+			log.Logger.Debugw("command failed", "command", key, "error", cl.err)
+		}
+	case <-cctx.Done():
+		cl.err = fmt.Errorf("timed out waiting for the concurrency limit: %w", cctx.Err())
+		metricRunsTotal.WithLabelValues(command, resultLabel(cl.err)).Inc()
+	}
+
+	r.mu.Lock()
+	delete(r.inflight, key)
+	if cl.err == nil && r.ttl > 0 {
+		r.cache[key] = cachedOutput{out: cl.out, expires: r.getTimeNowFunc().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	close(cl.done)
+}
+
+// Run runs the command with the arguments, and returns the combined output.
+// The command name and the arguments are the cache key.
+func (r *Runner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	return r.Do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		// #nosec G204 -- the commands are set by the components, not by the API callers.
+		return osexec.CommandContext(ctx, name, args...).CombinedOutput()
+	})
+}
+
+// Purge discards the cached outputs, so that the next calls run the commands again.
+func (r *Runner) Purge() {
+	r.mu.Lock()
+	r.cache = make(map[string]cachedOutput)
+	r.mu.Unlock()
+}
+
+var defaultRunner = NewRunner()
+
+// Default returns the runner shared across the components.
+func Default() *Runner {
+	return defaultRunner
+}
+
+// Do calls Do on the default runner.
+func Do(ctx context.Context, key string, fn Func) ([]byte, error) {
+	return defaultRunner.Do(ctx, key, fn)
+}
+
+// Run calls Run on the default runner.
+func Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return defaultRunner.Run(ctx, name, args...)
+}
+
+// commandLabel returns the executable name of the key as the metric label,
+// to bound the label cardinality (e.g., "lspci" for "/usr/bin/lspci -nn").
+func commandLabel(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+func resultLabel(err error) string {
+	switch {
+	case err == nil:
+		return resultSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return resultTimeout
+	default:
+		return resultError
+	}
+}
@@ -0,0 +1,190 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDeduplicatesConcurrentCalls(t *testing.T) {
+	r := NewRunner(WithTTL(0))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("out"), nil
+	}
+
+	var wg sync.WaitGroup
+	outs := make([][]byte, 5)
+	for i := range outs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out, err := r.Do(context.Background(), "lspci -nn", fn)
+			assert.NoError(t, err)
+			outs[i] = out
+		}(i)
+	}
+
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.inflight) == 1 && calls.Load() == 1
+	}, time.Second, 5*time.Millisecond)
+	// wait for all callers to join the invocation
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, out := range outs {
+		assert.Equal(t, "out", string(out))
+	}
+
+	// no cache with the zero TTL
+	_, err := r.Do(context.Background(), "lspci -nn", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDoCachesSuccessOnly(t *testing.T) {
+	r := NewRunner(WithTTL(time.Minute))
+	now := time.Now()
+	r.getTimeNowFunc = func() time.Time { return now }
+
+	var calls atomic.Int32
+	var fail atomic.Bool
+	fail.Store(true)
+	fn := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		if fail.Load() {
+			return nil, errors.New("ibstat failed")
+		}
+		return []byte("ok"), nil
+	}
+
+	_, err := r.Do(context.Background(), "ibstat", fn)
+	require.Error(t, err)
+	_, err = r.Do(context.Background(), "ibstat", fn)
+	require.Error(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	fail.Store(false)
+	out, err := r.Do(context.Background(), "ibstat", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(out))
+	out, err = r.Do(context.Background(), "ibstat", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(out))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// different key
+	_, err = r.Do(context.Background(), "ibstat -p", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	_, err = r.Do(context.Background(), "ibstat", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), calls.Load())
+
+	r.Purge()
+	_, err = r.Do(context.Background(), "ibstat", fn)
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestDoCallerCanceled(t *testing.T) {
+	r := NewRunner()
+
+	release := make(chan struct{})
+	var fnCtxErr atomic.Value
+	fn := func(ctx context.Context) ([]byte, error) {
+		<-release
+		if ctx.Err() != nil {
+			fnCtxErr.Store(ctx.Err())
+		}
+		return []byte("out"), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := r.Do(ctx, "nvidia-smi", fn)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the invocation keeps running for the other callers, not canceled
+	close(release)
+	out, err := r.Do(context.Background(), "nvidia-smi", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "out", string(out))
+	assert.Nil(t, fnCtxErr.Load())
+}
+
+func TestDoMaxConcurrency(t *testing.T) {
+	r := NewRunner(WithTTL(0), WithMaxConcurrency(2))
+
+	var running, maxRunning atomic.Int32
+	fn := func(context.Context) ([]byte, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, err := r.Do(context.Background(), key, fn)
+			assert.NoError(t, err)
+		}(key)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
+
+func TestDoTimeout(t *testing.T) {
+	r := NewRunner(WithTimeout(20 * time.Millisecond))
+
+	_, err := r.Do(context.Background(), "ipmitool sel elist", func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, resultTimeout, resultLabel(err))
+}
+
+func TestRun(t *testing.T) {
+	r := NewRunner()
+
+	out, err := r.Run(context.Background(), "echo", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(out))
+
+	_, err = r.Run(context.Background(), "/nonexistent-gpud-command")
+	require.Error(t, err)
+}
+
+func TestCommandLabel(t *testing.T) {
+	assert.Equal(t, "lspci", commandLabel("/usr/bin/lspci -nn"))
+	assert.Equal(t, "nvidia-smi", commandLabel("nvidia-smi nvlink --status"))
+	assert.Equal(t, "", commandLabel(""))
+}
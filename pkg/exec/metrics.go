@@ -0,0 +1,58 @@
+package exec
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+	resultTimeout = "timeout"
+
+	// sourceCache is the shared output returned from the cache.
+	sourceCache = "cache"
+	// sourceInflight is the shared output of the running invocation.
+	sourceInflight = "inflight"
+)
+
+var (
+	metricRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "exec",
+			Name:      "runs_total",
+			Help:      "total number of the external command invocations",
+		},
+		[]string{"command", "result"},
+	)
+	metricRunDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "exec",
+			Name:      "run_duration_seconds",
+			Help:      "time taken to run the external command",
+
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 120},
+		},
+		[]string{"command"},
+	)
+	metricSharedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "exec",
+			Name:      "shared_total",
+			Help:      "total number of the external command calls served without a new invocation",
+		},
+		[]string{"command", "source"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricRunsTotal,
+		metricRunDurationSeconds,
+		metricSharedTotal,
+	)
+}
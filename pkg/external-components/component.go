@@ -0,0 +1,306 @@
+package externalcomponents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultCheckInterval is the interval GPUd checks the registered plugins.
+	DefaultCheckInterval = time.Minute
+	// DefaultCallTimeout is the timeout of each call to the plugins.
+	DefaultCallTimeout = 30 * time.Second
+)
+
+var (
+	_ components.Component      = &remoteComponent{}
+	_ components.Deregisterable = &remoteComponent{}
+)
+
+// remoteComponent is the component of a registered plugin,
+// calling the plugin over its unix socket.
+type remoteComponent struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	name   string
+	socket string
+	tags   []string
+	conn   *grpc.ClientConn
+
+	getTimeNowFunc func() time.Time
+
+	heartbeatMu sync.RWMutex
+	heartbeatTs time.Time
+
+	lastMu          sync.RWMutex
+	lastCheckResult *checkResult
+
+	closeOnce sync.Once
+}
+
+func newRemoteComponent(ctx context.Context, name string, socket string, tags []string) (*remoteComponent, error) {
+	conn, err := dialUnix(socket)
+	if err != nil {
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithCancel(ctx)
+	return &remoteComponent{
+		ctx:    cctx,
+		cancel: ccancel,
+		name:   name,
+		socket: socket,
+		tags:   tags,
+		conn:   conn,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}, nil
+}
+
+func (c *remoteComponent) Name() string { return c.name }
+
+func (c *remoteComponent) Tags() []string {
+	return append([]string{"external", c.name}, c.tags...)
+}
+
+func (c *remoteComponent) IsSupported() bool {
+	return true
+}
+
+func (c *remoteComponent) Start() error {
+	go func() {
+		ticker := time.NewTicker(DefaultCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			_ = c.Check()
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *remoteComponent) Check() components.CheckResult {
+	log.Logger.Infow("checking external component", "name", c.name)
+
+	cr := &checkResult{
+		componentName: c.name,
+		ts:            c.getTimeNowFunc(),
+	}
+	defer func() {
+		c.lastMu.Lock()
+		c.lastCheckResult = cr
+		c.lastMu.Unlock()
+	}()
+
+	cctx, ccancel := context.WithTimeout(c.ctx, DefaultCallTimeout)
+	resp, err := invoke[CheckRequest, CheckResponse](cctx, c.conn, ComponentServiceName, "Check", &CheckRequest{})
+	ccancel()
+	if err != nil {
+		cr.err = err
+		cr.health = apiv1.HealthStateTypeUnhealthy
+		cr.reason = "failed to check external component"
+		log.Logger.Warnw(cr.reason, "name", c.name, "socket", c.socket, "error", err)
+		return cr
+	}
+	cr.states = resp.HealthStates
+
+	cr.health = apiv1.HealthStateTypeHealthy
+	for _, st := range cr.states {
+		switch {
+		case st.Health == apiv1.HealthStateTypeUnhealthy:
+			cr.health = apiv1.HealthStateTypeUnhealthy
+		case st.Health != "" && st.Health != apiv1.HealthStateTypeHealthy && cr.health == apiv1.HealthStateTypeHealthy:
+			cr.health = st.Health
+		}
+	}
+	cr.reason = fmt.Sprintf("%d health state(s) reported", len(cr.states))
+
+	// metrics are best-effort, the health states are still reported
+	cctx, ccancel = context.WithTimeout(c.ctx, DefaultCallTimeout)
+	mresp, err := invoke[MetricsRequest, MetricsResponse](cctx, c.conn, ComponentServiceName, "Metrics", &MetricsRequest{})
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to read external component metrics", "name", c.name, "error", err)
+	} else {
+		setRemoteMetrics(c.name, mresp.Metrics)
+	}
+
+	return cr
+}
+
+func (c *remoteComponent) LastHealthStates() apiv1.HealthStates {
+	c.lastMu.RLock()
+	lastCheckResult := c.lastCheckResult
+	c.lastMu.RUnlock()
+	if lastCheckResult == nil {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(time.Now().UTC()),
+				Component: c.name,
+				Name:      c.name,
+				Health:    apiv1.HealthStateTypeHealthy,
+				Reason:    "no data yet",
+			},
+		}
+	}
+	return lastCheckResult.HealthStates()
+}
+
+func (c *remoteComponent) Events(ctx context.Context, since time.Time) (apiv1.Events, error) {
+	cctx, ccancel := context.WithTimeout(ctx, DefaultCallTimeout)
+	defer ccancel()
+
+	resp, err := invoke[EventsRequest, EventsResponse](cctx, c.conn, ComponentServiceName, "Events", &EventsRequest{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	evs := resp.Events
+	for i := range evs {
+		evs[i].Component = c.name
+	}
+	return evs, nil
+}
+
+func (c *remoteComponent) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		log.Logger.Debugw("closing external component", "name", c.name)
+
+		c.cancel()
+		deleteRemoteMetrics(c.name)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *remoteComponent) CanDeregister() bool {
+	return true
+}
+
+func (c *remoteComponent) heartbeat(now time.Time) {
+	c.heartbeatMu.Lock()
+	c.heartbeatTs = now
+	c.heartbeatMu.Unlock()
+}
+
+func (c *remoteComponent) lastHeartbeat() time.Time {
+	c.heartbeatMu.RLock()
+	defer c.heartbeatMu.RUnlock()
+	return c.heartbeatTs
+}
+
+var _ components.CheckResult = &checkResult{}
+
+type checkResult struct {
+	componentName string
+
+	// states is the health states reported by the plugin
+	states apiv1.HealthStates
+
+	// timestamp of the last check
+	ts time.Time
+	// error from the last check
+	err error
+
+	// tracks the healthy evaluation result of the last check
+	health apiv1.HealthStateType
+	// tracks the reason of the last check
+	reason string
+}
+
+func (cr *checkResult) ComponentName() string {
+	return cr.componentName
+}
+
+func (cr *checkResult) String() string {
+	if cr == nil {
+		return ""
+	}
+	if len(cr.states) == 0 {
+		return cr.reason
+	}
+
+	buf := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buf)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	table.SetHeader([]string{"Name", "Health", "Reason"})
+	for _, st := range cr.states {
+		table.Append([]string{st.Name, string(st.Health), st.Reason})
+	}
+	table.Render()
+
+	return buf.String()
+}
+
+func (cr *checkResult) Summary() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.reason
+}
+
+func (cr *checkResult) HealthStateType() apiv1.HealthStateType {
+	if cr == nil {
+		return ""
+	}
+	return cr.health
+}
+
+func (cr *checkResult) getError() string {
+	if cr == nil || cr.err == nil {
+		return ""
+	}
+	return cr.err.Error()
+}
+
+// HealthStates returns the health states reported by the plugin,
+// with the component name and the missing fields set by GPUd.
+func (cr *checkResult) HealthStates() apiv1.HealthStates {
+	if cr.err != nil || len(cr.states) == 0 {
+		return apiv1.HealthStates{
+			{
+				Time:      metav1.NewTime(cr.ts),
+				Component: cr.componentName,
+				Name:      cr.componentName,
+				Reason:    cr.reason,
+				Error:     cr.getError(),
+				Health:    cr.health,
+			},
+		}
+	}
+
+	states := make(apiv1.HealthStates, 0, len(cr.states))
+	for _, st := range cr.states {
+		st.Component = cr.componentName
+		if st.Name == "" {
+			st.Name = cr.componentName
+		}
+		if st.Time.IsZero() {
+			st.Time = metav1.NewTime(cr.ts)
+		}
+		if st.Health == "" {
+			st.Health = apiv1.HealthStateTypeHealthy
+		}
+		states = append(states, st)
+	}
+	return states
+}
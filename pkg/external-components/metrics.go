@@ -0,0 +1,117 @@
+package externalcomponents
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// SubSystem is the Prometheus subsystem name of the plugin metrics.
+const SubSystem = "external_component"
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// remoteMetrics is the latest metrics of each plugin, keyed by the component name.
+var remoteMetrics = &remoteMetricsCollector{
+	metrics: make(map[string][]Metric),
+}
+
+func init() {
+	pkgmetrics.MustRegister(remoteMetrics)
+}
+
+func setRemoteMetrics(componentName string, ms []Metric) {
+	remoteMetrics.mu.Lock()
+	remoteMetrics.metrics[componentName] = ms
+	remoteMetrics.mu.Unlock()
+}
+
+func deleteRemoteMetrics(componentName string) {
+	remoteMetrics.mu.Lock()
+	delete(remoteMetrics.metrics, componentName)
+	remoteMetrics.mu.Unlock()
+}
+
+// remoteMetricsCollector exposes the plugin metrics as the gauges.
+// It is an unchecked collector, as the metrics are only known at the collection.
+type remoteMetricsCollector struct {
+	mu      sync.RWMutex
+	metrics map[string][]Metric
+}
+
+var _ prometheus.Collector = &remoteMetricsCollector{}
+
+func (c *remoteMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *remoteMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	componentNames := make([]string, 0, len(c.metrics))
+	for name := range c.metrics {
+		componentNames = append(componentNames, name)
+	}
+	sort.Strings(componentNames)
+
+	// the same metric name must have the same label names across the plugins,
+	// thus the first label names win
+	labelNamesByMetric := make(map[string]string)
+	for _, componentName := range componentNames {
+		for _, m := range c.metrics[componentName] {
+			labelNames, ok := validLabelNames(m)
+			if !ok {
+				log.Logger.Debugw("skipping invalid external component metric", "component", componentName, "metric", m.Name)
+				continue
+			}
+			key := strings.Join(labelNames, ",")
+			if prev, ok := labelNamesByMetric[m.Name]; ok && prev != key {
+				log.Logger.Debugw("skipping external component metric with inconsistent labels", "component", componentName, "metric", m.Name)
+				continue
+			}
+			labelNamesByMetric[m.Name] = key
+
+			labelValues := make([]string, 0, len(labelNames)+1)
+			labelValues = append(labelValues, componentName)
+			for _, k := range labelNames {
+				labelValues = append(labelValues, m.Labels[k])
+			}
+			desc := prometheus.NewDesc(
+				prometheus.BuildFQName("", SubSystem, m.Name),
+				"tracks the metric reported by the external component",
+				append([]string{pkgmetrics.MetricComponentLabelKey}, labelNames...),
+				nil,
+			)
+			pm, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.Value, labelValues...)
+			if err != nil {
+				log.Logger.Debugw("skipping invalid external component metric", "component", componentName, "metric", m.Name, "error", err)
+				continue
+			}
+			ch <- pm
+		}
+	}
+}
+
+// validLabelNames returns the sorted label names, or false if the metric is invalid.
+func validLabelNames(m Metric) ([]string, bool) {
+	if !metricNamePattern.MatchString(m.Name) {
+		return nil, false
+	}
+	names := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		if !labelNamePattern.MatchString(k) || k == pkgmetrics.MetricComponentLabelKey || strings.HasPrefix(k, "__") {
+			return nil, false
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, true
+}
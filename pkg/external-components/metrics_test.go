@@ -0,0 +1,58 @@
+package externalcomponents
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidLabelNames(t *testing.T) {
+	names, ok := validLabelNames(Metric{Name: "fan_rpm", Labels: map[string]string{"z": "1", "a": "2"}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "z"}, names)
+
+	_, ok = validLabelNames(Metric{Name: "fan-rpm"})
+	assert.False(t, ok)
+	_, ok = validLabelNames(Metric{Name: "fan_rpm", Labels: map[string]string{"gpud_component": "x"}})
+	assert.False(t, ok)
+	_, ok = validLabelNames(Metric{Name: "fan_rpm", Labels: map[string]string{"__name": "x"}})
+	assert.False(t, ok)
+	_, ok = validLabelNames(Metric{Name: "fan_rpm", Labels: map[string]string{"bad-label": "x"}})
+	assert.False(t, ok)
+}
+
+func TestRemoteMetricsCollector(t *testing.T) {
+	c := &remoteMetricsCollector{metrics: map[string][]Metric{
+		"external-a": {
+			{Name: "temp", Labels: map[string]string{"sensor": "0"}, Value: 40},
+			{Name: "bad-name", Value: 1},
+		},
+		"external-b": {
+			// inconsistent with the labels of "external-a"
+			{Name: "temp", Labels: map[string]string{"zone": "1"}, Value: 50},
+			{Name: "temp", Labels: map[string]string{"sensor": "1"}, Value: 60},
+		},
+	}}
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c))
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	assert.Equal(t, "external_component_temp", mfs[0].GetName())
+	require.Len(t, mfs[0].GetMetric(), 2)
+
+	values := make(map[string]float64)
+	for _, m := range mfs[0].GetMetric() {
+		var comp string
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == "gpud_component" {
+				comp = lp.GetValue()
+			}
+		}
+		values[comp] = m.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"external-a": 40, "external-b": 60}, values)
+}
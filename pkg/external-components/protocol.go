@@ -0,0 +1,172 @@
+// Package externalcomponents implements the external component protocol,
+// for the out-of-process components (plugins) to report to GPUd.
+//
+// The protocol is gRPC over the unix sockets, with the JSON-encoded messages
+// (content-subtype "json", i.e., "application/grpc+json"), so that the plugins
+// can be written in any language without the generated protobuf stubs.
+//
+// The plugin serves the "gpud.external.v1.Component" service (Check, Events, Metrics)
+// on its own unix socket, and registers the socket with the GPUd
+// "gpud.external.v1.Registry" service. GPUd then supervises the plugin as a regular
+// component, while the plugin keeps re-registering as the heartbeat.
+// See Serve for the typed Go SDK.
+package externalcomponents
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// ComponentServiceName is the gRPC service served by the plugins.
+	ComponentServiceName = "gpud.external.v1.Component"
+	// RegistryServiceName is the gRPC service served by GPUd.
+	RegistryServiceName = "gpud.external.v1.Registry"
+
+	// CodecName is the gRPC content-subtype of the JSON-encoded messages.
+	CodecName = "json"
+)
+
+// CheckRequest is the request of the Component/Check method.
+type CheckRequest struct{}
+
+// CheckResponse is the response of the Component/Check method.
+type CheckResponse struct {
+	// HealthStates is the health states of the plugin as of the check.
+	// The component name is set by GPUd.
+	HealthStates apiv1.HealthStates `json:"health_states,omitempty"`
+}
+
+// EventsRequest is the request of the Component/Events method.
+type EventsRequest struct {
+	// Since is the time to return the events from.
+	Since time.Time `json:"since"`
+}
+
+// EventsResponse is the response of the Component/Events method.
+type EventsResponse struct {
+	Events apiv1.Events `json:"events,omitempty"`
+}
+
+// MetricsRequest is the request of the Component/Metrics method.
+type MetricsRequest struct{}
+
+// MetricsResponse is the response of the Component/Metrics method.
+type MetricsResponse struct {
+	Metrics []Metric `json:"metrics,omitempty"`
+}
+
+// Metric is the current value of a plugin gauge.
+// GPUd exposes it as "external_component_<name>", with the "gpud_component" label.
+// The metrics of the same name must have the same label names.
+type Metric struct {
+	// Name is the metric name, in the Prometheus metric name format.
+	Name string `json:"name"`
+	// Labels is the metric labels, in the Prometheus label name format.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Value is the current value.
+	Value float64 `json:"value"`
+}
+
+// RegisterRequest is the request of the Registry/Register method.
+type RegisterRequest struct {
+	// Name is the plugin name, registered as the "external-<name>" component.
+	Name string `json:"name"`
+	// Socket is the absolute path of the unix socket the plugin serves on.
+	Socket string `json:"socket"`
+	// Tags is the additional component tags.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RegisterResponse is the response of the Registry/Register method.
+type RegisterResponse struct {
+	// ComponentName is the name of the registered component.
+	ComponentName string `json:"component_name"`
+	// Expiry is how long the registration lasts without another Register call.
+	Expiry time.Duration `json:"expiry"`
+}
+
+// DeregisterRequest is the request of the Registry/Deregister method.
+type DeregisterRequest struct {
+	Name string `json:"name"`
+}
+
+// DeregisterResponse is the response of the Registry/Deregister method.
+type DeregisterResponse struct{}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func (jsonCodec) Name() string { return CodecName }
+
+// componentService is the plugin side of the protocol.
+type componentService interface {
+	check(ctx context.Context, req *CheckRequest) (*CheckResponse, error)
+	events(ctx context.Context, req *EventsRequest) (*EventsResponse, error)
+	metrics(ctx context.Context, req *MetricsRequest) (*MetricsResponse, error)
+}
+
+var componentServiceDesc = grpc.ServiceDesc{
+	ServiceName: ComponentServiceName,
+	HandlerType: (*componentService)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Check", Handler: unaryHandler(ComponentServiceName, "Check", componentService.check)},
+		{MethodName: "Events", Handler: unaryHandler(ComponentServiceName, "Events", componentService.events)},
+		{MethodName: "Metrics", Handler: unaryHandler(ComponentServiceName, "Metrics", componentService.metrics)},
+	},
+}
+
+// registryService is the GPUd side of the protocol.
+type registryService interface {
+	register(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error)
+	deregister(ctx context.Context, req *DeregisterRequest) (*DeregisterResponse, error)
+}
+
+var registryServiceDesc = grpc.ServiceDesc{
+	ServiceName: RegistryServiceName,
+	HandlerType: (*registryService)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Register", Handler: unaryHandler(RegistryServiceName, "Register", registryService.register)},
+		{MethodName: "Deregister", Handler: unaryHandler(RegistryServiceName, "Deregister", registryService.deregister)},
+	},
+}
+
+// unaryHandler returns the gRPC handler of the method, as generated by protoc-gen-go-grpc.
+func unaryHandler[S any, Req any, Resp any](service, method string, call func(S, context.Context, *Req) (*Resp, error)) grpc.MethodHandler {
+	fullMethod := "/" + service + "/" + method
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(S), ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return call(srv.(S), ctx, req.(*Req))
+		})
+	}
+}
+
+// invoke calls the unary method with the JSON-encoded messages.
+func invoke[Req any, Resp any](ctx context.Context, conn *grpc.ClientConn, service, method string, req *Req) (*Resp, error) {
+	resp := new(Resp)
+	if err := conn.Invoke(ctx, "/"+service+"/"+method, req, resp, grpc.CallContentSubtype(CodecName)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
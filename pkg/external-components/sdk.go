@@ -0,0 +1,191 @@
+package externalcomponents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultSocketPath is the default unix socket of the GPUd registry,
+	// under the default data directory.
+	DefaultSocketPath = "/var/lib/gpud/external-components.sock"
+
+	// DefaultRegisterInterval is the default interval of the plugin to re-register,
+	// as the heartbeat to GPUd.
+	DefaultRegisterInterval = 30 * time.Second
+)
+
+// Component is implemented by the plugins written in Go.
+type Component interface {
+	// Check checks the plugin once, and returns the health states.
+	Check(ctx context.Context) (apiv1.HealthStates, error)
+	// Events returns all the events from "since".
+	Events(ctx context.Context, since time.Time) (apiv1.Events, error)
+	// Metrics returns the current values of the plugin gauges.
+	Metrics(ctx context.Context) ([]Metric, error)
+}
+
+type Op struct {
+	socket           string
+	gpudSocket       string
+	registerInterval time.Duration
+	tags             []string
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(name string, opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	if op.socket == "" {
+		op.socket = filepath.Join(os.TempDir(), "gpud-external-"+name+".sock")
+	}
+	if op.gpudSocket == "" {
+		op.gpudSocket = DefaultSocketPath
+	}
+	if op.registerInterval == 0 {
+		op.registerInterval = DefaultRegisterInterval
+	}
+}
+
+// WithSocket sets the unix socket for the plugin to serve on.
+func WithSocket(socket string) OpOption {
+	return func(op *Op) {
+		op.socket = socket
+	}
+}
+
+// WithGPUdSocket sets the unix socket of the GPUd registry.
+func WithGPUdSocket(socket string) OpOption {
+	return func(op *Op) {
+		op.gpudSocket = socket
+	}
+}
+
+// WithRegisterInterval sets the interval to re-register with GPUd.
+func WithRegisterInterval(interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.registerInterval = interval
+	}
+}
+
+// WithTags sets the additional component tags.
+func WithTags(tags ...string) OpOption {
+	return func(op *Op) {
+		op.tags = tags
+	}
+}
+
+// Serve serves the plugin component on its unix socket, and registers it
+// with GPUd until the context is canceled. GPUd restarts are tolerated,
+// as the plugin re-registers every register interval.
+func Serve(ctx context.Context, name string, comp Component, opts ...OpOption) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	op := &Op{}
+	op.applyOpts(name, opts)
+
+	socket, err := filepath.Abs(op.socket)
+	if err != nil {
+		return err
+	}
+	// remove the stale socket of the previous run
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&componentServiceDesc, &componentServer{comp: comp})
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Logger.Warnw("external component server stopped", "name", name, "error", err)
+		}
+	}()
+	defer srv.Stop()
+
+	conn, err := dialUnix(op.gpudSocket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := &RegisterRequest{Name: name, Socket: socket, Tags: op.tags}
+	ticker := time.NewTicker(op.registerInterval)
+	defer ticker.Stop()
+	for {
+		cctx, ccancel := context.WithTimeout(ctx, 10*time.Second)
+		_, err := invoke[RegisterRequest, RegisterResponse](cctx, conn, RegistryServiceName, "Register", req)
+		ccancel()
+		if err != nil {
+			log.Logger.Warnw("failed to register external component", "name", name, "gpudSocket", op.gpudSocket, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			dctx, dcancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, err := invoke[DeregisterRequest, DeregisterResponse](dctx, conn, RegistryServiceName, "Deregister", &DeregisterRequest{Name: name})
+			dcancel()
+			if err != nil {
+				log.Logger.Warnw("failed to deregister external component", "name", name, "error", err)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func dialUnix(socket string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %q: %w", socket, err)
+	}
+	return conn, nil
+}
+
+var _ componentService = &componentServer{}
+
+type componentServer struct {
+	comp Component
+}
+
+func (s *componentServer) check(ctx context.Context, _ *CheckRequest) (*CheckResponse, error) {
+	states, err := s.comp.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckResponse{HealthStates: states}, nil
+}
+
+func (s *componentServer) events(ctx context.Context, req *EventsRequest) (*EventsResponse, error) {
+	evs, err := s.comp.Events(ctx, req.Since)
+	if err != nil {
+		return nil, err
+	}
+	return &EventsResponse{Events: evs}, nil
+}
+
+func (s *componentServer) metrics(ctx context.Context, _ *MetricsRequest) (*MetricsResponse, error) {
+	ms, err := s.comp.Metrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricsResponse{Metrics: ms}, nil
+}
@@ -0,0 +1,223 @@
+package externalcomponents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// ComponentNamePrefix prefixes the plugin names, to not conflict with the built-in components.
+	ComponentNamePrefix = "external-"
+
+	// DefaultRegistrationExpiry is the default time for the registration to last
+	// without the heartbeat, before GPUd deregisters the plugin.
+	DefaultRegistrationExpiry = 3 * DefaultRegisterInterval
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+func validateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid external component name %q (must be lowercase alphanumeric or '-')", name)
+	}
+	return nil
+}
+
+// ComponentName returns the name of the component registered by the plugin.
+func ComponentName(name string) string {
+	return ComponentNamePrefix + name
+}
+
+// Server is the GPUd registry of the external components.
+// The plugins registered are supervised in the components registry,
+// and deregistered once the registration expires.
+type Server struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	socket   string
+	registry components.Registry
+	expiry   time.Duration
+
+	getTimeNowFunc func() time.Time
+
+	srv *grpc.Server
+
+	mu      sync.Mutex
+	remotes map[string]*remoteComponent
+}
+
+// NewServer creates the registry server on the unix socket,
+// registering the plugins to the components registry.
+func NewServer(ctx context.Context, socket string, registry components.Registry) *Server {
+	cctx, ccancel := context.WithCancel(ctx)
+	return &Server{
+		ctx:      cctx,
+		cancel:   ccancel,
+		socket:   socket,
+		registry: registry,
+		expiry:   DefaultRegistrationExpiry,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		remotes: make(map[string]*remoteComponent),
+	}
+}
+
+// Start listens on the socket, and starts deregistering the expired plugins.
+func (s *Server) Start() error {
+	if err := os.MkdirAll(filepath.Dir(s.socket), 0755); err != nil {
+		return err
+	}
+	// remove the stale socket of the previous run
+	if err := os.Remove(s.socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lis, err := net.Listen("unix", s.socket)
+	if err != nil {
+		return err
+	}
+	// only the root (or the same user) plugins may register
+	if err := os.Chmod(s.socket, 0600); err != nil {
+		_ = lis.Close()
+		return err
+	}
+
+	s.srv = grpc.NewServer()
+	s.srv.RegisterService(&registryServiceDesc, s)
+	go func() {
+		if err := s.srv.Serve(lis); err != nil {
+			log.Logger.Warnw("external components server stopped", "error", err)
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.deregisterExpired()
+			}
+		}
+	}()
+
+	log.Logger.Infow("serving external components", "socket", s.socket)
+	return nil
+}
+
+// Stop stops the server. The registered plugins are closed with the components registry.
+func (s *Server) Stop() {
+	s.cancel()
+	if s.srv != nil {
+		s.srv.Stop()
+	}
+}
+
+var _ registryService = &Server{}
+
+func (s *Server) register(_ context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+	if err := validateName(req.Name); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !filepath.IsAbs(req.Socket) {
+		return nil, status.Errorf(codes.InvalidArgument, "socket %q must be an absolute path", req.Socket)
+	}
+	name := ComponentName(req.Name)
+	resp := &RegisterResponse{ComponentName: name, Expiry: s.expiry}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.getTimeNowFunc()
+	if rc, ok := s.remotes[name]; ok {
+		if rc.socket == req.Socket && s.registry.Get(name) == components.Component(rc) {
+			rc.heartbeat(now)
+			return resp, nil
+		}
+		// the plugin restarted on another socket, or was deregistered via the API
+		log.Logger.Infow("re-registering external component", "name", name, "from", rc.socket, "to", req.Socket)
+		s.removeLocked(name)
+	} else if s.registry.Get(name) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "component %q is already registered", name)
+	}
+
+	rc, err := newRemoteComponent(s.ctx, name, req.Socket, req.Tags)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	rc.heartbeat(now)
+	if _, err := s.registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+		return rc, nil
+	}); err != nil {
+		_ = rc.Close()
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err := rc.Start(); err != nil {
+		_ = s.registry.Deregister(name)
+		_ = rc.Close()
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.remotes[name] = rc
+
+	log.Logger.Infow("registered external component", "name", name, "socket", req.Socket)
+	return resp, nil
+}
+
+func (s *Server) deregister(_ context.Context, req *DeregisterRequest) (*DeregisterResponse, error) {
+	name := ComponentName(req.Name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.remotes[name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "external component %q is not registered", name)
+	}
+	s.removeLocked(name)
+	log.Logger.Infow("deregistered external component", "name", name)
+	return &DeregisterResponse{}, nil
+}
+
+// deregisterExpired deregisters the plugins without the heartbeat within the expiry.
+func (s *Server) deregisterExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.getTimeNowFunc()
+	for name, rc := range s.remotes {
+		if now.Sub(rc.lastHeartbeat()) > s.expiry {
+			log.Logger.Warnw("external component registration expired", "name", name, "lastHeartbeat", rc.lastHeartbeat())
+			s.removeLocked(name)
+		}
+	}
+}
+
+// removeLocked deregisters and closes the plugin, the caller must hold the lock.
+func (s *Server) removeLocked(name string) {
+	rc := s.remotes[name]
+	delete(s.remotes, name)
+
+	// the plugin may have been deregistered already (e.g., via the API)
+	if s.registry.Get(name) == components.Component(rc) {
+		_ = s.registry.Deregister(name)
+	}
+	if err := rc.Close(); err != nil {
+		log.Logger.Warnw("failed to close external component", "name", name, "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package externalcomponents
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+type mockComponent struct {
+	states  apiv1.HealthStates
+	events  apiv1.Events
+	metrics []Metric
+}
+
+func (m *mockComponent) Check(context.Context) (apiv1.HealthStates, error) {
+	return m.states, nil
+}
+
+func (m *mockComponent) Events(context.Context, time.Time) (apiv1.Events, error) {
+	return m.events, nil
+}
+
+func (m *mockComponent) Metrics(context.Context) ([]Metric, error) {
+	return m.metrics, nil
+}
+
+// tempDir returns a short directory, as the unix socket paths are limited to 108 bytes.
+func tempDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "ext")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func newTestServer(t *testing.T, ctx context.Context) (*Server, components.Registry) {
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	s := NewServer(ctx, filepath.Join(tempDir(t), "gpud.sock"), registry)
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+	return s, registry
+}
+
+func TestServeRegistersAndDeregisters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, registry := newTestServer(t, ctx)
+
+	mock := &mockComponent{
+		states: apiv1.HealthStates{
+			{Name: "fan", Health: apiv1.HealthStateTypeDegraded, Reason: "fan slow"},
+		},
+		events: apiv1.Events{
+			{Name: "fan_stalled", Type: apiv1.EventTypeWarning, Message: "fan 0 stalled"},
+		},
+		metrics: []Metric{
+			{Name: "fan_rpm", Labels: map[string]string{"fan": "0"}, Value: 1200},
+		},
+	}
+
+	pctx, pcancel := context.WithCancel(ctx)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Serve(pctx, "fans", mock,
+			WithSocket(filepath.Join(tempDir(t), "fans.sock")),
+			WithGPUdSocket(s.socket),
+			WithRegisterInterval(100*time.Millisecond),
+			WithTags("hardware"),
+		)
+	}()
+
+	var comp components.Component
+	require.Eventually(t, func() bool {
+		comp = registry.Get("external-fans")
+		return comp != nil
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, []string{"external", "external-fans", "hardware"}, comp.Tags())
+
+	cr := comp.Check()
+	assert.Equal(t, apiv1.HealthStateTypeDegraded, cr.HealthStateType())
+	assert.Equal(t, "1 health state(s) reported", cr.Summary())
+
+	states := cr.HealthStates()
+	require.Len(t, states, 1)
+	assert.Equal(t, "external-fans", states[0].Component)
+	assert.Equal(t, "fan", states[0].Name)
+	assert.Equal(t, "fan slow", states[0].Reason)
+	assert.False(t, states[0].Time.IsZero())
+
+	evs, err := comp.Events(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "external-fans", evs[0].Component)
+	assert.Equal(t, "fan_stalled", evs[0].Name)
+
+	remoteMetrics.mu.RLock()
+	ms := remoteMetrics.metrics["external-fans"]
+	remoteMetrics.mu.RUnlock()
+	require.Len(t, ms, 1)
+	assert.Equal(t, "fan_rpm", ms[0].Name)
+
+	pcancel()
+	require.NoError(t, <-errc)
+	assert.Nil(t, registry.Get("external-fans"))
+
+	remoteMetrics.mu.RLock()
+	_, ok := remoteMetrics.metrics["external-fans"]
+	remoteMetrics.mu.RUnlock()
+	assert.False(t, ok)
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	s := NewServer(ctx, "/tmp/unused.sock", registry)
+
+	_, err := s.register(ctx, &RegisterRequest{Name: "Bad_Name", Socket: "/tmp/x.sock"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.register(ctx, &RegisterRequest{Name: "good", Socket: "relative.sock"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.deregister(ctx, &DeregisterRequest{Name: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRegisterHeartbeatAndExpiry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	s := NewServer(ctx, "/tmp/unused.sock", registry)
+	defer s.Stop()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.getTimeNowFunc = func() time.Time { return now }
+
+	// the plugin socket is not dialed until the first call
+	socket := filepath.Join(tempDir(t), "p.sock")
+	resp, err := s.register(ctx, &RegisterRequest{Name: "p", Socket: socket})
+	require.NoError(t, err)
+	assert.Equal(t, "external-p", resp.ComponentName)
+	assert.Equal(t, DefaultRegistrationExpiry, resp.Expiry)
+	first := registry.Get("external-p")
+	require.NotNil(t, first)
+
+	// heartbeat keeps the same component
+	now = now.Add(DefaultRegisterInterval)
+	_, err = s.register(ctx, &RegisterRequest{Name: "p", Socket: socket})
+	require.NoError(t, err)
+	assert.Equal(t, first, registry.Get("external-p"))
+
+	s.deregisterExpired()
+	assert.NotNil(t, registry.Get("external-p"))
+
+	now = now.Add(DefaultRegistrationExpiry + time.Second)
+	s.deregisterExpired()
+	assert.Nil(t, registry.Get("external-p"))
+	assert.Empty(t, s.remotes)
+}
+
+func TestRegisterAfterAPIDeregister(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	s := NewServer(ctx, "/tmp/unused.sock", registry)
+	defer s.Stop()
+
+	socket := filepath.Join(tempDir(t), "p.sock")
+	_, err := s.register(ctx, &RegisterRequest{Name: "p", Socket: socket})
+	require.NoError(t, err)
+
+	// deregistered by the operator, the next heartbeat registers again
+	require.NotNil(t, registry.Deregister("external-p"))
+	_, err = s.register(ctx, &RegisterRequest{Name: "p", Socket: socket})
+	require.NoError(t, err)
+	assert.NotNil(t, registry.Get("external-p"))
+}
+
+func TestRegisterConflictsWithBuiltin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	s := NewServer(ctx, "/tmp/unused.sock", registry)
+	defer s.Stop()
+
+	other, err := newRemoteComponent(ctx, "external-p", "/tmp/other.sock", nil)
+	require.NoError(t, err)
+	defer func() { _ = other.Close() }()
+	_, err = registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+		return other, nil
+	})
+	require.NoError(t, err)
+
+	_, err = s.register(ctx, &RegisterRequest{Name: "p", Socket: "/tmp/p.sock"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
@@ -4,6 +4,7 @@ package faultinjector
 import (
 	"errors"
 
+	sxidquery "github.com/leptonai/gpud/components/accelerator/nvidia/sxid"
 	xidquery "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
 	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
 )
@@ -32,6 +33,9 @@ type Request struct {
 	// XID is the XID to inject.
 	XID *XIDToInject `json:"xid,omitempty"`
 
+	// SXID is the NVSwitch SXID to inject.
+	SXID *SXIDToInject `json:"sxid,omitempty"`
+
 	// KernelMessage is the kernel message to inject.
 	KernelMessage *pkgkmsgwriter.KernelMessage `json:"kernel_message,omitempty"`
 }
@@ -40,10 +44,28 @@ type XIDToInject struct {
 	ID int `json:"id"`
 }
 
+type SXIDToInject struct {
+	ID int `json:"id"`
+}
+
 var ErrNoFaultFound = errors.New("no fault injection entry found")
 
 func (r *Request) Validate() error {
 	switch {
+	case r.SXID != nil:
+		if r.SXID.ID == 0 {
+			return ErrNoFaultFound
+		}
+
+		msg := sxidquery.GetMessageToInject(r.SXID.ID)
+		r.KernelMessage = &pkgkmsgwriter.KernelMessage{
+			Priority: pkgkmsgwriter.ConvertKernelMessagePriority(msg.Priority),
+			Message:  msg.Message,
+		}
+		r.SXID = nil
+
+		return r.KernelMessage.Validate()
+
 	case r.XID != nil:
 		if r.XID.ID == 0 {
 			return ErrNoFaultFound
@@ -377,6 +377,28 @@ func TestRequest_Validate_XidTransformationDetails(t *testing.T) {
 	require.Contains(t, request.KernelMessage.Message, "pid=34566")
 }
 
+// TestRequest_Validate_SXidTransformation tests the transformation from SXID to KernelMessage
+func TestRequest_Validate_SXidTransformation(t *testing.T) {
+	request := Request{
+		SXID: &SXIDToInject{
+			ID: 12028, // Known non-fatal SXid
+		},
+	}
+
+	err := request.Validate()
+	require.NoError(t, err)
+
+	require.Nil(t, request.SXID, "SXid should be nil after transformation")
+	require.NotNil(t, request.KernelMessage, "KernelMessage should be created")
+	require.Equal(t, pkgkmsgwriter.KernelMessagePriorityWarning, request.KernelMessage.Priority)
+	require.Contains(t, request.KernelMessage.Message, "SXid (PCI:0000:05:00.0): 12028, Non-fatal")
+
+	// SXid with ID 0 is not a fault
+	request = Request{SXID: &SXIDToInject{ID: 0}}
+	require.ErrorIs(t, request.Validate(), ErrNoFaultFound)
+	require.NotNil(t, request.SXID)
+}
+
 // TestRequest_Validate_SwitchCaseFallthrough tests the switch statement logic explicitly
 func TestRequest_Validate_SwitchCaseFallthrough(t *testing.T) {
 	tests := []struct {
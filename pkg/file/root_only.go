@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// CheckRootOnly returns an error describing the mode or the owner of the file
+// if the file is accessible by the group or others, or not owned by root
+// (e.g., the files of the secrets and the tokens).
+func CheckRootOnly(fi os.FileInfo) error {
+	if fi.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("mode %s", fi.Mode().Perm())
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && st.Uid != 0 {
+		return fmt.Errorf("uid %d", st.Uid)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRootOnly(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "secrets")
+	require.NoError(t, os.WriteFile(p, []byte("secret"), 0o644))
+	require.NoError(t, os.Chmod(p, 0o644))
+
+	fi, err := os.Stat(p)
+	require.NoError(t, err)
+	assert.EqualError(t, CheckRootOnly(fi), "mode -rw-r--r--")
+
+	require.NoError(t, os.Chmod(p, 0o600))
+	fi, err = os.Stat(p)
+	require.NoError(t, err)
+	if os.Geteuid() == 0 {
+		assert.NoError(t, CheckRootOnly(fi))
+	} else {
+		assert.EqualError(t, CheckRootOnly(fi), "uid "+strconv.Itoa(os.Geteuid()))
+	}
+}
@@ -0,0 +1,132 @@
+package gossip
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultBindPort is the default gossip port (TCP and UDP),
+	// same as the memberlist default.
+	DefaultBindPort = 7946
+	// DefaultInterval is the default interval to summarize the local health
+	// and broadcast it to the peers.
+	DefaultInterval = 30 * time.Second
+	// DefaultDeadPeerRetention is the default duration to keep reporting
+	// the dead peers before forgetting them.
+	DefaultDeadPeerRetention = time.Hour
+)
+
+// Config configures the peer gossip between the GPUd instances in a cluster.
+type Config struct {
+	// BindAddr is the address to listen on for the gossip (defaults to all interfaces).
+	BindAddr string `json:"bind_addr,omitempty"`
+	// BindPort is the port to listen on for the gossip (defaults to 7946),
+	// or -1 to pick a random port.
+	BindPort int `json:"bind_port,omitempty"`
+
+	// AdvertiseAddr is the address advertised to the peers,
+	// defaults to the first private address of the host.
+	AdvertiseAddr string `json:"advertise_addr,omitempty"`
+	// AdvertisePort is the port advertised to the peers, defaults to the bind port.
+	AdvertisePort int `json:"advertise_port,omitempty"`
+
+	// Join is the list of peer addresses ("host" or "host:port") to join the cluster.
+	// Any one reachable peer is enough, and the join is retried until it succeeds.
+	// Leave empty for the first node, for the others to join it.
+	Join []string `json:"join,omitempty"`
+
+	// SecretKey is the base64-encoded key of 16, 24, or 32 bytes
+	// to encrypt and authenticate the gossip (AES-128, AES-192, or AES-256).
+	// Required, since the peers otherwise accept the health summaries
+	// (and the joins) from anyone reaching the gossip port.
+	SecretKey string `json:"secret_key,omitempty"`
+
+	// Interval is the interval to summarize the local health
+	// and broadcast it to the peers (defaults to 30 seconds).
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// DeadPeerRetention is the duration to keep reporting the dead peers
+	// as unhealthy before forgetting them (defaults to 1 hour).
+	DeadPeerRetention metav1.Duration `json:"dead_peer_retention,omitempty"`
+}
+
+// Validate validates the gossip config.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.BindAddr != "" && net.ParseIP(cfg.BindAddr) == nil {
+		return fmt.Errorf("bind_addr %q is not an ip address", cfg.BindAddr)
+	}
+	if cfg.BindPort < -1 || cfg.BindPort > 65535 {
+		return fmt.Errorf("bind_port must be between -1 and 65535, got %d", cfg.BindPort)
+	}
+	if cfg.AdvertiseAddr != "" && net.ParseIP(cfg.AdvertiseAddr) == nil {
+		return fmt.Errorf("advertise_addr %q is not an ip address", cfg.AdvertiseAddr)
+	}
+	if cfg.AdvertisePort < 0 || cfg.AdvertisePort > 65535 {
+		return fmt.Errorf("advertise_port must be between 0 and 65535, got %d", cfg.AdvertisePort)
+	}
+	for _, addr := range cfg.Join {
+		if addr == "" {
+			return fmt.Errorf("join address must not be empty")
+		}
+	}
+	if _, err := cfg.secretKey(); err != nil {
+		return err
+	}
+	if cfg.Interval.Duration < 0 {
+		return fmt.Errorf("interval must be non-negative, got %v", cfg.Interval.Duration)
+	}
+	if cfg.DeadPeerRetention.Duration < 0 {
+		return fmt.Errorf("dead_peer_retention must be non-negative, got %v", cfg.DeadPeerRetention.Duration)
+	}
+	return nil
+}
+
+func (cfg *Config) secretKey() ([]byte, error) {
+	if cfg.SecretKey == "" {
+		return nil, errors.New("secret_key is required to authenticate the peers")
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret_key: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("secret_key must be 16, 24, or 32 bytes, got %d", len(key))
+	}
+}
+
+func (cfg *Config) bindPort() int {
+	switch cfg.BindPort {
+	case 0:
+		return DefaultBindPort
+	case -1:
+		// memberlist picks a random port
+		return 0
+	default:
+		return cfg.BindPort
+	}
+}
+
+func (cfg *Config) interval() time.Duration {
+	if cfg.Interval.Duration == 0 {
+		return DefaultInterval
+	}
+	return cfg.Interval.Duration
+}
+
+func (cfg *Config) deadPeerRetention() time.Duration {
+	if cfg.DeadPeerRetention.Duration == 0 {
+		return DefaultDeadPeerRetention
+	}
+	return cfg.DeadPeerRetention.Duration
+}
@@ -0,0 +1,58 @@
+package gossip
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	assert.NoError(t, nilCfg.Validate())
+
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "empty", cfg: Config{SecretKey: key}},
+		{name: "valid", cfg: Config{BindAddr: "0.0.0.0", BindPort: 7947, AdvertiseAddr: "10.0.0.1", Join: []string{"10.0.0.2", "10.0.0.3:7947"}, SecretKey: key}},
+		{name: "random port", cfg: Config{BindPort: -1, SecretKey: key}},
+		{name: "missing secret key", cfg: Config{Join: []string{"10.0.0.2"}}, wantErr: true},
+		{name: "invalid bind addr", cfg: Config{BindAddr: "localhost"}, wantErr: true},
+		{name: "invalid bind port", cfg: Config{BindPort: 70000}, wantErr: true},
+		{name: "invalid advertise addr", cfg: Config{AdvertiseAddr: "node-1"}, wantErr: true},
+		{name: "invalid advertise port", cfg: Config{AdvertisePort: -1}, wantErr: true},
+		{name: "empty join", cfg: Config{Join: []string{""}}, wantErr: true},
+		{name: "invalid secret key encoding", cfg: Config{SecretKey: "not base64!"}, wantErr: true},
+		{name: "invalid secret key length", cfg: Config{SecretKey: base64.StdEncoding.EncodeToString(make([]byte, 10))}, wantErr: true},
+		{name: "negative interval", cfg: Config{Interval: metav1.Duration{Duration: -time.Second}, SecretKey: key}, wantErr: true},
+		{name: "negative retention", cfg: Config{DeadPeerRetention: metav1.Duration{Duration: -time.Second}, SecretKey: key}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, DefaultBindPort, cfg.bindPort())
+	assert.Equal(t, DefaultInterval, cfg.interval())
+	assert.Equal(t, DefaultDeadPeerRetention, cfg.deadPeerRetention())
+
+	cfg = &Config{BindPort: -1, Interval: metav1.Duration{Duration: time.Second}, DeadPeerRetention: metav1.Duration{Duration: time.Minute}}
+	assert.Equal(t, 0, cfg.bindPort())
+	assert.Equal(t, time.Second, cfg.interval())
+	assert.Equal(t, time.Minute, cfg.deadPeerRetention())
+}
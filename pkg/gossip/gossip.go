@@ -0,0 +1,414 @@
+// Package gossip implements the peer-to-peer exchange of the compact health
+// summaries between the GPUd instances in a cluster (based on memberlist),
+// so that every node can report the unhealthy peers without the control plane.
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"go.uber.org/zap"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// leaveTimeout is the time to wait for the leave message to propagate.
+	leaveTimeout = 5 * time.Second
+
+	// maxReasonLength caps each health reason, to keep the summaries
+	// within a single gossip packet.
+	maxReasonLength = 128
+)
+
+// SummarizeFunc returns the current health summary of the local node.
+type SummarizeFunc func() apiv1.HealthSummary
+
+// Agent gossips the local health summary to the peers,
+// and collects the health summaries of the peers.
+// Safe for concurrent use.
+type Agent struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	cfg       Config
+	summarize SummarizeFunc
+
+	getTimeNowFunc func() time.Time
+
+	ml         *memberlist.Memberlist
+	broadcasts *memberlist.TransmitLimitedQueue
+
+	mu    sync.RWMutex
+	self  apiv1.NodeHealthSummary
+	peers map[string]*peer
+
+	closeOnce sync.Once
+}
+
+// peer is the last known state of a peer.
+type peer struct {
+	addr   string
+	state  apiv1.PeerState
+	health *apiv1.NodeHealthSummary
+	// lastSeen is when the health summary was last received
+	lastSeen time.Time
+	// goneAt is when the peer was declared dead, zero if alive
+	goneAt time.Time
+}
+
+// New creates the gossip agent listening on the configured address,
+// named by the machine ID. Call Start to join the cluster.
+func New(ctx context.Context, cfg Config, machineID string, hostname string, summarize SummarizeFunc) (*Agent, error) {
+	if machineID == "" {
+		return nil, errors.New("machine id is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	key, err := cfg.secretKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithCancel(ctx)
+	a := &Agent{
+		ctx:            cctx,
+		cancel:         ccancel,
+		cfg:            cfg,
+		summarize:      summarize,
+		getTimeNowFunc: func() time.Time { return time.Now().UTC() },
+		self:           apiv1.NodeHealthSummary{MachineID: machineID, Hostname: hostname},
+		peers:          make(map[string]*peer),
+	}
+	a.refreshSelf()
+
+	mlCfg := memberlist.DefaultLANConfig()
+	mlCfg.Name = machineID
+	if cfg.BindAddr != "" {
+		mlCfg.BindAddr = cfg.BindAddr
+	}
+	mlCfg.BindPort = cfg.bindPort()
+	mlCfg.AdvertiseAddr = cfg.AdvertiseAddr
+	mlCfg.AdvertisePort = mlCfg.BindPort
+	if cfg.AdvertisePort > 0 {
+		mlCfg.AdvertisePort = cfg.AdvertisePort
+	}
+	mlCfg.SecretKey = key
+	mlCfg.Delegate = &delegate{a: a}
+	mlCfg.Events = &eventDelegate{a: a}
+	mlCfg.Logger = zap.NewStdLog(log.Logger.Desugar().WithOptions(zap.IncreaseLevel(zap.WarnLevel)))
+
+	a.ml, err = memberlist.Create(mlCfg)
+	if err != nil {
+		ccancel()
+		return nil, fmt.Errorf("failed to create gossip memberlist: %w", err)
+	}
+	a.broadcasts = &memberlist.TransmitLimitedQueue{
+		NumNodes:       a.ml.NumMembers,
+		RetransmitMult: mlCfg.RetransmitMult,
+	}
+	return a, nil
+}
+
+// Addr returns the advertised gossip address of the local node ("host:port").
+func (a *Agent) Addr() string {
+	return a.ml.LocalNode().Address()
+}
+
+// Start joins the cluster and starts broadcasting the local health summary
+// in the background, retrying the join until any peer is reachable.
+func (a *Agent) Start() {
+	go a.run()
+}
+
+func (a *Agent) run() {
+	a.join()
+	a.queueBroadcast()
+
+	ticker := time.NewTicker(a.cfg.interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// retry until joined, in case the peers started later
+		if a.ml.NumMembers() <= 1 {
+			a.join()
+		}
+		a.refreshSelf()
+		a.queueBroadcast()
+		a.prune()
+	}
+}
+
+func (a *Agent) join() {
+	if len(a.cfg.Join) == 0 {
+		return
+	}
+	n, err := a.ml.Join(a.cfg.Join)
+	if err != nil {
+		log.Logger.Warnw("failed to join gossip peers", "peers", a.cfg.Join, "error", err)
+		return
+	}
+	log.Logger.Infow("joined gossip peers", "contacted", n, "members", a.ml.NumMembers())
+}
+
+// Close leaves the cluster and stops the gossip.
+func (a *Agent) Close() error {
+	var err error
+	a.closeOnce.Do(func() {
+		a.cancel()
+		if lerr := a.ml.Leave(leaveTimeout); lerr != nil {
+			log.Logger.Warnw("failed to leave gossip cluster", "error", lerr)
+		}
+		err = a.ml.Shutdown()
+	})
+	return err
+}
+
+// Summary returns the health of the local node and the known peers.
+func (a *Agent) Summary() apiv1.ClusterSummary {
+	// suspect peers are still listed as the members
+	suspects := make(map[string]struct{})
+	for _, n := range a.ml.Members() {
+		if n.State == memberlist.StateSuspect {
+			suspects[n.Name] = struct{}{}
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	cs := apiv1.ClusterSummary{
+		Self:  a.self,
+		Peers: make([]apiv1.ClusterPeer, 0, len(a.peers)),
+	}
+	for name, p := range a.peers {
+		cp := apiv1.ClusterPeer{
+			MachineID: name,
+			Address:   p.addr,
+			State:     p.state,
+		}
+		if _, ok := suspects[name]; ok && p.state == apiv1.PeerStateAlive {
+			cp.State = apiv1.PeerStateSuspect
+		}
+		if p.health != nil {
+			h := *p.health
+			cp.Health = &h
+			lastSeen := p.lastSeen
+			cp.LastSeen = &lastSeen
+		}
+		cs.Peers = append(cs.Peers, cp)
+
+		if cp.State != apiv1.PeerStateAlive || (cp.Health != nil && cp.Health.Summary.Verdict != apiv1.HealthVerdictHealthy) {
+			cs.Unhealthy = append(cs.Unhealthy, name)
+		}
+	}
+	sort.Slice(cs.Peers, func(i, j int) bool {
+		return cs.Peers[i].MachineID < cs.Peers[j].MachineID
+	})
+	sort.Strings(cs.Unhealthy)
+	return cs
+}
+
+// refreshSelf summarizes the local health.
+func (a *Agent) refreshSelf() {
+	var summary apiv1.HealthSummary
+	if a.summarize != nil {
+		summary = compact(a.summarize())
+	}
+	now := a.getTimeNowFunc()
+
+	a.mu.Lock()
+	a.self.Summary = summary
+	a.self.UpdatedAt = now
+	a.mu.Unlock()
+}
+
+// compact truncates the reasons to fit the summary in a gossip packet.
+func compact(summary apiv1.HealthSummary) apiv1.HealthSummary {
+	reasons := make([]apiv1.HealthSummaryReason, 0, len(summary.TopReasons))
+	for _, r := range summary.TopReasons {
+		if len(r.Reason) > maxReasonLength {
+			r.Reason = r.Reason[:maxReasonLength-3] + "..."
+		}
+		reasons = append(reasons, r)
+	}
+	summary.TopReasons = reasons
+	return summary
+}
+
+func (a *Agent) queueBroadcast() {
+	a.mu.RLock()
+	b, err := json.Marshal([]apiv1.NodeHealthSummary{a.self})
+	a.mu.RUnlock()
+	if err != nil {
+		log.Logger.Warnw("failed to marshal health summary", "error", err)
+		return
+	}
+	a.broadcasts.QueueBroadcast(&broadcast{name: a.self.MachineID, msg: b})
+}
+
+// merge stores the peer health summaries newer than the known ones.
+func (a *Agent) merge(buf []byte) {
+	var summaries []apiv1.NodeHealthSummary
+	if err := json.Unmarshal(buf, &summaries); err != nil {
+		log.Logger.Warnw("failed to unmarshal peer health summaries", "error", err)
+		return
+	}
+
+	now := a.getTimeNowFunc()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range summaries {
+		s := summaries[i]
+		if s.MachineID == "" || s.MachineID == a.self.MachineID {
+			continue
+		}
+		p, ok := a.peers[s.MachineID]
+		if !ok {
+			// the member event may arrive after the summary
+			p = &peer{state: apiv1.PeerStateAlive}
+			a.peers[s.MachineID] = p
+		}
+		if p.health != nil && !s.UpdatedAt.After(p.health.UpdatedAt) {
+			continue
+		}
+		p.health = &s
+		p.lastSeen = now
+	}
+}
+
+// localState returns the health summaries of the local node and the known peers,
+// to spread the summaries across the cluster on every push/pull.
+func (a *Agent) localState() []byte {
+	a.mu.RLock()
+	summaries := []apiv1.NodeHealthSummary{a.self}
+	for _, p := range a.peers {
+		if p.health != nil {
+			summaries = append(summaries, *p.health)
+		}
+	}
+	a.mu.RUnlock()
+
+	b, err := json.Marshal(summaries)
+	if err != nil {
+		log.Logger.Warnw("failed to marshal health summaries", "error", err)
+		return nil
+	}
+	return b
+}
+
+func (a *Agent) setPeerState(n *memberlist.Node, state apiv1.PeerState) {
+	if n.Name == a.self.MachineID {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p, ok := a.peers[n.Name]
+	if !ok {
+		p = &peer{}
+		a.peers[n.Name] = p
+	}
+	p.addr = n.Address()
+	p.state = state
+	p.goneAt = time.Time{}
+	if state == apiv1.PeerStateDead {
+		p.goneAt = a.getTimeNowFunc()
+	}
+}
+
+// prune forgets the peers dead longer than the retention.
+func (a *Agent) prune() {
+	cutoff := a.getTimeNowFunc().Add(-a.cfg.deadPeerRetention())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for name, p := range a.peers {
+		if !p.goneAt.IsZero() && p.goneAt.Before(cutoff) {
+			delete(a.peers, name)
+		}
+	}
+}
+
+var _ memberlist.Delegate = &delegate{}
+
+// delegate hooks the health summaries into the memberlist messages.
+type delegate struct {
+	a *Agent
+}
+
+func (d *delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+func (d *delegate) NotifyMsg(b []byte) {
+	d.a.merge(b)
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.a.broadcasts.GetBroadcasts(overhead, limit)
+}
+
+func (d *delegate) LocalState(join bool) []byte {
+	return d.a.localState()
+}
+
+func (d *delegate) MergeRemoteState(buf []byte, join bool) {
+	d.a.merge(buf)
+}
+
+var _ memberlist.EventDelegate = &eventDelegate{}
+
+// eventDelegate tracks the peer membership states.
+type eventDelegate struct {
+	a *Agent
+}
+
+func (e *eventDelegate) NotifyJoin(n *memberlist.Node) {
+	log.Logger.Infow("gossip peer joined", "machineID", n.Name, "address", n.Address())
+	e.a.setPeerState(n, apiv1.PeerStateAlive)
+}
+
+func (e *eventDelegate) NotifyLeave(n *memberlist.Node) {
+	// the node state is not set for the delegate, thus not telling the dead from the left
+	log.Logger.Warnw("gossip peer gone", "machineID", n.Name, "address", n.Address())
+	e.a.setPeerState(n, apiv1.PeerStateDead)
+}
+
+func (e *eventDelegate) NotifyUpdate(n *memberlist.Node) {
+	e.a.setPeerState(n, apiv1.PeerStateAlive)
+}
+
+var _ memberlist.Broadcast = &broadcast{}
+
+// broadcast is the health summary of a node, superseding the previous one.
+type broadcast struct {
+	name string
+	msg  []byte
+}
+
+func (b *broadcast) Invalidates(other memberlist.Broadcast) bool {
+	o, ok := other.(*broadcast)
+	return ok && o.name == b.name
+}
+
+func (b *broadcast) Message() []byte {
+	return b.msg
+}
+
+func (b *broadcast) Finished() {}
@@ -0,0 +1,155 @@
+package gossip
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func newTestAgent(t *testing.T, machineID string, join []string, summarize SummarizeFunc) *Agent {
+	cfg := Config{
+		BindAddr:      "127.0.0.1",
+		BindPort:      -1,
+		AdvertiseAddr: "127.0.0.1",
+		Join:          join,
+		SecretKey:     base64.StdEncoding.EncodeToString(make([]byte, 32)),
+		Interval:      metav1.Duration{Duration: 100 * time.Millisecond},
+	}
+	a, err := New(context.Background(), cfg, machineID, machineID+"-host", summarize)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+	return a
+}
+
+func TestNewRequiresMachineID(t *testing.T) {
+	_, err := New(context.Background(), Config{}, "", "", nil)
+	assert.Error(t, err)
+}
+
+func TestAgentClusterSummary(t *testing.T) {
+	healthy := func() apiv1.HealthSummary {
+		return apiv1.HealthSummary{Verdict: apiv1.HealthVerdictHealthy}
+	}
+	fatal := func() apiv1.HealthSummary {
+		return apiv1.HealthSummary{
+			Verdict:    apiv1.HealthVerdictFatal,
+			TopReasons: []apiv1.HealthSummaryReason{{Component: "accelerator-nvidia-xid", Health: apiv1.HealthStateTypeUnhealthy, Reason: "xid 79"}},
+		}
+	}
+
+	a := newTestAgent(t, "node-a", nil, healthy)
+	a.Start()
+	b := newTestAgent(t, "node-b", []string{a.Addr()}, fatal)
+	b.Start()
+
+	require.Eventually(t, func() bool {
+		cs := a.Summary()
+		return len(cs.Peers) == 1 && cs.Peers[0].Health != nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	cs := a.Summary()
+	assert.Equal(t, "node-a", cs.Self.MachineID)
+	assert.Equal(t, apiv1.HealthVerdictHealthy, cs.Self.Summary.Verdict)
+	assert.Equal(t, "node-b", cs.Peers[0].MachineID)
+	assert.Equal(t, b.Addr(), cs.Peers[0].Address)
+	assert.Equal(t, apiv1.PeerStateAlive, cs.Peers[0].State)
+	assert.Equal(t, apiv1.HealthVerdictFatal, cs.Peers[0].Health.Summary.Verdict)
+	assert.Equal(t, "node-b-host", cs.Peers[0].Health.Hostname)
+	assert.NotNil(t, cs.Peers[0].LastSeen)
+	assert.Equal(t, []string{"node-b"}, cs.Unhealthy)
+
+	require.Eventually(t, func() bool {
+		cs := b.Summary()
+		return len(cs.Peers) == 1 && cs.Peers[0].Health != nil
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Empty(t, b.Summary().Unhealthy)
+
+	require.NoError(t, b.Close())
+	require.Eventually(t, func() bool {
+		cs := a.Summary()
+		return len(cs.Peers) == 1 && cs.Peers[0].State == apiv1.PeerStateDead
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, []string{"node-b"}, a.Summary().Unhealthy)
+}
+
+func TestAgentMerge(t *testing.T) {
+	a := newTestAgent(t, "node-a", nil, nil)
+
+	t0 := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.getTimeNowFunc = func() time.Time { return t0 }
+
+	marshal := func(summaries ...apiv1.NodeHealthSummary) []byte {
+		b, err := json.Marshal(summaries)
+		require.NoError(t, err)
+		return b
+	}
+
+	a.merge(marshal(
+		apiv1.NodeHealthSummary{MachineID: "node-a", UpdatedAt: t0},
+		apiv1.NodeHealthSummary{MachineID: "node-b", Summary: apiv1.HealthSummary{Verdict: apiv1.HealthVerdictDegraded}, UpdatedAt: t0},
+	))
+	// older summaries are ignored
+	a.merge(marshal(apiv1.NodeHealthSummary{MachineID: "node-b", Summary: apiv1.HealthSummary{Verdict: apiv1.HealthVerdictHealthy}, UpdatedAt: t0.Add(-time.Minute)}))
+	// malformed messages are ignored
+	a.merge([]byte("{"))
+
+	cs := a.Summary()
+	require.Len(t, cs.Peers, 1)
+	assert.Equal(t, "node-b", cs.Peers[0].MachineID)
+	assert.Equal(t, apiv1.HealthVerdictDegraded, cs.Peers[0].Health.Summary.Verdict)
+	assert.Equal(t, []string{"node-b"}, cs.Unhealthy)
+
+	a.merge(marshal(apiv1.NodeHealthSummary{MachineID: "node-b", Summary: apiv1.HealthSummary{Verdict: apiv1.HealthVerdictHealthy}, UpdatedAt: t0.Add(time.Minute)}))
+	cs = a.Summary()
+	assert.Equal(t, apiv1.HealthVerdictHealthy, cs.Peers[0].Health.Summary.Verdict)
+	assert.Empty(t, cs.Unhealthy)
+
+	// the local state carries the known peers
+	var summaries []apiv1.NodeHealthSummary
+	require.NoError(t, json.Unmarshal(a.localState(), &summaries))
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "node-a", summaries[0].MachineID)
+	assert.Equal(t, "node-b", summaries[1].MachineID)
+}
+
+func TestAgentPrune(t *testing.T) {
+	a := newTestAgent(t, "node-a", nil, nil)
+
+	t0 := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.getTimeNowFunc = func() time.Time { return t0 }
+	a.peers["node-b"] = &peer{state: apiv1.PeerStateDead, goneAt: t0.Add(-2 * DefaultDeadPeerRetention)}
+	a.peers["node-c"] = &peer{state: apiv1.PeerStateDead, goneAt: t0.Add(-time.Minute)}
+	a.peers["node-d"] = &peer{state: apiv1.PeerStateAlive}
+
+	a.prune()
+
+	cs := a.Summary()
+	require.Len(t, cs.Peers, 2)
+	assert.Equal(t, "node-c", cs.Peers[0].MachineID)
+	assert.Equal(t, "node-d", cs.Peers[1].MachineID)
+	assert.Equal(t, []string{"node-c"}, cs.Unhealthy)
+}
+
+func TestCompact(t *testing.T) {
+	summary := compact(apiv1.HealthSummary{
+		Verdict: apiv1.HealthVerdictDegraded,
+		TopReasons: []apiv1.HealthSummaryReason{
+			{Component: "a", Reason: strings.Repeat("x", 2*maxReasonLength)},
+			{Component: "b", Reason: "short"},
+		},
+	})
+	assert.Len(t, summary.TopReasons[0].Reason, maxReasonLength)
+	assert.True(t, strings.HasSuffix(summary.TopReasons[0].Reason, "..."))
+	assert.Equal(t, "short", summary.TopReasons[1].Reason)
+}
@@ -0,0 +1,239 @@
+// Package gpureset tracks the GPU reset windows, during which the GPUs are reset
+// or the host reboots (initiated by GPUd or detected externally), so that the
+// expected cascade of the Xid and SXid events within the windows is tagged
+// and does not alert on the self-inflicted resets.
+package gpureset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// BucketName is the event bucket of the recorded reset windows.
+	BucketName = "gpu-reset"
+	// EventNameResetWindow is the event name of a recorded reset window.
+	EventNameResetWindow = "gpu_reset_window"
+
+	eventKeySource = "source"
+	eventKeyReason = "reason"
+	eventKeyEnd    = "end"
+
+	// EventKeyGPUReset is the event extra info key set to "true"
+	// if the event happened within a reset window.
+	EventKeyGPUReset = "gpu_reset"
+	// EventKeyGPUResetReason is the event extra info key of the reason of the reset window.
+	EventKeyGPUResetReason = "gpu_reset_reason"
+
+	// DefaultWindowPeriod is how long the GPUs are expected to settle
+	// after a reset starts (or the host boots).
+	DefaultWindowPeriod = 5 * time.Minute
+	// DefaultLeadPeriod is how long before the detected driver reload the window starts,
+	// to cover the events logged while the GPUs were being torn down.
+	DefaultLeadPeriod = time.Minute
+)
+
+// Source is where the reset was initiated or detected.
+type Source string
+
+const (
+	// SourceGPUd is the reset initiated by GPUd (e.g., the reboot requested by the control plane).
+	SourceGPUd Source = "gpud"
+	// SourceExternal is the reset detected externally (e.g., a host reboot or a driver reload).
+	SourceExternal Source = "external"
+)
+
+const (
+	// kmsgDriverLoad is the kernel message when the NVIDIA driver is (re)loaded,
+	// after which the GPUs are reinitialized.
+	kmsgDriverLoad = "NVRM: loading NVIDIA UNIX"
+
+	// ReasonDriverReloaded is the reason of the window detected from the kmsg
+	// of the NVIDIA driver (re)load.
+	ReasonDriverReloaded = "nvidia driver reloaded"
+)
+
+// Window is the period of a GPU reset or a host reboot.
+type Window struct {
+	Source Source    `json:"source"`
+	Reason string    `json:"reason"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Contains returns true if the time is within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && !t.After(w.End)
+}
+
+// Find returns the window that contains the time, and false if none contains it.
+func Find(windows []Window, t time.Time) (Window, bool) {
+	for _, w := range windows {
+		if w.Contains(t) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// Tracker records the reset windows initiated by GPUd or detected from the kmsg,
+// and derives the windows of the host reboots from the reboot events.
+// The windows are persisted in the event bucket, so that they survive the reboots.
+// Safe for concurrent use.
+type Tracker struct {
+	bucket           eventstore.Bucket
+	rebootEventStore pkghost.RebootEventStore
+	getTimeNowFunc   func() time.Time
+}
+
+// New creates the reset window tracker.
+// The reboot windows are not tracked if the reboot event store is nil.
+func New(bucket eventstore.Bucket, rebootEventStore pkghost.RebootEventStore) *Tracker {
+	return &Tracker{
+		bucket:           bucket,
+		rebootEventStore: rebootEventStore,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Begin records the reset window starting now for the default window period.
+// No-op if the tracker is nil.
+func (t *Tracker) Begin(ctx context.Context, source Source, reason string) error {
+	if t == nil {
+		return nil
+	}
+	now := t.getTimeNowFunc()
+	return t.record(ctx, Window{Source: source, Reason: reason, Start: now, End: now.Add(DefaultWindowPeriod)}, "")
+}
+
+// ObserveKmsg records the reset window if the kernel message shows
+// that the NVIDIA driver was reloaded (e.g., after the GPUs were reset externally).
+// It returns true if the message is a driver reload.
+// No-op if the tracker is nil.
+func (t *Tracker) ObserveKmsg(ctx context.Context, msg kmsg.Message) (bool, error) {
+	if t == nil || !strings.Contains(msg.Message, kmsgDriverLoad) {
+		return false, nil
+	}
+	ts := msg.Timestamp.Time
+	return true, t.record(ctx, Window{
+		Source: SourceExternal,
+		Reason: ReasonDriverReloaded,
+		Start:  ts.Add(-DefaultLeadPeriod),
+		End:    ts.Add(DefaultWindowPeriod),
+	}, msg.DedupKey())
+}
+
+// record stores the reset window, with the deduplication key of the source
+// kmsg message if any (see "kmsg.Message.DedupKey").
+func (t *Tracker) record(ctx context.Context, w Window, dedupKey string) error {
+	ev := eventstore.Event{
+		Time:    w.Start,
+		Name:    EventNameResetWindow,
+		Type:    string(apiv1.EventTypeInfo),
+		Message: fmt.Sprintf("gpu reset window (%s) until %s", w.Reason, w.End.Format(time.RFC3339)),
+		ExtraInfo: map[string]string{
+			eventKeySource: string(w.Source),
+			eventKeyReason: w.Reason,
+			eventKeyEnd:    w.End.Format(time.RFC3339Nano),
+		},
+		DedupKey: dedupKey,
+	}
+
+	// the same driver reload may be observed again (e.g., kmsg replayed)
+	existing, err := t.bucket.Find(ctx, ev)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+	if err := t.bucket.Insert(ctx, ev); err != nil {
+		return err
+	}
+	log.Logger.Infow("recorded gpu reset window", "source", w.Source, "reason", w.Reason, "start", w.Start, "end", w.End)
+	return nil
+}
+
+// Windows returns the reset windows that ended after the since time, sorted by the start time.
+// Returns nil if the tracker is nil.
+func (t *Tracker) Windows(ctx context.Context, since time.Time) ([]Window, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	// the windows started earlier may still cover the since time
+	evs, err := t.bucket.Get(ctx, since.Add(-DefaultWindowPeriod-DefaultLeadPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []Window
+	for _, ev := range evs {
+		if ev.Name != EventNameResetWindow {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339Nano, ev.ExtraInfo[eventKeyEnd])
+		if err != nil {
+			log.Logger.Warnw("failed to parse gpu reset window end", "start", ev.Time, "error", err)
+			continue
+		}
+		windows = append(windows, Window{
+			Source: Source(ev.ExtraInfo[eventKeySource]),
+			Reason: ev.ExtraInfo[eventKeyReason],
+			Start:  ev.Time,
+			End:    end,
+		})
+	}
+
+	if t.rebootEventStore != nil {
+		rebootEvents, err := t.rebootEventStore.GetRebootEvents(ctx, since.Add(-DefaultWindowPeriod))
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range rebootEvents {
+			if ev.Name != pkghost.EventNameReboot {
+				continue
+			}
+			// the GPUs are reinitialized while the host boots
+			windows = append(windows, Window{
+				Source: SourceExternal,
+				Reason: "host rebooted",
+				Start:  ev.Time,
+				End:    ev.Time.Add(DefaultWindowPeriod),
+			})
+		}
+	}
+
+	filtered := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		if w.End.After(since) {
+			filtered = append(filtered, w)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Start.Before(filtered[j].Start)
+	})
+	return filtered, nil
+}
+
+// Tag returns a copy of the extra info tagged with the reset window.
+func Tag(extraInfo map[string]string, w Window) map[string]string {
+	copied := make(map[string]string, len(extraInfo)+2)
+	for k, v := range extraInfo {
+		copied[k] = v
+	}
+	copied[EventKeyGPUReset] = "true"
+	copied[EventKeyGPUResetReason] = w.Reason
+	return copied
+}
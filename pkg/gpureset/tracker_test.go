@@ -0,0 +1,106 @@
+package gpureset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkghost "github.com/leptonai/gpud/pkg/host"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type fakeRebootEventStore struct {
+	events eventstore.Events
+}
+
+func (f *fakeRebootEventStore) RecordReboot(context.Context) error { return nil }
+
+func (f *fakeRebootEventStore) GetRebootEvents(_ context.Context, since time.Time) (eventstore.Events, error) {
+	var evs eventstore.Events
+	for _, ev := range f.events {
+		if !ev.Time.Before(since) {
+			evs = append(evs, ev)
+		}
+	}
+	return evs, nil
+}
+
+func TestTracker(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	boot := now.Add(-2 * time.Hour)
+	reboots := &fakeRebootEventStore{events: eventstore.Events{{Time: boot, Name: pkghost.EventNameReboot}}}
+
+	tr := New(bucket, reboots)
+	tr.getTimeNowFunc = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, tr.Begin(ctx, SourceGPUd, "reboot requested by control plane"))
+
+	reload := now.Add(-time.Hour)
+	msg := kmsg.Message{Timestamp: metav1.NewTime(reload), Message: "NVRM: loading NVIDIA UNIX x86_64 Kernel Module  535.129.03"}
+	reloaded, err := tr.ObserveKmsg(ctx, msg)
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	// the replayed message is not recorded again
+	_, err = tr.ObserveKmsg(ctx, msg)
+	require.NoError(t, err)
+
+	reloaded, err = tr.ObserveKmsg(ctx, kmsg.Message{Timestamp: metav1.NewTime(now), Message: "NVRM: Xid (PCI:0000:9b:00): 79"})
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	windows, err := tr.Windows(ctx, now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, windows, 3)
+
+	assert.Equal(t, Window{Source: SourceExternal, Reason: "host rebooted", Start: boot, End: boot.Add(DefaultWindowPeriod)}, windows[0])
+	assert.Equal(t, SourceExternal, windows[1].Source)
+	assert.True(t, windows[1].Start.Equal(reload.Add(-DefaultLeadPeriod)))
+	assert.True(t, windows[1].End.Equal(reload.Add(DefaultWindowPeriod)))
+	assert.Equal(t, SourceGPUd, windows[2].Source)
+	assert.True(t, windows[2].Start.Equal(now))
+
+	w, ok := Find(windows, reload.Add(-30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, "nvidia driver reloaded", w.Reason)
+	_, ok = Find(windows, reload.Add(10*time.Minute))
+	assert.False(t, ok)
+
+	// the windows ended before the since time are excluded
+	windows, err = tr.Windows(ctx, now.Add(-30*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, SourceGPUd, windows[0].Source)
+
+	tagged := Tag(map[string]string{"xid": "79"}, w)
+	assert.Equal(t, map[string]string{"xid": "79", EventKeyGPUReset: "true", EventKeyGPUResetReason: "nvidia driver reloaded"}, tagged)
+}
+
+func TestTrackerNil(t *testing.T) {
+	var tr *Tracker
+	ctx := context.Background()
+	require.NoError(t, tr.Begin(ctx, SourceGPUd, "reboot"))
+
+	reloaded, err := tr.ObserveKmsg(ctx, kmsg.Message{Message: "NVRM: loading NVIDIA UNIX x86_64 Kernel Module"})
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	windows, err := tr.Windows(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, windows)
+}
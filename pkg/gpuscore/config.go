@@ -0,0 +1,110 @@
+// Package gpuscore computes the rolling performance score of each GPU between 0 and 100,
+// combining the utilization capacity, the throttle time, the ECC errors, and the NVLink
+// errors from the metrics store, so that the worst GPUs in a fleet sort first by a single number.
+package gpuscore
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultWindow is the default rolling window of the metrics to score.
+	DefaultWindow = time.Hour
+
+	// DefaultECCErrorsSaturation is the default number of the corrected ECC errors
+	// within the window at which the ECC factor is fully penalized.
+	DefaultECCErrorsSaturation = 100
+	// DefaultNVLinkErrorsSaturation is the default number of the NVLink errors
+	// within the window at which the NVLink factor is fully penalized.
+	DefaultNVLinkErrorsSaturation = 100
+
+	// BusyUtilizationPercent is the GPU utilization at or above which
+	// the graphics clock counts towards the utilization capacity,
+	// as the idle GPUs lower their clocks regardless of their capacity.
+	BusyUtilizationPercent = 50
+)
+
+// Weights are the relative weights of the score factors.
+// The weights are normalized by their sum, thus need not sum to 1.
+type Weights struct {
+	Capacity float64 `json:"capacity"`
+	Throttle float64 `json:"throttle"`
+	ECC      float64 `json:"ecc"`
+	NVLink   float64 `json:"nvlink"`
+}
+
+// DefaultWeights returns the default weights, where the throttle time weighs the most
+// as it directly slows down the jobs.
+func DefaultWeights() Weights {
+	return Weights{
+		Capacity: 0.2,
+		Throttle: 0.35,
+		ECC:      0.25,
+		NVLink:   0.2,
+	}
+}
+
+func (w Weights) sum() float64 {
+	return w.Capacity + w.Throttle + w.ECC + w.NVLink
+}
+
+// Config configures the GPU performance score.
+type Config struct {
+	// Window is the rolling window of the metrics to score (defaults to 1 hour).
+	Window metav1.Duration `json:"window,omitempty"`
+	// Weights are the weights of the score factors (defaults to DefaultWeights if all zero).
+	Weights Weights `json:"weights,omitempty"`
+
+	// ECCErrorsSaturation is the number of the corrected ECC errors within the window
+	// at which the ECC factor is fully penalized (defaults to 100).
+	// Any uncorrected ECC error fully penalizes the ECC factor.
+	ECCErrorsSaturation float64 `json:"ecc_errors_saturation,omitempty"`
+	// NVLinkErrorsSaturation is the number of the NVLink errors within the window
+	// at which the NVLink factor is fully penalized (defaults to 100).
+	NVLinkErrorsSaturation float64 `json:"nvlink_errors_saturation,omitempty"`
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Window.Duration < 0 {
+		return fmt.Errorf("window must be non-negative, got %s", cfg.Window.Duration)
+	}
+	w := cfg.Weights
+	if w.Capacity < 0 || w.Throttle < 0 || w.ECC < 0 || w.NVLink < 0 {
+		return fmt.Errorf("weights must be non-negative, got %+v", w)
+	}
+	if cfg.ECCErrorsSaturation < 0 {
+		return fmt.Errorf("ecc_errors_saturation must be non-negative, got %v", cfg.ECCErrorsSaturation)
+	}
+	if cfg.NVLinkErrorsSaturation < 0 {
+		return fmt.Errorf("nvlink_errors_saturation must be non-negative, got %v", cfg.NVLinkErrorsSaturation)
+	}
+	return nil
+}
+
+// withDefaults returns the config with the zero values set to the defaults.
+func (cfg *Config) withDefaults() Config {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Window.Duration == 0 {
+		c.Window.Duration = DefaultWindow
+	}
+	if c.Weights.sum() == 0 {
+		c.Weights = DefaultWeights()
+	}
+	if c.ECCErrorsSaturation == 0 {
+		c.ECCErrorsSaturation = DefaultECCErrorsSaturation
+	}
+	if c.NVLinkErrorsSaturation == 0 {
+		c.NVLinkErrorsSaturation = DefaultNVLinkErrorsSaturation
+	}
+	return c
+}
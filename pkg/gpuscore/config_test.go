@@ -0,0 +1,34 @@
+package gpuscore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	assert.NoError(t, cfg.Validate())
+
+	assert.NoError(t, (&Config{Weights: Weights{Throttle: 1}}).Validate())
+	assert.Error(t, (&Config{Window: metav1.Duration{Duration: -time.Minute}}).Validate())
+	assert.Error(t, (&Config{Weights: Weights{Throttle: 1, ECC: -1}}).Validate())
+	assert.Error(t, (&Config{ECCErrorsSaturation: -1}).Validate())
+	assert.Error(t, (&Config{NVLinkErrorsSaturation: -1}).Validate())
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	var cfg *Config
+	assert.Equal(t, Config{
+		Window:                 metav1.Duration{Duration: DefaultWindow},
+		Weights:                DefaultWeights(),
+		ECCErrorsSaturation:    DefaultECCErrorsSaturation,
+		NVLinkErrorsSaturation: DefaultNVLinkErrorsSaturation,
+	}, cfg.withDefaults())
+
+	c := (&Config{Window: metav1.Duration{Duration: 10 * time.Minute}, Weights: Weights{Throttle: 1}}).withDefaults()
+	assert.Equal(t, 10*time.Minute, c.Window.Duration)
+	assert.Equal(t, Weights{Throttle: 1}, c.Weights)
+}
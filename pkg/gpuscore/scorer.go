@@ -0,0 +1,294 @@
+package gpuscore
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsnvidiaclockspeed "github.com/leptonai/gpud/components/accelerator/nvidia/clock-speed"
+	componentsnvidiaecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentsnvidiahwslowdown "github.com/leptonai/gpud/components/accelerator/nvidia/hw-slowdown"
+	componentsnvidianvlink "github.com/leptonai/gpud/components/accelerator/nvidia/nvlink"
+	componentsnvidiautilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+// computeInterval is the interval to recompute the scores in the background.
+const computeInterval = time.Minute
+
+var (
+	metricNameGraphicsMHz    = componentsnvidiaclockspeed.SubSystem + "_graphics_mhz"
+	metricNameGPUUtilPercent = componentsnvidiautilization.SubSystem + "_gpu_util_percent"
+	metricNameECCCorrected   = componentsnvidiaecc.SubSystem + "_volatile_total_corrected"
+	metricNameECCUncorrected = componentsnvidiaecc.SubSystem + "_volatile_total_uncorrected"
+
+	// any of the slowdowns counts the sample as throttled
+	metricNamesSlowdown = map[string]struct{}{
+		componentsnvidiahwslowdown.SubSystem + "_hw_slowdown":             {},
+		componentsnvidiahwslowdown.SubSystem + "_hw_slowdown_thermal":     {},
+		componentsnvidiahwslowdown.SubSystem + "_hw_slowdown_power_brake": {},
+	}
+	metricNamesNVLinkErrors = map[string]struct{}{
+		componentsnvidianvlink.SubSystem + "_replay_errors":   {},
+		componentsnvidianvlink.SubSystem + "_recovery_errors": {},
+		componentsnvidianvlink.SubSystem + "_crc_errors":      {},
+	}
+)
+
+var metricScore = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "gpud",
+		Subsystem: "gpu",
+		Name:      "performance_score",
+		Help:      "rolling performance score of the GPU between 0 (worst) and 100 (best)",
+	},
+	[]string{nvidianvml.LabelGPUUUID, nvidianvml.LabelGPUIndex},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricScore)
+}
+
+// Scorer computes the performance scores of the GPUs from the metrics store.
+// Safe for concurrent use.
+type Scorer struct {
+	metricsStore   pkgmetrics.Store
+	cfg            Config
+	getTimeNowFunc func() time.Time
+
+	mu     sync.RWMutex
+	scores map[string]apiv1.GPUPerformanceScore
+}
+
+// New creates the scorer of the GPUs, with the default config if nil.
+func New(metricsStore pkgmetrics.Store, cfg *Config) *Scorer {
+	return &Scorer{
+		metricsStore: metricsStore,
+		cfg:          cfg.withDefaults(),
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Start recomputes the scores in the background.
+func (s *Scorer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(computeInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Compute(ctx); err != nil {
+				log.Logger.Warnw("failed to compute gpu performance scores", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Scores returns the last computed scores, keyed by the GPU UUID.
+func (s *Scorer) Scores() map[string]apiv1.GPUPerformanceScore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := make(map[string]apiv1.GPUPerformanceScore, len(s.scores))
+	for uuid, score := range s.scores {
+		scores[uuid] = score
+	}
+	return scores
+}
+
+// Compute reads the metrics within the window, and computes the scores of the GPUs.
+func (s *Scorer) Compute(ctx context.Context) (map[string]apiv1.GPUPerformanceScore, error) {
+	now := s.getTimeNowFunc()
+
+	cctx, ccancel := context.WithTimeout(ctx, 30*time.Second)
+	ms, err := s.metricsStore.Read(cctx,
+		pkgmetrics.WithSince(now.Add(-s.cfg.Window.Duration)),
+		pkgmetrics.WithComponents(
+			componentsnvidiaclockspeed.Name,
+			componentsnvidiautilization.Name,
+			componentsnvidiahwslowdown.Name,
+			componentsnvidiaecc.Name,
+			componentsnvidianvlink.Name,
+		),
+	)
+	ccancel()
+	if err != nil {
+		return nil, err
+	}
+
+	scores, indexes := computeScores(s.cfg, now, ms)
+
+	metricScore.Reset()
+	for uuid, score := range scores {
+		metricScore.With(prometheus.Labels{
+			nvidianvml.LabelGPUUUID:  uuid,
+			nvidianvml.LabelGPUIndex: indexes[uuid],
+		}).Set(score.Score)
+	}
+
+	s.mu.Lock()
+	s.scores = scores
+	s.mu.Unlock()
+
+	return scores, nil
+}
+
+// gpuSamples is the data points of a GPU within the window.
+type gpuSamples struct {
+	index string
+
+	// keyed by the unix milliseconds, as the data points of a scrape share the timestamp
+	throttled   map[int64]bool
+	utilPercent map[int64]float64
+	graphicsMHz map[int64]float64
+
+	eccCorrected   []pkgmetrics.Metric
+	eccUncorrected []pkgmetrics.Metric
+	// keyed by the metric name
+	nvlinkErrors map[string][]pkgmetrics.Metric
+}
+
+// computeScores returns the scores and the indexes of the GPUs, keyed by the GPU UUID.
+func computeScores(cfg Config, now time.Time, ms pkgmetrics.Metrics) (map[string]apiv1.GPUPerformanceScore, map[string]string) {
+	gpus := make(map[string]*gpuSamples)
+	for _, m := range ms {
+		uuid := m.Labels[nvidianvml.LabelGPUUUID]
+		if uuid == "" {
+			continue
+		}
+		g, ok := gpus[uuid]
+		if !ok {
+			g = &gpuSamples{
+				throttled:    make(map[int64]bool),
+				utilPercent:  make(map[int64]float64),
+				graphicsMHz:  make(map[int64]float64),
+				nvlinkErrors: make(map[string][]pkgmetrics.Metric),
+			}
+			gpus[uuid] = g
+		}
+		if idx := m.Labels[nvidianvml.LabelGPUIndex]; idx != "" {
+			g.index = idx
+		}
+
+		switch {
+		case m.Name == metricNameGraphicsMHz:
+			g.graphicsMHz[m.UnixMilliseconds] = m.Value
+		case m.Name == metricNameGPUUtilPercent:
+			g.utilPercent[m.UnixMilliseconds] = m.Value
+		case m.Name == metricNameECCCorrected:
+			g.eccCorrected = append(g.eccCorrected, m)
+		case m.Name == metricNameECCUncorrected:
+			g.eccUncorrected = append(g.eccUncorrected, m)
+		default:
+			if _, ok := metricNamesSlowdown[m.Name]; ok {
+				g.throttled[m.UnixMilliseconds] = g.throttled[m.UnixMilliseconds] || m.Value > 0
+			}
+			if _, ok := metricNamesNVLinkErrors[m.Name]; ok {
+				g.nvlinkErrors[m.Name] = append(g.nvlinkErrors[m.Name], m)
+			}
+		}
+	}
+
+	// the fastest GPU on the host is the reference of the utilization capacity
+	busyMHz := make(map[string]float64, len(gpus))
+	fastestMHz := 0.0
+	for uuid, g := range gpus {
+		sum, n := 0.0, 0
+		for ts, mhz := range g.graphicsMHz {
+			if util, ok := g.utilPercent[ts]; ok && util >= BusyUtilizationPercent {
+				sum += mhz
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		busyMHz[uuid] = sum / float64(n)
+		fastestMHz = math.Max(fastestMHz, busyMHz[uuid])
+	}
+
+	scores := make(map[string]apiv1.GPUPerformanceScore, len(gpus))
+	indexes := make(map[string]string, len(gpus))
+	for uuid, g := range gpus {
+		score := apiv1.GPUPerformanceScore{
+			Time:                 now,
+			Window:               metav1.Duration{Duration: cfg.Window.Duration},
+			Capacity:             1,
+			ECCCorrectedErrors:   increase(g.eccCorrected),
+			ECCUncorrectedErrors: increase(g.eccUncorrected),
+		}
+		if mhz, ok := busyMHz[uuid]; ok && fastestMHz > 0 {
+			score.Capacity = mhz / fastestMHz
+		}
+		if len(g.throttled) > 0 {
+			throttled := 0
+			for _, t := range g.throttled {
+				if t {
+					throttled++
+				}
+			}
+			score.ThrottleRatio = float64(throttled) / float64(len(g.throttled))
+		}
+		for _, points := range g.nvlinkErrors {
+			score.NVLinkErrors += increase(points)
+		}
+		score.Score = evaluate(cfg, score)
+
+		scores[uuid] = score
+		indexes[uuid] = g.index
+	}
+	return scores, indexes
+}
+
+// evaluate returns the weighted score between 0 and 100:
+//
+//	score = 100 * (1 - sum(weight * penalty) / sum(weight))
+//
+// where each penalty is between 0 and 1.
+func evaluate(cfg Config, score apiv1.GPUPerformanceScore) float64 {
+	eccPenalty := math.Min(1, score.ECCCorrectedErrors/cfg.ECCErrorsSaturation)
+	if score.ECCUncorrectedErrors > 0 {
+		eccPenalty = 1
+	}
+	nvlinkPenalty := math.Min(1, score.NVLinkErrors/cfg.NVLinkErrorsSaturation)
+
+	w := cfg.Weights
+	penalty := w.Capacity*(1-score.Capacity) +
+		w.Throttle*score.ThrottleRatio +
+		w.ECC*eccPenalty +
+		w.NVLink*nvlinkPenalty
+	return math.Round(100*(1-penalty/w.sum())*100) / 100
+}
+
+// increase returns the increase of the cumulative counter within the window,
+// where the drop of the counter (e.g., the volatile ECC counters reset
+// by the driver reload) counts the value after the reset as the increase.
+func increase(points []pkgmetrics.Metric) float64 {
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].UnixMilliseconds < points[j].UnixMilliseconds
+	})
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		delta := points[i].Value - points[i-1].Value
+		if delta < 0 {
+			delta = points[i].Value
+		}
+		total += delta
+	}
+	return total
+}
@@ -0,0 +1,156 @@
+package gpuscore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+type fakeMetricsStore struct {
+	metrics pkgmetrics.Metrics
+}
+
+func (s *fakeMetricsStore) Record(_ context.Context, ms ...pkgmetrics.Metric) error {
+	s.metrics = append(s.metrics, ms...)
+	return nil
+}
+
+func (s *fakeMetricsStore) Read(_ context.Context, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	op := &pkgmetrics.Op{}
+	if err := op.ApplyOpts(opts); err != nil {
+		return nil, err
+	}
+	var ms pkgmetrics.Metrics
+	for _, m := range s.metrics {
+		if m.UnixMilliseconds >= op.Since.UnixMilli() {
+			ms = append(ms, m)
+		}
+	}
+	return ms, nil
+}
+
+func (s *fakeMetricsStore) Purge(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
+func gpuMetric(ts time.Time, name string, uuid string, index string, value float64) pkgmetrics.Metric {
+	return pkgmetrics.Metric{
+		UnixMilliseconds: ts.UnixMilli(),
+		Name:             name,
+		Value:            value,
+		Labels:           map[string]string{"gpu_uuid": uuid, "gpu_index": index},
+	}
+}
+
+func TestComputeScores(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := (*Config)(nil).withDefaults()
+
+	var ms pkgmetrics.Metrics
+	for i := 0; i < 4; i++ {
+		ts := now.Add(time.Duration(i-4) * time.Minute)
+
+		// the healthy GPU at the full clock
+		ms = append(ms,
+			gpuMetric(ts, metricNameGPUUtilPercent, "GPU-a", "0", 100),
+			gpuMetric(ts, metricNameGraphicsMHz, "GPU-a", "0", 1980),
+			gpuMetric(ts, "accelerator_nvidia_clock_hw_slowdown", "GPU-a", "0", 0),
+			gpuMetric(ts, metricNameECCCorrected, "GPU-a", "0", 3),
+		)
+
+		// the GPU throttled half of the time, at the half clock
+		slowdown := float64(i % 2)
+		ms = append(ms,
+			gpuMetric(ts, metricNameGPUUtilPercent, "GPU-b", "1", 100),
+			gpuMetric(ts, metricNameGraphicsMHz, "GPU-b", "1", 990),
+			gpuMetric(ts, "accelerator_nvidia_clock_hw_slowdown", "GPU-b", "1", 0),
+			gpuMetric(ts, "accelerator_nvidia_clock_hw_slowdown_thermal", "GPU-b", "1", slowdown),
+			gpuMetric(ts, "accelerator_nvidia_nvlink_replay_errors", "GPU-b", "1", float64(10*i)),
+		)
+
+		// the idle GPU with the uncorrected ECC error
+		ms = append(ms,
+			gpuMetric(ts, metricNameGPUUtilPercent, "GPU-c", "2", 0),
+			gpuMetric(ts, metricNameGraphicsMHz, "GPU-c", "2", 345),
+			gpuMetric(ts, metricNameECCUncorrected, "GPU-c", "2", float64(i/3)),
+		)
+	}
+	// not a per-GPU metric
+	ms = append(ms, pkgmetrics.Metric{UnixMilliseconds: now.UnixMilli(), Name: metricNameGPUUtilPercent, Value: 100})
+
+	scores, indexes := computeScores(cfg, now, ms)
+	require.Len(t, scores, 3)
+	assert.Equal(t, map[string]string{"GPU-a": "0", "GPU-b": "1", "GPU-c": "2"}, indexes)
+
+	a := scores["GPU-a"]
+	assert.Equal(t, now, a.Time)
+	assert.Equal(t, DefaultWindow, a.Window.Duration)
+	assert.Equal(t, 1.0, a.Capacity)
+	assert.Equal(t, 0.0, a.ThrottleRatio)
+	assert.Equal(t, 0.0, a.ECCCorrectedErrors)
+	assert.Equal(t, 100.0, a.Score)
+
+	b := scores["GPU-b"]
+	assert.Equal(t, 0.5, b.Capacity)
+	assert.Equal(t, 0.5, b.ThrottleRatio)
+	assert.Equal(t, 30.0, b.NVLinkErrors)
+	// 100 * (1 - (0.2*0.5 + 0.35*0.5 + 0.2*0.3))
+	assert.Equal(t, 66.5, b.Score)
+
+	c := scores["GPU-c"]
+	// never busy, thus not penalized by the capacity
+	assert.Equal(t, 1.0, c.Capacity)
+	assert.Equal(t, 1.0, c.ECCUncorrectedErrors)
+	assert.Equal(t, 75.0, c.Score)
+}
+
+func TestEvaluateWeights(t *testing.T) {
+	score := apiv1.GPUPerformanceScore{Capacity: 0.5, ThrottleRatio: 0.25, ECCUncorrectedErrors: 1}
+
+	// only the throttle time is weighted
+	cfg := (&Config{Weights: Weights{Throttle: 1}}).withDefaults()
+	assert.Equal(t, 75.0, evaluate(cfg, score))
+
+	// the weights need not sum to 1
+	cfg = (&Config{Weights: Weights{Capacity: 2, ECC: 2}}).withDefaults()
+	assert.Equal(t, 25.0, evaluate(cfg, score))
+}
+
+func TestIncrease(t *testing.T) {
+	assert.Equal(t, 0.0, increase(nil))
+	assert.Equal(t, 0.0, increase([]pkgmetrics.Metric{{Value: 5}}))
+
+	// out of order, and reset by the driver reload
+	assert.Equal(t, 7.0, increase([]pkgmetrics.Metric{
+		{UnixMilliseconds: 3, Value: 2},
+		{UnixMilliseconds: 1, Value: 5},
+		{UnixMilliseconds: 2, Value: 10},
+		{UnixMilliseconds: 4, Value: 2},
+	}))
+}
+
+func TestScorerCompute(t *testing.T) {
+	now := time.Now().UTC()
+	store := &fakeMetricsStore{}
+	require.NoError(t, store.Record(context.Background(),
+		// outside the window
+		gpuMetric(now.Add(-2*time.Hour), "accelerator_nvidia_clock_hw_slowdown", "GPU-a", "0", 1),
+		gpuMetric(now.Add(-time.Minute), "accelerator_nvidia_clock_hw_slowdown", "GPU-a", "0", 0),
+	))
+
+	s := New(store, nil)
+	s.getTimeNowFunc = func() time.Time { return now }
+	assert.Empty(t, s.Scores())
+
+	scores, err := s.Compute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, 100.0, scores["GPU-a"].Score)
+	assert.Equal(t, scores, s.Scores())
+}
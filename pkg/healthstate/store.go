@@ -0,0 +1,110 @@
+// Package healthstate persists the last known health states per component,
+// so that the health states survive the restarts (e.g., gpud upgrades).
+package healthstate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameComponentHealthStates = "gpud_component_health_states"
+
+	columnComponent = "component"
+	columnTime      = "time"
+	columnStates    = "states"
+)
+
+// CreateTable creates the table for the component health states.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s INTEGER NOT NULL,
+	%s TEXT NOT NULL
+) WITHOUT ROWID;`, tableNameComponentHealthStates, columnComponent, columnTime, columnStates))
+	return err
+}
+
+// Store stores the last health states per component.
+// Safe for concurrent use.
+type Store struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+}
+
+// NewStore creates the health state store.
+func NewStore(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) (*Store, error) {
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create component health states table: %w", err)
+	}
+	if err := CreateTransitionsTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create component health transitions table: %w", err)
+	}
+	return &Store{dbRW: dbRW, dbRO: dbRO}, nil
+}
+
+// SaveHealthStates replaces the last health states of the component.
+func (s *Store) SaveHealthStates(ctx context.Context, component string, states apiv1.HealthStates) error {
+	b, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	var ts time.Time
+	for _, st := range states {
+		if st.Time.After(ts) {
+			ts = st.Time.Time
+		}
+	}
+
+	start := time.Now()
+	_, err = s.dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT OR REPLACE INTO %s (%s, %s, %s) VALUES (?, ?, ?)`,
+		tableNameComponentHealthStates, columnComponent, columnTime, columnStates),
+		component, ts.Unix(), string(b))
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// LoadHealthStates returns the last health states of the component,
+// or nil if none is stored.
+func (s *Store) LoadHealthStates(ctx context.Context, component string) (apiv1.HealthStates, error) {
+	start := time.Now()
+	var b string
+	err := s.dbRO.QueryRowContext(ctx, fmt.Sprintf(`
+SELECT %s FROM %s WHERE %s = ?`, columnStates, tableNameComponentHealthStates, columnComponent), component).Scan(&b)
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var states apiv1.HealthStates
+	if err := json.Unmarshal([]byte(b), &states); err != nil {
+		return nil, fmt.Errorf("failed to parse health states of %q: %w", component, err)
+	}
+	return states, nil
+}
+
+// Purge deletes the health states last updated before the given time
+// (e.g., of the components disabled or removed since),
+// and the health transitions before the given time.
+func (s *Store) Purge(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	_, err := s.dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, tableNameComponentHealthStates, columnTime), before.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+	return s.purgeTransitions(ctx, before)
+}
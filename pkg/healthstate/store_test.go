@@ -0,0 +1,105 @@
+package healthstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestStore(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	s, err := NewStore(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+
+	states, err := s.LoadHealthStates(ctx, "cpu")
+	require.NoError(t, err)
+	assert.Nil(t, states)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	saved := apiv1.HealthStates{{
+		Time:      metav1.NewTime(now),
+		Component: "cpu",
+		Name:      "cpu",
+		Health:    apiv1.HealthStateTypeUnhealthy,
+		Reason:    "too hot",
+		ExtraInfo: map[string]string{"data": "{}"},
+	}}
+	require.NoError(t, s.SaveHealthStates(ctx, "cpu", saved))
+
+	states, err = s.LoadHealthStates(ctx, "cpu")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.True(t, now.Equal(states[0].Time.Time))
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, states[0].Health)
+	assert.Equal(t, "too hot", states[0].Reason)
+	assert.Equal(t, "{}", states[0].ExtraInfo["data"])
+
+	// replaced
+	saved[0].Health = apiv1.HealthStateTypeHealthy
+	saved[0].Time = metav1.NewTime(now.Add(time.Minute))
+	require.NoError(t, s.SaveHealthStates(ctx, "cpu", saved))
+	states, err = s.LoadHealthStates(ctx, "cpu")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, states[0].Health)
+
+	require.NoError(t, s.SaveHealthStates(ctx, "memory", apiv1.HealthStates{{Time: metav1.NewTime(now.Add(-time.Hour)), Health: apiv1.HealthStateTypeHealthy}}))
+	require.NoError(t, s.Purge(ctx, now))
+	states, err = s.LoadHealthStates(ctx, "memory")
+	require.NoError(t, err)
+	assert.Nil(t, states)
+	states, err = s.LoadHealthStates(ctx, "cpu")
+	require.NoError(t, err)
+	assert.Len(t, states, 1)
+}
+
+func TestHealthTransitions(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	s, err := NewStore(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, to := range []apiv1.HealthStateType{apiv1.HealthStateTypeUnhealthy, apiv1.HealthStateTypeHealthy} {
+		require.NoError(t, s.RecordHealthTransition(ctx, apiv1.HealthTransition{
+			Time:      metav1.NewTime(now.Add(time.Duration(i-1) * time.Hour)),
+			Component: "accelerator-nvidia-temperature",
+			Name:      "accelerator-nvidia-temperature",
+			From:      apiv1.HealthStateTypeHealthy,
+			To:        to,
+			Reason:    "reason",
+		}))
+	}
+
+	transitions, err := s.HealthTransitions(ctx, now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	assert.True(t, now.Add(-time.Hour).Equal(transitions[0].Time.Time))
+	assert.Equal(t, "accelerator-nvidia-temperature", transitions[0].Component)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, transitions[0].From)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, transitions[0].To)
+	assert.Equal(t, "reason", transitions[0].Reason)
+	assert.Equal(t, apiv1.HealthStateTypeHealthy, transitions[1].To)
+
+	transitions, err = s.HealthTransitions(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+
+	require.NoError(t, s.Purge(ctx, now.Add(-time.Minute)))
+	transitions, err = s.HealthTransitions(ctx, now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.True(t, now.Equal(transitions[0].Time.Time))
+}
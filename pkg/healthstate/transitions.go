@@ -0,0 +1,96 @@
+package healthstate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameComponentHealthTransitions = "gpud_component_health_transitions"
+
+	columnName   = "name"
+	columnFrom   = "from_health"
+	columnTo     = "to_health"
+	columnReason = "reason"
+)
+
+// CreateTransitionsTable creates the table for the component health transitions.
+func CreateTransitionsTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s INTEGER NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT
+);`, tableNameComponentHealthTransitions, columnTime, columnComponent, columnName, columnFrom, columnTo, columnReason))
+	if err != nil {
+		return err
+	}
+
+	_, err = dbRW.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s);`,
+		tableNameComponentHealthTransitions, columnTime, tableNameComponentHealthTransitions, columnTime))
+	return err
+}
+
+// RecordHealthTransition records the health transition of the component health state.
+func (s *Store) RecordHealthTransition(ctx context.Context, tr apiv1.HealthTransition) error {
+	start := time.Now()
+	_, err := s.dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, ?)`,
+		tableNameComponentHealthTransitions, columnTime, columnComponent, columnName, columnFrom, columnTo, columnReason),
+		tr.Time.Unix(), tr.Component, tr.Name, string(tr.From), string(tr.To), tr.Reason)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// HealthTransitions returns the health transitions of all components since the given time,
+// in the ascending order of the time (oldest first).
+func (s *Store) HealthTransitions(ctx context.Context, since time.Time) ([]apiv1.HealthTransition, error) {
+	start := time.Now()
+	rows, err := s.dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s, %s FROM %s WHERE %s >= ? ORDER BY %s ASC`,
+		columnTime, columnComponent, columnName, columnFrom, columnTo, columnReason,
+		tableNameComponentHealthTransitions, columnTime, columnTime), since.Unix())
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var transitions []apiv1.HealthTransition
+	for rows.Next() {
+		var (
+			ts       int64
+			tr       apiv1.HealthTransition
+			from, to string
+			reason   sql.NullString
+		)
+		if err := rows.Scan(&ts, &tr.Component, &tr.Name, &from, &to, &reason); err != nil {
+			return nil, err
+		}
+		tr.Time = metav1.NewTime(time.Unix(ts, 0).UTC())
+		tr.From = apiv1.HealthStateType(from)
+		tr.To = apiv1.HealthStateType(to)
+		tr.Reason = reason.String
+		transitions = append(transitions, tr)
+	}
+	return transitions, rows.Err()
+}
+
+func (s *Store) purgeTransitions(ctx context.Context, before time.Time) error {
+	start := time.Now()
+	_, err := s.dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, tableNameComponentHealthTransitions, columnTime), before.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
@@ -0,0 +1,116 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the inode number of the initial (host) PID namespace, fixed by the kernel
+// ref. https://github.com/torvalds/linux/blob/v6.8/include/linux/proc_ns.h (PROC_PID_INIT_INO)
+const initPIDNamespace = "pid:[4026531836]"
+
+// ContainerEnvironment describes whether gpud runs in a container,
+// and which host namespaces and host paths it has access to.
+type ContainerEnvironment struct {
+	// InContainer is true if gpud runs in a container
+	// (e.g., Docker, Podman, Kubernetes pod).
+	InContainer bool `json:"in_container"`
+
+	// HostPID is true if gpud shares the PID namespace of the host
+	// (e.g., "hostPID: true" in the Kubernetes pod spec).
+	HostPID bool `json:"host_pid"`
+	// HostNetwork is true if gpud shares the network namespace of the host
+	// (e.g., "hostNetwork: true" in the Kubernetes pod spec).
+	// Only detected when sharing the host PID namespace.
+	HostNetwork bool `json:"host_network"`
+	// HostMount is true if gpud shares the mount namespace of the host.
+	// Only detected when sharing the host PID namespace.
+	HostMount bool `json:"host_mount"`
+
+	// MissingPaths is the host paths that gpud reads but are not available
+	// (e.g., not mounted into the container), in which case the dependent
+	// components run in degraded mode.
+	MissingPaths []string `json:"missing_paths,omitempty"`
+}
+
+// ContainerRequiredPaths is the host paths that gpud reads,
+// which must be mounted when running in a container.
+var ContainerRequiredPaths = []string{
+	"/dev/kmsg",
+	"/proc/sys/kernel/random/boot_id",
+	"/sys/class",
+	"/sys/bus/pci/devices",
+	"/etc/machine-id",
+}
+
+// GetContainerEnvironment detects the container environment of gpud.
+func GetContainerEnvironment() ContainerEnvironment {
+	return getContainerEnvironment("/", os.Getenv, ContainerRequiredPaths)
+}
+
+func getContainerEnvironment(root string, getenv func(string) string, requiredPaths []string) ContainerEnvironment {
+	env := ContainerEnvironment{
+		InContainer: inContainer(root, getenv),
+	}
+
+	selfPID := readNamespace(root, "self", "pid")
+	env.HostPID = selfPID == initPIDNamespace
+
+	// only when sharing the host PID namespace, the PID 1 is the host init process
+	if env.HostPID {
+		env.HostNetwork = sameNamespace(root, "net")
+		env.HostMount = sameNamespace(root, "mnt")
+	}
+
+	for _, p := range requiredPaths {
+		if _, err := os.Stat(filepath.Join(root, p)); err != nil {
+			env.MissingPaths = append(env.MissingPaths, p)
+		}
+	}
+	return env
+}
+
+func inContainer(root string, getenv func(string) string) bool {
+	// set by the Kubernetes kubelet in every pod
+	if getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+	// set by systemd-nspawn, Podman, LXC
+	if getenv("container") != "" {
+		return true
+	}
+	for _, p := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(filepath.Join(root, p)); err == nil {
+			return true
+		}
+	}
+
+	// cgroup v1 paths of the container runtimes
+	b, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup"))
+	if err != nil {
+		return false
+	}
+	s := string(b)
+	for _, kw := range []string{"docker", "kubepods", "containerd", "libpod"} {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// readNamespace returns the namespace link of the process (e.g., "pid:[4026531836]"),
+// or an empty string if not readable.
+func readNamespace(root string, pid string, ns string) string {
+	link, err := os.Readlink(filepath.Join(root, "proc", pid, "ns", ns))
+	if err != nil {
+		return ""
+	}
+	return link
+}
+
+func sameNamespace(root string, ns string) bool {
+	self := readNamespace(root, "self", ns)
+	return self != "" && self == readNamespace(root, "1", ns)
+}
@@ -0,0 +1,83 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNamespaces(t *testing.T, root string, pid string, links map[string]string) {
+	dir := filepath.Join(root, "proc", pid, "ns")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for ns, link := range links {
+		require.NoError(t, os.Symlink(link, filepath.Join(dir, ns)))
+	}
+}
+
+func noEnv(string) string { return "" }
+
+func TestGetContainerEnvironmentHost(t *testing.T) {
+	root := t.TempDir()
+	writeNamespaces(t, root, "self", map[string]string{"pid": initPIDNamespace, "net": "net:[1]", "mnt": "mnt:[2]"})
+	writeNamespaces(t, root, "1", map[string]string{"pid": initPIDNamespace, "net": "net:[1]", "mnt": "mnt:[2]"})
+	require.NoError(t, os.WriteFile(filepath.Join(root, "proc", "1", "cgroup"), []byte("0::/init.scope\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sys", "class"), 0755))
+
+	env := getContainerEnvironment(root, noEnv, []string{"/sys/class", "/dev/kmsg"})
+	assert.False(t, env.InContainer)
+	assert.True(t, env.HostPID)
+	assert.True(t, env.HostNetwork)
+	assert.True(t, env.HostMount)
+	assert.Equal(t, []string{"/dev/kmsg"}, env.MissingPaths)
+}
+
+func TestGetContainerEnvironmentPod(t *testing.T) {
+	root := t.TempDir()
+	// hostPID and hostNetwork, but not the host mount namespace
+	writeNamespaces(t, root, "self", map[string]string{"pid": initPIDNamespace, "net": "net:[1]", "mnt": "mnt:[3]"})
+	writeNamespaces(t, root, "1", map[string]string{"pid": initPIDNamespace, "net": "net:[1]", "mnt": "mnt:[2]"})
+
+	env := getContainerEnvironment(root, func(k string) string {
+		if k == "KUBERNETES_SERVICE_HOST" {
+			return "10.0.0.1"
+		}
+		return ""
+	}, nil)
+	assert.True(t, env.InContainer)
+	assert.True(t, env.HostPID)
+	assert.True(t, env.HostNetwork)
+	assert.False(t, env.HostMount)
+	assert.Empty(t, env.MissingPaths)
+}
+
+func TestGetContainerEnvironmentIsolated(t *testing.T) {
+	root := t.TempDir()
+	writeNamespaces(t, root, "self", map[string]string{"pid": "pid:[4026532000]", "net": "net:[1]"})
+	writeNamespaces(t, root, "1", map[string]string{"pid": "pid:[4026532000]", "net": "net:[1]"})
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".dockerenv"), nil, 0644))
+
+	env := getContainerEnvironment(root, noEnv, nil)
+	assert.True(t, env.InContainer)
+	assert.False(t, env.HostPID)
+	// not detected without the host PID namespace
+	assert.False(t, env.HostNetwork)
+	assert.False(t, env.HostMount)
+}
+
+func TestInContainerCgroup(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "proc", "1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "proc", "1", "cgroup"), []byte("12:pids:/kubepods/besteffort/pod1234\n"), 0644))
+	assert.True(t, inContainer(root, noEnv))
+
+	assert.True(t, inContainer(t.TempDir(), func(k string) string {
+		if k == "container" {
+			return "podman"
+		}
+		return ""
+	}))
+	assert.False(t, inContainer(t.TempDir(), noEnv))
+}
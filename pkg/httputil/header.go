@@ -8,4 +8,5 @@ const (
 
 	RequestHeaderAcceptEncoding = "Accept-Encoding"
 	RequestHeaderEncodingGzip   = "gzip"
+	RequestHeaderEncodingZstd   = "zstd"
 )
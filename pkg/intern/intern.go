@@ -0,0 +1,54 @@
+// Package intern interns the repeated strings (e.g., the component names,
+// the metric names, and the label keys and values), so that the in-memory
+// metrics and events share one copy of each distinct string.
+package intern
+
+import "unique"
+
+// String returns the canonical copy of the string.
+// The canonical copies are garbage collected once no longer referenced.
+func String(s string) string {
+	if s == "" {
+		return ""
+	}
+	return unique.Make(s).Value()
+}
+
+// Labels returns the copy of the labels with the interned keys and values,
+// or nil if the labels are empty.
+func Labels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	interned := make(map[string]string, len(labels))
+	for k, v := range labels {
+		interned[String(k)] = String(v)
+	}
+	return interned
+}
+
+// Table interns the strings within a single pass (e.g., scanning the rows of a query),
+// without allocating for the strings already seen in the pass.
+type Table map[string]string
+
+// String returns the canonical copy of the string.
+func (t Table) String(s string) string {
+	if v, ok := t[s]; ok {
+		return v
+	}
+	v := String(s)
+	t[v] = v
+	return v
+}
+
+// Bytes returns the canonical copy of the bytes as a string.
+// The bytes are not retained, thus can be reused (e.g., "database/sql.RawBytes").
+func (t Table) Bytes(b []byte) string {
+	// no allocation for the map lookup with the converted key
+	if v, ok := t[string(b)]; ok {
+		return v
+	}
+	v := String(string(b))
+	t[v] = v
+	return v
+}
@@ -0,0 +1,68 @@
+package intern
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sameData(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "", String(""))
+
+	a := String(string([]byte("accelerator-nvidia-temperature")))
+	b := String(string([]byte("accelerator-nvidia-temperature")))
+	assert.Equal(t, "accelerator-nvidia-temperature", a)
+	assert.True(t, sameData(a, b))
+}
+
+func TestLabels(t *testing.T) {
+	assert.Nil(t, Labels(nil))
+	assert.Nil(t, Labels(map[string]string{}))
+
+	in := map[string]string{string([]byte("uuid")): string([]byte("GPU-0"))}
+	out := Labels(in)
+	assert.Equal(t, in, out)
+	for k, v := range out {
+		assert.True(t, sameData(k, String("uuid")))
+		assert.True(t, sameData(v, String("GPU-0")))
+	}
+
+	// not modified in place
+	out["uuid"] = "GPU-1"
+	assert.Equal(t, "GPU-0", in["uuid"])
+}
+
+func TestTable(t *testing.T) {
+	tbl := make(Table)
+	b := []byte("gpud_component")
+	a := tbl.Bytes(b)
+	assert.Equal(t, "gpud_component", a)
+
+	// the bytes are not retained
+	copy(b, "xxxx")
+	assert.Equal(t, "gpud_component", a)
+
+	assert.True(t, sameData(a, tbl.Bytes([]byte("gpud_component"))))
+	assert.True(t, sameData(a, tbl.String(string([]byte("gpud_component")))))
+	assert.True(t, sameData(a, String("gpud_component")))
+	assert.Len(t, tbl, 1)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = tbl.Bytes([]byte("gpud_component"))
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkTableBytes(b *testing.B) {
+	raw := [][]byte{[]byte("accelerator-nvidia-temperature"), []byte("accelerator_nvidia_temperature_current_celsius"), []byte(`{"uuid":"GPU-0"}`)}
+	tbl := make(Table)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = tbl.Bytes(raw[i%len(raw)])
+	}
+}
@@ -0,0 +1,60 @@
+// Package jobs runs the long-running background operations (e.g., the state
+// database compaction, the burn-in tests) as the jobs, whose records are persisted
+// in the state database, so that their progress and results are visible
+// (and the running jobs can be canceled) through the API.
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	// StateRunning means the job is running.
+	StateRunning State = "running"
+	// StateSucceeded means the job finished without an error.
+	StateSucceeded State = "succeeded"
+	// StateFailed means the job finished with an error,
+	// or was interrupted by the restart of GPUd.
+	StateFailed State = "failed"
+	// StateCanceled means the job was canceled before it finished.
+	StateCanceled State = "canceled"
+)
+
+// Finished returns true if the job is no longer running.
+func (s State) Finished() bool {
+	return s != StateRunning
+}
+
+// Job is the record of a background job.
+type Job struct {
+	// ID is the unique ID of the job.
+	ID string `json:"id"`
+	// Type is the type of the operation (e.g., "compact").
+	Type string `json:"type"`
+	// State is the state of the job.
+	State State `json:"state"`
+	// Progress is the percentage of the job done, in [0, 100].
+	Progress float64 `json:"progress"`
+	// Message is the last progress message of the job.
+	Message string `json:"message,omitempty"`
+	// Error is the error of the failed job.
+	Error string `json:"error,omitempty"`
+
+	// CreatedAt is when the job started.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the job record was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+	// FinishedAt is when the job finished, nil if running.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ProgressFunc reports the progress of a job, as the percentage in [0, 100]
+// (clamped if out of the range) and the optional message.
+type ProgressFunc func(progress float64, message string)
+
+// Func is the operation of a job, which must return once the context is canceled.
+type Func func(ctx context.Context, report ProgressFunc) error
@@ -0,0 +1,293 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// DefaultRetention is how long the finished jobs are kept.
+	DefaultRetention = 7 * 24 * time.Hour
+
+	// progressPersistInterval is the minimum interval between persisting the progress
+	// of a running job, so that the frequent progress reports do not flood the database
+	// (the latest progress is always served from the memory).
+	progressPersistInterval = 5 * time.Second
+	// persistTimeout is the timeout of persisting a job record.
+	persistTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNotFound is returned when the job does not exist.
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when canceling a job that already finished.
+	ErrFinished = errors.New("job already finished")
+	// ErrAlreadyRunning is returned when starting a job while a job of the same type is running.
+	ErrAlreadyRunning = errors.New("job of the same type already running")
+	// ErrInterrupted is the error of the jobs that were running when GPUd stopped.
+	ErrInterrupted = errors.New("interrupted by the gpud restart")
+)
+
+// Manager runs the background jobs, with the job records persisted in the database,
+// so that the finished jobs remain visible across the restarts until the retention.
+// Safe for concurrent use.
+type Manager struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	retention      time.Duration
+	getTimeNowFunc func() time.Time
+
+	mu   sync.RWMutex
+	jobs map[string]*entry
+}
+
+// entry is a job tracked by the manager.
+type entry struct {
+	job Job
+
+	// cancel cancels the context of the running job, nil if loaded from the database
+	cancel context.CancelFunc
+	// canceled is true if the job was canceled by Cancel
+	canceled bool
+	// persistedAt is when the job record was last persisted
+	persistedAt time.Time
+}
+
+// NewManager creates the job manager, with the stored jobs loaded.
+// The jobs left running by the previous process are marked as failed.
+func NewManager(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) (*Manager, error) {
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create jobs table: %w", err)
+	}
+
+	m := &Manager{
+		dbRW:      dbRW,
+		dbRO:      dbRO,
+		retention: DefaultRetention,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		jobs: make(map[string]*entry),
+	}
+
+	// truncated to the seconds stored in the database
+	now := m.getTimeNowFunc().Truncate(time.Second)
+	if err := Purge(ctx, dbRW, now.Add(-m.retention)); err != nil {
+		return nil, fmt.Errorf("failed to purge jobs: %w", err)
+	}
+	jobs, err := Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs: %w", err)
+	}
+	for _, j := range jobs {
+		if !j.State.Finished() {
+			j.State = StateFailed
+			j.Error = ErrInterrupted.Error()
+			j.UpdatedAt = now
+			j.FinishedAt = &now
+			if err := Upsert(ctx, dbRW, j); err != nil {
+				return nil, fmt.Errorf("failed to update interrupted job %q: %w", j.ID, err)
+			}
+			log.Logger.Warnw("marked interrupted job as failed", "id", j.ID, "type", j.Type)
+		}
+		m.jobs[j.ID] = &entry{job: j, persistedAt: now}
+	}
+
+	return m, nil
+}
+
+// Start starts the job of the type running the function in the background,
+// and returns the job record. The jobs of the same type do not overlap,
+// and ErrAlreadyRunning is returned while a job of the type is running.
+//
+// The job context is derived from the given context, thus pass the long-lived
+// context (e.g., the server root context) rather than the request context.
+func (m *Manager) Start(ctx context.Context, jobType string, f Func) (Job, error) {
+	if jobType == "" {
+		return Job{}, errors.New("job type is required")
+	}
+
+	now := m.getTimeNowFunc().Truncate(time.Second)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.jobs {
+		if e.job.Type == jobType && !e.job.State.Finished() {
+			return Job{}, fmt.Errorf("%w (type %q, id %q)", ErrAlreadyRunning, jobType, e.job.ID)
+		}
+	}
+
+	j := Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		State:     StateRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := Upsert(ctx, m.dbRW, j); err != nil {
+		return Job{}, err
+	}
+
+	cctx, ccancel := context.WithCancel(ctx)
+	e := &entry{job: j, cancel: ccancel, persistedAt: now}
+	m.jobs[j.ID] = e
+	m.purgeLocked(ctx, now)
+
+	log.Logger.Infow("started job", "id", j.ID, "type", j.Type)
+	go m.run(cctx, e, f)
+
+	return j, nil
+}
+
+func (m *Manager) run(ctx context.Context, e *entry, f Func) {
+	defer e.cancel()
+
+	err := f(ctx, func(progress float64, message string) {
+		m.report(e, progress, message)
+	})
+	m.finish(e, err)
+}
+
+// report updates the progress of the running job.
+func (m *Manager) report(e *entry, progress float64, message string) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+
+	now := m.getTimeNowFunc().Truncate(time.Second)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e.job.State.Finished() {
+		return
+	}
+	e.job.Progress = progress
+	e.job.Message = message
+	e.job.UpdatedAt = now
+
+	if now.Sub(e.persistedAt) >= progressPersistInterval {
+		m.persistLocked(e, now)
+	}
+}
+
+// finish records the result of the job.
+func (m *Manager) finish(e *entry, err error) {
+	now := m.getTimeNowFunc().Truncate(time.Second)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case e.canceled:
+		e.job.State = StateCanceled
+	case err != nil:
+		e.job.State = StateFailed
+		e.job.Error = err.Error()
+	default:
+		e.job.State = StateSucceeded
+		e.job.Progress = 100
+	}
+	e.job.UpdatedAt = now
+	e.job.FinishedAt = &now
+	m.persistLocked(e, now)
+
+	log.Logger.Infow("finished job", "id", e.job.ID, "type", e.job.Type, "state", e.job.State, "error", e.job.Error)
+}
+
+// persistLocked persists the job record, independent of the job context
+// that may already be canceled.
+// The persist failure is not fatal, to be retried on the next update.
+func (m *Manager) persistLocked(e *entry, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	if err := Upsert(ctx, m.dbRW, e.job); err != nil {
+		log.Logger.Warnw("failed to persist job", "id", e.job.ID, "error", err)
+		return
+	}
+	e.persistedAt = now
+}
+
+// Cancel cancels the running job, and returns the job record.
+// The job state becomes canceled once its function returns.
+// It returns ErrNotFound if the job does not exist,
+// or ErrFinished if the job already finished.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if e.job.State.Finished() || e.cancel == nil {
+		return e.job, ErrFinished
+	}
+
+	e.canceled = true
+	e.cancel()
+
+	log.Logger.Infow("canceling job", "id", e.job.ID, "type", e.job.Type)
+	return e.job, nil
+}
+
+// Get returns the job, and false if the job does not exist.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List returns the jobs of the type (all jobs if empty), the latest first.
+func (m *Manager) List(jobType string) []Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		if jobType == "" || e.job.Type == jobType {
+			jobs = append(jobs, e.job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs
+}
+
+// purgeLocked deletes the jobs finished before the retention.
+// The purge failure is not fatal, to be retried on the next call.
+func (m *Manager) purgeLocked(ctx context.Context, now time.Time) {
+	before := now.Add(-m.retention)
+	for id, e := range m.jobs {
+		if e.job.FinishedAt != nil && !e.job.FinishedAt.After(before) {
+			delete(m.jobs, id)
+		}
+	}
+	if err := Purge(ctx, m.dbRW, before); err != nil {
+		log.Logger.Warnw("failed to purge jobs", "error", err)
+	}
+}
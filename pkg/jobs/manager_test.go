@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func waitFinished(t *testing.T, m *Manager, id string) Job {
+	var j Job
+	require.Eventually(t, func() bool {
+		var ok bool
+		j, ok = m.Get(id)
+		return ok && j.State.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return j
+}
+
+func TestManager(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	assert.Empty(t, m.List(""))
+
+	_, err = m.Start(ctx, "", func(context.Context, ProgressFunc) error { return nil })
+	assert.Error(t, err)
+
+	// succeeded
+	progressed := make(chan struct{})
+	release := make(chan struct{})
+	j1, err := m.Start(ctx, "compact", func(ctx context.Context, report ProgressFunc) error {
+		report(150, "compacting")
+		close(progressed)
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, j1.State)
+	assert.NotEmpty(t, j1.ID)
+
+	<-progressed
+	got, ok := m.Get(j1.ID)
+	require.True(t, ok)
+	assert.Equal(t, StateRunning, got.State)
+	assert.Equal(t, float64(100), got.Progress)
+	assert.Equal(t, "compacting", got.Message)
+
+	// the jobs of the same type do not overlap
+	_, err = m.Start(ctx, "compact", func(context.Context, ProgressFunc) error { return nil })
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+
+	close(release)
+	got = waitFinished(t, m, j1.ID)
+	assert.Equal(t, StateSucceeded, got.State)
+	require.NotNil(t, got.FinishedAt)
+	_, err = m.Cancel(j1.ID)
+	assert.ErrorIs(t, err, ErrFinished)
+
+	// failed
+	j2, err := m.Start(ctx, "diagnose", func(context.Context, ProgressFunc) error {
+		return errors.New("collect failed")
+	})
+	require.NoError(t, err)
+	got = waitFinished(t, m, j2.ID)
+	assert.Equal(t, StateFailed, got.State)
+	assert.Equal(t, "collect failed", got.Error)
+
+	// canceled
+	started := make(chan struct{})
+	j3, err := m.Start(ctx, "burn-in", func(ctx context.Context, report ProgressFunc) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+	_, err = m.Cancel(j3.ID)
+	require.NoError(t, err)
+	got = waitFinished(t, m, j3.ID)
+	assert.Equal(t, StateCanceled, got.State)
+
+	_, err = m.Cancel("unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, ok = m.Get("unknown")
+	assert.False(t, ok)
+
+	assert.Len(t, m.List(""), 3)
+	list := m.List("diagnose")
+	require.Len(t, list, 1)
+	assert.Equal(t, j2.ID, list[0].ID)
+
+	// persisted across the restarts
+	m2, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, m.List(""), m2.List(""))
+}
+
+func TestManagerInterrupted(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, CreateTable(ctx, dbRW))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, Upsert(ctx, dbRW, Job{ID: "a", Type: "compact", State: StateRunning, Progress: 50, CreatedAt: now, UpdatedAt: now}))
+
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	j, ok := m.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, StateFailed, j.State)
+	assert.Equal(t, ErrInterrupted.Error(), j.Error)
+	require.NotNil(t, j.FinishedAt)
+
+	// a new job of the type can start
+	_, err = m.Start(ctx, "compact", func(context.Context, ProgressFunc) error { return nil })
+	assert.NoError(t, err)
+}
+
+func TestManagerPurge(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, CreateTable(ctx, dbRW))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := now.Add(-DefaultRetention - time.Hour)
+	recent := now.Add(-time.Hour)
+	require.NoError(t, Upsert(ctx, dbRW, Job{ID: "old", Type: "compact", State: StateSucceeded, CreatedAt: old, UpdatedAt: old, FinishedAt: &old}))
+	require.NoError(t, Upsert(ctx, dbRW, Job{ID: "recent", Type: "compact", State: StateSucceeded, CreatedAt: recent, UpdatedAt: recent, FinishedAt: &recent}))
+
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	list := m.List("")
+	require.Len(t, list, 1)
+	assert.Equal(t, "recent", list[0].ID)
+	assert.Equal(t, recent, *list[0].FinishedAt)
+}
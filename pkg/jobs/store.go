@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameJobs = "gpud_jobs"
+
+	columnID         = "id"
+	columnType       = "type"
+	columnState      = "state"
+	columnProgress   = "progress"
+	columnMessage    = "message"
+	columnError      = "error"
+	columnCreatedAt  = "created_at"
+	columnUpdatedAt  = "updated_at"
+	columnFinishedAt = "finished_at"
+)
+
+// CreateTable creates the table for the jobs.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s REAL NOT NULL,
+	%s TEXT,
+	%s TEXT,
+	%s INTEGER NOT NULL,
+	%s INTEGER NOT NULL,
+	%s INTEGER
+);`, tableNameJobs, columnID, columnType, columnState, columnProgress, columnMessage, columnError, columnCreatedAt, columnUpdatedAt, columnFinishedAt))
+	return err
+}
+
+// Upsert inserts or updates the job by its ID.
+func Upsert(ctx context.Context, dbRW *sql.DB, j Job) error {
+	var finishedAt sql.NullInt64
+	if j.FinishedAt != nil {
+		finishedAt = sql.NullInt64{Int64: j.FinishedAt.Unix(), Valid: true}
+	}
+
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT OR REPLACE INTO %s (%s, %s, %s, %s, %s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		tableNameJobs, columnID, columnType, columnState, columnProgress, columnMessage, columnError, columnCreatedAt, columnUpdatedAt, columnFinishedAt),
+		j.ID, j.Type, string(j.State), j.Progress, j.Message, j.Error, j.CreatedAt.Unix(), j.UpdatedAt.Unix(), finishedAt)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Purge deletes the jobs that finished at or before the given time.
+func Purge(ctx context.Context, dbRW *sql.DB, finishedAt time.Time) error {
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s IS NOT NULL AND %s <= ?`, tableNameJobs, columnFinishedAt, columnFinishedAt), finishedAt.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Read returns all the stored jobs, the latest first.
+func Read(ctx context.Context, dbRO *sql.DB) ([]Job, error) {
+	start := time.Now()
+	rows, err := dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s, %s, %s, %s, %s FROM %s
+ORDER BY %s DESC, %s ASC`,
+		columnID, columnType, columnState, columnProgress, columnMessage, columnError, columnCreatedAt, columnUpdatedAt, columnFinishedAt, tableNameJobs,
+		columnCreatedAt, columnID))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		var state string
+		var message, errMsg sql.NullString
+		var createdAtUnix, updatedAtUnix int64
+		var finishedAtUnix sql.NullInt64
+		if err := rows.Scan(&j.ID, &j.Type, &state, &j.Progress, &message, &errMsg, &createdAtUnix, &updatedAtUnix, &finishedAtUnix); err != nil {
+			return nil, err
+		}
+		j.State = State(state)
+		j.Message = message.String
+		j.Error = errMsg.String
+		j.CreatedAt = time.Unix(createdAtUnix, 0).UTC()
+		j.UpdatedAt = time.Unix(updatedAtUnix, 0).UTC()
+		if finishedAtUnix.Valid {
+			t := time.Unix(finishedAtUnix.Int64, 0).UTC()
+			j.FinishedAt = &t
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
@@ -102,7 +102,7 @@ func TestReadAll_SyscallConnError_WithMockey(t *testing.T) {
 			return nil, errors.New("syscallconn failed")
 		}).Build()
 
-		_, err = readAll(tmp, time.Unix(0, 0), nil)
+		_, err = readAll(tmp, time.Unix(0, 0), "", nil)
 		require.Error(t, err)
 	})
 }
@@ -120,7 +120,7 @@ func TestReadAll_ControlError_WithMockey(t *testing.T) {
 			return rawConnControlError{err: errors.New("control failed")}, nil
 		}).Build()
 
-		_, err = readAll(tmp, time.Unix(0, 0), nil)
+		_, err = readAll(tmp, time.Unix(0, 0), "", nil)
 		require.Error(t, err)
 	})
 }
@@ -138,7 +138,7 @@ func TestReadAll_SetNonblockError_WithMockey(t *testing.T) {
 			return errors.New("setnonblock failed")
 		}).Build()
 
-		_, err = readAll(tmp, time.Unix(0, 0), nil)
+		_, err = readAll(tmp, time.Unix(0, 0), "", nil)
 		require.Error(t, err)
 	})
 }
@@ -172,7 +172,7 @@ func TestReadAll_SuccessWithDeduper_WithMockey(t *testing.T) {
 			}
 		}).Build()
 
-		msgs, err := readAll(tmp, time.Unix(0, 0), newDeduper(time.Minute, time.Minute))
+		msgs, err := readAll(tmp, time.Unix(0, 0), "", newDeduper(time.Minute, time.Minute))
 		require.NoError(t, err)
 		require.Len(t, msgs, 1)
 		require.Equal(t, "dup-message", msgs[0].Message)
@@ -195,7 +195,7 @@ func TestReadAll_ParseError_WithMockey(t *testing.T) {
 			return len(line), nil
 		}).Build()
 
-		_, err = readAll(tmp, time.Unix(0, 0), nil)
+		_, err = readAll(tmp, time.Unix(0, 0), "", nil)
 		require.Error(t, err)
 	})
 }
@@ -214,7 +214,7 @@ func TestReadAll_UnexpectedReadError_WithMockey(t *testing.T) {
 			return 0, errors.New("read boom")
 		}).Build()
 
-		_, err = readAll(tmp, time.Unix(0, 0), nil)
+		_, err = readAll(tmp, time.Unix(0, 0), "", nil)
 		require.Error(t, err)
 	})
 }
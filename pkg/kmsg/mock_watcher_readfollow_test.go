@@ -26,7 +26,7 @@ func TestReadFollow_FileClosed_WithMockey(t *testing.T) {
 		}).Build()
 
 		msgs := make(chan Message, 1)
-		err = readFollow(tmp, time.Unix(0, 0), msgs, nil)
+		err = readFollow(tmp, time.Unix(0, 0), "", msgs, nil)
 		require.NoError(t, err)
 		_, ok := <-msgs
 		require.False(t, ok)
@@ -47,7 +47,7 @@ func TestReadFollow_UnexpectedError_WithMockey(t *testing.T) {
 		}).Build()
 
 		msgs := make(chan Message, 1)
-		err = readFollow(tmp, time.Unix(0, 0), msgs, nil)
+		err = readFollow(tmp, time.Unix(0, 0), "", msgs, nil)
 		require.Error(t, err)
 	})
 }
@@ -78,7 +78,7 @@ func TestReadFollow_DeduperSkipsDuplicates_WithMockey(t *testing.T) {
 		}).Build()
 
 		msgs := make(chan Message, 2)
-		err = readFollow(tmp, time.Unix(0, 0), msgs, newDeduper(time.Minute, time.Minute))
+		err = readFollow(tmp, time.Unix(0, 0), "", msgs, newDeduper(time.Minute, time.Minute))
 		require.NoError(t, err)
 
 		var collected []Message
@@ -87,7 +87,7 @@ func TestReadFollow_EPIPEThenEOFWithMockey(t *testing.T) {
 		}).Build()
 
 		msgs := make(chan Message, 1)
-		err = readFollow(tmp, time.Now(), msgs, nil)
+		err = readFollow(tmp, time.Now(), "", msgs, nil)
 		require.NoError(t, err)
 		_, ok := <-msgs
 		assert.False(t, ok)
@@ -115,7 +115,7 @@ func TestReadFollow_ParseErrorWithMockey(t *testing.T) {
 		}).Build()
 
 		msgs := make(chan Message, 1)
-		err = readFollow(tmp, time.Now(), msgs, nil)
+		err = readFollow(tmp, time.Now(), "", msgs, nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "malformed kmsg message")
 	})
@@ -88,10 +88,11 @@ func (w *Syncer) sync(ch <-chan Message) {
 			}
 
 			event := eventstore.Event{
-				Time:    kmsg.Timestamp.UTC(),
-				Name:    name,
-				Message: message,
-				Type:    string(apiv1.EventTypeWarning),
+				Time:     kmsg.Timestamp.UTC(),
+				Name:     name,
+				Message:  message,
+				Type:     string(apiv1.EventTypeWarning),
+				DedupKey: kmsg.DedupKey(),
 			}
 
 			// Deduplicate by parsed event name and message using the in-memory
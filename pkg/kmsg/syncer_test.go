@@ -116,6 +116,73 @@ func TestSyncer_Deduplication(t *testing.T) {
 	}
 }
 
+func TestSyncer_RestartReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test_restart_replay")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	matchFunc := func(msg string) (string, string) {
+		if msg != "" {
+			return "test_event", msg
+		}
+		return "", ""
+	}
+
+	// each syncer starts with the cold in-memory cache, as after the restart
+	sync := func(msgs ...Message) {
+		ch := make(chan Message, len(msgs))
+		for _, msg := range msgs {
+			ch <- msg
+		}
+		close(ch)
+
+		w, err := newSyncer(ctx, &mockChannelWatcher{ch: ch}, matchFunc, bucket)
+		require.NoError(t, err, "failed to create syncer")
+		defer w.Close()
+
+		require.Eventually(t, func() bool {
+			events, err := bucket.Get(ctx, time.Unix(0, 0))
+			require.NoError(t, err)
+			return len(events) >= msgs[len(msgs)-1].SequenceNumber
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	baseTime := time.Date(2026, 1, 1, 12, 30, 10, 0, time.UTC)
+	sync(
+		Message{Timestamp: metav1.NewTime(baseTime), SequenceNumber: 1, BootID: "boot-1", Message: "first"},
+		Message{Timestamp: metav1.NewTime(baseTime.Add(time.Second)), SequenceNumber: 2, BootID: "boot-1", Message: "second"},
+	)
+
+	// the timestamps of the replayed messages shift with the uptime read on the restart
+	shifted := baseTime.Add(1500 * time.Millisecond)
+	sync(
+		Message{Timestamp: metav1.NewTime(shifted), SequenceNumber: 1, BootID: "boot-1", Message: "first"},
+		Message{Timestamp: metav1.NewTime(shifted.Add(time.Second)), SequenceNumber: 2, BootID: "boot-1", Message: "second"},
+		Message{Timestamp: metav1.NewTime(shifted.Add(2 * time.Second)), SequenceNumber: 3, BootID: "boot-1", Message: "third"},
+	)
+
+	events, err := bucket.Get(ctx, time.Unix(0, 0))
+	require.NoError(t, err)
+	assert.Len(t, events, 3, "expected the replayed messages to be stored once")
+}
+
+func TestMessageDedupKey(t *testing.T) {
+	assert.Empty(t, Message{SequenceNumber: 1}.DedupKey())
+	assert.Equal(t, "kmsg-boot-1-1", Message{SequenceNumber: 1, BootID: "boot-1"}.DedupKey())
+	assert.NotEqual(t,
+		Message{SequenceNumber: 1, BootID: "boot-1"}.DedupKey(),
+		Message{SequenceNumber: 1, BootID: "boot-2"}.DedupKey(),
+	)
+}
+
 func TestSyncer_DisableDedup(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -37,6 +37,7 @@ import (
 	"github.com/shirou/gopsutil/v4/host"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/log"
 )
 
@@ -46,6 +47,12 @@ var (
 	kmsgFilePath = cmp.Or(strings.TrimSpace(os.Getenv("KMSG_FILE_PATH")), "/dev/kmsg")
 )
 
+// FilePath returns the path of the kernel message file read by the watcher,
+// "/dev/kmsg" unless overridden by the "KMSG_FILE_PATH" environment variable.
+func FilePath() string {
+	return kmsgFilePath
+}
+
 type Watcher interface {
 	// Watch starts a goroutine to read from kmsg and provide a channel of messages.
 	// Watcher is responsible for closing the channel when the watch is done.
@@ -59,6 +66,7 @@ type Watcher interface {
 type watcher struct {
 	kmsgFile *os.File
 	bootTime time.Time
+	bootID   string
 
 	// set to true when the watcher is started
 	// used to prevent redundant reads on kmsg file
@@ -76,12 +84,28 @@ type Message struct {
 	Priority       int         `json:"priority"`
 	SequenceNumber int         `json:"sequence_number"`
 	Message        string      `json:"message"`
+
+	// BootID is the boot ID of the host when the message was read,
+	// empty if unknown (e.g., the messages not read from the kmsg file).
+	BootID string `json:"boot_id,omitempty"`
 }
 
 func (m Message) DescribeTimestamp(since time.Time) string {
 	return humanize.RelTime(m.Timestamp.Time, since, "ago", "from now")
 }
 
+// DedupKey returns the deduplication key of the events of the message
+// (see "eventstore.Event.DedupKey"), from the boot ID and the sequence number,
+// which remain the same when the message is read again after the GPUd restart,
+// unlike the timestamp calculated from the uptime.
+// Returns an empty string if the boot ID is unknown.
+func (m Message) DedupKey() string {
+	if m.BootID == "" {
+		return ""
+	}
+	return "kmsg-" + m.BootID + "-" + strconv.Itoa(m.SequenceNumber)
+}
+
 // ReadAll reads all messages from the kmsg file, with no follow mode.
 func ReadAll(ctx context.Context) ([]Message, error) {
 	kmsgFile, err := os.Open(kmsgFilePath)
@@ -107,6 +131,7 @@ func ReadAll(ctx context.Context) ([]Message, error) {
 	return readAll(
 		kmsgFile,
 		bootTime,
+		pkghost.BootID(),
 		newDeduper(defaultCacheExpiration, defaultCachePurgeInterval),
 	)
 }
@@ -117,7 +142,7 @@ func ReadAll(ctx context.Context) ([]Message, error) {
 const readBufferSize = 8192
 
 // readAll reads all messages from the kmsg file, with no follow mode.
-func readAll(kmsgFile *os.File, bootTime time.Time, deduper *deduper) ([]Message, error) {
+func readAll(kmsgFile *os.File, bootTime time.Time, bootID string, deduper *deduper) ([]Message, error) {
 	rawReader, err := kmsgFile.SyscallConn()
 	if err != nil {
 		return nil, err
@@ -174,6 +199,7 @@ func readAll(kmsgFile *os.File, bootTime time.Time, deduper *deduper) ([]Message
 		if err != nil {
 			return nil, fmt.Errorf("malformed kmsg message: %w (%q)", err, line)
 		}
+		msg.BootID = bootID
 
 		if deduper != nil {
 			if occurrences := deduper.addCache(*msg); occurrences > 1 {
@@ -208,6 +234,7 @@ func NewWatcher(opts ...OpOption) (Watcher, error) {
 	return &watcher{
 		kmsgFile:    kmsgFile,
 		bootTime:    bootTime,
+		bootID:      pkghost.BootID(),
 		deduperOpts: append([]OpOption(nil), opts...),
 	}, nil
 }
@@ -227,7 +254,7 @@ func (w *watcher) Watch() (<-chan Message, error) {
 	kmsgCh := make(chan Message, 2048)
 	go func() {
 		deduper := newDeduper(defaultCacheExpiration, defaultCachePurgeInterval, w.deduperOpts...)
-		err := readFollow(w.kmsgFile, w.bootTime, kmsgCh, deduper)
+		err := readFollow(w.kmsgFile, w.bootTime, w.bootID, kmsgCh, deduper)
 		if err != nil {
 			log.Logger.Errorw("kmsg watcher error", "err", err)
 		}
@@ -241,7 +268,7 @@ func (w *watcher) Close() error {
 
 // readFollow reads messages from the kmsg file, with follow mode,
 // meaning it will continue to read the file as new messages are written to it.
-func readFollow(kmsgFile *os.File, bootTime time.Time, msgs chan<- Message, deduper *deduper) error {
+func readFollow(kmsgFile *os.File, bootTime time.Time, bootID string, msgs chan<- Message, deduper *deduper) error {
 	log.Logger.Infow("reading kmsg with follow mode")
 
 	defer close(msgs)
@@ -277,6 +304,7 @@ func readFollow(kmsgFile *os.File, bootTime time.Time, msgs chan<- Message, dedu
 		if err != nil {
 			return fmt.Errorf("malformed kmsg message: %w (%q)", err, line)
 		}
+		msg.BootID = bootID
 
 		if deduper != nil {
 			if occurrences := deduper.addCache(*msg); occurrences > 1 {
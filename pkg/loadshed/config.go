@@ -0,0 +1,86 @@
+// Package loadshed defers the expensive component checks (e.g., "nvidia-smi", "ibstat",
+// custom plugins) while the host is under the CPU or IO pressure, so that
+// the monitoring does not worsen an already overloaded node.
+package loadshed
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultCPUSomeAvg10Threshold is the default CPU pressure threshold,
+	// the share of the time in percent over the last 10 seconds
+	// that at least some tasks were waiting for the CPU.
+	DefaultCPUSomeAvg10Threshold = 80.0
+	// DefaultIOSomeAvg10Threshold is the default IO pressure threshold,
+	// the share of the time in percent over the last 10 seconds
+	// that at least some tasks were waiting for the IO.
+	DefaultIOSomeAvg10Threshold = 60.0
+
+	// DefaultInterval is the default interval to read the pressure.
+	DefaultInterval = 10 * time.Second
+	// MinInterval is the shortest interval allowed to read the pressure.
+	MinInterval = time.Second
+
+	// resumeRatio is the ratio of the threshold under which the pressure
+	// must subside to resume the checks, to not flip on the pressure around the threshold.
+	resumeRatio = 0.8
+)
+
+// Config configures the load shedding of the component checks.
+type Config struct {
+	// CPUSomeAvg10Threshold is the "some avg10" CPU pressure in percent
+	// at or above which the checks are deferred (defaults to 80).
+	// Negative to not shed on the CPU pressure.
+	CPUSomeAvg10Threshold float64 `json:"cpu_some_avg10_threshold,omitempty"`
+	// IOSomeAvg10Threshold is the "some avg10" IO pressure in percent
+	// at or above which the checks are deferred (defaults to 60).
+	// Negative to not shed on the IO pressure.
+	IOSomeAvg10Threshold float64 `json:"io_some_avg10_threshold,omitempty"`
+	// Interval is the interval to read the pressure (defaults to 10 seconds).
+	Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+// Validate validates the load shedding config.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.CPUSomeAvg10Threshold > 100 {
+		return fmt.Errorf("cpu_some_avg10_threshold must be at most 100, got %v", cfg.CPUSomeAvg10Threshold)
+	}
+	if cfg.IOSomeAvg10Threshold > 100 {
+		return fmt.Errorf("io_some_avg10_threshold must be at most 100, got %v", cfg.IOSomeAvg10Threshold)
+	}
+	if cfg.Interval.Duration < 0 {
+		return fmt.Errorf("interval must be non-negative, got %s", cfg.Interval.Duration)
+	}
+	if cfg.Interval.Duration > 0 && cfg.Interval.Duration < MinInterval {
+		return fmt.Errorf("interval must be at least %s, got %s", MinInterval, cfg.Interval.Duration)
+	}
+	return nil
+}
+
+func (cfg Config) cpuThreshold() float64 {
+	if cfg.CPUSomeAvg10Threshold == 0 {
+		return DefaultCPUSomeAvg10Threshold
+	}
+	return cfg.CPUSomeAvg10Threshold
+}
+
+func (cfg Config) ioThreshold() float64 {
+	if cfg.IOSomeAvg10Threshold == 0 {
+		return DefaultIOSomeAvg10Threshold
+	}
+	return cfg.IOSomeAvg10Threshold
+}
+
+func (cfg Config) interval() time.Duration {
+	if cfg.Interval.Duration == 0 {
+		return DefaultInterval
+	}
+	return cfg.Interval.Duration
+}
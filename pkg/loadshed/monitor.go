@@ -0,0 +1,146 @@
+package loadshed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/psi"
+)
+
+var metricShedding = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "gpud",
+		Subsystem: "load_shedding",
+		Name:      "active",
+		Help:      "set to 1 if the expensive component checks are deferred under the host CPU or IO pressure",
+	},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricShedding)
+}
+
+// Monitor reads the host CPU and IO pressure periodically,
+// and reports whether the expensive checks should be deferred.
+// Safe for concurrent use.
+type Monitor struct {
+	cfg Config
+
+	readCPUFunc    func() (*psi.Pressure, error)
+	readIOFunc     func() (*psi.Pressure, error)
+	getTimeNowFunc func() time.Time
+
+	mu       sync.RWMutex
+	shedding bool
+	reason   string
+	since    time.Time
+}
+
+// New creates the load shedding monitor.
+func New(cfg Config) *Monitor {
+	return &Monitor{
+		cfg: cfg,
+		readCPUFunc: func() (*psi.Pressure, error) {
+			return psi.Read(psi.DefaultCPUFile)
+		},
+		readIOFunc: func() (*psi.Pressure, error) {
+			return psi.Read(psi.DefaultIOFile)
+		},
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Start reads the pressure once, and then periodically in the background until the context is canceled.
+func (m *Monitor) Start(ctx context.Context) {
+	m.update()
+
+	go func() {
+		ticker := time.NewTicker(m.cfg.interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			m.update()
+		}
+	}()
+}
+
+// Shedding returns the reason and true if the expensive checks should be deferred.
+func (m *Monitor) Shedding() (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.reason, m.shedding
+}
+
+// update reads the pressure, and starts shedding once any pressure reaches its threshold.
+// Once shedding, all the pressure must subside under the resume ratio of the thresholds
+// to resume the checks.
+func (m *Monitor) update() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ratio := 1.0
+	if m.shedding {
+		ratio = resumeRatio
+	}
+
+	var reasons []string
+	for _, r := range []struct {
+		resource  string
+		threshold float64
+		read      func() (*psi.Pressure, error)
+	}{
+		{"cpu", m.cfg.cpuThreshold(), m.readCPUFunc},
+		{"io", m.cfg.ioThreshold(), m.readIOFunc},
+	} {
+		if r.threshold < 0 {
+			continue
+		}
+
+		p, err := r.read()
+		if err != nil {
+			log.Logger.Warnw("failed to read pressure", "resource", r.resource, "error", err)
+			continue
+		}
+		if p == nil {
+			// PSI not supported by the kernel
+			continue
+		}
+
+		if p.Some.Avg10 >= r.threshold*ratio {
+			reasons = append(reasons, fmt.Sprintf("%s pressure %.1f%% >= %.1f%%", r.resource, p.Some.Avg10, r.threshold*ratio))
+		}
+	}
+
+	shedding := len(reasons) > 0
+	reason := strings.Join(reasons, ", ")
+	switch {
+	case shedding && !m.shedding:
+		m.since = m.getTimeNowFunc()
+		log.Logger.Warnw("deferring expensive component checks under host pressure", "reason", reason)
+	case !shedding && m.shedding:
+		log.Logger.Infow("resuming expensive component checks as host pressure subsided", "deferredFor", m.getTimeNowFunc().Sub(m.since))
+		m.since = time.Time{}
+	}
+	m.shedding = shedding
+	m.reason = reason
+
+	if shedding {
+		metricShedding.Set(1)
+	} else {
+		metricShedding.Set(0)
+	}
+}
@@ -0,0 +1,105 @@
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/pkg/psi"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	require.NoError(t, cfg.Validate())
+	require.NoError(t, (&Config{}).Validate())
+	require.NoError(t, (&Config{CPUSomeAvg10Threshold: -1, IOSomeAvg10Threshold: 90, Interval: metav1.Duration{Duration: 5 * time.Second}}).Validate())
+	require.Error(t, (&Config{CPUSomeAvg10Threshold: 101}).Validate())
+	require.Error(t, (&Config{IOSomeAvg10Threshold: 101}).Validate())
+	require.Error(t, (&Config{Interval: metav1.Duration{Duration: -time.Second}}).Validate())
+	require.Error(t, (&Config{Interval: metav1.Duration{Duration: time.Millisecond}}).Validate())
+
+	assert.Equal(t, DefaultCPUSomeAvg10Threshold, Config{}.cpuThreshold())
+	assert.Equal(t, DefaultIOSomeAvg10Threshold, Config{}.ioThreshold())
+	assert.Equal(t, DefaultInterval, Config{}.interval())
+}
+
+func pressure(avg10 *float64) func() (*psi.Pressure, error) {
+	return func() (*psi.Pressure, error) {
+		return &psi.Pressure{Some: psi.Stats{Avg10: *avg10}}, nil
+	}
+}
+
+func TestMonitorHysteresis(t *testing.T) {
+	cpu, io := 10.0, 10.0
+	m := New(Config{})
+	m.readCPUFunc = pressure(&cpu)
+	m.readIOFunc = pressure(&io)
+
+	m.update()
+	_, shedding := m.Shedding()
+	assert.False(t, shedding)
+
+	cpu = 92
+	m.update()
+	reason, shedding := m.Shedding()
+	assert.True(t, shedding)
+	assert.Equal(t, "cpu pressure 92.0% >= 80.0%", reason)
+
+	// still shedding until under 80% of the threshold
+	cpu = 70
+	io = 50
+	m.update()
+	reason, shedding = m.Shedding()
+	assert.True(t, shedding)
+	assert.Equal(t, "cpu pressure 70.0% >= 64.0%, io pressure 50.0% >= 48.0%", reason)
+
+	io = 10
+	m.update()
+	_, shedding = m.Shedding()
+	assert.True(t, shedding)
+
+	cpu = 60
+	m.update()
+	reason, shedding = m.Shedding()
+	assert.False(t, shedding)
+	assert.Empty(t, reason)
+}
+
+func TestMonitorDisabledAndUnsupported(t *testing.T) {
+	cpu := 99.0
+	m := New(Config{CPUSomeAvg10Threshold: -1})
+	m.readCPUFunc = pressure(&cpu)
+	m.readIOFunc = func() (*psi.Pressure, error) { return nil, nil }
+
+	m.update()
+	_, shedding := m.Shedding()
+	assert.False(t, shedding)
+
+	// read errors do not shed
+	m = New(Config{})
+	m.readCPUFunc = func() (*psi.Pressure, error) { return nil, errors.New("permission denied") }
+	m.readIOFunc = func() (*psi.Pressure, error) { return nil, nil }
+	m.update()
+	_, shedding = m.Shedding()
+	assert.False(t, shedding)
+}
+
+func TestMonitorStart(t *testing.T) {
+	io := 75.0
+	m := New(Config{})
+	m.readCPUFunc = func() (*psi.Pressure, error) { return nil, nil }
+	m.readIOFunc = pressure(&io)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Start(ctx)
+
+	reason, shedding := m.Shedding()
+	assert.True(t, shedding)
+	assert.Contains(t, reason, "io pressure")
+}
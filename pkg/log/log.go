@@ -41,7 +41,8 @@ func CreateLoggerWithLumberjack(logFile string, maxSize int, logLevel zapcore.Le
 		w,
 		logLevel,
 	)
-	logger := zap.New(core)
+	// skip the gpudLogger wrapper frame to report the actual caller
+	logger := zap.New(withTail(core), zap.AddCaller(), zap.AddCallerSkip(1))
 	return newGpudLogger(logger.Sugar())
 }
 
@@ -72,7 +73,8 @@ func CreateLoggerWithConfig(config *zap.Config) *gpudLogger {
 		config = DefaultLoggerConfig()
 	}
 
-	l, err := config.Build()
+	// skip the gpudLogger wrapper frame to report the actual caller
+	l, err := config.Build(zap.WrapCore(withTail), zap.AddCallerSkip(1))
 	if err != nil {
 		panic(err)
 	}
@@ -124,7 +126,7 @@ func (l *gpudLogger) Errorw(msg string, keysAndValues ...interface{}) {
 		}
 		if err, ok := keysAndValues[i+1].(error); ok {
 			if strings.Contains(err.Error(), context.Canceled.Error()) {
-				l.Warnw(msg, keysAndValues...)
+				l.get().Warnw(msg, keysAndValues...)
 				return
 			}
 		}
@@ -187,10 +189,14 @@ func (l *gpudLogger) Fatal(args ...interface{}) {
 	l.get().Fatal(args...)
 }
 
+// With returns the underlying logger with the fields,
+// which is called directly without the gpudLogger wrapper frame.
 func (l *gpudLogger) With(args ...interface{}) *zap.SugaredLogger {
-	return l.get().With(args...)
+	return l.get().WithOptions(zap.AddCallerSkip(-1)).With(args...)
 }
 
+// Desugar returns the underlying logger,
+// which is called directly without the gpudLogger wrapper frame.
 func (l *gpudLogger) Desugar() *zap.Logger {
-	return l.get().Desugar()
+	return l.get().Desugar().WithOptions(zap.AddCallerSkip(-1))
 }
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultTailHistorySize is the number of the most recent log entries
+	// kept in memory for the log tail.
+	DefaultTailHistorySize = 1000
+
+	// fieldKeyComponent is the structured log field key for the component name.
+	fieldKeyComponent = "component"
+)
+
+// Entry is a structured log entry published to the log tail.
+type Entry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Message string
+	// CallerFile is the full path of the source file that wrote the log.
+	CallerFile string
+	// Caller is the trimmed caller (e.g., "xid/component.go:123").
+	Caller string
+	Fields map[string]any
+}
+
+// TailFilter selects the log entries to tail.
+type TailFilter struct {
+	// MinLevel is the minimum level of the entries (defaults to info).
+	MinLevel zapcore.Level
+	// Component is the component name matched against the "component" field.
+	Component string
+	// CallerDir is the source directory of the component package
+	// (e.g., "components/accelerator/nvidia/xid"), matched against the caller.
+	// The entries written by the component package rarely set
+	// the "component" field, so this matches them by the caller.
+	CallerDir string
+}
+
+// Match returns true if the entry is selected by the filter.
+func (f TailFilter) Match(e Entry) bool {
+	if e.Level < f.MinLevel {
+		return false
+	}
+	if f.Component == "" && f.CallerDir == "" {
+		return true
+	}
+	if f.Component != "" {
+		if v, ok := e.Fields[fieldKeyComponent].(string); ok && v == f.Component {
+			return true
+		}
+	}
+	if f.CallerDir != "" && e.CallerFile != "" {
+		dir := path.Dir(e.CallerFile)
+		return dir == f.CallerDir || strings.HasSuffix(dir, "/"+f.CallerDir)
+	}
+	return false
+}
+
+// tailHub fans out the log entries to the subscribers,
+// and keeps the most recent entries for the new subscribers.
+type tailHub struct {
+	mu          sync.RWMutex
+	history     []Entry
+	next        int
+	full        bool
+	subscribers map[chan Entry]struct{}
+}
+
+var defaultTailHub = newTailHub(DefaultTailHistorySize)
+
+func newTailHub(historySize int) *tailHub {
+	return &tailHub{
+		history:     make([]Entry, historySize),
+		subscribers: make(map[chan Entry]struct{}),
+	}
+}
+
+func (h *tailHub) publish(e Entry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.history) > 0 {
+		h.history[h.next] = e
+		h.next = (h.next + 1) % len(h.history)
+		if h.next == 0 {
+			h.full = true
+		}
+	}
+
+	for ch := range h.subscribers {
+		// never block the logging on a slow subscriber
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (h *tailHub) recent() []Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.full {
+		return append([]Entry(nil), h.history[:h.next]...)
+	}
+	entries := make([]Entry, 0, len(h.history))
+	entries = append(entries, h.history[h.next:]...)
+	return append(entries, h.history[:h.next]...)
+}
+
+func (h *tailHub) subscribe(bufferSize int) (<-chan Entry, func()) {
+	ch := make(chan Entry, bufferSize)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// RecentEntries returns the most recent log entries matching the filter,
+// up to the given limit (zero for all), in the order they were written.
+func RecentEntries(filter TailFilter, limit int) []Entry {
+	var entries []Entry
+	for _, e := range defaultTailHub.recent() {
+		if filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+// SubscribeEntries subscribes to the log entries written from now on.
+// The entries are dropped when the subscriber falls behind by the buffer size.
+// The returned function must be called to unsubscribe.
+func SubscribeEntries(bufferSize int) (<-chan Entry, func()) {
+	return defaultTailHub.subscribe(bufferSize)
+}
+
+var _ zapcore.Core = &tailCore{}
+
+// tailCore is a zapcore.Core that publishes the log entries to the log tail,
+// at the same levels enabled by the wrapped core.
+type tailCore struct {
+	zapcore.LevelEnabler
+	hub    *tailHub
+	fields []zapcore.Field
+}
+
+// withTail tees the core to the log tail.
+func withTail(core zapcore.Core) zapcore.Core {
+	return zapcore.NewTee(core, &tailCore{LevelEnabler: core, hub: defaultTailHub})
+}
+
+func (c *tailCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tailCore{
+		LevelEnabler: c.LevelEnabler,
+		hub:          c.hub,
+		fields:       append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *tailCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tailCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	e := Entry{
+		Time:    ent.Time,
+		Level:   ent.Level,
+		Message: ent.Message,
+		Fields:  enc.Fields,
+	}
+	if ent.Caller.Defined {
+		e.CallerFile = ent.Caller.File
+		e.Caller = ent.Caller.TrimmedPath()
+	}
+	c.hub.publish(e)
+	return nil
+}
+
+func (c *tailCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTailFilterMatch(t *testing.T) {
+	infoEntry := Entry{
+		Level:      zapcore.InfoLevel,
+		CallerFile: "/src/gpud/components/accelerator/nvidia/xid/component.go",
+	}
+	fieldEntry := Entry{
+		Level:  zapcore.WarnLevel,
+		Fields: map[string]any{"component": "accelerator-nvidia-xid"},
+	}
+
+	tests := []struct {
+		name   string
+		filter TailFilter
+		entry  Entry
+		want   bool
+	}{
+		{name: "empty filter", filter: TailFilter{}, entry: infoEntry, want: true},
+		{name: "below min level", filter: TailFilter{MinLevel: zapcore.WarnLevel}, entry: infoEntry, want: false},
+		{name: "caller dir match", filter: TailFilter{CallerDir: "components/accelerator/nvidia/xid"}, entry: infoEntry, want: true},
+		{name: "caller dir parent no match", filter: TailFilter{CallerDir: "components/accelerator/nvidia"}, entry: infoEntry, want: false},
+		{name: "caller dir partial name no match", filter: TailFilter{CallerDir: "nvidia/x"}, entry: infoEntry, want: false},
+		{name: "component field match", filter: TailFilter{Component: "accelerator-nvidia-xid"}, entry: fieldEntry, want: true},
+		{name: "component field mismatch", filter: TailFilter{Component: "accelerator-nvidia-sxid"}, entry: fieldEntry, want: false},
+		{name: "component without caller", filter: TailFilter{Component: "accelerator-nvidia-xid", CallerDir: "components/accelerator/nvidia/xid"}, entry: Entry{Level: zapcore.InfoLevel}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(tt.entry))
+		})
+	}
+}
+
+func TestTailHubRecent(t *testing.T) {
+	h := newTailHub(3)
+	assert.Empty(t, h.recent())
+
+	for _, msg := range []string{"a", "b"} {
+		h.publish(Entry{Message: msg})
+	}
+	assert.Equal(t, []string{"a", "b"}, messages(h.recent()))
+
+	for _, msg := range []string{"c", "d", "e"} {
+		h.publish(Entry{Message: msg})
+	}
+	assert.Equal(t, []string{"c", "d", "e"}, messages(h.recent()))
+}
+
+func TestTailHubSubscribe(t *testing.T) {
+	h := newTailHub(10)
+
+	ch, cancel := h.subscribe(1)
+	h.publish(Entry{Message: "first"})
+	// dropped since the subscriber buffer is full
+	h.publish(Entry{Message: "second"})
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, "first", e.Message)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the entry")
+	}
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected entry %q", e.Message)
+	default:
+	}
+
+	cancel()
+	cancel() // no-op
+	h.publish(Entry{Message: "third"})
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected entry %q after unsubscribe", e.Message)
+	default:
+	}
+}
+
+func TestLoggerPublishesToTail(t *testing.T) {
+	ch, cancel := SubscribeEntries(10)
+	defer cancel()
+
+	l := CreateLoggerWithLumberjack(filepath.Join(t.TempDir(), "test.log"), 1, zapcore.InfoLevel)
+	l.Debugw("not enabled")
+	l.With("component", "test-component").Infow("tail message", "key", "value")
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, "tail message", e.Message)
+		assert.Equal(t, zapcore.InfoLevel, e.Level)
+		assert.Equal(t, "value", e.Fields["key"])
+		assert.Equal(t, "test-component", e.Fields["component"])
+		// caller is the test, not the logger wrapper
+		assert.Equal(t, "tail_test.go", filepath.Base(e.CallerFile))
+		assert.True(t, TailFilter{CallerDir: "pkg/log"}.Match(e))
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the entry")
+	}
+
+	recent := RecentEntries(TailFilter{Component: "test-component"}, 1)
+	require.Len(t, recent, 1)
+	assert.Equal(t, "tail message", recent[0].Message)
+}
+
+func messages(entries []Entry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
@@ -0,0 +1,195 @@
+package machineinfo
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// Diff returns the structured changes from the previous to the current machine info,
+// with the list items (GPUs, block devices, network interfaces) matched by their identity,
+// so that the consumers do not need to diff the full snapshots.
+// The volatile fields (e.g., uptime, disk used bytes) are ignored.
+// Returns nil if either is nil or nothing changed.
+func Diff(prev *apiv1.MachineInfo, cur *apiv1.MachineInfo) []apiv1.MachineInfoChange {
+	if prev == nil || cur == nil {
+		return nil
+	}
+
+	d := &differ{}
+	d.value("gpudVersion", prev.GPUdVersion, cur.GPUdVersion)
+	d.value("gpuDriverVersion", prev.GPUDriverVersion, cur.GPUDriverVersion)
+	d.value("cudaVersion", prev.CUDAVersion, cur.CUDAVersion)
+	d.value("containerRuntimeVersion", prev.ContainerRuntimeVersion, cur.ContainerRuntimeVersion)
+	d.value("tailscaleVersion", prev.TailscaleVersion, cur.TailscaleVersion)
+	d.value("kernelVersion", prev.KernelVersion, cur.KernelVersion)
+	d.value("osImage", prev.OSImage, cur.OSImage)
+	d.value("operatingSystem", prev.OperatingSystem, cur.OperatingSystem)
+	d.value("systemUUID", prev.SystemUUID, cur.SystemUUID)
+	d.value("machineID", prev.MachineID, cur.MachineID)
+	d.value("bootID", prev.BootID, cur.BootID)
+	d.value("hostname", prev.Hostname, cur.Hostname)
+	d.value("appliedConfigVersion", prev.AppliedConfigVersion, cur.AppliedConfigVersion)
+
+	prevCPU, curCPU := derefOrZero(prev.CPUInfo), derefOrZero(cur.CPUInfo)
+	d.value("cpuInfo.type", prevCPU.Type, curCPU.Type)
+	d.value("cpuInfo.manufacturer", prevCPU.Manufacturer, curCPU.Manufacturer)
+	d.value("cpuInfo.architecture", prevCPU.Architecture, curCPU.Architecture)
+	d.value("cpuInfo.logicalCores", formatInt(prevCPU.LogicalCores), formatInt(curCPU.LogicalCores))
+
+	// e.g., a DIMM removed or failed
+	prevMem, curMem := derefOrZero(prev.MemoryInfo), derefOrZero(cur.MemoryInfo)
+	d.value("memoryInfo.totalBytes", formatUint(prevMem.TotalBytes), formatUint(curMem.TotalBytes))
+
+	prevGPU, curGPU := derefOrZero(prev.GPUInfo), derefOrZero(cur.GPUInfo)
+	d.value("gpuInfo.product", prevGPU.Product, curGPU.Product)
+	d.value("gpuInfo.manufacturer", prevGPU.Manufacturer, curGPU.Manufacturer)
+	d.value("gpuInfo.architecture", prevGPU.Architecture, curGPU.Architecture)
+	d.value("gpuInfo.memory", prevGPU.Memory, curGPU.Memory)
+	diffItems(d, "gpuInfo.gpus", prevGPU.GPUs, curGPU.GPUs,
+		func(gpu apiv1.MachineGPUInstance) string { return gpu.UUID },
+		func(gpu apiv1.MachineGPUInstance) string { return gpu.BusID },
+		func(field string, p apiv1.MachineGPUInstance, c apiv1.MachineGPUInstance) {
+			d.value(field+".busID", p.BusID, c.BusID)
+			d.value(field+".sn", p.SN, c.SN)
+			d.value(field+".minorID", p.MinorID, c.MinorID)
+			d.value(field+".boardID", formatUint(uint64(p.BoardID)), formatUint(uint64(c.BoardID)))
+		},
+	)
+
+	prevDisk, curDisk := derefOrZero(prev.DiskInfo), derefOrZero(cur.DiskInfo)
+	d.value("diskInfo.containerRootDisk", prevDisk.ContainerRootDisk, curDisk.ContainerRootDisk)
+	diffItems(d, "diskInfo.blockDevices", prevDisk.BlockDevices, curDisk.BlockDevices,
+		func(dev apiv1.MachineDiskDevice) string { return dev.Name },
+		func(dev apiv1.MachineDiskDevice) string { return joinNonEmpty(dev.Type, dev.Model, dev.Serial) },
+		func(field string, p apiv1.MachineDiskDevice, c apiv1.MachineDiskDevice) {
+			d.value(field+".type", p.Type, c.Type)
+			d.value(field+".size", formatInt(p.Size), formatInt(c.Size))
+			d.value(field+".serial", p.Serial, c.Serial)
+			d.value(field+".wwn", p.WWN, c.WWN)
+			d.value(field+".vendor", p.Vendor, c.Vendor)
+			d.value(field+".model", p.Model, c.Model)
+			// the firmware revision of the device
+			d.value(field+".rev", p.Rev, c.Rev)
+			d.value(field+".mountPoint", p.MountPoint, c.MountPoint)
+			d.value(field+".fsType", p.FSType, c.FSType)
+			d.value(field+".partUUID", p.PartUUID, c.PartUUID)
+		},
+	)
+
+	prevNIC, curNIC := derefOrZero(prev.NICInfo), derefOrZero(cur.NICInfo)
+	diffItems(d, "nicInfo.privateIPInterfaces", prevNIC.PrivateIPInterfaces, curNIC.PrivateIPInterfaces,
+		func(iface apiv1.MachineNetworkInterface) string { return iface.Interface },
+		func(iface apiv1.MachineNetworkInterface) string { return joinNonEmpty(iface.MAC, iface.IP) },
+		func(field string, p apiv1.MachineNetworkInterface, c apiv1.MachineNetworkInterface) {
+			d.value(field+".mac", p.MAC, c.MAC)
+			d.value(field+".ip", p.IP, c.IP)
+		},
+	)
+
+	return d.changes
+}
+
+type differ struct {
+	changes []apiv1.MachineInfoChange
+}
+
+func (d *differ) value(field string, from string, to string) {
+	if from == to {
+		return
+	}
+	d.changes = append(d.changes, apiv1.MachineInfoChange{
+		Type:  apiv1.MachineInfoChangeTypeChanged,
+		Field: field,
+		From:  from,
+		To:    to,
+	})
+}
+
+// diffItems diffs the list items matched by the key, in the order of the keys,
+// with the added and removed items described by the summary.
+func diffItems[T any](
+	d *differ,
+	field string,
+	prev []T,
+	cur []T,
+	keyFunc func(T) string,
+	summaryFunc func(T) string,
+	diffFunc func(field string, p T, c T),
+) {
+	prevByKey := make(map[string]T, len(prev))
+	for _, item := range prev {
+		prevByKey[keyFunc(item)] = item
+	}
+	curByKey := make(map[string]T, len(cur))
+	for _, item := range cur {
+		curByKey[keyFunc(item)] = item
+	}
+
+	keys := make([]string, 0, len(prevByKey)+len(curByKey))
+	for k := range prevByKey {
+		keys = append(keys, k)
+	}
+	for k := range curByKey {
+		if _, ok := prevByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		itemField := field + "[" + k + "]"
+		p, inPrev := prevByKey[k]
+		c, inCur := curByKey[k]
+		switch {
+		case inPrev && !inCur:
+			d.changes = append(d.changes, apiv1.MachineInfoChange{
+				Type:  apiv1.MachineInfoChangeTypeRemoved,
+				Field: itemField,
+				From:  summaryFunc(p),
+			})
+		case !inPrev && inCur:
+			d.changes = append(d.changes, apiv1.MachineInfoChange{
+				Type:  apiv1.MachineInfoChangeTypeAdded,
+				Field: itemField,
+				To:    summaryFunc(c),
+			})
+		default:
+			diffFunc(itemField, p, c)
+		}
+	}
+}
+
+func derefOrZero[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+func formatInt(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func formatUint(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+func joinNonEmpty(vs ...string) string {
+	parts := make([]string, 0, len(vs))
+	for _, v := range vs {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,75 @@
+package machineinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestDiff(t *testing.T) {
+	prev := &apiv1.MachineInfo{
+		GPUDriverVersion: "550.54.15",
+		KernelVersion:    "5.15.0-105-generic",
+		MemoryInfo:       &apiv1.MachineMemoryInfo{TotalBytes: 2 << 40},
+		GPUInfo: &apiv1.MachineGPUInfo{
+			Product: "H100-SXM",
+			GPUs: []apiv1.MachineGPUInstance{
+				{UUID: "GPU-0", BusID: "0000:0f:00.0", SN: "1"},
+				{UUID: "GPU-1", BusID: "0000:1f:00.0", SN: "2"},
+			},
+		},
+		DiskInfo: &apiv1.MachineDiskInfo{
+			BlockDevices: []apiv1.MachineDiskDevice{
+				{Name: "/dev/nvme0n1", Type: "disk", Model: "SAMSUNG", Rev: "GDC7302Q", Used: 100},
+			},
+		},
+		NICInfo: &apiv1.MachineNICInfo{
+			PrivateIPInterfaces: []apiv1.MachineNetworkInterface{{Interface: "eth0", MAC: "aa", IP: "10.0.0.1"}},
+		},
+	}
+
+	assert.Nil(t, Diff(nil, prev))
+	assert.Nil(t, Diff(prev, nil))
+	assert.Empty(t, Diff(prev, prev))
+
+	cur := &apiv1.MachineInfo{
+		GPUDriverVersion: "570.86.10",
+		KernelVersion:    "5.15.0-105-generic",
+		MemoryInfo:       &apiv1.MachineMemoryInfo{TotalBytes: 2<<40 - 64<<30},
+		GPUInfo: &apiv1.MachineGPUInfo{
+			Product: "H100-SXM",
+			GPUs: []apiv1.MachineGPUInstance{
+				{UUID: "GPU-0", BusID: "0000:0f:00.0", SN: "1"},
+				{UUID: "GPU-2", BusID: "0000:1f:00.0", SN: "3"},
+			},
+		},
+		DiskInfo: &apiv1.MachineDiskInfo{
+			BlockDevices: []apiv1.MachineDiskDevice{
+				// the used bytes are ignored
+				{Name: "/dev/nvme0n1", Type: "disk", Model: "SAMSUNG", Rev: "GDC7402Q", Used: 200},
+			},
+		},
+		NICInfo: &apiv1.MachineNICInfo{
+			PrivateIPInterfaces: []apiv1.MachineNetworkInterface{{Interface: "eth0", MAC: "aa", IP: "10.0.0.2"}},
+		},
+	}
+
+	assert.Equal(t, []apiv1.MachineInfoChange{
+		{Type: apiv1.MachineInfoChangeTypeChanged, Field: "gpuDriverVersion", From: "550.54.15", To: "570.86.10"},
+		{Type: apiv1.MachineInfoChangeTypeChanged, Field: "memoryInfo.totalBytes", From: "2199023255552", To: "2130303778816"},
+		{Type: apiv1.MachineInfoChangeTypeRemoved, Field: "gpuInfo.gpus[GPU-1]", From: "0000:1f:00.0"},
+		{Type: apiv1.MachineInfoChangeTypeAdded, Field: "gpuInfo.gpus[GPU-2]", To: "0000:1f:00.0"},
+		{Type: apiv1.MachineInfoChangeTypeChanged, Field: "diskInfo.blockDevices[/dev/nvme0n1].rev", From: "GDC7302Q", To: "GDC7402Q"},
+		{Type: apiv1.MachineInfoChangeTypeChanged, Field: "nicInfo.privateIPInterfaces[eth0].ip", From: "10.0.0.1", To: "10.0.0.2"},
+	}, Diff(prev, cur))
+
+	// the sections missing in either snapshot
+	changes := Diff(&apiv1.MachineInfo{}, &apiv1.MachineInfo{
+		NICInfo: &apiv1.MachineNICInfo{PrivateIPInterfaces: []apiv1.MachineNetworkInterface{{Interface: "eth1", MAC: "bb"}}},
+	})
+	assert.Equal(t, []apiv1.MachineInfoChange{
+		{Type: apiv1.MachineInfoChangeTypeAdded, Field: "nicInfo.privateIPInterfaces[eth1]", To: "bb"},
+	}, changes)
+}
@@ -0,0 +1,243 @@
+package machineinfo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/infiniband/class"
+	"github.com/leptonai/gpud/pkg/disk"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/smbios"
+)
+
+// AssetClass is the class of the hardware asset.
+type AssetClass string
+
+const (
+	AssetClassSystem    AssetClass = "system"
+	AssetClassBaseboard AssetClass = "baseboard"
+	AssetClassGPU       AssetClass = "gpu"
+	AssetClassNIC       AssetClass = "nic"
+	AssetClassMemory    AssetClass = "memory"
+	AssetClassDrive     AssetClass = "drive"
+)
+
+// Asset is a hardware asset of the machine,
+// in the fields common to the asset-management systems.
+type Asset struct {
+	Class AssetClass `json:"class"`
+	// Name identifies the asset within the machine
+	// (e.g., the GPU UUID, the interface name, the DIMM locator, the block device path).
+	Name string `json:"name"`
+
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	PartNumber   string `json:"part_number,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	// FirmwareVersion is the firmware version of the asset
+	// (e.g., the BIOS for the system, the VBIOS for the GPUs, the revision for the drives).
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+
+	// Location is the position of the asset (e.g., the PCI bus ID, the memory bank).
+	Location string `json:"location,omitempty"`
+	// Address is the hardware address of the asset (e.g., the MAC address, the WWN).
+	Address   string `json:"address,omitempty"`
+	SizeBytes uint64 `json:"size_bytes,omitempty"`
+}
+
+// Inventory is the hardware inventory of the machine.
+type Inventory struct {
+	Hostname   string    `json:"hostname,omitempty"`
+	MachineID  string    `json:"machine_id,omitempty"`
+	SystemUUID string    `json:"system_uuid,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Assets []Asset `json:"assets"`
+}
+
+// InventorySources are the collected data the inventory is built from,
+// where any missing source (e.g., the SMBIOS table not readable by non-root) is skipped.
+type InventorySources struct {
+	MachineInfo *apiv1.MachineInfo
+	SMBIOS      *smbios.Tables
+	InfiniBand  class.Devices
+	// GPUFirmwareVersions maps the GPU UUID to its VBIOS version.
+	GPUFirmwareVersions map[string]string
+	Drives              disk.BlockDevices
+}
+
+// GetInventory collects the hardware inventory of the machine,
+// from the machine info and the SMBIOS, InfiniBand, and block devices.
+func GetInventory(ctx context.Context, machineID string, machineInfo *apiv1.MachineInfo, nvmlInstance nvidianvml.Instance) *Inventory {
+	src := InventorySources{MachineInfo: machineInfo}
+
+	tables, err := smbios.ReadTables(smbios.DefaultTablePath)
+	if err != nil {
+		log.Logger.Warnw("failed to read smbios table, skipping system, baseboard, and memory devices", "error", err)
+	}
+	src.SMBIOS = tables
+
+	ibDevs, err := class.LoadDevices("")
+	if err != nil {
+		log.Logger.Debugw("failed to load infiniband devices", "error", err)
+	}
+	src.InfiniBand = ibDevs
+
+	if nvmlInstance != nil && nvmlInstance.NVMLExists() {
+		src.GPUFirmwareVersions = make(map[string]string)
+		for uuid, dev := range nvmlInstance.Devices() {
+			v, ret := dev.GetVbiosVersion()
+			if ret != nvml.SUCCESS {
+				log.Logger.Warnw("failed to get vbios version", "uuid", uuid, "error", nvml.ErrorString(ret))
+				continue
+			}
+			src.GPUFirmwareVersions[uuid] = v
+		}
+	}
+
+	cctx, ccancel := context.WithTimeout(ctx, 30*time.Second)
+	drives, err := disk.GetBlockDevicesWithLsblk(cctx, disk.WithDeviceType(func(dt string) bool { return dt == "disk" }))
+	ccancel()
+	if err != nil {
+		log.Logger.Warnw("failed to list block devices, skipping drives", "error", err)
+	}
+	src.Drives = drives
+
+	return BuildInventory(machineID, src, time.Now().UTC())
+}
+
+// BuildInventory builds the inventory from the collected sources,
+// with the assets ordered by the class and the name.
+func BuildInventory(machineID string, src InventorySources, now time.Time) *Inventory {
+	inv := &Inventory{
+		MachineID: machineID,
+		CreatedAt: now,
+		Assets:    []Asset{},
+	}
+
+	info := src.MachineInfo
+	if info != nil {
+		inv.Hostname = info.Hostname
+		inv.SystemUUID = info.SystemUUID
+	}
+
+	if t := src.SMBIOS; t != nil {
+		if t.System != nil {
+			sys := Asset{
+				Class:        AssetClassSystem,
+				Name:         inv.Hostname,
+				Manufacturer: t.System.Manufacturer,
+				Model:        t.System.ProductName,
+				SerialNumber: t.System.SerialNumber,
+			}
+			if t.BIOS != nil {
+				sys.FirmwareVersion = t.BIOS.Version
+			}
+			if inv.SystemUUID == "" {
+				inv.SystemUUID = t.System.UUID
+			}
+			inv.Assets = append(inv.Assets, sys)
+		}
+		if t.Baseboard != nil {
+			inv.Assets = append(inv.Assets, Asset{
+				Class:        AssetClassBaseboard,
+				Name:         "baseboard",
+				Manufacturer: t.Baseboard.Manufacturer,
+				Model:        t.Baseboard.ProductName,
+				SerialNumber: t.Baseboard.SerialNumber,
+			})
+		}
+		for _, md := range t.MemoryDevices {
+			model := md.Type
+			if md.SpeedMTs > 0 {
+				model = strings.TrimSpace(fmt.Sprintf("%s %d MT/s", md.Type, md.SpeedMTs))
+			}
+			inv.Assets = append(inv.Assets, Asset{
+				Class:        AssetClassMemory,
+				Name:         md.Locator,
+				Manufacturer: md.Manufacturer,
+				Model:        model,
+				PartNumber:   md.PartNumber,
+				SerialNumber: md.SerialNumber,
+				Location:     md.BankLocator,
+				SizeBytes:    md.SizeBytes,
+			})
+		}
+	}
+
+	if info != nil && info.GPUInfo != nil {
+		for _, gpu := range info.GPUInfo.GPUs {
+			inv.Assets = append(inv.Assets, Asset{
+				Class:           AssetClassGPU,
+				Name:            gpu.UUID,
+				Manufacturer:    info.GPUInfo.Manufacturer,
+				Model:           info.GPUInfo.Product,
+				SerialNumber:    gpu.SN,
+				FirmwareVersion: src.GPUFirmwareVersions[gpu.UUID],
+				Location:        gpu.BusID,
+			})
+		}
+	}
+
+	if info != nil && info.NICInfo != nil {
+		for _, nic := range info.NICInfo.PrivateIPInterfaces {
+			inv.Assets = append(inv.Assets, Asset{
+				Class:   AssetClassNIC,
+				Name:    nic.Interface,
+				Address: nic.MAC,
+			})
+		}
+	}
+	for _, dev := range src.InfiniBand {
+		manufacturer := ""
+		if strings.HasPrefix(dev.Name, "mlx") {
+			manufacturer = "Mellanox"
+		}
+		inv.Assets = append(inv.Assets, Asset{
+			Class:        AssetClassNIC,
+			Name:         dev.Name,
+			Manufacturer: manufacturer,
+			Model:        dev.HCAType,
+			// the board ID is the PSID of the ConnectX adapters
+			PartNumber:      dev.BoardID,
+			FirmwareVersion: dev.FirmwareVersion,
+		})
+	}
+
+	for _, d := range src.Drives {
+		inv.Assets = append(inv.Assets, Asset{
+			Class:           AssetClassDrive,
+			Name:            d.Name,
+			Manufacturer:    strings.TrimSpace(d.Vendor),
+			Model:           strings.TrimSpace(d.Model),
+			SerialNumber:    strings.TrimSpace(d.Serial),
+			FirmwareVersion: strings.TrimSpace(d.Rev),
+			Address:         d.WWN,
+			SizeBytes:       d.Size.Uint64,
+		})
+	}
+
+	sort.SliceStable(inv.Assets, func(i, j int) bool {
+		if inv.Assets[i].Class != inv.Assets[j].Class {
+			return assetClassOrder[inv.Assets[i].Class] < assetClassOrder[inv.Assets[j].Class]
+		}
+		return inv.Assets[i].Name < inv.Assets[j].Name
+	})
+	return inv
+}
+
+var assetClassOrder = map[AssetClass]int{
+	AssetClassSystem:    0,
+	AssetClassBaseboard: 1,
+	AssetClassGPU:       2,
+	AssetClassNIC:       3,
+	AssetClassMemory:    4,
+	AssetClassDrive:     5,
+}
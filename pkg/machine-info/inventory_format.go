@@ -0,0 +1,256 @@
+package machineinfo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/leptonai/gpud/version"
+)
+
+// InventoryFormat is the output format of the hardware inventory.
+type InventoryFormat string
+
+const (
+	// InventoryFormatSPDX is the SPDX 2.3 JSON document,
+	// with each asset as a "DEVICE" package.
+	// ref. https://spdx.github.io/spdx-spec/v2.3/
+	InventoryFormatSPDX InventoryFormat = "spdx"
+	// InventoryFormatCSV is a CSV row per asset, with the header.
+	InventoryFormatCSV InventoryFormat = "csv"
+	// InventoryFormatJSONAsset is the inventory in JSON.
+	InventoryFormatJSONAsset InventoryFormat = "json-asset"
+)
+
+// ParseInventoryFormat parses the inventory format.
+func ParseInventoryFormat(s string) (InventoryFormat, error) {
+	switch f := InventoryFormat(s); f {
+	case InventoryFormatSPDX, InventoryFormatCSV, InventoryFormatJSONAsset:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported inventory format %q (supported: %s, %s, %s)", s, InventoryFormatSPDX, InventoryFormatCSV, InventoryFormatJSONAsset)
+	}
+}
+
+// Write writes the inventory in the format.
+func (inv *Inventory) Write(wr io.Writer, format InventoryFormat) error {
+	switch format {
+	case InventoryFormatSPDX:
+		return inv.WriteSPDX(wr)
+	case InventoryFormatCSV:
+		return inv.WriteCSV(wr)
+	case InventoryFormatJSONAsset:
+		return inv.WriteJSON(wr)
+	default:
+		return fmt.Errorf("unsupported inventory format %q", format)
+	}
+}
+
+// WriteJSON writes the inventory in JSON.
+func (inv *Inventory) WriteJSON(wr io.Writer) error {
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+var inventoryCSVHeader = []string{
+	"hostname",
+	"machine_id",
+	"class",
+	"name",
+	"manufacturer",
+	"model",
+	"part_number",
+	"serial_number",
+	"firmware_version",
+	"location",
+	"address",
+	"size_bytes",
+}
+
+// WriteCSV writes the inventory in CSV, a row per asset with the machine identity.
+func (inv *Inventory) WriteCSV(wr io.Writer) error {
+	w := csv.NewWriter(wr)
+	if err := w.Write(inventoryCSVHeader); err != nil {
+		return err
+	}
+	for _, a := range inv.Assets {
+		size := ""
+		if a.SizeBytes > 0 {
+			size = strconv.FormatUint(a.SizeBytes, 10)
+		}
+		if err := w.Write([]string{
+			inv.Hostname,
+			inv.MachineID,
+			string(a.Class),
+			a.Name,
+			a.Manufacturer,
+			a.Model,
+			a.PartNumber,
+			a.SerialNumber,
+			a.FirmwareVersion,
+			a.Location,
+			a.Address,
+			size,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string `json:"name"`
+	SPDXID                string `json:"SPDXID"`
+	VersionInfo           string `json:"versionInfo,omitempty"`
+	Supplier              string `json:"supplier,omitempty"`
+	DownloadLocation      string `json:"downloadLocation"`
+	FilesAnalyzed         bool   `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+	Summary               string `json:"summary,omitempty"`
+	Comment               string `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+// WriteSPDX writes the inventory as the SPDX 2.3 JSON document,
+// where the document describes the machine that contains the other assets,
+// and the asset details without the SPDX fields (e.g., the serial number)
+// are in the package comment.
+func (inv *Inventory) WriteSPDX(wr io.Writer) error {
+	name := "gpud-inventory"
+	if inv.Hostname != "" {
+		name += "-" + inv.Hostname
+	}
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "urn:uuid:" + uuid.NewString(),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.CreatedAt.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: gpud-" + version.Version},
+		},
+		Packages:      make([]spdxPackage, 0, len(inv.Assets)+1),
+		Relationships: make([]spdxRelationship, 0, len(inv.Assets)+1),
+	}
+
+	// the machine is the root package, even without the SMBIOS system info
+	rootID := "SPDXRef-Machine"
+	root := spdxPackage{
+		Name:                  inv.Hostname,
+		SPDXID:                rootID,
+		Supplier:              spdxNoAssertion,
+		DownloadLocation:      spdxNoAssertion,
+		PrimaryPackagePurpose: "DEVICE",
+		Comment:               spdxComment("machine_id", inv.MachineID, "system_uuid", inv.SystemUUID),
+	}
+	assets := inv.Assets
+	if len(assets) > 0 && assets[0].Class == AssetClassSystem {
+		root = spdxAssetPackage(rootID, assets[0])
+		root.Comment = spdxComment(
+			"machine_id", inv.MachineID,
+			"system_uuid", inv.SystemUUID,
+			"serial_number", assets[0].SerialNumber,
+		)
+		assets = assets[1:]
+	}
+	if root.Name == "" {
+		root.Name = "machine"
+	}
+	doc.Packages = append(doc.Packages, root)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{
+		SPDXElementID:      doc.SPDXID,
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: rootID,
+	})
+
+	for i, a := range assets {
+		id := fmt.Sprintf("SPDXRef-%s-%d", a.Class, i)
+		doc.Packages = append(doc.Packages, spdxAssetPackage(id, a))
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      rootID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func spdxAssetPackage(id string, a Asset) spdxPackage {
+	name := a.Name
+	if name == "" {
+		name = string(a.Class)
+	}
+	supplier := spdxNoAssertion
+	if a.Manufacturer != "" {
+		supplier = "Organization: " + a.Manufacturer
+	}
+	size := ""
+	if a.SizeBytes > 0 {
+		size = strconv.FormatUint(a.SizeBytes, 10)
+	}
+	return spdxPackage{
+		Name:                  name,
+		SPDXID:                id,
+		VersionInfo:           a.FirmwareVersion,
+		Supplier:              supplier,
+		DownloadLocation:      spdxNoAssertion,
+		PrimaryPackagePurpose: "DEVICE",
+		Summary:               strings.TrimSpace(string(a.Class) + " " + a.Model),
+		Comment: spdxComment(
+			"model", a.Model,
+			"part_number", a.PartNumber,
+			"serial_number", a.SerialNumber,
+			"location", a.Location,
+			"address", a.Address,
+			"size_bytes", size,
+		),
+	}
+}
+
+// spdxComment formats the non-empty key and value pairs,
+// as "key=value" separated by "; ".
+func spdxComment(kvs ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if kvs[i+1] == "" {
+			continue
+		}
+		parts = append(parts, kvs[i]+"="+kvs[i+1])
+	}
+	return strings.Join(parts, "; ")
+}
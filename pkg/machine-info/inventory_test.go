@@ -0,0 +1,202 @@
+package machineinfo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/infiniband/class"
+	"github.com/leptonai/gpud/pkg/disk"
+	"github.com/leptonai/gpud/pkg/smbios"
+)
+
+func testInventorySources() InventorySources {
+	return InventorySources{
+		MachineInfo: &apiv1.MachineInfo{
+			Hostname: "gpu-node-1",
+			GPUInfo: &apiv1.MachineGPUInfo{
+				Product:      "NVIDIA H100 80GB HBM3",
+				Manufacturer: "NVIDIA",
+				GPUs: []apiv1.MachineGPUInstance{
+					{UUID: "GPU-2", BusID: "0000:1b:00.0", SN: "1650123456790"},
+					{UUID: "GPU-1", BusID: "0000:0f:00.0", SN: "1650123456789"},
+				},
+			},
+			NICInfo: &apiv1.MachineNICInfo{
+				PrivateIPInterfaces: []apiv1.MachineNetworkInterface{
+					{Interface: "eth0", MAC: "00:11:22:33:44:55", IP: "10.0.0.1"},
+				},
+			},
+		},
+		SMBIOS: &smbios.Tables{
+			BIOS:      &smbios.BIOS{Vendor: "AMI", Version: "2.1a"},
+			System:    &smbios.System{Manufacturer: "Supermicro", ProductName: "SYS-821GE-TNHR", SerialNumber: "S123", UUID: "00112233-4455-6677-8899-aabbccddeeff"},
+			Baseboard: &smbios.Baseboard{Manufacturer: "Supermicro", ProductName: "X13DEG-OAD", SerialNumber: "BB123"},
+			MemoryDevices: []smbios.MemoryDevice{
+				{Locator: "DIMM_A1", BankLocator: "P0_Node0", SizeBytes: 64 << 30, Type: "DDR5", SpeedMTs: 4800, Manufacturer: "Samsung", SerialNumber: "80CE01", PartNumber: "M321R8GA0BB0"},
+			},
+		},
+		InfiniBand: class.Devices{
+			{Name: "mlx5_0", BoardID: "MT_0000000838", FirmwareVersion: "28.41.1000", HCAType: "MT4129"},
+		},
+		GPUFirmwareVersions: map[string]string{"GPU-1": "96.00.74.00.01"},
+		Drives: disk.BlockDevices{
+			{Name: "/dev/nvme0n1", Type: "disk", Size: disk.CustomUint64{Uint64: 1 << 40}, Serial: "S6XYNE0T ", Model: "SAMSUNG MZ1L21T9", Rev: "GDC7302Q", WWN: "eui.0025"},
+		},
+	}
+}
+
+func TestBuildInventory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	inv := BuildInventory("machine-1", testInventorySources(), now)
+
+	assert.Equal(t, "gpu-node-1", inv.Hostname)
+	assert.Equal(t, "machine-1", inv.MachineID)
+	assert.Equal(t, "00112233-4455-6677-8899-aabbccddeeff", inv.SystemUUID)
+	assert.Equal(t, now, inv.CreatedAt)
+
+	classes := make([]AssetClass, 0, len(inv.Assets))
+	for _, a := range inv.Assets {
+		classes = append(classes, a.Class)
+	}
+	assert.Equal(t, []AssetClass{
+		AssetClassSystem,
+		AssetClassBaseboard,
+		AssetClassGPU,
+		AssetClassGPU,
+		AssetClassNIC,
+		AssetClassNIC,
+		AssetClassMemory,
+		AssetClassDrive,
+	}, classes)
+
+	sys := inv.Assets[0]
+	assert.Equal(t, "S123", sys.SerialNumber)
+	assert.Equal(t, "2.1a", sys.FirmwareVersion)
+
+	gpu := inv.Assets[2]
+	assert.Equal(t, "GPU-1", gpu.Name)
+	assert.Equal(t, "96.00.74.00.01", gpu.FirmwareVersion)
+	assert.Equal(t, "0000:0f:00.0", gpu.Location)
+	assert.Equal(t, "", inv.Assets[3].FirmwareVersion)
+
+	ib := inv.Assets[5]
+	assert.Equal(t, "mlx5_0", ib.Name)
+	assert.Equal(t, "Mellanox", ib.Manufacturer)
+	assert.Equal(t, "28.41.1000", ib.FirmwareVersion)
+
+	dimm := inv.Assets[6]
+	assert.Equal(t, "DDR5 4800 MT/s", dimm.Model)
+	assert.Equal(t, uint64(64<<30), dimm.SizeBytes)
+
+	drive := inv.Assets[7]
+	assert.Equal(t, "S6XYNE0T", drive.SerialNumber)
+	assert.Equal(t, "GDC7302Q", drive.FirmwareVersion)
+	assert.Equal(t, uint64(1<<40), drive.SizeBytes)
+}
+
+func TestBuildInventoryEmpty(t *testing.T) {
+	t.Parallel()
+
+	inv := BuildInventory("", InventorySources{}, time.Now())
+	assert.NotNil(t, inv.Assets)
+	assert.Empty(t, inv.Assets)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, inv.WriteSPDX(buf))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	pkgs := doc["packages"].([]any)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "machine", pkgs[0].(map[string]any)["name"])
+}
+
+func TestParseInventoryFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"spdx", "csv", "json-asset"} {
+		f, err := ParseInventoryFormat(s)
+		require.NoError(t, err)
+		assert.Equal(t, InventoryFormat(s), f)
+	}
+	_, err := ParseInventoryFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestInventoryWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	inv := BuildInventory("machine-1", testInventorySources(), time.Now())
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, inv.Write(buf, InventoryFormatCSV))
+
+	records, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(inv.Assets)+1)
+	assert.Equal(t, inventoryCSVHeader, records[0])
+	assert.Equal(t, []string{"gpu-node-1", "machine-1", "system", "gpu-node-1", "Supermicro", "SYS-821GE-TNHR", "", "S123", "2.1a", "", "", ""}, records[1])
+}
+
+func TestInventoryWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	inv := BuildInventory("machine-1", testInventorySources(), time.Now().UTC())
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, inv.Write(buf, InventoryFormatJSONAsset))
+
+	var decoded Inventory
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, inv.Assets, decoded.Assets)
+}
+
+func TestInventoryWriteSPDX(t *testing.T) {
+	t.Parallel()
+
+	inv := BuildInventory("machine-1", testInventorySources(), time.Now())
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, inv.Write(buf, InventoryFormatSPDX))
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "gpud-inventory-gpu-node-1", doc.Name)
+	assert.Contains(t, doc.DocumentNamespace, "urn:uuid:")
+
+	// the system asset is the root package
+	require.Len(t, doc.Packages, len(inv.Assets))
+	root := doc.Packages[0]
+	assert.Equal(t, "SPDXRef-Machine", root.SPDXID)
+	assert.Equal(t, "Organization: Supermicro", root.Supplier)
+	assert.Equal(t, "2.1a", root.VersionInfo)
+	assert.Contains(t, root.Comment, "serial_number=S123")
+	assert.Contains(t, root.Comment, "machine_id=machine-1")
+
+	require.Len(t, doc.Relationships, len(inv.Assets))
+	assert.Equal(t, "DESCRIBES", doc.Relationships[0].RelationshipType)
+	for _, rel := range doc.Relationships[1:] {
+		assert.Equal(t, "CONTAINS", rel.RelationshipType)
+		assert.Equal(t, "SPDXRef-Machine", rel.SPDXElementID)
+	}
+
+	gpu := doc.Packages[2]
+	assert.Equal(t, "GPU-1", gpu.Name)
+	assert.Equal(t, "96.00.74.00.01", gpu.VersionInfo)
+	assert.Contains(t, gpu.Comment, "serial_number=1650123456789")
+	assert.Contains(t, gpu.Comment, "location=0000:0f:00.0")
+}
+
+func TestInventoryWriteUnsupported(t *testing.T) {
+	t.Parallel()
+
+	inv := BuildInventory("", InventorySources{}, time.Now())
+	assert.Error(t, inv.Write(bytes.NewBuffer(nil), "xml"))
+}
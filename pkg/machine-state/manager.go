@@ -0,0 +1,104 @@
+// Package machinestate manages the machine-level operational state
+// (e.g., cordoned, draining) set by the control plane or the local API,
+// so that the on-node tooling can react to the fleet-level intent.
+package machinestate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+)
+
+const (
+	// SourceControlPlane is the source of the state set by the control plane.
+	SourceControlPlane = "control-plane"
+	// SourceLocal is the source of the state set by the local API.
+	SourceLocal = "local"
+)
+
+// Manager manages the machine state persisted in the metadata table,
+// so that the state survives the restarts.
+// Safe for concurrent use.
+type Manager struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	getTimeNowFunc func() time.Time
+
+	mu    sync.RWMutex
+	state apiv1.MachineState
+}
+
+// NewManager creates the machine state manager, with the persisted state loaded.
+// The state defaults to "active" if never set.
+func NewManager(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) (*Manager, error) {
+	if err := pkgmetadata.CreateTableMetadata(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create metadata table: %w", err)
+	}
+
+	m := &Manager{
+		dbRW: dbRW,
+		dbRO: dbRO,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		state: apiv1.MachineState{State: apiv1.MachineStateActive},
+	}
+
+	raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyMachineState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read machine state: %w", err)
+	}
+	if raw != "" {
+		var st apiv1.MachineState
+		if err := json.Unmarshal([]byte(raw), &st); err != nil {
+			return nil, fmt.Errorf("failed to parse machine state %q: %w", raw, err)
+		}
+		if st.State.IsValid() {
+			m.state = st
+		}
+	}
+
+	return m, nil
+}
+
+// Get returns the current machine state.
+func (m *Manager) Get() apiv1.MachineState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Set sets and persists the machine state.
+func (m *Manager) Set(ctx context.Context, state apiv1.MachineStateType, reason string, source string) (apiv1.MachineState, error) {
+	if !state.IsValid() {
+		return apiv1.MachineState{}, fmt.Errorf("invalid machine state %q", state)
+	}
+
+	st := apiv1.MachineState{
+		State:     state,
+		Reason:    reason,
+		Source:    source,
+		UpdatedAt: m.getTimeNowFunc(),
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return apiv1.MachineState{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := pkgmetadata.SetMetadata(ctx, m.dbRW, pkgmetadata.MetadataKeyMachineState, string(b)); err != nil {
+		return apiv1.MachineState{}, fmt.Errorf("failed to persist machine state: %w", err)
+	}
+	m.state = st
+
+	return st, nil
+}
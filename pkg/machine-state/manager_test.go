@@ -0,0 +1,59 @@
+package machinestate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestManager(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	m.getTimeNowFunc = func() time.Time { return now }
+	assert.Equal(t, apiv1.MachineState{State: apiv1.MachineStateActive}, m.Get())
+
+	st, err := m.Set(ctx, apiv1.MachineStateDraining, "kernel upgrade", SourceControlPlane)
+	require.NoError(t, err)
+	assert.Equal(t, apiv1.MachineState{State: apiv1.MachineStateDraining, Reason: "kernel upgrade", Source: SourceControlPlane, UpdatedAt: now}, st)
+	assert.Equal(t, st, m.Get())
+
+	_, err = m.Set(ctx, "unknown", "", SourceLocal)
+	assert.Error(t, err)
+	assert.Equal(t, st, m.Get())
+
+	// persisted across the restarts
+	m2, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, st, m2.Get())
+}
+
+func TestNewManagerInvalidPersisted(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	// unknown states (e.g., set by a newer version) fall back to the default
+	require.NoError(t, pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyMachineState, `{"state":"retired"}`))
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, apiv1.MachineStateActive, m.Get().State)
+
+	require.NoError(t, pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyMachineState, "{"))
+	_, err = NewManager(ctx, dbRW, dbRO)
+	assert.Error(t, err)
+}
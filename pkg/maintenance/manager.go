@@ -0,0 +1,174 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// DefaultEndedRetention is how long the ended windows are kept,
+// so that the events that happened during the window remain tagged
+// for the retention of the events.
+const DefaultEndedRetention = eventstore.DefaultRetention
+
+// ErrWindowEnded is returned when adding a window that has already ended.
+var ErrWindowEnded = errors.New("maintenance window already ended")
+
+// Manager manages the maintenance windows persisted in the database,
+// so that the windows survive the restarts (e.g., planned reboots).
+// Safe for concurrent use.
+type Manager struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	endedRetention time.Duration
+	getTimeNowFunc func() time.Time
+
+	mu      sync.RWMutex
+	windows map[string]Window
+}
+
+// NewManager creates the maintenance window manager,
+// and stores the windows declared in the config.
+func NewManager(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB, configured []Window) (*Manager, error) {
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance windows table: %w", err)
+	}
+
+	m := &Manager{
+		dbRW:           dbRW,
+		dbRO:           dbRO,
+		endedRetention: DefaultEndedRetention,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		windows: make(map[string]Window),
+	}
+
+	for _, w := range configured {
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: %w", w.ID, err)
+		}
+		if err := Upsert(ctx, dbRW, normalize(w)); err != nil {
+			return nil, fmt.Errorf("failed to store maintenance window %q: %w", w.ID, err)
+		}
+	}
+
+	if err := Purge(ctx, dbRW, m.getTimeNowFunc().Add(-m.endedRetention)); err != nil {
+		return nil, fmt.Errorf("failed to purge maintenance windows: %w", err)
+	}
+	windows, err := Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance windows: %w", err)
+	}
+	for _, w := range windows {
+		m.windows[w.ID] = w
+	}
+
+	return m, nil
+}
+
+// Add adds or replaces the window with the same ID.
+func (m *Manager) Add(ctx context.Context, w Window) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	w = normalize(w)
+
+	now := m.getTimeNowFunc()
+	if w.Ended(now) {
+		return ErrWindowEnded
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := Upsert(ctx, m.dbRW, w); err != nil {
+		return err
+	}
+	m.windows[w.ID] = w
+
+	m.purgeLocked(ctx, now)
+	return nil
+}
+
+// Delete deletes the window by its ID.
+// It returns false if the window does not exist.
+func (m *Manager) Delete(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted, err := Delete(ctx, m.dbRW, id)
+	if err != nil {
+		return false, err
+	}
+	delete(m.windows, id)
+	return deleted, nil
+}
+
+// List returns the windows that have not ended yet (active or upcoming),
+// sorted by the start time.
+func (m *Manager) List() []Window {
+	now := m.getTimeNowFunc()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	windows := make([]Window, 0, len(m.windows))
+	for _, w := range m.windows {
+		if !w.Ended(now) {
+			windows = append(windows, w)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Start.Equal(windows[j].Start) {
+			return windows[i].ID < windows[j].ID
+		}
+		return windows[i].Start.Before(windows[j].Start)
+	})
+	return windows
+}
+
+// ActiveWindow returns the ID of the window that covers the component
+// at the given time, and false if the component was not under maintenance.
+// The ended windows are kept for the retention, so that the past events
+// during the windows are still matched.
+func (m *Manager) ActiveWindow(component string, t time.Time) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, w := range m.windows {
+		if w.Contains(t) && w.Covers(component) {
+			return w.ID, true
+		}
+	}
+	return "", false
+}
+
+// purgeLocked deletes the windows ended beyond the retention.
+// The purge failure is not fatal, to be retried on the next call.
+func (m *Manager) purgeLocked(ctx context.Context, now time.Time) {
+	endedBefore := now.Add(-m.endedRetention)
+	for id, w := range m.windows {
+		if w.End.Before(endedBefore) {
+			delete(m.windows, id)
+		}
+	}
+	if err := Purge(ctx, m.dbRW, endedBefore); err != nil {
+		log.Logger.Warnw("failed to purge maintenance windows", "error", err)
+	}
+}
+
+// normalize truncates the times to the seconds stored in the database.
+func normalize(w Window) Window {
+	w.Start = w.Start.Truncate(time.Second).UTC()
+	w.End = w.End.Truncate(time.Second).UTC()
+	return w
+}
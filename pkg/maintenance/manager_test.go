@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestManager(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	configured := Window{ID: "configured", Start: now.Add(-time.Hour), End: now.Add(time.Hour), Components: []string{"os"}}
+	m, err := NewManager(ctx, dbRW, dbRO, []Window{configured})
+	require.NoError(t, err)
+	m.getTimeNowFunc = func() time.Time { return now }
+
+	id, ok := m.ActiveWindow("os", now)
+	assert.True(t, ok)
+	assert.Equal(t, "configured", id)
+	_, ok = m.ActiveWindow("cpu", now)
+	assert.False(t, ok)
+
+	upcoming := Window{ID: "upcoming", Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour), Reason: "reboot"}
+	require.NoError(t, m.Add(ctx, upcoming))
+	assert.ErrorIs(t, m.Add(ctx, Window{ID: "ended", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}), ErrWindowEnded)
+	assert.Error(t, m.Add(ctx, Window{ID: "invalid"}))
+
+	list := m.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "configured", list[0].ID)
+	assert.Equal(t, "upcoming", list[1].ID)
+	assert.Equal(t, "reboot", list[1].Reason)
+
+	// persisted across the restarts
+	m2, err := NewManager(ctx, dbRW, dbRO, nil)
+	require.NoError(t, err)
+	m2.getTimeNowFunc = func() time.Time { return now }
+	assert.Equal(t, list, m2.List())
+
+	// ended windows are not listed but still matched for the past events
+	m2.getTimeNowFunc = func() time.Time { return now.Add(90 * time.Minute) }
+	list = m2.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, "upcoming", list[0].ID)
+	_, ok = m2.ActiveWindow("os", now)
+	assert.True(t, ok)
+
+	deleted, err := m2.Delete(ctx, "upcoming")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = m2.Delete(ctx, "upcoming")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	// purged after the retention
+	m2.getTimeNowFunc = func() time.Time { return now.Add(DefaultEndedRetention + 2*time.Hour) }
+	require.NoError(t, m2.Add(ctx, Window{ID: "new", Start: now.Add(DefaultEndedRetention + 2*time.Hour), End: now.Add(DefaultEndedRetention + 3*time.Hour)}))
+	_, ok = m2.ActiveWindow("os", now)
+	assert.False(t, ok)
+	stored, err := Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "new", stored[0].ID)
+}
+
+func TestNewManagerInvalidConfigured(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	_, err := NewManager(context.Background(), dbRW, dbRO, []Window{{ID: "w1"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameMaintenanceWindows = "gpud_maintenance_windows"
+
+	columnID         = "id"
+	columnStart      = "start"
+	columnEnd        = "end"
+	columnReason     = "reason"
+	columnComponents = "components"
+)
+
+// CreateTable creates the table for the maintenance windows.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s INTEGER NOT NULL,
+	%s INTEGER NOT NULL,
+	%s TEXT,
+	%s TEXT
+);`, tableNameMaintenanceWindows, columnID, columnStart, columnEnd, columnReason, columnComponents))
+	return err
+}
+
+// Upsert inserts or updates the window by its ID.
+func Upsert(ctx context.Context, dbRW *sql.DB, w Window) error {
+	components := ""
+	if len(w.Components) > 0 {
+		b, err := json.Marshal(w.Components)
+		if err != nil {
+			return err
+		}
+		components = string(b)
+	}
+
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT OR REPLACE INTO %s (%s, %s, %s, %s, %s) VALUES (?, ?, ?, ?, ?)`,
+		tableNameMaintenanceWindows, columnID, columnStart, columnEnd, columnReason, columnComponents),
+		w.ID, w.Start.Unix(), w.End.Unix(), w.Reason, components)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Delete deletes the window by its ID.
+// It returns false if the window does not exist.
+func Delete(ctx context.Context, dbRW *sql.DB, id string) (bool, error) {
+	start := time.Now()
+	res, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = ?`, tableNameMaintenanceWindows, columnID), id)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Purge deletes the windows that ended before the given time.
+func Purge(ctx context.Context, dbRW *sql.DB, endedBefore time.Time) error {
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, tableNameMaintenanceWindows, columnEnd), endedBefore.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Read returns all the stored windows, sorted by the start time.
+func Read(ctx context.Context, dbRO *sql.DB) ([]Window, error) {
+	start := time.Now()
+	rows, err := dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s, %s FROM %s
+ORDER BY %s ASC`, columnID, columnStart, columnEnd, columnReason, columnComponents, tableNameMaintenanceWindows, columnStart))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var windows []Window
+	for rows.Next() {
+		var w Window
+		var startUnix, endUnix int64
+		var reason, components sql.NullString
+		if err := rows.Scan(&w.ID, &startUnix, &endUnix, &reason, &components); err != nil {
+			return nil, err
+		}
+		w.Start = time.Unix(startUnix, 0).UTC()
+		w.End = time.Unix(endUnix, 0).UTC()
+		w.Reason = reason.String
+		if components.String != "" {
+			if err := json.Unmarshal([]byte(components.String), &w.Components); err != nil {
+				return nil, fmt.Errorf("failed to parse components of maintenance window %q: %w", w.ID, err)
+			}
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
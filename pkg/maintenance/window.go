@@ -0,0 +1,67 @@
+// Package maintenance manages the scheduled maintenance windows, during which
+// the health states and events of the components are tagged as maintenance
+// so that the planned operations (e.g., reboots) are not reported as failures.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Window is a scheduled maintenance window.
+type Window struct {
+	// ID is the unique identifier of the window.
+	ID string `json:"id"`
+	// Start is when the window starts.
+	Start time.Time `json:"start"`
+	// End is when the window ends (exclusive), after which the window expires.
+	End time.Time `json:"end"`
+	// Reason is the optional description of the maintenance (e.g., "kernel upgrade").
+	Reason string `json:"reason,omitempty"`
+	// Components is the list of the components under maintenance.
+	// Empty for all components.
+	Components []string `json:"components,omitempty"`
+}
+
+// Validate returns an error if the window is invalid.
+func (w Window) Validate() error {
+	if w.ID == "" {
+		return errors.New("id is required")
+	}
+	if w.Start.IsZero() || w.End.IsZero() {
+		return errors.New("start and end are required")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("end %s must be after start %s", w.End.Format(time.RFC3339), w.Start.Format(time.RFC3339))
+	}
+	for _, c := range w.Components {
+		if c == "" {
+			return errors.New("component name must be non-empty")
+		}
+	}
+	return nil
+}
+
+// Contains returns true if the time is within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Covers returns true if the component is under maintenance during the window.
+func (w Window) Covers(component string) bool {
+	if len(w.Components) == 0 {
+		return true
+	}
+	for _, c := range w.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// Ended returns true if the window has expired at the given time.
+func (w Window) Ended(now time.Time) bool {
+	return !now.Before(w.End)
+}
@@ -0,0 +1,50 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowValidate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		window  Window
+		wantErr bool
+	}{
+		{name: "valid", window: Window{ID: "w1", Start: now, End: now.Add(time.Hour)}},
+		{name: "missing id", window: Window{Start: now, End: now.Add(time.Hour)}, wantErr: true},
+		{name: "missing start", window: Window{ID: "w1", End: now.Add(time.Hour)}, wantErr: true},
+		{name: "end before start", window: Window{ID: "w1", Start: now, End: now.Add(-time.Hour)}, wantErr: true},
+		{name: "empty component", window: Window{ID: "w1", Start: now, End: now.Add(time.Hour), Components: []string{""}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWindowContainsCovers(t *testing.T) {
+	now := time.Now()
+	w := Window{ID: "w1", Start: now, End: now.Add(time.Hour), Components: []string{"os"}}
+
+	assert.True(t, w.Contains(now))
+	assert.True(t, w.Contains(now.Add(30*time.Minute)))
+	assert.False(t, w.Contains(now.Add(-time.Second)))
+	assert.False(t, w.Contains(now.Add(time.Hour)))
+
+	assert.True(t, w.Covers("os"))
+	assert.False(t, w.Covers("cpu"))
+	assert.True(t, Window{}.Covers("cpu"))
+
+	assert.False(t, w.Ended(now.Add(time.Minute)))
+	assert.True(t, w.Ended(now.Add(time.Hour)))
+}
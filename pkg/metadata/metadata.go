@@ -43,10 +43,34 @@ const (
 	// MetadataKeyLastSentNodeLabels stores the last successfully sent login node labels payload.
 	// The value is a canonical JSON object string, e.g. {"rack":"r42"} or {} for explicit clear.
 	MetadataKeyLastSentNodeLabels = "last_sent_node_labels"
+	// MetadataKeyLastSentMachineInfo stores the last machine info snapshot sent via the gossip,
+	// as a JSON object string, to report the changes across the restarts (e.g., driver upgrades).
+	MetadataKeyLastSentMachineInfo = "last_sent_machine_info"
+	// MetadataKeyLastGPUModes stores the last observed ECC, MIG, and persistence modes of the GPUs,
+	// as a JSON object string, to report the mode changes across the restarts.
+	MetadataKeyLastGPUModes = "last_gpu_modes"
 
 	// MetadataKeyControlPlaneLoginSuccess represents the timestamp in unix seconds
 	// when the control plane login was successful.
 	MetadataKeyControlPlaneLoginSuccess = "control_plane_login_success"
+
+	// MetadataKeyAppliedConfigVersion is the version of the last signed config
+	// pushed by the control plane and successfully applied.
+	MetadataKeyAppliedConfigVersion = "applied_config_version"
+
+	// MetadataKeyMachineState is the machine-level operational state
+	// (e.g., cordoned, draining) set by the control plane or the local API,
+	// as a JSON object string.
+	MetadataKeyMachineState = "machine_state"
+
+	// MetadataKeyLastSelfTest is the time of the last startup self-test
+	// in RFC3339Nano, written to verify the state database is writable.
+	MetadataKeyLastSelfTest = "last_self_test"
+
+	// MetadataKeyLastNVIDIADriverVersion is the last observed version of the loaded
+	// NVIDIA kernel module, or "none" if not loaded, to detect the driver upgrades
+	// across the restarts and reboots.
+	MetadataKeyLastNVIDIADriverVersion = "last_nvidia_driver_version"
 )
 
 // SetMetadata sets the value of a metadata entry.
@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/postgres"
+)
+
+// secretMetadataKeys are never mirrored to the shared database.
+//
+// The local SQLite metadata remains the source of truth, since it holds the machine identity
+// and the session credentials needed before connecting to the shared database.
+// Only the non-secret entries are mirrored to the shared PostgreSQL database,
+// so the centralized deployments can list the metadata of all the machines.
+var secretMetadataKeys = map[string]struct{}{
+	MetadataKeyToken: {},
+}
+
+// CreatePostgresTableMetadata creates the shared metadata table in the PostgreSQL database.
+func CreatePostgresTableMetadata(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT,
+	PRIMARY KEY (%s, %s)
+);`, tableNameGPUdMetadata, postgres.ColumnMachineID, columnKey, columnValue, postgres.ColumnMachineID, columnKey))
+	return err
+}
+
+// SetPostgresMetadata sets the value of a metadata entry of the machine in the shared database.
+// The secret entries (e.g., session token) are ignored.
+func SetPostgresMetadata(ctx context.Context, db *sql.DB, machineID string, key string, value string) error {
+	if machineID == "" {
+		return errors.New("machine id is empty")
+	}
+	if _, ok := secretMetadataKeys[key]; ok {
+		return nil
+	}
+
+	start := time.Now()
+	_, err := db.ExecContext(ctx, postgres.Rebind(fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)
+ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s`,
+		tableNameGPUdMetadata, postgres.ColumnMachineID, columnKey, columnValue,
+		postgres.ColumnMachineID, columnKey, columnValue, columnValue)),
+		machineID, key, value)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+
+	return err
+}
+
+// ReadPostgresAllMetadata reads all the metadata entries of the machine in the shared database.
+func ReadPostgresAllMetadata(ctx context.Context, db *sql.DB, machineID string) (map[string]string, error) {
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, postgres.Rebind(fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s = ?",
+		columnKey, columnValue, tableNameGPUdMetadata, postgres.ColumnMachineID)), machineID)
+	defer func() {
+		pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	}()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		metadata[key] = value.String
+	}
+	return metadata, rows.Err()
+}
+
+// MirrorToPostgres copies the non-secret local metadata entries
+// to the shared database, and returns the number of the copied entries.
+func MirrorToPostgres(ctx context.Context, sqliteDB *sql.DB, pgDB *sql.DB, machineID string) (int, error) {
+	if err := CreatePostgresTableMetadata(ctx, pgDB); err != nil {
+		return 0, err
+	}
+
+	local, err := ReadAllMetadata(ctx, sqliteDB)
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for key, value := range local {
+		if _, ok := secretMetadataKeys[key]; ok {
+			continue
+		}
+		if err := SetPostgresMetadata(ctx, pgDB, machineID, key, value); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestMirrorToPostgres(t *testing.T) {
+	db, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	dbRW, dbRO, sqliteCleanup := sqlite.OpenTestDB(t)
+	defer sqliteCleanup()
+
+	ctx := context.Background()
+	machineID := "test-machine-" + time.Now().Format("20060102150405.000000000")
+
+	require.NoError(t, CreateTableMetadata(ctx, dbRW))
+	require.NoError(t, SetMetadata(ctx, dbRW, MetadataKeyMachineID, machineID))
+	require.NoError(t, SetMetadata(ctx, dbRW, MetadataKeyEndpoint, "https://example.com"))
+	require.NoError(t, SetMetadata(ctx, dbRW, MetadataKeyToken, "secret"))
+
+	copied, err := MirrorToPostgres(ctx, dbRO, db, machineID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+
+	mirrored, err := ReadPostgresAllMetadata(ctx, db, machineID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		MetadataKeyMachineID: machineID,
+		MetadataKeyEndpoint:  "https://example.com",
+	}, mirrored)
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ExportConfig configures the metric families exported on "/metrics"
+// in the Prometheus text (or OpenMetrics) exposition format,
+// to limit the scrape size for the external Prometheus servers.
+type ExportConfig struct {
+	// Allow is the list of the regular expressions to fully match the metric family names
+	// to export (e.g., "gpud_.*", "accelerator_nvidia_.*").
+	// If empty, all the metric families are exported unless denied.
+	Allow []string `json:"allow,omitempty"`
+	// Deny is the list of the regular expressions to fully match the metric family names
+	// not to export (e.g., ".*_bucket"), which takes precedence over the allow list.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Validate returns an error if any of the patterns is not a valid regular expression.
+func (cfg *ExportConfig) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if _, err := compilePatterns(cfg.Allow); err != nil {
+		return fmt.Errorf("invalid allow: %w", err)
+	}
+	if _, err := compilePatterns(cfg.Deny); err != nil {
+		return fmt.Errorf("invalid deny: %w", err)
+	}
+	return nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// NewFilteredGatherer returns the gatherer of the metric families
+// allowed and not denied by the config.
+// Returns the gatherer as is if the config is nil.
+func NewFilteredGatherer(g prometheus.Gatherer, cfg *ExportConfig) (prometheus.Gatherer, error) {
+	if cfg == nil {
+		return g, nil
+	}
+	allow, err := compilePatterns(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := compilePatterns(cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &filteredGatherer{gatherer: g, allow: allow, deny: deny}, nil
+}
+
+var _ prometheus.Gatherer = &filteredGatherer{}
+
+type filteredGatherer struct {
+	gatherer prometheus.Gatherer
+	allow    []*regexp.Regexp
+	deny     []*regexp.Regexp
+}
+
+func (f *filteredGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := f.gatherer.Gather()
+
+	// the partial results are still returned on the gather errors
+	filtered := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		if f.exported(mf.GetName()) {
+			filtered = append(filtered, mf)
+		}
+	}
+	return filtered, err
+}
+
+func (f *filteredGatherer) exported(name string) bool {
+	for _, re := range f.deny {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, re := range f.allow {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportConfigValidate(t *testing.T) {
+	var cfg *ExportConfig
+	assert.NoError(t, cfg.Validate())
+	assert.NoError(t, (&ExportConfig{Allow: []string{"gpud_.*"}, Deny: []string{".*_bucket"}}).Validate())
+	assert.Error(t, (&ExportConfig{Allow: []string{"("}}).Validate())
+	assert.Error(t, (&ExportConfig{Deny: []string{"["}}).Validate())
+}
+
+func TestNewFilteredGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	for _, name := range []string{"gpud_a", "gpud_b_seconds", "accelerator_nvidia_c"} {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+		g.Set(1)
+		reg.MustRegister(g)
+	}
+
+	gather := func(cfg *ExportConfig) []string {
+		g, err := NewFilteredGatherer(reg, cfg)
+		require.NoError(t, err)
+		mfs, err := g.Gather()
+		require.NoError(t, err)
+		names := make([]string, 0, len(mfs))
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"accelerator_nvidia_c", "gpud_a", "gpud_b_seconds"}, gather(nil))
+	assert.Equal(t, []string{"gpud_a", "gpud_b_seconds"}, gather(&ExportConfig{Allow: []string{"gpud_.*"}}))
+	assert.Equal(t, []string{"gpud_a"}, gather(&ExportConfig{Allow: []string{"gpud_.*"}, Deny: []string{".*_seconds"}}))
+	// fully matched
+	assert.Empty(t, gather(&ExportConfig{Allow: []string{"gpud"}}))
+
+	_, err := NewFilteredGatherer(reg, &ExportConfig{Allow: []string{"("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The histograms are stored as the cumulative bucket counts, the sum, and the count
+// of the observations, under the metric names with the reserved suffixes
+// (e.g., "gpud_component_check_duration_seconds_bucket" with the "le" label).
+const (
+	HistogramBucketSuffix = "_bucket"
+	HistogramSumSuffix    = "_sum"
+	HistogramCountSuffix  = "_count"
+
+	// HistogramBucketLabelKey is the label key of the bucket upper bound
+	// (e.g., "0.5", "+Inf").
+	HistogramBucketLabelKey = "le"
+)
+
+// FormatBucketUpperBound formats the bucket upper bound as the label value.
+func FormatBucketUpperBound(upperBound float64) string {
+	return strconv.FormatFloat(upperBound, 'g', -1, 64)
+}
+
+// Histogram is the increase of a histogram within a window.
+type Histogram struct {
+	// Buckets is the increase of the cumulative bucket counts by the upper bound.
+	Buckets map[float64]float64
+	// Count is the increase of the number of the observations.
+	Count float64
+	// Sum is the increase of the sum of the observations.
+	Sum float64
+}
+
+// HistogramIncreases returns the increase of the histogram of the name within the
+// metrics (e.g., read since the window start) by the component, summing the series
+// of the other labels. The counter resets (e.g., the daemon restarts) are accounted
+// as in the Prometheus "increase", and the observations before the first data points
+// of each series are not counted.
+func HistogramIncreases(ms Metrics, name string) map[string]*Histogram {
+	type seriesKey struct {
+		component string
+		name      string
+		labels    string
+	}
+	type series struct {
+		component string
+		name      string
+		le        float64
+		points    Metrics
+	}
+
+	bucketName, sumName, countName := name+HistogramBucketSuffix, name+HistogramSumSuffix, name+HistogramCountSuffix
+	byKey := make(map[seriesKey]*series)
+	for _, m := range ms {
+		if m.Name != bucketName && m.Name != sumName && m.Name != countName {
+			continue
+		}
+
+		le := math.NaN()
+		if m.Name == bucketName {
+			v, err := strconv.ParseFloat(m.Labels[HistogramBucketLabelKey], 64)
+			if err != nil {
+				continue
+			}
+			le = v
+		}
+
+		k := seriesKey{component: m.Component, name: m.Name, labels: labelsKey(m.Labels)}
+		s, ok := byKey[k]
+		if !ok {
+			s = &series{component: m.Component, name: m.Name, le: le}
+			byKey[k] = s
+		}
+		s.points = append(s.points, m)
+	}
+
+	hs := make(map[string]*Histogram)
+	for _, s := range byKey {
+		h, ok := hs[s.component]
+		if !ok {
+			h = &Histogram{Buckets: make(map[float64]float64)}
+			hs[s.component] = h
+		}
+
+		inc := increase(s.points)
+		switch s.name {
+		case bucketName:
+			h.Buckets[s.le] += inc
+		case sumName:
+			h.Sum += inc
+		case countName:
+			h.Count += inc
+		}
+	}
+	return hs
+}
+
+// increase returns the increase of the counter data points,
+// where a decrease is a counter reset.
+func increase(points Metrics) float64 {
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].UnixMilliseconds < points[j].UnixMilliseconds
+	})
+
+	inc := 0.0
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1].Value, points[i].Value
+		if cur < prev {
+			// reset, counted from zero
+			inc += cur
+			continue
+		}
+		inc += cur - prev
+	}
+	return inc
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := make([]byte, 0, 64)
+	for _, k := range keys {
+		b = append(b, k...)
+		b = append(b, '=')
+		b = append(b, labels[k]...)
+		b = append(b, ',')
+	}
+	return string(b)
+}
+
+// Quantile returns the estimated q-quantile (0 <= q <= 1) of the observations,
+// interpolated linearly within the bucket as in the Prometheus "histogram_quantile".
+// Returns the upper bound of the highest finite bucket if the quantile falls in the
+// "+Inf" bucket, and NaN if no observation.
+func (h *Histogram) Quantile(q float64) float64 {
+	if h == nil || q < 0 || q > 1 {
+		return math.NaN()
+	}
+
+	bounds := make([]float64, 0, len(h.Buckets))
+	for le := range h.Buckets {
+		bounds = append(bounds, le)
+	}
+	sort.Float64s(bounds)
+	if len(bounds) == 0 {
+		return math.NaN()
+	}
+
+	total := h.Buckets[bounds[len(bounds)-1]]
+	if total <= 0 {
+		return math.NaN()
+	}
+
+	rank := q * total
+	lower, prevCount := 0.0, 0.0
+	for _, le := range bounds {
+		count := h.Buckets[le]
+		if count >= rank {
+			if math.IsInf(le, 1) {
+				return lower
+			}
+			if count == prevCount {
+				return le
+			}
+			return lower + (le-lower)*(rank-prevCount)/(count-prevCount)
+		}
+		lower, prevCount = le, count
+	}
+	return lower
+}
+
+// Mean returns the mean of the observations, NaN if no observation.
+func (h *Histogram) Mean() float64 {
+	if h == nil || h.Count <= 0 {
+		return math.NaN()
+	}
+	return h.Sum / h.Count
+}
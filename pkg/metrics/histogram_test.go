@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramIncreases(t *testing.T) {
+	const name = "check_duration_seconds"
+	bucket := func(ts int64, component, le, state string, v float64) Metric {
+		return Metric{
+			UnixMilliseconds: ts,
+			Component:        component,
+			Name:             name + HistogramBucketSuffix,
+			Value:            v,
+			Labels:           map[string]string{HistogramBucketLabelKey: le, "state": state},
+		}
+	}
+	count := func(ts int64, component string, v float64) Metric {
+		return Metric{UnixMilliseconds: ts, Component: component, Name: name + HistogramCountSuffix, Value: v}
+	}
+	sum := func(ts int64, component string, v float64) Metric {
+		return Metric{UnixMilliseconds: ts, Component: component, Name: name + HistogramSumSuffix, Value: v}
+	}
+
+	ms := Metrics{
+		// out of order, summed with the other label series
+		bucket(2, "a", "1", "healthy", 15),
+		bucket(1, "a", "1", "healthy", 10),
+		bucket(1, "a", "+Inf", "healthy", 10),
+		bucket(2, "a", "+Inf", "healthy", 20),
+		bucket(1, "a", "1", "unhealthy", 0),
+		bucket(2, "a", "1", "unhealthy", 5),
+		bucket(1, "a", "+Inf", "unhealthy", 0),
+		bucket(2, "a", "+Inf", "unhealthy", 5),
+		count(1, "a", 10),
+		count(2, "a", 25),
+		sum(1, "a", 100),
+		sum(2, "a", 130),
+
+		// reset in between
+		count(1, "b", 10),
+		count(2, "b", 12),
+		count(3, "b", 3),
+
+		// not the histogram
+		{UnixMilliseconds: 1, Component: "a", Name: "other_count", Value: 100},
+		bucket(1, "c", "invalid", "healthy", 1),
+	}
+
+	hs := HistogramIncreases(ms, name)
+	require.Len(t, hs, 2)
+
+	assert.Equal(t, map[float64]float64{1: 10, math.Inf(1): 15}, hs["a"].Buckets)
+	assert.Equal(t, float64(15), hs["a"].Count)
+	assert.Equal(t, float64(30), hs["a"].Sum)
+	assert.Equal(t, float64(2), hs["a"].Mean())
+
+	assert.Equal(t, float64(5), hs["b"].Count)
+	assert.NotContains(t, hs, "c")
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &Histogram{Buckets: map[float64]float64{0.1: 50, 1: 90, 10: 100, math.Inf(1): 100}}
+	assert.InDelta(t, 0.1, h.Quantile(0.5), 1e-9)
+	assert.InDelta(t, 0.55, h.Quantile(0.7), 1e-9)
+	assert.InDelta(t, 5.5, h.Quantile(0.95), 1e-9)
+	assert.InDelta(t, 0.05, h.Quantile(0.25), 1e-9)
+
+	// falls in the "+Inf" bucket
+	h = &Histogram{Buckets: map[float64]float64{1: 1, math.Inf(1): 10}}
+	assert.Equal(t, float64(1), h.Quantile(0.99))
+
+	assert.True(t, math.IsNaN((&Histogram{}).Quantile(0.5)))
+	assert.True(t, math.IsNaN(h.Quantile(1.5)))
+	assert.True(t, math.IsNaN((*Histogram)(nil).Quantile(0.5)))
+	assert.True(t, math.IsNaN((&Histogram{}).Mean()))
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+// metadataRegistry keeps the metric metadata by the metric name.
+type metadataRegistry struct {
+	mu       sync.RWMutex
+	metadata map[string]apiv1.MetricMetadata
+}
+
+var defaultMetadataRegistry = newMetadataRegistry()
+
+func newMetadataRegistry() *metadataRegistry {
+	return &metadataRegistry{
+		metadata: make(map[string]apiv1.MetricMetadata),
+	}
+}
+
+func (r *metadataRegistry) register(component string, mds ...apiv1.MetricMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, md := range mds {
+		if md.Name == "" {
+			return fmt.Errorf("metric metadata name is empty (component %q)", component)
+		}
+		if _, ok := r.metadata[md.Name]; ok {
+			return fmt.Errorf("metric metadata %q already registered", md.Name)
+		}
+		md.Component = component
+		r.metadata[md.Name] = md
+	}
+	return nil
+}
+
+func (r *metadataRegistry) get(name string) (apiv1.MetricMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	md, ok := r.metadata[name]
+	return md, ok
+}
+
+// list returns the metadata of the selected components (all if empty),
+// sorted by the component and the metric name.
+// The empty descriptions are filled with the Prometheus help of the gathered metrics.
+func (r *metadataRegistry) list(gatherer prometheus.Gatherer, opts ...OpOption) (apiv1.MetricsMetadata, error) {
+	op := &Op{}
+	if err := op.ApplyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	helps := make(map[string]string)
+	if gatherer != nil {
+		families, err := gatherer.Gather()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range families {
+			helps[f.GetName()] = f.GetHelp()
+		}
+	}
+
+	r.mu.RLock()
+	mds := make(apiv1.MetricsMetadata, 0, len(r.metadata))
+	for _, md := range r.metadata {
+		if len(op.SelectedComponents) > 0 {
+			if _, ok := op.SelectedComponents[md.Component]; !ok {
+				continue
+			}
+		}
+		if md.Description == "" {
+			md.Description = helps[md.Name]
+		}
+		mds = append(mds, md)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(mds, func(i, j int) bool {
+		if mds[i].Component != mds[j].Component {
+			return mds[i].Component < mds[j].Component
+		}
+		return mds[i].Name < mds[j].Name
+	})
+	return mds, nil
+}
+
+// MustRegisterMetadata registers the metadata of the metrics reported by the component.
+// It panics if the metric name is empty or already registered.
+func MustRegisterMetadata(component string, mds ...apiv1.MetricMetadata) {
+	if err := defaultMetadataRegistry.register(component, mds...); err != nil {
+		panic(err)
+	}
+}
+
+// GetMetadata returns the metadata of the metric, false if not registered.
+func GetMetadata(name string) (apiv1.MetricMetadata, bool) {
+	return defaultMetadataRegistry.get(name)
+}
+
+// GetUnit returns the unit of the metric, empty if not registered.
+func GetUnit(name string) apiv1.MetricUnit {
+	md, _ := defaultMetadataRegistry.get(name)
+	return md.Unit
+}
+
+// ListMetadata returns the registered metric metadata,
+// optionally filtered by the components (see WithComponents).
+func ListMetadata(opts ...OpOption) (apiv1.MetricsMetadata, error) {
+	return defaultMetadataRegistry.list(defaultGatherer, opts...)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestMetadataRegistry(t *testing.T) {
+	r := newMetadataRegistry()
+
+	require.NoError(t, r.register("memory",
+		apiv1.MetricMetadata{Name: "memory_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+		apiv1.MetricMetadata{Name: "memory_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent, Description: "explicit"},
+	))
+	require.NoError(t, r.register("cpu",
+		apiv1.MetricMetadata{Name: "cpu_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	))
+	assert.ErrorContains(t, r.register("cpu", apiv1.MetricMetadata{Name: "cpu_used_percent"}), "already registered")
+	assert.ErrorContains(t, r.register("cpu", apiv1.MetricMetadata{}), "empty")
+
+	md, ok := r.get("memory_used_bytes")
+	require.True(t, ok)
+	assert.Equal(t, "memory", md.Component)
+	assert.Equal(t, apiv1.MetricUnitBytes, md.Unit)
+	_, ok = r.get("unknown")
+	assert.False(t, ok)
+
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: "memory", Name: "used_bytes", Help: "tracks the used memory in bytes"}, []string{"gpu"})
+	g.WithLabelValues("0").Set(1)
+	reg.MustRegister(g)
+
+	mds, err := r.list(reg)
+	require.NoError(t, err)
+	require.Len(t, mds, 3)
+	// sorted by component, then name
+	assert.Equal(t, "cpu_used_percent", mds[0].Name)
+	assert.Empty(t, mds[0].Description)
+	assert.Equal(t, "memory_used_bytes", mds[1].Name)
+	assert.Equal(t, "tracks the used memory in bytes", mds[1].Description)
+	assert.Equal(t, "explicit", mds[2].Description)
+
+	mds, err = r.list(nil, WithComponents("cpu"))
+	require.NoError(t, err)
+	require.Len(t, mds, 1)
+	assert.Equal(t, "cpu", mds[0].Component)
+}
+
+func TestDefaultMetadata(t *testing.T) {
+	MustRegisterMetadata("test-metadata-component",
+		apiv1.MetricMetadata{Name: "test_metadata_component_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+	)
+	assert.Panics(t, func() {
+		MustRegisterMetadata("test-metadata-component", apiv1.MetricMetadata{Name: "test_metadata_component_used_bytes"})
+	})
+	assert.Equal(t, apiv1.MetricUnitBytes, GetUnit("test_metadata_component_used_bytes"))
+	assert.Empty(t, GetUnit("unknown"))
+
+	_, err := RegisterHealthStateMetrics("test-metadata-component")
+	require.NoError(t, err)
+	md, ok := GetMetadata("test_metadata_component_health_state_unhealthy")
+	require.True(t, ok)
+	assert.Equal(t, apiv1.MetricUnitBoolean, md.Unit)
+
+	mds, err := ListMetadata(WithComponents("test-metadata-component"))
+	require.NoError(t, err)
+	assert.Len(t, mds, 4)
+
+	converted := ConvertToLeptonMetrics(Metrics{{Component: "test-metadata-component", Name: "test_metadata_component_used_bytes", Value: 1}})
+	require.Len(t, converted, 1)
+	assert.Equal(t, apiv1.MetricUnitBytes, converted[0].Metrics[0].Unit)
+}
@@ -3,10 +3,13 @@ package scraper
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 
+	"github.com/leptonai/gpud/pkg/intern"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 )
@@ -38,34 +41,50 @@ func (s *promScraper) Scrape(_ context.Context) (pkgmetrics.Metrics, error) {
 	log.Logger.Infow("scraping prometheus metrics")
 	now := time.Now().UTC().UnixMilli()
 
-	ms := make(pkgmetrics.Metrics, 0, len(gathered))
+	n := 0
 	for _, metricFamily := range gathered {
+		n += len(metricFamily.GetMetric())
+	}
+
+	// the component names, metric names, and labels repeat in every scrape,
+	// thus interned to share one copy while the metrics are held in memory
+	ms := make(pkgmetrics.Metrics, 0, n)
+	for _, metricFamily := range gathered {
+		name := intern.String(metricFamily.GetName())
 		for _, mtRaw := range metricFamily.GetMetric() {
 			m := pkgmetrics.Metric{
 				UnixMilliseconds: now,
-				Name:             metricFamily.GetName(),
+				Name:             name,
 			}
 
-			labels := make(map[string]string, 0)
+			var labels map[string]string
 			for _, label := range mtRaw.GetLabel() {
 				labelName := label.GetName()
 				labelValue := label.GetValue()
 
 				if labelName == pkgmetrics.MetricComponentLabelKey {
-					m.Component = labelValue
+					m.Component = intern.String(labelValue)
 					continue
 				}
-				labels[labelName] = labelValue
-			}
-			if len(labels) > 0 {
-				m.Labels = labels
+				if labels == nil {
+					labels = make(map[string]string, len(mtRaw.GetLabel()))
+				}
+				labels[intern.String(labelName)] = intern.String(labelValue)
 			}
 
 			if m.Component == "" {
 				continue
 			}
+			m.Labels = labels
 
-			// for now, only support counter and gauge
+			// the histogram is stored as the rows of the reserved names
+			// (e.g., the check durations to compute the latency percentiles)
+			if h := mtRaw.GetHistogram(); h != nil {
+				ms = append(ms, histogramMetrics(m, h)...)
+				continue
+			}
+
+			// for now, only support counter, gauge, and histogram
 			switch {
 			case mtRaw.GetCounter() != nil:
 				m.Value = mtRaw.GetCounter().GetValue()
@@ -79,3 +98,48 @@ func (s *promScraper) Scrape(_ context.Context) (pkgmetrics.Metrics, error) {
 
 	return ms, nil
 }
+
+// histogramMetrics returns the cumulative bucket counts with the bucket upper
+// bound label (including "+Inf"), the sum, and the count of the histogram,
+// as the rows of the reserved names with the suffixes of the base metric.
+func histogramMetrics(base pkgmetrics.Metric, h *dto.Histogram) pkgmetrics.Metrics {
+	ms := make(pkgmetrics.Metrics, 0, len(h.GetBucket())+3)
+
+	bucketName := intern.String(base.Name + pkgmetrics.HistogramBucketSuffix)
+	addBucket := func(upperBound float64, count uint64) {
+		labels := make(map[string]string, len(base.Labels)+1)
+		for k, v := range base.Labels {
+			labels[k] = v
+		}
+		labels[pkgmetrics.HistogramBucketLabelKey] = intern.String(pkgmetrics.FormatBucketUpperBound(upperBound))
+
+		m := base
+		m.Name = bucketName
+		m.Value = float64(count)
+		m.Labels = labels
+		ms = append(ms, m)
+	}
+
+	hasInf := false
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			hasInf = true
+		}
+		addBucket(b.GetUpperBound(), b.GetCumulativeCount())
+	}
+	if !hasInf {
+		addBucket(math.Inf(1), h.GetSampleCount())
+	}
+
+	sum := base
+	sum.Name = intern.String(base.Name + pkgmetrics.HistogramSumSuffix)
+	sum.Value = h.GetSampleSum()
+	ms = append(ms, sum)
+
+	count := base
+	count.Name = intern.String(base.Name + pkgmetrics.HistogramCountSuffix)
+	count.Value = float64(h.GetSampleCount())
+	ms = append(ms, count)
+
+	return ms
+}
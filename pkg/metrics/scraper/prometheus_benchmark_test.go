@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+// BenchmarkPrometheusScraper scrapes the per-GPU gauges of an 8-GPU node.
+func BenchmarkPrometheusScraper(b *testing.B) {
+	reg := prometheus.NewRegistry()
+	for c := 0; c < 20; c++ {
+		comp := fmt.Sprintf("accelerator-nvidia-component-%d", c)
+		for n := 0; n < 5; n++ {
+			g := prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Subsystem: fmt.Sprintf("component_%d", c),
+					Name:      fmt.Sprintf("metric_%d", n),
+					Help:      "benchmark gauge",
+				},
+				[]string{pkgmetrics.MetricComponentLabelKey, "uuid"},
+			).MustCurryWith(prometheus.Labels{pkgmetrics.MetricComponentLabelKey: comp})
+			reg.MustRegister(g)
+			for gpu := 0; gpu < 8; gpu++ {
+				g.With(prometheus.Labels{"uuid": fmt.Sprintf("GPU-%08d-0000-0000-0000-000000000000", gpu)}).Set(float64(gpu))
+			}
+		}
+	}
+
+	scraper, err := NewPrometheusScraper(reg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := scraper.Scrape(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -273,3 +273,48 @@ func TestPrometheusScraper_MultipleMetricTypes(t *testing.T) {
 	require.True(t, foundCounter, "Counter metric not found")
 	require.True(t, foundGauge, "Gauge metric not found")
 }
+
+func TestPrometheusScraper_Histogram(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "test_check_duration_seconds",
+			Help:    "test check duration",
+			Buckets: []float64{0.1, 1},
+		},
+		[]string{pkgmetrics.MetricComponentLabelKey, "label_state"},
+	)
+	require.NoError(t, reg.Register(histogram))
+	histogram.WithLabelValues("component1", "healthy").Observe(0.05)
+	histogram.WithLabelValues("component1", "healthy").Observe(0.5)
+	histogram.WithLabelValues("component1", "healthy").Observe(5)
+
+	scraper, err := NewPrometheusScraper(reg)
+	require.NoError(t, err)
+
+	ms, err := scraper.Scrape(context.Background())
+	require.NoError(t, err)
+
+	buckets := make(map[string]float64)
+	var sum, count float64
+	for _, m := range ms {
+		require.Equal(t, "component1", m.Component)
+		require.Equal(t, "healthy", m.Labels["label_state"])
+
+		switch m.Name {
+		case "test_check_duration_seconds" + pkgmetrics.HistogramBucketSuffix:
+			buckets[m.Labels[pkgmetrics.HistogramBucketLabelKey]] = m.Value
+		case "test_check_duration_seconds" + pkgmetrics.HistogramSumSuffix:
+			sum = m.Value
+		case "test_check_duration_seconds" + pkgmetrics.HistogramCountSuffix:
+			count = m.Value
+		default:
+			t.Fatalf("unexpected metric %q", m.Name)
+		}
+	}
+	require.Equal(t, map[string]float64{"0.1": 1, "1": 2, "+Inf": 3}, buckets)
+	require.InDelta(t, 5.55, sum, 1e-9)
+	require.Equal(t, float64(3), count)
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// labelsBuffer is the reusable buffer to encode the metric labels,
+// as the labels of every metric are encoded on each insert.
+type labelsBuffer struct {
+	keys []string
+	b    []byte
+}
+
+var labelsBufferPool = sync.Pool{
+	New: func() any {
+		return &labelsBuffer{b: make([]byte, 0, 256)}
+	},
+}
+
+// encodeLabels returns the labels encoded as a JSON object, or empty if no label.
+// The encoding is canonical, as the encoded labels are part of the primary key:
+// the keys sorted, the HTML characters, U+2028 and U+2029 escaped, and each byte of
+// the invalid UTF-8 escaped as "\ufffd" (see [appendJSONString]), the same as the
+// "encoding/json.Marshal" output of the labels stored by the previous versions,
+// regardless of the "encoding/json" version in use.
+func encodeLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	buf := labelsBufferPool.Get().(*labelsBuffer)
+	defer labelsBufferPool.Put(buf)
+
+	buf.keys = buf.keys[:0]
+	for k := range labels {
+		buf.keys = append(buf.keys, k)
+	}
+	slices.Sort(buf.keys)
+
+	b := append(buf.b[:0], '{')
+	for i, k := range buf.keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, k)
+		b = append(b, ':')
+		b = appendJSONString(b, labels[k])
+	}
+	b = append(b, '}')
+	buf.b = b
+
+	return string(b)
+}
+
+const hexChars = "0123456789abcdef"
+
+// appendJSONString appends the JSON-quoted string in the canonical encoding of the labels
+// (see [encodeLabels]), where the control characters without the short escapes
+// are escaped as "\u00XX" in lowercase hex.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '\\', '"':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexChars[c>>4], hexChars[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are escaped for JSONP, as "encoding/json" does
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexChars[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// valuesPlaceholders returns the comma-separated placeholders of the n rows,
+// each with the given placeholder (e.g., "(?, ?, ?, ?, ?)").
+func valuesPlaceholders(n int, placeholder string) string {
+	if n <= 0 {
+		return ""
+	}
+	b := make([]byte, 0, n*(len(placeholder)+2))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, placeholder...)
+	}
+	return string(b)
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeLabels(t *testing.T) {
+	assert.Equal(t, "", encodeLabels(nil))
+	assert.Equal(t, "", encodeLabels(map[string]string{}))
+
+	for _, tc := range []struct {
+		labels   map[string]string
+		expected string
+	}{
+		{
+			labels:   map[string]string{"uuid": "GPU-0"},
+			expected: `{"uuid":"GPU-0"}`,
+		},
+		{
+			labels:   map[string]string{"uuid": "GPU-0", "mount_point": "/var/lib", "device": "nvme0n1"},
+			expected: `{"device":"nvme0n1","mount_point":"/var/lib","uuid":"GPU-0"}`,
+		},
+		{
+			labels:   map[string]string{"quote": `a"b\c`, "html": "<a href='x'>&</a>", "ctrl": "\b\f\n\r\t\x00\x1f\x7f"},
+			expected: `{"ctrl":"\b\f\n\r\t\u0000\u001f` + "\x7f" + `","html":"\u003ca href='x'\u003e\u0026\u003c/a\u003e","quote":"a\"b\\c"}`,
+		},
+		{
+			labels:   map[string]string{"unicode": "温度 ✓ \u2028\u2029", "invalid": "a\xffb\xc3"},
+			expected: `{"invalid":"a\ufffdb\ufffd","unicode":"温度 ✓ \u2028\u2029"}`,
+		},
+		{
+			labels:   map[string]string{"": ""},
+			expected: `{"":""}`,
+		},
+	} {
+		assert.Equal(t, tc.expected, encodeLabels(tc.labels))
+	}
+}
+
+func FuzzEncodeLabels(f *testing.F) {
+	f.Add("uuid", "GPU-0")
+	f.Add("<key>", "a\xff\u2028\"\\")
+	f.Fuzz(func(t *testing.T, k string, v string) {
+		labels := map[string]string{k: v, "z" + k: v + k}
+		encoded := encodeLabels(labels)
+		assert.Equal(t, encoded, encodeLabels(labels))
+
+		// decodes to the same labels, with each byte of the invalid UTF-8 replaced by U+FFFD
+		var decoded map[string]string
+		require.NoError(t, json.Unmarshal([]byte(encoded), &decoded))
+		expected := make(map[string]string, len(labels))
+		for k, v := range labels {
+			expected[string([]rune(k))] = string([]rune(v))
+		}
+		assert.Equal(t, expected, decoded)
+	})
+}
+
+func TestValuesPlaceholders(t *testing.T) {
+	assert.Equal(t, "", valuesPlaceholders(0, "(?, ?)"))
+	assert.Equal(t, "(?, ?)", valuesPlaceholders(1, "(?, ?)"))
+	assert.Equal(t, "(?, ?), (?, ?), (?, ?)", valuesPlaceholders(3, "(?, ?)"))
+}
+
+func BenchmarkEncodeLabels(b *testing.B) {
+	labels := map[string]string{"uuid": "GPU-00000000-0000-0000-0000-000000000000", "gpu_index": "0"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = encodeLabels(labels)
+	}
+}
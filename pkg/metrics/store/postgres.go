@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+	"github.com/leptonai/gpud/pkg/postgres"
+)
+
+// ErrEmptyMachineID is returned when the machine ID of the shared store is empty.
+var ErrEmptyMachineID = errors.New("machine id is empty")
+
+var _ pkgmetrics.Store = &postgresStore{}
+
+// postgresStore stores the metrics in the PostgreSQL table shared by many GPUd instances,
+// every row is scoped by the machine ID.
+type postgresStore struct {
+	db        *sql.DB
+	table     string
+	machineID string
+}
+
+// NewPostgresStore creates the metrics store in the shared PostgreSQL database.
+// The same table is shared by all the GPUd instances,
+// and each instance only reads and purges its own metrics.
+func NewPostgresStore(ctx context.Context, db *sql.DB, table string, machineID string) (pkgmetrics.Store, error) {
+	if machineID == "" {
+		return nil, ErrEmptyMachineID
+	}
+	if err := CreatePostgresTable(ctx, db, table); err != nil {
+		return nil, err
+	}
+	return &postgresStore{
+		db:        db,
+		table:     table,
+		machineID: machineID,
+	}, nil
+}
+
+func (s *postgresStore) Record(ctx context.Context, ms ...pkgmetrics.Metric) error {
+	return insertPostgres(ctx, s.db, s.table, s.machineID, ms...)
+}
+
+func (s *postgresStore) Read(ctx context.Context, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	return readPostgres(ctx, s.db, s.table, s.machineID, opts...)
+}
+
+func (s *postgresStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	return purgePostgres(ctx, s.db, s.table, s.machineID, before)
+}
+
+// CreatePostgresTable creates the shared metrics table in the PostgreSQL database.
+func CreatePostgresTable(ctx context.Context, db *sql.DB, table string) error {
+	if table == "" {
+		return ErrEmptyTableName
+	}
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT NOT NULL,
+	%s BIGINT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL,
+	%s TEXT NOT NULL DEFAULT '',
+	%s DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (%s, %s, %s, %s, %s)
+);`,
+		table,
+		postgres.ColumnMachineID, columnUnixMilliseconds, columnComponentName, columnMetricName, columnMetricLabels, columnMetricValue, // columns
+		postgres.ColumnMachineID, columnUnixMilliseconds, columnComponentName, columnMetricName, columnMetricLabels, // primary keys
+	))
+	return err
+}
+
+func insertPostgres(ctx context.Context, db *sql.DB, table string, machineID string, ms ...pkgmetrics.Metric) error {
+	if table == "" {
+		return ErrEmptyTableName
+	}
+
+	if len(ms) == 0 {
+		return nil
+	}
+
+	for _, m := range ms {
+		if m.Component == "" {
+			return ErrEmptyComponentName
+		}
+		if m.Name == "" {
+			return ErrEmptyMetricName
+		}
+	}
+
+	// same as "INSERT OR REPLACE" in SQLite
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES ",
+		table,
+		postgres.ColumnMachineID,
+		columnUnixMilliseconds,
+		columnComponentName,
+		columnMetricName,
+		columnMetricLabels,
+		columnMetricValue,
+	)
+	query += valuesPlaceholders(len(ms), "(?, ?, ?, ?, ?, ?)")
+	query += fmt.Sprintf(" ON CONFLICT (%s, %s, %s, %s, %s) DO UPDATE SET %s = EXCLUDED.%s",
+		postgres.ColumnMachineID, columnUnixMilliseconds, columnComponentName, columnMetricName, columnMetricLabels,
+		columnMetricValue, columnMetricValue,
+	)
+
+	args := make([]any, 0, len(ms)*6)
+	for _, m := range ms {
+		args = append(args, machineID, m.UnixMilliseconds, m.Component, m.Name, encodeLabels(m.Labels), m.Value)
+	}
+
+	log.Logger.Infow("inserting metrics", "metrics", len(ms))
+	start := time.Now()
+	_, err := db.ExecContext(ctx, postgres.Rebind(query), args...)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+
+	return err
+}
+
+// readPostgres returns the metric data of the machine in the ascending order of unix seconds.
+func readPostgres(ctx context.Context, db *sql.DB, table string, machineID string, opts ...pkgmetrics.OpOption) (pkgmetrics.Metrics, error) {
+	op := &pkgmetrics.Op{}
+	if err := op.ApplyOpts(opts); err != nil {
+		return nil, err
+	}
+
+	if table == "" {
+		return nil, ErrEmptyTableName
+	}
+
+	conds := []string{fmt.Sprintf("%s = ?", postgres.ColumnMachineID)}
+	params := []any{machineID}
+	if !op.Since.IsZero() {
+		conds = append(conds, fmt.Sprintf("%s >= ?", columnUnixMilliseconds))
+		params = append(params, op.Since.UnixMilli())
+	}
+	if len(op.SelectedComponents) > 0 {
+		components := make([]string, 0, len(op.SelectedComponents))
+		for component := range op.SelectedComponents {
+			components = append(components, component)
+		}
+		sort.Strings(components)
+
+		placeholders := make([]string, len(components))
+		for i, component := range components {
+			placeholders[i] = "?"
+			params = append(params, component)
+		}
+		conds = append(conds, fmt.Sprintf("%s IN (%s)", columnComponentName, strings.Join(placeholders, ", ")))
+	}
+
+	query := fmt.Sprintf(`SELECT %s, %s, %s, %s, %s
+FROM %s
+WHERE %s
+ORDER BY %s ASC`,
+		columnUnixMilliseconds,
+		columnComponentName,
+		columnMetricName,
+		columnMetricLabels,
+		columnMetricValue,
+		table,
+		strings.Join(conds, " AND "),
+		columnUnixMilliseconds,
+	)
+
+	start := time.Now()
+	defer func() {
+		pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	}()
+
+	queryRows, err := db.QueryContext(ctx, postgres.Rebind(query), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = queryRows.Close()
+	}()
+	return scanMetrics(queryRows)
+}
+
+func purgePostgres(ctx context.Context, db *sql.DB, table string, machineID string, before time.Time) (int, error) {
+	if table == "" {
+		return 0, ErrEmptyTableName
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = ? AND %s < ?`, table, postgres.ColumnMachineID, columnUnixMilliseconds)
+
+	start := time.Now()
+	rs, err := db.ExecContext(ctx, postgres.Rebind(query), machineID, before.UnixMilli())
+	pkgmetricsrecorder.RecordSQLiteDelete(time.Since(start).Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := rs.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// migrateBatchSize is the number of metrics copied per insert,
+// below the PostgreSQL limit of 65535 parameters per statement.
+const migrateBatchSize = 1000
+
+// MigrateSQLiteToPostgres copies the metrics in the SQLite table
+// to the shared PostgreSQL table, scoped by the machine ID.
+// Safe to re-run, the already copied metrics are overwritten.
+// Returns the number of the copied metrics.
+func MigrateSQLiteToPostgres(ctx context.Context, sqliteDB *sql.DB, pgDB *sql.DB, table string, machineID string) (int, error) {
+	if machineID == "" {
+		return 0, ErrEmptyMachineID
+	}
+	if err := CreatePostgresTable(ctx, pgDB, table); err != nil {
+		return 0, err
+	}
+
+	ms, err := read(ctx, sqliteDB, table)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(ms); i += migrateBatchSize {
+		end := min(i+migrateBatchSize, len(ms))
+		if err := insertPostgres(ctx, pgDB, table, machineID, ms[i:end]...); err != nil {
+			return i, err
+		}
+	}
+	return len(ms), nil
+}
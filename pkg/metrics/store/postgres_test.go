@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/postgres"
+	pkgsqlite "github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestPostgresNewStore(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewPostgresStore(ctx, nil, "", "test-machine")
+	assert.Equal(t, ErrEmptyTableName, err)
+
+	_, err = NewPostgresStore(ctx, nil, "test_metrics", "")
+	assert.Equal(t, ErrEmptyMachineID, err)
+}
+
+func TestPostgresStore(t *testing.T) {
+	db, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	machineID := "test-machine-" + time.Now().Format("20060102150405.000000000")
+
+	store, err := NewPostgresStore(ctx, db, "test_metrics", machineID)
+	require.NoError(t, err)
+	other, err := NewPostgresStore(ctx, db, "test_metrics", machineID+"-other")
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+	require.NoError(t, store.Record(ctx,
+		pkgmetrics.Metric{UnixMilliseconds: now - 1000, Component: "test-component", Name: "metric1", Value: 1},
+		pkgmetrics.Metric{UnixMilliseconds: now, Component: "test-component", Name: "metric2", Labels: map[string]string{"gpu": "gpu0"}, Value: 2},
+	))
+	require.NoError(t, other.Record(ctx, pkgmetrics.Metric{UnixMilliseconds: now, Component: "test-component", Name: "metric1", Value: 3}))
+
+	// upsert on the same metric
+	require.NoError(t, store.Record(ctx, pkgmetrics.Metric{UnixMilliseconds: now, Component: "test-component", Name: "metric2", Labels: map[string]string{"gpu": "gpu0"}, Value: 4}))
+
+	ms, err := store.Read(ctx, pkgmetrics.WithComponents("test-component"))
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+	assert.Equal(t, "metric1", ms[0].Name)
+	assert.Equal(t, 4.0, ms[1].Value)
+	assert.Equal(t, map[string]string{"gpu": "gpu0"}, ms[1].Labels)
+
+	purged, err := store.Purge(ctx, time.UnixMilli(now))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	ms, err = other.Read(ctx)
+	require.NoError(t, err)
+	assert.Len(t, ms, 1)
+}
+
+func TestMigrateSQLiteToPostgres(t *testing.T) {
+	db, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	dbRW, dbRO, sqliteCleanup := pkgsqlite.OpenTestDB(t)
+	defer sqliteCleanup()
+
+	ctx := context.Background()
+	machineID := "test-machine-" + time.Now().Format("20060102150405.000000000")
+
+	local, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	require.NoError(t, err)
+	now := time.Now().UnixMilli()
+	for i := range 3 {
+		require.NoError(t, local.Record(ctx, pkgmetrics.Metric{UnixMilliseconds: now + int64(i), Component: "test-component", Name: "metric1", Value: float64(i)}))
+	}
+
+	copied, err := MigrateSQLiteToPostgres(ctx, dbRO, db, "test_metrics", machineID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, copied)
+
+	store, err := NewPostgresStore(ctx, db, "test_metrics", machineID)
+	require.NoError(t, err)
+	ms, err := store.Read(ctx)
+	require.NoError(t, err)
+	assert.Len(t, ms, 3)
+}
@@ -12,6 +12,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/leptonai/gpud/pkg/intern"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
@@ -136,23 +137,11 @@ func insert(ctx context.Context, dbRW *sql.DB, table string, ms ...pkgmetrics.Me
 	)
 
 	// Create proper placeholders with commas between value sets
-	placeholders := make([]string, len(ms))
-	for i := range placeholders {
-		placeholders[i] = "(?, ?, ?, ?, ?)"
-	}
-	query += strings.Join(placeholders, ", ")
+	query += valuesPlaceholders(len(ms), "(?, ?, ?, ?, ?)")
 
 	args := make([]interface{}, 0, len(ms)*5)
 	for _, m := range ms {
-		labels := ""
-		if len(m.Labels) > 0 {
-			b, err := json.Marshal(m.Labels)
-			if err != nil {
-				return err
-			}
-			labels = string(b)
-		}
-		args = append(args, m.UnixMilliseconds, m.Component, m.Name, labels, m.Value)
+		args = append(args, m.UnixMilliseconds, m.Component, m.Name, encodeLabels(m.Labels), m.Value)
 	}
 
 	log.Logger.Infow("inserting metrics", "metrics", len(ms))
@@ -232,18 +221,38 @@ FROM %s
 	defer func() {
 		_ = queryRows.Close()
 	}()
+	return scanMetrics(queryRows)
+}
+
+// scanMetrics scans the metric rows, where the component names, metric names, and labels
+// are interned and the labels are decoded once per distinct set, thus the returned labels
+// may be shared between the metrics and must not be modified.
+func scanMetrics(queryRows *sql.Rows) (pkgmetrics.Metrics, error) {
+	strs := make(intern.Table)
+	labelSets := make(map[string]map[string]string)
 
 	rows := make(pkgmetrics.Metrics, 0)
+	var component, name, labels sql.RawBytes
 	for queryRows.Next() {
 		m := pkgmetrics.Metric{}
-		var labels sql.NullString
-		if err := queryRows.Scan(&m.UnixMilliseconds, &m.Component, &m.Name, &labels, &m.Value); err != nil {
+		if err := queryRows.Scan(&m.UnixMilliseconds, &component, &name, &labels, &m.Value); err != nil {
 			return nil, err
 		}
-		if labels.Valid && labels.String != "" {
-			lm := make(map[string]string, 0)
-			if err := json.Unmarshal([]byte(labels.String), &lm); err != nil {
-				return nil, err
+		m.Component = strs.Bytes(component)
+		m.Name = strs.Bytes(name)
+
+		if len(labels) > 0 {
+			lm, ok := labelSets[string(labels)]
+			if !ok {
+				decoded := make(map[string]string, 0)
+				if err := json.Unmarshal(labels, &decoded); err != nil {
+					return nil, err
+				}
+				lm = make(map[string]string, len(decoded))
+				for k, v := range decoded {
+					lm[strs.String(k)] = strs.String(v)
+				}
+				labelSets[string(labels)] = lm
 			}
 			m.Labels = lm
 		}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	pkgsqlite "github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// generateBenchmarkMetrics returns the metrics of an 8-GPU node,
+// scraped every minute for the given minutes.
+func generateBenchmarkMetrics(minutes int) pkgmetrics.Metrics {
+	components := []string{
+		"accelerator-nvidia-temperature",
+		"accelerator-nvidia-power",
+		"accelerator-nvidia-clock-speed",
+		"accelerator-nvidia-utilization",
+		"accelerator-nvidia-memory",
+	}
+	names := []string{"current", "threshold", "used_percent"}
+
+	ms := make(pkgmetrics.Metrics, 0, minutes*len(components)*len(names)*8)
+	for i := 0; i < minutes; i++ {
+		for _, comp := range components {
+			for _, name := range names {
+				for gpu := 0; gpu < 8; gpu++ {
+					ms = append(ms, pkgmetrics.Metric{
+						UnixMilliseconds: int64(i) * 60 * 1000,
+						Component:        comp,
+						Name:             fmt.Sprintf("%s_%s", comp, name),
+						Labels: map[string]string{
+							"uuid": fmt.Sprintf("GPU-%08d-0000-0000-0000-000000000000", gpu),
+						},
+						Value: float64(i),
+					})
+				}
+			}
+		}
+	}
+	return ms
+}
+
+func BenchmarkSQLiteInsert(b *testing.B) {
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(b)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// one scrape of an 8-GPU node
+	ms := generateBenchmarkMetrics(1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range ms {
+			ms[j].UnixMilliseconds = int64(i)
+		}
+		if err := store.Record(ctx, ms...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSQLiteRead reads the 3-hour metrics of an 8-GPU node,
+// and reports the heap bytes retained by the returned metrics.
+func BenchmarkSQLiteRead(b *testing.B) {
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(b)
+	defer cleanup()
+
+	ctx := context.Background()
+	store, err := NewSQLiteStore(ctx, dbRW, dbRO, "test_metrics")
+	if err != nil {
+		b.Fatal(err)
+	}
+	ms := generateBenchmarkMetrics(180)
+	for i := 0; i < len(ms); i += 1000 {
+		if err := store.Record(ctx, ms[i:min(i+1000, len(ms))]...); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var retained uint64
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		read, err := store.Read(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(read) != len(ms) {
+			b.Fatalf("expected %d metrics, got %d", len(ms), len(read))
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		if after.HeapAlloc > before.HeapAlloc {
+			retained += after.HeapAlloc - before.HeapAlloc
+		}
+		runtime.KeepAlive(read)
+	}
+	b.ReportMetric(float64(retained)/float64(b.N)/float64(len(ms)), "retained-B/metric")
+}
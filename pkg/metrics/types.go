@@ -44,6 +44,7 @@ type Store interface {
 
 	// Returns all the data points since the given time.
 	// If since is zero, returns all metrics.
+	// The returned labels may be shared between the metrics, thus must not be modified.
 	Read(ctx context.Context, opts ...OpOption) (Metrics, error)
 
 	// Purge purges the metrics data points before the given time.
@@ -25,6 +25,7 @@ func ConvertToLeptonMetrics(ms Metrics) apiv1.GPUdComponentMetrics {
 			Name:        m.Name,
 			Labels:      m.Labels,
 			Value:       m.Value,
+			Unit:        GetUnit(m.Name),
 		}
 
 		aggregated[m.Component] = append(aggregated[m.Component], mt)
@@ -81,6 +82,14 @@ func RegisterHealthStateMetrics(componentName string) (HealthStateSetter, error)
 	}
 
 	registeredHealthStateMetrics[componentName] = setter
+
+	// never registered twice, as guarded by the registered health state metrics
+	subsystem := NormalizeComponentNameToMetricSubsystem(componentName)
+	_ = defaultMetadataRegistry.register(componentName,
+		apiv1.MetricMetadata{Name: subsystem + "_health_state_healthy", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: subsystem + "_health_state_unhealthy", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+		apiv1.MetricMetadata{Name: subsystem + "_health_state_degraded", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBoolean},
+	)
 	return setter, nil
 }
 
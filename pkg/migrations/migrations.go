@@ -0,0 +1,137 @@
+// Package migrations defines the schema migrations of the GPUd state database,
+// so that the schema changes of the stores (e.g., the new columns of the metrics
+// or the events tables) are applied in place, rather than deleting the databases.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+// all is the schema migrations of the state database, in the ascending order of the versions.
+// Append the new migration with the next version, and never change or remove
+// the released ones, for example:
+//
+//	{
+//		Version: 2,
+//		Name:    "add_metrics_unit_column",
+//		Up: func(ctx context.Context, tx *sql.Tx) error {
+//			found, err := sqlite.ColumnExists(ctx, tx, pkgmetricsstore.DefaultTableName, "unit")
+//			if err != nil || found {
+//				return err
+//			}
+//			_, err = tx.ExecContext(ctx, "ALTER TABLE "+pkgmetricsstore.DefaultTableName+" ADD COLUMN unit TEXT")
+//			return err
+//		},
+//	},
+//
+// The stores create the tables afterwards with the latest schema,
+// thus the migration must be a no-op if the table does not exist yet.
+var all = sqlite.Migrations{
+	{
+		Version: 1,
+		Name:    "add_events_dedup_key_column",
+		Up:      addEventsDedupKeyColumn,
+	},
+}
+
+// allPostgres is the schema migrations of the shared PostgreSQL database
+// (see "--postgres-dsn"), with the same rules as the state database migrations.
+var allPostgres = sqlite.Migrations{
+	{
+		Version: 1,
+		Name:    "add_events_dedup_key_column",
+		Up:      addPostgresEventsDedupKeyColumn,
+	},
+}
+
+// All returns the schema migrations of the state database.
+func All() sqlite.Migrations {
+	return all
+}
+
+// Apply applies the pending migrations to the state database, and returns the migrations applied.
+// Must be called before the stores create or read their tables.
+func Apply(ctx context.Context, dbRW *sql.DB) (sqlite.Migrations, error) {
+	return sqlite.Migrate(ctx, dbRW, all)
+}
+
+// ApplyPostgres applies the pending migrations to the shared PostgreSQL database,
+// and returns the migrations applied.
+// Must be called before the stores create or read their tables.
+func ApplyPostgres(ctx context.Context, pgDB *sql.DB) (sqlite.Migrations, error) {
+	return postgres.Migrate(ctx, pgDB, allPostgres)
+}
+
+// Check validates the pending migrations apply cleanly to the state database
+// without applying them, and returns the pending migrations.
+// The state database is only read, thus safe to open read-only while GPUd is running.
+func Check(ctx context.Context, dbRO *sql.DB) (sqlite.Migrations, error) {
+	return sqlite.CheckMigrations(ctx, dbRO, all)
+}
+
+const (
+	// the event tables as of the migration, one per bucket
+	// (e.g., "components_accelerator_nvidia_error_xid_events_v0_5_0"),
+	// not derived from the event store to keep the released migration unchanged
+	eventsTablePrefix = "components_"
+	eventsTableSuffix = "_events_v0_5_0"
+
+	// the shared event table of all the machines and the buckets
+	postgresEventsTable = "gpud_events_v0_5_0"
+
+	eventsColumnDedupKey = "dedup_key"
+)
+
+// addEventsDedupKeyColumn adds the deduplication key column and its unique index
+// to the event tables created before the key was introduced.
+// The events already stored are kept without the keys (NULL keys never conflict).
+func addEventsDedupKeyColumn(ctx context.Context, tx *sql.Tx) error {
+	tables, err := sqlite.ListTables(ctx, tx, eventsTablePrefix)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if !strings.HasSuffix(table, eventsTableSuffix) {
+			continue
+		}
+
+		found, err := sqlite.ColumnExists(ctx, tx, table, eventsColumnDedupKey)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT", table, eventsColumnDedupKey)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s)",
+			table, eventsColumnDedupKey, table, eventsColumnDedupKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPostgresEventsDedupKeyColumn adds the deduplication key column and its unique index
+// (per machine) to the shared event table created before the key was introduced.
+func addPostgresEventsDedupKeyColumn(ctx context.Context, tx *sql.Tx) error {
+	found, err := postgres.TableExists(ctx, tx, postgresEventsTable)
+	if err != nil || !found {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s TEXT", postgresEventsTable, eventsColumnDedupKey)); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_%s_%s ON %s(%s, %s)",
+		postgresEventsTable, postgres.ColumnMachineID, eventsColumnDedupKey,
+		postgresEventsTable, postgres.ColumnMachineID, eventsColumnDedupKey))
+	return err
+}
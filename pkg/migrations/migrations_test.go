@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestAllValid(t *testing.T) {
+	assert.NoError(t, All().Validate())
+}
+
+func TestApplyIdempotent(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// the state file already written by GPUd, as the empty file cannot be read in the WAL mode
+	_, err := dbRW.ExecContext(ctx, "CREATE TABLE test (id INTEGER)")
+	require.NoError(t, err)
+
+	pending, err := Check(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Len(t, pending, len(All()))
+
+	applied, err := Apply(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(All()))
+
+	applied, err = Apply(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	pending, err = Check(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestAddEventsDedupKeyColumn(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// the table created before the dedup key was introduced
+	tableName := "components_test_legacy_events_v0_5_0"
+	_, err := dbRW.ExecContext(ctx, `CREATE TABLE `+tableName+` (
+	timestamp INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	message TEXT,
+	extra_info TEXT
+);`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	_, err = dbRW.ExecContext(ctx, "INSERT INTO "+tableName+" (timestamp, name, type) VALUES (?, ?, ?)",
+		now.Unix(), "xid", string(apiv1.EventTypeFatal))
+	require.NoError(t, err)
+
+	applied, err := Apply(ctx, dbRW)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(All()))
+
+	found, err := sqlite.ColumnExists(ctx, dbRW, tableName, "dedup_key")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	store, err := eventstore.New(dbRW, dbRO, 0)
+	require.NoError(t, err)
+	bucket, err := store.Bucket("test_legacy")
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	// the legacy event is kept without the key, and does not conflict with the new one
+	ev := eventstore.Event{Time: now, Name: "xid", Type: string(apiv1.EventTypeFatal)}
+	require.NoError(t, bucket.Insert(ctx, ev))
+	require.NoError(t, bucket.Insert(ctx, ev))
+
+	events, err := bucket.Get(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.ElementsMatch(t, []string{"", eventstore.DedupKey("test_legacy", ev)}, []string{events[0].DedupKey, events[1].DedupKey})
+}
+
+func TestApplyPostgres(t *testing.T) {
+	pgDB, cleanup := postgres.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := ApplyPostgres(ctx, pgDB)
+	require.NoError(t, err)
+
+	applied, err := ApplyPostgres(ctx, pgDB)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
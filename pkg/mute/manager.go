@@ -0,0 +1,151 @@
+package mute
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// ErrInvalidDuration is returned when muting for a non-positive duration.
+var ErrInvalidDuration = errors.New("mute duration must be positive")
+
+// Manager manages the component mutes persisted in the database,
+// so that the mutes survive the restarts until their expiry.
+// Safe for concurrent use.
+type Manager struct {
+	dbRW *sql.DB
+	dbRO *sql.DB
+
+	getTimeNowFunc func() time.Time
+
+	mu    sync.RWMutex
+	mutes map[string]Mute
+}
+
+// NewManager creates the component mute manager, with the unexpired mutes loaded.
+func NewManager(ctx context.Context, dbRW *sql.DB, dbRO *sql.DB) (*Manager, error) {
+	if err := CreateTable(ctx, dbRW); err != nil {
+		return nil, fmt.Errorf("failed to create component mutes table: %w", err)
+	}
+
+	m := &Manager{
+		dbRW: dbRW,
+		dbRO: dbRO,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		mutes: make(map[string]Mute),
+	}
+
+	if err := Purge(ctx, dbRW, m.getTimeNowFunc()); err != nil {
+		return nil, fmt.Errorf("failed to purge component mutes: %w", err)
+	}
+	mutes, err := Read(ctx, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read component mutes: %w", err)
+	}
+	for _, mu := range mutes {
+		m.mutes[mu.Component] = mu
+	}
+
+	return m, nil
+}
+
+// Mute mutes the component for the duration from now,
+// replacing the existing mute of the component.
+func (m *Manager) Mute(ctx context.Context, component string, d time.Duration, reason string) (Mute, error) {
+	if d <= 0 {
+		return Mute{}, ErrInvalidDuration
+	}
+
+	// truncated to the seconds stored in the database
+	now := m.getTimeNowFunc().Truncate(time.Second)
+	mu := Mute{
+		Component: component,
+		Until:     now.Add(d.Truncate(time.Second)),
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	if err := mu.Validate(); err != nil {
+		return Mute{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := Upsert(ctx, m.dbRW, mu); err != nil {
+		return Mute{}, err
+	}
+	m.mutes[component] = mu
+
+	m.purgeLocked(ctx, now)
+	return mu, nil
+}
+
+// Unmute deletes the mute of the component, to end it early.
+// It returns false if the component is not muted.
+func (m *Manager) Unmute(ctx context.Context, component string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted, err := Delete(ctx, m.dbRW, component)
+	if err != nil {
+		return false, err
+	}
+	mu, ok := m.mutes[component]
+	delete(m.mutes, component)
+	return deleted && ok && !mu.Expired(m.getTimeNowFunc()), nil
+}
+
+// List returns the unexpired mutes, sorted by the component.
+func (m *Manager) List() []Mute {
+	now := m.getTimeNowFunc()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mutes := make([]Mute, 0, len(m.mutes))
+	for _, mu := range m.mutes {
+		if !mu.Expired(now) {
+			mutes = append(mutes, mu)
+		}
+	}
+	sort.Slice(mutes, func(i, j int) bool {
+		return mutes[i].Component < mutes[j].Component
+	})
+	return mutes
+}
+
+// MutedUntil returns when the mute of the component expires,
+// and false if the component is not muted now.
+func (m *Manager) MutedUntil(component string) (time.Time, bool) {
+	now := m.getTimeNowFunc()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mu, ok := m.mutes[component]
+	if !ok || mu.Expired(now) {
+		return time.Time{}, false
+	}
+	return mu.Until, true
+}
+
+// purgeLocked deletes the expired mutes.
+// The purge failure is not fatal, to be retried on the next call.
+func (m *Manager) purgeLocked(ctx context.Context, now time.Time) {
+	for component, mu := range m.mutes {
+		if mu.Expired(now) {
+			delete(m.mutes, component)
+		}
+	}
+	if err := Purge(ctx, m.dbRW, now); err != nil {
+		log.Logger.Warnw("failed to purge component mutes", "error", err)
+	}
+}
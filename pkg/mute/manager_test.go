@@ -0,0 +1,92 @@
+package mute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestManager(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	m, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	m.getTimeNowFunc = func() time.Time { return now }
+
+	_, ok := m.MutedUntil("nfs")
+	assert.False(t, ok)
+
+	mu, err := m.Mute(ctx, "nfs", 4*time.Hour, "RMA")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(4*time.Hour), mu.Until)
+	_, err = m.Mute(ctx, "os", time.Hour, "")
+	require.NoError(t, err)
+
+	_, err = m.Mute(ctx, "cpu", 0, "")
+	assert.ErrorIs(t, err, ErrInvalidDuration)
+	_, err = m.Mute(ctx, "cpu", MaxDuration+time.Hour, "")
+	assert.Error(t, err)
+
+	until, ok := m.MutedUntil("nfs")
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(4*time.Hour), until)
+
+	list := m.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "nfs", list[0].Component)
+	assert.Equal(t, "RMA", list[0].Reason)
+	assert.Equal(t, "os", list[1].Component)
+
+	// persisted across the restarts
+	m2, err := NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	m2.getTimeNowFunc = func() time.Time { return now }
+	assert.Equal(t, list, m2.List())
+
+	// expired
+	m2.getTimeNowFunc = func() time.Time { return now.Add(2 * time.Hour) }
+	_, ok = m2.MutedUntil("os")
+	assert.False(t, ok)
+	list = m2.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, "nfs", list[0].Component)
+
+	unmuted, err := m2.Unmute(ctx, "nfs")
+	require.NoError(t, err)
+	assert.True(t, unmuted)
+	unmuted, err = m2.Unmute(ctx, "nfs")
+	require.NoError(t, err)
+	assert.False(t, unmuted)
+	_, ok = m2.MutedUntil("nfs")
+	assert.False(t, ok)
+
+	// expired mutes are purged on the next mute
+	stored, err := Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "os", stored[0].Component)
+	_, err = m2.Mute(ctx, "cpu", time.Hour, "")
+	require.NoError(t, err)
+	stored, err = Read(ctx, dbRO)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "cpu", stored[0].Component)
+}
+
+func TestMuteValidate(t *testing.T) {
+	now := time.Now().UTC()
+	assert.NoError(t, Mute{Component: "nfs", CreatedAt: now, Until: now.Add(time.Hour)}.Validate())
+	assert.Error(t, Mute{CreatedAt: now, Until: now.Add(time.Hour)}.Validate())
+	assert.Error(t, Mute{Component: "nfs"}.Validate())
+	assert.Error(t, Mute{Component: "nfs", CreatedAt: now, Until: now}.Validate())
+	assert.Error(t, Mute{Component: "nfs", CreatedAt: now, Until: now.Add(MaxDuration + time.Second)}.Validate())
+}
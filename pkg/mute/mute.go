@@ -0,0 +1,49 @@
+// Package mute manages the component mutes, until whose expiry the unhealthy
+// health states of the component are tagged as muted and excluded from the
+// health verdicts, while the checks keep running and the data keep recorded
+// (e.g., a known-bad component during an RMA window).
+package mute
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxDuration is the maximum duration of a mute, so that a forgotten mute
+// does not silence the component indefinitely.
+const MaxDuration = 30 * 24 * time.Hour
+
+// Mute is a mute of a component.
+type Mute struct {
+	// Component is the name of the muted component.
+	Component string `json:"component"`
+	// Until is when the mute expires.
+	Until time.Time `json:"until"`
+	// Reason is the optional description of the mute (e.g., "RMA ticket 1234").
+	Reason string `json:"reason,omitempty"`
+	// CreatedAt is when the component was muted.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate returns an error if the mute is invalid.
+func (m Mute) Validate() error {
+	if m.Component == "" {
+		return errors.New("component is required")
+	}
+	if m.CreatedAt.IsZero() || m.Until.IsZero() {
+		return errors.New("created_at and until are required")
+	}
+	if !m.Until.After(m.CreatedAt) {
+		return fmt.Errorf("until %s must be after created_at %s", m.Until.Format(time.RFC3339), m.CreatedAt.Format(time.RFC3339))
+	}
+	if d := m.Until.Sub(m.CreatedAt); d > MaxDuration {
+		return fmt.Errorf("duration %s exceeds the maximum %s", d, MaxDuration)
+	}
+	return nil
+}
+
+// Expired returns true if the mute has expired at the given time.
+func (m Mute) Expired(t time.Time) bool {
+	return !t.Before(m.Until)
+}
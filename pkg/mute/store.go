@@ -0,0 +1,96 @@
+package mute
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
+)
+
+const (
+	tableNameComponentMutes = "gpud_component_mutes"
+
+	columnComponent = "component"
+	columnUntil     = "until"
+	columnReason    = "reason"
+	columnCreatedAt = "created_at"
+)
+
+// CreateTable creates the table for the component mutes.
+func CreateTable(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s TEXT PRIMARY KEY,
+	%s INTEGER NOT NULL,
+	%s TEXT,
+	%s INTEGER NOT NULL
+);`, tableNameComponentMutes, columnComponent, columnUntil, columnReason, columnCreatedAt))
+	return err
+}
+
+// Upsert inserts or updates the mute by its component.
+func Upsert(ctx context.Context, dbRW *sql.DB, m Mute) error {
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+INSERT OR REPLACE INTO %s (%s, %s, %s, %s) VALUES (?, ?, ?, ?)`,
+		tableNameComponentMutes, columnComponent, columnUntil, columnReason, columnCreatedAt),
+		m.Component, m.Until.Unix(), m.Reason, m.CreatedAt.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Delete deletes the mute of the component.
+// It returns false if the component is not muted.
+func Delete(ctx context.Context, dbRW *sql.DB, component string) (bool, error) {
+	start := time.Now()
+	res, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = ?`, tableNameComponentMutes, columnComponent), component)
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Purge deletes the mutes that expired at or before the given time.
+func Purge(ctx context.Context, dbRW *sql.DB, expiredAt time.Time) error {
+	start := time.Now()
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s <= ?`, tableNameComponentMutes, columnUntil), expiredAt.Unix())
+	pkgmetricsrecorder.RecordSQLiteInsertUpdate(time.Since(start).Seconds())
+	return err
+}
+
+// Read returns all the stored mutes, sorted by the component.
+func Read(ctx context.Context, dbRO *sql.DB) ([]Mute, error) {
+	start := time.Now()
+	rows, err := dbRO.QueryContext(ctx, fmt.Sprintf(`
+SELECT %s, %s, %s, %s FROM %s
+ORDER BY %s ASC`, columnComponent, columnUntil, columnReason, columnCreatedAt, tableNameComponentMutes, columnComponent))
+	pkgmetricsrecorder.RecordSQLiteSelect(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var mutes []Mute
+	for rows.Next() {
+		var m Mute
+		var untilUnix, createdAtUnix int64
+		var reason sql.NullString
+		if err := rows.Scan(&m.Component, &untilUnix, &reason, &createdAtUnix); err != nil {
+			return nil, err
+		}
+		m.Until = time.Unix(untilUnix, 0).UTC()
+		m.Reason = reason.String
+		m.CreatedAt = time.Unix(createdAtUnix, 0).UTC()
+		mutes = append(mutes, m)
+	}
+	return mutes, rows.Err()
+}
@@ -1,4 +1,6 @@
-// Package nfschecker checks the health of the NFS mount points.
+// Package nfschecker checks the health of the NFS mount points,
+// by the shared-file protocol among the group members, the RPC statistics
+// of the mounts, and the bounded stat probes to detect the hung mounts.
 package nfschecker
 
 import (
@@ -24,6 +26,22 @@ type Checker interface {
 	Clean() error
 }
 
+// FailureMode is the distinct failure mode of an NFS mount.
+type FailureMode string
+
+const (
+	// FailureModeReadWrite is when the file written to the mount cannot be read back as written.
+	FailureModeReadWrite FailureMode = "read-write"
+	// FailureModeHung is when a stat on the mount does not return within the timeout.
+	FailureModeHung FailureMode = "hung"
+	// FailureModeRPCTimeouts is when the RPC operations hit the major timeouts.
+	FailureModeRPCTimeouts FailureMode = "rpc-timeouts"
+	// FailureModeRPCRetransmits is when the RPC operations are excessively retransmitted.
+	FailureModeRPCRetransmits FailureMode = "rpc-retransmits"
+	// FailureModeRPCLatency is when the RPC operations are slow on average.
+	FailureModeRPCLatency FailureMode = "rpc-latency"
+)
+
 // CheckResult is the result of the check.
 type CheckResult struct {
 	// Dir is the directory that is checked.
@@ -41,6 +59,15 @@ type CheckResult struct {
 	// TimeoutError indicates that if the [CheckResult].Error is not empty,
 	// the error was due to operation timeout (e.g., [context.DeadlineExceeded]).
 	TimeoutError bool `json:"timeout_error,omitempty"`
+
+	// FailureMode is the failure mode if the check failed, empty if healthy.
+	FailureMode FailureMode `json:"failure_mode,omitempty"`
+
+	// MountPoint is the NFS mount point of the directory, empty if not sampled.
+	MountPoint string `json:"mount_point,omitempty"`
+	// RPCStats is the increase of the RPC statistics of the mount
+	// since the previous check, nil if not sampled.
+	RPCStats *RPCStats `json:"rpc_stats,omitempty"`
 }
 
 // NewChecker creates a new checker with the given configuration.
@@ -92,12 +119,14 @@ func (c *checker) Check(ctx context.Context) CheckResult {
 		result.Message = "failed"
 		result.Error = fmt.Sprintf("failed to read file %s: %s", file, err)
 		result.TimeoutError = errors.Is(err, context.DeadlineExceeded)
+		result.FailureMode = FailureModeReadWrite
 		return result
 	}
 
 	if string(contents) != c.cfg.FileContents {
 		result.Message = "failed"
 		result.Error = fmt.Sprintf("file %q has unexpected contents", file)
+		result.FailureMode = FailureModeReadWrite
 		return result
 	}
 
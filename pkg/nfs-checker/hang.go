@@ -0,0 +1,82 @@
+package nfschecker
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultHangTimeout is the default duration of the stat probe
+// on a mount point, after which the mount is considered hung.
+const DefaultHangTimeout = 5 * time.Second
+
+// HangDetector detects the hung NFS mounts by the bounded stat probes,
+// each run in a separate goroutine so that the caller never blocks
+// on the unresponsive NFS server.
+//
+// A stat on a hung mount may never return (i.e., the uninterruptible sleep
+// of the "hard" mounts), thus at most one probe is in flight per path,
+// and the path stays hung until the outstanding probe returns,
+// rather than piling up the goroutines on every check.
+// Safe for concurrent use.
+type HangDetector struct {
+	timeout  time.Duration
+	statFunc func(path string) (os.FileInfo, error)
+
+	mu     sync.Mutex
+	probes map[string]*probe
+}
+
+// probe is a stat probe in flight, with done closed once the stat returns.
+type probe struct {
+	start time.Time
+	done  chan struct{}
+}
+
+// NewHangDetector creates the hang detector with the probe timeout
+// (defaults to DefaultHangTimeout if zero).
+func NewHangDetector(timeout time.Duration) *HangDetector {
+	if timeout == 0 {
+		timeout = DefaultHangTimeout
+	}
+	return &HangDetector{
+		timeout:  timeout,
+		statFunc: os.Stat,
+		probes:   make(map[string]*probe),
+	}
+}
+
+// Probe stats the path with the timeout, and returns the duration
+// the path has been hung for, or zero if the stat returned in time.
+// The stat errors (e.g., the path does not exist) are not hangs,
+// and left to the other checks to report.
+func (d *HangDetector) Probe(path string) time.Duration {
+	d.mu.Lock()
+	p, ok := d.probes[path]
+	if ok {
+		select {
+		case <-p.done:
+			ok = false
+		default:
+		}
+	}
+	if !ok {
+		p = &probe{start: time.Now(), done: make(chan struct{})}
+		d.probes[path] = p
+		go func() {
+			_, _ = d.statFunc(path)
+			close(p.done)
+		}()
+	}
+	d.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(p.start.Add(d.timeout)))
+	defer timer.Stop()
+
+	select {
+	case <-p.done:
+		return 0
+	case <-timer.C:
+		return time.Since(p.start)
+	}
+}
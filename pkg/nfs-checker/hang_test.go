@@ -0,0 +1,44 @@
+package nfschecker
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHangDetectorProbe(t *testing.T) {
+	d := NewHangDetector(50 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), d.Probe(t.TempDir()))
+	// the stat errors are not hangs
+	assert.Equal(t, time.Duration(0), d.Probe("/not/found"))
+}
+
+func TestHangDetectorProbeHung(t *testing.T) {
+	release := make(chan struct{})
+	var stats atomic.Int32
+
+	d := NewHangDetector(50 * time.Millisecond)
+	d.statFunc = func(path string) (os.FileInfo, error) {
+		stats.Add(1)
+		<-release
+		return nil, nil
+	}
+
+	hung := d.Probe("/mnt/nfs")
+	assert.GreaterOrEqual(t, hung, 50*time.Millisecond)
+
+	// the outstanding probe is reused, not piling up the goroutines
+	time.Sleep(20 * time.Millisecond)
+	assert.GreaterOrEqual(t, d.Probe("/mnt/nfs"), hung+20*time.Millisecond)
+	assert.Equal(t, int32(1), stats.Load())
+
+	// recovered once the outstanding stat returns
+	close(release)
+	assert.Eventually(t, func() bool {
+		return d.Probe("/mnt/nfs") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), stats.Load())
+}
@@ -0,0 +1,238 @@
+package nfschecker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMountStatsPath is the per-mount statistics of the NFS client.
+const DefaultMountStatsPath = "/proc/self/mountstats"
+
+const (
+	// DefaultMinRPCOps is the minimum number of the RPC operations
+	// between the samples to evaluate the retransmits and the round trip time,
+	// to not fail on a few slow operations of an idle mount.
+	DefaultMinRPCOps = 100
+	// DefaultMaxRetransmitRatio is the ratio of the retransmissions to the operations
+	// between the samples, above which the mount is failing.
+	DefaultMaxRetransmitRatio = 0.05
+	// DefaultMaxAvgRTT is the average round trip time of the RPC operations
+	// between the samples, above which the mount is failing.
+	DefaultMaxAvgRTT = time.Second
+)
+
+// RPCStats is the RPC statistics of an NFS mount, summed over all the operations.
+type RPCStats struct {
+	// Ops is the number of the RPC operations.
+	Ops uint64 `json:"ops"`
+	// Transmissions is the number of the RPC transmissions, including the retransmissions.
+	Transmissions uint64 `json:"transmissions"`
+	// MajorTimeouts is the number of the major timeouts
+	// (i.e., "server not responding" in the kernel log).
+	MajorTimeouts uint64 `json:"major_timeouts"`
+	// RTTMilliseconds is the total round trip time of the RPC operations in milliseconds.
+	RTTMilliseconds uint64 `json:"rtt_milliseconds"`
+}
+
+// Retransmits returns the number of the RPC retransmissions.
+func (s RPCStats) Retransmits() uint64 {
+	if s.Transmissions < s.Ops {
+		return 0
+	}
+	return s.Transmissions - s.Ops
+}
+
+// AvgRTT returns the average round trip time of the RPC operations.
+func (s RPCStats) AvgRTT() time.Duration {
+	if s.Ops == 0 {
+		return 0
+	}
+	return time.Duration(s.RTTMilliseconds/s.Ops) * time.Millisecond
+}
+
+// sub returns the increase of the statistics since the previous sample,
+// or the statistics as is, if the counters were reset (e.g., remounted).
+func (s RPCStats) sub(prev RPCStats) RPCStats {
+	if s.Ops < prev.Ops || s.Transmissions < prev.Transmissions || s.MajorTimeouts < prev.MajorTimeouts || s.RTTMilliseconds < prev.RTTMilliseconds {
+		return s
+	}
+	return RPCStats{
+		Ops:             s.Ops - prev.Ops,
+		Transmissions:   s.Transmissions - prev.Transmissions,
+		MajorTimeouts:   s.MajorTimeouts - prev.MajorTimeouts,
+		RTTMilliseconds: s.RTTMilliseconds - prev.RTTMilliseconds,
+	}
+}
+
+// Evaluate returns the failure mode and the reason, if the RPC statistics
+// between the samples show the timeouts, the excessive retransmits, or the slow round trips.
+// Returns an empty failure mode if healthy.
+func (s RPCStats) Evaluate() (FailureMode, string) {
+	if s.MajorTimeouts > 0 {
+		return FailureModeRPCTimeouts, fmt.Sprintf("%d RPC major timeout(s)", s.MajorTimeouts)
+	}
+	if s.Ops < DefaultMinRPCOps {
+		return "", ""
+	}
+	if ratio := float64(s.Retransmits()) / float64(s.Ops); ratio > DefaultMaxRetransmitRatio {
+		return FailureModeRPCRetransmits, fmt.Sprintf("%d RPC retransmit(s) for %d operation(s) (%.1f%%)", s.Retransmits(), s.Ops, ratio*100)
+	}
+	if rtt := s.AvgRTT(); rtt > DefaultMaxAvgRTT {
+		return FailureModeRPCLatency, fmt.Sprintf("RPC average round trip time %s for %d operation(s)", rtt, s.Ops)
+	}
+	return "", ""
+}
+
+// parseMountStats parses the NFS mounts of the "/proc/self/mountstats" output,
+// keyed by the mount point, for example:
+//
+//	device server:/export mounted on /mnt/nfs with fstype nfs4 statvers=1.1
+//		...
+//		per-op statistics
+//		        NULL: 1 1 0 44 24 0 0 1 0
+//		        READ: 100 102 1 15200 409600 10 250 270 0
+//
+// where the per-op fields are the operations, the transmissions, the major timeouts,
+// the bytes sent and received, and the queue, round trip, and execution times in milliseconds.
+func parseMountStats(r io.Reader) (map[string]RPCStats, error) {
+	stats := make(map[string]RPCStats)
+
+	var mountPoint string
+	var perOp bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "device ") {
+			mountPoint, perOp = "", false
+
+			// e.g., "device server:/export mounted on /mnt/nfs with fstype nfs4 statvers=1.1"
+			fields := strings.Fields(line)
+			if len(fields) < 8 || fields[2] != "mounted" || fields[3] != "on" || fields[5] != "with" || fields[6] != "fstype" {
+				continue
+			}
+			if !strings.HasPrefix(fields[7], "nfs") {
+				continue
+			}
+			mountPoint = fields[4]
+			stats[mountPoint] = RPCStats{}
+			continue
+		}
+		if mountPoint == "" {
+			continue
+		}
+		if line == "per-op statistics" {
+			perOp = true
+			continue
+		}
+		if !perOp {
+			continue
+		}
+
+		_, counters, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 8 {
+			continue
+		}
+		var values [8]uint64
+		for i := range values {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse per-op statistics %q: %w", line, err)
+			}
+			values[i] = v
+		}
+
+		s := stats[mountPoint]
+		s.Ops += values[0]
+		s.Transmissions += values[1]
+		s.MajorTimeouts += values[2]
+		s.RTTMilliseconds += values[6]
+		stats[mountPoint] = s
+	}
+	return stats, scanner.Err()
+}
+
+// findMountPoint returns the longest mount point containing the path, or false if none.
+func findMountPoint(stats map[string]RPCStats, path string) (string, bool) {
+	path = filepath.Clean(path)
+
+	var found string
+	for mountPoint := range stats {
+		if path != mountPoint && !strings.HasPrefix(path, strings.TrimSuffix(mountPoint, "/")+"/") {
+			continue
+		}
+		if len(mountPoint) > len(found) {
+			found = mountPoint
+		}
+	}
+	return found, found != ""
+}
+
+// RPCSampler samples the RPC statistics of the NFS mounts,
+// returning the increase since the previous sample of the same mount.
+// Safe for concurrent use.
+type RPCSampler struct {
+	mountStatsPath string
+
+	mu   sync.Mutex
+	last map[string]RPCStats
+}
+
+// NewRPCSampler creates the sampler of the RPC statistics from the mountstats file
+// (defaults to "/proc/self/mountstats" if empty).
+func NewRPCSampler(mountStatsPath string) *RPCSampler {
+	if mountStatsPath == "" {
+		mountStatsPath = DefaultMountStatsPath
+	}
+	return &RPCSampler{
+		mountStatsPath: mountStatsPath,
+		last:           make(map[string]RPCStats),
+	}
+}
+
+// Sample returns the mount point of the path, and the increase of its RPC statistics
+// since the previous sample. Returns false if the path is not on an NFS mount,
+// or it is the first sample of the mount.
+//
+// Reading the mountstats file does not block on the unresponsive NFS servers,
+// as the statistics are kept by the client in the kernel.
+func (s *RPCSampler) Sample(path string) (string, RPCStats, bool, error) {
+	f, err := os.Open(s.mountStatsPath)
+	if err != nil {
+		return "", RPCStats{}, false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	stats, err := parseMountStats(f)
+	if err != nil {
+		return "", RPCStats{}, false, err
+	}
+	mountPoint, ok := findMountPoint(stats, path)
+	if !ok {
+		return "", RPCStats{}, false, nil
+	}
+	cur := stats[mountPoint]
+
+	s.mu.Lock()
+	prev, ok := s.last[mountPoint]
+	s.last[mountPoint] = cur
+	s.mu.Unlock()
+
+	if !ok {
+		return mountPoint, RPCStats{}, false, nil
+	}
+	return mountPoint, cur.sub(prev), true, nil
+}
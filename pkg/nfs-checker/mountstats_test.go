@@ -0,0 +1,116 @@
+package nfschecker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMountStats = `device rootfs mounted on / with fstype rootfs
+device proc mounted on /proc with fstype proc
+device server:/export mounted on /mnt/nfs with fstype nfs4 statvers=1.1
+	opts:	rw,vers=4.1,rsize=1048576,wsize=1048576,hard,proto=tcp,timeo=600,retrans=2
+	age:	12345
+	xprt:	tcp 0 1 2 0 0 1234 1234 0 5678 0 2 0 0
+	per-op statistics
+	        NULL: 1 1 0 44 24 0 0 1 0
+	        READ: 100 102 1 15200 409600 10 250 270 0
+	       WRITE: 50 55 0 204800 7200 5 150 160 0
+device server:/export/shared mounted on /mnt/nfs/shared with fstype nfs statvers=1.1
+	per-op statistics
+	     GETATTR: 10 10 0 1200 2400 0 20 25
+`
+
+func TestParseMountStats(t *testing.T) {
+	stats, err := parseMountStats(strings.NewReader(testMountStats))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]RPCStats{
+		"/mnt/nfs":        {Ops: 151, Transmissions: 158, MajorTimeouts: 1, RTTMilliseconds: 400},
+		"/mnt/nfs/shared": {Ops: 10, Transmissions: 10, RTTMilliseconds: 20},
+	}, stats)
+
+	_, err = parseMountStats(strings.NewReader("device server:/export mounted on /mnt/nfs with fstype nfs\n\tper-op statistics\n\tREAD: 1 x 0 0 0 0 0 0\n"))
+	require.Error(t, err)
+}
+
+func TestFindMountPoint(t *testing.T) {
+	stats := map[string]RPCStats{"/mnt/nfs": {}, "/mnt/nfs/shared": {}}
+
+	mountPoint, ok := findMountPoint(stats, "/mnt/nfs/shared/data/")
+	assert.True(t, ok)
+	assert.Equal(t, "/mnt/nfs/shared", mountPoint)
+
+	mountPoint, ok = findMountPoint(stats, "/mnt/nfs")
+	assert.True(t, ok)
+	assert.Equal(t, "/mnt/nfs", mountPoint)
+
+	_, ok = findMountPoint(stats, "/mnt/nfs2")
+	assert.False(t, ok)
+}
+
+func TestRPCStats(t *testing.T) {
+	s := RPCStats{Ops: 200, Transmissions: 210, RTTMilliseconds: 1000}
+	assert.Equal(t, uint64(10), s.Retransmits())
+	assert.Equal(t, 5*time.Millisecond, s.AvgRTT())
+	assert.Equal(t, uint64(0), RPCStats{Ops: 2, Transmissions: 1}.Retransmits())
+	assert.Equal(t, time.Duration(0), RPCStats{}.AvgRTT())
+
+	assert.Equal(t, RPCStats{Ops: 100, Transmissions: 105, RTTMilliseconds: 500}, s.sub(RPCStats{Ops: 100, Transmissions: 105, RTTMilliseconds: 500}))
+	// reset by the remount
+	assert.Equal(t, s, s.sub(RPCStats{Ops: 1000}))
+}
+
+func TestRPCStatsEvaluate(t *testing.T) {
+	mode, _ := RPCStats{Ops: 1000, Transmissions: 1010, RTTMilliseconds: 5000}.Evaluate()
+	assert.Empty(t, mode)
+
+	mode, reason := RPCStats{Ops: 1, Transmissions: 3, MajorTimeouts: 1}.Evaluate()
+	assert.Equal(t, FailureModeRPCTimeouts, mode)
+	assert.Equal(t, "1 RPC major timeout(s)", reason)
+
+	// too few operations to evaluate the retransmits
+	mode, _ = RPCStats{Ops: 10, Transmissions: 20}.Evaluate()
+	assert.Empty(t, mode)
+
+	mode, _ = RPCStats{Ops: 100, Transmissions: 110}.Evaluate()
+	assert.Equal(t, FailureModeRPCRetransmits, mode)
+
+	mode, reason = RPCStats{Ops: 100, Transmissions: 100, RTTMilliseconds: 200_000}.Evaluate()
+	assert.Equal(t, FailureModeRPCLatency, mode)
+	assert.Equal(t, "RPC average round trip time 2s for 100 operation(s)", reason)
+}
+
+func TestRPCSamplerSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mountstats")
+	require.NoError(t, os.WriteFile(path, []byte(testMountStats), 0644))
+
+	s := NewRPCSampler(path)
+
+	// the first sample has nothing to compare against
+	mountPoint, _, ok, err := s.Sample("/mnt/nfs/data")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "/mnt/nfs", mountPoint)
+
+	updated := strings.Replace(testMountStats, "READ: 100 102 1", "READ: 120 130 1", 1)
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0644))
+	mountPoint, stats, ok, err := s.Sample("/mnt/nfs/data")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "/mnt/nfs", mountPoint)
+	assert.Equal(t, RPCStats{Ops: 20, Transmissions: 28}, stats)
+
+	// not on an NFS mount
+	mountPoint, _, ok, err = s.Sample("/var/lib")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, mountPoint)
+
+	_, _, _, err = NewRPCSampler(filepath.Join(t.TempDir(), "not-found")).Sample("/mnt/nfs")
+	require.Error(t, err)
+}
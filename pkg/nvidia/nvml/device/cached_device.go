@@ -0,0 +1,154 @@
+package device
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// CacheField is the NVML device field cached by the device.
+type CacheField string
+
+const (
+	// CacheFieldTemperatureThreshold is the temperature thresholds (nvmlDeviceGetTemperatureThreshold).
+	CacheFieldTemperatureThreshold CacheField = "temperature_threshold"
+	// CacheFieldPowerManagementLimit is the power management limit (nvmlDeviceGetPowerManagementLimit).
+	CacheFieldPowerManagementLimit CacheField = "power_management_limit"
+	// CacheFieldEnforcedPowerLimit is the enforced power limit (nvmlDeviceGetEnforcedPowerLimit).
+	CacheFieldEnforcedPowerLimit CacheField = "enforced_power_limit"
+	// CacheFieldMaxClockInfo is the max clock speeds (nvmlDeviceGetMaxClockInfo).
+	CacheFieldMaxClockInfo CacheField = "max_clock_info"
+
+	// CacheFieldTemperature is the current temperatures (nvmlDeviceGetTemperature).
+	CacheFieldTemperature CacheField = "temperature"
+	// CacheFieldPowerUsage is the current power usage (nvmlDeviceGetPowerUsage).
+	CacheFieldPowerUsage CacheField = "power_usage"
+	// CacheFieldClockInfo is the current clock speeds (nvmlDeviceGetClockInfo).
+	CacheFieldClockInfo CacheField = "clock_info"
+	// CacheFieldUtilizationRates is the current utilization rates (nvmlDeviceGetUtilizationRates).
+	CacheFieldUtilizationRates CacheField = "utilization_rates"
+)
+
+// DefaultCacheTTLs is the default caching TTLs per field.
+// The thresholds and limits are rarely changed (e.g., "nvidia-smi -pl"),
+// thus cached longer than the current readings, which are only cached
+// to share a single NVML call across the components checking at the same time.
+var DefaultCacheTTLs = map[CacheField]time.Duration{
+	CacheFieldTemperatureThreshold: 10 * time.Minute,
+	CacheFieldPowerManagementLimit: time.Minute,
+	CacheFieldEnforcedPowerLimit:   time.Minute,
+	CacheFieldMaxClockInfo:         10 * time.Minute,
+
+	CacheFieldTemperature:      5 * time.Second,
+	CacheFieldPowerUsage:       5 * time.Second,
+	CacheFieldClockInfo:        5 * time.Second,
+	CacheFieldUtilizationRates: 5 * time.Second,
+}
+
+// cachedDevice wraps a Device and caches the field values for the configured TTLs.
+// Only the successful NVML returns are cached, so that the errors
+// (e.g., GPU lost) are returned as soon as they happen.
+type cachedDevice struct {
+	Device
+
+	getTimeNowFunc func() time.Time
+	ttls           map[CacheField]time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+var _ Device = &cachedDevice{}
+
+type cacheKey struct {
+	field CacheField
+	// arg is the field argument (e.g., temperature sensor, clock type)
+	arg int
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func newCachedDevice(dev Device, ttls map[CacheField]time.Duration) *cachedDevice {
+	return &cachedDevice{
+		Device: dev,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		ttls:    ttls,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+// getCached returns the cached value of the field, or calls the function if
+// the field is not cached or expired. Concurrent misses may call the function
+// more than once, which is safe since NVML is thread-safe.
+func getCached[T any](d *cachedDevice, field CacheField, arg int, fn func() (T, nvml.Return)) (T, nvml.Return) {
+	ttl := d.ttls[field]
+	if ttl <= 0 {
+		return fn()
+	}
+
+	key := cacheKey{field: field, arg: arg}
+	now := d.getTimeNowFunc()
+
+	d.mu.Lock()
+	e, ok := d.entries[key]
+	d.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.value.(T), nvml.SUCCESS
+	}
+
+	v, ret := fn()
+	if ret != nvml.SUCCESS {
+		return v, ret
+	}
+
+	d.mu.Lock()
+	d.entries[key] = cacheEntry{value: v, expires: now.Add(ttl)}
+	d.mu.Unlock()
+	return v, ret
+}
+
+func (d *cachedDevice) GetTemperatureThreshold(thresholdType nvml.TemperatureThresholds) (uint32, nvml.Return) {
+	return getCached(d, CacheFieldTemperatureThreshold, int(thresholdType), func() (uint32, nvml.Return) {
+		return d.Device.GetTemperatureThreshold(thresholdType)
+	})
+}
+
+func (d *cachedDevice) GetPowerManagementLimit() (uint32, nvml.Return) {
+	return getCached(d, CacheFieldPowerManagementLimit, 0, d.Device.GetPowerManagementLimit)
+}
+
+func (d *cachedDevice) GetEnforcedPowerLimit() (uint32, nvml.Return) {
+	return getCached(d, CacheFieldEnforcedPowerLimit, 0, d.Device.GetEnforcedPowerLimit)
+}
+
+func (d *cachedDevice) GetMaxClockInfo(clockType nvml.ClockType) (uint32, nvml.Return) {
+	return getCached(d, CacheFieldMaxClockInfo, int(clockType), func() (uint32, nvml.Return) {
+		return d.Device.GetMaxClockInfo(clockType)
+	})
+}
+
+func (d *cachedDevice) GetTemperature(sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+	return getCached(d, CacheFieldTemperature, int(sensorType), func() (uint32, nvml.Return) {
+		return d.Device.GetTemperature(sensorType)
+	})
+}
+
+func (d *cachedDevice) GetPowerUsage() (uint32, nvml.Return) {
+	return getCached(d, CacheFieldPowerUsage, 0, d.Device.GetPowerUsage)
+}
+
+func (d *cachedDevice) GetClockInfo(clockType nvml.ClockType) (uint32, nvml.Return) {
+	return getCached(d, CacheFieldClockInfo, int(clockType), func() (uint32, nvml.Return) {
+		return d.Device.GetClockInfo(clockType)
+	})
+}
+
+func (d *cachedDevice) GetUtilizationRates() (nvml.Utilization, nvml.Return) {
+	return getCached(d, CacheFieldUtilizationRates, 0, d.Device.GetUtilizationRates)
+}
@@ -0,0 +1,101 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedDevice(t *testing.T) {
+	thresholdCalls := 0
+	powerCalls := 0
+	powerRet := nvml.SUCCESS
+	mockDev := &mock.Device{
+		GetTemperatureThresholdFunc: func(thresholdType nvml.TemperatureThresholds) (uint32, nvml.Return) {
+			thresholdCalls++
+			return 80 + uint32(thresholdType), nvml.SUCCESS
+		},
+		GetPowerUsageFunc: func() (uint32, nvml.Return) {
+			powerCalls++
+			return uint32(powerCalls * 1000), powerRet
+		},
+		GetEnforcedPowerLimitFunc: func() (uint32, nvml.Return) {
+			return 700000, nvml.SUCCESS
+		},
+	}
+	base := &nvDevice{Device: &stubDevice{Device: mockDev}, busID: "0000:00:00.0", uuid: "GPU-0"}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newCachedDevice(base, map[CacheField]time.Duration{
+		CacheFieldTemperatureThreshold: time.Minute,
+		CacheFieldPowerUsage:           5 * time.Second,
+	})
+	d.getTimeNowFunc = func() time.Time { return now }
+
+	// cached per threshold type
+	v, ret := d.GetTemperatureThreshold(nvml.TEMPERATURE_THRESHOLD_SHUTDOWN)
+	require.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint32(80), v)
+	v, _ = d.GetTemperatureThreshold(nvml.TEMPERATURE_THRESHOLD_SHUTDOWN)
+	assert.Equal(t, uint32(80), v)
+	v, _ = d.GetTemperatureThreshold(nvml.TEMPERATURE_THRESHOLD_SLOWDOWN)
+	assert.Equal(t, uint32(81), v)
+	assert.Equal(t, 2, thresholdCalls)
+
+	// expired
+	now = now.Add(time.Minute)
+	_, _ = d.GetTemperatureThreshold(nvml.TEMPERATURE_THRESHOLD_SHUTDOWN)
+	assert.Equal(t, 3, thresholdCalls)
+
+	p, _ := d.GetPowerUsage()
+	assert.Equal(t, uint32(1000), p)
+	p, _ = d.GetPowerUsage()
+	assert.Equal(t, uint32(1000), p)
+	assert.Equal(t, 1, powerCalls)
+
+	// errors are never cached, and the cached value is not returned once expired
+	now = now.Add(5 * time.Second)
+	powerRet = nvml.ERROR_GPU_IS_LOST
+	_, ret = d.GetPowerUsage()
+	assert.Equal(t, nvml.ERROR_GPU_IS_LOST, ret)
+	_, ret = d.GetPowerUsage()
+	assert.Equal(t, nvml.ERROR_GPU_IS_LOST, ret)
+	assert.Equal(t, 3, powerCalls)
+
+	// no TTL, not cached
+	l, ret := d.GetEnforcedPowerLimit()
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint32(700000), l)
+
+	// the wrapped device methods are still available
+	assert.Equal(t, "GPU-0", d.UUID())
+	assert.Equal(t, "0000:00:00.0", d.PCIBusID())
+}
+
+func TestNewWithCacheTTLs(t *testing.T) {
+	mockDev := &mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return "GPU-0", nvml.SUCCESS
+		},
+	}
+
+	dev := New(&stubDevice{Device: mockDev}, "0000:00:00.0")
+	_, ok := dev.(*nvDevice)
+	assert.True(t, ok)
+
+	dev = New(&stubDevice{Device: mockDev}, "0000:00:00.0", WithCacheTTLs(DefaultCacheTTLs))
+	_, ok = dev.(*cachedDevice)
+	assert.True(t, ok)
+	assert.Equal(t, "GPU-0", dev.UUID())
+
+	// test device wraps the cached device, so the injected errors are not cached
+	dev = New(&stubDevice{Device: mockDev}, "0000:00:00.0", WithCacheTTLs(DefaultCacheTTLs), WithGPULost())
+	td, ok := dev.(*testDevice)
+	require.True(t, ok)
+	_, ok = td.Device.(*cachedDevice)
+	assert.True(t, ok)
+}
@@ -0,0 +1,112 @@
+package device
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// errChaosTimeout is the fabric state error of the injected NVML timeouts.
+var errChaosTimeout = errors.New("nvml: timeout (injected by chaos mode)")
+
+// chaosDevice wraps a Device to time out the NVML queries at random,
+// blocking for the delay and then returning "nvml.ERROR_TIMEOUT".
+// Only the most commonly used queries are covered.
+type chaosDevice struct {
+	Device
+
+	timeoutFunc  func() bool
+	timeoutDelay time.Duration
+	sleepFunc    func(time.Duration)
+}
+
+var _ Device = &chaosDevice{}
+
+// timeout returns true after the delay if the query should time out.
+func (d *chaosDevice) timeout() bool {
+	if !d.timeoutFunc() {
+		return false
+	}
+	if d.timeoutDelay > 0 {
+		d.sleepFunc(d.timeoutDelay)
+	}
+	return true
+}
+
+func (d *chaosDevice) GetFabricState() (FabricState, error) {
+	if d.timeout() {
+		return FabricState{}, errChaosTimeout
+	}
+	return d.Device.GetFabricState()
+}
+
+func (d *chaosDevice) GetTemperature(sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetTemperature(sensorType)
+}
+
+func (d *chaosDevice) GetPowerUsage() (uint32, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetPowerUsage()
+}
+
+func (d *chaosDevice) GetUtilizationRates() (nvml.Utilization, nvml.Return) {
+	if d.timeout() {
+		return nvml.Utilization{}, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetUtilizationRates()
+}
+
+func (d *chaosDevice) GetMemoryInfo() (nvml.Memory, nvml.Return) {
+	if d.timeout() {
+		return nvml.Memory{}, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetMemoryInfo()
+}
+
+func (d *chaosDevice) GetClockInfo(clockType nvml.ClockType) (uint32, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetClockInfo(clockType)
+}
+
+func (d *chaosDevice) GetCurrentClocksEventReasons() (uint64, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetCurrentClocksEventReasons()
+}
+
+func (d *chaosDevice) GetTotalEccErrors(errorType nvml.MemoryErrorType, counterType nvml.EccCounterType) (uint64, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetTotalEccErrors(errorType, counterType)
+}
+
+func (d *chaosDevice) GetRemappedRows() (int, int, bool, bool, nvml.Return) {
+	if d.timeout() {
+		return 0, 0, false, false, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetRemappedRows()
+}
+
+func (d *chaosDevice) GetNvLinkState(link int) (nvml.EnableState, nvml.Return) {
+	if d.timeout() {
+		return 0, nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetNvLinkState(link)
+}
+
+func (d *chaosDevice) GetFieldValues(values []nvml.FieldValue) nvml.Return {
+	if d.timeout() {
+		return nvml.ERROR_TIMEOUT
+	}
+	return d.Device.GetFieldValues(values)
+}
@@ -0,0 +1,51 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/NVIDIA/go-nvml/pkg/nvml/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosDevice(t *testing.T) {
+	md := &mock.Device{
+		GetUUIDFunc: func() (string, nvml.Return) {
+			return "GPU-CHAOS", nvml.SUCCESS
+		},
+		GetTemperatureFunc: func(nvml.TemperatureSensors) (uint32, nvml.Return) {
+			return 50, nvml.SUCCESS
+		},
+		GetPowerUsageFunc: func() (uint32, nvml.Return) {
+			return 300, nvml.SUCCESS
+		},
+	}
+
+	timeout := false
+	dev := New(newStubDevice(md), "0000:00:1e.0", WithTimeoutInjection(func() bool { return timeout }, time.Second))
+	cd, ok := dev.(*chaosDevice)
+	require.True(t, ok)
+
+	var slept time.Duration
+	cd.sleepFunc = func(d time.Duration) { slept += d }
+
+	temp, ret := dev.GetTemperature(nvml.TEMPERATURE_GPU)
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint32(50), temp)
+	assert.Zero(t, slept)
+
+	timeout = true
+	_, ret = dev.GetTemperature(nvml.TEMPERATURE_GPU)
+	assert.Equal(t, nvml.ERROR_TIMEOUT, ret)
+	_, ret = dev.GetPowerUsage()
+	assert.Equal(t, nvml.ERROR_TIMEOUT, ret)
+	_, err := dev.GetFabricState()
+	assert.ErrorIs(t, err, errChaosTimeout)
+	assert.Equal(t, 3*time.Second, slept)
+
+	// no chaos wrapper without the timeout function
+	_, ok = New(newStubDevice(md), "0000:00:1e.0").(*chaosDevice)
+	assert.False(t, ok)
+}
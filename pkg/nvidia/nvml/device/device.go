@@ -4,6 +4,7 @@ package device
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
@@ -54,15 +55,30 @@ func New(dev device.Device, busID string, opts ...OpOption) Device {
 	}
 
 	// Create the base device
-	baseDevice := &nvDevice{Device: dev, busID: busID, uuid: uuid, driverMajor: op.DriverMajor}
+	var baseDevice Device = &nvDevice{Device: dev, busID: busID, uuid: uuid, driverMajor: op.DriverMajor}
+
+	// Cache below the test device, so the injected errors and values are never cached
+	if len(op.CacheTTLs) > 0 {
+		baseDevice = newCachedDevice(baseDevice, op.CacheTTLs)
+	}
 
 	// If ANY test flags are set, wrap with testDevice
-	if op.GPULost || op.GPURequiresReset || op.FabricHealthUnhealthy {
-		return &testDevice{
+	if op.GPULost || op.GPURequiresReset || op.FabricHealthUnhealthy || !op.FieldOverrides.IsEmpty() {
+		baseDevice = &testDevice{
 			Device:                baseDevice,
 			gpuLost:               op.GPULost,
 			gpuRequiresReset:      op.GPURequiresReset,
 			fabricHealthUnhealthy: op.FabricHealthUnhealthy,
+			fieldOverrides:        op.FieldOverrides,
+		}
+	}
+
+	if op.TimeoutFunc != nil {
+		baseDevice = &chaosDevice{
+			Device:       baseDevice,
+			timeoutFunc:  op.TimeoutFunc,
+			timeoutDelay: op.TimeoutDelay,
+			sleepFunc:    time.Sleep,
 		}
 	}
 
@@ -80,6 +96,14 @@ type Op struct {
 	GPURequiresReset bool
 	// FabricHealthUnhealthy indicates that GetGpuFabricState should return SUCCESS but with unhealthy status
 	FabricHealthUnhealthy bool
+	// FieldOverrides is the fake NVML values returned instead of the hardware values
+	FieldOverrides *FieldOverrides
+	// CacheTTLs is the caching TTLs per field (no caching if empty)
+	CacheTTLs map[CacheField]time.Duration
+	// TimeoutFunc decides whether to time out each NVML query (nil to never time out)
+	TimeoutFunc func() bool
+	// TimeoutDelay is how long a timed out NVML query blocks before returning
+	TimeoutDelay time.Duration
 }
 
 // OpOption is a function that configures the Op struct
@@ -113,6 +137,21 @@ func WithFabricHealthUnhealthy() OpOption {
 	}
 }
 
+// WithFieldOverrides returns an OpOption that enables fake NVML values injection
+func WithFieldOverrides(overrides *FieldOverrides) OpOption {
+	return func(op *Op) {
+		op.FieldOverrides = overrides
+	}
+}
+
+// WithCacheTTLs returns an OpOption that caches the field values for the TTLs.
+// The fields with zero TTL are not cached.
+func WithCacheTTLs(ttls map[CacheField]time.Duration) OpOption {
+	return func(op *Op) {
+		op.CacheTTLs = ttls
+	}
+}
+
 // WithDriverMajor returns an OpOption that sets the driver major version.
 // This is used to gate V3 fabric API calls which require driver >= 550.
 func WithDriverMajor(major int) OpOption {
@@ -120,3 +159,13 @@ func WithDriverMajor(major int) OpOption {
 		op.DriverMajor = major
 	}
 }
+
+// WithTimeoutInjection returns an OpOption that times out the NVML queries
+// whenever the function returns true, after blocking for the delay.
+// This is used by the chaos mode to validate the NVML timeout handling.
+func WithTimeoutInjection(timeoutFunc func() bool, delay time.Duration) OpOption {
+	return func(op *Op) {
+		op.TimeoutFunc = timeoutFunc
+		op.TimeoutDelay = delay
+	}
+}
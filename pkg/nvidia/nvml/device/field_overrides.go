@@ -0,0 +1,63 @@
+package device
+
+import (
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// FieldOverrides is the fake NVML values returned by a test device
+// instead of the values read from the hardware, to validate the alerting
+// end-to-end on real hardware without harming it.
+// Nil fields are read from the hardware.
+type FieldOverrides struct {
+	// TemperatureGPU overrides the GPU core temperature (nvml.TEMPERATURE_GPU) in degrees Celsius.
+	TemperatureGPU *uint32 `json:"temperature_gpu,omitempty"`
+
+	// ECCCorrectedTotal overrides the total corrected ECC error count
+	// (both volatile and aggregate) returned by nvmlDeviceGetTotalEccErrors.
+	ECCCorrectedTotal *uint64 `json:"ecc_corrected_total,omitempty"`
+	// ECCUncorrectedTotal overrides the total uncorrected ECC error count
+	// (both volatile and aggregate) returned by nvmlDeviceGetTotalEccErrors.
+	ECCUncorrectedTotal *uint64 `json:"ecc_uncorrected_total,omitempty"`
+
+	// RemappedRows overrides the row remapping state returned by nvmlDeviceGetRemappedRows.
+	RemappedRows *RemappedRowsOverride `json:"remapped_rows,omitempty"`
+}
+
+// RemappedRowsOverride is the fake row remapping state.
+type RemappedRowsOverride struct {
+	Correctable     int  `json:"correctable"`
+	Uncorrectable   int  `json:"uncorrectable"`
+	Pending         bool `json:"pending"`
+	FailureOccurred bool `json:"failure_occurred"`
+}
+
+// IsEmpty returns true if no field is overridden.
+func (o *FieldOverrides) IsEmpty() bool {
+	return o == nil || (o.TemperatureGPU == nil && o.ECCCorrectedTotal == nil && o.ECCUncorrectedTotal == nil && o.RemappedRows == nil)
+}
+
+// temperature returns the overridden temperature for the sensor, if any.
+func (o *FieldOverrides) temperature(sensorType nvml.TemperatureSensors) (uint32, bool) {
+	if o == nil || o.TemperatureGPU == nil || sensorType != nvml.TEMPERATURE_GPU {
+		return 0, false
+	}
+	return *o.TemperatureGPU, true
+}
+
+// totalEccErrors returns the overridden total ECC error count for the error type, if any.
+func (o *FieldOverrides) totalEccErrors(errorType nvml.MemoryErrorType) (uint64, bool) {
+	if o == nil {
+		return 0, false
+	}
+	switch errorType {
+	case nvml.MEMORY_ERROR_TYPE_CORRECTED:
+		if o.ECCCorrectedTotal != nil {
+			return *o.ECCCorrectedTotal, true
+		}
+	case nvml.MEMORY_ERROR_TYPE_UNCORRECTED:
+		if o.ECCUncorrectedTotal != nil {
+			return *o.ECCUncorrectedTotal, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,94 @@
+//go:build linux
+
+package device
+
+import (
+	"testing"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldOverridesIsEmpty(t *testing.T) {
+	var nilOverrides *FieldOverrides
+	assert.True(t, nilOverrides.IsEmpty())
+	assert.True(t, (&FieldOverrides{}).IsEmpty())
+
+	temp := uint32(95)
+	assert.False(t, (&FieldOverrides{TemperatureGPU: &temp}).IsEmpty())
+	assert.False(t, (&FieldOverrides{RemappedRows: &RemappedRowsOverride{}}).IsEmpty())
+}
+
+func TestTestDevice_FieldOverrides(t *testing.T) {
+	temp := uint32(95)
+	corrected := uint64(10)
+	uncorrected := uint64(3)
+
+	mockDev := newMockNvDevice()
+	mockDev.GetTemperatureFunc = func(sensorType nvml.TemperatureSensors) (uint32, nvml.Return) {
+		return 40, nvml.SUCCESS
+	}
+	mockDev.GetTotalEccErrorsFunc = func(errorType nvml.MemoryErrorType, counterType nvml.EccCounterType) (uint64, nvml.Return) {
+		return 0, nvml.SUCCESS
+	}
+	mockDev.GetRemappedRowsFunc = func() (int, int, bool, bool, nvml.Return) {
+		return 0, 0, false, false, nvml.SUCCESS
+	}
+
+	td := &testDevice{
+		Device: mockDev,
+		fieldOverrides: &FieldOverrides{
+			TemperatureGPU:      &temp,
+			ECCCorrectedTotal:   &corrected,
+			ECCUncorrectedTotal: &uncorrected,
+			RemappedRows:        &RemappedRowsOverride{Uncorrectable: 2, Pending: true},
+		},
+	}
+
+	v, ret := td.GetTemperature(nvml.TEMPERATURE_GPU)
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint32(95), v)
+
+	// other sensors are read from the device
+	v, ret = td.GetTemperature(nvml.TemperatureSensors(1))
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint32(40), v)
+
+	cnt, ret := td.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.VOLATILE_ECC)
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint64(10), cnt)
+	cnt, ret = td.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.AGGREGATE_ECC)
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, uint64(3), cnt)
+
+	corrRows, uncRows, pending, failed, ret := td.GetRemappedRows()
+	assert.Equal(t, nvml.SUCCESS, ret)
+	assert.Equal(t, 0, corrRows)
+	assert.Equal(t, 2, uncRows)
+	assert.True(t, pending)
+	assert.False(t, failed)
+
+	// GPU lost error takes precedence over the overrides
+	td.gpuLost = true
+	v, ret = td.GetTemperature(nvml.TEMPERATURE_GPU)
+	assert.Equal(t, nvml.ERROR_GPU_IS_LOST, ret)
+	assert.Equal(t, uint32(0), v)
+}
+
+func TestNewWithFieldOverrides(t *testing.T) {
+	mockDev := newMockNvDevice()
+	mockDev.GetUUIDFunc = func() (string, nvml.Return) {
+		return "GPU-1", nvml.SUCCESS
+	}
+
+	// empty overrides do not wrap the device
+	dev := New(mockDev.stubDevice, "0000:00:00.0", WithFieldOverrides(&FieldOverrides{}))
+	_, ok := dev.(*testDevice)
+	assert.False(t, ok)
+
+	temp := uint32(95)
+	dev = New(mockDev.stubDevice, "0000:00:00.0", WithFieldOverrides(&FieldOverrides{TemperatureGPU: &temp}))
+	td, ok := dev.(*testDevice)
+	assert.True(t, ok)
+	assert.Equal(t, &temp, td.fieldOverrides.TemperatureGPU)
+}
@@ -18,6 +18,9 @@ type testDevice struct {
 
 	// Fabric health injection
 	fabricHealthUnhealthy bool
+
+	// Fake NVML values injection
+	fieldOverrides *FieldOverrides
 }
 
 var _ Device = &testDevice{}
@@ -158,6 +161,9 @@ func (d *testDevice) GetTotalEccErrors(errorType nvml.MemoryErrorType, counterTy
 	if err := d.getErrorReturn(); err != nvml.SUCCESS {
 		return 0, err
 	}
+	if v, ok := d.fieldOverrides.totalEccErrors(errorType); ok {
+		return v, nvml.SUCCESS
+	}
 	return d.Device.GetTotalEccErrors(errorType, counterType)
 }
 
@@ -165,6 +171,10 @@ func (d *testDevice) GetRemappedRows() (int, int, bool, bool, nvml.Return) {
 	if err := d.getErrorReturn(); err != nvml.SUCCESS {
 		return 0, 0, false, false, err
 	}
+	if d.fieldOverrides != nil && d.fieldOverrides.RemappedRows != nil {
+		rr := d.fieldOverrides.RemappedRows
+		return rr.Correctable, rr.Uncorrectable, rr.Pending, rr.FailureOccurred, nvml.SUCCESS
+	}
 	return d.Device.GetRemappedRows()
 }
 
@@ -179,6 +189,9 @@ func (d *testDevice) GetTemperature(sensorType nvml.TemperatureSensors) (uint32,
 	if err := d.getErrorReturn(); err != nvml.SUCCESS {
 		return 0, err
 	}
+	if v, ok := d.fieldOverrides.temperature(sensorType); ok {
+		return v, nvml.SUCCESS
+	}
 	return d.Device.GetTemperature(sensorType)
 }
 
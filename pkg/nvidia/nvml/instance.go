@@ -35,6 +35,16 @@ type FailureInjectorConfig struct {
 	// When enabled, gpud continues running but all nvidia components report unhealthy.
 	// ref. https://github.com/leptonai/gpud/pull/1180
 	NVMLDeviceGetDevicesError bool
+
+	// GPUFieldOverrides maps the GPU UUID to the fake NVML values
+	// (e.g., temperature, ECC error counts, remapped rows) returned
+	// instead of the values read from the hardware.
+	GPUFieldOverrides map[string]*device.FieldOverrides
+
+	// NVMLTimeoutFunc decides whether to time out each NVML device query,
+	// blocking for the NVMLTimeoutDelay (e.g., set by the chaos mode).
+	NVMLTimeoutFunc  func() bool
+	NVMLTimeoutDelay time.Duration
 }
 
 var _ Instance = &instance{}
@@ -245,6 +255,10 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context),
 			// drivers (e.g., 535.x) causes a symbol lookup crash.
 			opts = append(opts, device.WithDriverMajor(driverMajor))
 
+			// Cache the field values, since multiple components query the same fields
+			// and the thresholds/limits rarely change.
+			opts = append(opts, device.WithCacheTTLs(device.DefaultCacheTTLs))
+
 			if failureInjector != nil {
 				// Check if this UUID should inject GPU Lost error
 				for _, injectedUUID := range failureInjector.GPUUUIDsWithGPULost {
@@ -267,6 +281,14 @@ func newInstance(refreshCtx context.Context, refreshNVML func(context.Context),
 						break
 					}
 				}
+				// Check if this UUID should return fake NVML values
+				if overrides, ok := failureInjector.GPUFieldOverrides[uuid]; ok {
+					opts = append(opts, device.WithFieldOverrides(overrides))
+				}
+				// Check if the NVML queries should time out at random
+				if failureInjector.NVMLTimeoutFunc != nil {
+					opts = append(opts, device.WithTimeoutInjection(failureInjector.NVMLTimeoutFunc, failureInjector.NVMLTimeoutDelay))
+				}
 			}
 
 			devs[uuid] = device.New(dev, busID, opts...)
@@ -0,0 +1,91 @@
+package nvml
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+const (
+	// LabelGPUUUID is the metric label of the GPU UUID.
+	LabelGPUUUID = "gpu_uuid"
+	// LabelGPUIndex is the metric label of the stable GPU index (see GPUMappings).
+	LabelGPUIndex = "gpu_index"
+)
+
+// GPUMetricLabelKeys returns the label keys of a per-GPU metric:
+// the component, the GPU UUID and index, followed by the extra label keys.
+// Every per-GPU NVIDIA metric must be labeled with both the GPU UUID and index,
+// so that the metrics can be joined downstream by either.
+func GPUMetricLabelKeys(extra ...string) []string {
+	return append([]string{pkgmetrics.MetricComponentLabelKey, LabelGPUUUID, LabelGPUIndex}, extra...)
+}
+
+// GPUMappings returns the GPU UUID to index mappings of the devices.
+// The index is assigned in the order of the PCI bus IDs, thus stable
+// across the restarts and the same as the "nvidia-smi" index.
+// The devices without the bus ID are ordered last, by the UUID.
+func GPUMappings(devs map[string]device.Device) apiv1.GPUMappings {
+	mappings := make(apiv1.GPUMappings, 0, len(devs))
+	for uuid, dev := range devs {
+		m := apiv1.GPUMapping{UUID: uuid}
+		if dev != nil {
+			m.BusID = dev.PCIBusID()
+		}
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		bi, bj := normalizeBusID(mappings[i].BusID), normalizeBusID(mappings[j].BusID)
+		if (bi == "") != (bj == "") {
+			return bj == ""
+		}
+		if bi != bj {
+			return bi < bj
+		}
+		return mappings[i].UUID < mappings[j].UUID
+	})
+	for i := range mappings {
+		mappings[i].Index = i
+	}
+	return mappings
+}
+
+// normalizeBusID normalizes the PCI bus ID for the ordering, as NVML reports
+// the 8-digit domain in the upper case (e.g., "00000000:3B:00.0" for "0000:3b:00.0").
+func normalizeBusID(busID string) string {
+	busID = strings.ToLower(busID)
+	if len(busID) == len("00000000:3b:00.0") && strings.HasPrefix(busID, "0000") {
+		busID = busID[4:]
+	}
+	return busID
+}
+
+// GPULabeler returns the per-GPU metric labels.
+type GPULabeler struct {
+	indexes map[string]string
+}
+
+// NewGPULabeler creates a GPU labeler with the indexes of the devices.
+func NewGPULabeler(devs map[string]device.Device) *GPULabeler {
+	l := &GPULabeler{indexes: make(map[string]string, len(devs))}
+	for _, m := range GPUMappings(devs) {
+		l.indexes[m.UUID] = strconv.Itoa(m.Index)
+	}
+	return l
+}
+
+// Labels returns the GPU UUID and index labels of the GPU.
+// The index is empty if the GPU is not one of the devices.
+// The returned labels are a new map, safe to add the extra labels.
+func (l *GPULabeler) Labels(uuid string) prometheus.Labels {
+	return prometheus.Labels{
+		LabelGPUUUID:  uuid,
+		LabelGPUIndex: l.indexes[uuid],
+	}
+}
@@ -0,0 +1,49 @@
+package nvml
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func TestGPUMappings(t *testing.T) {
+	devs := map[string]device.Device{
+		"GPU-3": testutil.NewMockDevice(nil, "", "", "", ""),
+		"GPU-2": testutil.NewMockDevice(nil, "", "", "", "00000000:5E:00.0"),
+		"GPU-1": testutil.NewMockDevice(nil, "", "", "", "0000:86:00.0"),
+		"GPU-0": testutil.NewMockDevice(nil, "", "", "", "0000:3b:00.0"),
+	}
+	assert.Equal(t, apiv1.GPUMappings{
+		{Index: 0, UUID: "GPU-0", BusID: "0000:3b:00.0"},
+		{Index: 1, UUID: "GPU-2", BusID: "00000000:5E:00.0"},
+		{Index: 2, UUID: "GPU-1", BusID: "0000:86:00.0"},
+		{Index: 3, UUID: "GPU-3"},
+	}, GPUMappings(devs))
+
+	assert.Empty(t, GPUMappings(nil))
+}
+
+func TestGPULabeler(t *testing.T) {
+	l := NewGPULabeler(map[string]device.Device{
+		"GPU-b": testutil.NewMockDevice(nil, "", "", "", "0000:5e:00.0"),
+		"GPU-a": testutil.NewMockDevice(nil, "", "", "", "0000:3b:00.0"),
+	})
+	assert.Equal(t, prometheus.Labels{LabelGPUUUID: "GPU-b", LabelGPUIndex: "1"}, l.Labels("GPU-b"))
+	assert.Equal(t, prometheus.Labels{LabelGPUUUID: "GPU-x", LabelGPUIndex: ""}, l.Labels("GPU-x"))
+
+	// the labels are safe to extend
+	labels := l.Labels("GPU-a")
+	labels["link"] = "0"
+	assert.Equal(t, prometheus.Labels{LabelGPUUUID: "GPU-a", LabelGPUIndex: "0"}, l.Labels("GPU-a"))
+}
+
+func TestGPUMetricLabelKeys(t *testing.T) {
+	assert.Equal(t, []string{pkgmetrics.MetricComponentLabelKey, LabelGPUUUID, LabelGPUIndex}, GPUMetricLabelKeys())
+	assert.Equal(t, []string{pkgmetrics.MetricComponentLabelKey, LabelGPUUUID, LabelGPUIndex, "link"}, GPUMetricLabelKeys("link"))
+}
@@ -77,6 +77,15 @@ var allSuccessInterface = &nvmlmock.Interface{
 			GetClockInfoFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
 				return 1, nvml.SUCCESS
 			},
+			GetMaxClockInfoFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				return 1, nvml.SUCCESS
+			},
+			GetApplicationsClockFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				return 1, nvml.SUCCESS
+			},
+			GetDefaultApplicationsClockFunc: func(clockType nvml.ClockType) (uint32, nvml.Return) {
+				return 1, nvml.SUCCESS
+			},
 			GetMemoryInfo_v2Func: func() (nvml.Memory_v2, nvml.Return) {
 				return nvml.Memory_v2{}, nvml.SUCCESS
 			},
@@ -107,6 +116,9 @@ var allSuccessInterface = &nvmlmock.Interface{
 			GetUtilizationRatesFunc: func() (nvml.Utilization, nvml.Return) {
 				return nvml.Utilization{}, nvml.SUCCESS
 			},
+			GetPcieThroughputFunc: func(pcieUtilCounter nvml.PcieUtilCounter) (uint32, nvml.Return) {
+				return 1, nvml.SUCCESS
+			},
 			GetComputeRunningProcessesFunc: func() ([]nvml.ProcessInfo, nvml.Return) {
 				return []nvml.ProcessInfo{{Pid: 999}}, nvml.SUCCESS
 			},
@@ -205,6 +205,58 @@ func TestNewInstance_FailureInjectorAndOverrideBranches_WithMockey(t *testing.T)
 	})
 }
 
+func TestNewInstance_FailureInjectorFieldOverrides_WithMockey(t *testing.T) {
+	mockey.PatchConvey("newInstance returns fake NVML values for the overridden GPU", t, func() {
+		dev := testutil.NewMockDeviceWithIDs(
+			&mock.Device{
+				GetNameFunc: func() (string, nvml.Return) {
+					return "NVIDIA H100 80GB HBM3", nvml.SUCCESS
+				},
+				GetUUIDFunc: func() (string, nvml.Return) {
+					return "GPU-OVERRIDE-1", nvml.SUCCESS
+				},
+				GetCudaComputeCapabilityFunc: func() (int, int, nvml.Return) {
+					return 9, 0, nvml.SUCCESS
+				},
+				GetBrandFunc: func() (nvml.BrandType, nvml.Return) {
+					return nvml.BRAND_TESLA, nvml.SUCCESS
+				},
+				GetTemperatureFunc: func(sensor nvml.TemperatureSensors) (uint32, nvml.Return) {
+					return 40, nvml.SUCCESS
+				},
+			},
+			"hopper",
+			"Tesla",
+			"9.0",
+			"0000:01:00.0",
+			"GPU-OVERRIDE-1",
+			"SERIAL-1",
+			1,
+			11,
+		)
+
+		lib := newMockLibrary("550.120.05", 12040, []nvlibdevice.Device{dev}, nil)
+		mockey.Mock(nvmllib.New).To(func(opts ...nvmllib.OpOption) (nvmllib.Library, error) {
+			return lib, nil
+		}).Build()
+
+		temp := uint32(95)
+		inst, err := newInstance(context.Background(), nil, &FailureInjectorConfig{
+			GPUFieldOverrides: map[string]*device.FieldOverrides{
+				"GPU-OVERRIDE-1": {TemperatureGPU: &temp},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, inst)
+
+		d, ok := inst.Devices()["GPU-OVERRIDE-1"]
+		require.True(t, ok)
+		v, ret := d.GetTemperature(nvml.TEMPERATURE_GPU)
+		assert.Equal(t, nvml.SUCCESS, ret)
+		assert.Equal(t, uint32(95), v)
+	})
+}
+
 func TestNewInstance_GetUUIDError_WithMockey(t *testing.T) {
 	mockey.PatchConvey("newInstance returns error when device UUID query fails", t, func() {
 		dev := testutil.NewMockDevice(
@@ -0,0 +1,44 @@
+package nvml
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+// DeviceResult is the query result of a device.
+type DeviceResult[T any] struct {
+	UUID   string
+	Device device.Device
+	Value  T
+	Err    error
+}
+
+// QueryDevices runs the query against all devices in parallel,
+// and returns the results sorted by the device UUID.
+// NVML is thread-safe, so the devices can be queried concurrently
+// with the single shared NVML session, rather than polling one GPU after another,
+// which takes long enough on 8-GPU nodes to skew the readings across GPUs.
+// ref. https://docs.nvidia.com/deploy/nvml-api/nvml-api-reference.html#nvml-api-reference
+func QueryDevices[T any](devs map[string]device.Device, query func(uuid string, dev device.Device) (T, error)) []DeviceResult[T] {
+	results := make([]DeviceResult[T], 0, len(devs))
+	for uuid, dev := range devs {
+		results = append(results, DeviceResult[T]{UUID: uuid, Device: dev})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UUID < results[j].UUID
+	})
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *DeviceResult[T]) {
+			defer wg.Done()
+			r.Value, r.Err = query(r.UUID, r.Device)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
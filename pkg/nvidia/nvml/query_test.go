@@ -0,0 +1,63 @@
+package nvml
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+)
+
+func TestQueryDevices(t *testing.T) {
+	devs := map[string]device.Device{
+		"GPU-2": nil,
+		"GPU-0": nil,
+		"GPU-1": nil,
+	}
+
+	// every query blocks until all devices are queried,
+	// which only completes if the devices are queried in parallel
+	var started sync.WaitGroup
+	started.Add(len(devs))
+	errGPU1 := errors.New("gpu lost")
+
+	done := make(chan []DeviceResult[string])
+	go func() {
+		done <- QueryDevices(devs, func(uuid string, _ device.Device) (string, error) {
+			started.Done()
+			started.Wait()
+			if uuid == "GPU-1" {
+				return "", errGPU1
+			}
+			return "value-" + uuid, nil
+		})
+	}()
+
+	var results []DeviceResult[string]
+	select {
+	case results = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("devices are not queried in parallel")
+	}
+
+	require.Len(t, results, 3)
+	assert.Equal(t, "GPU-0", results[0].UUID)
+	assert.Equal(t, "value-GPU-0", results[0].Value)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "GPU-1", results[1].UUID)
+	assert.ErrorIs(t, results[1].Err, errGPU1)
+	assert.Equal(t, "GPU-2", results[2].UUID)
+	assert.Equal(t, "value-GPU-2", results[2].Value)
+}
+
+func TestQueryDevicesEmpty(t *testing.T) {
+	results := QueryDevices(nil, func(string, device.Device) (int, error) {
+		t.Fatal("unexpected query")
+		return 0, nil
+	})
+	assert.Empty(t, results)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
 	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/log"
 	"github.com/leptonai/gpud/pkg/process"
@@ -18,7 +19,14 @@ const DeviceVendorID = "10de"
 
 // ListPCIGPUs returns all "lspci" lines that represents NVIDIA GPU devices.
 func ListPCIGPUs(ctx context.Context) ([]string, error) {
-	return listPCIs(ctx, "lspci -nn", isNVIDIAGPUPCI)
+	return ListPCIs(ctx, isNVIDIAGPUPCI)
+}
+
+// ListPCIs returns all NVIDIA "lspci -nn" lines that match the function.
+// The "lspci" output is shared across the callers (e.g., the GPU count and
+// the fabric manager components), thus "lspci" runs once per check cycle.
+func ListPCIs(ctx context.Context, matchFunc func(line string) bool) ([]string, error) {
+	return listPCIs(ctx, "lspci -nn", matchFunc)
 }
 
 // 3D controller represents the GPU device itself
@@ -33,6 +41,25 @@ func isNVIDIAGPUPCI(line string) bool {
 }
 
 func listPCIs(ctx context.Context, command string, matchFunc func(line string) bool) ([]string, error) {
+	out, err := pkgexec.Do(ctx, command, func(ctx context.Context) ([]byte, error) {
+		lines, err := readNVIDIAPCIs(ctx, command)
+		return []byte(strings.Join(lines, "\n")), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" && matchFunc != nil && matchFunc(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// readNVIDIAPCIs runs the lspci command and returns the lines of the NVIDIA devices.
+func readNVIDIAPCIs(ctx context.Context, command string) ([]string, error) {
 	lspciPath, err := file.LocateExecutable(strings.Split(command, " ")[0])
 	if lspciPath == "" || err != nil {
 		return nil, fmt.Errorf("failed to locate lspci: %w", err)
@@ -63,11 +90,7 @@ func listPCIs(ctx context.Context, command string, matchFunc func(line string) b
 		process.WithReadStdout(),
 		process.WithReadStderr(),
 		process.WithProcessLine(func(line string) {
-			if !strings.Contains(strings.ToLower(line), DeviceVendorID) {
-				return
-			}
-
-			if matchFunc != nil && matchFunc(line) {
+			if strings.Contains(strings.ToLower(line), DeviceVendorID) {
 				lines = append(lines, line)
 			}
 		}),
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	pkgexec "github.com/leptonai/gpud/pkg/exec"
 	"github.com/leptonai/gpud/pkg/file"
 	"github.com/leptonai/gpud/pkg/process"
 )
@@ -51,6 +53,8 @@ func TestDeviceVendorID(t *testing.T) {
 // TestListPCIs_LspciNotFound tests the lspci not found error path.
 func TestListPCIs_LspciNotFound(t *testing.T) {
 	mockey.PatchConvey("lspci not found", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("", errors.New("executable not found")).Build()
 
 		ctx := context.Background()
@@ -65,6 +69,8 @@ func TestListPCIs_LspciNotFound(t *testing.T) {
 // TestListPCIs_LspciEmptyPath tests when LocateExecutable returns empty path.
 func TestListPCIs_LspciEmptyPath(t *testing.T) {
 	mockey.PatchConvey("lspci empty path", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("", nil).Build()
 
 		ctx := context.Background()
@@ -79,6 +85,8 @@ func TestListPCIs_LspciEmptyPath(t *testing.T) {
 // TestListPCIs_ProcessNewError tests when process creation fails.
 func TestListPCIs_ProcessNewError(t *testing.T) {
 	mockey.PatchConvey("process new error", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 		mockey.Mock(process.New).Return(nil, errors.New("failed to create process")).Build()
 
@@ -94,6 +102,8 @@ func TestListPCIs_ProcessNewError(t *testing.T) {
 // TestListPCIs_ProcessStartError tests when process start fails.
 func TestListPCIs_ProcessStartError(t *testing.T) {
 	mockey.PatchConvey("process start error", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 
 		mockProc := &mockProcess{startErr: errors.New("failed to start process")}
@@ -111,6 +121,8 @@ func TestListPCIs_ProcessStartError(t *testing.T) {
 // TestListPCIs_ProcessReadError tests when reading process output fails.
 func TestListPCIs_ProcessReadError(t *testing.T) {
 	mockey.PatchConvey("process read error", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 
 		mockProc := &mockProcess{}
@@ -129,6 +141,8 @@ func TestListPCIs_ProcessReadError(t *testing.T) {
 // TestListPCIs_SuccessWithNoGPUs tests successful execution but no GPUs found.
 func TestListPCIs_SuccessWithNoGPUs(t *testing.T) {
 	mockey.PatchConvey("success with no GPUs", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 
 		mockProc := &mockProcess{}
@@ -149,6 +163,8 @@ func TestListPCIs_SuccessWithNoGPUs(t *testing.T) {
 // TestListPCIs_CloseError tests that close errors are logged but don't fail the operation.
 func TestListPCIs_CloseError(t *testing.T) {
 	mockey.PatchConvey("close error logged", t, func() {
+		pkgexec.Default().Purge()
+
 		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
 
 		// Mock process that fails on Close
@@ -164,3 +180,45 @@ func TestListPCIs_CloseError(t *testing.T) {
 		assert.NotNil(t, gpus)
 	})
 }
+
+// outputProcess is a mock process that writes the lines to the stdout.
+type outputProcess struct {
+	mockProcess
+	stdout string
+}
+
+func (m *outputProcess) StdoutReader() io.Reader { return strings.NewReader(m.stdout) }
+func (m *outputProcess) StderrReader() io.Reader { return strings.NewReader("") }
+func (m *outputProcess) Closed() bool            { return false }
+
+// TestListPCIs_SharedOutput tests that the lspci output is shared across the match functions.
+func TestListPCIs_SharedOutput(t *testing.T) {
+	mockey.PatchConvey("lspci runs once for both match functions", t, func() {
+		pkgexec.Default().Purge()
+
+		mockey.Mock(file.LocateExecutable).Return("/usr/bin/lspci", nil).Build()
+		runs := 0
+		mockey.Mock(process.New).To(func(...process.OpOption) (process.Process, error) {
+			runs++
+			return &outputProcess{stdout: strings.Join([]string{
+				"0000:00:1f.0 ISA bridge [0601]: Intel Corporation Device [8086:1234]",
+				"0005:00:00.0 Bridge [0680]: NVIDIA Corporation Device [10de:1af1] (rev a1)",
+				"000b:00:00.0 3D controller [0302]: NVIDIA Corporation GA100 [A100 SXM4 80GB] [10de:20b2] (rev a1)",
+			}, "\n")}, nil
+		}).Build()
+
+		gpus, err := ListPCIGPUs(context.Background())
+		require.NoError(t, err)
+		require.Len(t, gpus, 1)
+		assert.Contains(t, gpus[0], "3D controller")
+
+		bridges, err := ListPCIs(context.Background(), func(line string) bool {
+			return strings.Contains(line, "Bridge")
+		})
+		require.NoError(t, err)
+		require.Len(t, bridges, 1)
+		assert.Contains(t, bridges[0], "10de:1af1")
+
+		assert.Equal(t, 1, runs)
+	})
+}
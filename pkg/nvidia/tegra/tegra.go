@@ -0,0 +1,246 @@
+// Package tegra detects the NVIDIA Jetson/Tegra devices, and reads the stats of
+// their integrated GPU from the sysfs (the same interfaces "tegrastats" and "jtop" read),
+// as NVML is not available (or only partially) on the integrated GPUs.
+package tegra
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultReleaseFile is the Linux for Tegra (L4T) release file.
+	DefaultReleaseFile = "/etc/nv_tegra_release"
+	// DefaultDeviceTreeDir is the device tree of the board and the SoC.
+	DefaultDeviceTreeDir = "/proc/device-tree"
+
+	// DefaultDevfreqDir is the devfreq class directory, with the integrated GPU
+	// frequency and the load of its device.
+	// ref. https://docs.kernel.org/driver-api/devfreq.html
+	DefaultDevfreqDir = "/sys/class/devfreq"
+	// DefaultThermalDir is the thermal class directory, with the GPU thermal zone.
+	// ref. https://docs.kernel.org/driver-api/thermal/sysfs-api.html
+	DefaultThermalDir = "/sys/class/thermal"
+)
+
+// Info is the Jetson/Tegra board information.
+type Info struct {
+	// Model is the board model (e.g., "NVIDIA Jetson AGX Orin Developer Kit").
+	Model string `json:"model,omitempty"`
+	// SoC is the Tegra SoC (e.g., "tegra234").
+	SoC string `json:"soc,omitempty"`
+	// L4TRelease is the Linux for Tegra release (e.g., "36.3.0"),
+	// empty if the release file does not exist (e.g., not a JetPack image).
+	L4TRelease string `json:"l4t_release,omitempty"`
+}
+
+var isTegra = sync.OnceValue(func() bool {
+	info, err := Detect(DefaultReleaseFile, DefaultDeviceTreeDir)
+	return err == nil && info != nil
+})
+
+// IsTegra returns true if the host is a Jetson/Tegra device, detected once.
+func IsTegra() bool {
+	return isTegra()
+}
+
+// Detect returns the board information, or nil if the host is not a Jetson/Tegra device.
+func Detect(releaseFile string, deviceTreeDir string) (*Info, error) {
+	info := &Info{}
+
+	compatible, err := readFile(filepath.Join(deviceTreeDir, "compatible"))
+	if err != nil {
+		return nil, err
+	}
+	// e.g., "nvidia,p3737-0000+p3701-0005\x00nvidia,p3701-0005\x00nvidia,tegra234\x00"
+	for _, s := range strings.Split(compatible, "\x00") {
+		if soc, ok := strings.CutPrefix(s, "nvidia,"); ok && strings.HasPrefix(soc, "tegra") {
+			info.SoC = soc
+			break
+		}
+	}
+
+	release, err := readFile(releaseFile)
+	if err != nil {
+		return nil, err
+	}
+	info.L4TRelease = parseL4TRelease(release)
+
+	if info.SoC == "" && info.L4TRelease == "" {
+		return nil, nil
+	}
+
+	model, err := readFile(filepath.Join(deviceTreeDir, "model"))
+	if err != nil {
+		return nil, err
+	}
+	info.Model = strings.TrimRight(model, "\x00\n")
+
+	return info, nil
+}
+
+// e.g., "# R36 (release), REVISION: 3.0, GCID: 36191598, BOARD: generic, EABI: aarch64, DATE: ..."
+var l4tReleaseRegex = regexp.MustCompile(`^# R(\d+) \(release\), REVISION: ([\d.]+)`)
+
+func parseL4TRelease(s string) string {
+	m := l4tReleaseRegex.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return m[1] + "." + m[2]
+}
+
+// GPUStats is the stats of the integrated GPU.
+type GPUStats struct {
+	// Device is the devfreq device of the GPU (e.g., "17000000.ga10b").
+	Device string `json:"device"`
+	// LoadPercent is the GPU load in percent.
+	LoadPercent float64 `json:"load_percent"`
+
+	CurFrequencyHz uint64 `json:"cur_frequency_hz"`
+	MinFrequencyHz uint64 `json:"min_frequency_hz"`
+	MaxFrequencyHz uint64 `json:"max_frequency_hz"`
+
+	// ThermalZone is the GPU thermal zone (e.g., "GPU-therm"), empty if not found.
+	ThermalZone string `json:"thermal_zone,omitempty"`
+	// TemperatureCelsius is the GPU temperature, zero if the thermal zone is not found.
+	TemperatureCelsius float64 `json:"temperature_celsius,omitempty"`
+}
+
+// gpuDevfreqSuffixes are the devfreq device name suffixes of the integrated GPUs
+// (e.g., "57000000.gpu" on Nano, "17000000.gv11b" on Xavier, "17000000.ga10b" on Orin).
+var gpuDevfreqSuffixes = []string{".gpu", ".gm20b", ".gp10b", ".gv11b", ".ga10b", ".gb10b"}
+
+// gpuThermalZoneTypes are the thermal zone types of the integrated GPUs.
+var gpuThermalZoneTypes = map[string]struct{}{
+	"GPU-therm":   {},
+	"gpu-therm":   {},
+	"gpu-thermal": {},
+}
+
+// ReadGPUStats reads the stats of the integrated GPU,
+// and returns nil if the GPU is not found (e.g., the GPU driver not loaded).
+func ReadGPUStats(devfreqDir string, thermalDir string) (*GPUStats, error) {
+	entries, err := os.ReadDir(devfreqDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	var dev string
+	for _, name := range names {
+		for _, suffix := range gpuDevfreqSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				dev = name
+				break
+			}
+		}
+		if dev != "" {
+			break
+		}
+	}
+	if dev == "" {
+		return nil, nil
+	}
+
+	dir := filepath.Join(devfreqDir, dev)
+	stats := &GPUStats{Device: dev}
+
+	// load is in per mille (e.g., "450" is 45%)
+	load, err := readUint(filepath.Join(dir, "device", "load"))
+	if err != nil {
+		return nil, err
+	}
+	stats.LoadPercent = float64(load) / 10
+
+	for _, f := range []struct {
+		file string
+		v    *uint64
+	}{
+		{"cur_freq", &stats.CurFrequencyHz},
+		{"min_freq", &stats.MinFrequencyHz},
+		{"max_freq", &stats.MaxFrequencyHz},
+	} {
+		if *f.v, err = readUint(filepath.Join(dir, f.file)); err != nil {
+			return nil, err
+		}
+	}
+
+	stats.ThermalZone, stats.TemperatureCelsius, err = readGPUTemperature(thermalDir)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// readGPUTemperature returns the GPU thermal zone type and its temperature,
+// or empty if not found.
+func readGPUTemperature(thermalDir string) (string, float64, error) {
+	zones, err := filepath.Glob(filepath.Join(thermalDir, "thermal_zone*"))
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		typ, err := readFile(filepath.Join(zone, "type"))
+		if err != nil {
+			return "", 0, err
+		}
+		typ = strings.TrimSpace(typ)
+		if _, ok := gpuThermalZoneTypes[typ]; !ok {
+			continue
+		}
+
+		// in millidegree Celsius
+		b, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			return "", 0, err
+		}
+		milli, err := strconv.ParseInt(string(bytes.TrimSpace(b)), 10, 64)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to parse %s temperature: %w", typ, err)
+		}
+		return typ, float64(milli) / 1000, nil
+	}
+	return "", 0, nil
+}
+
+// readFile returns the file content, or empty if the file does not exist.
+func readFile(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(b), nil
+}
+
+func readUint(file string) (uint64, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(string(bytes.TrimSpace(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,86 @@
+package tegra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, file string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	releaseFile := filepath.Join(dir, "nv_tegra_release")
+	deviceTreeDir := filepath.Join(dir, "device-tree")
+
+	info, err := Detect(releaseFile, deviceTreeDir)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	// e.g., x86 servers
+	writeFile(t, filepath.Join(deviceTreeDir, "compatible"), "qemu,virt\x00")
+	info, err = Detect(releaseFile, deviceTreeDir)
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	writeFile(t, filepath.Join(deviceTreeDir, "compatible"), "nvidia,p3737-0000+p3701-0005\x00nvidia,p3701-0005\x00nvidia,tegra234\x00")
+	writeFile(t, filepath.Join(deviceTreeDir, "model"), "NVIDIA Jetson AGX Orin Developer Kit\x00")
+	writeFile(t, releaseFile, "# R36 (release), REVISION: 3.0, GCID: 36191598, BOARD: generic, EABI: aarch64, DATE: Mon May  6 17:34:21 UTC 2024\n")
+	info, err = Detect(releaseFile, deviceTreeDir)
+	require.NoError(t, err)
+	assert.Equal(t, &Info{Model: "NVIDIA Jetson AGX Orin Developer Kit", SoC: "tegra234", L4TRelease: "36.3.0"}, info)
+}
+
+func TestParseL4TRelease(t *testing.T) {
+	assert.Equal(t, "35.4.1", parseL4TRelease("# R35 (release), REVISION: 4.1, GCID: 33958178, BOARD: t186ref, EABI: aarch64\n"))
+	assert.Empty(t, parseL4TRelease(""))
+	assert.Empty(t, parseL4TRelease("invalid"))
+}
+
+func TestReadGPUStats(t *testing.T) {
+	dir := t.TempDir()
+	devfreqDir := filepath.Join(dir, "devfreq")
+	thermalDir := filepath.Join(dir, "thermal")
+
+	stats, err := ReadGPUStats(devfreqDir, thermalDir)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	// the other devfreq devices (e.g., the NVIDIA deep learning accelerators)
+	writeFile(t, filepath.Join(devfreqDir, "15880000.nvdla0", "cur_freq"), "1600000000\n")
+	stats, err = ReadGPUStats(devfreqDir, thermalDir)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	gpuDir := filepath.Join(devfreqDir, "17000000.ga10b")
+	writeFile(t, filepath.Join(gpuDir, "device", "load"), "453\n")
+	writeFile(t, filepath.Join(gpuDir, "cur_freq"), "1300500000\n")
+	writeFile(t, filepath.Join(gpuDir, "min_freq"), "306000000\n")
+	writeFile(t, filepath.Join(gpuDir, "max_freq"), "1300500000\n")
+	writeFile(t, filepath.Join(thermalDir, "thermal_zone0", "type"), "CPU-therm\n")
+	writeFile(t, filepath.Join(thermalDir, "thermal_zone0", "temp"), "48250\n")
+	writeFile(t, filepath.Join(thermalDir, "thermal_zone1", "type"), "GPU-therm\n")
+	writeFile(t, filepath.Join(thermalDir, "thermal_zone1", "temp"), "51500\n")
+
+	stats, err = ReadGPUStats(devfreqDir, thermalDir)
+	require.NoError(t, err)
+	assert.Equal(t, &GPUStats{
+		Device:             "17000000.ga10b",
+		LoadPercent:        45.3,
+		CurFrequencyHz:     1300500000,
+		MinFrequencyHz:     306000000,
+		MaxFrequencyHz:     1300500000,
+		ThermalZone:        "GPU-therm",
+		TemperatureCelsius: 51.5,
+	}, stats)
+
+	writeFile(t, filepath.Join(gpuDir, "device", "load"), "bad\n")
+	_, err = ReadGPUStats(devfreqDir, thermalDir)
+	assert.Error(t, err)
+}
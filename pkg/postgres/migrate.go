@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+const (
+	// TableNameSchemaMigrations is the table of the applied schema migrations
+	// of the shared database.
+	TableNameSchemaMigrations = "gpud_schema_migrations"
+
+	// migrationLockID is the transaction-level advisory lock held while migrating,
+	// since many GPUd instances sharing the database start concurrently.
+	migrationLockID = 0x67707564 // "gpud"
+)
+
+// Migrate applies the pending migrations in the ascending order of the versions,
+// all in a single transaction holding the advisory lock, so that only one of the
+// GPUd instances sharing the database applies them, and a failed migration
+// leaves the schema unchanged. It returns the migrations applied.
+func Migrate(ctx context.Context, db *sql.DB, ms sqlite.Migrations) (applied sqlite.Migrations, retErr error) {
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				retErr = errors.Join(retErr, err)
+			}
+			applied = nil
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at BIGINT NOT NULL
+);`, TableNameSchemaMigrations)); err != nil {
+		return nil, err
+	}
+
+	for _, m := range ms {
+		var found bool
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE version = $1)", TableNameSchemaMigrations), m.Version).Scan(&found); err != nil {
+			return nil, err
+		}
+		if found {
+			continue
+		}
+
+		if err := m.Up(ctx, tx); err != nil {
+			return nil, fmt.Errorf("failed to apply migration %d %q: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, $3)", TableNameSchemaMigrations),
+			m.Version, m.Name, time.Now().UTC().Unix()); err != nil {
+			return nil, fmt.Errorf("failed to record migration %d %q: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// TableExists returns true if the table exists in the current schema search path.
+func TableExists(ctx context.Context, tx *sql.Tx, table string) (bool, error) {
+	var found bool
+	err := tx.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&found)
+	return found, err
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT a FROM t WHERE b = ?", "SELECT a FROM t WHERE b = $1"},
+		{"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)", "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)"},
+		{"SELECT a FROM t WHERE b = '?' AND c = ?", "SELECT a FROM t WHERE b = '?' AND c = $1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Rebind(tt.query))
+	}
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open(context.Background(), "")
+	require.Error(t, err)
+
+	db, cleanup := OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, db.PingContext(context.Background()))
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// EnvTestDSN is the environment variable of the PostgreSQL database for the tests.
+// The tests that require PostgreSQL are skipped if not set.
+const EnvTestDSN = "GPUD_TEST_POSTGRES_DSN"
+
+// OpenTestDB opens the test PostgreSQL database, or skips the test if not configured.
+func OpenTestDB(t *testing.T) (*sql.DB, func()) {
+	dsn := os.Getenv(EnvTestDSN)
+	if dsn == "" {
+		t.Skipf("%s not set, skipping postgres test", EnvTestDSN)
+	}
+
+	db, err := Open(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	return db, func() {
+		_ = db.Close()
+	}
+}
@@ -0,0 +1,104 @@
+// Package psi reads the pressure stall information (PSI) of the host,
+// the share of the time that the tasks were stalled on the CPU, IO, or memory.
+package psi
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ref. https://docs.kernel.org/accounting/psi.html
+const (
+	DefaultCPUFile    = "/proc/pressure/cpu"
+	DefaultIOFile     = "/proc/pressure/io"
+	DefaultMemoryFile = "/proc/pressure/memory"
+)
+
+// Stats is a line of the pressure stall information,
+// the share of the time in percent that the tasks were stalled.
+type Stats struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	// TotalMicroseconds is the total stall time in microseconds.
+	TotalMicroseconds uint64 `json:"total_us"`
+}
+
+// Pressure is the pressure stall information of a resource.
+type Pressure struct {
+	// Some is the share of the time that at least some tasks were stalled.
+	Some Stats `json:"some"`
+	// Full is the share of the time that all the non-idle tasks were stalled.
+	// Not reported for the CPU on older kernels (e.g., < 5.13).
+	Full Stats `json:"full"`
+}
+
+// Read reads the PSI file, and returns nil
+// if not supported by the kernel (e.g., "CONFIG_PSI" disabled or "psi=0").
+func Read(file string) (*Pressure, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	p := &Pressure{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g., "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stats *Stats
+		switch fields[0] {
+		case "some":
+			stats = &p.Some
+		case "full":
+			stats = &p.Full
+		default:
+			continue
+		}
+		if err := parseStats(fields[1:], stats); err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", scanner.Text(), err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func parseStats(fields []string, stats *Stats) error {
+	for _, field := range fields {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch k {
+		case "avg10":
+			stats.Avg10, err = strconv.ParseFloat(v, 64)
+		case "avg60":
+			stats.Avg60, err = strconv.ParseFloat(v, 64)
+		case "avg300":
+			stats.Avg300, err = strconv.ParseFloat(v, 64)
+		case "total":
+			stats.TotalMicroseconds, err = strconv.ParseUint(v, 10, 64)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
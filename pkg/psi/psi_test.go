@@ -0,0 +1,49 @@
+package psi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "io")
+	require.NoError(t, os.WriteFile(file, []byte(`some avg10=1.50 avg60=2.25 avg300=0.75 total=123456
+full avg10=0.50 avg60=12.00 avg300=0.10 total=6543
+`), 0644))
+
+	p, err := Read(file)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, Stats{Avg10: 1.5, Avg60: 2.25, Avg300: 0.75, TotalMicroseconds: 123456}, p.Some)
+	assert.Equal(t, Stats{Avg10: 0.5, Avg60: 12, Avg300: 0.1, TotalMicroseconds: 6543}, p.Full)
+}
+
+func TestReadSomeOnly(t *testing.T) {
+	// e.g., "/proc/pressure/cpu" on the older kernels
+	file := filepath.Join(t.TempDir(), "cpu")
+	require.NoError(t, os.WriteFile(file, []byte("some avg10=92.10 avg60=40.00 avg300=10.00 total=99\n"), 0644))
+
+	p, err := Read(file)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, 92.1, p.Some.Avg10)
+	assert.Equal(t, Stats{}, p.Full)
+}
+
+func TestReadNotSupported(t *testing.T) {
+	p, err := Read(filepath.Join(t.TempDir(), "does-not-exist"))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestReadInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cpu")
+	require.NoError(t, os.WriteFile(file, []byte("some avg10=bad avg60=0.00 avg300=0.00 total=0\n"), 0644))
+
+	_, err := Read(file)
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// ReasonRateLimited is the rejection reason when the client exceeds its request rate.
+	ReasonRateLimited = "rate_limited"
+	// ReasonConcurrencyLimited is the rejection reason when the concurrent requests are capped.
+	ReasonConcurrencyLimited = "concurrency_limited"
+)
+
+var metricRejectedRequestsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "gpud",
+		Subsystem: "api_server",
+		Name:      "rejected_requests_total",
+		Help:      "total number of API requests rejected by the rate limiter",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	pkgmetrics.MustRegister(metricRejectedRequestsTotal)
+}
+
+// RecordRejected increments the rejected request counter for the reason.
+func RecordRejected(reason string) {
+	metricRejectedRequestsTotal.WithLabelValues(reason).Inc()
+}
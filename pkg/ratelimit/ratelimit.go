@@ -0,0 +1,195 @@
+// Package ratelimit implements the per-client rate limiting and
+// the global concurrent request cap for the GPUd API server.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultClientIdleTimeout is the duration after which the limiter
+	// of a client without any request is discarded.
+	DefaultClientIdleTimeout = 10 * time.Minute
+
+	// cleanupInterval is the minimum interval between two idle client cleanups.
+	cleanupInterval = time.Minute
+)
+
+// Config configures the API server rate limiting.
+type Config struct {
+	// RequestsPerSecond is the sustained number of requests per second
+	// allowed for each client IP. Zero disables the per-client rate limiting.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst is the maximum number of requests a client IP can make at once.
+	// Defaults to the ceiling of the requests per second (at least 1).
+	Burst int `json:"burst"`
+
+	// MaxConcurrentRequests is the maximum number of requests
+	// being served at the same time across all clients.
+	// Zero disables the concurrency cap.
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+
+	// ExemptPaths is the list of the route paths that are not limited
+	// (e.g., "/metrics"), in addition to the health endpoints.
+	// A path ending with "/*" exempts the paths with the prefix.
+	ExemptPaths []string `json:"exempt_paths,omitempty"`
+}
+
+// Validate validates the rate limit config.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests_per_second must be non-negative, got %v", cfg.RequestsPerSecond)
+	}
+	if cfg.Burst < 0 {
+		return fmt.Errorf("burst must be non-negative, got %d", cfg.Burst)
+	}
+	if cfg.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("max_concurrent_requests must be non-negative, got %d", cfg.MaxConcurrentRequests)
+	}
+	if cfg.RequestsPerSecond == 0 && cfg.MaxConcurrentRequests == 0 {
+		return errors.New("at least one of requests_per_second or max_concurrent_requests must be set")
+	}
+	for _, p := range cfg.ExemptPaths {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("exempt path %q must start with '/'", p)
+		}
+	}
+	return nil
+}
+
+// Limiter limits the request rate per client and the concurrent requests.
+// Safe for concurrent use.
+type Limiter struct {
+	rps   rate.Limit
+	burst int
+
+	exemptPaths    map[string]struct{}
+	exemptPrefixes []string
+
+	// sem caps the concurrent requests, nil if disabled
+	sem chan struct{}
+
+	idleTimeout time.Duration
+	nowFunc     func() time.Time
+
+	mu          sync.Mutex
+	clients     map[string]*client
+	lastCleanup time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewLimiter creates a limiter from the config.
+func NewLimiter(cfg *Config) (*Limiter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = int(cfg.RequestsPerSecond)
+		if float64(burst) < cfg.RequestsPerSecond {
+			burst++
+		}
+		if burst < 1 {
+			burst = 1
+		}
+	}
+
+	l := &Limiter{
+		rps:         rate.Limit(cfg.RequestsPerSecond),
+		burst:       burst,
+		exemptPaths: make(map[string]struct{}),
+		idleTimeout: DefaultClientIdleTimeout,
+		nowFunc:     time.Now,
+		clients:     make(map[string]*client),
+	}
+	for _, p := range cfg.ExemptPaths {
+		if prefix, ok := strings.CutSuffix(p, "/*"); ok {
+			l.exemptPrefixes = append(l.exemptPrefixes, prefix+"/")
+			continue
+		}
+		l.exemptPaths[p] = struct{}{}
+	}
+	if cfg.MaxConcurrentRequests > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrentRequests)
+	}
+	return l, nil
+}
+
+// Exempt returns true if the path is exempted from the limits.
+func (l *Limiter) Exempt(path string) bool {
+	if _, ok := l.exemptPaths[path]; ok {
+		return true
+	}
+	for _, prefix := range l.exemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow returns true if the client is within its request rate.
+// Always true if the per-client rate limiting is disabled.
+func (l *Limiter) Allow(clientIP string) bool {
+	if l.rps == 0 {
+		return true
+	}
+
+	now := l.nowFunc()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) >= cleanupInterval {
+		for ip, c := range l.clients {
+			if now.Sub(c.lastSeen) > l.idleTimeout {
+				delete(l.clients, ip)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	c, ok := l.clients[clientIP]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[clientIP] = c
+	}
+	c.lastSeen = now
+	return c.limiter.AllowN(now, 1)
+}
+
+// Acquire reserves a concurrent request slot without blocking.
+// It returns false if all slots are in use. The returned function
+// must be called to release the slot once the request is served.
+func (l *Limiter) Acquire() (func(), bool) {
+	if l.sem == nil {
+		return func() {}, true
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, true
+	default:
+		return nil, false
+	}
+}
+
+// clientCount returns the number of the tracked clients.
+func (l *Limiter) clientCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.clients)
+}
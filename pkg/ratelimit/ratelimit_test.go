@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	require.NoError(t, nilCfg.Validate())
+	require.NoError(t, (&Config{RequestsPerSecond: 10}).Validate())
+	require.NoError(t, (&Config{MaxConcurrentRequests: 8, ExemptPaths: []string{"/metrics", "/admin/*"}}).Validate())
+
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{"nothing limited", &Config{}},
+		{"negative rps", &Config{RequestsPerSecond: -1}},
+		{"negative burst", &Config{RequestsPerSecond: 1, Burst: -1}},
+		{"negative concurrency", &Config{MaxConcurrentRequests: -1}},
+		{"relative exempt path", &Config{RequestsPerSecond: 1, ExemptPaths: []string{"metrics"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.cfg.Validate())
+		})
+	}
+
+	_, err := NewLimiter(&Config{})
+	require.Error(t, err)
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l, err := NewLimiter(&Config{RequestsPerSecond: 1, Burst: 2})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	l.nowFunc = func() time.Time { return now }
+
+	assert.True(t, l.Allow("10.0.0.1"))
+	assert.True(t, l.Allow("10.0.0.1"))
+	assert.False(t, l.Allow("10.0.0.1"))
+
+	// per client
+	assert.True(t, l.Allow("10.0.0.2"))
+
+	// refilled after a second
+	now = now.Add(time.Second)
+	assert.True(t, l.Allow("10.0.0.1"))
+	assert.False(t, l.Allow("10.0.0.1"))
+	assert.Equal(t, 2, l.clientCount())
+
+	// idle clients are discarded
+	now = now.Add(DefaultClientIdleTimeout + time.Minute)
+	assert.True(t, l.Allow("10.0.0.3"))
+	assert.Equal(t, 1, l.clientCount())
+}
+
+func TestLimiterDefaultBurst(t *testing.T) {
+	l, err := NewLimiter(&Config{RequestsPerSecond: 2.5})
+	require.NoError(t, err)
+	assert.Equal(t, 3, l.burst)
+
+	l, err = NewLimiter(&Config{RequestsPerSecond: 0.1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.burst)
+
+	// rate limiting disabled
+	l, err = NewLimiter(&Config{MaxConcurrentRequests: 1})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow("10.0.0.1"))
+	}
+}
+
+func TestLimiterAcquire(t *testing.T) {
+	l, err := NewLimiter(&Config{MaxConcurrentRequests: 2})
+	require.NoError(t, err)
+
+	r1, ok := l.Acquire()
+	require.True(t, ok)
+	r2, ok := l.Acquire()
+	require.True(t, ok)
+	_, ok = l.Acquire()
+	assert.False(t, ok)
+
+	r1()
+	r3, ok := l.Acquire()
+	require.True(t, ok)
+	r2()
+	r3()
+
+	// concurrency cap disabled
+	l, err = NewLimiter(&Config{RequestsPerSecond: 1})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, ok := l.Acquire()
+		assert.True(t, ok)
+	}
+}
+
+func TestLimiterExempt(t *testing.T) {
+	l, err := NewLimiter(&Config{RequestsPerSecond: 1, ExemptPaths: []string{"/metrics", "/admin/*"}})
+	require.NoError(t, err)
+
+	assert.True(t, l.Exempt("/metrics"))
+	assert.True(t, l.Exempt("/admin/pprof/profile"))
+	assert.False(t, l.Exempt("/admin"))
+	assert.False(t, l.Exempt("/metrics/extra"))
+	assert.False(t, l.Exempt("/v1/info"))
+}
+
+func TestRecordRejected(t *testing.T) {
+	before := testutil.ToFloat64(metricRejectedRequestsTotal.WithLabelValues(ReasonRateLimited))
+	RecordRejected(ReasonRateLimited)
+	assert.Equal(t, before+1, testutil.ToFloat64(metricRejectedRequestsTotal.WithLabelValues(ReasonRateLimited)))
+}
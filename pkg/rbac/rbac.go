@@ -0,0 +1,309 @@
+// Package rbac implements the role-based access control for the GPUd API.
+package rbac
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// Role is the role assigned to an API client.
+// Each role grants the access of all the roles below it.
+type Role string
+
+const (
+	// RoleViewer grants the read-only access (e.g., states, events, metrics).
+	RoleViewer Role = "viewer"
+	// RoleOperator grants the viewer access and triggers the component checks
+	// (e.g., run the custom plugins on demand).
+	RoleOperator Role = "operator"
+	// RoleAdmin grants the operator access and mutates the component registry
+	// (e.g., deregister plugins, set healthy, inject faults).
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Valid returns true if the role is one of the known roles.
+func (r Role) Valid() bool {
+	return r.level() > 0
+}
+
+// ParseRole parses the role name, or its scope alias
+// ("read-only" for viewer, "trigger" for operator).
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case "read-only", "readonly":
+		return RoleViewer, nil
+	case "trigger":
+		return RoleOperator, nil
+	default:
+		if !r.Valid() {
+			return "", fmt.Errorf("invalid role %q (must be one of viewer/read-only, operator/trigger, admin)", s)
+		}
+		return r, nil
+	}
+}
+
+// Allows returns true if the role grants the access required by the "required" role.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && r.level() >= required.level()
+}
+
+// Token is a static bearer token with its role claim.
+type Token struct {
+	// Name identifies the token owner in the access logs.
+	Name string `json:"name"`
+	// SHA256 is the hex-encoded SHA-256 digest of the bearer token,
+	// so that the plaintext token is never stored in the config.
+	SHA256 string `json:"sha256"`
+	// Role is the role claimed by the token.
+	Role Role `json:"role"`
+	// ExpiresAt is when the token is no longer accepted.
+	// Leave empty to never expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired returns true if the token has expired at the given time.
+func (t Token) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Config configures the role-based access control for the GPUd API.
+type Config struct {
+	// Tokens is the list of bearer tokens accepted via the "Authorization: Bearer <token>" header.
+	Tokens []Token `json:"tokens,omitempty"`
+
+	// TokensFile is the file of the additional bearer tokens (e.g., DefaultTokensFile),
+	// managed by "gpud tokens" and reloaded on change without restarting GPUd.
+	// The file must be owned by root and not accessible by group or others.
+	// Leave empty to disable, or skipped if not exist.
+	TokensFile string `json:"tokens_file,omitempty"`
+
+	// ClientCAFile is the PEM-encoded CA bundle to verify the client certificates.
+	// The role of a verified client certificate is read from its
+	// organizational unit (OU), e.g., "OU=operator".
+	// Leave empty to disable the client certificate authentication.
+	ClientCAFile string `json:"client_ca_file,omitempty"`
+
+	// AnonymousRole is the role assigned to the unauthenticated requests.
+	// Leave empty to reject the unauthenticated requests.
+	AnonymousRole Role `json:"anonymous_role,omitempty"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+
+	names := make(map[string]struct{}, len(cfg.Tokens))
+	for _, t := range cfg.Tokens {
+		if t.Name == "" {
+			return errors.New("token name is required")
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicate token name %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
+
+		if b, err := hex.DecodeString(t.SHA256); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("token %q has invalid sha256 digest", t.Name)
+		}
+		if !t.Role.Valid() {
+			return fmt.Errorf("token %q has invalid role %q", t.Name, t.Role)
+		}
+	}
+
+	if cfg.AnonymousRole != "" && !cfg.AnonymousRole.Valid() {
+		return fmt.Errorf("invalid anonymous role %q", cfg.AnonymousRole)
+	}
+	return nil
+}
+
+// ClientCAs loads the client CA pool, or returns nil if not configured.
+func (cfg *Config) ClientCAs() (*x509.CertPool, error) {
+	if cfg == nil || cfg.ClientCAFile == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificate found in client ca file %q", cfg.ClientCAFile)
+	}
+	return pool, nil
+}
+
+// Identity is the authenticated API client.
+type Identity struct {
+	// Name is the token name or the client certificate common name.
+	Name string
+	// Role is the role assigned to the client.
+	Role Role
+	// Method is how the client was authenticated ("token", "cert", or "anonymous").
+	Method string
+}
+
+// Authorizer resolves the identity of the API requests.
+type Authorizer struct {
+	tokens        map[string]Token
+	anonymousRole Role
+
+	getTimeNowFunc func() time.Time
+
+	// tokensFile is reloaded when its modification time or size changes,
+	// and its tokens are dropped if it fails to load (e.g., unsafe permissions)
+	tokensFile  string
+	fileMu      sync.Mutex
+	fileModTime time.Time
+	fileSize    int64
+	fileTokens  map[string]Token
+}
+
+// NewAuthorizer creates an authorizer from the config.
+func NewAuthorizer(cfg *Config) (*Authorizer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	a := &Authorizer{
+		tokens: make(map[string]Token),
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+	if cfg == nil {
+		return a, nil
+	}
+	for _, t := range cfg.Tokens {
+		a.tokens[strings.ToLower(t.SHA256)] = t
+	}
+	a.anonymousRole = cfg.AnonymousRole
+
+	if cfg.TokensFile != "" {
+		a.tokensFile = cfg.TokensFile
+		// fail early on the invalid file, the later errors drop the file tokens
+		if _, err := LoadTokensFile(cfg.TokensFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// lookupToken returns the token of the digest, from the config or the tokens file.
+func (a *Authorizer) lookupToken(digest string) (Token, bool) {
+	if t, ok := a.tokens[digest]; ok {
+		return t, true
+	}
+	if a.tokensFile == "" {
+		return Token{}, false
+	}
+
+	a.fileMu.Lock()
+	defer a.fileMu.Unlock()
+
+	a.reloadTokensFileLocked()
+	t, ok := a.fileTokens[digest]
+	return t, ok
+}
+
+func (a *Authorizer) reloadTokensFileLocked() {
+	fi, err := os.Stat(a.tokensFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Logger.Warnw("failed to stat tokens file", "file", a.tokensFile, "error", err)
+		}
+		a.fileTokens, a.fileModTime, a.fileSize = nil, time.Time{}, 0
+		return
+	}
+	if a.fileTokens != nil && fi.ModTime().Equal(a.fileModTime) && fi.Size() == a.fileSize {
+		return
+	}
+
+	tokens, err := LoadTokensFile(a.tokensFile)
+	if err != nil {
+		log.Logger.Warnw("failed to load tokens file, rejecting its tokens", "file", a.tokensFile, "error", err)
+		a.fileTokens, a.fileModTime, a.fileSize = nil, time.Time{}, 0
+		return
+	}
+	a.fileTokens = make(map[string]Token, len(tokens))
+	for _, t := range tokens {
+		a.fileTokens[strings.ToLower(t.SHA256)] = t
+	}
+	a.fileModTime, a.fileSize = fi.ModTime(), fi.Size()
+	log.Logger.Infow("loaded tokens file", "file", a.tokensFile, "tokens", len(tokens))
+}
+
+// Authenticate returns the identity of the request.
+// The bearer token takes precedence over the client certificate.
+// It returns false if the request presents an unknown token,
+// or if it is unauthenticated and no anonymous role is configured.
+func (a *Authorizer) Authenticate(req *http.Request) (Identity, bool) {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok {
+			return Identity{}, false
+		}
+		sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+		t, ok := a.lookupToken(hex.EncodeToString(sum[:]))
+		if !ok {
+			return Identity{}, false
+		}
+		if t.Expired(a.getTimeNowFunc()) {
+			log.Logger.Warnw("rejected expired token", "name", t.Name, "expiresAt", t.ExpiresAt)
+			return Identity{}, false
+		}
+		return Identity{Name: t.Name, Role: t.Role, Method: "token"}, true
+	}
+
+	if id, ok := identityFromClientCert(req); ok {
+		return id, true
+	}
+
+	if a.anonymousRole.Valid() {
+		return Identity{Role: a.anonymousRole, Method: "anonymous"}, true
+	}
+	return Identity{}, false
+}
+
+// identityFromClientCert returns the highest role found in the
+// organizational units of the verified client certificate.
+func identityFromClientCert(req *http.Request) (Identity, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+
+	leaf := req.TLS.VerifiedChains[0][0]
+	id := Identity{Name: leaf.Subject.CommonName, Method: "cert"}
+	for _, ou := range leaf.Subject.OrganizationalUnit {
+		r := Role(strings.ToLower(ou))
+		if r.level() > id.Role.level() {
+			id.Role = r
+		}
+	}
+	return id, id.Role.Valid()
+}
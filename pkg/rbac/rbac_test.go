@@ -0,0 +1,196 @@
+package rbac
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleViewer, RoleAdmin, false},
+		{RoleOperator, RoleViewer, true},
+		{RoleOperator, RoleOperator, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleAdmin, RoleViewer, true},
+		{RoleAdmin, RoleAdmin, true},
+		{Role(""), RoleViewer, false},
+		{Role("root"), RoleViewer, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"_"+string(tt.required), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.role.Allows(tt.required))
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	require.NoError(t, nilCfg.Validate())
+	require.NoError(t, (&Config{}).Validate())
+	require.NoError(t, (&Config{
+		Tokens:        []Token{{Name: "ops", SHA256: digest("secret"), Role: RoleOperator}},
+		AnonymousRole: RoleViewer,
+	}).Validate())
+
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{"missing name", &Config{Tokens: []Token{{SHA256: digest("a"), Role: RoleAdmin}}}},
+		{"duplicate name", &Config{Tokens: []Token{
+			{Name: "a", SHA256: digest("a"), Role: RoleAdmin},
+			{Name: "a", SHA256: digest("b"), Role: RoleAdmin},
+		}}},
+		{"invalid digest", &Config{Tokens: []Token{{Name: "a", SHA256: "secret", Role: RoleAdmin}}}},
+		{"invalid role", &Config{Tokens: []Token{{Name: "a", SHA256: digest("a"), Role: "root"}}}},
+		{"invalid anonymous role", &Config{AnonymousRole: "guest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.cfg.Validate())
+		})
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	a, err := NewAuthorizer(&Config{
+		Tokens: []Token{
+			{Name: "ops", SHA256: digest("ops-secret"), Role: RoleOperator},
+			{Name: "root", SHA256: digest("admin-secret"), Role: RoleAdmin},
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/states", nil)
+	req.Header.Set("Authorization", "Bearer ops-secret")
+	id, ok := a.Authenticate(req)
+	require.True(t, ok)
+	assert.Equal(t, Identity{Name: "ops", Role: RoleOperator, Method: "token"}, id)
+
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	id, ok = a.Authenticate(req)
+	require.True(t, ok)
+	assert.Equal(t, RoleAdmin, id.Role)
+
+	req.Header.Set("Authorization", "Bearer unknown")
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+
+	req.Header.Set("Authorization", "Basic b3BzOnNlY3JldA==")
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+
+	// unauthenticated without anonymous role
+	req.Header.Del("Authorization")
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+}
+
+func TestAuthenticateAnonymous(t *testing.T) {
+	a, err := NewAuthorizer(&Config{AnonymousRole: RoleViewer})
+	require.NoError(t, err)
+
+	id, ok := a.Authenticate(httptest.NewRequest(http.MethodGet, "/v1/states", nil))
+	require.True(t, ok)
+	assert.Equal(t, Identity{Role: RoleViewer, Method: "anonymous"}, id)
+
+	// an unknown token is not downgraded to the anonymous role
+	req := httptest.NewRequest(http.MethodGet, "/v1/states", nil)
+	req.Header.Set("Authorization", "Bearer unknown")
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+}
+
+func TestAuthenticateClientCert(t *testing.T) {
+	a, err := NewAuthorizer(&Config{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/states", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{
+			{Subject: pkix.Name{CommonName: "node-1", OrganizationalUnit: []string{"gpu-team", "viewer", "Operator"}}},
+		}},
+	}
+	id, ok := a.Authenticate(req)
+	require.True(t, ok)
+	assert.Equal(t, Identity{Name: "node-1", Role: RoleOperator, Method: "cert"}, id)
+
+	// no role in the organizational units
+	req.TLS.VerifiedChains[0][0].Subject.OrganizationalUnit = []string{"gpu-team"}
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+
+	// unverified peer certificates are ignored
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "node-1", OrganizationalUnit: []string{"admin"}}},
+		},
+	}
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+}
+
+func TestClientCAs(t *testing.T) {
+	var nilCfg *Config
+	pool, err := nilCfg.ClientCAs()
+	require.NoError(t, err)
+	assert.Nil(t, pool)
+
+	dir := t.TempDir()
+
+	_, err = (&Config{ClientCAFile: filepath.Join(dir, "missing.pem")}).ClientCAs()
+	require.Error(t, err)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a pem"), 0644))
+	_, err = (&Config{ClientCAFile: invalid}).ClientCAs()
+	require.Error(t, err)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gpud-test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	valid := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(valid, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	pool, err = (&Config{ClientCAFile: valid}).ClientCAs()
+	require.NoError(t, err)
+	assert.NotNil(t, pool)
+}
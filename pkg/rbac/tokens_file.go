@@ -0,0 +1,111 @@
+package rbac
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pkgfile "github.com/leptonai/gpud/pkg/file"
+)
+
+// DefaultTokensFile is the default file of the API tokens managed by "gpud tokens".
+const DefaultTokensFile = "/etc/gpud/tokens.json"
+
+// tokenPrefix identifies the generated GPUd API tokens (e.g., in the secret scanners).
+const tokenPrefix = "gpud_"
+
+// ErrTokensFileNotSafe is returned when the tokens file is readable or writable
+// by the non-root users, as the file grants the API access.
+var ErrTokensFileNotSafe = errors.New("tokens file must be owned by root and not accessible by group or others")
+
+// TokensFile is the file of the API tokens, where only the token digests are stored.
+type TokensFile struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadTokensFile reads and validates the tokens file.
+// Returns an error wrapping os.ErrNotExist if the file does not exist.
+func LoadTokensFile(path string) ([]Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := pkgfile.CheckRootOnly(fi); err != nil {
+		return nil, fmt.Errorf("%w (%v)", ErrTokensFileNotSafe, err)
+	}
+
+	var tf TokensFile
+	if err := json.NewDecoder(f).Decode(&tf); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file %q: %w", path, err)
+	}
+	if err := (&Config{Tokens: tf.Tokens}).Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tokens file %q: %w", path, err)
+	}
+	return tf.Tokens, nil
+}
+
+// WriteTokensFile validates the tokens and atomically replaces the tokens file,
+// readable and writable only by the owner.
+func WriteTokensFile(path string, tokens []Token) error {
+	if tokens == nil {
+		tokens = []Token{}
+	}
+	if err := (&Config{Tokens: tokens}).Validate(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(TokensFile{Tokens: tokens}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GenerateToken returns a new random bearer token and its hex-encoded SHA-256 digest.
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(sum[:]), nil
+}
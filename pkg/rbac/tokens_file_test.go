@@ -0,0 +1,129 @@
+package rbac
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	for s, want := range map[string]Role{
+		"viewer":    RoleViewer,
+		"read-only": RoleViewer,
+		"operator":  RoleOperator,
+		"Trigger":   RoleOperator,
+		"admin":     RoleAdmin,
+	} {
+		r, err := ParseRole(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, r, s)
+	}
+	_, err := ParseRole("root")
+	assert.Error(t, err)
+}
+
+func TestTokenExpired(t *testing.T) {
+	now := time.Now()
+	assert.False(t, Token{}.Expired(now))
+	later := now.Add(time.Minute)
+	assert.False(t, Token{ExpiresAt: &later}.Expired(now))
+	assert.True(t, Token{ExpiresAt: &now}.Expired(now))
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, sum, err := GenerateToken()
+	require.NoError(t, err)
+	assert.Contains(t, token, tokenPrefix)
+	assert.Equal(t, digest(token), sum)
+
+	token2, _, err := GenerateToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, token2)
+}
+
+func TestTokensFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	_, err := LoadTokensFile(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.Error(t, WriteTokensFile(path, []Token{{Name: "bad", SHA256: "x", Role: RoleViewer}}))
+
+	require.NoError(t, WriteTokensFile(path, []Token{{Name: "ci", SHA256: digest("secret"), Role: RoleOperator}}))
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	if os.Geteuid() != 0 {
+		t.Skip("tokens file must be owned by root")
+	}
+	tokens, err := LoadTokensFile(path)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "ci", tokens[0].Name)
+
+	require.NoError(t, os.Chmod(path, 0o644))
+	_, err = LoadTokensFile(path)
+	assert.ErrorIs(t, err, ErrTokensFileNotSafe)
+}
+
+func TestAuthenticateTokensFile(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("tokens file must be owned by root")
+	}
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	now := time.Now().UTC()
+
+	// the file does not exist yet
+	a, err := NewAuthorizer(&Config{TokensFile: path})
+	require.NoError(t, err)
+	a.getTimeNowFunc = func() time.Time { return now }
+
+	req := httptest.NewRequest("GET", "/v1/states", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	_, ok := a.Authenticate(req)
+	assert.False(t, ok)
+
+	// created while running
+	expiresAt := now.Add(time.Hour)
+	require.NoError(t, WriteTokensFile(path, []Token{{Name: "ci", SHA256: digest("secret"), Role: RoleOperator, ExpiresAt: &expiresAt}}))
+	id, ok := a.Authenticate(req)
+	require.True(t, ok)
+	assert.Equal(t, Identity{Name: "ci", Role: RoleOperator, Method: "token"}, id)
+
+	// expired
+	a.getTimeNowFunc = func() time.Time { return expiresAt }
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+	a.getTimeNowFunc = func() time.Time { return now }
+
+	// unsafe permissions reject the file tokens
+	require.NoError(t, os.Chmod(path, 0o644))
+	require.NoError(t, os.Chtimes(path, now.Add(time.Second), now.Add(time.Second)))
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+	_, err = NewAuthorizer(&Config{TokensFile: path})
+	assert.ErrorIs(t, err, ErrTokensFileNotSafe)
+	require.NoError(t, os.Chmod(path, 0o600))
+
+	// revoked
+	require.NoError(t, WriteTokensFile(path, []Token{{Name: "other", SHA256: digest("other"), Role: RoleViewer}}))
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+
+	req.Header.Set("Authorization", "Bearer other")
+	id, ok = a.Authenticate(req)
+	require.True(t, ok)
+	assert.Equal(t, "other", id.Name)
+
+	// removed
+	require.NoError(t, os.Remove(path))
+	_, ok = a.Authenticate(req)
+	assert.False(t, ok)
+}
@@ -0,0 +1,182 @@
+// Package reasoncode implements the registry of the machine-readable reason codes
+// reported in the health states and the events.
+package reasoncode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvmlerrors "github.com/leptonai/gpud/pkg/nvidia/errors"
+)
+
+// registry keeps the reason code metadata by the code.
+type registry struct {
+	mu       sync.RWMutex
+	metadata map[apiv1.ReasonCode]apiv1.ReasonCodeMetadata
+}
+
+var defaultRegistry = newRegistry()
+
+func newRegistry() *registry {
+	r := &registry{
+		metadata: make(map[apiv1.ReasonCode]apiv1.ReasonCodeMetadata),
+	}
+	if err := r.register("", sharedCodes...); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// sharedCodes are the codes reported by any component.
+var sharedCodes = []apiv1.ReasonCodeMetadata{
+	{Code: apiv1.ReasonCodeHealthy, Health: apiv1.HealthStateTypeHealthy, Description: "The component is healthy, with no specific reason code."},
+	{Code: apiv1.ReasonCodeUnhealthy, Health: apiv1.HealthStateTypeUnhealthy, Description: "The component is unhealthy, with no specific reason code."},
+	{Code: apiv1.ReasonCodeDegraded, Health: apiv1.HealthStateTypeDegraded, Description: "The component is degraded, with no specific reason code."},
+	{Code: apiv1.ReasonCodeInitializing, Health: apiv1.HealthStateTypeInitializing, Description: "The component is initializing, with no specific reason code."},
+	{Code: apiv1.ReasonCodeNVMLNotAvailable, Health: apiv1.HealthStateTypeHealthy, Description: "The NVIDIA NVML library is not loaded or the GPU is not detected, thus the check is skipped."},
+	{Code: apiv1.ReasonCodeNVMLInitError, Health: apiv1.HealthStateTypeUnhealthy, Description: "The NVIDIA NVML library failed to initialize."},
+	{Code: apiv1.ReasonCodeGPULost, Health: apiv1.HealthStateTypeUnhealthy, Description: "The GPU has fallen off the bus (e.g., Xid 79)."},
+	{Code: apiv1.ReasonCodeGPURequiresReset, Health: apiv1.HealthStateTypeUnhealthy, Description: "The GPU requires a reset."},
+}
+
+func (r *registry) register(component string, mds ...apiv1.ReasonCodeMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, md := range mds {
+		if md.Code == "" {
+			return fmt.Errorf("reason code is empty (component %q)", component)
+		}
+		if strings.ToUpper(string(md.Code)) != string(md.Code) || strings.ContainsAny(string(md.Code), " -") {
+			return fmt.Errorf("reason code %q is not upper snake case", md.Code)
+		}
+		if _, ok := r.metadata[md.Code]; ok {
+			return fmt.Errorf("reason code %q already registered", md.Code)
+		}
+		md.Component = component
+		r.metadata[md.Code] = md
+	}
+	return nil
+}
+
+func (r *registry) get(code apiv1.ReasonCode) (apiv1.ReasonCodeMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	md, ok := r.metadata[code]
+	return md, ok
+}
+
+// list returns the codes of the selected components (all if empty) and the shared codes,
+// sorted by the component and the code.
+func (r *registry) list(components ...string) apiv1.ReasonCodesMetadata {
+	selected := make(map[string]struct{}, len(components))
+	for _, c := range components {
+		selected[c] = struct{}{}
+	}
+
+	r.mu.RLock()
+	mds := make(apiv1.ReasonCodesMetadata, 0, len(r.metadata))
+	for _, md := range r.metadata {
+		if len(selected) > 0 && md.Component != "" {
+			if _, ok := selected[md.Component]; !ok {
+				continue
+			}
+		}
+		mds = append(mds, md)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(mds, func(i, j int) bool {
+		if mds[i].Component != mds[j].Component {
+			return mds[i].Component < mds[j].Component
+		}
+		return mds[i].Code < mds[j].Code
+	})
+	return mds
+}
+
+// MustRegister registers the reason codes reported by the component.
+// It panics if the code is empty, not upper snake case, or already registered.
+func MustRegister(component string, mds ...apiv1.ReasonCodeMetadata) {
+	if err := defaultRegistry.register(component, mds...); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the metadata of the reason code, false if not registered.
+func Get(code apiv1.ReasonCode) (apiv1.ReasonCodeMetadata, bool) {
+	return defaultRegistry.get(code)
+}
+
+// List returns the registered reason codes, optionally filtered by the components.
+// The shared codes (e.g., "GPU_LOST") are always included.
+func List(components ...string) apiv1.ReasonCodesMetadata {
+	return defaultRegistry.list(components...)
+}
+
+// the well-known reasons reported by the NVIDIA components
+var nvmlReasonCodes = map[string]apiv1.ReasonCode{
+	"NVIDIA NVML instance is nil":                                          apiv1.ReasonCodeNVMLNotAvailable,
+	"NVIDIA NVML library is not loaded":                                    apiv1.ReasonCodeNVMLNotAvailable,
+	"NVIDIA NVML is loaded but GPU is not detected (missing product name)": apiv1.ReasonCodeNVMLNotAvailable,
+	nvmlerrors.ErrGPULost.Error():                                          apiv1.ReasonCodeGPULost,
+	nvmlerrors.ErrGPURequiresReset.Error():                                 apiv1.ReasonCodeGPURequiresReset,
+}
+
+// ForHealthState returns the reason code of the health state.
+// It returns the code set by the component if any, or the code of the well-known
+// NVML reasons, or the fallback code of the health state type.
+func ForHealthState(st apiv1.HealthState) apiv1.ReasonCode {
+	if st.ReasonCode != "" {
+		return st.ReasonCode
+	}
+	if code, ok := nvmlReasonCodes[st.Reason]; ok {
+		return code
+	}
+	if strings.HasPrefix(st.Reason, "NVML initialization error") {
+		return apiv1.ReasonCodeNVMLInitError
+	}
+	return apiv1.ReasonCodeFromHealth(st.Health)
+}
+
+// ForEvent returns the reason code of the event.
+// It returns the code set by the component if any, or the code derived from the event name.
+func ForEvent(ev apiv1.Event) apiv1.ReasonCode {
+	if ev.ReasonCode != "" {
+		return ev.ReasonCode
+	}
+	return apiv1.ReasonCodeFromEventName(ev.Name)
+}
+
+// SetHealthStates returns a copy of the health states with the reason codes set,
+// or the same health states if all the codes are already set.
+func SetHealthStates(states apiv1.HealthStates) apiv1.HealthStates {
+	missing := false
+	for _, st := range states {
+		if st.ReasonCode == "" {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return states
+	}
+
+	copied := make(apiv1.HealthStates, 0, len(states))
+	for _, st := range states {
+		st.ReasonCode = ForHealthState(st)
+		copied = append(copied, st)
+	}
+	return copied
+}
+
+// SetEvents sets the reason codes of the events in place.
+func SetEvents(evs apiv1.Events) {
+	for i := range evs {
+		evs[i].ReasonCode = ForEvent(evs[i])
+	}
+}
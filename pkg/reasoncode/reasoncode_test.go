@@ -0,0 +1,76 @@
+package reasoncode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry()
+	require.NoError(t, r.register("b", apiv1.ReasonCodeMetadata{Code: "B_FAILED", Health: apiv1.HealthStateTypeUnhealthy}))
+	require.NoError(t, r.register("a", apiv1.ReasonCodeMetadata{Code: "A_FAILED", Health: apiv1.HealthStateTypeUnhealthy}))
+
+	assert.Error(t, r.register("a", apiv1.ReasonCodeMetadata{Code: "A_FAILED"}))
+	assert.Error(t, r.register("a", apiv1.ReasonCodeMetadata{}))
+	assert.Error(t, r.register("a", apiv1.ReasonCodeMetadata{Code: "a_failed"}))
+	assert.Error(t, r.register("a", apiv1.ReasonCodeMetadata{Code: "A-FAILED"}))
+	// the shared codes are registered
+	assert.Error(t, r.register("a", apiv1.ReasonCodeMetadata{Code: apiv1.ReasonCodeGPULost}))
+
+	md, ok := r.get("A_FAILED")
+	require.True(t, ok)
+	assert.Equal(t, "a", md.Component)
+
+	all := r.list()
+	require.Len(t, all, len(sharedCodes)+2)
+	// the shared codes first, then sorted by the component
+	assert.Equal(t, "", all[0].Component)
+	assert.Equal(t, apiv1.ReasonCode("A_FAILED"), all[len(all)-2].Code)
+	assert.Equal(t, apiv1.ReasonCode("B_FAILED"), all[len(all)-1].Code)
+
+	selected := r.list("b")
+	require.Len(t, selected, len(sharedCodes)+1)
+	assert.Equal(t, apiv1.ReasonCode("B_FAILED"), selected[len(selected)-1].Code)
+}
+
+func TestForHealthState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state apiv1.HealthState
+		want  apiv1.ReasonCode
+	}{
+		{"explicit", apiv1.HealthState{Health: apiv1.HealthStateTypeUnhealthy, ReasonCode: "IB_PORT_DROP"}, "IB_PORT_DROP"},
+		{"nvml not loaded", apiv1.HealthState{Health: apiv1.HealthStateTypeHealthy, Reason: "NVIDIA NVML library is not loaded"}, apiv1.ReasonCodeNVMLNotAvailable},
+		{"nvml init error", apiv1.HealthState{Health: apiv1.HealthStateTypeUnhealthy, Reason: "NVML initialization error: device handle"}, apiv1.ReasonCodeNVMLInitError},
+		{"gpu lost", apiv1.HealthState{Health: apiv1.HealthStateTypeUnhealthy, Reason: "GPU lost"}, apiv1.ReasonCodeGPULost},
+		{"gpu requires reset", apiv1.HealthState{Health: apiv1.HealthStateTypeUnhealthy, Reason: "GPU requires reset"}, apiv1.ReasonCodeGPURequiresReset},
+		{"fallback degraded", apiv1.HealthState{Health: apiv1.HealthStateTypeDegraded, Reason: "slow"}, apiv1.ReasonCodeDegraded},
+		{"fallback empty", apiv1.HealthState{}, apiv1.ReasonCodeHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ForHealthState(tt.state))
+		})
+	}
+}
+
+func TestSetHealthStates(t *testing.T) {
+	states := apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy}}
+	set := SetHealthStates(states)
+	assert.Equal(t, apiv1.ReasonCodeUnhealthy, set[0].ReasonCode)
+	// not modified in place
+	assert.Empty(t, states[0].ReasonCode)
+
+	assert.Nil(t, SetHealthStates(nil))
+}
+
+func TestForEvent(t *testing.T) {
+	assert.Equal(t, apiv1.ReasonCode("EVENT_PERSISTENCE_MODE_CHANGED"), ForEvent(apiv1.Event{Name: "persistence_mode_changed"}))
+	assert.Equal(t, apiv1.ReasonCode("EVENT_ERROR_XID"), ForEvent(apiv1.Event{Name: "error-xid"}))
+	assert.Equal(t, apiv1.ReasonCode("GPU_XID_79"), ForEvent(apiv1.Event{Name: "error_xid", ReasonCode: "GPU_XID_79"}))
+	assert.Empty(t, ForEvent(apiv1.Event{}))
+}
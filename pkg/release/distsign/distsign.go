@@ -151,6 +151,15 @@ func (s *SigningKey) SignPackageHash(hash []byte, len int64) ([]byte, error) {
 	return ed25519.Sign(s.k, msg), nil
 }
 
+// SignPayload signs the in-memory payload (e.g., the config pushed over the
+// control plane session). Use VerifyPayload to validate the signature.
+func (s *SigningKey) SignPayload(payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("payload must not be empty")
+	}
+	return ed25519.Sign(s.k, payload), nil
+}
+
 // PackageHash is a hash.Hash that counts the number of bytes written. Use it
 // to get the hash and length inputs to SigningKey.SignPackageHash.
 type PackageHash struct {
@@ -317,6 +326,26 @@ func (c *Client) signingKeys() ([]ed25519.PublicKey, error) {
 	return keys, nil
 }
 
+// VerifyPayload validates the bundle of public signing keys against the root
+// keys, and then the payload signature against the signing keys. If rootKeys is
+// empty, the root keys embedded in the keys/ subdirectory of this package are used.
+func VerifyPayload(rootKeys []ed25519.PublicKey, signingKeys, signingKeysSig, payload, sig []byte) error {
+	if len(rootKeys) == 0 {
+		rootKeys = roots()
+	}
+	if !VerifyAny(rootKeys, signingKeys, signingKeysSig) {
+		return errors.New("signing keys do not validate with any known root key")
+	}
+	keys, err := ParseSigningKeyBundle(signingKeys)
+	if err != nil {
+		return fmt.Errorf("cannot parse signing key bundle: %w", err)
+	}
+	if !VerifyAny(keys, payload, sig) {
+		return errors.New("payload signature does not validate with any signing key")
+	}
+	return nil
+}
+
 // fetch reads the response body from url into memory, up to limit bytes.
 func Fetch(url string, limit int64) ([]byte, error) {
 	resp, err := http.Get(url)
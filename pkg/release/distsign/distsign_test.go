@@ -320,6 +320,53 @@ func TestRotateSigning(t *testing.T) {
 	}
 }
 
+func TestVerifyPayload(t *testing.T) {
+	root := newRootKeyPair(t)
+	rootPub, err := parseSinglePublicKey(root.pubRaw, pemTypeRootPublic)
+	if err != nil {
+		t.Fatalf("parseSinglePublicKey: %v", err)
+	}
+	signing := newSigningKeyPair(t)
+	keysSig := root.sign(signing.pubRaw)
+
+	payload := []byte(`{"version":"v1"}`)
+	sig, err := signing.SignPayload(payload)
+	if err != nil {
+		t.Fatalf("SignPayload: %v", err)
+	}
+	if _, err := signing.SignPayload(nil); err == nil {
+		t.Fatal("expected error signing empty payload")
+	}
+
+	rootKeys := []ed25519.PublicKey{rootPub}
+	if err := VerifyPayload(rootKeys, signing.pubRaw, keysSig, payload, sig); err != nil {
+		t.Fatalf("VerifyPayload: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keysSig []byte
+		payload []byte
+		sig     []byte
+	}{
+		{name: "tampered payload", keysSig: keysSig, payload: []byte(`{"version":"v2"}`), sig: sig},
+		{name: "bad payload signature", keysSig: keysSig, payload: payload, sig: make([]byte, ed25519.SignatureSize)},
+		{name: "bad signing keys signature", keysSig: make([]byte, ed25519.SignatureSize), payload: payload, sig: sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyPayload(rootKeys, signing.pubRaw, tt.keysSig, tt.payload, tt.sig); err == nil {
+				t.Fatal("expected non-nil error")
+			}
+		})
+	}
+
+	// signed by an unknown root, not embedded
+	if err := VerifyPayload(nil, signing.pubRaw, keysSig, payload, sig); err == nil {
+		t.Fatal("expected non-nil error with the embedded root keys")
+	}
+}
+
 func TestParseRootKey(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -0,0 +1,259 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	cmdcommon "github.com/leptonai/gpud/cmd/common"
+	"github.com/leptonai/gpud/components"
+	nvidiaxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+)
+
+const (
+	// DefaultMaxResults is the default number of the scan results kept in the results directory.
+	DefaultMaxResults = 10
+
+	resultFilePrefix = "scan-"
+	resultFileSuffix = ".json"
+)
+
+// Result is the stored result of a scan run.
+type Result struct {
+	Time       time.Time         `json:"time"`
+	Components []ComponentResult `json:"components"`
+}
+
+// ComponentResult is the result of a component check in a scan run.
+type ComponentResult struct {
+	Name   string                `json:"name"`
+	Health apiv1.HealthStateType `json:"health"`
+	Reason string                `json:"reason,omitempty"`
+
+	// Xids are the Xid errors found, only set for the Xid component.
+	Xids []XidResult `json:"xids,omitempty"`
+	// IBPortRates are the rates in Gb/sec keyed by "<device>:<port>",
+	// only set for the InfiniBand component.
+	IBPortRates map[string]uint64 `json:"ib_port_rates,omitempty"`
+}
+
+// XidResult is a Xid error found in a scan run.
+type XidResult struct {
+	Xid        int    `json:"xid"`
+	DeviceUUID string `json:"device_uuid"`
+}
+
+// xidErrorsGetter is implemented by the check result of the Xid component.
+type xidErrorsGetter interface {
+	XidErrors() []nvidiaxid.Error
+}
+
+// portRatesGetter is implemented by the check result of the InfiniBand component.
+type portRatesGetter interface {
+	PortRates() map[string]uint64
+}
+
+func newComponentResult(name string, cr components.CheckResult) ComponentResult {
+	r := ComponentResult{
+		Name:   name,
+		Health: cr.HealthStateType(),
+		Reason: cr.Summary(),
+	}
+	if g, ok := cr.(xidErrorsGetter); ok {
+		for _, e := range g.XidErrors() {
+			r.Xids = append(r.Xids, XidResult{Xid: e.Xid, DeviceUUID: e.DeviceUUID})
+		}
+	}
+	if g, ok := cr.(portRatesGetter); ok {
+		r.IBPortRates = g.PortRates()
+	}
+	return r
+}
+
+// SaveResult stores the scan result in the directory,
+// and removes the oldest results beyond the max results.
+func SaveResult(dir string, r Result, maxResults int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%s%d%s", resultFilePrefix, r.Time.UnixNano(), resultFileSuffix))
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return err
+	}
+
+	files, err := listResultFiles(dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > maxResults {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// LoadLastResult returns the last stored scan result in the directory,
+// or nil if none is stored.
+func LoadLastResult(dir string) (*Result, error) {
+	files, err := listResultFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(files[len(files)-1])
+	if err != nil {
+		return nil, err
+	}
+	var r Result
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// listResultFiles returns the result files in the directory, sorted from the oldest.
+func listResultFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, resultFilePrefix) || !strings.HasSuffix(name, resultFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	// the file names are the same length until the year 2262
+	sort.Strings(files)
+	return files, nil
+}
+
+// Change is a change of a component since the previous scan.
+type Change struct {
+	Component string `json:"component"`
+	// Regressed is true if the change is for the worse
+	// (e.g., the component became unhealthy, a new Xid found, a port rate dropped).
+	Regressed bool   `json:"regressed"`
+	Message   string `json:"message"`
+}
+
+// Compare returns the changes of the current scan since the previous one,
+// sorted by the component names.
+func Compare(prev Result, cur Result) []Change {
+	prevs := make(map[string]ComponentResult, len(prev.Components))
+	for _, c := range prev.Components {
+		prevs[c.Name] = c
+	}
+
+	var changes []Change
+	for _, c := range cur.Components {
+		p, ok := prevs[c.Name]
+		if !ok {
+			if c.Health != apiv1.HealthStateTypeHealthy {
+				changes = append(changes, Change{Component: c.Name, Regressed: true, Message: fmt.Sprintf("newly scanned as %s (%s)", c.Health, c.Reason)})
+			}
+			continue
+		}
+
+		switch {
+		case p.Health == apiv1.HealthStateTypeHealthy && c.Health != apiv1.HealthStateTypeHealthy:
+			changes = append(changes, Change{Component: c.Name, Regressed: true, Message: fmt.Sprintf("became %s (%s)", c.Health, c.Reason)})
+		case p.Health != apiv1.HealthStateTypeHealthy && c.Health == apiv1.HealthStateTypeHealthy:
+			changes = append(changes, Change{Component: c.Name, Message: fmt.Sprintf("recovered from %s (%s)", p.Health, p.Reason)})
+		case p.Health != c.Health:
+			changes = append(changes, Change{Component: c.Name, Regressed: true, Message: fmt.Sprintf("changed from %s to %s (%s)", p.Health, c.Health, c.Reason)})
+		}
+
+		changes = append(changes, compareXids(c.Name, p.Xids, c.Xids)...)
+		changes = append(changes, comparePortRates(c.Name, p.IBPortRates, c.IBPortRates)...)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Component < changes[j].Component
+	})
+	return changes
+}
+
+func compareXids(component string, prev []XidResult, cur []XidResult) []Change {
+	seen := make(map[XidResult]struct{}, len(prev))
+	for _, x := range prev {
+		seen[x] = struct{}{}
+	}
+
+	var changes []Change
+	for _, x := range cur {
+		if _, ok := seen[x]; ok {
+			continue
+		}
+		seen[x] = struct{}{}
+		changes = append(changes, Change{Component: component, Regressed: true, Message: fmt.Sprintf("new xid %d on %s", x.Xid, x.DeviceUUID)})
+	}
+	return changes
+}
+
+func comparePortRates(component string, prev map[string]uint64, cur map[string]uint64) []Change {
+	ports := make([]string, 0, len(prev)+len(cur))
+	for port := range prev {
+		ports = append(ports, port)
+	}
+	for port := range cur {
+		if _, ok := prev[port]; !ok {
+			ports = append(ports, port)
+		}
+	}
+	sort.Strings(ports)
+
+	var changes []Change
+	for _, port := range ports {
+		p, hadPrev := prev[port]
+		c, hasCur := cur[port]
+		switch {
+		case !hadPrev:
+			changes = append(changes, Change{Component: component, Message: fmt.Sprintf("new port %s at %d Gb/sec", port, c)})
+		case !hasCur:
+			changes = append(changes, Change{Component: component, Regressed: true, Message: fmt.Sprintf("port %s no longer found (was %d Gb/sec)", port, p)})
+		case p != c:
+			changes = append(changes, Change{Component: component, Regressed: c < p, Message: fmt.Sprintf("port %s rate changed from %d to %d Gb/sec", port, p, c)})
+		}
+	}
+	return changes
+}
+
+// renderChanges writes the changes since the previous scan.
+func renderChanges(wr io.Writer, prevTime time.Time, changes []Change) {
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(wr, "%s no change since the last scan at %s\n\n", cmdcommon.CheckMark, prevTime.Format(time.RFC3339))
+		return
+	}
+
+	_, _ = fmt.Fprintf(wr, "%s %d change(s) since the last scan at %s\n", cmdcommon.InProgress, len(changes), prevTime.Format(time.RFC3339))
+	for _, ch := range changes {
+		mark := cmdcommon.CheckMark
+		if ch.Regressed {
+			mark = cmdcommon.WarningSign
+		}
+		_, _ = fmt.Fprintf(wr, "%s %s: %s\n", mark, ch.Component, ch.Message)
+	}
+	_, _ = fmt.Fprintln(wr)
+}
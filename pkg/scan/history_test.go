@@ -0,0 +1,82 @@
+package scan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestSaveAndLoadLastResult(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan-results")
+
+	last, err := LoadLastResult(dir)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	start := time.Unix(1700000000, 0).UTC()
+	for i := 0; i < 5; i++ {
+		r := Result{
+			Time:       start.Add(time.Duration(i) * time.Minute),
+			Components: []ComponentResult{{Name: "cpu", Health: apiv1.HealthStateTypeHealthy}},
+		}
+		require.NoError(t, SaveResult(dir, r, 3))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	last, err = LoadLastResult(dir)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.True(t, start.Add(4*time.Minute).Equal(last.Time))
+	assert.Equal(t, "cpu", last.Components[0].Name)
+}
+
+func TestCompare(t *testing.T) {
+	prev := Result{Components: []ComponentResult{
+		{Name: "accelerator-nvidia-infiniband", Health: apiv1.HealthStateTypeHealthy, IBPortRates: map[string]uint64{"mlx5_0:1": 400, "mlx5_1:1": 400, "mlx5_2:1": 400}},
+		{Name: "accelerator-nvidia-xid", Health: apiv1.HealthStateTypeUnhealthy, Reason: "xid 79", Xids: []XidResult{{Xid: 79, DeviceUUID: "GPU-0"}}},
+		{Name: "cpu", Health: apiv1.HealthStateTypeHealthy},
+		{Name: "memory", Health: apiv1.HealthStateTypeUnhealthy, Reason: "ecc errors"},
+	}}
+	cur := Result{Components: []ComponentResult{
+		{Name: "accelerator-nvidia-infiniband", Health: apiv1.HealthStateTypeHealthy, IBPortRates: map[string]uint64{"mlx5_0:1": 400, "mlx5_1:1": 200, "mlx5_3:1": 400}},
+		{Name: "accelerator-nvidia-xid", Health: apiv1.HealthStateTypeUnhealthy, Reason: "xid 79, 48", Xids: []XidResult{{Xid: 79, DeviceUUID: "GPU-0"}, {Xid: 48, DeviceUUID: "GPU-1"}}},
+		{Name: "cpu", Health: apiv1.HealthStateTypeUnhealthy, Reason: "high load"},
+		{Name: "disk", Health: apiv1.HealthStateTypeDegraded, Reason: "disk full"},
+		{Name: "memory", Health: apiv1.HealthStateTypeHealthy},
+	}}
+
+	assert.Equal(t, []Change{
+		{Component: "accelerator-nvidia-infiniband", Regressed: true, Message: "port mlx5_1:1 rate changed from 400 to 200 Gb/sec"},
+		{Component: "accelerator-nvidia-infiniband", Regressed: true, Message: "port mlx5_2:1 no longer found (was 400 Gb/sec)"},
+		{Component: "accelerator-nvidia-infiniband", Message: "new port mlx5_3:1 at 400 Gb/sec"},
+		{Component: "accelerator-nvidia-xid", Regressed: true, Message: "new xid 48 on GPU-1"},
+		{Component: "cpu", Regressed: true, Message: "became Unhealthy (high load)"},
+		{Component: "disk", Regressed: true, Message: "newly scanned as Degraded (disk full)"},
+		{Component: "memory", Message: "recovered from Unhealthy (ecc errors)"},
+	}, Compare(prev, cur))
+
+	assert.Empty(t, Compare(cur, cur))
+}
+
+func TestRenderChanges(t *testing.T) {
+	ts := time.Unix(1700000000, 0).UTC()
+
+	buf := bytes.NewBuffer(nil)
+	renderChanges(buf, ts, nil)
+	assert.Contains(t, buf.String(), "no change since the last scan")
+
+	buf.Reset()
+	renderChanges(buf, ts, []Change{{Component: "cpu", Regressed: true, Message: "became Unhealthy (high load)"}})
+	assert.Contains(t, buf.String(), "1 change(s) since the last scan")
+	assert.Contains(t, buf.String(), "cpu: became Unhealthy (high load)")
+}
@@ -1,6 +1,8 @@
 package scan
 
 import (
+	"errors"
+
 	"github.com/leptonai/gpud/components"
 	infinibandclass "github.com/leptonai/gpud/components/accelerator/nvidia/infiniband/class"
 )
@@ -9,6 +11,10 @@ type Op struct {
 	infinibandClassRootDir string
 	debug                  bool
 	failureInjector        *components.FailureInjector
+
+	// resultsDir is the directory to store the scan results, not stored if empty
+	resultsDir  string
+	compareLast bool
 }
 
 type OpOption func(*Op)
@@ -21,6 +27,9 @@ func (op *Op) applyOpts(opts []OpOption) error {
 	if op.infinibandClassRootDir == "" {
 		op.infinibandClassRootDir = infinibandclass.DefaultRootDir
 	}
+	if op.compareLast && op.resultsDir == "" {
+		return errors.New("results directory is required to compare with the last scan")
+	}
 
 	return nil
 }
@@ -43,3 +52,17 @@ func WithDebug(b bool) OpOption {
 		op.debug = b
 	}
 }
+
+// Specifies the directory to store the result of each scan.
+func WithResultsDir(dir string) OpOption {
+	return func(op *Op) {
+		op.resultsDir = dir
+	}
+}
+
+// Set true to print the changes since the last scan stored in the results directory.
+func WithCompareLast(b bool) OpOption {
+	return func(op *Op) {
+		op.compareLast = b
+	}
+}
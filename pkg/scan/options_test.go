@@ -69,3 +69,14 @@ func TestWithFailureInjector(t *testing.T) {
 		})
 	}
 }
+
+func TestWithResultsDir(t *testing.T) {
+	op := &Op{}
+	assert.NoError(t, op.applyOpts([]OpOption{WithResultsDir("/tmp/scan-results"), WithCompareLast(true)}))
+	assert.Equal(t, "/tmp/scan-results", op.resultsDir)
+	assert.True(t, op.compareLast)
+
+	// nothing to compare with if the results are not stored
+	op = &Op{}
+	assert.Error(t, op.applyOpts([]OpOption{WithCompareLast(true)}))
+}
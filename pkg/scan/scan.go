@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	cmdcommon "github.com/leptonai/gpud/cmd/common"
@@ -90,6 +91,7 @@ func Scan(ctx context.Context, opts ...OpOption) error {
 		FailureInjector: op.failureInjector,
 	}
 
+	result := Result{Time: time.Now().UTC()}
 	for _, c := range all.All() {
 		c, err := c.InitFunc(gpudInstance)
 		if err != nil {
@@ -98,7 +100,28 @@ func Scan(ctx context.Context, opts ...OpOption) error {
 		if !c.IsSupported() {
 			continue
 		}
-		printSummary(c.Check())
+		cr := c.Check()
+		printSummary(cr)
+		result.Components = append(result.Components, newComponentResult(c.Name(), cr))
+	}
+
+	if op.compareLast {
+		// loaded before storing the current result
+		prev, err := LoadLastResult(op.resultsDir)
+		if err != nil {
+			return fmt.Errorf("failed to load the last scan result: %w", err)
+		}
+		if prev == nil {
+			fmt.Printf("%s no previous scan result to compare with\n\n", cmdcommon.WarningSign)
+		} else {
+			renderChanges(os.Stdout, prev.Time, Compare(*prev, result))
+		}
+	}
+
+	if op.resultsDir != "" {
+		if err := SaveResult(op.resultsDir, result, DefaultMaxResults); err != nil {
+			log.Logger.Warnw("failed to store the scan result", "dir", op.resultsDir, "error", err)
+		}
 	}
 
 	fmt.Printf("\n\n%s scan complete\n\n", cmdcommon.CheckMark)
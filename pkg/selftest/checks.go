@@ -0,0 +1,177 @@
+package selftest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/leptonai/gpud/pkg/disk"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/version"
+)
+
+const (
+	// The names of the checks.
+	CheckNameClockSane    = "clock-sane"
+	CheckNameDBWritable   = "db-writable"
+	CheckNameNVMLLoadable = "nvml-loadable"
+	CheckNameDiskSpace    = "disk-space"
+	CheckNameKmsgReadable = "kmsg-readable"
+
+	// DefaultMinFreeBytes is the default minimum free space of the data directory.
+	DefaultMinFreeBytes = 512 * 1024 * 1024
+
+	// clockTolerance is how far the wall clock may be behind the last self-test,
+	// to not fail on the small NTP corrections.
+	clockTolerance = time.Minute
+)
+
+// minClockTime is the earliest sane wall clock time, when no build time is set.
+var minClockTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ClockSane returns the check that fails if the wall clock is before the build
+// time of the daemon, or behind the last self-test recorded in the state database
+// (e.g., the RTC reset to the epoch, the clock stepped back across a restart).
+// Must run before [DBWritable], which records the self-test time.
+func ClockSane(dbRO *sql.DB, getTimeNowFunc func() time.Time) Check {
+	return Check{
+		Name: CheckNameClockSane,
+		Run: func(ctx context.Context) error {
+			now := getTimeNowFunc()
+
+			notBefore := minClockTime
+			if built, ok := parseBuildTimestamp(version.BuildTimestamp); ok && built.After(notBefore) {
+				notBefore = built
+			}
+			if now.Before(notBefore) {
+				return fmt.Errorf("wall clock %s is before the build time %s", now.Format(time.RFC3339), notBefore.Format(time.RFC3339))
+			}
+
+			raw, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyLastSelfTest)
+			if err != nil {
+				return fmt.Errorf("failed to read the last self-test time: %w", err)
+			}
+			if raw == "" {
+				return nil
+			}
+			last, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse the last self-test time %q: %w", raw, err)
+			}
+			if now.Add(clockTolerance).Before(last) {
+				return fmt.Errorf("wall clock %s is behind the last self-test at %s", now.Format(time.RFC3339), last.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
+
+// parseBuildTimestamp parses the build timestamp set at linking time,
+// in RFC3339 (e.g., "make") or in unix seconds (e.g., "goreleaser").
+func parseBuildTimestamp(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// DBWritable returns the check that fails if the state database is not writable,
+// by recording the self-test time and reading it back.
+func DBWritable(dbRW *sql.DB, getTimeNowFunc func() time.Time) Check {
+	return Check{
+		Name: CheckNameDBWritable,
+		Run: func(ctx context.Context) error {
+			want := getTimeNowFunc().UTC().Format(time.RFC3339Nano)
+			if err := pkgmetadata.SetMetadata(ctx, dbRW, pkgmetadata.MetadataKeyLastSelfTest, want); err != nil {
+				return fmt.Errorf("failed to write the state database: %w", err)
+			}
+			got, err := pkgmetadata.ReadMetadata(ctx, dbRW, pkgmetadata.MetadataKeyLastSelfTest)
+			if err != nil {
+				return fmt.Errorf("failed to read the state database: %w", err)
+			}
+			if got != want {
+				return fmt.Errorf("read %q from the state database, expected %q", got, want)
+			}
+			return nil
+		},
+	}
+}
+
+// NVMLLoadable returns the check that fails if the NVML library is not loaded
+// while the NVIDIA devices are present (e.g., the library missing or mismatching
+// the driver), or if NVML found no GPU. Skipped on the host without the NVIDIA devices.
+func NVMLLoadable(nvmlInstance nvidianvml.Instance, countDevicesFunc func() (int, error)) Check {
+	return Check{
+		Name: CheckNameNVMLLoadable,
+		Run: func(ctx context.Context) error {
+			devCnt, err := countDevicesFunc()
+			if err != nil {
+				return fmt.Errorf("failed to count the NVIDIA devices: %w", err)
+			}
+			if devCnt == 0 {
+				return Skip("no NVIDIA device found")
+			}
+
+			if nvmlInstance == nil || !nvmlInstance.NVMLExists() {
+				return fmt.Errorf("NVML not loaded while %d NVIDIA device(s) found", devCnt)
+			}
+			if n := len(nvmlInstance.Devices()); n == 0 {
+				return fmt.Errorf("NVML found no GPU while %d NVIDIA device(s) found", devCnt)
+			}
+			return nil
+		},
+	}
+}
+
+// DiskSpace returns the check that fails if the free space of the file system
+// of the directory is below the minimum.
+func DiskSpace(dir string, minFreeBytes uint64) Check {
+	return Check{
+		Name: CheckNameDiskSpace,
+		Run: func(ctx context.Context) error {
+			usage, err := disk.GetUsage(ctx, dir)
+			if err != nil {
+				return fmt.Errorf("failed to get the disk usage of %q: %w", dir, err)
+			}
+			if usage.FreeBytes < minFreeBytes {
+				return fmt.Errorf("%s free in %q, below %s", humanize.IBytes(usage.FreeBytes), dir, humanize.IBytes(minFreeBytes))
+			}
+			return nil
+		},
+	}
+}
+
+// KmsgReadable returns the check that fails if the kernel message file
+// (e.g., "/dev/kmsg") cannot be opened for reading (e.g., not running as root,
+// not mounted in the container), as the kernel message based components
+// (e.g., Xid) see no event.
+func KmsgReadable(path string) Check {
+	return Check{
+		Name: CheckNameKmsgReadable,
+		Run: func(ctx context.Context) error {
+			// non-blocking not to wait for a new message
+			f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("%q not found", path)
+				}
+				return fmt.Errorf("failed to open %q: %w", path, err)
+			}
+			return f.Close()
+		},
+	}
+}
@@ -0,0 +1,146 @@
+// Package selftest runs the quick checks of the core assumptions of the daemon
+// at startup (e.g., the state database writable, NVML loadable, the clock sane),
+// before declaring the readiness, so that a partial initialization is reported
+// through the readiness check and a startup event, rather than manifesting as
+// the missing data.
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// BucketName is the event bucket of the startup self-test results.
+	BucketName = "self-test"
+	// EventName is the event name of the startup self-test result.
+	EventName = "startup_self_test"
+
+	// DefaultTimeout is the default timeout of a single check.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Check is a single self-test check.
+type Check struct {
+	// Name is the check name (e.g., "db-writable").
+	Name string
+	// Run returns nil if the check passed, or the error returned by [Skip]
+	// if the check is not applicable to the host.
+	Run func(ctx context.Context) error
+}
+
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip returns the error to skip the check for the reason
+// (e.g., no NVIDIA driver on the host), not failing the self-test.
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Run runs the checks in order, each bounded by the timeout
+// (or DefaultTimeout if not positive), and returns the results.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) apiv1.StartupSelfTest {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	res := apiv1.StartupSelfTest{Passed: true}
+	for _, c := range checks {
+		start := time.Now()
+		err := run(ctx, timeout, c)
+		cr := apiv1.StartupSelfTestCheck{
+			Name:    c.Name,
+			Result:  apiv1.StartupSelfTestPassed,
+			Elapsed: metav1.Duration{Duration: time.Since(start)},
+		}
+
+		var skip *skipError
+		switch {
+		case err == nil:
+		case errors.As(err, &skip):
+			cr.Result = apiv1.StartupSelfTestSkipped
+			cr.Message = skip.reason
+		default:
+			cr.Result = apiv1.StartupSelfTestFailed
+			cr.Message = err.Error()
+			res.Passed = false
+		}
+		log.Logger.Infow("startup self-test check done", "check", c.Name, "result", cr.Result, "elapsed", cr.Elapsed.Duration, "message", cr.Message)
+
+		res.Checks = append(res.Checks, cr)
+	}
+	return res
+}
+
+// run runs the check with the timeout, and returns the timeout error
+// without waiting for the check ignoring the context.
+func run(ctx context.Context, timeout time.Duration, c Check) error {
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.Run(cctx)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-cctx.Done():
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// Failed returns the names of the failed checks.
+func Failed(res apiv1.StartupSelfTest) []string {
+	var failed []string
+	for _, c := range res.Checks {
+		if c.Result == apiv1.StartupSelfTestFailed {
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed
+}
+
+// Event returns the startup event of the self-test result, of the warning type
+// with the error of each failed check by the check name if any check failed.
+func Event(res apiv1.StartupSelfTest, now time.Time) eventstore.Event {
+	ev := eventstore.Event{
+		Component: BucketName,
+		Time:      now,
+		Name:      EventName,
+		Type:      string(apiv1.EventTypeInfo),
+		Message:   fmt.Sprintf("startup self-test passed (%d check(s))", len(res.Checks)),
+	}
+	failed := Failed(res)
+	if len(failed) == 0 {
+		return ev
+	}
+
+	sort.Strings(failed)
+	ev.Type = string(apiv1.EventTypeWarning)
+	ev.Message = "startup self-test failed: " + strings.Join(failed, ", ")
+	ev.ExtraInfo = make(map[string]string, len(failed))
+	for _, c := range res.Checks {
+		if c.Result == apiv1.StartupSelfTestFailed {
+			ev.ExtraInfo[c.Name] = c.Message
+		}
+	}
+	return ev
+}
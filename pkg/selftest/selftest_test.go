@@ -0,0 +1,165 @@
+package selftest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestRun(t *testing.T) {
+	res := Run(context.Background(), 50*time.Millisecond,
+		Check{Name: "pass", Run: func(context.Context) error { return nil }},
+		Check{Name: "skip", Run: func(context.Context) error { return Skip("not applicable") }},
+		Check{Name: "fail", Run: func(context.Context) error { return errors.New("broken") }},
+		Check{Name: "hang", Run: func(context.Context) error { select {} }},
+	)
+
+	assert.False(t, res.Passed)
+	require.Len(t, res.Checks, 4)
+	assert.Equal(t, apiv1.StartupSelfTestPassed, res.Checks[0].Result)
+	assert.Equal(t, apiv1.StartupSelfTestSkipped, res.Checks[1].Result)
+	assert.Equal(t, "not applicable", res.Checks[1].Message)
+	assert.Equal(t, apiv1.StartupSelfTestFailed, res.Checks[2].Result)
+	assert.Equal(t, "broken", res.Checks[2].Message)
+	assert.Equal(t, apiv1.StartupSelfTestFailed, res.Checks[3].Result)
+	assert.Contains(t, res.Checks[3].Message, "timed out")
+	assert.Equal(t, []string{"fail", "hang"}, Failed(res))
+
+	res = Run(context.Background(), 0, Check{Name: "skip", Run: func(context.Context) error { return Skip("n/a") }})
+	assert.True(t, res.Passed)
+}
+
+func TestEvent(t *testing.T) {
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	ev := Event(apiv1.StartupSelfTest{Passed: true, Checks: []apiv1.StartupSelfTestCheck{{Name: "a", Result: apiv1.StartupSelfTestPassed}}}, now)
+	assert.Equal(t, BucketName, ev.Component)
+	assert.Equal(t, EventName, ev.Name)
+	assert.Equal(t, string(apiv1.EventTypeInfo), ev.Type)
+	assert.Equal(t, now, ev.Time)
+	assert.Nil(t, ev.ExtraInfo)
+
+	ev = Event(apiv1.StartupSelfTest{Checks: []apiv1.StartupSelfTestCheck{
+		{Name: "kmsg-readable", Result: apiv1.StartupSelfTestFailed, Message: "permission denied"},
+		{Name: "db-writable", Result: apiv1.StartupSelfTestFailed, Message: "readonly database"},
+		{Name: "nvml-loadable", Result: apiv1.StartupSelfTestSkipped, Message: "no NVIDIA device found"},
+	}}, now)
+	assert.Equal(t, string(apiv1.EventTypeWarning), ev.Type)
+	assert.Equal(t, "startup self-test failed: db-writable, kmsg-readable", ev.Message)
+	assert.Equal(t, map[string]string{"kmsg-readable": "permission denied", "db-writable": "readonly database"}, ev.ExtraInfo)
+}
+
+func TestClockSaneAndDBWritable(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	getTimeNowFunc := func() time.Time { return now }
+
+	// no self-test recorded yet
+	require.NoError(t, ClockSane(dbRO, getTimeNowFunc).Run(ctx))
+	require.NoError(t, DBWritable(dbRW, getTimeNowFunc).Run(ctx))
+
+	got, err := pkgmetadata.ReadMetadata(ctx, dbRO, pkgmetadata.MetadataKeyLastSelfTest)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-06-01T00:00:00Z", got)
+
+	// within the tolerance
+	now = now.Add(-30 * time.Second)
+	require.NoError(t, ClockSane(dbRO, getTimeNowFunc).Run(ctx))
+
+	// stepped back across the restart
+	now = now.Add(-time.Hour)
+	err = ClockSane(dbRO, getTimeNowFunc).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "behind the last self-test")
+
+	// e.g., the RTC reset to the epoch
+	now = time.Unix(0, 0).UTC()
+	err = ClockSane(dbRO, getTimeNowFunc).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "before the build time")
+}
+
+func TestDBWritableMissingTable(t *testing.T) {
+	dbRW, _, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	err := DBWritable(dbRW, time.Now).Run(context.Background())
+	require.Error(t, err)
+}
+
+func TestParseBuildTimestamp(t *testing.T) {
+	want := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	got, ok := parseBuildTimestamp("2025-03-04T05:06:07Z")
+	assert.True(t, ok)
+	assert.True(t, want.Equal(got))
+
+	got, ok = parseBuildTimestamp("1741064767")
+	assert.True(t, ok)
+	assert.True(t, want.Equal(got))
+
+	_, ok = parseBuildTimestamp("")
+	assert.False(t, ok)
+	_, ok = parseBuildTimestamp("unknown")
+	assert.False(t, ok)
+}
+
+func TestNVMLLoadable(t *testing.T) {
+	ctx := context.Background()
+	countDevices := func(n int, err error) func() (int, error) {
+		return func() (int, error) { return n, err }
+	}
+
+	var skip *skipError
+	err := NVMLLoadable(nvidianvml.NewNoOp(), countDevices(0, nil)).Run(ctx)
+	assert.ErrorAs(t, err, &skip)
+
+	err = NVMLLoadable(nvidianvml.NewNoOp(), countDevices(8, nil)).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NVML not loaded while 8 NVIDIA device(s) found")
+
+	err = NVMLLoadable(nil, countDevices(0, errors.New("permission denied"))).Run(ctx)
+	require.Error(t, err)
+	assert.NotErrorAs(t, err, &skip)
+}
+
+func TestDiskSpace(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	require.NoError(t, DiskSpace(dir, 1).Run(ctx))
+
+	err := DiskSpace(dir, 1<<62).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "below")
+
+	require.Error(t, DiskSpace(filepath.Join(dir, "missing"), 1).Run(ctx))
+}
+
+func TestKmsgReadable(t *testing.T) {
+	ctx := context.Background()
+
+	p := filepath.Join(t.TempDir(), "kmsg")
+	err := KmsgReadable(p).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, os.WriteFile(p, nil, 0o600))
+	require.NoError(t, KmsgReadable(p).Run(ctx))
+}
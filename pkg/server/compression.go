@@ -0,0 +1,205 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	encodingGzip     = "gzip"
+	encodingZstd     = "zstd"
+	encodingIdentity = "identity"
+
+	// ctxKeyUncompressedResponseSize is the gin context key of the response body size
+	// before the compression, for the payload size metrics.
+	ctxKeyUncompressedResponseSize = "gpud.uncompressed_response_size"
+)
+
+// negotiateEncoding returns the response encoding accepted by the "Accept-Encoding" header
+// with the highest weight, preferring zstd over gzip for the equal weights,
+// or empty to not compress (e.g., "Accept-Encoding: identity", "gzip;q=0").
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	for part := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				f = 0
+			}
+			q = f
+		}
+		weights[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingZstd, encodingGzip} {
+		q, ok := weights[coding]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// encoder is the streaming compressor of the response body.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var (
+	gzipEncoderPool = sync.Pool{
+		New: func() any {
+			return gzip.NewWriter(io.Discard)
+		},
+	}
+	zstdEncoderPool = sync.Pool{
+		New: func() any {
+			// single goroutine per encoder, as the responses are compressed concurrently
+			enc, err := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				// only fails on the invalid options
+				panic(err)
+			}
+			return enc
+		},
+	}
+)
+
+func getEncoder(encoding string, w io.Writer) encoder {
+	var enc encoder
+	switch encoding {
+	case encodingZstd:
+		enc = zstdEncoderPool.Get().(*zstd.Encoder)
+	default:
+		enc = gzipEncoderPool.Get().(*gzip.Writer)
+	}
+	enc.Reset(w)
+	return enc
+}
+
+func putEncoder(encoding string, enc encoder) {
+	// release the reference to the response writer
+	enc.Reset(io.Discard)
+	switch encoding {
+	case encodingZstd:
+		zstdEncoderPool.Put(enc)
+	default:
+		gzipEncoderPool.Put(enc)
+	}
+}
+
+// compressionMiddleware compresses the response bodies in the zstd or gzip encoding
+// negotiated by the "Accept-Encoding" header, with the "Content-Encoding" response header.
+// The paths with the excluded prefixes (e.g., the streaming endpoints) are never compressed.
+//
+// The "Content-Encoding" header is set before the handlers, so that the downstream
+// middlewares (e.g., errorRequestIDMiddleware) know the body written is compressed.
+func compressionMiddleware(excludedPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead || hasPathPrefix(c.Request.URL.Path, excludedPaths) {
+			c.Next()
+			return
+		}
+
+		c.Header("Content-Encoding", encoding)
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = w
+		c.Next()
+
+		if w.enc == nil {
+			// no body (e.g., 204), not to send the empty body as compressed
+			if !w.Written() {
+				w.Header().Del("Content-Encoding")
+			}
+			return
+		}
+		if err := w.enc.Close(); err != nil {
+			log.Logger.Warnw("failed to close response encoder", "encoding", encoding, "error", err)
+		}
+		putEncoder(encoding, w.enc)
+		w.enc = nil
+
+		c.Set(ctxKeyUncompressedResponseSize, w.uncompressed)
+	}
+}
+
+func hasPathPrefix(p string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ gin.ResponseWriter = &compressWriter{}
+
+// compressWriter compresses the response body written by the handlers.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+
+	// enc is created on the first write
+	enc          encoder
+	uncompressed int
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	// the handlers may set the uncompressed length
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.enc == nil {
+		w.Header().Del("Content-Length")
+		w.enc = getEncoder(w.encoding, w.ResponseWriter)
+	}
+	n, err := w.enc.Write(b)
+	w.uncompressed += n
+	return n, err
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush flushes the compressed data written so far to the client.
+func (w *compressWriter) Flush() {
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
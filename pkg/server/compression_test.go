@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "identity", want: ""},
+		{header: "gzip", want: encodingGzip},
+		{header: "zstd", want: encodingZstd},
+		{header: "gzip, deflate, br, zstd", want: encodingZstd},
+		{header: "GZIP", want: encodingGzip},
+		{header: "zstd;q=0.5, gzip", want: encodingGzip},
+		{header: "zstd;q=0, gzip;q=0.1", want: encodingGzip},
+		{header: "gzip;q=0", want: ""},
+		{header: "*", want: encodingZstd},
+		{header: "*;q=0.5, zstd;q=0", want: encodingGzip},
+		{header: "br", want: ""},
+		{header: "gzip;q=invalid", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiateEncoding(tt.header))
+		})
+	}
+}
+
+func newCompressionTestRouter(body string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/v1")
+	group.Use(compressionMiddleware([]string{"/v1/stream"}))
+	group.GET("/info", func(c *gin.Context) {
+		c.Header("Content-Length", strconv.Itoa(len(body)))
+		c.String(http.StatusOK, body)
+	})
+	group.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	group.DELETE("/info", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"component":"accelerator-nvidia-info","healthy":true}`, 1000)
+	router := newCompressionTestRouter(body)
+
+	get := func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("zstd", func(t *testing.T) {
+		w := get("/v1/info", "gzip, zstd")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, encodingZstd, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Empty(t, w.Header().Get("Content-Length"))
+		assert.Less(t, w.Body.Len(), len(body))
+
+		dec, err := zstd.NewReader(w.Body)
+		require.NoError(t, err)
+		defer dec.Close()
+		b, err := io.ReadAll(dec)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(b))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		w := get("/v1/info", "gzip")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, encodingGzip, w.Header().Get("Content-Encoding"))
+
+		gr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		b, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(b))
+	})
+
+	t.Run("identity", func(t *testing.T) {
+		w := get("/v1/info", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, strconv.Itoa(len(body)), w.Header().Get("Content-Length"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("excluded path", func(t *testing.T) {
+		w := get("/v1/stream", "zstd")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/info", nil)
+		req.Header.Set("Accept-Encoding", "zstd")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Zero(t, w.Body.Len())
+	})
+
+	t.Run("encoders reused", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			for _, enc := range []string{"zstd", "gzip"} {
+				w := get("/v1/info", enc)
+				require.Equal(t, http.StatusOK, w.Code)
+				var rd io.Reader
+				if enc == "zstd" {
+					dec, err := zstd.NewReader(bytes.NewReader(w.Body.Bytes()))
+					require.NoError(t, err)
+					defer dec.Close()
+					rd = dec
+				} else {
+					gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+					require.NoError(t, err)
+					rd = gr
+				}
+				b, err := io.ReadAll(rd)
+				require.NoError(t, err)
+				assert.Equal(t, body, string(b))
+			}
+		}
+	})
+}
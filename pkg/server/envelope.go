@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/version"
+)
+
+const (
+	urlPathV1 = "/v1"
+	urlPathV2 = "/v2"
+
+	// mediaTypePrefix is the prefix of the versioned GPUd media types
+	// (e.g., "application/vnd.gpud.v2+json").
+	mediaTypePrefix = "application/vnd.gpud."
+	mediaTypeSuffix = "+json"
+
+	// ctxKeyDataTimes is the gin context key for the time range of the response data.
+	ctxKeyDataTimes = "gpud-data-times"
+)
+
+// negotiateAPIVersion returns the API version requested by the "Accept" header.
+// It returns an empty string if no GPUd media type is requested,
+// and false if the requested version is not supported.
+func negotiateAPIVersion(accept string) (string, bool) {
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil || !strings.HasPrefix(mediaType, mediaTypePrefix) {
+			continue
+		}
+
+		ver := strings.TrimSuffix(strings.TrimPrefix(mediaType, mediaTypePrefix), mediaTypeSuffix)
+		switch ver {
+		case "v1", apiv2.Version:
+			return ver, true
+		default:
+			return ver, false
+		}
+	}
+	return "", true
+}
+
+// negotiateAPIVersionMiddleware wraps the responses in the v2 envelope
+// only if requested by the "Accept" header, so the v1 responses are unchanged by default.
+func negotiateAPIVersionMiddleware(node apiv2.Node) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ver, ok := negotiateAPIVersion(c.GetHeader("Accept"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"code": http.StatusNotAcceptable, "message": fmt.Sprintf("unsupported api version %q", ver)})
+			return
+		}
+		if ver != apiv2.Version {
+			c.Next()
+			return
+		}
+		serveEnvelope(c, node)
+	}
+}
+
+// envelopeMiddleware wraps every response in the v2 envelope,
+// for the routes under the "/v2" path prefix.
+func envelopeMiddleware(node apiv2.Node) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// the path prefix takes precedence over the "Accept" header,
+		// but still reject the unknown versions
+		if ver, ok := negotiateAPIVersion(c.GetHeader("Accept")); !ok {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"code": http.StatusNotAcceptable, "message": fmt.Sprintf("unsupported api version %q", ver)})
+			return
+		}
+		serveEnvelope(c, node)
+	}
+}
+
+// serveEnvelope runs the handler chain with the JSON response buffered,
+// and writes the buffered response wrapped in the v2 envelope.
+// The non-JSON responses (e.g., YAML, server-sent events) are written as is.
+func serveEnvelope(c *gin.Context, node apiv2.Node) {
+	c.Header(apiv2.ResponseHeaderAPIVersion, apiv2.Version)
+
+	w := &envelopeWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if w.passthrough || !bodyAllowedForStatus(w.Status()) {
+		return
+	}
+
+	env := apiv2.Envelope{
+		APIVersion:  apiv2.Version,
+		RequestID:   requestid.Get(c),
+		Node:        node,
+		GPUdVersion: version.Version,
+		GeneratedAt: metav1.NewTime(time.Now().UTC()),
+	}
+	if v, ok := c.Get(ctxKeyDataTimes); ok {
+		env.DataTimes, _ = v.(*apiv2.DataTimes)
+	}
+
+	body := bytes.TrimSpace(w.buf.Bytes())
+	if w.Status() >= http.StatusBadRequest {
+		env.Error = &apiv2.Error{
+			Code:    w.Status(),
+			Message: errorMessage(w.Status(), body),
+		}
+		if json.Valid(body) {
+			env.Error.Details = body
+		}
+	} else if len(body) > 0 {
+		env.Data = body
+	}
+
+	var b []byte
+	var err error
+	if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+		b, err = json.MarshalIndent(env, "", "    ")
+	} else {
+		b, err = json.Marshal(env)
+	}
+	if err != nil {
+		// the data is already a valid JSON, should never happen
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal envelope " + err.Error()})
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = c.Writer.Write(b)
+}
+
+// errorMessage returns the "message" (or "error") field of the v1 error response,
+// or the status text if not set.
+func errorMessage(status int, body []byte) string {
+	var resp struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	_ = json.Unmarshal(body, &resp)
+	switch {
+	case resp.Message != "":
+		return resp.Message
+	case resp.Error != "":
+		return resp.Error
+	default:
+		return http.StatusText(status)
+	}
+}
+
+// bodyAllowedForStatus returns false for the status codes that do not permit a body.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+var _ gin.ResponseWriter = &envelopeWriter{}
+
+// envelopeWriter buffers the JSON response body to be wrapped in the envelope.
+// The response is written as is if the handler sets any other content type.
+type envelopeWriter struct {
+	gin.ResponseWriter
+
+	buf bytes.Buffer
+
+	decided     bool
+	passthrough bool
+}
+
+func (w *envelopeWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	w.passthrough = mediaType != "" && mediaType != httputil.RequestHeaderJSON
+}
+
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.buf.WriteString(s)
+}
+
+// Flush only flushes the passthrough response (e.g., server-sent events),
+// the buffered response is flushed once wrapped in the envelope.
+func (w *envelopeWriter) Flush() {
+	w.decide()
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// setDataTime extends the time range of the response data with the timestamp,
+// reported as the data times in the v2 envelope.
+// The zero timestamp is ignored.
+func setDataTime(c *gin.Context, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	t = t.UTC()
+
+	if v, ok := c.Get(ctxKeyDataTimes); ok {
+		if dt, ok := v.(*apiv2.DataTimes); ok {
+			if t.Before(dt.Oldest.Time) {
+				dt.Oldest = metav1.NewTime(t)
+			}
+			if t.After(dt.Newest.Time) {
+				dt.Newest = metav1.NewTime(t)
+			}
+			return
+		}
+	}
+	c.Set(ctxKeyDataTimes, &apiv2.DataTimes{
+		Oldest: metav1.NewTime(t),
+		Newest: metav1.NewTime(t),
+	})
+}
+
+// setHealthStatesDataTimes sets the data times to the check times of the health states.
+func setHealthStatesDataTimes(c *gin.Context, states []apiv1.HealthState) {
+	for _, st := range states {
+		setDataTime(c, st.Time.Time)
+	}
+}
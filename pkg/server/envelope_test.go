@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/version"
+)
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{accept: "", want: "", wantOK: true},
+		{accept: "application/json", want: "", wantOK: true},
+		{accept: "*/*", want: "", wantOK: true},
+		{accept: apiv2.MediaType, want: "v2", wantOK: true},
+		{accept: "application/json, application/vnd.gpud.v2+json; q=0.9", want: "v2", wantOK: true},
+		{accept: "application/vnd.gpud.v1+json", want: "v1", wantOK: true},
+		{accept: "application/vnd.gpud.v3+json", want: "v3", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			got, ok := negotiateAPIVersion(tt.accept)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func newEnvelopeTestRouter(node apiv2.Node) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.New())
+
+	checkTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	handlers := func(r gin.IRoutes) {
+		r.GET("/states", func(c *gin.Context) {
+			states := []apiv1.HealthState{
+				{Time: metav1.NewTime(checkTime), Health: apiv1.HealthStateTypeHealthy},
+				{Time: metav1.NewTime(checkTime.Add(time.Minute)), Health: apiv1.HealthStateTypeHealthy},
+			}
+			setHealthStatesDataTimes(c, states)
+			c.JSON(http.StatusOK, states)
+		})
+		r.GET("/missing", func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusNotFound, "message": "component not found"})
+		})
+		r.GET("/yaml", func(c *gin.Context) {
+			c.String(http.StatusOK, "a: b\n")
+		})
+	}
+
+	v1Group := router.Group(urlPathV1)
+	v1Group.Use(negotiateAPIVersionMiddleware(node))
+	handlers(v1Group)
+
+	v2Group := router.Group(urlPathV2)
+	v2Group.Use(envelopeMiddleware(node))
+	handlers(v2Group)
+
+	return router
+}
+
+func doEnvelopeTestRequest(t *testing.T, router *gin.Engine, path string, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEnvelopeV1Untouched(t *testing.T) {
+	router := newEnvelopeTestRouter(apiv2.Node{MachineID: "m1"})
+
+	w := doEnvelopeTestRequest(t, router, "/v1/states", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(apiv2.ResponseHeaderAPIVersion))
+
+	var states []apiv1.HealthState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	assert.Len(t, states, 2)
+
+	w = doEnvelopeTestRequest(t, router, "/v1/states", "application/vnd.gpud.v1+json")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &states))
+	assert.Len(t, states, 2)
+}
+
+func TestEnvelopeV2(t *testing.T) {
+	node := apiv2.Node{MachineID: "m1", Hostname: "host1"}
+	router := newEnvelopeTestRouter(node)
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{name: "path prefix", path: "/v2/states"},
+		{name: "accept header", path: "/v1/states", accept: apiv2.MediaType},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doEnvelopeTestRequest(t, router, tc.path, tc.accept)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, apiv2.Version, w.Header().Get(apiv2.ResponseHeaderAPIVersion))
+
+			var env apiv2.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			assert.Equal(t, apiv2.Version, env.APIVersion)
+			assert.Equal(t, w.Header().Get("X-Request-ID"), env.RequestID)
+			assert.NotEmpty(t, env.RequestID)
+			assert.Equal(t, node, env.Node)
+			assert.Equal(t, version.Version, env.GPUdVersion)
+			assert.False(t, env.GeneratedAt.IsZero())
+			assert.Nil(t, env.Error)
+
+			require.NotNil(t, env.DataTimes)
+			assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), env.DataTimes.Oldest.UTC())
+			assert.Equal(t, time.Date(2025, 1, 2, 3, 5, 5, 0, time.UTC), env.DataTimes.Newest.UTC())
+
+			var states []apiv1.HealthState
+			require.NoError(t, json.Unmarshal(env.Data, &states))
+			assert.Len(t, states, 2)
+		})
+	}
+}
+
+func TestEnvelopeV2Error(t *testing.T) {
+	router := newEnvelopeTestRouter(apiv2.Node{})
+
+	w := doEnvelopeTestRequest(t, router, "/v2/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var env apiv2.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Empty(t, env.Data)
+	assert.Nil(t, env.DataTimes)
+	require.NotNil(t, env.Error)
+	assert.Equal(t, http.StatusNotFound, env.Error.Code)
+	assert.Equal(t, "component not found", env.Error.Message)
+	assert.JSONEq(t, `{"code":404,"message":"component not found"}`, string(env.Error.Details))
+}
+
+func TestEnvelopeV2NonJSONPassthrough(t *testing.T) {
+	router := newEnvelopeTestRouter(apiv2.Node{})
+
+	w := doEnvelopeTestRequest(t, router, "/v2/yaml", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "a: b\n", w.Body.String())
+}
+
+func TestEnvelopeUnsupportedVersion(t *testing.T) {
+	router := newEnvelopeTestRouter(apiv2.Node{})
+
+	for _, path := range []string{"/v1/states", "/v2/states"} {
+		w := doEnvelopeTestRequest(t, router, path, "application/vnd.gpud.v3+json")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code, path)
+	}
+}
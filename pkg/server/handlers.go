@@ -11,10 +11,25 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	pkgcapabilities "github.com/leptonai/gpud/pkg/capabilities"
+	"github.com/leptonai/gpud/pkg/changetrack"
 	gpudconfig "github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/drain"
+	"github.com/leptonai/gpud/pkg/driverupgrade"
 	"github.com/leptonai/gpud/pkg/errdefs"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/gossip"
+	"github.com/leptonai/gpud/pkg/gpuscore"
+	pkghealthstate "github.com/leptonai/gpud/pkg/healthstate"
+	"github.com/leptonai/gpud/pkg/jobs"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+	"github.com/leptonai/gpud/pkg/maintenance"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/mute"
+	"github.com/leptonai/gpud/pkg/session"
+	"github.com/leptonai/gpud/pkg/slo"
 )
 
 const (
@@ -30,11 +45,67 @@ type globalHandler struct {
 	componentNamesMu sync.RWMutex
 	componentNames   []string
 
+	// pluginSpecsMu serializes the bulk plugin spec applies
+	pluginSpecsMu sync.Mutex
+
 	metricsStore pkgmetrics.Store
 
 	gpudInstance *components.GPUdInstance
 
 	faultInjector pkgfaultinjector.Injector
+
+	// maintenanceManager manages the scheduled maintenance windows, nil if not set up
+	maintenanceManager *maintenance.Manager
+
+	// componentMutes manages the component mutes, nil if not set up
+	componentMutes *mute.Manager
+
+	// jobs runs the background jobs, nil if not set up
+	jobs *jobs.Manager
+
+	// syncStatusFunc returns the control plane upload backlog, nil if not set up
+	syncStatusFunc func() (session.SyncStatus, error)
+
+	// machineStates manages the machine state, nil if not set up
+	machineStates *machinestate.Manager
+
+	// eventDispositions records the operator dispositions on the events, nil if not set up
+	eventDispositions *disposition.Manager
+
+	// bootTracker records the host boots for the reboot history, nil if not set up
+	bootTracker *boottracker.Tracker
+
+	// healthStateStore persists the health states and transitions, nil if not set up
+	healthStateStore *pkghealthstate.Store
+
+	// capabilitiesDetector probes the data sources available on the host, nil if not set up
+	capabilitiesDetector *pkgcapabilities.Detector
+	// componentCapabilities maps the enabled component names to their required capabilities
+	componentCapabilities map[string][]string
+
+	// sloTracker tracks the latency objectives, nil if not set up
+	sloTracker *slo.Tracker
+
+	// drainChecker checks whether the node can be safely maintained, nil if not set up
+	drainChecker *drain.Checker
+
+	// gpuScorer computes the rolling performance scores of the GPUs, nil if not set up
+	gpuScorer *gpuscore.Scorer
+	// driverUpgrades revalidates the components after the driver upgrades, nil if not set up
+	driverUpgrades *driverupgrade.Watcher
+
+	// changes tracks the changes of the component data for the conditional requests,
+	// nil if not set up (always responds the full data)
+	changes *changetrack.Tracker
+
+	// gossipAgent exchanges the health summaries with the peers, nil if not enabled
+	gossipAgent *gossip.Agent
+
+	// startup tracks the background component initialization, nil if not set up
+	startup *startupTracker
+
+	// startTime is when the server started, used to report the uptime
+	startTime time.Time
 }
 
 func newGlobalHandler(cfg *gpudconfig.Config, componentsRegistry components.Registry, metricsStore pkgmetrics.Store, gpudInstance *components.GPUdInstance, faultInjector pkgfaultinjector.Injector) *globalHandler {
@@ -51,19 +122,34 @@ func newGlobalHandler(cfg *gpudconfig.Config, componentsRegistry components.Regi
 		metricsStore:       metricsStore,
 		gpudInstance:       gpudInstance,
 		faultInjector:      faultInjector,
+		startTime:          time.Now().UTC(),
+	}
+}
+
+// refreshComponentNames reloads the component names from the registry,
+// after the components are registered or deregistered.
+func (g *globalHandler) refreshComponentNames() {
+	var componentNames []string
+	for _, c := range g.componentsRegistry.All() {
+		componentNames = append(componentNames, c.Name())
 	}
+	sort.Strings(componentNames)
+
+	g.componentNamesMu.Lock()
+	g.componentNames = componentNames
+	g.componentNamesMu.Unlock()
 }
 
 func (g *globalHandler) getReqTime(c *gin.Context) (time.Time, time.Time, error) {
-	startTime := time.Now()
-	endTime := time.Now()
+	startTime := time.Now().UTC()
+	endTime := time.Now().UTC()
 	startTimeStr := c.Query("startTime")
 	if startTimeStr != "" {
 		startTimeInt, err := strconv.ParseInt(startTimeStr, 10, 64)
 		if err != nil {
 			return time.Time{}, time.Time{}, err
 		}
-		startTime = time.Unix(startTimeInt, 0)
+		startTime = time.Unix(startTimeInt, 0).UTC()
 	}
 	endTimeStr := c.Query("endTime")
 	if endTimeStr != "" {
@@ -71,7 +157,7 @@ func (g *globalHandler) getReqTime(c *gin.Context) (time.Time, time.Time, error)
 		if err != nil {
 			return time.Time{}, time.Time{}, err
 		}
-		endTime = time.Unix(endTimeInt, 0)
+		endTime = time.Unix(endTimeInt, 0).UTC()
 	}
 	return startTime, endTime, nil
 }
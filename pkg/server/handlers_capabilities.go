@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+// URLPathCapabilities is for getting the data sources available on the host
+const URLPathCapabilities = "/capabilities"
+
+func (g *globalHandler) registerCapabilitiesRoutes(r gin.IRoutes) {
+	r.GET(URLPathCapabilities, g.getCapabilities)
+}
+
+// getCapabilities godoc
+// @Summary Get the host capabilities
+// @Description Probes the data sources available on the host (NVML, nvidia-smi, ibstat, DCGM, ipmitool, kmsg access), and returns them with the reasons if not available, along with the capabilities each enabled component is running with or degraded without. The health states of the components are annotated with the same capabilities in the "capabilities" and "degraded_without" extra info.
+// @ID getCapabilities
+// @Tags status
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.CapabilitiesReport "Capabilities report"
+// @Failure 404 {object} map[string]interface{} "Capabilities detection not set up"
+// @Router /v1/capabilities [get]
+func (g *globalHandler) getCapabilities(c *gin.Context) {
+	if g.capabilitiesDetector == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "capabilities detection not set up"})
+		return
+	}
+
+	// re-probe on demand, e.g., after installing the missing tools
+	_ = g.capabilitiesDetector.Probe()
+	report := g.capabilitiesDetector.Report(g.componentCapabilities)
+
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
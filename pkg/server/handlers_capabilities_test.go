@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgcapabilities "github.com/leptonai/gpud/pkg/capabilities"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerCapabilitiesRoutes(v1)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/capabilities", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// without the NVML instance
+	handler.capabilitiesDetector = pkgcapabilities.NewDetector(nil)
+	handler.componentCapabilities = map[string][]string{
+		"accelerator-nvidia-error-xid": {pkgcapabilities.NVML, pkgcapabilities.Kmsg},
+		"cpu":                          nil,
+	}
+
+	w = get()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report apiv1.CapabilitiesReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.False(t, report.Time.IsZero())
+	require.Len(t, report.Capabilities, 6)
+	assert.Equal(t, pkgcapabilities.NVML, report.Capabilities[0].Name)
+	assert.False(t, report.Capabilities[0].Available)
+	assert.NotEmpty(t, report.Capabilities[0].Reason)
+
+	require.Len(t, report.Components, 1)
+	assert.Equal(t, "accelerator-nvidia-error-xid", report.Components[0].Component)
+	assert.Contains(t, report.Components[0].DegradedWithout, pkgcapabilities.NVML)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+// URLPathClusterSummary is for getting the health of the peers via the gossip
+const URLPathClusterSummary = "/cluster/summary"
+
+func (g *globalHandler) registerClusterRoutes(r gin.IRoutes) {
+	r.GET(URLPathClusterSummary, g.getClusterSummary)
+}
+
+// getClusterSummary godoc
+// @Summary Get the cluster health summary
+// @Description Returns the health summaries of the local node and the peers exchanged via the peer-to-peer gossip, with the peers that are unhealthy or unreachable, without the control plane. Requires the gossip to be enabled.
+// @ID getClusterSummary
+// @Tags status
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.ClusterSummary "Cluster health summary"
+// @Failure 404 {object} map[string]interface{} "Gossip not enabled"
+// @Router /v1/cluster/summary [get]
+func (g *globalHandler) getClusterSummary(c *gin.Context) {
+	if g.gossipAgent == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gossip not enabled"})
+		return
+	}
+
+	summary := g.gossipAgent.Summary()
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, summary)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/gossip"
+)
+
+func TestGetClusterSummary(t *testing.T) {
+	degraded := &mockComponent{name: "disk", isSupported: true, healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded, Reason: "disk almost full"}}}
+
+	handler, _, _ := setupTestHandler([]components.Component{degraded})
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerClusterRoutes(v1)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/cluster/summary", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	agent, err := gossip.New(context.Background(), gossip.Config{BindAddr: "127.0.0.1", BindPort: -1, AdvertiseAddr: "127.0.0.1", SecretKey: base64.StdEncoding.EncodeToString(make([]byte, 32))}, "node-a", "host-a", handler.healthSummary)
+	require.NoError(t, err)
+	defer func() {
+		_ = agent.Close()
+	}()
+	handler.gossipAgent = agent
+
+	w = get()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var summary apiv1.ClusterSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, "node-a", summary.Self.MachineID)
+	assert.Equal(t, "host-a", summary.Self.Hostname)
+	assert.Equal(t, apiv1.HealthVerdictDegraded, summary.Self.Summary.Verdict)
+	assert.Empty(t, summary.Peers)
+	assert.Empty(t, summary.Unhealthy)
+}
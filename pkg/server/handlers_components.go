@@ -1,19 +1,26 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/changetrack"
 	"github.com/leptonai/gpud/pkg/errdefs"
 	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 func (g *globalHandler) registerComponentRoutes(r gin.IRoutes) {
@@ -22,11 +29,14 @@ func (g *globalHandler) registerComponentRoutes(r gin.IRoutes) {
 
 	r.GET(URLPathComponentsTriggerCheck, g.triggerComponentCheck)
 	r.GET(URLPathComponentsTriggerTag, g.triggerComponentsByTag)
+	r.GET(URLPathComponentsLatency, g.getComponentsLatency)
 
 	r.GET(URLPathStates, g.getHealthStates)
 	r.GET(URLPathEvents, g.getEvents)
 	r.GET(URLPathInfo, g.getInfo)
 	r.GET(URLPathMetrics, g.getMetrics)
+	r.GET(URLPathMetricsMetadata, g.getMetricsMetadata)
+	r.GET(URLPathReasonCodes, g.getReasonCodes)
 
 	r.POST(URLPathHealthStatesSetHealthy, g.setHealthyStates)
 }
@@ -126,20 +136,36 @@ func (g *globalHandler) deregisterComponent(c *gin.Context) {
 
 const URLPathComponentsTriggerCheck = "/components/trigger-check"
 
+const (
+	// DefaultTriggerChecksTimeout is the default timeout of the component checks triggered in a batch.
+	DefaultTriggerChecksTimeout = time.Minute
+	// MaxTriggerChecksTimeout is the maximum timeout of the component checks triggered in a batch.
+	MaxTriggerChecksTimeout = 10 * time.Minute
+)
+
 // triggerComponentCheck godoc
 // @Summary Trigger component health check
 // @Description Triggers a health check for a specific component or all components with a specific tag. Either componentName or tagName must be provided, but not both.
+// @Description To trigger multiple components in one call, set the comma-separated component names ("components") and/or tag names ("tags") instead, and the checks run concurrently within the unified timeout, with the results keyed by the component name.
 // @ID triggerComponentCheck
 // @Tags components
 // @Accept json
 // @Produce json
 // @Param componentName query string false "Name of the specific component to check (mutually exclusive with tagName)"
 // @Param tagName query string false "Tag name to check all components with this tag (mutually exclusive with componentName)"
-// @Success 200 {object} apiv1.GPUdComponentHealthStates "Health check results with component states"
-// @Failure 400 {object} map[string]interface{} "Bad request - component or tag name required (but not both)"
+// @Param components query string false "Comma-separated component names to check concurrently, with the results keyed by the component name"
+// @Param tags query string false "Comma-separated tag names to check all components with any of the tags concurrently, with the results keyed by the component name"
+// @Param timeout query string false "Timeout of the concurrent checks (e.g., 30s), defaults to 1 minute, at most 10 minutes"
+// @Success 200 {object} apiv1.GPUdComponentHealthStates "Health check results with component states (apiv1.ComponentCheckResults keyed by the component name, if components or tags are set)"
+// @Failure 400 {object} map[string]interface{} "Bad request - component or tag name required (but not both), or invalid timeout"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Router /v1/components/trigger-check [get]
 func (g *globalHandler) triggerComponentCheck(c *gin.Context) {
+	if c.Query("components") != "" || c.Query("tags") != "" {
+		g.triggerComponentChecks(c)
+		return
+	}
+
 	componentName := c.Query("componentName")
 	tagName := c.Query("tagName")
 
@@ -157,7 +183,7 @@ func (g *globalHandler) triggerComponentCheck(c *gin.Context) {
 			return
 		}
 
-		checkResults = append(checkResults, comp.Check())
+		checkResults = append(checkResults, triggerCheck(c, comp))
 	} else if tagName != "" {
 		components := g.componentsRegistry.All()
 		for _, comp := range components {
@@ -172,7 +198,7 @@ func (g *globalHandler) triggerComponentCheck(c *gin.Context) {
 				continue
 			}
 
-			checkResults = append(checkResults, comp.Check())
+			checkResults = append(checkResults, triggerCheck(c, comp))
 		}
 	}
 
@@ -186,6 +212,134 @@ func (g *globalHandler) triggerComponentCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// triggerComponentChecks triggers the checks of the components selected by the names
+// and the tags concurrently, and responds with the results keyed by the component name.
+// The checks not completed within the timeout are reported with their last health states,
+// and keep running in the background.
+func (g *globalHandler) triggerComponentChecks(c *gin.Context) {
+	timeout := DefaultTriggerChecksTimeout
+	if s := c.Query("timeout"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 || d > MaxTriggerChecksTimeout {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": fmt.Sprintf("invalid timeout %q (must be a positive duration up to %s)", s, MaxTriggerChecksTimeout)})
+			return
+		}
+		timeout = d
+	}
+
+	selected := make(map[string]components.Component)
+	for _, name := range splitQueryList(c.Query("components")) {
+		comp := g.componentsRegistry.Get(name)
+		if comp == nil {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + name})
+			return
+		}
+		selected[name] = comp
+	}
+	if tags := splitQueryList(c.Query("tags")); len(tags) > 0 {
+		for _, comp := range g.componentsRegistry.All() {
+			for _, tag := range comp.Tags() {
+				if slices.Contains(tags, tag) {
+					selected[comp.Name()] = comp
+					break
+				}
+			}
+		}
+	}
+
+	comps := make([]components.Component, 0, len(selected))
+	for _, comp := range selected {
+		comps = append(comps, comp)
+	}
+	c.JSON(http.StatusOK, triggerChecks(requestid.Get(c), comps, timeout))
+}
+
+// splitQueryList returns the non-empty values of the comma-separated query parameter.
+func splitQueryList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// triggerChecks runs the component checks concurrently, and returns the results
+// of the checks completed within the timeout, and the last health states of the others.
+func triggerChecks(reqID string, comps []components.Component, timeout time.Duration) apiv1.ComponentCheckResults {
+	type completed struct {
+		comp    components.Component
+		result  components.CheckResult
+		elapsed time.Duration
+	}
+	// buffered, so that the checks completed after the timeout do not block
+	completedCh := make(chan completed, len(comps))
+	for _, comp := range comps {
+		go func() {
+			start := time.Now()
+			result := runTriggeredCheck(reqID, comp)
+			completedCh <- completed{comp: comp, result: result, elapsed: time.Since(start)}
+		}()
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	results := make(apiv1.ComponentCheckResults, len(comps))
+	timedOut := false
+	for pending := len(comps); pending > 0 && !timedOut; pending-- {
+		select {
+		case done := <-completedCh:
+			states := done.comp.LastHealthStates()
+			if done.result != nil {
+				states = done.result.HealthStates()
+			}
+			results[done.comp.Name()] = apiv1.ComponentCheckResult{
+				Component: done.comp.Name(),
+				States:    states,
+				Elapsed:   metav1.Duration{Duration: done.elapsed},
+			}
+		case <-timer.C:
+			timedOut = true
+		}
+	}
+
+	for _, comp := range comps {
+		if _, ok := results[comp.Name()]; ok {
+			continue
+		}
+		log.Logger.Warnw("triggered component check timed out", fieldRequestID, reqID, "component", comp.Name(), "timeout", timeout)
+		results[comp.Name()] = apiv1.ComponentCheckResult{
+			Component: comp.Name(),
+			States:    comp.LastHealthStates(),
+			TimedOut:  true,
+			Elapsed:   metav1.Duration{Duration: timeout},
+		}
+	}
+	return results
+}
+
+// triggerCheck runs the component check triggered by the request (e.g., running the plugin),
+// logged with the request ID to correlate with the access log of the request.
+func triggerCheck(c *gin.Context, comp components.Component) components.CheckResult {
+	return runTriggeredCheck(requestid.Get(c), comp)
+}
+
+// runTriggeredCheck runs the component check triggered by the request of the ID,
+// safe to call after the request handler returned.
+func runTriggeredCheck(reqID string, comp components.Component) components.CheckResult {
+	start := time.Now()
+	result := comp.Check()
+
+	var health apiv1.HealthStateType
+	if result != nil {
+		health = result.HealthStateType()
+	}
+	log.Logger.Infow("triggered component check", fieldRequestID, reqID, "component", comp.Name(), "health", health, "latency", time.Since(start))
+	return result
+}
+
 // URLPathComponentsTriggerTag is for triggering components by tag
 const URLPathComponentsTriggerTag = "/components/trigger-tag"
 
@@ -221,7 +375,7 @@ func (g *globalHandler) triggerComponentsByTag(c *gin.Context) {
 		for _, tag := range tags {
 			if tag == tagName {
 				triggeredComponents = append(triggeredComponents, comp.Name())
-				if result := comp.Check(); result != nil && result.HealthStateType() != apiv1.HealthStateTypeHealthy {
+				if result := triggerCheck(c, comp); result != nil && result.HealthStateType() != apiv1.HealthStateTypeHealthy {
 					success = false
 					exitStatus = 1
 				}
@@ -251,7 +405,10 @@ const URLPathStates = "/states"
 // @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
 // @Param components query string false "Comma-separated list of component names to query (if empty, returns all components)"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param If-None-Match header string false "ETag of the previous response, responds 304 if the data is not changed"
+// @Param If-Modified-Since header string false "Last-Modified of the previous response, responds 304 if the data is not changed"
 // @Success 200 {object} apiv1.GPUdComponentHealthStates "Component health states"
+// @Success 304 "Not modified since the previous response"
 // @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -268,6 +425,10 @@ func (g *globalHandler) getHealthStates(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
 		return
 	}
+	g.observeHealthStates(components)
+	if g.checkNotModified(c, components, changetrack.KindStates) {
+		return
+	}
 	for _, componentName := range components {
 		currState := apiv1.ComponentHealthStates{
 			Component: componentName,
@@ -293,6 +454,7 @@ func (g *globalHandler) getHealthStates(c *gin.Context) {
 
 		log.Logger.Debugw("successfully got states", "component", componentName)
 		currState.States = state
+		setHealthStatesDataTimes(c, state)
 
 		states = append(states, currState)
 	}
@@ -334,7 +496,10 @@ const URLPathEvents = "/events"
 // @Param startTime query string false "Start time for event query (RFC3339 format, defaults to current time)"
 // @Param endTime query string false "End time for event query (RFC3339 format, defaults to current time)"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param If-None-Match header string false "ETag of the previous response, responds 304 if the data is not changed"
+// @Param If-Modified-Since header string false "Last-Modified of the previous response, responds 304 if the data is not changed"
 // @Success 200 {object} apiv1.GPUdComponentEvents "Component events within the specified time range"
+// @Success 304 "Not modified since the previous response"
 // @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, or time parsing error"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -356,6 +521,9 @@ func (g *globalHandler) getEvents(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse time: " + err.Error()})
 		return
 	}
+	if g.checkNotModified(c, components, changetrack.KindEvents) {
+		return
+	}
 	for _, componentName := range components {
 		currEvent := apiv1.ComponentEvents{
 			Component: componentName,
@@ -431,7 +599,10 @@ const URLPathInfo = "/info"
 // @Param endTime query string false "End time for query (RFC3339 format, defaults to current time)"
 // @Param since query string false "Duration string for metrics query (e.g., '30m', '1h') - defaults to 30 minutes"
 // @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param If-None-Match header string false "ETag of the previous response, responds 304 if the data is not changed"
+// @Param If-Modified-Since header string false "Last-Modified of the previous response, responds 304 if the data is not changed"
 // @Success 200 {object} apiv1.GPUdComponentInfos "Component information including events, states, and metrics"
+// @Success 304 "Not modified since the previous response"
 // @Failure 400 {object} map[string]interface{} "Bad request - invalid content type, component parsing error, time parsing error, or duration parsing error"
 // @Failure 404 {object} map[string]interface{} "Component not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -465,6 +636,11 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 		metricsSince = now.Add(-dur)
 	}
 
+	g.observeHealthStates(reqComps)
+	if g.checkNotModified(c, reqComps, changetrack.KindStates, changetrack.KindEvents, changetrack.KindMetrics) {
+		return
+	}
+
 	metricsData, err := g.metricsStore.Read(c, pkgmetrics.WithSince(metricsSince), pkgmetrics.WithComponents(reqComps...))
 	if err != nil {
 		log.Logger.Errorw("failed to invoke component metrics",
@@ -476,6 +652,7 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 
 	componentsToMetrics := make(map[string][]apiv1.Metric)
 	for _, data := range metricsData {
+		setDataTime(c, time.UnixMilli(data.UnixMilliseconds))
 		if _, ok := componentsToMetrics[data.Component]; !ok {
 			componentsToMetrics[data.Component] = make([]apiv1.Metric, 0)
 		}
@@ -484,6 +661,7 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 			Name:        data.Name,
 			Labels:      data.Labels,
 			Value:       data.Value,
+			Unit:        pkgmetrics.GetUnit(data.Name),
 		}
 		componentsToMetrics[data.Component] = append(componentsToMetrics[data.Component], d)
 	}
@@ -524,6 +702,7 @@ func (g *globalHandler) getInfo(c *gin.Context) {
 
 		state := comp.LastHealthStates()
 		currInfo.Info.States = state
+		setHealthStatesDataTimes(c, state)
 
 		currInfo.Info.Metrics = componentsToMetrics[componentName]
 
@@ -600,6 +779,9 @@ func (g *globalHandler) getMetrics(c *gin.Context) {
 		return
 	}
 
+	for _, data := range metricsData {
+		setDataTime(c, time.UnixMilli(data.UnixMilliseconds))
+	}
 	metrics := pkgmetrics.ConvertToLeptonMetrics(metricsData)
 	switch c.GetHeader(httputil.RequestHeaderContentType) {
 	case httputil.RequestHeaderYAML:
@@ -622,6 +804,124 @@ func (g *globalHandler) getMetrics(c *gin.Context) {
 	}
 }
 
+// URLPathMetricsMetadata is for getting the metadata (unit, type, description) of the metrics
+const URLPathMetricsMetadata = "/metrics/metadata"
+
+// getMetricsMetadata godoc
+// @Summary Get metric metadata
+// @Description Returns the name, unit, type (gauge or counter), and description of the metrics reported by the specified components. If no components specified, returns the metadata for all components.
+// @ID getMetricsMetadata
+// @Tags components
+// @Accept json
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.MetricsMetadata "Metric metadata of the components"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to list metric metadata"
+// @Router /v1/metrics/metadata [get]
+func (g *globalHandler) getMetricsMetadata(c *gin.Context) {
+	components, err := g.getReqComponentNames(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	metadata, err := pkgmetrics.ListMetadata(pkgmetrics.WithComponents(components...))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to list metric metadata: " + err.Error()})
+		return
+	}
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(metadata)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal metric metadata " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, metadata)
+			return
+		}
+		c.JSON(http.StatusOK, metadata)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
+// URLPathReasonCodes is for getting the registry of the reason codes in the health states and events
+const URLPathReasonCodes = "/reason-codes"
+
+// getReasonCodes godoc
+// @Summary Get reason code registry
+// @Description Returns the machine-readable reason codes reported in the health states and events of the specified components, with the codes shared by all components. If no components specified, returns the codes of all components.
+// @ID getReasonCodes
+// @Tags components
+// @Accept json
+// @Produce json
+// @Header 200 {string} Content-Type "application/json or application/yaml"
+// @Param Accept header string false "Content type preference" Enums(application/json,application/yaml)
+// @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} apiv1.ReasonCodesMetadata "Reason codes of the components"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid content type or component parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/reason-codes [get]
+func (g *globalHandler) getReasonCodes(c *gin.Context) {
+	var components []string
+	if c.Query("components") != "" {
+		var err error
+		components, err = g.getReqComponentNames(c)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+			return
+		}
+	}
+
+	// all the registered codes if no component is specified,
+	// including the ones of the components not enabled on this host
+	codes := reasoncode.List(components...)
+
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML:
+		yb, err := yaml.Marshal(codes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal reason codes " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case httputil.RequestHeaderJSON, "":
+		if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, codes)
+			return
+		}
+		c.JSON(http.StatusOK, codes)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
 // URLPathHealthStatesSetHealthy is for setting components to healthy state
 const URLPathHealthStatesSetHealthy = "/health-states/set-healthy"
 
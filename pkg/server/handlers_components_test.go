@@ -22,6 +22,7 @@ import (
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	"github.com/leptonai/gpud/pkg/httputil"
 	"github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/reasoncode"
 )
 
 // mockComponent is a simplified component implementation for testing
@@ -1158,6 +1159,110 @@ func TestGetMetricsStoreError(t *testing.T) {
 	assert.Contains(t, response["message"], "failed to read metrics")
 }
 
+func TestGetMetricsMetadata(t *testing.T) {
+	metrics.MustRegisterMetadata("metadata-comp",
+		apiv1.MetricMetadata{Name: "metadata_comp_used_bytes", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitBytes},
+	)
+	metrics.MustRegisterMetadata("metadata-other-comp",
+		apiv1.MetricMetadata{Name: "metadata_other_comp_used_percent", Type: apiv1.MetricTypeGauge, Unit: apiv1.MetricUnitPercent},
+	)
+
+	handler, _, store := setupTestHandler([]components.Component{
+		&mockComponent{name: "metadata-comp", isSupported: true},
+		&mockComponent{name: "metadata-other-comp", isSupported: true},
+	})
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics/metadata?components=metadata-comp", nil)
+	handler.getMetricsMetadata(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var mds apiv1.MetricsMetadata
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mds))
+	assert.Equal(t, apiv1.MetricsMetadata{{
+		Name:      "metadata_comp_used_bytes",
+		Component: "metadata-comp",
+		Type:      apiv1.MetricTypeGauge,
+		Unit:      apiv1.MetricUnitBytes,
+	}}, mds)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics/metadata", nil)
+	handler.getMetricsMetadata(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mds))
+	assert.Len(t, mds, 2)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics/metadata?components=unknown", nil)
+	handler.getMetricsMetadata(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// the metrics carry the registered unit
+	store.metrics = []metrics.Metric{
+		{UnixMilliseconds: 1234567890000, Component: "metadata-comp", Name: "metadata_comp_used_bytes", Value: 42},
+	}
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/metrics?components=metadata-comp", nil)
+	handler.getMetrics(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var ms apiv1.GPUdComponentMetrics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ms))
+	require.Len(t, ms, 1)
+	require.Len(t, ms[0].Metrics, 1)
+	assert.Equal(t, apiv1.MetricUnitBytes, ms[0].Metrics[0].Unit)
+}
+
+func TestGetReasonCodes(t *testing.T) {
+	reasoncode.MustRegister("reason-code-comp",
+		apiv1.ReasonCodeMetadata{Code: "REASON_CODE_COMP_FAILED", Health: apiv1.HealthStateTypeUnhealthy},
+	)
+	reasoncode.MustRegister("reason-code-other-comp",
+		apiv1.ReasonCodeMetadata{Code: "REASON_CODE_OTHER_COMP_FAILED", Health: apiv1.HealthStateTypeUnhealthy},
+	)
+
+	handler, _, _ := setupTestHandler([]components.Component{
+		&mockComponent{name: "reason-code-comp", isSupported: true},
+		&mockComponent{name: "reason-code-other-comp", isSupported: true},
+	})
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/reason-codes?components=reason-code-comp", nil)
+	handler.getReasonCodes(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var codes apiv1.ReasonCodesMetadata
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codes))
+	found := map[apiv1.ReasonCode]string{}
+	for _, md := range codes {
+		found[md.Code] = md.Component
+	}
+	assert.Equal(t, "reason-code-comp", found["REASON_CODE_COMP_FAILED"])
+	assert.NotContains(t, found, apiv1.ReasonCode("REASON_CODE_OTHER_COMP_FAILED"))
+	// the shared codes are always included
+	assert.Contains(t, found, apiv1.ReasonCodeGPULost)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/reason-codes", nil)
+	handler.getReasonCodes(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codes))
+	assert.Len(t, codes, len(reasoncode.List()))
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/reason-codes?components=unknown", nil)
+	handler.getReasonCodes(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest("GET", "/v1/reason-codes", nil)
+	c.Request.Header.Set(httputil.RequestHeaderContentType, httputil.RequestHeaderYAML)
+	handler.getReasonCodes(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "code: GPU_LOST")
+}
+
 func TestSetHealthyStates(t *testing.T) {
 	tests := []struct {
 		name                      string
@@ -1382,3 +1487,90 @@ type mockHealthSettableComponent struct {
 func (m *mockHealthSettableComponent) SetHealthy() error {
 	return m.setHealthyError
 }
+
+// blockingComponent blocks the check until released.
+type blockingComponent struct {
+	*mockComponent
+	release chan struct{}
+}
+
+func (b *blockingComponent) Check() components.CheckResult {
+	<-b.release
+	return b.mockComponent.Check()
+}
+
+func TestTriggerComponentChecks(t *testing.T) {
+	newComp := func(name string, health apiv1.HealthStateType, tags ...string) *mockComponent {
+		return &mockComponent{
+			name:        name,
+			tags:        tags,
+			isSupported: true,
+			checkResult: &mockCheckResult{
+				componentName:   name,
+				healthStateType: health,
+				healthStates:    apiv1.HealthStates{{Component: name, Health: health}},
+			},
+			healthStates: apiv1.HealthStates{{Component: name, Health: apiv1.HealthStateTypeInitializing}},
+		}
+	}
+	slow := &blockingComponent{mockComponent: newComp("slow", apiv1.HealthStateTypeHealthy, "nvidia"), release: make(chan struct{})}
+	defer close(slow.release)
+
+	handler, _, _ := setupTestHandler([]components.Component{
+		newComp("comp1", apiv1.HealthStateTypeHealthy),
+		newComp("comp2", apiv1.HealthStateTypeUnhealthy, "nvidia"),
+		newComp("comp3", apiv1.HealthStateTypeHealthy, "other"),
+		slow,
+	})
+
+	trigger := func(t *testing.T, query string) (int, apiv1.ComponentCheckResults) {
+		_, c, w := setupTestRouter()
+		c.Request = httptest.NewRequest("GET", "/v1/components/trigger-check?"+query, nil)
+		handler.triggerComponentCheck(c)
+
+		var results apiv1.ComponentCheckResults
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+		}
+		return w.Code, results
+	}
+
+	t.Run("components", func(t *testing.T) {
+		code, results := trigger(t, "components=comp1,comp2")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, results, 2)
+		assert.Equal(t, apiv1.HealthStateTypeHealthy, results["comp1"].States[0].Health)
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, results["comp2"].States[0].Health)
+		assert.False(t, results["comp2"].TimedOut)
+	})
+
+	t.Run("components and tags with timeout", func(t *testing.T) {
+		code, results := trigger(t, "components=comp1&tags=nvidia&timeout=100ms")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, results, 3)
+		assert.Equal(t, apiv1.HealthStateTypeUnhealthy, results["comp2"].States[0].Health)
+
+		// the slow check is reported with its last health states
+		assert.True(t, results["slow"].TimedOut)
+		assert.Equal(t, 100*time.Millisecond, results["slow"].Elapsed.Duration)
+		assert.Equal(t, apiv1.HealthStateTypeInitializing, results["slow"].States[0].Health)
+	})
+
+	t.Run("tag not matched", func(t *testing.T) {
+		code, results := trigger(t, "tags=nonexistent")
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, results)
+	})
+
+	t.Run("component not found", func(t *testing.T) {
+		code, _ := trigger(t, "components=comp1,nonexistent")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	for _, timeout := range []string{"invalid", "-1s", "0s", "11m"} {
+		t.Run("invalid timeout "+timeout, func(t *testing.T) {
+			code, _ := trigger(t, "components=comp1&timeout="+timeout)
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/changetrack"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+// checkNotModified sets the validators (the ETag and the Last-Modified headers)
+// of the kinds of the data of the components, and returns true after responding
+// 304 if the request is conditional on the unchanged data, so that the polling
+// requests skip reading and serializing the data.
+// The "If-None-Match" header takes precedence over the "If-Modified-Since" header.
+func (g *globalHandler) checkNotModified(c *gin.Context, componentNames []string, kinds ...changetrack.Kind) bool {
+	if g.changes == nil {
+		return false
+	}
+	switch c.GetHeader(httputil.RequestHeaderContentType) {
+	case httputil.RequestHeaderYAML, httputil.RequestHeaderJSON, "":
+	default:
+		// responds the invalid content type error without the validators
+		return false
+	}
+
+	v := g.changes.Version(responseVariant(c), componentNames, kinds...)
+	etag := `"` + v.Token + `"`
+	// weak, as the response may differ by the default time range
+	// (e.g., the start time defaults to the current time)
+	c.Header("ETag", "W/"+etag)
+	c.Header("Cache-Control", "no-cache")
+
+	nowSec := time.Now().UTC().Truncate(time.Second)
+	lastModified := v.LastModified.UTC().Truncate(time.Second)
+
+	// the changes later in the current second would share the same
+	// last modified time, thus only sent once the second has passed
+	if lastModified.Before(nowSec) {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		notModified = etagMatches(ifNoneMatch, etag)
+	} else if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" {
+		t, err := http.ParseTime(ifModifiedSince)
+		notModified = err == nil && !lastModified.After(t) && t.Before(nowSec)
+	}
+	if notModified {
+		c.Status(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// responseVariant returns the variant of the response by the request,
+// as the different paths, queries, or formats of the same data
+// must not share the validators (e.g., the v1 route responding
+// the v2 envelope if requested by the "Accept" header).
+func responseVariant(c *gin.Context) string {
+	return c.Request.URL.Path + "?" + c.Request.URL.RawQuery +
+		"\x00" + c.GetHeader(httputil.RequestHeaderContentType) +
+		"\x00" + c.GetHeader(httputil.RequestHeaderJSONIndent) +
+		"\x00" + c.GetHeader("Accept")
+}
+
+// observeHealthStates records the changes of the current health states
+// of the supported components.
+func (g *globalHandler) observeHealthStates(componentNames []string) {
+	if g.changes == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, name := range componentNames {
+		comp := g.componentsRegistry.Get(name)
+		if comp == nil || !comp.IsSupported() {
+			continue
+		}
+		g.changes.ObserveStates(name, comp.LastHealthStates(), now)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/changetrack"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+func TestGetHealthStatesConditional(t *testing.T) {
+	comp := &mockComponent{
+		name:         "comp1",
+		isSupported:  true,
+		healthStates: apiv1.HealthStates{{Component: "comp1", Health: apiv1.HealthStateTypeHealthy}},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+	handler.changes = changetrack.New()
+
+	router := gin.New()
+	router.GET("/v1/states", handler.getHealthStates)
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/states?components=comp1", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = get("If-None-Match", `"other"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the changed health states are responded again
+	comp.healthStates = apiv1.HealthStates{{Component: "comp1", Health: apiv1.HealthStateTypeUnhealthy}}
+	w = get("If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// the v2 envelope requested on the v1 route does not share the validators
+	etag = get("", "").Header().Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/v1/states?components=comp1", nil)
+	req.Header.Set("Accept", apiv2.MediaType)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// the last modified time is not yet sent within the same second of the change
+	w = get("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the invalid content type is not answered as not modified
+	w = get(httputil.RequestHeaderContentType, "text/plain")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestGetEventsConditional(t *testing.T) {
+	comp := &mockComponent{
+		name:        "comp1",
+		isSupported: true,
+		events:      apiv1.Events{{Component: "comp1", Name: "test", Type: apiv1.EventTypeWarning}},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+
+	router := gin.New()
+	router.GET("/v1/events", handler.getEvents)
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// no validators without the tracker
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	handler.changes = changetrack.New()
+	w = get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	assert.Equal(t, http.StatusNotModified, get(etag).Code)
+
+	handler.changes.Touch("comp1", changetrack.KindEvents, time.Now())
+	assert.Equal(t, http.StatusOK, get(etag).Code)
+}
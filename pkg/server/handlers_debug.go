@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/jobs"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/session"
+	sessionstates "github.com/leptonai/gpud/pkg/session/states"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/version"
+)
+
+// URLPathDebugSnapshot is for getting the complete internal state in one document
+const URLPathDebugSnapshot = "/debug/snapshot"
+
+// defaultDebugSnapshotEventsLookback is how far back to include the component events.
+const defaultDebugSnapshotEventsLookback = time.Hour
+
+// DebugSnapshot is the complete internal state of the GPUd daemon,
+// with every list sorted so that the same state renders the same document.
+type DebugSnapshot struct {
+	GPUdVersion string `json:"gpud_version"`
+	MachineID   string `json:"machine_id,omitempty"`
+	// Time is when the snapshot was taken, omitted if stable.
+	Time *metav1.Time `json:"time,omitempty"`
+
+	// StartupState is the startup state ("starting", "ready", or "failed").
+	StartupState apiv1.StartupState `json:"startup_state"`
+	// Components are the registered components, sorted by the name.
+	Components []DebugComponent `json:"components"`
+
+	// Session is the latest control plane session state, nil if none recorded.
+	Session *apiv1.SessionStatus `json:"session,omitempty"`
+	// Sync is the upload backlog of the events and metrics pending to the control plane,
+	// nil if the session upload is not enabled.
+	Sync *session.SyncStatus `json:"sync,omitempty"`
+
+	// Jobs are the background jobs, sorted by the type and the ID.
+	Jobs []jobs.Job `json:"jobs,omitempty"`
+}
+
+// DebugComponent is the internal state of a registered component.
+type DebugComponent struct {
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags,omitempty"`
+	Supported bool     `json:"supported"`
+
+	// Schedule is the periodic check schedule, nil if not started.
+	Schedule *components.CheckSchedule `json:"schedule,omitempty"`
+	// HealthStates are the results of the last check, sorted by the name.
+	HealthStates apiv1.HealthStates `json:"health_states,omitempty"`
+	// Events are the events within the lookback, sorted by the time, name, and message.
+	Events apiv1.Events `json:"events,omitempty"`
+}
+
+func (g *globalHandler) registerDebugRoutes(r gin.IRoutes) {
+	r.GET(URLPathDebugSnapshot, g.getDebugSnapshot)
+}
+
+// getDebugSnapshot godoc
+// @Summary Get the deterministic snapshot of the internal state
+// @Description Returns the complete internal state of the GPUd daemon in one document: the registered components with their check schedules, last check results, and recent events, the control plane session state, the upload backlog, and the background jobs. Every list is sorted, and with "stable=true" the timestamps and durations are cleared, so that the same state renders the same document (e.g., for the golden-file tests). Only served if enabled with "--debug-snapshot".
+// @ID getDebugSnapshot
+// @Tags debug
+// @Produce json
+// @Param stable query bool false "Clear the timestamps and durations for the golden-file comparison"
+// @Param events_lookback query string false "How far back to include the events (e.g., 30m), defaults to 1h, 0 to skip"
+// @Success 200 {object} DebugSnapshot "Internal state snapshot"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Router /v1/debug/snapshot [get]
+func (g *globalHandler) getDebugSnapshot(c *gin.Context) {
+	stable := false
+	if s := c.Query("stable"); s != "" {
+		var err error
+		stable, err = strconv.ParseBool(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid stable: " + err.Error()})
+			return
+		}
+	}
+	lookback := defaultDebugSnapshotEventsLookback
+	if s := c.Query("events_lookback"); s != "" {
+		var err error
+		lookback, err = time.ParseDuration(s)
+		if err != nil || lookback < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid events_lookback: " + s})
+			return
+		}
+	}
+
+	c.IndentedJSON(http.StatusOK, g.debugSnapshot(c, time.Now().UTC(), lookback, stable))
+}
+
+// debugSnapshot collects the internal state.
+// Each field is best-effort, a failure to read one field is logged
+// and does not fail the whole snapshot.
+func (g *globalHandler) debugSnapshot(ctx context.Context, now time.Time, eventsLookback time.Duration, stable bool) DebugSnapshot {
+	snap := DebugSnapshot{
+		GPUdVersion:  version.Version,
+		StartupState: apiv1.StartupStateReady,
+		Components:   []DebugComponent{},
+	}
+	if g.gpudInstance != nil {
+		snap.MachineID = g.gpudInstance.MachineID
+	}
+	if !stable {
+		t := metav1.NewTime(now)
+		snap.Time = &t
+	}
+	if g.startup != nil {
+		snap.StartupState = g.startup.status().State
+	}
+
+	for _, comp := range g.componentsRegistry.All() {
+		snap.Components = append(snap.Components, g.debugComponent(ctx, comp, now, eventsLookback, stable))
+	}
+	sort.Slice(snap.Components, func(i, j int) bool {
+		return snap.Components[i].Name < snap.Components[j].Name
+	})
+
+	if g.gpudInstance != nil && g.gpudInstance.DBRO != nil {
+		lastState, err := sessionstates.ReadLast(ctx, g.gpudInstance.DBRO)
+		if err != nil && !sqlite.IsNoSuchTableError(err) {
+			log.Logger.Warnw("failed to read last session state for debug snapshot", "error", err)
+		}
+		if lastState != nil {
+			snap.Session = &apiv1.SessionStatus{
+				Success: lastState.Success,
+				Message: lastState.Message,
+			}
+			if !stable {
+				snap.Session.Time = metav1.NewTime(time.Unix(lastState.Timestamp, 0).UTC())
+			}
+		}
+	}
+
+	if g.syncStatusFunc != nil {
+		if st, err := g.syncStatusFunc(); err == nil {
+			if stable {
+				st.OldestUnackedAt = nil
+				st.LastAckAt = nil
+			}
+			snap.Sync = &st
+		}
+	}
+
+	if g.jobs != nil {
+		snap.Jobs = g.jobs.List("")
+		sort.Slice(snap.Jobs, func(i, j int) bool {
+			if snap.Jobs[i].Type != snap.Jobs[j].Type {
+				return snap.Jobs[i].Type < snap.Jobs[j].Type
+			}
+			return snap.Jobs[i].ID < snap.Jobs[j].ID
+		})
+		if stable {
+			for i := range snap.Jobs {
+				snap.Jobs[i].CreatedAt = time.Time{}
+				snap.Jobs[i].UpdatedAt = time.Time{}
+				snap.Jobs[i].FinishedAt = nil
+			}
+		}
+	}
+
+	return snap
+}
+
+func (g *globalHandler) debugComponent(ctx context.Context, comp components.Component, now time.Time, eventsLookback time.Duration, stable bool) DebugComponent {
+	dc := DebugComponent{
+		Name:      comp.Name(),
+		Tags:      append([]string(nil), comp.Tags()...),
+		Supported: comp.IsSupported(),
+	}
+	sort.Strings(dc.Tags)
+
+	if s, ok := components.GetCheckSchedule(comp.Name()); ok {
+		if stable {
+			s.NextCheckAt = metav1.Time{}
+		}
+		dc.Schedule = &s
+	}
+
+	dc.HealthStates = append(apiv1.HealthStates(nil), comp.LastHealthStates()...)
+	sort.SliceStable(dc.HealthStates, func(i, j int) bool {
+		return dc.HealthStates[i].Name < dc.HealthStates[j].Name
+	})
+	if stable {
+		for i := range dc.HealthStates {
+			dc.HealthStates[i].Time = metav1.Time{}
+		}
+	}
+
+	if eventsLookback > 0 {
+		evs, err := comp.Events(ctx, now.Add(-eventsLookback))
+		if err != nil {
+			log.Logger.Warnw("failed to get events for debug snapshot", "component", comp.Name(), "error", err)
+		}
+		dc.Events = append(apiv1.Events(nil), evs...)
+		sort.SliceStable(dc.Events, func(i, j int) bool {
+			a, b := dc.Events[i], dc.Events[j]
+			if !a.Time.Equal(&b.Time) {
+				return a.Time.Before(&b.Time)
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.Message < b.Message
+		})
+		if stable {
+			for i := range dc.Events {
+				dc.Events[i].Time = metav1.Time{}
+			}
+		}
+	}
+
+	return dc
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/rbac"
+	"github.com/leptonai/gpud/pkg/session"
+)
+
+func debugSnapshotComponents(now time.Time) []components.Component {
+	return []components.Component{
+		&mockComponent{
+			name:        "disk",
+			tags:        []string{"disk", "always"},
+			isSupported: true,
+			healthStates: apiv1.HealthStates{
+				{Time: metav1.NewTime(now), Name: "disk-usage", Health: apiv1.HealthStateTypeDegraded, Reason: "usage high"},
+				{Time: metav1.NewTime(now), Name: "disk-io", Health: apiv1.HealthStateTypeHealthy, Reason: "ok"},
+			},
+			events: apiv1.Events{
+				{Time: metav1.NewTime(now.Add(-time.Minute)), Name: "b", Type: apiv1.EventTypeWarning, Message: "second"},
+				{Time: metav1.NewTime(now.Add(-2 * time.Minute)), Name: "a", Type: apiv1.EventTypeWarning, Message: "first"},
+			},
+		},
+		&mockComponent{
+			name:         "cpu",
+			isSupported:  true,
+			healthStates: apiv1.HealthStates{{Time: metav1.NewTime(now), Name: "cpu", Health: apiv1.HealthStateTypeHealthy}},
+		},
+	}
+}
+
+func getDebugSnapshot(t *testing.T, handler *globalHandler, query string) (int, []byte) {
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerDebugRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/debug/snapshot"+query, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code, w.Body.Bytes()
+}
+
+func TestGetDebugSnapshot(t *testing.T) {
+	now := time.Now().UTC()
+	handler, _, _ := setupTestHandler(debugSnapshotComponents(now))
+	handler.syncStatusFunc = func() (session.SyncStatus, error) {
+		return session.SyncStatus{QueuedBatches: 2, QueuedBytes: 1024, LastAckAt: &now}, nil
+	}
+
+	code, body := getDebugSnapshot(t, handler, "")
+	require.Equal(t, http.StatusOK, code)
+
+	var snap DebugSnapshot
+	require.NoError(t, json.Unmarshal(body, &snap))
+	require.NotNil(t, snap.Time)
+	assert.Equal(t, apiv1.StartupStateReady, snap.StartupState)
+
+	require.Len(t, snap.Components, 2)
+	assert.Equal(t, "cpu", snap.Components[0].Name)
+	disk := snap.Components[1]
+	assert.Equal(t, "disk", disk.Name)
+	assert.True(t, disk.Supported)
+	assert.Equal(t, []string{"always", "disk"}, disk.Tags)
+	require.Len(t, disk.HealthStates, 2)
+	assert.Equal(t, "disk-io", disk.HealthStates[0].Name)
+	assert.False(t, disk.HealthStates[0].Time.IsZero())
+	require.Len(t, disk.Events, 2)
+	assert.Equal(t, "first", disk.Events[0].Message)
+
+	require.NotNil(t, snap.Sync)
+	assert.Equal(t, 2, snap.Sync.QueuedBatches)
+	assert.NotNil(t, snap.Sync.LastAckAt)
+}
+
+func TestGetDebugSnapshotStable(t *testing.T) {
+	now := time.Now().UTC()
+	handler, _, _ := setupTestHandler(debugSnapshotComponents(now))
+
+	code, body := getDebugSnapshot(t, handler, "?stable=true")
+	require.Equal(t, http.StatusOK, code)
+
+	// the same state with the different timestamps and registration order
+	comps := debugSnapshotComponents(now.Add(time.Hour))
+	comps[0], comps[1] = comps[1], comps[0]
+	handler2, _, _ := setupTestHandler(comps)
+	code, body2 := getDebugSnapshot(t, handler2, "?stable=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, string(body), string(body2))
+
+	var snap DebugSnapshot
+	require.NoError(t, json.Unmarshal(body, &snap))
+	assert.Nil(t, snap.Time)
+	for _, c := range snap.Components {
+		for _, hs := range c.HealthStates {
+			assert.True(t, hs.Time.IsZero())
+		}
+		for _, ev := range c.Events {
+			assert.True(t, ev.Time.IsZero())
+		}
+	}
+
+	// no events
+	code, body = getDebugSnapshot(t, handler, "?stable=true&events_lookback=0")
+	require.Equal(t, http.StatusOK, code)
+	var noEvents DebugSnapshot
+	require.NoError(t, json.Unmarshal(body, &noEvents))
+	assert.Empty(t, noEvents.Components[1].Events)
+}
+
+func TestGetDebugSnapshotInvalidQuery(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	code, _ := getDebugSnapshot(t, handler, "?stable=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = getDebugSnapshot(t, handler, "?events_lookback=-1h")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, body := getDebugSnapshot(t, handler, "")
+	require.Equal(t, http.StatusOK, code)
+	var snap DebugSnapshot
+	require.NoError(t, json.Unmarshal(body, &snap))
+	assert.Empty(t, snap.Components)
+}
+
+func TestDebugSnapshotRequiresAdmin(t *testing.T) {
+	role, public := requiredRole(http.MethodGet, "/v1"+URLPathDebugSnapshot)
+	assert.False(t, public)
+	assert.Equal(t, rbac.RoleAdmin, role)
+
+	role, _ = requiredRole(http.MethodGet, "/v2"+URLPathDebugSnapshot)
+	assert.Equal(t, rbac.RoleAdmin, role)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+const (
+	// URLPathEventDispositions is for recording the operator dispositions on the events
+	URLPathEventDispositions = "/events/dispositions"
+	// URLPathEventAdjustments is for exporting the severity adjustments from the dispositions
+	URLPathEventAdjustments = "/events/adjustments"
+)
+
+func (g *globalHandler) registerDispositionRoutes(r gin.IRoutes) {
+	r.GET(URLPathEventDispositions, g.getEventDispositions)
+	r.POST(URLPathEventDispositions, g.createEventDisposition)
+	r.GET(URLPathEventAdjustments, g.getEventAdjustments)
+}
+
+// getEventDispositions godoc
+// @Summary List the event dispositions
+// @Description Returns the operator dispositions on the events, sorted by the time
+// @ID getEventDispositions
+// @Tags events
+// @Produce json
+// @Param component query string false "Component name to filter the dispositions"
+// @Success 200 {array} disposition.Record "Event dispositions"
+// @Failure 404 {object} map[string]interface{} "Event dispositions not set up"
+// @Router /v1/events/dispositions [get]
+func (g *globalHandler) getEventDispositions(c *gin.Context) {
+	if g.eventDispositions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "event dispositions not set up"})
+		return
+	}
+	c.JSON(http.StatusOK, g.eventDispositions.List(c.Query("component")))
+}
+
+// createEventDisposition godoc
+// @Summary Record an event disposition
+// @Description Records the operator disposition ("true-positive", "false-positive", or "benign") on the events of a kind (e.g., the Xid code "79" of the "accelerator-nvidia-xid" component). Once the benign and false-positive dispositions since the last true positive reach the threshold, the critical and fatal events of the kind are downgraded to the warnings on this GPU product and driver version. The ID is generated if empty, the time defaults to now, and the GPU product and driver version default to the ones of this host.
+// @ID createEventDisposition
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param request body disposition.Record true "Event disposition"
+// @Success 200 {object} disposition.Record "Event disposition recorded"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body or disposition"
+// @Failure 404 {object} map[string]interface{} "Event dispositions not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/events/dispositions [post]
+func (g *globalHandler) createEventDisposition(c *gin.Context) {
+	if g.eventDispositions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "event dispositions not set up"})
+		return
+	}
+
+	var r disposition.Record
+	if err := json.NewDecoder(c.Request.Body).Decode(&r); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+
+	// the dispositions apply to the hardware and driver the events were observed on
+	if g.gpudInstance != nil && g.gpudInstance.NVMLInstance != nil {
+		if r.Product == "" {
+			r.Product = g.gpudInstance.NVMLInstance.ProductName()
+		}
+		if r.DriverVersion == "" {
+			r.DriverVersion = g.gpudInstance.NVMLInstance.DriverVersion()
+		}
+	}
+
+	recorded, err := g.eventDispositions.Record(c, r)
+	if err != nil {
+		if errors.Is(err, disposition.ErrInvalidRecord) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to record disposition: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recorded)
+}
+
+// getEventAdjustments godoc
+// @Summary Export the event severity adjustments
+// @Description Returns the severity adjustments in effect, derived from the accumulated operator dispositions per component, event kind, GPU product, and driver version, for the review of the known-noisy events
+// @ID getEventAdjustments
+// @Tags events
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {array} disposition.Adjustment "Event severity adjustments"
+// @Failure 404 {object} map[string]interface{} "Event dispositions not set up"
+// @Router /v1/events/adjustments [get]
+func (g *globalHandler) getEventAdjustments(c *gin.Context) {
+	if g.eventDispositions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "event dispositions not set up"})
+		return
+	}
+
+	adjustments := g.eventDispositions.Adjustments()
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, adjustments)
+		return
+	}
+	c.JSON(http.StatusOK, adjustments)
+}
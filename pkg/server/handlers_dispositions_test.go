@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestDispositionHandlers(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	m, err := disposition.NewManager(context.Background(), dbRW, dbRO, 2)
+	require.NoError(t, err)
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.eventDispositions = m
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerDispositionRoutes(v1)
+
+	do := func(method string, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, d := range []disposition.Disposition{disposition.DispositionBenign, disposition.DispositionFalsePositive} {
+		b, err := json.Marshal(disposition.Record{Component: "accelerator-nvidia-xid", Key: "79", Disposition: d, Operator: "oncall"})
+		require.NoError(t, err)
+		w := do(http.MethodPost, "/v1/events/dispositions", b)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var recorded disposition.Record
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &recorded))
+		assert.NotEmpty(t, recorded.ID)
+		assert.False(t, recorded.Time.IsZero())
+	}
+
+	w := do(http.MethodGet, "/v1/events/dispositions?component=accelerator-nvidia-xid", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []disposition.Record
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 2)
+	assert.Equal(t, "oncall", listed[0].Operator)
+
+	w = do(http.MethodGet, "/v1/events/dispositions?component=cpu", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	w = do(http.MethodGet, "/v1/events/adjustments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var adjustments []disposition.Adjustment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &adjustments))
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, "79", adjustments[0].Key)
+	assert.Equal(t, 1, adjustments[0].Benign)
+	assert.Equal(t, 1, adjustments[0].FalsePositive)
+
+	// invalid requests
+	w = do(http.MethodPost, "/v1/events/dispositions", []byte("{"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/v1/events/dispositions", []byte(`{"component":"accelerator-nvidia-xid","key":"79","disposition":"ignored"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/v1/events/dispositions", []byte(`{"key":"79","disposition":"benign"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDispositionHandlersNotSetUp(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerDispositionRoutes(v1)
+
+	for _, tc := range []struct {
+		method string
+		target string
+	}{
+		{http.MethodGet, "/v1/events/dispositions"},
+		{http.MethodPost, "/v1/events/dispositions"},
+		{http.MethodGet, "/v1/events/adjustments"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, bytes.NewReader([]byte("{}")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, tc.target)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+)
+
+const (
+	// URLPathDrainReadiness is for reporting whether the node can be safely maintained
+	URLPathDrainReadiness = "/drain-readiness"
+)
+
+func (g *globalHandler) registerDrainRoutes(r gin.IRoutes) {
+	r.GET(URLPathDrainReadiness, g.getDrainReadiness)
+}
+
+// getDrainReadiness godoc
+// @Summary Get the drain readiness of the node
+// @Description Returns whether the node can be safely maintained (e.g., drained, rebooted, or have its GPUs reset), ready only if no process is running on the GPUs, no GPU reset or reboot is in progress, no shared NVSwitch partition is activated, and the configured plugins are all healthy
+// @ID getDrainReadiness
+// @Tags drain
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.DrainReadiness "Drain readiness of the node"
+// @Failure 404 {object} map[string]interface{} "Drain readiness not set up"
+// @Router /v1/drain-readiness [get]
+func (g *globalHandler) getDrainReadiness(c *gin.Context) {
+	if g.drainChecker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "drain readiness not set up"})
+		return
+	}
+
+	readiness := g.drainChecker.Check(c)
+	if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+		c.IndentedJSON(http.StatusOK, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/drain"
+)
+
+func TestGetDrainReadiness(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerDrainRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/drain-readiness", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.drainChecker = drain.New(&components.GPUdInstance{}, handler.componentsRegistry, &drain.Config{Plugins: []string{"missing-plugin"}})
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/drain-readiness", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var readiness apiv1.DrainReadiness
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	// the configured plugin is not registered
+	assert.False(t, readiness.Ready)
+	require.Len(t, readiness.Checks, 4)
+	assert.Equal(t, drain.CheckGPUProcesses, readiness.Checks[0].Name)
+	assert.True(t, readiness.Checks[0].Ready)
+	assert.Equal(t, "plugin/missing-plugin", readiness.Checks[3].Name)
+	assert.False(t, readiness.Checks[3].Ready)
+}
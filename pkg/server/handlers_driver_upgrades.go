@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+const (
+	// URLPathDriverUpgrades is for listing the revalidations after the NVIDIA driver upgrades
+	URLPathDriverUpgrades = "/driver-upgrades"
+
+	// DefaultDriverUpgradesSince is the default lookback of the driver upgrade validations.
+	DefaultDriverUpgradesSince = 7 * 24 * time.Hour
+)
+
+func (g *globalHandler) registerDriverUpgradeRoutes(r gin.IRoutes) {
+	r.GET(URLPathDriverUpgrades, g.getDriverUpgrades)
+}
+
+// getDriverUpgrades godoc
+// @Summary List the NVIDIA driver upgrade validations
+// @Description Returns the revalidations of the NVIDIA components and the selected plugins after the detected NVIDIA driver installs, version changes, and module reloads within the lookback, the latest first, with the reason of each component not healthy.
+// @ID getDriverUpgrades
+// @Tags driver-upgrades
+// @Produce json
+// @Param since query string false "Lookback duration of the validations (e.g., 72h), defaults to 7 days"
+// @Success 200 {array} driverupgrade.Validation "Driver upgrade validations"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid duration"
+// @Failure 404 {object} map[string]interface{} "Driver upgrade detection not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/driver-upgrades [get]
+func (g *globalHandler) getDriverUpgrades(c *gin.Context) {
+	if g.driverUpgrades == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "driver upgrade detection not set up"})
+		return
+	}
+
+	since := time.Now().UTC().Add(-DefaultDriverUpgradesSince)
+	if sinceRaw := c.Query("since"); sinceRaw != "" {
+		dur, err := time.ParseDuration(sinceRaw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse duration: " + err.Error()})
+			return
+		}
+		since = time.Now().UTC().Add(-dur)
+	}
+
+	validations, err := g.driverUpgrades.Validations(c, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to get driver upgrade validations: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, validations)
+}
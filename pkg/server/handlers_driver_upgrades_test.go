@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/driverupgrade"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetDriverUpgrades(t *testing.T) {
+	handler, registry, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerDriverUpgradeRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/driver-upgrades", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(driverupgrade.BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	require.NoError(t, bucket.Insert(context.Background(), driverupgrade.Event(driverupgrade.Validation{
+		Time:        time.Now().UTC(),
+		Reason:      driverupgrade.ReasonVersionChanged,
+		FromVersion: "535.129.03",
+		ToVersion:   "550.54.15",
+		Checked:     2,
+		Failed:      map[string]string{"accelerator-nvidia-xid": "XID 79"},
+	})))
+	handler.driverUpgrades = driverupgrade.New(nil, dbRW, dbRO, bucket, nil, registry, nil)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/driver-upgrades?since=1h", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var validations []driverupgrade.Validation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &validations))
+	require.Len(t, validations, 1)
+	assert.Equal(t, driverupgrade.ReasonVersionChanged, validations[0].Reason)
+	assert.False(t, validations[0].Passed)
+	assert.Equal(t, map[string]string{"accelerator-nvidia-xid": "XID 79"}, validations[0].Failed)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/driver-upgrades?since=invalid", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
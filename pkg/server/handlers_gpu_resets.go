@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+const (
+	// URLPathGPUResets is for listing the GPU reset windows
+	URLPathGPUResets = "/gpu-resets"
+
+	// DefaultGPUResetsSince is the default lookback of the GPU reset windows.
+	DefaultGPUResetsSince = 24 * time.Hour
+)
+
+func (g *globalHandler) registerGPUResetRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUResets, g.getGPUResets)
+}
+
+// getGPUResets godoc
+// @Summary List the GPU reset windows
+// @Description Returns the GPU reset windows (the reboots requested by the control plane, the host reboots, and the NVIDIA driver reloads) that ended within the lookback, sorted by the start time. The Xid and SXid events within the windows are downgraded to the info events, tagged with "gpu_reset".
+// @ID getGPUResets
+// @Tags gpu-resets
+// @Produce json
+// @Param since query string false "Lookback duration of the windows (e.g., 72h), defaults to 24 hours"
+// @Success 200 {array} gpureset.Window "GPU reset windows"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid duration"
+// @Failure 404 {object} map[string]interface{} "GPU reset tracking not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/gpu-resets [get]
+func (g *globalHandler) getGPUResets(c *gin.Context) {
+	if g.gpudInstance == nil || g.gpudInstance.GPUResets == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gpu reset tracking not set up"})
+		return
+	}
+
+	since := time.Now().UTC().Add(-DefaultGPUResetsSince)
+	if sinceRaw := c.Query("since"); sinceRaw != "" {
+		dur, err := time.ParseDuration(sinceRaw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse duration: " + err.Error()})
+			return
+		}
+		since = time.Now().UTC().Add(-dur)
+	}
+
+	windows, err := g.gpudInstance.GPUResets.Windows(c, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to get gpu reset windows: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, windows)
+}
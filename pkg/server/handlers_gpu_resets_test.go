@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetGPUResets(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerGPUResetRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/gpu-resets", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(gpureset.BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	resets := gpureset.New(bucket, nil)
+	require.NoError(t, resets.Begin(context.Background(), gpureset.SourceGPUd, "reboot requested by control plane"))
+	handler.gpudInstance = &components.GPUdInstance{GPUResets: resets}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/gpu-resets?since=1h", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var windows []gpureset.Window
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &windows))
+	require.Len(t, windows, 1)
+	assert.Equal(t, gpureset.SourceGPUd, windows[0].Source)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/gpu-resets?since=invalid", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+)
+
+const (
+	// URLPathGPUs is for listing the GPUs with their performance scores
+	URLPathGPUs = "/gpus"
+	// URLPathGPUMapping is for getting the GPU UUID to index mapping
+	URLPathGPUMapping = "/gpus/mapping"
+)
+
+func (g *globalHandler) registerGPUsRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUs, g.getGPUs)
+}
+
+func (g *globalHandler) registerGPUMappingRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUMapping, g.getGPUMapping)
+}
+
+// getGPUMapping godoc
+// @Summary Get the GPU UUID to index mapping
+// @Description Returns the GPU UUIDs with the stable indexes in the order of the PCI bus IDs (the same order as nvidia-smi), the same values as the "gpu_uuid" and "gpu_index" labels of the per-GPU metrics. Returns an empty list if no GPU is detected.
+// @ID getGPUMapping
+// @Tags gpus
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.GPUMappings "GPU mappings"
+// @Router /v1/gpus/mapping [get]
+func (g *globalHandler) getGPUMapping(c *gin.Context) {
+	mappings := apiv1.GPUMappings{}
+	if g.gpudInstance != nil && g.gpudInstance.NVMLInstance != nil {
+		mappings = nvidianvml.GPUMappings(g.gpudInstance.NVMLInstance.Devices())
+	}
+
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, mappings)
+		return
+	}
+	c.JSON(http.StatusOK, mappings)
+}
+
+// getGPUs godoc
+// @Summary List the GPUs with their performance scores
+// @Description Returns the GPUs in the order of the index, each with the rolling performance score between 0 (worst) and 100 (best) combining the utilization capacity, the throttle time, the ECC errors, and the NVLink errors (see "docs/INTEGRATION.md" for the formula). The score is omitted if not yet computed. Set the "sort" query parameter to "score" to list the worst GPUs first. Returns an empty list if no GPU is detected.
+// @ID getGPUs
+// @Tags gpus
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Param sort query string false "Set to 'score' to sort by the performance score in the ascending order"
+// @Success 200 {object} v1.GPUs "GPUs"
+// @Router /v1/gpus [get]
+func (g *globalHandler) getGPUs(c *gin.Context) {
+	var scores map[string]apiv1.GPUPerformanceScore
+	if g.gpuScorer != nil {
+		scores = g.gpuScorer.Scores()
+	}
+
+	gpus := apiv1.GPUs{}
+	if g.gpudInstance != nil && g.gpudInstance.NVMLInstance != nil {
+		for _, m := range nvidianvml.GPUMappings(g.gpudInstance.NVMLInstance.Devices()) {
+			gpu := apiv1.GPU{GPUMapping: m}
+			if score, ok := scores[m.UUID]; ok {
+				gpu.PerformanceScore = &score
+			}
+			gpus = append(gpus, gpu)
+		}
+	}
+
+	if c.Query("sort") == "score" {
+		// the GPUs without the score are listed last
+		sort.SliceStable(gpus, func(i, j int) bool {
+			si, sj := gpus[i].PerformanceScore, gpus[j].PerformanceScore
+			if si == nil || sj == nil {
+				return si != nil
+			}
+			return si.Score < sj.Score
+		})
+	}
+
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, gpus)
+		return
+	}
+	c.JSON(http.StatusOK, gpus)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/gpuscore"
+	"github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
+	"github.com/leptonai/gpud/pkg/nvidia/nvml/testutil"
+)
+
+func TestGetGPUMapping(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerGPUMappingRoutes(v1)
+
+	get := func() apiv1.GPUMappings {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gpus/mapping", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var mappings apiv1.GPUMappings
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mappings))
+		return mappings
+	}
+
+	// without the NVML instance
+	assert.Empty(t, get())
+
+	handler.gpudInstance = &components.GPUdInstance{
+		NVMLInstance: &mockNVMLInstance{
+			devices: map[string]device.Device{
+				"GPU-b": testutil.NewMockDevice(nil, "", "", "", "0000:5e:00.0"),
+				"GPU-a": testutil.NewMockDevice(nil, "", "", "", "0000:3b:00.0"),
+			},
+		},
+	}
+	assert.Equal(t, apiv1.GPUMappings{
+		{Index: 0, UUID: "GPU-a", BusID: "0000:3b:00.0"},
+		{Index: 1, UUID: "GPU-b", BusID: "0000:5e:00.0"},
+	}, get())
+}
+
+func TestGetGPUs(t *testing.T) {
+	handler, _, store := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerGPUsRoutes(v1)
+
+	get := func(query string) apiv1.GPUs {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gpus"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var gpus apiv1.GPUs
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gpus))
+		return gpus
+	}
+
+	// without the NVML instance
+	assert.Empty(t, get(""))
+
+	handler.gpudInstance = &components.GPUdInstance{
+		NVMLInstance: &mockNVMLInstance{
+			devices: map[string]device.Device{
+				"GPU-a": testutil.NewMockDevice(nil, "", "", "", "0000:3b:00.0"),
+				"GPU-b": testutil.NewMockDevice(nil, "", "", "", "0000:5e:00.0"),
+				"GPU-c": testutil.NewMockDevice(nil, "", "", "", "0000:86:00.0"),
+			},
+		},
+	}
+
+	// GPU-b throttled all the time, GPU-c not yet scored
+	ts := time.Now().UTC().UnixMilli()
+	store.metrics = []metrics.Metric{
+		{UnixMilliseconds: ts, Name: "accelerator_nvidia_clock_hw_slowdown", Labels: map[string]string{"gpu_uuid": "GPU-a", "gpu_index": "0"}},
+		{UnixMilliseconds: ts, Name: "accelerator_nvidia_clock_hw_slowdown", Value: 1, Labels: map[string]string{"gpu_uuid": "GPU-b", "gpu_index": "1"}},
+	}
+	handler.gpuScorer = gpuscore.New(store, nil)
+	_, err := handler.gpuScorer.Compute(context.Background())
+	require.NoError(t, err)
+
+	gpus := get("")
+	require.Len(t, gpus, 3)
+	assert.Equal(t, "GPU-a", gpus[0].UUID)
+	require.NotNil(t, gpus[0].PerformanceScore)
+	assert.Equal(t, 100.0, gpus[0].PerformanceScore.Score)
+	require.NotNil(t, gpus[1].PerformanceScore)
+	assert.Equal(t, 65.0, gpus[1].PerformanceScore.Score)
+	assert.Nil(t, gpus[2].PerformanceScore)
+
+	// the worst GPUs first
+	gpus = get("?sort=score")
+	require.Len(t, gpus, 3)
+	assert.Equal(t, []string{"GPU-b", "GPU-a", "GPU-c"}, []string{gpus[0].UUID, gpus[1].UUID, gpus[2].UUID})
+}
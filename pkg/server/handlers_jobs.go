@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/jobs"
+)
+
+const (
+	// URLPathJobs is for listing the background jobs
+	URLPathJobs = "/jobs"
+	// URLPathJob is for getting and canceling a background job
+	URLPathJob = "/jobs/:id"
+)
+
+func (g *globalHandler) registerJobRoutes(r gin.IRoutes) {
+	r.GET(URLPathJobs, g.getJobs)
+	r.GET(URLPathJob, g.getJob)
+	r.DELETE(URLPathJob, g.cancelJob)
+}
+
+// getJobs godoc
+// @Summary List the background jobs
+// @Description Returns the background jobs (e.g., the state database compaction) running or finished within the retention, the latest first
+// @ID getJobs
+// @Tags jobs
+// @Produce json
+// @Param type query string false "Only the jobs of the type (e.g., compact)"
+// @Success 200 {array} jobs.Job "Jobs"
+// @Failure 404 {object} map[string]interface{} "Jobs not set up"
+// @Router /v1/jobs [get]
+func (g *globalHandler) getJobs(c *gin.Context) {
+	if g.jobs == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "jobs not set up"})
+		return
+	}
+	c.JSON(http.StatusOK, g.jobs.List(c.Query("type")))
+}
+
+// getJob godoc
+// @Summary Get a background job
+// @Description Returns the background job with its state and progress
+// @ID getJob
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} jobs.Job "Job"
+// @Failure 404 {object} map[string]interface{} "Job not found or jobs not set up"
+// @Router /v1/jobs/{id} [get]
+func (g *globalHandler) getJob(c *gin.Context) {
+	if g.jobs == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "jobs not set up"})
+		return
+	}
+
+	id := c.Param("id")
+	j, ok := g.jobs.Get(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "job not found: " + id})
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// cancelJob godoc
+// @Summary Cancel a background job
+// @Description Cancels the running background job. The job state becomes "canceled" once its operation stops.
+// @ID cancelJob
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} jobs.Job "Job cancellation requested"
+// @Failure 404 {object} map[string]interface{} "Job not found or jobs not set up"
+// @Failure 409 {object} map[string]interface{} "Job already finished"
+// @Router /v1/jobs/{id} [delete]
+func (g *globalHandler) cancelJob(c *gin.Context) {
+	if g.jobs == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "jobs not set up"})
+		return
+	}
+
+	id := c.Param("id")
+	j, err := g.jobs.Cancel(id)
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "job not found: " + id})
+		return
+	case errors.Is(err, jobs.ErrFinished):
+		c.JSON(http.StatusConflict, gin.H{"code": errdefs.ErrFailedPrecondition, "message": "job already " + string(j.State) + ": " + id})
+		return
+	}
+	c.JSON(http.StatusAccepted, j)
+}
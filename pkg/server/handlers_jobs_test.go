@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/jobs"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestJobHandlers(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	m, err := jobs.NewManager(context.Background(), dbRW, dbRO)
+	require.NoError(t, err)
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.jobs = m
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerJobRoutes(v1)
+
+	do := func(method string, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	started := make(chan struct{})
+	j, err := m.Start(context.Background(), "burn-in", func(ctx context.Context, report jobs.ProgressFunc) error {
+		report(10, "stressing GPUs")
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+
+	w := do(http.MethodGet, "/v1/jobs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []jobs.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, j.ID, listed[0].ID)
+	assert.Equal(t, jobs.StateRunning, listed[0].State)
+
+	w = do(http.MethodGet, "/v1/jobs?type=compact")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Empty(t, listed)
+
+	w = do(http.MethodGet, "/v1/jobs/"+j.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var got jobs.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, float64(10), got.Progress)
+	assert.Equal(t, "stressing GPUs", got.Message)
+
+	w = do(http.MethodGet, "/v1/jobs/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = do(http.MethodDelete, "/v1/jobs/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(http.MethodDelete, "/v1/jobs/"+j.ID)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	require.Eventually(t, func() bool {
+		got, _ := m.Get(j.ID)
+		return got.State == jobs.StateCanceled
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = do(http.MethodDelete, "/v1/jobs/"+j.ID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestJobHandlersNotSetUp(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerJobRoutes(v1)
+
+	for _, tc := range []struct {
+		method string
+		target string
+	}{
+		{http.MethodGet, "/v1/jobs"},
+		{http.MethodGet, "/v1/jobs/a"},
+		{http.MethodDelete, "/v1/jobs/a"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, tc.target)
+	}
+}
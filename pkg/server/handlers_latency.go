@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// URLPathComponentsLatency is for summarizing the check latencies of the components
+	URLPathComponentsLatency = "/components/latency"
+
+	// defaultLatencyWindows is the default windows of the check latencies,
+	// bounded by the metrics retention period.
+	defaultLatencyWindows = "15m,1h"
+)
+
+// getComponentsLatency godoc
+// @Summary Get the check latency percentiles of the components
+// @Description Returns the p50/p95/p99 and the mean of the check durations of each component over each window, estimated from the check duration histograms in the metrics store. The components are sorted by the p99 in descending order, the slowest first. The windows beyond the metrics retention period only cover the retained data.
+// @ID getComponentsLatency
+// @Tags components
+// @Produce json
+// @Param windows query string false "Comma-separated list of the windows (e.g., 15m,1h,3h), defaults to 15m,1h"
+// @Param components query string false "Comma-separated list of component names to query (if empty, queries all components)"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.ComponentCheckLatencies "Check latencies of the components"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid window or component parsing error"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error - failed to read metrics"
+// @Router /v1/components/latency [get]
+func (g *globalHandler) getComponentsLatency(c *gin.Context) {
+	componentNames, err := g.getReqComponentNames(c)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse components: " + err.Error()})
+		return
+	}
+
+	windows, err := parseLatencyWindows(c.DefaultQuery("windows", defaultLatencyWindows))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse windows: " + err.Error()})
+		return
+	}
+
+	// read once for the longest window, and filter for the shorter ones
+	now := time.Now().UTC()
+	metricsData, err := g.metricsStore.Read(c, pkgmetrics.WithSince(now.Add(-windows[len(windows)-1])), pkgmetrics.WithComponents(componentNames...))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read metrics: " + err.Error()})
+		return
+	}
+
+	resp := apiv1.ComponentCheckLatencies{
+		Time:    metav1.NewTime(now),
+		Windows: make([]apiv1.ComponentCheckLatencyWindow, 0, len(windows)),
+	}
+	for _, window := range windows {
+		resp.Windows = append(resp.Windows, summarizeCheckLatencies(metricsData, window, now))
+	}
+
+	if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+		c.IndentedJSON(http.StatusOK, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseLatencyWindows parses the comma-separated windows,
+// and returns the unique windows in ascending order.
+func parseLatencyWindows(s string) ([]time.Duration, error) {
+	seen := make(map[time.Duration]struct{})
+	var windows []time.Duration
+	for _, raw := range splitQueryList(s) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("window %q must be positive", raw)
+		}
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		windows = append(windows, d)
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no window specified")
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows, nil
+}
+
+// summarizeCheckLatencies returns the check latencies of the components
+// from the check duration histogram data points within the window.
+func summarizeCheckLatencies(ms pkgmetrics.Metrics, window time.Duration, now time.Time) apiv1.ComponentCheckLatencyWindow {
+	since := now.Add(-window)
+	sinceMs := since.UnixMilli()
+
+	inWindow := make(pkgmetrics.Metrics, 0, len(ms))
+	for _, m := range ms {
+		if m.UnixMilliseconds >= sinceMs {
+			inWindow = append(inWindow, m)
+		}
+	}
+
+	resp := apiv1.ComponentCheckLatencyWindow{
+		Window:     metav1.Duration{Duration: window},
+		Since:      metav1.NewTime(since),
+		Components: make([]apiv1.ComponentCheckLatency, 0),
+	}
+	for componentName, h := range pkgmetrics.HistogramIncreases(inWindow, components.CheckDurationMetricName) {
+		checks := int64(math.Round(h.Count))
+		if checks <= 0 {
+			continue
+		}
+		resp.Components = append(resp.Components, apiv1.ComponentCheckLatency{
+			Component: componentName,
+			Checks:    checks,
+			P50:       secondsToDuration(h.Quantile(0.5)),
+			P95:       secondsToDuration(h.Quantile(0.95)),
+			P99:       secondsToDuration(h.Quantile(0.99)),
+			Mean:      secondsToDuration(h.Mean()),
+		})
+	}
+
+	sort.Slice(resp.Components, func(i, j int) bool {
+		if resp.Components[i].P99.Duration != resp.Components[j].P99.Duration {
+			return resp.Components[i].P99.Duration > resp.Components[j].P99.Duration
+		}
+		return resp.Components[i].Component < resp.Components[j].Component
+	})
+	return resp
+}
+
+func secondsToDuration(sec float64) metav1.Duration {
+	if math.IsNaN(sec) || math.IsInf(sec, 0) {
+		return metav1.Duration{}
+	}
+	return metav1.Duration{Duration: time.Duration(sec * float64(time.Second))}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+func TestGetComponentsLatency(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) int64 { return now.Add(-ago).UnixMilli() }
+
+	// the cumulative check duration histogram of a component
+	// scraped at the time, with the buckets 0.1s, 1s, 10s
+	scrape := func(ts int64, component string, b01, b1, b10, count, sum float64) pkgmetrics.Metrics {
+		bucket := func(le string, v float64) pkgmetrics.Metric {
+			return pkgmetrics.Metric{
+				UnixMilliseconds: ts,
+				Component:        component,
+				Name:             components.CheckDurationMetricName + pkgmetrics.HistogramBucketSuffix,
+				Value:            v,
+				Labels:           map[string]string{pkgmetrics.HistogramBucketLabelKey: le},
+			}
+		}
+		return pkgmetrics.Metrics{
+			bucket("0.1", b01),
+			bucket("1", b1),
+			bucket("10", b10),
+			bucket("+Inf", count),
+			{UnixMilliseconds: ts, Component: component, Name: components.CheckDurationMetricName + pkgmetrics.HistogramCountSuffix, Value: count},
+			{UnixMilliseconds: ts, Component: component, Name: components.CheckDurationMetricName + pkgmetrics.HistogramSumSuffix, Value: sum},
+		}
+	}
+
+	var ms pkgmetrics.Metrics
+	// fast: 100 checks within 0.1s in the last 10 minutes
+	ms = append(ms, scrape(at(50*time.Minute), "fast", 0, 0, 0, 0, 0)...)
+	ms = append(ms, scrape(at(10*time.Minute), "fast", 0, 0, 0, 0, 0)...)
+	ms = append(ms, scrape(at(time.Minute), "fast", 100, 100, 100, 100, 5)...)
+	// slow: 10 checks between 1s and 10s in the last 50 minutes, none in the last 15 minutes
+	ms = append(ms, scrape(at(50*time.Minute), "slow", 0, 0, 0, 0, 0)...)
+	ms = append(ms, scrape(at(14*time.Minute), "slow", 0, 0, 10, 10, 50)...)
+	ms = append(ms, scrape(at(time.Minute), "slow", 0, 0, 10, 10, 50)...)
+	// not the check duration
+	ms = append(ms, pkgmetrics.Metric{UnixMilliseconds: at(time.Minute), Component: "fast", Name: "other", Value: 1})
+
+	handler, _, store := setupTestHandler([]components.Component{
+		&mockComponent{name: "fast", isSupported: true},
+		&mockComponent{name: "slow", isSupported: true},
+	})
+	store.metrics = ms
+
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerComponentRoutes(v1)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/components/latency"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?windows=1h,15m")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp apiv1.ComponentCheckLatencies
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Windows, 2)
+
+	// shortest window first, the slow component has no check within
+	w15 := resp.Windows[0]
+	assert.Equal(t, 15*time.Minute, w15.Window.Duration)
+	require.Len(t, w15.Components, 1)
+	assert.Equal(t, "fast", w15.Components[0].Component)
+	assert.Equal(t, int64(100), w15.Components[0].Checks)
+	assert.Equal(t, 50*time.Millisecond, w15.Components[0].P50.Duration)
+	assert.Equal(t, 50*time.Millisecond, w15.Components[0].Mean.Duration)
+
+	// slowest component first
+	w1h := resp.Windows[1]
+	assert.Equal(t, time.Hour, w1h.Window.Duration)
+	require.Len(t, w1h.Components, 2)
+	assert.Equal(t, "slow", w1h.Components[0].Component)
+	assert.Equal(t, int64(10), w1h.Components[0].Checks)
+	assert.Equal(t, 5500*time.Millisecond, w1h.Components[0].P50.Duration)
+	assert.Equal(t, 9910*time.Millisecond, w1h.Components[0].P99.Duration)
+	assert.Equal(t, 5*time.Second, w1h.Components[0].Mean.Duration)
+	assert.Equal(t, "fast", w1h.Components[1].Component)
+
+	// default windows
+	w = get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Windows, 2)
+	assert.Equal(t, 15*time.Minute, resp.Windows[0].Window.Duration)
+	assert.Equal(t, time.Hour, resp.Windows[1].Window.Duration)
+
+	for _, query := range []string{"?windows=abc", "?windows=-1h", "?windows=,"} {
+		w = get(query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w = get("?components=unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestParseLatencyWindows(t *testing.T) {
+	windows, err := parseLatencyWindows("1h, 5m,1h,30s")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{30 * time.Second, 5 * time.Minute, time.Hour}, windows)
+
+	_, err = parseLatencyWindows("0s")
+	require.Error(t, err)
+}
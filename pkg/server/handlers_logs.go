@@ -0,0 +1,150 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// URLPathLogsTail is for tailing the GPUd daemon logs
+const URLPathLogsTail = "/logs/tail"
+
+const (
+	// defaultLogsTailLines is the default number of the recent log entries to return.
+	defaultLogsTailLines = 100
+	// logsTailBufferSize is the number of the log entries buffered for a follower,
+	// the entries are dropped if the follower falls behind.
+	logsTailBufferSize = 256
+
+	// modulePathPrefix is trimmed from the component package path
+	// to match the source file of the log caller.
+	modulePathPrefix = "github.com/leptonai/gpud/"
+)
+
+func (g *globalHandler) registerLogsRoutes(r gin.IRoutes) {
+	r.GET(URLPathLogsTail, g.tailLogs)
+}
+
+// tailLogs godoc
+// @Summary Tail the GPUd logs
+// @Description Returns the recent structured log entries of the GPUd daemon, filtered by the component and the level. If follow is true, streams the recent and the new log entries as server-sent events ("log" event with the JSON entry as data)
+// @ID tailLogs
+// @Tags logs
+// @Produce json
+// @Produce text/event-stream
+// @Param component query string false "Component name to filter the logs"
+// @Param level query string false "Minimum log level (debug, info, warn, error), defaults to info"
+// @Param lines query int false "Number of the recent log entries to return, defaults to 100 (0 for all kept in memory)"
+// @Param follow query bool false "Set to 'true' to stream the new log entries"
+// @Success 200 {array} v1.LogEntry "Log entries"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid query parameter"
+// @Failure 404 {object} map[string]interface{} "Component not found"
+// @Router /v1/logs/tail [get]
+func (g *globalHandler) tailLogs(c *gin.Context) {
+	filter := log.TailFilter{}
+	if lvl := c.Query("level"); lvl != "" {
+		var err error
+		filter.MinLevel, err = zapcore.ParseLevel(lvl)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid level: " + err.Error()})
+			return
+		}
+	}
+
+	if componentName := c.Query("component"); componentName != "" {
+		comp := g.componentsRegistry.Get(componentName)
+		if comp == nil {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found"})
+			return
+		}
+		filter.Component = componentName
+		filter.CallerDir = componentSourceDir(comp)
+	}
+
+	lines := defaultLogsTailLines
+	if s := c.Query("lines"); s != "" {
+		var err error
+		lines, err = strconv.Atoi(s)
+		if err != nil || lines < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid lines: " + s})
+			return
+		}
+	}
+
+	follow := false
+	if s := c.Query("follow"); s != "" {
+		var err error
+		follow, err = strconv.ParseBool(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid follow: " + s})
+			return
+		}
+	}
+
+	if !follow {
+		entries := make([]apiv1.LogEntry, 0)
+		for _, e := range log.RecentEntries(filter, lines) {
+			entries = append(entries, toAPILogEntry(e))
+		}
+		c.JSON(http.StatusOK, entries)
+		return
+	}
+
+	// subscribe before reading the recent entries to not miss any entry in between
+	ch, cancel := log.SubscribeEntries(logsTailBufferSize)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream;charset=utf-8")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	for _, e := range log.RecentEntries(filter, lines) {
+		c.SSEvent("log", toAPILogEntry(e))
+	}
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case e := <-ch:
+			if filter.Match(e) {
+				c.SSEvent("log", toAPILogEntry(e))
+			}
+			return true
+		}
+	})
+}
+
+// componentSourceDir returns the source directory of the component package
+// relative to the module (e.g., "components/accelerator/nvidia/xid").
+func componentSourceDir(comp components.Component) string {
+	t := reflect.TypeOf(comp)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.TrimPrefix(t.PkgPath(), modulePathPrefix)
+}
+
+func toAPILogEntry(e log.Entry) apiv1.LogEntry {
+	return apiv1.LogEntry{
+		Time:    metav1.NewTime(e.Time),
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Caller:  e.Caller,
+		Fields:  e.Fields,
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+func TestTailLogs(t *testing.T) {
+	comps := []components.Component{&mockComponent{name: "logs-test"}}
+	handler, _, _ := setupTestHandler(comps)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerLogsRoutes(v1)
+
+	log.Logger.Infow("tail logs test message", "key", "value")
+	log.Logger.Debugw("tail logs test debug message")
+
+	t.Run("recent entries of component", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/logs/tail?component=logs-test&lines=10", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var entries []apiv1.LogEntry
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		require.NotEmpty(t, entries)
+		last := entries[len(entries)-1]
+		assert.Equal(t, "tail logs test message", last.Message)
+		assert.Equal(t, "info", last.Level)
+		assert.Equal(t, "value", last.Fields["key"])
+		assert.Contains(t, last.Caller, "handlers_logs_test.go")
+	})
+
+	t.Run("level filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/logs/tail?component=logs-test&level=error", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var entries []apiv1.LogEntry
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		for _, e := range entries {
+			assert.Equal(t, "error", e.Level)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{name: "unknown component", query: "component=unknown", wantCode: http.StatusNotFound},
+		{name: "invalid level", query: "level=invalid", wantCode: http.StatusBadRequest},
+		{name: "invalid lines", query: "lines=-1", wantCode: http.StatusBadRequest},
+		{name: "invalid follow", query: "follow=maybe", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/logs/tail?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestTailLogsFollow(t *testing.T) {
+	comps := []components.Component{&mockComponent{name: "logs-test"}}
+	handler, _, _ := setupTestHandler(comps)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerLogsRoutes(v1)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/logs/tail?component=logs-test&follow=true&lines=0", nil)
+	require.NoError(t, err)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	// keep logging until the follower receives the entry
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			log.Logger.Warnw("tail logs follow message")
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var entry apiv1.LogEntry
+		require.NoError(t, json.Unmarshal([]byte(data), &entry))
+		if entry.Message == "tail logs follow message" {
+			assert.Equal(t, "warn", entry.Level)
+			return
+		}
+	}
+	t.Fatalf("stream ended without the log entry: %v", scanner.Err())
+}
@@ -8,6 +8,7 @@ import (
 	_ "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/errdefs"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 )
 
 const URLPathMachineInfo = "/machine-info"
@@ -34,5 +35,13 @@ func (g *globalHandler) machineInfo(c *gin.Context) {
 		return
 	}
 
+	if g.gpudInstance.DBRO != nil {
+		info.AppliedConfigVersion, err = pkgmetadata.ReadMetadata(c, g.gpudInstance.DBRO, pkgmetadata.MetadataKeyAppliedConfigVersion)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to read applied config version: " + err.Error()})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, info)
 }
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+)
+
+// URLPathMachineState is for getting and setting the machine-level operational state
+const URLPathMachineState = "/machine-state"
+
+func (g *globalHandler) registerMachineStateRoutes(r gin.IRoutes) {
+	r.GET(URLPathMachineState, g.getMachineState)
+	r.PUT(URLPathMachineState, g.setMachineState)
+}
+
+// getMachineState godoc
+// @Summary Get the machine state
+// @Description Returns the machine-level operational state (active, cordoned, draining, or maintenance) set by the control plane or the local API, "active" if never set
+// @ID getMachineState
+// @Tags machine
+// @Produce json
+// @Success 200 {object} v1.MachineState "Machine state"
+// @Failure 404 {object} map[string]interface{} "Machine state not set up"
+// @Router /v1/machine-state [get]
+func (g *globalHandler) getMachineState(c *gin.Context) {
+	if g.machineStates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "machine state not set up"})
+		return
+	}
+
+	st := g.machineStates.Get()
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, st)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// setMachineState godoc
+// @Summary Set the machine state
+// @Description Sets and persists the machine-level operational state, included in the "machine_state" extra info of every health state
+// @ID setMachineState
+// @Tags machine
+// @Accept json
+// @Produce json
+// @Param request body v1.MachineState true "Machine state (only the state and the reason are used)"
+// @Success 200 {object} v1.MachineState "Machine state set"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body or state"
+// @Failure 404 {object} map[string]interface{} "Machine state not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/machine-state [put]
+func (g *globalHandler) setMachineState(c *gin.Context) {
+	if g.machineStates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "machine state not set up"})
+		return
+	}
+
+	var req apiv1.MachineState
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+	if !req.State.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid machine state: " + string(req.State)})
+		return
+	}
+
+	st, err := g.machineStates.Set(c, req.State, req.Reason, machinestate.SourceLocal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to set machine state: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestMachineStateHandlers(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	m, err := machinestate.NewManager(context.Background(), dbRW, dbRO)
+	require.NoError(t, err)
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.machineStates = m
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerMachineStateRoutes(v1)
+
+	do := func(method string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/machine-state", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var st apiv1.MachineState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Equal(t, apiv1.MachineStateActive, st.State)
+
+	w = do(http.MethodPut, `{"state":"cordoned","reason":"bad NVLink","source":"ignored"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Equal(t, apiv1.MachineStateCordoned, st.State)
+	assert.Equal(t, "bad NVLink", st.Reason)
+	assert.Equal(t, machinestate.SourceLocal, st.Source)
+	assert.False(t, st.UpdatedAt.IsZero())
+
+	w = do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var got apiv1.MachineState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, apiv1.MachineStateCordoned, got.State)
+
+	// invalid requests
+	w = do(http.MethodPut, "{")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPut, `{"state":"retired"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apiv1.MachineStateCordoned, m.Get().State)
+}
+
+func TestMachineStateHandlersNotSetUp(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerMachineStateRoutes(v1)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		req := httptest.NewRequest(method, "/v1/machine-state", bytes.NewReader([]byte(`{"state":"active"}`)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+	}
+}
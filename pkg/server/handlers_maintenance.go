@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/maintenance"
+)
+
+// URLPathMaintenance is for managing the scheduled maintenance windows
+const URLPathMaintenance = "/maintenance"
+
+func (g *globalHandler) registerMaintenanceRoutes(r gin.IRoutes) {
+	r.GET(URLPathMaintenance, g.getMaintenanceWindows)
+	r.POST(URLPathMaintenance, g.createMaintenanceWindow)
+	r.DELETE(URLPathMaintenance, g.deleteMaintenanceWindow)
+}
+
+// getMaintenanceWindows godoc
+// @Summary List the maintenance windows
+// @Description Returns the active and upcoming maintenance windows, sorted by the start time
+// @ID getMaintenanceWindows
+// @Tags maintenance
+// @Produce json
+// @Success 200 {array} maintenance.Window "Maintenance windows"
+// @Failure 404 {object} map[string]interface{} "Maintenance windows not set up"
+// @Router /v1/maintenance [get]
+func (g *globalHandler) getMaintenanceWindows(c *gin.Context) {
+	if g.maintenanceManager == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "maintenance windows not set up"})
+		return
+	}
+	c.JSON(http.StatusOK, g.maintenanceManager.List())
+}
+
+// createMaintenanceWindow godoc
+// @Summary Schedule a maintenance window
+// @Description Schedules a maintenance window (or replaces the one with the same ID), during which the health states and events of the covered components are tagged as maintenance. The ID is generated if empty, and the start defaults to now.
+// @ID createMaintenanceWindow
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param request body maintenance.Window true "Maintenance window"
+// @Success 200 {object} maintenance.Window "Maintenance window scheduled"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid request body or window"
+// @Failure 404 {object} map[string]interface{} "Maintenance windows not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/maintenance [post]
+func (g *globalHandler) createMaintenanceWindow(c *gin.Context) {
+	if g.maintenanceManager == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "maintenance windows not set up"})
+		return
+	}
+
+	var w maintenance.Window
+	if err := json.NewDecoder(c.Request.Body).Decode(&w); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode request body: " + err.Error()})
+		return
+	}
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	if w.Start.IsZero() {
+		w.Start = time.Now().UTC()
+	}
+	if err := w.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid maintenance window: " + err.Error()})
+		return
+	}
+
+	if err := g.maintenanceManager.Add(c, w); err != nil {
+		if errors.Is(err, maintenance.ErrWindowEnded) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to schedule maintenance window: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, w)
+}
+
+// deleteMaintenanceWindow godoc
+// @Summary Delete a maintenance window
+// @Description Deletes the maintenance window by its ID, to end the maintenance early
+// @ID deleteMaintenanceWindow
+// @Tags maintenance
+// @Produce json
+// @Param id query string true "ID of the maintenance window"
+// @Success 200 {object} map[string]interface{} "Maintenance window deleted"
+// @Failure 400 {object} map[string]interface{} "Bad request - id required"
+// @Failure 404 {object} map[string]interface{} "Maintenance window not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/maintenance [delete]
+func (g *globalHandler) deleteMaintenanceWindow(c *gin.Context) {
+	if g.maintenanceManager == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "maintenance windows not set up"})
+		return
+	}
+
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "id is required"})
+		return
+	}
+
+	deleted, err := g.maintenanceManager.Delete(c, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to delete maintenance window: " + err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "maintenance window not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "maintenance window deleted", "id": id})
+}
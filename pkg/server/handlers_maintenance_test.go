@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/maintenance"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestMaintenanceHandlers(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	m, err := maintenance.NewManager(context.Background(), dbRW, dbRO, nil)
+	require.NoError(t, err)
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.maintenanceManager = m
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerMaintenanceRoutes(v1)
+
+	do := func(method string, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// ID and start are filled in
+	end := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	b, err := json.Marshal(maintenance.Window{End: end, Reason: "kernel upgrade", Components: []string{"os"}})
+	require.NoError(t, err)
+	w := do(http.MethodPost, "/v1/maintenance", b)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var created maintenance.Window
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.Start.IsZero())
+
+	id, ok := m.ActiveWindow("os", time.Now())
+	assert.True(t, ok)
+	assert.Equal(t, created.ID, id)
+
+	w = do(http.MethodGet, "/v1/maintenance", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []maintenance.Window
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, created.ID, listed[0].ID)
+	assert.Equal(t, "kernel upgrade", listed[0].Reason)
+
+	w = do(http.MethodDelete, "/v1/maintenance?id="+created.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodDelete, "/v1/maintenance?id="+created.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = do(http.MethodDelete, "/v1/maintenance", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// invalid requests
+	w = do(http.MethodPost, "/v1/maintenance", []byte("{"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/v1/maintenance", []byte(`{"id":"w1"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	ended, err := json.Marshal(maintenance.Window{ID: "ended", Start: time.Now().Add(-2 * time.Hour), End: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	w = do(http.MethodPost, "/v1/maintenance", ended)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMaintenanceHandlersNotSetUp(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerMaintenanceRoutes(v1)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/v1/maintenance?id=w1", bytes.NewReader([]byte("{}")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/mute"
+)
+
+const (
+	// URLPathComponentMute is for muting and unmuting a component
+	URLPathComponentMute = "/components/:name/mute"
+	// URLPathComponentMutes is for listing the component mutes
+	URLPathComponentMutes = "/components/mutes"
+)
+
+func (g *globalHandler) registerMuteRoutes(r gin.IRoutes) {
+	r.GET(URLPathComponentMutes, g.getComponentMutes)
+	r.POST(URLPathComponentMute, g.muteComponent)
+	r.DELETE(URLPathComponentMute, g.unmuteComponent)
+}
+
+// getComponentMutes godoc
+// @Summary List the component mutes
+// @Description Returns the unexpired component mutes, sorted by the component name
+// @ID getComponentMutes
+// @Tags components
+// @Produce json
+// @Success 200 {array} mute.Mute "Component mutes"
+// @Failure 404 {object} map[string]interface{} "Component mutes not set up"
+// @Router /v1/components/mutes [get]
+func (g *globalHandler) getComponentMutes(c *gin.Context) {
+	if g.componentMutes == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component mutes not set up"})
+		return
+	}
+	c.JSON(http.StatusOK, g.componentMutes.List())
+}
+
+// muteComponent godoc
+// @Summary Mute a component
+// @Description Mutes the component for the duration (or replaces its existing mute), during which its unhealthy health states are tagged as muted and excluded from the health summary verdicts. The checks keep running and the data keep recorded. The mute persists across the restarts until it expires.
+// @ID muteComponent
+// @Tags components
+// @Produce json
+// @Param name path string true "Component name"
+// @Param duration query string true "Mute duration (e.g., 4h), up to 720h"
+// @Param reason query string false "Reason of the mute (e.g., RMA ticket)"
+// @Success 200 {object} mute.Mute "Component muted"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid duration"
+// @Failure 404 {object} map[string]interface{} "Component not found or component mutes not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/components/{name}/mute [post]
+func (g *globalHandler) muteComponent(c *gin.Context) {
+	if g.componentMutes == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component mutes not set up"})
+		return
+	}
+
+	name := c.Param("name")
+	if g.componentsRegistry.Get(name) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found: " + name})
+		return
+	}
+
+	durationRaw := c.Query("duration")
+	if durationRaw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "duration is required"})
+		return
+	}
+	d, err := time.ParseDuration(durationRaw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse duration: " + err.Error()})
+		return
+	}
+	if d > mute.MaxDuration {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "duration exceeds the maximum " + mute.MaxDuration.String()})
+		return
+	}
+
+	m, err := g.componentMutes.Mute(c, name, d, c.Query("reason"))
+	if err != nil {
+		if errors.Is(err, mute.ErrInvalidDuration) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to mute component: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// unmuteComponent godoc
+// @Summary Unmute a component
+// @Description Deletes the mute of the component, to end it before the expiry
+// @ID unmuteComponent
+// @Tags components
+// @Produce json
+// @Param name path string true "Component name"
+// @Success 200 {object} map[string]interface{} "Component unmuted"
+// @Failure 404 {object} map[string]interface{} "Component not muted"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/components/{name}/mute [delete]
+func (g *globalHandler) unmuteComponent(c *gin.Context) {
+	if g.componentMutes == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component mutes not set up"})
+		return
+	}
+
+	name := c.Param("name")
+	unmuted, err := g.componentMutes.Unmute(c, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to unmute component: " + err.Error()})
+		return
+	}
+	if !unmuted {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not muted: " + name})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "component unmuted", "component": name})
+}
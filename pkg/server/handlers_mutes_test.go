@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/mute"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestMuteHandlers(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	m, err := mute.NewManager(context.Background(), dbRW, dbRO)
+	require.NoError(t, err)
+
+	handler, _, _ := setupTestHandler([]components.Component{&mockComponent{name: "nfs", isSupported: true}})
+	handler.componentMutes = m
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerComponentRoutes(v1)
+	handler.registerMuteRoutes(v1)
+
+	do := func(method string, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/v1/components/nfs/mute?duration=4h&reason=RMA")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var muted mute.Mute
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &muted))
+	assert.Equal(t, "nfs", muted.Component)
+	assert.Equal(t, "RMA", muted.Reason)
+	assert.Equal(t, 4*time.Hour, muted.Until.Sub(muted.CreatedAt))
+
+	_, ok := m.MutedUntil("nfs")
+	assert.True(t, ok)
+
+	w = do(http.MethodGet, "/v1/components/mutes")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []mute.Mute
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, "nfs", listed[0].Component)
+
+	w = do(http.MethodDelete, "/v1/components/nfs/mute")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodDelete, "/v1/components/nfs/mute")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// invalid requests
+	w = do(http.MethodPost, "/v1/components/unknown/mute?duration=4h")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	for _, d := range []string{"", "abc", "-1h", "0s", "1000h"} {
+		w = do(http.MethodPost, "/v1/components/nfs/mute?duration="+d)
+		assert.Equal(t, http.StatusBadRequest, w.Code, d)
+	}
+}
+
+func TestMuteHandlersNotSetUp(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerMuteRoutes(v1)
+
+	for _, tc := range []struct{ method, target string }{
+		{http.MethodGet, "/v1/components/mutes"},
+		{http.MethodPost, "/v1/components/nfs/mute?duration=4h"},
+		{http.MethodDelete, "/v1/components/nfs/mute"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, tc.method)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,12 +10,17 @@ import (
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	"github.com/leptonai/gpud/pkg/errdefs"
 	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/log"
 )
 
 const URLPathComponentsCustomPlugins = "/plugins"
 
+// URLPathComponentsCustomPluginsBulk is for replacing all the custom plugins at once
+const URLPathComponentsCustomPluginsBulk = "/components/custom-plugins/bulk"
+
 func (g *globalHandler) registerPluginRoutes(r gin.IRoutes) {
 	r.GET(URLPathComponentsCustomPlugins, g.getPluginSpecs)
+	r.PUT(URLPathComponentsCustomPluginsBulk, g.applyPluginSpecs)
 }
 
 // getPluginSpecs godoc
@@ -31,14 +37,7 @@ func (g *globalHandler) registerPluginRoutes(r gin.IRoutes) {
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /v1/plugins [get]
 func (g *globalHandler) getPluginSpecs(c *gin.Context) {
-	var specs pkgcustomplugins.Specs
-	for _, c := range g.componentsRegistry.All() {
-		if customPluginRegisteree, ok := c.(pkgcustomplugins.CustomPluginRegisteree); ok {
-			if customPluginRegisteree.IsCustomPlugin() {
-				specs = append(specs, customPluginRegisteree.Spec())
-			}
-		}
-	}
+	specs := pkgcustomplugins.RegisteredSpecs(g.componentsRegistry)
 
 	switch c.GetHeader(httputil.RequestHeaderContentType) {
 	case httputil.RequestHeaderYAML:
@@ -60,3 +59,79 @@ func (g *globalHandler) getPluginSpecs(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
 	}
 }
+
+// applyPluginSpecsResponse is the per-plugin report of the bulk plugin spec apply.
+type applyPluginSpecsResponse struct {
+	// DryRun is true if the changes were only computed, not applied.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Results is the change to each plugin, sorted by the component name.
+	Results []pkgcustomplugins.ApplyResult `json:"results"`
+	// Error is the error that rolled back all the changes, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// applyPluginSpecs godoc
+// @Summary Replace all custom plugin specifications
+// @Description Validates the full plugin spec document (JSON or YAML), diffs it against the registered custom plugins, and applies it as a whole or not at all: registers the new plugins, re-registers the changed ones, and deregisters the ones not in the document. The specs are persisted to the plugin specs file if configured. Init plugins are only saved and run on the next GPUd start.
+// @ID applyPluginSpecs
+// @Tags plugins
+// @Accept json
+// @Accept x-yaml
+// @Produce json
+// @Param dry_run query bool false "Only report the changes without applying them"
+// @Param request body pkgcustomplugins.Specs true "Full list of the custom plugin specifications"
+// @Success 200 {object} applyPluginSpecsResponse "Per-plugin results"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid plugin specs"
+// @Failure 500 {object} applyPluginSpecsResponse "Failed to apply, all changes rolled back"
+// @Router /v1/components/custom-plugins/bulk [put]
+func (g *globalHandler) applyPluginSpecs(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to read request body: " + err.Error()})
+		return
+	}
+
+	// YAML is a superset of JSON, thus decodes both
+	var specs pkgcustomplugins.Specs
+	if err := yaml.Unmarshal(body, &specs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to decode plugin specs: " + err.Error()})
+		return
+	}
+	expanded, err := specs.ExpandedValidate()
+	if err == nil {
+		err = expanded.Validate()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid plugin specs: " + err.Error()})
+		return
+	}
+
+	g.pluginSpecsMu.Lock()
+	defer g.pluginSpecsMu.Unlock()
+
+	if c.Query("dry_run") == "true" {
+		c.JSON(http.StatusOK, applyPluginSpecsResponse{
+			DryRun:  true,
+			Results: pkgcustomplugins.DiffSpecs(pkgcustomplugins.RegisteredSpecs(g.componentsRegistry), expanded),
+		})
+		return
+	}
+
+	var save func() error
+	if g.cfg != nil && g.cfg.PluginSpecsFile != "" {
+		save = func() error {
+			_, err := pkgcustomplugins.SaveSpecs(g.cfg.PluginSpecsFile, specs)
+			return err
+		}
+	}
+	results, err := pkgcustomplugins.ApplySpecs(g.componentsRegistry, expanded, save)
+	if err != nil {
+		log.Logger.Warnw("rolled back plugin specs", "error", err)
+		c.JSON(http.StatusInternalServerError, applyPluginSpecsResponse{Results: results, Error: err.Error()})
+		return
+	}
+	g.refreshComponentNames()
+
+	log.Logger.Infow("applied plugin specs", "plugins", len(expanded))
+	c.JSON(http.StatusOK, applyPluginSpecsResponse{Results: results})
+}
@@ -1,9 +1,12 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/config"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	"github.com/leptonai/gpud/pkg/httputil"
 )
@@ -337,3 +341,80 @@ func TestRegisterPluginRoutes(t *testing.T) {
 	// Should get a response (we don't care about the exact content, just that the route is registered)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestApplyPluginSpecs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	specsFile := filepath.Join(t.TempDir(), "plugins.yaml")
+	handler := newGlobalHandler(&config.Config{PluginSpecsFile: specsFile}, registry, &mockMetricsStore{}, nil, nil)
+
+	body := `
+- plugin_name: bulk-plugin
+  plugin_type: component
+  run_mode: manual
+  health_state_plugin:
+    steps:
+      - name: step
+        run_bash_script:
+          content_type: plaintext
+          script: echo hello
+`
+
+	// dry run only reports the changes
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/components/custom-plugins/bulk?dry_run=true", strings.NewReader(body))
+	handler.applyPluginSpecs(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp applyPluginSpecsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.DryRun)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, pkgcustomplugins.ApplyActionCreated, resp.Results[0].Action)
+	assert.Empty(t, pkgcustomplugins.RegisteredSpecs(registry))
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/components/custom-plugins/bulk", strings.NewReader(body))
+	handler.applyPluginSpecs(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp = applyPluginSpecsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.DryRun)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, pkgcustomplugins.ApplyActionCreated, resp.Results[0].Action)
+	assert.NotNil(t, registry.Get(resp.Results[0].ComponentName))
+
+	saved, err := pkgcustomplugins.LoadSpecs(specsFile)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "bulk-plugin", saved[0].PluginName)
+
+	// an empty document removes all the plugins
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/components/custom-plugins/bulk", strings.NewReader("[]"))
+	handler.applyPluginSpecs(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp = applyPluginSpecsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, pkgcustomplugins.ApplyActionDeleted, resp.Results[0].Action)
+	assert.Empty(t, pkgcustomplugins.RegisteredSpecs(registry))
+}
+
+func TestApplyPluginSpecsInvalid(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+
+	_, c, w := setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/components/custom-plugins/bulk", strings.NewReader(`[{"plugin_name": "x", "plugin_type": "unknown"}]`))
+	handler.applyPluginSpecs(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	_, c, w = setupTestRouter()
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/components/custom-plugins/bulk", strings.NewReader(`{not yaml`))
+	handler.applyPluginSpecs(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
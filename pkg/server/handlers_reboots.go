@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// URLPathReboots is for getting the host reboot history
+	URLPathReboots = "/reboots"
+
+	// DefaultRebootHistorySince is the default lookback of the reboot history.
+	DefaultRebootHistorySince = 7 * 24 * time.Hour
+)
+
+func (g *globalHandler) registerRebootRoutes(r gin.IRoutes) {
+	r.GET(URLPathReboots, g.getReboots)
+}
+
+// getReboots godoc
+// @Summary Get the host reboot history
+// @Description Returns the host boots (boot IDs, boot times, and uptimes) with the fatal events that preceded each reboot, and whether the reboot resolved the condition (the same event has not recurred since and the component is not unhealthy). The fatal events not yet followed by a reboot are returned as the pending events.
+// @ID getReboots
+// @Tags reboots
+// @Produce json
+// @Param since query string false "Lookback duration of the history (e.g., 72h), defaults to 7 days"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.RebootHistory "Reboot history"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid duration"
+// @Failure 404 {object} map[string]interface{} "Boot tracking not set up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/reboots [get]
+func (g *globalHandler) getReboots(c *gin.Context) {
+	if g.bootTracker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "boot tracking not set up"})
+		return
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-DefaultRebootHistorySince)
+	if sinceRaw := c.Query("since"); sinceRaw != "" {
+		dur, err := time.ParseDuration(sinceRaw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse duration: " + err.Error()})
+			return
+		}
+		since = now.Add(-dur)
+	}
+
+	boots, err := g.bootTracker.Boots(c, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to read boots: " + err.Error()})
+		return
+	}
+
+	// the reboots recorded before the boot tracking have no boot ID
+	if g.gpudInstance != nil && g.gpudInstance.RebootEventStore != nil {
+		rebootEvents, err := g.gpudInstance.RebootEventStore.GetRebootEvents(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get reboot events", "error", err)
+		}
+		rebootTimes := make([]time.Time, 0, len(rebootEvents))
+		for _, ev := range rebootEvents {
+			rebootTimes = append(rebootTimes, ev.Time)
+		}
+		boots = boottracker.MergeRebootEvents(boots, rebootTimes)
+	}
+
+	var events apiv1.Events
+	unhealthy := make(map[string]bool)
+	for _, comp := range g.componentsRegistry.All() {
+		evs, err := comp.Events(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get events", "component", comp.Name(), "error", err)
+		}
+		for _, ev := range evs {
+			// the planned reboots during the maintenance windows are not correlated
+			if ev.Type != apiv1.EventTypeFatal || components.IsUnderMaintenance(ev.ExtraInfo) {
+				continue
+			}
+			if ev.Component == "" {
+				ev.Component = comp.Name()
+			}
+			events = append(events, ev)
+		}
+
+		for _, st := range comp.LastHealthStates() {
+			if st.Health != "" && st.Health != apiv1.HealthStateTypeHealthy {
+				unhealthy[comp.Name()] = true
+			}
+		}
+	}
+
+	hist := boottracker.Correlate(boots, g.bootTracker.Current().ID, events, unhealthy, now)
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, hist)
+		return
+	}
+	c.JSON(http.StatusOK, hist)
+}
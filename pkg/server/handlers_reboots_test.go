@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetReboots(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	bootTime := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	_, err := boottracker.New(ctx, dbRW, dbRO, "boot-1", bootTime.Add(-24*time.Hour))
+	require.NoError(t, err)
+	tracker, err := boottracker.New(ctx, dbRW, dbRO, "boot-2", bootTime)
+	require.NoError(t, err)
+
+	comp := &mockComponent{
+		name:        "xid",
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(bootTime.Add(-30 * time.Minute)), Name: "error_xid", Type: apiv1.EventTypeFatal},
+			// planned reboot
+			{Time: metav1.NewTime(bootTime.Add(-20 * time.Minute)), Name: "error_xid", Type: apiv1.EventTypeFatal, ExtraInfo: map[string]string{components.MaintenanceExtraInfoKey: "true"}},
+		},
+		healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{comp})
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerRebootRoutes(v1)
+
+	// not set up
+	req := httptest.NewRequest(http.MethodGet, "/v1/reboots", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.bootTracker = tracker
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/reboots?since=bad", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/reboots?since=72h", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var hist apiv1.RebootHistory
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &hist))
+	require.Len(t, hist.Reboots, 2)
+	assert.Equal(t, "boot-2", hist.Reboots[0].BootID)
+	assert.True(t, hist.Reboots[0].Current)
+	require.Len(t, hist.Reboots[0].PrecedingEvents, 1)
+	assert.Equal(t, "xid", hist.Reboots[0].PrecedingEvents[0].Event.Component)
+	assert.True(t, hist.Reboots[0].PrecedingEvents[0].Resolved)
+	assert.Empty(t, hist.PendingEvents)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/slo"
+)
+
+const (
+	// URLPathSLO is for reporting the attainment of the latency objectives
+	URLPathSLO = "/slo"
+)
+
+func (g *globalHandler) registerSLORoutes(r gin.IRoutes) {
+	r.GET(URLPathSLO, g.getSLO)
+}
+
+// getSLO godoc
+// @Summary Get the latency objectives report
+// @Description Returns the attainment and the remaining error budget of each latency objective over its rolling window, with the recent violation and recovery events
+// @ID getSLO
+// @Tags slo
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.SLOReport "Latency objectives report"
+// @Failure 404 {object} map[string]interface{} "Latency objectives not set up"
+// @Router /v1/slo [get]
+func (g *globalHandler) getSLO(c *gin.Context) {
+	if g.sloTracker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "slo not set up"})
+		return
+	}
+
+	report := g.sloTracker.Report(c)
+	if c.GetHeader(httputil.RequestHeaderJSONIndent) == "true" {
+		c.IndentedJSON(http.StatusOK, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// installSLOGinMiddleware installs the middleware that observes the API latencies
+// for the latency objectives.
+// No-op if the tracker is nil (latency objectives not set up).
+func installSLOGinMiddleware(router *gin.Engine, tracker *slo.Tracker) {
+	if tracker == nil {
+		return
+	}
+	router.Use(sloMiddleware(tracker))
+}
+
+// sloMiddleware observes the latency of every request on its path.
+func sloMiddleware(tracker *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		tracker.ObserveAPI(c.Request.URL.Path, time.Since(start))
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/slo"
+)
+
+func TestGetSLO(t *testing.T) {
+	tracker := slo.New(slo.Config{Objectives: []slo.Objective{{
+		Name:      "api",
+		Kind:      apiv1.SLOKindAPI,
+		Match:     "/v1/",
+		Threshold: metav1.Duration{Duration: time.Minute},
+		Target:    0.99,
+	}}}, nil)
+
+	handler, _, _ := setupTestHandler(nil)
+	// the middleware must be installed before the group is created
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	installSLOGinMiddleware(router, tracker)
+	handler.registerSLORoutes(router.Group("/v1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/slo", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.sloTracker = tracker
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/slo", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report apiv1.SLOReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Objectives, 1)
+	assert.Equal(t, "api", report.Objectives[0].Name)
+	// the previous request observed by the middleware
+	assert.Equal(t, int64(1), report.Objectives[0].Total)
+	assert.False(t, report.Objectives[0].Violated)
+}
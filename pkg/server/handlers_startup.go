@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+// URLPathStartup is for getting the startup progress of the GPUd daemon
+const URLPathStartup = "/startup"
+
+func (g *globalHandler) registerStartupRoutes(r gin.IRoutes) {
+	r.GET(URLPathStartup, g.getStartup)
+}
+
+// getStartup godoc
+// @Summary Get the GPUd startup progress
+// @Description Returns the startup progress of the GPUd daemon: the state ("starting", "ready", or "failed"), the elapsed time of each startup phase, and the initialization time of each component (slowest first). Served while the components are still being initialized.
+// @ID getStartup
+// @Tags status
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.StartupStatus "GPUd startup progress"
+// @Router /v1/startup [get]
+func (g *globalHandler) getStartup(c *gin.Context) {
+	st := apiv1.StartupStatus{State: apiv1.StartupStateReady}
+	if g.startup != nil {
+		st = g.startup.status()
+	}
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, st)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// URLPathReadyz is the readiness check, for the Kubernetes readiness probe
+const URLPathReadyz = "/readyz"
+
+// readyz godoc
+// @Summary Readiness check endpoint
+// @Description Returns 200 once all the components are initialized and started, or 503 with the startup progress while starting, if the startup failed, or if the startup self-test (if enabled) failed. Unlike /healthz, which only reports the process is alive, the readiness gates the traffic to the daemon.
+// @ID readyz
+// @Tags health
+// @Produce json
+// @Success 200 {object} v1.StartupStatus "GPUd is ready"
+// @Failure 503 {object} v1.StartupStatus "GPUd is starting or failed to start"
+// @Router /readyz [get]
+func readyz(tracker *startupTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := apiv1.StartupStatus{State: apiv1.StartupStateReady}
+		if tracker != nil {
+			st = tracker.status()
+		}
+		code := http.StatusOK
+		if st.State != apiv1.StartupStateReady || (st.SelfTest != nil && !st.SelfTest.Passed) {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, st)
+	}
+}
+
+// installStartupGinMiddleware installs the middleware that rejects the requests
+// depending on the components until the startup is ready.
+func installStartupGinMiddleware(router *gin.Engine, tracker *startupTracker) {
+	router.Use(startupMiddleware(tracker))
+}
+
+// startupMiddleware rejects the requests with 503 while the components are
+// being initialized. The health and readiness checks, the startup progress,
+// the Prometheus metrics, and the admin endpoints are served as soon as the
+// server listens.
+func startupMiddleware(tracker *startupTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tracker.isReady() || servedWhileStarting(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		st := tracker.status()
+		c.Header("Retry-After", "5")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"code":    errdefs.ErrUnavailable,
+			"message": "gpud is " + string(st.State) + ", try again later (see " + urlPathV1 + URLPathStartup + ")",
+			"startup": st,
+		})
+	}
+}
+
+// servedWhileStarting returns true if the route does not depend on the components.
+func servedWhileStarting(fullPath string) bool {
+	switch fullPath {
+	case "", // not found, let the router respond
+		URLPathHealthz,
+		urlPathV2 + URLPathHealthz,
+		URLPathReadyz,
+		urlPathV2 + URLPathReadyz,
+		urlPathV1 + URLPathStartup,
+		urlPathV2 + URLPathStartup,
+		URLPathSwagger,
+		"/metrics":
+		return true
+	}
+	return fullPath == urlPathAdmin || strings.HasPrefix(fullPath, urlPathAdmin+"/")
+}
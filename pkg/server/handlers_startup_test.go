@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestGetStartup(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerStartupRoutes(v1)
+
+	get := func() apiv1.StartupStatus {
+		req := httptest.NewRequest(http.MethodGet, "/v1/startup", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var st apiv1.StartupStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+		return st
+	}
+
+	// without the tracker
+	assert.Equal(t, apiv1.StartupStateReady, get().State)
+
+	handler.startup = newStartupTracker()
+	handler.startup.addComponents("cpu")
+	st := get()
+	assert.Equal(t, apiv1.StartupStateStarting, st.State)
+	assert.Equal(t, 1, st.ComponentsTotal)
+	assert.Equal(t, 0, st.ComponentsInitialized)
+}
+
+func TestStartupMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracker := newStartupTracker()
+	router := gin.New()
+	installStartupGinMiddleware(router, tracker)
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	router.GET(URLPathHealthz, ok)
+	router.GET(urlPathV1+URLPathStartup, ok)
+	router.GET(urlPathAdmin+urlPathConfig, ok)
+	router.GET(urlPathV1+URLPathComponents, ok)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, get(URLPathHealthz).Code)
+	assert.Equal(t, http.StatusOK, get(urlPathV1+URLPathStartup).Code)
+	assert.Equal(t, http.StatusOK, get(urlPathAdmin+urlPathConfig).Code)
+	assert.Equal(t, http.StatusNotFound, get("/v1/unknown").Code)
+
+	w := get(urlPathV1 + URLPathComponents)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	var resp struct {
+		Message string              `json:"message"`
+		Startup apiv1.StartupStatus `json:"startup"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Message, "starting")
+	assert.Equal(t, apiv1.StartupStateStarting, resp.Startup.State)
+
+	tracker.finish(nil)
+	assert.Equal(t, http.StatusOK, get(urlPathV1+URLPathComponents).Code)
+}
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracker := newStartupTracker()
+	router := gin.New()
+	installStartupGinMiddleware(router, tracker)
+	router.GET(URLPathReadyz, readyz(tracker))
+
+	get := func() (int, apiv1.StartupStatus) {
+		req := httptest.NewRequest(http.MethodGet, URLPathReadyz, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var st apiv1.StartupStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+		return w.Code, st
+	}
+
+	code, st := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, apiv1.StartupStateStarting, st.State)
+
+	tracker.finish(nil)
+	code, st = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, apiv1.StartupStateReady, st.State)
+
+	// without the tracker
+	router = gin.New()
+	router.GET(URLPathReadyz, readyz(nil))
+	code, _ = get()
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestReadyzFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracker := newStartupTracker()
+	tracker.finish(errors.New("failed to create NVML instance"))
+
+	router := gin.New()
+	router.GET(URLPathReadyz, readyz(tracker))
+	req := httptest.NewRequest(http.MethodGet, URLPathReadyz, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var st apiv1.StartupStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Equal(t, apiv1.StartupStateFailed, st.State)
+	assert.Contains(t, st.Error, "NVML")
+}
+
+func TestReadyzSelfTestFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tracker := newStartupTracker()
+	tracker.setSelfTest(apiv1.StartupSelfTest{
+		Passed: false,
+		Checks: []apiv1.StartupSelfTestCheck{
+			{Name: "db-writable", Result: apiv1.StartupSelfTestFailed, Message: "readonly database"},
+		},
+	})
+	tracker.finish(nil)
+
+	router := gin.New()
+	router.GET(URLPathReadyz, readyz(tracker))
+	req := httptest.NewRequest(http.MethodGet, URLPathReadyz, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var st apiv1.StartupStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.Equal(t, apiv1.StartupStateReady, st.State)
+	require.NotNil(t, st.SelfTest)
+	assert.False(t, st.SelfTest.Passed)
+	assert.Equal(t, "readonly database", st.SelfTest.Checks[0].Message)
+
+	// the component routes are served, as the startup is ready
+	assert.True(t, tracker.isReady())
+}
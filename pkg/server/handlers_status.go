@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	sessionstates "github.com/leptonai/gpud/pkg/session/states"
+	"github.com/leptonai/gpud/pkg/sqlite"
+	"github.com/leptonai/gpud/version"
+)
+
+// URLPathStatus is for getting the aggregated status of the GPUd daemon
+const URLPathStatus = "/status"
+
+const (
+	// defaultStatusFatalEventLookback is how far back to look for the last fatal event.
+	defaultStatusFatalEventLookback = 7 * 24 * time.Hour
+	// defaultStatusMetricsIngestWindow is the window to average the metrics ingest rate over.
+	defaultStatusMetricsIngestWindow = 10 * time.Minute
+)
+
+func (g *globalHandler) registerStatusRoutes(r gin.IRoutes) {
+	r.GET(URLPathStatus, g.getStatus)
+}
+
+// getStatus godoc
+// @Summary Get the GPUd status
+// @Description Returns the aggregated status of the GPUd daemon, including the uptime, control plane session state, number of components by health, last fatal event, database size, and metrics ingest rate
+// @ID getStatus
+// @Tags status
+// @Produce json
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.GPUdStatus "GPUd status"
+// @Router /v1/status [get]
+func (g *globalHandler) getStatus(c *gin.Context) {
+	st := g.status(c)
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, st)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// status aggregates the GPUd status.
+// Each field is best-effort, a failure to read one field is logged
+// and does not fail the whole status.
+func (g *globalHandler) status(ctx context.Context) apiv1.GPUdStatus {
+	now := time.Now().UTC()
+	st := apiv1.GPUdStatus{
+		GPUdVersion: version.Version,
+		StartTime:   metav1.NewTime(g.startTime),
+		Uptime:      metav1.Duration{Duration: now.Sub(g.startTime)},
+		Components:  g.componentHealthSummary(),
+	}
+
+	if g.gpudInstance != nil {
+		st.MachineID = g.gpudInstance.MachineID
+	}
+
+	if g.gpudInstance != nil && g.gpudInstance.DBRO != nil {
+		lastState, err := sessionstates.ReadLast(ctx, g.gpudInstance.DBRO)
+		if err != nil && !sqlite.IsNoSuchTableError(err) {
+			log.Logger.Warnw("failed to read last session state", "error", err)
+		}
+		if lastState != nil {
+			st.Session = &apiv1.SessionStatus{
+				Time:    metav1.NewTime(time.Unix(lastState.Timestamp, 0).UTC()),
+				Success: lastState.Success,
+				Message: lastState.Message,
+			}
+		}
+
+		st.DBSizeBytes, err = sqlite.ReadDBSize(ctx, g.gpudInstance.DBRO)
+		if err != nil {
+			log.Logger.Warnw("failed to read db size", "error", err)
+		}
+	}
+
+	st.LastFatalEvent = g.lastFatalEvent(ctx, now.Add(-defaultStatusFatalEventLookback))
+
+	if g.metricsStore != nil {
+		ms, err := g.metricsStore.Read(ctx, pkgmetrics.WithSince(now.Add(-defaultStatusMetricsIngestWindow)))
+		if err != nil {
+			log.Logger.Warnw("failed to read metrics", "error", err)
+		} else {
+			window := defaultStatusMetricsIngestWindow
+			if st.Uptime.Duration < window {
+				window = st.Uptime.Duration
+			}
+			if window >= time.Minute {
+				st.MetricsIngestRatePerMinute = float64(len(ms)) / window.Minutes()
+			}
+		}
+	}
+
+	return st
+}
+
+func (g *globalHandler) componentHealthSummary() apiv1.ComponentHealthSummary {
+	summary := apiv1.ComponentHealthSummary{
+		ByHealth: make(map[apiv1.HealthStateType]int),
+	}
+	for _, comp := range g.componentsRegistry.All() {
+		states := comp.LastHealthStates()
+		health := components.WorstHealthStateType(states)
+
+		summary.Total++
+		summary.ByHealth[health]++
+		if stale(states) {
+			summary.Stale = append(summary.Stale, comp.Name())
+		}
+
+		// the failures during the maintenance are expected (e.g., planned reboots)
+		if underMaintenance(states) {
+			summary.InMaintenance = append(summary.InMaintenance, comp.Name())
+			continue
+		}
+		// the failures of the known-bad components are silenced by the operators (e.g., RMA)
+		if muted(states) {
+			summary.Muted = append(summary.Muted, comp.Name())
+			continue
+		}
+		if health != apiv1.HealthStateTypeHealthy && health != apiv1.HealthStateTypeInitializing {
+			summary.Unhealthy = append(summary.Unhealthy, comp.Name())
+		}
+	}
+	sort.Strings(summary.Unhealthy)
+	sort.Strings(summary.InMaintenance)
+	sort.Strings(summary.Muted)
+	sort.Strings(summary.Stale)
+	return summary
+}
+
+func underMaintenance(states apiv1.HealthStates) bool {
+	for _, s := range states {
+		if components.IsUnderMaintenance(s.ExtraInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+func muted(states apiv1.HealthStates) bool {
+	for _, s := range states {
+		if components.IsMuted(s.ExtraInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+func stale(states apiv1.HealthStates) bool {
+	for _, s := range states {
+		if components.IsStale(s.ExtraInfo) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastFatalEvent returns the most recent fatal event since the given time, or nil if none.
+// The events during the maintenance windows are excluded.
+func (g *globalHandler) lastFatalEvent(ctx context.Context, since time.Time) *apiv1.Event {
+	var last *apiv1.Event
+	for _, comp := range g.componentsRegistry.All() {
+		evs, err := comp.Events(ctx, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get events", "component", comp.Name(), "error", err)
+			continue
+		}
+		for i := range evs {
+			if evs[i].Type != apiv1.EventTypeFatal || components.IsUnderMaintenance(evs[i].ExtraInfo) {
+				continue
+			}
+			if last == nil || evs[i].Time.After(last.Time.Time) {
+				ev := evs[i]
+				if ev.Component == "" {
+					ev.Component = comp.Name()
+				}
+				last = &ev
+			}
+		}
+	}
+	return last
+}
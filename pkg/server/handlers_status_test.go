@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/pkg/metrics"
+	sessionstates "github.com/leptonai/gpud/pkg/session/states"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetStatus(t *testing.T) {
+	now := time.Now().UTC()
+	comps := []components.Component{
+		&mockComponent{
+			name:         "cpu",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}},
+		},
+		&mockComponent{
+			name:         "disk",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded}},
+			events: apiv1.Events{
+				{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Type: apiv1.EventTypeFatal, Message: "older fatal"},
+			},
+		},
+		&mockComponent{
+			name:         "accelerator-nvidia-error-xid",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy}},
+			events: apiv1.Events{
+				{Time: metav1.NewTime(now.Add(-time.Hour)), Type: apiv1.EventTypeFatal, Message: "xid 79"},
+				{Time: metav1.NewTime(now), Type: apiv1.EventTypeWarning, Message: "newer warning"},
+			},
+		},
+		&mockComponent{
+			name:        "memory",
+			eventsError: errors.New("events error"),
+		},
+	}
+	handler, _, store := setupTestHandler(comps)
+	store.metrics = make([]metrics.Metric, 100)
+	handler.startTime = now.Add(-time.Hour)
+
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerStatusRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var st apiv1.GPUdStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+
+	assert.GreaterOrEqual(t, st.Uptime.Duration, time.Hour)
+	assert.Nil(t, st.Session)
+
+	assert.Equal(t, 4, st.Components.Total)
+	assert.Equal(t, 2, st.Components.ByHealth[apiv1.HealthStateTypeHealthy])
+	assert.Equal(t, 1, st.Components.ByHealth[apiv1.HealthStateTypeDegraded])
+	assert.Equal(t, 1, st.Components.ByHealth[apiv1.HealthStateTypeUnhealthy])
+	assert.Equal(t, []string{"accelerator-nvidia-error-xid", "disk"}, st.Components.Unhealthy)
+
+	require.NotNil(t, st.LastFatalEvent)
+	assert.Equal(t, "accelerator-nvidia-error-xid", st.LastFatalEvent.Component)
+	assert.Equal(t, "xid 79", st.LastFatalEvent.Message)
+
+	// 100 data points in the 10-minute window
+	assert.InDelta(t, 10.0, st.MetricsIngestRatePerMinute, 0.001)
+}
+
+func TestStatusMaintenance(t *testing.T) {
+	now := time.Now().UTC()
+	maintenanceInfo := map[string]string{components.MaintenanceExtraInfoKey: "true"}
+	comps := []components.Component{
+		&mockComponent{
+			name:         "os",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, ExtraInfo: maintenanceInfo}},
+			events: apiv1.Events{
+				{Time: metav1.NewTime(now), Type: apiv1.EventTypeFatal, Message: "planned reboot", ExtraInfo: maintenanceInfo},
+			},
+		},
+		&mockComponent{
+			name:         "disk",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded}},
+			events: apiv1.Events{
+				{Time: metav1.NewTime(now.Add(-time.Hour)), Type: apiv1.EventTypeFatal, Message: "disk failure"},
+			},
+		},
+	}
+	handler, _, _ := setupTestHandler(comps)
+
+	st := handler.status(context.Background())
+	assert.Equal(t, 1, st.Components.ByHealth[apiv1.HealthStateTypeUnhealthy])
+	assert.Equal(t, []string{"disk"}, st.Components.Unhealthy)
+	assert.Equal(t, []string{"os"}, st.Components.InMaintenance)
+	require.NotNil(t, st.LastFatalEvent)
+	assert.Equal(t, "disk failure", st.LastFatalEvent.Message)
+}
+
+func TestStatusMuted(t *testing.T) {
+	comps := []components.Component{
+		&mockComponent{
+			name:         "nfs",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, ExtraInfo: map[string]string{components.MutedExtraInfoKey: "true"}}},
+		},
+		&mockComponent{
+			name:         "disk",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded}},
+		},
+	}
+	handler, _, _ := setupTestHandler(comps)
+
+	st := handler.status(context.Background())
+	assert.Equal(t, []string{"disk"}, st.Components.Unhealthy)
+	assert.Equal(t, []string{"nfs"}, st.Components.Muted)
+
+	summary := handler.healthSummary()
+	assert.Equal(t, apiv1.HealthVerdictDegraded, summary.Verdict)
+	require.Len(t, summary.TopReasons, 1)
+	assert.Equal(t, "disk", summary.TopReasons[0].Component)
+}
+
+func TestStatusStale(t *testing.T) {
+	comps := []components.Component{
+		&mockComponent{
+			name:         "os",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, ExtraInfo: map[string]string{components.StaleExtraInfoKey: "true"}}},
+		},
+		&mockComponent{
+			name:         "disk",
+			healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}},
+		},
+	}
+	handler, _, _ := setupTestHandler(comps)
+
+	st := handler.status(context.Background())
+	assert.Equal(t, []string{"os"}, st.Components.Stale)
+	// the pre-restart failure is still reported until checked
+	assert.Equal(t, []string{"os"}, st.Components.Unhealthy)
+}
+
+func TestStatusWithDB(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, sessionstates.CreateTable(ctx, dbRW))
+	ts := time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, sessionstates.Insert(ctx, dbRW, ts, false, "token expired"))
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.gpudInstance = &components.GPUdInstance{MachineID: "machine-1", DBRW: dbRW, DBRO: dbRO}
+
+	st := handler.status(ctx)
+	assert.Equal(t, "machine-1", st.MachineID)
+	require.NotNil(t, st.Session)
+	assert.False(t, st.Session.Success)
+	assert.Equal(t, "token expired", st.Session.Message)
+	assert.Equal(t, ts, st.Session.Time.Unix())
+	assert.Positive(t, st.DBSizeBytes)
+	assert.Nil(t, st.LastFatalEvent)
+
+	// uptime below a minute does not report the rate
+	assert.Zero(t, st.MetricsIngestRatePerMinute)
+}
+
+func TestStatusNoSessionTable(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	handler, _, _ := setupTestHandler(nil)
+	handler.gpudInstance = &components.GPUdInstance{DBRW: dbRW, DBRO: dbRO}
+
+	st := handler.status(context.Background())
+	assert.Nil(t, st.Session)
+	assert.Equal(t, 0, st.Components.Total)
+}
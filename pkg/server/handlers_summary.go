@@ -0,0 +1,137 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+// URLPathSummary is for getting the compact overall health summary
+const URLPathSummary = "/summary"
+
+// maxSummaryTopReasons is the maximum number of reasons in the health summary.
+const maxSummaryTopReasons = 5
+
+func (g *globalHandler) registerSummaryRoutes(r gin.IRoutes) {
+	r.GET(URLPathSummary, g.getSummary)
+}
+
+// getSummary godoc
+// @Summary Get the health summary
+// @Description Returns the compact overall health verdict (healthy, degraded, or fatal), the number of components by health, and the top reasons. The response has an ETag header, and returns 304 if the "If-None-Match" header matches the current summary.
+// @ID getSummary
+// @Tags status
+// @Produce json
+// @Param If-None-Match header string false "ETag of the previous response"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.HealthSummary "Health summary"
+// @Success 304 "Not modified since the previous response"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/summary [get]
+func (g *globalHandler) getSummary(c *gin.Context) {
+	summary := g.healthSummary()
+
+	b, err := json.Marshal(summary)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal summary " + err.Error()})
+		return
+	}
+	etag := summaryETag(b)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, summary)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", b)
+}
+
+// healthSummary summarizes the last health states of all components.
+// The summary has no timestamp, so that it only changes when the health changes.
+func (g *globalHandler) healthSummary() apiv1.HealthSummary {
+	summary := apiv1.HealthSummary{
+		Verdict: apiv1.HealthVerdictHealthy,
+		Counts:  make(map[apiv1.HealthStateType]int),
+	}
+	for _, comp := range g.componentsRegistry.All() {
+		states := comp.LastHealthStates()
+		health := components.WorstHealthStateType(states)
+		summary.Counts[health]++
+
+		// the failures during the maintenance are expected (e.g., planned reboots)
+		if underMaintenance(states) {
+			continue
+		}
+		// the failures of the known-bad components are silenced by the operators (e.g., RMA)
+		if muted(states) {
+			continue
+		}
+
+		switch health {
+		case apiv1.HealthStateTypeUnhealthy:
+			summary.Verdict = apiv1.HealthVerdictFatal
+		case apiv1.HealthStateTypeDegraded:
+			if summary.Verdict == apiv1.HealthVerdictHealthy {
+				summary.Verdict = apiv1.HealthVerdictDegraded
+			}
+		default:
+			continue
+		}
+
+		var reasons []string
+		for _, st := range states {
+			if st.Health == health && st.Reason != "" {
+				reasons = append(reasons, st.Reason)
+			}
+		}
+		summary.TopReasons = append(summary.TopReasons, apiv1.HealthSummaryReason{
+			Component: comp.Name(),
+			Health:    health,
+			Reason:    strings.Join(reasons, "; "),
+		})
+	}
+
+	sort.Slice(summary.TopReasons, func(i, j int) bool {
+		a, b := summary.TopReasons[i], summary.TopReasons[j]
+		if a.Health != b.Health {
+			return a.Health == apiv1.HealthStateTypeUnhealthy
+		}
+		return a.Component < b.Component
+	})
+	if len(summary.TopReasons) > maxSummaryTopReasons {
+		summary.TopReasons = summary.TopReasons[:maxSummaryTopReasons]
+	}
+	return summary
+}
+
+// summaryETag returns the strong ETag of the summary response body.
+func summaryETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches returns true if the "If-None-Match" header matches the ETag,
+// with the weak comparison as required for the "If-None-Match" header.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+func TestGetSummary(t *testing.T) {
+	healthy := &mockComponent{name: "cpu", isSupported: true, healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeHealthy}}}
+	degraded := &mockComponent{name: "disk", isSupported: true, healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeDegraded, Reason: "disk almost full"}}}
+	maintenance := &mockComponent{name: "xid", isSupported: true, healthStates: apiv1.HealthStates{{
+		Health:    apiv1.HealthStateTypeUnhealthy,
+		Reason:    "xid 79",
+		ExtraInfo: map[string]string{components.MaintenanceExtraInfoKey: "true"},
+	}}}
+
+	handler, registry, _ := setupTestHandler([]components.Component{healthy, degraded, maintenance})
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerSummaryRoutes(v1)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/summary", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var summary apiv1.HealthSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, apiv1.HealthVerdictDegraded, summary.Verdict)
+	assert.Equal(t, map[apiv1.HealthStateType]int{
+		apiv1.HealthStateTypeHealthy:   1,
+		apiv1.HealthStateTypeDegraded:  1,
+		apiv1.HealthStateTypeUnhealthy: 1,
+	}, summary.Counts)
+	assert.Equal(t, []apiv1.HealthSummaryReason{{Component: "disk", Health: apiv1.HealthStateTypeDegraded, Reason: "disk almost full"}}, summary.TopReasons)
+
+	// nothing changed
+	w = get(etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	w = get(`"other", W/` + etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	registry.AddMockComponent(&mockComponent{name: "nvlink", isSupported: true, healthStates: apiv1.HealthStates{{Health: apiv1.HealthStateTypeUnhealthy, Reason: "nvlink down"}}})
+	w = get(etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, apiv1.HealthVerdictFatal, summary.Verdict)
+	require.Len(t, summary.TopReasons, 2)
+	assert.Equal(t, "nvlink", summary.TopReasons[0].Component)
+	assert.Equal(t, "disk", summary.TopReasons[1].Component)
+}
+
+func TestGetSummaryHealthy(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerSummaryRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/summary", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var summary apiv1.HealthSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, apiv1.HealthVerdictHealthy, summary.Verdict)
+	assert.Empty(t, summary.TopReasons)
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`W/"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`"x", "abc"`, `"abc"`))
+	assert.True(t, etagMatches(`*`, `"abc"`))
+	assert.False(t, etagMatches(``, `"abc"`))
+	assert.False(t, etagMatches(`"abd"`, `"abc"`))
+}
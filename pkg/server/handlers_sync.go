@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/session"
+)
+
+const (
+	// URLPathSyncStatus is for getting the control plane upload backlog
+	URLPathSyncStatus = "/sync/status"
+)
+
+func (g *globalHandler) registerSyncRoutes(r gin.IRoutes) {
+	r.GET(URLPathSyncStatus, g.getSyncStatus)
+}
+
+// getSyncStatus godoc
+// @Summary Get the control plane upload backlog
+// @Description Returns the metrics and events batches queued for the upload to the control plane, and (if the acknowledgements are required) the batches sent but not yet acknowledged
+// @ID getSyncStatus
+// @Tags sync
+// @Produce json
+// @Success 200 {object} session.SyncStatus "Upload backlog"
+// @Failure 404 {object} map[string]interface{} "Session upload not enabled"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /v1/sync/status [get]
+func (g *globalHandler) getSyncStatus(c *gin.Context) {
+	if g.syncStatusFunc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "session upload not enabled"})
+		return
+	}
+
+	st, err := g.syncStatusFunc()
+	if err != nil {
+		if errors.Is(err, session.ErrUploadNotEnabled) {
+			c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "session upload not enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"code": errdefs.ErrUnknown, "message": "failed to get sync status: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/session"
+)
+
+func TestGetSyncStatus(t *testing.T) {
+	handler, _, _ := setupTestHandler(nil)
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerSyncRoutes(v1)
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/sync/status", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do().Code)
+
+	handler.syncStatusFunc = func() (session.SyncStatus, error) {
+		return session.SyncStatus{}, session.ErrUploadNotEnabled
+	}
+	assert.Equal(t, http.StatusNotFound, do().Code)
+
+	handler.syncStatusFunc = func() (session.SyncStatus, error) {
+		return session.SyncStatus{}, errors.New("read error")
+	}
+	assert.Equal(t, http.StatusInternalServerError, do().Code)
+
+	handler.syncStatusFunc = func() (session.SyncStatus, error) {
+		return session.SyncStatus{RequireAck: true, QueuedBatches: 3, UnackedBatches: 2, Redeliveries: 1}, nil
+	}
+	w := do()
+	require.Equal(t, http.StatusOK, w.Code)
+	var st session.SyncStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+	assert.True(t, st.RequireAck)
+	assert.Equal(t, 3, st.QueuedBatches)
+	assert.Equal(t, 2, st.UnackedBatches)
+	assert.Equal(t, int64(1), st.Redeliveries)
+}
@@ -68,11 +68,11 @@ func TestGetReqTime(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				if tt.startTimeQuery != "" {
-					expectedStartTime := time.Unix(1609459200, 0)
+					expectedStartTime := time.Unix(1609459200, 0).UTC()
 					assert.Equal(t, expectedStartTime, startTime)
 				}
 				if tt.endTimeQuery != "" {
-					expectedEndTime := time.Unix(1609545600, 0)
+					expectedEndTime := time.Unix(1609545600, 0).UTC()
 					assert.Equal(t, expectedEndTime, endTime)
 				}
 			}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	componentsecc "github.com/leptonai/gpud/components/accelerator/nvidia/ecc"
+	componentshwslowdown "github.com/leptonai/gpud/components/accelerator/nvidia/hw-slowdown"
+	componentsremappedrows "github.com/leptonai/gpud/components/accelerator/nvidia/remapped-rows"
+	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	componentsxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	"github.com/leptonai/gpud/pkg/errdefs"
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+const (
+	// URLPathGPUTimeline is for getting the event timeline of a single GPU
+	URLPathGPUTimeline = "/gpus/:uuid/timeline"
+
+	// DefaultGPUTimelineSince is the default lookback of the GPU timeline.
+	DefaultGPUTimelineSince = 7 * 24 * time.Hour
+
+	// gpuComponentPrefix is the name prefix of the NVIDIA GPU components,
+	// whose health transitions are included in the GPU timelines.
+	gpuComponentPrefix = "accelerator-nvidia-"
+)
+
+// timelineEntryKinds maps the component names to the kinds of their events in the GPU timeline.
+var timelineEntryKinds = map[string]apiv1.GPUTimelineEntryKind{
+	componentsxid.Name:          apiv1.GPUTimelineEntryKindXid,
+	componentsecc.Name:          apiv1.GPUTimelineEntryKindECC,
+	componentsremappedrows.Name: apiv1.GPUTimelineEntryKindECC,
+	componentstemperature.Name:  apiv1.GPUTimelineEntryKindThermal,
+	componentshwslowdown.Name:   apiv1.GPUTimelineEntryKindThermal,
+}
+
+func (g *globalHandler) registerTimelineRoutes(r gin.IRoutes) {
+	r.GET(URLPathGPUTimeline, g.getGPUTimeline)
+}
+
+// getGPUTimeline godoc
+// @Summary Get the event timeline of a GPU
+// @Description Returns the Xid, ECC, thermal, and other events of the GPU, the host reboots, and the health transitions of the GPU components, merged in the ascending order of the time. The events are matched to the GPU by its UUID in the event extra info or message. The health transitions of the GPU components are included unless the reason names another GPU.
+// @ID getGPUTimeline
+// @Tags gpus
+// @Produce json
+// @Param uuid path string true "GPU UUID"
+// @Param since query string false "Lookback duration (e.g., 72h) or RFC3339 start time of the timeline, defaults to 7 days"
+// @Param json-indent header string false "Set to 'true' for indented JSON output"
+// @Success 200 {object} v1.GPUTimeline "GPU timeline"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid since"
+// @Failure 404 {object} map[string]interface{} "GPU not found"
+// @Router /v1/gpus/{uuid}/timeline [get]
+func (g *globalHandler) getGPUTimeline(c *gin.Context) {
+	uuid := c.Param("uuid")
+	if g.gpudInstance != nil && g.gpudInstance.NVMLInstance != nil {
+		if devs := g.gpudInstance.NVMLInstance.Devices(); len(devs) > 0 {
+			if _, ok := devs[uuid]; !ok {
+				c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "gpu not found: " + uuid})
+				return
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	since, err := parseSince(c.Query("since"), now, DefaultGPUTimelineSince)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "failed to parse since: " + err.Error()})
+		return
+	}
+
+	timeline := apiv1.GPUTimeline{
+		UUID:    uuid,
+		Since:   metav1.NewTime(since),
+		Entries: []apiv1.GPUTimelineEntry{},
+	}
+
+	for _, comp := range g.componentsRegistry.All() {
+		evs, err := comp.Events(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get events", "component", comp.Name(), "error", err)
+		}
+		for _, ev := range evs {
+			if ev.Time.Time.Before(since) || !eventMatchesGPU(ev, uuid) {
+				continue
+			}
+			if ev.Component == "" {
+				ev.Component = comp.Name()
+			}
+			kind, ok := timelineEntryKinds[ev.Component]
+			if !ok {
+				kind = apiv1.GPUTimelineEntryKindEvent
+			}
+			timeline.Entries = append(timeline.Entries, apiv1.GPUTimelineEntry{
+				Time:      ev.Time,
+				Kind:      kind,
+				Component: ev.Component,
+				Name:      ev.Name,
+				Type:      ev.Type,
+				Message:   ev.Message,
+				ExtraInfo: ev.ExtraInfo,
+			})
+		}
+	}
+
+	for _, bootTime := range g.rebootTimes(c, since) {
+		timeline.Entries = append(timeline.Entries, apiv1.GPUTimelineEntry{
+			Time: metav1.NewTime(bootTime),
+			Kind: apiv1.GPUTimelineEntryKindReboot,
+			Name: "reboot",
+		})
+	}
+
+	if g.healthStateStore != nil {
+		transitions, err := g.healthStateStore.HealthTransitions(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get health transitions", "error", err)
+		}
+		for _, tr := range transitions {
+			if !transitionMatchesGPU(tr, uuid) {
+				continue
+			}
+			tr := tr
+			timeline.Entries = append(timeline.Entries, apiv1.GPUTimelineEntry{
+				Time:       tr.Time,
+				Kind:       apiv1.GPUTimelineEntryKindHealth,
+				Component:  tr.Component,
+				Name:       tr.Name,
+				Message:    tr.Reason,
+				Transition: &tr,
+			})
+		}
+	}
+
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].Time.Before(&timeline.Entries[j].Time)
+	})
+
+	if c.GetHeader("json-indent") == "true" {
+		c.IndentedJSON(http.StatusOK, timeline)
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
+// rebootTimes returns the boot times since the given time,
+// from the boot tracker and the reboot events recorded before the boot tracking.
+func (g *globalHandler) rebootTimes(c *gin.Context, since time.Time) []time.Time {
+	var boots []boottracker.Boot
+	if g.bootTracker != nil {
+		var err error
+		boots, err = g.bootTracker.Boots(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to read boots", "error", err)
+		}
+	}
+
+	var rebootTimes []time.Time
+	if g.gpudInstance != nil && g.gpudInstance.RebootEventStore != nil {
+		rebootEvents, err := g.gpudInstance.RebootEventStore.GetRebootEvents(c, since)
+		if err != nil {
+			log.Logger.Warnw("failed to get reboot events", "error", err)
+		}
+		for _, ev := range rebootEvents {
+			rebootTimes = append(rebootTimes, ev.Time)
+		}
+	}
+
+	var times []time.Time
+	for _, b := range boottracker.MergeRebootEvents(boots, rebootTimes) {
+		// the boot before the lookback may still be listed while GPUd ran during it
+		if !b.Time.Before(since) {
+			times = append(times, b.Time)
+		}
+	}
+	return times
+}
+
+// parseSince parses the lookback duration (e.g., "72h") or the RFC3339 time,
+// or returns the default lookback from now if empty.
+func parseSince(sinceRaw string, now time.Time, defaultSince time.Duration) (time.Time, error) {
+	if sinceRaw == "" {
+		return now.Add(-defaultSince), nil
+	}
+	if dur, err := time.ParseDuration(sinceRaw); err == nil {
+		return now.Add(-dur), nil
+	}
+	return time.Parse(time.RFC3339, sinceRaw)
+}
+
+// eventMatchesGPU returns true if the event names the GPU UUID
+// in its extra info (e.g., "device_uuid", "gpu_uuid") or message.
+func eventMatchesGPU(ev apiv1.Event, uuid string) bool {
+	for _, v := range ev.ExtraInfo {
+		if v == uuid {
+			return true
+		}
+	}
+	return strings.Contains(ev.Message, uuid)
+}
+
+// transitionMatchesGPU returns true if the health transition names the GPU UUID,
+// or is of a GPU component and names no other GPU.
+func transitionMatchesGPU(tr apiv1.HealthTransition, uuid string) bool {
+	if strings.Contains(tr.Reason, uuid) {
+		return true
+	}
+	return strings.HasPrefix(tr.Component, gpuComponentPrefix) && !strings.Contains(tr.Reason, "GPU-")
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
+	componentsxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	pkghealthstate "github.com/leptonai/gpud/pkg/healthstate"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestGetGPUTimeline(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	tracker, err := boottracker.New(ctx, dbRW, dbRO, "boot-1", now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	healthStateStore, err := pkghealthstate.NewStore(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	require.NoError(t, healthStateStore.RecordHealthTransition(ctx, apiv1.HealthTransition{
+		Time:      metav1.NewTime(now.Add(-time.Hour)),
+		Component: componentstemperature.Name,
+		From:      apiv1.HealthStateTypeHealthy,
+		To:        apiv1.HealthStateTypeUnhealthy,
+		Reason:    "GPU-1 temperature exceeded the threshold",
+	}))
+	// names another GPU
+	require.NoError(t, healthStateStore.RecordHealthTransition(ctx, apiv1.HealthTransition{
+		Time:      metav1.NewTime(now.Add(-time.Hour)),
+		Component: componentstemperature.Name,
+		From:      apiv1.HealthStateTypeHealthy,
+		To:        apiv1.HealthStateTypeUnhealthy,
+		Reason:    "GPU-2 temperature exceeded the threshold",
+	}))
+
+	xid := &mockComponent{
+		name:        componentsxid.Name,
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(now.Add(-2 * time.Hour)), Name: "error_xid", Type: apiv1.EventTypeFatal, ExtraInfo: map[string]string{"device_uuid": "GPU-1"}},
+			{Time: metav1.NewTime(now.Add(-90 * time.Minute)), Name: "error_xid", Type: apiv1.EventTypeFatal, ExtraInfo: map[string]string{"device_uuid": "GPU-2"}},
+		},
+	}
+	other := &mockComponent{
+		name:        "accelerator-nvidia-nvlink",
+		isSupported: true,
+		events: apiv1.Events{
+			{Time: metav1.NewTime(now.Add(-30 * time.Minute)), Name: "nvlink_down", Message: "nvlink down on GPU-1"},
+		},
+	}
+	handler, _, _ := setupTestHandler([]components.Component{xid, other})
+	handler.bootTracker = tracker
+	handler.healthStateStore = healthStateStore
+	router, v1 := setupRouterWithPath("/v1")
+	handler.registerTimelineRoutes(v1)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/gpus/GPU-1/timeline?since=bad", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/gpus/GPU-1/timeline?since=24h", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var timeline apiv1.GPUTimeline
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	assert.Equal(t, "GPU-1", timeline.UUID)
+	require.Len(t, timeline.Entries, 4)
+
+	assert.Equal(t, apiv1.GPUTimelineEntryKindReboot, timeline.Entries[0].Kind)
+	assert.Equal(t, apiv1.GPUTimelineEntryKindXid, timeline.Entries[1].Kind)
+	assert.Equal(t, componentsxid.Name, timeline.Entries[1].Component)
+	assert.Equal(t, apiv1.GPUTimelineEntryKindHealth, timeline.Entries[2].Kind)
+	require.NotNil(t, timeline.Entries[2].Transition)
+	assert.Equal(t, apiv1.HealthStateTypeUnhealthy, timeline.Entries[2].Transition.To)
+	assert.Equal(t, apiv1.GPUTimelineEntryKindEvent, timeline.Entries[3].Kind)
+	assert.Equal(t, "nvlink_down", timeline.Entries[3].Name)
+
+	// RFC3339 start time
+	req = httptest.NewRequest(http.MethodGet, "/v1/gpus/GPU-1/timeline?since="+now.Add(-100*time.Minute).Format(time.RFC3339), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	timeline = apiv1.GPUTimeline{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Entries, 2)
+	assert.Equal(t, apiv1.GPUTimelineEntryKindHealth, timeline.Entries[0].Kind)
+}
+
+func TestTransitionMatchesGPU(t *testing.T) {
+	assert.True(t, transitionMatchesGPU(apiv1.HealthTransition{Component: "os", Reason: "GPU-1 lost"}, "GPU-1"))
+	assert.True(t, transitionMatchesGPU(apiv1.HealthTransition{Component: componentstemperature.Name, Reason: "too hot"}, "GPU-1"))
+	assert.False(t, transitionMatchesGPU(apiv1.HealthTransition{Component: componentstemperature.Name, Reason: "GPU-2 too hot"}, "GPU-1"))
+	assert.False(t, transitionMatchesGPU(apiv1.HealthTransition{Component: "os", Reason: "too hot"}, "GPU-1"))
+}
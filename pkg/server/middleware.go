@@ -1,17 +1,28 @@
 package server
 
 import (
+	"net/http"
+	"path"
+	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/requestid"
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/leptonai/gpud/pkg/crashreport"
+	"github.com/leptonai/gpud/pkg/log"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
 )
 
 // installRootGinMiddlewares installs gin middlewares for the root gin engine
 func installRootGinMiddlewares(router *gin.Engine) {
-	router.Use(requestid.New())
+	// reuses the request ID sent by the client if valid, otherwise generates one
+	router.Use(sanitizeRequestIDMiddleware(), requestid.New(requestid.WithCustomHeaderStrKey(headerRequestID)), errorRequestIDMiddleware())
 	router.ContextWithFallback = true
 }
 
@@ -21,9 +32,202 @@ func installCommonGinMiddlewares(router *gin.Engine, logger *zap.Logger) {
 	//   - Logs all requests, like a combined access and error log.
 	//   - Logs to stdout.
 	//   - RFC3339 with UTC time format.
-	router.Use(ginzap.Ginzap(logger, time.RFC3339, true))
+	//   - Logs the status, the latency, and the request ID of each request.
+	router.Use(ginzap.GinzapWithConfig(logger, &ginzap.Config{
+		TimeFormat:   time.RFC3339,
+		UTC:          true,
+		DefaultLevel: zapcore.InfoLevel,
+		Context:      accessLogFields,
+	}))
+
+	// Recovers the panics in the handlers into the 500 responses,
+	// with the crash reports (see "pkg/crashreport").
+	router.Use(recoveryMiddleware())
+}
+
+// recoveryMiddleware recovers the panics in the handlers, captures the crash reports
+// with the request method, path, and ID, and responds with 500 if not yet written.
+func recoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				// aborted on purpose (e.g., the client went away), let the server handle it
+				panic(r)
+			}
+
+			crashreport.Capture(crashreport.KindHTTP, "", r, debug.Stack(), map[string]string{
+				"method":       c.Request.Method,
+				"path":         c.Request.URL.Path,
+				"route":        c.FullPath(),
+				fieldRequestID: requestid.Get(c),
+			})
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "internal error"})
+		}()
+		c.Next()
+	}
+}
+
+// installRateLimitGinMiddleware installs the per-client rate limiting
+// and the concurrent request cap middleware.
+// No-op if the limiter is nil (rate limiting disabled).
+func installRateLimitGinMiddleware(router *gin.Engine, limiter *ratelimit.Limiter) {
+	if limiter == nil {
+		return
+	}
+	router.Use(rateLimitMiddleware(limiter))
+}
+
+// rateLimitMiddleware rejects the requests with 429 when the client exceeds
+// its request rate or when too many requests are being served.
+// The probe endpoints are never limited, so the liveness and readiness probes keep working.
+func rateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := c.Request.URL.Path
+		if isProbeRoute(p) || limiter.Exempt(p) {
+			c.Next()
+			return
+		}
+
+		// use the remote address rather than the forwarded headers,
+		// which can be set by the client to evade the limit
+		if !limiter.Allow(c.RemoteIP()) {
+			ratelimit.RecordRejected(ratelimit.ReasonRateLimited)
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": http.StatusTooManyRequests, "message": "rate limit exceeded"})
+			return
+		}
+
+		release, ok := limiter.Acquire()
+		if !ok {
+			ratelimit.RecordRejected(ratelimit.ReasonConcurrencyLimited)
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": http.StatusTooManyRequests, "message": "too many concurrent requests"})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
+
+// installRBACGinMiddleware installs the role-based access control middleware.
+// Must be installed before the routes are registered.
+// No-op if the authorizer is nil (RBAC disabled).
+func installRBACGinMiddleware(router *gin.Engine, authorizer *rbac.Authorizer) {
+	if authorizer == nil {
+		return
+	}
+	router.Use(rbacMiddleware(authorizer))
+}
+
+// rbacMiddleware rejects the requests whose role does not grant
+// the access to the endpoint group of the matched route.
+func rbacMiddleware(authorizer *rbac.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// unmatched route, let the router return 404
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		required, public := requiredRole(c.Request.Method, c.FullPath())
+		if public {
+			c.Next()
+			return
+		}
+
+		id, ok := authorizer.Authenticate(c.Request)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": http.StatusUnauthorized, "message": "unauthenticated"})
+			return
+		}
+		if !id.Role.Allows(required) {
+			log.Logger.Warnw("rejected request", "method", c.Request.Method, "path", c.FullPath(), "name", id.Name, "role", id.Role, "requiredRole", required)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "message": "role " + string(id.Role) + " is not allowed, requires " + string(required)})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// routeRoles maps the mutating routes to their endpoint groups.
+// Keyed by "<method> <route path>".
+var routeRoles = map[string]rbac.Role{
+	// trigger the checks (e.g., run the plugins on demand)
+	http.MethodGet + " " + path.Join("/v1", URLPathComponentsTriggerCheck): rbac.RoleOperator,
+	http.MethodGet + " " + path.Join("/v1", URLPathComponentsTriggerTag):   rbac.RoleOperator,
+
+	// schedule the maintenance windows (e.g., planned reboots)
+	http.MethodPost + " " + path.Join("/v1", URLPathMaintenance):   rbac.RoleOperator,
+	http.MethodDelete + " " + path.Join("/v1", URLPathMaintenance): rbac.RoleOperator,
+
+	// mutate the component registry and the health states
+	http.MethodDelete + " " + path.Join("/v1", URLPathComponents):               rbac.RoleAdmin,
+	http.MethodPut + " " + path.Join("/v1", URLPathComponentsCustomPluginsBulk): rbac.RoleAdmin,
+	http.MethodPost + " " + path.Join("/v1", URLPathHealthStatesSetHealthy):     rbac.RoleAdmin,
+	http.MethodPost + " " + URLPathInjectFault:                                  rbac.RoleAdmin,
+
+	// expose the complete internal state (e.g., the health state details of every component)
+	http.MethodGet + " " + path.Join("/v1", URLPathDebugSnapshot): rbac.RoleAdmin,
+}
+
+// v2RootRoutes are the root routes (without the "/v1" prefix)
+// also served under the "/v2" prefix.
+var v2RootRoutes = map[string]bool{
+	URLPathHealthz:     true,
+	URLPathReadyz:      true,
+	URLPathMachineInfo: true,
+	URLPathInjectFault: true,
+}
+
+// isProbeRoute returns true if the route is the health probe endpoint (e.g., for the kubelet),
+// including the v2 routes.
+func isProbeRoute(fullPath string) bool {
+	if rest, ok := strings.CutPrefix(fullPath, urlPathV2); ok {
+		fullPath = rest
+	}
+	return fullPath == URLPathHealthz || fullPath == URLPathReadyz
+}
+
+// requiredRole returns the role required to access the route.
+// It returns true if the route is public (e.g., health checks).
+func requiredRole(method string, fullPath string) (rbac.Role, bool) {
+	// the v2 routes serve the same handlers as the v1 and the root routes,
+	// so require the same role
+	if rest, ok := strings.CutPrefix(fullPath, urlPathV2); ok && strings.HasPrefix(rest, "/") {
+		if v2RootRoutes[rest] {
+			return requiredRole(method, rest)
+		}
+		return requiredRole(method, urlPathV1+rest)
+	}
+
+	// the probes (e.g., the kubelet) do not authenticate
+	if isProbeRoute(fullPath) {
+		return "", true
+	}
+	if r, ok := routeRoles[method+" "+fullPath]; ok {
+		return r, false
+	}
+
+	// the admin endpoints expose the config and the profiles
+	if fullPath == urlPathAdmin || strings.HasPrefix(fullPath, urlPathAdmin+"/") {
+		return rbac.RoleAdmin, false
+	}
 
-	// Logs all panic to error log
-	//   - stack means whether output the stack info.
-	router.Use(ginzap.RecoveryWithZap(logger, true))
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return rbac.RoleViewer, false
+	default:
+		// new mutating endpoints are admin-only unless explicitly mapped
+		return rbac.RoleAdmin, false
+	}
 }
@@ -1,13 +1,22 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+
+	"github.com/leptonai/gpud/pkg/crashreport"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
 )
 
 func TestInstallRootGinMiddlewares(t *testing.T) {
@@ -60,3 +69,253 @@ func TestInstallCommonGinMiddlewares(t *testing.T) {
 	// Check that we got a 500 error but the server didn't crash
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestRecoveryMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	crashreport.SetWriter(crashreport.NewWriter(dir, 0))
+	t.Cleanup(func() { crashreport.SetWriter(nil) })
+
+	router := gin.New()
+	installRootGinMiddlewares(router)
+	router.Use(recoveryMiddleware())
+	router.GET("/panic/:id", func(c *gin.Context) {
+		panic("test panic")
+	})
+
+	req := httptest.NewRequest("GET", "/panic/1", nil)
+	req.Header.Set(headerRequestID, "test-request-id")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":500,"message":"internal error","request_id":"test-request-id"}`, w.Body.String())
+
+	files, err := crashreport.List(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	var rep crashreport.Report
+	require.NoError(t, json.Unmarshal(b, &rep))
+	assert.Equal(t, crashreport.KindHTTP, rep.Kind)
+	assert.Equal(t, "test panic", rep.Panic)
+	assert.Contains(t, rep.Stack, "TestRecoveryMiddleware")
+	assert.Equal(t, map[string]string{
+		"method":       "GET",
+		"path":         "/panic/1",
+		"route":        "/panic/:id",
+		fieldRequestID: "test-request-id",
+	}, rep.Context)
+}
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		method     string
+		path       string
+		wantRole   rbac.Role
+		wantPublic bool
+	}{
+		{http.MethodGet, URLPathHealthz, "", true},
+		{http.MethodGet, URLPathReadyz, "", true},
+		{http.MethodGet, "/v1/states", rbac.RoleViewer, false},
+		{http.MethodGet, "/v1/components", rbac.RoleViewer, false},
+		{http.MethodGet, "/machine-info", rbac.RoleViewer, false},
+		{http.MethodGet, "/v1/components/trigger-check", rbac.RoleOperator, false},
+		{http.MethodGet, "/v1/components/trigger-tag", rbac.RoleOperator, false},
+		{http.MethodGet, "/v1/maintenance", rbac.RoleViewer, false},
+		{http.MethodPost, "/v1/maintenance", rbac.RoleOperator, false},
+		{http.MethodDelete, "/v1/maintenance", rbac.RoleOperator, false},
+		{http.MethodDelete, "/v1/components", rbac.RoleAdmin, false},
+		{http.MethodPost, "/v1/health-states/set-healthy", rbac.RoleAdmin, false},
+		{http.MethodPost, URLPathInjectFault, rbac.RoleAdmin, false},
+		{http.MethodGet, "/admin/config", rbac.RoleAdmin, false},
+		{http.MethodGet, "/admin/pprof/heap", rbac.RoleAdmin, false},
+		{http.MethodPut, "/v1/unknown", rbac.RoleAdmin, false},
+		{http.MethodGet, "/v2" + URLPathHealthz, "", true},
+		{http.MethodGet, "/v2" + URLPathReadyz, "", true},
+		{http.MethodGet, "/v2/states", rbac.RoleViewer, false},
+		{http.MethodGet, "/v2/machine-info", rbac.RoleViewer, false},
+		{http.MethodGet, "/v2/components/trigger-check", rbac.RoleOperator, false},
+		{http.MethodPost, "/v2/maintenance", rbac.RoleOperator, false},
+		{http.MethodPost, "/v2/health-states/set-healthy", rbac.RoleAdmin, false},
+		{http.MethodPost, "/v2" + URLPathInjectFault, rbac.RoleAdmin, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			role, public := requiredRole(tt.method, tt.path)
+			assert.Equal(t, tt.wantRole, role)
+			assert.Equal(t, tt.wantPublic, public)
+		})
+	}
+}
+
+func TestInstallRBACGinMiddleware(t *testing.T) {
+	digest := func(token string) string {
+		sum := sha256.Sum256([]byte(token))
+		return hex.EncodeToString(sum[:])
+	}
+	authorizer, err := rbac.NewAuthorizer(&rbac.Config{
+		Tokens: []rbac.Token{
+			{Name: "view", SHA256: digest("viewer-token"), Role: rbac.RoleViewer},
+			{Name: "ops", SHA256: digest("operator-token"), Role: rbac.RoleOperator},
+			{Name: "root", SHA256: digest("admin-token"), Role: rbac.RoleAdmin},
+		},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	installRBACGinMiddleware(router, authorizer)
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	router.GET(URLPathHealthz, ok)
+	router.GET(URLPathReadyz, ok)
+	v2 := router.Group(urlPathV2)
+	v2.GET(URLPathReadyz, ok)
+	v1 := router.Group("/v1")
+	v1.GET(URLPathStates, ok)
+	v1.GET(URLPathComponentsTriggerCheck, ok)
+	v1.DELETE(URLPathComponents, ok)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{"healthz is public", http.MethodGet, URLPathHealthz, "", http.StatusOK},
+		{"readyz is public", http.MethodGet, URLPathReadyz, "", http.StatusOK},
+		{"v2 readyz is public", http.MethodGet, urlPathV2 + URLPathReadyz, "", http.StatusOK},
+		{"unauthenticated", http.MethodGet, "/v1/states", "", http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "/v1/states", "invalid", http.StatusUnauthorized},
+		{"viewer reads states", http.MethodGet, "/v1/states", "viewer-token", http.StatusOK},
+		{"viewer cannot trigger", http.MethodGet, "/v1/components/trigger-check", "viewer-token", http.StatusForbidden},
+		{"operator triggers", http.MethodGet, "/v1/components/trigger-check", "operator-token", http.StatusOK},
+		{"operator cannot deregister", http.MethodDelete, "/v1/components", "operator-token", http.StatusForbidden},
+		{"admin deregisters", http.MethodDelete, "/v1/components", "admin-token", http.StatusOK},
+		{"unmatched route", http.MethodGet, "/v1/unknown", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestInstallRBACGinMiddlewareDisabled(t *testing.T) {
+	router := gin.New()
+	installRBACGinMiddleware(router, nil)
+	router.DELETE("/v1"+URLPathComponents, func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/v1/components", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestInstallRateLimitGinMiddleware(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(&ratelimit.Config{
+		RequestsPerSecond: 0.001,
+		Burst:             2,
+		ExemptPaths:       []string{"/metrics"},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	installRateLimitGinMiddleware(router, limiter)
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	router.GET(URLPathHealthz, ok)
+	router.GET(URLPathReadyz, ok)
+	router.GET("/v2"+URLPathHealthz, ok)
+	router.GET("/v2"+URLPathReadyz, ok)
+	router.GET("/metrics", ok)
+	router.GET("/v1/info", ok)
+
+	do := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("/v1/info", "10.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusOK, do("/v1/info", "10.0.0.1:1235").Code)
+
+	w := do("/v1/info", "10.0.0.1:1236")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate limit exceeded")
+
+	// the forwarded headers do not evade the limit
+	req := httptest.NewRequest(http.MethodGet, "/v1/info", nil)
+	req.RemoteAddr = "10.0.0.1:1237"
+	req.Header.Set("X-Forwarded-For", "192.168.0.1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// other clients and the exempted paths are not limited
+	assert.Equal(t, http.StatusOK, do("/v1/info", "10.0.0.2:1234").Code)
+	for i := 0; i < 5; i++ {
+		for _, p := range []string{URLPathHealthz, URLPathReadyz, "/v2" + URLPathHealthz, "/v2" + URLPathReadyz, "/metrics"} {
+			assert.Equal(t, http.StatusOK, do(p, "10.0.0.1:1234").Code, p)
+		}
+	}
+}
+
+func TestInstallRateLimitGinMiddlewareConcurrency(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(&ratelimit.Config{MaxConcurrentRequests: 1})
+	require.NoError(t, err)
+
+	router := gin.New()
+	installRateLimitGinMiddleware(router, limiter)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router.GET("/v1/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET(URLPathHealthz, func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/slow", nil))
+		done <- w.Code
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/slow", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "too many concurrent requests")
+
+	// health checks bypass the cap
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, URLPathHealthz, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestInstallRateLimitGinMiddlewareDisabled(t *testing.T) {
+	router := gin.New()
+	installRateLimitGinMiddleware(router, nil)
+	router.GET("/v1/info", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/info", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
@@ -32,7 +32,6 @@ import (
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
 	pkgmetricssyncer "github.com/leptonai/gpud/pkg/metrics/syncer"
-	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
 	pkgsession "github.com/leptonai/gpud/pkg/session"
 )
@@ -86,7 +85,7 @@ func TestNew_SuccessPathWithPprofAndPlugins_WithMockey(t *testing.T) {
 		mockey.Mock((*Server).updateToken).To(func(_ *Server, _ context.Context, _ pkgmetrics.Store, _ *UserToken) {
 			updateTokenCalled.Store(true)
 		}).Build()
-		mockey.Mock((*Server).startListener).To(func(_ *Server, _ nvidianvml.Instance, _ *pkgmetricssyncer.Syncer, _ *lepconfig.Config, _ *gin.Engine, _ tls.Certificate) {
+		mockey.Mock((*Server).startListener).To(func(_ *Server, _ *pkgmetricssyncer.Syncer, _ *lepconfig.Config, _ *gin.Engine, _ tls.Certificate) {
 			startListenerCalled.Store(true)
 		}).Build()
 		mockey.Mock(updateFromVersionFile).To(func(_ context.Context, _ int, _ string) {
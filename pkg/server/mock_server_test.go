@@ -28,6 +28,7 @@ import (
 	gpudmanager "github.com/leptonai/gpud/pkg/gpud-manager"
 	gpudpackages "github.com/leptonai/gpud/pkg/gpud-manager/packages"
 	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/jobs"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
@@ -43,13 +44,14 @@ import (
 
 type mockNVMLInstance struct {
 	shutdownCalled bool
+	devices        map[string]device.Device
 }
 
 func (m *mockNVMLInstance) NVMLExists() bool { return true }
 func (m *mockNVMLInstance) Library() nvmllib.Library {
 	return nil
 }
-func (m *mockNVMLInstance) Devices() map[string]device.Device { return nil }
+func (m *mockNVMLInstance) Devices() map[string]device.Device { return m.devices }
 func (m *mockNVMLInstance) ProductName() string               { return "mock-gpu" }
 func (m *mockNVMLInstance) Architecture() string              { return "mock-arch" }
 func (m *mockNVMLInstance) Brand() string                     { return "mock-brand" }
@@ -548,12 +550,15 @@ func TestDoCompact_CompactError(t *testing.T) {
 		require.NoError(t, err)
 		defer func() { _ = dbRW.Close() }()
 
+		jobManager, err := jobs.NewManager(context.Background(), dbRW, dbRW)
+		require.NoError(t, err)
+
 		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 		defer cancel()
 
 		done := make(chan struct{})
 		go func() {
-			doCompact(ctx, dbRW, 50*time.Millisecond)
+			doCompact(ctx, jobManager, dbRW, 50*time.Millisecond)
 			close(done)
 		}()
 
@@ -1196,7 +1201,7 @@ func TestDoCompact_NegativePeriod(t *testing.T) {
 
 		done := make(chan struct{})
 		go func() {
-			doCompact(ctx, dbRW, -1*time.Second)
+			doCompact(ctx, nil, dbRW, -1*time.Second)
 			close(done)
 		}()
 
@@ -1222,12 +1227,15 @@ func TestDoCompact_CompactSuccess(t *testing.T) {
 		require.NoError(t, err)
 		defer func() { _ = dbRW.Close() }()
 
+		jobManager, err := jobs.NewManager(context.Background(), dbRW, dbRW)
+		require.NoError(t, err)
+
 		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 		defer cancel()
 
 		done := make(chan struct{})
 		go func() {
-			doCompact(ctx, dbRW, 50*time.Millisecond)
+			doCompact(ctx, jobManager, dbRW, 50*time.Millisecond)
 			close(done)
 		}()
 
@@ -1327,7 +1335,8 @@ func TestCreateURL_ProtocolVariants(t *testing.T) {
 
 func TestStartListener_ListenErrorExits(t *testing.T) {
 	mockey.PatchConvey("startListener exits when ListenAndServeTLS fails", t, func() {
-		s := &Server{}
+		nvml := &mockNVMLInstance{}
+		s := &Server{gpudInstance: &components.GPUdInstance{NVMLInstance: nvml}}
 		cfg := &lepconfig.Config{
 			Address: "127.0.0.1:0",
 		}
@@ -1338,7 +1347,6 @@ func TestStartListener_ListenErrorExits(t *testing.T) {
 		cert, err := s.generateSelfSignedCert()
 		require.NoError(t, err)
 
-		nvml := &mockNVMLInstance{}
 		syncer := &pkgmetricssyncer.Syncer{}
 
 		exitCode := 0
@@ -1362,7 +1370,7 @@ func TestStartListener_ListenErrorExits(t *testing.T) {
 			return errors.New("listen failed")
 		}).Build()
 
-		s.startListener(nvml, syncer, cfg, router, cert)
+		s.startListener(syncer, cfg, router, cert)
 
 		assert.True(t, exitCalled, "expected os.Exit to be invoked")
 		assert.Equal(t, 1, exitCode)
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/leptonai/gpud/pkg/config"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+var (
+	// 256 bytes to 64 MiB
+	payloadSizeBuckets = prometheus.ExponentialBuckets(256, 4, 10)
+
+	metricRequestBodyBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "server",
+			Name:      "request_body_size_bytes",
+			Help:      "size of the API request bodies",
+			Buckets:   payloadSizeBuckets,
+		},
+		[]string{"route"},
+	)
+	metricResponseBodyBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "server",
+			Name:      "response_body_size_bytes",
+			Help:      "size of the API response bodies sent, after the compression if any",
+			Buckets:   payloadSizeBuckets,
+		},
+		[]string{"route", "encoding"},
+	)
+	metricResponseUncompressedBodyBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "server",
+			Name:      "response_uncompressed_body_size_bytes",
+			Help:      "size of the API response bodies before the compression",
+			Buckets:   payloadSizeBuckets,
+		},
+		[]string{"route"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricRequestBodyBytes,
+		metricResponseBodyBytes,
+		metricResponseUncompressedBodyBytes,
+	)
+}
+
+// installPayloadGinMiddleware installs the request body size limit
+// and the payload size metrics middleware.
+func installPayloadGinMiddleware(router *gin.Engine, maxRequestBodyBytes int64) {
+	if maxRequestBodyBytes <= 0 {
+		maxRequestBodyBytes = config.DefaultMaxRequestBodyBytes
+	}
+	router.Use(payloadMiddleware(maxRequestBodyBytes))
+}
+
+// payloadMiddleware rejects the request bodies larger than the limit with 413,
+// and records the request and response body sizes by the route template
+// (e.g., "/v1/info") to bound the label cardinality.
+func payloadMiddleware(maxRequestBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		if c.Request.ContentLength > maxRequestBodyBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    http.StatusRequestEntityTooLarge,
+				"message": fmt.Sprintf("request body too large (%d bytes, limit %d bytes)", c.Request.ContentLength, maxRequestBodyBytes),
+			})
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			// the chunked bodies of unknown length fail to read past the limit
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestBodyBytes)
+		}
+		if c.Request.ContentLength > 0 {
+			metricRequestBodyBytes.WithLabelValues(route).Observe(float64(c.Request.ContentLength))
+		}
+
+		c.Next()
+
+		size := c.Writer.Size()
+		if size <= 0 {
+			return
+		}
+		encoding := c.Writer.Header().Get("Content-Encoding")
+		if encoding == "" {
+			encoding = encodingIdentity
+		}
+		metricResponseBodyBytes.WithLabelValues(route, encoding).Observe(float64(size))
+
+		uncompressed := size
+		if v, ok := c.Get(ctxKeyUncompressedResponseSize); ok {
+			uncompressed = v.(int)
+		}
+		metricResponseUncompressedBodyBytes.WithLabelValues(route).Observe(float64(uncompressed))
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPayloadTestRouter(maxRequestBodyBytes int64, body string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	installPayloadGinMiddleware(router, maxRequestBodyBytes)
+	group := router.Group("/v1")
+	group.Use(compressionMiddleware(nil))
+	group.POST("/echo", func(c *gin.Context) {
+		b, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusBadRequest, "message": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(b))
+	})
+	group.GET("/info", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	return router
+}
+
+func TestPayloadMiddlewareRequestBodyLimit(t *testing.T) {
+	router := newPayloadTestRouter(16, "")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/echo", strings.NewReader("small")))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "small", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/echo", strings.NewReader(strings.Repeat("x", 17))))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large (17 bytes, limit 16 bytes)")
+
+	// unknown length, failed to read past the limit
+	req := httptest.NewRequest(http.MethodPost, "/v1/echo", strings.NewReader(strings.Repeat("x", 32)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+}
+
+// histogramSample returns the sample count and the sum of the histogram.
+func histogramSample(t *testing.T, vec *prometheus.HistogramVec, labels ...string) (uint64, float64) {
+	o, err := vec.GetMetricWithLabelValues(labels...)
+	require.NoError(t, err)
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestPayloadMiddlewareMetrics(t *testing.T) {
+	body := strings.Repeat("gpud ", 10000)
+	router := newPayloadTestRouter(0, body)
+
+	for _, encoding := range []string{encodingIdentity, encodingGzip, encodingZstd} {
+		wireCount, wireSum := histogramSample(t, metricResponseBodyBytes, "/v1/info", encoding)
+		rawCount, rawSum := histogramSample(t, metricResponseUncompressedBodyBytes, "/v1/info")
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/info", nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		count, sum := histogramSample(t, metricResponseBodyBytes, "/v1/info", encoding)
+		assert.Equal(t, wireCount+1, count, encoding)
+		assert.Equal(t, float64(w.Body.Len()), sum-wireSum, encoding)
+
+		count, sum = histogramSample(t, metricResponseUncompressedBodyBytes, "/v1/info")
+		assert.Equal(t, rawCount+1, count, encoding)
+		assert.Equal(t, float64(len(body)), sum-rawSum, encoding)
+
+		if encoding != encodingIdentity {
+			assert.Less(t, w.Body.Len(), len(body), encoding)
+		}
+	}
+
+	reqCount, reqSum := histogramSample(t, metricRequestBodyBytes, "/v1/echo")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/echo", strings.NewReader("hello")))
+	require.Equal(t, http.StatusOK, w.Code)
+	count, sum := histogramSample(t, metricRequestBodyBytes, "/v1/echo")
+	assert.Equal(t, reqCount+1, count)
+	assert.Equal(t, float64(5), sum-reqSum)
+}
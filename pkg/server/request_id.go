@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	apiv2 "github.com/leptonai/gpud/api/v2"
+)
+
+const (
+	// headerRequestID is the request header to pass the request ID from the client,
+	// and the response header to echo the request ID generated if not passed.
+	headerRequestID = "X-Request-ID"
+
+	// fieldRequestID is the log field and the error response field of the request ID.
+	fieldRequestID = "request_id"
+
+	// maxRequestIDLength is the max length of the request ID passed by the client,
+	// longer or non-printable IDs are replaced with the generated ones.
+	maxRequestIDLength = 128
+)
+
+// sanitizeRequestIDMiddleware drops the invalid request ID sent by the client,
+// so that the request ID middleware generates a new one,
+// rather than echoing the arbitrary bytes into the logs and the responses.
+func sanitizeRequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rid := c.GetHeader(headerRequestID); rid != "" && !validRequestID(rid) {
+			c.Request.Header.Del(headerRequestID)
+		}
+		c.Next()
+	}
+}
+
+// validRequestID returns true if the request ID is printable ASCII within the max length.
+func validRequestID(rid string) bool {
+	if len(rid) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(rid); i++ {
+		if rid[i] < 0x21 || rid[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// accessLogFields returns the request ID field of the access log entry.
+func accessLogFields(c *gin.Context) []zapcore.Field {
+	return []zapcore.Field{zap.String(fieldRequestID, requestid.Get(c))}
+}
+
+// errorRequestIDMiddleware echoes the request ID in the JSON error responses
+// (e.g., {"code":404,"message":"component not found","request_id":"..."}),
+// so that the clients can report the failed requests with the correlatable identifiers.
+// The successful, the non-JSON, and the v2 envelope responses
+// (already with the request ID) are written as is.
+//
+// The responses compressed by the downstream middlewares are written as is,
+// thus install after the compression middleware to echo the request ID in the compressed responses.
+func errorRequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &errorRequestIDWriter{
+			ResponseWriter: c.Writer,
+			// the upstream compression middleware sets the header before the handler,
+			// and compresses the body written here
+			compressedUpstream: c.Writer.Header().Get("Content-Encoding") != "",
+		}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if !w.buffered {
+			return
+		}
+		body := w.buf.Bytes()
+		if b, ok := withRequestID(body, requestid.Get(c)); ok {
+			body = b
+		}
+		if w.Header().Get("Content-Length") != "" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
+
+// withRequestID returns the JSON object with the request ID field added,
+// or false if the body is not a JSON object or already has the field.
+func withRequestID(body []byte, rid string) ([]byte, bool) {
+	if rid == "" {
+		return nil, false
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, false
+	}
+	if _, ok := obj[fieldRequestID]; ok {
+		return nil, false
+	}
+
+	v, err := json.Marshal(rid)
+	if err != nil {
+		return nil, false
+	}
+
+	// insert as the last field, to keep the order of the other fields
+	trimmed := bytes.TrimSpace(body)
+	inner := bytes.TrimSpace(trimmed[1 : len(trimmed)-1])
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	if len(inner) > 0 {
+		b.Write(inner)
+		b.WriteByte(',')
+	}
+	b.WriteString(`"` + fieldRequestID + `":`)
+	b.Write(v)
+	b.WriteByte('}')
+	return b.Bytes(), true
+}
+
+var _ gin.ResponseWriter = &errorRequestIDWriter{}
+
+// errorRequestIDWriter buffers the JSON error response body.
+type errorRequestIDWriter struct {
+	gin.ResponseWriter
+	compressedUpstream bool
+
+	decided  bool
+	buffered bool
+	buf      bytes.Buffer
+}
+
+// decide decides whether to buffer the response body on the first write.
+func (w *errorRequestIDWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	h := w.Header()
+	w.buffered = w.Status() >= http.StatusBadRequest &&
+		strings.Contains(h.Get("Content-Type"), "json") &&
+		(w.compressedUpstream || h.Get("Content-Encoding") == "") &&
+		h.Get(apiv2.ResponseHeaderAPIVersion) == ""
+}
+
+func (w *errorRequestIDWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.buffered {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorRequestIDWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffered {
+		return w.buf.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
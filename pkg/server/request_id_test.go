@@ -0,0 +1,155 @@
+package server
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/leptonai/gpud/pkg/errdefs"
+)
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, validRequestID("3f2b9c1e-7d4a-4c1b-9a8e-0f6d5c4b3a21"))
+	assert.False(t, validRequestID("has space"))
+	assert.False(t, validRequestID("line\nbreak"))
+	assert.False(t, validRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
+
+func TestWithRequestID(t *testing.T) {
+	b, ok := withRequestID([]byte(`{"code":"not_found","message":"component not found"}`), "abc")
+	require.True(t, ok)
+	assert.Equal(t, `{"code":"not_found","message":"component not found","request_id":"abc"}`, string(b))
+
+	b, ok = withRequestID([]byte(" {} \n"), "abc")
+	require.True(t, ok)
+	assert.Equal(t, `{"request_id":"abc"}`, string(b))
+
+	// already set, not an object, or no request ID
+	_, ok = withRequestID([]byte(`{"request_id":"x"}`), "abc")
+	assert.False(t, ok)
+	_, ok = withRequestID([]byte(`["a"]`), "abc")
+	assert.False(t, ok)
+	_, ok = withRequestID([]byte(`not json`), "abc")
+	assert.False(t, ok)
+	_, ok = withRequestID([]byte(`{}`), "")
+	assert.False(t, ok)
+}
+
+func newRequestIDTestRouter() *gin.Engine {
+	router := gin.New()
+	installRootGinMiddlewares(router)
+
+	router.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/not-found", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found"})
+	})
+	router.GET("/text-error", func(c *gin.Context) {
+		c.String(http.StatusBadRequest, "bad request")
+	})
+
+	group := router.Group("/v1")
+	group.Use(compressionMiddleware(nil), errorRequestIDMiddleware())
+	group.GET("/not-found", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"code": errdefs.ErrNotFound, "message": "component not found"})
+	})
+	return router
+}
+
+func TestErrorRequestIDMiddleware(t *testing.T) {
+	router := newRequestIDTestRouter()
+
+	t.Run("generated request ID echoed in the error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/not-found", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		rid := w.Header().Get(headerRequestID)
+		require.NotEmpty(t, rid)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, rid, body[fieldRequestID])
+		assert.Equal(t, "component not found", body["message"])
+	})
+
+	t.Run("client request ID reused", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/not-found", nil)
+		req.Header.Set(headerRequestID, "client-id-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "client-id-1", w.Header().Get(headerRequestID))
+		assert.Contains(t, w.Body.String(), `"request_id":"client-id-1"`)
+	})
+
+	t.Run("invalid client request ID replaced", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/not-found", nil)
+		req.Header.Set(headerRequestID, strings.Repeat("a", maxRequestIDLength+1))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		rid := w.Header().Get(headerRequestID)
+		assert.NotEmpty(t, rid)
+		assert.LessOrEqual(t, len(rid), maxRequestIDLength)
+	})
+
+	t.Run("success and non-JSON responses unchanged", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+		assert.Equal(t, `{"message":"ok"}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/text-error", nil))
+		assert.Equal(t, "bad request", w.Body.String())
+	})
+
+	t.Run("compressed error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/not-found", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		r, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(b, &body))
+		assert.Equal(t, w.Header().Get(headerRequestID), body[fieldRequestID])
+	})
+}
+
+func TestAccessLogRequestID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	router := gin.New()
+	installRootGinMiddlewares(router)
+	installCommonGinMiddlewares(router, zap.New(core))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(headerRequestID, "client-id-2")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterField(zap.String(fieldRequestID, "client-id-2")).All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.EqualValues(t, http.StatusOK, fields["status"])
+	assert.Contains(t, fields, "latency")
+}
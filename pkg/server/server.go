@@ -23,33 +23,58 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginswagger "github.com/swaggo/gin-swagger"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	apiv2 "github.com/leptonai/gpud/api/v2"
 	"github.com/leptonai/gpud/components"
-	"github.com/leptonai/gpud/components/all"
 	_ "github.com/leptonai/gpud/docs/apis"
+	"github.com/leptonai/gpud/pkg/boottracker"
+	"github.com/leptonai/gpud/pkg/budget"
+	"github.com/leptonai/gpud/pkg/changetrack"
+	pkgchaos "github.com/leptonai/gpud/pkg/chaos"
+	"github.com/leptonai/gpud/pkg/clockskew"
 	lepconfig "github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/contracts"
+	"github.com/leptonai/gpud/pkg/crashreport"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/disposition"
+	"github.com/leptonai/gpud/pkg/drain"
+	"github.com/leptonai/gpud/pkg/driverupgrade"
+	"github.com/leptonai/gpud/pkg/eventmetrics"
 	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgexternalcomponents "github.com/leptonai/gpud/pkg/external-components"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/gossip"
 	gpudmanager "github.com/leptonai/gpud/pkg/gpud-manager"
+	"github.com/leptonai/gpud/pkg/gpureset"
+	"github.com/leptonai/gpud/pkg/gpuscore"
+	pkghealthstate "github.com/leptonai/gpud/pkg/healthstate"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/httputil"
+	"github.com/leptonai/gpud/pkg/jobs"
 	pkgkmsgwriter "github.com/leptonai/gpud/pkg/kmsg/writer"
 	"github.com/leptonai/gpud/pkg/log"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+	"github.com/leptonai/gpud/pkg/maintenance"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgmetricsrecorder "github.com/leptonai/gpud/pkg/metrics/recorder"
 	pkgmetricsscraper "github.com/leptonai/gpud/pkg/metrics/scraper"
 	pkgmetricsstore "github.com/leptonai/gpud/pkg/metrics/store"
 	pkgmetricssyncer "github.com/leptonai/gpud/pkg/metrics/syncer"
+	pkgmigrations "github.com/leptonai/gpud/pkg/migrations"
+	"github.com/leptonai/gpud/pkg/mute"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/postgres"
+	"github.com/leptonai/gpud/pkg/ratelimit"
+	"github.com/leptonai/gpud/pkg/rbac"
 	"github.com/leptonai/gpud/pkg/session"
+	"github.com/leptonai/gpud/pkg/session/upload"
+	"github.com/leptonai/gpud/pkg/slo"
 	"github.com/leptonai/gpud/pkg/sqlite"
 	pkgupdate "github.com/leptonai/gpud/pkg/update"
 )
@@ -60,6 +85,8 @@ type Server struct {
 
 	dbRW *sql.DB
 	dbRO *sql.DB
+	// pgDB is the shared database for the metrics and events, nil if not configured
+	pgDB *sql.DB
 
 	// initRegistry is the registry for init plugins
 	// that runs before the regular components
@@ -86,14 +113,37 @@ type Server struct {
 	dbInMemory bool
 
 	gpudInstance *components.GPUdInstance
-	session      *session.Session
+
+	// sessionMu guards the session, replaced on the token updates
+	sessionMu sync.RWMutex
+	session   *session.Session
 
 	enableAutoUpdate        bool
 	autoUpdateExitCode      int
 	skipSessionUpdateConfig bool
+	sessionUploadConfig     *upload.Config
+	// dataBudget caps the events, the metric samples, and the uploads, nil if not set up
+	dataBudget *budget.Budget
+	// machineStates manages the machine state set by the control plane or the local API
+	machineStates *machinestate.Manager
 
 	pluginSpecsFile string
-	faultInjector   pkgfaultinjector.Injector
+	// externalComponents is the registry of the external components, nil if disabled
+	externalComponents *pkgexternalcomponents.Server
+	faultInjector      pkgfaultinjector.Injector
+	// chaos injects the random internal failures, nil if the chaos mode is disabled
+	chaos *pkgchaos.Injector
+
+	// clientCAs verifies the client certificates for RBAC, nil if disabled
+	clientCAs *x509.CertPool
+
+	// gossipAgent exchanges the health summaries with the peers, nil if disabled
+	gossipAgent *gossip.Agent
+
+	// startup tracks the background component initialization
+	startup *startupTracker
+	// startupCancel cancels the component initialization, nil if not started
+	startupCancel context.CancelFunc
 }
 
 type UserToken struct {
@@ -125,30 +175,57 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 	if config.State == "" {
 		config.State = lepconfig.StateFilePath(config.DataDir)
 	}
+	if config.CrashReportDir == "" {
+		config.CrashReportDir = lepconfig.CrashReportDirPath(config.DataDir)
+	}
+	// set before starting the components, to report the panics in their first checks
+	crashreport.SetWriter(crashreport.NewWriter(config.CrashReportDir, config.MaxCrashReports))
+
+	var chaosInjector *pkgchaos.Injector
+	if config.Chaos != nil {
+		chaosInjector, err = pkgchaos.New(*config.Chaos)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create chaos injector: %w", err)
+		}
+		// must be set before opening the databases
+		sqlite.SetWriteFailureFunc(chaosInjector.FailFunc(pkgchaos.BoundarySQLiteWrite))
+		log.Logger.Warnw("chaos mode enabled, failures are injected at random", "config", chaosInjector.Config())
+	}
 
 	var dbRW, dbRO *sql.DB
+	var dbFiles []string
 	if config.DBInMemory {
 		// Use shared in-memory database for both read-write and read-only connections
 		// ref. https://github.com/mattn/go-sqlite3?tab=readme-ov-file#faq
-		dbRW, err = sqlite.Open(":memory:", sqlite.WithCache("shared"))
+		dbRW, err = sqlite.Open(":memory:", sqlite.WithCache("shared"), sqlite.WithConfig(config.SQLite))
 		if err != nil {
 			return nil, fmt.Errorf("failed to open in-memory database (for read-write): %w", err)
 		}
-		dbRO, err = sqlite.Open(":memory:", sqlite.WithCache("shared"), sqlite.WithReadOnly(true))
+		dbRO, err = sqlite.Open(":memory:", sqlite.WithCache("shared"), sqlite.WithReadOnly(true), sqlite.WithConfig(config.SQLite))
 		if err != nil {
 			return nil, fmt.Errorf("failed to open in-memory database (for read-only): %w", err)
 		}
 		log.Logger.Infow("using in-memory SQLite database", "connection", "file::memory:?cache=shared")
 	} else {
 		// File-based database (config.State is guaranteed to be set earlier)
-		dbRW, err = sqlite.Open(config.State)
+		dbRW, err = sqlite.Open(config.State, sqlite.WithConfig(config.SQLite))
 		if err != nil {
 			return nil, fmt.Errorf("failed to open state file (for read-write): %w", err)
 		}
-		dbRO, err = sqlite.Open(config.State, sqlite.WithReadOnly(true))
+		dbRO, err = sqlite.Open(config.State, sqlite.WithReadOnly(true), sqlite.WithConfig(config.SQLite))
 		if err != nil {
 			return nil, fmt.Errorf("failed to open state file (for read-only): %w", err)
 		}
+		dbFiles = append(dbFiles, config.State)
+	}
+
+	// migrate the existing tables before the stores create or read them
+	appliedMigrations, err := pkgmigrations.Apply(ctx, dbRW)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply schema migrations: %w", err)
+	}
+	for _, m := range appliedMigrations {
+		log.Logger.Infow("applied schema migration", "version", m.Version, "name", m.Name)
 	}
 
 	if err := pkgmetadata.CreateTableMetadata(ctx, dbRW); err != nil {
@@ -198,13 +275,97 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 		// Backward-compatible fallback for callers that still only populate retention_period.
 		eventsRetentionPeriod = config.MetricsRetentionPeriod.Duration
 	}
-	eventStore, err := eventstore.New(dbRW, dbRO, eventsRetentionPeriod)
+	// the metrics and events are stored in the shared database if configured,
+	// scoped by the machine ID of this instance
+	var pgDB *sql.DB
+	var storeMachineID string
+	if config.PostgresDSN != "" {
+		pgDB, err = postgres.Open(ctx, config.PostgresDSN)
+		if err != nil {
+			return nil, err
+		}
+		appliedMigrations, err := pkgmigrations.ApplyPostgres(ctx, pgDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply postgres schema migrations: %w", err)
+		}
+		for _, m := range appliedMigrations {
+			log.Logger.Infow("applied postgres schema migration", "version", m.Version, "name", m.Name)
+		}
+		storeMachineID, err = pkgmetadata.ReadMachineID(ctx, dbRO)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read machine id: %w", err)
+		}
+		if storeMachineID == "" {
+			storeMachineID = pkghost.MachineID()
+		}
+		if _, err := pkgmetadata.MirrorToPostgres(ctx, dbRO, pgDB, storeMachineID); err != nil {
+			log.Logger.Warnw("failed to mirror metadata to postgres", "error", err)
+		}
+		log.Logger.Infow("using postgres database for metrics and events", "machineID", storeMachineID)
+	}
+
+	var eventStore eventstore.Store
+	if pgDB != nil {
+		eventStore, err = eventstore.NewPostgres(pgDB, eventsRetentionPeriod, config.EventsRetentionPolicy, storeMachineID)
+	} else {
+		eventStore, err = eventstore.NewWithRetentionPolicy(dbRW, dbRO, eventsRetentionPeriod, config.EventsRetentionPolicy)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open events database: %w", err)
 	}
 
+	// annotate the events and the metric samples timestamped after the backward wall clock jump
+	// (e.g., the NTP step), until the wall clock catches up with the already recorded timestamps
+	clockSkewDetector := clockskew.New(clockskew.DefaultThreshold)
+	go clockSkewDetector.Start(ctx, clockskew.DefaultInterval)
+	eventStore = clockskew.WrapEventStore(eventStore, clockSkewDetector)
+
+	// track the changes of the data of each component, so that the polling requests
+	// with the "If-None-Match" or "If-Modified-Since" headers respond 304 for the unchanged data
+	changes := changetrack.New()
+	eventStore = changetrack.WrapEventStore(eventStore, changes)
+
+	maintenanceManager, err := maintenance.NewManager(ctx, dbRW, dbRO, config.MaintenanceWindows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window manager: %w", err)
+	}
+
+	componentMutes, err := mute.NewManager(ctx, dbRW, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create component mute manager: %w", err)
+	}
+
+	jobManager, err := jobs.NewManager(ctx, dbRW, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job manager: %w", err)
+	}
+
+	eventDispositions, err := disposition.NewManager(ctx, dbRW, dbRO, config.EventDispositionThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event disposition manager: %w", err)
+	}
+
+	// persist the last health states so that the pre-restart health states
+	// are reported (as stale) until the components complete the first check
+	healthStateStore, err := pkghealthstate.NewStore(ctx, dbRW, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health state store: %w", err)
+	}
+	if eventsRetentionPeriod > 0 {
+		if err := healthStateStore.Purge(ctx, time.Now().Add(-eventsRetentionPeriod)); err != nil {
+			return nil, fmt.Errorf("failed to purge health states: %w", err)
+		}
+	}
+
 	rebootEventStore := pkghost.NewRebootEventStore(eventStore)
 
+	// the reset windows tag the expected Xid and SXid events while the GPUs are reset
+	gpuResetBucket, err := eventStore.Bucket(gpureset.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gpu reset event bucket: %w", err)
+	}
+	gpuResets := gpureset.New(gpuResetBucket, rebootEventStore)
+
 	// only record once when we create the server instance
 	cctx, ccancel := context.WithTimeout(ctx, time.Minute)
 	err = rebootEventStore.RecordReboot(cctx)
@@ -213,18 +374,43 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 		log.Logger.Errorw("failed to record reboot", "error", err)
 	}
 
+	// track the boot IDs to correlate the fatal events with the following reboots
+	var bootTracker *boottracker.Tracker
+	if bootID, bootTime := pkghost.BootID(), pkghost.BootTime(); bootID != "" && !bootTime.IsZero() {
+		bootTracker, err = boottracker.New(ctx, dbRW, dbRO, bootID, bootTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create boot tracker: %w", err)
+		}
+		if eventsRetentionPeriod > 0 {
+			if err := bootTracker.Purge(ctx, time.Now().Add(-eventsRetentionPeriod)); err != nil {
+				return nil, fmt.Errorf("failed to purge boots: %w", err)
+			}
+		}
+		go bootTracker.Start(ctx, boottracker.DefaultHeartbeatInterval)
+	} else {
+		log.Logger.Warnw("boot id or boot time not found, skipping boot tracking")
+	}
+
 	promScraper, err := pkgmetricsscraper.NewPrometheusScraper(pkgmetrics.DefaultGatherer())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scraper: %w", err)
 	}
-	metricsSQLiteStore, err := pkgmetricsstore.NewSQLiteStore(ctx, dbRW, dbRO, pkgmetricsstore.DefaultTableName)
+	var metricsStore pkgmetrics.Store
+	if pgDB != nil {
+		metricsStore, err = pkgmetricsstore.NewPostgresStore(ctx, pgDB, pkgmetricsstore.DefaultTableName, storeMachineID)
+	} else {
+		metricsStore, err = pkgmetricsstore.NewSQLiteStore(ctx, dbRW, dbRO, pkgmetricsstore.DefaultTableName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metrics store: %w", err)
 	}
+	metricsStore = clockskew.WrapMetricsStore(metricsStore, clockSkewDetector)
+	metricsStore = changetrack.WrapMetricsStore(metricsStore, changes)
+	dataBudget := budget.New(config.DataBudget)
 	syncer := pkgmetricssyncer.NewSyncer(
 		ctx,
 		promScraper,
-		metricsSQLiteStore,
+		budget.WrapMetricsStore(metricsStore, dataBudget),
 		time.Minute,
 		time.Minute,
 		config.MetricsRetentionPeriod.Duration,
@@ -240,6 +426,7 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 
 		dbRW: dbRW,
 		dbRO: dbRO,
+		pgDB: pgDB,
 
 		fifoPath:   fifoPath,
 		dataDir:    config.DataDir,
@@ -248,8 +435,12 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 		enableAutoUpdate:        config.EnableAutoUpdate,
 		autoUpdateExitCode:      config.AutoUpdateExitCode,
 		skipSessionUpdateConfig: config.SkipSessionUpdateConfig,
+		sessionUploadConfig:     config.SessionUpload,
 
 		pluginSpecsFile: config.PluginSpecsFile,
+
+		chaos:      chaosInjector,
+		dataBudget: dataBudget,
 	}
 	defer func() {
 		if retErr != nil {
@@ -262,150 +453,262 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 		return nil, fmt.Errorf("failed to read machine uid: %w", err)
 	}
 
+	s.machineStates, err = machinestate.NewManager(ctx, dbRW, dbRO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine state manager: %w", err)
+	}
+
 	kmsgWriter := pkgkmsgwriter.NewWriter(pkgkmsgwriter.DefaultDevKmsg)
 	s.faultInjector = pkgfaultinjector.NewInjector(kmsgWriter)
 
-	var nvmlInstance nvidianvml.Instance
+	var nvmlFailureInjector *nvidianvml.FailureInjectorConfig
 	if config.FailureInjector != nil && (len(config.FailureInjector.GPUUUIDsWithGPULost) > 0 ||
 		len(config.FailureInjector.GPUUUIDsWithGPURequiresReset) > 0 ||
 		len(config.FailureInjector.GPUUUIDsWithFabricStateHealthSummaryUnhealthy) > 0 ||
 		config.FailureInjector.GPUProductNameOverride != "" ||
-		config.FailureInjector.NVMLDeviceGetDevicesError) {
-		// If failure injector is configured for NVML-level errors or product name override, use it
-		nvmlInstance, err = nvidianvml.NewWithFailureInjector(&nvidianvml.FailureInjectorConfig{
+		config.FailureInjector.NVMLDeviceGetDevicesError ||
+		len(config.FailureInjector.GPUFieldOverrides) > 0) {
+		nvmlFailureInjector = &nvidianvml.FailureInjectorConfig{
 			GPUUUIDsWithGPULost:                           config.FailureInjector.GPUUUIDsWithGPULost,
 			GPUUUIDsWithGPURequiresReset:                  config.FailureInjector.GPUUUIDsWithGPURequiresReset,
 			GPUUUIDsWithFabricStateHealthSummaryUnhealthy: config.FailureInjector.GPUUUIDsWithFabricStateHealthSummaryUnhealthy,
 			GPUProductNameOverride:                        config.FailureInjector.GPUProductNameOverride,
 			NVMLDeviceGetDevicesError:                     config.FailureInjector.NVMLDeviceGetDevicesError,
-		})
-	} else {
-		nvmlInstance, err = nvidianvml.NewWithExitOnSuccessfulLoad(ctx)
+			GPUFieldOverrides:                             config.FailureInjector.GPUFieldOverrides,
+		}
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to create NVML instance: %w", err)
+	if timeoutFunc := s.chaos.FailFunc(pkgchaos.BoundaryNVML); timeoutFunc != nil {
+		if nvmlFailureInjector == nil {
+			nvmlFailureInjector = &nvidianvml.FailureInjectorConfig{}
+		}
+		nvmlFailureInjector.NVMLTimeoutFunc = timeoutFunc
+		nvmlFailureInjector.NVMLTimeoutDelay = s.chaos.Config().NVMLTimeoutDelay.Duration
+	}
+
+	// the events of the components are capped by the data budget,
+	// while the reboot events are always recorded,
+	// and counted as the metrics before sampled by the budget
+	componentsEventStore := eventmetrics.WrapEventStore(budget.WrapEventStore(eventStore, dataBudget))
+	if config.ValidateContracts {
+		// the events violating the contracts are neither counted nor recorded
+		componentsEventStore = contracts.WrapEventStore(componentsEventStore)
 	}
 
+	// NVML instance is set once initialized in the background
 	s.gpudInstance = &components.GPUdInstance{
 		RootCtx: ctx,
 
 		MachineID: s.machineID,
 
-		NVMLInstance:         nvmlInstance,
 		NVIDIAToolOverwrites: config.NvidiaToolOverwrites,
 
 		DBRW: dbRW,
 		DBRO: dbRO,
 
-		EventStore:       eventStore,
+		DBFiles: dbFiles,
+
+		EventStore:       componentsEventStore,
 		RebootEventStore: rebootEventStore,
 
+		MetricsStore: metricsStore,
+
 		MountPoints:  []string{"/"},
 		MountTargets: []string{"/var/lib/kubelet"},
 
 		FailureInjector: config.FailureInjector,
+		Chaos:           s.chaos,
+
+		EventDispositions: eventDispositions,
+		GPUResets:         gpuResets,
+
+		ListenAddress: config.Address,
 	}
 	if s.gpudInstance.MachineID == "" {
 		s.gpudInstance.MachineID = pkghost.MachineID()
 		log.Logger.Infow("assigned machine id not found, using host level machine ID", "machineID", s.gpudInstance.MachineID)
 	}
 
+	// the components are registered in the background, while the server is already serving
 	s.componentsRegistry = components.NewRegistry(s.gpudInstance)
-	for _, c := range all.All() {
-		name := c.Name
-
-		shouldEnable := config.ShouldEnable(name)
-		if config.ShouldDisable(name) {
-			shouldEnable = false
-		}
-
-		if shouldEnable {
-			s.componentsRegistry.MustRegister(c.InitFunc)
-		}
-	}
-
-	// must be registered before starting the components
 	s.initRegistry = components.NewRegistry(s.gpudInstance)
-	if config.PluginSpecsFile != "" {
-		_, err := stdos.Stat(config.PluginSpecsFile)
-		exists := err == nil
+	s.startup = newStartupTracker()
 
-		if exists {
-			specs, err := pkgcustomplugins.LoadSpecs(config.PluginSpecsFile)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load plugin specs: %w", err)
-			}
+	go doCompact(ctx, jobManager, dbRW, config.CompactPeriod.Duration)
 
-			for _, spec := range specs {
-				initFunc := spec.NewInitFunc()
-				if initFunc == nil {
-					log.Logger.Errorw("failed to load plugin", "name", spec.ComponentName())
-					continue
-				}
-
-				if spec.PluginType == pkgcustomplugins.SpecTypeInit {
-					s.initRegistry.MustRegister(initFunc)
-					log.Logger.Infow("loaded init plugin", "name", spec.ComponentName())
-				} else {
-					s.componentsRegistry.MustRegister(initFunc)
-					log.Logger.Infow("loaded component plugin", "name", spec.ComponentName())
-				}
-			}
-		} else {
-			log.Logger.Warnw("plugin specs file does not exist, skipping", "path", config.PluginSpecsFile)
-		}
+	cert, err := s.generateSelfSignedCert()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tls cert: %w", err)
 	}
 
-	// init plugin run only "once", and "before" regular components
-	// thus no need to start
-	for _, c := range s.initRegistry.All() {
-		rs := c.Check()
-		if rs.HealthStateType() != apiv1.HealthStateTypeHealthy {
-			return nil, fmt.Errorf("failed to start init plugin %s: %s", c.Name(), rs.Summary())
+	var authorizer *rbac.Authorizer
+	if config.RBAC != nil {
+		authorizer, err = rbac.NewAuthorizer(config.RBAC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rbac authorizer: %w", err)
 		}
-		log.Logger.Infow("successfully executed init plugin", "name", c.Name(), "summary", rs.Summary())
-
-		debugger, ok := rs.(components.CheckResultDebugger)
-		if ok {
-			fmt.Printf("init plugin debug output %q:\n\n%s\n\n", c.Name(), debugger.Debug())
+		s.clientCAs, err = config.RBAC.ClientCAs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rbac client cas: %w", err)
 		}
+		log.Logger.Infow("rbac enabled", "tokens", len(config.RBAC.Tokens), "tokensFile", config.RBAC.TokensFile, "clientCAFile", config.RBAC.ClientCAFile, "anonymousRole", config.RBAC.AnonymousRole)
 	}
 
-	// component must be started after initialization
-	for _, c := range s.componentsRegistry.All() {
-		if err = c.Start(); err != nil {
-			return nil, fmt.Errorf("failed to start component %s: %w", c.Name(), err)
+	var limiter *ratelimit.Limiter
+	if config.RateLimit != nil {
+		limiter, err = ratelimit.NewLimiter(config.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rate limiter: %w", err)
 		}
+		log.Logger.Infow("api rate limit enabled", "requestsPerSecond", config.RateLimit.RequestsPerSecond, "burst", config.RateLimit.Burst, "maxConcurrentRequests", config.RateLimit.MaxConcurrentRequests)
 	}
-	go doCompact(ctx, dbRW, config.CompactPeriod.Duration)
 
-	cert, err := s.generateSelfSignedCert()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate tls cert: %w", err)
+	var sloTracker *slo.Tracker
+	if config.SLO != nil {
+		sloBucket, err := eventStore.Bucket(slo.BucketName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create slo event bucket: %w", err)
+		}
+		sloTracker = slo.New(*config.SLO, sloBucket)
+		sloTracker.Start(ctx)
+		// read by the components once initialized
+		s.gpudInstance.CheckDurationObserver = sloTracker.ObserveComponentCheck
+		log.Logger.Infow("latency objectives enabled", "objectives", len(config.SLO.Objectives))
 	}
 
 	router := gin.Default()
 	installRootGinMiddlewares(router)
 	installCommonGinMiddlewares(router, log.Logger.Desugar())
+	installRateLimitGinMiddleware(router, limiter)
+	installPayloadGinMiddleware(router, config.MaxRequestBodyBytes)
+	installRBACGinMiddleware(router, authorizer)
+	installStartupGinMiddleware(router, s.startup)
+	installSLOGinMiddleware(router, sloTracker)
+
+	globalHandler := newGlobalHandler(config, s.componentsRegistry, metricsStore, s.gpudInstance, s.faultInjector)
+	globalHandler.maintenanceManager = maintenanceManager
+	globalHandler.componentMutes = componentMutes
+	globalHandler.jobs = jobManager
+	globalHandler.syncStatusFunc = s.syncStatus
+	globalHandler.machineStates = s.machineStates
+	globalHandler.eventDispositions = eventDispositions
+	globalHandler.bootTracker = bootTracker
+	globalHandler.healthStateStore = healthStateStore
+	globalHandler.startup = s.startup
+	globalHandler.changes = changes
+	globalHandler.sloTracker = sloTracker
+	globalHandler.drainChecker = drain.New(s.gpudInstance, s.componentsRegistry, config.DrainReadiness)
+	globalHandler.gpuScorer = gpuscore.New(metricsStore, config.GPUPerformanceScore)
+	globalHandler.gpuScorer.Start(ctx)
+
+	driverUpgradeBucket, err := eventStore.Bucket(driverupgrade.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver upgrade event bucket: %w", err)
+	}
+	// the revalidation checks are triggered as in the batch trigger-check,
+	// started once the components are started
+	globalHandler.driverUpgrades = driverupgrade.New(config.DriverUpgrade, dbRW, dbRO, driverUpgradeBucket, gpuResets, s.componentsRegistry,
+		func(comps []components.Component, timeout time.Duration) apiv1.ComponentCheckResults {
+			return triggerChecks("", comps, timeout)
+		},
+	)
 
-	globalHandler := newGlobalHandler(config, s.componentsRegistry, metricsSQLiteStore, s.gpudInstance, s.faultInjector)
+	hostname, err := stdos.Hostname()
+	if err != nil {
+		log.Logger.Warnw("failed to get hostname", "error", err)
+	}
+	node := apiv2.Node{MachineID: s.gpudInstance.MachineID, Hostname: hostname}
+
+	if config.Gossip != nil {
+		s.gossipAgent, err = gossip.New(ctx, *config.Gossip, s.gpudInstance.MachineID, hostname, globalHandler.healthSummary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gossip agent: %w", err)
+		}
+		s.gossipAgent.Start()
+		globalHandler.gossipAgent = s.gossipAgent
+		log.Logger.Infow("gossip enabled", "address", s.gossipAgent.Addr(), "join", config.Gossip.Join)
+	}
 
-	// if the request header is set "Accept-Encoding: gzip",
-	// the middleware automatically gzip-compresses the response with the response header "Content-Encoding: gzip"
-	v1Group := router.Group("/v1")
-	v1Group.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/update/"})))
+	// if the request header is set "Accept-Encoding: zstd" or "Accept-Encoding: gzip",
+	// the middleware compresses the response with the response header "Content-Encoding" set
+	// the v1 responses are wrapped in the v2 envelope only if requested by the "Accept" header
+	v1Group := router.Group(urlPathV1)
+	v1Group.Use(compressionMiddleware([]string{"/update/", urlPathV1 + URLPathLogsTail}), negotiateAPIVersionMiddleware(node), errorRequestIDMiddleware())
 	globalHandler.registerComponentRoutes(v1Group)
 	globalHandler.registerPluginRoutes(v1Group)
+	globalHandler.registerStatusRoutes(v1Group)
+	globalHandler.registerSummaryRoutes(v1Group)
+	globalHandler.registerCapabilitiesRoutes(v1Group)
+	globalHandler.registerLogsRoutes(v1Group)
+	globalHandler.registerMaintenanceRoutes(v1Group)
+	globalHandler.registerMuteRoutes(v1Group)
+	globalHandler.registerJobRoutes(v1Group)
+	globalHandler.registerSyncRoutes(v1Group)
+	globalHandler.registerMachineStateRoutes(v1Group)
+	globalHandler.registerDispositionRoutes(v1Group)
+	globalHandler.registerRebootRoutes(v1Group)
+	globalHandler.registerTimelineRoutes(v1Group)
+	globalHandler.registerGPUMappingRoutes(v1Group)
+	globalHandler.registerGPUsRoutes(v1Group)
+	globalHandler.registerStartupRoutes(v1Group)
+	globalHandler.registerClusterRoutes(v1Group)
+	globalHandler.registerSLORoutes(v1Group)
+	globalHandler.registerGPUResetRoutes(v1Group)
+	globalHandler.registerDriverUpgradeRoutes(v1Group)
+	globalHandler.registerDrainRoutes(v1Group)
+	if config.DebugSnapshot {
+		log.Logger.Infow("registering debug snapshot handler")
+		globalHandler.registerDebugRoutes(v1Group)
+	}
 
-	promHandler := promhttp.HandlerFor(pkgmetrics.DefaultGatherer(), promhttp.HandlerOpts{})
+	// the v2 routes serve the same handlers, with every response wrapped in the v2 envelope
+	v2Group := router.Group(urlPathV2)
+	v2Group.Use(compressionMiddleware([]string{urlPathV2 + URLPathLogsTail}), envelopeMiddleware(node))
+	globalHandler.registerComponentRoutes(v2Group)
+	globalHandler.registerPluginRoutes(v2Group)
+	globalHandler.registerStatusRoutes(v2Group)
+	globalHandler.registerSummaryRoutes(v2Group)
+	globalHandler.registerCapabilitiesRoutes(v2Group)
+	globalHandler.registerLogsRoutes(v2Group)
+	globalHandler.registerMaintenanceRoutes(v2Group)
+	globalHandler.registerMuteRoutes(v2Group)
+	globalHandler.registerJobRoutes(v2Group)
+	globalHandler.registerSyncRoutes(v2Group)
+	globalHandler.registerMachineStateRoutes(v2Group)
+	globalHandler.registerDispositionRoutes(v2Group)
+	globalHandler.registerRebootRoutes(v2Group)
+	globalHandler.registerTimelineRoutes(v2Group)
+	globalHandler.registerGPUMappingRoutes(v2Group)
+	globalHandler.registerGPUsRoutes(v2Group)
+	globalHandler.registerStartupRoutes(v2Group)
+	globalHandler.registerClusterRoutes(v2Group)
+	globalHandler.registerSLORoutes(v2Group)
+	globalHandler.registerGPUResetRoutes(v2Group)
+	globalHandler.registerDriverUpgradeRoutes(v2Group)
+	globalHandler.registerDrainRoutes(v2Group)
+	if config.DebugSnapshot {
+		globalHandler.registerDebugRoutes(v2Group)
+	}
+	v2Group.GET(URLPathHealthz, healthz())
+	v2Group.GET(URLPathReadyz, readyz(s.startup))
+	v2Group.GET(URLPathMachineInfo, globalHandler.machineInfo)
+	v2Group.POST(URLPathInjectFault, globalHandler.injectFault)
+
+	// the scrape format (OpenMetrics or Prometheus text) and the gzip compression
+	// are negotiated by the request headers
+	promGatherer, err := pkgmetrics.NewFilteredGatherer(pkgmetrics.DefaultGatherer(), config.MetricsExport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics gatherer: %w", err)
+	}
+	promHandler := promhttp.HandlerFor(promGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
 	router.GET("/metrics", func(ctx *gin.Context) {
 		promHandler.ServeHTTP(ctx.Writer, ctx.Request)
 	})
 
 	router.GET(URLPathSwagger, ginswagger.WrapHandler(swaggerfiles.Handler))
-	router.GET(URLPathHealthz, healthz())
-	router.GET(URLPathMachineInfo, globalHandler.machineInfo)
-	router.POST(URLPathInjectFault, globalHandler.injectFault)
+	router.GET(URLPathHealthz, negotiateAPIVersionMiddleware(node), healthz())
+	router.GET(URLPathReadyz, readyz(s.startup))
+	router.GET(URLPathMachineInfo, negotiateAPIVersionMiddleware(node), globalHandler.machineInfo)
+	router.POST(URLPathInjectFault, negotiateAPIVersionMiddleware(node), globalHandler.injectFault)
 
 	adminGroup := router.Group(urlPathAdmin)
 	adminGroup.GET(urlPathConfig, handleAdminConfig(config))
@@ -430,16 +733,34 @@ func New(ctx context.Context, auditLogger log.AuditLogger, config *lepconfig.Con
 	}
 
 	userToken := &UserToken{}
-	go s.updateToken(ctx, metricsSQLiteStore, userToken)
-	go s.startListener(nvmlInstance, syncer, config, router, cert)
+	go s.updateToken(ctx, metricsStore, userToken)
+	go s.startListener(syncer, config, router, cert)
+
+	startupCtx, startupCancel := context.WithCancel(ctx)
+	s.startupCancel = startupCancel
+	go s.startComponents(startupCtx, config, nvmlFailureInjector, globalHandler)
 	go updateFromVersionFile(ctx, config.AutoUpdateExitCode, config.VersionFile)
 
 	return s, nil
 }
 
 func (s *Server) Stop() {
-	if s.session != nil {
-		s.session.Stop()
+	// the components must not be closed while being initialized
+	if s.startupCancel != nil {
+		s.startupCancel()
+		<-s.startup.done
+	}
+
+	if sess := s.getSession(); sess != nil {
+		sess.Stop()
+	}
+	if s.gossipAgent != nil {
+		if err := s.gossipAgent.Close(); err != nil {
+			log.Logger.Warnw("failed to close gossip agent", "error", err)
+		}
+	}
+	if s.externalComponents != nil {
+		s.externalComponents.Stop()
 	}
 
 	if s.componentsRegistry != nil {
@@ -476,6 +797,13 @@ func (s *Server) Stop() {
 			log.Logger.Debugw("successfully closed read-only db")
 		}
 	}
+	if s.pgDB != nil {
+		if cerr := s.pgDB.Close(); cerr != nil {
+			log.Logger.Warnw("failed to close postgres db", "error", cerr)
+		} else {
+			log.Logger.Debugw("successfully closed postgres db")
+		}
+	}
 
 	if s.fifo != nil {
 		if err := s.fifo.Close(); err != nil {
@@ -572,7 +900,37 @@ func (s *Server) WaitUntilMachineID(ctx context.Context) {
 	}
 }
 
+func (s *Server) getSession() *session.Session {
+	s.sessionMu.RLock()
+	defer s.sessionMu.RUnlock()
+	return s.session
+}
+
+func (s *Server) setSession(sess *session.Session) {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	s.session = sess
+}
+
+// syncStatus returns the upload backlog of the current control plane session.
+func (s *Server) syncStatus() (session.SyncStatus, error) {
+	return s.getSession().SyncStatus()
+}
+
 func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store, token *UserToken) {
+	// the session serves the components, thus must wait for their initialization
+	if s.startup != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.startup.done:
+		}
+		if !s.startup.isReady() {
+			log.Logger.Warnw("gpud startup not ready, skipping session")
+			return
+		}
+	}
+
 	s.machineIDMu.RLock()
 	machineID := s.machineID
 	s.machineIDMu.RUnlock()
@@ -588,8 +946,7 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 	}
 
 	if userToken != "" {
-		var err error
-		s.session, err = session.NewSession(
+		sess, err := session.NewSession(
 			ctx,
 			s.epLocalGPUdServer,
 			s.epControlPlane,
@@ -600,6 +957,7 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 			session.WithEnableAutoUpdate(s.enableAutoUpdate),
 			session.WithAutoUpdateExitCode(s.autoUpdateExitCode),
 			session.WithSkipUpdateConfig(s.skipSessionUpdateConfig),
+			session.WithUploadConfig(s.sessionUploadConfig),
 			session.WithComponentsRegistry(s.componentsRegistry),
 			session.WithDataDir(s.dataDir),
 			session.WithDBInMemory(s.dbInMemory),
@@ -609,11 +967,16 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 				return pkgcustomplugins.SaveSpecs(s.pluginSpecsFile, specs)
 			}),
 			session.WithFaultInjector(s.faultInjector),
+			session.WithGPUResets(s.gpudInstance.GPUResets),
+			session.WithDataBudget(s.dataBudget),
+			session.WithMachineStates(s.machineStates),
 			session.WithDB(s.dbRW, s.dbRO),
+			session.WithDisconnectInjection(s.chaos.FailFunc(pkgchaos.BoundaryControlPlane), s.chaos.Config().ControlPlaneCheckInterval.Duration),
 		)
 		if err != nil {
 			log.Logger.Errorw("error creating session", "error", err)
 		}
+		s.setSession(sess)
 	}
 
 	if _, err := stdos.Stat(pipePath); err == nil {
@@ -650,10 +1013,10 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 			token.mu.Lock()
 			token.userToken = userToken
 			token.mu.Unlock()
-			if s.session != nil {
-				s.session.Stop()
+			if prev := s.getSession(); prev != nil {
+				prev.Stop()
 			}
-			s.session, err = session.NewSession(
+			sess, err := session.NewSession(
 				ctx,
 				s.epLocalGPUdServer,
 				s.epControlPlane,
@@ -664,6 +1027,7 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 				session.WithEnableAutoUpdate(s.enableAutoUpdate),
 				session.WithAutoUpdateExitCode(s.autoUpdateExitCode),
 				session.WithSkipUpdateConfig(s.skipSessionUpdateConfig),
+				session.WithUploadConfig(s.sessionUploadConfig),
 				session.WithComponentsRegistry(s.componentsRegistry),
 				session.WithDataDir(s.dataDir),
 				session.WithDBInMemory(s.dbInMemory),
@@ -674,10 +1038,12 @@ func (s *Server) updateToken(ctx context.Context, metricsStore pkgmetrics.Store,
 				}),
 				session.WithFaultInjector(s.faultInjector),
 				session.WithDB(s.dbRW, s.dbRO),
+				session.WithDisconnectInjection(s.chaos.FailFunc(pkgchaos.BoundaryControlPlane), s.chaos.Config().ControlPlaneCheckInterval.Duration),
 			)
 			if err != nil {
 				log.Logger.Errorw("error creating session", "error", err)
 			}
+			s.setSession(sess)
 		}
 
 		time.Sleep(time.Second)
@@ -715,7 +1081,10 @@ func WriteToken(token string, fifoFile string) error {
 	return nil
 }
 
-func doCompact(ctx context.Context, db *sql.DB, compactPeriod time.Duration) {
+// jobTypeCompact is the job type of the state database compaction.
+const jobTypeCompact = "compact"
+
+func doCompact(ctx context.Context, jobManager *jobs.Manager, db *sql.DB, compactPeriod time.Duration) {
 	if compactPeriod <= 0 {
 		log.Logger.Debugw("compact period is not set, skipping compacting")
 		return
@@ -731,39 +1100,55 @@ func doCompact(ctx context.Context, db *sql.DB, compactPeriod time.Duration) {
 			ticker.Reset(compactPeriod)
 		}
 
-		start := time.Now()
-		err := sqlite.Compact(ctx, db)
-		pkgmetricsrecorder.RecordSQLiteVacuum(time.Since(start).Seconds())
+		// the compaction is skipped if the previous one is still running
+		_, err := jobManager.Start(ctx, jobTypeCompact, func(ctx context.Context, report jobs.ProgressFunc) error {
+			report(0, "compacting state database")
 
+			start := time.Now()
+			err := sqlite.Compact(ctx, db)
+			pkgmetricsrecorder.RecordSQLiteVacuum(time.Since(start).Seconds())
+
+			if err != nil {
+				log.Logger.Errorw("failed to compact state database", "error", err)
+			}
+			return err
+		})
 		if err != nil {
-			log.Logger.Errorw("failed to compact state database", "error", err)
+			log.Logger.Warnw("failed to start compaction job", "error", err)
 		}
 	}
 }
 
-func (s *Server) startListener(nvmlInstance nvidianvml.Instance, metricsSyncer *pkgmetricssyncer.Syncer, config *lepconfig.Config, router *gin.Engine, cert tls.Certificate) {
+func (s *Server) startListener(metricsSyncer *pkgmetricssyncer.Syncer, config *lepconfig.Config, router *gin.Engine, cert tls.Certificate) {
 	defer func() {
-		if nvmlInstance != nil {
-			if err := nvmlInstance.Shutdown(); err != nil {
-				log.Logger.Warnw("failed to shutdown NVML instance", "error", err)
-			}
-		}
-
 		if metricsSyncer != nil {
 			metricsSyncer.Stop()
 		}
 
+		// stops the components before shutting down the NVML instance
 		s.Stop()
+
+		if s.gpudInstance != nil && s.gpudInstance.NVMLInstance != nil {
+			if err := s.gpudInstance.NVMLInstance.Shutdown(); err != nil {
+				log.Logger.Warnw("failed to shutdown NVML instance", "error", err)
+			}
+		}
 	}()
 
 	log.Logger.Infow("gpud started serving", "address", config.Address, "pluginSpecFile", config.PluginSpecsFile)
 
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if s.clientCAs != nil {
+		// the client certificate is optional, as the clients may use the bearer tokens
+		tlsConfig.ClientCAs = s.clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
 	srv := &http.Server{
-		Addr:    config.Address,
-		Handler: router,
-		TLSConfig: &tls.Config{
-			Certificates: []tls.Certificate{cert},
-		},
+		Addr:      config.Address,
+		Handler:   router,
+		TLSConfig: tlsConfig,
 	}
 	if err := srv.ListenAndServeTLS("", ""); err != nil {
 		log.Logger.Warnw("gpud serve failed", "address", config.Address, "error", err)
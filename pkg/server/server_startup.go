@@ -0,0 +1,584 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdos "os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/all"
+	pkgcapabilities "github.com/leptonai/gpud/pkg/capabilities"
+	lepconfig "github.com/leptonai/gpud/pkg/config"
+	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	pkgexternalcomponents "github.com/leptonai/gpud/pkg/external-components"
+	"github.com/leptonai/gpud/pkg/kmsg"
+	"github.com/leptonai/gpud/pkg/loadshed"
+	"github.com/leptonai/gpud/pkg/log"
+	nvidiadev "github.com/leptonai/gpud/pkg/nvidia/dev"
+	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
+	"github.com/leptonai/gpud/pkg/selftest"
+)
+
+const (
+	startupPhaseNVML               = "nvml"
+	startupPhaseSelfTest           = "self-test"
+	startupPhaseCapabilities       = "capabilities"
+	startupPhaseComponents         = "components"
+	startupPhasePlugins            = "plugins"
+	startupPhaseInitPlugins        = "init-plugins"
+	startupPhaseStartComponents    = "start-components"
+	startupPhaseExternalComponents = "external-components"
+
+	// defaultStartupConcurrency is the number of the components initialized concurrently.
+	defaultStartupConcurrency = 8
+)
+
+// startupTracker tracks the progress of the lazy component initialization,
+// while the API server is already serving.
+type startupTracker struct {
+	getTimeNowFunc func() time.Time
+
+	mu sync.RWMutex
+
+	startTime time.Time
+	endTime   time.Time
+	state     apiv1.StartupState
+	err       error
+
+	phases     []*startupStep
+	components map[string]*startupStep
+	// selfTest is the startup self-test result, nil if not run
+	selfTest *apiv1.StartupSelfTest
+
+	// done is closed once the startup is ready or failed
+	done chan struct{}
+}
+
+// startupStep is the progress of a single phase or component initialization.
+type startupStep struct {
+	name      string
+	state     apiv1.StartupState
+	startTime time.Time
+	endTime   time.Time
+	err       error
+}
+
+func newStartupTracker() *startupTracker {
+	now := time.Now().UTC()
+	return &startupTracker{
+		getTimeNowFunc: func() time.Time { return time.Now().UTC() },
+		startTime:      now,
+		state:          apiv1.StartupStateStarting,
+		components:     make(map[string]*startupStep),
+		done:           make(chan struct{}),
+	}
+}
+
+// isReady returns true if all the components are initialized and started.
+// A nil tracker is always ready.
+func (t *startupTracker) isReady() bool {
+	if t == nil {
+		return true
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.state == apiv1.StartupStateReady
+}
+
+// beginPhase marks the phase started, and returns the function to mark it done.
+func (t *startupTracker) beginPhase(name string) func(error) {
+	t.mu.Lock()
+	step := &startupStep{name: name, state: apiv1.StartupStateStarting, startTime: t.getTimeNowFunc()}
+	t.phases = append(t.phases, step)
+	t.mu.Unlock()
+
+	return func(err error) {
+		t.mu.Lock()
+		step.end(t.getTimeNowFunc(), err)
+		t.mu.Unlock()
+
+		log.Logger.Infow("startup phase done", "phase", name, "elapsed", step.endTime.Sub(step.startTime), "error", err)
+	}
+}
+
+// beginComponent marks the component initialization started, and returns the function to mark it done.
+func (t *startupTracker) beginComponent(name string) func(error) {
+	t.mu.Lock()
+	step := &startupStep{name: name, state: apiv1.StartupStateStarting, startTime: t.getTimeNowFunc()}
+	t.components[name] = step
+	t.mu.Unlock()
+
+	return func(err error) {
+		t.mu.Lock()
+		step.end(t.getTimeNowFunc(), err)
+		t.mu.Unlock()
+	}
+}
+
+// addComponents registers the components to initialize, not yet started.
+func (t *startupTracker) addComponents(names ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range names {
+		if _, ok := t.components[name]; !ok {
+			t.components[name] = &startupStep{name: name, state: apiv1.StartupStateStarting}
+		}
+	}
+}
+
+// setSelfTest sets the startup self-test result.
+func (t *startupTracker) setSelfTest(res apiv1.StartupSelfTest) {
+	t.mu.Lock()
+	t.selfTest = &res
+	t.mu.Unlock()
+}
+
+// finish marks the startup ready, or failed if the error is not nil.
+func (t *startupTracker) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state != apiv1.StartupStateStarting {
+		return
+	}
+	t.endTime = t.getTimeNowFunc()
+	t.err = err
+	if err != nil {
+		t.state = apiv1.StartupStateFailed
+	} else {
+		t.state = apiv1.StartupStateReady
+	}
+	close(t.done)
+}
+
+// status returns the snapshot of the startup progress.
+func (t *startupTracker) status() apiv1.StartupStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := t.getTimeNowFunc()
+	end := t.endTime
+	if end.IsZero() {
+		end = now
+	}
+	st := apiv1.StartupStatus{
+		State:           t.state,
+		StartTime:       metav1.NewTime(t.startTime),
+		Elapsed:         metav1.Duration{Duration: end.Sub(t.startTime)},
+		ComponentsTotal: len(t.components),
+	}
+	if t.err != nil {
+		st.Error = t.err.Error()
+	}
+	if t.selfTest != nil {
+		selfTest := *t.selfTest
+		st.SelfTest = &selfTest
+	}
+
+	for _, p := range t.phases {
+		st.Phases = append(st.Phases, apiv1.StartupPhase{
+			Name:      p.name,
+			State:     p.state,
+			StartTime: metav1.NewTime(p.startTime),
+			Elapsed:   metav1.Duration{Duration: p.elapsed(now)},
+			Error:     p.errString(),
+		})
+	}
+
+	for _, c := range t.components {
+		if c.state == apiv1.StartupStateReady {
+			st.ComponentsInitialized++
+		}
+		st.Components = append(st.Components, apiv1.StartupComponent{
+			Name:    c.name,
+			State:   c.state,
+			Elapsed: metav1.Duration{Duration: c.elapsed(now)},
+			Error:   c.errString(),
+		})
+	}
+	sort.Slice(st.Components, func(i, j int) bool {
+		if st.Components[i].Elapsed.Duration != st.Components[j].Elapsed.Duration {
+			return st.Components[i].Elapsed.Duration > st.Components[j].Elapsed.Duration
+		}
+		return st.Components[i].Name < st.Components[j].Name
+	})
+
+	return st
+}
+
+func (s *startupStep) end(now time.Time, err error) {
+	s.endTime = now
+	s.err = err
+	if err != nil {
+		s.state = apiv1.StartupStateFailed
+	} else {
+		s.state = apiv1.StartupStateReady
+	}
+}
+
+func (s *startupStep) elapsed(now time.Time) time.Duration {
+	if s.startTime.IsZero() {
+		return 0
+	}
+	if s.endTime.IsZero() {
+		return now.Sub(s.startTime)
+	}
+	return s.endTime.Sub(s.startTime)
+}
+
+func (s *startupStep) errString() string {
+	if s.err == nil {
+		return ""
+	}
+	return s.err.Error()
+}
+
+// startComponents initializes and starts the components in the background,
+// while the server is already serving the health check and the startup progress.
+// The process exits if the startup fails, as the daemon cannot serve without the components.
+func (s *Server) startComponents(ctx context.Context, config *lepconfig.Config, nvmlFailureInjector *nvidianvml.FailureInjectorConfig, g *globalHandler) {
+	err := s.initComponents(ctx, config, nvmlFailureInjector, g)
+	if err == nil {
+		g.refreshComponentNames()
+	}
+	s.startup.finish(err)
+
+	st := s.startup.status()
+	if err == nil {
+		log.Logger.Infow("gpud startup ready", "elapsed", st.Elapsed.Duration, "components", st.ComponentsTotal)
+		for i, c := range st.Components {
+			if i >= 5 {
+				break
+			}
+			log.Logger.Infow("slowest component initialization", "component", c.Name, "elapsed", c.Elapsed.Duration)
+		}
+		return
+	}
+
+	if ctx.Err() != nil {
+		log.Logger.Warnw("gpud startup canceled", "elapsed", st.Elapsed.Duration, "error", err)
+		return
+	}
+	log.Logger.Errorw("gpud startup failed", "elapsed", st.Elapsed.Duration, "error", err)
+	stdos.Exit(1)
+}
+
+// runSelfTest runs the startup self-test, and reports the result through the
+// readiness check and the startup event. Returns the error listing the failed checks.
+// The failures do not fail the startup, as the components not depending on the
+// failed checks keep working.
+func (s *Server) runSelfTest(ctx context.Context, config *lepconfig.Config, nvmlInstance nvidianvml.Instance) error {
+	getTimeNowFunc := func() time.Time { return time.Now().UTC() }
+	res := selftest.Run(ctx, selftest.DefaultTimeout,
+		// before recording the self-test time in the state database
+		selftest.ClockSane(s.dbRO, getTimeNowFunc),
+		selftest.DBWritable(s.dbRW, getTimeNowFunc),
+		selftest.NVMLLoadable(nvmlInstance, nvidiadev.CountAllDevicesFromDevDir),
+		selftest.DiskSpace(config.DataDir, selftest.DefaultMinFreeBytes),
+		selftest.KmsgReadable(kmsg.FilePath()),
+	)
+	s.startup.setSelfTest(res)
+	s.recordSelfTestEvent(ctx, selftest.Event(res, getTimeNowFunc()))
+
+	if !res.Passed {
+		failed := selftest.Failed(res)
+		log.Logger.Warnw("startup self-test failed", "failed", failed)
+		return fmt.Errorf("self-test check(s) failed: %s", strings.Join(failed, ", "))
+	}
+	log.Logger.Infow("startup self-test passed", "checks", len(res.Checks))
+	return nil
+}
+
+func (s *Server) recordSelfTestEvent(ctx context.Context, ev eventstore.Event) {
+	if s.gpudInstance == nil || s.gpudInstance.EventStore == nil {
+		return
+	}
+	bucket, err := s.gpudInstance.EventStore.Bucket(selftest.BucketName)
+	if err != nil {
+		log.Logger.Errorw("failed to create self-test event bucket", "error", err)
+		return
+	}
+	if err := bucket.Insert(ctx, ev); err != nil {
+		log.Logger.Errorw("failed to insert self-test event", "error", err)
+	}
+}
+
+// isCustomPlugin returns true if the registered component is a custom plugin.
+func (s *Server) isCustomPlugin(name string) bool {
+	registeree, ok := s.componentsRegistry.Get(name).(pkgcustomplugins.CustomPluginRegisteree)
+	return ok && registeree.IsCustomPlugin()
+}
+
+// initComponents runs the startup phases in order.
+func (s *Server) initComponents(ctx context.Context, config *lepconfig.Config, nvmlFailureInjector *nvidianvml.FailureInjectorConfig, g *globalHandler) error {
+	done := s.startup.beginPhase(startupPhaseNVML)
+	var nvmlInstance nvidianvml.Instance
+	var err error
+	if nvmlFailureInjector != nil {
+		// If failure injector is configured for NVML-level errors or product name override, use it
+		nvmlInstance, err = nvidianvml.NewWithFailureInjector(nvmlFailureInjector)
+	} else {
+		nvmlInstance, err = nvidianvml.NewWithExitOnSuccessfulLoad(ctx)
+	}
+	done(err)
+	if err != nil {
+		return fmt.Errorf("failed to create NVML instance: %w", err)
+	}
+	s.gpudInstance.NVMLInstance = nvmlInstance
+
+	if config.StartupSelfTest {
+		// the failed checks fail the phase, but not the startup
+		done = s.startup.beginPhase(startupPhaseSelfTest)
+		done(s.runSelfTest(ctx, config, nvmlInstance))
+	}
+
+	done = s.startup.beginPhase(startupPhaseCapabilities)
+	capabilitiesDetector := pkgcapabilities.NewDetector(nvmlInstance)
+	for _, c := range capabilitiesDetector.Probe().Capabilities {
+		if !c.Available {
+			log.Logger.Infow("capability not available", "capability", c.Name, "reason", c.Reason)
+		}
+	}
+	done(nil)
+
+	// read by the components once started
+	if len(config.ComponentAdaptiveCheckInterval) > 0 {
+		s.gpudInstance.AdaptiveCheckIntervals = config.AdaptiveCheckInterval
+	}
+
+	var names []string
+	var initFuncs []components.InitFunc
+	componentCapabilities := make(map[string][]string)
+	deferrable := make(map[string]bool)
+	for _, c := range all.All() {
+		name := c.Name
+
+		shouldEnable := config.ShouldEnable(name)
+		if config.ShouldDisable(name) {
+			shouldEnable = false
+		}
+
+		if shouldEnable {
+			initFunc := c.InitFunc
+			if config.ValidateContracts {
+				// innermost, as the other wrappers add the extra info not in the contracts
+				initFunc = components.WithContracts(initFunc)
+			}
+			// inside the watchdog, as the watchdog runs the checks in its own goroutines
+			initFunc = components.WithRecover(initFunc)
+			initFunc = components.WithWatchdog(initFunc, config.CheckWatchdog(name))
+			initFunc = components.WithHysteresis(initFunc, config.HealthHysteresis(name))
+			initFunc = components.WithMaintenance(initFunc, g.maintenanceManager)
+			initFunc = components.WithMute(initFunc, g.componentMutes)
+			initFunc = components.WithMachineState(initFunc, g.machineStates)
+			initFunc = components.WithCapabilities(initFunc, c.Capabilities, capabilitiesDetector)
+			initFunc = components.WithReasonCodes(initFunc)
+			if c.Deferrable {
+				initFunc = components.WithLoadShedding(initFunc)
+				deferrable[name] = true
+			}
+			componentCapabilities[name] = c.Capabilities
+
+			names = append(names, name)
+			initFunc = components.WithPersistence(initFunc, g.healthStateStore)
+			// outermost, to run the periodic checks through all the other wrappers
+			initFuncs = append(initFuncs, components.WithCheckLoop(initFunc))
+		}
+	}
+	s.startup.addComponents(names...)
+
+	// read by the components once initialized
+	if config.LoadShedding != nil {
+		monitor := loadshed.New(*config.LoadShedding)
+		monitor.Start(ctx)
+		s.gpudInstance.CheckDeferrer = func(name string) (string, bool) {
+			if !deferrable[name] && !s.isCustomPlugin(name) {
+				return "", false
+			}
+			return monitor.Shedding()
+		}
+		log.Logger.Infow("load shedding enabled", "config", config.LoadShedding)
+	}
+
+	done = s.startup.beginPhase(startupPhaseComponents)
+	err = s.registerComponents(ctx, names, initFuncs)
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	// must be registered before starting the components
+	done = s.startup.beginPhase(startupPhasePlugins)
+	err = loadPluginSecrets(config.PluginSecretsFile)
+	if err == nil {
+		err = s.registerPlugins(config.PluginSpecsFile)
+	}
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	// init plugin run only "once", and "before" regular components
+	// thus no need to start
+	done = s.startup.beginPhase(startupPhaseInitPlugins)
+	err = s.runInitPlugins()
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	// component must be started after initialization
+	done = s.startup.beginPhase(startupPhaseStartComponents)
+	for _, c := range s.componentsRegistry.All() {
+		if err = c.Start(); err != nil {
+			err = fmt.Errorf("failed to start component %s: %w", c.Name(), err)
+			break
+		}
+	}
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	// external components register after the built-in components started
+	if config.ExternalComponentsSocket != "" {
+		done = s.startup.beginPhase(startupPhaseExternalComponents)
+		s.externalComponents = pkgexternalcomponents.NewServer(ctx, config.ExternalComponentsSocket, s.componentsRegistry)
+		err = s.externalComponents.Start()
+		done(err)
+		if err != nil {
+			return fmt.Errorf("failed to start external components server: %w", err)
+		}
+	}
+
+	// run the custom plugins in reaction to the events and health state changes
+	pkgcustomplugins.NewTriggerWatcher(s.componentsRegistry).Start(ctx)
+
+	// revalidate the NVIDIA components once the driver is installed, upgraded, or reloaded
+	if g.driverUpgrades != nil {
+		g.driverUpgrades.Start(ctx)
+	}
+
+	g.capabilitiesDetector = capabilitiesDetector
+	g.componentCapabilities = componentCapabilities
+	return nil
+}
+
+// registerComponents initializes the components concurrently,
+// so that a slow component (e.g., waiting on the NVML or the IB discovery)
+// does not delay the others.
+func (s *Server) registerComponents(ctx context.Context, names []string, initFuncs []components.InitFunc) error {
+	sem := make(chan struct{}, defaultStartupConcurrency)
+	errs := make([]error, len(initFuncs))
+
+	var wg sync.WaitGroup
+	for i := range initFuncs {
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			wg.Wait()
+			return err
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			end := s.startup.beginComponent(names[i])
+			_, err := s.componentsRegistry.Register(initFuncs[i])
+			end(err)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to initialize component %s: %w", names[i], err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// registerPlugins registers the custom plugins in the specs file, if exists.
+// loadPluginSecrets loads the secrets for the plugin steps, if the file exists.
+func loadPluginSecrets(secretsFile string) error {
+	if secretsFile == "" {
+		return nil
+	}
+	if _, err := stdos.Stat(secretsFile); err != nil {
+		log.Logger.Debugw("plugin secrets file does not exist, skipping", "path", secretsFile)
+		return nil
+	}
+
+	secrets, err := pkgcustomplugins.LoadSecrets(secretsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin secrets: %w", err)
+	}
+	pkgcustomplugins.SetSecrets(secrets)
+	log.Logger.Infow("loaded plugin secrets", "path", secretsFile)
+	return nil
+}
+
+func (s *Server) registerPlugins(specsFile string) error {
+	if specsFile == "" {
+		return nil
+	}
+	if _, err := stdos.Stat(specsFile); err != nil {
+		log.Logger.Warnw("plugin specs file does not exist, skipping", "path", specsFile)
+		return nil
+	}
+
+	specs, err := pkgcustomplugins.LoadSpecs(specsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin specs: %w", err)
+	}
+
+	for _, spec := range specs {
+		initFunc := spec.NewInitFunc()
+		if initFunc == nil {
+			log.Logger.Errorw("failed to load plugin", "name", spec.ComponentName())
+			continue
+		}
+
+		registry := s.componentsRegistry
+		if spec.PluginType == pkgcustomplugins.SpecTypeInit {
+			registry = s.initRegistry
+		}
+		if _, err := registry.Register(initFunc); err != nil {
+			return fmt.Errorf("failed to register plugin %s: %w", spec.ComponentName(), err)
+		}
+		log.Logger.Infow("loaded plugin", "name", spec.ComponentName(), "type", spec.PluginType)
+	}
+	return nil
+}
+
+// runInitPlugins runs the init plugins once, before starting the regular components.
+func (s *Server) runInitPlugins() error {
+	for _, c := range s.initRegistry.All() {
+		rs := c.Check()
+		if rs.HealthStateType() != apiv1.HealthStateTypeHealthy {
+			return fmt.Errorf("failed to start init plugin %s: %s", c.Name(), rs.Summary())
+		}
+		log.Logger.Infow("successfully executed init plugin", "name", c.Name(), "summary", rs.Summary())
+
+		debugger, ok := rs.(components.CheckResultDebugger)
+		if ok {
+			fmt.Printf("init plugin debug output %q:\n\n%s\n\n", c.Name(), debugger.Debug())
+		}
+	}
+	return nil
+}
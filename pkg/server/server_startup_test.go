@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/components"
+)
+
+func TestStartupTracker(t *testing.T) {
+	tracker := newStartupTracker()
+	now := tracker.startTime
+	tracker.getTimeNowFunc = func() time.Time { return now }
+
+	var nilTracker *startupTracker
+	assert.True(t, nilTracker.isReady())
+	assert.False(t, tracker.isReady())
+
+	tracker.addComponents("fast", "slow", "pending")
+
+	endPhase := tracker.beginPhase(startupPhaseNVML)
+	now = now.Add(2 * time.Second)
+	endPhase(nil)
+
+	endFast := tracker.beginComponent("fast")
+	endSlow := tracker.beginComponent("slow")
+	now = now.Add(time.Second)
+	endFast(nil)
+	now = now.Add(3 * time.Second)
+	endSlow(errors.New("init failed"))
+
+	st := tracker.status()
+	assert.Equal(t, apiv1.StartupStateStarting, st.State)
+	assert.Equal(t, 6*time.Second, st.Elapsed.Duration)
+	require.Len(t, st.Phases, 1)
+	assert.Equal(t, startupPhaseNVML, st.Phases[0].Name)
+	assert.Equal(t, apiv1.StartupStateReady, st.Phases[0].State)
+	assert.Equal(t, 2*time.Second, st.Phases[0].Elapsed.Duration)
+
+	assert.Equal(t, 3, st.ComponentsTotal)
+	assert.Equal(t, 1, st.ComponentsInitialized)
+	require.Len(t, st.Components, 3)
+	// the slowest first
+	assert.Equal(t, "slow", st.Components[0].Name)
+	assert.Equal(t, apiv1.StartupStateFailed, st.Components[0].State)
+	assert.Equal(t, "init failed", st.Components[0].Error)
+	assert.Equal(t, 4*time.Second, st.Components[0].Elapsed.Duration)
+	assert.Equal(t, "fast", st.Components[1].Name)
+	assert.Equal(t, "pending", st.Components[2].Name)
+	assert.Equal(t, apiv1.StartupStateStarting, st.Components[2].State)
+
+	tracker.finish(nil)
+	assert.True(t, tracker.isReady())
+	select {
+	case <-tracker.done:
+	default:
+		t.Fatal("expected done to be closed")
+	}
+
+	// finishing again is a no-op
+	now = now.Add(time.Minute)
+	tracker.finish(errors.New("ignored"))
+	st = tracker.status()
+	assert.Equal(t, apiv1.StartupStateReady, st.State)
+	assert.Empty(t, st.Error)
+	assert.Equal(t, 6*time.Second, st.Elapsed.Duration)
+}
+
+func TestStartupTrackerFailed(t *testing.T) {
+	tracker := newStartupTracker()
+	tracker.finish(errors.New("nvml failed"))
+
+	assert.False(t, tracker.isReady())
+	st := tracker.status()
+	assert.Equal(t, apiv1.StartupStateFailed, st.State)
+	assert.Equal(t, "nvml failed", st.Error)
+}
+
+func TestRegisterComponentsConcurrently(t *testing.T) {
+	s := &Server{
+		componentsRegistry: components.NewRegistry(&components.GPUdInstance{}),
+		startup:            newStartupTracker(),
+	}
+
+	var running, maxRunning atomic.Int32
+	newInitFunc := func(name string, err error) components.InitFunc {
+		return func(*components.GPUdInstance) (components.Component, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			if err != nil {
+				return nil, err
+			}
+			return &nonClosableComponent{name: name}, nil
+		}
+	}
+
+	var names []string
+	var initFuncs []components.InitFunc
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		names = append(names, name)
+		initFuncs = append(initFuncs, newInitFunc(name, nil))
+	}
+	s.startup.addComponents(names...)
+
+	require.NoError(t, s.registerComponents(context.Background(), names, initFuncs))
+	assert.Len(t, s.componentsRegistry.All(), len(names))
+	assert.Greater(t, maxRunning.Load(), int32(1))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(defaultStartupConcurrency))
+
+	st := s.startup.status()
+	assert.Equal(t, len(names), st.ComponentsTotal)
+	assert.Equal(t, len(names), st.ComponentsInitialized)
+
+	// the failed components are reported by name
+	err := s.registerComponents(context.Background(), []string{"x", "y"}, []components.InitFunc{
+		newInitFunc("x", errors.New("no device")),
+		newInitFunc("y", nil),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to initialize component x")
+	assert.NotNil(t, s.componentsRegistry.Get("y"))
+
+	// canceled before initializing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.registerComponents(ctx, []string{"z"}, []components.InitFunc{newInitFunc("z", nil)})
+	require.ErrorIs(t, err, context.Canceled)
+}
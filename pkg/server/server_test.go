@@ -16,6 +16,7 @@ import (
 
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/pkg/config"
+	"github.com/leptonai/gpud/pkg/jobs"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
@@ -285,9 +286,12 @@ func setupTestDB(t *testing.T) (*sql.DB, *sql.DB, func()) {
 }
 
 func TestDoCompact(t *testing.T) {
-	db, _, cleanup := setupTestDB(t)
+	db, dbRO, cleanup := sqlite.OpenTestDB(t)
 	defer cleanup()
 
+	jobManager, err := jobs.NewManager(context.Background(), db, dbRO)
+	require.NoError(t, err)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -296,7 +300,7 @@ func TestDoCompact(t *testing.T) {
 	done := make(chan struct{})
 
 	go func() {
-		doCompact(ctx, db, compactPeriod)
+		doCompact(ctx, jobManager, db, compactPeriod)
 		close(done)
 	}()
 
@@ -317,7 +321,7 @@ func TestDoCompact(t *testing.T) {
 
 	done = make(chan struct{})
 	go func() {
-		doCompact(ctx, db, 0)
+		doCompact(ctx, jobManager, db, 0)
 		close(done)
 	}()
 
@@ -391,7 +395,7 @@ func TestStartListener(t *testing.T) {
 	// the server and then exit when the server fails to bind
 	done := make(chan struct{})
 	go func() {
-		s.startListener(nil, nil, cfg, router, cert)
+		s.startListener(nil, cfg, router, cert)
 		close(done)
 	}()
 
@@ -640,3 +644,25 @@ func TestDefaultBehaviorNotAffected_EmptySessionCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestDoCompactJob(t *testing.T) {
+	db, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	jobManager, err := jobs.NewManager(context.Background(), db, dbRO)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go doCompact(ctx, jobManager, db, 20*time.Millisecond)
+
+	// each compaction is recorded as a job
+	require.Eventually(t, func() bool {
+		for _, j := range jobManager.List(jobTypeCompact) {
+			if j.State == jobs.StateSucceeded {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
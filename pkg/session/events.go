@@ -18,8 +18,8 @@ func (s *Session) getEvents(ctx context.Context, payload Request) (apiv1.GPUdCom
 	if len(payload.Components) > 0 {
 		allComponents = payload.Components
 	}
-	startTime := time.Now()
-	endTime := time.Now()
+	startTime := time.Now().UTC()
+	endTime := time.Now().UTC()
 	if !payload.StartTime.IsZero() {
 		startTime = payload.StartTime
 	}
@@ -1,10 +1,17 @@
 package session
 
 import (
+	"context"
+	"encoding/json"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
 	"github.com/leptonai/gpud/pkg/log"
+	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 )
 
-func (s *Session) processGossip(resp *Response) {
+func (s *Session) processGossip(payload Request, resp *Response) {
 	if s.createGossipRequestFunc == nil {
 		return
 	}
@@ -16,6 +23,77 @@ func (s *Session) processGossip(resp *Response) {
 		return
 	}
 
+	if gossipReq.MachineInfo != nil && s.dbRO != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		v, err := pkgmetadata.ReadMetadata(ctx, s.dbRO, pkgmetadata.MetadataKeyAppliedConfigVersion)
+		cancel()
+		if err != nil {
+			log.Logger.Warnw("failed to read applied config version", "error", err)
+		}
+		gossipReq.MachineInfo.AppliedConfigVersion = v
+	}
+
+	if gossipReq.MachineInfo != nil {
+		s.setMachineInfoChanges(payload, gossipReq)
+	}
+
 	resp.GossipRequest = gossipReq
 	log.Logger.Debugw("successfully set gossip request")
 }
+
+// setMachineInfoChanges sets the changes since the last sent machine info,
+// and omits the full snapshot if only the changes are requested.
+// The last sent machine info is persisted, to report the changes across the restarts
+// (e.g., the driver upgraded while GPUd was down).
+func (s *Session) setMachineInfoChanges(payload Request, gossipReq *apiv1.GossipRequest) {
+	s.lastSentMachineInfoMu.Lock()
+	defer s.lastSentMachineInfoMu.Unlock()
+
+	if s.lastSentMachineInfo == nil && s.dbRO != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		v, err := pkgmetadata.ReadMetadata(ctx, s.dbRO, pkgmetadata.MetadataKeyLastSentMachineInfo)
+		cancel()
+		if err != nil {
+			log.Logger.Warnw("failed to read last sent machine info", "error", err)
+		} else if v != "" {
+			prev := &apiv1.MachineInfo{}
+			if err := json.Unmarshal([]byte(v), prev); err != nil {
+				log.Logger.Warnw("failed to parse last sent machine info", "error", err)
+			} else {
+				s.lastSentMachineInfo = prev
+			}
+		}
+	}
+
+	cur := gossipReq.MachineInfo
+	prev := s.lastSentMachineInfo
+	gossipReq.MachineInfoChanges = pkgmachineinfo.Diff(prev, cur)
+	if len(gossipReq.MachineInfoChanges) > 0 {
+		log.Logger.Infow("machine info changed since last gossip", "changes", len(gossipReq.MachineInfoChanges))
+	}
+
+	// the full snapshot until reported once, and on demand
+	if payload.MachineInfoChangesOnly && prev != nil {
+		gossipReq.MachineInfo = nil
+	}
+
+	if prev != nil && len(gossipReq.MachineInfoChanges) == 0 {
+		return
+	}
+	s.lastSentMachineInfo = cur
+
+	if s.dbRW == nil {
+		return
+	}
+	b, err := json.Marshal(cur)
+	if err != nil {
+		log.Logger.Warnw("failed to marshal machine info", "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err = pkgmetadata.SetMetadata(ctx, s.dbRW, pkgmetadata.MetadataKeyLastSentMachineInfo, string(b))
+	cancel()
+	if err != nil {
+		log.Logger.Warnw("failed to persist last sent machine info", "error", err)
+	}
+}
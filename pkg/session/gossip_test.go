@@ -1,16 +1,20 @@
 package session
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	apiv1 "github.com/leptonai/gpud/api/v1"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/nvidia/nvml/device"
 	nvmllib "github.com/leptonai/gpud/pkg/nvidia/nvml/lib"
 	nvidiaproduct "github.com/leptonai/gpud/pkg/nvidia/product"
+	pkgsqlite "github.com/leptonai/gpud/pkg/sqlite"
 )
 
 // Mock NVML instance for testing
@@ -41,7 +45,7 @@ func TestProcessGossip(t *testing.T) {
 		}
 		resp := &Response{}
 
-		session.processGossip(resp)
+		session.processGossip(Request{}, resp)
 
 		// Should return early without setting anything
 		assert.Nil(t, resp.GossipRequest)
@@ -65,7 +69,7 @@ func TestProcessGossip(t *testing.T) {
 		}
 		resp := &Response{}
 
-		session.processGossip(resp)
+		session.processGossip(Request{}, resp)
 
 		assert.Equal(t, expectedGossipReq, resp.GossipRequest)
 		assert.Empty(t, resp.Error)
@@ -85,7 +89,7 @@ func TestProcessGossip(t *testing.T) {
 		}
 		resp := &Response{}
 
-		session.processGossip(resp)
+		session.processGossip(Request{}, resp)
 
 		assert.Nil(t, resp.GossipRequest)
 		assert.Equal(t, expectedError.Error(), resp.Error)
@@ -111,7 +115,7 @@ func TestProcessGossip(t *testing.T) {
 		}
 		resp := &Response{}
 
-		session.processGossip(resp)
+		session.processGossip(Request{}, resp)
 
 		assert.Equal(t, expectedGossipReq, resp.GossipRequest)
 		assert.Empty(t, resp.Error)
@@ -132,9 +136,71 @@ func TestProcessGossip(t *testing.T) {
 		}
 		resp := &Response{}
 
-		session.processGossip(resp)
+		session.processGossip(Request{}, resp)
 
 		assert.Equal(t, expectedGossipReq, resp.GossipRequest)
 		assert.Empty(t, resp.Error)
 	})
 }
+
+func TestProcessGossipMachineInfoChanges(t *testing.T) {
+	ctx := context.Background()
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(t)
+	defer cleanup()
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	driverVersion := "550.54.15"
+	gpus := []apiv1.MachineGPUInstance{{UUID: "GPU-0", BusID: "0000:0f:00.0"}, {UUID: "GPU-1", BusID: "0000:1f:00.0"}}
+	newSession := func() *Session {
+		return &Session{
+			machineID: "test-machine-id",
+			dbRW:      dbRW,
+			dbRO:      dbRO,
+			createGossipRequestFunc: func(machineID string, nvmlInstance nvidianvml.Instance) (*apiv1.GossipRequest, error) {
+				return &apiv1.GossipRequest{
+					MachineID: machineID,
+					MachineInfo: &apiv1.MachineInfo{
+						GPUDriverVersion: driverVersion,
+						GPUInfo:          &apiv1.MachineGPUInfo{GPUs: gpus},
+					},
+				}, nil
+			},
+		}
+	}
+
+	s := newSession()
+	changesOnly := Request{Method: "gossip", MachineInfoChangesOnly: true}
+
+	// full snapshot until reported once
+	resp := &Response{}
+	s.processGossip(changesOnly, resp)
+	require.NotNil(t, resp.GossipRequest.MachineInfo)
+	assert.Empty(t, resp.GossipRequest.MachineInfoChanges)
+
+	resp = &Response{}
+	s.processGossip(changesOnly, resp)
+	assert.Nil(t, resp.GossipRequest.MachineInfo)
+	assert.Empty(t, resp.GossipRequest.MachineInfoChanges)
+
+	// the changes are reported across the restarts
+	driverVersion = "570.86.10"
+	gpus = gpus[:1]
+	s = newSession()
+	resp = &Response{}
+	s.processGossip(changesOnly, resp)
+	assert.Nil(t, resp.GossipRequest.MachineInfo)
+	assert.Equal(t, []apiv1.MachineInfoChange{
+		{Type: apiv1.MachineInfoChangeTypeChanged, Field: "gpuDriverVersion", From: "550.54.15", To: "570.86.10"},
+		{Type: apiv1.MachineInfoChangeTypeRemoved, Field: "gpuInfo.gpus[GPU-1]", From: "0000:1f:00.0"},
+	}, resp.GossipRequest.MachineInfoChanges)
+
+	resp = &Response{}
+	s.processGossip(changesOnly, resp)
+	assert.Empty(t, resp.GossipRequest.MachineInfoChanges)
+
+	// full snapshot on demand
+	resp = &Response{}
+	s.processGossip(Request{Method: "gossip"}, resp)
+	require.NotNil(t, resp.GossipRequest.MachineInfo)
+	assert.Equal(t, "570.86.10", resp.GossipRequest.MachineInfo.GPUDriverVersion)
+}
@@ -26,7 +26,7 @@ func (s *Session) getHealthStates(payload Request) (apiv1.GPUdComponentHealthSta
 	// use BootTimeUnixSeconds which reads directly from system sources via syscall
 	// cached at initialization for performance and reliability
 	bootTimeUnix := pkghost.BootTimeUnixSeconds()
-	rebootTime := time.Unix(int64(bootTimeUnix), 0)
+	rebootTime := time.Unix(int64(bootTimeUnix), 0).UTC()
 
 	// sanity check: if boot time is 0 or in the future, use zero time
 	// This prevents accidentally setting components to initializing
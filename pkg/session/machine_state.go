@@ -0,0 +1,40 @@
+package session
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/leptonai/gpud/pkg/log"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+)
+
+// processSetMachineState handles the setMachineState request,
+// and responds with the machine state in effect.
+func (s *Session) processSetMachineState(ctx context.Context, payload Request, response *Response) {
+	if s.machineStates == nil {
+		response.Error = "machine state is not set up"
+		response.ErrorCode = http.StatusNotFound
+		return
+	}
+	if payload.MachineState == nil {
+		response.Error = "machine state is required"
+		response.ErrorCode = http.StatusBadRequest
+		return
+	}
+	if !payload.MachineState.State.IsValid() {
+		response.Error = "invalid machine state: " + string(payload.MachineState.State)
+		response.ErrorCode = http.StatusBadRequest
+		return
+	}
+
+	st, err := s.machineStates.Set(ctx, payload.MachineState.State, payload.MachineState.Reason, machinestate.SourceControlPlane)
+	if err != nil {
+		log.Logger.Errorw("failed to set machine state", "state", payload.MachineState.State, "error", err)
+		response.Error = err.Error()
+		response.ErrorCode = http.StatusInternalServerError
+		return
+	}
+	log.Logger.Infow("machine state set by control plane", "state", st.State, "reason", st.Reason)
+
+	response.MachineState = &st
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestProcessSetMachineState(t *testing.T) {
+	ctx := context.Background()
+
+	s := &Session{}
+	response := &Response{}
+	s.processSetMachineState(ctx, Request{MachineState: &apiv1.MachineState{State: apiv1.MachineStateDraining}}, response)
+	assert.Equal(t, int32(http.StatusNotFound), response.ErrorCode)
+
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+	m, err := machinestate.NewManager(ctx, dbRW, dbRO)
+	require.NoError(t, err)
+	s.machineStates = m
+
+	response = &Response{}
+	s.processSetMachineState(ctx, Request{}, response)
+	assert.Equal(t, int32(http.StatusBadRequest), response.ErrorCode)
+
+	response = &Response{}
+	s.processSetMachineState(ctx, Request{MachineState: &apiv1.MachineState{State: "unknown"}}, response)
+	assert.Equal(t, int32(http.StatusBadRequest), response.ErrorCode)
+	assert.Equal(t, apiv1.MachineStateActive, m.Get().State)
+
+	response = &Response{}
+	s.processSetMachineState(ctx, Request{MachineState: &apiv1.MachineState{State: apiv1.MachineStateDraining, Reason: "rack upgrade"}}, response)
+	assert.Empty(t, response.Error)
+	require.NotNil(t, response.MachineState)
+	assert.Equal(t, apiv1.MachineStateDraining, response.MachineState.State)
+	assert.Equal(t, machinestate.SourceControlPlane, response.MachineState.Source)
+	assert.Equal(t, *response.MachineState, m.Get())
+}
@@ -76,6 +76,7 @@ func (s *Session) getMetricsFromComponent(ctx context.Context, componentName str
 			Name:        data.Name,
 			Labels:      data.Labels,
 			Value:       data.Value,
+			Unit:        pkgmetrics.GetUnit(data.Name),
 		})
 	}
 	return currMetrics
@@ -262,7 +262,7 @@ func TestMockeyProcessGossip_NilFunc(t *testing.T) {
 			createGossipRequestFunc: nil,
 		}
 		resp := &Response{}
-		s.processGossip(resp)
+		s.processGossip(Request{}, resp)
 		assert.Nil(t, resp.GossipRequest)
 		assert.Empty(t, resp.Error)
 	})
@@ -280,7 +280,7 @@ func TestMockeyProcessGossip_Success(t *testing.T) {
 			},
 		}
 		resp := &Response{}
-		s.processGossip(resp)
+		s.processGossip(Request{}, resp)
 		assert.Equal(t, expectedGossipReq, resp.GossipRequest)
 		assert.Empty(t, resp.Error)
 	})
@@ -295,7 +295,7 @@ func TestMockeyProcessGossip_Error(t *testing.T) {
 			},
 		}
 		resp := &Response{}
-		s.processGossip(resp)
+		s.processGossip(Request{}, resp)
 		assert.Nil(t, resp.GossipRequest)
 		assert.Equal(t, "gossip creation failed", resp.Error)
 	})
@@ -316,7 +316,7 @@ func TestMockeyProcessGossip_WithNvmlInstance(t *testing.T) {
 			},
 		}
 		resp := &Response{}
-		s.processGossip(resp)
+		s.processGossip(Request{}, resp)
 		assert.Equal(t, inst, receivedInst)
 		assert.Equal(t, expectedGossipReq, resp.GossipRequest)
 	})
@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -24,16 +25,20 @@ import (
 	componentstemperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
 	componentsxid "github.com/leptonai/gpud/components/accelerator/nvidia/xid"
 	componentsnfs "github.com/leptonai/gpud/components/nfs"
+	"github.com/leptonai/gpud/pkg/budget"
 	"github.com/leptonai/gpud/pkg/config"
 	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
 	pkgfaultinjector "github.com/leptonai/gpud/pkg/fault-injector"
+	"github.com/leptonai/gpud/pkg/gpureset"
 	"github.com/leptonai/gpud/pkg/log"
 	pkgmachineinfo "github.com/leptonai/gpud/pkg/machine-info"
+	machinestate "github.com/leptonai/gpud/pkg/machine-state"
 	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
 	nvidianvml "github.com/leptonai/gpud/pkg/nvidia/nvml"
 	"github.com/leptonai/gpud/pkg/process"
 	sessionstates "github.com/leptonai/gpud/pkg/session/states"
+	"github.com/leptonai/gpud/pkg/session/upload"
 	"github.com/leptonai/gpud/pkg/sqlite"
 )
 
@@ -51,8 +56,15 @@ type Op struct {
 	metricsStore        pkgmetrics.Store
 	savePluginSpecsFunc func(context.Context, pkgcustomplugins.Specs) (bool, error)
 	faultInjector       pkgfaultinjector.Injector
+	gpuResets           *gpureset.Tracker
+	dataBudget          *budget.Budget
+	machineStates       *machinestate.Manager
 	dbRW                *sql.DB
 	dbRO                *sql.DB
+	uploadConfig        *upload.Config
+
+	disconnectFunc          func() bool
+	disconnectCheckInterval time.Duration
 }
 
 type OpOption func(*Op)
@@ -150,6 +162,29 @@ func WithFaultInjector(faultInjector pkgfaultinjector.Injector) OpOption {
 	}
 }
 
+// WithGPUResets records the reset window on the reboots requested by the control plane,
+// so that the expected Xid and SXid events during the reboots do not alert.
+func WithGPUResets(gpuResets *gpureset.Tracker) OpOption {
+	return func(op *Op) {
+		op.gpuResets = gpuResets
+	}
+}
+
+// WithDataBudget caps the bytes uploaded to the control plane per hour,
+// with only the critical and fatal events uploaded when over budget.
+func WithDataBudget(dataBudget *budget.Budget) OpOption {
+	return func(op *Op) {
+		op.dataBudget = dataBudget
+	}
+}
+
+// WithMachineStates sets the machine state on the control plane requests.
+func WithMachineStates(machineStates *machinestate.Manager) OpOption {
+	return func(op *Op) {
+		op.machineStates = machineStates
+	}
+}
+
 func WithDB(dbRW *sql.DB, dbRO *sql.DB) OpOption {
 	return func(op *Op) {
 		op.dbRW = dbRW
@@ -157,6 +192,24 @@ func WithDB(dbRW *sql.DB, dbRO *sql.DB) OpOption {
 	}
 }
 
+// WithUploadConfig enables the periodic upload of the metrics and events
+// to the control plane, in compressed batches with the on-disk overflow queue.
+// If nil, the metrics and events are only sent on the control plane requests.
+func WithUploadConfig(cfg *upload.Config) OpOption {
+	return func(op *Op) {
+		op.uploadConfig = cfg
+	}
+}
+
+// WithDisconnectInjection disconnects the control plane session whenever the function
+// returns true, checked every interval while connected, to validate the reconnects (e.g., chaos mode).
+func WithDisconnectInjection(disconnectFunc func() bool, interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.disconnectFunc = disconnectFunc
+		op.disconnectCheckInterval = interval
+	}
+}
+
 // Triggers an auto update of GPUd itself by exiting the process with the given exit code.
 // Useful when the machine is managed by the Kubernetes daemonset and we want to
 // trigger an auto update when the daemonset restarts the machine.
@@ -191,6 +244,11 @@ type Session struct {
 
 	createGossipRequestFunc func(machineID string, nvmlInstance nvidianvml.Instance) (*apiv1.GossipRequest, error)
 
+	// lastSentMachineInfo is the machine info last sent via the gossip,
+	// to report the changes since, nil until sent (or read from the metadata)
+	lastSentMachineInfoMu sync.Mutex
+	lastSentMachineInfo   *apiv1.MachineInfo
+
 	setDefaultIbExpectedPortStatesFunc     func(states componentsnvidiainfinibanditypes.ExpectedPortStates)
 	setDefaultNVLinkExpectedLinkStatesFunc func(states componentsnvidianvlink.ExpectedLinkStates)
 	setDefaultGPUCountsFunc                func(counts componentsnvidiagpucounts.ExpectedGPUCounts)
@@ -217,6 +275,43 @@ type Session struct {
 	faultInjector       pkgfaultinjector.Injector
 	skipUpdateConfig    bool
 
+	// gpuResets records the reset windows of the requested reboots, nil if not set up
+	gpuResets *gpureset.Tracker
+
+	// configRootKeys are the root keys to verify the signed configs,
+	// nil to use the embedded release root keys
+	configRootKeys []ed25519.PublicKey
+	// applySignedConfigMu serializes the signed config applies,
+	// so that the concurrent ones are checked against the applied version in order
+	applySignedConfigMu sync.Mutex
+
+	// uploadConfig is the metrics and events upload config, nil if disabled
+	uploadConfig *upload.Config
+	uploadQueue  *upload.Queue
+	// uploadAcks tracks the sent batches pending the acknowledgements,
+	// nil if the acknowledgements are not required
+	uploadAcks *upload.Acks
+	// uploadFlushMu serializes the upload queue flushes,
+	// so that a batch is never sent twice by the concurrent flushes
+	uploadFlushMu sync.Mutex
+	// dataBudget caps the upload bytes, nil if not set up
+	dataBudget *budget.Budget
+
+	// machineStates manages the machine state set by the control plane, nil if not set up
+	machineStates *machinestate.Manager
+	// uploadCursor is the end time of the last upload
+	uploadCursor time.Time
+	// uploadPendingUntil is the end time of the upload window whose batches
+	// are not all queued yet, zero if none
+	uploadPendingUntil time.Time
+	// uploadPendingQueued is the number of the batches of the pending upload window
+	// already queued
+	uploadPendingQueued int
+
+	// disconnectFunc decides whether to disconnect the session, nil to never disconnect
+	disconnectFunc          func() bool
+	disconnectCheckInterval time.Duration
+
 	lastPackageTimestampMu sync.RWMutex
 	lastPackageTimestamp   time.Time
 
@@ -276,6 +371,25 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 		cps = append(cps, c.Name())
 	}
 
+	var uploadConfig *upload.Config
+	var uploadQueue *upload.Queue
+	var uploadAcks *upload.Acks
+	if op.uploadConfig != nil {
+		cfg := *op.uploadConfig
+		cfg.SetDefaults(config.SessionUploadQueueDir(dataDir))
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid upload config: %w", err)
+		}
+		uploadQueue, err = upload.OpenQueue(cfg.QueueDir, cfg.QueueMaxBytes, cfg.EvictionPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open upload queue: %w", err)
+		}
+		uploadConfig = &cfg
+		if cfg.RequireAck {
+			uploadAcks = upload.NewAcks(cfg.AckTimeout.Duration)
+		}
+	}
+
 	cctx, ccancel := context.WithCancel(ctx)
 	s := &Session{
 		ctx:    cctx,
@@ -316,9 +430,21 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 		savePluginSpecsFunc: op.savePluginSpecsFunc,
 		faultInjector:       op.faultInjector,
 		skipUpdateConfig:    op.skipUpdateConfig,
+		gpuResets:           op.gpuResets,
 
 		enableAutoUpdate:   op.enableAutoUpdate,
 		autoUpdateExitCode: op.autoUpdateExitCode,
+
+		uploadConfig: uploadConfig,
+		uploadQueue:  uploadQueue,
+		uploadAcks:   uploadAcks,
+		dataBudget:   op.dataBudget,
+		uploadCursor: time.Now().UTC(),
+
+		machineStates: op.machineStates,
+
+		disconnectFunc:          op.disconnectFunc,
+		disconnectCheckInterval: op.disconnectCheckInterval,
 	}
 
 	s.timeAfterFunc = time.After
@@ -333,6 +459,10 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 	s.closer = &closeOnce{closer: make(chan any)}
 	go s.keepAlive()
 	go s.serve()
+	if s.uploadConfig != nil {
+		log.Logger.Infow("session upload enabled", "interval", s.uploadConfig.Interval.Duration, "maxBatchSize", s.uploadConfig.MaxBatchSize, "compression", s.uploadConfig.Compression, "queueDir", s.uploadConfig.QueueDir, "requireAck", s.uploadConfig.RequireAck)
+		go s.uploadLoop()
+	}
 
 	return s, nil
 }
@@ -340,6 +470,13 @@ func NewSession(ctx context.Context, epLocalGPUdServer string, epControlPlane st
 type Body struct {
 	Data  []byte `json:"data,omitempty"`
 	ReqID string `json:"req_id,omitempty"`
+
+	// UploadID is the ID of the metrics and events batch uploaded
+	// by GPUd without a control plane request (empty for the responses).
+	UploadID string `json:"upload_id,omitempty"`
+	// Encoding is the compression algorithm of the data (e.g., "gzip", "zstd").
+	// Empty if the data is not compressed.
+	Encoding string `json:"encoding,omitempty"`
 }
 
 // drainReaderChannel removes any stale messages from the reader channel
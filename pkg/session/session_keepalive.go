@@ -92,6 +92,13 @@ func (s *Session) keepAlive() {
 
 			go s.startReaderFunc(ctx, readerExit, jar)
 			go s.startWriterFunc(ctx, writerExit, jar)
+			if s.uploadAcks != nil {
+				// the batches written to the previous connection may be lost
+				go s.redeliverUploads()
+			}
+			if s.disconnectFunc != nil {
+				go s.injectDisconnects(ctx, cancel)
+			}
 
 			// CRITICAL: We must handle EITHER reader or writer exiting first to prevent deadlock
 			//
@@ -125,3 +132,21 @@ func (s *Session) keepAlive() {
 		}
 	}
 }
+
+// injectDisconnects cancels the connection context whenever the disconnect function
+// returns true, which makes the reader and writer exit as if the connection dropped.
+func (s *Session) injectDisconnects(ctx context.Context, cancel context.CancelFunc) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.timeAfterFunc(s.disconnectCheckInterval):
+		}
+
+		if s.disconnectFunc() {
+			log.Logger.Warnw("session keep alive: injecting control plane disconnect")
+			cancel()
+			return
+		}
+	}
+}
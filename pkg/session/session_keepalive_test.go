@@ -401,3 +401,36 @@ func TestKeepAliveContextCancellation(t *testing.T) {
 		t.Fatal("keepAlive did not exit on context cancellation")
 	}
 }
+
+func TestInjectDisconnects(t *testing.T) {
+	var checks int32
+	s := &Session{
+		disconnectCheckInterval: time.Minute,
+		disconnectFunc: func() bool {
+			return atomic.AddInt32(&checks, 1) == 3
+		},
+	}
+	s.timeAfterFunc = func(d time.Duration) <-chan time.Time {
+		assert.Equal(t, time.Minute, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.injectDisconnects(ctx, cancel)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("injectDisconnects did not return")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&checks))
+	assert.Error(t, ctx.Err(), "connection context should be canceled")
+}
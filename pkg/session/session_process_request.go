@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/leptonai/gpud/pkg/gpureset"
 	pkghost "github.com/leptonai/gpud/pkg/host"
 	"github.com/leptonai/gpud/pkg/log"
 )
@@ -16,6 +17,11 @@ import (
 func (s *Session) processRequest(ctx context.Context, reqID string, payload Request, response *Response, restartExitCode *int) bool {
 	switch payload.Method {
 	case "reboot":
+		// the Xids and SXids during the reboot are expected
+		if err := s.gpuResets.Begin(ctx, gpureset.SourceGPUd, "reboot requested by control plane"); err != nil {
+			log.Logger.Warnw("failed to record gpu reset window", "error", err)
+		}
+
 		// To inform the control plane that the reboot request has been processed, reboot after 10 seconds.
 		err := pkghost.Reboot(s.ctx, pkghost.WithDelaySeconds(10))
 		if err != nil {
@@ -126,11 +132,20 @@ func (s *Session) processRequest(ctx context.Context, reqID string, payload Requ
 	case "getPluginSpecs":
 		s.processGetPluginSpecs(response)
 
+	case "applySignedConfig":
+		s.processApplySignedConfig(ctx, payload.SignedConfig, response)
+
 	case "updateToken":
 		s.processUpdateToken(payload, response)
 
 	case "getToken":
 		s.processGetToken(response)
+
+	case "setMachineState":
+		s.processSetMachineState(ctx, payload, response)
+
+	case "ackUploads":
+		s.processAckUploads(payload, response)
 	}
 
 	return false // Request is handled synchronously
@@ -153,7 +168,7 @@ func (s *Session) processRequestAsync(reqID, method string, payload Request) {
 	case "gossip":
 		// Process gossip requests asynchronously to avoid blocking on disk I/O operations
 		// that may hang if filesystems (especially NFS) are unresponsive
-		s.processGossip(response)
+		s.processGossip(payload, response)
 
 	// Add other async method handlers here as needed in the future
 	// Note: Only methods that can block for significant time should be handled async
@@ -47,6 +47,21 @@ type Request struct {
 
 	// Token is the new token to update on the agent side.
 	Token string `json:"token,omitempty"`
+
+	// SignedConfig is the signed config to verify and apply.
+	SignedConfig *SignedConfig `json:"signed_config,omitempty"`
+
+	// MachineInfoChangesOnly is set for the gossip request to only report the machine info
+	// changes since the last report, without the full snapshot once reported.
+	// The full snapshot is available on demand by the gossip request without this flag.
+	MachineInfoChangesOnly bool `json:"machine_info_changes_only,omitempty"`
+
+	// MachineState is the machine-level operational state to set (e.g., cordoned, draining).
+	MachineState *apiv1.MachineState `json:"machine_state,omitempty"`
+
+	// UploadIDs are the upload IDs of the batches received by the control plane,
+	// to acknowledge with the "ackUploads" request.
+	UploadIDs []string `json:"upload_ids,omitempty"`
 }
 
 // Response is the response from GPUd to the control plane.
@@ -74,6 +89,9 @@ type Response struct {
 
 	// Token is the current token value from the agent.
 	Token string `json:"token,omitempty"`
+
+	// MachineState is the current machine-level operational state.
+	MachineState *apiv1.MachineState `json:"machine_state,omitempty"`
 }
 
 type BootstrapRequest struct {
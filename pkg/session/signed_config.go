@@ -0,0 +1,290 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/leptonai/gpud/components"
+	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+)
+
+// SignedConfig is the config pushed by the control plane, signed with one of the
+// release signing keys, which are in turn signed by the GPUd root keys.
+type SignedConfig struct {
+	// Payload is the JSON-encoded ConfigPayload, verified as is.
+	Payload []byte `json:"payload"`
+	// Signature is the signature of the payload by one of the signing keys.
+	Signature []byte `json:"signature"`
+	// SigningKeys is the bundle of the PEM-encoded public signing keys.
+	SigningKeys []byte `json:"signing_keys"`
+	// SigningKeysSignature is the signature of the signing keys by one of the root keys.
+	SigningKeysSignature []byte `json:"signing_keys_signature"`
+}
+
+// ConfigPayload is the config applied as a whole, or not at all.
+type ConfigPayload struct {
+	// Version identifies the config, and is reported in the machine info once applied.
+	// Must increase monotonically, as the dot-separated numbers with the optional "v" prefix
+	// (e.g., "v3", "2025.10.14.1"), so that the older configs are never applied again.
+	Version string `json:"version"`
+	// MachineID restricts the config to the machine, if not empty.
+	MachineID string `json:"machine_id,omitempty"`
+
+	// UpdateConfig is the component configs, same as the "updateConfig" request.
+	UpdateConfig map[string]string `json:"update_config,omitempty"`
+	// CustomPluginSpecs replaces all the custom plugins, if not nil.
+	// The init plugins are only saved, and run on the next GPUd start.
+	CustomPluginSpecs pkgcustomplugins.Specs `json:"custom_plugin_specs,omitempty"`
+}
+
+var errSignedConfigRequired = errors.New("signed config is required")
+
+// verifySignedConfig verifies the signatures and decodes the payload.
+func (s *Session) verifySignedConfig(sc *SignedConfig) (*ConfigPayload, error) {
+	if sc == nil {
+		return nil, errSignedConfigRequired
+	}
+	if err := distsign.VerifyPayload(s.configRootKeys, sc.SigningKeys, sc.SigningKeysSignature, sc.Payload, sc.Signature); err != nil {
+		return nil, err
+	}
+
+	var payload ConfigPayload
+	if err := json.Unmarshal(sc.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config payload: %w", err)
+	}
+	if payload.Version == "" {
+		return nil, errors.New("config version is required")
+	}
+	if _, err := parseConfigVersion(payload.Version); err != nil {
+		return nil, err
+	}
+	if payload.MachineID != "" && payload.MachineID != s.machineID {
+		return nil, fmt.Errorf("config is for machine %q, not %q", payload.MachineID, s.machineID)
+	}
+	return &payload, nil
+}
+
+// parseConfigVersion parses the config version as the dot-separated numbers
+// with the optional "v" prefix (e.g., "v3" or "2025.10.14.1").
+func parseConfigVersion(v string) ([]uint64, error) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	nums := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config version %q (must be the dot-separated numbers, e.g., \"v3\")", v)
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
+
+// isNotNewerConfigVersion returns true if the version is older than or the same as the previous one,
+// comparing the numbers in order (the missing numbers are zeros, e.g., "v1" equals "v1.0").
+// Returns false if no config was applied before.
+func isNotNewerConfigVersion(version string, prevVersion string) (bool, error) {
+	if prevVersion == "" {
+		return false, nil
+	}
+	cur, err := parseConfigVersion(version)
+	if err != nil {
+		return false, err
+	}
+	prev, err := parseConfigVersion(prevVersion)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < max(len(cur), len(prev)); i++ {
+		var c, p uint64
+		if i < len(cur) {
+			c = cur[i]
+		}
+		if i < len(prev) {
+			p = prev[i]
+		}
+		if c != p {
+			return c < p, nil
+		}
+	}
+	return true, nil
+}
+
+// processApplySignedConfig verifies and applies the signed config.
+// If any custom plugin fails to initialize, or the config cannot be persisted,
+// the previous custom plugins are restored and no component config is applied.
+// The signed configs are applied one at a time.
+func (s *Session) processApplySignedConfig(ctx context.Context, sc *SignedConfig, resp *Response) {
+	s.applySignedConfigMu.Lock()
+	defer s.applySignedConfigMu.Unlock()
+
+	payload, err := s.verifySignedConfig(sc)
+	if err != nil {
+		log.Logger.Warnw("rejected signed config", "error", err)
+		resp.Error = err.Error()
+		if errors.Is(err, errSignedConfigRequired) {
+			resp.ErrorCode = http.StatusBadRequest
+		} else {
+			resp.ErrorCode = http.StatusForbidden
+		}
+		return
+	}
+
+	if s.dbRW == nil || s.dbRO == nil {
+		resp.Error = "database connection not available"
+		log.Logger.Errorw("applySignedConfig failed: database connection is nil")
+		return
+	}
+
+	prevVersion, err := pkgmetadata.ReadMetadata(ctx, s.dbRO, pkgmetadata.MetadataKeyAppliedConfigVersion)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	if prevVersion == payload.Version {
+		log.Logger.Infow("signed config already applied", "version", payload.Version)
+		return
+	}
+	if notNewer, err := isNotNewerConfigVersion(payload.Version, prevVersion); err != nil {
+		// e.g., applied before the versions were required to be monotonic
+		log.Logger.Warnw("failed to compare with applied config version", "version", payload.Version, "previousVersion", prevVersion, "error", err)
+	} else if notNewer {
+		// the older config signed validly (e.g., captured and replayed) must not roll back the config
+		log.Logger.Warnw("rejected signed config not newer than applied", "version", payload.Version, "previousVersion", prevVersion)
+		resp.Error = fmt.Sprintf("config version %q is not newer than the applied version %q", payload.Version, prevVersion)
+		resp.ErrorCode = http.StatusConflict
+		return
+	}
+
+	if err := validateUpdateConfig(payload.UpdateConfig); err != nil {
+		resp.Error = err.Error()
+		resp.ErrorCode = http.StatusBadRequest
+		return
+	}
+
+	var swap *pluginSwap
+	if payload.CustomPluginSpecs != nil {
+		expanded, err := payload.CustomPluginSpecs.ExpandedValidate()
+		if err == nil {
+			err = expanded.Validate()
+		}
+		if err != nil {
+			resp.Error = err.Error()
+			resp.ErrorCode = http.StatusBadRequest
+			return
+		}
+
+		swap, err = s.swapPluginSpecs(expanded)
+		if err != nil {
+			log.Logger.Warnw("rolled back signed config", "version", payload.Version, "error", err)
+			resp.Error = err.Error()
+			return
+		}
+	}
+
+	if err := pkgmetadata.SetMetadata(ctx, s.dbRW, pkgmetadata.MetadataKeyAppliedConfigVersion, payload.Version); err != nil {
+		swap.rollback()
+		resp.Error = err.Error()
+		return
+	}
+
+	if payload.CustomPluginSpecs != nil && s.savePluginSpecsFunc != nil {
+		if _, err := s.savePluginSpecsFunc(ctx, payload.CustomPluginSpecs); err != nil {
+			swap.rollback()
+			if rerr := pkgmetadata.SetMetadata(ctx, s.dbRW, pkgmetadata.MetadataKeyAppliedConfigVersion, prevVersion); rerr != nil {
+				log.Logger.Errorw("failed to restore applied config version", "version", prevVersion, "error", rerr)
+			}
+			resp.Error = err.Error()
+			return
+		}
+	}
+
+	swap.commit()
+
+	if len(payload.UpdateConfig) > 0 {
+		if s.skipUpdateConfig {
+			log.Logger.Warnw("skipping update config of signed config", "reason", "skip-session-update-config flag enabled")
+		} else {
+			s.processUpdateConfig(payload.UpdateConfig, resp)
+		}
+	}
+
+	log.Logger.Infow("successfully applied signed config", "version", payload.Version, "previousVersion", prevVersion, "plugins", len(payload.CustomPluginSpecs))
+}
+
+// pluginSwap tracks the custom plugins replaced in the registry,
+// not yet started (current) nor closed (previous).
+type pluginSwap struct {
+	registry components.Registry
+	previous []components.Component
+	current  []components.Component
+}
+
+// swapPluginSpecs replaces all the registered custom plugins with the ones of the specs.
+// If any fails to initialize, the previous plugins are restored before returning the error.
+func (s *Session) swapPluginSpecs(specs pkgcustomplugins.Specs) (*pluginSwap, error) {
+	swap := &pluginSwap{registry: s.componentsRegistry}
+	for _, c := range s.componentsRegistry.All() {
+		if registeree, ok := c.(pkgcustomplugins.CustomPluginRegisteree); ok && registeree.IsCustomPlugin() {
+			swap.previous = append(swap.previous, s.componentsRegistry.Deregister(c.Name()))
+		}
+	}
+
+	for i := range specs {
+		if specs[i].PluginType == pkgcustomplugins.SpecTypeInit {
+			continue
+		}
+		c, err := s.componentsRegistry.Register(specs[i].NewInitFunc())
+		if err != nil {
+			swap.rollback()
+			return nil, fmt.Errorf("failed to initialize plugin %q: %w", specs[i].ComponentName(), err)
+		}
+		swap.current = append(swap.current, c)
+	}
+	return swap, nil
+}
+
+// rollback closes the plugins just initialized, and re-registers the previous ones.
+func (sw *pluginSwap) rollback() {
+	if sw == nil {
+		return
+	}
+	for _, c := range sw.current {
+		_ = sw.registry.Deregister(c.Name())
+		if err := c.Close(); err != nil {
+			log.Logger.Warnw("failed to close plugin on rollback", "name", c.Name(), "error", err)
+		}
+	}
+	for _, c := range sw.previous {
+		prev := c
+		if _, err := sw.registry.Register(func(*components.GPUdInstance) (components.Component, error) {
+			return prev, nil
+		}); err != nil {
+			log.Logger.Errorw("failed to restore plugin on rollback", "name", prev.Name(), "error", err)
+		}
+	}
+}
+
+// commit closes the previous plugins, and starts the current ones.
+func (sw *pluginSwap) commit() {
+	if sw == nil {
+		return
+	}
+	for _, c := range sw.previous {
+		if err := c.Close(); err != nil {
+			log.Logger.Warnw("failed to close previous plugin", "name", c.Name(), "error", err)
+		}
+	}
+	for _, c := range sw.current {
+		if err := c.Start(); err != nil {
+			log.Logger.Warnw("failed to start plugin", "name", c.Name(), "error", err)
+		}
+	}
+}
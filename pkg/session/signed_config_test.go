@@ -0,0 +1,320 @@
+package session
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/components"
+	pkgcustomplugins "github.com/leptonai/gpud/pkg/custom-plugins"
+	pkgmetadata "github.com/leptonai/gpud/pkg/metadata"
+	"github.com/leptonai/gpud/pkg/release/distsign"
+	pkgsqlite "github.com/leptonai/gpud/pkg/sqlite"
+)
+
+type testConfigSigner struct {
+	rootKeys    []ed25519.PublicKey
+	signingKeys []byte
+	keysSig     []byte
+	signingKey  *distsign.SigningKey
+}
+
+func newTestConfigSigner(t *testing.T) *testConfigSigner {
+	rootPriv, rootPub, err := distsign.GenerateRootKey()
+	require.NoError(t, err)
+	rootKey, err := distsign.ParseRootKey(rootPriv)
+	require.NoError(t, err)
+	rootKeys, err := distsign.ParseRootKeyBundle(rootPub)
+	require.NoError(t, err)
+
+	signingPriv, signingPub, err := distsign.GenerateSigningKey()
+	require.NoError(t, err)
+	signingKey, err := distsign.ParseSigningKey(signingPriv)
+	require.NoError(t, err)
+	keysSig, err := rootKey.SignSigningKeys(signingPub)
+	require.NoError(t, err)
+
+	return &testConfigSigner{
+		rootKeys:    rootKeys,
+		signingKeys: signingPub,
+		keysSig:     keysSig,
+		signingKey:  signingKey,
+	}
+}
+
+func (ts *testConfigSigner) sign(t *testing.T, payload ConfigPayload) *SignedConfig {
+	b, err := json.Marshal(payload)
+	require.NoError(t, err)
+	sig, err := ts.signingKey.SignPayload(b)
+	require.NoError(t, err)
+	return &SignedConfig{
+		Payload:              b,
+		Signature:            sig,
+		SigningKeys:          ts.signingKeys,
+		SigningKeysSignature: ts.keysSig,
+	}
+}
+
+func testPluginSpec(name string) pkgcustomplugins.Spec {
+	return pkgcustomplugins.Spec{
+		PluginName: name,
+		PluginType: pkgcustomplugins.SpecTypeComponent,
+		RunMode:    "manual",
+		Timeout:    metav1.Duration{Duration: time.Minute},
+		HealthStatePlugin: &pkgcustomplugins.Plugin{
+			Steps: []pkgcustomplugins.Step{
+				{
+					Name: name,
+					RunBashScript: &pkgcustomplugins.RunBashScript{
+						ContentType: "plaintext",
+						Script:      "echo hello",
+					},
+				},
+			},
+		},
+	}
+}
+
+func setupSignedConfigSession(t *testing.T) (*Session, components.Registry, *testConfigSigner) {
+	ctx := context.Background()
+	dbRW, dbRO, cleanup := pkgsqlite.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	require.NoError(t, pkgmetadata.CreateTableMetadata(ctx, dbRW))
+
+	registry := components.NewRegistry(&components.GPUdInstance{RootCtx: ctx})
+	signer := newTestConfigSigner(t)
+	s := &Session{
+		ctx:                ctx,
+		machineID:          "test-machine",
+		dbRW:               dbRW,
+		dbRO:               dbRO,
+		componentsRegistry: registry,
+		configRootKeys:     signer.rootKeys,
+	}
+	return s, registry, signer
+}
+
+func appliedConfigVersion(t *testing.T, s *Session) string {
+	v, err := pkgmetadata.ReadMetadata(context.Background(), s.dbRO, pkgmetadata.MetadataKeyAppliedConfigVersion)
+	require.NoError(t, err)
+	return v
+}
+
+func TestProcessApplySignedConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nil signed config", func(t *testing.T) {
+		s, _, _ := setupSignedConfigSession(t)
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, nil, resp)
+		assert.Equal(t, int32(http.StatusBadRequest), resp.ErrorCode)
+	})
+
+	t.Run("untrusted signer", func(t *testing.T) {
+		s, registry, _ := setupSignedConfigSession(t)
+		other := newTestConfigSigner(t)
+
+		spec := testPluginSpec("untrusted")
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, other.sign(t, ConfigPayload{Version: "v1", CustomPluginSpecs: pkgcustomplugins.Specs{spec}}), resp)
+		assert.Equal(t, int32(http.StatusForbidden), resp.ErrorCode)
+		assert.Nil(t, registry.Get(spec.ComponentName()))
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		s, _, signer := setupSignedConfigSession(t)
+		sc := signer.sign(t, ConfigPayload{Version: "v1"})
+		sc.Payload = []byte(`{"version":"v2"}`)
+
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, sc, resp)
+		assert.Equal(t, int32(http.StatusForbidden), resp.ErrorCode)
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+
+	t.Run("other machine", func(t *testing.T) {
+		s, _, signer := setupSignedConfigSession(t)
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", MachineID: "other-machine"}), resp)
+		assert.Equal(t, int32(http.StatusForbidden), resp.ErrorCode)
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+
+	t.Run("invalid component config", func(t *testing.T) {
+		s, _, signer := setupSignedConfigSession(t)
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", UpdateConfig: map[string]string{"accelerator-nvidia-infiniband": "not json"}}), resp)
+		assert.Equal(t, int32(http.StatusBadRequest), resp.ErrorCode)
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+
+	t.Run("apply and replace plugins", func(t *testing.T) {
+		s, registry, signer := setupSignedConfigSession(t)
+		var saved pkgcustomplugins.Specs
+		s.savePluginSpecsFunc = func(_ context.Context, specs pkgcustomplugins.Specs) (bool, error) {
+			saved = specs
+			return true, nil
+		}
+
+		first := testPluginSpec("first")
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", CustomPluginSpecs: pkgcustomplugins.Specs{first}}), resp)
+		require.Empty(t, resp.Error)
+		assert.NotNil(t, registry.Get(first.ComponentName()))
+		assert.Equal(t, "v1", appliedConfigVersion(t, s))
+		assert.Len(t, saved, 1)
+
+		// same version is not applied again
+		saved = nil
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", CustomPluginSpecs: pkgcustomplugins.Specs{}}), resp)
+		require.Empty(t, resp.Error)
+		assert.NotNil(t, registry.Get(first.ComponentName()))
+		assert.Nil(t, saved)
+
+		second := testPluginSpec("second")
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v2", CustomPluginSpecs: pkgcustomplugins.Specs{second}}), resp)
+		require.Empty(t, resp.Error)
+		assert.Nil(t, registry.Get(first.ComponentName()))
+		assert.NotNil(t, registry.Get(second.ComponentName()))
+		assert.Equal(t, "v2", appliedConfigVersion(t, s))
+	})
+
+	t.Run("replayed older config", func(t *testing.T) {
+		s, registry, signer := setupSignedConfigSession(t)
+
+		old := testPluginSpec("old")
+		oldConfig := signer.sign(t, ConfigPayload{Version: "v9", CustomPluginSpecs: pkgcustomplugins.Specs{old}})
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, oldConfig, resp)
+		require.Empty(t, resp.Error)
+
+		current := testPluginSpec("current")
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v10", CustomPluginSpecs: pkgcustomplugins.Specs{current}}), resp)
+		require.Empty(t, resp.Error)
+		assert.Equal(t, "v10", appliedConfigVersion(t, s))
+
+		// the captured older config is validly signed, but must not roll back the config
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, oldConfig, resp)
+		assert.Equal(t, int32(http.StatusConflict), resp.ErrorCode)
+		assert.Nil(t, registry.Get(old.ComponentName()))
+		assert.NotNil(t, registry.Get(current.ComponentName()))
+		assert.Equal(t, "v10", appliedConfigVersion(t, s))
+
+		// the same version in the other form is not newer either
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v10.0", CustomPluginSpecs: pkgcustomplugins.Specs{old}}), resp)
+		assert.Equal(t, int32(http.StatusConflict), resp.ErrorCode)
+		assert.Equal(t, "v10", appliedConfigVersion(t, s))
+	})
+
+	t.Run("concurrent applies", func(t *testing.T) {
+		s, _, signer := setupSignedConfigSession(t)
+		older := signer.sign(t, ConfigPayload{Version: "v1"})
+		newer := signer.sign(t, ConfigPayload{Version: "v2"})
+
+		// whichever is applied first, the older config must not overwrite the newer one
+		var wg sync.WaitGroup
+		for _, sc := range []*SignedConfig{newer, older} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.processApplySignedConfig(ctx, sc, &Response{})
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, "v2", appliedConfigVersion(t, s))
+	})
+
+	t.Run("non-numeric version", func(t *testing.T) {
+		s, _, signer := setupSignedConfigSession(t)
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "latest"}), resp)
+		assert.Equal(t, int32(http.StatusForbidden), resp.ErrorCode)
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+
+	t.Run("rollback on plugin init failure", func(t *testing.T) {
+		s, registry, signer := setupSignedConfigSession(t)
+
+		prev := testPluginSpec("previous")
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", CustomPluginSpecs: pkgcustomplugins.Specs{prev}}), resp)
+		require.Empty(t, resp.Error)
+		prevComp := registry.Get(prev.ComponentName())
+		require.NotNil(t, prevComp)
+
+		// a built-in component with the same name fails the plugin registration
+		conflict := testPluginSpec("conflict")
+		builtin := &mockComponent{}
+		builtin.On("Name").Return(conflict.ComponentName())
+		_, err := registry.Register(func(*components.GPUdInstance) (components.Component, error) { return builtin, nil })
+		require.NoError(t, err)
+
+		next := testPluginSpec("next")
+		resp = &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v2", CustomPluginSpecs: pkgcustomplugins.Specs{next, conflict}}), resp)
+		assert.NotEmpty(t, resp.Error)
+
+		assert.Same(t, prevComp, registry.Get(prev.ComponentName()))
+		assert.Nil(t, registry.Get(next.ComponentName()))
+		assert.Same(t, builtin, registry.Get(conflict.ComponentName()))
+		assert.Equal(t, "v1", appliedConfigVersion(t, s))
+	})
+
+	t.Run("rollback on save failure", func(t *testing.T) {
+		s, registry, signer := setupSignedConfigSession(t)
+		s.savePluginSpecsFunc = func(context.Context, pkgcustomplugins.Specs) (bool, error) {
+			return false, errors.New("disk full")
+		}
+
+		spec := testPluginSpec("unsaved")
+		resp := &Response{}
+		s.processApplySignedConfig(ctx, signer.sign(t, ConfigPayload{Version: "v1", CustomPluginSpecs: pkgcustomplugins.Specs{spec}}), resp)
+		assert.Equal(t, "disk full", resp.Error)
+		assert.Nil(t, registry.Get(spec.ComponentName()))
+		assert.Empty(t, appliedConfigVersion(t, s))
+	})
+}
+
+func TestIsNotNewerConfigVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		prev     string
+		expected bool
+	}{
+		{version: "v1", prev: "", expected: false},
+		{version: "v2", prev: "v1", expected: false},
+		{version: "v10", prev: "v9", expected: false},
+		{version: "v1.1", prev: "v1", expected: false},
+		{version: "2025.10.14.1", prev: "2025.10.14", expected: false},
+		{version: "v1", prev: "v2", expected: true},
+		{version: "v9", prev: "v10", expected: true},
+		{version: "v1", prev: "v1.0", expected: true},
+		{version: "1", prev: "v1", expected: true},
+	} {
+		notNewer, err := isNotNewerConfigVersion(tc.version, tc.prev)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, notNewer, "%q after %q", tc.version, tc.prev)
+	}
+
+	_, err := isNotNewerConfigVersion("v1", "abc")
+	assert.Error(t, err)
+	_, err = parseConfigVersion("v1.x")
+	assert.Error(t, err)
+	_, err = parseConfigVersion("v")
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	componentsnvidiagpucounts "github.com/leptonai/gpud/components/accelerator/nvidia/gpu-counts"
@@ -16,6 +17,67 @@ import (
 	pkgnfschecker "github.com/leptonai/gpud/pkg/nfs-checker"
 )
 
+// updateConfigDecoder decodes the config of a component,
+// and returns the function to apply the decoded config to the session.
+type updateConfigDecoder func(value string) (apply func(s *Session), err error)
+
+// newUpdateConfigDecoder returns the decoder of the config of type T, applied with the apply function.
+func newUpdateConfigDecoder[T any](apply func(s *Session, cfg T)) updateConfigDecoder {
+	return func(value string) (func(s *Session), error) {
+		var cfg T
+		if err := json.Unmarshal([]byte(value), &cfg); err != nil {
+			return nil, err
+		}
+		return func(s *Session) { apply(s, cfg) }, nil
+	}
+}
+
+// updateConfigDecoders are the config decoders of the supported components by the component name,
+// shared by the config updates and their validation.
+var updateConfigDecoders = map[string]updateConfigDecoder{
+	componentsnvidiainfiniband.Name: newUpdateConfigDecoder(func(s *Session, cfg componentsnvidiainfinibanditypes.ExpectedPortStates) {
+		if s.setDefaultIbExpectedPortStatesFunc != nil {
+			s.setDefaultIbExpectedPortStatesFunc(cfg)
+		}
+	}),
+	componentsnvidianvlink.Name: newUpdateConfigDecoder(func(s *Session, cfg componentsnvidianvlink.ExpectedLinkStates) {
+		if s.setDefaultNVLinkExpectedLinkStatesFunc != nil {
+			s.setDefaultNVLinkExpectedLinkStatesFunc(cfg)
+		}
+	}),
+	componentsnvidiagpucounts.Name: newUpdateConfigDecoder(func(s *Session, cfg componentsnvidiagpucounts.ExpectedGPUCounts) {
+		if s.setDefaultGPUCountsFunc != nil {
+			s.setDefaultGPUCountsFunc(cfg)
+		}
+	}),
+	componentsxid.Name: newUpdateConfigDecoder(func(s *Session, cfg componentsxid.RebootThreshold) {
+		if s.setDefaultXIDRebootThresholdFunc != nil {
+			s.setDefaultXIDRebootThresholdFunc(cfg)
+		}
+	}),
+	componentstemperature.Name: newUpdateConfigDecoder(func(s *Session, cfg componentstemperature.Thresholds) {
+		if s.setDefaultTemperatureThresholdsFunc != nil {
+			s.setDefaultTemperatureThresholdsFunc(cfg)
+		}
+	}),
+	componentsnfs.Name: newUpdateConfigDecoder(func(s *Session, cfgs pkgnfschecker.Configs) {
+		// if NFS validation takes too long, it can block other session requests
+		// so we set a timeout and do it async
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := cfgs.Validate(ctx)
+			cancel()
+			if err != nil {
+				log.Logger.Warnw("invalid nfs config but proceeding with update to allow the user to fix the config", "error", err)
+			}
+
+			if s.setDefaultNFSGroupConfigsFunc != nil {
+				s.setDefaultNFSGroupConfigsFunc(cfgs)
+			}
+		}()
+	}),
+}
+
 func (s *Session) processUpdateConfig(configMap map[string]string, resp *Response) {
 	if len(configMap) == 0 {
 		return
@@ -25,94 +87,20 @@ func (s *Session) processUpdateConfig(configMap map[string]string, resp *Respons
 	for componentName, value := range configMap {
 		log.Logger.Infow("processing update config request", "component", componentName, "config", value)
 
-		switch componentName {
-		case componentsnvidiainfiniband.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfg componentsnvidiainfinibanditypes.ExpectedPortStates
-			if err := json.Unmarshal([]byte(value), &updateCfg); err != nil {
-				log.Logger.Warnw("failed to unmarshal infiniband config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-			if s.setDefaultIbExpectedPortStatesFunc != nil {
-				s.setDefaultIbExpectedPortStatesFunc(updateCfg)
-			}
-
-		case componentsnvidianvlink.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfg componentsnvidianvlink.ExpectedLinkStates
-			if err := json.Unmarshal([]byte(value), &updateCfg); err != nil {
-				log.Logger.Warnw("failed to unmarshal nvlink config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-			if s.setDefaultNVLinkExpectedLinkStatesFunc != nil {
-				s.setDefaultNVLinkExpectedLinkStatesFunc(updateCfg)
-			}
-
-		case componentsnvidiagpucounts.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfg componentsnvidiagpucounts.ExpectedGPUCounts
-			if err := json.Unmarshal([]byte(value), &updateCfg); err != nil {
-				log.Logger.Warnw("failed to unmarshal nvidia gpu counts config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-			if s.setDefaultGPUCountsFunc != nil {
-				s.setDefaultGPUCountsFunc(updateCfg)
-			}
-
-		case componentsxid.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfg componentsxid.RebootThreshold
-			if err := json.Unmarshal([]byte(value), &updateCfg); err != nil {
-				log.Logger.Warnw("failed to unmarshal xid config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-			if s.setDefaultXIDRebootThresholdFunc != nil {
-				s.setDefaultXIDRebootThresholdFunc(updateCfg)
-			}
-
-		case componentstemperature.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfg componentstemperature.Thresholds
-			if err := json.Unmarshal([]byte(value), &updateCfg); err != nil {
-				log.Logger.Warnw("failed to unmarshal temperature config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-			if s.setDefaultTemperatureThresholdsFunc != nil {
-				s.setDefaultTemperatureThresholdsFunc(updateCfg)
-			}
-
-		case componentsnfs.Name:
-			setComponents[componentName] = struct{}{}
-			var updateCfgs pkgnfschecker.Configs
-			if err := json.Unmarshal([]byte(value), &updateCfgs); err != nil {
-				log.Logger.Warnw("failed to unmarshal nfs config", "error", err)
-				resp.Error = err.Error()
-				return
-			}
-
-			// if NFS validation takes too long, it can block other session requests
-			// so we set a timeout and do it async
-			go func() {
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				err := updateCfgs.Validate(ctx)
-				cancel()
-				if err != nil {
-					log.Logger.Warnw("invalid nfs config but proceeding with update to allow the user to fix the config", "error", err)
-				}
-
-				if s.setDefaultNFSGroupConfigsFunc != nil {
-					s.setDefaultNFSGroupConfigsFunc(updateCfgs)
-				}
-			}()
-
-		default:
+		decode, ok := updateConfigDecoders[componentName]
+		if !ok {
 			log.Logger.Warnw("unsupported component for updateConfig", "component", componentName)
+			continue
 		}
+
+		setComponents[componentName] = struct{}{}
+		apply, err := decode(value)
+		if err != nil {
+			log.Logger.Warnw("failed to unmarshal config", "component", componentName, "error", err)
+			resp.Error = err.Error()
+			return
+		}
+		apply(s)
 	}
 
 	// fallback to default if the component is not set
@@ -141,3 +129,18 @@ func (s *Session) processUpdateConfig(configMap map[string]string, resp *Respons
 		s.setDefaultTemperatureThresholdsFunc(componentstemperature.Thresholds{CelsiusSlowdownMargin: componentstemperature.ThresholdCelsiusSlowdownMargin})
 	}
 }
+
+// validateUpdateConfig decodes the config of each supported component without applying it,
+// so that a signed config is either fully applied or not at all.
+func validateUpdateConfig(configMap map[string]string) error {
+	for componentName, value := range configMap {
+		decode, ok := updateConfigDecoders[componentName]
+		if !ok {
+			continue
+		}
+		if _, err := decode(value); err != nil {
+			return fmt.Errorf("invalid %s config: %w", componentName, err)
+		}
+	}
+	return nil
+}
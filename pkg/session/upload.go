@@ -0,0 +1,333 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/session/upload"
+)
+
+// uploadLoop periodically uploads the metrics and events recorded
+// since the last upload, until the session is stopped.
+func (s *Session) uploadLoop() {
+	ticker := time.NewTicker(s.uploadConfig.Interval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+			s.uploadOnce(ctx, now.UTC())
+			cancel()
+		}
+	}
+}
+
+// maxUploadWindowIntervals caps the window of a single upload in the upload intervals,
+// so that an upload never reads an unbounded window (e.g., the cursor long behind).
+// The rest of the metrics and events are collected with the next uploads.
+const maxUploadWindowIntervals = 10
+
+// uploadOnce persists the batches of the metrics and events
+// recorded between the last upload and the given time to the upload queue,
+// and then sends the queued batches to the control plane.
+//
+// If the batches of the previous upload were not all queued (e.g., the queue is full),
+// the same window is collected again with the same upload IDs, and only the
+// batches not yet queued are queued.
+func (s *Session) uploadOnce(ctx context.Context, now time.Time) {
+	since := s.uploadCursor
+	until := s.uploadPendingUntil
+	if until.IsZero() {
+		until = now
+		if maxUntil := since.Add(maxUploadWindowIntervals * s.uploadConfig.Interval.Duration); until.After(maxUntil) {
+			until = maxUntil
+		}
+	}
+	batches := s.collectUploadBatches(ctx, since, until)
+
+	for i := s.uploadPendingQueued; i < len(batches); i++ {
+		b := batches[i]
+		uploadID := uploadBatchID(s.machineID, since, until, i)
+		body, err := s.encodeUploadBatch(uploadID, b)
+		if err != nil {
+			log.Logger.Errorw("session upload: failed to encode batch", "error", err)
+			continue
+		}
+		if !s.dataBudget.AdmitUpload(len(body), false) {
+			body = s.sampleUploadBatch(uploadID, b, body)
+			if body == nil {
+				continue
+			}
+		}
+		evicted, err := s.uploadQueue.Push(body)
+		if evicted > 0 {
+			log.Logger.Warnw("session upload: queue full, evicted oldest batches", "evicted", evicted)
+		}
+		if err != nil {
+			if errors.Is(err, upload.ErrBatchTooLarge) {
+				// never fits in the queue, so retrying does not help
+				log.Logger.Errorw("session upload: dropping batch", "error", err)
+				continue
+			}
+			if errors.Is(err, upload.ErrQueueFull) {
+				log.Logger.Warnw("session upload: queue full, retrying with the next upload")
+			} else {
+				log.Logger.Errorw("session upload: failed to queue batch, retrying with the next upload", "error", err)
+			}
+
+			// only advance the cursor once all the batches are queued, so that the failed batch
+			// is collected again with the next upload
+			s.uploadPendingUntil = until
+			s.uploadPendingQueued = i
+			s.flushUploadQueue()
+			return
+		}
+	}
+
+	s.uploadCursor = until
+	s.uploadPendingUntil = time.Time{}
+	s.uploadPendingQueued = 0
+
+	s.flushUploadQueue()
+}
+
+// uploadBatchID returns the upload ID of the i-th batch of the (since, until] window,
+// the same for the batch collected again, for the control plane to deduplicate.
+func uploadBatchID(machineID string, since, until time.Time, i int) string {
+	return fmt.Sprintf("%s-%d-%d-%d", machineID, since.UnixMilli(), until.UnixMilli(), i)
+}
+
+// sampleUploadBatch returns the body of the batch with only the critical and fatal events,
+// once over the upload budget, or nil if the batch has none of them.
+func (s *Session) sampleUploadBatch(uploadID string, batch Response, body []byte) []byte {
+	reduced := Response{}
+	for _, evs := range batch.Events {
+		var kept apiv1.Events
+		for _, ev := range evs.Events {
+			if ev.Type == apiv1.EventTypeCritical || ev.Type == apiv1.EventTypeFatal {
+				kept = append(kept, ev)
+			}
+		}
+		if len(kept) > 0 {
+			evs.Events = kept
+			reduced.Events = append(reduced.Events, evs)
+		}
+	}
+	if len(reduced.Events) == 0 {
+		log.Logger.Warnw("session upload: over upload budget, sampled away batch", "uploadID", uploadID, "bytes", len(body))
+		s.dataBudget.SampledUpload(len(body))
+		return nil
+	}
+
+	reducedBody, err := s.encodeUploadBatch(uploadID, reduced)
+	if err != nil {
+		log.Logger.Errorw("session upload: failed to encode batch", "error", err)
+		return nil
+	}
+	log.Logger.Warnw("session upload: over upload budget, uploading only critical and fatal events", "uploadID", uploadID, "bytes", len(body), "reducedBytes", len(reducedBody))
+	s.dataBudget.SampledUpload(len(body) - len(reducedBody))
+	s.dataBudget.AdmitUpload(len(reducedBody), true)
+	return reducedBody
+}
+
+// encodeUploadBatch marshals and compresses the batch into the session body.
+func (s *Session) encodeUploadBatch(uploadID string, batch Response) ([]byte, error) {
+	raw, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	data, err := upload.Compress(s.uploadConfig.Compression, raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Body{
+		Data:     data,
+		UploadID: uploadID,
+		Encoding: string(s.uploadConfig.Compression),
+	})
+}
+
+// flushUploadQueue sends the queued batches to the control plane, oldest first.
+// It stops when the session writer is busy, leaving the rest for the next upload.
+func (s *Session) flushUploadQueue() {
+	s.uploadFlushMu.Lock()
+	defer s.uploadFlushMu.Unlock()
+
+	if s.uploadAcks != nil {
+		s.flushUploadQueueAcked()
+		return
+	}
+
+	for {
+		batch, ok, err := s.uploadQueue.Peek()
+		if err != nil {
+			log.Logger.Errorw("session upload: failed to read queue", "error", err)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		var body Body
+		if err := json.Unmarshal(batch.Data, &body); err != nil {
+			log.Logger.Errorw("session upload: dropping corrupted batch", "batchID", batch.ID, "error", err)
+			if err := s.uploadQueue.Remove(batch.ID); err != nil {
+				log.Logger.Errorw("session upload: failed to remove batch", "batchID", batch.ID, "error", err)
+				return
+			}
+			continue
+		}
+
+		if !s.trySendUpload(body) {
+			return
+		}
+		if err := s.uploadQueue.Remove(batch.ID); err != nil {
+			log.Logger.Errorw("session upload: failed to remove batch", "batchID", batch.ID, "error", err)
+			return
+		}
+	}
+}
+
+// trySendUpload writes the batch to s.writer without blocking, so that
+// the uploads never delay the responses to the control plane requests.
+// It keeps the half of the writer buffer for the responses.
+func (s *Session) trySendUpload(body Body) (sent bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Warnw("session upload: failed to write batch, writer closed", "uploadID", body.UploadID, "panic", r)
+			sent = false
+		}
+	}()
+
+	if len(s.writer) >= cap(s.writer)/2 {
+		log.Logger.Debugw("session upload: writer busy, keeping batch queued", "uploadID", body.UploadID)
+		return false
+	}
+
+	select {
+	case <-s.ctx.Done():
+		return false
+	case s.writer <- body:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectUploadBatches reads the metrics and events recorded in (since, until],
+// split into the batches of at most the configured batch size.
+func (s *Session) collectUploadBatches(ctx context.Context, since, until time.Time) []Response {
+	b := newUploadBatcher(s.uploadConfig.MaxBatchSize)
+
+	if s.metricsStore != nil {
+		ms, err := s.metricsStore.Read(ctx, pkgmetrics.WithSince(since))
+		if err != nil {
+			log.Logger.Errorw("session upload: failed to read metrics", "error", err)
+		}
+		for _, m := range ms {
+			if m.UnixMilliseconds <= since.UnixMilli() || m.UnixMilliseconds > until.UnixMilli() {
+				continue
+			}
+			b.addMetric(m.Component, apiv1.Metric{
+				UnixSeconds: m.UnixMilliseconds,
+				Name:        m.Name,
+				Labels:      m.Labels,
+				Value:       m.Value,
+				Unit:        pkgmetrics.GetUnit(m.Name),
+			})
+		}
+	}
+
+	for _, name := range s.components {
+		comp := s.componentsRegistry.Get(name)
+		if comp == nil {
+			continue
+		}
+		evs, err := comp.Events(ctx, since)
+		if err != nil {
+			log.Logger.Errorw("session upload: failed to read events", "component", name, "error", err)
+			continue
+		}
+		for _, ev := range evs {
+			if !ev.Time.After(since) || ev.Time.After(until) {
+				continue
+			}
+			b.addEvent(name, since, until, ev)
+		}
+	}
+
+	return b.finish()
+}
+
+// uploadBatcher splits the metrics and events into the batches,
+// grouped by the component within each batch.
+type uploadBatcher struct {
+	maxSize int
+
+	batches []Response
+	cur     Response
+	size    int
+
+	metricIdx map[string]int
+	eventIdx  map[string]int
+}
+
+func newUploadBatcher(maxSize int) *uploadBatcher {
+	if maxSize <= 0 {
+		maxSize = upload.DefaultMaxBatchSize
+	}
+	return &uploadBatcher{
+		maxSize:   maxSize,
+		metricIdx: make(map[string]int),
+		eventIdx:  make(map[string]int),
+	}
+}
+
+func (b *uploadBatcher) next() {
+	if b.size >= b.maxSize {
+		b.batches = append(b.batches, b.cur)
+		b.cur = Response{}
+		b.size = 0
+		b.metricIdx = make(map[string]int)
+		b.eventIdx = make(map[string]int)
+	}
+	b.size++
+}
+
+func (b *uploadBatcher) addMetric(component string, m apiv1.Metric) {
+	b.next()
+	idx, ok := b.metricIdx[component]
+	if !ok {
+		idx = len(b.cur.Metrics)
+		b.metricIdx[component] = idx
+		b.cur.Metrics = append(b.cur.Metrics, apiv1.ComponentMetrics{Component: component})
+	}
+	b.cur.Metrics[idx].Metrics = append(b.cur.Metrics[idx].Metrics, m)
+}
+
+func (b *uploadBatcher) addEvent(component string, since, until time.Time, ev apiv1.Event) {
+	b.next()
+	idx, ok := b.eventIdx[component]
+	if !ok {
+		idx = len(b.cur.Events)
+		b.eventIdx[component] = idx
+		b.cur.Events = append(b.cur.Events, apiv1.ComponentEvents{Component: component, StartTime: since, EndTime: until})
+	}
+	b.cur.Events[idx].Events = append(b.cur.Events[idx].Events, ev)
+}
+
+func (b *uploadBatcher) finish() []Response {
+	if b.size > 0 {
+		b.batches = append(b.batches, b.cur)
+	}
+	return b.batches
+}
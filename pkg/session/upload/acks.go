@@ -0,0 +1,150 @@
+package upload
+
+import (
+	"sync"
+	"time"
+)
+
+// Acks tracks the queued batches sent to the control plane,
+// pending the acknowledgements of their upload IDs.
+// The tracking is in memory only, thus all the queued batches
+// are sent again after the GPUd restarts.
+// Safe for concurrent use.
+type Acks struct {
+	timeout time.Duration
+
+	mu sync.Mutex
+	// sent is the sent batches keyed by the batch ID
+	sent map[string]*sentBatch
+	// batchIDs maps the upload ID to the batch ID
+	batchIDs map[string]string
+
+	lastAckAt    time.Time
+	redeliveries int64
+}
+
+// sentBatch is a batch sent, pending the acknowledgement.
+type sentBatch struct {
+	uploadID string
+	// sentAt is when the batch was last sent,
+	// zero once the connection is reset to redeliver
+	sentAt time.Time
+	// firstSentAt is when the batch was first sent
+	firstSentAt time.Time
+}
+
+// NewAcks creates the acknowledgement tracker, with the sent batches
+// redelivered once unacknowledged for the timeout
+// (defaults to DefaultAckTimeout if zero).
+func NewAcks(timeout time.Duration) *Acks {
+	if timeout <= 0 {
+		timeout = DefaultAckTimeout
+	}
+	return &Acks{
+		timeout:  timeout,
+		sent:     make(map[string]*sentBatch),
+		batchIDs: make(map[string]string),
+	}
+}
+
+// Pending returns true if the batch was sent and is waiting for the acknowledgement
+// within the timeout, thus not to be sent again yet.
+func (a *Acks) Pending(batchID string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sb, ok := a.sent[batchID]
+	if !ok || sb.sentAt.IsZero() {
+		return false
+	}
+	return now.Sub(sb.sentAt) < a.timeout
+}
+
+// MarkSent records the batch sent with the upload ID.
+// Sending an already sent batch counts as a redelivery.
+func (a *Acks) MarkSent(batchID string, uploadID string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if sb, ok := a.sent[batchID]; ok {
+		sb.sentAt = now
+		a.redeliveries++
+		return
+	}
+	a.sent[batchID] = &sentBatch{uploadID: uploadID, sentAt: now, firstSentAt: now}
+	a.batchIDs[uploadID] = batchID
+}
+
+// Ack records the acknowledgement of the upload ID, and returns the acknowledged batch ID
+// to remove from the queue. It returns false if the upload ID was never sent
+// (e.g., acknowledged twice, or sent before the restart).
+func (a *Acks) Ack(uploadID string, now time.Time) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	batchID, ok := a.batchIDs[uploadID]
+	if !ok {
+		return "", false
+	}
+	delete(a.batchIDs, uploadID)
+	delete(a.sent, batchID)
+	a.lastAckAt = now
+	return batchID, true
+}
+
+// Reset forgets when the batches were sent, so that all the unacknowledged batches
+// are redelivered (e.g., on the reconnect, as the batches written to the previous
+// connection may be lost). It returns the number of the unacknowledged batches.
+func (a *Acks) Reset() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, sb := range a.sent {
+		sb.sentAt = time.Time{}
+	}
+	return len(a.sent)
+}
+
+// Retain forgets the sent batches no longer queued (e.g., evicted).
+func (a *Acks) Retain(queued map[string]struct{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for batchID, sb := range a.sent {
+		if _, ok := queued[batchID]; !ok {
+			delete(a.sent, batchID)
+			delete(a.batchIDs, sb.uploadID)
+		}
+	}
+}
+
+// AckStats is the statistics of the acknowledgements.
+type AckStats struct {
+	// Unacked is the number of the sent batches pending the acknowledgements.
+	Unacked int
+	// OldestUnackedAt is when the oldest unacknowledged batch was first sent,
+	// zero if none.
+	OldestUnackedAt time.Time
+	// LastAckAt is when the last acknowledgement was received, zero if never.
+	LastAckAt time.Time
+	// Redeliveries is the number of the batches sent again since the start.
+	Redeliveries int64
+}
+
+// Stats returns the statistics of the acknowledgements.
+func (a *Acks) Stats() AckStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := AckStats{
+		Unacked:      len(a.sent),
+		LastAckAt:    a.lastAckAt,
+		Redeliveries: a.redeliveries,
+	}
+	for _, sb := range a.sent {
+		if st.OldestUnackedAt.IsZero() || sb.firstSentAt.Before(st.OldestUnackedAt) {
+			st.OldestUnackedAt = sb.firstSentAt
+		}
+	}
+	return st
+}
@@ -0,0 +1,49 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcks(t *testing.T) {
+	a := NewAcks(time.Minute)
+	now := time.Now()
+
+	assert.False(t, a.Pending("b1", now))
+	a.MarkSent("b1", "u1", now)
+	a.MarkSent("b2", "u2", now.Add(time.Second))
+	assert.True(t, a.Pending("b1", now.Add(30*time.Second)))
+
+	st := a.Stats()
+	assert.Equal(t, 2, st.Unacked)
+	assert.Equal(t, now, st.OldestUnackedAt)
+	assert.True(t, st.LastAckAt.IsZero())
+
+	// redelivered after the timeout
+	assert.False(t, a.Pending("b1", now.Add(time.Minute)))
+	a.MarkSent("b1", "u1", now.Add(time.Minute))
+	assert.Equal(t, int64(1), a.Stats().Redeliveries)
+
+	batchID, ok := a.Ack("u1", now.Add(2*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, "b1", batchID)
+	_, ok = a.Ack("u1", now.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	st = a.Stats()
+	assert.Equal(t, 1, st.Unacked)
+	assert.Equal(t, now.Add(time.Second), st.OldestUnackedAt)
+	assert.Equal(t, now.Add(2*time.Minute), st.LastAckAt)
+
+	// redelivered on the reconnect
+	assert.Equal(t, 1, a.Reset())
+	assert.False(t, a.Pending("b2", now.Add(2*time.Second)))
+
+	// evicted
+	a.Retain(map[string]struct{}{})
+	assert.Zero(t, a.Stats().Unacked)
+	_, ok = a.Ack("u2", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package upload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compress compresses the data with the compression algorithm.
+// The data is returned as is for "none" or empty compression.
+func Compress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case "", CompressionNone:
+		return data, nil
+
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = enc.Close()
+		}()
+		return enc.EncodeAll(data, nil), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCompression, c)
+	}
+}
+
+// Decompress decompresses the data compressed by [Compress].
+func Decompress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case "", CompressionNone:
+		return data, nil
+
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = r.Close()
+		}()
+		return io.ReadAll(r)
+
+	case CompressionZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		return dec.DecodeAll(data, nil)
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCompression, c)
+	}
+}
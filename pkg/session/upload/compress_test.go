@@ -0,0 +1,37 @@
+package upload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"name":"accelerator_nvidia_temperature_current_celsius","value":42}`), 100)
+
+	for _, c := range []Compression{"", CompressionNone, CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			compressed, err := Compress(c, data)
+			require.NoError(t, err)
+			if c == CompressionGzip || c == CompressionZstd {
+				assert.Less(t, len(compressed), len(data))
+			} else {
+				assert.Equal(t, data, compressed)
+			}
+
+			decompressed, err := Decompress(c, compressed)
+			require.NoError(t, err)
+			assert.Equal(t, data, decompressed)
+		})
+	}
+
+	_, err := Compress("brotli", data)
+	assert.ErrorIs(t, err, ErrInvalidCompression)
+	_, err = Decompress("brotli", data)
+	assert.ErrorIs(t, err, ErrInvalidCompression)
+
+	_, err = Decompress(CompressionGzip, []byte("not gzip"))
+	assert.Error(t, err)
+}
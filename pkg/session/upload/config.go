@@ -0,0 +1,148 @@
+// Package upload implements the batching, compression, and on-disk
+// overflow queue for the metrics and events uploaded to the control plane.
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultInterval is the default interval between two uploads.
+	DefaultInterval = time.Minute
+	// MinInterval is the minimum interval between two uploads.
+	MinInterval = 10 * time.Second
+
+	// DefaultMaxBatchSize is the default maximum number of metric data points
+	// and events in a single upload batch.
+	DefaultMaxBatchSize = 5000
+
+	// DefaultQueueMaxBytes is the default maximum size of the on-disk queue (256 MiB).
+	DefaultQueueMaxBytes = 256 * 1024 * 1024
+
+	// DefaultAckTimeout is the default duration to wait for the acknowledgement
+	// of a sent batch, after which the batch is redelivered.
+	DefaultAckTimeout = 5 * time.Minute
+)
+
+// Compression is the compression algorithm of the upload batches.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// EvictionPolicy defines which batches to drop when the on-disk queue is full.
+type EvictionPolicy string
+
+const (
+	// EvictionPolicyDropOldest drops the oldest batches to make room for the new one.
+	EvictionPolicyDropOldest EvictionPolicy = "drop-oldest"
+	// EvictionPolicyDropNewest rejects the new batch, keeping the queued ones.
+	EvictionPolicyDropNewest EvictionPolicy = "drop-newest"
+)
+
+var (
+	ErrInvalidCompression    = errors.New("invalid compression")
+	ErrInvalidEvictionPolicy = errors.New("invalid eviction policy")
+)
+
+// Config configures the metrics and events upload to the control plane.
+type Config struct {
+	// Interval is the interval between two uploads.
+	// The metrics and events recorded within the interval are sent in batches.
+	// Defaults to 1 minute, must be >= 10 seconds if specified.
+	Interval metav1.Duration `json:"interval"`
+
+	// MaxBatchSize is the maximum number of metric data points and events
+	// in a single upload batch. Defaults to 5000.
+	MaxBatchSize int `json:"max_batch_size"`
+
+	// Compression is the compression algorithm of the upload batches,
+	// one of "none", "gzip", and "zstd". Defaults to "gzip".
+	Compression Compression `json:"compression"`
+
+	// QueueDir is the directory to persist the batches that are not yet uploaded
+	// (e.g., the control plane is unreachable). Defaults to the "session-upload-queue"
+	// directory under the GPUd data directory.
+	QueueDir string `json:"queue_dir"`
+
+	// QueueMaxBytes is the maximum total size of the queued batches in bytes.
+	// Defaults to 256 MiB.
+	QueueMaxBytes int64 `json:"queue_max_bytes"`
+
+	// EvictionPolicy is the policy to apply when the queue is full,
+	// one of "drop-oldest" and "drop-newest". Defaults to "drop-oldest".
+	EvictionPolicy EvictionPolicy `json:"eviction_policy"`
+
+	// RequireAck keeps the sent batches queued until the control plane acknowledges
+	// their upload IDs (with the "ackUploads" request), and redelivers the unacknowledged
+	// batches on the reconnects or after the ack timeout (i.e., at-least-once delivery).
+	// Otherwise, the batches are removed from the queue once written to the session.
+	RequireAck bool `json:"require_ack"`
+
+	// AckTimeout is the duration to wait for the acknowledgement of a sent batch
+	// before redelivering it, only if RequireAck is set. Defaults to 5 minutes.
+	AckTimeout metav1.Duration `json:"ack_timeout"`
+}
+
+// Validate validates the upload config.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Interval.Duration < 0 || (cfg.Interval.Duration > 0 && cfg.Interval.Duration < MinInterval) {
+		return fmt.Errorf("interval must be at least %s, got %s", MinInterval, cfg.Interval.Duration)
+	}
+	if cfg.MaxBatchSize < 0 {
+		return fmt.Errorf("max_batch_size must be non-negative, got %d", cfg.MaxBatchSize)
+	}
+	if cfg.AckTimeout.Duration < 0 {
+		return fmt.Errorf("ack_timeout must be non-negative, got %s", cfg.AckTimeout.Duration)
+	}
+	if cfg.QueueMaxBytes < 0 {
+		return fmt.Errorf("queue_max_bytes must be non-negative, got %d", cfg.QueueMaxBytes)
+	}
+	switch cfg.Compression {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidCompression, cfg.Compression)
+	}
+	switch cfg.EvictionPolicy {
+	case "", EvictionPolicyDropOldest, EvictionPolicyDropNewest:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidEvictionPolicy, cfg.EvictionPolicy)
+	}
+	return nil
+}
+
+// SetDefaults sets the default values for the unset fields.
+// The queue directory defaults to the given directory.
+func (cfg *Config) SetDefaults(defaultQueueDir string) {
+	if cfg.Interval.Duration == 0 {
+		cfg.Interval.Duration = DefaultInterval
+	}
+	if cfg.MaxBatchSize == 0 {
+		cfg.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if cfg.Compression == "" {
+		cfg.Compression = CompressionGzip
+	}
+	if cfg.QueueDir == "" {
+		cfg.QueueDir = defaultQueueDir
+	}
+	if cfg.QueueMaxBytes == 0 {
+		cfg.QueueMaxBytes = DefaultQueueMaxBytes
+	}
+	if cfg.EvictionPolicy == "" {
+		cfg.EvictionPolicy = EvictionPolicyDropOldest
+	}
+	if cfg.RequireAck && cfg.AckTimeout.Duration == 0 {
+		cfg.AckTimeout.Duration = DefaultAckTimeout
+	}
+}
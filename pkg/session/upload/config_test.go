@@ -0,0 +1,65 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	require.NoError(t, nilCfg.Validate())
+	require.NoError(t, (&Config{}).Validate())
+	require.NoError(t, (&Config{
+		Interval:       metav1.Duration{Duration: 5 * time.Minute},
+		MaxBatchSize:   100,
+		Compression:    CompressionZstd,
+		QueueMaxBytes:  1024,
+		EvictionPolicy: EvictionPolicyDropNewest,
+	}).Validate())
+
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{"interval too short", &Config{Interval: metav1.Duration{Duration: time.Second}}},
+		{"negative interval", &Config{Interval: metav1.Duration{Duration: -time.Minute}}},
+		{"negative batch size", &Config{MaxBatchSize: -1}},
+		{"negative queue size", &Config{QueueMaxBytes: -1}},
+		{"negative ack timeout", &Config{RequireAck: true, AckTimeout: metav1.Duration{Duration: -time.Minute}}},
+		{"invalid compression", &Config{Compression: "brotli"}},
+		{"invalid eviction policy", &Config{EvictionPolicy: "random"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.cfg.Validate())
+		})
+	}
+}
+
+func TestConfigSetDefaults(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetDefaults("/var/lib/gpud/session-upload-queue")
+	assert.Equal(t, Config{
+		Interval:       metav1.Duration{Duration: DefaultInterval},
+		MaxBatchSize:   DefaultMaxBatchSize,
+		Compression:    CompressionGzip,
+		QueueDir:       "/var/lib/gpud/session-upload-queue",
+		QueueMaxBytes:  DefaultQueueMaxBytes,
+		EvictionPolicy: EvictionPolicyDropOldest,
+	}, *cfg)
+
+	cfg = &Config{Compression: CompressionNone, QueueDir: "/tmp/q", MaxBatchSize: 10}
+	cfg.SetDefaults("/var/lib/gpud/session-upload-queue")
+	assert.Equal(t, CompressionNone, cfg.Compression)
+	assert.Equal(t, "/tmp/q", cfg.QueueDir)
+	assert.Equal(t, 10, cfg.MaxBatchSize)
+	assert.Zero(t, cfg.AckTimeout.Duration)
+
+	cfg = &Config{RequireAck: true}
+	cfg.SetDefaults("/var/lib/gpud/session-upload-queue")
+	assert.Equal(t, DefaultAckTimeout, cfg.AckTimeout.Duration)
+}
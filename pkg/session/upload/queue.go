@@ -0,0 +1,222 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const batchFileExt = ".batch"
+
+var (
+	// ErrQueueFull is returned when the queue is full
+	// and the eviction policy rejects the new batch.
+	ErrQueueFull = errors.New("upload queue is full")
+	// ErrBatchTooLarge is returned when a single batch exceeds the queue size limit.
+	ErrBatchTooLarge = errors.New("upload batch exceeds the queue size limit")
+)
+
+// Batch is a batch persisted in the queue.
+type Batch struct {
+	// ID is the unique ID of the batch, sortable by the enqueue order.
+	ID   string
+	Data []byte
+}
+
+// Queue is a FIFO queue of the upload batches persisted on disk,
+// one file per batch, so that the batches survive the control plane
+// outages and the GPUd restarts.
+type Queue struct {
+	dir      string
+	maxBytes int64
+	policy   EvictionPolicy
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// OpenQueue opens the queue in the directory, creating it if not exists.
+func OpenQueue(dir string, maxBytes int64, policy EvictionPolicy) (*Queue, error) {
+	if dir == "" {
+		return nil, errors.New("queue directory is required")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultQueueMaxBytes
+	}
+	if policy == "" {
+		policy = EvictionPolicyDropOldest
+	}
+	return &Queue{
+		dir:      dir,
+		maxBytes: maxBytes,
+		policy:   policy,
+	}, nil
+}
+
+type queuedFile struct {
+	id   string
+	size int64
+}
+
+// list returns the queued batch files, oldest first.
+func (q *Queue) list() ([]queuedFile, int64, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var files []queuedFile
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), batchFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// removed in the meantime
+			continue
+		}
+		files = append(files, queuedFile{id: strings.TrimSuffix(e.Name(), batchFileExt), size: info.Size()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].id < files[j].id })
+	return files, total, nil
+}
+
+// Push persists the batch at the end of the queue.
+// It evicts the oldest batches or returns [ErrQueueFull]
+// if the queue would exceed its size limit, depending on the eviction policy.
+// It returns the number of the evicted batches.
+func (q *Queue) Push(data []byte) (int, error) {
+	size := int64(len(data))
+	if size > q.maxBytes {
+		return 0, fmt.Errorf("%w (%d > %d bytes)", ErrBatchTooLarge, size, q.maxBytes)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, total, err := q.list()
+	if err != nil {
+		return 0, err
+	}
+
+	evicted := 0
+	if total+size > q.maxBytes {
+		if q.policy == EvictionPolicyDropNewest {
+			return 0, ErrQueueFull
+		}
+		for _, f := range files {
+			if total+size <= q.maxBytes {
+				break
+			}
+			if err := os.Remove(q.path(f.id)); err != nil && !os.IsNotExist(err) {
+				return evicted, err
+			}
+			total -= f.size
+			evicted++
+		}
+	}
+
+	q.seq++
+	id := fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), q.seq%1000000)
+
+	// write to a temporary file first, so a partially written batch is never read
+	tmp := q.path(id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return evicted, err
+	}
+	if err := os.Rename(tmp, q.path(id)); err != nil {
+		_ = os.Remove(tmp)
+		return evicted, err
+	}
+	return evicted, nil
+}
+
+// Peek returns the oldest batch without removing it.
+// It returns false if the queue is empty.
+func (q *Queue) Peek() (Batch, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, _, err := q.list()
+	if err != nil {
+		return Batch{}, false, err
+	}
+	if len(files) == 0 {
+		return Batch{}, false, nil
+	}
+
+	data, err := os.ReadFile(q.path(files[0].id))
+	if err != nil {
+		return Batch{}, false, err
+	}
+	return Batch{ID: files[0].id, Data: data}, true, nil
+}
+
+// List returns the IDs of the queued batches, oldest first.
+func (q *Queue) List() ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, _, err := q.list()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.id)
+	}
+	return ids, nil
+}
+
+// Get returns the batch by its ID.
+// It returns false if the batch is no longer queued (e.g., evicted).
+func (q *Queue) Get(id string) (Batch, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data, err := os.ReadFile(q.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Batch{}, false, nil
+		}
+		return Batch{}, false, err
+	}
+	return Batch{ID: id, Data: data}, true, nil
+}
+
+// Remove removes the batch from the queue.
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.Remove(q.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Stats returns the number of the queued batches and their total size in bytes.
+func (q *Queue) Stats() (int, int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, total, err := q.list()
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(files), total, nil
+}
+
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.dir, id+batchFileExt)
+}
@@ -0,0 +1,128 @@
+package upload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queue")
+	q, err := OpenQueue(dir, 1024, EvictionPolicyDropOldest)
+	require.NoError(t, err)
+
+	_, ok, err := q.Peek()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, d := range []string{"a", "b", "c"} {
+		evicted, err := q.Push([]byte(d))
+		require.NoError(t, err)
+		assert.Zero(t, evicted)
+	}
+	n, size, err := q.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, int64(3), size)
+
+	ids, err := q.List()
+	require.NoError(t, err)
+	require.Len(t, ids, 3)
+	b, ok, err := q.Get(ids[1])
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "b", string(b.Data))
+	_, ok, err = q.Get("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// FIFO order
+	for _, want := range []string{"a", "b", "c"} {
+		b, ok, err := q.Peek()
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, want, string(b.Data))
+		require.NoError(t, q.Remove(b.ID))
+	}
+	_, ok, err = q.Peek()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// removing a missing batch is not an error
+	require.NoError(t, q.Remove("missing"))
+}
+
+func TestQueuePersisted(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenQueue(dir, 1024, EvictionPolicyDropOldest)
+	require.NoError(t, err)
+	_, err = q.Push([]byte("persisted"))
+	require.NoError(t, err)
+
+	// partially written batches are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0-0.batch.tmp"), []byte("partial"), 0600))
+
+	reopened, err := OpenQueue(dir, 1024, EvictionPolicyDropOldest)
+	require.NoError(t, err)
+	b, ok, err := reopened.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "persisted", string(b.Data))
+
+	n, _, err := reopened.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestQueueEvictDropOldest(t *testing.T) {
+	q, err := OpenQueue(t.TempDir(), 10, EvictionPolicyDropOldest)
+	require.NoError(t, err)
+
+	_, err = q.Push([]byte("aaaa"))
+	require.NoError(t, err)
+	_, err = q.Push([]byte("bbbb"))
+	require.NoError(t, err)
+
+	evicted, err := q.Push([]byte("cccc"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+
+	b, ok, err := q.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "bbbb", string(b.Data))
+
+	n, size, err := q.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int64(8), size)
+
+	_, err = q.Push([]byte("this batch is too large"))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+func TestQueueEvictDropNewest(t *testing.T) {
+	q, err := OpenQueue(t.TempDir(), 10, EvictionPolicyDropNewest)
+	require.NoError(t, err)
+
+	_, err = q.Push([]byte("aaaa"))
+	require.NoError(t, err)
+	_, err = q.Push([]byte("bbbb"))
+	require.NoError(t, err)
+
+	_, err = q.Push([]byte("cccc"))
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	b, ok, err := q.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "aaaa", string(b.Data))
+}
+
+func TestOpenQueueNoDir(t *testing.T) {
+	_, err := OpenQueue("", 10, EvictionPolicyDropOldest)
+	require.Error(t, err)
+}
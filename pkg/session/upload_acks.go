@@ -0,0 +1,153 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/leptonai/gpud/pkg/log"
+)
+
+// ErrUploadNotEnabled is returned when the session upload is not enabled.
+var ErrUploadNotEnabled = errors.New("session upload not enabled")
+
+// SyncStatus is the local view of the metrics and events batches
+// not yet delivered to (or acknowledged by) the control plane.
+type SyncStatus struct {
+	// RequireAck is true if the sent batches are kept queued until acknowledged.
+	RequireAck bool `json:"require_ack"`
+
+	// QueuedBatches is the number of the queued batches,
+	// not yet sent or (if RequireAck) sent but not yet acknowledged.
+	QueuedBatches int `json:"queued_batches"`
+	// QueuedBytes is the total size of the queued batches in bytes.
+	QueuedBytes int64 `json:"queued_bytes"`
+
+	// UnackedBatches is the number of the batches sent but not yet acknowledged.
+	UnackedBatches int `json:"unacked_batches"`
+	// OldestUnackedAt is when the oldest unacknowledged batch was first sent.
+	OldestUnackedAt *time.Time `json:"oldest_unacked_at,omitempty"`
+	// LastAckAt is when the control plane last acknowledged the batches.
+	LastAckAt *time.Time `json:"last_ack_at,omitempty"`
+	// Redeliveries is the number of the batches sent again since the start,
+	// on the reconnects or the ack timeouts.
+	Redeliveries int64 `json:"redeliveries"`
+}
+
+// SyncStatus returns the upload backlog of the session,
+// or ErrUploadNotEnabled if the session upload is not enabled.
+func (s *Session) SyncStatus() (SyncStatus, error) {
+	if s == nil || s.uploadConfig == nil || s.uploadQueue == nil {
+		return SyncStatus{}, ErrUploadNotEnabled
+	}
+
+	n, size, err := s.uploadQueue.Stats()
+	if err != nil {
+		return SyncStatus{}, err
+	}
+	st := SyncStatus{
+		RequireAck:    s.uploadAcks != nil,
+		QueuedBatches: n,
+		QueuedBytes:   size,
+	}
+	if s.uploadAcks == nil {
+		return st, nil
+	}
+
+	acks := s.uploadAcks.Stats()
+	st.UnackedBatches = acks.Unacked
+	st.Redeliveries = acks.Redeliveries
+	if !acks.OldestUnackedAt.IsZero() {
+		st.OldestUnackedAt = &acks.OldestUnackedAt
+	}
+	if !acks.LastAckAt.IsZero() {
+		st.LastAckAt = &acks.LastAckAt
+	}
+	return st, nil
+}
+
+// flushUploadQueueAcked sends the queued batches not pending the acknowledgements,
+// oldest first, keeping the sent batches queued until acknowledged.
+// It stops when the session writer is busy, leaving the rest for the next upload.
+func (s *Session) flushUploadQueueAcked() {
+	ids, err := s.uploadQueue.List()
+	if err != nil {
+		log.Logger.Errorw("session upload: failed to read queue", "error", err)
+		return
+	}
+	queued := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		queued[id] = struct{}{}
+	}
+	s.uploadAcks.Retain(queued)
+
+	for _, id := range ids {
+		now := time.Now().UTC()
+		if s.uploadAcks.Pending(id, now) {
+			continue
+		}
+
+		batch, ok, err := s.uploadQueue.Get(id)
+		if err != nil {
+			log.Logger.Errorw("session upload: failed to read queue", "batchID", id, "error", err)
+			return
+		}
+		if !ok {
+			// evicted in the meantime
+			continue
+		}
+
+		var body Body
+		if err := json.Unmarshal(batch.Data, &body); err != nil {
+			log.Logger.Errorw("session upload: dropping corrupted batch", "batchID", batch.ID, "error", err)
+			if err := s.uploadQueue.Remove(batch.ID); err != nil {
+				log.Logger.Errorw("session upload: failed to remove batch", "batchID", batch.ID, "error", err)
+				return
+			}
+			continue
+		}
+
+		if !s.trySendUpload(body) {
+			return
+		}
+		s.uploadAcks.MarkSent(batch.ID, body.UploadID, now)
+	}
+}
+
+// redeliverUploads sends all the unacknowledged batches again,
+// as the batches written to the previous connection may be lost.
+func (s *Session) redeliverUploads() {
+	if n := s.uploadAcks.Reset(); n > 0 {
+		log.Logger.Infow("session upload: redelivering unacknowledged batches", "batches", n)
+	}
+	s.flushUploadQueue()
+}
+
+// processAckUploads handles the ackUploads request,
+// removing the acknowledged batches from the upload queue.
+func (s *Session) processAckUploads(payload Request, response *Response) {
+	if s.uploadAcks == nil {
+		response.Error = "session upload acknowledgements not enabled"
+		response.ErrorCode = http.StatusNotFound
+		return
+	}
+
+	now := time.Now().UTC()
+	acked := 0
+	for _, uploadID := range payload.UploadIDs {
+		batchID, ok := s.uploadAcks.Ack(uploadID, now)
+		if !ok {
+			// e.g., acknowledged twice, or sent before the restart and not yet sent again
+			log.Logger.Debugw("session upload: ignoring acknowledgement of unknown upload", "uploadID", uploadID)
+			continue
+		}
+		if err := s.uploadQueue.Remove(batchID); err != nil {
+			// the batch is redelivered on the next flush
+			log.Logger.Errorw("session upload: failed to remove acknowledged batch", "batchID", batchID, "error", err)
+			continue
+		}
+		acked++
+	}
+	log.Logger.Debugw("session upload: acknowledged batches", "acked", acked, "uploadIDs", len(payload.UploadIDs))
+}
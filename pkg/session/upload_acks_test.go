@@ -0,0 +1,105 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leptonai/gpud/pkg/session/upload"
+)
+
+func createMockAckedUploadSession(t *testing.T) *Session {
+	s := createMockUploadSession(t, new(mockComponentRegistry), upload.Config{RequireAck: true})
+	s.uploadAcks = upload.NewAcks(s.uploadConfig.AckTimeout.Duration)
+	return s
+}
+
+func pushUploadBody(t *testing.T, s *Session, uploadID string) {
+	raw, err := json.Marshal(Body{UploadID: uploadID, Data: []byte("data")})
+	require.NoError(t, err)
+	_, err = s.uploadQueue.Push(raw)
+	require.NoError(t, err)
+}
+
+func TestSession_flushUploadQueueAcked(t *testing.T) {
+	s := createMockAckedUploadSession(t)
+	pushUploadBody(t, s, "u1")
+	pushUploadBody(t, s, "u2")
+
+	s.flushUploadQueue()
+	require.Len(t, s.writer, 2)
+	assert.Equal(t, "u1", (<-s.writer).UploadID)
+	assert.Equal(t, "u2", (<-s.writer).UploadID)
+
+	// kept queued until acknowledged, and not sent again within the ack timeout
+	st, err := s.SyncStatus()
+	require.NoError(t, err)
+	assert.True(t, st.RequireAck)
+	assert.Equal(t, 2, st.QueuedBatches)
+	assert.Equal(t, 2, st.UnackedBatches)
+	require.NotNil(t, st.OldestUnackedAt)
+	assert.Nil(t, st.LastAckAt)
+	s.flushUploadQueue()
+	assert.Empty(t, s.writer)
+
+	response := &Response{}
+	s.processAckUploads(Request{UploadIDs: []string{"u1", "unknown"}}, response)
+	assert.Empty(t, response.Error)
+
+	st, err = s.SyncStatus()
+	require.NoError(t, err)
+	assert.Equal(t, 1, st.QueuedBatches)
+	assert.Equal(t, 1, st.UnackedBatches)
+	require.NotNil(t, st.LastAckAt)
+
+	// redelivered on the reconnect
+	s.redeliverUploads()
+	require.Len(t, s.writer, 1)
+	assert.Equal(t, "u2", (<-s.writer).UploadID)
+	st, err = s.SyncStatus()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), st.Redeliveries)
+
+	s.processAckUploads(Request{UploadIDs: []string{"u2"}}, response)
+	st, err = s.SyncStatus()
+	require.NoError(t, err)
+	assert.Zero(t, st.QueuedBatches)
+	assert.Zero(t, st.UnackedBatches)
+}
+
+func TestSession_flushUploadQueueAckedWriterBusy(t *testing.T) {
+	s := createMockAckedUploadSession(t)
+	pushUploadBody(t, s, "u1")
+
+	for i := 0; i < cap(s.writer)/2; i++ {
+		s.writer <- Body{ReqID: "response"}
+	}
+	s.flushUploadQueue()
+	assert.Len(t, s.writer, cap(s.writer)/2)
+
+	st, err := s.SyncStatus()
+	require.NoError(t, err)
+	assert.Equal(t, 1, st.QueuedBatches)
+	assert.Zero(t, st.UnackedBatches)
+}
+
+func TestSession_processAckUploadsNotEnabled(t *testing.T) {
+	s := createMockUploadSession(t, new(mockComponentRegistry), upload.Config{})
+
+	response := &Response{}
+	s.processAckUploads(Request{UploadIDs: []string{"u1"}}, response)
+	assert.Equal(t, int32(http.StatusNotFound), response.ErrorCode)
+
+	st, err := s.SyncStatus()
+	require.NoError(t, err)
+	assert.False(t, st.RequireAck)
+
+	var nilSession *Session
+	_, err = nilSession.SyncStatus()
+	assert.ErrorIs(t, err, ErrUploadNotEnabled)
+	_, err = createMockSession(new(mockComponentRegistry)).SyncStatus()
+	assert.ErrorIs(t, err, ErrUploadNotEnabled)
+}
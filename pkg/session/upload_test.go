@@ -0,0 +1,310 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/budget"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+	"github.com/leptonai/gpud/pkg/session/upload"
+)
+
+func createMockUploadSession(t *testing.T, registry *mockComponentRegistry, cfg upload.Config) *Session {
+	cfg.SetDefaults(t.TempDir())
+	queue, err := upload.OpenQueue(cfg.QueueDir, cfg.QueueMaxBytes, cfg.EvictionPolicy)
+	require.NoError(t, err)
+
+	s := createMockSession(registry)
+	s.machineID = "machine-1"
+	s.uploadConfig = &cfg
+	s.uploadQueue = queue
+	return s
+}
+
+func decodeUploadBody(t *testing.T, body Body) Response {
+	data, err := upload.Decompress(upload.Compression(body.Encoding), body.Data)
+	require.NoError(t, err)
+	var resp Response
+	require.NoError(t, json.Unmarshal(data, &resp))
+	return resp
+}
+
+func TestSession_uploadOnce(t *testing.T) {
+	since := time.Unix(1000, 0).UTC()
+	until := since.Add(time.Minute)
+
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{MaxBatchSize: 2, Compression: upload.CompressionZstd})
+	s.metricsStore = metricsStore
+	s.components = []string{"comp1"}
+	s.uploadCursor = since
+
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{
+		// already uploaded
+		{Name: "m0", UnixMilliseconds: since.UnixMilli(), Component: "comp1"},
+		{Name: "m1", UnixMilliseconds: since.Add(time.Second).UnixMilli(), Component: "comp1"},
+		{Name: "m2", UnixMilliseconds: since.Add(2 * time.Second).UnixMilli(), Component: "comp2"},
+		// recorded after the upload window
+		{Name: "m3", UnixMilliseconds: until.Add(time.Second).UnixMilli(), Component: "comp1"},
+	}, nil)
+
+	comp1 := new(mockComponent)
+	comp1.On("Events", mock.Anything, since).Return(apiv1.Events{
+		{Name: "ev-old", Time: metav1.NewTime(since)},
+		{Name: "ev1", Time: metav1.NewTime(since.Add(3 * time.Second))},
+	}, nil)
+	registry.On("Get", "comp1").Return(comp1)
+
+	s.uploadOnce(context.Background(), until)
+	assert.Equal(t, until, s.uploadCursor)
+
+	// 3 items in the batches of 2
+	require.Len(t, s.writer, 2)
+	first := <-s.writer
+	assert.Equal(t, "zstd", first.Encoding)
+	assert.Equal(t, "machine-1-1000000-1060000-0", first.UploadID)
+	assert.Empty(t, first.ReqID)
+
+	resp := decodeUploadBody(t, first)
+	require.Len(t, resp.Metrics, 2)
+	assert.Equal(t, "comp1", resp.Metrics[0].Component)
+	assert.Equal(t, "m1", resp.Metrics[0].Metrics[0].Name)
+	assert.Equal(t, "comp2", resp.Metrics[1].Component)
+	assert.Empty(t, resp.Events)
+
+	resp = decodeUploadBody(t, <-s.writer)
+	assert.Empty(t, resp.Metrics)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "comp1", resp.Events[0].Component)
+	require.Len(t, resp.Events[0].Events, 1)
+	assert.Equal(t, "ev1", resp.Events[0].Events[0].Name)
+
+	// all sent batches are removed from the queue
+	n, _, err := s.uploadQueue.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestSession_uploadOnceOverBudget(t *testing.T) {
+	since := time.Unix(1000, 0).UTC()
+	until := since.Add(time.Minute)
+
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{MaxBatchSize: 10, Compression: upload.CompressionZstd})
+	s.metricsStore = metricsStore
+	s.components = []string{"comp1"}
+	s.uploadCursor = since
+	// too small for any batch with the metrics
+	s.dataBudget = budget.New(&budget.Config{UploadBytesPerHour: 1})
+
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{
+		{Name: "m1", UnixMilliseconds: since.Add(time.Second).UnixMilli(), Component: "comp1"},
+	}, nil)
+
+	comp1 := new(mockComponent)
+	comp1.On("Events", mock.Anything, since).Return(apiv1.Events{
+		{Name: "ev-info", Type: apiv1.EventTypeInfo, Time: metav1.NewTime(since.Add(time.Second))},
+		{Name: "ev-fatal", Type: apiv1.EventTypeFatal, Time: metav1.NewTime(since.Add(2 * time.Second))},
+	}, nil)
+	registry.On("Get", "comp1").Return(comp1)
+
+	s.uploadOnce(context.Background(), until)
+
+	// only the fatal event is uploaded over the budget
+	require.Len(t, s.writer, 1)
+	resp := decodeUploadBody(t, <-s.writer)
+	assert.Empty(t, resp.Metrics)
+	require.Len(t, resp.Events, 1)
+	require.Len(t, resp.Events[0].Events, 1)
+	assert.Equal(t, "ev-fatal", resp.Events[0].Events[0].Name)
+
+	// batches without the critical and fatal events are sampled away
+	comp1.ExpectedCalls = nil
+	comp1.On("Events", mock.Anything, until).Return(apiv1.Events{
+		{Name: "ev-info", Type: apiv1.EventTypeInfo, Time: metav1.NewTime(until.Add(time.Second))},
+	}, nil)
+	s.uploadOnce(context.Background(), until.Add(time.Minute))
+	assert.Empty(t, s.writer)
+}
+
+func TestSession_uploadOnceWriterBusy(t *testing.T) {
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{})
+	s.metricsStore = metricsStore
+	s.components = nil
+
+	now := time.Now().UTC()
+	s.uploadCursor = now.Add(-time.Minute)
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{
+		{Name: "m1", UnixMilliseconds: now.Add(-time.Second).UnixMilli(), Component: "comp1"},
+	}, nil)
+
+	// fill the half of the writer buffer reserved for the responses
+	for i := 0; i < cap(s.writer)/2; i++ {
+		s.writer <- Body{ReqID: "response"}
+	}
+
+	s.uploadOnce(context.Background(), now)
+	assert.Len(t, s.writer, cap(s.writer)/2)
+
+	n, _, err := s.uploadQueue.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// the queued batch is sent once the writer drains
+	for len(s.writer) > 0 {
+		<-s.writer
+	}
+	s.flushUploadQueue()
+	require.Len(t, s.writer, 1)
+	body := <-s.writer
+	assert.Equal(t, "gzip", body.Encoding)
+	resp := decodeUploadBody(t, body)
+	require.Len(t, resp.Metrics, 1)
+	assert.Equal(t, "m1", resp.Metrics[0].Metrics[0].Name)
+
+	n, _, err = s.uploadQueue.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestSession_uploadOnceQueueFull(t *testing.T) {
+	since := time.Unix(1000, 0).UTC()
+	until := since.Add(time.Minute)
+
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{QueueMaxBytes: 4096, EvictionPolicy: upload.EvictionPolicyDropNewest})
+	s.metricsStore = metricsStore
+	s.components = nil
+	s.uploadCursor = since
+
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{
+		{Name: "m1", UnixMilliseconds: since.Add(time.Second).UnixMilli(), Component: "comp1"},
+	}, nil)
+
+	// fill the queue for the new batch to be rejected
+	queued, err := json.Marshal(Body{UploadID: "queued", Data: make([]byte, 2900)})
+	require.NoError(t, err)
+	_, err = s.uploadQueue.Push(queued)
+	require.NoError(t, err)
+
+	// the cursor does not advance, and only the queued batch is sent
+	s.uploadOnce(context.Background(), until)
+	assert.Equal(t, since, s.uploadCursor)
+	require.Len(t, s.writer, 1)
+	assert.Equal(t, "queued", (<-s.writer).UploadID)
+
+	// the rejected batch is collected again with the next upload,
+	// from the same window with the same upload ID
+	s.uploadOnce(context.Background(), until.Add(time.Minute))
+	assert.Equal(t, until, s.uploadCursor)
+	require.Len(t, s.writer, 1)
+	body := <-s.writer
+	assert.Equal(t, "machine-1-1000000-1060000-0", body.UploadID)
+	resp := decodeUploadBody(t, body)
+	require.Len(t, resp.Metrics, 1)
+	assert.Equal(t, "m1", resp.Metrics[0].Metrics[0].Name)
+
+	// the next upload continues from the window
+	s.uploadOnce(context.Background(), until.Add(time.Minute))
+	assert.Equal(t, until.Add(time.Minute), s.uploadCursor)
+	assert.Empty(t, s.writer)
+}
+
+func TestSession_uploadOnceWindowCapped(t *testing.T) {
+	since := time.Unix(1000, 0).UTC()
+
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{})
+	s.metricsStore = metricsStore
+	s.components = nil
+	s.uploadCursor = since
+
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{
+		{Name: "m1", UnixMilliseconds: since.Add(time.Minute).UnixMilli(), Component: "comp1"},
+		{Name: "m2", UnixMilliseconds: since.Add(time.Hour).UnixMilli(), Component: "comp1"},
+	}, nil)
+
+	// at most 10 upload intervals (1 minute by default) per upload
+	s.uploadOnce(context.Background(), since.Add(time.Hour))
+	assert.Equal(t, since.Add(10*time.Minute), s.uploadCursor)
+	require.Len(t, s.writer, 1)
+	resp := decodeUploadBody(t, <-s.writer)
+	require.Len(t, resp.Metrics, 1)
+	require.Len(t, resp.Metrics[0].Metrics, 1)
+	assert.Equal(t, "m1", resp.Metrics[0].Metrics[0].Name)
+}
+
+func TestUploadBatchID(t *testing.T) {
+	since := time.Unix(1000, 0).UTC()
+	until := since.Add(time.Minute)
+	assert.Equal(t, "machine-1-1000000-1060000-2", uploadBatchID("machine-1", since, until, 2))
+	assert.Equal(t, uploadBatchID("machine-1", since, until, 0), uploadBatchID("machine-1", since, until.In(time.Local), 0))
+	assert.NotEqual(t, uploadBatchID("machine-1", since, until, 0), uploadBatchID("machine-1", since, until.Add(time.Minute), 0))
+}
+
+func TestSession_uploadOnceReadErrors(t *testing.T) {
+	registry := new(mockComponentRegistry)
+	metricsStore := new(mockMetricsStore)
+	s := createMockUploadSession(t, registry, upload.Config{})
+	s.metricsStore = metricsStore
+	s.components = []string{"comp1", "missing"}
+
+	metricsStore.On("Read", mock.Anything, mock.Anything).Return(pkgmetrics.Metrics{}, errors.New("read error"))
+	comp1 := new(mockComponent)
+	comp1.On("Events", mock.Anything, mock.Anything).Return(apiv1.Events{}, errors.New("events error"))
+	registry.On("Get", "comp1").Return(comp1)
+	registry.On("Get", "missing").Return(nil)
+
+	s.uploadOnce(context.Background(), time.Now().UTC())
+	assert.Empty(t, s.writer)
+}
+
+func TestSession_flushUploadQueueCorrupted(t *testing.T) {
+	s := createMockUploadSession(t, new(mockComponentRegistry), upload.Config{})
+
+	_, err := s.uploadQueue.Push([]byte("not json"))
+	require.NoError(t, err)
+	raw, err := json.Marshal(Body{UploadID: "u1", Data: []byte("data")})
+	require.NoError(t, err)
+	_, err = s.uploadQueue.Push(raw)
+	require.NoError(t, err)
+
+	s.flushUploadQueue()
+	require.Len(t, s.writer, 1)
+	assert.Equal(t, "u1", (<-s.writer).UploadID)
+}
+
+func TestUploadBatcher(t *testing.T) {
+	b := newUploadBatcher(0)
+	assert.Empty(t, b.finish())
+
+	b = newUploadBatcher(3)
+	for i := 0; i < 4; i++ {
+		b.addMetric("comp1", apiv1.Metric{Name: "m"})
+	}
+	b.addEvent("comp2", time.Time{}, time.Time{}, apiv1.Event{Name: "ev"})
+
+	batches := b.finish()
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0].Metrics, 1)
+	assert.Len(t, batches[0].Metrics[0].Metrics, 3)
+	require.Len(t, batches[1].Metrics, 1)
+	assert.Len(t, batches[1].Metrics[0].Metrics, 1)
+	require.Len(t, batches[1].Events, 1)
+	assert.Equal(t, "comp2", batches[1].Events[0].Component)
+}
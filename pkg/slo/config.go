@@ -0,0 +1,127 @@
+// Package slo tracks the latency objectives of the component checks and the API
+// served by GPUd over the rolling windows (e.g., 99% of the NVIDIA component checks
+// complete within 2 seconds), with the violations recorded as the events.
+package slo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+const (
+	// DefaultWindow is the default rolling window of the attainment.
+	DefaultWindow = time.Hour
+	// DefaultMinSamples is the default minimum number of the observations
+	// within the window to evaluate the violation, to not alert on a few slow ones.
+	DefaultMinSamples = 10
+)
+
+// Config configures the latency objectives.
+type Config struct {
+	Objectives []Objective `json:"objectives"`
+}
+
+// Objective is a latency objective, where the ratio of the observations
+// under the threshold should be at least the target within the window.
+type Objective struct {
+	// Name is the unique name of the objective (e.g., "nvidia-checks").
+	Name string `json:"name"`
+	// Kind is either "component_check" or "api".
+	Kind apiv1.SLOKind `json:"kind"`
+	// Match is the glob of the component names (e.g., "accelerator-nvidia-*")
+	// for the component checks, or the path prefix (e.g., "/v1/states")
+	// for the API. Empty to match all.
+	Match string `json:"match,omitempty"`
+
+	// Threshold is the latency under which an observation is good (e.g., "2s").
+	Threshold metav1.Duration `json:"threshold"`
+	// Target is the objective ratio of the good observations, between 0 and 1 (e.g., 0.99).
+	Target float64 `json:"target"`
+	// Window is the rolling window of the attainment (defaults to 1 hour).
+	Window metav1.Duration `json:"window,omitempty"`
+	// MinSamples is the minimum number of the observations within the window
+	// to evaluate the violation (defaults to 10).
+	MinSamples int `json:"min_samples,omitempty"`
+}
+
+// Validate validates the latency objectives.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(cfg.Objectives))
+	for _, o := range cfg.Objectives {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("invalid objective %q: %w", o.Name, err)
+		}
+		if _, ok := names[o.Name]; ok {
+			return fmt.Errorf("duplicate objective %q", o.Name)
+		}
+		names[o.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Validate validates the latency objective.
+func (o Objective) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch o.Kind {
+	case apiv1.SLOKindComponentCheck:
+		if _, err := path.Match(o.Match, ""); err != nil {
+			return fmt.Errorf("invalid match %q: %w", o.Match, err)
+		}
+	case apiv1.SLOKindAPI:
+	default:
+		return fmt.Errorf("kind must be one of %q and %q, got %q", apiv1.SLOKindComponentCheck, apiv1.SLOKindAPI, o.Kind)
+	}
+	if o.Threshold.Duration <= 0 {
+		return fmt.Errorf("threshold must be positive, got %s", o.Threshold.Duration)
+	}
+	if o.Target <= 0 || o.Target >= 1 {
+		return fmt.Errorf("target must be between 0 and 1 (exclusive), got %v", o.Target)
+	}
+	if o.Window.Duration < 0 {
+		return fmt.Errorf("window must be non-negative, got %s", o.Window.Duration)
+	}
+	if o.MinSamples < 0 {
+		return fmt.Errorf("min_samples must be non-negative, got %d", o.MinSamples)
+	}
+	return nil
+}
+
+func (o Objective) window() time.Duration {
+	if o.Window.Duration == 0 {
+		return DefaultWindow
+	}
+	return o.Window.Duration
+}
+
+func (o Objective) minSamples() int64 {
+	if o.MinSamples == 0 {
+		return DefaultMinSamples
+	}
+	return int64(o.MinSamples)
+}
+
+// matches returns true if the objective tracks the component or the API path.
+func (o Objective) matches(kind apiv1.SLOKind, name string) bool {
+	if o.Kind != kind {
+		return false
+	}
+	if o.Match == "" {
+		return true
+	}
+	if kind == apiv1.SLOKindAPI {
+		return strings.HasPrefix(name, o.Match)
+	}
+	ok, _ := path.Match(o.Match, name)
+	return ok
+}
@@ -0,0 +1,64 @@
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var nilCfg *Config
+	assert.NoError(t, nilCfg.Validate())
+
+	valid := Objective{
+		Name:      "nvidia-checks",
+		Kind:      apiv1.SLOKindComponentCheck,
+		Match:     "accelerator-nvidia-*",
+		Threshold: metav1.Duration{Duration: 2 * time.Second},
+		Target:    0.99,
+	}
+	assert.NoError(t, (&Config{Objectives: []Objective{valid}}).Validate())
+	assert.Error(t, (&Config{Objectives: []Objective{valid, valid}}).Validate())
+
+	tests := []struct {
+		name   string
+		modify func(o *Objective)
+	}{
+		{name: "empty name", modify: func(o *Objective) { o.Name = "" }},
+		{name: "unknown kind", modify: func(o *Objective) { o.Kind = "unknown" }},
+		{name: "invalid glob", modify: func(o *Objective) { o.Match = "[" }},
+		{name: "zero threshold", modify: func(o *Objective) { o.Threshold = metav1.Duration{} }},
+		{name: "zero target", modify: func(o *Objective) { o.Target = 0 }},
+		{name: "target of one", modify: func(o *Objective) { o.Target = 1 }},
+		{name: "negative window", modify: func(o *Objective) { o.Window = metav1.Duration{Duration: -time.Minute} }},
+		{name: "negative min samples", modify: func(o *Objective) { o.MinSamples = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid
+			tt.modify(&o)
+			assert.Error(t, o.Validate())
+		})
+	}
+}
+
+func TestObjectiveMatches(t *testing.T) {
+	check := Objective{Kind: apiv1.SLOKindComponentCheck, Match: "accelerator-nvidia-*"}
+	assert.True(t, check.matches(apiv1.SLOKindComponentCheck, "accelerator-nvidia-xid"))
+	assert.False(t, check.matches(apiv1.SLOKindComponentCheck, "cpu"))
+	assert.False(t, check.matches(apiv1.SLOKindAPI, "accelerator-nvidia-xid"))
+
+	api := Objective{Kind: apiv1.SLOKindAPI, Match: "/v1/"}
+	assert.True(t, api.matches(apiv1.SLOKindAPI, "/v1/states"))
+	assert.False(t, api.matches(apiv1.SLOKindAPI, "/metrics"))
+
+	all := Objective{Kind: apiv1.SLOKindAPI}
+	assert.True(t, all.matches(apiv1.SLOKindAPI, "/metrics"))
+
+	assert.Equal(t, DefaultWindow, all.window())
+	assert.Equal(t, int64(DefaultMinSamples), all.minSamples())
+}
@@ -0,0 +1,286 @@
+package slo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/log"
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	// BucketName is the event bucket of the objective violations and recoveries.
+	BucketName = "slo"
+
+	// EventNameViolated is the event name when an objective is violated.
+	EventNameViolated = "slo_violated"
+	// EventNameRecovered is the event name when a violated objective is attained again.
+	EventNameRecovered = "slo_recovered"
+
+	// EventKeyObjective is the event extra info key of the objective name.
+	EventKeyObjective = "objective"
+
+	// evaluateInterval is the interval to evaluate the violations in the background.
+	evaluateInterval = time.Minute
+
+	// numSlots is the number of the slots per window, so that the window
+	// rolls in the steps of 1/60 of the window (e.g., every minute of 1 hour).
+	numSlots = 60
+)
+
+var (
+	metricAttainment = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "gpud",
+			Subsystem: "slo",
+			Name:      "attainment_ratio",
+			Help:      "ratio of the observations under the threshold of the latency objective within its window",
+		},
+		[]string{"objective"},
+	)
+	metricViolated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "gpud",
+			Subsystem: "slo",
+			Name:      "violated",
+			Help:      "set to 1 if the latency objective is violated within its window",
+		},
+		[]string{"objective"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricAttainment,
+		metricViolated,
+	)
+}
+
+// Tracker tracks the attainment of the latency objectives.
+// Safe for concurrent use.
+type Tracker struct {
+	eventBucket    eventstore.Bucket
+	getTimeNowFunc func() time.Time
+
+	mu     sync.Mutex
+	states []*objectiveState
+}
+
+// objectiveState counts the observations in the slots rolling over the window.
+type objectiveState struct {
+	objective Objective
+	slotDur   time.Duration
+	slots     [numSlots]slot
+
+	// violatedSince is when the objective was last violated, zero if not violated
+	violatedSince time.Time
+}
+
+type slot struct {
+	start time.Time
+	total int64
+	good  int64
+}
+
+// New creates the tracker of the latency objectives,
+// with the violations recorded in the event bucket, if not nil.
+func New(cfg Config, eventBucket eventstore.Bucket) *Tracker {
+	t := &Tracker{
+		eventBucket: eventBucket,
+		getTimeNowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+	for _, o := range cfg.Objectives {
+		slotDur := o.window() / numSlots
+		if slotDur <= 0 {
+			slotDur = time.Nanosecond
+		}
+		t.states = append(t.states, &objectiveState{objective: o, slotDur: slotDur})
+	}
+	return t
+}
+
+// ObserveComponentCheck observes the duration of a component check.
+func (t *Tracker) ObserveComponentCheck(componentName string, elapsed time.Duration) {
+	t.observe(apiv1.SLOKindComponentCheck, componentName, elapsed)
+}
+
+// ObserveAPI observes the latency of an API request on the path.
+func (t *Tracker) ObserveAPI(path string, elapsed time.Duration) {
+	t.observe(apiv1.SLOKindAPI, path, elapsed)
+}
+
+func (t *Tracker) observe(kind apiv1.SLOKind, name string, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	now := t.getTimeNowFunc()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, st := range t.states {
+		if st.objective.matches(kind, name) {
+			st.observe(now, elapsed)
+		}
+	}
+}
+
+func (st *objectiveState) observe(now time.Time, elapsed time.Duration) {
+	start := now.Truncate(st.slotDur)
+	s := &st.slots[(start.UnixNano()/int64(st.slotDur))%numSlots]
+	if !s.start.Equal(start) {
+		*s = slot{start: start}
+	}
+	s.total++
+	if elapsed <= st.objective.Threshold.Duration {
+		s.good++
+	}
+}
+
+func (st *objectiveState) status(now time.Time) apiv1.SLOStatus {
+	o := st.objective
+	window := o.window()
+
+	var total, good int64
+	cutoff := now.Add(-window)
+	for _, s := range st.slots {
+		if s.start.After(cutoff) && !s.start.After(now) {
+			total += s.total
+			good += s.good
+		}
+	}
+
+	status := apiv1.SLOStatus{
+		Name:                 o.Name,
+		Kind:                 o.Kind,
+		Match:                o.Match,
+		Threshold:            o.Threshold,
+		Target:               o.Target,
+		Window:               metav1.Duration{Duration: window},
+		Total:                total,
+		Good:                 good,
+		Attainment:           1,
+		ErrorBudgetRemaining: 1,
+	}
+	if total > 0 {
+		status.Attainment = float64(good) / float64(total)
+		allowed := (1 - o.Target) * float64(total)
+		status.ErrorBudgetRemaining = 1 - float64(total-good)/allowed
+	}
+	status.Violated = total >= o.minSamples() && status.Attainment < o.Target
+	return status
+}
+
+// Start evaluates the violations of the objectives in the background.
+func (t *Tracker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(evaluateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			t.evaluate(ctx)
+		}
+	}()
+}
+
+// evaluate returns the statuses of the objectives, and records the events
+// on the objectives violated or recovered since the last evaluation.
+func (t *Tracker) evaluate(ctx context.Context) []apiv1.SLOStatus {
+	now := t.getTimeNowFunc()
+
+	var events []eventstore.Event
+	statuses := make([]apiv1.SLOStatus, 0, len(t.states))
+
+	t.mu.Lock()
+	for _, st := range t.states {
+		status := st.status(now)
+
+		switch {
+		case status.Violated && st.violatedSince.IsZero():
+			st.violatedSince = now
+			events = append(events, eventstore.Event{
+				Component: BucketName,
+				Time:      now,
+				Name:      EventNameViolated,
+				Type:      string(apiv1.EventTypeWarning),
+				Message: fmt.Sprintf("%s: %.2f%% of %d observation(s) within %s under %s, below %.2f%% target",
+					status.Name, status.Attainment*100, status.Total, status.Window.Duration, status.Threshold.Duration, status.Target*100),
+				ExtraInfo: map[string]string{EventKeyObjective: status.Name},
+			})
+
+		case !status.Violated && !st.violatedSince.IsZero():
+			events = append(events, eventstore.Event{
+				Component: BucketName,
+				Time:      now,
+				Name:      EventNameRecovered,
+				Type:      string(apiv1.EventTypeInfo),
+				Message:   fmt.Sprintf("%s: attained again after violated since %s", status.Name, st.violatedSince.Format(time.RFC3339)),
+				ExtraInfo: map[string]string{EventKeyObjective: status.Name},
+			})
+			st.violatedSince = time.Time{}
+		}
+		if !st.violatedSince.IsZero() {
+			since := st.violatedSince
+			status.ViolatedSince = &since
+		}
+
+		metricAttainment.With(prometheus.Labels{"objective": status.Name}).Set(status.Attainment)
+		violated := 0.0
+		if status.Violated {
+			violated = 1
+		}
+		metricViolated.With(prometheus.Labels{"objective": status.Name}).Set(violated)
+
+		statuses = append(statuses, status)
+	}
+	t.mu.Unlock()
+
+	for _, ev := range events {
+		log.Logger.Warnw(ev.Message, "event", ev.Name)
+		if t.eventBucket == nil {
+			continue
+		}
+		if err := t.eventBucket.Insert(ctx, ev); err != nil {
+			log.Logger.Warnw("failed to insert slo event", "event", ev.Name, "error", err)
+		}
+	}
+	return statuses
+}
+
+// Report evaluates the objectives, and returns their statuses
+// with the events within the longest window.
+func (t *Tracker) Report(ctx context.Context) apiv1.SLOReport {
+	statuses := t.evaluate(ctx)
+	report := apiv1.SLOReport{
+		Time:       t.getTimeNowFunc(),
+		Objectives: statuses,
+	}
+	if t.eventBucket == nil {
+		return report
+	}
+
+	var window time.Duration
+	for _, st := range t.states {
+		window = max(window, st.objective.window())
+	}
+	evs, err := t.eventBucket.Get(ctx, report.Time.Add(-window))
+	if err != nil {
+		log.Logger.Warnw("failed to get slo events", "error", err)
+		return report
+	}
+	report.Events = evs.Events()
+	return report
+}
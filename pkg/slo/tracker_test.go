@@ -0,0 +1,124 @@
+package slo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/leptonai/gpud/api/v1"
+	"github.com/leptonai/gpud/pkg/eventstore"
+	"github.com/leptonai/gpud/pkg/sqlite"
+)
+
+func TestTracker(t *testing.T) {
+	dbRW, dbRO, cleanup := sqlite.OpenTestDB(t)
+	defer cleanup()
+
+	store, err := eventstore.New(dbRW, dbRO, eventstore.DefaultRetention)
+	require.NoError(t, err)
+	bucket, err := store.Bucket(BucketName)
+	require.NoError(t, err)
+	defer bucket.Close()
+
+	cfg := Config{Objectives: []Objective{
+		{
+			Name:      "nvidia-checks",
+			Kind:      apiv1.SLOKindComponentCheck,
+			Match:     "accelerator-nvidia-*",
+			Threshold: metav1.Duration{Duration: 2 * time.Second},
+			Target:    0.9,
+			Window:    metav1.Duration{Duration: time.Hour},
+		},
+		{
+			Name:      "api",
+			Kind:      apiv1.SLOKindAPI,
+			Match:     "/v1/",
+			Threshold: metav1.Duration{Duration: 100 * time.Millisecond},
+			Target:    0.99,
+		},
+	}}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := New(cfg, bucket)
+	tr.getTimeNowFunc = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// 8 out of 10 checks under the threshold, below the 90% target
+	for i := 0; i < 10; i++ {
+		elapsed := time.Second
+		if i < 2 {
+			elapsed = 5 * time.Second
+		}
+		tr.ObserveComponentCheck("accelerator-nvidia-xid", elapsed)
+	}
+	tr.ObserveComponentCheck("cpu", time.Minute)
+	tr.ObserveAPI("/v1/states", 10*time.Millisecond)
+
+	report := tr.Report(ctx)
+	require.Len(t, report.Objectives, 2)
+
+	checks := report.Objectives[0]
+	assert.Equal(t, int64(10), checks.Total)
+	assert.Equal(t, int64(8), checks.Good)
+	assert.InDelta(t, 0.8, checks.Attainment, 1e-9)
+	assert.InDelta(t, -1, checks.ErrorBudgetRemaining, 1e-9)
+	assert.True(t, checks.Violated)
+	require.NotNil(t, checks.ViolatedSince)
+	assert.Equal(t, now, *checks.ViolatedSince)
+
+	// too few observations to be violated
+	api := report.Objectives[1]
+	assert.Equal(t, int64(1), api.Total)
+	assert.False(t, api.Violated)
+
+	require.Len(t, report.Events, 1)
+	assert.Equal(t, EventNameViolated, report.Events[0].Name)
+	assert.Equal(t, apiv1.EventTypeWarning, report.Events[0].Type)
+
+	// no duplicate event while still violated
+	report = tr.Report(ctx)
+	assert.Len(t, report.Events, 1)
+
+	// the slow checks roll out of the window
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 10; i++ {
+		tr.ObserveComponentCheck("accelerator-nvidia-xid", time.Second)
+	}
+	report = tr.Report(ctx)
+	checks = report.Objectives[0]
+	assert.Equal(t, int64(10), checks.Total)
+	assert.Equal(t, 1.0, checks.Attainment)
+	assert.False(t, checks.Violated)
+	assert.Nil(t, checks.ViolatedSince)
+
+	evs, err := bucket.Get(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.Equal(t, EventNameRecovered, evs[0].Name)
+	assert.Equal(t, "nvidia-checks", evs[0].ExtraInfo[EventKeyObjective])
+}
+
+func TestTrackerNoObservations(t *testing.T) {
+	tr := New(Config{Objectives: []Objective{{
+		Name:      "api",
+		Kind:      apiv1.SLOKindAPI,
+		Threshold: metav1.Duration{Duration: 100 * time.Millisecond},
+		Target:    0.99,
+	}}}, nil)
+
+	report := tr.Report(context.Background())
+	require.Len(t, report.Objectives, 1)
+	assert.Equal(t, int64(0), report.Objectives[0].Total)
+	assert.Equal(t, 1.0, report.Objectives[0].Attainment)
+	assert.Equal(t, 1.0, report.Objectives[0].ErrorBudgetRemaining)
+	assert.False(t, report.Objectives[0].Violated)
+	assert.Empty(t, report.Events)
+
+	var nilTracker *Tracker
+	nilTracker.ObserveAPI("/v1/states", time.Second)
+}
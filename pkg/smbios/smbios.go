@@ -0,0 +1,312 @@
+// Package smbios parses the SMBIOS (DMI) structure table exposed by the kernel,
+// for the hardware inventory (e.g., the system, the baseboard, the DIMMs)
+// with the serial numbers and the firmware versions.
+// ref. https://www.dmtf.org/standards/smbios
+package smbios
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultTablePath is the raw SMBIOS structure table exposed by the kernel,
+// readable by root only.
+const DefaultTablePath = "/sys/firmware/dmi/tables/DMI"
+
+const (
+	typeBIOS         = 0
+	typeSystem       = 1
+	typeBaseboard    = 2
+	typeMemoryDevice = 17
+	typeEndOfTable   = 127
+
+	headerLength = 4
+)
+
+// BIOS is the SMBIOS type 0 structure.
+type BIOS struct {
+	Vendor      string `json:"vendor,omitempty"`
+	Version     string `json:"version,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+}
+
+// System is the SMBIOS type 1 structure.
+type System struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"product_name,omitempty"`
+	Version      string `json:"version,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+}
+
+// Baseboard is the SMBIOS type 2 structure.
+type Baseboard struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"product_name,omitempty"`
+	Version      string `json:"version,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	AssetTag     string `json:"asset_tag,omitempty"`
+}
+
+// MemoryDevice is the SMBIOS type 17 structure of an installed memory device (e.g., a DIMM).
+type MemoryDevice struct {
+	// Locator is the socket or the board position (e.g., "DIMM_A1").
+	Locator     string `json:"locator,omitempty"`
+	BankLocator string `json:"bank_locator,omitempty"`
+	// SizeBytes is the size of the device in bytes, 0 if unknown.
+	SizeBytes uint64 `json:"size_bytes,omitempty"`
+	// Type is the memory type (e.g., "DDR5").
+	Type string `json:"type,omitempty"`
+	// SpeedMTs is the maximum speed in megatransfers per second, 0 if unknown.
+	SpeedMTs     uint32 `json:"speed_mts,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	PartNumber   string `json:"part_number,omitempty"`
+}
+
+// Tables is the parsed SMBIOS structure table.
+type Tables struct {
+	BIOS      *BIOS      `json:"bios,omitempty"`
+	System    *System    `json:"system,omitempty"`
+	Baseboard *Baseboard `json:"baseboard,omitempty"`
+	// MemoryDevices are the installed memory devices, the empty sockets are skipped.
+	MemoryDevices []MemoryDevice `json:"memory_devices,omitempty"`
+}
+
+// ReadTables reads and parses the SMBIOS structure table from the file.
+func ReadTables(path string) (*Tables, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTables(b)
+}
+
+// ParseTables parses the raw SMBIOS structure table.
+// Only the BIOS, system, baseboard, and memory device structures are parsed,
+// and the first structure is used for the type expected only once.
+func ParseTables(b []byte) (*Tables, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty smbios table")
+	}
+
+	tables := &Tables{}
+	for len(b) >= headerLength {
+		s, rest, err := nextStructure(b)
+		if err != nil {
+			return nil, err
+		}
+		b = rest
+
+		switch s.typ {
+		case typeBIOS:
+			if tables.BIOS == nil {
+				tables.BIOS = parseBIOS(s)
+			}
+		case typeSystem:
+			if tables.System == nil {
+				tables.System = parseSystem(s)
+			}
+		case typeBaseboard:
+			if tables.Baseboard == nil {
+				tables.Baseboard = parseBaseboard(s)
+			}
+		case typeMemoryDevice:
+			if md, ok := parseMemoryDevice(s); ok {
+				tables.MemoryDevices = append(tables.MemoryDevices, md)
+			}
+		case typeEndOfTable:
+			return tables, nil
+		}
+	}
+	return tables, nil
+}
+
+// structure is a single SMBIOS structure,
+// with the formatted area (including the header) and the string set.
+type structure struct {
+	typ       byte
+	formatted []byte
+	strings   []string
+}
+
+func nextStructure(b []byte) (structure, []byte, error) {
+	length := int(b[1])
+	if length < headerLength || length > len(b) {
+		return structure{}, nil, fmt.Errorf("invalid smbios structure length %d (type %d)", length, b[0])
+	}
+	s := structure{typ: b[0], formatted: b[:length]}
+
+	// the string set is terminated by two null bytes,
+	// two null bytes right after the formatted area if no string
+	rest := b[length:]
+	end := -1
+	for i := 0; i+1 < len(rest); i++ {
+		if rest[i] == 0 && rest[i+1] == 0 {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return structure{}, nil, fmt.Errorf("unterminated smbios string set (type %d)", s.typ)
+	}
+	if end > 0 {
+		s.strings = strings.Split(string(rest[:end]), "\x00")
+	}
+	return s, rest[end+2:], nil
+}
+
+// str returns the string referenced at the offset of the formatted area,
+// empty if not set or out of range.
+func (s structure) str(offset int) string {
+	if offset >= len(s.formatted) {
+		return ""
+	}
+	idx := int(s.formatted[offset])
+	if idx == 0 || idx > len(s.strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.strings[idx-1])
+}
+
+func (s structure) byteAt(offset int) (byte, bool) {
+	if offset >= len(s.formatted) {
+		return 0, false
+	}
+	return s.formatted[offset], true
+}
+
+func (s structure) word(offset int) (uint16, bool) {
+	if offset+2 > len(s.formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(s.formatted[offset:]), true
+}
+
+func (s structure) dword(offset int) (uint32, bool) {
+	if offset+4 > len(s.formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(s.formatted[offset:]), true
+}
+
+func parseBIOS(s structure) *BIOS {
+	return &BIOS{
+		Vendor:      s.str(0x04),
+		Version:     s.str(0x05),
+		ReleaseDate: s.str(0x08),
+	}
+}
+
+func parseSystem(s structure) *System {
+	sys := &System{
+		Manufacturer: s.str(0x04),
+		ProductName:  s.str(0x05),
+		Version:      s.str(0x06),
+		SerialNumber: s.str(0x07),
+	}
+	if len(s.formatted) >= 0x08+16 {
+		sys.UUID = formatUUID(s.formatted[0x08 : 0x08+16])
+	}
+	return sys
+}
+
+// formatUUID formats the SMBIOS UUID, where the first three fields are little-endian
+// (SMBIOS 2.6 or later), empty if not present or not set.
+func formatUUID(b []byte) string {
+	allZero, allOnes := true, true
+	for _, v := range b {
+		if v != 0x00 {
+			allZero = false
+		}
+		if v != 0xff {
+			allOnes = false
+		}
+	}
+	if allZero || allOnes {
+		return ""
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10],
+		b[10:16],
+	)
+}
+
+func parseBaseboard(s structure) *Baseboard {
+	return &Baseboard{
+		Manufacturer: s.str(0x04),
+		ProductName:  s.str(0x05),
+		Version:      s.str(0x06),
+		SerialNumber: s.str(0x07),
+		AssetTag:     s.str(0x08),
+	}
+}
+
+// parseMemoryDevice parses the memory device,
+// and returns false if the socket is empty.
+func parseMemoryDevice(s structure) (MemoryDevice, bool) {
+	size, ok := s.word(0x0C)
+	if !ok || size == 0 {
+		return MemoryDevice{}, false
+	}
+
+	md := MemoryDevice{
+		Locator:      s.str(0x10),
+		BankLocator:  s.str(0x11),
+		Manufacturer: s.str(0x17),
+		SerialNumber: s.str(0x18),
+		PartNumber:   s.str(0x1A),
+	}
+
+	switch {
+	case size == 0xFFFF:
+		// unknown
+	case size == 0x7FFF:
+		// the size in MB is in the extended size field
+		if ext, ok := s.dword(0x1C); ok {
+			md.SizeBytes = uint64(ext&0x7FFFFFFF) << 20
+		}
+	case size&0x8000 != 0:
+		md.SizeBytes = uint64(size&0x7FFF) << 10
+	default:
+		md.SizeBytes = uint64(size) << 20
+	}
+
+	if t, ok := s.byteAt(0x12); ok {
+		md.Type = memoryTypes[t]
+	}
+	if speed, ok := s.word(0x15); ok {
+		md.SpeedMTs = uint32(speed)
+		if speed == 0xFFFF {
+			// the speed in MT/s is in the extended speed field
+			md.SpeedMTs = 0
+			if ext, ok := s.dword(0x54); ok {
+				md.SpeedMTs = ext & 0x7FFFFFFF
+			}
+		}
+	}
+	return md, true
+}
+
+// memoryTypes maps the memory type of the memory device to its name.
+var memoryTypes = map[byte]string{
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x18: "DDR3",
+	0x1A: "DDR4",
+	0x1B: "LPDDR",
+	0x1C: "LPDDR2",
+	0x1D: "LPDDR3",
+	0x1E: "LPDDR4",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
@@ -0,0 +1,144 @@
+package smbios
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildStructure builds a raw SMBIOS structure of the formatted area length,
+// with the fields set at the offsets and the string set.
+func buildStructure(typ byte, length int, fields map[int][]byte, strs ...string) []byte {
+	b := make([]byte, length)
+	b[0] = typ
+	b[1] = byte(length)
+	for off, v := range fields {
+		copy(b[off:], v)
+	}
+	for _, s := range strs {
+		b = append(b, []byte(s)...)
+		b = append(b, 0)
+	}
+	if len(strs) == 0 {
+		b = append(b, 0)
+	}
+	return append(b, 0)
+}
+
+func le16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func testTable() []byte {
+	var b []byte
+	b = append(b, buildStructure(typeBIOS, 0x18, map[int][]byte{0x04: {1}, 0x05: {2}, 0x08: {3}}, "American Megatrends", "2.1a", "03/15/2024")...)
+	b = append(b, buildStructure(typeSystem, 0x1B, map[int][]byte{
+		0x04: {1}, 0x05: {2}, 0x06: {0}, 0x07: {3},
+		0x08: {0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+	}, "Supermicro", "SYS-821GE-TNHR", "S123456789")...)
+	b = append(b, buildStructure(typeBaseboard, 0x0F, map[int][]byte{0x04: {1}, 0x05: {2}, 0x07: {3}}, "Supermicro", "X13DEG-OAD", "BB123")...)
+
+	// installed DIMM, 64 GB
+	b = append(b, buildStructure(typeMemoryDevice, 0x28, map[int][]byte{
+		0x0C: le16(0x7FFF), 0x1C: le32(65536),
+		0x10: {1}, 0x11: {2}, 0x12: {0x22}, 0x15: le16(4800),
+		0x17: {3}, 0x18: {4}, 0x1A: {5},
+	}, "DIMM_A1", "P0_Node0_Channel0_Dimm0", "Samsung", "80CE0123ABCD", "M321R8GA0BB0-CQKZJ")...)
+	// empty socket
+	b = append(b, buildStructure(typeMemoryDevice, 0x28, map[int][]byte{0x10: {1}}, "DIMM_A2")...)
+	// installed DIMM, 16 GB in the size field
+	b = append(b, buildStructure(typeMemoryDevice, 0x28, map[int][]byte{
+		0x0C: le16(16384), 0x10: {1}, 0x12: {0x1A}, 0x15: le16(3200),
+	}, "DIMM_B1")...)
+
+	b = append(b, buildStructure(typeEndOfTable, 4, nil)...)
+	return b
+}
+
+func TestParseTables(t *testing.T) {
+	t.Parallel()
+
+	tables, err := ParseTables(testTable())
+	require.NoError(t, err)
+
+	require.NotNil(t, tables.BIOS)
+	assert.Equal(t, BIOS{Vendor: "American Megatrends", Version: "2.1a", ReleaseDate: "03/15/2024"}, *tables.BIOS)
+
+	require.NotNil(t, tables.System)
+	assert.Equal(t, "Supermicro", tables.System.Manufacturer)
+	assert.Equal(t, "SYS-821GE-TNHR", tables.System.ProductName)
+	assert.Equal(t, "", tables.System.Version)
+	assert.Equal(t, "S123456789", tables.System.SerialNumber)
+	assert.Equal(t, "00112233-4455-6677-8899-aabbccddeeff", tables.System.UUID)
+
+	require.NotNil(t, tables.Baseboard)
+	assert.Equal(t, "X13DEG-OAD", tables.Baseboard.ProductName)
+	assert.Equal(t, "BB123", tables.Baseboard.SerialNumber)
+
+	require.Len(t, tables.MemoryDevices, 2)
+	assert.Equal(t, MemoryDevice{
+		Locator:      "DIMM_A1",
+		BankLocator:  "P0_Node0_Channel0_Dimm0",
+		SizeBytes:    64 << 30,
+		Type:         "DDR5",
+		SpeedMTs:     4800,
+		Manufacturer: "Samsung",
+		SerialNumber: "80CE0123ABCD",
+		PartNumber:   "M321R8GA0BB0-CQKZJ",
+	}, tables.MemoryDevices[0])
+	assert.Equal(t, "DIMM_B1", tables.MemoryDevices[1].Locator)
+	assert.Equal(t, uint64(16<<30), tables.MemoryDevices[1].SizeBytes)
+	assert.Equal(t, "DDR4", tables.MemoryDevices[1].Type)
+}
+
+func TestParseTablesInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseTables(nil)
+	assert.Error(t, err)
+
+	// length beyond the table
+	_, err = ParseTables([]byte{typeBIOS, 0x40, 0, 0, 0, 0})
+	assert.Error(t, err)
+
+	// unterminated string set
+	_, err = ParseTables([]byte{typeBIOS, 0x04, 0, 0, 'a', 'b'})
+	assert.Error(t, err)
+}
+
+func TestFormatUUID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", formatUUID(make([]byte, 16)))
+	ones := make([]byte, 16)
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	assert.Equal(t, "", formatUUID(ones))
+}
+
+func TestReadTables(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "DMI")
+	require.NoError(t, os.WriteFile(path, testTable(), 0o644))
+
+	tables, err := ReadTables(path)
+	require.NoError(t, err)
+	assert.Equal(t, "S123456789", tables.System.SerialNumber)
+
+	_, err = ReadTables(filepath.Join(t.TempDir(), "nonexistent"))
+	assert.Error(t, err)
+}
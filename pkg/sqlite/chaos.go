@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// chaosDriverName is the SQLite driver that consults the write failure function
+// on every commit, registered once for all the chaos databases.
+const chaosDriverName = "sqlite3_chaos"
+
+// writeFailureFunc is the function to decide whether to fail the write transaction.
+var writeFailureFunc atomic.Pointer[func() bool]
+
+// chaosDriver is the base driver of the chaos databases.
+var chaosDriver = &sqlite3.SQLiteDriver{
+	ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+		// non-zero return turns the commit into a rollback,
+		// failing the write with "SQLITE_CONSTRAINT_COMMITHOOK"
+		conn.RegisterCommitHook(func() int {
+			if f := writeFailureFunc.Load(); f != nil && (*f)() {
+				return 1
+			}
+			return 0
+		})
+		return nil
+	},
+}
+
+func init() {
+	sql.Register(chaosDriverName, chaosDriver)
+}
+
+// SetWriteFailureFunc sets the function to decide whether to fail each write transaction
+// of the databases opened afterwards, for the resilience testing (e.g., chaos mode).
+// Set nil to disable, which does not affect the databases already opened.
+func SetWriteFailureFunc(f func() bool) {
+	if f == nil {
+		writeFailureFunc.Store(nil)
+		return
+	}
+	writeFailureFunc.Store(&f)
+}
+
+// driverName returns the SQLite driver to open the database with.
+func driverName(readOnly bool) string {
+	if !readOnly && writeFailureFunc.Load() != nil {
+		return chaosDriverName
+	}
+	return "sqlite3"
+}
@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWriteFailureFunc(t *testing.T) {
+	var fail atomic.Bool
+	SetWriteFailureFunc(fail.Load)
+	defer SetWriteFailureFunc(nil)
+
+	assert.Equal(t, chaosDriverName, driverName(false))
+	assert.Equal(t, "sqlite3", driverName(true))
+
+	f := filepath.Join(t.TempDir(), "chaos.db")
+	db, err := Open(f)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	_, err = db.ExecContext(ctx, "CREATE TABLE t (v INTEGER)")
+	require.NoError(t, err)
+
+	fail.Store(true)
+	_, err = db.ExecContext(ctx, "INSERT INTO t (v) VALUES (1)")
+	require.Error(t, err)
+
+	fail.Store(false)
+	_, err = db.ExecContext(ctx, "INSERT INTO t (v) VALUES (2)")
+	require.NoError(t, err)
+
+	var cnt int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM t").Scan(&cnt))
+	assert.Equal(t, 1, cnt)
+
+	SetWriteFailureFunc(nil)
+	assert.Equal(t, "sqlite3", driverName(false))
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultBusyTimeout is how long a connection waits on the lock held
+	// by another connection, before failing with SQLITE_BUSY.
+	DefaultBusyTimeout = 5 * time.Second
+	// DefaultSynchronous is the default synchronous mode,
+	// safe from the corruptions in the WAL mode while fewer fsyncs than "FULL".
+	DefaultSynchronous = "NORMAL"
+	// DefaultMaxBusyRetries is the default number of retries of the single statements
+	// outside the transactions and the transaction begins failed with SQLITE_BUSY.
+	DefaultMaxBusyRetries = 2
+)
+
+// Config tunes the SQLite lock waits and the WAL durability.
+//
+// ref. https://www.sqlite.org/pragma.html#pragma_busy_timeout
+// ref. https://www.sqlite.org/pragma.html#pragma_synchronous
+// ref. https://www.sqlite.org/pragma.html#pragma_wal_autocheckpoint
+type Config struct {
+	// BusyTimeout is how long a connection waits on the lock held by another connection.
+	// Defaults to 5 seconds if zero.
+	BusyTimeout metav1.Duration `json:"busy_timeout,omitempty"`
+	// Synchronous is the synchronous mode (one of "OFF", "NORMAL", "FULL", and "EXTRA").
+	// Defaults to "NORMAL" if empty.
+	Synchronous string `json:"synchronous,omitempty"`
+	// WALAutocheckpoint is the number of the WAL pages that triggers the automatic checkpoint.
+	// Zero keeps the SQLite default (1000 pages), and negative disables the automatic checkpoints.
+	WALAutocheckpoint int `json:"wal_autocheckpoint,omitempty"`
+	// MaxBusyRetries is the number of retries of the single statements outside the transactions
+	// and the transaction begins failed with SQLITE_BUSY, which do not modify the database
+	// on the failure thus are safe to retry.
+	// Defaults to 2 if zero, and negative disables the retries.
+	MaxBusyRetries int `json:"max_busy_retries,omitempty"`
+}
+
+// Validate returns an error if the config is invalid, nil if the config is nil.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.BusyTimeout.Duration < 0 {
+		return fmt.Errorf("busy_timeout must be non-negative, got %s", cfg.BusyTimeout.Duration)
+	}
+	switch strings.ToUpper(cfg.Synchronous) {
+	case "", "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		return fmt.Errorf("synchronous must be one of OFF, NORMAL, FULL, and EXTRA, got %q", cfg.Synchronous)
+	}
+	return nil
+}
+
+// withDefaults returns a copy of the config with the defaults filled in.
+func (cfg *Config) withDefaults() Config {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.BusyTimeout.Duration == 0 {
+		c.BusyTimeout.Duration = DefaultBusyTimeout
+	}
+	if c.Synchronous == "" {
+		c.Synchronous = DefaultSynchronous
+	}
+	c.Synchronous = strings.ToUpper(c.Synchronous)
+	if c.MaxBusyRetries == 0 {
+		c.MaxBusyRetries = DefaultMaxBusyRetries
+	}
+	if c.MaxBusyRetries < 0 {
+		c.MaxBusyRetries = 0
+	}
+	return c
+}
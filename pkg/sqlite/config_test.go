@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var cfg *Config
+	assert.NoError(t, cfg.Validate())
+
+	assert.NoError(t, (&Config{Synchronous: "full", WALAutocheckpoint: -1, MaxBusyRetries: -1}).Validate())
+	assert.Error(t, (&Config{BusyTimeout: metav1.Duration{Duration: -time.Second}}).Validate())
+	assert.Error(t, (&Config{Synchronous: "FAST"}).Validate())
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	var cfg *Config
+	assert.Equal(t, Config{
+		BusyTimeout:    metav1.Duration{Duration: DefaultBusyTimeout},
+		Synchronous:    DefaultSynchronous,
+		MaxBusyRetries: DefaultMaxBusyRetries,
+	}, cfg.withDefaults())
+
+	c := (&Config{Synchronous: "extra", MaxBusyRetries: -1}).withDefaults()
+	assert.Equal(t, "EXTRA", c.Synchronous)
+	assert.Equal(t, 0, c.MaxBusyRetries)
+}
+
+func TestBuildConnectionStringWithConfig(t *testing.T) {
+	conns, err := BuildConnectionString("/path/to/db.sqlite", WithConfig(&Config{
+		BusyTimeout:       metav1.Duration{Duration: 30 * time.Second},
+		Synchronous:       "full",
+		WALAutocheckpoint: -1,
+		MaxBusyRetries:    5,
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, conns, "_busy_timeout=30000")
+	assert.Contains(t, conns, "_synchronous=FULL")
+	assert.Contains(t, conns, "_wal_autocheckpoint=0")
+	assert.Contains(t, conns, "_busy_retries=5")
+
+	// the defaults do not set the parameters handled by the instrumented driver
+	conns, err = BuildConnectionString("/path/to/db.sqlite", WithConfig(&Config{}))
+	require.NoError(t, err)
+	assert.Contains(t, conns, "_busy_timeout=5000")
+	assert.NotContains(t, conns, "_wal_autocheckpoint")
+	assert.NotContains(t, conns, "_busy_retries")
+
+	_, err = BuildConnectionString("/path/to/db.sqlite", WithConfig(&Config{Synchronous: "FAST"}))
+	require.Error(t, err)
+}
@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// busyRetryBackoff is the wait before the first retry on the busy error,
+// doubled on every retry.
+const busyRetryBackoff = 10 * time.Millisecond
+
+const (
+	// instrumentedDriverSuffix is the suffix of the SQLite drivers
+	// with the contention instrumentation, registered for each of the base drivers.
+	instrumentedDriverSuffix = "_instrumented"
+
+	// paramWALAutocheckpoint and paramBusyRetries are the connection string parameters
+	// handled by the instrumented driver, not supported by the go-sqlite3 driver,
+	// and stripped before opening the connection.
+	paramWALAutocheckpoint = "_wal_autocheckpoint"
+	paramBusyRetries       = "_busy_retries"
+)
+
+func init() {
+	sql.Register("sqlite3"+instrumentedDriverSuffix, &instrumentedDriver{base: &sqlite3.SQLiteDriver{}})
+	sql.Register(chaosDriverName+instrumentedDriverSuffix, &instrumentedDriver{base: chaosDriver})
+}
+
+var (
+	_ driver.Driver        = &instrumentedDriver{}
+	_ driver.DriverContext = &instrumentedDriver{}
+)
+
+// instrumentedDriver opens the connections of the base driver
+// with the busy errors counted and retried, and the transactions timed.
+type instrumentedDriver struct {
+	base *sqlite3.SQLiteDriver
+}
+
+func (d *instrumentedDriver) Open(dsn string) (driver.Conn, error) {
+	c, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+func (d *instrumentedDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	c := &connector{driver: d, maxBusyRetries: DefaultMaxBusyRetries}
+
+	base, query, _ := strings.Cut(dsn, "?")
+	var params []string
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(param, "=")
+		switch k {
+		case paramWALAutocheckpoint:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", k, v, err)
+			}
+			c.walAutocheckpoint = &n
+		case paramBusyRetries:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", k, v, err)
+			}
+			c.maxBusyRetries = n
+		default:
+			if k == "mode" && v == "ro" {
+				c.readOnly = true
+			}
+			params = append(params, param)
+		}
+	}
+
+	c.dsn = base
+	if len(params) > 0 {
+		c.dsn += "?" + strings.Join(params, "&")
+	}
+	return c, nil
+}
+
+var _ driver.Connector = &connector{}
+
+// connector opens the instrumented connections, applying the per-connection pragmas
+// not supported in the connection string of the go-sqlite3 driver.
+type connector struct {
+	driver   *instrumentedDriver
+	dsn      string
+	readOnly bool
+
+	// nil to keep the SQLite default (1000 pages)
+	walAutocheckpoint *int
+	maxBusyRetries    int
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dconn, err := c.driver.base.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sconn, ok := dconn.(*sqlite3.SQLiteConn)
+	if !ok {
+		_ = dconn.Close()
+		return nil, fmt.Errorf("unexpected sqlite3 connection type %T", dconn)
+	}
+
+	// ref. https://www.sqlite.org/pragma.html#pragma_wal_autocheckpoint
+	if !c.readOnly && c.walAutocheckpoint != nil {
+		if _, err := sconn.ExecContext(ctx, fmt.Sprintf("PRAGMA wal_autocheckpoint = %d", *c.walAutocheckpoint), nil); err != nil {
+			_ = sconn.Close()
+			return nil, fmt.Errorf("failed to set wal_autocheckpoint: %w", err)
+		}
+	}
+
+	return &conn{SQLiteConn: sconn, readOnly: c.readOnly, maxBusyRetries: c.maxBusyRetries}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+var (
+	_ driver.Conn               = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.Pinger             = &conn{}
+)
+
+// conn counts the busy errors, retries the busy statements outside the transactions
+// and the busy transaction begins, and times the write transactions.
+type conn struct {
+	*sqlite3.SQLiteConn
+
+	readOnly       bool
+	maxBusyRetries int
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	// the statement outside the transaction is the implicit transaction on its own
+	autocommit := c.AutoCommit()
+	start := time.Now()
+
+	// the multiple statements may have partially applied before the busy one,
+	// thus only the single statement is safe to retry
+	retryable := autocommit && isSingleStatement(query)
+
+	var res driver.Result
+	err := c.retryOnBusy(ctx, operationExec, retryable, func() error {
+		var err error
+		res, err = c.SQLiteConn.ExecContext(ctx, query, args)
+		return err
+	})
+
+	if autocommit && !c.readOnly {
+		metricTransactionDurationSeconds.WithLabelValues(transactionAutocommit).Observe(time.Since(start).Seconds())
+	}
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := c.retryOnBusy(ctx, operationQuery, c.AutoCommit(), func() error {
+		var err error
+		rows, err = c.SQLiteConn.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var tx driver.Tx
+	err := c.retryOnBusy(ctx, operationBegin, true, func() error {
+		var err error
+		tx, err = c.SQLiteConn.BeginTx(ctx, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &timedTx{Tx: tx, start: start}, nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// retryOnBusy runs the function, and retries it on the busy errors if retryable,
+// with the doubling waits in between.
+func (c *conn) retryOnBusy(ctx context.Context, operation string, retryable bool, f func() error) error {
+	backoff := busyRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if !recordBusyError(operation, err) || !retryable || attempt >= c.maxBusyRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		metricBusyRetriesTotal.WithLabelValues(operation).Inc()
+	}
+}
+
+// isSingleStatement returns true if the query has no statement separator
+// other than the trailing one (conservatively false for the separator in a literal).
+func isSingleStatement(query string) bool {
+	return !strings.Contains(strings.TrimSuffix(strings.TrimSpace(query), ";"), ";")
+}
+
+var _ driver.Tx = &timedTx{}
+
+// timedTx times the explicit transaction from the begin to the commit or rollback.
+type timedTx struct {
+	driver.Tx
+	start time.Time
+}
+
+func (tx *timedTx) Commit() error {
+	err := tx.Tx.Commit()
+	recordBusyError(operationCommit, err)
+	metricTransactionDurationSeconds.WithLabelValues(transactionExplicit).Observe(time.Since(tx.start).Seconds())
+	return err
+}
+
+func (tx *timedTx) Rollback() error {
+	err := tx.Tx.Rollback()
+	metricTransactionDurationSeconds.WithLabelValues(transactionExplicit).Observe(time.Since(tx.start).Seconds())
+	return err
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOpenConnectorStripsParams(t *testing.T) {
+	d := &instrumentedDriver{base: &sqlite3.SQLiteDriver{}}
+
+	c, err := d.OpenConnector("file:/tmp/a.db?_busy_timeout=5000&_wal_autocheckpoint=100&_busy_retries=3&mode=ro")
+	require.NoError(t, err)
+	cc := c.(*connector)
+	assert.Equal(t, "file:/tmp/a.db?_busy_timeout=5000&mode=ro", cc.dsn)
+	assert.True(t, cc.readOnly)
+	require.NotNil(t, cc.walAutocheckpoint)
+	assert.Equal(t, 100, *cc.walAutocheckpoint)
+	assert.Equal(t, 3, cc.maxBusyRetries)
+
+	c, err = d.OpenConnector("file::memory:")
+	require.NoError(t, err)
+	cc = c.(*connector)
+	assert.Equal(t, "file::memory:", cc.dsn)
+	assert.False(t, cc.readOnly)
+	assert.Nil(t, cc.walAutocheckpoint)
+	assert.Equal(t, DefaultMaxBusyRetries, cc.maxBusyRetries)
+
+	_, err = d.OpenConnector("file:/tmp/a.db?_busy_retries=x")
+	require.Error(t, err)
+}
+
+func TestOpenWithWALAutocheckpoint(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbFile, WithConfig(&Config{WALAutocheckpoint: 50}))
+	require.NoError(t, err)
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var pages int
+	require.NoError(t, db.QueryRow("PRAGMA wal_autocheckpoint").Scan(&pages))
+	assert.Equal(t, 50, pages)
+}
+
+func TestIsSingleStatement(t *testing.T) {
+	assert.True(t, isSingleStatement("INSERT INTO t VALUES (1)"))
+	assert.True(t, isSingleStatement("INSERT INTO t VALUES (1); "))
+	assert.False(t, isSingleStatement("CREATE TABLE t (id INTEGER); CREATE INDEX i ON t (id);"))
+}
+
+func TestBusyErrorsRetried(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	cfg := &Config{BusyTimeout: metav1.Duration{Duration: time.Millisecond}, MaxBusyRetries: -1}
+
+	db1, err := Open(dbFile, WithConfig(cfg))
+	require.NoError(t, err)
+	defer func() {
+		_ = db1.Close()
+	}()
+	_, err = db1.Exec("CREATE TABLE t (id INTEGER)")
+	require.NoError(t, err)
+
+	db2, err := Open(dbFile, WithConfig(cfg))
+	require.NoError(t, err)
+	defer func() {
+		_ = db2.Close()
+	}()
+
+	// holds the write lock
+	tx, err := db1.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+
+	busyBefore := testutil.ToFloat64(metricBusyErrorsTotal.WithLabelValues(operationExec))
+	_, err = db2.Exec("INSERT INTO t VALUES (2)")
+	require.Error(t, err)
+	assert.True(t, IsBusyError(err))
+	assert.Equal(t, busyBefore+1, testutil.ToFloat64(metricBusyErrorsTotal.WithLabelValues(operationExec)))
+
+	// the write lock is released during the retries
+	db3, err := Open(dbFile, WithConfig(&Config{BusyTimeout: metav1.Duration{Duration: time.Millisecond}, MaxBusyRetries: 10}))
+	require.NoError(t, err)
+	defer func() {
+		_ = db3.Close()
+	}()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = tx.Commit()
+	}()
+
+	retriesBefore := testutil.ToFloat64(metricBusyRetriesTotal.WithLabelValues(operationExec))
+	_, err = db3.ExecContext(context.Background(), "INSERT INTO t VALUES (3)")
+	require.NoError(t, err)
+	assert.Greater(t, testutil.ToFloat64(metricBusyRetriesTotal.WithLabelValues(operationExec)), retriesBefore)
+
+	var n int
+	require.NoError(t, db3.QueryRow("SELECT COUNT(*) FROM t").Scan(&n))
+	assert.Equal(t, 2, n)
+}
+
+func TestIsBusyError(t *testing.T) {
+	assert.False(t, IsBusyError(nil))
+	assert.False(t, IsBusyError(errors.New("database is locked")))
+	assert.True(t, IsBusyError(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.True(t, IsBusyError(sqlite3.Error{Code: sqlite3.ErrLocked}))
+	assert.False(t, IsBusyError(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pkgmetrics "github.com/leptonai/gpud/pkg/metrics"
+)
+
+const (
+	operationExec   = "exec"
+	operationQuery  = "query"
+	operationBegin  = "begin"
+	operationCommit = "commit"
+
+	transactionExplicit   = "explicit"
+	transactionAutocommit = "autocommit"
+)
+
+var (
+	metricBusyErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "sqlite",
+			Name:      "busy_errors_total",
+			Help:      "total number of the SQLITE_BUSY and SQLITE_LOCKED errors (i.e., the busy timeouts hit), including the retried ones",
+		},
+		[]string{"operation"},
+	)
+	metricBusyRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gpud",
+			Subsystem: "sqlite",
+			Name:      "busy_retries_total",
+			Help:      "total number of the retries on the SQLITE_BUSY errors",
+		},
+		[]string{"operation"},
+	)
+	metricTransactionDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "sqlite",
+			Name:      "transaction_duration_seconds",
+			Help:      "duration of the write transactions, from the begin to the commit or rollback for the explicit ones",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	pkgmetrics.MustRegister(
+		metricBusyErrorsTotal,
+		metricBusyRetriesTotal,
+		metricTransactionDurationSeconds,
+	)
+}
+
+// IsBusyError returns true if the error is a SQLite "database is locked" error
+// (SQLITE_BUSY or SQLITE_LOCKED), failed to acquire the lock within the busy timeout.
+func IsBusyError(err error) bool {
+	var serr sqlite3.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	return serr.Code == sqlite3.ErrBusy || serr.Code == sqlite3.ErrLocked
+}
+
+// recordBusyError counts the error if it is a busy error, and returns true if so.
+func recordBusyError(operation string, err error) bool {
+	if !IsBusyError(err) {
+		return false
+	}
+	metricBusyErrorsTotal.WithLabelValues(operation).Inc()
+	return true
+}
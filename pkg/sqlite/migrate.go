@@ -0,0 +1,277 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// TableNameSchemaMigrations is the table of the applied schema migrations.
+	TableNameSchemaMigrations = "gpud_schema_migrations"
+
+	columnMigrationVersion   = "version"
+	columnMigrationName      = "name"
+	columnMigrationAppliedAt = "applied_at"
+)
+
+// Migration is a forward schema migration of the state database.
+// Once released, a migration must never be changed or removed,
+// as the migrated databases record its version as applied.
+type Migration struct {
+	// Version is the unique version of the migration, greater than zero.
+	// The migrations are applied in the ascending order of the versions.
+	Version int
+	// Name describes the migration (e.g., "add_metrics_unit_column").
+	Name string
+	// Up migrates the schema within the transaction.
+	// The up migrations must tolerate the tables not existing yet
+	// (e.g., on the fresh databases, the tables are created afterwards
+	// with the latest schema), thus check the tables and the columns first.
+	Up func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Migrations is the list of the migrations, in the ascending order of the versions.
+type Migrations []Migration
+
+// Validate validates the migrations are in the strictly ascending order of the versions.
+func (ms Migrations) Validate() error {
+	prev := 0
+	for _, m := range ms {
+		if m.Version <= 0 {
+			return fmt.Errorf("migration %q has invalid version %d (must be greater than zero)", m.Name, m.Version)
+		}
+		if m.Version <= prev {
+			return fmt.Errorf("migration %q version %d is not greater than the previous version %d", m.Name, m.Version, prev)
+		}
+		if m.Name == "" {
+			return fmt.Errorf("migration version %d has empty name", m.Version)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("migration %q has no up function", m.Name)
+		}
+		prev = m.Version
+	}
+	return nil
+}
+
+// CreateTableSchemaMigrations creates the table of the applied schema migrations.
+func CreateTableSchemaMigrations(ctx context.Context, dbRW *sql.DB) error {
+	_, err := dbRW.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	%s INTEGER PRIMARY KEY,
+	%s TEXT NOT NULL,
+	%s INTEGER NOT NULL
+);`, TableNameSchemaMigrations, columnMigrationVersion, columnMigrationName, columnMigrationAppliedAt))
+	return err
+}
+
+// ReadAppliedMigrationVersions returns the versions of the applied migrations.
+func ReadAppliedMigrationVersions(ctx context.Context, db *sql.DB) (map[int]struct{}, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %s`, columnMigrationVersion, TableNameSchemaMigrations))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	versions := make(map[int]struct{})
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		versions[v] = struct{}{}
+	}
+	return versions, rows.Err()
+}
+
+// PendingMigrations returns the migrations not yet applied to the database,
+// all of them if the table of the applied migrations does not exist yet.
+// It only reads the database, thus the read-only database is accepted.
+func PendingMigrations(ctx context.Context, db *sql.DB, ms Migrations) (Migrations, error) {
+	if err := ms.Validate(); err != nil {
+		return nil, err
+	}
+	tables, err := ListTables(ctx, db, TableNameSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]struct{})
+	if slices.Contains(tables, TableNameSchemaMigrations) {
+		applied, err = ReadAppliedMigrationVersions(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pending Migrations
+	for _, m := range ms {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies the pending migrations in the ascending order of the versions,
+// each in its own transaction along with its version recorded as applied,
+// so that a failed migration leaves the database at the last applied version.
+// It returns the migrations applied.
+//
+// The versions applied but unknown to the migrations (e.g., applied by a newer GPUd
+// before the downgrade) are ignored, as the migrations only add to the schema.
+func Migrate(ctx context.Context, dbRW *sql.DB, ms Migrations) (Migrations, error) {
+	if err := CreateTableSchemaMigrations(ctx, dbRW); err != nil {
+		return nil, err
+	}
+	pending, err := PendingMigrations(ctx, dbRW, ms)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied Migrations
+	for _, m := range pending {
+		if err := runMigrations(ctx, dbRW, Migrations{m}, false); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// CheckMigrations validates the pending migrations apply cleanly without applying them,
+// by running all of them against a snapshot of the database in a temporary file.
+// The database is only read (e.g., opened read-only while GPUd is running).
+// It returns the pending migrations checked.
+func CheckMigrations(ctx context.Context, db *sql.DB, ms Migrations) (Migrations, error) {
+	pending, err := PendingMigrations(ctx, db, ms)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gpud-migrate-check")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	// the consistent snapshot, even while the database is written
+	snapshot := filepath.Join(dir, "state.db")
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", snapshot); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	snapshotDB, err := Open(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = snapshotDB.Close()
+	}()
+
+	if err := CreateTableSchemaMigrations(ctx, snapshotDB); err != nil {
+		return nil, err
+	}
+	return pending, runMigrations(ctx, snapshotDB, pending, true)
+}
+
+// runMigrations runs the migrations in a single transaction,
+// and commits or rolls back (if dry run) the transaction.
+func runMigrations(ctx context.Context, dbRW *sql.DB, ms Migrations, dryRun bool) (retErr error) {
+	tx, err := dbRW.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if dryRun || retErr != nil {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) && retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+
+	for _, m := range ms {
+		if err := m.Up(ctx, tx); err != nil {
+			return fmt.Errorf("failed to apply migration %d %q: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)`,
+			TableNameSchemaMigrations, columnMigrationVersion, columnMigrationName, columnMigrationAppliedAt),
+			m.Version, m.Name, time.Now().UTC().Unix()); err != nil {
+			return fmt.Errorf("failed to record migration %d %q: %w", m.Version, m.Name, err)
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// queryer is the common interface of *sql.DB and *sql.Tx to query.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// ColumnExists returns true if the table has the column,
+// or false if the table or the column does not exist.
+func ColumnExists(ctx context.Context, db queryer, table string, column string) (bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	found := false
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			typ       string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			found = true
+		}
+	}
+	return found, rows.Err()
+}
+
+// ListTables returns the names of the tables with the prefix (all tables if empty),
+// for the migrations of the tables created per component (e.g., the events tables).
+func ListTables(ctx context.Context, db queryer, prefix string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND substr(name, 1, length(?)) = ? ORDER BY name`, prefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
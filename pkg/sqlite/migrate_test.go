@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addColumnMigration(version int, column string) Migration {
+	return Migration{
+		Version: version,
+		Name:    "add_" + column,
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			found, err := ColumnExists(ctx, tx, "test_metrics", column)
+			if err != nil || found {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, "ALTER TABLE test_metrics ADD COLUMN "+column+" TEXT")
+			return err
+		},
+	}
+}
+
+func TestMigrationsValidate(t *testing.T) {
+	up := func(context.Context, *sql.Tx) error { return nil }
+
+	assert.NoError(t, Migrations(nil).Validate())
+	assert.NoError(t, Migrations{{Version: 1, Name: "a", Up: up}, {Version: 3, Name: "b", Up: up}}.Validate())
+
+	assert.Error(t, Migrations{{Version: 0, Name: "a", Up: up}}.Validate())
+	assert.Error(t, Migrations{{Version: 2, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}}.Validate())
+	assert.Error(t, Migrations{{Version: 1, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}}.Validate())
+	assert.Error(t, Migrations{{Version: 1, Up: up}}.Validate())
+	assert.Error(t, Migrations{{Version: 1, Name: "a"}}.Validate())
+}
+
+func TestMigrate(t *testing.T) {
+	dbRW, dbRO, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := dbRW.ExecContext(ctx, "CREATE TABLE test_metrics (name TEXT NOT NULL)")
+	require.NoError(t, err)
+	_, err = dbRW.ExecContext(ctx, "INSERT INTO test_metrics (name) VALUES ('a')")
+	require.NoError(t, err)
+
+	ms := Migrations{addColumnMigration(1, "unit")}
+
+	applied, err := Migrate(ctx, dbRW, ms)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, 1, applied[0].Version)
+
+	found, err := ColumnExists(ctx, dbRO, "test_metrics", "unit")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// already applied
+	applied, err = Migrate(ctx, dbRW, ms)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// the next release adds a migration
+	ms = append(ms, addColumnMigration(2, "tags"))
+	applied, err = Migrate(ctx, dbRW, ms)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, 2, applied[0].Version)
+
+	// the rows are kept
+	var n int
+	require.NoError(t, dbRO.QueryRowContext(ctx, "SELECT COUNT(*) FROM test_metrics").Scan(&n))
+	assert.Equal(t, 1, n)
+
+	versions, err := ReadAppliedMigrationVersions(ctx, dbRO)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]struct{}{1: {}, 2: {}}, versions)
+
+	// downgraded to the release without the second migration
+	applied, err = Migrate(ctx, dbRW, ms[:1])
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrateFailure(t *testing.T) {
+	dbRW, dbRO, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := dbRW.ExecContext(ctx, "CREATE TABLE test_metrics (name TEXT NOT NULL)")
+	require.NoError(t, err)
+
+	errMigration := errors.New("migration failed")
+	ms := Migrations{
+		addColumnMigration(1, "unit"),
+		{
+			Version: 2,
+			Name:    "add_tags_and_fail",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, "ALTER TABLE test_metrics ADD COLUMN tags TEXT"); err != nil {
+					return err
+				}
+				return errMigration
+			},
+		},
+	}
+
+	applied, err := Migrate(ctx, dbRW, ms)
+	require.ErrorIs(t, err, errMigration)
+	require.Len(t, applied, 1)
+
+	// the failed migration is rolled back, and left pending
+	found, err := ColumnExists(ctx, dbRO, "test_metrics", "tags")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	pending, err := PendingMigrations(ctx, dbRW, ms)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 2, pending[0].Version)
+}
+
+func TestCheckMigrations(t *testing.T) {
+	dbRW, dbRO, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := dbRW.ExecContext(ctx, "CREATE TABLE test_metrics (name TEXT NOT NULL)")
+	require.NoError(t, err)
+
+	ms := Migrations{addColumnMigration(1, "unit"), addColumnMigration(2, "tags")}
+
+	// only reads the database, not even creating the table of the applied migrations
+	pending, err := CheckMigrations(ctx, dbRO, ms)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	// nothing applied
+	found, err := ColumnExists(ctx, dbRO, "test_metrics", "unit")
+	require.NoError(t, err)
+	assert.False(t, found)
+	tables, err := ListTables(ctx, dbRO, TableNameSchemaMigrations)
+	require.NoError(t, err)
+	assert.Empty(t, tables)
+
+	// the later migration depending on the earlier one fails the check
+	ms = append(ms, Migration{
+		Version: 3,
+		Name:    "drop_unit",
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "ALTER TABLE test_metrics DROP COLUMN nonexistent")
+			return err
+		},
+	})
+	_, err = CheckMigrations(ctx, dbRO, ms)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `migration 3 "drop_unit"`)
+
+	// nothing pending once applied
+	_, err = Migrate(ctx, dbRW, ms[:2])
+	require.NoError(t, err)
+	pending, err = CheckMigrations(ctx, dbRO, ms[:2])
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestListTables(t *testing.T) {
+	dbRW, dbRO, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, table := range []string{"components_a_events", "components_b_events", "gpud_metrics"} {
+		_, err := dbRW.ExecContext(ctx, "CREATE TABLE "+table+" (name TEXT)")
+		require.NoError(t, err)
+	}
+
+	tables, err := ListTables(ctx, dbRO, "components_")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"components_a_events", "components_b_events"}, tables)
+
+	tables, err = ListTables(ctx, dbRO, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"components_a_events", "components_b_events", "gpud_metrics"}, tables)
+}
+
+func TestColumnExistsNoTable(t *testing.T) {
+	dbRW, _, cleanup := OpenTestDB(t)
+	defer cleanup()
+
+	found, err := ColumnExists(context.Background(), dbRW, "nonexistent", "name")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
@@ -3,6 +3,7 @@ package sqlite
 type Op struct {
 	readOnly bool
 	cache    string // cache mode for in-memory databases (e.g., "shared")
+	config   *Config
 }
 
 type OpOption func(*Op)
@@ -12,6 +13,10 @@ func (op *Op) applyOpts(opts []OpOption) error {
 		opt(op)
 	}
 
+	if err := op.config.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -31,3 +36,11 @@ func WithCache(mode string) OpOption {
 		op.cache = mode
 	}
 }
+
+// WithConfig sets the busy timeout, the synchronous mode, the WAL autocheckpoint,
+// and the busy retries. If nil (default), uses the defaults.
+func WithConfig(cfg *Config) OpOption {
+	return func(op *Op) {
+		op.config = cfg
+	}
+}
@@ -54,7 +54,17 @@ func BuildConnectionString(file string, opts ...OpOption) (string, error) {
 	// ref. https://github.com/mattn/go-sqlite3/blob/7658c06970ecf5588d8cd930ed1f2de7223f1010/sqlite3.go#L975
 	// Note: WAL mode is ignored for in-memory databases (SQLite uses default mode), but including it
 	// for consistency and to handle any edge cases where file might not be ":memory:".
-	conns += separator + "_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL"
+	cfg := op.config.withDefaults()
+	conns += separator + fmt.Sprintf("_busy_timeout=%d&_journal_mode=WAL&_synchronous=%s", cfg.BusyTimeout.Milliseconds(), cfg.Synchronous)
+
+	// handled by the instrumented driver, as not supported by the go-sqlite3 driver
+	if op.config != nil && op.config.WALAutocheckpoint != 0 {
+		// zero pages disables the automatic checkpoints
+		conns += fmt.Sprintf("&%s=%d", paramWALAutocheckpoint, max(op.config.WALAutocheckpoint, 0))
+	}
+	if op.config != nil && op.config.MaxBusyRetries != 0 {
+		conns += fmt.Sprintf("&%s=%d", paramBusyRetries, cfg.MaxBusyRetries)
+	}
 
 	if op.readOnly {
 		conns += "&mode=ro"
@@ -73,15 +83,16 @@ func Open(file string, opts ...OpOption) (*sql.DB, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", conns)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open sqlite3 database: %w (%q)", err, conns)
-	}
-
 	// Check if this is a read-only connection by checking the options
 	op := &Op{}
 	_ = op.applyOpts(opts)
 
+	// the busy errors are counted and retried, and the transactions are timed
+	db, err := sql.Open(driverName(op.readOnly)+instrumentedDriverSuffix, conns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite3 database: %w (%q)", err, conns)
+	}
+
 	if !op.readOnly {
 		// single connection for writing
 		db.SetMaxOpenConns(1)
@@ -6,7 +6,7 @@ import (
 	"testing"
 )
 
-func OpenTestDB(t *testing.T) (*sql.DB, *sql.DB, func()) {
+func OpenTestDB(t testing.TB) (*sql.DB, *sql.DB, func()) {
 	tmpf, err := os.CreateTemp(os.TempDir(), "test-sqlite")
 	if err != nil {
 		t.Fatalf("failed to create temp file: %v", err)
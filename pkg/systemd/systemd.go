@@ -183,3 +183,67 @@ func parseSystemdUnitUptime(s string) (time.Duration, error) {
 	}
 	return time.Since(t), nil
 }
+
+// UnitState is the state of the systemd unit.
+// ref. https://www.freedesktop.org/software/systemd/man/latest/systemctl.html
+type UnitState struct {
+	// LoadState is "loaded" if the unit file is found, "not-found" otherwise.
+	LoadState string `json:"load_state"`
+	// ActiveState is the high-level state (e.g., "active", "inactive", "failed").
+	ActiveState string `json:"active_state"`
+	// SubState is the low-level state (e.g., "running", "dead", "exited").
+	SubState string `json:"sub_state"`
+	// UnitFileState is the enablement state (e.g., "enabled", "disabled", "masked").
+	UnitFileState string `json:"unit_file_state"`
+}
+
+// GetUnitState returns the state of the systemd unit,
+// where the unit not installed is returned with the "not-found" load state.
+func GetUnitState(ctx context.Context, unit string) (UnitState, error) {
+	p, err := exec.LookPath("systemctl")
+	if err != nil {
+		return UnitState{}, fmt.Errorf("systemd unit state check requires systemctl (%w)", err)
+	}
+
+	b, err := exec.CommandContext(ctx, p, "show", "--property=LoadState,ActiveState,SubState,UnitFileState", unit).CombinedOutput()
+	if err != nil {
+		return UnitState{}, fmt.Errorf("failed to get unit state %q: %w (output: %s)", unit, err, strings.TrimSpace(string(b)))
+	}
+	return parseUnitState(string(b)), nil
+}
+
+// parseUnitState parses the "systemctl show" output, for example,
+//
+//	LoadState=loaded
+//	ActiveState=failed
+//	SubState=failed
+//	UnitFileState=enabled
+func parseUnitState(s string) UnitState {
+	var st UnitState
+	for _, line := range strings.Split(s, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "LoadState":
+			st.LoadState = v
+		case "ActiveState":
+			st.ActiveState = v
+		case "SubState":
+			st.SubState = v
+		case "UnitFileState":
+			st.UnitFileState = v
+		}
+	}
+	return st
+}
+
+// RestartUnit restarts the systemd unit, and returns the combined output.
+func RestartUnit(ctx context.Context, unit string) ([]byte, error) {
+	p, err := exec.LookPath("systemctl")
+	if err != nil {
+		return nil, fmt.Errorf("systemd unit restart requires systemctl (%w)", err)
+	}
+	return exec.CommandContext(ctx, p, "restart", unit).CombinedOutput()
+}
@@ -102,3 +102,17 @@ func TestParseSystemdUnitUptime(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUnitState(t *testing.T) {
+	t.Parallel()
+
+	st := parseUnitState("LoadState=loaded\nActiveState=failed\nSubState=failed\nUnitFileState=enabled\n")
+	if st != (UnitState{LoadState: "loaded", ActiveState: "failed", SubState: "failed", UnitFileState: "enabled"}) {
+		t.Errorf("unexpected unit state: %+v", st)
+	}
+
+	st = parseUnitState("LoadState=not-found\nActiveState=inactive\nSubState=dead\nUnitFileState=\ninvalid line\n")
+	if st != (UnitState{LoadState: "not-found", ActiveState: "inactive", SubState: "dead"}) {
+		t.Errorf("unexpected unit state: %+v", st)
+	}
+}
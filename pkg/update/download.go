@@ -51,6 +51,13 @@ func downloadLinuxTarball(ctx context.Context, ver, pkgAddr string) (string, err
 	return dlPath, nil
 }
 
+// DownloadTarball downloads the release tarball of the version,
+// and validates its signature with the signing keys issued by the embedded root keys.
+// It returns the path of the downloaded tarball.
+func DownloadTarball(ctx context.Context, ver, pkgAddr string) (string, error) {
+	return downloadLinuxTarball(ctx, ver, pkgAddr)
+}
+
 func writeFile(r io.Reader, path string, perm os.FileMode) error {
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove existing file at %q: %w", path, err)